@@ -18,8 +18,6 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
-	"github.com/juju/utils"
-	"github.com/juju/utils/series"
 	"github.com/juju/utils/shell"
 
 	"github.com/juju/juju/api"
@@ -42,10 +40,10 @@ const (
 
 // These are base values used for the corresponding defaults.
 var (
-	logDir          = paths.MustSucceed(paths.LogDir(series.HostSeries()))
-	dataDir         = paths.MustSucceed(paths.DataDir(series.HostSeries()))
-	confDir         = paths.MustSucceed(paths.ConfDir(series.HostSeries()))
-	metricsSpoolDir = paths.MustSucceed(paths.MetricsSpoolDir(series.HostSeries()))
+	logDir          = paths.MustSucceed(paths.HostLogDir())
+	dataDir         = paths.MustSucceed(paths.HostDataDir())
+	confDir         = paths.MustSucceed(paths.HostConfDir())
+	metricsSpoolDir = paths.MustSucceed(paths.HostMetricsSpoolDir())
 )
 
 // Agent exposes the agent's configuration to other components. This
@@ -164,6 +162,54 @@ const (
 	MongoOplogSize         = "MONGO_OPLOG_SIZE"
 	NumaCtlPreference      = "NUMA_CTL_PREFERENCE"
 	AllowsSecureConnection = "SECURE_STATESERVER_CONNECTION"
+
+	// LogForwarding holds the comma-separated destinations that the
+	// agent forwards its logs to, as well as its log file; see
+	// logging.InstallForwarding.
+	LogForwarding = "LOG_FORWARDING"
+
+	// AuditLogging is "false" to disable auditing. Otherwise the
+	// agent writes the messages logged to the audit module to a
+	// tamper-evident audit log (see logging.InstallAuditLog) and, on
+	// a state server, its API server records the calls made to it
+	// both there and in state (see apiserver.AuditConfig).
+	AuditLogging = "AUDIT_LOGGING"
+
+	// AuditLogKey holds the secret the agent's audit log is hashed
+	// with. It is generated when the audit log is first written.
+	AuditLogKey = "AUDIT_LOG_KEY"
+
+	// These override the limits a state server's API server applies
+	// to each of its clients; see apiserver.Limits.
+	APIMaxConnectionsPerEntity = "API_MAX_CONNECTIONS_PER_ENTITY"
+	APIAddressLoginRate        = "API_ADDRESS_LOGIN_RATE"
+	APIAddressLoginBurst       = "API_ADDRESS_LOGIN_BURST"
+	APILoginRate               = "API_LOGIN_RATE"
+	APILoginBurst              = "API_LOGIN_BURST"
+	APIFacadeCallRate          = "API_FACADE_CALL_RATE"
+	APIFacadeCallBurst         = "API_FACADE_CALL_BURST"
+
+	// These configure the record a state server's API server keeps of
+	// the calls made to it; see apiserver.AuditConfig. They hold
+	// comma-separated lists that replace the defaults.
+	APIAuditRedactedParams  = "API_AUDIT_REDACTED_PARAMS"
+	APIAuditFacades         = "API_AUDIT_FACADES"
+	APIAuditExcludedFacades = "API_AUDIT_EXCLUDED_FACADES"
+
+	// APIReadMirror is "true" to have a state server's API server
+	// serve read-only calls from a secondary member of the mongo
+	// replica set; see apiserver.ServerConfig.ReadMirror.
+	APIReadMirror = "API_READ_MIRROR"
+
+	// These hold the shell commands that a machine's provisioner
+	// runs before it starts an instance, after the instance has
+	// started, and before it terminates an instance; see
+	// provisioner.NewInstanceHooks. They are run as root on the
+	// machine, so they may only be set in its agent configuration,
+	// never through the environment configuration.
+	PreStartHook     = "PRE_START_HOOK"
+	PostStartHook    = "POST_START_HOOK"
+	PreTerminateHook = "PRE_TERMINATE_HOOK"
 )
 
 // The Config interface is the sole way that the agent gets access to the
@@ -304,6 +350,11 @@ func LogFilename(c Config) string {
 	return filepath.Join(c.LogDir(), c.Tag().String()+".log")
 }
 
+// AuditLogFilename returns the filename for the Agent's audit log.
+func AuditLogFilename(c Config) string {
+	return filepath.Join(c.LogDir(), c.Tag().String()+"-audit.log")
+}
+
 type ConfigMutator func(ConfigSetter) error
 
 type ConfigWriter interface {
@@ -522,6 +573,9 @@ func ReadConfig(configFilePath string) (ConfigSetterWriter, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := config.check(); err != nil {
+		return nil, errors.Annotatef(err, "invalid agent config %q", configFilePath)
+	}
 	logger.Debugf("read agent config, format %q", format.version())
 	config.configFilePath = configFilePath
 	if format != currentFormat {
@@ -615,7 +669,13 @@ func (c *configInternal) SetPassword(newPassword string) {
 	}
 }
 
+// Write is defined on ConfigWriter. It refuses to write an invalid
+// config, and replaces the file such that a crash or power loss cannot
+// leave it partially written.
 func (c *configInternal) Write() error {
+	if err := c.check(); err != nil {
+		return errors.Annotate(err, "cannot write invalid agent config")
+	}
 	data, err := c.fileContents()
 	if err != nil {
 		return err
@@ -625,7 +685,7 @@ func (c *configInternal) Write() error {
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("cannot create agent config dir %q: %v", configDir, err)
 	}
-	return utils.AtomicWriteFile(c.configFilePath, data, 0600)
+	return writeFileDurably(c.configFilePath, data, 0600)
 }
 
 func requiredError(what string) error {
@@ -6,6 +6,7 @@ package agent_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"reflect"
 
@@ -584,6 +585,51 @@ func (*suite) TestWriteAndRead(c *gc.C) {
 	c.Assert(reread, jc.DeepEquals, conf)
 }
 
+func (*suite) TestWriteLeavesNoTempFiles(c *gc.C) {
+	testParams := attributeParams
+	testParams.Paths.DataDir = c.MkDir()
+	testParams.Paths.LogDir = c.MkDir()
+	conf, err := agent.NewAgentConfig(testParams)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(conf.Write(), gc.IsNil)
+	c.Assert(conf.Write(), gc.IsNil)
+	infos, err := ioutil.ReadDir(conf.Dir())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(infos, gc.HasLen, 1)
+	c.Assert(infos[0].Name(), gc.Equals, "agent.conf")
+}
+
+func (*suite) TestWriteInvalid(c *gc.C) {
+	testParams := attributeParams
+	testParams.Paths.DataDir = c.MkDir()
+	testParams.Paths.LogDir = c.MkDir()
+	conf, err := agent.NewAgentConfig(testParams)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conf.Write(), gc.IsNil)
+
+	conf.SetAPIHostPorts([][]network.HostPort{})
+	err = conf.Write()
+	c.Assert(err, gc.ErrorMatches, "cannot write invalid agent config: API server address not found in configuration")
+
+	// The previously written config is untouched.
+	reread, err := agent.ReadConfig(agent.ConfigPath(conf.DataDir(), conf.Tag()))
+	c.Assert(err, jc.ErrorIsNil)
+	addrs, err := reread.APIAddresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addrs, jc.DeepEquals, []string{"localhost:1235"})
+}
+
+func (*suite) TestReadConfigTruncated(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "agent.conf")
+	data := "# format 1.18\ntag: machine-0\ndatadir: /var/lib/juju\n"
+	err := ioutil.WriteFile(path, []byte(data), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = agent.ReadConfig(path)
+	c.Assert(err, gc.ErrorMatches, `invalid agent config ".*agent.conf": state or API addresses not found in configuration`)
+}
+
 func (*suite) TestAPIInfoMissingAddress(c *gc.C) {
 	conf := agent.EmptyConfig()
 	_, ok := conf.APIInfo()
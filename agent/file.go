@@ -0,0 +1,70 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// writeFileDurably atomically replaces the named file with the given
+// contents, such that after a crash or power loss the file holds
+// either its old or its new contents in full. The contents are written
+// and synced to a temporary file in the same directory, which is then
+// renamed over the original; finally the directory itself is synced,
+// so that the rename is not lost.
+func writeFileDurably(filename string, contents []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(filename)
+	f, err := ioutil.TempFile(dir, filepath.Base(filename)+".tmp")
+	if err != nil {
+		return errors.Annotate(err, "cannot create temp file")
+	}
+	defer func() {
+		if err != nil {
+			// Don't leave the temp file lying around on error. It
+			// must be closed before removal on windows.
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err := f.Write(contents); err != nil {
+		return errors.Annotatef(err, "cannot write %q contents", filename)
+	}
+	// File.Chmod is not implemented on windows, so use os.Chmod.
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return errors.Trace(err)
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Annotatef(err, "cannot sync %q", f.Name())
+	}
+	if err := f.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := utils.ReplaceFile(f.Name(), filename); err != nil {
+		return errors.Annotatef(err, "cannot replace %q with %q", filename, f.Name())
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes the directory entries of the named directory to
+// disk. Directories cannot be synced on windows, where it is a no-op.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return errors.Annotatef(err, "cannot sync directory %q", dir)
+	}
+	return nil
+}
@@ -76,8 +76,19 @@ func (c *Client) ListCompleted(arg params.Entities) (params.ActionsByReceivers,
 	return results, err
 }
 
-// Cancel attempts to cancel a queued up Action from running.
-func (c *Client) Cancel(arg params.Actions) (params.ActionResults, error) {
+// ListQueued takes a list of Entities representing ActionReceivers
+// and returns all of the Actions that are waiting for a free slot to
+// be dispatched to each of those Entities.
+func (c *Client) ListQueued(arg params.Entities) (params.ActionsByReceivers, error) {
+	results := params.ActionsByReceivers{}
+	err := c.facade.FacadeCall("ListQueued", arg, &results)
+	return results, err
+}
+
+// Cancel takes a list of Entities representing Actions and cancels
+// them: pending Actions are cancelled at once, and running Actions are
+// stopped by the agents running them.
+func (c *Client) Cancel(arg params.Entities) (params.ActionResults, error) {
 	results := params.ActionResults{}
 	err := c.facade.FacadeCall("Cancel", arg, &results)
 	return results, err
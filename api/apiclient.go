@@ -127,6 +127,11 @@ func open(info *Info, opts DialOpts, loginFunc func(st *state, tag names.Tag, pw
 			return nil, errors.New("open should specifiy UseMacaroons or a username & password. Not both")
 		}
 	}
+	if info.Token != "" {
+		if info.Tag != nil || info.Password != "" || info.UseMacaroons {
+			return nil, errors.New("open should specify a token or other credentials. Not both")
+		}
+	}
 	conn, tlsConfig, err := connectWebsocket(info, opts)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -172,7 +177,12 @@ func open(info *Info, opts DialOpts, loginFunc func(st *state, tag names.Tag, pw
 		tlsConfig:    tlsConfig,
 		bakeryClient: bakeryClient,
 	}
-	if info.Tag != nil || info.Password != "" || info.UseMacaroons {
+	if info.Token != "" {
+		if err := st.loginWithToken(info.Token); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	} else if info.Tag != nil || info.Password != "" || info.UseMacaroons {
 		if err := loginFunc(st, info.Tag, info.Password, info.Nonce); err != nil {
 			conn.Close()
 			return nil, err
@@ -0,0 +1,34 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the record the API servers keep of the
+// calls made to them.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new audit log client.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "AuditLog")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Entries returns the audit log entries selected by the filter, newest
+// first.
+func (c *Client) Entries(filter params.AuditLogFilter) ([]params.AuditLogEntry, error) {
+	var result params.AuditLogEntries
+	if err := c.facade.FacadeCall("Entries", filter, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Entries, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/auditlog"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type auditLogSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&auditLogSuite{})
+
+func (s *auditLogSuite) TestEntries(c *gc.C) {
+	filter := params.AuditLogFilter{Caller: "user-admin", Limit: 10}
+	entries := []params.AuditLogEntry{{
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "FullStatus",
+		ResultCode: "ok",
+	}}
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			called = true
+			c.Check(objType, gc.Equals, "AuditLog")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "Entries")
+			c.Check(arg, jc.DeepEquals, filter)
+			*(result.(*params.AuditLogEntries)) = params.AuditLogEntries{Entries: entries}
+			return nil
+		})
+	client := auditlog.NewClient(apiCaller)
+	found, err := client.Entries(filter)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(found, jc.DeepEquals, entries)
+}
+
+func (s *auditLogSuite) TestEntriesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			return errors.New("boom")
+		})
+	client := auditlog.NewClient(apiCaller)
+	found, err := client.Entries(params.AuditLogFilter{})
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(found, gc.IsNil)
+}
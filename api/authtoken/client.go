@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authtoken
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client issues and revokes the bearer tokens that let external
+// systems call the API on behalf of the logged in user.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new auth token client.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "AuthToken")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Issue issues a token that permits calls to the given scopes until it
+// expires. Each scope names a facade, or a facade method as
+// "Facade.Method".
+func (c *Client) Issue(scopes []string, expires time.Time) (params.IssuedAuthToken, error) {
+	args := params.IssueAuthToken{
+		Scopes:  scopes,
+		Expires: expires,
+	}
+	var result params.IssuedAuthToken
+	if err := c.facade.FacadeCall("Issue", args, &result); err != nil {
+		return params.IssuedAuthToken{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// Tokens returns the tokens issued for the logged in user.
+func (c *Client) Tokens() ([]params.AuthToken, error) {
+	var result params.AuthTokens
+	if err := c.facade.FacadeCall("Tokens", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Tokens, nil
+}
+
+// Revoke revokes the token with the given id.
+func (c *Client) Revoke(id string) error {
+	args := params.AuthTokenIds{Ids: []string{id}}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall("Revoke", args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
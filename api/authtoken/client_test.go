@@ -0,0 +1,73 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authtoken_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/authtoken"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type authTokenSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&authTokenSuite{})
+
+func (s *authTokenSuite) TestIssue(c *gc.C) {
+	expires := time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC)
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "AuthToken")
+			c.Check(request, gc.Equals, "Issue")
+			c.Check(arg, jc.DeepEquals, params.IssueAuthToken{
+				Scopes:  []string{"Client"},
+				Expires: expires,
+			})
+			*(result.(*params.IssuedAuthToken)) = params.IssuedAuthToken{Id: "id", Token: "token"}
+			return nil
+		})
+	client := authtoken.NewClient(apiCaller)
+	issued, err := client.Issue([]string{"Client"}, expires)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(issued, jc.DeepEquals, params.IssuedAuthToken{Id: "id", Token: "token"})
+}
+
+func (s *authTokenSuite) TestTokens(c *gc.C) {
+	tokens := []params.AuthToken{{Id: "id", User: "user-bob", Scopes: []string{"Client"}}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "AuthToken")
+			c.Check(request, gc.Equals, "Tokens")
+			c.Check(arg, gc.IsNil)
+			*(result.(*params.AuthTokens)) = params.AuthTokens{Tokens: tokens}
+			return nil
+		})
+	client := authtoken.NewClient(apiCaller)
+	found, err := client.Tokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.DeepEquals, tokens)
+}
+
+func (s *authTokenSuite) TestRevoke(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "AuthToken")
+			c.Check(request, gc.Equals, "Revoke")
+			c.Check(arg, jc.DeepEquals, params.AuthTokenIds{Ids: []string{"id"}})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		})
+	client := authtoken.NewClient(apiCaller)
+	err := client.Revoke("id")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
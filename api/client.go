@@ -5,6 +5,7 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -138,6 +139,15 @@ func (c *Client) PrivateAddress(target string) (string, error) {
 	return results.PrivateAddress, err
 }
 
+// SSHHostKeys returns the SSH host keys recorded for the specified
+// machine or unit, in the authorized_keys format.
+func (c *Client) SSHHostKeys(target string) ([]string, error) {
+	var results params.SSHHostKeysResults
+	p := params.SSHHostKeys{Target: target}
+	err := c.facade.FacadeCall("SSHHostKeys", p, &results)
+	return results.Keys, err
+}
+
 // ServiceSetYAML sets configuration options on a service
 // given options in YAML format.
 func (c *Client) ServiceSetYAML(service string, yaml string) error {
@@ -494,6 +504,36 @@ func (c *Client) UnshareEnvironment(users ...names.UserTag) error {
 	return result.Combine()
 }
 
+// GrantEnvironment gives the user the given level of access ("read",
+// "write" or "admin") to the environment, sharing the environment with
+// them if necessary.
+func (c *Client) GrantEnvironment(user names.UserTag, access string) error {
+	return c.modifyEnvironmentAccess(user, params.GrantEnvUser, access)
+}
+
+// RevokeEnvironment takes the given level of access to the environment
+// away from the user. If access is empty, or "read", the user can no
+// longer access the environment at all.
+func (c *Client) RevokeEnvironment(user names.UserTag, access string) error {
+	return c.modifyEnvironmentAccess(user, params.RevokeEnvUser, access)
+}
+
+func (c *Client) modifyEnvironmentAccess(user names.UserTag, action params.EnvironAction, access string) error {
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: user.String(),
+			Action:  action,
+			Access:  access,
+		}},
+	}
+	var result params.ErrorResults
+	err := c.facade.FacadeCall("ShareEnvironment", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
 // WatchAll holds the id of the newly-created AllWatcher/AllEnvWatcher.
 type WatchAll struct {
 	AllWatcherId string
@@ -584,18 +624,43 @@ func (c *Client) FindTools(majorVersion, minorVersion int, series, arch string)
 // RunOnAllMachines runs the command on all the machines with the specified
 // timeout.
 func (c *Client) RunOnAllMachines(commands string, timeout time.Duration) ([]params.RunResult, error) {
-	var results params.RunResults
-	args := params.RunParams{Commands: commands, Timeout: timeout}
-	err := c.facade.FacadeCall("RunOnAllMachines", args, &results)
+	results, err := c.RunOnAllMachinesRecorded(params.RunParams{Commands: commands, Timeout: timeout})
 	return results.Results, err
 }
 
+// RunOnAllMachinesRecorded runs the commands on all the machines, with
+// the timeout and concurrency limit specified in run. Any targets
+// specified in run are ignored. The results are returned along with
+// the Id under which they are recorded; see RunResults.
+func (c *Client) RunOnAllMachinesRecorded(run params.RunParams) (params.RunResults, error) {
+	var results params.RunResults
+	err := c.facade.FacadeCall("RunOnAllMachines", run, &results)
+	return results, err
+}
+
 // Run the Commands specified on the machines identified through the ids
 // provided in the machines, services and units slices.
 func (c *Client) Run(run params.RunParams) ([]params.RunResult, error) {
+	results, err := c.RunRecorded(run)
+	return results.Results, err
+}
+
+// RunRecorded runs the Commands specified on the machines identified
+// through the ids provided in the machines, services and units slices.
+// The results are returned along with the Id under which they are
+// recorded; see RunResults.
+func (c *Client) RunRecorded(run params.RunParams) (params.RunResults, error) {
 	var results params.RunResults
 	err := c.facade.FacadeCall("Run", run, &results)
-	return results.Results, err
+	return results, err
+}
+
+// RunResults returns the results of an earlier Run or RunOnAllMachines
+// call, identified by the Id returned with them.
+func (c *Client) RunResults(id string) (params.RunResults, error) {
+	var results params.RunResults
+	err := c.facade.FacadeCall("RunResults", params.RunResultsId{Id: id}, &results)
+	return results, err
 }
 
 // DestroyEnvironment puts the environment into a "dying" state,
@@ -918,3 +983,66 @@ func (c *Client) WatchDebugLog(args DebugLogParams) (io.ReadCloser, error) {
 	}
 	return connection, nil
 }
+
+// WatchStreamParams holds the optional parameters for WatchStream.
+type WatchStreamParams struct {
+	// Gzip requests that the frames are compressed.
+	Gzip bool
+
+	// ResumeToken holds the resume token of the last frame received
+	// on an earlier stream. If the server still holds the frames that
+	// followed it, the new stream starts with them; otherwise its first
+	// frame has Resync set.
+	ResumeToken string
+}
+
+// WatchStream returns a stream of the changes to the environment. Each
+// frame holds only the fields of each entity that have changed since
+// the previous frame. The first frame of a new stream has Resync set,
+// and holds every entity in the environment.
+func (c *Client) WatchStream(args WatchStreamParams) (*WatchStream, error) {
+	attrs := url.Values{}
+	if args.Gzip {
+		attrs.Set("gzip", fmt.Sprint(args.Gzip))
+	}
+	if args.ResumeToken != "" {
+		attrs.Set("resume", args.ResumeToken)
+	}
+	connection, err := c.st.ConnectStream("/watch", attrs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var r io.Reader = connection
+	if args.Gzip {
+		zr, err := gzip.NewReader(connection)
+		if err != nil {
+			connection.Close()
+			return nil, errors.Annotate(err, "cannot read compressed stream")
+		}
+		r = zr
+	}
+	return &WatchStream{
+		conn:    connection,
+		decoder: json.NewDecoder(r),
+	}, nil
+}
+
+// WatchStream reads frames from a stream returned by Client.WatchStream.
+type WatchStream struct {
+	conn    io.Closer
+	decoder *json.Decoder
+}
+
+// Next blocks until the next frame is received, and returns it.
+func (w *WatchStream) Next() (*params.WatchStreamFrame, error) {
+	var frame params.WatchStreamFrame
+	if err := w.decoder.Decode(&frame); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &frame, nil
+}
+
+// Close closes the stream.
+func (w *WatchStream) Close() error {
+	return w.conn.Close()
+}
@@ -381,6 +381,59 @@ func (s *clientSuite) TestUnshareEnvironmentMissingUser(c *gc.C) {
 	c.Assert(c.GetTestLog(), jc.Contains, logMsg)
 }
 
+func (s *clientSuite) TestGrantEnvironment(c *gc.C) {
+	client := s.APIState.Client()
+	user := names.NewUserTag("bob@local")
+	var called bool
+	cleanup := api.PatchClientFacadeCall(client,
+		func(request string, paramsIn interface{}, response interface{}) error {
+			called = true
+			c.Assert(request, gc.Equals, "ShareEnvironment")
+			c.Assert(paramsIn, jc.DeepEquals, params.ModifyEnvironUsers{
+				Changes: []params.ModifyEnvironUser{{
+					UserTag: user.String(),
+					Action:  params.GrantEnvUser,
+					Access:  "write",
+				}},
+			})
+			*(response.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{}},
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	err := client.GrantEnvironment(user, "write")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *clientSuite) TestRevokeEnvironment(c *gc.C) {
+	client := s.APIState.Client()
+	user := names.NewUserTag("bob@local")
+	cleanup := api.PatchClientFacadeCall(client,
+		func(request string, paramsIn interface{}, response interface{}) error {
+			c.Assert(request, gc.Equals, "ShareEnvironment")
+			c.Assert(paramsIn, jc.DeepEquals, params.ModifyEnvironUsers{
+				Changes: []params.ModifyEnvironUser{{
+					UserTag: user.String(),
+					Action:  params.RevokeEnvUser,
+					Access:  "admin",
+				}},
+			})
+			*(response.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	err := client.RevokeEnvironment(user, "admin")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
 func (s *clientSuite) TestWatchDebugLogConnected(c *gc.C) {
 	// Shows both the unmarshalling of a real error, and
 	// that the api server is connected.
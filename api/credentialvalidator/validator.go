@@ -0,0 +1,37 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator
+
+import (
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/common"
+	"github.com/juju/juju/apiserver/params"
+)
+
+const credentialValidatorFacade = "CredentialValidator"
+
+// API provides access to the CredentialValidator API facade.
+type API struct {
+	*common.EnvironWatcher
+	facade base.FacadeCaller
+}
+
+// NewAPI creates a new client-side CredentialValidator facade.
+func NewAPI(caller base.APICaller) *API {
+	facadeCaller := base.NewFacadeCaller(caller, credentialValidatorFacade)
+	return &API{
+		EnvironWatcher: common.NewEnvironWatcher(facadeCaller),
+		facade:         facadeCaller,
+	}
+}
+
+// SetCredentialStatus records whether the environment's cloud
+// credentials were accepted by the cloud, and if not, why not.
+func (api *API) SetCredentialStatus(valid bool, reason string) error {
+	args := params.CredentialStatus{
+		Valid:  valid,
+		Reason: reason,
+	}
+	return api.facade.FacadeCall("SetCredentialStatus", args, nil)
+}
@@ -0,0 +1,48 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/credentialvalidator"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type CredentialValidatorSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&CredentialValidatorSuite{})
+
+func (s *CredentialValidatorSuite) TestSetCredentialStatus(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "CredentialValidator")
+		c.Check(id, gc.Equals, "")
+		c.Check(request, gc.Equals, "SetCredentialStatus")
+		c.Check(arg, jc.DeepEquals, params.CredentialStatus{
+			Valid:  false,
+			Reason: "expired",
+		})
+		called = true
+		return nil
+	})
+	err := credentialvalidator.NewAPI(apiCaller).SetCredentialStatus(false, "expired")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *CredentialValidatorSuite) TestSetCredentialStatusError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		return errors.New("boom")
+	})
+	err := credentialvalidator.NewAPI(apiCaller).SetCredentialStatus(true, "")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -17,12 +17,16 @@ var facadeVersions = map[string]int{
 	"AllWatcher":                   0,
 	"AllEnvWatcher":                1,
 	"Annotations":                  1,
+	"AuditLog":                     1,
+	"AuthToken":                    1,
 	"Backups":                      0,
 	"Block":                        1,
 	"Charms":                       1,
+	"CharmResources":               1,
 	"CharmRevisionUpdater":         0,
 	"Client":                       0,
 	"Cleaner":                      1,
+	"CredentialValidator":          1,
 	"Deployer":                     0,
 	"DiskManager":                  1,
 	"EntityWatcher":                1,
@@ -31,6 +35,8 @@ var facadeVersions = map[string]int{
 	"FilesystemAttachmentsWatcher": 1,
 	"Firewaller":                   1,
 	"HighAvailability":             1,
+	"HistoryPruner":                1,
+	"HostKeyReporter":              1,
 	"ImageManager":                 1,
 	"ImageMetadata":                1,
 	"InstancePoller":               1,
@@ -49,9 +55,11 @@ var facadeVersions = map[string]int{
 	"Provisioner":                  1,
 	"Reboot":                       1,
 	"RelationUnitsWatcher":         0,
+	"RemoteRelations":              1,
 	"Resumer":                      1,
 	"Rsyslog":                      0,
-	"Service":                      1,
+	"Service":                      2,
+	"ServiceOffers":                1,
 	"Storage":                      1,
 	"Spaces":                       1,
 	"Subnets":                      1,
@@ -60,7 +68,7 @@ var facadeVersions = map[string]int{
 	"StringsWatcher":               0,
 	"SystemManager":                1,
 	"Upgrader":                     0,
-	"Uniter":                       2,
+	"Uniter":                       3,
 	"UserManager":                  0,
 	"VolumeAttachmentsWatcher":     1,
 }
@@ -0,0 +1,26 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package historypruner
+
+import (
+	"github.com/juju/juju/api/base"
+)
+
+const historyPrunerFacade = "HistoryPruner"
+
+// API provides access to the HistoryPruner API facade.
+type API struct {
+	facade base.FacadeCaller
+}
+
+// NewAPI creates a new client-side HistoryPruner facade.
+func NewAPI(caller base.APICaller) *API {
+	facadeCaller := base.NewFacadeCaller(caller, historyPrunerFacade)
+	return &API{facade: facadeCaller}
+}
+
+// Prune calls the server-side Prune method.
+func (api *API) Prune() error {
+	return api.facade.FacadeCall("Prune", nil, nil)
+}
@@ -0,0 +1,44 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package historypruner_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/historypruner"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type HistoryPrunerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&HistoryPrunerSuite{})
+
+func (s *HistoryPrunerSuite) TestPrune(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "HistoryPruner")
+		c.Check(id, gc.Equals, "")
+		c.Check(request, gc.Equals, "Prune")
+		c.Check(arg, gc.IsNil)
+		called = true
+		return nil
+	})
+	err := historypruner.NewAPI(apiCaller).Prune()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *HistoryPrunerSuite) TestPruneError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		return errors.New("boom")
+	})
+	err := historypruner.NewAPI(apiCaller).Prune()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -0,0 +1,41 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostkeyreporter
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+const hostKeyReporterFacade = "HostKeyReporter"
+
+// API provides access to the HostKeyReporter API facade.
+type API struct {
+	facade base.FacadeCaller
+}
+
+// NewAPI creates a new client-side HostKeyReporter facade.
+func NewAPI(caller base.APICaller) *API {
+	facadeCaller := base.NewFacadeCaller(caller, hostKeyReporterFacade)
+	return &API{facade: facadeCaller}
+}
+
+// ReportKeys records the SSH host keys of the given machine, in the
+// authorized_keys format.
+func (api *API) ReportKeys(tag names.MachineTag, publicKeys []string) error {
+	args := params.SSHHostKeySet{
+		EntityKeys: []params.SSHHostKeysArg{{
+			Tag:        tag.String(),
+			PublicKeys: publicKeys,
+		}},
+	}
+	var result params.ErrorResults
+	err := api.facade.FacadeCall("ReportKeys", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostkeyreporter_test
+
+import (
+	"errors"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/hostkeyreporter"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type HostKeyReporterSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&HostKeyReporterSuite{})
+
+func (s *HostKeyReporterSuite) TestReportKeys(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "HostKeyReporter")
+		c.Check(id, gc.Equals, "")
+		c.Check(request, gc.Equals, "ReportKeys")
+		c.Check(arg, jc.DeepEquals, params.SSHHostKeySet{
+			EntityKeys: []params.SSHHostKeysArg{{
+				Tag:        "machine-1",
+				PublicKeys: []string{"ssh-rsa rsa"},
+			}},
+		})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{}},
+		}
+		called = true
+		return nil
+	})
+	err := hostkeyreporter.NewAPI(apiCaller).ReportKeys(names.NewMachineTag("1"), []string{"ssh-rsa rsa"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *HostKeyReporterSuite) TestReportKeysResultError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(_ string, _ int, _, _ string, _, result interface{}) error {
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+		}
+		return nil
+	})
+	err := hostkeyreporter.NewAPI(apiCaller).ReportKeys(names.NewMachineTag("1"), []string{"ssh-rsa rsa"})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *HostKeyReporterSuite) TestReportKeysError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		return errors.New("boom")
+	})
+	err := hostkeyreporter.NewAPI(apiCaller).ReportKeys(names.NewMachineTag("1"), []string{"ssh-rsa rsa"})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
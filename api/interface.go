@@ -72,6 +72,11 @@ type Info struct {
 	// Nonce holds the nonce used when provisioning the machine. Used
 	// only by the machine agent.
 	Nonce string `yaml:",omitempty"`
+
+	// Token holds a bearer token, as issued by the AuthToken facade,
+	// to log in with instead of a tag and password. The connection
+	// may only be used to call the API methods the token permits.
+	Token string `yaml:",omitempty"`
 }
 
 // DialOpts holds configuration parameters that control the
@@ -128,6 +128,13 @@ func (st *State) MachinesWithTransientErrors() ([]*Machine, []params.StatusResul
 	return machines, results.Results, nil
 }
 
+// CredentialStatus returns the last known validity of the
+// environment's cloud credentials.
+func (st *State) CredentialStatus() (result params.CredentialStatus, err error) {
+	err = st.facade.FacadeCall("CredentialStatus", nil, &result)
+	return result, err
+}
+
 // FindTools returns al ist of tools matching the specified version number and
 // series, and, arch. If arch is blank, a default will be used.
 func (st *State) FindTools(v version.Number, series string, arch string) (tools.List, error) {
@@ -761,6 +761,16 @@ func (s *provisionerSuite) TestContainerConfig(c *gc.C) {
 	c.Assert(result.PreferIPv6, jc.IsTrue)
 }
 
+func (s *provisionerSuite) TestCredentialStatus(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetCredentialValid(false, "expired")
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.provisioner.CredentialStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, params.CredentialStatus{Valid: false, Reason: "expired"})
+}
+
 func (s *provisionerSuite) TestSetSupportedContainers(c *gc.C) {
 	apiMachine, err := s.provisioner.Machine(s.machine.Tag().(names.MachineTag))
 	c.Assert(err, jc.ErrorIsNil)
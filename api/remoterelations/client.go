@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client passes relation settings across the offer connections this
+// environment is on either side of.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new remote relations client.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "RemoteRelations")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Connections returns the offer connections the logged in user may
+// pass settings through.
+func (c *Client) Connections() ([]params.OfferConnection, error) {
+	var result params.OfferConnections
+	if err := c.facade.FacadeCall("Connections", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Connections, nil
+}
+
+// PublishSettings publishes the relation settings of a unit in this
+// environment across the given offer connection.
+func (c *Client) PublishSettings(connId, unit string, settings params.Settings) error {
+	args := params.RemoteUnitSettingsArgs{
+		Args: []params.RemoteUnitSettings{{
+			ConnectionId: connId,
+			Unit:         unit,
+			Settings:     settings,
+		}},
+	}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall("PublishSettings", args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// RemoveSettings removes the relation settings published by a unit in
+// this environment across the given offer connection.
+func (c *Client) RemoveSettings(connId, unit string) error {
+	args := params.RemoteUnits{
+		Args: []params.RemoteUnit{{
+			ConnectionId: connId,
+			Unit:         unit,
+		}},
+	}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall("RemoveSettings", args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// Settings returns the relation settings published by the units on the
+// other side of the given offer connection, keyed by unit name.
+func (c *Client) Settings(connId string) (map[string]params.Settings, error) {
+	args := params.OfferConnectionIds{Ids: []string{connId}}
+	var results params.RemoteSettingsResults
+	if err := c.facade.FacadeCall("Settings", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, err
+	}
+	return results.Results[0].Units, nil
+}
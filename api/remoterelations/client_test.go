@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/remoterelations"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type remoteRelationsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&remoteRelationsSuite{})
+
+func (s *remoteRelationsSuite) TestConnections(c *gc.C) {
+	conns := []params.OfferConnection{{Id: "uuid:remotedb", ServiceName: "remotedb"}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "RemoteRelations")
+			c.Check(request, gc.Equals, "Connections")
+			c.Check(arg, gc.IsNil)
+			*(result.(*params.OfferConnections)) = params.OfferConnections{Connections: conns}
+			return nil
+		})
+	client := remoterelations.NewClient(apiCaller)
+	got, err := client.Connections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, conns)
+}
+
+func (s *remoteRelationsSuite) TestPublishSettings(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "RemoteRelations")
+			c.Check(request, gc.Equals, "PublishSettings")
+			c.Check(arg, jc.DeepEquals, params.RemoteUnitSettingsArgs{
+				Args: []params.RemoteUnitSettings{{
+					ConnectionId: "uuid:remotedb",
+					Unit:         "wordpress/0",
+					Settings:     params.Settings{"user": "wp"},
+				}},
+			})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{}},
+			}
+			return nil
+		})
+	client := remoterelations.NewClient(apiCaller)
+	err := client.PublishSettings("uuid:remotedb", "wordpress/0", params.Settings{"user": "wp"})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *remoteRelationsSuite) TestRemoveSettings(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "RemoteRelations")
+			c.Check(request, gc.Equals, "RemoveSettings")
+			c.Check(arg, jc.DeepEquals, params.RemoteUnits{
+				Args: []params.RemoteUnit{{ConnectionId: "uuid:remotedb", Unit: "wordpress/0"}},
+			})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		})
+	client := remoterelations.NewClient(apiCaller)
+	err := client.RemoveSettings("uuid:remotedb", "wordpress/0")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *remoteRelationsSuite) TestSettings(c *gc.C) {
+	units := map[string]params.Settings{"mysql/0": {"host": "10.0.0.1"}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "RemoteRelations")
+			c.Check(request, gc.Equals, "Settings")
+			c.Check(arg, jc.DeepEquals, params.OfferConnectionIds{Ids: []string{"uuid:remotedb"}})
+			*(result.(*params.RemoteSettingsResults)) = params.RemoteSettingsResults{
+				Results: []params.RemoteSettingsResult{{Units: units}},
+			}
+			return nil
+		})
+	client := remoterelations.NewClient(apiCaller)
+	got, err := client.Settings("uuid:remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, units)
+}
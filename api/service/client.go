@@ -58,48 +58,60 @@ func (c *Client) EnvironmentUUID() string {
 	return tag.Id()
 }
 
+// DeployArgs holds the arguments for the ServiceDeploy call.
+type DeployArgs struct {
+	CharmURL      string
+	ServiceName   string
+	NumUnits      int
+	ConfigYAML    string
+	Constraints   constraints.Value
+	ToMachineSpec string
+	Placement     []*instance.Placement
+	Networks      []string
+	Storage       map[string]storage.Constraints
+
+	// EndpointBindings maps the names of the charm's relation
+	// endpoints to the spaces they should be bound to. Bindings
+	// require version 2 of the Service facade.
+	EndpointBindings map[string]string
+}
+
 // ServiceDeploy obtains the charm, either locally or from
 // the charm store, and deploys it. It allows the specification of
 // requested networks that must be present on the machines where the
 // service is deployed. Another way to specify networks to include/exclude
 // is using constraints. Placement directives, if provided, specify the
 // machine on which the charm is deployed.
-func (c *Client) ServiceDeploy(
-	charmURL string,
-	serviceName string,
-	numUnits int,
-	configYAML string,
-	cons constraints.Value,
-	toMachineSpec string,
-	placement []*instance.Placement,
-	networks []string,
-	storage map[string]storage.Constraints,
-) error {
-	args := params.ServicesDeploy{
+func (c *Client) ServiceDeploy(args DeployArgs) error {
+	if len(args.EndpointBindings) > 0 && c.BestAPIVersion() < 2 {
+		return errors.NotSupportedf("endpoint bindings (need Service facade V2+)")
+	}
+	deployArgs := params.ServicesDeploy{
 		Services: []params.ServiceDeploy{{
-			ServiceName:   serviceName,
-			CharmUrl:      charmURL,
-			NumUnits:      numUnits,
-			ConfigYAML:    configYAML,
-			Constraints:   cons,
-			ToMachineSpec: toMachineSpec,
-			Placement:     placement,
-			Networks:      networks,
-			Storage:       storage,
+			ServiceName:      args.ServiceName,
+			CharmUrl:         args.CharmURL,
+			NumUnits:         args.NumUnits,
+			ConfigYAML:       args.ConfigYAML,
+			Constraints:      args.Constraints,
+			ToMachineSpec:    args.ToMachineSpec,
+			Placement:        args.Placement,
+			Networks:         args.Networks,
+			Storage:          args.Storage,
+			EndpointBindings: args.EndpointBindings,
 		}},
 	}
 	var results params.ErrorResults
 	var err error
-	if len(placement) > 0 {
-		err = c.facade.FacadeCall("ServicesDeployWithPlacement", args, &results)
+	if len(args.Placement) > 0 {
+		err = c.facade.FacadeCall("ServicesDeployWithPlacement", deployArgs, &results)
 		if err != nil {
 			if params.IsCodeNotImplemented(err) {
-				return errors.Errorf("unsupported --to parameter %q", toMachineSpec)
+				return errors.Errorf("unsupported --to parameter %q", args.ToMachineSpec)
 			}
 			return err
 		}
 	} else {
-		err = c.facade.FacadeCall("ServicesDeploy", args, &results)
+		err = c.facade.FacadeCall("ServicesDeploy", deployArgs, &results)
 	}
 	if err != nil {
 		return err
@@ -4,9 +4,11 @@
 package service_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	basetesting "github.com/juju/juju/api/base/testing"
 	"github.com/juju/juju/api/service"
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
@@ -89,13 +91,38 @@ func (s *serviceSuite) TestSetServiceDeploy(c *gc.C) {
 		c.Assert(args.Services[0].ToMachineSpec, gc.Equals, "machineSpec")
 		c.Assert(args.Services[0].Networks, gc.DeepEquals, []string{"neta"})
 		c.Assert(args.Services[0].Storage, gc.DeepEquals, map[string]storage.Constraints{"data": storage.Constraints{Pool: "pool"}})
+		c.Assert(args.Services[0].EndpointBindings, gc.DeepEquals, map[string]string{"db": "internal"})
 
 		result := response.(*params.ErrorResults)
 		result.Results = make([]params.ErrorResult, 1)
 		return nil
 	})
-	err := s.client.ServiceDeploy("charmURL", "serviceA", 2, "configYAML", constraints.MustParse("mem=4G"),
-		"machineSpec", nil, []string{"neta"}, map[string]storage.Constraints{"data": storage.Constraints{Pool: "pool"}})
+	err := s.client.ServiceDeploy(service.DeployArgs{
+		CharmURL:         "charmURL",
+		ServiceName:      "serviceA",
+		NumUnits:         2,
+		ConfigYAML:       "configYAML",
+		Constraints:      constraints.MustParse("mem=4G"),
+		ToMachineSpec:    "machineSpec",
+		Networks:         []string{"neta"},
+		Storage:          map[string]storage.Constraints{"data": storage.Constraints{Pool: "pool"}},
+		EndpointBindings: map[string]string{"db": "internal"},
+	})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(called, jc.IsTrue)
 }
+
+func (s *serviceSuite) TestServiceDeployBindingsNeedV2(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	client := service.NewClientWithCaller(apiCaller)
+	err := client.ServiceDeploy(service.DeployArgs{
+		CharmURL:         "charmURL",
+		ServiceName:      "serviceA",
+		EndpointBindings: map[string]string{"db": "internal"},
+	})
+	c.Assert(err, gc.ErrorMatches, `endpoint bindings \(need Service facade V2\+\) not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
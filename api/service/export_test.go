@@ -4,6 +4,7 @@
 package service
 
 import (
+	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/base/testing"
 )
 
@@ -13,3 +14,10 @@ import (
 func PatchFacadeCall(p testing.Patcher, client *Client, f func(request string, params, response interface{}) error) {
 	testing.PatchFacadeCall(p, &client.facade, f)
 }
+
+// NewClientWithCaller returns a Client that makes its calls through
+// the given caller.
+func NewClientWithCaller(caller base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(caller, "Service")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
@@ -0,0 +1,120 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package serviceoffers
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client offers services' endpoints to other environments, and
+// consumes offers made from them.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new service offers client.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "ServiceOffers")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Offer offers the named endpoints of a service to other environments,
+// letting the given users consume them.
+func (c *Client) Offer(offerName, serviceName string, endpoints []string, description string, users ...names.UserTag) (params.ServiceOffer, error) {
+	offer := params.AddServiceOffer{
+		OfferName:   offerName,
+		ServiceName: serviceName,
+		Endpoints:   endpoints,
+		Description: description,
+	}
+	for _, user := range users {
+		offer.UserTags = append(offer.UserTags, user.String())
+	}
+	args := params.AddServiceOffers{Offers: []params.AddServiceOffer{offer}}
+	var results params.ServiceOfferResults
+	if err := c.facade.FacadeCall("Offer", args, &results); err != nil {
+		return params.ServiceOffer{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.ServiceOffer{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return params.ServiceOffer{}, err
+	}
+	return *results.Results[0].Result, nil
+}
+
+// ListOffers returns the offers that match the filter and that the
+// logged in user may consume.
+func (c *Client) ListOffers(filter params.ServiceOfferFilter) ([]params.ServiceOffer, error) {
+	var result params.ServiceOffers
+	if err := c.facade.FacadeCall("ListOffers", filter, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Offers, nil
+}
+
+// RemoveOffer removes the offer with the given URL.
+func (c *Client) RemoveOffer(url string) error {
+	args := params.ServiceOfferURLs{URLs: []string{url}}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall("RemoveOffers", args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// GrantAccess lets the given user consume the offer with the given URL.
+func (c *Client) GrantAccess(url string, user names.UserTag) error {
+	return c.modifyAccess(url, user, params.GrantOfferAccess)
+}
+
+// RevokeAccess stops the given user consuming the offer with the given
+// URL.
+func (c *Client) RevokeAccess(url string, user names.UserTag) error {
+	return c.modifyAccess(url, user, params.RevokeOfferAccess)
+}
+
+func (c *Client) modifyAccess(url string, user names.UserTag, action params.OfferAction) error {
+	args := params.ModifyOfferAccessRequest{
+		Changes: []params.ModifyOfferAccess{{
+			URL:     url,
+			UserTag: user.String(),
+			Action:  action,
+		}},
+	}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall("ModifyOfferAccess", args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// Consume consumes the offer with the given URL, adding a remote
+// service with the given name to the environment. If the name is
+// empty, the offered service's name is used.
+func (c *Client) Consume(url, serviceAlias string) (params.RemoteService, error) {
+	args := params.ConsumeServiceArgs{
+		Args: []params.ConsumeServiceArg{{
+			OfferURL:     url,
+			ServiceAlias: serviceAlias,
+		}},
+	}
+	var results params.ConsumeServiceResults
+	if err := c.facade.FacadeCall("Consume", args, &results); err != nil {
+		return params.RemoteService{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.RemoteService{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return params.RemoteService{}, err
+	}
+	return *results.Results[0].Result, nil
+}
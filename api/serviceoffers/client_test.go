@@ -0,0 +1,143 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package serviceoffers_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/serviceoffers"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type serviceOffersSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&serviceOffersSuite{})
+
+const offerURL = "local:/u/admin/env/db"
+
+func (s *serviceOffersSuite) TestOffer(c *gc.C) {
+	offer := params.ServiceOffer{URL: offerURL, ServiceName: "mysql"}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "ServiceOffers")
+			c.Check(request, gc.Equals, "Offer")
+			c.Check(arg, jc.DeepEquals, params.AddServiceOffers{
+				Offers: []params.AddServiceOffer{{
+					OfferName:   "db",
+					ServiceName: "mysql",
+					Endpoints:   []string{"server"},
+					Description: "a database",
+					UserTags:    []string{"user-bob"},
+				}},
+			})
+			*(result.(*params.ServiceOfferResults)) = params.ServiceOfferResults{
+				Results: []params.ServiceOfferResult{{Result: &offer}},
+			}
+			return nil
+		})
+	client := serviceoffers.NewClient(apiCaller)
+	got, err := client.Offer("db", "mysql", []string{"server"}, "a database", names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, offer)
+}
+
+func (s *serviceOffersSuite) TestOfferError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			*(result.(*params.ServiceOfferResults)) = params.ServiceOfferResults{
+				Results: []params.ServiceOfferResult{{Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		})
+	client := serviceoffers.NewClient(apiCaller)
+	_, err := client.Offer("db", "mysql", []string{"server"}, "")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *serviceOffersSuite) TestListOffers(c *gc.C) {
+	offers := []params.ServiceOffer{{URL: offerURL}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "ServiceOffers")
+			c.Check(request, gc.Equals, "ListOffers")
+			c.Check(arg, jc.DeepEquals, params.ServiceOfferFilter{ServiceName: "mysql"})
+			*(result.(*params.ServiceOffers)) = params.ServiceOffers{Offers: offers}
+			return nil
+		})
+	client := serviceoffers.NewClient(apiCaller)
+	got, err := client.ListOffers(params.ServiceOfferFilter{ServiceName: "mysql"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, offers)
+}
+
+func (s *serviceOffersSuite) TestRemoveOffer(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "ServiceOffers")
+			c.Check(request, gc.Equals, "RemoveOffers")
+			c.Check(arg, jc.DeepEquals, params.ServiceOfferURLs{URLs: []string{offerURL}})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		})
+	client := serviceoffers.NewClient(apiCaller)
+	err := client.RemoveOffer(offerURL)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *serviceOffersSuite) TestGrantAndRevokeAccess(c *gc.C) {
+	var actions []params.OfferAction
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "ServiceOffers")
+			c.Check(request, gc.Equals, "ModifyOfferAccess")
+			changes := arg.(params.ModifyOfferAccessRequest).Changes
+			c.Check(changes, gc.HasLen, 1)
+			c.Check(changes[0].URL, gc.Equals, offerURL)
+			c.Check(changes[0].UserTag, gc.Equals, "user-bob")
+			actions = append(actions, changes[0].Action)
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{}},
+			}
+			return nil
+		})
+	client := serviceoffers.NewClient(apiCaller)
+	err := client.GrantAccess(offerURL, names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = client.RevokeAccess(offerURL, names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actions, jc.DeepEquals, []params.OfferAction{
+		params.GrantOfferAccess, params.RevokeOfferAccess,
+	})
+}
+
+func (s *serviceOffersSuite) TestConsume(c *gc.C) {
+	remote := params.RemoteService{Name: "remotedb", OfferURL: offerURL}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "ServiceOffers")
+			c.Check(request, gc.Equals, "Consume")
+			c.Check(arg, jc.DeepEquals, params.ConsumeServiceArgs{
+				Args: []params.ConsumeServiceArg{{
+					OfferURL:     offerURL,
+					ServiceAlias: "remotedb",
+				}},
+			})
+			*(result.(*params.ConsumeServiceResults)) = params.ConsumeServiceResults{
+				Results: []params.ConsumeServiceResult{{Result: &remote}},
+			}
+			return nil
+		})
+	client := serviceoffers.NewClient(apiCaller)
+	got, err := client.Consume(offerURL, "remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, remote)
+}
@@ -125,6 +125,35 @@ func (st *state) loginV2(tag names.Tag, password, nonce string) error {
 	return nil
 }
 
+// loginWithToken authenticates using a bearer token issued by the
+// AuthToken facade. Subsequent requests on the state will act as the
+// user the token was issued for, but may only call the methods the
+// token permits.
+func (st *state) loginWithToken(token string) error {
+	var result params.LoginResultV1
+	request := &params.LoginRequest{
+		Token: token,
+	}
+	if err := st.APICall("Admin", 2, "", "Login", request, &result); err != nil {
+		return errors.Trace(err)
+	}
+	var tag names.Tag
+	if result.UserInfo != nil {
+		var err error
+		tag, err = names.ParseTag(result.UserInfo.Identity)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	servers := params.NetworkHostsPorts(result.Servers)
+	err := st.setLoginResult(tag, result.EnvironTag, result.ServerTag, servers, result.Facades)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	st.serverVersion, err = version.Parse(result.ServerVersion)
+	return errors.Trace(err)
+}
+
 func (st *state) loginV1(tag names.Tag, password, nonce string) error {
 	var result struct {
 		// TODO (cmars): remove once we can drop 1.18 login compatibility
@@ -114,3 +114,25 @@ func (c *Client) AddToUnit(storages []params.StorageAddParams) ([]params.ErrorRe
 	}
 	return out.Results, nil
 }
+
+// Attach attaches existing, detached storage instances to units.
+func (c *Client) Attach(ids []params.StorageAttachmentId) ([]params.ErrorResult, error) {
+	return c.modifyStorageAttachments("Attach", ids)
+}
+
+// Detach detaches storage instances from units, without destroying them.
+func (c *Client) Detach(ids []params.StorageAttachmentId) ([]params.ErrorResult, error) {
+	return c.modifyStorageAttachments("Detach", ids)
+}
+
+func (c *Client) modifyStorageAttachments(method string, ids []params.StorageAttachmentId) ([]params.ErrorResult, error) {
+	out := params.ErrorResults{}
+	in := params.StorageAttachmentIds{Ids: ids}
+	if err := c.facade.FacadeCall(method, in, &out); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(out.Results) != len(ids) {
+		return nil, errors.Errorf("expected %d result(s), got %d", len(ids), len(out.Results))
+	}
+	return out.Results, nil
+}
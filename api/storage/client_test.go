@@ -564,3 +564,40 @@ func (s *storageMockSuite) TestAddToUnitFacadeCallError(c *gc.C) {
 	c.Assert(errors.Cause(err), gc.ErrorMatches, msg)
 	c.Assert(found, gc.HasLen, 0)
 }
+
+func (s *storageMockSuite) TestAttachDetach(c *gc.C) {
+	ids := []params.StorageAttachmentId{{
+		StorageTag: "storage-data-0",
+		UnitTag:    "unit-mysql-1",
+	}}
+	expectedError := common.ServerError(errors.NotSupportedf("detaching filesystem storage"))
+
+	for _, method := range []string{"Attach", "Detach"} {
+		apiCaller := basetesting.APICallerFunc(
+			func(objType string,
+				version int,
+				id, request string,
+				a, result interface{},
+			) error {
+				c.Check(objType, gc.Equals, "Storage")
+				c.Check(id, gc.Equals, "")
+				c.Check(request, gc.Equals, method)
+				c.Check(a, jc.DeepEquals, params.StorageAttachmentIds{ids})
+				c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+				*(result.(*params.ErrorResults)) = params.ErrorResults{
+					[]params.ErrorResult{{expectedError}},
+				}
+				return nil
+			})
+		storageClient := storage.NewClient(apiCaller)
+		var results []params.ErrorResult
+		var err error
+		if method == "Attach" {
+			results, err = storageClient.Attach(ids)
+		} else {
+			results, err = storageClient.Detach(ids)
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(results, jc.DeepEquals, []params.ErrorResult{{expectedError}})
+	}
+}
@@ -88,6 +88,29 @@ func (c *Client) RemoveBlocks() error {
 	return c.facade.FacadeCall("RemoveBlocks", args, nil)
 }
 
+// UpdateCredentials replaces the cloud credentials of the specified
+// environment, and of every other environment in the system that
+// shares them. It returns the tags of the environments updated.
+func (c *Client) UpdateCredentials(envTag names.EnvironTag, credentials map[string]string) ([]names.EnvironTag, error) {
+	args := params.UpdateCredentials{
+		EnvironTag:  envTag.String(),
+		Credentials: credentials,
+	}
+	var result params.UpdateCredentialsResult
+	if err := c.facade.FacadeCall("UpdateCredentials", args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	tags := make([]names.EnvironTag, len(result.EnvironTags))
+	for i, tag := range result.EnvironTags {
+		envTag, err := names.ParseEnvironTag(tag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		tags[i] = envTag
+	}
+	return tags, nil
+}
+
 // WatchAllEnv returns an AllEnvWatcher, from which you can request
 // the Next collection of Deltas (for all environments).
 func (c *Client) WatchAllEnvs() (*api.AllWatcher, error) {
@@ -116,6 +116,23 @@ func (s *systemManagerSuite) TestRemoveBlocks(c *gc.C) {
 	c.Assert(blocks, gc.HasLen, 0)
 }
 
+func (s *systemManagerSuite) TestUpdateCredentials(c *gc.C) {
+	sysManager := s.OpenAPI(c)
+	tags, err := sysManager.UpdateCredentials(s.State.EnvironTag(), map[string]string{"secret": "fish"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tags, jc.DeepEquals, []names.EnvironTag{s.State.EnvironTag()})
+
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.AllAttrs()["secret"], gc.Equals, "fish")
+}
+
+func (s *systemManagerSuite) TestUpdateCredentialsInvalid(c *gc.C) {
+	sysManager := s.OpenAPI(c)
+	_, err := sysManager.UpdateCredentials(s.State.EnvironTag(), map[string]string{"secret": "invalid"})
+	c.Assert(err, gc.ErrorMatches, "new credentials not valid: dummy secret not valid")
+}
+
 func (s *systemManagerSuite) TestWatchAllEnvs(c *gc.C) {
 	// The WatchAllEnvs infrastructure is comprehensively tested
 	// else. This test just ensure that the API calls work end-to-end.
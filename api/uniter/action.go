@@ -3,10 +3,15 @@
 
 package uniter
 
+import (
+	"time"
+)
+
 // Action represents a single instance of an Action call, by name and params.
 type Action struct {
-	name   string
-	params map[string]interface{}
+	name    string
+	params  map[string]interface{}
+	timeout time.Duration
 }
 
 // NewAction makes a new Action with specified name and params map.
@@ -23,3 +28,9 @@ func (a *Action) Name() string {
 func (a *Action) Params() map[string]interface{} {
 	return a.params
 }
+
+// Timeout retrieves the time the Action may run for before it is
+// stopped; zero means no limit.
+func (a *Action) Timeout() time.Duration {
+	return a.timeout
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Resource holds the revision of one of the unit's service's resources
+// that the unit is to use.
+type Resource struct {
+	// Revision holds the revision of the resource.
+	Revision int
+
+	// Content holds the content of a file resource; it is nil for
+	// other types of resource. The caller must close it.
+	Content io.ReadCloser
+
+	// ImageRef holds the image reference of an OCI image resource.
+	ImageRef string
+}
+
+// OpenResource fetches the named resource of the unit's service from
+// the API server.
+func (st *State) OpenResource(name string) (*Resource, error) {
+	httpClient, err := st.facade.RawAPICaller().HTTPClient()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	path := fmt.Sprintf("/units/%s/resources/%s", st.unitTag, name)
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create resource request")
+	}
+	var resp *http.Response
+	if err := httpClient.Do(req, nil, &resp); err != nil {
+		return nil, errors.Annotatef(err, "cannot fetch resource %q", name)
+	}
+	revision, err := strconv.Atoi(resp.Header.Get(params.ResourceRevisionHeader))
+	if err != nil {
+		resp.Body.Close()
+		return nil, errors.Errorf("resource %q has invalid revision %q", name, resp.Header.Get(params.ResourceRevisionHeader))
+	}
+	if resp.Header.Get("Content-Type") == params.ContentTypeRaw {
+		return &Resource{Revision: revision, Content: resp.Body}, nil
+	}
+	defer resp.Body.Close()
+	var result params.ResourceResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Annotatef(err, "cannot read resource %q", name)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.Resource == nil {
+		return nil, errors.Errorf("resource %q missing from response", name)
+	}
+	return &Resource{Revision: revision, ImageRef: result.Resource.ImageRef}, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"io/ioutil"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/uniter"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+)
+
+type resourcesSuite struct {
+	uniterSuite
+}
+
+var _ = gc.Suite(&resourcesSuite{})
+
+func (s *resourcesSuite) TestOpenFileResource(c *gc.C) {
+	path := state.ResourceStoragePath("wordpress", "data")
+	stor := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession())
+	err := stor.Put(path, strings.NewReader("some data"), 9)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "wordpress",
+		Name:        "data",
+		Type:        state.ResourceTypeFile,
+		Size:        9,
+		SHA256:      "1307990e6ba5ca145eb35e99182a9bec46531bc54ddf656a602c780fa0240dee",
+		StoragePath: path,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	res, err := s.uniter.OpenResource("data")
+	c.Assert(err, jc.ErrorIsNil)
+	defer res.Content.Close()
+	c.Assert(res.Revision, gc.Equals, 1)
+	data, err := ioutil.ReadAll(res.Content)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "some data")
+}
+
+func (s *resourcesSuite) TestOpenOCIImageResource(c *gc.C) {
+	_, err := s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "wordpress",
+		Name:        "image",
+		Type:        state.ResourceTypeOCIImage,
+		ImageRef:    "docker.io/library/wordpress:4",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	res, err := s.uniter.OpenResource("image")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, jc.DeepEquals, &uniter.Resource{
+		Revision: 1,
+		ImageRef: "docker.io/library/wordpress:4",
+	})
+}
+
+func (s *resourcesSuite) TestOpenResourceNotFound(c *gc.C) {
+	_, err := s.uniter.OpenResource("data")
+	c.Assert(err, gc.ErrorMatches, `cannot fetch resource "data": .*resource "data" of service "wordpress" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
@@ -114,6 +114,148 @@ func (u *Unit) SetAgentStatus(status params.Status, info string, data map[string
 	return result.OneError()
 }
 
+// SetWorkloadVersion records the version of the workload software
+// that the unit's charm is running.
+func (u *Unit) SetWorkloadVersion(version string) error {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return errors.NotImplementedf("SetWorkloadVersion() (need V3+)")
+	}
+	var result params.ErrorResults
+	args := params.EntityWorkloadVersions{
+		Entities: []params.EntityWorkloadVersion{
+			{Tag: u.tag.String(), WorkloadVersion: version},
+		},
+	}
+	err := u.st.facade.FacadeCall("SetWorkloadVersion", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// WorkloadVersion returns the version of the workload software that
+// the unit's charm last reported.
+func (u *Unit) WorkloadVersion() (string, error) {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return "", errors.NotImplementedf("WorkloadVersion() (need V3+)")
+	}
+	var results params.StringResults
+	args := params.Entities{
+		Entities: []params.Entity{
+			{Tag: u.tag.String()},
+		},
+	}
+	err := u.st.facade.FacadeCall("WorkloadVersion", args, &results)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return "", errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result, nil
+}
+
+// CharmState returns the key/value state stored by the unit's charm.
+func (u *Unit) CharmState() (map[string]string, error) {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return nil, errors.NotImplementedf("CharmState() (need V3+)")
+	}
+	var results params.CharmStateResults
+	args := params.Entities{
+		Entities: []params.Entity{
+			{Tag: u.tag.String()},
+		},
+	}
+	err := u.st.facade.FacadeCall("CharmState", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.State, nil
+}
+
+// SetCharmState updates the key/value state stored by the unit's
+// charm: keys in set are given their values, and keys in unset are
+// removed.
+func (u *Unit) SetCharmState(set map[string]string, unset []string) error {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return errors.NotImplementedf("SetCharmState() (need V3+)")
+	}
+	var result params.ErrorResults
+	args := params.SetUnitCharmStateArgs{
+		Args: []params.SetUnitCharmState{
+			{Tag: u.tag.String(), Set: set, Unset: unset},
+		},
+	}
+	err := u.st.facade.FacadeCall("SetCharmState", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// NetworkConfig returns the network configuration of the unit for the
+// given binding name: the addresses of the unit's machine in the space
+// to which the charm endpoint is bound.
+func (u *Unit) NetworkConfig(bindingName string) ([]params.NetworkConfig, error) {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return nil, errors.NotImplementedf("NetworkConfig() (need V3+)")
+	}
+	var results params.UnitNetworkConfigResults
+	args := params.UnitsNetworkConfig{
+		Args: []params.UnitNetworkConfig{
+			{UnitTag: u.tag.String(), BindingName: bindingName},
+		},
+	}
+	err := u.st.facade.FacadeCall("NetworkConfig", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Config, nil
+}
+
+// HookRetryStrategy returns how the unit should retry failed hooks.
+func (u *Unit) HookRetryStrategy() (params.HookRetryStrategy, error) {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return params.HookRetryStrategy{}, errors.NotImplementedf("HookRetryStrategy() (need V3+)")
+	}
+	var results params.HookRetryStrategyResults
+	args := params.Entities{
+		Entities: []params.Entity{
+			{Tag: u.tag.String()},
+		},
+	}
+	err := u.st.facade.FacadeCall("HookRetryStrategy", args, &results)
+	if err != nil {
+		return params.HookRetryStrategy{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.HookRetryStrategy{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.HookRetryStrategy{}, result.Error
+	}
+	return *result.Result, nil
+}
+
 // AddMetrics adds the metrics for the unit.
 func (u *Unit) AddMetrics(metrics []params.Metric) error {
 	var result params.ErrorResults
@@ -129,6 +129,101 @@ func (s *unitSuite) TestSetUnitStatusOldServer(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "SetUnitStatus not implemented")
 }
 
+func (s *unitSuite) TestSetWorkloadVersion(c *gc.C) {
+	err := s.apiUnit.SetWorkloadVersion("4.2.1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.wordpressUnit.WorkloadVersion(), gc.Equals, "4.2.1")
+
+	version, err := s.apiUnit.WorkloadVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "4.2.1")
+}
+
+func (s *unitSuite) TestSetWorkloadVersionOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV2)
+
+	err := s.apiUnit.SetWorkloadVersion("4.2.1")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	_, err = s.apiUnit.WorkloadVersion()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
+func (s *unitSuite) TestCharmState(c *gc.C) {
+	charmState, err := s.apiUnit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, gc.HasLen, 0)
+
+	err = s.apiUnit.SetCharmState(map[string]string{"foo": "bar", "baz": "qux"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.apiUnit.SetCharmState(nil, []string{"baz"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	charmState, err = s.apiUnit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, jc.DeepEquals, map[string]string{"foo": "bar"})
+	charmState, err = s.wordpressUnit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, jc.DeepEquals, map[string]string{"foo": "bar"})
+}
+
+func (s *unitSuite) TestCharmStateOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV2)
+
+	_, err := s.apiUnit.CharmState()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	err = s.apiUnit.SetCharmState(map[string]string{"foo": "bar"}, nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
+func (s *unitSuite) TestNetworkConfig(c *gc.C) {
+	err := s.wordpressMachine.SetProviderAddresses(
+		network.NewScopedAddress("10.0.0.5", network.ScopeCloudLocal),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	config, err := s.apiUnit.NetworkConfig("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config, jc.DeepEquals, []params.NetworkConfig{{Address: "10.0.0.5"}})
+
+	_, err = s.apiUnit.NetworkConfig("foo")
+	c.Assert(err, gc.ErrorMatches, `binding "foo" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *unitSuite) TestNetworkConfigOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV2)
+
+	_, err := s.apiUnit.NetworkConfig("db")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
+func (s *unitSuite) TestHookRetryStrategy(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"automatically-retry-hooks": true,
+		"hook-retry-min-delay":      10,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	strategy, err := s.apiUnit.HookRetryStrategy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(strategy, jc.DeepEquals, params.HookRetryStrategy{
+		ShouldRetry:   true,
+		MinDelay:      10 * time.Second,
+		MaxDelay:      5 * time.Minute,
+		BackoffFactor: 2,
+	})
+}
+
+func (s *unitSuite) TestHookRetryStrategyOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV2)
+
+	_, err := s.apiUnit.HookRetryStrategy()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
 func (s *unitSuite) TestSetAgentStatusOldServer(c *gc.C) {
 	s.patchNewState(c, uniter.NewStateV1)
 
@@ -80,9 +80,12 @@ var newStateV1 = newStateForVersionFn(1)
 // newStateV2 creates a new client-side Uniter facade, version 2.
 var newStateV2 = newStateForVersionFn(2)
 
+// newStateV3 creates a new client-side Uniter facade, version 3.
+var newStateV3 = newStateForVersionFn(3)
+
 // NewState creates a new client-side Uniter facade.
 // Defined like this to allow patching during tests.
-var NewState = newStateV2
+var NewState = newStateV3
 
 // BestAPIVersion returns the API version that we were able to
 // determine is supported by both the client and the API Server.
@@ -221,11 +224,38 @@ func (st *State) Action(tag names.ActionTag) (*Action, error) {
 		return nil, err
 	}
 	return &Action{
-		name:   result.Action.Action.Name,
-		params: result.Action.Action.Parameters,
+		name:    result.Action.Action.Name,
+		params:  result.Action.Action.Parameters,
+		timeout: result.Action.Action.Timeout,
 	}, nil
 }
 
+// ActionStatus returns the current status of an action, so that a unit
+// running it can find out whether it has been cancelled.
+func (st *State) ActionStatus(tag names.ActionTag) (string, error) {
+	if st.BestAPIVersion() < 3 {
+		return "", errors.NotImplementedf("ActionStatus() (need V3+)")
+	}
+	var results params.StringResults
+	args := params.Entities{
+		Entities: []params.Entity{
+			{Tag: tag.String()},
+		},
+	}
+	err := st.facade.FacadeCall("ActionStatus", args, &results)
+	if err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result, nil
+}
+
 // ActionBegin marks an action as running.
 func (st *State) ActionBegin(tag names.ActionTag) error {
 	var outcome params.ErrorResults
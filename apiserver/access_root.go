@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// accessRoot restricts API calls to those permitted by the level of
+// access the logged in user has to the environment.
+type accessRoot struct {
+	rpc.MethodFinder
+	access state.EnvironmentAccess
+}
+
+// newAccessRoot returns a new accessRoot that permits the calls
+// allowed by the given level of access.
+func newAccessRoot(finder rpc.MethodFinder, access state.EnvironmentAccess) *accessRoot {
+	return &accessRoot{
+		MethodFinder: finder,
+		access:       access,
+	}
+}
+
+// readOnlyFacades holds the facades that users with read access
+// may call in their entirety.
+var readOnlyFacades = set.NewStrings(
+	"AllWatcher",
+	"AllEnvWatcher",
+	"Pinger",
+)
+
+// readOnlyCalls holds the "Facade.Method" calls, outside the
+// readOnlyFacades, that users with read access may make.
+var readOnlyCalls = set.NewStrings(
+	"Annotations.Get",
+	"Block.List",
+	"Charms.CharmInfo",
+	"Charms.IsMetered",
+	"Charms.List",
+	"Client.AgentVersion",
+	"Client.APIHostPorts",
+	"Client.CharmInfo",
+	"Client.EnvUserInfo",
+	"Client.EnvironmentGet",
+	"Client.EnvironmentInfo",
+	"Client.FullStatus",
+	"Client.GetAnnotations",
+	"Client.GetEnvironmentConstraints",
+	"Client.GetServiceConstraints",
+	"Client.PrivateAddress",
+	"Client.PublicAddress",
+	"Client.RunResults",
+	"Client.SSHHostKeys",
+	"Client.ServiceGet",
+	"Client.ServiceGetCharmURL",
+	"Client.Status",
+	"Client.UnitStatusHistory",
+	"Client.WatchAll",
+	"UserManager.SetPassword",
+	"UserManager.UserInfo",
+)
+
+// adminOnlyCalls holds the "Facade.Method" calls that only users
+// with admin access may make.
+var adminOnlyCalls = set.NewStrings(
+	"Client.ShareEnvironment",
+)
+
+// FindMethod returns a permission denied error if the user's level
+// of access does not permit the method to be called.
+func (r *accessRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if !r.permitted(rootName, methodName) {
+		return nil, common.ErrPerm
+	}
+	return caller, nil
+}
+
+func (r *accessRoot) permitted(rootName, methodName string) bool {
+	call := rootName + "." + methodName
+	switch r.access {
+	case state.EnvironmentAdminAccess:
+		return true
+	case state.EnvironmentWriteAccess:
+		return !adminOnlyCalls.Contains(call)
+	case state.EnvironmentReadAccess:
+		return readOnlyFacades.Contains(rootName) || readOnlyCalls.Contains(call)
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type accessRootSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&accessRootSuite{})
+
+func (s *accessRootSuite) TestFindMethod(c *gc.C) {
+	for i, test := range []struct {
+		access   state.EnvironmentAccess
+		rootName string
+		method   string
+		allowed  bool
+	}{
+		{state.EnvironmentReadAccess, "Client", "FullStatus", true},
+		{state.EnvironmentReadAccess, "AllWatcher", "Next", true},
+		{state.EnvironmentReadAccess, "Pinger", "Ping", true},
+		{state.EnvironmentReadAccess, "Client", "DestroyMachines", false},
+		{state.EnvironmentReadAccess, "Client", "ShareEnvironment", false},
+		{state.EnvironmentReadAccess, "Annotations", "Set", false},
+		{state.EnvironmentWriteAccess, "Client", "FullStatus", true},
+		{state.EnvironmentWriteAccess, "Client", "DestroyMachines", true},
+		{state.EnvironmentWriteAccess, "Annotations", "Set", true},
+		{state.EnvironmentWriteAccess, "Client", "ShareEnvironment", false},
+		{state.EnvironmentAdminAccess, "Client", "DestroyMachines", true},
+		{state.EnvironmentAdminAccess, "Client", "ShareEnvironment", true},
+		{"bogus", "Client", "FullStatus", false},
+	} {
+		c.Logf("test %d: %s %s.%s", i, test.access, test.rootName, test.method)
+		root := newAccessRoot(&auditFinder{}, test.access)
+		caller, err := root.FindMethod(test.rootName, 0, test.method)
+		if test.allowed {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(caller, gc.NotNil)
+		} else {
+			c.Check(err, gc.Equals, common.ErrPerm)
+			c.Check(caller, gc.IsNil)
+		}
+	}
+}
+
+func (s *accessRootSuite) TestFindMethodNotFound(c *gc.C) {
+	finder := &auditFinder{err: common.ErrBadRequest}
+	root := newAccessRoot(finder, state.EnvironmentAdminAccess)
+	_, err := root.FindMethod("Client", 0, "FullStatus")
+	c.Assert(err, gc.Equals, common.ErrBadRequest)
+}
@@ -129,6 +129,13 @@ func (a *ActionAPI) ListRunning(arg params.Entities) (params.ActionsByReceivers,
 	return a.internalList(arg, runningActions)
 }
 
+// ListQueued takes a list of Entities representing ActionReceivers and
+// returns the pending Actions waiting in the queue of each of those
+// Entities for earlier Actions to finish, oldest first.
+func (a *ActionAPI) ListQueued(arg params.Entities) (params.ActionsByReceivers, error) {
+	return a.internalList(arg, queuedActions)
+}
+
 // ListCompleted takes a list of Entities representing ActionReceivers
 // and returns all of the Actions that have been run on each of those
 // Entities.
@@ -136,7 +143,9 @@ func (a *ActionAPI) ListCompleted(arg params.Entities) (params.ActionsByReceiver
 	return a.internalList(arg, completedActions)
 }
 
-// Cancel attempts to cancel enqueued Actions from running.
+// Cancel cancels the given Actions. Pending Actions are cancelled at
+// once; running Actions are marked as aborting, and stopped by their
+// receivers' agents.
 func (a *ActionAPI) Cancel(arg params.Entities) (params.ActionResults, error) {
 	response := params.ActionResults{Results: make([]params.ActionResult, len(arg.Entities))}
 	for i, entity := range arg.Entities {
@@ -156,7 +165,7 @@ func (a *ActionAPI) Cancel(arg params.Entities) (params.ActionResults, error) {
 			currentResult.Error = common.ServerError(err)
 			continue
 		}
-		result, err := action.Finish(state.ActionResults{Status: state.ActionCancelled, Message: "action cancelled via the API"})
+		result, err := action.Cancel()
 		if err != nil {
 			currentResult.Error = common.ServerError(err)
 			continue
@@ -274,6 +283,12 @@ func runningActions(ar state.ActionReceiver) ([]params.ActionResult, error) {
 	return convertActions(ar, ar.RunningActions)
 }
 
+// queuedActions iterates through the Actions() waiting in the queue of
+// an ActionReceiver, and converts them to a slice of params.ActionResult.
+func queuedActions(ar state.ActionReceiver) ([]params.ActionResult, error) {
+	return convertActions(ar, ar.QueuedActions)
+}
+
 // completedActions iterates through the Actions() that have run to
 // completion for an ActionReceiver, and converts them to a slice of
 // params.ActionResult.
@@ -313,8 +328,10 @@ func makeActionResult(actionReceiverTag names.Tag, action *state.Action) params.
 			Tag:        action.ActionTag().String(),
 			Name:       action.Name(),
 			Parameters: action.Parameters(),
+			Timeout:    action.Timeout(),
 		},
 		Status:    string(action.Status()),
+		Queued:    action.Queued(),
 		Message:   message,
 		Output:    output,
 		Enqueued:  action.Enqueued(),
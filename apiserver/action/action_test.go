@@ -574,6 +574,57 @@ func (s *actionSuite) TestCancel(c *gc.C) {
 	c.Assert(myActions[1].Status, gc.Equals, params.ActionCancelled)
 }
 
+func (s *actionSuite) TestCancelRunning(c *gc.C) {
+	added, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = added.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.action.Cancel(params.Entities{
+		Entities: []params.Entity{{Tag: added.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Status, gc.Equals, params.ActionAborting)
+
+	// Aborting Actions are listed as running until the unit's agent
+	// stops them.
+	arg := params.Entities{Entities: []params.Entity{{Tag: s.wordpressUnit.Tag().String()}}}
+	running, err := s.action.ListRunning(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(running.Actions, gc.HasLen, 1)
+	c.Assert(running.Actions[0].Actions, gc.HasLen, 1)
+	c.Assert(running.Actions[0].Actions[0].Status, gc.Equals, params.ActionAborting)
+}
+
+func (s *actionSuite) TestListQueued(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"action-parallelism": 1}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	arg := params.Actions{Actions: []params.Action{
+		{Receiver: s.wordpressUnit.Tag().String(), Name: "fakeaction"},
+		{Receiver: s.wordpressUnit.Tag().String(), Name: "fakeaction"},
+		{Receiver: s.wordpressUnit.Tag().String(), Name: "fakeaction"},
+	}}
+	enqueued, err := s.action.Enqueue(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(enqueued.Results, gc.HasLen, 3)
+	c.Assert(enqueued.Results[0].Queued, jc.IsFalse)
+	c.Assert(enqueued.Results[1].Queued, jc.IsTrue)
+	c.Assert(enqueued.Results[2].Queued, jc.IsTrue)
+
+	queued, err := s.action.ListQueued(params.Entities{
+		Entities: []params.Entity{{Tag: s.wordpressUnit.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued.Actions, gc.HasLen, 1)
+	actions := queued.Actions[0].Actions
+	c.Assert(actions, gc.HasLen, 2)
+	c.Assert(actions[0].Action.Tag, gc.Equals, enqueued.Results[1].Action.Tag)
+	c.Assert(actions[1].Action.Tag, gc.Equals, enqueued.Results[2].Action.Tag)
+}
+
 func (s *actionSuite) TestServicesCharmActions(c *gc.C) {
 	actionSchemas := map[string]map[string]interface{}{
 		"snapshot": {
@@ -4,6 +4,7 @@
 package apiserver
 
 import (
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -40,6 +41,24 @@ var MaintenanceNoLoginError = errors.New("login failed - maintenance in progress
 var errAlreadyLoggedIn = errors.New("already logged in")
 
 func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.LoginResultV1, error) {
+	result, err := a.login(req, loginVersion)
+	if a.srv.auditLog != nil {
+		code := auditResultCode(err)
+		if err == nil && result.DischargeRequired != nil {
+			code = params.CodeDischargeRequired
+		}
+		// Token and macaroon logins may not name the entity logging
+		// in, so record the authenticated entity where there is one.
+		caller := req.AuthTag
+		if err == nil && a.root.entity != nil {
+			caller = a.root.entity.Tag().String()
+		}
+		a.srv.auditLog.recordLogin(a.root.state.EnvironUUID(), caller, loginVersion, code)
+	}
+	return result, err
+}
+
+func (a *admin) login(req params.LoginRequest, loginVersion int) (params.LoginResultV1, error) {
 	var fail params.LoginResultV1
 
 	a.mu.Lock()
@@ -52,6 +71,19 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 	// authedApi is the API method finder we'll use after getting logged in.
 	var authedApi rpc.MethodFinder = newApiRoot(a.root.state, a.root.resources, a.root)
 
+	// Serve reads from the environment's read mirror, if the server
+	// has them. The mirror is only opened when a read is first made.
+	if a.srv.readMirrors != nil {
+		st, resources, authorizer := a.root.state, a.root.resources, a.root
+		authedApi = newReadMirrorRoot(authedApi, func() (rpc.MethodFinder, error) {
+			mirror, err := a.srv.readMirrors.get(st)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return newApiRoot(mirror, resources, authorizer), nil
+		})
+	}
+
 	// Use the login validation function, if one was specified.
 	if a.srv.validator != nil {
 		err := a.srv.validator(req)
@@ -69,6 +101,12 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 		}
 	}
 
+	// Only the remote address is known before authentication; the
+	// entity's own login quota is charged once it has authenticated.
+	if err := a.srv.clientLimiter.LoginAttempt(a.remoteHost()); err != nil {
+		return fail, errors.Trace(err)
+	}
+
 	var agentPingerNeeded = true
 	var isUser bool
 	kind, err := names.TagKind(req.AuthTag)
@@ -125,8 +163,37 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 		// worker for the state server environment.
 		agentPingerNeeded = false
 	}
+
+	// A token only permits calls to its scopes, in the environment it
+	// was issued for.
+	if req.Token != "" {
+		claims, err := a.srv.authCtxt.tokenClaims(req.Token)
+		if err != nil {
+			return fail, errors.Trace(err)
+		}
+		if claims.EnvUUID != a.root.state.EnvironUUID() {
+			return fail, errors.Trace(common.ErrBadCreds)
+		}
+		authedApi = newScopedRoot(authedApi, claims.Scopes)
+		isUser = true
+	}
+	// Users may only make the calls permitted by their level of
+	// access to the environment.
+	if u, ok := entity.(*environmentUserEntity); ok {
+		authedApi = newAccessRoot(authedApi, u.envUser.Access())
+	}
 	a.root.entity = entity
 
+	limitKey := clientKey(a.root.state.EnvironUUID(), entity.Tag().String())
+	if err := a.srv.clientLimiter.Login(limitKey); err != nil {
+		return fail, errors.Trace(err)
+	}
+	release, err := a.srv.clientLimiter.AcquireConnection(limitKey)
+	if err != nil {
+		return fail, errors.Trace(err)
+	}
+	a.root.release = release
+
 	if a.reqNotifier != nil {
 		a.reqNotifier.login(entity.Tag().String())
 	}
@@ -188,6 +255,21 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 		loginResult.Facades = facades
 	}
 
+	if a.srv.clientLimiter.limits.FacadeCallRate > 0 {
+		authedApi = &rateLimitedRoot{
+			MethodFinder: authedApi,
+			limiter:      a.srv.clientLimiter,
+			key:          limitKey,
+		}
+	}
+	if a.srv.auditLog != nil {
+		authedApi = &auditingRoot{
+			MethodFinder: authedApi,
+			log:          a.srv.auditLog,
+			envUUID:      a.root.state.EnvironUUID(),
+			caller:       entity.Tag().String(),
+		}
+	}
 	a.root.rpcConn.ServeFinder(authedApi, serverError)
 
 	return loginResult, nil
@@ -216,6 +298,19 @@ func (a *admin) checkCredsOfStateServerMachine(req params.LoginRequest) (state.E
 	return nil, errors.Trace(common.ErrBadCreds)
 }
 
+// remoteHost returns the host the client connected from, or "" if it
+// is not known.
+func (a *admin) remoteHost() string {
+	if a.reqNotifier == nil {
+		return ""
+	}
+	addr := a.reqNotifier.remoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
 func (a *admin) maintenanceInProgress() bool {
 	if a.srv.validator == nil {
 		return false
@@ -358,6 +453,14 @@ func (u *environmentUserEntity) PasswordValid(pass string) bool {
 	return u.user.PasswordValid(pass)
 }
 
+// IsDisabled returns whether the local user has been disabled.
+func (u *environmentUserEntity) IsDisabled() bool {
+	if u.user == nil {
+		return false
+	}
+	return u.user.IsDisabled()
+}
+
 // Tag implements state.Entity.Tag.
 func (u *environmentUserEntity) Tag() names.Tag {
 	return u.envUser.UserTag()
@@ -18,8 +18,10 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/authtoken"
 	apitesting "github.com/juju/juju/api/testing"
 	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/authentication"
 	"github.com/juju/juju/apiserver/params"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
@@ -205,6 +207,29 @@ func (s *loginSuite) TestLoginAsDeactivatedUser(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `.*unknown object type "Client"`)
 }
 
+func (s *loginSuite) TestLoginAsReadOnlyUser(c *gc.C) {
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+
+	password := "password"
+	u := s.Factory.MakeUser(c, &factory.UserParams{Password: password, NoEnvUser: true})
+	_, err := s.State.AddEnvironmentUserWithAccess(u.UserTag(), s.AdminUserTag(c), "", state.EnvironmentReadAccess)
+	c.Assert(err, jc.ErrorIsNil)
+
+	info.Tag = u.Tag()
+	info.Password = password
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	_, err = st.Client().Status([]string{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = st.Client().DestroyMachines("0")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(params.ErrCode(err), gc.Equals, params.CodeUnauthorized)
+}
+
 func (s *loginV0Suite) TestLoginSetsLogIdentifier(c *gc.C) {
 	s.runLoginSetsLogIdentifier(c)
 }
@@ -1024,3 +1049,72 @@ func (s *macaroonLoginSuite) TestUnknownUserLogin(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
 	c.Assert(client, gc.Equals, nil)
 }
+
+var _ = gc.Suite(&tokenLoginSuite{})
+
+type tokenLoginSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+func (s *tokenLoginSuite) issueToken(c *gc.C, scopes ...string) params.IssuedAuthToken {
+	issued, err := authtoken.NewClient(s.APIState).Issue(scopes, time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	return issued
+}
+
+func (s *tokenLoginSuite) tokenInfo(c *gc.C, token string) *api.Info {
+	info := s.APIInfo(c)
+	info.Tag = nil
+	info.Password = ""
+	info.Token = token
+	return info
+}
+
+func (s *tokenLoginSuite) TestLoginWithToken(c *gc.C) {
+	issued := s.issueToken(c, "Client.FullStatus")
+	st, err := api.Open(s.tokenInfo(c, issued.Token), fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	_, err = st.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Calls outside the token's scopes are refused.
+	_, err = st.Client().EnvironmentGet()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = authtoken.NewClient(st).Issue([]string{"Client"}, time.Now().Add(time.Hour))
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *tokenLoginSuite) TestLoginWithRevokedToken(c *gc.C) {
+	issued := s.issueToken(c, "Client")
+	err := authtoken.NewClient(s.APIState).Revoke(issued.Id)
+	c.Assert(err, jc.ErrorIsNil)
+
+	st, err := api.Open(s.tokenInfo(c, issued.Token), fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "token revoked: invalid entity name or password")
+	c.Assert(st, gc.IsNil)
+}
+
+func (s *tokenLoginSuite) TestLoginWithForgedToken(c *gc.C) {
+	issued := s.issueToken(c, "Client.FullStatus")
+	forged, err := authentication.SignToken([]byte("not the key"), authentication.TokenClaims{
+		Id:      issued.Id,
+		User:    s.AdminUserTag(c).String(),
+		EnvUUID: s.State.EnvironUUID(),
+		Scopes:  []string{"Client"},
+		Expires: time.Now().Add(time.Hour).Unix(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	st, err := api.Open(s.tokenInfo(c, forged), fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid token signature: invalid entity name or password")
+	c.Assert(st, gc.IsNil)
+}
+
+func (s *tokenLoginSuite) TestLoginWithTokenAndPassword(c *gc.C) {
+	info := s.tokenInfo(c, "token")
+	info.Tag = s.AdminUserTag(c)
+	_, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "open should specify a token or other credentials. Not both")
+}
@@ -231,6 +231,39 @@ func (s *annotationSuite) TestMultipleEntitiesAnnotations(c *gc.C) {
 	c.Assert(rGet, jc.IsTrue)
 }
 
+func (s *annotationSuite) TestBulkAnnotationsAllEntityKinds(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.Factory.MakeUnit(c, nil)
+	service, err := unit.Service()
+	c.Assert(err, jc.ErrorIsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+
+	entities := []string{
+		env.Tag().String(),
+		names.NewMachineTag(machineId).String(),
+		service.Tag().String(),
+		unit.Tag().String(),
+	}
+	annotations := map[string]string{"gui-x": "10", "gui-y": "20"}
+	setResult := s.annotationsApi.Set(
+		params.AnnotationsSet{Annotations: constructSetParameters(entities, annotations)})
+	c.Assert(setResult.Combine(), jc.ErrorIsNil)
+
+	args := params.Entities{}
+	for _, entity := range entities {
+		args.Entities = append(args.Entities, params.Entity{entity})
+	}
+	got := s.annotationsApi.Get(args)
+	c.Assert(got.Results, gc.HasLen, len(entities))
+	for i, aResult := range got.Results {
+		c.Check(aResult.EntityTag, gc.Equals, entities[i])
+		c.Check(aResult.Error.Error, gc.IsNil)
+		c.Check(aResult.Annotations, gc.DeepEquals, annotations)
+	}
+}
+
 func (s *annotationSuite) testSetGetEntitiesAnnotations(c *gc.C, tag names.Tag) {
 	entity := tag.String()
 	entities := []string{entity}
@@ -18,6 +18,7 @@ import (
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
 	"golang.org/x/net/websocket"
 	"launchpad.net/tomb"
 
@@ -46,6 +47,10 @@ type Server struct {
 	dataDir           string
 	logDir            string
 	limiter           utils.Limiter
+	clientLimiter     *clientLimiter
+	watchSessions     *watchSessions
+	auditLog          *auditLog
+	readMirrors       *readMirrors
 	validator         LoginValidator
 	adminApiFactories map[int]adminApiFactory
 	mongoUnavailable  uint32 // non zero if mongoUnavailable
@@ -67,6 +72,21 @@ type ServerConfig struct {
 	LogDir      string
 	Validator   LoginValidator
 	CertChanged chan params.StateServingInfo
+
+	// Limits holds the limits applied to each client of the server.
+	Limits Limits
+
+	// Audit configures the server's audit log.
+	Audit AuditConfig
+
+	// ReadMirror, if true, causes read-only calls, such as those
+	// reporting status or watching the environment, to be served by
+	// state that reads from a secondary member of the mongo replica
+	// set where one is available, rather than from the primary. On a
+	// state server that is not the primary, the local secondary is
+	// preferred. All other calls, and all writes, are served by the
+	// primary as usual.
+	ReadMirror bool
 }
 
 // changeCertListener wraps a TLS net.Listener.
@@ -180,14 +200,16 @@ func NewServer(s *state.State, lis net.Listener, cfg ServerConfig) (*Server, err
 func newServer(s *state.State, lis *net.TCPListener, cfg ServerConfig) (_ *Server, err error) {
 	logger.Infof("listening on %q", lis.Addr())
 	srv := &Server{
-		state:     s,
-		statePool: state.NewStatePool(s),
-		addr:      lis.Addr().(*net.TCPAddr), // cannot fail
-		tag:       cfg.Tag,
-		dataDir:   cfg.DataDir,
-		logDir:    cfg.LogDir,
-		limiter:   utils.NewLimiter(loginRateLimit),
-		validator: cfg.Validator,
+		state:         s,
+		statePool:     state.NewStatePool(s),
+		addr:          lis.Addr().(*net.TCPAddr), // cannot fail
+		tag:           cfg.Tag,
+		dataDir:       cfg.DataDir,
+		logDir:        cfg.LogDir,
+		limiter:       utils.NewLimiter(loginRateLimit),
+		clientLimiter: newClientLimiter(cfg.Limits, clock.WallClock),
+		watchSessions: newWatchSessions(),
+		validator:     cfg.Validator,
 		adminApiFactories: map[int]adminApiFactory{
 			0: newAdminApiV0,
 			1: newAdminApiV1,
@@ -204,11 +226,23 @@ func newServer(s *state.State, lis *net.TCPListener, cfg ServerConfig) (_ *Serve
 	tlsConfig := tls.Config{
 		Certificates: []tls.Certificate{tlsCert},
 	}
+	if cfg.Audit.Enabled {
+		srv.auditLog = newAuditLog(cfg.Audit, clock.WallClock, s.AddAuditEntries)
+	}
+	if cfg.ReadMirror {
+		srv.readMirrors = newReadMirrors()
+	}
 	changeCertListener := newChangeCertListener(lis, cfg.CertChanged, tlsConfig)
 	go srv.run(changeCertListener)
 	return srv, nil
 }
 
+// LimitStats returns the number of requests the server has rejected
+// because clients exceeded their limits.
+func (srv *Server) LimitStats() LimitStats {
+	return srv.clientLimiter.Stats()
+}
+
 // Dead returns a channel that signals when the server has exited.
 func (srv *Server) Dead() <-chan struct{} {
 	return srv.tomb.Dead()
@@ -235,6 +269,9 @@ type requestNotifier struct {
 	id    int64
 	start time.Time
 
+	// remoteAddr holds the address the connection was made from.
+	remoteAddr string
+
 	mu   sync.Mutex
 	tag_ string
 }
@@ -291,6 +328,7 @@ func (n *requestNotifier) ServerReply(req rpc.Request, hdr *rpc.Header, body int
 }
 
 func (n *requestNotifier) join(req *http.Request) {
+	n.remoteAddr = req.RemoteAddr
 	logger.Infof("[%X] API connection from %s", n.id, req.RemoteAddr)
 }
 
@@ -317,6 +355,15 @@ func (srv *Server) run(lis net.Listener) {
 	defer func() {
 		srv.state.HackLeadership() // Break deadlocks caused by BlockUntil... calls.
 		srv.wg.Wait()              // wait for any outstanding requests to complete.
+		srv.watchSessions.stopAll()
+		if srv.auditLog != nil {
+			if err := srv.auditLog.Stop(); err != nil {
+				logger.Errorf("error stopping audit log: %v", err)
+			}
+		}
+		if srv.readMirrors != nil {
+			srv.readMirrors.closeAll()
+		}
 		srv.tomb.Done()
 		srv.statePool.Close()
 	}()
@@ -347,6 +394,12 @@ func (srv *Server) run(lis net.Listener) {
 			newLogSinkHandler(httpCtxt, srv.logDir))
 		handleAll(mux, "/environment/:envuuid/log",
 			newDebugLogDBHandler(httpCtxt, srvDying))
+		handleAll(mux, "/environment/:envuuid/logquery",
+			&logQueryHandler{
+				ctxt: httpCtxt,
+				stop: srvDying,
+			},
+		)
 	} else {
 		handleAll(mux, "/environment/:envuuid/log",
 			newDebugLogFileHandler(httpCtxt, srvDying, srv.logDir))
@@ -356,6 +409,17 @@ func (srv *Server) run(lis net.Listener) {
 			ctxt:    httpCtxt,
 			dataDir: srv.dataDir},
 	)
+	handleAll(mux, "/environment/:envuuid/services/:service/resources/:name",
+		&resourcesUploadHandler{
+			ctxt: httpCtxt,
+		},
+	)
+	handleAll(mux, "/environment/:envuuid/units/:unit/resources/:name",
+		&resourcesDownloadHandler{
+			ctxt:    httpCtxt,
+			dataDir: srv.dataDir,
+		},
+	)
 	// TODO: We can switch from handleAll to mux.Post/Get/etc for entries
 	// where we only want to support specific request methods. However, our
 	// tests currently assert that errors come back as application/json and
@@ -378,6 +442,13 @@ func (srv *Server) run(lis net.Listener) {
 			ctxt: strictCtxt,
 		},
 	)
+	handleAll(mux, "/environment/:envuuid/watch",
+		&watchStreamHandler{
+			ctxt:     httpCtxt,
+			sessions: srv.watchSessions,
+			stop:     srvDying,
+		},
+	)
 	handleAll(mux, "/environment/:envuuid/api", http.HandlerFunc(srv.apiHandler))
 
 	handleAll(mux, "/environment/:envuuid/images/:kind/:series/:arch/:filename",
@@ -480,7 +551,11 @@ func (srv *Server) serveConn(wsConn *websocket.Conn, reqNotifier *requestNotifie
 	case <-conn.Dead():
 	case <-srv.tomb.Dying():
 	}
-	return conn.Close()
+	err = conn.Close()
+	if h != nil {
+		h.releaseConnection()
+	}
+	return err
 }
 
 func (srv *Server) newAPIHandler(conn *rpc.Conn, reqNotifier *requestNotifier, envUUID string) (*apiHandler, error) {
@@ -0,0 +1,420 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/clock"
+	"github.com/juju/utils/set"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/audit"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// AuditConfig configures the audit log of the calls made to the API
+// server. Each call made after login is recorded, along with each
+// login attempt, as a state.AuditEntry.
+type AuditConfig struct {
+	// Enabled is true if calls are recorded.
+	Enabled bool
+
+	// RedactedParams holds the names of sensitive parameters, whose
+	// values are not recorded. A parameter is redacted if its name
+	// contains, ignoring case, any of these.
+	RedactedParams []string
+
+	// Facades holds the names of the facades whose calls are
+	// recorded. If it is empty, calls to all facades are recorded.
+	Facades []string
+
+	// ExcludedFacades holds the names of the facades whose calls are
+	// not recorded, even if they are in Facades.
+	ExcludedFacades []string
+}
+
+// DefaultAuditConfig holds the audit configuration used by the machine
+// agent when none is configured. Calls to all facades, including those
+// used by agents, are recorded, except for the watcher and pinger
+// facades: agents call those continuously just to follow changes and
+// stay connected, and the calls change nothing. The agent config's
+// API_AUDIT_FACADES and API_AUDIT_EXCLUDED_FACADES narrow this down,
+// for example to the facades used by clients.
+var DefaultAuditConfig = AuditConfig{
+	Enabled: true,
+	RedactedParams: []string{
+		"password",
+		"secret",
+		"key",
+		"credential",
+		"management-certificate",
+		"maas-oauth",
+		"macaroon",
+		"nonce",
+		"token",
+	},
+	ExcludedFacades: []string{
+		"AllEnvWatcher",
+		"AllWatcher",
+		"EntityWatcher",
+		"FilesystemAttachmentsWatcher",
+		"NotifyWatcher",
+		"Pinger",
+		"RelationUnitsWatcher",
+		"StringsWatcher",
+		"VolumeAttachmentsWatcher",
+	},
+}
+
+const (
+	// auditLogBufferSize is the number of entries that may be waiting
+	// to be written before further entries are dropped.
+	auditLogBufferSize = 1000
+
+	// auditLogBatchSize is the largest number of entries written at
+	// once.
+	auditLogBatchSize = 100
+
+	// maxAuditParamsSize is the largest encoding of a call's
+	// parameters recorded; larger encodings are truncated.
+	maxAuditParamsSize = 4096
+
+	// maxAuditEntityTags is the largest number of entity tags recorded
+	// for a call.
+	maxAuditEntityTags = 100
+
+	// redactedValue replaces the values of redacted parameters.
+	redactedValue = "REDACTED"
+)
+
+// auditLog records API calls in the background, so that callers are
+// not kept waiting for the audit log to be written. If entries are
+// recorded faster than they can be written, the excess entries are
+// dropped and counted, rather than slowing down the API server.
+type auditLog struct {
+	tomb     tomb.Tomb
+	clock    clock.Clock
+	write    func(entries []state.AuditEntry) error
+	entries  chan state.AuditEntry
+	redacted []string
+	facades  set.Strings
+	excluded set.Strings
+
+	mu      sync.Mutex
+	dropped int64
+}
+
+// newAuditLog starts an auditLog that writes entries with the given
+// function.
+func newAuditLog(config AuditConfig, clock clock.Clock, write func(entries []state.AuditEntry) error) *auditLog {
+	l := &auditLog{
+		clock:    clock,
+		write:    write,
+		entries:  make(chan state.AuditEntry, auditLogBufferSize),
+		facades:  set.NewStrings(config.Facades...),
+		excluded: set.NewStrings(config.ExcludedFacades...),
+	}
+	for _, name := range config.RedactedParams {
+		if name != "" {
+			l.redacted = append(l.redacted, strings.ToLower(name))
+		}
+	}
+	go func() {
+		defer l.tomb.Done()
+		l.tomb.Kill(l.loop())
+	}()
+	return l
+}
+
+// Stop writes any entries still waiting and stops the audit log.
+func (l *auditLog) Stop() error {
+	l.tomb.Kill(nil)
+	return errors.Trace(l.tomb.Wait())
+}
+
+// Dropped returns the number of entries dropped so far.
+func (l *auditLog) Dropped() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dropped
+}
+
+func (l *auditLog) loop() error {
+	for {
+		select {
+		case <-l.tomb.Dying():
+			l.writeBatches()
+			return tomb.ErrDying
+		case entry := <-l.entries:
+			batch := []state.AuditEntry{entry}
+			l.writeBatch(l.fill(batch))
+		}
+	}
+}
+
+// fill adds waiting entries to the batch, up to auditLogBatchSize.
+func (l *auditLog) fill(batch []state.AuditEntry) []state.AuditEntry {
+	for len(batch) < auditLogBatchSize {
+		select {
+		case entry := <-l.entries:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// writeBatches writes all the waiting entries.
+func (l *auditLog) writeBatches() {
+	for {
+		batch := l.fill(nil)
+		if len(batch) == 0 {
+			return
+		}
+		l.writeBatch(batch)
+	}
+}
+
+// writeBatch writes the batch of entries, first logging each one to
+// the audit module, so that agents that keep an audit log (see
+// logging.InstallAuditLog) have a tamper-evident copy.
+func (l *auditLog) writeBatch(batch []state.AuditEntry) {
+	for _, entry := range batch {
+		audit.Audit(auditTagger(entry.Caller), "%s.%s v%d in environment %q: %s %s",
+			entry.Facade, entry.Method, entry.Version, entry.EnvUUID, entry.ResultCode, entry.Params,
+		)
+	}
+	if err := l.write(batch); err != nil {
+		logger.Errorf("cannot write %d audit log entries: %v", len(batch), err)
+	}
+}
+
+// add queues the entry to be written, or drops it if too many entries
+// are waiting.
+func (l *auditLog) add(entry state.AuditEntry) {
+	select {
+	case l.entries <- entry:
+		return
+	default:
+	}
+	l.mu.Lock()
+	l.dropped++
+	dropped := l.dropped
+	l.mu.Unlock()
+	if dropped%auditLogBufferSize == 1 {
+		logger.Warningf("audit log is falling behind; %d entries dropped", dropped)
+	}
+}
+
+// recordLogin records a login attempt with the given result code. The
+// credentials used are never recorded.
+func (l *auditLog) recordLogin(envUUID, authTag string, version int, code string) {
+	entry := state.AuditEntry{
+		Time:       l.clock.Now(),
+		EnvUUID:    envUUID,
+		Caller:     authTag,
+		Facade:     "Admin",
+		Version:    version,
+		Method:     "Login",
+		ResultCode: code,
+	}
+	if _, err := names.ParseTag(authTag); err == nil {
+		entry.EntityTags = []string{authTag}
+	}
+	l.add(entry)
+}
+
+// auditTagger is the audit.Tagger for the caller recorded in an
+// audit entry; failed logins may have no caller.
+type auditTagger string
+
+// Tag implements audit.Tagger.
+func (c auditTagger) Tag() string {
+	if c == "" {
+		return "unknown"
+	}
+	return string(c)
+}
+
+// alwaysAudited holds the "Facade.Method" calls that change who may
+// access an environment; they are recorded even if their facade is
+// not.
+var alwaysAudited = set.NewStrings(
+	"Client.ShareEnvironment",
+)
+
+// recordCall records a call made by the caller to the given method.
+// The arg is the call's parameter, which is invalid if there is none.
+func (l *auditLog) recordCall(envUUID, caller, facade string, version int, method string, arg reflect.Value, err error) {
+	if !l.isAudited(facade) && !alwaysAudited.Contains(facade+"."+method) {
+		return
+	}
+	entry := state.AuditEntry{
+		Time:       l.clock.Now(),
+		EnvUUID:    envUUID,
+		Caller:     caller,
+		Facade:     facade,
+		Version:    version,
+		Method:     method,
+		ResultCode: auditResultCode(err),
+	}
+	if arg.IsValid() {
+		var encodeErr error
+		entry.Params, entry.EntityTags, encodeErr = l.encodeParams(arg.Interface())
+		if encodeErr != nil {
+			logger.Warningf("cannot record parameters of %s.%s: %v", facade, method, encodeErr)
+		}
+	}
+	l.add(entry)
+}
+
+// isAudited reports whether calls to the facade are recorded.
+func (l *auditLog) isAudited(facade string) bool {
+	if l.excluded.Contains(facade) {
+		return false
+	}
+	return l.facades.IsEmpty() || l.facades.Contains(facade)
+}
+
+// encodeParams returns the JSON encoding of the parameters, with the
+// values of redacted parameters replaced, and the entity tags they
+// hold.
+func (l *auditLog) encodeParams(arg interface{}) (string, []string, error) {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	tags := set.NewStrings()
+	value = l.scrub(value, tags)
+	if data, err = json.Marshal(value); err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	if len(data) > maxAuditParamsSize {
+		data = append(data[:maxAuditParamsSize], "..."...)
+	}
+	var entityTags []string
+	if !tags.IsEmpty() {
+		entityTags = tags.SortedValues()
+	}
+	if len(entityTags) > maxAuditEntityTags {
+		entityTags = entityTags[:maxAuditEntityTags]
+	}
+	return string(data), entityTags, nil
+}
+
+// scrub replaces the values of redacted parameters in the decoded JSON
+// value, and adds the entity tags it holds to tags. An entity tag is the
+// string value of a field whose name ends in "tag".
+func (l *auditLog) scrub(value interface{}, tags set.Strings) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			if l.isRedacted(key) {
+				value[key] = redactedValue
+				continue
+			}
+			if s, ok := v.(string); ok && strings.HasSuffix(strings.ToLower(key), "tag") {
+				if _, err := names.ParseTag(s); err == nil {
+					tags.Add(s)
+				}
+			}
+			value[key] = l.scrub(v, tags)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = l.scrub(v, tags)
+		}
+	}
+	return value
+}
+
+// isRedacted reports whether the value of the named parameter must not
+// be recorded.
+func (l *auditLog) isRedacted(name string) bool {
+	name = strings.ToLower(name)
+	for _, redacted := range l.redacted {
+		if strings.Contains(name, redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditResultCode returns the result code recorded for a call that
+// returned the given error.
+func auditResultCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	if serverErr := common.ServerError(err); serverErr != nil && serverErr.Code != "" {
+		return serverErr.Code
+	}
+	return "error"
+}
+
+// auditingRoot records the calls made by a client in the audit log.
+type auditingRoot struct {
+	rpc.MethodFinder
+	log     *auditLog
+	envUUID string
+	caller  string
+}
+
+// FindMethod implements rpc.MethodFinder. Calls to methods that cannot
+// be found are recorded straight away; others are recorded once they
+// return.
+func (r *auditingRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		r.log.recordCall(r.envUUID, r.caller, rootName, version, methodName, reflect.Value{}, err)
+		return nil, err
+	}
+	return &auditingCaller{
+		MethodCaller: caller,
+		root:         r,
+		facade:       rootName,
+		version:      version,
+		method:       methodName,
+	}, nil
+}
+
+// Kill implements rpc.Killer, killing the wrapped root if it can be.
+func (r *auditingRoot) Kill() {
+	if killer, ok := r.MethodFinder.(rpc.Killer); ok {
+		killer.Kill()
+	}
+}
+
+// auditingCaller records a call to a method in the audit log.
+type auditingCaller struct {
+	rpcreflect.MethodCaller
+	root    *auditingRoot
+	facade  string
+	version int
+	method  string
+}
+
+// Call implements rpcreflect.MethodCaller.
+func (c *auditingCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+	result, err := c.MethodCaller.Call(objId, arg)
+	c.root.log.recordCall(c.root.envUUID, c.root.caller, c.facade, c.version, c.method, arg, err)
+	return result, err
+}
@@ -0,0 +1,378 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type auditIntSuite struct {
+	coretesting.BaseSuite
+	clock  *coretesting.Clock
+	writer *auditWriter
+}
+
+var _ = gc.Suite(&auditIntSuite{})
+
+func (s *auditIntSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = coretesting.NewClock(time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC))
+	s.writer = &auditWriter{}
+}
+
+func (s *auditIntSuite) newAuditLog(c *gc.C) *auditLog {
+	l := newAuditLog(DefaultAuditConfig, s.clock, s.writer.write)
+	s.AddCleanup(func(*gc.C) { l.Stop() })
+	return l
+}
+
+func (s *auditIntSuite) TestEncodeParams(c *gc.C) {
+	l := s.newAuditLog(c)
+	arg := struct {
+		Entities []params.Entity
+		UnitTag  string
+		Password string
+		Config   map[string]interface{}
+		Count    int64
+	}{
+		Entities: []params.Entity{{Tag: "unit-mysql-0"}, {Tag: "machine-1"}, {Tag: "bogus"}},
+		UnitTag:  "unit-mysql-0",
+		Password: "sekrit",
+		Config: map[string]interface{}{
+			"admin-secret": "sekrit",
+			"name":         "env",
+		},
+		Count: 1 << 60,
+	}
+	encoded, tags, err := l.encodeParams(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(encoded, gc.Equals,
+		`{"Config":{"admin-secret":"REDACTED","name":"env"},`+
+			`"Count":1152921504606846976,`+
+			`"Entities":[{"Tag":"unit-mysql-0"},{"Tag":"machine-1"},{"Tag":"bogus"}],`+
+			`"Password":"REDACTED","UnitTag":"unit-mysql-0"}`)
+	c.Assert(tags, jc.DeepEquals, []string{"machine-1", "unit-mysql-0"})
+}
+
+func (s *auditIntSuite) TestEncodeParamsRedactsCredentials(c *gc.C) {
+	l := s.newAuditLog(c)
+	arg := params.EnvironmentSet{
+		Config: map[string]interface{}{
+			"maas-oauth":             "a:b:c",
+			"management-certificate": "cert",
+			"access-key":             "AKIA",
+			"secret-key":             "sekrit",
+			"default-series":         "trusty",
+		},
+	}
+	encoded, _, err := l.encodeParams(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(encoded, gc.Equals,
+		`{"Config":{"access-key":"REDACTED","default-series":"trusty",`+
+			`"maas-oauth":"REDACTED","management-certificate":"REDACTED",`+
+			`"secret-key":"REDACTED"}}`)
+}
+
+func (s *auditIntSuite) TestEncodeParamsTruncated(c *gc.C) {
+	l := s.newAuditLog(c)
+	arg := params.Entity{Tag: string(make([]byte, maxAuditParamsSize))}
+	encoded, tags, err := l.encodeParams(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(encoded, gc.HasLen, maxAuditParamsSize+3)
+	c.Assert(encoded[maxAuditParamsSize:], gc.Equals, "...")
+	c.Assert(tags, gc.IsNil)
+}
+
+func (s *auditIntSuite) TestAuditResultCode(c *gc.C) {
+	c.Assert(auditResultCode(nil), gc.Equals, "ok")
+	c.Assert(auditResultCode(common.ErrPerm), gc.Equals, params.CodeUnauthorized)
+	c.Assert(auditResultCode(errors.NotFoundf("machine")), gc.Equals, params.CodeNotFound)
+	c.Assert(auditResultCode(errors.New("boom")), gc.Equals, "error")
+}
+
+func (s *auditIntSuite) TestAuditingRoot(c *gc.C) {
+	l := s.newAuditLog(c)
+	finder := &auditFinder{}
+	root := &auditingRoot{
+		MethodFinder: finder,
+		log:          l,
+		envUUID:      "uuid",
+		caller:       "user-admin",
+	}
+	caller, err := root.FindMethod("Client", 0, "DestroyMachines")
+	c.Assert(err, jc.ErrorIsNil)
+	arg := params.DestroyMachines{MachineNames: []string{"1"}, Force: true}
+	_, err = caller.Call("", reflect.ValueOf(arg))
+	c.Assert(err, jc.ErrorIsNil)
+
+	finder.err = common.ErrPerm
+	_, err = root.FindMethod("Client", 0, "DestroyMachines")
+	c.Assert(err, gc.Equals, common.ErrPerm)
+
+	// Calls to facades that are not audited are not recorded.
+	finder.err = nil
+	caller, err = root.FindMethod("Pinger", 0, "Ping")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = caller.Call("", reflect.Value{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	root.Kill()
+	c.Assert(finder.killed, jc.IsTrue)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	now := s.clock.Now()
+	c.Assert(s.writer.all(), jc.DeepEquals, []state.AuditEntry{{
+		Time:       now,
+		EnvUUID:    "uuid",
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "DestroyMachines",
+		Params:     `{"Force":true,"MachineNames":["1"]}`,
+		ResultCode: "ok",
+	}, {
+		Time:       now,
+		EnvUUID:    "uuid",
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "DestroyMachines",
+		ResultCode: params.CodeUnauthorized,
+	}})
+}
+
+func (s *auditIntSuite) TestAccessChangesAlwaysRecorded(c *gc.C) {
+	config := DefaultAuditConfig
+	config.ExcludedFacades = []string{"Client"}
+	l := newAuditLog(config, s.clock, s.writer.write)
+	arg := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: "user-bob@local",
+			Action:  params.GrantEnvUser,
+			Access:  "write",
+		}},
+	}
+	l.recordCall("uuid", "user-admin", "Client", 0, "FullStatus", reflect.Value{}, nil)
+	l.recordCall("uuid", "user-admin", "Client", 0, "ShareEnvironment", reflect.ValueOf(arg), nil)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	c.Assert(s.writer.all(), jc.DeepEquals, []state.AuditEntry{{
+		Time:       s.clock.Now(),
+		EnvUUID:    "uuid",
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "ShareEnvironment",
+		Params:     `{"Changes":[{"access":"write","action":"grant","user-tag":"user-bob@local"}]}`,
+		EntityTags: []string{"user-bob@local"},
+		ResultCode: "ok",
+	}})
+}
+
+func (s *auditIntSuite) TestAgentFacadesRecorded(c *gc.C) {
+	l := s.newAuditLog(c)
+	l.recordCall("uuid", "machine-0", "Machiner", 0, "SetStatus", reflect.Value{}, nil)
+	l.recordCall("uuid", "unit-mysql-0", "Uniter", 3, "Watch", reflect.Value{}, nil)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	c.Assert(s.writer.all(), jc.DeepEquals, []state.AuditEntry{{
+		Time:       s.clock.Now(),
+		EnvUUID:    "uuid",
+		Caller:     "machine-0",
+		Facade:     "Machiner",
+		Method:     "SetStatus",
+		ResultCode: "ok",
+	}, {
+		Time:       s.clock.Now(),
+		EnvUUID:    "uuid",
+		Caller:     "unit-mysql-0",
+		Facade:     "Uniter",
+		Version:    3,
+		Method:     "Watch",
+		ResultCode: "ok",
+	}})
+}
+
+func (s *auditIntSuite) TestWatcherAndPingerFacadesNotRecorded(c *gc.C) {
+	l := s.newAuditLog(c)
+	l.recordCall("uuid", "unit-mysql-0", "NotifyWatcher", 0, "Next", reflect.Value{}, nil)
+	l.recordCall("uuid", "user-admin", "AllWatcher", 0, "Next", reflect.Value{}, nil)
+	l.recordCall("uuid", "machine-0", "Pinger", 0, "Ping", reflect.Value{}, nil)
+	l.recordCall("uuid", "user-admin", "Service", 1, "ServiceDeploy", reflect.Value{}, nil)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	c.Assert(s.writer.all(), jc.DeepEquals, []state.AuditEntry{{
+		Time:       s.clock.Now(),
+		EnvUUID:    "uuid",
+		Caller:     "user-admin",
+		Facade:     "Service",
+		Version:    1,
+		Method:     "ServiceDeploy",
+		ResultCode: "ok",
+	}})
+}
+
+func (s *auditIntSuite) TestClientFacadesOnly(c *gc.C) {
+	config := DefaultAuditConfig
+	config.Facades = []string{"Client", "Service"}
+	l := newAuditLog(config, s.clock, s.writer.write)
+	l.recordCall("uuid", "machine-0", "Machiner", 0, "SetStatus", reflect.Value{}, nil)
+	l.recordCall("uuid", "user-admin", "Service", 1, "ServiceDeploy", reflect.Value{}, nil)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	c.Assert(s.writer.all(), jc.DeepEquals, []state.AuditEntry{{
+		Time:       s.clock.Now(),
+		EnvUUID:    "uuid",
+		Caller:     "user-admin",
+		Facade:     "Service",
+		Version:    1,
+		Method:     "ServiceDeploy",
+		ResultCode: "ok",
+	}})
+}
+
+func (s *auditIntSuite) TestEntriesLoggedToAuditModule(c *gc.C) {
+	var tw loggo.TestWriter
+	c.Assert(loggo.RegisterWriter("audit-test", &tw, loggo.INFO), jc.ErrorIsNil)
+	defer loggo.RemoveWriter("audit-test")
+	loggo.GetLogger("audit").SetLogLevel(loggo.INFO)
+	l := s.newAuditLog(c)
+	l.recordCall("uuid", "user-admin", "Client", 0, "FullStatus", reflect.Value{}, nil)
+	l.recordLogin("uuid", "", 2, params.CodeUnauthorized)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	var messages []string
+	for _, entry := range tw.Log() {
+		if entry.Module == "audit" {
+			messages = append(messages, entry.Message)
+		}
+	}
+	c.Assert(messages, jc.DeepEquals, []string{
+		`user-admin: Client.FullStatus v0 in environment "uuid": ok `,
+		`unknown: Admin.Login v2 in environment "uuid": unauthorized access `,
+	})
+}
+
+func (s *auditIntSuite) TestRecordLogin(c *gc.C) {
+	l := s.newAuditLog(c)
+	l.recordLogin("uuid", "machine-0", 2, "ok")
+	l.recordLogin("uuid", "", 2, params.CodeUnauthorized)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	now := s.clock.Now()
+	c.Assert(s.writer.all(), jc.DeepEquals, []state.AuditEntry{{
+		Time:       now,
+		EnvUUID:    "uuid",
+		Caller:     "machine-0",
+		Facade:     "Admin",
+		Version:    2,
+		Method:     "Login",
+		EntityTags: []string{"machine-0"},
+		ResultCode: "ok",
+	}, {
+		Time:       now,
+		EnvUUID:    "uuid",
+		Facade:     "Admin",
+		Version:    2,
+		Method:     "Login",
+		ResultCode: params.CodeUnauthorized,
+	}})
+}
+
+func (s *auditIntSuite) TestDropsEntriesWhenFull(c *gc.C) {
+	s.writer.block = make(chan struct{})
+	l := s.newAuditLog(c)
+	// The first entry is taken by the blocked writer; the rest fill
+	// the buffer, after which entries are dropped.
+	for i := 0; i < auditLogBufferSize+10; i++ {
+		l.recordLogin("uuid", "machine-0", 2, "ok")
+		if i == 0 {
+			s.writer.waitForWrite(c)
+		}
+	}
+	c.Assert(l.Dropped(), gc.Equals, int64(9))
+	close(s.writer.block)
+	c.Assert(l.Stop(), jc.ErrorIsNil)
+	c.Assert(s.writer.all(), gc.HasLen, auditLogBufferSize+1)
+	c.Assert(s.writer.maxBatch <= auditLogBatchSize, jc.IsTrue)
+}
+
+// auditWriter records the entries written by an auditLog.
+type auditWriter struct {
+	// block, if not nil, blocks writes until it is closed.
+	block chan struct{}
+
+	mu       sync.Mutex
+	started  bool
+	entries  []state.AuditEntry
+	maxBatch int
+}
+
+func (w *auditWriter) write(entries []state.AuditEntry) error {
+	w.mu.Lock()
+	w.started = true
+	w.mu.Unlock()
+	if w.block != nil {
+		<-w.block
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries = append(w.entries, entries...)
+	if len(entries) > w.maxBatch {
+		w.maxBatch = len(entries)
+	}
+	return nil
+}
+
+func (w *auditWriter) waitForWrite(c *gc.C) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		w.mu.Lock()
+		started := w.started
+		w.mu.Unlock()
+		if started {
+			return
+		}
+	}
+	c.Fatalf("nothing written")
+}
+
+func (w *auditWriter) all() []state.AuditEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.entries
+}
+
+// auditFinder is an rpc.MethodFinder whose methods do nothing.
+type auditFinder struct {
+	err    error
+	killed bool
+}
+
+func (f *auditFinder) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return auditCaller{}, nil
+}
+
+func (f *auditFinder) Kill() {
+	f.killed = true
+}
+
+type auditCaller struct{}
+
+func (auditCaller) ParamsType() reflect.Type {
+	return nil
+}
+
+func (auditCaller) ResultType() reflect.Type {
+	return nil
+}
+
+func (auditCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+	return reflect.Value{}, nil
+}
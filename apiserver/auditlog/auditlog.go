@@ -0,0 +1,96 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The auditlog package implements the API facade used to read the
+// record the API servers keep of the calls made to them.
+package auditlog
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("AuditLog", 1, NewAPI)
+}
+
+const (
+	// defaultLimit is the number of entries returned when the filter
+	// sets no limit.
+	defaultLimit = 100
+
+	// maxLimit is the largest number of entries returned.
+	maxLimit = 10000
+)
+
+// API implements the AuditLog facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new AuditLog API. As the audit log may reveal
+// details of any environment, it is only available to system
+// administrators.
+func NewAPI(st *state.State, _ *common.Resources, authorizer common.Authorizer) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	// Since we know this is a user tag (because AuthClient is true),
+	// we just do the type assertion to the UserTag.
+	apiUser, _ := authorizer.GetAuthTag().(names.UserTag)
+	isAdmin, err := st.IsSystemAdministrator(apiUser)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !isAdmin {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+// Entries returns the audit log entries selected by the filter, newest
+// first.
+func (api *API) Entries(args params.AuditLogFilter) (params.AuditLogEntries, error) {
+	filter := state.AuditFilter{
+		Caller: args.Caller,
+		Facade: args.Facade,
+		Method: args.Method,
+		Limit:  args.Limit,
+	}
+	if !args.AllEnvironments {
+		filter.EnvUUID = api.st.EnvironUUID()
+	}
+	if args.Since != nil {
+		filter.Since = *args.Since
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	} else if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	entries, err := api.st.AuditEntries(filter)
+	if err != nil {
+		return params.AuditLogEntries{}, errors.Trace(err)
+	}
+	result := params.AuditLogEntries{
+		Entries: make([]params.AuditLogEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		result.Entries[i] = params.AuditLogEntry{
+			Time:       entry.Time,
+			EnvUUID:    entry.EnvUUID,
+			Caller:     entry.Caller,
+			Facade:     entry.Facade,
+			Version:    entry.Version,
+			Method:     entry.Method,
+			EntityTags: entry.EntityTags,
+			Params:     entry.Params,
+			ResultCode: entry.ResultCode,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/auditlog"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type auditLogSuite struct {
+	jujutesting.JujuConnSuite
+
+	api *auditlog.API
+	now time.Time
+}
+
+var _ = gc.Suite(&auditLogSuite{})
+
+func (s *auditLogSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	}
+	api, err := auditlog.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	s.api = api
+
+	s.now = time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC)
+	err = s.State.AddAuditEntries([]state.AuditEntry{{
+		Time:       s.now,
+		EnvUUID:    s.State.EnvironUUID(),
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "FullStatus",
+		ResultCode: "ok",
+	}, {
+		Time:       s.now.Add(time.Minute),
+		EnvUUID:    s.State.EnvironUUID(),
+		Caller:     "machine-0",
+		Facade:     "Machiner",
+		Method:     "SetStatus",
+		EntityTags: []string{"machine-0"},
+		Params:     `{"Entities":[{"Tag":"machine-0"}]}`,
+		ResultCode: "ok",
+	}, {
+		Time:       s.now.Add(2 * time.Minute),
+		EnvUUID:    "another-uuid",
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "FullStatus",
+		ResultCode: "ok",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *auditLogSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: names.NewUnitTag("mysql/0"),
+	}
+	api, err := auditlog.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *auditLogSuite) TestNewAPIRefusesNonAdmins(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	}
+	api, err := auditlog.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *auditLogSuite) TestEntries(c *gc.C) {
+	result, err := s.api.Entries(params.AuditLogFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Entries, jc.DeepEquals, []params.AuditLogEntry{{
+		Time:       s.now.Add(time.Minute),
+		EnvUUID:    s.State.EnvironUUID(),
+		Caller:     "machine-0",
+		Facade:     "Machiner",
+		Method:     "SetStatus",
+		EntityTags: []string{"machine-0"},
+		Params:     `{"Entities":[{"Tag":"machine-0"}]}`,
+		ResultCode: "ok",
+	}, {
+		Time:       s.now,
+		EnvUUID:    s.State.EnvironUUID(),
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Method:     "FullStatus",
+		ResultCode: "ok",
+	}})
+}
+
+func (s *auditLogSuite) TestEntriesFilter(c *gc.C) {
+	since := s.now.Add(time.Minute)
+	result, err := s.api.Entries(params.AuditLogFilter{
+		Caller:          "user-admin",
+		Since:           &since,
+		AllEnvironments: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Entries, gc.HasLen, 1)
+	c.Assert(result.Entries[0].EnvUUID, gc.Equals, "another-uuid")
+
+	result, err = s.api.Entries(params.AuditLogFilter{
+		Facade:          "Client",
+		Method:          "FullStatus",
+		Limit:           1,
+		AllEnvironments: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Entries, gc.HasLen, 1)
+	c.Assert(result.Entries[0].Time, gc.Equals, s.now.Add(2*time.Minute))
+}
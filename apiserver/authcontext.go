@@ -6,6 +6,7 @@ package apiserver
 import (
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"github.com/juju/utils/clock"
 	"gopkg.in/macaroon-bakery.v1/bakery"
 	"gopkg.in/macaroon-bakery.v1/httpbakery"
 	"net/http"
@@ -29,6 +30,10 @@ type authContext struct {
 	macaroonAuthOnce   sync.Once
 	_macaroonAuth      *authentication.MacaroonAuthenticator
 	_macaroonAuthError error
+
+	// tokenAuthMu guards the field below it.
+	tokenAuthMu sync.Mutex
+	_tokenAuth  *authentication.TokenAuthenticator
 }
 
 // newAuthContext creates a new authentication context for srv.
@@ -42,6 +47,13 @@ func newAuthContext(srv *Server) *authContext {
 // by choosing the right kind of authentication for the given
 // tag.
 func (ctxt *authContext) Authenticate(entityFinder authentication.EntityFinder, tag names.Tag, req params.LoginRequest) (state.Entity, error) {
+	if req.Token != "" {
+		auth, err := ctxt.tokenAuth()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return auth.Authenticate(entityFinder, tag, req)
+	}
 	auth, err := ctxt.authenticatorForTag(tag)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -85,6 +97,35 @@ func (ctxt *authContext) macaroonAuth() (authentication.EntityAuthenticator, err
 	return ctxt._macaroonAuth, nil
 }
 
+// tokenAuth returns an authenticator that can authenticate token-based
+// logins.
+func (ctxt *authContext) tokenAuth() (*authentication.TokenAuthenticator, error) {
+	ctxt.tokenAuthMu.Lock()
+	defer ctxt.tokenAuthMu.Unlock()
+	if ctxt._tokenAuth == nil {
+		st := ctxt.srv.statePool.SystemState()
+		key, err := st.AuthTokenKey()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ctxt._tokenAuth = &authentication.TokenAuthenticator{
+			Key:    key,
+			Tokens: st,
+			Clock:  clock.WallClock,
+		}
+	}
+	return ctxt._tokenAuth, nil
+}
+
+// tokenClaims verifies the given token and returns its claims.
+func (ctxt *authContext) tokenClaims(token string) (authentication.TokenClaims, error) {
+	auth, err := ctxt.tokenAuth()
+	if err != nil {
+		return authentication.TokenClaims{}, errors.Trace(err)
+	}
+	return authentication.ParseToken(auth.Key, token, auth.Clock.Now())
+}
+
 var errMacaroonAuthNotConfigured = errors.New("macaroon authentication is not configured")
 
 // newMacaroonAuth returns an authenticator that can authenticate
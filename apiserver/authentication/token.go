@@ -0,0 +1,171 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// TokenClaims holds the claims made by an auth token. Tokens are
+// encoded as JSON Web Tokens signed with HMAC-SHA256, so the field
+// names follow that format.
+type TokenClaims struct {
+	// Id identifies the token's record in state.
+	Id string `json:"jti"`
+
+	// User holds the tag of the user the token acts for.
+	User string `json:"sub"`
+
+	// EnvUUID identifies the environment the token may be used with.
+	EnvUUID string `json:"env"`
+
+	// Scopes holds the facades, or facade methods, that the token
+	// permits calls to.
+	Scopes []string `json:"scope"`
+
+	// Issued and Expires hold when the token was issued and when it
+	// stops being valid, in seconds since the epoch.
+	Issued  int64 `json:"iat"`
+	Expires int64 `json:"exp"`
+}
+
+// tokenHeader is the encoded header of every token.
+var tokenHeader = encodeTokenSegment([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// SignToken returns a token making the given claims, signed with key.
+func SignToken(key []byte, claims TokenClaims) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	payload := tokenHeader + "." + encodeTokenSegment(data)
+	return payload + "." + encodeTokenSegment(tokenSignature(key, payload)), nil
+}
+
+// ParseToken verifies that token was signed with key and has not
+// expired at the given time, and returns its claims.
+func ParseToken(key []byte, token string, now time.Time) (TokenClaims, error) {
+	var claims TokenClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return claims, errors.Annotate(common.ErrBadCreds, "malformed token")
+	}
+	signature, err := decodeTokenSegment(parts[2])
+	if err != nil {
+		return claims, errors.Annotate(common.ErrBadCreds, "malformed token")
+	}
+	if !hmac.Equal(signature, tokenSignature(key, parts[0]+"."+parts[1])) {
+		return claims, errors.Annotate(common.ErrBadCreds, "invalid token signature")
+	}
+	data, err := decodeTokenSegment(parts[1])
+	if err != nil {
+		return claims, errors.Annotate(common.ErrBadCreds, "malformed token")
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return claims, errors.Annotate(common.ErrBadCreds, "malformed token")
+	}
+	if now.Unix() >= claims.Expires {
+		return claims, errors.Annotate(common.ErrBadCreds, "token expired")
+	}
+	return claims, nil
+}
+
+// encodeTokenSegment encodes data as unpadded URL-safe base64, as
+// used for each segment of a token.
+func encodeTokenSegment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
+
+func decodeTokenSegment(s string) ([]byte, error) {
+	if n := len(s) % 4; n != 0 {
+		s += strings.Repeat("=", 4-n)
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+func tokenSignature(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// TokenGetter is the interface used by TokenAuthenticator to get the
+// record of a token.
+type TokenGetter interface {
+	AuthToken(id string) (state.AuthToken, error)
+}
+
+// TokenAuthenticator performs authentication for users using signed
+// bearer tokens, as issued by the AuthToken facade. A token only
+// authenticates the user it was issued for, and only until it expires
+// or is revoked.
+type TokenAuthenticator struct {
+	// Key holds the key that tokens are signed with.
+	Key []byte
+
+	// Tokens is used to check that a token has not been revoked.
+	Tokens TokenGetter
+
+	// Clock is used to check whether a token has expired.
+	Clock clock.Clock
+}
+
+var _ EntityAuthenticator = (*TokenAuthenticator)(nil)
+
+// disableable is implemented by entities, such as users, that can be
+// disabled. A token does not authenticate a disabled user.
+type disableable interface {
+	IsDisabled() bool
+}
+
+// Authenticate authenticates the user named by the token in the login
+// request. If a tag is also given, it must match the token's user.
+func (a *TokenAuthenticator) Authenticate(entityFinder EntityFinder, tag names.Tag, req params.LoginRequest) (state.Entity, error) {
+	claims, err := ParseToken(a.Key, req.Token, a.Clock.Now())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if tag != nil && tag.String() != claims.User {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	token, err := a.Tokens.AuthToken(claims.Id)
+	if errors.IsNotFound(err) {
+		return nil, errors.Trace(common.ErrBadCreds)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if token.Revoked {
+		return nil, errors.Annotate(common.ErrBadCreds, "token revoked")
+	}
+	if token.User != claims.User || token.EnvUUID != claims.EnvUUID {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	userTag, err := names.ParseUserTag(claims.User)
+	if err != nil {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	entity, err := entityFinder.FindEntity(userTag)
+	if errors.IsNotFound(err) {
+		return nil, errors.Trace(common.ErrBadCreds)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if user, ok := entity.(disableable); ok && user.IsDisabled() {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	return entity, nil
+}
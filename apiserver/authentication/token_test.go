@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type tokenAuthenticatorSuite struct {
+	coretesting.BaseSuite
+	clock  *coretesting.Clock
+	key    []byte
+	tokens fakeTokenGetter
+	user   *fakeUser
+	auth   *authentication.TokenAuthenticator
+}
+
+var _ = gc.Suite(&tokenAuthenticatorSuite{})
+
+func (s *tokenAuthenticatorSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = coretesting.NewClock(time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC))
+	s.key = []byte("0123456789abcdef0123456789abcdef")
+	s.tokens = fakeTokenGetter{
+		"id": {Id: "id", User: "user-bob", EnvUUID: "uuid"},
+	}
+	s.user = &fakeUser{tag: names.NewUserTag("bob")}
+	s.auth = &authentication.TokenAuthenticator{
+		Key:    s.key,
+		Tokens: s.tokens,
+		Clock:  s.clock,
+	}
+}
+
+func (s *tokenAuthenticatorSuite) claims() authentication.TokenClaims {
+	return authentication.TokenClaims{
+		Id:      "id",
+		User:    "user-bob",
+		EnvUUID: "uuid",
+		Scopes:  []string{"Client.FullStatus"},
+		Issued:  s.clock.Now().Unix(),
+		Expires: s.clock.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func (s *tokenAuthenticatorSuite) sign(c *gc.C, claims authentication.TokenClaims) string {
+	token, err := authentication.SignToken(s.key, claims)
+	c.Assert(err, jc.ErrorIsNil)
+	return token
+}
+
+func (s *tokenAuthenticatorSuite) authenticate(token string, tag names.Tag) (state.Entity, error) {
+	finder := entityFinder{s.user}
+	return s.auth.Authenticate(finder, tag, params.LoginRequest{Token: token})
+}
+
+func (s *tokenAuthenticatorSuite) TestParseToken(c *gc.C) {
+	claims := s.claims()
+	token := s.sign(c, claims)
+	parsed, err := authentication.ParseToken(s.key, token, s.clock.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, jc.DeepEquals, claims)
+
+	_, err = authentication.ParseToken([]byte("another key"), token, s.clock.Now())
+	c.Assert(err, gc.ErrorMatches, "invalid token signature: invalid entity name or password")
+
+	_, err = authentication.ParseToken(s.key, token, s.clock.Now().Add(time.Hour))
+	c.Assert(err, gc.ErrorMatches, "token expired: invalid entity name or password")
+
+	_, err = authentication.ParseToken(s.key, "not.a.token", s.clock.Now())
+	c.Assert(err, gc.ErrorMatches, "malformed token: invalid entity name or password")
+}
+
+func (s *tokenAuthenticatorSuite) TestAuthenticate(c *gc.C) {
+	token := s.sign(c, s.claims())
+	entity, err := s.authenticate(token, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity, gc.Equals, s.user)
+
+	entity, err = s.authenticate(token, names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity, gc.Equals, s.user)
+}
+
+func (s *tokenAuthenticatorSuite) TestAuthenticateTagMismatch(c *gc.C) {
+	token := s.sign(c, s.claims())
+	_, err := s.authenticate(token, names.NewUserTag("mary"))
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthenticatorSuite) TestAuthenticateRevoked(c *gc.C) {
+	s.tokens["id"] = state.AuthToken{Id: "id", User: "user-bob", EnvUUID: "uuid", Revoked: true}
+	token := s.sign(c, s.claims())
+	_, err := s.authenticate(token, nil)
+	c.Assert(err, gc.ErrorMatches, "token revoked: invalid entity name or password")
+}
+
+func (s *tokenAuthenticatorSuite) TestAuthenticateUnknownToken(c *gc.C) {
+	claims := s.claims()
+	claims.Id = "unknown"
+	_, err := s.authenticate(s.sign(c, claims), nil)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthenticatorSuite) TestAuthenticateDisabledUser(c *gc.C) {
+	s.user.disabled = true
+	_, err := s.authenticate(s.sign(c, s.claims()), nil)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+type fakeTokenGetter map[string]state.AuthToken
+
+func (f fakeTokenGetter) AuthToken(id string) (state.AuthToken, error) {
+	token, ok := f[id]
+	if !ok {
+		return state.AuthToken{}, errors.NotFoundf("auth token %q", id)
+	}
+	return token, nil
+}
+
+type fakeUser struct {
+	state.Entity
+	tag      names.UserTag
+	disabled bool
+}
+
+func (u *fakeUser) Tag() names.Tag {
+	return u.tag
+}
+
+func (u *fakeUser) IsDisabled() bool {
+	return u.disabled
+}
@@ -0,0 +1,164 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The authtoken package implements the API facade used to issue and
+// revoke the bearer tokens that let external systems call the API on
+// behalf of a user, without knowing the user's password.
+package authtoken
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.authtoken")
+
+func init() {
+	common.RegisterStandardFacade("AuthToken", 1, NewAPI)
+}
+
+// maxTokenLifetime is the longest time for which a token may be
+// issued.
+const maxTokenLifetime = 365 * 24 * time.Hour
+
+// validScope matches the facades, and facade methods, that a token's
+// scopes may name.
+var validScope = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*(\.[A-Z][A-Za-z0-9]*)?$`)
+
+// API implements the AuthToken facade.
+type API struct {
+	st   *state.State
+	user names.UserTag
+}
+
+// NewAPI returns a new AuthToken API, which issues tokens for the
+// logged in user.
+func NewAPI(st *state.State, _ *common.Resources, authorizer common.Authorizer) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	// Since we know this is a user tag (because AuthClient is true),
+	// we just do the type assertion to the UserTag.
+	user, _ := authorizer.GetAuthTag().(names.UserTag)
+	return &API{st: st, user: user}, nil
+}
+
+// Issue issues a token that lets the logged in user call the given
+// scopes of the API in this environment until it expires.
+func (api *API) Issue(args params.IssueAuthToken) (params.IssuedAuthToken, error) {
+	var result params.IssuedAuthToken
+	if len(args.Scopes) == 0 {
+		return result, errors.NotValidf("token with no scopes")
+	}
+	for _, scope := range args.Scopes {
+		if !validScope.MatchString(scope) {
+			return result, errors.NotValidf("scope %q", scope)
+		}
+	}
+	now := time.Now()
+	if !args.Expires.After(now) {
+		return result, errors.NotValidf("expiry time in the past")
+	}
+	if args.Expires.After(now.Add(maxTokenLifetime)) {
+		return result, errors.NotValidf("expiry time more than %v away", maxTokenLifetime)
+	}
+	// Expired tokens can never be used again, so take the chance to
+	// discard their records.
+	if err := api.st.RemoveExpiredAuthTokens(now); err != nil {
+		logger.Warningf("%v", err)
+	}
+	key, err := api.st.AuthTokenKey()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	token, err := api.st.AddAuthToken(api.user, args.Scopes, args.Expires)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	signed, err := authentication.SignToken(key, authentication.TokenClaims{
+		Id:      token.Id,
+		User:    token.User,
+		EnvUUID: token.EnvUUID,
+		Scopes:  token.Scopes,
+		Issued:  token.Issued.Unix(),
+		Expires: token.Expires.Unix(),
+	})
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	logger.Infof("issued auth token %s for %s with scopes %v", token.Id, token.User, token.Scopes)
+	return params.IssuedAuthToken{
+		Id:    token.Id,
+		Token: signed,
+	}, nil
+}
+
+// Tokens returns the tokens issued for the logged in user in this
+// environment.
+func (api *API) Tokens() (params.AuthTokens, error) {
+	tokens, err := api.st.AuthTokens(api.user)
+	if err != nil {
+		return params.AuthTokens{}, errors.Trace(err)
+	}
+	result := params.AuthTokens{
+		Tokens: make([]params.AuthToken, len(tokens)),
+	}
+	for i, token := range tokens {
+		result.Tokens[i] = params.AuthToken{
+			Id:      token.Id,
+			User:    token.User,
+			Scopes:  token.Scopes,
+			Issued:  token.Issued,
+			Expires: token.Expires,
+			Revoked: token.Revoked,
+		}
+	}
+	return result, nil
+}
+
+// Revoke revokes the tokens with the given ids. Users may revoke
+// their own tokens; system administrators may revoke any token.
+func (api *API) Revoke(args params.AuthTokenIds) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		result.Results[i].Error = common.ServerError(api.revoke(id))
+	}
+	return result, nil
+}
+
+func (api *API) revoke(id string) error {
+	token, err := api.st.AuthToken(id)
+	if errors.IsNotFound(err) {
+		return common.ErrPerm
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if token.EnvUUID != api.st.EnvironUUID() {
+		return common.ErrPerm
+	}
+	if token.User != api.user.String() {
+		isAdmin, err := api.st.IsSystemAdministrator(api.user)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !isAdmin {
+			return common.ErrPerm
+		}
+	}
+	if err := api.st.RevokeAuthToken(id); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("revoked auth token %s for %s", id, token.User)
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authtoken_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/authtoken"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type authTokenSuite struct {
+	jujutesting.JujuConnSuite
+
+	api *authtoken.API
+}
+
+var _ = gc.Suite(&authTokenSuite{})
+
+func (s *authTokenSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.api = s.newAPI(c, s.AdminUserTag(c))
+}
+
+func (s *authTokenSuite) newAPI(c *gc.C, tag names.Tag) *authtoken.API {
+	authorizer := apiservertesting.FakeAuthorizer{Tag: tag}
+	api, err := authtoken.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *authTokenSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("0"),
+	}
+	api, err := authtoken.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *authTokenSuite) TestIssue(c *gc.C) {
+	expires := time.Now().Add(time.Hour)
+	issued, err := s.api.Issue(params.IssueAuthToken{
+		Scopes:  []string{"Client.FullStatus", "Pinger"},
+		Expires: expires,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	key, err := s.State.AuthTokenKey()
+	c.Assert(err, jc.ErrorIsNil)
+	claims, err := authentication.ParseToken(key, issued.Token, time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claims.Id, gc.Equals, issued.Id)
+	c.Assert(claims.User, gc.Equals, s.AdminUserTag(c).String())
+	c.Assert(claims.EnvUUID, gc.Equals, s.State.EnvironUUID())
+	c.Assert(claims.Scopes, jc.DeepEquals, []string{"Client.FullStatus", "Pinger"})
+	c.Assert(claims.Expires, gc.Equals, expires.Unix())
+
+	tokens, err := s.api.Tokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens.Tokens, gc.HasLen, 1)
+	c.Assert(tokens.Tokens[0].Id, gc.Equals, issued.Id)
+	c.Assert(tokens.Tokens[0].Scopes, jc.DeepEquals, []string{"Client.FullStatus", "Pinger"})
+	c.Assert(tokens.Tokens[0].Revoked, jc.IsFalse)
+}
+
+func (s *authTokenSuite) TestIssueInvalid(c *gc.C) {
+	now := time.Now()
+	for i, test := range []struct {
+		args     params.IssueAuthToken
+		errMatch string
+	}{{
+		args:     params.IssueAuthToken{Expires: now.Add(time.Hour)},
+		errMatch: "token with no scopes not valid",
+	}, {
+		args:     params.IssueAuthToken{Scopes: []string{"Client.Full.Status"}, Expires: now.Add(time.Hour)},
+		errMatch: `scope "Client.Full.Status" not valid`,
+	}, {
+		args:     params.IssueAuthToken{Scopes: []string{"Client"}, Expires: now.Add(-time.Hour)},
+		errMatch: "expiry time in the past not valid",
+	}, {
+		args:     params.IssueAuthToken{Scopes: []string{"Client"}, Expires: now.Add(2 * 365 * 24 * time.Hour)},
+		errMatch: "expiry time more than .* away not valid",
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.api.Issue(test.args)
+		c.Check(err, gc.ErrorMatches, test.errMatch)
+	}
+}
+
+func (s *authTokenSuite) TestRevoke(c *gc.C) {
+	issued, err := s.api.Issue(params.IssueAuthToken{
+		Scopes:  []string{"Client"},
+		Expires: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Another user may not revoke the token.
+	user := s.Factory.MakeUser(c, &factory.UserParams{})
+	other := s.newAPI(c, user.Tag())
+	results, err := other.Revoke(params.AuthTokenIds{Ids: []string{issued.Id, "missing"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	results, err = s.api.Revoke(params.AuthTokenIds{Ids: []string{issued.Id}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	token, err := s.State.AuthToken(issued.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Revoked, jc.IsTrue)
+}
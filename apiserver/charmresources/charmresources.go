@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The charmresources package implements the API facade used to list
+// the resources attached to services, and to pin them to revisions.
+// Resource content is uploaded and downloaded through the API server's
+// HTTP endpoints.
+package charmresources
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("CharmResources", 1, NewAPI)
+}
+
+// API implements the CharmResources facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new CharmResources API.
+func NewAPI(st *state.State, _ *common.Resources, authorizer common.Authorizer) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+// ServiceResources returns the revisions of their resources that the
+// given services' units use.
+func (api *API) ServiceResources(args params.Entities) (params.ServiceResourcesResults, error) {
+	result := params.ServiceResourcesResults{
+		Results: make([]params.ServiceResourcesResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		resources, err := api.serviceResources(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Resources = resources
+	}
+	return result, nil
+}
+
+func (api *API) serviceResources(tag string) ([]params.Resource, error) {
+	serviceTag, err := names.ParseServiceTag(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := api.st.Service(serviceTag.Id()); err != nil {
+		return nil, errors.Trace(err)
+	}
+	resources, err := api.st.ServiceResources(serviceTag.Id())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]params.Resource, len(resources))
+	for i, res := range resources {
+		result[i] = ResourceParams(res.Resource, res.Pinned)
+	}
+	return result, nil
+}
+
+// PinResources pins services' resources to the given revisions, or
+// unpins them if no revision is given.
+func (api *API) PinResources(args params.PinResources) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		err := api.pinResource(arg)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (api *API) pinResource(arg params.PinResource) error {
+	serviceTag, err := names.ParseServiceTag(arg.ServiceTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if arg.Revision == 0 {
+		return api.st.UnpinResource(serviceTag.Id(), arg.Name)
+	}
+	return api.st.PinResource(serviceTag.Id(), arg.Name, arg.Revision)
+}
+
+// ResourceParams returns the API representation of a resource.
+func ResourceParams(res state.Resource, pinned bool) params.Resource {
+	return params.Resource{
+		ServiceTag: names.NewServiceTag(res.ServiceName).String(),
+		Name:       res.Name,
+		Type:       string(res.Type),
+		Revision:   res.Revision,
+		Size:       res.Size,
+		SHA256:     res.SHA256,
+		ImageRef:   res.ImageRef,
+		Added:      res.Added,
+		Pinned:     pinned,
+	}
+}
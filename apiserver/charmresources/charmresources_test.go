@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmresources_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/charmresources"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type charmResourcesSuite struct {
+	jujutesting.JujuConnSuite
+	api *charmresources.API
+}
+
+var _ = gc.Suite(&charmResourcesSuite{})
+
+func (s *charmResourcesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	authorizer := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = charmresources.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *charmResourcesSuite) addImage(c *gc.C, ref string) state.Resource {
+	res, err := s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "mysql",
+		Name:        "server-image",
+		Type:        state.ResourceTypeOCIImage,
+		ImageRef:    ref,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return res
+}
+
+func (s *charmResourcesSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: names.NewUnitTag("mysql/0"),
+	}
+	api, err := charmresources.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *charmResourcesSuite) TestServiceResources(c *gc.C) {
+	s.addImage(c, "mysql:5.6")
+	res := s.addImage(c, "mysql:5.7")
+
+	results, err := s.api.ServiceResources(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "service-mysql"},
+			{Tag: "service-wordpress"},
+			{Tag: "unit-mysql-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0], jc.DeepEquals, params.ServiceResourcesResult{
+		Resources: []params.Resource{{
+			ServiceTag: "service-mysql",
+			Name:       "server-image",
+			Type:       "oci-image",
+			Revision:   2,
+			ImageRef:   "mysql:5.7",
+			Added:      res.Added,
+		}},
+	})
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `service "wordpress" not found`)
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `"unit-mysql-0" is not a valid service tag`)
+}
+
+func (s *charmResourcesSuite) TestPinResources(c *gc.C) {
+	s.addImage(c, "mysql:5.6")
+	s.addImage(c, "mysql:5.7")
+
+	results, err := s.api.PinResources(params.PinResources{
+		Args: []params.PinResource{
+			{ServiceTag: "service-mysql", Name: "server-image", Revision: 1},
+			{ServiceTag: "service-mysql", Name: "server-image", Revision: 3},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `cannot pin resource "server-image" of service "mysql": revision 3 not found`)
+	current, err := s.State.ServiceResource("mysql", "server-image")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, 1)
+	c.Assert(current.Pinned, jc.IsTrue)
+
+	// A zero revision unpins.
+	results, err = s.api.PinResources(params.PinResources{
+		Args: []params.PinResource{{ServiceTag: "service-mysql", Name: "server-image"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	current, err = s.State.ServiceResource("mysql", "server-image")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, 2)
+}
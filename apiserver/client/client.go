@@ -236,6 +236,35 @@ func (c *Client) PrivateAddress(p params.PrivateAddress) (results params.Private
 
 }
 
+// SSHHostKeys returns the SSH host keys recorded for the specified
+// machine, or for the machine the specified unit is assigned to.
+func (c *Client) SSHHostKeys(p params.SSHHostKeys) (results params.SSHHostKeysResults, err error) {
+	machineId := p.Target
+	switch {
+	case names.IsValidMachine(p.Target):
+	case names.IsValidUnit(p.Target):
+		unit, err := c.api.stateAccessor.Unit(p.Target)
+		if err != nil {
+			return results, err
+		}
+		machineId, err = unit.AssignedMachineId()
+		if err != nil {
+			return results, err
+		}
+	default:
+		return results, errors.Errorf("unknown unit or machine %q", p.Target)
+	}
+	machine, err := c.api.stateAccessor.Machine(machineId)
+	if err != nil {
+		return results, err
+	}
+	keys, err := machine.SSHHostKeys()
+	if err != nil {
+		return results, err
+	}
+	return params.SSHHostKeysResults{Keys: keys}, nil
+}
+
 // ServiceExpose changes the juju-managed firewall to expose any ports that
 // were also explicitly marked by units as open.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
@@ -813,7 +842,11 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 		}
 		switch arg.Action {
 		case params.AddEnvUser:
-			_, err := c.api.stateAccessor.AddEnvironmentUser(user, createdBy, "")
+			access := state.EnvironmentAdminAccess
+			if arg.Access != "" {
+				access = state.EnvironmentAccess(arg.Access)
+			}
+			_, err := c.api.stateAccessor.AddEnvironmentUserWithAccess(user, createdBy, "", access)
 			if err != nil {
 				err = errors.Annotate(err, "could not share environment")
 				result.Results[i].Error = common.ServerError(err)
@@ -824,6 +857,18 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 				err = errors.Annotate(err, "could not unshare environment")
 				result.Results[i].Error = common.ServerError(err)
 			}
+		case params.GrantEnvUser:
+			err := c.grantEnvironmentAccess(user, createdBy, state.EnvironmentAccess(arg.Access))
+			if err != nil {
+				err = errors.Annotate(err, "could not grant environment access")
+				result.Results[i].Error = common.ServerError(err)
+			}
+		case params.RevokeEnvUser:
+			err := c.revokeEnvironmentAccess(user, state.EnvironmentAccess(arg.Access))
+			if err != nil {
+				err = errors.Annotate(err, "could not revoke environment access")
+				result.Results[i].Error = common.ServerError(err)
+			}
 		default:
 			result.Results[i].Error = common.ServerError(errors.Errorf("unknown action %q", arg.Action))
 		}
@@ -831,6 +876,62 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 	return result, nil
 }
 
+// grantEnvironmentAccess gives the user the given level of access to
+// the environment, adding them to the environment if necessary.
+func (c *Client) grantEnvironmentAccess(user, createdBy names.UserTag, access state.EnvironmentAccess) error {
+	if err := access.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	envUser, err := c.api.stateAccessor.EnvironmentUser(user)
+	if errors.IsNotFound(err) {
+		_, err = c.api.stateAccessor.AddEnvironmentUserWithAccess(user, createdBy, "", access)
+		return errors.Trace(err)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return envUser.SetAccess(access)
+}
+
+// revokeEnvironmentAccess takes the given level of access away from
+// the user, leaving them with the level below it. Revoking read access,
+// or revoking without specifying a level, removes the user from the
+// environment.
+func (c *Client) revokeEnvironmentAccess(user names.UserTag, access state.EnvironmentAccess) error {
+	var remaining state.EnvironmentAccess
+	switch access {
+	case "", state.EnvironmentReadAccess:
+		return c.api.stateAccessor.RemoveEnvironmentUser(user)
+	case state.EnvironmentWriteAccess:
+		remaining = state.EnvironmentReadAccess
+	case state.EnvironmentAdminAccess:
+		remaining = state.EnvironmentWriteAccess
+	default:
+		return access.Validate()
+	}
+	envUser, err := c.api.stateAccessor.EnvironmentUser(user)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !accessIncludes(envUser.Access(), access) {
+		// The user does not have the access being revoked.
+		return nil
+	}
+	return envUser.SetAccess(remaining)
+}
+
+var accessOrder = map[state.EnvironmentAccess]int{
+	state.EnvironmentReadAccess:  0,
+	state.EnvironmentWriteAccess: 1,
+	state.EnvironmentAdminAccess: 2,
+}
+
+// accessIncludes reports whether the held level of access includes
+// the wanted level.
+func accessIncludes(held, wanted state.EnvironmentAccess) bool {
+	return accessOrder[held] >= accessOrder[wanted]
+}
+
 // EnvUserInfo returns information on all users in the environment.
 func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 	var results params.EnvUserInfoResults
@@ -860,6 +961,7 @@ func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 				CreatedBy:      user.CreatedBy(),
 				DateCreated:    user.DateCreated(),
 				LastConnection: lastConn,
+				Access:         string(user.Access()),
 			},
 		})
 	}
@@ -175,6 +175,7 @@ func (s *serverSuite) TestEnvUsersInfo(c *gc.C) {
 		r.info.CreatedBy = owner.UserName()
 		r.info.DateCreated = r.user.DateCreated()
 		r.info.LastConnection = lastConnPointer(c, r.user)
+		r.info.Access = "admin"
 		expected.Results = append(expected.Results, params.EnvUserInfoResult{Result: r.info})
 	}
 
@@ -433,6 +434,92 @@ func (s *serverSuite) TestShareEnvironmentInvalidAction(c *gc.C) {
 	c.Assert(result.Results[0].Error, gc.ErrorMatches, expectedErr)
 }
 
+func (s *serverSuite) shareEnvironment(c *gc.C, user names.UserTag, action params.EnvironAction, access string) error {
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: user.String(),
+			Action:  action,
+			Access:  access,
+		}}}
+	result, err := s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	return result.OneError()
+}
+
+func (s *serverSuite) assertEnvironmentAccess(c *gc.C, user names.UserTag, access state.EnvironmentAccess) {
+	envUser, err := s.State.EnvironmentUser(user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, access)
+}
+
+func (s *serverSuite) TestShareEnvironmentAddWithAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	err := s.shareEnvironment(c, user.UserTag(), params.AddEnvUser, "read")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentReadAccess)
+}
+
+func (s *serverSuite) TestShareEnvironmentGrant(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	err := s.shareEnvironment(c, user.UserTag(), params.GrantEnvUser, "write")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentWriteAccess)
+
+	err = s.shareEnvironment(c, user.UserTag(), params.GrantEnvUser, "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentAdminAccess)
+
+	err = s.shareEnvironment(c, user.UserTag(), params.GrantEnvUser, "read")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentReadAccess)
+}
+
+func (s *serverSuite) TestShareEnvironmentGrantInvalidAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	err := s.shareEnvironment(c, user.UserTag(), params.GrantEnvUser, "")
+	c.Assert(err, gc.ErrorMatches, `could not grant environment access: environment access "" not valid`)
+	err = s.shareEnvironment(c, user.UserTag(), params.GrantEnvUser, "superuser")
+	c.Assert(err, gc.ErrorMatches, `could not grant environment access: environment access "superuser" not valid`)
+}
+
+func (s *serverSuite) TestShareEnvironmentRevoke(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar"})
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentAdminAccess)
+
+	err := s.shareEnvironment(c, user.UserTag(), params.RevokeEnvUser, "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentWriteAccess)
+
+	// Revoking admin access again leaves the user's access unchanged.
+	err = s.shareEnvironment(c, user.UserTag(), params.RevokeEnvUser, "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentWriteAccess)
+
+	err = s.shareEnvironment(c, user.UserTag(), params.RevokeEnvUser, "write")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvironmentAccess(c, user.UserTag(), state.EnvironmentReadAccess)
+
+	err = s.shareEnvironment(c, user.UserTag(), params.RevokeEnvUser, "read")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *serverSuite) TestShareEnvironmentRevokeAll(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar"})
+	err := s.shareEnvironment(c, user.UserTag(), params.RevokeEnvUser, "")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *serverSuite) TestShareEnvironmentRevokeMissingUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	err := s.shareEnvironment(c, user.UserTag(), params.RevokeEnvUser, "write")
+	c.Assert(err, gc.ErrorMatches, `could not revoke environment access: environment user "foobar@local" not found`)
+}
+
 func (s *serverSuite) TestSetEnvironAgentVersion(c *gc.C) {
 	args := params.SetEnvironAgentVersion{
 		Version: version.MustParse("9.8.7"),
@@ -2524,6 +2611,18 @@ func (s *clientSuite) TestClientSetEnvironmentConstraints(c *gc.C) {
 	c.Assert(obtained, gc.DeepEquals, cons)
 }
 
+func (s *clientSuite) TestClientSetEnvironmentConstraintsNewKeys(c *gc.C) {
+	cons, err := constraints.Parse("root-disk-source=ssd virt-type=hvm instance-role=web")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.APIState.Client().SetEnvironmentConstraints(cons)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Ensure the new keys survive the round trip through the API.
+	obtained, err := s.APIState.Client().GetEnvironmentConstraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, gc.DeepEquals, cons)
+}
+
 func (s *clientSuite) assertSetEnvironmentConstraints(c *gc.C) {
 	// Set constraints for the environment.
 	cons, err := constraints.Parse("mem=4096", "cpu-cores=2")
@@ -2670,6 +2769,29 @@ func (s *clientSuite) TestClientPrivateAddressUnit(c *gc.C) {
 	c.Assert(addr, gc.Equals, "private")
 }
 
+func (s *clientSuite) TestClientSSHHostKeysErrors(c *gc.C) {
+	s.setUpScenario(c)
+	_, err := s.APIState.Client().SSHHostKeys("wordpress")
+	c.Assert(err, gc.ErrorMatches, `unknown unit or machine "wordpress"`)
+	_, err = s.APIState.Client().SSHHostKeys("0")
+	c.Assert(err, gc.ErrorMatches, `ssh host keys for machine "0" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *clientSuite) TestClientSSHHostKeys(c *gc.C) {
+	s.setUpScenario(c)
+
+	m1, err := s.State.Machine("1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m1.SetSSHHostKeys([]string{"ssh-rsa rsa", "ssh-ed25519 ed25519"})
+	c.Assert(err, jc.ErrorIsNil)
+	for _, target := range []string{"1", "wordpress/0"} {
+		keys, err := s.APIState.Client().SSHHostKeys(target)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(keys, jc.DeepEquals, []string{"ssh-rsa rsa", "ssh-ed25519 ed25519"})
+	}
+}
+
 func (s *serverSuite) TestClientEnvironmentGet(c *gc.C) {
 	envConfig, err := s.State.EnvironConfig()
 	c.Assert(err, jc.ErrorIsNil)
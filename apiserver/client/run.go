@@ -12,6 +12,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/utils"
+	"github.com/juju/utils/exec"
 	"github.com/juju/utils/set"
 
 	"github.com/juju/juju/agent"
@@ -119,7 +120,8 @@ func (c *Client) Run(run params.RunParams) (results params.RunResults, err error
 		execParam := remoteParamsForMachine(machine, command, run.Timeout)
 		params = append(params, execParam)
 	}
-	return ParallelExecute(c.getDataDir(), params), nil
+	started := time.Now()
+	return c.recordRunResults(run.Commands, started, ParallelExecuteLimited(c.getDataDir(), run.MaxConcurrency, params)), nil
 }
 
 // RunOnAllMachines attempts to run the specified command on all the machines.
@@ -137,7 +139,61 @@ func (c *Client) RunOnAllMachines(run params.RunParams) (params.RunResults, erro
 	for _, machine := range machines {
 		params = append(params, remoteParamsForMachine(machine, command, run.Timeout))
 	}
-	return ParallelExecute(c.getDataDir(), params), nil
+	started := time.Now()
+	return c.recordRunResults(run.Commands, started, ParallelExecuteLimited(c.getDataDir(), run.MaxConcurrency, params)), nil
+}
+
+// RunResults returns the results of an earlier Run or RunOnAllMachines
+// call, identified by the Id returned with them.
+func (c *Client) RunResults(arg params.RunResultsId) (params.RunResults, error) {
+	recorded, err := c.api.stateAccessor.RunResults(arg.Id)
+	if err != nil {
+		return params.RunResults{}, errors.Trace(err)
+	}
+	results := params.RunResults{
+		Id:      recorded.Id(),
+		Results: make([]params.RunResult, len(recorded.Results())),
+	}
+	for i, result := range recorded.Results() {
+		results.Results[i] = params.RunResult{
+			ExecResponse: exec.ExecResponse{
+				Stdout: result.Stdout,
+				Stderr: result.Stderr,
+				Code:   result.Code,
+			},
+			MachineId: result.MachineId,
+			UnitId:    result.UnitId,
+			Error:     result.Error,
+		}
+	}
+	return results, nil
+}
+
+// recordRunResults records the results of running the given commands
+// in state, so that they can be retrieved later with RunResults, and
+// returns them with the id they were recorded under. Only the first
+// state.MaxRunResultOutput bytes of each target's output are recorded,
+// though the results returned are complete. Failing to record the
+// results is not fatal, as the commands have already been run.
+func (c *Client) recordRunResults(commands string, started time.Time, results params.RunResults) params.RunResults {
+	recorded := make([]state.RunResult, len(results.Results))
+	for i, result := range results.Results {
+		recorded[i] = state.RunResult{
+			MachineId: result.MachineId,
+			UnitId:    result.UnitId,
+			Stdout:    result.Stdout,
+			Stderr:    result.Stderr,
+			Code:      result.Code,
+			Error:     result.Error,
+		}
+	}
+	id, err := c.api.stateAccessor.AddRunResults(commands, started, recorded)
+	if err != nil {
+		logger.Warningf("cannot record run results: %v", err)
+		return results
+	}
+	results.Id = id
+	return results
 }
 
 // RemoteExec extends the standard ssh.ExecParams by providing the machine and
@@ -152,7 +208,19 @@ type RemoteExec struct {
 // ParallelExecute executes all of the requests defined in the params,
 // using the system identity stored in the dataDir.
 func ParallelExecute(dataDir string, runParams []*RemoteExec) params.RunResults {
+	return ParallelExecuteLimited(dataDir, 0, runParams)
+}
+
+// ParallelExecuteLimited executes all of the requests defined in the
+// params, as ParallelExecute does, but executes at most maxConcurrency
+// requests at once. If maxConcurrency is not positive, all requests are
+// executed at once.
+func ParallelExecuteLimited(dataDir string, maxConcurrency int, runParams []*RemoteExec) params.RunResults {
 	logger.Debugf("exec %#v", runParams)
+	var slots chan struct{}
+	if maxConcurrency > 0 {
+		slots = make(chan struct{}, maxConcurrency)
+	}
 	var outstanding sync.WaitGroup
 	var lock sync.Mutex
 	var result []params.RunResult
@@ -162,6 +230,10 @@ func ParallelExecute(dataDir string, runParams []*RemoteExec) params.RunResults
 		logger.Debugf("exec on %s: %#v", param.MachineId, *param)
 		param.IdentityFile = identity
 		go func(param *RemoteExec) {
+			if slots != nil {
+				slots <- struct{}{}
+				defer func() { <-slots }()
+			}
 			response, err := ssh.ExecuteCommandOnMachine(param.ExecParams)
 			logger.Debugf("reponse from %s: %v (err:%v)", param.MachineId, response, err)
 			execResponse := params.RunResult{
@@ -182,7 +254,7 @@ func ParallelExecute(dataDir string, runParams []*RemoteExec) params.RunResults
 
 	outstanding.Wait()
 	sort.Sort(MachineOrder(result))
-	return params.RunResults{result}
+	return params.RunResults{Results: result}
 }
 
 // MachineOrder is used to provide the api to sort the results by the machine
@@ -227,6 +227,29 @@ func (s *runSuite) TestParallelExecuteCopiesAcrossMachineAndUnit(c *gc.C) {
 	c.Assert(result.UnitId, gc.Equals, "unit-id")
 }
 
+func (s *runSuite) TestParallelExecuteLimited(c *gc.C) {
+	s.mockSSH(c, echoInputShowArgs)
+
+	var params []*client.RemoteExec
+	for i := 0; i < 5; i++ {
+		params = append(params, &client.RemoteExec{
+			ExecParams: ssh.ExecParams{
+				Host:    "localhost",
+				Command: "foo",
+				Timeout: testing.LongWait,
+			},
+			MachineId: fmt.Sprint(i),
+		})
+	}
+
+	runResults := client.ParallelExecuteLimited("/some/dir", 2, params)
+	c.Assert(runResults.Results, gc.HasLen, 5)
+	for i, result := range runResults.Results {
+		c.Check(result.Error, gc.Equals, "")
+		c.Check(result.MachineId, gc.Equals, fmt.Sprint(i))
+	}
+}
+
 func (s *runSuite) TestRunOnAllMachines(c *gc.C) {
 	// Make three machines.
 	s.addMachineWithAddress(c, "10.3.2.1")
@@ -256,6 +279,25 @@ func (s *runSuite) TestRunOnAllMachines(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, expectedResults)
 }
 
+func (s *runSuite) TestRunOnAllMachinesRecorded(c *gc.C) {
+	s.addMachineWithAddress(c, "10.3.2.1")
+	s.addMachineWithAddress(c, "10.3.2.2")
+	s.mockSSH(c, echoInput)
+
+	client := s.APIState.Client()
+	results, err := client.RunOnAllMachinesRecorded(params.RunParams{
+		Commands:       "hostname",
+		Timeout:        testing.LongWait,
+		MaxConcurrency: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Id, gc.Not(gc.Equals), "")
+	recorded, err := client.RunResults(results.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(recorded, jc.DeepEquals, results)
+}
+
 func (s *runSuite) TestBlockRunOnAllMachines(c *gc.C) {
 	// Make three machines.
 	s.addMachineWithAddress(c, "10.3.2.1")
@@ -317,6 +359,32 @@ func (s *runSuite) TestRunMachineAndService(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, expectedResults)
 }
 
+func (s *runSuite) TestRunRecorded(c *gc.C) {
+	s.addMachineWithAddress(c, "10.3.2.1")
+	s.mockSSH(c, echoInput)
+
+	client := s.APIState.Client()
+	results, err := client.RunRecorded(params.RunParams{
+		Commands: "hostname",
+		Timeout:  testing.LongWait,
+		Machines: []string{"0"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+
+	// The results are recorded, and can be retrieved later.
+	c.Assert(results.Id, gc.Not(gc.Equals), "")
+	recorded, err := client.RunResults(results.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(recorded, jc.DeepEquals, results)
+}
+
+func (s *runSuite) TestRunResultsNotFound(c *gc.C) {
+	_, err := s.APIState.Client().RunResults("42")
+	c.Assert(err, gc.ErrorMatches, `run results "42" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
 func (s *runSuite) TestBlockRunMachineAndService(c *gc.C) {
 	// Make three machines.
 	s.addMachineWithAddress(c, "10.3.2.1")
@@ -4,6 +4,8 @@
 package client
 
 import (
+	"time"
+
 	"github.com/juju/names"
 	"gopkg.in/juju/charm.v6-unstable"
 
@@ -23,6 +25,7 @@ type Unit interface {
 	IsPrincipal() bool
 	PublicAddress() (network.Address, error)
 	PrivateAddress() (network.Address, error)
+	AssignedMachineId() (string, error)
 	Resolve(retryHooks bool) error
 	AgentHistory() state.StatusHistoryGetter
 }
@@ -57,10 +60,14 @@ type stateInterface interface {
 	LatestPlaceholderCharm(*charm.URL) (*state.Charm, error)
 	AddRelation(...state.Endpoint) (*state.Relation, error)
 	AddEnvironmentUser(user, createdBy names.UserTag, displayName string) (*state.EnvironmentUser, error)
+	AddEnvironmentUserWithAccess(user, createdBy names.UserTag, displayName string, access state.EnvironmentAccess) (*state.EnvironmentUser, error)
+	EnvironmentUser(names.UserTag) (*state.EnvironmentUser, error)
 	RemoveEnvironmentUser(names.UserTag) error
 	Watch() *state.Multiwatcher
 	AbortCurrentUpgrade() error
 	APIHostPorts() ([][]network.HostPort, error)
+	AddRunResults(commands string, started time.Time, results []state.RunResult) (string, error)
+	RunResults(id string) (*state.RunResults, error)
 }
 
 type stateShim struct {
@@ -10,6 +10,7 @@ import (
 	"github.com/juju/juju/apiserver/metricsender"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
+	providercommon "github.com/juju/juju/provider/common"
 	"github.com/juju/juju/state"
 )
 
@@ -112,5 +113,5 @@ func destroyNonManagerMachines(st *state.State, machines []*state.Machine) error
 	if err != nil {
 		return err
 	}
-	return env.StopInstances(ids...)
+	return providercommon.StopInstances(env, ids...)
 }
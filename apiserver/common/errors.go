@@ -85,6 +85,25 @@ func IsDischargeRequiredError(err error) bool {
 	return ok
 }
 
+// RateLimitExceededError is returned when a client exceeds one of the
+// limits the API server applies to its clients.
+type RateLimitExceededError struct {
+	// Limit describes the limit that was exceeded.
+	Limit string
+}
+
+// Error implements the error interface.
+func (e *RateLimitExceededError) Error() string {
+	return "rate limit exceeded: " + e.Limit
+}
+
+// IsRateLimitExceededError reports whether the cause of the error is a
+// *RateLimitExceededError.
+func IsRateLimitExceededError(err error) bool {
+	_, ok := errors.Cause(err).(*RateLimitExceededError)
+	return ok
+}
+
 var (
 	ErrBadId              = stderrors.New("id not found")
 	ErrBadCreds           = stderrors.New("invalid entity name or password")
@@ -165,6 +184,9 @@ func ServerErrorAndStatus(err error) (*params.Error, int) {
 		status = http.StatusForbidden
 	case params.CodeDischargeRequired:
 		status = http.StatusUnauthorized
+	case params.CodeRateLimitExceeded:
+		// http.StatusTooManyRequests
+		status = 429
 	}
 	return err1, status
 }
@@ -209,6 +231,8 @@ func ServerError(err error) *params.Error {
 		code = params.CodeBadRequest
 	case errors.IsMethodNotAllowed(err):
 		code = params.CodeMethodNotAllowed
+	case IsRateLimitExceededError(err):
+		code = params.CodeRateLimitExceeded
 	default:
 		if err, ok := err.(*DischargeRequiredError); ok {
 			code = params.CodeDischargeRequired
@@ -179,6 +179,11 @@ var errorTransformTests = []struct {
 	err:    unhashableError{"foo"},
 	status: http.StatusInternalServerError,
 	code:   "",
+}, {
+	err:        &common.RateLimitExceededError{Limit: "too many logins"},
+	code:       params.CodeRateLimitExceeded,
+	status:     429,
+	helperFunc: params.IsCodeRateLimitExceeded,
 }, {
 	err:        common.UnknownEnvironmentError("dead-beef-123456"),
 	code:       params.CodeNotFound,
@@ -10,8 +10,8 @@ import (
 	"sort"
 
 	"github.com/juju/errors"
-	"github.com/juju/utils/featureflag"
 
+	"github.com/juju/juju/juju/osenv/featureflag"
 	"github.com/juju/juju/state"
 )
 
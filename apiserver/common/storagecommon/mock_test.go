@@ -81,6 +81,10 @@ func (i *fakeStorageInstance) Owner() names.Tag {
 	return i.owner
 }
 
+func (i *fakeStorageInstance) HasOwner() bool {
+	return i.owner != nil
+}
+
 func (i *fakeStorageInstance) Kind() state.StorageKind {
 	return i.kind
 }
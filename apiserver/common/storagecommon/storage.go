@@ -260,7 +260,9 @@ func storageTags(
 	storageTags := tags.ResourceTags(names.NewEnvironTag(uuid), cfg)
 	if storageInstance != nil {
 		storageTags[tags.JujuStorageInstance] = storageInstance.Tag().Id()
-		storageTags[tags.JujuStorageOwner] = storageInstance.Owner().Id()
+		if storageInstance.HasOwner() {
+			storageTags[tags.JujuStorageOwner] = storageInstance.Owner().Id()
+		}
 	}
 	return storageTags, nil
 }
@@ -0,0 +1,33 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator
+
+import (
+	"github.com/juju/juju/state"
+)
+
+type StateInterface interface {
+	Environment() (Environment, error)
+}
+
+type Environment interface {
+	CredentialValid() (bool, string)
+	SetCredentialValid(valid bool, reason string) error
+}
+
+type stateShim struct {
+	*state.State
+}
+
+func (s stateShim) Environment() (Environment, error) {
+	env, err := s.State.Environment()
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+var getState = func(st *state.State) StateInterface {
+	return stateShim{st}
+}
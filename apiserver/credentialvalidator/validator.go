@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The credentialvalidator package implements the API interface
+// used by the credential validator worker.
+package credentialvalidator
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("CredentialValidator", 1, NewAPI)
+}
+
+// API implements the API used by the credential validator worker.
+type API struct {
+	*common.EnvironWatcher
+	st StateInterface
+}
+
+// NewAPI creates a new instance of the CredentialValidator API.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &API{
+		EnvironWatcher: common.NewEnvironWatcher(st, resources, authorizer),
+		st:             getState(st),
+	}, nil
+}
+
+// CredentialStatus returns whether the environment's cloud credentials
+// were accepted by the cloud when last checked.
+func (api *API) CredentialStatus() (params.CredentialStatus, error) {
+	env, err := api.st.Environment()
+	if err != nil {
+		return params.CredentialStatus{}, err
+	}
+	valid, reason := env.CredentialValid()
+	return params.CredentialStatus{Valid: valid, Reason: reason}, nil
+}
+
+// SetCredentialStatus records whether the environment's cloud
+// credentials were accepted by the cloud.
+func (api *API) SetCredentialStatus(args params.CredentialStatus) error {
+	env, err := api.st.Environment()
+	if err != nil {
+		return err
+	}
+	return env.SetCredentialValid(args.Valid, args.Reason)
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/credentialvalidator"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type CredentialValidatorSuite struct {
+	coretesting.BaseSuite
+	st  *mockState
+	api *credentialvalidator.API
+}
+
+var _ = gc.Suite(&CredentialValidatorSuite{})
+
+func (s *CredentialValidatorSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.st = &mockState{Stub: &testing.Stub{}}
+	credentialvalidator.PatchState(s, s.st)
+	var err error
+	s.api, err = credentialvalidator.NewAPI(nil, nil, apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CredentialValidatorSuite) TestNewAPIRequiresEnvironManager(c *gc.C) {
+	api, err := credentialvalidator.NewAPI(nil, nil, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(common.ServerError(err), jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *CredentialValidatorSuite) TestCredentialStatus(c *gc.C) {
+	s.st.valid = false
+	s.st.reason = "expired"
+	status, err := s.api.CredentialStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, params.CredentialStatus{Valid: false, Reason: "expired"})
+	s.st.CheckCallNames(c, "Environment", "CredentialValid")
+}
+
+func (s *CredentialValidatorSuite) TestSetCredentialStatus(c *gc.C) {
+	err := s.api.SetCredentialStatus(params.CredentialStatus{Valid: false, Reason: "expired"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.st.CheckCalls(c, []testing.StubCall{
+		{"Environment", nil},
+		{"SetCredentialValid", []interface{}{false, "expired"}},
+	})
+}
+
+func (s *CredentialValidatorSuite) TestSetCredentialStatusError(c *gc.C) {
+	s.st.SetErrors(nil, errors.New("boom"))
+	err := s.api.SetCredentialStatus(params.CredentialStatus{Valid: true})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+type mockState struct {
+	*testing.Stub
+	valid  bool
+	reason string
+}
+
+func (st *mockState) Environment() (credentialvalidator.Environment, error) {
+	st.MethodCall(st, "Environment")
+	return st, st.NextErr()
+}
+
+func (st *mockState) CredentialValid() (bool, string) {
+	st.MethodCall(st, "CredentialValid")
+	return st.valid, st.reason
+}
+
+func (st *mockState) SetCredentialValid(valid bool, reason string) error {
+	st.MethodCall(st, "SetCredentialValid", valid, reason)
+	return st.NextErr()
+}
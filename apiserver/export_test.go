@@ -30,6 +30,7 @@ var (
 	ParseLogLine          = parseLogLine
 	AgentMatchesFilter    = agentMatchesFilter
 	NewLogTailer          = &newLogTailer
+	LogQueryPollInterval  = &logQueryPollInterval
 )
 
 func ServerMacaroon(srv *Server) (*macaroon.Macaroon, error) {
@@ -0,0 +1,59 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The historypruner package implements the API interface
+// used by the history pruner worker.
+package historypruner
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("HistoryPruner", 1, NewAPI)
+}
+
+// API implements the API used by the history pruner worker.
+type API struct {
+	st StateInterface
+}
+
+// NewAPI creates a new instance of the HistoryPruner API.
+func NewAPI(
+	st *state.State,
+	_ *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: getState(st)}, nil
+}
+
+// Prune removes the environment's status history, finished actions
+// and "juju run" results that are not kept by the retention policies
+// in its config.
+func (api *API) Prune() error {
+	cfg, err := api.st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	policies := state.HistoryRetentionPolicies(cfg)
+	kinds := make([]string, 0, len(policies))
+	for kind := range policies {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		kind := state.HistoryKind(kind)
+		if err := api.st.PruneHistory(kind, policies[kind]); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
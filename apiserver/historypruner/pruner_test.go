@@ -0,0 +1,93 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package historypruner_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/historypruner"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type HistoryPrunerSuite struct {
+	coretesting.BaseSuite
+
+	st  *mockState
+	api *historypruner.API
+}
+
+var _ = gc.Suite(&HistoryPrunerSuite{})
+
+func (s *HistoryPrunerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.st = &mockState{
+		Stub: &testing.Stub{},
+		cfg: coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+			"status-history-max-age":     24,
+			"status-history-max-entries": 0,
+		}),
+	}
+	historypruner.PatchState(s, s.st)
+	var err error
+	s.api, err = historypruner.NewAPI(nil, nil, apiservertesting.FakeAuthorizer{EnvironManager: true})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *HistoryPrunerSuite) TestNewAPIRequiresEnvironManager(c *gc.C) {
+	api, err := historypruner.NewAPI(nil, nil, apiservertesting.FakeAuthorizer{})
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(common.ServerError(err), jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *HistoryPrunerSuite) TestPrune(c *gc.C) {
+	err := s.api.Prune()
+	c.Assert(err, jc.ErrorIsNil)
+	s.st.CheckCalls(c, []testing.StubCall{
+		{"EnvironConfig", nil},
+		{"PruneHistory", []interface{}{state.ActionResults, state.RetentionPolicy{
+			MaxAge:     config.DefaultActionResultsMaxAge * time.Hour,
+			MaxEntries: config.DefaultActionResultsMaxEntries,
+		}}},
+		{"PruneHistory", []interface{}{state.RunResultsHistory, state.RetentionPolicy{
+			MaxAge:     config.DefaultRunResultsMaxAge * time.Hour,
+			MaxEntries: config.DefaultRunResultsMaxEntries,
+		}}},
+		{"PruneHistory", []interface{}{state.StatusHistory, state.RetentionPolicy{
+			MaxAge: 24 * time.Hour,
+		}}},
+	})
+}
+
+func (s *HistoryPrunerSuite) TestPruneFailure(c *gc.C) {
+	s.st.SetErrors(nil, errors.New("boom"))
+	err := s.api.Prune()
+	c.Assert(err, gc.ErrorMatches, "boom")
+	s.st.CheckCallNames(c, "EnvironConfig", "PruneHistory")
+}
+
+type mockState struct {
+	*testing.Stub
+	cfg *config.Config
+}
+
+func (st *mockState) EnvironConfig() (*config.Config, error) {
+	st.MethodCall(st, "EnvironConfig")
+	return st.cfg, st.NextErr()
+}
+
+func (st *mockState) PruneHistory(kind state.HistoryKind, policy state.RetentionPolicy) error {
+	st.MethodCall(st, "PruneHistory", kind, policy)
+	return st.NextErr()
+}
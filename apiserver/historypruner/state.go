@@ -0,0 +1,18 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package historypruner
+
+import (
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+)
+
+type StateInterface interface {
+	EnvironConfig() (*config.Config, error)
+	PruneHistory(kind state.HistoryKind, policy state.RetentionPolicy) error
+}
+
+var getState = func(st *state.State) StateInterface {
+	return st
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The hostkeyreporter package implements the API interface
+// used by the host key reporter worker.
+package hostkeyreporter
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("HostKeyReporter", 1, NewAPI)
+}
+
+// API implements the API used by the host key reporter worker.
+type API struct {
+	st   StateInterface
+	auth common.Authorizer
+}
+
+// NewAPI creates a new instance of the HostKeyReporter API.
+func NewAPI(
+	st *state.State,
+	_ *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: getState(st), auth: authorizer}, nil
+}
+
+// ReportKeys records the SSH host keys of the given machines, so
+// that clients connecting to them can verify their keys. A machine
+// agent may only report its own keys.
+func (api *API) ReportKeys(args params.SSHHostKeySet) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.EntityKeys)),
+	}
+	for i, arg := range args.EntityKeys {
+		err := api.reportKeys(arg)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (api *API) reportKeys(arg params.SSHHostKeysArg) error {
+	tag, err := names.ParseMachineTag(arg.Tag)
+	if err != nil || !api.auth.AuthOwner(tag) {
+		return common.ErrPerm
+	}
+	machine, err := api.st.Machine(tag.Id())
+	if err != nil {
+		return err
+	}
+	return machine.SetSSHHostKeys(arg.PublicKeys)
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostkeyreporter_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/hostkeyreporter"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type HostKeyReporterSuite struct {
+	coretesting.BaseSuite
+	st  *mockState
+	api *hostkeyreporter.API
+}
+
+var _ = gc.Suite(&HostKeyReporterSuite{})
+
+func (s *HostKeyReporterSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.st = &mockState{Stub: &testing.Stub{}}
+	hostkeyreporter.PatchState(s, s.st)
+	var err error
+	s.api, err = hostkeyreporter.NewAPI(nil, nil, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *HostKeyReporterSuite) TestNewAPIRequiresMachineAgent(c *gc.C) {
+	api, err := hostkeyreporter.NewAPI(nil, nil, apiservertesting.FakeAuthorizer{
+		Tag: names.NewUnitTag("mysql/0"),
+	})
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(common.ServerError(err), jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *HostKeyReporterSuite) TestReportKeys(c *gc.C) {
+	s.st.SetErrors(nil, errors.New("boom"))
+	results, err := s.api.ReportKeys(params.SSHHostKeySet{
+		EntityKeys: []params.SSHHostKeysArg{{
+			Tag:        "machine-1",
+			PublicKeys: []string{"ssh-rsa rsa"},
+		}, {
+			Tag:        "machine-1",
+			PublicKeys: []string{"ssh-rsa other"},
+		}, {
+			Tag:        "machine-2",
+			PublicKeys: []string{"ssh-rsa rsa"},
+		}, {
+			Tag:        "unit-mysql-0",
+			PublicKeys: []string{"ssh-rsa rsa"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{},
+			{Error: &params.Error{Message: "boom"}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+	s.st.CheckCalls(c, []testing.StubCall{
+		{"Machine", []interface{}{"1"}},
+		{"SetSSHHostKeys", []interface{}{[]string{"ssh-rsa rsa"}}},
+		{"Machine", []interface{}{"1"}},
+		{"SetSSHHostKeys", []interface{}{[]string{"ssh-rsa other"}}},
+	})
+}
+
+type mockState struct {
+	*testing.Stub
+}
+
+func (st *mockState) Machine(id string) (hostkeyreporter.Machine, error) {
+	st.MethodCall(st, "Machine", id)
+	return &mockMachine{st.Stub}, nil
+}
+
+type mockMachine struct {
+	*testing.Stub
+}
+
+func (m *mockMachine) SetSSHHostKeys(keys []string) error {
+	m.MethodCall(m, "SetSSHHostKeys", keys)
+	return m.NextErr()
+}
@@ -0,0 +1,32 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostkeyreporter
+
+import (
+	"github.com/juju/juju/state"
+)
+
+type StateInterface interface {
+	Machine(id string) (Machine, error)
+}
+
+type Machine interface {
+	SetSSHHostKeys(keys []string) error
+}
+
+type stateShim struct {
+	*state.State
+}
+
+func (s stateShim) Machine(id string) (Machine, error) {
+	m, err := s.State.Machine(id)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var getState = func(st *state.State) StateInterface {
+	return stateShim{st}
+}
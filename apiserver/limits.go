@@ -0,0 +1,304 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+)
+
+// Limits holds the limits the API server applies to each of its
+// clients, so that a single misbehaving client cannot starve the
+// others. Clients are identified by the entity they log in as and the
+// environment they connect to, once they have authenticated, and by
+// their remote address before then. A zero value means no limit.
+type Limits struct {
+	// MaxConnectionsPerEntity is the number of concurrent API
+	// connections allowed for an entity.
+	MaxConnectionsPerEntity int
+
+	// AddressLoginRate is the number of login attempts per second
+	// allowed from a remote address, once AddressLoginBurst attempts
+	// have been made. Attempts are counted before authentication, so
+	// failed logins count too.
+	AddressLoginRate  float64
+	AddressLoginBurst int
+
+	// LoginRate is the number of successful logins per second allowed
+	// for an entity, once LoginBurst logins have been made.
+	LoginRate  float64
+	LoginBurst int
+
+	// FacadeCallRate is the number of calls per second an entity may
+	// make to each facade, once FacadeCallBurst calls have been made.
+	FacadeCallRate  float64
+	FacadeCallBurst int
+}
+
+// DefaultLimits holds the limits used by the machine agent when none
+// are configured.
+var DefaultLimits = Limits{
+	MaxConnectionsPerEntity: 50,
+	AddressLoginRate:        20,
+	AddressLoginBurst:       100,
+	LoginRate:               5,
+	LoginBurst:              20,
+	FacadeCallRate:          100,
+	FacadeCallBurst:         200,
+}
+
+// LimitStats counts the requests rejected because a client exceeded
+// its limits.
+type LimitStats struct {
+	RejectedConnections   int64
+	RejectedLoginAttempts int64
+	RejectedLogins        int64
+
+	// RejectedCalls holds the number of calls rejected for each
+	// facade.
+	RejectedCalls map[string]int64
+}
+
+// limitMetrics publishes the requests rejected by all the API servers
+// in the process, as the "apiserver-limits" expvar variable.
+var limitMetrics = expvar.NewMap("apiserver-limits")
+
+// maxIdleBuckets is the number of token buckets kept before those
+// that have refilled are discarded.
+const maxIdleBuckets = 1000
+
+// clientLimiter enforces Limits.
+type clientLimiter struct {
+	limits Limits
+	clock  clock.Clock
+
+	mu            sync.Mutex
+	connections   map[string]int
+	loginAttempts map[string]*tokenBucket
+	logins        map[string]*tokenBucket
+	calls         map[string]*tokenBucket
+	stats         LimitStats
+}
+
+func newClientLimiter(limits Limits, clock clock.Clock) *clientLimiter {
+	return &clientLimiter{
+		limits:        limits,
+		clock:         clock,
+		connections:   make(map[string]int),
+		loginAttempts: make(map[string]*tokenBucket),
+		logins:        make(map[string]*tokenBucket),
+		calls:         make(map[string]*tokenBucket),
+		stats:         LimitStats{RejectedCalls: make(map[string]int64)},
+	}
+}
+
+// clientKey returns the key used to identify an entity's connections
+// to an environment.
+func clientKey(envUUID, tag string) string {
+	return envUUID + ":" + tag
+}
+
+// Stats returns the number of requests rejected so far.
+func (l *clientLimiter) Stats() LimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := l.stats
+	stats.RejectedCalls = make(map[string]int64, len(l.stats.RejectedCalls))
+	for facade, n := range l.stats.RejectedCalls {
+		stats.RejectedCalls[facade] = n
+	}
+	return stats
+}
+
+// AcquireConnection records a new connection for the client, returning
+// a function that must be called when the connection closes. It returns
+// a *common.RateLimitExceededError if the client already has as many
+// connections as it is allowed.
+func (l *clientLimiter) AcquireConnection(key string) (release func(), err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	max := l.limits.MaxConnectionsPerEntity
+	if max > 0 && l.connections[key] >= max {
+		l.stats.RejectedConnections++
+		limitMetrics.Add("rejected-connections", 1)
+		logger.Debugf("too many connections for %s", key)
+		return nil, &common.RateLimitExceededError{
+			Limit: fmt.Sprintf("more than %d concurrent connections", max),
+		}
+	}
+	l.connections[key]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.connections[key]--; l.connections[key] <= 0 {
+				delete(l.connections, key)
+			}
+		})
+	}, nil
+}
+
+// LoginAttempt returns a *common.RateLimitExceededError if too many
+// logins have been attempted from the given remote address. It is
+// called before the client is authenticated, so that clients cannot
+// exhaust the login quota of entities they cannot log in as.
+func (l *clientLimiter) LoginAttempt(addr string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.take(l.loginAttempts, addr, l.limits.AddressLoginRate, l.limits.AddressLoginBurst) {
+		return nil
+	}
+	l.stats.RejectedLoginAttempts++
+	limitMetrics.Add("rejected-login-attempts", 1)
+	logger.Debugf("too many login attempts from %s", addr)
+	return &common.RateLimitExceededError{
+		Limit: fmt.Sprintf("more than %g login attempts per second", l.limits.AddressLoginRate),
+	}
+}
+
+// Login returns a *common.RateLimitExceededError if the client has
+// logged in too often. It must only be called once the client has
+// authenticated as the entity identified by key.
+func (l *clientLimiter) Login(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.take(l.logins, key, l.limits.LoginRate, l.limits.LoginBurst) {
+		return nil
+	}
+	l.stats.RejectedLogins++
+	limitMetrics.Add("rejected-logins", 1)
+	logger.Debugf("too many logins for %s", key)
+	return &common.RateLimitExceededError{
+		Limit: fmt.Sprintf("more than %g logins per second", l.limits.LoginRate),
+	}
+}
+
+// Call returns a *common.RateLimitExceededError if the client has
+// called the facade too often.
+func (l *clientLimiter) Call(key, facade string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.take(l.calls, key+":"+facade, l.limits.FacadeCallRate, l.limits.FacadeCallBurst) {
+		return nil
+	}
+	l.stats.RejectedCalls[facade]++
+	limitMetrics.Add("rejected-calls-"+facade, 1)
+	logger.Debugf("too many %s calls for %s", facade, key)
+	return &common.RateLimitExceededError{
+		Limit: fmt.Sprintf("more than %g %s calls per second", l.limits.FacadeCallRate, facade),
+	}
+}
+
+// take takes a token from the bucket with the given key, creating it
+// if necessary, and reports whether one was available. l.mu must be
+// held.
+func (l *clientLimiter) take(buckets map[string]*tokenBucket, key string, rate float64, burst int) bool {
+	if rate <= 0 {
+		return true
+	}
+	now := l.clock.Now()
+	bucket, ok := buckets[key]
+	if !ok {
+		if len(buckets) >= maxIdleBuckets {
+			for key, bucket := range buckets {
+				if bucket.full(now) {
+					delete(buckets, key)
+				}
+			}
+		}
+		bucket = newTokenBucket(rate, burst, now)
+		buckets[key] = bucket
+	}
+	return bucket.take(now)
+}
+
+// tokenBucket allows events at a steady rate, with bursts of up to
+// its capacity.
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	updated  time.Time
+}
+
+// newTokenBucket returns a full tokenBucket. If burst is less than
+// one, the bucket holds a single token.
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	capacity := float64(burst)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		updated:  now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.updated = now
+}
+
+// take takes a token from the bucket, and reports whether one was
+// available.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full reports whether the bucket has refilled completely.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// rateLimitedRoot rejects calls made by a client more often than its
+// facade call rate allows. Calls to the Pinger facade are never
+// rejected, as a client whose pings fail is disconnected.
+type rateLimitedRoot struct {
+	rpc.MethodFinder
+	limiter *clientLimiter
+	key     string
+}
+
+// FindMethod implements rpc.MethodFinder.
+func (r *rateLimitedRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if rootName != "Pinger" {
+		if err := r.limiter.Call(r.key, rootName); err != nil {
+			return nil, err
+		}
+	}
+	return caller, nil
+}
+
+// Kill implements rpc.Killer, killing the wrapped root if it can be.
+func (r *rateLimitedRoot) Kill() {
+	if killer, ok := r.MethodFinder.(rpc.Killer); ok {
+		killer.Kill()
+	}
+}
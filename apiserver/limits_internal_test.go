@@ -0,0 +1,158 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"expvar"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc/rpcreflect"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type limitsIntSuite struct {
+	coretesting.BaseSuite
+	clock *coretesting.Clock
+}
+
+var _ = gc.Suite(&limitsIntSuite{})
+
+func (s *limitsIntSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = coretesting.NewClock(time.Now())
+}
+
+func (s *limitsIntSuite) TestNoLimits(c *gc.C) {
+	l := newClientLimiter(Limits{}, s.clock)
+	for i := 0; i < 100; i++ {
+		_, err := l.AcquireConnection("uuid:machine-0")
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(l.Login("uuid:machine-0"), jc.ErrorIsNil)
+		c.Assert(l.Call("uuid:machine-0", "Machiner"), jc.ErrorIsNil)
+	}
+	c.Assert(l.calls, gc.HasLen, 0)
+}
+
+func (s *limitsIntSuite) TestAcquireConnection(c *gc.C) {
+	l := newClientLimiter(Limits{MaxConnectionsPerEntity: 2}, s.clock)
+	release1, err := l.AcquireConnection("uuid:machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = l.AcquireConnection("uuid:machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = l.AcquireConnection("uuid:machine-0")
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 2 concurrent connections")
+	c.Assert(err, jc.Satisfies, common.IsRateLimitExceededError)
+
+	// Other entities have their own quota.
+	_, err = l.AcquireConnection("uuid:machine-1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Releasing twice only frees one place.
+	release1()
+	release1()
+	_, err = l.AcquireConnection("uuid:machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = l.AcquireConnection("uuid:machine-0")
+	c.Assert(err, gc.NotNil)
+	c.Assert(l.Stats().RejectedConnections, gc.Equals, int64(2))
+}
+
+func (s *limitsIntSuite) TestLoginRate(c *gc.C) {
+	l := newClientLimiter(Limits{LoginRate: 2, LoginBurst: 3}, s.clock)
+	for i := 0; i < 3; i++ {
+		c.Assert(l.Login("uuid:user-bob"), jc.ErrorIsNil)
+	}
+	err := l.Login("uuid:user-bob")
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 2 logins per second")
+	c.Assert(l.Login("uuid:user-mary"), jc.ErrorIsNil)
+
+	s.clock.Advance(500 * time.Millisecond)
+	c.Assert(l.Login("uuid:user-bob"), jc.ErrorIsNil)
+	c.Assert(l.Login("uuid:user-bob"), gc.NotNil)
+	c.Assert(l.Stats().RejectedLogins, gc.Equals, int64(2))
+}
+
+func (s *limitsIntSuite) TestLoginAttemptRate(c *gc.C) {
+	before := limitMetric("rejected-login-attempts")
+	l := newClientLimiter(Limits{AddressLoginRate: 1, AddressLoginBurst: 2, LoginRate: 1}, s.clock)
+	c.Assert(l.LoginAttempt("10.0.0.1"), jc.ErrorIsNil)
+	c.Assert(l.LoginAttempt("10.0.0.1"), jc.ErrorIsNil)
+	err := l.LoginAttempt("10.0.0.1")
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 1 login attempts per second")
+	c.Assert(l.LoginAttempt("10.0.0.2"), jc.ErrorIsNil)
+
+	// Attempts do not charge any entity's login quota.
+	c.Assert(l.Login("uuid:user-admin"), jc.ErrorIsNil)
+	c.Assert(l.Stats().RejectedLoginAttempts, gc.Equals, int64(1))
+	c.Assert(l.Stats().RejectedLogins, gc.Equals, int64(0))
+	c.Assert(limitMetric("rejected-login-attempts"), gc.Equals, before+1)
+}
+
+// limitMetric returns the value of the named counter published in
+// limitMetrics.
+func limitMetric(name string) int64 {
+	v, ok := limitMetrics.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func (s *limitsIntSuite) TestCallRate(c *gc.C) {
+	l := newClientLimiter(Limits{FacadeCallRate: 10}, s.clock)
+	c.Assert(l.Call("uuid:unit-mysql-0", "Uniter"), jc.ErrorIsNil)
+	err := l.Call("uuid:unit-mysql-0", "Uniter")
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 10 Uniter calls per second")
+	c.Assert(l.Call("uuid:unit-mysql-0", "Upgrader"), jc.ErrorIsNil)
+
+	s.clock.Advance(100 * time.Millisecond)
+	c.Assert(l.Call("uuid:unit-mysql-0", "Uniter"), jc.ErrorIsNil)
+	c.Assert(l.Stats().RejectedCalls, jc.DeepEquals, map[string]int64{"Uniter": 1})
+}
+
+func (s *limitsIntSuite) TestIdleBucketsDiscarded(c *gc.C) {
+	l := newClientLimiter(Limits{FacadeCallRate: 1}, s.clock)
+	for i := 0; i < maxIdleBuckets; i++ {
+		c.Assert(l.Call("uuid:machine-0", string(rune('a'+i%26))+string(rune('a'+i/26))), jc.ErrorIsNil)
+	}
+	c.Assert(l.calls, gc.HasLen, maxIdleBuckets)
+	s.clock.Advance(time.Second)
+	c.Assert(l.Call("uuid:machine-1", "Machiner"), jc.ErrorIsNil)
+	c.Assert(l.calls, gc.HasLen, 1)
+}
+
+type fakeFinder struct {
+	killed bool
+}
+
+func (f *fakeFinder) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	return nil, nil
+}
+
+func (f *fakeFinder) Kill() {
+	f.killed = true
+}
+
+func (s *limitsIntSuite) TestRateLimitedRoot(c *gc.C) {
+	finder := &fakeFinder{}
+	root := &rateLimitedRoot{
+		MethodFinder: finder,
+		limiter:      newClientLimiter(Limits{FacadeCallRate: 1}, s.clock),
+		key:          "uuid:machine-0",
+	}
+	_, err := root.FindMethod("Machiner", 0, "Life")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = root.FindMethod("Machiner", 0, "Life")
+	c.Assert(err, jc.Satisfies, common.IsRateLimitExceededError)
+	for i := 0; i < 3; i++ {
+		_, err = root.FindMethod("Pinger", 0, "Ping")
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	root.Kill()
+	c.Assert(finder.killed, jc.IsTrue)
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"net"
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver"
+	jujutesting "github.com/juju/juju/juju/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type limitsSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&limitsSuite{})
+
+func (s *limitsSuite) newServer(c *gc.C, limits apiserver.Limits) *apiserver.Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	srv, err := apiserver.NewServer(s.State, listener, apiserver.ServerConfig{
+		Cert:   []byte(coretesting.ServerCert),
+		Key:    []byte(coretesting.ServerKey),
+		Tag:    names.NewMachineTag("0"),
+		Limits: limits,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { srv.Stop() })
+	return srv
+}
+
+func (s *limitsSuite) machineInfo(c *gc.C, srv *apiserver.Server) *api.Info {
+	machine, password := s.Factory.MakeMachineReturningPassword(
+		c, &factory.MachineParams{Nonce: "fake_nonce"})
+	return &api.Info{
+		Tag:        machine.Tag(),
+		Password:   password,
+		Nonce:      "fake_nonce",
+		Addrs:      []string{srv.Addr().String()},
+		CACert:     coretesting.CACert,
+		EnvironTag: s.State.EnvironTag(),
+	}
+}
+
+func (s *limitsSuite) TestMaxConnectionsPerEntity(c *gc.C) {
+	srv := s.newServer(c, apiserver.Limits{MaxConnectionsPerEntity: 1})
+	info := s.machineInfo(c, srv)
+
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 1 concurrent connections")
+	c.Assert(srv.LimitStats().RejectedConnections, gc.Equals, int64(1))
+
+	// Once the first connection closes, another may be opened.
+	err = st.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	attempt := utils.AttemptStrategy{Total: coretesting.LongWait, Delay: 10 * time.Millisecond}
+	for a := attempt.Start(); a.Next(); {
+		st, err = api.Open(info, fastDialOpts)
+		if err == nil {
+			st.Close()
+			return
+		}
+	}
+	c.Fatalf("cannot connect after first connection closed: %v", err)
+}
+
+func (s *limitsSuite) TestLoginRate(c *gc.C) {
+	srv := s.newServer(c, apiserver.Limits{LoginRate: 0.001, LoginBurst: 1})
+	info := s.machineInfo(c, srv)
+
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 0.001 logins per second")
+	c.Assert(srv.LimitStats().RejectedLogins, gc.Equals, int64(1))
+}
+
+func (s *limitsSuite) TestFailedLoginsDoNotChargeEntity(c *gc.C) {
+	srv := s.newServer(c, apiserver.Limits{LoginRate: 0.001, LoginBurst: 1})
+	info := s.machineInfo(c, srv)
+
+	// Logins that fail to authenticate as the machine must not use
+	// up its quota, or anyone could lock it out.
+	bad := *info
+	bad.Password = "not the password"
+	for i := 0; i < 3; i++ {
+		_, err := api.Open(&bad, fastDialOpts)
+		c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	}
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+	c.Assert(srv.LimitStats().RejectedLogins, gc.Equals, int64(0))
+}
+
+func (s *limitsSuite) TestAddressLoginRate(c *gc.C) {
+	srv := s.newServer(c, apiserver.Limits{AddressLoginRate: 0.001, AddressLoginBurst: 1})
+	info := s.machineInfo(c, srv)
+
+	bad := *info
+	bad.Password = "not the password"
+	_, err := api.Open(&bad, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 0.001 login attempts per second")
+	c.Assert(srv.LimitStats().RejectedLoginAttempts, gc.Equals, int64(1))
+}
+
+func (s *limitsSuite) TestFacadeCallRate(c *gc.C) {
+	srv := s.newServer(c, apiserver.Limits{FacadeCallRate: 0.001, FacadeCallBurst: 1})
+	info := s.machineInfo(c, srv)
+
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	tag := info.Tag.(names.MachineTag)
+	_, err = st.Machiner().Machine(tag)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = st.Machiner().Machine(tag)
+	c.Assert(err, gc.ErrorMatches, "rate limit exceeded: more than 0.001 Machiner calls per second")
+	c.Assert(srv.LimitStats().RejectedCalls, jc.DeepEquals, map[string]int64{"Machiner": 1})
+
+	// Other facades have their own limits.
+	_, err = st.Upgrader().DesiredVersion(tag.String())
+	c.Assert(err, jc.ErrorIsNil)
+}
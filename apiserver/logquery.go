@@ -0,0 +1,174 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// logQueryPollInterval is how often a following log query checks for
+// new logs once it has sent those already recorded.
+var logQueryPollInterval = time.Second
+
+// logQueryHandler serves the recorded logs of an environment over
+// HTTP, so that external log collectors can backfill the logs they
+// missed. Unlike the debug-log endpoint, which only tails, it returns
+// pages of logs within a time range, each with a token naming where
+// the next page starts.
+//
+// The query parameters accepted are:
+//
+//	start -> RFC3339 time - return only logs at or after this time
+//	end -> RFC3339 time - return only logs before this time
+//	after -> string - the next token of a previously returned page
+//	limit -> uint - return *at most* this many logs in each page
+//	follow -> bool - if true, keep the response open, sending a page
+//	   for each batch of new logs as they are written; may not be
+//	   combined with end
+//	level, includeEntity, excludeEntity, includeModule, excludeModule
+//	   - filter the logs as they do for the debug-log endpoint
+type logQueryHandler struct {
+	ctxt httpContext
+	stop <-chan struct{}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *logQueryHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", req.Method))
+		return
+	}
+	st, _, err := h.ctxt.stateForRequestAuthenticatedUser(req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	queryParams, follow, err := readLogQueryParams(req.URL.Query())
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	result, err := queryLogs(st, queryParams)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if !follow {
+		sendStatusAndJSON(w, http.StatusOK, result)
+		return
+	}
+
+	w.Header().Set("Content-Type", params.ContentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	var closed <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		closed = notifier.CloseNotify()
+	}
+	for {
+		var poll <-chan time.Time
+		if len(result.Records) > 0 {
+			sendJSON(w, result)
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		} else {
+			// Wait for more logs to be written.
+			poll = time.After(logQueryPollInterval)
+		}
+		if poll != nil {
+			select {
+			case <-h.stop:
+				return
+			case <-closed:
+				return
+			case <-poll:
+			}
+		}
+		queryParams.After = result.Next
+		if result, err = queryLogs(st, queryParams); err != nil {
+			logger.Errorf("log query failed: %v", err)
+			sendJSON(w, &params.LogQueryResult{Error: common.ServerError(err)})
+			return
+		}
+	}
+}
+
+// queryLogs returns a page of the logs that match the given parameters.
+func queryLogs(st state.LoggingState, queryParams *state.LogQueryParams) (*params.LogQueryResult, error) {
+	records, next, err := state.QueryLogs(st, queryParams)
+	if errors.IsNotValid(err) {
+		return nil, errors.BadRequestf("%v", err)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := &params.LogQueryResult{
+		Records: make([]params.LogQueryRecord, len(records)),
+		Next:    next,
+	}
+	for i, rec := range records {
+		result.Records[i] = params.LogQueryRecord{
+			Time:     rec.Time,
+			Entity:   rec.Entity,
+			Module:   rec.Module,
+			Location: rec.Location,
+			Level:    rec.Level.String(),
+			Message:  rec.Message,
+		}
+	}
+	return result, nil
+}
+
+// readLogQueryParams returns the log query parameters held in the
+// given query values, and whether the query should follow new logs.
+func readLogQueryParams(queryMap url.Values) (*state.LogQueryParams, bool, error) {
+	// The filters are the same as those used by debug-log.
+	filter, err := readDebugLogParams(queryMap)
+	if err != nil {
+		return nil, false, errors.BadRequestf("%v", err)
+	}
+	queryParams := &state.LogQueryParams{
+		MinLevel:      filter.filterLevel,
+		IncludeEntity: filter.includeEntity,
+		ExcludeEntity: filter.excludeEntity,
+		IncludeModule: filter.includeModule,
+		ExcludeModule: filter.excludeModule,
+		After:         queryMap.Get("after"),
+	}
+	if value := queryMap.Get("start"); value != "" {
+		if queryParams.StartTime, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, false, errors.BadRequestf("start value %q is not a valid RFC3339 time", value)
+		}
+	}
+	if value := queryMap.Get("end"); value != "" {
+		if queryParams.EndTime, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, false, errors.BadRequestf("end value %q is not a valid RFC3339 time", value)
+		}
+	}
+	if value := queryMap.Get("limit"); value != "" {
+		num, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, false, errors.BadRequestf("limit value %q is not a valid unsigned number", value)
+		}
+		queryParams.Limit = int(num)
+	}
+	follow := false
+	if value := queryMap.Get("follow"); value != "" {
+		if follow, err = strconv.ParseBool(value); err != nil {
+			return nil, false, errors.BadRequestf("follow value %q is not a valid boolean", value)
+		}
+	}
+	if follow && !queryParams.EndTime.IsZero() {
+		return nil, false, errors.BadRequestf("cannot follow logs with an end time")
+	}
+	return queryParams, follow, nil
+}
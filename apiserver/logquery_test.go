@@ -0,0 +1,189 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+type logQuerySuite struct {
+	authHttpSuite
+	start time.Time
+}
+
+var _ = gc.Suite(&logQuerySuite{})
+
+func (s *logQuerySuite) SetUpTest(c *gc.C) {
+	s.SetInitialFeatureFlags("db-log")
+	s.authHttpSuite.SetUpTest(c)
+	s.PatchValue(apiserver.LogQueryPollInterval, 10*time.Millisecond)
+	s.start = time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *logQuerySuite) logQueryURL(c *gc.C, query url.Values) string {
+	return s.makeURL(c, "https", "/environment/"+s.State.EnvironUUID()+"/logquery", query).String()
+}
+
+// writeLogs writes a log for each message, a second apart.
+func (s *logQuerySuite) writeLogs(c *gc.C, level loggo.Level, messages ...string) {
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer dbLogger.Close()
+	for _, message := range messages {
+		err := dbLogger.Log(s.start, "juju.test", "test.go:99", level, message)
+		c.Assert(err, jc.ErrorIsNil)
+		s.start = s.start.Add(time.Second)
+	}
+}
+
+func (s *logQuerySuite) query(c *gc.C, query url.Values) params.LogQueryResult {
+	resp := s.authRequest(c, httpRequestParams{method: "GET", url: s.logQueryURL(c, query)})
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeJSON)
+	var result params.LogQueryResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil, gc.Commentf("body: %s", body))
+	return result
+}
+
+func (s *logQuerySuite) assertErrorResponse(c *gc.C, resp *http.Response, expCode int, expError string) {
+	body := assertResponse(c, resp, expCode, params.ContentTypeJSON)
+	var result params.ErrorResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil, gc.Commentf("body: %s", body))
+	c.Assert(result.Error, gc.NotNil)
+	c.Assert(result.Error.Message, gc.Matches, expError)
+}
+
+func messages(records []params.LogQueryRecord) []string {
+	var out []string
+	for _, rec := range records {
+		out = append(out, rec.Message)
+	}
+	return out
+}
+
+func (s *logQuerySuite) TestRequiresAuth(c *gc.C) {
+	resp := s.sendRequest(c, httpRequestParams{method: "GET", url: s.logQueryURL(c, nil)})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "no credentials provided")
+}
+
+func (s *logQuerySuite) TestRequiresGET(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.logQueryURL(c, nil)})
+	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "POST"`)
+}
+
+func (s *logQuerySuite) TestQuery(c *gc.C) {
+	s.writeLogs(c, loggo.INFO, "one")
+
+	result := s.query(c, nil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.Records, gc.HasLen, 1)
+	rec := result.Records[0]
+	c.Assert(rec.Time.Equal(s.start.Add(-time.Second)), jc.IsTrue)
+	rec.Time = time.Time{}
+	c.Assert(rec, jc.DeepEquals, params.LogQueryRecord{
+		Entity:   "machine-0",
+		Module:   "juju.test",
+		Location: "test.go:99",
+		Level:    "INFO",
+		Message:  "one",
+	})
+	c.Assert(result.Next, gc.Not(gc.Equals), "")
+}
+
+func (s *logQuerySuite) TestQueryPaging(c *gc.C) {
+	s.writeLogs(c, loggo.INFO, "one", "two", "three")
+
+	result := s.query(c, url.Values{"limit": {"2"}})
+	c.Assert(messages(result.Records), jc.DeepEquals, []string{"one", "two"})
+	next := result.Next
+	result = s.query(c, url.Values{"limit": {"2"}, "after": {next}})
+	c.Assert(messages(result.Records), jc.DeepEquals, []string{"three"})
+	next = result.Next
+	result = s.query(c, url.Values{"limit": {"2"}, "after": {next}})
+	c.Assert(result.Records, gc.HasLen, 0)
+	c.Assert(result.Next, gc.Equals, next)
+}
+
+func (s *logQuerySuite) TestQueryFiltering(c *gc.C) {
+	start := s.start
+	s.writeLogs(c, loggo.INFO, "one", "two")
+	s.writeLogs(c, loggo.ERROR, "three", "four", "five")
+
+	result := s.query(c, url.Values{
+		"level": {"ERROR"},
+		"start": {start.Add(3 * time.Second).Format(time.RFC3339)},
+		"end":   {start.Add(4 * time.Second).Format(time.RFC3339)},
+	})
+	c.Assert(messages(result.Records), jc.DeepEquals, []string{"four"})
+	result = s.query(c, url.Values{"excludeModule": {"juju"}})
+	c.Assert(result.Records, gc.HasLen, 0)
+}
+
+func (s *logQuerySuite) TestBadParams(c *gc.C) {
+	for i, test := range []struct {
+		query url.Values
+		err   string
+	}{{
+		query: url.Values{"start": {"yesterday"}},
+		err:   `start value "yesterday" is not a valid RFC3339 time`,
+	}, {
+		query: url.Values{"limit": {"-1"}},
+		err:   `limit value "-1" is not a valid unsigned number`,
+	}, {
+		query: url.Values{"level": {"LOUD"}},
+		err:   `level value "LOUD" is not one of .*`,
+	}, {
+		query: url.Values{"after": {"nowhere"}},
+		err:   `log query token "nowhere" not valid`,
+	}, {
+		query: url.Values{"follow": {"true"}, "end": {"2015-06-01T00:00:00Z"}},
+		err:   `cannot follow logs with an end time`,
+	}} {
+		c.Logf("test %d: %v", i, test.query)
+		resp := s.authRequest(c, httpRequestParams{method: "GET", url: s.logQueryURL(c, test.query)})
+		s.assertErrorResponse(c, resp, http.StatusBadRequest, test.err)
+	}
+}
+
+func (s *logQuerySuite) TestFollow(c *gc.C) {
+	s.writeLogs(c, loggo.INFO, "one")
+
+	resp := s.authRequest(c, httpRequestParams{
+		method: "GET",
+		url:    s.logQueryURL(c, url.Values{"follow": {"true"}}),
+	})
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	reader := bufio.NewReader(resp.Body)
+	nextPage := func() params.LogQueryResult {
+		line, err := reader.ReadSlice('\n')
+		c.Assert(err, jc.ErrorIsNil)
+		var result params.LogQueryResult
+		err = json.Unmarshal(line, &result)
+		c.Assert(err, jc.ErrorIsNil)
+		return result
+	}
+
+	c.Assert(messages(nextPage().Records), jc.DeepEquals, []string{"one"})
+	s.writeLogs(c, loggo.INFO, "two", "three")
+	// The new logs may be sent in one page or two.
+	var got []string
+	for len(got) < 2 {
+		got = append(got, messages(nextPage().Records)...)
+	}
+	c.Assert(got, jc.DeepEquals, []string{"two", "three"})
+}
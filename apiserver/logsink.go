@@ -14,10 +14,10 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/utils"
 	"golang.org/x/net/websocket"
-	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/state"
 )
 
@@ -32,9 +32,9 @@ func newLogSinkHandler(h httpContext, logDir string) http.Handler {
 
 	return &logSinkHandler{
 		ctxt: h,
-		fileLogger: &lumberjack.Logger{
+		fileLogger: &logging.RotatingFile{
 			Filename:   logPath,
-			MaxSize:    500, // MB
+			MaxSize:    500 * logging.Megabyte,
 			MaxBackups: 1,
 		},
 	}
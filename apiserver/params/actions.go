@@ -30,6 +30,10 @@ const (
 	// ActionRunning is the status of an Action that has been started but
 	// not completed yet.
 	ActionRunning string = "running"
+
+	// ActionAborting is the status of an Action that was cancelled while
+	// running, and is being stopped by its receiver's agent.
+	ActionAborting string = "aborting"
 )
 
 // Actions is a slice of Action for bulk requests.
@@ -43,6 +47,10 @@ type Action struct {
 	Receiver   string                 `json:"receiver"`
 	Name       string                 `json:"name"`
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
+
+	// Timeout is how long the Action may run for before it is
+	// stopped; zero means no limit.
+	Timeout time.Duration `json:"timeout,omitempty"`
 }
 
 // ActionResults is a slice of ActionResult for bulk requests.
@@ -60,6 +68,10 @@ type ActionResult struct {
 	Message   string                 `json:"message,omitempty"`
 	Output    map[string]interface{} `json:"output,omitempty"`
 	Error     *Error                 `json:"error,omitempty"`
+
+	// Queued is true while a pending Action waits in its receiver's
+	// queue for earlier Actions to finish.
+	Queued bool `json:"queued,omitempty"`
 }
 
 // ActionsByReceivers wrap a slice of Actions for API calls.
@@ -83,6 +83,7 @@ const (
 	CodeMethodNotAllowed          = "method not allowed"
 	CodeForbidden                 = "forbidden"
 	CodeDischargeRequired         = "macaroon discharge required"
+	CodeRateLimitExceeded         = "rate limit exceeded"
 )
 
 // ErrCode returns the error code associated with
@@ -182,6 +183,10 @@ func IsCodeTryAgain(err error) bool {
 	return ErrCode(err) == CodeTryAgain
 }
 
+func IsCodeRateLimitExceeded(err error) bool {
+	return ErrCode(err) == CodeRateLimitExceeded
+}
+
 func IsCodeNotImplemented(err error) bool {
 	return ErrCode(err) == CodeNotImplemented
 }
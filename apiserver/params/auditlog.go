@@ -0,0 +1,56 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// AuditLogFilter selects the entries returned by AuditLog.Entries.
+// Empty fields select all entries.
+type AuditLogFilter struct {
+	// Caller holds the tag of the entity that made the calls.
+	Caller string `json:"caller,omitempty"`
+
+	// Facade and Method identify the methods called.
+	Facade string `json:"facade,omitempty"`
+	Method string `json:"method,omitempty"`
+
+	// Since selects the calls made at or after the given time.
+	Since *time.Time `json:"since,omitempty"`
+
+	// Limit is the maximum number of entries returned. If it is zero,
+	// the server's default is used.
+	Limit int `json:"limit,omitempty"`
+
+	// AllEnvironments selects the calls made in every environment,
+	// rather than only those made in the environment connected to.
+	AllEnvironments bool `json:"all-environments,omitempty"`
+}
+
+// AuditLogEntry records a call made to the API server.
+type AuditLogEntry struct {
+	Time    time.Time `json:"time"`
+	EnvUUID string    `json:"env-uuid"`
+	Caller  string    `json:"caller"`
+	Facade  string    `json:"facade"`
+	Version int       `json:"version"`
+	Method  string    `json:"method"`
+
+	// EntityTags holds the tags of the entities named in the call's
+	// parameters.
+	EntityTags []string `json:"entity-tags,omitempty"`
+
+	// Params holds the JSON encoding of the call's parameters, with
+	// any sensitive values redacted.
+	Params string `json:"params,omitempty"`
+
+	// ResultCode is "ok" if the call succeeded. Otherwise it holds the
+	// code of the error returned, or "error" if that has no code.
+	ResultCode string `json:"result-code"`
+}
+
+// AuditLogEntries holds the entries returned by AuditLog.Entries,
+// newest first.
+type AuditLogEntries struct {
+	Entries []AuditLogEntry `json:"entries"`
+}
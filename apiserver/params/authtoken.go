@@ -0,0 +1,46 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// IssueAuthToken holds the arguments for AuthToken.Issue.
+type IssueAuthToken struct {
+	// Scopes holds the facades, as "Facade", or facade methods, as
+	// "Facade.Method", that the token will permit calls to.
+	Scopes []string `json:"scopes"`
+
+	// Expires holds when the token will stop being valid.
+	Expires time.Time `json:"expires"`
+}
+
+// IssuedAuthToken holds a token returned by AuthToken.Issue.
+type IssuedAuthToken struct {
+	// Id identifies the token, so that it may later be revoked.
+	Id string `json:"id"`
+
+	// Token holds the bearer token to log in with.
+	Token string `json:"token"`
+}
+
+// AuthToken describes an issued token. The token itself is only
+// returned when it is issued.
+type AuthToken struct {
+	Id      string    `json:"id"`
+	User    string    `json:"user"`
+	Scopes  []string  `json:"scopes"`
+	Issued  time.Time `json:"issued"`
+	Expires time.Time `json:"expires"`
+	Revoked bool      `json:"revoked,omitempty"`
+}
+
+// AuthTokens holds the tokens returned by AuthToken.Tokens.
+type AuthTokens struct {
+	Tokens []AuthToken `json:"tokens"`
+}
+
+// AuthTokenIds holds the ids of tokens to revoke.
+type AuthTokenIds struct {
+	Ids []string `json:"ids"`
+}
@@ -39,12 +39,18 @@ type EnvironAction string
 const (
 	AddEnvUser    EnvironAction = "add"
 	RemoveEnvUser EnvironAction = "remove"
+	GrantEnvUser  EnvironAction = "grant"
+	RevokeEnvUser EnvironAction = "revoke"
 )
 
 // ModifyEnvironUser stores the parameters used for a Client.ShareEnvironment call.
+// Access is the level of access ("read", "write" or "admin") to add,
+// grant or revoke. If it is empty, adding a user grants admin access
+// and revoking removes the user's access entirely.
 type ModifyEnvironUser struct {
 	UserTag string        `json:"user-tag"`
 	Action  EnvironAction `json:"action"`
+	Access  string        `json:"access,omitempty"`
 }
 
 // SetEnvironAgentVersion contains the arguments for
@@ -60,6 +66,7 @@ type EnvUserInfo struct {
 	CreatedBy      string     `json:"createdby"`
 	DateCreated    time.Time  `json:"datecreated"`
 	LastConnection *time.Time `json:"lastconnection"`
+	Access         string     `json:"access"`
 }
 
 // EnvUserInfoResult holds the result of an EnvUserInfo call.
@@ -72,3 +79,25 @@ type EnvUserInfoResult struct {
 type EnvUserInfoResults struct {
 	Results []EnvUserInfoResult `json:"results"`
 }
+
+// CredentialStatus holds whether an environment's cloud credentials
+// were accepted by the cloud when last checked, and if not, why not.
+type CredentialStatus struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// UpdateCredentials holds the parameters for a SystemManager
+// UpdateCredentials call. Credentials holds the new values of the
+// environment's credential attributes, such as "access-key" and
+// "secret-key".
+type UpdateCredentials struct {
+	EnvironTag  string            `json:"environ-tag"`
+	Credentials map[string]string `json:"credentials"`
+}
+
+// UpdateCredentialsResult holds the tags of the environments whose
+// credentials were updated by a SystemManager UpdateCredentials call.
+type UpdateCredentialsResult struct {
+	EnvironTags []string `json:"environ-tags"`
+}
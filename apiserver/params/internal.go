@@ -360,6 +360,91 @@ type SetStatus struct {
 	Entities []EntityStatusArgs
 }
 
+// EntityWorkloadVersion holds the workload version for an entity.
+type EntityWorkloadVersion struct {
+	Tag             string
+	WorkloadVersion string
+}
+
+// EntityWorkloadVersions holds the parameters for making a
+// SetWorkloadVersion call.
+type EntityWorkloadVersions struct {
+	Entities []EntityWorkloadVersion
+}
+
+// SetUnitCharmState holds changes to the key/value state stored by a
+// unit's charm: keys in Set are given their values, and keys in Unset
+// are removed.
+type SetUnitCharmState struct {
+	Tag   string
+	Set   map[string]string
+	Unset []string
+}
+
+// SetUnitCharmStateArgs holds the parameters for making a SetCharmState
+// call.
+type SetUnitCharmStateArgs struct {
+	Args []SetUnitCharmState
+}
+
+// CharmStateResult holds the key/value state stored by a unit's charm,
+// or an error.
+type CharmStateResult struct {
+	Error *Error
+	State map[string]string
+}
+
+// CharmStateResults holds the results of a CharmState call.
+type CharmStateResults struct {
+	Results []CharmStateResult
+}
+
+// HookRetryStrategy describes how a unit agent retries failed hooks.
+type HookRetryStrategy struct {
+	ShouldRetry   bool
+	MaxAttempts   int
+	MinDelay      time.Duration
+	MaxDelay      time.Duration
+	BackoffFactor float64
+}
+
+// HookRetryStrategyResult holds a HookRetryStrategy or an error.
+type HookRetryStrategyResult struct {
+	Error  *Error
+	Result *HookRetryStrategy
+}
+
+// HookRetryStrategyResults holds the results of a HookRetryStrategy
+// call.
+type HookRetryStrategyResults struct {
+	Results []HookRetryStrategyResult
+}
+
+// UnitNetworkConfig holds a unit tag and the name of one of the
+// relation endpoints of the unit's charm.
+type UnitNetworkConfig struct {
+	UnitTag     string
+	BindingName string
+}
+
+// UnitsNetworkConfig holds the parameters for making a NetworkConfig
+// call.
+type UnitsNetworkConfig struct {
+	Args []UnitNetworkConfig
+}
+
+// UnitNetworkConfigResult holds the network configuration of a unit
+// for one of its charm's endpoints, or an error.
+type UnitNetworkConfigResult struct {
+	Error  *Error
+	Config []NetworkConfig
+}
+
+// UnitNetworkConfigResults holds the results of a NetworkConfig call.
+type UnitNetworkConfigResults struct {
+	Results []UnitNetworkConfigResult
+}
+
 // InstanceStatus holds an entity tag and instance status.
 type InstanceStatus struct {
 	Tag    string
@@ -531,12 +616,16 @@ type CharmsResponse struct {
 // RunParams is used to provide the parameters to the Run method.
 // Commands and Timeout are expected to have values, and one or more
 // values should be in the Machines, Services, or Units slices.
+//
+// Timeout applies to each target separately. If MaxConcurrency is
+// positive, the commands are run on at most that many targets at once.
 type RunParams struct {
-	Commands string
-	Timeout  time.Duration
-	Machines []string
-	Services []string
-	Units    []string
+	Commands       string
+	Timeout        time.Duration
+	Machines       []string
+	Services       []string
+	Units          []string
+	MaxConcurrency int `json:",omitempty"`
 }
 
 // RunResult contains the result from an individual run call on a machine.
@@ -549,9 +638,16 @@ type RunResult struct {
 }
 
 // RunResults is used to return the slice of results.  API server side calls
-// need to return single structure values.
+// need to return single structure values. Id, if set, identifies the
+// recorded results so that they can be retrieved later.
 type RunResults struct {
 	Results []RunResult
+	Id      string `json:",omitempty"`
+}
+
+// RunResultsId identifies a recorded set of run results.
+type RunResultsId struct {
+	Id string
 }
 
 // AgentVersionResult is used to return the current version number of the
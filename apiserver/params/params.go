@@ -4,6 +4,7 @@
 package params
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -204,6 +205,9 @@ type ServiceDeploy struct {
 	Placement     []*instance.Placement
 	Networks      []string
 	Storage       map[string]storage.Constraints
+	// EndpointBindings maps charm relation endpoint names to the
+	// spaces they are bound to.
+	EndpointBindings map[string]string `json:",omitempty"`
 }
 
 // ServiceUpdate holds the parameters for making the ServiceUpdate call.
@@ -312,6 +316,29 @@ type PrivateAddressResults struct {
 	PrivateAddress string
 }
 
+// SSHHostKeys holds parameters for the SSHHostKeys call.
+type SSHHostKeys struct {
+	Target string
+}
+
+// SSHHostKeysResults holds results of the SSHHostKeys call.
+type SSHHostKeysResults struct {
+	Keys []string
+}
+
+// SSHHostKeySet holds the SSH host keys reported for one or more
+// machines.
+type SSHHostKeySet struct {
+	EntityKeys []SSHHostKeysArg
+}
+
+// SSHHostKeysArg holds the SSH host keys of the machine with the
+// given tag, in the authorized_keys format.
+type SSHHostKeysArg struct {
+	Tag        string
+	PublicKeys []string
+}
+
 // Resolved holds parameters for the Resolved call.
 type Resolved struct {
 	UnitName string
@@ -362,12 +389,14 @@ type Creds struct {
 // any one is valid, the authentication succeeds). If there are no
 // valid macaroons and macaroon authentication is configured,
 // the LoginResponse will contain a macaroon that when
-// discharged, may allow access.
+// discharged, may allow access. If Token is set, it holds a bearer
+// token issued by the AuthToken facade, which is used instead.
 type LoginRequest struct {
 	AuthTag     string           `json:"auth-tag"`
 	Credentials string           `json:"credentials"`
 	Nonce       string           `json:"nonce"`
 	Macaroons   []macaroon.Slice `json:"macaroons"`
+	Token       string           `json:"token,omitempty"`
 }
 
 // LoginRequestCompat holds credentials for identifying an entity to the Login v1
@@ -435,6 +464,38 @@ type AllWatcherNextResults struct {
 	Deltas []multiwatcher.Delta
 }
 
+// WatchStreamFrame holds the changes sent in one message of a watch
+// stream.
+type WatchStreamFrame struct {
+	// ResumeToken identifies the frame. A client that reconnects
+	// with it is sent the frames that followed this one.
+	ResumeToken string
+
+	// Resync is true if the frame holds every entity the client
+	// should know about, in full. The client must discard any
+	// entities it held before.
+	Resync bool
+
+	// Deltas holds the changes to each entity.
+	Deltas []WatchStreamDelta
+}
+
+// WatchStreamDelta holds the change to an entity sent on a watch
+// stream.
+type WatchStreamDelta struct {
+	Kind    string
+	EnvUUID string
+	Id      string
+
+	// Removed is true if the entity has been removed.
+	Removed bool
+
+	// Fields holds the JSON encoding of each field of the entity
+	// that has changed since the client last saw it, or of every
+	// field if the entity is new to the client.
+	Fields map[string]json.RawMessage
+}
+
 // ListSSHKeys stores parameters used for a KeyManager.ListKeys call.
 type ListSSHKeys struct {
 	Entities
@@ -499,6 +560,8 @@ type ContainerConfig struct {
 	Proxy                   proxy.Settings
 	AptProxy                proxy.Settings
 	AptMirror               string
+	PackageSources          []string
+	PackageSourceKeys       string
 	PreferIPv6              bool
 	AllowLXCLoopMounts      bool
 	*UpdateBehavior
@@ -771,6 +834,26 @@ type LogRecord struct {
 	Message  string      `json:"x"`
 }
 
+// LogQueryRecord holds a log message returned by the log query API
+// endpoint.
+type LogQueryRecord struct {
+	Time     time.Time `json:"time"`
+	Entity   string    `json:"entity"`
+	Module   string    `json:"module"`
+	Location string    `json:"location"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+}
+
+// LogQueryResult holds a page of logs returned by the log query API
+// endpoint. Next holds the token to pass as the "after" query
+// parameter to fetch the logs that follow.
+type LogQueryResult struct {
+	Records []LogQueryRecord `json:"records"`
+	Next    string           `json:"next,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
 // GetBundleChangesParams holds parameters for making GetBundleChanges calls.
 type GetBundleChangesParams struct {
 	// BundleDataYAML is the YAML-encoded charm bundle data
@@ -0,0 +1,59 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// ResourceRevisionHeader is the HTTP header in which the revision of a
+// resource downloaded from the API server is returned.
+const ResourceRevisionHeader = "Juju-Resource-Revision"
+
+// Resource describes a revision of a resource attached to a service.
+type Resource struct {
+	ServiceTag string    `json:"service-tag"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Revision   int       `json:"revision"`
+	Size       int64     `json:"size,omitempty"`
+	SHA256     string    `json:"sha256,omitempty"`
+	ImageRef   string    `json:"image-ref,omitempty"`
+	Added      time.Time `json:"added"`
+
+	// Pinned records whether the service's units use the revision
+	// because it is pinned, rather than because it is the latest.
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// ResourceResult holds a resource, or an error. It is returned by the
+// resources HTTP endpoints.
+type ResourceResult struct {
+	Resource *Resource `json:"resource,omitempty"`
+	Error    *Error    `json:"error,omitempty"`
+}
+
+// ServiceResourcesResult holds the resources a service's units use, or
+// an error.
+type ServiceResourcesResult struct {
+	Resources []Resource `json:"resources"`
+	Error     *Error     `json:"error,omitempty"`
+}
+
+// ServiceResourcesResults holds the results of
+// Resources.ServiceResources.
+type ServiceResourcesResults struct {
+	Results []ServiceResourcesResult `json:"results"`
+}
+
+// PinResource holds the arguments for pinning a service's resource to
+// a revision. A zero Revision unpins the resource.
+type PinResource struct {
+	ServiceTag string `json:"service-tag"`
+	Name       string `json:"name"`
+	Revision   int    `json:"revision,omitempty"`
+}
+
+// PinResources holds the arguments for Resources.PinResources.
+type PinResources struct {
+	Args []PinResource `json:"args"`
+}
@@ -0,0 +1,197 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// AddServiceOffer holds the arguments for offering a service's
+// endpoints to other environments.
+type AddServiceOffer struct {
+	// OfferName names the offer.
+	OfferName string `json:"offer-name"`
+
+	// ServiceName is the name of the service to offer.
+	ServiceName string `json:"service-name"`
+
+	// Endpoints holds the names of the endpoints to offer.
+	Endpoints []string `json:"endpoints"`
+
+	// Description describes the offer to potential consumers.
+	Description string `json:"description,omitempty"`
+
+	// UserTags holds the tags of the users, other than the one making
+	// the offer, who may consume it.
+	UserTags []string `json:"user-tags,omitempty"`
+}
+
+// AddServiceOffers holds the arguments for ServiceOffers.Offer.
+type AddServiceOffers struct {
+	Offers []AddServiceOffer `json:"offers"`
+}
+
+// ServiceOffer describes an offer of a service's endpoints.
+type ServiceOffer struct {
+	URL             string   `json:"url"`
+	EnvironTag      string   `json:"environ-tag"`
+	ServiceName     string   `json:"service-name"`
+	Endpoints       []string `json:"endpoints"`
+	Description     string   `json:"description,omitempty"`
+	OwnerTag        string   `json:"owner-tag"`
+	AllowedUserTags []string `json:"allowed-user-tags,omitempty"`
+}
+
+// ServiceOfferResult holds an offer, or an error.
+type ServiceOfferResult struct {
+	Result *ServiceOffer `json:"result,omitempty"`
+	Error  *Error        `json:"error,omitempty"`
+}
+
+// ServiceOfferResults holds the results of ServiceOffers.Offer.
+type ServiceOfferResults struct {
+	Results []ServiceOfferResult `json:"results"`
+}
+
+// ServiceOfferFilter restricts the offers returned by
+// ServiceOffers.ListOffers. Empty fields match any offer.
+type ServiceOfferFilter struct {
+	EnvironTag  string `json:"environ-tag,omitempty"`
+	ServiceName string `json:"service-name,omitempty"`
+}
+
+// ServiceOffers holds the offers returned by ServiceOffers.ListOffers.
+type ServiceOffers struct {
+	Offers []ServiceOffer `json:"offers"`
+}
+
+// ServiceOfferURLs holds the URLs of offers.
+type ServiceOfferURLs struct {
+	URLs []string `json:"urls"`
+}
+
+// OfferAction is a change that can be made to who may consume an
+// offer.
+type OfferAction string
+
+// Changes that can be made to who may consume an offer.
+const (
+	GrantOfferAccess  OfferAction = "grant"
+	RevokeOfferAccess OfferAction = "revoke"
+)
+
+// ModifyOfferAccess holds a change to who may consume an offer.
+type ModifyOfferAccess struct {
+	URL     string      `json:"url"`
+	UserTag string      `json:"user-tag"`
+	Action  OfferAction `json:"action"`
+}
+
+// ModifyOfferAccessRequest holds the arguments for
+// ServiceOffers.ModifyOfferAccess.
+type ModifyOfferAccessRequest struct {
+	Changes []ModifyOfferAccess `json:"changes"`
+}
+
+// ConsumeServiceArg holds the arguments for consuming an offer.
+type ConsumeServiceArg struct {
+	// OfferURL is the URL of the offer to consume.
+	OfferURL string `json:"offer-url"`
+
+	// ServiceAlias is the name the remote service will have in the
+	// consuming environment. If empty, the offered service's name is
+	// used.
+	ServiceAlias string `json:"service-alias,omitempty"`
+}
+
+// ConsumeServiceArgs holds the arguments for ServiceOffers.Consume.
+type ConsumeServiceArgs struct {
+	Args []ConsumeServiceArg `json:"args"`
+}
+
+// RemoteEndpoint describes an endpoint of a remote service.
+type RemoteEndpoint struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Interface string `json:"interface"`
+	Limit     int    `json:"limit"`
+	Scope     string `json:"scope"`
+}
+
+// RemoteService describes a service, offered from another environment,
+// that is consumed by this one.
+type RemoteService struct {
+	Name             string           `json:"name"`
+	OfferURL         string           `json:"offer-url"`
+	SourceEnvironTag string           `json:"source-environ-tag"`
+	ConnectionId     string           `json:"connection-id"`
+	Endpoints        []RemoteEndpoint `json:"endpoints"`
+}
+
+// ConsumeServiceResult holds the remote service added by consuming an
+// offer, or an error.
+type ConsumeServiceResult struct {
+	Result *RemoteService `json:"result,omitempty"`
+	Error  *Error         `json:"error,omitempty"`
+}
+
+// ConsumeServiceResults holds the results of ServiceOffers.Consume.
+type ConsumeServiceResults struct {
+	Results []ConsumeServiceResult `json:"results"`
+}
+
+// OfferConnection describes the connection through which a remote
+// service consumes an offer.
+type OfferConnection struct {
+	Id                 string `json:"id"`
+	OfferURL           string `json:"offer-url"`
+	OfferEnvironTag    string `json:"offer-environ-tag"`
+	ConsumerEnvironTag string `json:"consumer-environ-tag"`
+	ServiceName        string `json:"service-name"`
+	UserTag            string `json:"user-tag"`
+}
+
+// OfferConnections holds the connections returned by
+// RemoteRelations.Connections.
+type OfferConnections struct {
+	Connections []OfferConnection `json:"connections"`
+}
+
+// RemoteUnitSettings holds the relation settings a unit publishes
+// across an offer connection.
+type RemoteUnitSettings struct {
+	ConnectionId string   `json:"connection-id"`
+	Unit         string   `json:"unit"`
+	Settings     Settings `json:"settings"`
+}
+
+// RemoteUnitSettingsArgs holds the arguments for
+// RemoteRelations.PublishSettings.
+type RemoteUnitSettingsArgs struct {
+	Args []RemoteUnitSettings `json:"args"`
+}
+
+// OfferConnectionIds holds the ids of offer connections.
+type OfferConnectionIds struct {
+	Ids []string `json:"ids"`
+}
+
+// RemoteSettingsResult holds the relation settings published, by
+// each unit on the other side of an offer connection, or an error.
+type RemoteSettingsResult struct {
+	Units map[string]Settings `json:"units,omitempty"`
+	Error *Error              `json:"error,omitempty"`
+}
+
+// RemoteSettingsResults holds the results of RemoteRelations.Settings.
+type RemoteSettingsResults struct {
+	Results []RemoteSettingsResult `json:"results"`
+}
+
+// RemoteUnit identifies a unit on one side of an offer connection.
+type RemoteUnit struct {
+	ConnectionId string `json:"connection-id"`
+	Unit         string `json:"unit"`
+}
+
+// RemoteUnits holds the arguments for RemoteRelations.RemoveSettings.
+type RemoteUnits struct {
+	Args []RemoteUnit `json:"args"`
+}
@@ -87,6 +87,10 @@ type UnitStatus struct {
 	// Workload holds the status for a unit's workload
 	Workload AgentStatus
 
+	// WorkloadVersion holds the version of the unit's workload
+	// software, as reported by its charm.
+	WorkloadVersion string
+
 	// Until Juju 2.0, we need to continue to return legacy agent state values
 	// as top level struct attributes when the "FullStatus" API is called.
 	AgentState     Status
@@ -307,6 +307,9 @@ func (p *ProvisionerAPI) ContainerConfig() (params.ContainerConfig, error) {
 	result.SSLHostnameVerification = config.SSLHostnameVerification()
 	result.Proxy = config.ProxySettings()
 	result.AptProxy = config.AptProxySettings()
+	result.AptMirror = config.AptMirror()
+	result.PackageSources = config.PackageSources()
+	result.PackageSourceKeys = config.PackageSourceKeys()
 	result.PreferIPv6 = config.PreferIPv6()
 	result.AllowLXCLoopMounts, _ = config.AllowLXCLoopMounts()
 
@@ -829,6 +832,22 @@ func (p *ProvisionerAPI) WatchMachineErrorRetry() (params.NotifyWatchResult, err
 	return result, nil
 }
 
+// CredentialStatus returns the last known validity of the
+// environment's cloud credentials, so the provisioner can avoid
+// starting instances with credentials the cloud has rejected.
+func (p *ProvisionerAPI) CredentialStatus() (params.CredentialStatus, error) {
+	result := params.CredentialStatus{}
+	if !p.authorizer.AuthEnvironManager() {
+		return result, common.ErrPerm
+	}
+	env, err := p.st.Environment()
+	if err != nil {
+		return result, err
+	}
+	result.Valid, result.Reason = env.CredentialValid()
+	return result, nil
+}
+
 // ReleaseContainerAddresses finds addresses allocated to a container
 // and marks them as Dead, to be released and removed. It accepts
 // container tags as arguments. If address allocation feature flag is
@@ -1385,6 +1385,9 @@ func (s *withoutStateServerSuite) TestContainerConfig(c *gc.C) {
 	attrs := map[string]interface{}{
 		"http-proxy":            "http://proxy.example.com:9000",
 		"allow-lxc-loop-mounts": true,
+		"apt-mirror":            "http://mirror.example.com/ubuntu",
+		"package-sources":       "ppa:juju/stable",
+		"package-source-keys":   "some-key",
 	}
 	err := s.State.UpdateEnvironConfig(attrs, nil, nil)
 	c.Assert(err, jc.ErrorIsNil)
@@ -1400,6 +1403,9 @@ func (s *withoutStateServerSuite) TestContainerConfig(c *gc.C) {
 	c.Check(results.SSLHostnameVerification, jc.IsTrue)
 	c.Check(results.Proxy, gc.DeepEquals, expectedProxy)
 	c.Check(results.AptProxy, gc.DeepEquals, expectedProxy)
+	c.Check(results.AptMirror, gc.Equals, "http://mirror.example.com/ubuntu")
+	c.Check(results.PackageSources, jc.DeepEquals, []string{"ppa:juju/stable"})
+	c.Check(results.PackageSourceKeys, gc.Equals, "some-key")
 	c.Check(results.PreferIPv6, jc.IsTrue)
 	c.Check(results.AllowLXCLoopMounts, jc.IsTrue)
 }
@@ -1558,6 +1564,29 @@ func (s *withoutStateServerSuite) TestWatchMachineErrorRetry(c *gc.C) {
 	c.Assert(result, gc.DeepEquals, params.NotifyWatchResult{})
 }
 
+func (s *withoutStateServerSuite) TestCredentialStatus(c *gc.C) {
+	result, err := s.provisioner.CredentialStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, params.CredentialStatus{Valid: true})
+
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetCredentialValid(false, "expired")
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.provisioner.CredentialStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, params.CredentialStatus{Valid: false, Reason: "expired"})
+
+	// Make sure CredentialStatus fails with a machine agent login.
+	anAuthorizer := s.authorizer
+	anAuthorizer.Tag = names.NewMachineTag("1")
+	anAuthorizer.EnvironManager = false
+	aProvisioner, err := provisioner.NewProvisionerAPI(s.State, s.resources, anAuthorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = aProvisioner.CredentialStatus()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
 func (s *withoutStateServerSuite) TestFindTools(c *gc.C) {
 	args := params.FindToolsParams{
 		MajorVersion: -1,
@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// readMirrorMethods holds the methods that are served from a read
+// mirror when the server is configured to use one. They must only read
+// from state, and must tolerate reading state that lags slightly behind
+// the primary. Watchers created by them are read from the mirror too;
+// the watcher facades themselves need no state.
+var readMirrorMethods = set.NewStrings(
+	"Client.AgentVersion",
+	"Client.CharmInfo",
+	"Client.EnvironmentGet",
+	"Client.EnvironmentInfo",
+	"Client.FullStatus",
+	"Client.GetAnnotations",
+	"Client.ServiceGet",
+	"Client.Status",
+	"Client.UnitStatusHistory",
+	"Client.WatchAll",
+)
+
+// readMirrors holds the read mirrors of the environments served by an
+// API server, each opened when it is first needed.
+type readMirrors struct {
+	mu      sync.Mutex
+	mirrors map[string]*state.State
+	closed  bool
+}
+
+// newReadMirrors returns a new, empty set of read mirrors.
+func newReadMirrors() *readMirrors {
+	return &readMirrors{
+		mirrors: make(map[string]*state.State),
+	}
+}
+
+// get returns the read mirror of the given state's environment,
+// opening it if necessary.
+func (m *readMirrors) get(st *state.State) (*state.State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, errors.New("read mirrors closed")
+	}
+	uuid := st.EnvironUUID()
+	if mirror, ok := m.mirrors[uuid]; ok {
+		return mirror, nil
+	}
+	mirror, err := st.ReadMirror()
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot open read mirror of environment %s", uuid)
+	}
+	logger.Infof("opened read mirror of environment %s", uuid)
+	m.mirrors[uuid] = mirror
+	return mirror, nil
+}
+
+// closeAll closes all the read mirrors. No more may be opened.
+func (m *readMirrors) closeAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	for uuid, mirror := range m.mirrors {
+		if err := mirror.Close(); err != nil {
+			logger.Errorf("error closing read mirror of environment %s: %v", uuid, err)
+		}
+		delete(m.mirrors, uuid)
+	}
+}
+
+// readMirrorRoot serves the methods in readMirrorMethods from a root
+// that reads from a read mirror, and all other methods from the
+// wrapped root, whose state reads from and writes to the primary.
+type readMirrorRoot struct {
+	rpc.MethodFinder
+
+	// newMirror returns the root to serve reads from. It is called
+	// when a read is first made, and again if it fails.
+	newMirror func() (rpc.MethodFinder, error)
+
+	mu     sync.Mutex
+	mirror rpc.MethodFinder
+}
+
+// newReadMirrorRoot returns a new readMirrorRoot that serves calls
+// from finder, and reads from the root returned by newMirror.
+func newReadMirrorRoot(finder rpc.MethodFinder, newMirror func() (rpc.MethodFinder, error)) *readMirrorRoot {
+	return &readMirrorRoot{
+		MethodFinder: finder,
+		newMirror:    newMirror,
+	}
+}
+
+// FindMethod implements rpc.MethodFinder. If the read mirror cannot be
+// used, reads are served by the wrapped root.
+func (r *readMirrorRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	if readMirrorMethods.Contains(rootName + "." + methodName) {
+		mirror, err := r.getMirror()
+		if err == nil {
+			return mirror.FindMethod(rootName, version, methodName)
+		}
+		logger.Warningf("serving %s.%s from primary: %v", rootName, methodName, err)
+	}
+	return r.MethodFinder.FindMethod(rootName, version, methodName)
+}
+
+func (r *readMirrorRoot) getMirror() (rpc.MethodFinder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mirror == nil {
+		mirror, err := r.newMirror()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.mirror = mirror
+	}
+	return r.mirror, nil
+}
+
+// Kill implements rpc.Killer, killing the wrapped root if it can be.
+// The mirror root shares its resources, so they are stopped too.
+func (r *readMirrorRoot) Kill() {
+	if killer, ok := r.MethodFinder.(rpc.Killer); ok {
+		killer.Kill()
+	}
+}
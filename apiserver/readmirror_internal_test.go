@@ -0,0 +1,78 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type readMirrorRootSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&readMirrorRootSuite{})
+
+func (s *readMirrorRootSuite) TestFindMethod(c *gc.C) {
+	primary := &recordingFinder{}
+	mirror := &recordingFinder{}
+	opened := 0
+	root := newReadMirrorRoot(primary, func() (rpc.MethodFinder, error) {
+		opened++
+		return mirror, nil
+	})
+	for _, method := range []string{"FullStatus", "AddMachines", "WatchAll", "FullStatus"} {
+		_, err := root.FindMethod("Client", 0, method)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(primary.calls, jc.DeepEquals, []string{"Client.AddMachines"})
+	c.Assert(mirror.calls, jc.DeepEquals, []string{"Client.FullStatus", "Client.WatchAll", "Client.FullStatus"})
+	c.Assert(opened, gc.Equals, 1)
+
+	root.Kill()
+	c.Assert(primary.killed, jc.IsTrue)
+}
+
+func (s *readMirrorRootSuite) TestFindMethodWithoutMirror(c *gc.C) {
+	primary := &recordingFinder{}
+	opened := 0
+	root := newReadMirrorRoot(primary, func() (rpc.MethodFinder, error) {
+		opened++
+		return nil, errors.New("no mirror")
+	})
+	for i := 0; i < 2; i++ {
+		_, err := root.FindMethod("Client", 0, "FullStatus")
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(primary.calls, jc.DeepEquals, []string{"Client.FullStatus", "Client.FullStatus"})
+	c.Assert(opened, gc.Equals, 2)
+}
+
+func (s *readMirrorRootSuite) TestReadMirrorsClosed(c *gc.C) {
+	mirrors := newReadMirrors()
+	mirrors.closeAll()
+	_, err := mirrors.get(nil)
+	c.Assert(err, gc.ErrorMatches, "read mirrors closed")
+}
+
+// recordingFinder is an rpc.MethodFinder that records the methods
+// looked up in it.
+type recordingFinder struct {
+	calls  []string
+	killed bool
+}
+
+func (f *recordingFinder) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	f.calls = append(f.calls, rootName+"."+methodName)
+	return auditCaller{}, nil
+}
+
+func (f *recordingFinder) Kill() {
+	f.killed = true
+}
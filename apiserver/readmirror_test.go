@@ -0,0 +1,70 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"net"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type readMirrorSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&readMirrorSuite{})
+
+func (s *readMirrorSuite) TestReadMirror(c *gc.C) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	srv, err := apiserver.NewServer(s.State, listener, apiserver.ServerConfig{
+		Cert:       []byte(coretesting.ServerCert),
+		Key:        []byte(coretesting.ServerKey),
+		Tag:        names.NewMachineTag("0"),
+		ReadMirror: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer srv.Stop()
+
+	info := s.APIInfo(c)
+	info.Addrs = []string{srv.Addr().String()}
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	client := st.Client()
+
+	// Writes are made as usual, and reads see them.
+	machines, err := client.AddMachines([]params.AddMachineParams{{
+		Jobs: []multiwatcher.MachineJob{multiwatcher.JobHostUnits},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Assert(machines[0].Error, gc.IsNil)
+
+	status, err := client.Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Machines, gc.HasLen, 1)
+
+	// Watchers are read from the mirror.
+	watcher, err := client.WatchAll()
+	c.Assert(err, jc.ErrorIsNil)
+	defer watcher.Stop()
+	deltas, err := watcher.Next()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deltas, gc.Not(gc.HasLen), 0)
+
+	machine, err := s.State.Machine(machines[0].Machine)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Jobs(), jc.DeepEquals, []state.MachineJob{state.JobHostUnits})
+}
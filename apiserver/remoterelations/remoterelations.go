@@ -0,0 +1,295 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The remoterelations package implements the API facade used to pass
+// relation settings between the units of a service offered from one
+// environment and the units that consume it in another.
+package remoterelations
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("RemoteRelations", 1, NewAPI)
+}
+
+// API implements the RemoteRelations facade. Each side of an offer
+// connection publishes its units' settings through the API of its own
+// environment, and reads those published by the other side. The
+// facade may be used by clients, and by the agents of the units whose
+// settings are passed.
+type API struct {
+	st *state.State
+
+	// user is the logged in user, if a client is logged in.
+	user names.UserTag
+
+	// unit is the logged in unit, if a unit agent is logged in.
+	unit names.UnitTag
+}
+
+// NewAPI returns a new RemoteRelations API, which acts for the logged
+// in user or unit agent.
+func NewAPI(st *state.State, _ *common.Resources, authorizer common.Authorizer) (*API, error) {
+	api := &API{st: st}
+	switch tag := authorizer.GetAuthTag().(type) {
+	case names.UserTag:
+		if !authorizer.AuthClient() {
+			return nil, common.ErrPerm
+		}
+		api.user = tag
+	case names.UnitTag:
+		if !authorizer.AuthUnitAgent() {
+			return nil, common.ErrPerm
+		}
+		api.unit = tag
+	default:
+		return nil, common.ErrPerm
+	}
+	return api, nil
+}
+
+// Connections returns the offer connections this environment is on
+// either side of, and that the logged in user may pass settings
+// through.
+func (api *API) Connections() (params.OfferConnections, error) {
+	var result params.OfferConnections
+	conns, err := api.st.OfferConnections()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Connections = []params.OfferConnection{}
+	for _, conn := range conns {
+		side, err := conn.Side(api.st.EnvironUUID())
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		if err := api.checkCanUse(conn, side); err == common.ErrPerm {
+			continue
+		} else if err != nil {
+			return result, errors.Trace(err)
+		}
+		result.Connections = append(result.Connections, params.OfferConnection{
+			Id:                 conn.Id,
+			OfferURL:           conn.OfferURL,
+			OfferEnvironTag:    names.NewEnvironTag(conn.OfferEnvUUID).String(),
+			ConsumerEnvironTag: names.NewEnvironTag(conn.ConsumerEnvUUID).String(),
+			ServiceName:        conn.ServiceName,
+			UserTag:            conn.User,
+		})
+	}
+	return result, nil
+}
+
+// PublishSettings publishes the relation settings of units in this
+// environment across offer connections, replacing any they published
+// before.
+func (api *API) PublishSettings(args params.RemoteUnitSettingsArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		result.Results[i].Error = common.ServerError(api.publishSettings(arg))
+	}
+	return result, nil
+}
+
+func (api *API) publishSettings(arg params.RemoteUnitSettings) error {
+	if !names.IsValidUnit(arg.Unit) {
+		return errors.NotValidf("unit name %q", arg.Unit)
+	}
+	if err := api.checkUnit(arg.Unit); err != nil {
+		return errors.Trace(err)
+	}
+	side, err := api.connectionSide(arg.ConnectionId)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	settings := make(map[string]interface{}, len(arg.Settings))
+	for key, value := range arg.Settings {
+		settings[key] = value
+	}
+	err = api.st.SetRemoteUnitSettings(arg.ConnectionId, side, arg.Unit, settings)
+	return errors.Trace(err)
+}
+
+// RemoveSettings removes the relation settings published by units in
+// this environment, when they leave the relation.
+func (api *API) RemoveSettings(args params.RemoteUnits) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		err := api.checkUnit(arg.Unit)
+		var side state.ConnectionSide
+		if err == nil {
+			side, err = api.connectionSide(arg.ConnectionId)
+		}
+		if err == nil {
+			err = api.st.RemoveRemoteUnitSettings(arg.ConnectionId, side, arg.Unit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// Settings returns the relation settings published by the units on
+// the other side of each of the given offer connections.
+func (api *API) Settings(args params.OfferConnectionIds) (params.RemoteSettingsResults, error) {
+	result := params.RemoteSettingsResults{
+		Results: make([]params.RemoteSettingsResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		units, err := api.settings(id)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Units = units
+	}
+	return result, nil
+}
+
+func (api *API) settings(connId string) (map[string]params.Settings, error) {
+	side, err := api.connectionSide(connId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	units, err := api.st.RemoteUnitSettings(connId, side.Other())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make(map[string]params.Settings, len(units))
+	for unit, settings := range units {
+		converted := make(params.Settings, len(settings))
+		for key, value := range settings {
+			if s, ok := value.(string); ok {
+				converted[key] = s
+			} else {
+				converted[key] = fmt.Sprint(value)
+			}
+		}
+		result[unit] = converted
+	}
+	return result, nil
+}
+
+// connectionSide returns the side of the given offer connection that
+// this environment is on, if the logged in user may pass settings
+// through it.
+func (api *API) connectionSide(connId string) (state.ConnectionSide, error) {
+	conn, err := api.st.OfferConnection(connId)
+	if errors.IsNotFound(err) {
+		return "", common.ErrPerm
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	side, err := conn.Side(api.st.EnvironUUID())
+	if errors.IsNotFound(err) {
+		return "", common.ErrPerm
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := api.checkCanUse(conn, side); err != nil {
+		return "", errors.Trace(err)
+	}
+	return side, nil
+}
+
+// checkUnit returns common.ErrPerm if a unit agent is logged in, and
+// the named unit is not its own: a unit agent may only pass its own
+// settings.
+func (api *API) checkUnit(unitName string) error {
+	if api.unit.Id() != "" && unitName != api.unit.Id() {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// checkCanUse returns common.ErrPerm unless the logged in user or unit
+// agent may pass settings through the given side of an offer
+// connection.
+func (api *API) checkCanUse(conn state.OfferConnection, side state.ConnectionSide) error {
+	if api.unit.Id() != "" {
+		return api.checkUnitCanUse(conn, side)
+	}
+	return api.checkUserCanUse(conn, side)
+}
+
+// checkUserCanUse returns common.ErrPerm unless the logged in user may
+// pass settings through the given side of an offer connection. The
+// consuming side may be used by the user that consumed the offer, and
+// the offering side by the offer's owner. System administrators may
+// use either side.
+func (api *API) checkUserCanUse(conn state.OfferConnection, side state.ConnectionSide) error {
+	switch side {
+	case state.ConsumingSide:
+		if conn.User == api.user.String() {
+			return nil
+		}
+	case state.OfferingSide:
+		offer, err := api.st.ServiceOffer(conn.OfferURL)
+		if err == nil && offer.Owner == api.user.String() {
+			return nil
+		} else if err != nil && !errors.IsNotFound(err) {
+			return errors.Trace(err)
+		}
+	}
+	isAdmin, err := api.st.IsSystemAdministrator(api.user)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !isAdmin {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// checkUnitCanUse returns common.ErrPerm unless the logged in unit
+// agent may pass settings through the given side of an offer
+// connection. On the offering side, the units of the offered service
+// may do so; on the consuming side, the units of the services related
+// to the remote service.
+func (api *API) checkUnitCanUse(conn state.OfferConnection, side state.ConnectionSide) error {
+	serviceName, err := names.UnitService(api.unit.Id())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	switch side {
+	case state.OfferingSide:
+		offer, err := api.st.ServiceOffer(conn.OfferURL)
+		if errors.IsNotFound(err) {
+			return common.ErrPerm
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if offer.ServiceName == serviceName {
+			return nil
+		}
+	case state.ConsumingSide:
+		svc, err := api.st.Service(serviceName)
+		if errors.IsNotFound(err) {
+			return common.ErrPerm
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		relations, err := svc.Relations()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, rel := range relations {
+			if _, err := rel.Endpoint(conn.ServiceName); err == nil {
+				return nil
+			}
+		}
+	}
+	return common.ErrPerm
+}
@@ -0,0 +1,272 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/remoterelations"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type remoteRelationsSuite struct {
+	jujutesting.JujuConnSuite
+
+	consumer *state.State
+	bob      names.UserTag
+	connId   string
+}
+
+var _ = gc.Suite(&remoteRelationsSuite{})
+
+func (s *remoteRelationsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	s.bob = s.Factory.MakeUser(c, &factory.UserParams{
+		Name:      "bob",
+		NoEnvUser: true,
+	}).UserTag()
+	offer, err := s.State.AddServiceOffer(state.AddServiceOfferArgs{
+		OfferName:   "db",
+		ServiceName: "mysql",
+		Endpoints:   []string{"server"},
+		Owner:       s.AdminUserTag(c),
+		Users:       []names.UserTag{s.bob},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.consumer = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.consumer.Close() })
+	remote, err := s.consumer.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:     "remotedb",
+		OfferURL: offer.URL,
+		Consumer: s.bob,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.connId = remote.ConnectionId()
+}
+
+func (s *remoteRelationsSuite) newAPI(c *gc.C, st *state.State, tag names.Tag) *remoterelations.API {
+	authorizer := apiservertesting.FakeAuthorizer{Tag: tag}
+	api, err := remoterelations.NewAPI(st, common.NewResources(), authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *remoteRelationsSuite) TestNewAPIRefusesMachineAgent(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("0"),
+	}
+	api, err := remoterelations.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *remoteRelationsSuite) TestConnections(c *gc.C) {
+	expect := []params.OfferConnection{{
+		Id:                 s.connId,
+		OfferURL:           state.ServiceOfferURL(s.AdminUserTag(c), s.envName(c), "db"),
+		OfferEnvironTag:    s.State.EnvironTag().String(),
+		ConsumerEnvironTag: s.consumer.EnvironTag().String(),
+		ServiceName:        "remotedb",
+		UserTag:            s.bob.String(),
+	}}
+	conns, err := s.newAPI(c, s.State, s.AdminUserTag(c)).Connections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conns.Connections, jc.DeepEquals, expect)
+
+	conns, err = s.newAPI(c, s.consumer, s.bob).Connections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conns.Connections, jc.DeepEquals, expect)
+
+	// Bob did not make the offer, so cannot use its side.
+	conns, err = s.newAPI(c, s.State, s.bob).Connections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conns.Connections, gc.HasLen, 0)
+}
+
+func (s *remoteRelationsSuite) envName(c *gc.C) string {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	return env.Name()
+}
+
+func (s *remoteRelationsSuite) TestSettingsExchange(c *gc.C) {
+	offering := s.newAPI(c, s.State, s.AdminUserTag(c))
+	consuming := s.newAPI(c, s.consumer, s.bob)
+
+	results, err := consuming.PublishSettings(params.RemoteUnitSettingsArgs{
+		Args: []params.RemoteUnitSettings{{
+			ConnectionId: s.connId,
+			Unit:         "wordpress/0",
+			Settings:     params.Settings{"user": "wp"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	results, err = offering.PublishSettings(params.RemoteUnitSettingsArgs{
+		Args: []params.RemoteUnitSettings{{
+			ConnectionId: s.connId,
+			Unit:         "mysql/0",
+			Settings:     params.Settings{"host": "10.0.0.1"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+
+	// Each side reads the settings published by the other.
+	settings, err := offering.Settings(params.OfferConnectionIds{Ids: []string{s.connId}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, params.RemoteSettingsResults{
+		Results: []params.RemoteSettingsResult{{
+			Units: map[string]params.Settings{"wordpress/0": {"user": "wp"}},
+		}},
+	})
+	settings, err = consuming.Settings(params.OfferConnectionIds{Ids: []string{s.connId}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, params.RemoteSettingsResults{
+		Results: []params.RemoteSettingsResult{{
+			Units: map[string]params.Settings{"mysql/0": {"host": "10.0.0.1"}},
+		}},
+	})
+
+	results, err = consuming.RemoveSettings(params.RemoteUnits{
+		Args: []params.RemoteUnit{{ConnectionId: s.connId, Unit: "wordpress/0"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	settings, err = offering.Settings(params.OfferConnectionIds{Ids: []string{s.connId}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings.Results[0].Error, gc.IsNil)
+	c.Assert(settings.Results[0].Units, gc.HasLen, 0)
+}
+
+func (s *remoteRelationsSuite) TestPermissionDenied(c *gc.C) {
+	mary := s.Factory.MakeUser(c, &factory.UserParams{
+		Name:      "mary",
+		NoEnvUser: true,
+	}).UserTag()
+	for i, test := range []struct {
+		st   *state.State
+		user names.UserTag
+		id   string
+	}{{
+		// Bob consumed the offer, but does not own it.
+		st:   s.State,
+		user: s.bob,
+		id:   s.connId,
+	}, {
+		// Mary did not consume the offer.
+		st:   s.consumer,
+		user: mary,
+		id:   s.connId,
+	}, {
+		st:   s.consumer,
+		user: s.bob,
+		id:   "nowhere:nothing",
+	}} {
+		c.Logf("test %d", i)
+		api := s.newAPI(c, test.st, test.user)
+		results, err := api.PublishSettings(params.RemoteUnitSettingsArgs{
+			Args: []params.RemoteUnitSettings{{
+				ConnectionId: test.id,
+				Unit:         "wordpress/0",
+				Settings:     params.Settings{"user": "wp"},
+			}},
+		})
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(results.OneError(), gc.ErrorMatches, "permission denied")
+		settings, err := api.Settings(params.OfferConnectionIds{Ids: []string{test.id}})
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(settings.Results[0].Error, gc.ErrorMatches, "permission denied")
+	}
+}
+
+func (s *remoteRelationsSuite) TestPublishSettingsInvalidUnit(c *gc.C) {
+	api := s.newAPI(c, s.consumer, s.bob)
+	results, err := api.PublishSettings(params.RemoteUnitSettingsArgs{
+		Args: []params.RemoteUnitSettings{{
+			ConnectionId: s.connId,
+			Unit:         "wordpress",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, `unit name "wordpress" not valid`)
+}
+
+func (s *remoteRelationsSuite) publish(c *gc.C, api *remoterelations.API, unit string) error {
+	results, err := api.PublishSettings(params.RemoteUnitSettingsArgs{
+		Args: []params.RemoteUnitSettings{{
+			ConnectionId: s.connId,
+			Unit:         unit,
+			Settings:     params.Settings{"unit": unit},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return results.OneError()
+}
+
+func (s *remoteRelationsSuite) TestUnitAgentOfferingSide(c *gc.C) {
+	api := s.newAPI(c, s.State, names.NewUnitTag("mysql/0"))
+	err := s.publish(c, api, "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A unit agent may only publish its own settings.
+	err = s.publish(c, api, "mysql/1")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	// The units of other services may not use the connection.
+	s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	api = s.newAPI(c, s.State, names.NewUnitTag("wordpress/0"))
+	err = s.publish(c, api, "wordpress/0")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	conns, err := api.Connections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conns.Connections, gc.HasLen, 0)
+}
+
+func (s *remoteRelationsSuite) TestUnitAgentConsumingSide(c *gc.C) {
+	f := factory.NewFactory(s.consumer)
+	f.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: f.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	api := s.newAPI(c, s.consumer, names.NewUnitTag("wordpress/0"))
+
+	// Units may use the connection once their service is related to
+	// the remote service.
+	err := s.publish(c, api, "wordpress/0")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	eps, err := s.consumer.InferEndpoints("wordpress", "remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.consumer.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.publish(c, api, "wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	conns, err := api.Connections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conns.Connections, gc.HasLen, 1)
+	c.Assert(conns.Connections[0].Id, gc.Equals, s.connId)
+
+	offering := s.newAPI(c, s.State, names.NewUnitTag("mysql/0"))
+	settings, err := offering.Settings(params.OfferConnectionIds{Ids: []string{s.connId}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings.Results[0].Units, jc.DeepEquals, map[string]params.Settings{
+		"wordpress/0": {"unit": "wordpress/0"},
+	})
+}
@@ -0,0 +1,263 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/charmresources"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+)
+
+// resourcesUploadHandler handles the upload of new revisions of
+// services' resources through HTTPS in the API server. The content of
+// file resources is held in the environment's storage, so that units
+// fetch it from the API server rather than from the internet.
+//
+// The resource type is given by the type query parameter, which
+// defaults to "file". The body of a file resource upload holds the
+// content, and its SHA256 may be given in the Digest header; an OCI
+// image resource names its image in the image query parameter.
+type resourcesUploadHandler struct {
+	ctxt httpContext
+}
+
+// ServeHTTP implements http.Handler.
+func (h *resourcesUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st, _, err := h.ctxt.stateForRequestAuthenticatedUser(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	switch r.Method {
+	case "POST":
+		res, err := h.processPost(r, st)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+		result := charmresources.ResourceParams(res, false)
+		sendStatusAndJSON(w, http.StatusOK, &params.ResourceResult{Resource: &result})
+	default:
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+	}
+}
+
+// processPost handles a resource upload POST request.
+func (h *resourcesUploadHandler) processPost(r *http.Request, st *state.State) (state.Resource, error) {
+	query := r.URL.Query()
+	args := state.AddResourceArgs{
+		ServiceName: query.Get(":service"),
+		Name:        query.Get(":name"),
+		Type:        state.ResourceType(query.Get("type")),
+		ImageRef:    query.Get("image"),
+	}
+	if args.Type == "" {
+		args.Type = state.ResourceTypeFile
+	}
+	if !state.IsValidResourceName(args.Name) {
+		return state.Resource{}, errors.BadRequestf("resource name %q not valid", args.Name)
+	}
+	if err := args.Type.Validate(); err != nil {
+		return state.Resource{}, errors.BadRequestf("%v", err)
+	}
+	if _, err := st.Service(args.ServiceName); err != nil {
+		return state.Resource{}, errors.Trace(err)
+	}
+	if args.Type != state.ResourceTypeFile {
+		return addResource(st, args)
+	}
+	if args.ImageRef != "" {
+		return state.Resource{}, errors.BadRequestf("file resource with image reference not valid")
+	}
+	if r.ContentLength < 0 {
+		return state.Resource{}, errors.BadRequestf("missing Content-Length")
+	}
+	expectSHA256, err := readDigestSHA(r.Header)
+	if err != nil {
+		return state.Resource{}, errors.Trace(err)
+	}
+
+	args.Size = r.ContentLength
+	args.StoragePath = state.ResourceStoragePath(args.ServiceName, args.Name)
+	stor := storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+	hash := sha256.New()
+	if err := stor.Put(args.StoragePath, io.TeeReader(r.Body, hash), args.Size); err != nil {
+		return state.Resource{}, errors.Annotate(err, "cannot store resource content")
+	}
+	args.SHA256 = fmt.Sprintf("%x", hash.Sum(nil))
+	if expectSHA256 != "" && args.SHA256 != expectSHA256 {
+		err = errors.BadRequestf("resource SHA256 %s does not match expected %s", args.SHA256, expectSHA256)
+	} else {
+		var res state.Resource
+		if res, err = addResource(st, args); err == nil {
+			return res, nil
+		}
+	}
+	if err := stor.Remove(args.StoragePath); err != nil {
+		logger.Errorf("cannot remove content of resource %q: %v", args.Name, err)
+	}
+	return state.Resource{}, errors.Trace(err)
+}
+
+// addResource adds a revision of a service's resource, reporting
+// invalid arguments as a bad request.
+func addResource(st *state.State, args state.AddResourceArgs) (state.Resource, error) {
+	res, err := st.AddResource(args)
+	if errors.IsNotValid(err) {
+		return state.Resource{}, errors.BadRequestf("%v", err)
+	}
+	return res, errors.Trace(err)
+}
+
+// readDigestSHA returns the SHA256 given in the Digest header, if any.
+func readDigestSHA(header http.Header) (string, error) {
+	digest := header.Get("Digest")
+	if digest == "" {
+		return "", nil
+	}
+	prefix := string(params.DigestSHA) + "="
+	if !strings.HasPrefix(digest, prefix) {
+		return "", errors.BadRequestf("unsupported digest %q", digest)
+	}
+	return strings.ToLower(digest[len(prefix):]), nil
+}
+
+// resourcesDownloadHandler handles the download by units of the
+// resources attached to their services. The revision of the resource
+// that the unit's service is to use is returned: the content of a file
+// resource, or a JSON-encoded params.ResourceResult for an OCI image
+// resource. The revision is given in the Juju-Resource-Revision
+// header.
+//
+// The content of file resources is cached in the API server's data
+// directory, so that it is read from the environment's storage only
+// once however many units fetch it.
+type resourcesDownloadHandler struct {
+	ctxt    httpContext
+	dataDir string
+}
+
+// ServeHTTP implements http.Handler.
+func (h *resourcesDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st, entity, err := h.ctxt.stateForRequestAuthenticatedAgent(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		if err := h.processGet(r, w, st, entity.Tag()); err != nil {
+			logger.Errorf("GET(%s) failed: %v", r.URL, err)
+			sendError(w, err)
+			return
+		}
+	default:
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+	}
+}
+
+// processGet handles a resource download GET request.
+func (h *resourcesDownloadHandler) processGet(r *http.Request, w http.ResponseWriter, st *state.State, authTag names.Tag) error {
+	query := r.URL.Query()
+	unitTag, err := names.ParseUnitTag(query.Get(":unit"))
+	if err != nil {
+		return errors.BadRequestf("%v", err)
+	}
+	if unitTag != authTag {
+		return common.ErrPerm
+	}
+	serviceName, err := names.UnitService(unitTag.Id())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	res, err := st.ServiceResource(serviceName, query.Get(":name"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	w.Header().Set(params.ResourceRevisionHeader, fmt.Sprint(res.Revision))
+	if res.Type != state.ResourceTypeFile {
+		result := charmresources.ResourceParams(res.Resource, res.Pinned)
+		sendStatusAndJSON(w, http.StatusOK, &params.ResourceResult{Resource: &result})
+		return nil
+	}
+	cachePath, err := h.cachedResourcePath(st, res.Resource)
+	if err != nil {
+		return errors.Annotate(err, "cannot get resource content")
+	}
+	content, err := os.Open(cachePath)
+	if err != nil {
+		return errors.Annotate(err, "cannot get resource content")
+	}
+	defer content.Close()
+	w.Header().Set("Content-Type", params.ContentTypeRaw)
+	w.Header().Set("Content-Length", fmt.Sprint(res.Size))
+	w.Header().Set("Digest", fmt.Sprintf("%s=%s", params.DigestSHA, res.SHA256))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		// The headers have been sent, so the error cannot be.
+		logger.Errorf("cannot send resource %q: %v", res.Name, err)
+	}
+	return nil
+}
+
+// cachedResourcePath returns the path of the file in the resources
+// cache that holds the content of the file resource, fetching it from
+// the environment's storage if it is not already cached. The cache is
+// keyed by the SHA256 of the content, so a cached file never needs to
+// be replaced.
+func (h *resourcesDownloadHandler) cachedResourcePath(st *state.State, res state.Resource) (string, error) {
+	cachePath := filepath.Join(h.dataDir, "resource-get-cache", res.SHA256)
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	} else if !os.IsNotExist(err) {
+		return "", errors.Annotate(err, "cannot access the resources cache")
+	}
+
+	// As for charms, the content is saved in a temporary file in the
+	// cache directory which is then atomically renamed, so that
+	// concurrent downloads do not see a partial file.
+	cacheDir := filepath.Dir(cachePath)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", errors.Annotate(err, "cannot create the resources cache")
+	}
+	tempFile, err := ioutil.TempFile(cacheDir, "resource")
+	if err != nil {
+		return "", errors.Annotate(err, "cannot create resource temp file")
+	}
+	defer cleanupFile(tempFile)
+
+	stor := storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+	reader, _, err := stor.Get(res.StoragePath)
+	if err != nil {
+		return "", errors.Annotate(err, "cannot get resource from environment storage")
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tempFile, hash), reader); err != nil {
+		return "", errors.Annotate(err, "cannot save resource")
+	}
+	if sha := fmt.Sprintf("%x", hash.Sum(nil)); sha != res.SHA256 {
+		return "", errors.Errorf("resource SHA256 %s does not match expected %s", sha, res.SHA256)
+	}
+	tempFile.Close()
+	if err := os.Rename(tempFile.Name(), cachePath); err != nil {
+		return "", errors.Annotate(err, "cannot save resource")
+	}
+	return cachePath, nil
+}
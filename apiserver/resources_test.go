@@ -0,0 +1,238 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/testing/factory"
+)
+
+type resourcesSuite struct {
+	authHttpSuite
+	unit         *state.Unit
+	unitPassword string
+}
+
+var _ = gc.Suite(&resourcesSuite{})
+
+func (s *resourcesSuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+	svc := s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	s.unit, s.unitPassword = s.Factory.MakeUnitReturningPassword(c, &factory.UnitParams{
+		Service: svc,
+	})
+}
+
+func (s *resourcesSuite) uploadURL(c *gc.C, service, name string, query url.Values) string {
+	path := fmt.Sprintf("/environment/%s/services/%s/resources/%s", s.State.EnvironUUID(), service, name)
+	return s.makeURL(c, "https", path, query).String()
+}
+
+func (s *resourcesSuite) downloadURL(c *gc.C, unitTag, name string) string {
+	path := fmt.Sprintf("/environment/%s/units/%s/resources/%s", s.State.EnvironUUID(), unitTag, name)
+	return s.makeURL(c, "https", path, nil).String()
+}
+
+func (s *resourcesSuite) upload(c *gc.C, name, content string, header http.Header) *http.Response {
+	return s.uploadWithHeader(c, s.uploadURL(c, "mysql", name, nil), content, header)
+}
+
+func (s *resourcesSuite) uploadWithHeader(c *gc.C, url, content string, header http.Header) *http.Response {
+	return s.authRequest(c, httpRequestParams{
+		method: "POST",
+		url:    url,
+		body:   strings.NewReader(content),
+		do: func(req *http.Request) (*http.Response, error) {
+			for k, v := range header {
+				req.Header[k] = v
+			}
+			return utils.GetNonValidatingHTTPClient().Do(req)
+		},
+	})
+}
+
+func (s *resourcesSuite) download(c *gc.C, name string) *http.Response {
+	return s.sendRequest(c, httpRequestParams{
+		tag:      s.unit.Tag().String(),
+		password: s.unitPassword,
+		method:   "GET",
+		url:      s.downloadURL(c, s.unit.Tag().String(), name),
+	})
+}
+
+func (s *resourcesSuite) assertResourceResponse(c *gc.C, resp *http.Response) params.Resource {
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeJSON)
+	var result params.ResourceResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil, gc.Commentf("body: %s", body))
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.Resource, gc.NotNil)
+	return *result.Resource
+}
+
+func (s *resourcesSuite) assertErrorResponse(c *gc.C, resp *http.Response, expCode int, expError string) {
+	body := assertResponse(c, resp, expCode, params.ContentTypeJSON)
+	var result params.ErrorResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil, gc.Commentf("body: %s", body))
+	c.Assert(result.Error, gc.NotNil)
+	c.Assert(result.Error.Message, gc.Matches, expError)
+}
+
+func sha256Hex(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+func (s *resourcesSuite) TestUploadRequiresAuth(c *gc.C) {
+	resp := s.sendRequest(c, httpRequestParams{method: "POST", url: s.uploadURL(c, "mysql", "data", nil)})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "no credentials provided")
+}
+
+func (s *resourcesSuite) TestUploadRequiresPOST(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{method: "PUT", url: s.uploadURL(c, "mysql", "data", nil)})
+	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "PUT"`)
+}
+
+func (s *resourcesSuite) TestUploadAndDownloadFile(c *gc.C) {
+	header := http.Header{"Digest": {"SHA=" + sha256Hex("some data")}}
+	res := s.assertResourceResponse(c, s.upload(c, "data", "some data", header))
+	c.Assert(res.ServiceTag, gc.Equals, "service-mysql")
+	c.Assert(res.Name, gc.Equals, "data")
+	c.Assert(res.Type, gc.Equals, "file")
+	c.Assert(res.Revision, gc.Equals, 1)
+	c.Assert(res.Size, gc.Equals, int64(len("some data")))
+	c.Assert(res.SHA256, gc.Equals, sha256Hex("some data"))
+
+	resp := s.download(c, "data")
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeRaw)
+	c.Assert(string(body), gc.Equals, "some data")
+	c.Assert(resp.Header.Get(params.ResourceRevisionHeader), gc.Equals, "1")
+	c.Assert(resp.Header.Get("Digest"), gc.Equals, "SHA="+sha256Hex("some data"))
+}
+
+func (s *resourcesSuite) TestDownloadUsesCache(c *gc.C) {
+	res := s.assertResourceResponse(c, s.upload(c, "data", "some data", nil))
+	resp := s.download(c, "data")
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeRaw)
+	c.Assert(string(body), gc.Equals, "some data")
+
+	cachePath := filepath.Join(s.DataDir(), "resource-get-cache", sha256Hex("some data"))
+	_, err := os.Stat(cachePath)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Once cached, the content is not read from storage again.
+	current, err := s.State.ServiceResource("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, res.Revision)
+	stor := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession())
+	err = stor.Remove(current.StoragePath)
+	c.Assert(err, jc.ErrorIsNil)
+	resp = s.download(c, "data")
+	body = assertResponse(c, resp, http.StatusOK, params.ContentTypeRaw)
+	c.Assert(string(body), gc.Equals, "some data")
+}
+
+func (s *resourcesSuite) TestDownloadPinnedRevision(c *gc.C) {
+	s.assertResourceResponse(c, s.upload(c, "data", "old data", nil))
+	s.assertResourceResponse(c, s.upload(c, "data", "new data", nil))
+	err := s.State.PinResource("mysql", "data", 1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp := s.download(c, "data")
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeRaw)
+	c.Assert(string(body), gc.Equals, "old data")
+	c.Assert(resp.Header.Get(params.ResourceRevisionHeader), gc.Equals, "1")
+}
+
+func (s *resourcesSuite) TestUploadDigestMismatch(c *gc.C) {
+	header := http.Header{"Digest": {"SHA=" + sha256Hex("other data")}}
+	resp := s.upload(c, "data", "some data", header)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "resource SHA256 .* does not match expected .*")
+	revisions, err := s.State.ResourceRevisions("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, gc.HasLen, 0)
+}
+
+func (s *resourcesSuite) TestUploadAndDownloadOCIImage(c *gc.C) {
+	url := s.uploadURL(c, "mysql", "server-image", url.Values{
+		"type":  {"oci-image"},
+		"image": {"docker.io/library/mysql:5.7"},
+	})
+	res := s.assertResourceResponse(c, s.uploadWithHeader(c, url, "", nil))
+	c.Assert(res.Type, gc.Equals, "oci-image")
+	c.Assert(res.ImageRef, gc.Equals, "docker.io/library/mysql:5.7")
+
+	resp := s.download(c, "server-image")
+	c.Assert(resp.Header.Get(params.ResourceRevisionHeader), gc.Equals, "1")
+	res = s.assertResourceResponse(c, resp)
+	c.Assert(res.ImageRef, gc.Equals, "docker.io/library/mysql:5.7")
+}
+
+func (s *resourcesSuite) TestUploadInvalid(c *gc.C) {
+	resp := s.upload(c, "Data", "some data", nil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `resource name "Data" not valid`)
+
+	url := s.uploadURL(c, "mysql", "data", url.Values{"type": {"tarball"}})
+	resp = s.uploadWithHeader(c, url, "some data", nil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `resource type "tarball" not valid`)
+
+	url = s.uploadURL(c, "mysql", "image", url.Values{"type": {"oci-image"}})
+	resp = s.uploadWithHeader(c, url, "", nil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `.*OCI image resource without image reference not valid`)
+}
+
+func (s *resourcesSuite) TestUploadNotDeclared(c *gc.C) {
+	resp := s.upload(c, "backup", "some data", nil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `.*resource not declared by charm "cs:quantal/mysql-[0-9]+"`)
+	revisions, err := s.State.ResourceRevisions("mysql", "backup")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, gc.HasLen, 0)
+}
+
+func (s *resourcesSuite) TestUploadServiceNotFound(c *gc.C) {
+	url := s.uploadURL(c, "wordpress", "data", nil)
+	resp := s.uploadWithHeader(c, url, "some data", nil)
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `service "wordpress" not found`)
+}
+
+func (s *resourcesSuite) TestDownloadNotFound(c *gc.C) {
+	resp := s.download(c, "data")
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `resource "data" of service "mysql" not found`)
+}
+
+func (s *resourcesSuite) TestDownloadOtherUnit(c *gc.C) {
+	resp := s.sendRequest(c, httpRequestParams{
+		tag:      s.unit.Tag().String(),
+		password: s.unitPassword,
+		method:   "GET",
+		url:      s.downloadURL(c, "unit-mysql-1", "data"),
+	})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "permission denied")
+}
+
+func (s *resourcesSuite) TestDownloadRequiresAgent(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{
+		method: "GET",
+		url:    s.downloadURL(c, s.unit.Tag().String(), "data"),
+	})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "invalid entity name or password")
+}
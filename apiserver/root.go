@@ -52,6 +52,10 @@ type apiHandler struct {
 	// path, logins processed with v2 or later will only offer the
 	// user manager and environment manager api endpoints from here.
 	envUUID string
+
+	// release, if set, releases the connection's place in its
+	// client's connection quota.
+	release func()
 }
 
 var _ = (*apiHandler)(nil)
@@ -77,6 +81,14 @@ func newApiHandler(srv *Server, st *state.State, rpcConn *rpc.Conn, reqNotifier
 	return r, nil
 }
 
+// releaseConnection releases the connection's place in its client's
+// connection quota, if it has one.
+func (r *apiHandler) releaseConnection() {
+	if r.release != nil {
+		r.release()
+	}
+}
+
 func (r *apiHandler) getResources() *common.Resources {
 	return r.resources
 }
@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+)
+
+// scopedRoot restricts API calls to those permitted by the scopes of
+// the auth token used to log in.
+type scopedRoot struct {
+	rpc.MethodFinder
+	scopes set.Strings
+}
+
+// newScopedRoot returns a new scopedRoot that permits calls to the
+// given scopes, each of which names a facade or a facade method as
+// "Facade.Method".
+func newScopedRoot(finder rpc.MethodFinder, scopes []string) *scopedRoot {
+	return &scopedRoot{
+		MethodFinder: finder,
+		scopes:       set.NewStrings(scopes...),
+	}
+}
+
+// alwaysInScope holds the facades that may be called whatever the
+// token's scopes, as a connection cannot be maintained without them.
+var alwaysInScope = set.NewStrings("Pinger")
+
+// neverInScope holds the facades that may never be called with a
+// token, so that a token cannot be used to issue others.
+var neverInScope = set.NewStrings("AuthToken")
+
+// FindMethod returns a permission denied error if the method is not
+// in the token's scopes.
+func (r *scopedRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if !r.inScope(rootName, methodName) {
+		return nil, common.ErrPerm
+	}
+	return caller, nil
+}
+
+func (r *scopedRoot) inScope(rootName, methodName string) bool {
+	switch {
+	case neverInScope.Contains(rootName):
+		return false
+	case alwaysInScope.Contains(rootName):
+		return true
+	}
+	return r.scopes.Contains(rootName) || r.scopes.Contains(rootName+"."+methodName)
+}
@@ -0,0 +1,51 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type scopedRootSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&scopedRootSuite{})
+
+func (s *scopedRootSuite) TestFindMethod(c *gc.C) {
+	root := newScopedRoot(&auditFinder{}, []string{"Client.FullStatus", "AllWatcher", "AuthToken"})
+	for i, test := range []struct {
+		rootName string
+		method   string
+		allowed  bool
+	}{
+		{"Client", "FullStatus", true},
+		{"Client", "DestroyMachines", false},
+		{"AllWatcher", "Next", true},
+		{"Pinger", "Ping", true},
+		{"Machiner", "SetStatus", false},
+		{"AuthToken", "Issue", false},
+	} {
+		c.Logf("test %d: %s.%s", i, test.rootName, test.method)
+		caller, err := root.FindMethod(test.rootName, 0, test.method)
+		if test.allowed {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(caller, gc.NotNil)
+		} else {
+			c.Check(err, gc.Equals, common.ErrPerm)
+			c.Check(caller, gc.IsNil)
+		}
+	}
+}
+
+func (s *scopedRootSuite) TestFindMethodNotFound(c *gc.C) {
+	finder := &auditFinder{err: common.ErrBadRequest}
+	root := newScopedRoot(finder, []string{"Client"})
+	_, err := root.FindMethod("Client", 0, "FullStatus")
+	c.Assert(err, gc.Equals, common.ErrBadRequest)
+}
@@ -27,6 +27,9 @@ var (
 
 func init() {
 	common.RegisterStandardFacade("Service", 1, NewAPI)
+	// Version 2 accepts endpoint bindings in ServicesDeploy; older
+	// servers would silently ignore them.
+	common.RegisterStandardFacade("Service", 2, NewAPI)
 }
 
 // Service defines the methods on the service API end point.
@@ -160,15 +163,16 @@ func DeployService(st *state.State, owner string, args params.ServiceDeploy) err
 		jjj.DeployServiceParams{
 			ServiceName: args.ServiceName,
 			// TODO(dfc) ServiceOwner should be a tag
-			ServiceOwner:   owner,
-			Charm:          ch,
-			NumUnits:       args.NumUnits,
-			ConfigSettings: settings,
-			Constraints:    args.Constraints,
-			ToMachineSpec:  args.ToMachineSpec,
-			Placement:      args.Placement,
-			Networks:       requestedNetworks,
-			Storage:        args.Storage,
+			ServiceOwner:     owner,
+			Charm:            ch,
+			NumUnits:         args.NumUnits,
+			ConfigSettings:   settings,
+			Constraints:      args.Constraints,
+			ToMachineSpec:    args.ToMachineSpec,
+			Placement:        args.Placement,
+			Networks:         requestedNetworks,
+			Storage:          args.Storage,
+			EndpointBindings: args.EndpointBindings,
 		})
 	return err
 }
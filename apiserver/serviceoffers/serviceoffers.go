@@ -0,0 +1,274 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The serviceoffers package implements the API facade used to offer a
+// service's endpoints to other environments, and to consume such
+// offers.
+package serviceoffers
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.serviceoffers")
+
+func init() {
+	common.RegisterStandardFacade("ServiceOffers", 1, NewAPI)
+}
+
+// API implements the ServiceOffers facade.
+type API struct {
+	st   *state.State
+	user names.UserTag
+}
+
+// NewAPI returns a new ServiceOffers API, which acts for the logged in
+// user.
+func NewAPI(st *state.State, _ *common.Resources, authorizer common.Authorizer) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	// Since we know this is a user tag (because AuthClient is true),
+	// we just do the type assertion to the UserTag.
+	user, _ := authorizer.GetAuthTag().(names.UserTag)
+	return &API{st: st, user: user}, nil
+}
+
+// Offer offers endpoints of services in this environment to other
+// environments. The logged in user owns the offers.
+func (api *API) Offer(args params.AddServiceOffers) (params.ServiceOfferResults, error) {
+	result := params.ServiceOfferResults{
+		Results: make([]params.ServiceOfferResult, len(args.Offers)),
+	}
+	for i, arg := range args.Offers {
+		offer, err := api.offer(arg)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = &offer
+	}
+	return result, nil
+}
+
+func (api *API) offer(arg params.AddServiceOffer) (params.ServiceOffer, error) {
+	users := make([]names.UserTag, len(arg.UserTags))
+	for i, tag := range arg.UserTags {
+		user, err := names.ParseUserTag(tag)
+		if err != nil {
+			return params.ServiceOffer{}, errors.Trace(err)
+		}
+		users[i] = user
+	}
+	offer, err := api.st.AddServiceOffer(state.AddServiceOfferArgs{
+		OfferName:   arg.OfferName,
+		ServiceName: arg.ServiceName,
+		Endpoints:   arg.Endpoints,
+		Description: arg.Description,
+		Owner:       api.user,
+		Users:       users,
+	})
+	if err != nil {
+		return params.ServiceOffer{}, errors.Trace(err)
+	}
+	logger.Infof("%s offered %s as %s", api.user, offer.ServiceName, offer.URL)
+	return serviceOffer(offer), nil
+}
+
+// ListOffers returns the offers, from any environment, that match the
+// filter and that the logged in user may consume. System
+// administrators see all offers.
+func (api *API) ListOffers(args params.ServiceOfferFilter) (params.ServiceOffers, error) {
+	var result params.ServiceOffers
+	filter := state.ServiceOfferFilter{
+		ServiceName: args.ServiceName,
+	}
+	if args.EnvironTag != "" {
+		envTag, err := names.ParseEnvironTag(args.EnvironTag)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		filter.EnvUUID = envTag.Id()
+	}
+	offers, err := api.st.ServiceOffers(filter)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	isAdmin, err := api.st.IsSystemAdministrator(api.user)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Offers = []params.ServiceOffer{}
+	for _, offer := range offers {
+		if isAdmin || offer.CanConsume(api.user) {
+			result.Offers = append(result.Offers, serviceOffer(offer))
+		}
+	}
+	return result, nil
+}
+
+// RemoveOffers removes offers made from this environment. Only an
+// offer's owner, or a system administrator, may remove it.
+func (api *API) RemoveOffers(args params.ServiceOfferURLs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.URLs)),
+	}
+	for i, url := range args.URLs {
+		result.Results[i].Error = common.ServerError(api.removeOffer(url))
+	}
+	return result, nil
+}
+
+func (api *API) removeOffer(url string) error {
+	if _, err := api.ownedOffer(url); err != nil {
+		return errors.Trace(err)
+	}
+	if err := api.st.RemoveServiceOffer(url); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("%s removed offer %s", api.user, url)
+	return nil
+}
+
+// ModifyOfferAccess grants or revokes users' permission to consume
+// offers made from this environment. Only an offer's owner, or a
+// system administrator, may change who may consume it.
+func (api *API) ModifyOfferAccess(args params.ModifyOfferAccessRequest) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Changes)),
+	}
+	for i, change := range args.Changes {
+		result.Results[i].Error = common.ServerError(api.modifyOfferAccess(change))
+	}
+	return result, nil
+}
+
+func (api *API) modifyOfferAccess(change params.ModifyOfferAccess) error {
+	user, err := names.ParseUserTag(change.UserTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := api.ownedOffer(change.URL); err != nil {
+		return errors.Trace(err)
+	}
+	switch change.Action {
+	case params.GrantOfferAccess:
+		err = api.st.GrantOfferAccess(change.URL, user)
+	case params.RevokeOfferAccess:
+		err = api.st.RevokeOfferAccess(change.URL, user)
+	default:
+		return errors.NotValidf("offer access action %q", change.Action)
+	}
+	return errors.Trace(err)
+}
+
+// ownedOffer returns the offer with the given URL if it was made from
+// this environment, and the logged in user may change it.
+func (api *API) ownedOffer(url string) (state.ServiceOffer, error) {
+	offer, err := api.st.ServiceOffer(url)
+	if errors.IsNotFound(err) {
+		return state.ServiceOffer{}, common.ErrPerm
+	} else if err != nil {
+		return state.ServiceOffer{}, errors.Trace(err)
+	}
+	if offer.EnvUUID != api.st.EnvironUUID() {
+		return state.ServiceOffer{}, common.ErrPerm
+	}
+	if offer.Owner != api.user.String() {
+		isAdmin, err := api.st.IsSystemAdministrator(api.user)
+		if err != nil {
+			return state.ServiceOffer{}, errors.Trace(err)
+		}
+		if !isAdmin {
+			return state.ServiceOffer{}, common.ErrPerm
+		}
+	}
+	return offer, nil
+}
+
+// Consume consumes offers made from other environments, adding remote
+// services to this environment. The logged in user must be permitted
+// to consume the offers.
+func (api *API) Consume(args params.ConsumeServiceArgs) (params.ConsumeServiceResults, error) {
+	result := params.ConsumeServiceResults{
+		Results: make([]params.ConsumeServiceResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		remote, err := api.consume(arg)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = &remote
+	}
+	return result, nil
+}
+
+func (api *API) consume(arg params.ConsumeServiceArg) (params.RemoteService, error) {
+	offer, err := api.st.ServiceOffer(arg.OfferURL)
+	if errors.IsNotFound(err) {
+		return params.RemoteService{}, common.ErrPerm
+	} else if err != nil {
+		return params.RemoteService{}, errors.Trace(err)
+	}
+	if !offer.CanConsume(api.user) {
+		isAdmin, err := api.st.IsSystemAdministrator(api.user)
+		if err != nil {
+			return params.RemoteService{}, errors.Trace(err)
+		}
+		if !isAdmin {
+			return params.RemoteService{}, common.ErrPerm
+		}
+	}
+	name := arg.ServiceAlias
+	if name == "" {
+		name = offer.ServiceName
+	}
+	remote, err := api.st.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:     name,
+		OfferURL: offer.URL,
+		Consumer: api.user,
+	})
+	if err != nil {
+		return params.RemoteService{}, errors.Trace(err)
+	}
+	logger.Infof("%s consumed %s as %s", api.user, offer.URL, name)
+	return remoteService(remote), nil
+}
+
+func serviceOffer(offer state.ServiceOffer) params.ServiceOffer {
+	return params.ServiceOffer{
+		URL:             offer.URL,
+		EnvironTag:      names.NewEnvironTag(offer.EnvUUID).String(),
+		ServiceName:     offer.ServiceName,
+		Endpoints:       offer.Endpoints,
+		Description:     offer.Description,
+		OwnerTag:        offer.Owner,
+		AllowedUserTags: offer.Users,
+	}
+}
+
+func remoteService(remote *state.RemoteService) params.RemoteService {
+	result := params.RemoteService{
+		Name:             remote.Name(),
+		OfferURL:         remote.OfferURL(),
+		SourceEnvironTag: names.NewEnvironTag(remote.SourceEnvUUID()).String(),
+		ConnectionId:     remote.ConnectionId(),
+	}
+	for _, ep := range remote.Endpoints() {
+		result.Endpoints = append(result.Endpoints, params.RemoteEndpoint{
+			Name:      ep.Name,
+			Role:      string(ep.Role),
+			Interface: ep.Interface,
+			Limit:     ep.Limit,
+			Scope:     string(ep.Scope),
+		})
+	}
+	return result
+}
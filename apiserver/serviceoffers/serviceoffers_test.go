@@ -0,0 +1,263 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package serviceoffers_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/serviceoffers"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type serviceOffersSuite struct {
+	jujutesting.JujuConnSuite
+
+	consumer *state.State
+	bob      names.UserTag
+	api      *serviceoffers.API
+}
+
+var _ = gc.Suite(&serviceOffersSuite{})
+
+func (s *serviceOffersSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	s.consumer = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.consumer.Close() })
+	s.bob = s.Factory.MakeUser(c, &factory.UserParams{
+		Name:      "bob",
+		NoEnvUser: true,
+	}).UserTag()
+	s.api = s.newAPI(c, s.State, s.AdminUserTag(c))
+}
+
+func (s *serviceOffersSuite) newAPI(c *gc.C, st *state.State, tag names.Tag) *serviceoffers.API {
+	authorizer := apiservertesting.FakeAuthorizer{Tag: tag}
+	api, err := serviceoffers.NewAPI(st, common.NewResources(), authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *serviceOffersSuite) offer(c *gc.C, userTags ...string) params.ServiceOffer {
+	results, err := s.api.Offer(params.AddServiceOffers{
+		Offers: []params.AddServiceOffer{{
+			OfferName:   "db",
+			ServiceName: "mysql",
+			Endpoints:   []string{"server"},
+			Description: "a database",
+			UserTags:    userTags,
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	return *results.Results[0].Result
+}
+
+func (s *serviceOffersSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("0"),
+	}
+	api, err := serviceoffers.NewAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *serviceOffersSuite) TestOffer(c *gc.C) {
+	offer := s.offer(c, s.bob.String())
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer, jc.DeepEquals, params.ServiceOffer{
+		URL:             state.ServiceOfferURL(s.AdminUserTag(c), env.Name(), "db"),
+		EnvironTag:      s.State.EnvironTag().String(),
+		ServiceName:     "mysql",
+		Endpoints:       []string{"server"},
+		Description:     "a database",
+		OwnerTag:        s.AdminUserTag(c).String(),
+		AllowedUserTags: []string{s.bob.String()},
+	})
+}
+
+func (s *serviceOffersSuite) TestOfferErrors(c *gc.C) {
+	results, err := s.api.Offer(params.AddServiceOffers{
+		Offers: []params.AddServiceOffer{{
+			OfferName:   "db",
+			ServiceName: "wordpress",
+			Endpoints:   []string{"db"},
+		}, {
+			OfferName:   "db",
+			ServiceName: "mysql",
+			Endpoints:   []string{"server"},
+			UserTags:    []string{"machine-0"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `cannot offer service "wordpress": service "wordpress" not found`)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `"machine-0" is not a valid user tag`)
+}
+
+func (s *serviceOffersSuite) TestListOffers(c *gc.C) {
+	offer := s.offer(c)
+
+	offers, err := s.api.ListOffers(params.ServiceOfferFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers.Offers, jc.DeepEquals, []params.ServiceOffer{offer})
+
+	// Bob may not consume the offer, so cannot see it.
+	bobAPI := s.newAPI(c, s.consumer, s.bob)
+	offers, err = bobAPI.ListOffers(params.ServiceOfferFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers.Offers, gc.HasLen, 0)
+
+	err = s.State.GrantOfferAccess(offer.URL, s.bob)
+	c.Assert(err, jc.ErrorIsNil)
+	offers, err = bobAPI.ListOffers(params.ServiceOfferFilter{
+		EnvironTag:  s.State.EnvironTag().String(),
+		ServiceName: "mysql",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers.Offers, gc.HasLen, 1)
+	c.Assert(offers.Offers[0].URL, gc.Equals, offer.URL)
+
+	offers, err = bobAPI.ListOffers(params.ServiceOfferFilter{
+		EnvironTag: s.consumer.EnvironTag().String(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers.Offers, gc.HasLen, 0)
+}
+
+func (s *serviceOffersSuite) TestRemoveOffers(c *gc.C) {
+	offer := s.offer(c, s.bob.String())
+	bobAPI := s.newAPI(c, s.State, s.bob)
+	results, err := bobAPI.RemoveOffers(params.ServiceOfferURLs{
+		URLs: []string{offer.URL},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, "permission denied")
+
+	results, err = s.api.RemoveOffers(params.ServiceOfferURLs{
+		URLs: []string{offer.URL, "local:/u/admin/nowhere/nothing"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, "permission denied")
+	_, err = s.State.ServiceOffer(offer.URL)
+	c.Assert(err, gc.ErrorMatches, `offer ".*" not found`)
+}
+
+func (s *serviceOffersSuite) TestModifyOfferAccess(c *gc.C) {
+	offer := s.offer(c)
+	results, err := s.api.ModifyOfferAccess(params.ModifyOfferAccessRequest{
+		Changes: []params.ModifyOfferAccess{{
+			URL:     offer.URL,
+			UserTag: s.bob.String(),
+			Action:  params.GrantOfferAccess,
+		}, {
+			URL:     offer.URL,
+			UserTag: s.bob.String(),
+			Action:  "frobnicate",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `offer access action "frobnicate" not valid`)
+	got, err := s.State.ServiceOffer(offer.URL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got.CanConsume(s.bob), jc.IsTrue)
+
+	// Bob may consume the offer, but not let others consume it.
+	bobAPI := s.newAPI(c, s.State, s.bob)
+	results, err = bobAPI.ModifyOfferAccess(params.ModifyOfferAccessRequest{
+		Changes: []params.ModifyOfferAccess{{
+			URL:     offer.URL,
+			UserTag: "user-mary",
+			Action:  params.GrantOfferAccess,
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, "permission denied")
+
+	results, err = s.api.ModifyOfferAccess(params.ModifyOfferAccessRequest{
+		Changes: []params.ModifyOfferAccess{{
+			URL:     offer.URL,
+			UserTag: s.bob.String(),
+			Action:  params.RevokeOfferAccess,
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	got, err = s.State.ServiceOffer(offer.URL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got.CanConsume(s.bob), jc.IsFalse)
+}
+
+func (s *serviceOffersSuite) TestConsume(c *gc.C) {
+	offer := s.offer(c, s.bob.String())
+	bobAPI := s.newAPI(c, s.consumer, s.bob)
+	results, err := bobAPI.Consume(params.ConsumeServiceArgs{
+		Args: []params.ConsumeServiceArg{{
+			OfferURL:     offer.URL,
+			ServiceAlias: "remotedb",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	remote := results.Results[0].Result
+	c.Assert(remote.Name, gc.Equals, "remotedb")
+	c.Assert(remote.OfferURL, gc.Equals, offer.URL)
+	c.Assert(remote.SourceEnvironTag, gc.Equals, s.State.EnvironTag().String())
+	c.Assert(remote.Endpoints, gc.HasLen, 1)
+	c.Assert(remote.Endpoints[0].Name, gc.Equals, "server")
+	c.Assert(remote.Endpoints[0].Role, gc.Equals, "provider")
+	c.Assert(remote.Endpoints[0].Interface, gc.Equals, "mysql")
+
+	conn, err := s.State.OfferConnection(remote.ConnectionId)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conn.User, gc.Equals, s.bob.String())
+	_, err = s.consumer.RemoteService("remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serviceOffersSuite) TestConsumeDefaultsToServiceName(c *gc.C) {
+	offer := s.offer(c)
+	api := s.newAPI(c, s.consumer, s.AdminUserTag(c))
+	results, err := api.Consume(params.ConsumeServiceArgs{
+		Args: []params.ConsumeServiceArg{{OfferURL: offer.URL}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Result.Name, gc.Equals, "mysql")
+}
+
+func (s *serviceOffersSuite) TestConsumeRefused(c *gc.C) {
+	offer := s.offer(c)
+	bobAPI := s.newAPI(c, s.consumer, s.bob)
+	results, err := bobAPI.Consume(params.ConsumeServiceArgs{
+		Args: []params.ConsumeServiceArg{{
+			OfferURL: offer.URL,
+		}, {
+			OfferURL: "local:/u/admin/nowhere/nothing",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, "permission denied")
+	_, err = s.consumer.RemoteService("mysql")
+	c.Assert(err, gc.ErrorMatches, `remote service "mysql" not found`)
+}
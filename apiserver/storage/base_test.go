@@ -82,6 +82,8 @@ const (
 	filesystemAttachmentsCall               = "filesystemAttachments"
 	allFilesystemsCall                      = "allFilesystems"
 	addStorageForUnitCall                   = "addStorageForUnit"
+	attachStorageCall                       = "attachStorage"
+	detachStorageCall                       = "detachStorage"
 	getBlockForTypeCall                     = "getBlockForType"
 	volumeAttachmentCall                    = "volumeAttachment"
 )
@@ -222,6 +224,17 @@ func (s *baseStorageSuite) constructState() *mockState {
 			s.calls = append(s.calls, addStorageForUnitCall)
 			return nil
 		},
+		attachStorage: func(names.StorageTag, names.UnitTag) error {
+			s.calls = append(s.calls, attachStorageCall)
+			return nil
+		},
+		detachStorage: func(tag names.StorageTag, _ names.UnitTag) error {
+			s.calls = append(s.calls, detachStorageCall)
+			if tag.Id() == "data/1" {
+				return errors.NotSupportedf("detaching filesystem storage")
+			}
+			return nil
+		},
 		getBlockForType: func(t state.BlockType) (state.Block, bool, error) {
 			s.calls = append(s.calls, getBlockForTypeCall)
 			val, found := s.blocks[t]
@@ -58,6 +58,8 @@ type mockState struct {
 	filesystemAttachments               func(filesystem names.FilesystemTag) ([]state.FilesystemAttachment, error)
 	allFilesystems                      func() ([]state.Filesystem, error)
 	addStorageForUnit                   func(u names.UnitTag, name string, cons state.StorageConstraints) error
+	attachStorage                       func(names.StorageTag, names.UnitTag) error
+	detachStorage                       func(names.StorageTag, names.UnitTag) error
 	getBlockForType                     func(t state.BlockType) (state.Block, bool, error)
 	blockDevices                        func(names.MachineTag) ([]state.BlockDeviceInfo, error)
 }
@@ -150,6 +152,14 @@ func (st *mockState) AddStorageForUnit(u names.UnitTag, name string, cons state.
 	return st.addStorageForUnit(u, name, cons)
 }
 
+func (st *mockState) AttachStorage(s names.StorageTag, u names.UnitTag) error {
+	return st.attachStorage(s, u)
+}
+
+func (st *mockState) DetachStorage(s names.StorageTag, u names.UnitTag) error {
+	return st.detachStorage(s, u)
+}
+
 func (st *mockState) GetBlockForType(t state.BlockType) (state.Block, bool, error) {
 	return st.getBlockForType(t)
 }
@@ -280,6 +290,10 @@ func (m *mockStorageInstance) Owner() names.Tag {
 	return m.owner
 }
 
+func (m *mockStorageInstance) HasOwner() bool {
+	return m.owner != nil
+}
+
 func (m *mockStorageInstance) Tag() names.Tag {
 	return m.storageTag
 }
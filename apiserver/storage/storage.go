@@ -174,9 +174,13 @@ func createStorageDetails(st storageAccess, si state.StorageInstance) (*params.S
 		}
 	}
 
+	var ownerTag string
+	if si.HasOwner() {
+		ownerTag = si.Owner().String()
+	}
 	return &params.StorageDetails{
 		StorageTag:  si.Tag().String(),
-		OwnerTag:    si.Owner().String(),
+		OwnerTag:    ownerTag,
 		Kind:        params.StorageKind(si.Kind()),
 		Status:      common.EntityStatusFromState(status),
 		Persistent:  persistent,
@@ -694,3 +698,48 @@ func (a *API) AddToUnit(args params.StoragesAddParams) (params.ErrorResults, err
 	}
 	return params.ErrorResults{Results: result}, nil
 }
+
+// Attach attaches existing, detached storage instances to units.
+// This method handles bulk operations, and a failure on one
+// attachment does not block the remaining attachments.
+// A "CHANGE" block can block this operation.
+func (a *API) Attach(args params.StorageAttachmentIds) (params.ErrorResults, error) {
+	return a.modifyStorageAttachments(args, a.storage.AttachStorage)
+}
+
+// Detach detaches storage instances from units, leaving the storage
+// instances in place so that they may be attached to other units.
+// This method handles bulk operations, and a failure on one
+// attachment does not block the remaining attachments.
+// A "CHANGE" block can block this operation.
+func (a *API) Detach(args params.StorageAttachmentIds) (params.ErrorResults, error) {
+	return a.modifyStorageAttachments(args, a.storage.DetachStorage)
+}
+
+func (a *API) modifyStorageAttachments(
+	args params.StorageAttachmentIds,
+	modify func(names.StorageTag, names.UnitTag) error,
+) (params.ErrorResults, error) {
+	blockChecker := common.NewBlockChecker(a.storage)
+	if err := blockChecker.ChangeAllowed(); err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+
+	result := make([]params.ErrorResult, len(args.Ids))
+	for i, id := range args.Ids {
+		storageTag, err := names.ParseStorageTag(id.StorageTag)
+		if err != nil {
+			result[i].Error = common.ServerError(err)
+			continue
+		}
+		unitTag, err := names.ParseUnitTag(id.UnitTag)
+		if err != nil {
+			result[i].Error = common.ServerError(err)
+			continue
+		}
+		if err := modify(storageTag, unitTag); err != nil {
+			result[i].Error = common.ServerError(err)
+		}
+	}
+	return params.ErrorResults{Results: result}, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+type storageAttachSuite struct {
+	baseStorageSuite
+}
+
+var _ = gc.Suite(&storageAttachSuite{})
+
+func (s *storageAttachSuite) TestAttach(c *gc.C) {
+	results, err := s.api.Attach(params.StorageAttachmentIds{[]params.StorageAttachmentId{{
+		StorageTag: s.storageTag.String(),
+		UnitTag:    s.unitTag.String(),
+	}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	s.assertCalls(c, []string{getBlockForTypeCall, attachStorageCall})
+}
+
+func (s *storageAttachSuite) TestAttachBlocked(c *gc.C) {
+	s.blockAllChanges(c, "TestAttachBlocked")
+	_, err := s.api.Attach(params.StorageAttachmentIds{[]params.StorageAttachmentId{{
+		StorageTag: s.storageTag.String(),
+		UnitTag:    s.unitTag.String(),
+	}}})
+	s.assertBlocked(c, err, "TestAttachBlocked")
+}
+
+func (s *storageAttachSuite) TestDetach(c *gc.C) {
+	results, err := s.api.Detach(params.StorageAttachmentIds{[]params.StorageAttachmentId{{
+		StorageTag: s.storageTag.String(),
+		UnitTag:    s.unitTag.String(),
+	}, {
+		StorageTag: "storage-data-1",
+		UnitTag:    s.unitTag.String(),
+	}, {
+		StorageTag: "volume-0",
+		UnitTag:    s.unitTag.String(),
+	}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{[]params.ErrorResult{
+		{},
+		{Error: &params.Error{
+			Code:    params.CodeNotSupported,
+			Message: "detaching filesystem storage not supported",
+		}},
+		{Error: &params.Error{
+			Message: `"volume-0" is not a valid storage tag`,
+		}},
+	}})
+	s.assertCalls(c, []string{getBlockForTypeCall, detachStorageCall, detachStorageCall})
+}
@@ -15,6 +15,8 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/state"
 )
@@ -32,6 +34,7 @@ type SystemManager interface {
 	EnvironmentConfig() (params.EnvironmentConfigResults, error)
 	ListBlockedEnvironments() (params.EnvironmentBlockInfoList, error)
 	RemoveBlocks(args params.RemoveBlocksArgs) error
+	UpdateCredentials(args params.UpdateCredentials) (params.UpdateCredentialsResult, error)
 	WatchAllEnvs() (params.AllWatcherId, error)
 }
 
@@ -260,6 +263,114 @@ func (s *SystemManagerAPI) RemoveBlocks(args params.RemoveBlocksArgs) error {
 	return errors.Trace(s.state.RemoveAllBlocksForSystem())
 }
 
+// UpdateCredentials replaces the cloud credentials of the specified
+// environment, after checking the new values with the cloud where the
+// provider supports it. Every environment in the system that shares
+// the old credentials is updated too, and marked as having valid
+// credentials. The tags of the updated environments are returned,
+// sorted.
+func (s *SystemManagerAPI) UpdateCredentials(args params.UpdateCredentials) (params.UpdateCredentialsResult, error) {
+	result := params.UpdateCredentialsResult{}
+	if len(args.Credentials) == 0 {
+		return result, errors.New("no credentials specified")
+	}
+	tag, err := names.ParseEnvironTag(args.EnvironTag)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	env, err := s.state.GetEnvironment(tag)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	oldCfg, err := env.Config()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	provider, err := environs.Provider(oldCfg.Type())
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	oldCreds, err := provider.SecretAttrs(oldCfg)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	attrs := make(map[string]interface{})
+	for key, value := range args.Credentials {
+		if _, ok := oldCreds[key]; !ok {
+			return result, errors.Errorf("attribute %q is not a credential", key)
+		}
+		attrs[key] = value
+	}
+	if err := checkCredentials(oldCfg, attrs); err != nil {
+		return result, errors.Annotate(err, "new credentials not valid")
+	}
+
+	allEnvs, err := s.state.AllEnvironments()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, env := range allEnvs {
+		cfg, err := env.Config()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		if cfg.Type() != oldCfg.Type() || !sharesCredentials(cfg, oldCreds, args.Credentials) {
+			continue
+		}
+		if err := s.updateCredentials(env, attrs); err != nil {
+			return result, errors.Annotatef(err, "cannot update credentials for environment %q", env.UUID())
+		}
+		result.EnvironTags = append(result.EnvironTags, env.EnvironTag().String())
+	}
+	sort.Strings(result.EnvironTags)
+	return result, nil
+}
+
+// updateCredentials sets the given credential attributes in the
+// environment's config, and records that they are valid.
+func (s *SystemManagerAPI) updateCredentials(env *state.Environment, attrs map[string]interface{}) error {
+	st, err := s.state.ForEnviron(env.EnvironTag())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	if err := st.UpdateEnvironConfig(attrs, nil, nil); err != nil {
+		return errors.Trace(err)
+	}
+	return env.SetCredentialValid(true, "")
+}
+
+// sharesCredentials reports whether cfg holds the same values as
+// oldCreds for every credential attribute being updated.
+func sharesCredentials(cfg *config.Config, oldCreds, newCreds map[string]string) bool {
+	all := cfg.AllAttrs()
+	for key := range newCreds {
+		if value, _ := all[key].(string); value != oldCreds[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCredentials applies attrs to cfg and, if the provider can check
+// credentials, verifies them with the cloud.
+func checkCredentials(cfg *config.Config, attrs map[string]interface{}) error {
+	newCfg, err := cfg.Apply(attrs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env, err := environs.New(newCfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	checker, ok := env.(environs.CredentialChecker)
+	if !ok {
+		logger.Debugf("provider %q cannot check credentials", newCfg.Type())
+		return nil
+	}
+	return checker.CheckCredentials()
+}
+
 // WatchAllEnvs starts watching events for all environments in the
 // system. The returned AllWatcherId should be used with Next on the
 // AllEnvWatcher endpoint to receive deltas.
@@ -4,6 +4,7 @@
 package systemmanager_test
 
 import (
+	"sort"
 	"time"
 
 	"github.com/juju/loggo"
@@ -186,6 +187,63 @@ func (s *systemManagerSuite) TestRemoveBlocksNotAll(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "not supported")
 }
 
+func (s *systemManagerSuite) TestUpdateCredentials(c *gc.C) {
+	shared := s.Factory.MakeEnvironment(c, &factory.EnvParams{
+		Name: "shared", ConfigAttrs: testing.Attrs{"secret": "pork"}})
+	defer shared.Close()
+	other := s.Factory.MakeEnvironment(c, &factory.EnvParams{
+		Name: "other", ConfigAttrs: testing.Attrs{"secret": "beef"}})
+	defer other.Close()
+	sharedEnv, err := shared.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = sharedEnv.SetCredentialValid(false, "expired")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.systemManager.UpdateCredentials(params.UpdateCredentials{
+		EnvironTag:  s.State.EnvironTag().String(),
+		Credentials: map[string]string{"secret": "fish"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	expected := []string{s.State.EnvironTag().String(), shared.EnvironTag().String()}
+	sort.Strings(expected)
+	c.Assert(result.EnvironTags, jc.DeepEquals, expected)
+
+	for _, st := range []*state.State{s.State, shared} {
+		cfg, err := st.EnvironConfig()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(cfg.AllAttrs()["secret"], gc.Equals, "fish")
+	}
+	err = sharedEnv.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	valid, reason := sharedEnv.CredentialValid()
+	c.Check(valid, jc.IsTrue)
+	c.Check(reason, gc.Equals, "")
+
+	cfg, err := other.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg.AllAttrs()["secret"], gc.Equals, "beef")
+}
+
+func (s *systemManagerSuite) TestUpdateCredentialsRejectsInvalid(c *gc.C) {
+	_, err := s.systemManager.UpdateCredentials(params.UpdateCredentials{
+		EnvironTag:  s.State.EnvironTag().String(),
+		Credentials: map[string]string{"secret": "invalid"},
+	})
+	c.Assert(err, gc.ErrorMatches, "new credentials not valid: dummy secret not valid")
+
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.AllAttrs()["secret"], gc.Equals, "pork")
+}
+
+func (s *systemManagerSuite) TestUpdateCredentialsRejectsNonCredential(c *gc.C) {
+	_, err := s.systemManager.UpdateCredentials(params.UpdateCredentials{
+		EnvironTag:  s.State.EnvironTag().String(),
+		Credentials: map[string]string{"name": "foo"},
+	})
+	c.Assert(err, gc.ErrorMatches, `attribute "name" is not a credential`)
+}
+
 func (s *systemManagerSuite) TestWatchAllEnvs(c *gc.C) {
 	watcherId, err := s.systemManager.WatchAllEnvs()
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,312 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 3.
+
+package uniter
+
+import (
+	"net"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 3, NewUniterAPIV3)
+}
+
+// UniterAPIV3 implements the API version 3, used by the uniter worker.
+type UniterAPIV3 struct {
+	UniterAPIV2
+}
+
+// NewUniterAPIV3 creates a new instance of the Uniter API, version 3.
+func NewUniterAPIV3(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV3, error) {
+	baseAPI, err := NewUniterAPIV2(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV3{
+		UniterAPIV2: *baseAPI,
+	}, nil
+}
+
+// ActionStatus returns the status of each of the Actions by Tags passed,
+// so that a Unit running one of them can learn that it has been
+// cancelled.
+func (u *UniterAPIV3) ActionStatus(args params.Entities) (params.StringResults, error) {
+	nothing := params.StringResults{}
+
+	actionFn, err := u.authAndActionFromTagFn()
+	if err != nil {
+		return nothing, err
+	}
+
+	results := params.StringResults{Results: make([]params.StringResult, len(args.Entities))}
+
+	for i, arg := range args.Entities {
+		action, err := actionFn(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Result = string(action.Status())
+	}
+
+	return results, nil
+}
+
+// SetWorkloadVersion sets the workload version of each of the units
+// passed in args.
+func (u *UniterAPIV3) SetWorkloadVersion(args params.EntityWorkloadVersions) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				err = unit.SetWorkloadVersion(entity.WorkloadVersion)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WorkloadVersion returns the workload version of each of the units
+// passed in args.
+func (u *UniterAPIV3) WorkloadVersion(args params.Entities) (params.StringResults, error) {
+	result := params.StringResults{
+		Results: make([]params.StringResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				result.Results[i].Result = unit.WorkloadVersion()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// CharmState returns the key/value state stored by the charm of each
+// of the units passed in args.
+func (u *UniterAPIV3) CharmState(args params.Entities) (params.CharmStateResults, error) {
+	result := params.CharmStateResults{
+		Results: make([]params.CharmStateResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.CharmStateResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				result.Results[i].State, err = unit.CharmState()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetCharmState updates the key/value state stored by the charm of each
+// of the units passed in args.
+func (u *UniterAPIV3) SetCharmState(args params.SetUnitCharmStateArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		tag, err := names.ParseUnitTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				err = unit.SetCharmState(arg.Set, arg.Unset)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// HookRetryStrategy returns how each of the units passed in args
+// should retry failed hooks.
+func (u *UniterAPIV3) HookRetryStrategy(args params.Entities) (params.HookRetryStrategyResults, error) {
+	result := params.HookRetryStrategyResults{
+		Results: make([]params.HookRetryStrategyResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.HookRetryStrategyResults{}, err
+	}
+	cfg, err := u.UniterAPIV1.st.EnvironConfig()
+	if err != nil {
+		return params.HookRetryStrategyResults{}, err
+	}
+	opts := cfg.HookRetryStrategy()
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		result.Results[i].Result = &params.HookRetryStrategy{
+			ShouldRetry:   opts.Enabled,
+			MaxAttempts:   opts.MaxAttempts,
+			MinDelay:      opts.MinDelay,
+			MaxDelay:      opts.MaxDelay,
+			BackoffFactor: opts.BackoffFactor,
+		}
+	}
+	return result, nil
+}
+
+// NetworkConfig returns, for each of the units and endpoint bindings
+// passed in args, the addresses of the unit's machine in the space
+// the endpoint is bound to. The unit's private address is returned
+// for endpoints that are not bound to a space.
+func (u *UniterAPIV3) NetworkConfig(args params.UnitsNetworkConfig) (params.UnitNetworkConfigResults, error) {
+	result := params.UnitNetworkConfigResults{
+		Results: make([]params.UnitNetworkConfigResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.UnitNetworkConfigResults{}, err
+	}
+	for i, arg := range args.Args {
+		tag, err := names.ParseUnitTag(arg.UnitTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				result.Results[i].Config, err = u.getOneNetworkConfig(unit, arg.BindingName)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV3) getOneNetworkConfig(unit *state.Unit, bindingName string) ([]params.NetworkConfig, error) {
+	if bindingName == "" {
+		return nil, errors.NotValidf("empty binding name")
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := service.Endpoint(bindingName); err != nil {
+		return nil, errors.NotFoundf("binding %q", bindingName)
+	}
+	bindings, err := service.EndpointBindings()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	spaceName, ok := bindings[bindingName]
+	if !ok {
+		address, err := unit.PrivateAddress()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []params.NetworkConfig{{Address: address.Value}}, nil
+	}
+
+	space, err := u.UniterAPIV1.st.Space(spaceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	subnets, err := space.Subnets()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machineId, err := unit.AssignedMachineId()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machine, err := u.UniterAPIV1.st.Machine(machineId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var config []params.NetworkConfig
+	for _, address := range machine.Addresses() {
+		ip := net.ParseIP(address.Value)
+		if ip == nil {
+			continue
+		}
+		for _, subnet := range subnets {
+			_, ipNet, err := net.ParseCIDR(subnet.CIDR())
+			if err != nil || !ipNet.Contains(ip) {
+				continue
+			}
+			config = append(config, params.NetworkConfig{
+				CIDR:             subnet.CIDR(),
+				Address:          address.Value,
+				ProviderSubnetId: subnet.ProviderId(),
+				VLANTag:          subnet.VLANTag(),
+			})
+			break
+		}
+	}
+	if len(config) == 0 {
+		return nil, errors.NotFoundf("address of machine %q in space %q", machineId, spaceName)
+	}
+	return config, nil
+}
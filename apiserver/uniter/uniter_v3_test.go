@@ -0,0 +1,220 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type uniterV3Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV3
+}
+
+var _ = gc.Suite(&uniterV3Suite{})
+
+func (s *uniterV3Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV3, err := uniter.NewUniterAPIV3(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV3
+}
+
+func (s *uniterV3Suite) TestActionStatus(c *gc.C) {
+	good, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = good.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	good, err = good.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+
+	bad, err := s.mysqlUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.ActionStatus(params.Entities{Entities: []params.Entity{
+		{Tag: good.Tag().String()},
+		{Tag: bad.Tag().String()},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{Results: []params.StringResult{
+		{Result: params.ActionAborting},
+		{Error: apiservertesting.ErrUnauthorized},
+	}})
+}
+
+func (s *uniterV3Suite) TestSetWorkloadVersion(c *gc.C) {
+	args := params.EntityWorkloadVersions{Entities: []params.EntityWorkloadVersion{
+		{Tag: "unit-mysql-0", WorkloadVersion: "5.7"},
+		{Tag: "unit-wordpress-0", WorkloadVersion: "4.2.1"},
+		{Tag: "unit-foo-42", WorkloadVersion: "1.0"},
+	}}
+	result, err := s.uniter.SetWorkloadVersion(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.wordpressUnit.WorkloadVersion(), gc.Equals, "4.2.1")
+	err = s.mysqlUnit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysqlUnit.WorkloadVersion(), gc.Equals, "")
+}
+
+func (s *uniterV3Suite) TestWorkloadVersion(c *gc.C) {
+	err := s.wordpressUnit.SetWorkloadVersion("4.2.1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.WorkloadVersion(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: "4.2.1"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV3Suite) TestHookRetryStrategy(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"automatically-retry-hooks": true,
+		"hook-retry-max-attempts":   3,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "service-wordpress"},
+	}}
+	result, err := s.uniter.HookRetryStrategy(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.HookRetryStrategyResults{
+		Results: []params.HookRetryStrategyResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: &params.HookRetryStrategy{
+				ShouldRetry:   true,
+				MaxAttempts:   3,
+				MinDelay:      5 * time.Second,
+				MaxDelay:      5 * time.Minute,
+				BackoffFactor: 2,
+			}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV3Suite) TestCharmState(c *gc.C) {
+	err := s.wordpressUnit.SetCharmState(map[string]string{"foo": "bar"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.CharmState(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.CharmStateResults{
+		Results: []params.CharmStateResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{State: map[string]string{"foo": "bar"}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV3Suite) TestSetCharmState(c *gc.C) {
+	err := s.wordpressUnit.SetCharmState(map[string]string{"foo": "bar", "baz": "qux"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.SetUnitCharmStateArgs{Args: []params.SetUnitCharmState{
+		{Tag: "unit-mysql-0", Set: map[string]string{"foo": "mysql"}},
+		{Tag: "unit-wordpress-0", Set: map[string]string{"foo": "new"}, Unset: []string{"baz"}},
+		{Tag: "unit-foo-42", Set: map[string]string{"foo": "foo"}},
+	}}
+	result, err := s.uniter.SetCharmState(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	charmState, err := s.wordpressUnit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, jc.DeepEquals, map[string]string{"foo": "new"})
+	charmState, err = s.mysqlUnit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, gc.HasLen, 0)
+}
+
+func (s *uniterV3Suite) TestNetworkConfig(c *gc.C) {
+	_, err := s.State.AddSubnet(state.SubnetInfo{
+		CIDR:       "10.0.0.0/24",
+		ProviderId: "subnet-0",
+		VLANTag:    42,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSpace("internal", []string{"10.0.0.0/24"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.wordpress.SetEndpointBindings(map[string]string{"db": "internal"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine0.SetProviderAddresses(
+		network.NewScopedAddress("8.8.8.8", network.ScopePublic),
+		network.NewScopedAddress("10.0.0.5", network.ScopeCloudLocal),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.UnitsNetworkConfig{Args: []params.UnitNetworkConfig{
+		{UnitTag: "unit-mysql-0", BindingName: "server"},
+		{UnitTag: "unit-wordpress-0", BindingName: "db"},
+		{UnitTag: "unit-wordpress-0", BindingName: "url"},
+		{UnitTag: "unit-wordpress-0", BindingName: "foo"},
+		{UnitTag: "unit-foo-42", BindingName: "db"},
+	}}
+	result, err := s.uniter.NetworkConfig(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.UnitNetworkConfigResults{
+		Results: []params.UnitNetworkConfigResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Config: []params.NetworkConfig{{
+				CIDR:             "10.0.0.0/24",
+				Address:          "10.0.0.5",
+				ProviderSubnetId: "subnet-0",
+				VLANTag:          42,
+			}}},
+			{Config: []params.NetworkConfig{{Address: "10.0.0.5"}}},
+			{Error: apiservertesting.NotFoundError(`binding "foo"`)},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
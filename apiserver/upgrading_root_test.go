@@ -22,7 +22,7 @@ func (r *upgradingRootSuite) TestClientMethods(c *gc.C) {
 
 	for _, method := range []string{
 		"FullStatus", "EnvironmentGet", "PrivateAddress",
-		"PublicAddress",
+		"PublicAddress", "SSHHostKeys",
 	} {
 		caller, err := root.FindMethod("Client", 0, method)
 		c.Check(err, jc.ErrorIsNil)
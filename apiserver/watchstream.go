@@ -0,0 +1,507 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"golang.org/x/net/websocket"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+var (
+	// watchStreamHistory is the number of frames a watch session
+	// keeps, so that a client that reconnects can be sent the frames
+	// it missed.
+	watchStreamHistory = 100
+
+	// watchSessionExpiry is how long a watch session is kept after
+	// its client disconnects.
+	watchSessionExpiry = time.Minute
+)
+
+// allWatcher is the part of *state.Multiwatcher used by a watch
+// session.
+type allWatcher interface {
+	Next() ([]multiwatcher.Delta, error)
+	Stop() error
+}
+
+// watchStreamHandler serves the changes to an environment, as seen by
+// an all-watcher, over a websocket. Rather than whole entities, each
+// frame sent holds only the fields of each entity that have changed
+// since the client last saw it.
+//
+// The changes are tracked by a watch session that outlives the
+// connection for a while, so that a client that reconnects after a
+// network blip can carry on where it left off without a full resync.
+//
+// If the gzip query parameter is true, the frames that follow the
+// initial error response are gzip-compressed. The resume query
+// parameter holds the resume token of the last frame the client
+// received; if the session it names has expired, or no longer holds the
+// frames that followed, the client is sent a frame with Resync set
+// instead.
+type watchStreamHandler struct {
+	ctxt     httpContext
+	sessions *watchSessions
+	stop     <-chan struct{}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *watchStreamHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			st, entity, err := h.ctxt.stateForRequestAuthenticatedUser(req)
+			if err != nil {
+				sendJSON(conn, &params.ErrorResult{Error: common.ServerError(err)})
+				return
+			}
+			compress := false
+			if value := req.URL.Query().Get("gzip"); value != "" {
+				if compress, err = strconv.ParseBool(value); err != nil {
+					err = errors.NotValidf("gzip value %q", value)
+					sendJSON(conn, &params.ErrorResult{Error: common.ServerError(err)})
+					return
+				}
+			}
+			owner := clientKey(st.EnvironUUID(), entity.Tag().String())
+			session, seq, err := h.sessions.resume(req.URL.Query().Get("resume"), owner, func() allWatcher {
+				return st.Watch()
+			})
+			if err != nil {
+				sendJSON(conn, &params.ErrorResult{Error: common.ServerError(err)})
+				return
+			}
+			sendJSON(conn, &params.ErrorResult{})
+			if compress {
+				conn.PayloadType = websocket.BinaryFrame
+			}
+
+			// The client sends nothing once connected, so the
+			// connection is closed when a read fails.
+			closed := make(chan struct{})
+			go func() {
+				io.Copy(ioutil.Discard, conn)
+				close(closed)
+			}()
+			if err := h.sessions.serve(session, seq, conn, compress, closed, h.stop); err != nil {
+				logger.Errorf("watch stream error: %v", err)
+			}
+		},
+	}
+	server.ServeHTTP(w, req)
+}
+
+// watchSessions holds the server's watch sessions.
+type watchSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*watchSession
+}
+
+func newWatchSessions() *watchSessions {
+	return &watchSessions{
+		sessions: make(map[string]*watchSession),
+	}
+}
+
+// resume returns the session named by the resume token, and the
+// sequence number of the last frame its client received. If the token
+// is empty, or names a session that has expired or belongs to a
+// different client, a new session is started with a watcher returned
+// by newWatcher.
+func (r *watchSessions) resume(token, owner string, newWatcher func() allWatcher) (*watchSession, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token != "" {
+		id, seq, err := parseResumeToken(token)
+		if err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		if session, ok := r.sessions[id]; ok && session.owner == owner {
+			return session, seq, nil
+		}
+		logger.Debugf("watch session %q not found; starting a new session", id)
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	session := newWatchSession(uuid.String(), owner, newWatcher())
+	r.sessions[session.id] = session
+	go func() {
+		if err := session.tomb.Wait(); err != nil {
+			logger.Errorf("watch session %q failed: %v", session.id, err)
+		}
+		r.mu.Lock()
+		delete(r.sessions, session.id)
+		r.mu.Unlock()
+	}()
+	return session, 0, nil
+}
+
+// serve writes the session's frames that follow the one with the given
+// sequence number to w, until the client disconnects and closed is
+// closed, another client attaches to the session, or stop is closed.
+// When serve returns, the session is stopped if no client attaches to
+// it within watchSessionExpiry.
+func (r *watchSessions) serve(
+	session *watchSession,
+	seq int64,
+	w io.Writer,
+	compress bool,
+	closed, stop <-chan struct{},
+) error {
+	attached, generation := session.attach()
+	defer func() {
+		if session.detach(generation) {
+			time.AfterFunc(watchSessionExpiry, func() {
+				session.expire(generation)
+			})
+		}
+	}()
+
+	var gzw *gzip.Writer
+	if compress {
+		gzw = gzip.NewWriter(w)
+		// Write the gzip header straight away, so the
+		// client's reader can be created.
+		if err := gzw.Flush(); err != nil {
+			return errors.Trace(err)
+		}
+		w = gzw
+	}
+	for {
+		frames, changed := session.framesSince(seq)
+		for _, frame := range frames {
+			if err := writeWatchFrame(w, frame.frame); err != nil {
+				return errors.Trace(err)
+			}
+			seq = frame.seq
+		}
+		if gzw != nil && len(frames) > 0 {
+			if err := gzw.Flush(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		select {
+		case <-changed:
+		case <-attached:
+			// Another client has taken over the session.
+			return nil
+		case <-closed:
+			return nil
+		case <-stop:
+			return nil
+		case <-session.tomb.Dead():
+			return errors.Trace(session.tomb.Err())
+		}
+	}
+}
+
+// stopAll stops all the sessions.
+func (r *watchSessions) stopAll() {
+	r.mu.Lock()
+	sessions := make([]*watchSession, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions = append(sessions, session)
+	}
+	r.mu.Unlock()
+	for _, session := range sessions {
+		session.Stop()
+	}
+}
+
+// writeWatchFrame writes the JSON encoding of the frame to w, followed
+// by a newline, with a single call to w.Write.
+func writeWatchFrame(w io.Writer, frame params.WatchStreamFrame) error {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return errors.Trace(err)
+}
+
+// resumeToken returns the resume token for the frame with the given
+// sequence number in the given session.
+func resumeToken(id string, seq int64) string {
+	return fmt.Sprintf("%s:%d", id, seq)
+}
+
+// parseResumeToken returns the session id and sequence number held in
+// a resume token.
+func parseResumeToken(token string) (string, int64, error) {
+	i := strings.LastIndex(token, ":")
+	if i <= 0 {
+		return "", 0, errors.NotValidf("resume token %q", token)
+	}
+	seq, err := strconv.ParseInt(token[i+1:], 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, errors.NotValidf("resume token %q", token)
+	}
+	return token[:i], seq, nil
+}
+
+// watchFrame holds a frame sent by a watch session, and its sequence
+// number.
+type watchFrame struct {
+	seq   int64
+	frame params.WatchStreamFrame
+}
+
+// watchSession turns the deltas from an all-watcher into frames holding
+// field-level changes. It keeps the latest frames, so a client that
+// reconnects can be sent those it missed, and the fields of every entity
+// sent, so that it can tell which have changed.
+type watchSession struct {
+	tomb    tomb.Tomb
+	id      string
+	owner   string
+	watcher allWatcher
+
+	mu       sync.Mutex
+	seq      int64
+	frames   []watchFrame
+	entities map[multiwatcher.EntityId]map[string]json.RawMessage
+
+	// changed is closed, and replaced, when a frame is added.
+	changed chan struct{}
+
+	// attached is closed, and replaced, when a client attaches to the
+	// session; it is nil while no client is attached. generation is
+	// incremented each time.
+	attached   chan struct{}
+	generation int64
+}
+
+func newWatchSession(id, owner string, watcher allWatcher) *watchSession {
+	s := &watchSession{
+		id:       id,
+		owner:    owner,
+		watcher:  watcher,
+		entities: make(map[multiwatcher.EntityId]map[string]json.RawMessage),
+		changed:  make(chan struct{}),
+	}
+	go func() {
+		defer s.tomb.Done()
+		s.tomb.Kill(s.loop())
+	}()
+	return s
+}
+
+// Stop stops the session and its watcher.
+func (s *watchSession) Stop() error {
+	s.tomb.Kill(nil)
+	return s.tomb.Wait()
+}
+
+func (s *watchSession) loop() error {
+	go func() {
+		<-s.tomb.Dying()
+		s.watcher.Stop()
+	}()
+	for {
+		deltas, err := s.watcher.Next()
+		if err != nil {
+			select {
+			case <-s.tomb.Dying():
+				return tomb.ErrDying
+			default:
+			}
+			return errors.Trace(err)
+		}
+		if err := s.addFrame(deltas); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// addFrame adds a frame holding the changes in the deltas, if there
+// are any. The first frame of a session is always added, and has
+// Resync set.
+func (s *watchSession) addFrame(deltas []multiwatcher.Delta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	frame := params.WatchStreamFrame{Resync: s.seq == 0}
+	for _, delta := range deltas {
+		id := delta.Entity.EntityId()
+		if delta.Removed {
+			if _, ok := s.entities[id]; !ok {
+				// The client never saw it.
+				continue
+			}
+			delete(s.entities, id)
+			frame.Deltas = append(frame.Deltas, params.WatchStreamDelta{
+				Kind:    id.Kind,
+				EnvUUID: id.EnvUUID,
+				Id:      id.Id,
+				Removed: true,
+			})
+			continue
+		}
+		fields, err := entityFields(delta.Entity)
+		if err != nil {
+			return errors.Annotatef(err, "cannot encode %s %q", id.Kind, id.Id)
+		}
+		changed := changedFields(s.entities[id], fields)
+		if len(changed) == 0 {
+			continue
+		}
+		s.entities[id] = fields
+		frame.Deltas = append(frame.Deltas, params.WatchStreamDelta{
+			Kind:    id.Kind,
+			EnvUUID: id.EnvUUID,
+			Id:      id.Id,
+			Fields:  changed,
+		})
+	}
+	if len(frame.Deltas) == 0 && !frame.Resync {
+		return nil
+	}
+	s.seq++
+	frame.ResumeToken = resumeToken(s.id, s.seq)
+	s.frames = append(s.frames, watchFrame{s.seq, frame})
+	if n := len(s.frames) - watchStreamHistory; n > 0 {
+		s.frames = append([]watchFrame(nil), s.frames[n:]...)
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return nil
+}
+
+// framesSince returns the frames that follow the one with the given
+// sequence number, and a channel that is closed when another frame is
+// added. If the session no longer holds all the frames that follow, it
+// returns a single frame holding every entity the session has sent, in
+// full, with Resync set.
+func (s *watchSession) framesSince(seq int64) ([]watchFrame, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq >= s.seq {
+		return nil, s.changed
+	}
+	if len(s.frames) > 0 && seq >= s.frames[0].seq-1 {
+		i := int(seq - s.frames[0].seq + 1)
+		return append([]watchFrame(nil), s.frames[i:]...), s.changed
+	}
+	frame := params.WatchStreamFrame{
+		ResumeToken: resumeToken(s.id, s.seq),
+		Resync:      true,
+	}
+	for id, fields := range s.entities {
+		frame.Deltas = append(frame.Deltas, params.WatchStreamDelta{
+			Kind:    id.Kind,
+			EnvUUID: id.EnvUUID,
+			Id:      id.Id,
+			Fields:  fields,
+		})
+	}
+	sort.Sort(byEntityId(frame.Deltas))
+	return []watchFrame{{s.seq, frame}}, s.changed
+}
+
+// attach attaches a client to the session, detaching any other. It
+// returns a channel that is closed when another client attaches, and
+// the generation of the attachment.
+func (s *watchSession) attach() (<-chan struct{}, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attached != nil {
+		close(s.attached)
+	}
+	s.attached = make(chan struct{})
+	s.generation++
+	return s.attached, s.generation
+}
+
+// detach detaches the client attached with the given generation, and
+// reports whether the session has been left without a client.
+func (s *watchSession) detach(generation int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return false
+	}
+	s.attached = nil
+	return true
+}
+
+// expire stops the session if no client has attached to it since the
+// one with the given generation detached.
+func (s *watchSession) expire(generation int64) {
+	s.mu.Lock()
+	expired := s.generation == generation && s.attached == nil
+	s.mu.Unlock()
+	if expired {
+		logger.Debugf("watch session %q expired", s.id)
+		s.Stop()
+	}
+}
+
+// entityFields returns the JSON encoding of each field of the entity.
+func entityFields(info multiwatcher.EntityInfo) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return fields, nil
+}
+
+// changedFields returns the fields in new whose encoding differs from
+// that in old. Fields in old that are missing from new are returned as
+// null.
+func changedFields(old, new map[string]json.RawMessage) map[string]json.RawMessage {
+	changed := make(map[string]json.RawMessage)
+	for name, value := range new {
+		if oldValue, ok := old[name]; !ok || !bytes.Equal(oldValue, value) {
+			changed[name] = value
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			changed[name] = json.RawMessage("null")
+		}
+	}
+	return changed
+}
+
+type byEntityId []params.WatchStreamDelta
+
+func (d byEntityId) Len() int      { return len(d) }
+func (d byEntityId) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d byEntityId) Less(i, j int) bool {
+	if d[i].Kind != d[j].Kind {
+		return d[i].Kind < d[j].Kind
+	}
+	if d[i].EnvUUID != d[j].EnvUUID {
+		return d[i].EnvUUID < d[j].EnvUUID
+	}
+	return d[i].Id < d[j].Id
+}
+
+var _ allWatcher = (*state.Multiwatcher)(nil)
@@ -0,0 +1,353 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/multiwatcher"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type watchStreamIntSuite struct {
+	coretesting.BaseSuite
+	watcher *fakeAllWatcher
+}
+
+var _ = gc.Suite(&watchStreamIntSuite{})
+
+func (s *watchStreamIntSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.watcher = newFakeAllWatcher()
+}
+
+func (s *watchStreamIntSuite) newSession(c *gc.C) *watchSession {
+	session := newWatchSession("session", "uuid:user-admin", s.watcher)
+	s.AddCleanup(func(*gc.C) { session.Stop() })
+	return session
+}
+
+// waitForSeq waits until the session has added the frame with the given
+// sequence number.
+func waitForSeq(c *gc.C, session *watchSession, seq int64) {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		session.mu.Lock()
+		current := session.seq
+		session.mu.Unlock()
+		if current >= seq {
+			return
+		}
+		select {
+		case <-time.After(coretesting.ShortWait):
+		case <-timeout:
+			c.Fatalf("timed out waiting for frame %d", seq)
+		}
+	}
+}
+
+func machineDelta(id, status string) multiwatcher.Delta {
+	return multiwatcher.Delta{
+		Entity: &multiwatcher.MachineInfo{
+			EnvUUID: "uuid",
+			Id:      id,
+			Status:  multiwatcher.Status(status),
+		},
+	}
+}
+
+func (s *watchStreamIntSuite) TestFirstFrameIsResync(c *gc.C) {
+	session := s.newSession(c)
+	s.watcher.send(nil)
+	waitForSeq(c, session, 1)
+
+	frames, _ := session.framesSince(0)
+	c.Assert(frames, gc.HasLen, 1)
+	c.Assert(frames[0].frame, jc.DeepEquals, params.WatchStreamFrame{
+		ResumeToken: "session:1",
+		Resync:      true,
+	})
+}
+
+func (s *watchStreamIntSuite) TestFieldDeltas(c *gc.C) {
+	session := s.newSession(c)
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "pending")})
+	waitForSeq(c, session, 1)
+	frames, _ := session.framesSince(0)
+	c.Assert(frames, gc.HasLen, 1)
+	c.Assert(frames[0].frame.Deltas, gc.HasLen, 1)
+	delta := frames[0].frame.Deltas[0]
+	c.Assert(delta.Kind, gc.Equals, "machine")
+	c.Assert(delta.Id, gc.Equals, "0")
+	c.Assert(string(delta.Fields["Status"]), gc.Equals, `"pending"`)
+	c.Assert(string(delta.Fields["Id"]), gc.Equals, `"0"`)
+
+	// An unchanged entity adds no frame; a changed one sends only the
+	// fields that changed.
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "pending")})
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "started")})
+	waitForSeq(c, session, 2)
+	frames, _ = session.framesSince(1)
+	c.Assert(frames, gc.HasLen, 1)
+	c.Assert(frames[0].seq, gc.Equals, int64(2))
+	c.Assert(frames[0].frame, jc.DeepEquals, params.WatchStreamFrame{
+		ResumeToken: "session:2",
+		Deltas: []params.WatchStreamDelta{{
+			Kind:    "machine",
+			EnvUUID: "uuid",
+			Id:      "0",
+			Fields:  map[string]json.RawMessage{"Status": json.RawMessage(`"started"`)},
+		}},
+	})
+}
+
+func (s *watchStreamIntSuite) TestRemovals(c *gc.C) {
+	session := s.newSession(c)
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "pending")})
+	waitForSeq(c, session, 1)
+
+	removed := machineDelta("0", "")
+	removed.Removed = true
+	unseen := machineDelta("1", "")
+	unseen.Removed = true
+	s.watcher.send([]multiwatcher.Delta{unseen, removed})
+	waitForSeq(c, session, 2)
+	frames, _ := session.framesSince(1)
+	c.Assert(frames, gc.HasLen, 1)
+	c.Assert(frames[0].frame.Deltas, jc.DeepEquals, []params.WatchStreamDelta{{
+		Kind:    "machine",
+		EnvUUID: "uuid",
+		Id:      "0",
+		Removed: true,
+	}})
+	c.Assert(session.entities, gc.HasLen, 0)
+}
+
+func (s *watchStreamIntSuite) TestResyncWhenHistoryExceeded(c *gc.C) {
+	s.PatchValue(&watchStreamHistory, 2)
+	session := s.newSession(c)
+	for i, status := range []string{"pending", "started", "error", "down"} {
+		s.watcher.send([]multiwatcher.Delta{machineDelta("0", status)})
+		waitForSeq(c, session, int64(i+1))
+	}
+
+	frames, _ := session.framesSince(2)
+	c.Assert(frames, gc.HasLen, 2)
+	c.Assert(frames[0].seq, gc.Equals, int64(3))
+	c.Assert(frames[1].seq, gc.Equals, int64(4))
+
+	frames, _ = session.framesSince(1)
+	c.Assert(frames, gc.HasLen, 1)
+	frame := frames[0].frame
+	c.Assert(frame.Resync, jc.IsTrue)
+	c.Assert(frame.ResumeToken, gc.Equals, "session:4")
+	c.Assert(frame.Deltas, gc.HasLen, 1)
+	c.Assert(string(frame.Deltas[0].Fields["Status"]), gc.Equals, `"down"`)
+	c.Assert(string(frame.Deltas[0].Fields["Id"]), gc.Equals, `"0"`)
+}
+
+func (s *watchStreamIntSuite) TestServe(c *gc.C) {
+	session := s.newSession(c)
+	var buf syncBuffer
+	closed := make(chan struct{})
+	done := make(chan error, 1)
+	sessions := newWatchSessions()
+	go func() {
+		done <- sessions.serve(session, 0, &buf, false, closed, nil)
+	}()
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "pending")})
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "started")})
+
+	var frames []params.WatchStreamFrame
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		frames = buf.frames(c)
+		if len(frames) == 2 {
+			break
+		}
+	}
+	c.Assert(frames, gc.HasLen, 2)
+	c.Assert(frames[0].Resync, jc.IsTrue)
+	c.Assert(frames[1].ResumeToken, gc.Equals, "session:2")
+	close(closed)
+	select {
+	case err := <-done:
+		c.Assert(err, jc.ErrorIsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("serve did not return")
+	}
+}
+
+func (s *watchStreamIntSuite) TestServeGzip(c *gc.C) {
+	session := s.newSession(c)
+	var buf syncBuffer
+	closed := make(chan struct{})
+	defer close(closed)
+	go newWatchSessions().serve(session, 0, &buf, true, closed, nil)
+	s.watcher.send([]multiwatcher.Delta{machineDelta("0", "pending")})
+	waitForSeq(c, session, 1)
+
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		data := buf.bytes()
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		var frame params.WatchStreamFrame
+		if err := json.NewDecoder(zr).Decode(&frame); err != nil {
+			continue
+		}
+		c.Assert(frame.Resync, jc.IsTrue)
+		c.Assert(frame.Deltas, gc.HasLen, 1)
+		return
+	}
+	c.Fatalf("no compressed frame written")
+}
+
+func (s *watchStreamIntSuite) TestExpiry(c *gc.C) {
+	session := s.newSession(c)
+	_, generation := session.attach()
+	c.Assert(session.detach(generation), jc.IsTrue)
+
+	// A client that attached since keeps the session alive.
+	_, newGeneration := session.attach()
+	session.expire(generation)
+	select {
+	case <-session.tomb.Dead():
+		c.Fatalf("session stopped while attached")
+	default:
+	}
+	c.Assert(session.detach(generation), jc.IsFalse)
+	c.Assert(session.detach(newGeneration), jc.IsTrue)
+
+	session.expire(newGeneration)
+	select {
+	case <-session.tomb.Dead():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("session not stopped")
+	}
+	c.Assert(s.watcher.stopped(), jc.IsTrue)
+}
+
+func (s *watchStreamIntSuite) TestResume(c *gc.C) {
+	sessions := newWatchSessions()
+	s.AddCleanup(func(*gc.C) { sessions.stopAll() })
+	newWatcher := func() allWatcher { return newFakeAllWatcher() }
+
+	session, seq, err := sessions.resume("", "uuid:user-admin", newWatcher)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(seq, gc.Equals, int64(0))
+
+	resumed, seq, err := sessions.resume(resumeToken(session.id, 5), "uuid:user-admin", newWatcher)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resumed == session, jc.IsTrue)
+	c.Assert(seq, gc.Equals, int64(5))
+
+	// Another client cannot resume the session.
+	other, seq, err := sessions.resume(resumeToken(session.id, 5), "uuid:user-bob", newWatcher)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(other == session, jc.IsFalse)
+	c.Assert(seq, gc.Equals, int64(0))
+
+	_, _, err = sessions.resume("bad-token", "uuid:user-admin", newWatcher)
+	c.Assert(err, gc.ErrorMatches, `resume token "bad-token" not valid`)
+}
+
+func (s *watchStreamIntSuite) TestParseResumeToken(c *gc.C) {
+	id, seq, err := parseResumeToken("abc:def:12")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(id, gc.Equals, "abc:def")
+	c.Assert(seq, gc.Equals, int64(12))
+
+	for _, token := range []string{"", ":1", "abc", "abc:", "abc:x", "abc:-1"} {
+		_, _, err := parseResumeToken(token)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+// fakeAllWatcher is an allWatcher that returns the deltas sent to it.
+type fakeAllWatcher struct {
+	deltas chan []multiwatcher.Delta
+	stop   chan struct{}
+}
+
+func newFakeAllWatcher() *fakeAllWatcher {
+	return &fakeAllWatcher{
+		deltas: make(chan []multiwatcher.Delta),
+		stop:   make(chan struct{}),
+	}
+}
+
+func (w *fakeAllWatcher) send(deltas []multiwatcher.Delta) {
+	w.deltas <- deltas
+}
+
+func (w *fakeAllWatcher) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *fakeAllWatcher) Next() ([]multiwatcher.Delta, error) {
+	select {
+	case deltas := <-w.deltas:
+		return deltas, nil
+	case <-w.stop:
+		return nil, errors.New("watcher was stopped")
+	}
+}
+
+func (w *fakeAllWatcher) Stop() error {
+	if !w.stopped() {
+		close(w.stop)
+	}
+	return nil
+}
+
+// syncBuffer is a bytes.Buffer that can be written and read
+// concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(data)
+}
+
+func (b *syncBuffer) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func (b *syncBuffer) frames(c *gc.C) []params.WatchStreamFrame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var frames []params.WatchStreamFrame
+	decoder := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for {
+		var frame params.WatchStreamFrame
+		err := decoder.Decode(&frame)
+		if err == io.EOF {
+			return frames
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		frames = append(frames, frame)
+	}
+}
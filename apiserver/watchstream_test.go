@@ -0,0 +1,135 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"encoding/json"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type watchStreamSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&watchStreamSuite{})
+
+// nextFrame returns the next frame from the stream, failing the test if
+// none arrives in time.
+func nextFrame(c *gc.C, stream *api.WatchStream) *params.WatchStreamFrame {
+	type result struct {
+		frame *params.WatchStreamFrame
+		err   error
+	}
+	results := make(chan result, 1)
+	go func() {
+		frame, err := stream.Next()
+		results <- result{frame, err}
+	}()
+	select {
+	case r := <-results:
+		c.Assert(r.err, jc.ErrorIsNil)
+		return r.frame
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for frame")
+	}
+	panic("unreachable")
+}
+
+// machineDelta returns the delta for the machine with the given id in
+// the frame, or nil if there is none.
+func machineDelta(frame *params.WatchStreamFrame, id string) *params.WatchStreamDelta {
+	for i, delta := range frame.Deltas {
+		if delta.Kind == "machine" && delta.Id == id {
+			return &frame.Deltas[i]
+		}
+	}
+	return nil
+}
+
+func (s *watchStreamSuite) testStream(c *gc.C, compress bool) {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	stream, err := s.APIState.Client().WatchStream(api.WatchStreamParams{Gzip: compress})
+	c.Assert(err, jc.ErrorIsNil)
+	defer stream.Close()
+
+	frame := nextFrame(c, stream)
+	c.Assert(frame.Resync, jc.IsTrue)
+	c.Assert(frame.ResumeToken, gc.Not(gc.Equals), "")
+	delta := machineDelta(frame, m.Id())
+	c.Assert(delta, gc.NotNil)
+	c.Assert(string(delta.Fields["Series"]), gc.Equals, `"quantal"`)
+
+	err = m.SetProvisioned("i-0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	frame = nextFrame(c, stream)
+	c.Assert(frame.Resync, jc.IsFalse)
+	delta = machineDelta(frame, m.Id())
+	c.Assert(delta, gc.NotNil)
+	c.Assert(string(delta.Fields["InstanceId"]), gc.Equals, `"i-0"`)
+	_, ok := delta.Fields["Series"]
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *watchStreamSuite) TestStream(c *gc.C) {
+	s.testStream(c, false)
+}
+
+func (s *watchStreamSuite) TestStreamGzip(c *gc.C) {
+	s.testStream(c, true)
+}
+
+func (s *watchStreamSuite) TestResume(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.APIState.Client()
+	stream, err := client.WatchStream(api.WatchStreamParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	frame := nextFrame(c, stream)
+	c.Assert(frame.Resync, jc.IsTrue)
+	stream.Close()
+
+	// Changes made while the client is disconnected are sent when it
+	// resumes, without a resync.
+	err = m.SetProvisioned("i-0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	stream, err = client.WatchStream(api.WatchStreamParams{
+		ResumeToken: frame.ResumeToken,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer stream.Close()
+	frame = nextFrame(c, stream)
+	c.Assert(frame.Resync, jc.IsFalse)
+	delta := machineDelta(frame, m.Id())
+	c.Assert(delta, gc.NotNil)
+	c.Assert(delta.Fields["InstanceId"], jc.DeepEquals, json.RawMessage(`"i-0"`))
+}
+
+func (s *watchStreamSuite) TestUnknownResumeToken(c *gc.C) {
+	stream, err := s.APIState.Client().WatchStream(api.WatchStreamParams{
+		ResumeToken: "unknown:10",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer stream.Close()
+	frame := nextFrame(c, stream)
+	c.Assert(frame.Resync, jc.IsTrue)
+}
+
+func (s *watchStreamSuite) TestBadResumeToken(c *gc.C) {
+	stream, err := s.APIState.Client().WatchStream(api.WatchStreamParams{
+		ResumeToken: "bad",
+	})
+	c.Assert(err, gc.ErrorMatches, `resume token "bad" not valid`)
+	c.Assert(stream, gc.IsNil)
+}
@@ -1,7 +1,9 @@
 // Copyright 2013, 2014 Canonical Ltd.
 // Licensed under the AGPLv3, see LICENCE file for details.
 
-// Package audit records auditable events
+// Package audit records auditable events. Agents that install an
+// audit log with logging.InstallAuditLog write the events to a
+// tamper-evident log.
 package audit
 
 import (
@@ -162,6 +162,15 @@ type InstanceConfig struct {
 	// override the default APT sources.
 	AptMirror string
 
+	// PackageSources holds additional package sources to install
+	// packages from: apt sources such as "ppa:juju/stable" or
+	// "deb ..." lines, or yum repository URLs.
+	PackageSources []string
+
+	// PackageSourceKeys holds the ASCII-armored GPG public keys that
+	// packages from PackageSources are signed with.
+	PackageSourceKeys string
+
 	// PreferIPv6 mirrors the value of prefer-ipv6 environment setting
 	// and when set IPv6 addresses for connecting to the API/state
 	// servers will be preferred over IPv4 ones.
@@ -479,6 +488,8 @@ func PopulateInstanceConfig(icfg *InstanceConfig,
 	sslHostnameVerification bool,
 	proxySettings, aptProxySettings proxy.Settings,
 	aptMirror string,
+	packageSources []string,
+	packageSourceKeys string,
 	preferIPv6 bool,
 	enableOSRefreshUpdates bool,
 	enableOSUpgrade bool,
@@ -496,6 +507,8 @@ func PopulateInstanceConfig(icfg *InstanceConfig,
 	icfg.ProxySettings = proxySettings
 	icfg.AptProxySettings = aptProxySettings
 	icfg.AptMirror = aptMirror
+	icfg.PackageSources = packageSources
+	icfg.PackageSourceKeys = packageSourceKeys
 	icfg.PreferIPv6 = preferIPv6
 	icfg.EnableOSRefreshUpdate = enableOSRefreshUpdates
 	icfg.EnableOSUpgrade = enableOSUpgrade
@@ -523,6 +536,8 @@ func FinishInstanceConfig(icfg *InstanceConfig, cfg *config.Config) (err error)
 		cfg.ProxySettings(),
 		cfg.AptProxySettings(),
 		cfg.AptMirror(),
+		cfg.PackageSources(),
+		cfg.PackageSourceKeys(),
 		cfg.PreferIPv6(),
 		cfg.EnableOSRefreshUpdate(),
 		cfg.EnableOSUpgrade(),
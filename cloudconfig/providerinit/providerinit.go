@@ -44,7 +44,9 @@ func configureCloudinit(icfg *instancecfg.InstanceConfig, cloudcfg cloudinit.Clo
 // so it is suitable for initialising a machine with the given configuration,
 // and then renders it and encodes it using the supplied renderer.
 // When calling ComposeUserData a encoding implementation must be chosen from
-// the providerinit/encoders package according to the need of the provider.
+// the providerinit/encoders package according to the need of the provider;
+// if it is a renderers.FormatRenderer it also chooses whether the userdata
+// is rendered as cloud-config or as a script.
 //
 // If the provided cloudcfg is nil, a new one will be created internally.
 func ComposeUserData(icfg *instancecfg.InstanceConfig, cloudcfg cloudinit.CloudConfig, renderer renderers.ProviderRenderer) ([]byte, error) {
@@ -63,13 +65,7 @@ func ComposeUserData(icfg *instancecfg.InstanceConfig, cloudcfg cloudinit.CloudC
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	// This might get replaced by a renderer.RenderUserdata which will either
-	// render it as YAML or Bash since some CentOS images might ship without cloudnit
-	udata, err := cloudcfg.RenderYAML()
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	udata, err = renderer.EncodeUserdata(udata, operatingSystem)
+	udata, err := renderers.RenderUserdata(cloudcfg, renderer, operatingSystem)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
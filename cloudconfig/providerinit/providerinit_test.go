@@ -24,6 +24,7 @@ import (
 	"github.com/juju/juju/cloudconfig/cloudinit"
 	"github.com/juju/juju/cloudconfig/instancecfg"
 	"github.com/juju/juju/cloudconfig/providerinit"
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/juju/paths"
@@ -260,6 +261,8 @@ func (*CloudInitSuite) testUserData(c *gc.C, bootstrap bool) {
 
 	unzipped, err := utils.Gunzip(result)
 	c.Assert(err, jc.ErrorIsNil)
+	err = renderers.CheckUserdataSyntax(unzipped, renderers.CloudConfigFormat)
+	c.Assert(err, jc.ErrorIsNil)
 
 	config := make(map[interface{}]interface{})
 	err = goyaml.Unmarshal(unzipped, &config)
@@ -14,12 +14,46 @@ import (
 
 // ProviderRenderer defines a method to encode userdata depending on
 // the OS and the provider.
-// In the future this might support another method for rendering
-// the userdata differently(bash vs yaml) since some providers might
-// not ship cloudinit on every OS
 type ProviderRenderer interface {
 
 	// EncodeUserdata takes a []byte and encodes it in the right format.
 	// The implementations are based on the different providers and OSTypes.
 	EncodeUserdata([]byte, os.OSType) ([]byte, error)
 }
+
+// FormatRenderer is implemented by ProviderRenderers that choose the
+// format the userdata is rendered in before it is encoded, since some
+// providers do not ship cloudinit on every OS. The userdata for other
+// ProviderRenderers is rendered in the DefaultFormat for the OS.
+type FormatRenderer interface {
+	ProviderRenderer
+
+	// UserdataFormat returns the format of the userdata for the OS.
+	UserdataFormat(os.OSType) Format
+}
+
+// Format is a format in which userdata can be rendered.
+type Format string
+
+const (
+	// CloudConfigFormat renders userdata as a cloud-config YAML
+	// document, to be run by cloudinit.
+	CloudConfigFormat Format = "cloud-config"
+
+	// ScriptFormat renders userdata as a bash script, for images
+	// without cloudinit.
+	ScriptFormat Format = "script"
+
+	// PowershellFormat renders userdata as a powershell script, for
+	// Windows images.
+	PowershellFormat Format = "powershell"
+)
+
+// DefaultFormat returns the format userdata is rendered in for the OS,
+// unless the provider chooses otherwise.
+func DefaultFormat(operatingSystem os.OSType) Format {
+	if operatingSystem == os.Windows {
+		return PowershellFormat
+	}
+	return CloudConfigFormat
+}
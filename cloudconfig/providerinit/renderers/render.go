@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package renderers
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/os"
+
+	"github.com/juju/juju/cloudconfig/cloudinit"
+)
+
+var logger = loggo.GetLogger("juju.cloudconfig.providerinit.renderers")
+
+// UserdataFormat returns the format the renderer's userdata is
+// rendered in for the OS.
+func UserdataFormat(renderer ProviderRenderer, operatingSystem os.OSType) Format {
+	if formatter, ok := renderer.(FormatRenderer); ok {
+		return formatter.UserdataFormat(operatingSystem)
+	}
+	return DefaultFormat(operatingSystem)
+}
+
+// RenderUserdata renders the cloud config in the format the renderer
+// chooses for the OS, validates it, and then encodes it using the
+// renderer. If the renderer is a SizeLimiter, userdata over the
+// cloud's limit is made smaller, or a *UserdataTooLargeError is
+// returned.
+func RenderUserdata(cfg cloudinit.RenderConfig, renderer ProviderRenderer, operatingSystem os.OSType) ([]byte, error) {
+	format := UserdataFormat(renderer, operatingSystem)
+	udata, err := Render(cfg, format, operatingSystem)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := ValidateUserdata(udata, format); err != nil {
+		return nil, errors.Annotate(err, "invalid userdata")
+	}
+	udata, err = fitUserdata(udata, format, renderer, operatingSystem)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return udata, nil
+}
+
+// Render renders the cloud config, for a machine running the OS, in the
+// format. Windows machines can only be given powershell userdata, and
+// other machines cannot.
+func Render(cfg cloudinit.RenderConfig, format Format, operatingSystem os.OSType) ([]byte, error) {
+	if (format == PowershellFormat) != (operatingSystem == os.Windows) {
+		return nil, errors.NotValidf("%s userdata for OS %s", format, operatingSystem)
+	}
+	switch format {
+	case CloudConfigFormat, PowershellFormat:
+		// Windows cloud configs render as powershell.
+		udata, err := cfg.RenderYAML()
+		return udata, errors.Trace(err)
+	case ScriptFormat:
+		script, err := cfg.RenderScript()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []byte(script), nil
+	}
+	return nil, errors.NotValidf("userdata format %q", format)
+}
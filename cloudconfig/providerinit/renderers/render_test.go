@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package renderers_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/os"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cloudconfig/cloudinit"
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
+	"github.com/juju/juju/testing"
+)
+
+type RenderSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&RenderSuite{})
+
+// plainRenderer encodes userdata unchanged, in the default format.
+type plainRenderer struct{}
+
+func (plainRenderer) EncodeUserdata(udata []byte, _ os.OSType) ([]byte, error) {
+	return udata, nil
+}
+
+// scriptRenderer encodes userdata unchanged, as a script on CentOS.
+type scriptRenderer struct {
+	plainRenderer
+}
+
+func (scriptRenderer) UserdataFormat(operatingSystem os.OSType) renderers.Format {
+	if operatingSystem == os.CentOS {
+		return renderers.ScriptFormat
+	}
+	return renderers.DefaultFormat(operatingSystem)
+}
+
+func newCloudConfig(c *gc.C, series string) cloudinit.CloudConfig {
+	cfg, err := cloudinit.New(series)
+	c.Assert(err, jc.ErrorIsNil)
+	cfg.AddRunCmd("echo provisioned")
+	return cfg
+}
+
+func (s *RenderSuite) TestDefaultFormat(c *gc.C) {
+	c.Check(renderers.DefaultFormat(os.Ubuntu), gc.Equals, renderers.CloudConfigFormat)
+	c.Check(renderers.DefaultFormat(os.CentOS), gc.Equals, renderers.CloudConfigFormat)
+	c.Check(renderers.DefaultFormat(os.Windows), gc.Equals, renderers.PowershellFormat)
+}
+
+func (s *RenderSuite) TestUserdataFormat(c *gc.C) {
+	c.Check(renderers.UserdataFormat(plainRenderer{}, os.CentOS), gc.Equals, renderers.CloudConfigFormat)
+	c.Check(renderers.UserdataFormat(scriptRenderer{}, os.CentOS), gc.Equals, renderers.ScriptFormat)
+	c.Check(renderers.UserdataFormat(scriptRenderer{}, os.Ubuntu), gc.Equals, renderers.CloudConfigFormat)
+}
+
+func (s *RenderSuite) TestRenderUserdataCloudConfig(c *gc.C) {
+	udata, err := renderers.RenderUserdata(newCloudConfig(c, "trusty"), scriptRenderer{}, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(strings.HasPrefix(string(udata), "#cloud-config\n"), jc.IsTrue)
+	c.Check(string(udata), jc.Contains, "echo provisioned")
+	err = renderers.CheckUserdataSyntax(udata, renderers.CloudConfigFormat)
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *RenderSuite) TestRenderUserdataScript(c *gc.C) {
+	udata, err := renderers.RenderUserdata(newCloudConfig(c, "centos7"), scriptRenderer{}, os.CentOS)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(strings.HasPrefix(string(udata), "#!/bin/bash\n"), jc.IsTrue)
+	c.Check(string(udata), jc.Contains, "echo provisioned")
+	err = renderers.CheckUserdataSyntax(udata, renderers.ScriptFormat)
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *RenderSuite) TestRenderUserdataPowershell(c *gc.C) {
+	cfg := newCloudConfig(c, "win2012r2")
+	expected, err := cfg.RenderYAML()
+	c.Assert(err, jc.ErrorIsNil)
+	udata, err := renderers.RenderUserdata(cfg, plainRenderer{}, os.Windows)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(udata), gc.Equals, string(expected))
+}
+
+func (s *RenderSuite) TestRenderInvalid(c *gc.C) {
+	for i, test := range []struct {
+		series string
+		format renderers.Format
+		os     os.OSType
+		err    string
+	}{{
+		series: "win2012r2",
+		format: renderers.ScriptFormat,
+		os:     os.Windows,
+		err:    "script userdata for OS Windows not valid",
+	}, {
+		series: "trusty",
+		format: renderers.PowershellFormat,
+		os:     os.Ubuntu,
+		err:    "powershell userdata for OS Ubuntu not valid",
+	}, {
+		series: "trusty",
+		format: "ignition",
+		os:     os.Ubuntu,
+		err:    `userdata format "ignition" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := renderers.Render(newCloudConfig(c, test.series), test.format, test.os)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
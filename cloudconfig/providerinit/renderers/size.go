@@ -0,0 +1,217 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package renderers
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"github.com/juju/utils/os"
+
+	"github.com/juju/juju/environs/storage"
+)
+
+// SizeLimiter is implemented by ProviderRenderers for clouds that limit
+// the size of userdata. Cloud-config userdata that is too large is
+// compressed, or, if the renderer is also a UserdataStore, replaced by
+// userdata that fetches it.
+type SizeLimiter interface {
+	ProviderRenderer
+
+	// UserdataSizeLimit returns the size, in bytes, of the largest
+	// encoded userdata the cloud accepts for the OS. Zero means
+	// there is no limit.
+	UserdataSizeLimit(os.OSType) int
+}
+
+// UserdataStore is implemented by ProviderRenderers that can store
+// userdata too large for their cloud, so that it can be fetched by the
+// machine instead.
+type UserdataStore interface {
+	// StoreUserdata stores the userdata and returns the HTTPS URL
+	// from which the machine can fetch it.
+	StoreUserdata(udata []byte) (string, error)
+}
+
+// StorageUserdataStore is a UserdataStore that puts userdata in an
+// environment's storage. The userdata holds the machine's secrets, so
+// the storage must give URLs that only the machine can use, such as
+// signed URLs for a private bucket, and they should expire soon after
+// the machine has started.
+type StorageUserdataStore struct {
+	// Storage is the storage the userdata is put in.
+	Storage storage.Storage
+
+	// Name is the name of the userdata's file in the storage.
+	Name string
+
+	// URL, if set, returns the URL from which the machine fetches
+	// the named file, in place of the storage's own URL for it.
+	URL func(name string) (string, error)
+}
+
+// StoreUserdata implements UserdataStore.
+func (s StorageUserdataStore) StoreUserdata(udata []byte) (string, error) {
+	if err := s.Storage.Put(s.Name, bytes.NewReader(udata), int64(len(udata))); err != nil {
+		return "", errors.Trace(err)
+	}
+	urlFunc := s.Storage.URL
+	if s.URL != nil {
+		urlFunc = s.URL
+	}
+	url, err := urlFunc(s.Name)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return url, nil
+}
+
+// RemoveUserdata removes any userdata stored by StoreUserdata.
+func (s StorageUserdataStore) RemoveUserdata() error {
+	return errors.Trace(s.Storage.Remove(s.Name))
+}
+
+// UserdataTooLargeError is returned when userdata cannot be made to
+// fit within a cloud's limit.
+type UserdataTooLargeError struct {
+	// Size is the size of the smallest userdata produced.
+	Size int
+
+	// Limit is the cloud's limit.
+	Limit int
+}
+
+// Error implements error.
+func (e *UserdataTooLargeError) Error() string {
+	return fmt.Sprintf("userdata is %d bytes, over the cloud's limit of %d bytes", e.Size, e.Limit)
+}
+
+// IsUserdataTooLarge returns whether the error's cause is a
+// UserdataTooLargeError.
+func IsUserdataTooLarge(err error) bool {
+	_, ok := errors.Cause(err).(*UserdataTooLargeError)
+	return ok
+}
+
+// MIME types of the parts of multi-part userdata understood by
+// cloud-init.
+const (
+	CloudConfigPartType = "text/cloud-config"
+	ScriptPartType      = "text/x-shellscript"
+	IncludeURLPartType  = "text/x-include-url"
+)
+
+// Part is a part of multi-part userdata.
+type Part struct {
+	// ContentType is the MIME type of the part, which tells
+	// cloud-init how to handle it.
+	ContentType string
+
+	// Content is the part's content.
+	Content []byte
+}
+
+// Multipart returns cloud-init multi-part userdata holding the parts.
+func Multipart(parts ...Part) ([]byte, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, part := range parts {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Type", part.ContentType+`; charset="us-ascii"`)
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "7bit")
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, err := pw.Write(part.Content); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n", w.Boundary())
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// fitUserdata encodes the rendered userdata with the renderer, making
+// it fit within the cloud's size limit if there is one. Only
+// cloud-config userdata can be made smaller, as cloud-init does the
+// work of expanding it.
+func fitUserdata(udata []byte, format Format, renderer ProviderRenderer, operatingSystem os.OSType) ([]byte, error) {
+	encoded, err := renderer.EncodeUserdata(udata, operatingSystem)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	limiter, ok := renderer.(SizeLimiter)
+	if !ok {
+		return encoded, nil
+	}
+	limit := limiter.UserdataSizeLimit(operatingSystem)
+	if limit <= 0 || len(encoded) <= limit {
+		return encoded, nil
+	}
+	logger.Debugf("userdata is %d bytes, over the limit of %d bytes", len(encoded), limit)
+	if format != CloudConfigFormat {
+		return nil, &UserdataTooLargeError{Size: len(encoded), Limit: limit}
+	}
+
+	// Compress the userdata, unless the renderer already does.
+	if !isCompressed(encoded) {
+		compressed, err := renderer.EncodeUserdata(utils.Gzip(udata), operatingSystem)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		logger.Debugf("compressed userdata is %d bytes", len(compressed))
+		if len(compressed) <= limit {
+			return compressed, nil
+		}
+	}
+
+	// Have the machine fetch the userdata instead.
+	store, ok := renderer.(UserdataStore)
+	if !ok {
+		return nil, &UserdataTooLargeError{Size: len(encoded), Limit: limit}
+	}
+	url, err := store.StoreUserdata(udata)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot store userdata")
+	}
+	include, err := Multipart(Part{
+		ContentType: IncludeURLPartType,
+		Content:     []byte(url + "\n"),
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	encoded, err = renderer.EncodeUserdata(include, operatingSystem)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(encoded) > limit {
+		return nil, &UserdataTooLargeError{Size: len(encoded), Limit: limit}
+	}
+	return encoded, nil
+}
+
+// gzipMagic starts gzipped data; base64Gzip starts base64-encoded
+// gzipped data.
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	base64Gzip = []byte("H4sI")
+)
+
+// isCompressed returns whether the encoded userdata is gzipped, or
+// base64-encoded gzipped data.
+func isCompressed(encoded []byte) bool {
+	return bytes.HasPrefix(encoded, gzipMagic) || bytes.HasPrefix(encoded, base64Gzip)
+}
@@ -0,0 +1,219 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package renderers_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	"github.com/juju/utils/os"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/testing"
+)
+
+type SizeSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&SizeSuite{})
+
+// limitedRenderer encodes userdata unchanged, and limits its size.
+type limitedRenderer struct {
+	plainRenderer
+	limit int
+}
+
+func (r limitedRenderer) UserdataSizeLimit(os.OSType) int {
+	return r.limit
+}
+
+// gzipRenderer compresses userdata, like most providers' renderers.
+type gzipRenderer struct {
+	limitedRenderer
+}
+
+func (gzipRenderer) EncodeUserdata(udata []byte, _ os.OSType) ([]byte, error) {
+	return utils.Gzip(udata), nil
+}
+
+// storingRenderer stores userdata that is too large.
+type storingRenderer struct {
+	gzipRenderer
+	stored *[]byte
+}
+
+func (r storingRenderer) StoreUserdata(udata []byte) (string, error) {
+	*r.stored = udata
+	return "https://storage.invalid/userdata", nil
+}
+
+// compressible returns userdata of the given size that compresses well.
+func compressible(size int) []byte {
+	return append([]byte("#cloud-config\n"), bytes.Repeat([]byte("a"), size-14)...)
+}
+
+// incompressible returns userdata of the given size that does not
+// compress.
+func incompressible(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(0)).Read(data)
+	return data
+}
+
+func (s *SizeSuite) TestNoLimit(c *gc.C) {
+	udata := compressible(32 * 1024)
+	encoded, err := renderers.FitUserdata(udata, renderers.CloudConfigFormat, plainRenderer{}, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(encoded, jc.DeepEquals, udata)
+}
+
+func (s *SizeSuite) TestWithinLimit(c *gc.C) {
+	udata := compressible(1024)
+	renderer := limitedRenderer{limit: 1024}
+	encoded, err := renderers.FitUserdata(udata, renderers.CloudConfigFormat, renderer, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(encoded, jc.DeepEquals, udata)
+}
+
+func (s *SizeSuite) TestCompressed(c *gc.C) {
+	udata := compressible(32 * 1024)
+	renderer := limitedRenderer{limit: 16 * 1024}
+	encoded, err := renderers.FitUserdata(udata, renderers.CloudConfigFormat, renderer, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(encoded, jc.DeepEquals, utils.Gzip(udata))
+}
+
+func (s *SizeSuite) TestTooLarge(c *gc.C) {
+	udata := incompressible(32 * 1024)
+	renderer := gzipRenderer{limitedRenderer{limit: 16 * 1024}}
+	_, err := renderers.FitUserdata(udata, renderers.CloudConfigFormat, renderer, os.Ubuntu)
+	c.Check(err, jc.Satisfies, renderers.IsUserdataTooLarge)
+	c.Check(err, gc.ErrorMatches, `userdata is \d+ bytes, over the cloud's limit of 16384 bytes`)
+}
+
+func (s *SizeSuite) TestScriptTooLarge(c *gc.C) {
+	udata := compressible(32 * 1024)
+	renderer := limitedRenderer{limit: 16 * 1024}
+	_, err := renderers.FitUserdata(udata, renderers.ScriptFormat, renderer, os.CentOS)
+	c.Check(err, jc.Satisfies, renderers.IsUserdataTooLarge)
+	c.Check(err, gc.ErrorMatches, `userdata is 32768 bytes, over the cloud's limit of 16384 bytes`)
+}
+
+func (s *SizeSuite) TestStored(c *gc.C) {
+	udata := incompressible(32 * 1024)
+	var stored []byte
+	renderer := storingRenderer{gzipRenderer{limitedRenderer{limit: 16 * 1024}}, &stored}
+	encoded, err := renderers.FitUserdata(udata, renderers.CloudConfigFormat, renderer, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(stored, jc.DeepEquals, udata)
+
+	include, err := utils.Gunzip(encoded)
+	c.Assert(err, jc.ErrorIsNil)
+	parts := readMultipart(c, include)
+	c.Assert(parts, gc.HasLen, 1)
+	c.Check(parts[0].ContentType, gc.Equals, `text/x-include-url; charset="us-ascii"`)
+	c.Check(string(parts[0].Content), gc.Equals, "https://storage.invalid/userdata\n")
+}
+
+func (s *SizeSuite) TestMultipart(c *gc.C) {
+	data, err := renderers.Multipart(renderers.Part{
+		ContentType: renderers.CloudConfigPartType,
+		Content:     []byte("#cloud-config\nruncmd: []\n"),
+	}, renderers.Part{
+		ContentType: renderers.ScriptPartType,
+		Content:     []byte("#!/bin/bash\necho hello\n"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	parts := readMultipart(c, data)
+	c.Assert(parts, gc.HasLen, 2)
+	c.Check(parts[0].ContentType, gc.Equals, `text/cloud-config; charset="us-ascii"`)
+	c.Check(string(parts[0].Content), gc.Equals, "#cloud-config\nruncmd: []\n")
+	c.Check(parts[1].ContentType, gc.Equals, `text/x-shellscript; charset="us-ascii"`)
+	c.Check(string(parts[1].Content), gc.Equals, "#!/bin/bash\necho hello\n")
+}
+
+func (s *SizeSuite) TestIsUserdataTooLarge(c *gc.C) {
+	err := errors.Annotate(&renderers.UserdataTooLargeError{Size: 2, Limit: 1}, "cannot start instance")
+	c.Check(err, jc.Satisfies, renderers.IsUserdataTooLarge)
+	c.Check(errors.New("other"), gc.Not(jc.Satisfies), renderers.IsUserdataTooLarge)
+}
+
+func (s *SizeSuite) TestStorageUserdataStore(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	store := renderers.StorageUserdataStore{Storage: stor, Name: "userdata/machine-0"}
+	url, err := store.StoreUserdata([]byte("#cloud-config\n"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected, err := stor.URL("userdata/machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(url, gc.Equals, expected)
+	r, err := stor.Get("userdata/machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "#cloud-config\n")
+}
+
+func (s *SizeSuite) TestStorageUserdataStoreURL(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	store := renderers.StorageUserdataStore{
+		Storage: stor,
+		Name:    "userdata/machine-0",
+		URL: func(name string) (string, error) {
+			return "https://example.com/" + name + "?expires=1", nil
+		},
+	}
+	url, err := store.StoreUserdata([]byte("#cloud-config\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(url, gc.Equals, "https://example.com/userdata/machine-0?expires=1")
+}
+
+func (s *SizeSuite) TestStorageUserdataStoreRemove(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	store := renderers.StorageUserdataStore{Storage: stor, Name: "userdata/machine-0"}
+	_, err = store.StoreUserdata([]byte("#cloud-config\n"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = store.RemoveUserdata()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = stor.Get("userdata/machine-0")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func readMultipart(c *gc.C, data []byte) []renderers.Part {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mediaType, gc.Equals, "multipart/mixed")
+	var parts []renderers.Part
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		content, err := ioutil.ReadAll(p)
+		c.Assert(err, jc.ErrorIsNil)
+		parts = append(parts, renderers.Part{
+			ContentType: p.Header.Get("Content-Type"),
+			Content:     content,
+		})
+	}
+	return parts
+}
@@ -0,0 +1,194 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package renderers
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/yaml.v2"
+)
+
+// cloudConfigHeader starts every cloud-config document.
+const cloudConfigHeader = "#cloud-config\n"
+
+// cloudConfigKeys holds the cloud-config keys that juju generates.
+var cloudConfigKeys = set.NewStrings(
+	"apt_mirror",
+	"apt_preferences",
+	"apt_proxy",
+	"apt_sources",
+	"bootcmd",
+	"disable_root",
+	"final_message",
+	"hostname",
+	"locale",
+	"mounts",
+	"output",
+	"package_mirror",
+	"package_proxy",
+	"package_sources",
+	"package_update",
+	"package_upgrade",
+	"packages",
+	"runcmd",
+	"ssh_authorized_keys",
+	"ssh_keys",
+	"user",
+)
+
+// ValidateUserdata checks rendered userdata before it is given to a
+// machine, so that mistakes are reported when the machine is started
+// rather than leaving a machine that never comes up. Cloud-config must
+// be valid YAML using only known keys, with its commands and packages
+// given as lists of strings; scripts and powershell are not checked.
+func ValidateUserdata(udata []byte, format Format) error {
+	switch format {
+	case CloudConfigFormat:
+		_, err := parseCloudConfig(udata)
+		return errors.Trace(err)
+	case ScriptFormat, PowershellFormat:
+		return nil
+	}
+	return errors.NotValidf("userdata format %q", format)
+}
+
+// CheckUserdataSyntax checks the userdata as ValidateUserdata does, and
+// also checks the syntax of its shell commands and scripts where a
+// shell is available. It starts a shell for every check, so it is
+// meant for tests of the rendered userdata rather than for every
+// machine that is started.
+func CheckUserdataSyntax(udata []byte, format Format) error {
+	switch format {
+	case CloudConfigFormat:
+		attrs, err := parseCloudConfig(udata)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, key := range []string{"bootcmd", "runcmd"} {
+			if err := checkCommandsSyntax(key, attrs[key]); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	case ScriptFormat:
+		return errors.Annotate(checkShellSyntax("bash", string(udata)), "script")
+	}
+	return errors.Trace(ValidateUserdata(udata, format))
+}
+
+// parseCloudConfig parses and validates a cloud-config document.
+func parseCloudConfig(udata []byte) (map[string]interface{}, error) {
+	if !bytes.HasPrefix(udata, []byte(cloudConfigHeader)) {
+		return nil, errors.Errorf("cloud-config does not start with %q", strings.TrimSpace(cloudConfigHeader))
+	}
+	var attrs map[string]interface{}
+	if err := yaml.Unmarshal(udata, &attrs); err != nil {
+		return nil, errors.Annotate(err, "cloud-config is not valid YAML")
+	}
+	for key := range attrs {
+		if !cloudConfigKeys.Contains(key) {
+			return nil, errors.Errorf("unknown cloud-config key %q", key)
+		}
+	}
+	for _, key := range []string{"bootcmd", "runcmd"} {
+		if err := validateCommands(key, attrs[key]); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if packages, ok := attrs["packages"]; ok {
+		list, ok := packages.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("packages: expected list, got %T", packages)
+		}
+		for i, pkg := range list {
+			if _, ok := pkg.(string); !ok {
+				return nil, errors.Errorf("packages[%d]: expected string, got %T", i, pkg)
+			}
+		}
+	}
+	return attrs, nil
+}
+
+// validateCommands checks the commands held in a bootcmd or runcmd
+// list, each of which is either a shell command or a list of
+// arguments.
+func validateCommands(key string, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return errors.Errorf("%s: expected list, got %T", key, value)
+	}
+	for i, cmd := range list {
+		switch cmd := cmd.(type) {
+		case string:
+		case []interface{}:
+			for j, arg := range cmd {
+				if _, ok := arg.(string); !ok {
+					return errors.Errorf("%s[%d][%d]: expected string, got %T", key, i, j, arg)
+				}
+			}
+		default:
+			return errors.Errorf("%s[%d]: expected string or list, got %T", key, i, cmd)
+		}
+	}
+	return nil
+}
+
+// checkCommandsSyntax checks the syntax of the shell commands in a
+// bootcmd or runcmd list that has already been validated.
+func checkCommandsSyntax(key string, value interface{}) error {
+	list, _ := value.([]interface{})
+	var commands []string
+	for _, cmd := range list {
+		if cmd, ok := cmd.(string); ok {
+			commands = append(commands, cmd)
+		}
+	}
+	// cloud-init runs the commands as a single script, so check
+	// them together, and only look for the culprit if that fails.
+	err := checkShellSyntax("sh", strings.Join(commands, "\n"))
+	if err == nil {
+		return nil
+	}
+	for i, cmd := range list {
+		cmd, ok := cmd.(string)
+		if !ok {
+			continue
+		}
+		if err := checkShellSyntax("sh", cmd); err != nil {
+			return errors.Annotatef(err, "%s[%d]", key, i)
+		}
+	}
+	return errors.Annotate(err, key)
+}
+
+// lookPath is patched by tests.
+var lookPath = exec.LookPath
+
+// checkShellSyntax checks the script's syntax with the shell, if the
+// shell is available.
+func checkShellSyntax(shell, script string) error {
+	path, err := lookPath(shell)
+	if err != nil {
+		logger.Debugf("not checking %s syntax: %v", shell, err)
+		return nil
+	}
+	cmd := exec.Command(path, "-n")
+	cmd.Stdin = strings.NewReader(script)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return errors.Errorf("invalid %s syntax: %s", shell, msg)
+	}
+	return nil
+}
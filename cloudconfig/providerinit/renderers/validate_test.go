@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package renderers_test
+
+import (
+	"os/exec"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/os"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
+	"github.com/juju/juju/testing"
+)
+
+type ValidateSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ValidateSuite{})
+
+func requireShell(c *gc.C, shell string) {
+	if _, err := exec.LookPath(shell); err != nil {
+		c.Skip(shell + " not available")
+	}
+}
+
+func (s *ValidateSuite) TestValidCloudConfig(c *gc.C) {
+	cfg := newCloudConfig(c, "trusty")
+	cfg.AddBootCmd("mkdir -p /var/lib/juju")
+	cfg.AddRunCmd("if [ -e /etc/juju ]; then echo found; fi")
+	cfg.AddPackage("curl")
+	udata, err := cfg.RenderYAML()
+	c.Assert(err, jc.ErrorIsNil)
+	err = renderers.ValidateUserdata(udata, renderers.CloudConfigFormat)
+	c.Assert(err, jc.ErrorIsNil)
+	requireShell(c, "sh")
+	err = renderers.CheckUserdataSyntax(udata, renderers.CloudConfigFormat)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ValidateSuite) TestInvalidCloudConfig(c *gc.C) {
+	for i, test := range []struct {
+		udata string
+		err   string
+	}{{
+		udata: "runcmd: [true]\n",
+		err:   `cloud-config does not start with "#cloud-config"`,
+	}, {
+		udata: "#cloud-config\nruncmd: [\n",
+		err:   "cloud-config is not valid YAML: .*",
+	}, {
+		udata: "#cloud-config\nrun_cmd: [true]\n",
+		err:   `unknown cloud-config key "run_cmd"`,
+	}, {
+		udata: "#cloud-config\nruncmd: true\n",
+		err:   "runcmd: expected list, got bool",
+	}, {
+		udata: "#cloud-config\nruncmd:\n- {echo: hello}\n",
+		err:   `runcmd\[0\]: expected string or list, got .*`,
+	}, {
+		udata: "#cloud-config\nbootcmd:\n- [echo, 1]\n",
+		err:   `bootcmd\[0\]\[1\]: expected string, got int`,
+	}, {
+		udata: "#cloud-config\npackages: curl\n",
+		err:   "packages: expected list, got string",
+	}} {
+		c.Logf("test %d", i)
+		err := renderers.ValidateUserdata([]byte(test.udata), renderers.CloudConfigFormat)
+		c.Check(err, gc.ErrorMatches, test.err)
+		err = renderers.CheckUserdataSyntax([]byte(test.udata), renderers.CloudConfigFormat)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ValidateSuite) TestValidateDoesNotRunShell(c *gc.C) {
+	s.PatchValue(renderers.LookPath, func(file string) (string, error) {
+		c.Errorf("unexpected shell %q", file)
+		return "", errors.NotFoundf(file)
+	})
+	err := renderers.ValidateUserdata([]byte("#cloud-config\nruncmd:\n- if then\n"), renderers.CloudConfigFormat)
+	c.Assert(err, jc.ErrorIsNil)
+	err = renderers.ValidateUserdata([]byte("for x in\n"), renderers.ScriptFormat)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ValidateSuite) TestCheckCommandsSyntax(c *gc.C) {
+	requireShell(c, "sh")
+	for i, test := range []struct {
+		udata string
+		err   string
+	}{{
+		udata: "#cloud-config\nruncmd:\n- echo hello\n- if then\n",
+		err:   `runcmd\[1\]: invalid sh syntax: .*`,
+	}, {
+		udata: "#cloud-config\nruncmd:\n- if true; then\n- echo hello\n",
+		err:   `runcmd: invalid sh syntax: .*`,
+	}} {
+		c.Logf("test %d", i)
+		err := renderers.CheckUserdataSyntax([]byte(test.udata), renderers.CloudConfigFormat)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ValidateSuite) TestSplitCommandsValid(c *gc.C) {
+	requireShell(c, "sh")
+	udata := "#cloud-config\nruncmd:\n- if true; then\n- echo hello\n- fi\n"
+	err := renderers.CheckUserdataSyntax([]byte(udata), renderers.CloudConfigFormat)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ValidateSuite) TestScript(c *gc.C) {
+	requireShell(c, "bash")
+	err := renderers.CheckUserdataSyntax([]byte("#!/bin/bash\necho hello\n"), renderers.ScriptFormat)
+	c.Assert(err, jc.ErrorIsNil)
+	err = renderers.CheckUserdataSyntax([]byte("#!/bin/bash\nfor x in\n"), renderers.ScriptFormat)
+	c.Assert(err, gc.ErrorMatches, "script: invalid bash syntax: .*")
+}
+
+func (s *ValidateSuite) TestShellUnavailable(c *gc.C) {
+	s.PatchValue(renderers.LookPath, func(file string) (string, error) {
+		return "", errors.NotFoundf(file)
+	})
+	err := renderers.CheckUserdataSyntax([]byte("#cloud-config\nruncmd:\n- if then\n"), renderers.CloudConfigFormat)
+	c.Assert(err, jc.ErrorIsNil)
+	err = renderers.CheckUserdataSyntax([]byte("for x in\n"), renderers.ScriptFormat)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ValidateSuite) TestPowershell(c *gc.C) {
+	err := renderers.ValidateUserdata([]byte("#ps1_sysnative\n"), renderers.PowershellFormat)
+	c.Assert(err, jc.ErrorIsNil)
+	err = renderers.CheckUserdataSyntax([]byte("#ps1_sysnative\n"), renderers.PowershellFormat)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ValidateSuite) TestInvalidFormat(c *gc.C) {
+	err := renderers.ValidateUserdata(nil, "ignition")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *ValidateSuite) TestRenderUserdataDoesNotRunShell(c *gc.C) {
+	s.PatchValue(renderers.LookPath, func(file string) (string, error) {
+		c.Errorf("unexpected shell %q", file)
+		return "", errors.NotFoundf(file)
+	})
+	cfg := newCloudConfig(c, "trusty")
+	cfg.AddRunCmd("echo hello")
+	_, err := renderers.RenderUserdata(cfg, plainRenderer{}, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = renderers.RenderUserdata(newCloudConfig(c, "centos7"), scriptRenderer{}, os.CentOS)
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -6,6 +6,8 @@ package cloudconfig
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -117,6 +119,15 @@ func (c *baseConfigure) addMachineAgentToBoot() error {
 	if err != nil {
 		return errors.Annotatef(err, "cannot make cloud-init init script for the %s agent", name)
 	}
+	if c.os == os.CentOS {
+		// The agent's binaries and init scripts live in the data
+		// directory, which SELinux does not allow systemd to
+		// execute from.
+		cmds = append(cmds, selinuxRelabelCommand(
+			path.Join(c.icfg.DataDir, "tools"),
+			path.Join(c.icfg.DataDir, "init"),
+		))
+	}
 	cmds = append(cmds, startCmds...)
 
 	svcName := c.icfg.MachineAgentServiceName
@@ -155,6 +166,19 @@ func (c *baseConfigure) toolsSymlinkCommand(toolsDir string) string {
 	}
 }
 
+// selinuxRelabelCommand returns a command that, if SELinux is enabled,
+// labels everything in the directories as executable by services.
+func selinuxRelabelCommand(dirs ...string) string {
+	quoted := make([]string, len(dirs))
+	for i, dir := range dirs {
+		quoted[i] = shquote(dir)
+	}
+	return fmt.Sprintf(
+		"if selinuxenabled &> /dev/null; then chcon -R -t bin_t %s; fi",
+		strings.Join(quoted, " "),
+	)
+}
+
 func shquote(p string) string {
 	return utils.ShQuote(p)
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/packaging"
 	pacconf "github.com/juju/utils/packaging/config"
 	"github.com/juju/utils/set"
 	gc "gopkg.in/check.v1"
@@ -389,6 +390,8 @@ printf '%s\\n' 'FAKE_NONCE' > '/var/lib/juju/nonce.txt'
 systemctl is-enabled firewalld &> /dev/null && systemctl mask firewalld || true
 systemctl is-active firewalld &> /dev/null && systemctl stop firewalld || true
 sed -i "s/\^\.\*requiretty/#Defaults requiretty/" /etc/sudoers
+if selinuxenabled &> /dev/null; then chcon -R -t bin_t '/var/lib/juju/tools' '/var/lib/juju/init'; fi
+/bin/systemctl start jujud-machine-0\.service
 `,
 	},
 
@@ -578,6 +581,32 @@ func (*cloudinitSuite) TestCloudInitConfigure(c *gc.C) {
 	}
 }
 
+func (*cloudinitSuite) TestCloudInitConfigurePackageSources(c *gc.C) {
+	for _, series := range []string{"trusty", "centos7"} {
+		c.Logf("series %s", series)
+		instConfig := makeNormalConfig(series).mutate(func(cfg *testInstanceConfig) {
+			cfg.PackageSources = []string{"ppa:juju/stable", "http://mirror.example.com/juju"}
+			cfg.PackageSourceKeys = "some-key"
+		}).maybeSetEnvironConfig(minimalEnvironConfig(c))
+		rendered := instConfig.render()
+		cloudcfg, err := cloudinit.New(rendered.Series)
+		c.Assert(err, jc.ErrorIsNil)
+		udata, err := cloudconfig.NewUserdataConfig(&rendered, cloudcfg)
+		c.Assert(err, jc.ErrorIsNil)
+		err = udata.Configure()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(cloudcfg.PackageSources(), jc.DeepEquals, []packaging.PackageSource{{
+			Name: "juju-package-source-0",
+			URL:  "ppa:juju/stable",
+			Key:  "some-key",
+		}, {
+			Name: "juju-package-source-1",
+			URL:  "http://mirror.example.com/juju",
+			Key:  "some-key",
+		}})
+	}
+}
+
 func (*cloudinitSuite) TestCloudInitConfigureBootstrapLogging(c *gc.C) {
 	loggo.GetLogger("").SetLogLevel(loggo.INFO)
 	envConfig := minimalEnvironConfig(c)
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/names"
 	"github.com/juju/utils"
 	"github.com/juju/utils/os"
+	"github.com/juju/utils/packaging"
 	"github.com/juju/utils/proxy"
 	"github.com/juju/utils/series"
 	goyaml "gopkg.in/yaml.v2"
@@ -186,6 +187,7 @@ func (w *unixConfigure) ConfigureJuju() error {
 		w.icfg.EnableOSRefreshUpdate,
 		w.icfg.EnableOSUpgrade,
 	)
+	w.addPackageSources()
 
 	// Write out the normal proxy settings so that the settings are
 	// sourced by bash, and ssh through that.
@@ -347,6 +349,18 @@ func (w *unixConfigure) ConfigureJuju() error {
 	return w.addMachineAgentToBoot()
 }
 
+// addPackageSources adds the additional package sources configured for
+// the environment, each trusting the configured keys.
+func (w *unixConfigure) addPackageSources() {
+	for i, source := range w.icfg.PackageSources {
+		w.conf.AddPackageSource(packaging.PackageSource{
+			Name: fmt.Sprintf("juju-package-source-%d", i),
+			URL:  source,
+			Key:  w.icfg.PackageSourceKeys,
+		})
+	}
+}
+
 // toolsDownloadCommand takes a curl command minus the source URL,
 // and generates a command that will cycle through the URLs until
 // one succeeds.
@@ -5,7 +5,6 @@ package envcmd
 
 import (
 	"net/http"
-	"os"
 	"path"
 
 	"github.com/juju/cmd"
@@ -18,6 +17,7 @@ import (
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/juju"
+	"github.com/juju/juju/juju/osenv"
 )
 
 var errNoNameSpecified = errors.New("no name specified")
@@ -122,7 +122,7 @@ func (w *baseCommandWrapper) Init(args []string) error {
 // macaroons. The returned value can be overridden by setting the
 // JUJU_COOKIEFILE environment variable.
 func cookieFile() string {
-	if file := os.Getenv("JUJU_COOKIEFILE"); file != "" {
+	if file := osenv.JujuCookieFileVar.Value(); file != "" {
 		return file
 	}
 	return path.Join(utils.Home(), ".go-cookies")
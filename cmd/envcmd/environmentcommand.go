@@ -5,8 +5,6 @@ package envcmd
 
 import (
 	"io"
-	"os"
-	"strconv"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -37,7 +35,7 @@ var ErrNoEnvironmentSpecified = errors.New("no environment specified")
 // If no default is specified in the environments file, an empty string is returned.
 // Not having a default environment specified is not an error.
 func GetDefaultEnvironment() (string, error) {
-	if defaultEnv := os.Getenv(osenv.JujuEnvEnvKey); defaultEnv != "" {
+	if defaultEnv := osenv.JujuEnvVar.Value(); defaultEnv != "" {
 		return defaultEnv, nil
 	}
 	if currentEnv, err := ReadCurrentEnvironment(); err != nil {
@@ -271,15 +269,10 @@ func (c *EnvCommandBase) CompatVersion() int {
 	if c.compatVerson != nil {
 		return *c.compatVerson
 	}
-	compatVerson := 1
-	val := os.Getenv(osenv.JujuCLIVersion)
-	if val != "" {
-		vers, err := strconv.Atoi(val)
-		if err != nil {
-			logger.Warningf("invalid %s value: %v", osenv.JujuCLIVersion, val)
-		} else {
-			compatVerson = vers
-		}
+	compatVerson, err := osenv.JujuCLIVersionVar.Int()
+	if err != nil {
+		logger.Warningf("invalid %s value: %v", osenv.JujuCLIVersion, osenv.JujuCLIVersionVar.Value())
+		compatVerson = 1
 	}
 	c.compatVerson = &compatVerson
 	return *c.compatVerson
@@ -32,6 +32,7 @@ func NewSuperCommand() cmd.Command {
 			UsagePrefix: "juju",
 			Purpose:     actionPurpose,
 		})
+	actionCmd.Register(newCancelCommand())
 	actionCmd.Register(newDefinedCommand())
 	actionCmd.Register(newDoCommand())
 	actionCmd.Register(newFetchCommand())
@@ -64,8 +65,14 @@ type APIClient interface {
 	// Entities.
 	ListCompleted(params.Entities) (params.ActionsByReceivers, error)
 
-	// Cancel attempts to cancel a queued up Action from running.
-	Cancel(params.Actions) (params.ActionResults, error)
+	// ListQueued takes a list of Tags representing ActionReceivers
+	// and returns all of the Actions that are waiting for a free slot
+	// to be dispatched to each of those Entities.
+	ListQueued(params.Entities) (params.ActionsByReceivers, error)
+
+	// Cancel takes a list of Tags representing Actions and cancels
+	// them, stopping any that are running.
+	Cancel(params.Entities) (params.ActionResults, error)
 
 	// ServiceCharmActions is a single query which uses ServicesCharmActions to
 	// get the charm.Actions for a single Service by tag.
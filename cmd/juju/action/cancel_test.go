@@ -0,0 +1,111 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/action"
+	"github.com/juju/juju/testing"
+)
+
+type CancelSuite struct {
+	BaseActionSuite
+	subcommand cmd.Command
+}
+
+var _ = gc.Suite(&CancelSuite{})
+
+func (s *CancelSuite) SetUpTest(c *gc.C) {
+	s.BaseActionSuite.SetUpTest(c)
+	s.subcommand = action.NewCancelCommand()
+}
+
+func (s *CancelSuite) TestHelp(c *gc.C) {
+	s.checkHelp(c, s.subcommand)
+}
+
+func (s *CancelSuite) TestRun(c *gc.C) {
+	prefix := "deadbeef"
+	fakeid := prefix + "-0000-4000-8000-feedfacebeef"
+	fakeid2 := prefix + "-0001-4000-8000-feedfacebeef"
+	faketag := "action-" + fakeid
+	faketag2 := "action-" + fakeid2
+
+	result := []params.ActionResult{{Status: params.ActionAborting}}
+
+	tests := []cancelTestCase{{
+		expectError: "no action ID specified",
+	}, {
+		args:        []string{prefix},
+		expectError: `actions for identifier "` + prefix + `" not found`,
+	}, {
+		args:        []string{prefix},
+		tags:        tagsForIdPrefix(prefix, faketag, faketag2),
+		expectError: `identifier "` + prefix + `" matched multiple actions .*`,
+	}, {
+		args:        []string{prefix},
+		tags:        tagsForIdPrefix(prefix, faketag),
+		expectError: `identifiers \[` + prefix + `\] matched actions, but found no results`,
+	}, {
+		args:    []string{prefix},
+		tags:    tagsForIdPrefix(prefix, faketag),
+		results: result,
+		expect:  []string{faketag},
+	}}
+
+	for i, test := range tests {
+		c.Logf("iteration %d, test case %+v", i, test)
+		s.runTestCase(c, test)
+	}
+}
+
+func (s *CancelSuite) runTestCase(c *gc.C, tc cancelTestCase) {
+	fakeClient := makeFakeClient(
+		0*time.Second, // No API delay
+		5*time.Second, // 5 second test timeout
+		tc.tags,
+		tc.results,
+		"", // No API error
+	)
+
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	s.subcommand = action.NewCancelCommand()
+	args := append([]string{"-e", "dummyenv"}, tc.args...)
+	ctx, err := testing.RunCommand(c, s.subcommand, args...)
+	if tc.expectError == "" {
+		c.Assert(err, jc.ErrorIsNil)
+	} else {
+		c.Assert(err, gc.ErrorMatches, tc.expectError)
+	}
+	if len(tc.expect) > 0 {
+		entities := make([]params.Entity, len(tc.expect))
+		for i, tag := range tc.expect {
+			entities[i].Tag = tag
+		}
+		c.Check(fakeClient.cancelledActions, jc.DeepEquals, params.Entities{Entities: entities})
+	}
+	if len(tc.results) > 0 {
+		buf, err := cmd.DefaultFormatters["yaml"](action.ActionResultsToMap(tc.results))
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(ctx.Stdout.(*bytes.Buffer).String(), gc.Equals, string(buf)+"\n")
+		c.Check(ctx.Stderr.(*bytes.Buffer).String(), gc.Equals, "")
+	}
+}
+
+type cancelTestCase struct {
+	args        []string
+	expectError string
+	tags        params.FindTagsResults
+	results     []params.ActionResult
+	expect      []string
+}
@@ -14,6 +14,7 @@ import (
 var (
 	NewActionAPIClient = &newAPIClient
 	AddValueToMap      = addValueToMap
+	NewCancelCommand   = newCancelCommand
 	NewFetchCommand    = newFetchCommand
 	NewStatusCommand   = newStatusCommand
 )
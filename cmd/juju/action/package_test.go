@@ -132,6 +132,7 @@ type fakeAPIClient struct {
 	timeout            *time.Timer
 	actionResults      []params.ActionResult
 	enqueuedActions    params.Actions
+	cancelledActions   params.Entities
 	actionsByReceivers []params.ActionsByReceiver
 	actionTagMatches   params.FindTagsResults
 	charmActions       *charm.Actions
@@ -173,7 +174,14 @@ func (c *fakeAPIClient) ListCompleted(args params.Entities) (params.ActionsByRec
 	}, c.apiErr
 }
 
-func (c *fakeAPIClient) Cancel(args params.Actions) (params.ActionResults, error) {
+func (c *fakeAPIClient) ListQueued(args params.Entities) (params.ActionsByReceivers, error) {
+	return params.ActionsByReceivers{
+		Actions: c.actionsByReceivers,
+	}, c.apiErr
+}
+
+func (c *fakeAPIClient) Cancel(args params.Entities) (params.ActionResults, error) {
+	c.cancelledActions = args
 	return params.ActionResults{
 		Results: c.actionResults,
 	}, c.apiErr
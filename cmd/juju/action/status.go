@@ -122,5 +122,8 @@ func resultToMap(result params.ActionResult) map[string]interface{} {
 
 	}
 	item["status"] = result.Status
+	if result.Queued {
+		item["queued"] = true
+	}
 	return item
 }
@@ -0,0 +1,186 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/auditlog"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/common"
+)
+
+func newAuditLogCommand() cmd.Command {
+	return envcmd.Wrap(&auditLogCommand{})
+}
+
+type auditLogCommand struct {
+	envcmd.EnvCommandBase
+	out     cmd.Output
+	filter  params.AuditLogFilter
+	since   time.Duration
+	isoTime bool
+}
+
+const auditLogDoc = `
+Show the record the API servers keep of the calls made to them, newest
+first. Each entry shows who made the call, the method called, the
+entities named in its parameters, and whether it succeeded. Sensitive
+parameters, such as passwords, are never recorded.
+
+Only system administrators may read the audit log.
+
+Examples:
+
+  # Show the last 20 calls made in the environment.
+  juju audit-log -n 20
+
+  # Show the calls made by machine 0 in the last hour.
+  juju audit-log --caller machine-0 --since 1h
+
+  # Show the calls to Client.DestroyMachines in all environments.
+  juju audit-log --method Client.DestroyMachines --all-environments
+`
+
+func (c *auditLogCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "audit-log",
+		Purpose: "show the calls made to the API servers",
+		Doc:     auditLogDoc,
+	}
+}
+
+func (c *auditLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.filter.Caller, "caller", "", "only show calls made by the entity with this tag")
+	f.StringVar(&c.filter.Method, "method", "", "only show calls to this method, as Facade or Facade.Method")
+	f.DurationVar(&c.since, "since", 0, "only show calls made within this duration")
+	f.IntVar(&c.filter.Limit, "n", 0, "show at most this many calls")
+	f.IntVar(&c.filter.Limit, "limit", 0, "")
+	f.BoolVar(&c.filter.AllEnvironments, "all-environments", false, "show calls made in all environments")
+	f.BoolVar(&c.isoTime, "utc", false, "display time as UTC in RFC3339 format")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatAuditLogTabular,
+	})
+}
+
+func (c *auditLogCommand) Init(args []string) error {
+	if c.filter.Caller != "" {
+		if _, err := names.ParseTag(c.filter.Caller); err != nil {
+			return errors.Errorf("invalid caller %q: expected an entity tag", c.filter.Caller)
+		}
+	}
+	if c.filter.Method != "" {
+		parts := strings.SplitN(c.filter.Method, ".", 2)
+		c.filter.Facade = parts[0]
+		if len(parts) == 2 {
+			c.filter.Method = parts[1]
+		} else {
+			c.filter.Method = ""
+		}
+		if c.filter.Facade == "" {
+			return errors.Errorf("invalid method: facade name is missing")
+		}
+	}
+	if c.since < 0 {
+		return errors.Errorf("invalid since %v: must not be negative", c.since)
+	}
+	if c.filter.Limit < 0 {
+		return errors.Errorf("invalid limit %d: must not be negative", c.filter.Limit)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// AuditLogAPI defines the API methods used by the audit-log command.
+type AuditLogAPI interface {
+	Entries(filter params.AuditLogFilter) ([]params.AuditLogEntry, error)
+	Close() error
+}
+
+var getAuditLogAPI = func(c *auditLogCommand) (AuditLogAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, err
+	}
+	return auditlog.NewClient(root), nil
+}
+
+// AuditLogEntry defines the serialization behaviour of an audit log
+// entry.
+type AuditLogEntry struct {
+	Time        string   `yaml:"time" json:"time"`
+	Environment string   `yaml:"environment" json:"environment"`
+	Caller      string   `yaml:"caller" json:"caller"`
+	Method      string   `yaml:"method" json:"method"`
+	Entities    []string `yaml:"entities,omitempty" json:"entities,omitempty"`
+	Params      string   `yaml:"params,omitempty" json:"params,omitempty"`
+	Result      string   `yaml:"result" json:"result"`
+}
+
+// Run shows the audit log entries selected by the command's flags.
+func (c *auditLogCommand) Run(ctx *cmd.Context) error {
+	client, err := getAuditLogAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	filter := c.filter
+	if c.since > 0 {
+		since := time.Now().Add(-c.since)
+		filter.Since = &since
+	}
+	entries, err := client.Entries(filter)
+	if err != nil {
+		return err
+	}
+	output := make([]AuditLogEntry, len(entries))
+	for i, entry := range entries {
+		output[i] = AuditLogEntry{
+			Time:        common.FormatTime(&entry.Time, c.isoTime),
+			Environment: entry.EnvUUID,
+			Caller:      entry.Caller,
+			Method:      fmt.Sprintf("%s(%d).%s", entry.Facade, entry.Version, entry.Method),
+			Entities:    entry.EntityTags,
+			Params:      entry.Params,
+			Result:      entry.ResultCode,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+func formatAuditLogTabular(value interface{}) ([]byte, error) {
+	entries, ok := value.([]AuditLogEntry)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", entries, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "TIME\tCALLER\tMETHOD\tENTITIES\tRESULT\n")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			entry.Time, entry.Caller, entry.Method, strings.Join(entry.Entities, ","), entry.Result)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/testing"
+)
+
+type AuditLogSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeAuditLogAPI
+}
+
+var _ = gc.Suite(&AuditLogSuite{})
+
+func (s *AuditLogSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeAuditLogAPI{}
+	s.PatchValue(&getAuditLogAPI, func(_ *auditLogCommand) (AuditLogAPI, error) {
+		return s.fake, nil
+	})
+}
+
+func (s *AuditLogSuite) TestArgParsing(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		expected params.AuditLogFilter
+		errMatch string
+	}{{}, {
+		args:     []string{"--caller", "machine-0", "-n", "5"},
+		expected: params.AuditLogFilter{Caller: "machine-0", Limit: 5},
+	}, {
+		args:     []string{"--method", "Client"},
+		expected: params.AuditLogFilter{Facade: "Client"},
+	}, {
+		args:     []string{"--method", "Client.FullStatus", "--all-environments"},
+		expected: params.AuditLogFilter{Facade: "Client", Method: "FullStatus", AllEnvironments: true},
+	}, {
+		args:     []string{"--caller", "admin"},
+		errMatch: `invalid caller "admin": expected an entity tag`,
+	}, {
+		args:     []string{"--method", ".FullStatus"},
+		errMatch: `invalid method: facade name is missing`,
+	}, {
+		args:     []string{"--since", "-1h"},
+		errMatch: `invalid since -1h0m0s: must not be negative`,
+	}, {
+		args:     []string{"unexpected"},
+		errMatch: `unrecognized args: \["unexpected"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := &auditLogCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(command.filter, jc.DeepEquals, test.expected)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *AuditLogSuite) TestSince(c *gc.C) {
+	before := time.Now()
+	_, err := testing.RunCommand(c, newAuditLogCommand(), "--since", "1h")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.filter.Since, gc.NotNil)
+	since := *s.fake.filter.Since
+	c.Assert(since.Before(before.Add(-time.Hour)), jc.IsFalse)
+	c.Assert(since.After(time.Now().Add(-time.Hour)), jc.IsFalse)
+	c.Assert(s.fake.closed, jc.IsTrue)
+}
+
+func (s *AuditLogSuite) TestOutput(c *gc.C) {
+	s.fake.entries = []params.AuditLogEntry{{
+		Time:       time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC),
+		EnvUUID:    "uuid",
+		Caller:     "machine-0",
+		Facade:     "Machiner",
+		Version:    1,
+		Method:     "SetStatus",
+		EntityTags: []string{"machine-0", "machine-1"},
+		Params:     `{"Entities":[]}`,
+		ResultCode: "ok",
+	}, {
+		Time:       time.Date(2015, 9, 1, 11, 0, 0, 0, time.UTC),
+		EnvUUID:    "uuid",
+		Caller:     "user-bob",
+		Facade:     "Client",
+		Method:     "DestroyMachines",
+		ResultCode: "unauthorized access",
+	}}
+	ctx, err := testing.RunCommand(c, newAuditLogCommand(), "--utc")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"TIME                  CALLER     METHOD                     ENTITIES             RESULT\n"+
+		"2015-09-01 12:00:00Z  machine-0  Machiner(1).SetStatus      machine-0,machine-1  ok\n"+
+		"2015-09-01 11:00:00Z  user-bob   Client(0).DestroyMachines                       unauthorized access\n")
+
+	ctx, err = testing.RunCommand(c, newAuditLogCommand(), "--utc", "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "["+
+		`{"time":"2015-09-01 12:00:00Z","environment":"uuid","caller":"machine-0",`+
+		`"method":"Machiner(1).SetStatus","entities":["machine-0","machine-1"],`+
+		`"params":"{\"Entities\":[]}","result":"ok"},`+
+		`{"time":"2015-09-01 11:00:00Z","environment":"uuid","caller":"user-bob",`+
+		`"method":"Client(0).DestroyMachines","result":"unauthorized access"}`+
+		"]\n")
+}
+
+type fakeAuditLogAPI struct {
+	filter  params.AuditLogFilter
+	entries []params.AuditLogEntry
+	closed  bool
+}
+
+func (f *fakeAuditLogAPI) Entries(filter params.AuditLogFilter) ([]params.AuditLogEntry, error) {
+	f.filter = filter
+	return f.entries, nil
+}
+
+func (f *fakeAuditLogAPI) Close() error {
+	f.closed = true
+	return nil
+}
@@ -4,10 +4,11 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
-	"strings"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
@@ -29,6 +30,13 @@ type listKeysCommand struct {
 	AuthorizedKeysBase
 	showFullKey bool
 	user        string
+	out         cmd.Output
+}
+
+// authorizedKeys holds the keys listed for a user.
+type authorizedKeys struct {
+	User string   `yaml:"user" json:"user"`
+	Keys []string `yaml:"keys" json:"keys"`
 }
 
 func (c *listKeysCommand) Info() *cmd.Info {
@@ -42,6 +50,11 @@ func (c *listKeysCommand) Info() *cmd.Info {
 func (c *listKeysCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.showFullKey, "full", false, "show full key instead of just the key fingerprint")
 	f.StringVar(&c.user, "user", "admin", "the user for which to list the keys")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatKeysTabular,
+	})
 }
 
 func (c *listKeysCommand) Run(context *cmd.Context) error {
@@ -63,7 +76,22 @@ func (c *listKeysCommand) Run(context *cmd.Context) error {
 	if result.Error != nil {
 		return result.Error
 	}
-	fmt.Fprintf(context.Stdout, "Keys for user %s:\n", c.user)
-	fmt.Fprintln(context.Stdout, strings.Join(result.Result, "\n"))
-	return nil
+	return c.out.Write(context, authorizedKeys{
+		User: c.user,
+		Keys: result.Result,
+	})
+}
+
+// formatKeysTabular takes an interface{} to adhere to the cmd.Formatter interface
+func formatKeysTabular(value interface{}) ([]byte, error) {
+	keys, ok := value.(authorizedKeys)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", keys, value)
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "Keys for user %s:\n", keys.User)
+	for _, key := range keys.Keys {
+		fmt.Fprintln(&out, key)
+	}
+	return out.Bytes(), nil
 }
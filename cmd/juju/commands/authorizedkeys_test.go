@@ -157,6 +157,26 @@ func (s *ListKeysSuite) TestListKeysNonDefaultUser(c *gc.C) {
 	c.Assert(output, gc.Matches, "Keys for user fred:\n.*\\(user@host\\)\n.*\\(another@host\\)")
 }
 
+func (s *ListKeysSuite) TestListKeysYAML(c *gc.C) {
+	key1 := sshtesting.ValidKeyOne.Key + " user@host"
+	s.setAuthorizedKeys(c, key1)
+
+	context, err := coretesting.RunCommand(c, newListKeysCommand(), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	fingerprint := sshtesting.ValidKeyOne.Fingerprint + " (user@host)"
+	c.Assert(coretesting.Stdout(context), gc.Equals, "user: admin\nkeys:\n- "+fingerprint+"\n")
+}
+
+func (s *ListKeysSuite) TestListKeysJSON(c *gc.C) {
+	key1 := sshtesting.ValidKeyOne.Key + " user@host"
+	s.setAuthorizedKeys(c, key1)
+
+	context, err := coretesting.RunCommand(c, newListKeysCommand(), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	fingerprint := sshtesting.ValidKeyOne.Fingerprint + " (user@host)"
+	c.Assert(coretesting.Stdout(context), gc.Equals, `{"user":"admin","keys":["`+fingerprint+`"]}`+"\n")
+}
+
 func (s *ListKeysSuite) TestTooManyArgs(c *gc.C) {
 	_, err := coretesting.RunCommand(c, newListKeysCommand(), "foo")
 	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
@@ -106,6 +106,118 @@ func deployBundle(data *charm.BundleData, client *api.Client, csclient *csClient
 	return nil
 }
 
+// applyBundleOverlay merges the overlay bundle data into the given bundle
+// data. Services and machines in the overlay are added to the bundle, or
+// merged into those of the same name: options and annotations are merged
+// key by key, and any other attributes set in the overlay replace those
+// in the bundle. Relations in the overlay are added to those in the bundle
+// if not already present.
+func applyBundleOverlay(data, overlay *charm.BundleData) {
+	if overlay.Series != "" {
+		data.Series = overlay.Series
+	}
+	for name, spec := range overlay.Services {
+		if spec == nil {
+			continue
+		}
+		base, ok := data.Services[name]
+		if !ok || base == nil {
+			if data.Services == nil {
+				data.Services = make(map[string]*charm.ServiceSpec)
+			}
+			data.Services[name] = spec
+			continue
+		}
+		merged := *base
+		if spec.Charm != "" {
+			merged.Charm = spec.Charm
+		}
+		if spec.NumUnits != 0 {
+			merged.NumUnits = spec.NumUnits
+		}
+		if len(spec.To) > 0 {
+			merged.To = spec.To
+		}
+		if spec.Expose {
+			merged.Expose = true
+		}
+		if spec.Constraints != "" {
+			merged.Constraints = spec.Constraints
+		}
+		if len(spec.Options) > 0 {
+			merged.Options = make(map[string]interface{})
+			for key, value := range base.Options {
+				merged.Options[key] = value
+			}
+			for key, value := range spec.Options {
+				merged.Options[key] = value
+			}
+		}
+		merged.Annotations = mergeAnnotations(base.Annotations, spec.Annotations)
+		data.Services[name] = &merged
+	}
+	for name, spec := range overlay.Machines {
+		if spec == nil {
+			continue
+		}
+		base, ok := data.Machines[name]
+		if !ok || base == nil {
+			if data.Machines == nil {
+				data.Machines = make(map[string]*charm.MachineSpec)
+			}
+			data.Machines[name] = spec
+			continue
+		}
+		merged := *base
+		if spec.Constraints != "" {
+			merged.Constraints = spec.Constraints
+		}
+		if spec.Series != "" {
+			merged.Series = spec.Series
+		}
+		merged.Annotations = mergeAnnotations(base.Annotations, spec.Annotations)
+		data.Machines[name] = &merged
+	}
+	for _, relation := range overlay.Relations {
+		if !hasBundleRelation(data.Relations, relation) {
+			data.Relations = append(data.Relations, relation)
+		}
+	}
+}
+
+// mergeAnnotations returns the annotations in base, updated with those in
+// overlay. The base annotations are not modified.
+func mergeAnnotations(base, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make(map[string]string)
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+	return merged
+}
+
+// hasBundleRelation reports whether the given relations include one
+// between the same endpoints as relation, in either order.
+func hasBundleRelation(relations [][]string, relation []string) bool {
+	key := func(endpoints []string) string {
+		sorted := append([]string(nil), endpoints...)
+		sort.Strings(sorted)
+		return strings.Join(sorted, " ")
+	}
+	want := key(relation)
+	for _, existing := range relations {
+		if key(existing) == want {
+			return true
+		}
+	}
+	return false
+}
+
 // bundleHandler provides helpers and the state required to deploy a bundle.
 type bundleHandler struct {
 	// changes holds the changes to be applied in order to deploy the bundle.
@@ -151,7 +263,7 @@ type bundleHandler struct {
 
 // addCharm adds a charm to the environment.
 func (h *bundleHandler) addCharm(id string, p bundlechanges.AddCharmParams) error {
-	url, repo, err := resolveCharmStoreEntityURL(p.Charm, h.csclient.params, h.repoPath, h.conf)
+	url, repo, err := resolveCharmStoreEntityURL(p.Charm, h.csclient, h.repoPath, h.conf)
 	if err != nil {
 		return errors.Annotatef(err, "cannot resolve URL %q", p.Charm)
 	}
@@ -1228,3 +1228,145 @@ deployment of bundle "local:bundle/example-0" completed`
 		"answer": "42",
 	})
 }
+
+func (s *deployRepoCharmStoreSuite) TestDeployBundleOverlay(c *gc.C) {
+	testcharms.UploadCharm(c, s.client, "trusty/mysql-42", "mysql")
+	testcharms.UploadCharm(c, s.client, "trusty/wordpress-47", "wordpress")
+	bundlePath := filepath.Join(c.MkDir(), "bundle.yaml")
+	err := ioutil.WriteFile(bundlePath, []byte(`
+        services:
+            wordpress:
+                charm: wordpress
+                num_units: 1
+                options:
+                    blog-title: these are the voyages
+            mysql:
+                charm: mysql
+                num_units: 1
+    `), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	overlayPath := filepath.Join(c.MkDir(), "overlay.yaml")
+	err = ioutil.WriteFile(overlayPath, []byte(`
+        services:
+            wordpress:
+                options:
+                    blog-title: to boldly go
+        relations:
+            - ["wordpress:db", "mysql:server"]
+    `), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx, err := coretesting.RunCommand(c, newDeployCommand(), bundlePath, "--overlay", overlayPath)
+	c.Assert(err, jc.ErrorIsNil)
+	output := strings.Trim(coretesting.Stderr(ctx), "\n")
+	expectedOutput := `
+added charm cs:trusty/mysql-42
+service mysql deployed (charm: cs:trusty/mysql-42)
+added charm cs:trusty/wordpress-47
+service wordpress deployed (charm: cs:trusty/wordpress-47)
+related wordpress:db and mysql:server
+added mysql/0 unit to new machine
+added wordpress/0 unit to new machine
+deployment of bundle "` + bundlePath + `" completed`
+	c.Assert(output, gc.Equals, strings.TrimSpace(expectedOutput))
+	s.assertServicesDeployed(c, map[string]serviceInfo{
+		"mysql": {charm: "cs:trusty/mysql-42"},
+		"wordpress": {
+			charm:  "cs:trusty/wordpress-47",
+			config: charm.Settings{"blog-title": "to boldly go"},
+		},
+	})
+	s.assertRelationsEstablished(c, "wordpress:db mysql:server")
+}
+
+func (s *deployRepoCharmStoreSuite) TestDeployBundleOverlayNotFound(c *gc.C) {
+	bundlePath := filepath.Join(c.MkDir(), "bundle.yaml")
+	err := ioutil.WriteFile(bundlePath, []byte("services: {}"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = coretesting.RunCommand(c, newDeployCommand(), bundlePath, "--overlay", "no-such-overlay.yaml")
+	c.Assert(err, gc.ErrorMatches, "cannot read bundle overlay: open .*no-such-overlay.yaml: no such file or directory")
+}
+
+func (s *DeploySuite) TestDeployCharmWithOverlay(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--overlay", "overlay.yaml")
+	c.Assert(err, gc.ErrorMatches, "--overlay can only be used when deploying a bundle")
+}
+
+type bundleOverlaySuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&bundleOverlaySuite{})
+
+func (s *bundleOverlaySuite) TestApplyBundleOverlay(c *gc.C) {
+	data := &charm.BundleData{
+		Services: map[string]*charm.ServiceSpec{
+			"wordpress": {
+				Charm:       "wordpress",
+				NumUnits:    1,
+				Options:     map[string]interface{}{"blog-title": "base", "debug": "no"},
+				Annotations: map[string]string{"gui-x": "1"},
+			},
+			"mysql": {
+				Charm:    "mysql",
+				NumUnits: 1,
+			},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Series: "trusty"},
+		},
+		Relations: [][]string{{"wordpress:db", "mysql:server"}},
+	}
+	overlay := &charm.BundleData{
+		Series: "trusty",
+		Services: map[string]*charm.ServiceSpec{
+			"wordpress": {
+				NumUnits:    3,
+				Constraints: "mem=4G",
+				Options:     map[string]interface{}{"blog-title": "overlay"},
+				Annotations: map[string]string{"gui-y": "2"},
+			},
+			"haproxy": {
+				Charm:    "haproxy",
+				NumUnits: 1,
+				Expose:   true,
+			},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Constraints: "cpu-cores=4"},
+		},
+		Relations: [][]string{
+			{"mysql:server", "wordpress:db"},
+			{"haproxy:reverseproxy", "wordpress:website"},
+		},
+	}
+	applyBundleOverlay(data, overlay)
+	c.Assert(data, jc.DeepEquals, &charm.BundleData{
+		Series: "trusty",
+		Services: map[string]*charm.ServiceSpec{
+			"wordpress": {
+				Charm:       "wordpress",
+				NumUnits:    3,
+				Constraints: "mem=4G",
+				Options:     map[string]interface{}{"blog-title": "overlay", "debug": "no"},
+				Annotations: map[string]string{"gui-x": "1", "gui-y": "2"},
+			},
+			"mysql": {
+				Charm:    "mysql",
+				NumUnits: 1,
+			},
+			"haproxy": {
+				Charm:    "haproxy",
+				NumUnits: 1,
+				Expose:   true,
+			},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Series: "trusty", Constraints: "cpu-cores=4"},
+		},
+		Relations: [][]string{
+			{"wordpress:db", "mysql:server"},
+			{"haproxy:reverseproxy", "wordpress:website"},
+		},
+	})
+}
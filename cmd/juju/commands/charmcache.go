@@ -0,0 +1,229 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/juju/charmrepo.v1"
+)
+
+// charmCacheTTL holds how long a cached charm store URL resolution is
+// used before the charm store is asked again.
+const charmCacheTTL = 24 * time.Hour
+
+// charmCache is a client side cache of charm store URL resolutions and
+// of the charm and bundle archives downloaded from the charm store, so
+// that repeated deployments of the same charm or bundle do not need to
+// contact the charm store.
+//
+// Fully resolved URLs always refer to the same archive, so archives are
+// cached until an explicit refresh. Resolutions of partial URLs (for
+// instance, without a revision) expire after the cache TTL. If the
+// charm store cannot be reached, expired resolutions are used anyway
+// so that a charm that has been deployed before can be deployed again
+// while offline.
+type charmCache struct {
+	// dir holds the directory where the cache is stored.
+	dir string
+
+	// ttl holds how long a URL resolution stays fresh.
+	ttl time.Duration
+
+	// refresh records whether cached data should be ignored and
+	// fetched again from the charm store.
+	refresh bool
+
+	// now returns the current time.
+	now func() time.Time
+}
+
+// newCharmCache returns a charm cache stored alongside the charm
+// repository cache. If refresh is true, cached data is fetched again
+// and the cache updated with the result. It returns nil, meaning no
+// caching, if the charm repository cache directory has not been set.
+func newCharmCache(refresh bool) *charmCache {
+	if charmrepo.CacheDir == "" {
+		return nil
+	}
+	return &charmCache{
+		dir:     filepath.Join(charmrepo.CacheDir, "store"),
+		ttl:     charmCacheTTL,
+		refresh: refresh,
+		now:     time.Now,
+	}
+}
+
+// wrap returns a charm repository that serves URL resolutions and
+// archives from the cache where possible, and otherwise uses repo
+// and caches the results.
+func (c *charmCache) wrap(repo charmrepo.Interface) charmrepo.Interface {
+	return &cachingRepo{
+		Interface: repo,
+		cache:     c,
+	}
+}
+
+// resolution records the URL that a charm or bundle reference was
+// resolved to, and when.
+type resolution struct {
+	URL  string    `json:"url"`
+	Time time.Time `json:"time"`
+}
+
+func (c *charmCache) indexPath() string {
+	return filepath.Join(c.dir, "resolved.json")
+}
+
+// readIndex returns the cached resolutions, keyed by reference.
+func (c *charmCache) readIndex() (map[string]resolution, error) {
+	index := make(map[string]resolution)
+	data, err := ioutil.ReadFile(c.indexPath())
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, errors.Annotate(err, "cannot parse charm cache index")
+	}
+	return index, nil
+}
+
+// lookup returns the cached resolution of the given reference, and
+// whether it is still fresh.
+func (c *charmCache) lookup(ref *charm.Reference) (curl *charm.URL, fresh bool, err error) {
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	entry, ok := index[ref.String()]
+	if !ok {
+		return nil, false, errors.NotFoundf("cached resolution of %q", ref)
+	}
+	curl, err = charm.ParseURL(entry.URL)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	return curl, c.now().Sub(entry.Time) < c.ttl, nil
+}
+
+// store records that the given reference resolves to curl.
+func (c *charmCache) store(ref *charm.Reference, curl *charm.URL) error {
+	index, err := c.readIndex()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	index[ref.String()] = resolution{
+		URL:  curl.String(),
+		Time: c.now(),
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	return utils.AtomicWriteFile(c.indexPath(), data, 0644)
+}
+
+// archivePath returns the path of the cached archive for curl, with
+// the given extension.
+func (c *charmCache) archivePath(curl *charm.URL, ext string) string {
+	return filepath.Join(c.dir, charm.Quote(curl.String())+ext)
+}
+
+// storeArchive copies the archive at path into the cache as dest.
+func (c *charmCache) storeArchive(path, dest string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	return utils.AtomicWriteFile(dest, data, 0644)
+}
+
+// cachingRepo is a charm repository that uses a charmCache in front
+// of another repository.
+type cachingRepo struct {
+	charmrepo.Interface
+	cache *charmCache
+}
+
+// Resolve implements charmrepo.Interface.Resolve.
+func (r *cachingRepo) Resolve(ref *charm.Reference) (*charm.URL, error) {
+	cached, fresh, err := r.cache.lookup(ref)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Warningf("cannot read charm cache: %v", err)
+	}
+	if cached != nil && fresh && !r.cache.refresh {
+		logger.Debugf("using cached resolution of %q: %q", ref, cached)
+		return cached, nil
+	}
+	curl, err := r.Interface.Resolve(ref)
+	if err != nil {
+		if cached == nil {
+			return nil, errors.Trace(err)
+		}
+		logger.Warningf("cannot resolve %q: %v; using previously resolved %q", ref, err, cached)
+		return cached, nil
+	}
+	if err := r.cache.store(ref, curl); err != nil {
+		logger.Warningf("cannot update charm cache: %v", err)
+	}
+	return curl, nil
+}
+
+// Get implements charmrepo.Interface.Get.
+func (r *cachingRepo) Get(curl *charm.URL) (charm.Charm, error) {
+	path := r.cache.archivePath(curl, ".charm")
+	if !r.cache.refresh {
+		if ch, err := charm.ReadCharmArchive(path); err == nil {
+			logger.Debugf("using cached archive for %q", curl)
+			return ch, nil
+		}
+	}
+	ch, err := r.Interface.Get(curl)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if archive, ok := ch.(*charm.CharmArchive); ok {
+		if err := r.cache.storeArchive(archive.Path, path); err != nil {
+			logger.Warningf("cannot cache archive for %q: %v", curl, err)
+		}
+	}
+	return ch, nil
+}
+
+// GetBundle implements charmrepo.Interface.GetBundle.
+func (r *cachingRepo) GetBundle(curl *charm.URL) (charm.Bundle, error) {
+	path := r.cache.archivePath(curl, ".bundle")
+	if !r.cache.refresh {
+		if b, err := charm.ReadBundleArchive(path); err == nil {
+			logger.Debugf("using cached archive for %q", curl)
+			return b, nil
+		}
+	}
+	b, err := r.Interface.GetBundle(curl)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if archive, ok := b.(*charm.BundleArchive); ok {
+		if err := r.cache.storeArchive(archive.Path, path); err != nil {
+			logger.Warningf("cannot cache archive for %q: %v", curl, err)
+		}
+	}
+	return b, nil
+}
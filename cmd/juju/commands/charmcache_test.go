@@ -0,0 +1,183 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/juju/charmrepo.v1"
+
+	"github.com/juju/juju/testcharms"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type charmCacheSuite struct {
+	coretesting.BaseSuite
+	now   time.Time
+	repo  *fakeCharmRepo
+	cache *charmCache
+}
+
+var _ = gc.Suite(&charmCacheSuite{})
+
+func (s *charmCacheSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.now = time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.repo = &fakeCharmRepo{
+		resolved: charm.MustParseURL("cs:trusty/wordpress-3"),
+		charm:    testcharms.Repo.CharmArchive(c.MkDir(), "wordpress"),
+		bundle:   testcharms.Repo.BundleArchive(c.MkDir(), "wordpress-simple"),
+	}
+	s.cache = s.newCache(c.MkDir(), false)
+}
+
+func (s *charmCacheSuite) newCache(dir string, refresh bool) *charmCache {
+	return &charmCache{
+		dir:     dir,
+		ttl:     time.Hour,
+		refresh: refresh,
+		now:     func() time.Time { return s.now },
+	}
+}
+
+func (s *charmCacheSuite) TestNewCharmCacheWithoutCacheDir(c *gc.C) {
+	s.PatchValue(&charmrepo.CacheDir, "")
+	c.Assert(newCharmCache(false), gc.IsNil)
+}
+
+func (s *charmCacheSuite) TestResolveCached(c *gc.C) {
+	ref := charm.MustParseReference("cs:trusty/wordpress")
+	repo := s.cache.wrap(s.repo)
+	curl, err := repo.Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl, jc.DeepEquals, s.repo.resolved)
+
+	s.repo.resolved = charm.MustParseURL("cs:trusty/wordpress-4")
+	s.now = s.now.Add(30 * time.Minute)
+	curl, err = repo.Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-3")
+	c.Assert(s.repo.resolveCalls, gc.Equals, 1)
+}
+
+func (s *charmCacheSuite) TestResolveExpired(c *gc.C) {
+	ref := charm.MustParseReference("cs:trusty/wordpress")
+	repo := s.cache.wrap(s.repo)
+	_, err := repo.Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.repo.resolved = charm.MustParseURL("cs:trusty/wordpress-4")
+	s.now = s.now.Add(2 * time.Hour)
+	curl, err := repo.Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-4")
+	c.Assert(s.repo.resolveCalls, gc.Equals, 2)
+}
+
+func (s *charmCacheSuite) TestResolveRefresh(c *gc.C) {
+	ref := charm.MustParseReference("cs:trusty/wordpress")
+	_, err := s.cache.wrap(s.repo).Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.repo.resolved = charm.MustParseURL("cs:trusty/wordpress-4")
+	refreshing := s.newCache(s.cache.dir, true)
+	curl, err := refreshing.wrap(s.repo).Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-4")
+
+	// The refreshed resolution replaces the cached one.
+	curl, err = s.cache.wrap(s.repo).Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-4")
+	c.Assert(s.repo.resolveCalls, gc.Equals, 2)
+}
+
+func (s *charmCacheSuite) TestResolveOfflineUsesExpired(c *gc.C) {
+	ref := charm.MustParseReference("cs:trusty/wordpress")
+	repo := s.cache.wrap(s.repo)
+	_, err := repo.Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.repo.err = errors.New("no route to host")
+	s.now = s.now.Add(2 * time.Hour)
+	curl, err := repo.Resolve(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-3")
+}
+
+func (s *charmCacheSuite) TestResolveOfflineNotCached(c *gc.C) {
+	s.repo.err = errors.New("no route to host")
+	_, err := s.cache.wrap(s.repo).Resolve(charm.MustParseReference("cs:trusty/wordpress"))
+	c.Assert(err, gc.ErrorMatches, "no route to host")
+}
+
+func (s *charmCacheSuite) TestGetCached(c *gc.C) {
+	curl := charm.MustParseURL("cs:trusty/wordpress-3")
+	repo := s.cache.wrap(s.repo)
+	ch, err := repo.Get(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().Name, gc.Equals, "wordpress")
+
+	s.repo.err = errors.New("no route to host")
+	ch, err = repo.Get(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().Name, gc.Equals, "wordpress")
+	c.Assert(s.repo.getCalls, gc.Equals, 1)
+}
+
+func (s *charmCacheSuite) TestGetBundleCached(c *gc.C) {
+	curl := charm.MustParseURL("cs:bundle/wordpress-simple-1")
+	repo := s.cache.wrap(s.repo)
+	b, err := repo.GetBundle(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(b.Data(), jc.DeepEquals, s.repo.bundle.Data())
+
+	s.repo.err = errors.New("no route to host")
+	b, err = repo.GetBundle(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(b.Data(), jc.DeepEquals, s.repo.bundle.Data())
+	c.Assert(s.repo.getCalls, gc.Equals, 1)
+
+	// Refreshing fetches the bundle again.
+	_, err = s.newCache(s.cache.dir, true).wrap(s.repo).GetBundle(curl)
+	c.Assert(err, gc.ErrorMatches, "no route to host")
+}
+
+type fakeCharmRepo struct {
+	charmrepo.Interface
+	resolved     *charm.URL
+	charm        *charm.CharmArchive
+	bundle       *charm.BundleArchive
+	err          error
+	resolveCalls int
+	getCalls     int
+}
+
+func (r *fakeCharmRepo) Resolve(ref *charm.Reference) (*charm.URL, error) {
+	r.resolveCalls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.resolved, nil
+}
+
+func (r *fakeCharmRepo) Get(curl *charm.URL) (charm.Charm, error) {
+	r.getCalls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.charm, nil
+}
+
+func (r *fakeCharmRepo) GetBundle(curl *charm.URL) (charm.Bundle, error) {
+	r.getCalls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.bundle, nil
+}
@@ -110,8 +110,9 @@ func environFromNameProductionFunc(
 
 // resolveCharmStoreEntityURL resolves the given charm or bundle URL string
 // by looking it up in the appropriate charm repository.
-// If it is a charm store URL, the given csParams will
-// be used to access the charm store repository.
+// If it is a charm store URL, the parameters of the given csClient will
+// be used to access the charm store repository, and the client's charm
+// cache, if any, will be used in front of it.
 // If it is a local charm or bundle URL, the local charm repository at
 // the given repoPath will be used. The given configuration
 // will be used to add any necessary attributes to the repo
@@ -119,16 +120,19 @@ func environFromNameProductionFunc(
 //
 // resolveCharmStoreEntityURL also returns the charm repository holding
 // the charm or bundle.
-func resolveCharmStoreEntityURL(urlStr string, csParams charmrepo.NewCharmStoreParams, repoPath string, conf *config.Config) (*charm.URL, charmrepo.Interface, error) {
+func resolveCharmStoreEntityURL(urlStr string, csclient *csClient, repoPath string, conf *config.Config) (*charm.URL, charmrepo.Interface, error) {
 	ref, err := charm.ParseReference(urlStr)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
-	repo, err := charmrepo.InferRepository(ref, csParams, repoPath)
+	repo, err := charmrepo.InferRepository(ref, csclient.params, repoPath)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
 	repo = config.SpecializeCharmRepo(repo, conf)
+	if ref.Schema == "cs" && csclient.cache != nil {
+		repo = csclient.cache.wrap(repo)
+	}
 	if ref.Series == "" {
 		if defaultSeries, ok := conf.DefaultSeries(); ok {
 			ref.Series = defaultSeries
@@ -195,6 +199,10 @@ func addCharmViaAPI(client *api.Client, curl *charm.URL, repo charmrepo.Interfac
 // for connecting to the charm store.
 type csClient struct {
 	params charmrepo.NewCharmStoreParams
+
+	// cache, if not nil, holds the client side cache of charm store
+	// data to use when resolving URLs and fetching archives.
+	cache *charmCache
 }
 
 // newCharmStoreClient is called to obtain a charm store client
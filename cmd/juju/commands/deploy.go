@@ -6,6 +6,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -46,6 +47,19 @@ type deployCommand struct {
 	// Storage is a map of storage constraints, keyed on the storage name
 	// defined in charm storage metadata.
 	Storage map[string]storage.Constraints
+
+	// BindToSpaces holds the raw value of the --bind flag, and
+	// Bindings the endpoint bindings parsed from it.
+	BindToSpaces string
+	Bindings     map[string]string
+
+	// Overlays holds the paths of the bundle overlay files to apply
+	// when deploying a bundle, in order.
+	Overlays []string
+
+	// RefreshCache records whether cached charm store data should
+	// be ignored and fetched again.
+	RefreshCache bool
 }
 
 const deployDoc = `
@@ -85,6 +99,23 @@ To deploy this using a direct path:
 
   juju deploy $JUJU_REPOSITORY/bundle/openstack/bundle.yaml
 
+A bundle may be tweaked for a particular environment with the --overlay flag,
+which names a file holding bundle YAML to merge into the bundle before it is
+deployed. The flag may be repeated; overlays are applied in order. Services
+and machines in an overlay are added to the bundle, or merged into those of
+the same name: options and annotations are merged key by key, and the charm,
+number of units, placement and constraints replace those in the bundle when
+set. Relations in an overlay are added to those in the bundle. For example:
+
+  juju deploy bundle/openstack --overlay production.yaml
+
+Charm store URL resolutions and downloaded bundles are cached locally, so
+repeated deployments of the same charm or bundle do not need to contact the
+charm store. A URL without a revision is resolved again after a day; until
+then, and whenever the charm store cannot be reached, the cached revision is
+used. The --refresh-cache flag ignores the cache and fetches everything from
+the charm store again.
+
 <service name>, if omitted, will be derived from <charm name>.
 
 Constraints can be specified when using deploy by specifying the --constraints
@@ -117,6 +148,10 @@ When deploying a service or adding machines, the "spaces" constraint can be
 used to define a comma-delimited list of required and forbidden spaces
 (the latter prefixed with "^", similar to the "tags" constraint).
 
+The --bind flag binds the charm's relation endpoints to spaces, given as a
+space-separated list of <endpoint>=<space> pairs. Hooks can then use the
+network-get hook tool to find the unit's address in the bound space.
+
 If you have the main container directory mounted on a btrfs partition,
 then the clone will be using btrfs snapshots to create the containers.
 This means that clones use up much less disk space.  If you do not have btrfs,
@@ -137,6 +172,10 @@ Examples:
    (deploy 2 instances of haproxy on cloud instances being part of the dmz
     space but not of the cmd and the database space)
 
+   juju deploy wordpress --bind "db=internal website=public"
+   (deploy wordpress with its db endpoint bound to the internal space and
+    its website endpoint bound to the public space)
+
 See Also:
    juju help spaces
    juju help constraints
@@ -161,8 +200,11 @@ func (c *deployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(&c.Config, "config", "path to yaml-formatted service config")
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "set service constraints")
 	f.StringVar(&c.Networks, "networks", "", "deprecated and ignored: use space constraints instead.")
-	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepositoryEnvKey), "local charm repository")
+	f.StringVar(&c.RepoPath, "repository", osenv.JujuRepositoryVar.Value(), "local charm repository")
 	f.Var(storageFlag{&c.Storage}, "storage", "charm storage constraints")
+	f.StringVar(&c.BindToSpaces, "bind", "", "bind charm endpoints to spaces, e.g. \"db=internal url=public\"")
+	f.Var(cmd.NewAppendStringsValue(&c.Overlays), "overlay", "bundle overlay file to apply when deploying a bundle")
+	f.BoolVar(&c.RefreshCache, "refresh-cache", false, "ignore cached charm store data and fetch it again")
 }
 
 func (c *deployCommand) Init(args []string) error {
@@ -180,9 +222,56 @@ func (c *deployCommand) Init(args []string) error {
 	default:
 		return cmd.CheckEmpty(args[2:])
 	}
+	bindings, err := parseBindings(c.BindToSpaces)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.Bindings = bindings
 	return c.UnitCommandBase.Init(args)
 }
 
+// parseBindings parses a space-separated list of endpoint=space pairs,
+// as given to the --bind flag.
+func parseBindings(value string) (map[string]string, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	bindings := make(map[string]string)
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid --bind parameter %q: expected <endpoint>=<space>", field)
+		}
+		if !names.IsValidSpace(parts[1]) {
+			return nil, errors.Errorf("invalid --bind parameter %q: invalid space name %q", field, parts[1])
+		}
+		if _, ok := bindings[parts[0]]; ok {
+			return nil, errors.Errorf("invalid --bind parameter: endpoint %q bound more than once", parts[0])
+		}
+		bindings[parts[0]] = parts[1]
+	}
+	return bindings, nil
+}
+
+// applyOverlays reads each of the bundle overlay files given with the
+// --overlay flag, and merges them into the bundle data in order.
+func (c *deployCommand) applyOverlays(ctx *cmd.Context, data *charm.BundleData) error {
+	for _, path := range c.Overlays {
+		f, err := os.Open(ctx.AbsPath(path))
+		if err != nil {
+			return errors.Annotate(err, "cannot read bundle overlay")
+		}
+		overlay, err := charm.ReadBundleData(f)
+		f.Close()
+		if err != nil {
+			return errors.Annotatef(err, "cannot read bundle overlay %q", path)
+		}
+		applyBundleOverlay(data, overlay)
+	}
+	return nil
+}
+
 func (c *deployCommand) newServiceAPIClient() (*apiservice.Client, error) {
 	root, err := c.NewAPIRoot()
 	if err != nil {
@@ -212,6 +301,7 @@ func (c *deployCommand) Run(ctx *cmd.Context) error {
 		return errors.Trace(err)
 	}
 	csClient := newCharmStoreClient(httpClient)
+	csClient.cache = newCharmCache(c.RefreshCache)
 	repoPath := ctx.AbsPath(c.RepoPath)
 
 	// Handle local bundle paths.
@@ -229,6 +319,9 @@ func (c *deployCommand) Run(ctx *cmd.Context) error {
 		if err != nil {
 			return block.ProcessBlockedError(err, block.BlockChange)
 		}
+		if err := c.applyOverlays(ctx, bundleData); err != nil {
+			return errors.Trace(err)
+		}
 		if err := deployBundle(bundleData, client, csClient, repoPath, conf, ctx); err != nil {
 			return block.ProcessBlockedError(err, block.BlockChange)
 		}
@@ -238,7 +331,7 @@ func (c *deployCommand) Run(ctx *cmd.Context) error {
 		logger.Warningf("cannot open %q: %v; falling back to using charm repository", c.CharmOrBundle, err)
 	}
 
-	curl, repo, err := resolveCharmStoreEntityURL(c.CharmOrBundle, csClient.params, repoPath, conf)
+	curl, repo, err := resolveCharmStoreEntityURL(c.CharmOrBundle, csClient, repoPath, conf)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -250,12 +343,19 @@ func (c *deployCommand) Run(ctx *cmd.Context) error {
 		if err != nil {
 			return block.ProcessBlockedError(err, block.BlockChange)
 		}
-		if err := deployBundle(bundle.Data(), client, csClient, repoPath, conf, ctx); err != nil {
+		bundleData := bundle.Data()
+		if err := c.applyOverlays(ctx, bundleData); err != nil {
+			return errors.Trace(err)
+		}
+		if err := deployBundle(bundleData, client, csClient, repoPath, conf, ctx); err != nil {
 			return block.ProcessBlockedError(err, block.BlockChange)
 		}
 		ctx.Infof("deployment of bundle %q completed", curl)
 		return nil
 	}
+	if len(c.Overlays) > 0 {
+		return errors.New("--overlay can only be used when deploying a bundle")
+	}
 
 	curl, err = addCharmViaAPI(client, curl, repo, csClient)
 	if err != nil {
@@ -296,9 +396,9 @@ func (c *deployCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
-	// If storage or placement is specified, we attempt to use a new API on the service facade.
-	if len(c.Storage) > 0 || len(c.Placement) > 0 {
-		notSupported := errors.New("cannot deploy charms with storage or placement: not supported by the API server")
+	// If storage, placement or bindings are specified, we attempt to use a new API on the service facade.
+	if len(c.Storage) > 0 || len(c.Placement) > 0 || len(c.Bindings) > 0 {
+		notSupported := errors.New("cannot deploy charms with storage, placement or bindings: not supported by the API server")
 		serviceClient, err := c.newServiceAPIClient()
 		if err != nil {
 			return notSupported
@@ -310,17 +410,18 @@ func (c *deployCommand) Run(ctx *cmd.Context) error {
 			}
 			c.Placement[i] = p
 		}
-		err = serviceClient.ServiceDeploy(
-			curl.String(),
-			serviceName,
-			numUnits,
-			string(configYAML),
-			c.Constraints,
-			c.PlacementSpec,
-			c.Placement,
-			[]string{},
-			c.Storage,
-		)
+		err = serviceClient.ServiceDeploy(apiservice.DeployArgs{
+			CharmURL:         curl.String(),
+			ServiceName:      serviceName,
+			NumUnits:         numUnits,
+			ConfigYAML:       string(configYAML),
+			Constraints:      c.Constraints,
+			ToMachineSpec:    c.PlacementSpec,
+			Placement:        c.Placement,
+			Networks:         []string{},
+			Storage:          c.Storage,
+			EndpointBindings: c.Bindings,
+		})
 		if params.IsCodeNotImplemented(err) {
 			return notSupported
 		}
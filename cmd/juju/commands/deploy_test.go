@@ -80,6 +80,15 @@ var initErrorTests = []struct {
 	}, {
 		args: []string{"craziness", "burble1", "--constraints", "gibber=plop"},
 		err:  `invalid value "gibber=plop" for flag --constraints: unknown constraint "gibber"`,
+	}, {
+		args: []string{"craziness", "burble1", "--bind", "db"},
+		err:  `invalid --bind parameter "db": expected <endpoint>=<space>`,
+	}, {
+		args: []string{"craziness", "burble1", "--bind", "db=Bad_Space"},
+		err:  `invalid --bind parameter "db=Bad_Space": invalid space name "Bad_Space"`,
+	}, {
+		args: []string{"craziness", "burble1", "--bind", "db=a db=b"},
+		err:  `invalid --bind parameter: endpoint "db" bound more than once`,
 	},
 }
 
@@ -255,6 +264,26 @@ func (s *DeploySuite) TestPlacement(c *gc.C) {
 	c.Assert(mid, gc.Not(gc.Equals), machine.Id())
 }
 
+func (s *DeploySuite) TestBindings(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "wordpress")
+	_, err := s.State.AddSpace("internal", nil, false)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSpace("public", nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = runDeploy(c, "local:wordpress", "--bind", "db=internal url=public")
+	c.Assert(err, jc.ErrorIsNil)
+
+	svc, err := s.State.Service("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	bindings, err := svc.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, jc.DeepEquals, map[string]string{
+		"db":  "internal",
+		"url": "public",
+	})
+}
+
 func (s *DeploySuite) TestSubordinateConstraints(c *gc.C) {
 	testcharms.Repo.CharmArchivePath(s.SeriesPath, "logging")
 	err := runDeploy(c, "local:logging", "--constraints", "mem=1G")
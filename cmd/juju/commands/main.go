@@ -131,6 +131,8 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(newEndpointCommand())
 	r.Register(newAPIInfoCommand())
 	r.Register(status.NewStatusHistoryCommand())
+	r.Register(newAuditLogCommand())
+	r.Register(newWaitForCommand())
 
 	// Error resolution and debugging commands.
 	r.Register(newRunCommand())
@@ -142,6 +144,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 
 	// Configuration commands.
 	r.Register(newInitCommand())
+	r.Register(newMigrateHomeCommand())
 	r.RegisterDeprecated(common.NewGetConstraintsCommand(),
 		twoDotOhDeprecation("environment get-constraints or service get-constraints"))
 	r.RegisterDeprecated(common.NewSetConstraintsCommand(),
@@ -201,6 +201,7 @@ var commandNames = []string{
 	"add-unit",
 	"api-endpoints",
 	"api-info",
+	"audit-log",
 	"authorised-keys", // alias for authorized-keys
 	"authorized-keys",
 	"backups",
@@ -228,6 +229,7 @@ var commandNames = []string{
 	"help-tool",
 	"init",
 	"machine",
+	"migrate-home",
 	"publish",
 	"remove-machine",  // alias for destroy-machine
 	"remove-relation", // alias for destroy-relation
@@ -261,6 +263,7 @@ var commandNames = []string{
 	"upgrade-juju",
 	"user",
 	"version",
+	"wait-for",
 }
 
 func (s *MainSuite) TestHelpCommands(c *gc.C) {
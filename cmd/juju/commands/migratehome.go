@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju/osenv"
+)
+
+func newMigrateHomeCommand() cmd.Command {
+	return &migrateHomeCommand{}
+}
+
+const migrateHomeDoc = `
+Moves the contents of a legacy ~/.juju directory to the juju data
+directory ($XDG_DATA_HOME/juju, by default ~/.local/share/juju).
+
+Nothing is moved if $JUJU_DATA or $JUJU_HOME is set, or if the data
+directory is already in use. Local provider environments keep their
+root directories in the juju home, so they must be destroyed before
+migrating.
+`
+
+// migrateHomeCommand moves a legacy juju home to the XDG data home.
+type migrateHomeCommand struct {
+	cmd.CommandBase
+}
+
+func (c *migrateHomeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "migrate-home",
+		Purpose: "move ~/.juju to the juju data directory",
+		Doc:     migrateHomeDoc,
+	}
+}
+
+func (c *migrateHomeCommand) Run(ctx *cmd.Context) error {
+	store, err := configstore.Default()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	envNames, err := store.List()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, envName := range envNames {
+		info, err := store.ReadInfo(envName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if info.BootstrapConfig()["type"] == "local" {
+			return errors.Errorf("local environment %q is running from %s; destroy it before migrating", envName, osenv.JujuHome())
+		}
+	}
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	if err != nil {
+		return errors.Annotate(err, "cannot migrate juju home")
+	}
+	if !migrated {
+		fmt.Fprintf(ctx.Stdout, "nothing to migrate, juju home is %s\n", osenv.JujuHomeDir())
+		return nil
+	}
+	osenv.SetJujuHome(osenv.JujuHomeDir())
+	fmt.Fprintf(ctx.Stdout, "juju home moved to %s\n", osenv.JujuHome())
+	return nil
+}
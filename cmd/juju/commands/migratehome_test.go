@@ -0,0 +1,73 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type MigrateHomeSuite struct {
+	testing.FakeJujuHomeSuite
+	legacy   string
+	dataHome string
+}
+
+var _ = gc.Suite(&MigrateHomeSuite{})
+
+func (s *MigrateHomeSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.PatchEnvironment(osenv.XDGDataHomeEnvKey, "")
+	s.legacy = gitjujutesting.HomePath(".juju")
+	s.dataHome = gitjujutesting.HomePath(".local", "share", "juju")
+}
+
+func (s *MigrateHomeSuite) writeEnvironInfo(c *gc.C, envName, envType string) {
+	store, err := configstore.Default()
+	c.Assert(err, jc.ErrorIsNil)
+	info := store.CreateInfo(envName)
+	info.SetBootstrapConfig(map[string]interface{}{"type": envType})
+	err = info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MigrateHomeSuite) TestMigrate(c *gc.C) {
+	s.writeEnvironInfo(c, "ec2", "ec2")
+	ctx, err := testing.RunCommand(c, newMigrateHomeCommand())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, "juju home moved to "+s.dataHome+"\n")
+
+	_, err = os.Stat(s.legacy)
+	c.Check(os.IsNotExist(err), jc.IsTrue)
+	_, err = os.Stat(filepath.Join(s.dataHome, "environments.yaml"))
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(osenv.JujuHome(), gc.Equals, s.dataHome)
+}
+
+func (s *MigrateHomeSuite) TestNothingToMigrate(c *gc.C) {
+	err := os.RemoveAll(s.legacy)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx, err := testing.RunCommand(c, newMigrateHomeCommand())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, "nothing to migrate, juju home is "+s.dataHome+"\n")
+}
+
+func (s *MigrateHomeSuite) TestRefusesWithLocalEnvironment(c *gc.C) {
+	s.writeEnvironInfo(c, "local", "local")
+	_, err := testing.RunCommand(c, newMigrateHomeCommand())
+	c.Assert(err, gc.ErrorMatches, `local environment "local" is running from .*; destroy it before migrating`)
+
+	_, err = os.Stat(filepath.Join(s.legacy, "environments.yaml"))
+	c.Check(err, jc.ErrorIsNil)
+	_, err = os.Stat(s.dataHome)
+	c.Check(os.IsNotExist(err), jc.IsTrue)
+}
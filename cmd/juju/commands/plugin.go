@@ -91,10 +91,21 @@ func (c *PluginCommand) Init(args []string) error {
 
 func (c *PluginCommand) Run(ctx *cmd.Context) error {
 	command := exec.Command(c.name, c.args...)
-	command.Env = append(os.Environ(), []string{
-		osenv.JujuHomeEnvKey + "=" + osenv.JujuHome(),
-		osenv.JujuEnvEnvKey + "=" + c.ConnectionName()}...,
-	)
+	env := osenv.NewEnvBuilder(os.Environ()).
+		Set(osenv.JujuHomeEnvKey, osenv.JujuHome()).
+		Set(osenv.JujuEnvEnvKey, c.ConnectionName())
+	// Pass on the cached API endpoint of the environment, if it has
+	// been bootstrapped, so the plugin need not look it up itself.
+	// The endpoint is not refreshed; that would require connecting
+	// to the API server before every plugin is run.
+	if info, err := envcmd.ConnectionInfoForName(c.ConnectionName()); err == nil {
+		endpoint := info.APIEndpoint()
+		env.Set(osenv.JujuAPIAddressesEnvKey, strings.Join(endpoint.Addresses, " "))
+		env.Set(osenv.JujuEnvUUIDEnvKey, endpoint.EnvironUUID)
+	} else {
+		logger.Debugf("not passing API endpoint to plugin: %v", err)
+	}
+	command.Env = env.Environ()
 
 	// Now hook up stdin, stdout, stderr
 	command.Stdin = ctx.Stdin
@@ -17,6 +17,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/environs/configstore"
 	"github.com/juju/juju/testing"
 )
 
@@ -174,7 +175,27 @@ func (suite *PluginSuite) TestDebugAsArg(c *gc.C) {
 func (suite *PluginSuite) TestJujuEnvVars(c *gc.C) {
 	suite.makeFullPlugin(PluginParams{Name: "foo"})
 	output := badrun(c, 0, "foo", "-e", "myenv", "-p", "pluginarg")
-	expectedDebug := `foo -e myenv -p pluginarg\n.*env is:  myenv\n.*home is: .*\.juju\n`
+	expectedDebug := `foo -e myenv -p pluginarg\n.*env is:  myenv\n.*home is: .*\.juju\n` +
+		`.*api addresses is: \n.*env uuid is: \n`
+	c.Assert(output, gc.Matches, expectedDebug)
+}
+
+func (suite *PluginSuite) TestJujuAPIEndpointEnvVars(c *gc.C) {
+	store, err := configstore.Default()
+	c.Assert(err, jc.ErrorIsNil)
+	info := store.CreateInfo("myenv")
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"10.0.0.1:17070", "10.0.0.2:17070"},
+		CACert:      testing.CACert,
+		EnvironUUID: "some-uuid",
+	})
+	err = info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+
+	suite.makeFullPlugin(PluginParams{Name: "foo"})
+	output := badrun(c, 0, "foo", "-e", "myenv")
+	expectedDebug := `foo -e myenv\n.*env is:  myenv\n.*home is: .*\.juju\n` +
+		`.*api addresses is:  10\.0\.0\.1:17070 10\.0\.0\.2:17070\n.*env uuid is:  some-uuid\n`
 	c.Assert(output, gc.Matches, expectedDebug)
 }
 
@@ -226,6 +247,8 @@ fi
 echo {{.Name}} $*
 echo "env is: " $JUJU_ENV
 echo "home is: " $JUJU_HOME
+echo "api addresses is: " $JUJU_API_ADDRESSES
+echo "env uuid is: " $JUJU_ENV_UUID
 exit {{.ExitStatus}}
 `
 
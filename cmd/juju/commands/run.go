@@ -26,13 +26,15 @@ func newRunCommand() cmd.Command {
 // runCommand is responsible for running arbitrary commands on remote machines.
 type runCommand struct {
 	envcmd.EnvCommandBase
-	out      cmd.Output
-	all      bool
-	timeout  time.Duration
-	machines []string
-	services []string
-	units    []string
-	commands string
+	out            cmd.Output
+	all            bool
+	timeout        time.Duration
+	maxConcurrency int
+	resultsId      string
+	machines       []string
+	services       []string
+	units          []string
+	commands       string
 }
 
 const runDoc = `
@@ -61,12 +63,21 @@ the unit.
 in the environment.  If you specify --all you cannot provide additional
 targets.
 
+The commands are run on all targets in parallel. --max-concurrency limits
+the number of targets the commands are run on at once. --timeout applies
+to each target separately.
+
+The results of each run are recorded in the environment, and the id they
+are recorded under is reported. Pass that id to --results to show them
+again later, in place of commands and targets:
+  juju run --results 3
+
 `
 
 func (c *runCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "run",
-		Args:    "<commands>",
+		Args:    "<commands> | --results <id>",
 		Purpose: "run the commands on the remote targets specified",
 		Doc:     runDoc,
 	}
@@ -76,15 +87,26 @@ func (c *runCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
 	f.BoolVar(&c.all, "all", false, "run the commands on all the machines")
 	f.DurationVar(&c.timeout, "timeout", 5*time.Minute, "how long to wait before the remote command is considered to have failed")
+	f.IntVar(&c.maxConcurrency, "max-concurrency", 0, "the maximum number of targets to run the commands on at once (0 means no limit)")
+	f.StringVar(&c.resultsId, "results", "", "show the recorded results with the given id, rather than running commands")
 	f.Var(cmd.NewStringsValue(nil, &c.machines), "machine", "one or more machine ids")
 	f.Var(cmd.NewStringsValue(nil, &c.services), "service", "one or more service names")
 	f.Var(cmd.NewStringsValue(nil, &c.units), "unit", "one or more unit ids")
 }
 
 func (c *runCommand) Init(args []string) error {
+	if c.resultsId != "" {
+		if c.all || len(c.machines) != 0 || len(c.services) != 0 || len(c.units) != 0 {
+			return fmt.Errorf("You cannot specify --results and targets")
+		}
+		return cmd.CheckEmpty(args)
+	}
 	if len(args) == 0 {
 		return fmt.Errorf("no commands specified")
 	}
+	if c.maxConcurrency < 0 {
+		return fmt.Errorf("--max-concurrency must not be negative")
+	}
 	c.commands, args = args[0], args[1:]
 
 	if c.all {
@@ -183,27 +205,38 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	var runResults []params.RunResult
-	if c.all {
-		runResults, err = client.RunOnAllMachines(c.commands, c.timeout)
+	var results params.RunResults
+	if c.resultsId != "" {
+		results, err = client.RunResults(c.resultsId)
+		if err != nil {
+			return err
+		}
 	} else {
-		params := params.RunParams{
-			Commands: c.commands,
-			Timeout:  c.timeout,
-			Machines: c.machines,
-			Services: c.services,
-			Units:    c.units,
+		runParams := params.RunParams{
+			Commands:       c.commands,
+			Timeout:        c.timeout,
+			MaxConcurrency: c.maxConcurrency,
+		}
+		if c.all {
+			results, err = client.RunOnAllMachinesRecorded(runParams)
+		} else {
+			runParams.Machines = c.machines
+			runParams.Services = c.services
+			runParams.Units = c.units
+			results, err = client.RunRecorded(runParams)
+		}
+		if err != nil {
+			return block.ProcessBlockedError(err, block.BlockChange)
 		}
-		runResults, err = client.Run(params)
-	}
-
-	if err != nil {
-		return block.ProcessBlockedError(err, block.BlockChange)
 	}
+	runResults := results.Results
 
 	// If we are just dealing with one result, AND we are using the smart
 	// format, then pretend we were running it locally.
 	if len(runResults) == 1 && c.out.Name() == "smart" {
+		if results.Id != "" && c.resultsId == "" {
+			ctx.Verbosef("results recorded with id %s", results.Id)
+		}
 		result := runResults[0]
 		ctx.Stdout.Write(result.Stdout)
 		ctx.Stderr.Write(result.Stderr)
@@ -217,7 +250,12 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 		return nil
 	}
 
-	c.out.Write(ctx, ConvertRunResults(runResults))
+	if err := c.out.Write(ctx, ConvertRunResults(runResults)); err != nil {
+		return err
+	}
+	if results.Id != "" && c.resultsId == "" {
+		ctx.Infof("results recorded with id %s", results.Id)
+	}
 	return nil
 }
 
@@ -226,8 +264,9 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 
 type RunClient interface {
 	Close() error
-	RunOnAllMachines(commands string, timeout time.Duration) ([]params.RunResult, error)
-	Run(run params.RunParams) ([]params.RunResult, error)
+	RunOnAllMachinesRecorded(run params.RunParams) (params.RunResults, error)
+	RunRecorded(run params.RunParams) (params.RunResults, error)
+	RunResults(id string) (params.RunResults, error)
 }
 
 // Here we need the signature to be correct for the interface.
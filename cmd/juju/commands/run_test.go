@@ -155,6 +155,48 @@ func (*RunSuite) TestTimeoutArgParsing(c *gc.C) {
 	}
 }
 
+func (*RunSuite) TestMaxConcurrencyAndResultsArgParsing(c *gc.C) {
+	for i, test := range []struct {
+		message        string
+		args           []string
+		errMatch       string
+		maxConcurrency int
+		resultsId      string
+	}{{
+		message: "default concurrency",
+		args:    []string{"--all", "sudo reboot"},
+	}, {
+		message:        "limited concurrency",
+		args:           []string{"--max-concurrency=3", "--all", "sudo reboot"},
+		maxConcurrency: 3,
+	}, {
+		message:  "negative concurrency",
+		args:     []string{"--max-concurrency=-1", "--all", "sudo reboot"},
+		errMatch: "--max-concurrency must not be negative",
+	}, {
+		message:   "results",
+		args:      []string{"--results", "3"},
+		resultsId: "3",
+	}, {
+		message:  "results and commands",
+		args:     []string{"--results", "3", "sudo reboot"},
+		errMatch: `unrecognized args: \["sudo reboot"\]`,
+	}, {
+		message:  "results and targets",
+		args:     []string{"--results", "3", "--machine=0"},
+		errMatch: "You cannot specify --results and targets",
+	}} {
+		c.Log(fmt.Sprintf("%v: %s", i, test.message))
+		cmd := &runCommand{}
+		runCmd := envcmd.Wrap(cmd)
+		testing.TestInit(c, runCmd, test.args, test.errMatch)
+		if test.errMatch == "" {
+			c.Check(cmd.maxConcurrency, gc.Equals, test.maxConcurrency)
+			c.Check(cmd.resultsId, gc.Equals, test.resultsId)
+		}
+	}
+}
+
 func (s *RunSuite) TestConvertRunResults(c *gc.C) {
 	for i, test := range []struct {
 		message  string
@@ -312,6 +354,37 @@ func (s *RunSuite) TestAllMachines(c *gc.C) {
 	c.Check(testing.Stdout(context), gc.Equals, string(jsonFormatted)+"\n")
 }
 
+func (s *RunSuite) TestRunRecordsResults(c *gc.C) {
+	mock := s.setupMockAPI()
+	mock.setMachinesAlive("0", "1")
+	mock.resultsId = "7"
+	mock.setResponse("0", mockResponse{stdout: "megatron\n", machineId: "0"})
+	mock.setResponse("1", mockResponse{stdout: "bumblebee\n", machineId: "1"})
+
+	context, err := testing.RunCommand(c, newRunCommand(),
+		"--format=json", "--max-concurrency=1", "--timeout=1m", "--all", "hostname",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(mock.runParams, jc.DeepEquals, params.RunParams{
+		Commands:       "hostname",
+		Timeout:        time.Minute,
+		MaxConcurrency: 1,
+	})
+	c.Check(testing.Stderr(context), gc.Equals, "results recorded with id 7\n")
+	recorded := testing.Stdout(context)
+
+	context, err = testing.RunCommand(c, newRunCommand(), "--format=json", "--results", "7")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(context), gc.Equals, recorded)
+	c.Check(testing.Stderr(context), gc.Equals, "")
+}
+
+func (s *RunSuite) TestRunResultsNotFound(c *gc.C) {
+	s.setupMockAPI()
+	_, err := testing.RunCommand(c, newRunCommand(), "--results", "7")
+	c.Assert(err, gc.ErrorMatches, "not found")
+}
+
 func (s *RunSuite) TestBlockAllMachines(c *gc.C) {
 	mock := s.setupMockAPI()
 	// Block operation
@@ -393,6 +466,10 @@ type mockRunAPI struct {
 	machines  map[string]bool
 	responses map[string]params.RunResult
 	block     bool
+	// runParams records the parameters of the last run.
+	runParams params.RunParams
+	// resultsId is the id the mock reports results recorded under.
+	resultsId string
 }
 
 type mockResponse struct {
@@ -439,12 +516,13 @@ func (*mockRunAPI) Close() error {
 	return nil
 }
 
-func (m *mockRunAPI) RunOnAllMachines(commands string, timeout time.Duration) ([]params.RunResult, error) {
+func (m *mockRunAPI) RunOnAllMachinesRecorded(runParams params.RunParams) (params.RunResults, error) {
 	var result []params.RunResult
 
 	if m.block {
-		return result, common.OperationBlockedError("the operation has been blocked")
+		return params.RunResults{}, common.OperationBlockedError("the operation has been blocked")
 	}
+	m.runParams = runParams
 	sortedMachineIds := make([]string, 0, len(m.machines))
 	for machineId := range m.machines {
 		sortedMachineIds = append(sortedMachineIds, machineId)
@@ -460,15 +538,16 @@ func (m *mockRunAPI) RunOnAllMachines(commands string, timeout time.Duration) ([
 		result = append(result, response)
 	}
 
-	return result, nil
+	return params.RunResults{Results: result, Id: m.resultsId}, nil
 }
 
-func (m *mockRunAPI) Run(runParams params.RunParams) ([]params.RunResult, error) {
+func (m *mockRunAPI) RunRecorded(runParams params.RunParams) (params.RunResults, error) {
 	var result []params.RunResult
 
 	if m.block {
-		return result, common.OperationBlockedError("the operation has been blocked")
+		return params.RunResults{}, common.OperationBlockedError("the operation has been blocked")
 	}
+	m.runParams = runParams
 	// Just add in ids that match in order.
 	for _, id := range runParams.Machines {
 		response, found := m.responses[id]
@@ -484,5 +563,21 @@ func (m *mockRunAPI) Run(runParams params.RunParams) ([]params.RunResult, error)
 		}
 	}
 
-	return result, nil
+	return params.RunResults{Results: result, Id: m.resultsId}, nil
+}
+
+func (m *mockRunAPI) RunResults(id string) (params.RunResults, error) {
+	if id != m.resultsId {
+		return params.RunResults{}, &params.Error{Code: params.CodeNotFound, Message: "not found"}
+	}
+	keys := make([]string, 0, len(m.responses))
+	for key := range m.responses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var result []params.RunResult
+	for _, key := range keys {
+		result = append(result, m.responses[key])
+	}
+	return params.RunResults{Results: result, Id: id}, nil
 }
@@ -73,6 +73,20 @@ func (c *scpCommand) Init(args []string) error {
 	return nil
 }
 
+// scpTargets returns the targets of any remote locations
+// in the given scp arguments.
+func scpTargets(args []string) []string {
+	var targets []string
+	for _, arg := range args {
+		v := strings.SplitN(arg, ":", 2)
+		if strings.HasPrefix(arg, "-") || len(v) <= 1 {
+			continue
+		}
+		targets = append(targets, v[0])
+	}
+	return targets
+}
+
 // expandArgs takes a list of arguments and looks for ones in the form of
 // 0:some/path or service/0:some/path, and translates them into
 // ubuntu@machine:some/path so they can be passed as arguments to scp, and pass
@@ -105,7 +119,7 @@ func (c *scpCommand) Run(ctx *cmd.Context) error {
 	}
 	defer c.apiClient.Close()
 
-	options, err := c.getSSHOptions(false)
+	options, err := c.getSSHOptions(false, scpTargets(c.Args)...)
 	if err != nil {
 		return err
 	}
@@ -113,5 +127,9 @@ func (c *scpCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	if err := c.setKnownHosts(options); err != nil {
+		return err
+	}
+	defer c.cleanupKnownHosts()
 	return ssh.Copy(args, options)
 }
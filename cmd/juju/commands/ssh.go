@@ -5,6 +5,7 @@ package commands
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"os/exec"
@@ -16,6 +17,7 @@ import (
 	"github.com/juju/utils"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/utils/ssh"
@@ -33,17 +35,28 @@ type sshCommand struct {
 // SSHCommon provides common methods for sshCommand, SCPCommand and DebugHooksCommand.
 type SSHCommon struct {
 	envcmd.EnvCommandBase
-	proxy     bool
-	pty       bool
-	Target    string
-	Args      []string
-	apiClient sshAPIClient
-	apiAddr   string
+	proxy           bool
+	pty             bool
+	noHostKeyChecks bool
+	Target          string
+	Args            []string
+	apiClient       sshAPIClient
+	apiAddr         string
+
+	// knownHosts holds the known_hosts entries for the resolved
+	// targets, built from the host keys reported by their machines.
+	knownHosts []string
+	// checkedTargets holds the resolved targets with host keys
+	// recorded, and uncheckedTargets those without.
+	checkedTargets   []string
+	uncheckedTargets []string
+	knownHostsPath   string
 }
 
 func (c *SSHCommon) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.proxy, "proxy", true, "proxy through the API server")
 	f.BoolVar(&c.pty, "pty", true, "enable pseudo-tty allocation")
+	f.BoolVar(&c.noHostKeyChecks, "no-host-key-checks", false, "skip verification of host keys against those reported by the machine agent")
 }
 
 // setProxyCommand sets the proxy command option.
@@ -66,6 +79,18 @@ Launch an ssh shell on the machine identified by the <target> parameter.
 "machines" section or a unit name as listed in the "services" section.
 Any extra parameters are passed as extra parameters to the ssh command.
 
+If the environment's "proxy-ssh" setting is true, the connection is proxied
+through the API server, so machines without public addresses can be reached.
+Containers never have public addresses, so connections to container machines
+(such as "0/lxc/1") are always proxied unless --proxy=false is given.
+
+When connecting to a machine or unit, the host keys presented are checked
+against those reported by the machine agent, and the connection is refused
+if they do not match. Pass --no-host-key-checks to skip this verification.
+If the machine agent has not reported any host keys, they are not checked;
+scp refuses to copy between a machine whose keys are checked and one whose
+keys cannot be, unless --no-host-key-checks is given.
+
 Examples:
 
 Connect to machine 0:
@@ -87,6 +112,10 @@ Connect to the first mysql unit and run 'ls -la /var/log/juju':
 Connect to the first jenkins unit as the user jenkins:
 
     juju ssh jenkins@jenkins/0
+
+Connect to the first LXC container on machine 0:
+
+    juju ssh 0/lxc/0
 `
 
 func (c *sshCommand) Info() *cmd.Info {
@@ -112,8 +141,9 @@ var getJujuExecutable = func() (string, error) {
 	return exec.LookPath(os.Args[0])
 }
 
-// getSSHOptions configures and returns SSH options and proxy settings.
-func (c *SSHCommon) getSSHOptions(enablePty bool) (*ssh.Options, error) {
+// getSSHOptions configures and returns SSH options and proxy settings
+// for connecting to the given targets.
+func (c *SSHCommon) getSSHOptions(enablePty bool, targets ...string) (*ssh.Options, error) {
 	var options ssh.Options
 
 	// TODO(waigani) do not save fingerprint only until this bug is addressed:
@@ -123,7 +153,7 @@ func (c *SSHCommon) getSSHOptions(enablePty bool) (*ssh.Options, error) {
 		options.EnablePTY()
 	}
 	var err error
-	if c.proxy, err = c.proxySSH(); err != nil {
+	if c.proxy, err = c.proxySSH(targets...); err != nil {
 		return nil, err
 	} else if c.proxy {
 		if err := c.setProxyCommand(&options); err != nil {
@@ -146,7 +176,7 @@ func (c *sshCommand) Run(ctx *cmd.Context) error {
 			}
 		}()
 	}
-	options, err := c.getSSHOptions(c.pty)
+	options, err := c.getSSHOptions(c.pty, c.Target)
 	if err != nil {
 		return err
 	}
@@ -155,6 +185,10 @@ func (c *sshCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	if err := c.setKnownHosts(options); err != nil {
+		return err
+	}
+	defer c.cleanupKnownHosts()
 	cmd := ssh.Command(user+"@"+host, c.Args, options)
 	cmd.Stdin = ctx.Stdin
 	cmd.Stdout = ctx.Stdout
@@ -162,16 +196,23 @@ func (c *sshCommand) Run(ctx *cmd.Context) error {
 	return cmd.Run()
 }
 
-// proxySSH returns true iff both c.proxy and
-// the proxy-ssh environment configuration
-// are true.
-func (c *SSHCommon) proxySSH() (bool, error) {
+// proxySSH returns true iff c.proxy is true and either
+// the proxy-ssh environment configuration is true or
+// one of the targets is a container, which will have
+// no public address.
+func (c *SSHCommon) proxySSH(targets ...string) (bool, error) {
 	if !c.proxy {
 		return false, nil
 	}
 	if _, err := c.ensureAPIClient(); err != nil {
 		return false, err
 	}
+	for _, target := range targets {
+		if isContainerTarget(target) {
+			logger.Debugf("proxying ssh to container %q", target)
+			return true, nil
+		}
+	}
 	var cfg *config.Config
 	attrs, err := c.apiClient.EnvironmentGet()
 	if err == nil {
@@ -203,10 +244,20 @@ func (c *SSHCommon) initAPIClient() (sshAPIClient, error) {
 	return c.apiClient, nil
 }
 
+// isContainerTarget reports whether the target, optionally
+// prefixed with a user name, identifies a container machine.
+func isContainerTarget(target string) bool {
+	if i := strings.IndexRune(target, '@'); i != -1 {
+		target = target[i+1:]
+	}
+	return names.IsValidMachine(target) && strings.Contains(target, "/")
+}
+
 type sshAPIClient interface {
 	EnvironmentGet() (map[string]interface{}, error)
 	PublicAddress(target string) (string, error)
 	PrivateAddress(target string) (string, error)
+	SSHHostKeys(target string) ([]string, error)
 	ServiceCharmRelations(service string) ([]string, error)
 	Close() error
 }
@@ -259,12 +310,77 @@ func (c *SSHCommon) userHostFromTarget(target string) (user, host string, err er
 			addr, err = c.apiClient.PublicAddress(target)
 		}
 		if err == nil {
+			if err := c.addKnownHost(target, addr); err != nil {
+				return "", "", err
+			}
 			return user, addr, nil
 		}
 	}
 	return "", "", err
 }
 
+// addKnownHost records the host keys reported for the target, so
+// that they can be checked when connecting to it at the given address.
+func (c *SSHCommon) addKnownHost(target, addr string) error {
+	if c.noHostKeyChecks {
+		return nil
+	}
+	keys, err := c.apiClient.SSHHostKeys(target)
+	if params.IsCodeNotFound(err) {
+		logger.Warningf("no ssh host keys recorded for %q, not checking its host keys", target)
+		c.uncheckedTargets = append(c.uncheckedTargets, target)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get ssh host keys for %q: %v", target, err)
+	}
+	c.checkedTargets = append(c.checkedTargets, target)
+	for _, key := range keys {
+		c.knownHosts = append(c.knownHosts, addr+" "+key)
+	}
+	return nil
+}
+
+// setKnownHosts configures options to check host keys against those
+// recorded by addKnownHost. Host key checking applies to every host
+// ssh connects to, so the keys of targets without recorded keys cannot
+// be left unchecked while others are checked; that is an error, unless
+// --no-host-key-checks was given.
+func (c *SSHCommon) setKnownHosts(options *ssh.Options) error {
+	if c.noHostKeyChecks || len(c.checkedTargets) == 0 {
+		return nil
+	}
+	if len(c.uncheckedTargets) > 0 {
+		return fmt.Errorf(
+			"cannot check host keys of %s: none recorded for %s (use --no-host-key-checks to skip host key checks)",
+			strings.Join(c.checkedTargets, ", "), strings.Join(c.uncheckedTargets, ", "),
+		)
+	}
+	f, err := ioutil.TempFile("", "juju-known-hosts")
+	if err != nil {
+		return fmt.Errorf("cannot create known hosts file: %v", err)
+	}
+	defer f.Close()
+	c.knownHostsPath = f.Name()
+	if _, err := f.WriteString(strings.Join(c.knownHosts, "\n") + "\n"); err != nil {
+		return fmt.Errorf("cannot write known hosts file: %v", err)
+	}
+	options.SetKnownHostsFile(c.knownHostsPath)
+	options.EnableStrictHostKeyChecking()
+	return nil
+}
+
+// cleanupKnownHosts removes any known hosts file written by
+// setKnownHosts.
+func (c *SSHCommon) cleanupKnownHosts() {
+	if c.knownHostsPath == "" {
+		return
+	}
+	if err := os.Remove(c.knownHostsPath); err != nil {
+		logger.Warningf("cannot remove known hosts file: %v", err)
+	}
+	c.knownHostsPath = ""
+}
+
 // AllowInterspersedFlags for ssh/scp is set to false so that
 // flags after the unit name are passed through to ssh, for eg.
 // `juju ssh -v service-name/0 uname -a`.
@@ -6,6 +6,7 @@ package commands
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -17,6 +18,8 @@ import (
 	"gopkg.in/juju/charm.v6-unstable"
 
 	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -142,6 +145,131 @@ func (s *SSHSuite) TestSSHCommandEnvironProxySSH(c *gc.C) {
 	c.Check(strings.TrimRight(ctx.Stdout.(*bytes.Buffer).String(), "\r\n"), gc.Equals, sshArgsNoProxy+"ubuntu@dummyenv-0.dns")
 }
 
+func (s *SSHSuite) runSSH(c *gc.C, args ...string) string {
+	ctx := coretesting.Context(c)
+	jujucmd := cmd.NewSuperCommand(cmd.SuperCommandParams{})
+	jujucmd.Register(newSSHCommand())
+	code := cmd.Main(jujucmd, ctx, append([]string{"ssh"}, args...))
+	c.Check(code, gc.Equals, 0)
+	c.Check(ctx.Stderr.(*bytes.Buffer).String(), gc.Equals, "")
+	return strings.TrimRight(ctx.Stdout.(*bytes.Buffer).String(), "\r\n")
+}
+
+func (s *SSHSuite) TestSSHCommandHostKeyChecking(c *gc.C) {
+	m := s.makeMachines(1, c, true)
+	err := m[0].SetSSHHostKeys([]string{"ssh-rsa rsa"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	out := s.runSSH(c, "--proxy=false", "0")
+	c.Assert(out, gc.Matches, `-o StrictHostKeyChecking yes -o PasswordAuthentication no -o ServerAliveInterval 30 -t -t -o UserKnownHostsFile .*juju-known-hosts.* ubuntu@dummyenv-0.dns`)
+
+	// The known hosts file is removed once ssh exits.
+	knownHosts := strings.Fields(out)[13]
+	_, err = os.Stat(knownHosts)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *SSHSuite) TestSSHCommandNoHostKeyChecks(c *gc.C) {
+	m := s.makeMachines(1, c, true)
+	err := m[0].SetSSHHostKeys([]string{"ssh-rsa rsa"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	out := s.runSSH(c, "--proxy=false", "--no-host-key-checks", "0")
+	c.Assert(out, gc.Equals, sshArgsNoProxy+"ubuntu@dummyenv-0.dns")
+}
+
+func (s *SSHSuite) TestSSHCommandKnownHosts(c *gc.C) {
+	var sshCmd sshCommand
+	sshCmd.apiClient = &fakeSSHHostKeysClient{keys: map[string][]string{
+		"0": {"ssh-rsa rsa", "ssh-ed25519 ed25519"},
+	}}
+	err := sshCmd.addKnownHost("0", "10.0.0.1")
+	c.Assert(err, jc.ErrorIsNil)
+	var options ssh.Options
+	err = sshCmd.setKnownHosts(&options)
+	c.Assert(err, jc.ErrorIsNil)
+	defer sshCmd.cleanupKnownHosts()
+	data, err := ioutil.ReadFile(sshCmd.knownHostsPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "10.0.0.1 ssh-rsa rsa\n10.0.0.1 ssh-ed25519 ed25519\n")
+
+}
+
+func (s *SSHSuite) TestSSHCommandKnownHostsNoneRecorded(c *gc.C) {
+	var sshCmd sshCommand
+	sshCmd.apiClient = &fakeSSHHostKeysClient{}
+	err := sshCmd.addKnownHost("1", "10.0.0.2")
+	c.Assert(err, jc.ErrorIsNil)
+	var options ssh.Options
+	err = sshCmd.setKnownHosts(&options)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sshCmd.knownHostsPath, gc.Equals, "")
+}
+
+func (s *SSHSuite) TestSSHCommandKnownHostsSomeMissing(c *gc.C) {
+	var sshCmd sshCommand
+	sshCmd.apiClient = &fakeSSHHostKeysClient{keys: map[string][]string{
+		"0": {"ssh-rsa rsa"},
+	}}
+	err := sshCmd.addKnownHost("0", "10.0.0.1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = sshCmd.addKnownHost("1", "10.0.0.2")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// One target's keys are not silently left unchecked because
+	// another's are missing.
+	var options ssh.Options
+	err = sshCmd.setKnownHosts(&options)
+	c.Assert(err, gc.ErrorMatches, `cannot check host keys of 0: none recorded for 1 \(use --no-host-key-checks to skip host key checks\)`)
+	c.Assert(sshCmd.knownHostsPath, gc.Equals, "")
+
+	sshCmd.noHostKeyChecks = true
+	err = sshCmd.setKnownHosts(&options)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sshCmd.knownHostsPath, gc.Equals, "")
+}
+
+func (s *SSHSuite) TestIsContainerTarget(c *gc.C) {
+	for target, expect := range map[string]bool{
+		"0":               false,
+		"0/lxc/1":         true,
+		"jenkins@0/kvm/0": true,
+		"mysql/0":         false,
+		"10.0.0.1":        false,
+	} {
+		c.Check(isContainerTarget(target), gc.Equals, expect, gc.Commentf("%s", target))
+	}
+}
+
+func (s *SSHSuite) TestSSHCommandContainerAlwaysProxied(c *gc.C) {
+	s.makeMachines(1, c, true)
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"proxy-ssh": false}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, "0", instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	s.setAddresses(container, c)
+
+	out := s.runSSH(c, "0/lxc/0")
+	c.Assert(out, gc.Equals, sshArgs+"ubuntu@dummyenv-0/lxc/0.internal")
+}
+
+type fakeSSHHostKeysClient struct {
+	sshAPIClient
+	keys map[string][]string
+}
+
+func (f *fakeSSHHostKeysClient) SSHHostKeys(target string) ([]string, error) {
+	keys, ok := f.keys[target]
+	if !ok {
+		return nil, &params.Error{Code: params.CodeNotFound, Message: "not found"}
+	}
+	return keys, nil
+}
+
 func (s *SSHSuite) TestSSHWillWorkInUpgrade(c *gc.C) {
 	// Check the API client interface used by "juju ssh" against what
 	// the API server will allow during upgrades. Ensure that the API
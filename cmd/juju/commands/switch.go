@@ -5,7 +5,6 @@ package commands
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -15,6 +14,7 @@ import (
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju/osenv"
 )
 
 func newSwitchCommand() cmd.Command {
@@ -121,7 +121,7 @@ func (c *switchCommand) Run(ctx *cmd.Context) error {
 		return nil
 	}
 
-	jujuEnv := os.Getenv("JUJU_ENV")
+	jujuEnv := osenv.JujuEnvVar.Value()
 	if jujuEnv != "" {
 		if c.EnvName == "" {
 			fmt.Fprintf(ctx.Stdout, "%s\n", jujuEnv)
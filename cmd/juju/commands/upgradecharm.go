@@ -5,7 +5,6 @@ package commands
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -16,6 +15,7 @@ import (
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/cmd/juju/service"
+	"github.com/juju/juju/juju/osenv"
 )
 
 func newUpgradeCharmCommand() cmd.Command {
@@ -81,7 +81,7 @@ func (c *upgradeCharmCommand) Info() *cmd.Info {
 
 func (c *upgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.Force, "force", false, "upgrade all units immediately, even if in error state")
-	f.StringVar(&c.RepoPath, "repository", os.Getenv("JUJU_REPOSITORY"), "local charm repository path")
+	f.StringVar(&c.RepoPath, "repository", osenv.JujuRepositoryVar.Value(), "local charm repository path")
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
 }
@@ -138,7 +138,7 @@ func (c *upgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return errors.Trace(err)
 	}
 	csClient := newCharmStoreClient(httpClient)
-	newURL, repo, err := resolveCharmStoreEntityURL(newRef.String(), csClient.params, ctx.AbsPath(c.RepoPath), conf)
+	newURL, repo, err := resolveCharmStoreEntityURL(newRef.String(), csClient, ctx.AbsPath(c.RepoPath), conf)
 	if err != nil {
 		return errors.Trace(err)
 	}
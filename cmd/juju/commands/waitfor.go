@@ -0,0 +1,274 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+func newWaitForCommand() cmd.Command {
+	return envcmd.Wrap(&waitForCommand{})
+}
+
+// waitForCommand blocks until the units of a service, or a single
+// unit, reach the requested status.
+type waitForCommand struct {
+	envcmd.EnvCommandBase
+	serviceName    string
+	unitName       string
+	workloadStatus string
+	agentStatus    string
+	timeout        time.Duration
+}
+
+var waitForDoc = `
+Wait until a service or unit reaches the requested status, and then exit.
+For a service, all of its units must have the requested status; the command
+keeps waiting while the service has no units.
+
+By default, the command waits for the workload status to be "active". Use
+--workload-status and --agent-status to wait for other statuses; when both
+are given, both must be reached. When only --agent-status is given, the
+workload status is not checked.
+
+Changes to the environment are followed with the API's watcher, so status is
+not polled. If the status has not been reached within the --timeout, the
+command fails.
+
+Examples:
+
+   juju wait-for wordpress
+   (wait for all units of wordpress to have an active workload)
+
+   juju wait-for mysql/0 --agent-status idle --timeout 5m
+   (wait up to 5 minutes for the agent of mysql/0 to be idle)
+`
+
+func (c *waitForCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "wait-for",
+		Args:    "<service or unit>",
+		Purpose: "wait for a service or unit to reach a status",
+		Doc:     waitForDoc,
+	}
+}
+
+func (c *waitForCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.workloadStatus, "workload-status", "", `the workload status to wait for (default "active" unless --agent-status is given)`)
+	f.StringVar(&c.agentStatus, "agent-status", "", "the agent status to wait for")
+	f.DurationVar(&c.timeout, "timeout", 10*time.Minute, "how long to wait before failing")
+}
+
+func (c *waitForCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no service or unit specified")
+	}
+	switch entity := args[0]; {
+	case names.IsValidUnit(entity):
+		c.unitName = entity
+	case names.IsValidService(entity):
+		c.serviceName = entity
+	default:
+		return errors.Errorf("invalid service or unit name %q", entity)
+	}
+	if c.workloadStatus == "" && c.agentStatus == "" {
+		c.workloadStatus = string(params.StatusActive)
+	}
+	if err := checkWaitForStatus("workload", c.workloadStatus, waitForWorkloadStatuses); err != nil {
+		return err
+	}
+	if err := checkWaitForStatus("agent", c.agentStatus, waitForAgentStatuses); err != nil {
+		return err
+	}
+	if c.timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// waitForWorkloadStatuses and waitForAgentStatuses hold the statuses
+// a unit's workload and agent can report, which are the statuses the
+// wait-for command can wait for.
+var (
+	waitForWorkloadStatuses = set.NewStrings(
+		string(params.StatusActive),
+		string(params.StatusBlocked),
+		string(params.StatusError),
+		string(params.StatusMaintenance),
+		string(params.StatusTerminated),
+		string(params.StatusUnknown),
+		string(params.StatusWaiting),
+	)
+	waitForAgentStatuses = set.NewStrings(
+		string(params.StatusAllocating),
+		string(params.StatusError),
+		string(params.StatusExecuting),
+		string(params.StatusFailed),
+		string(params.StatusIdle),
+		string(params.StatusLost),
+		string(params.StatusRebooting),
+	)
+)
+
+// checkWaitForStatus returns an error if status, when set, is not one
+// of the known statuses.
+func checkWaitForStatus(kind, status string, known set.Strings) error {
+	if status == "" || known.Contains(status) {
+		return nil
+	}
+	return errors.Errorf(
+		"invalid %s status %q, expected one of: %s",
+		kind, status, strings.Join(known.SortedValues(), ", "),
+	)
+}
+
+// waitForAPI holds the API methods used by the wait-for command.
+type waitForAPI interface {
+	WatchAll() (waitForWatcher, error)
+	Close() error
+}
+
+// waitForWatcher is the part of the API's AllWatcher that the
+// wait-for command uses to follow changes to the environment.
+type waitForWatcher interface {
+	Next() ([]multiwatcher.Delta, error)
+	Stop() error
+}
+
+// waitForClient adapts an api.Client to the waitForAPI interface.
+type waitForClient struct {
+	*api.Client
+}
+
+// WatchAll is part of the waitForAPI interface.
+func (c waitForClient) WatchAll() (waitForWatcher, error) {
+	w, err := c.Client.WatchAll()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+var newWaitForAPI = func(c *waitForCommand) (waitForAPI, error) {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return waitForClient{client}, nil
+}
+
+func (c *waitForCommand) Run(ctx *cmd.Context) error {
+	client, err := newWaitForAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	watcher, err := client.WatchAll()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch environment")
+	}
+	defer watcher.Stop()
+
+	done := make(chan struct{})
+	defer close(done)
+	changes := make(chan []multiwatcher.Delta)
+	watchErr := make(chan error, 1)
+	go func() {
+		for {
+			deltas, err := watcher.Next()
+			if err != nil {
+				watchErr <- err
+				return
+			}
+			select {
+			case changes <- deltas:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	units := make(map[string]*multiwatcher.UnitInfo)
+	timeout := time.After(c.timeout)
+	for {
+		select {
+		case deltas := <-changes:
+			for _, delta := range deltas {
+				unit, ok := delta.Entity.(*multiwatcher.UnitInfo)
+				if !ok {
+					continue
+				}
+				if delta.Removed {
+					delete(units, unit.Name)
+				} else {
+					units[unit.Name] = unit
+				}
+			}
+			if c.reached(units) {
+				ctx.Infof("%s reached %s", c.entityName(), c.statusDescription())
+				return nil
+			}
+		case err := <-watchErr:
+			return errors.Annotate(err, "cannot watch environment")
+		case <-timeout:
+			return errors.Errorf(
+				"timed out after %v waiting for %s to reach %s",
+				c.timeout, c.entityName(), c.statusDescription(),
+			)
+		}
+	}
+}
+
+// reached reports whether all of the units being waited for, of which
+// there must be at least one, have the requested status.
+func (c *waitForCommand) reached(units map[string]*multiwatcher.UnitInfo) bool {
+	matched := 0
+	for _, unit := range units {
+		if c.unitName != "" && unit.Name != c.unitName {
+			continue
+		}
+		if c.serviceName != "" && unit.Service != c.serviceName {
+			continue
+		}
+		matched++
+		if c.workloadStatus != "" && string(unit.WorkloadStatus.Current) != c.workloadStatus {
+			return false
+		}
+		if c.agentStatus != "" && string(unit.AgentStatus.Current) != c.agentStatus {
+			return false
+		}
+	}
+	return matched > 0
+}
+
+func (c *waitForCommand) entityName() string {
+	if c.unitName != "" {
+		return "unit " + c.unitName
+	}
+	return "service " + c.serviceName
+}
+
+func (c *waitForCommand) statusDescription() string {
+	switch {
+	case c.agentStatus == "":
+		return fmt.Sprintf("workload status %q", c.workloadStatus)
+	case c.workloadStatus == "":
+		return fmt.Sprintf("agent status %q", c.agentStatus)
+	}
+	return fmt.Sprintf("workload status %q and agent status %q", c.workloadStatus, c.agentStatus)
+}
@@ -0,0 +1,210 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/multiwatcher"
+	"github.com/juju/juju/testing"
+)
+
+type WaitForSuite struct {
+	testing.FakeJujuHomeSuite
+	watcher *fakeWaitForWatcher
+	client  *fakeWaitForAPI
+}
+
+var _ = gc.Suite(&WaitForSuite{})
+
+func (s *WaitForSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.watcher = &fakeWaitForWatcher{
+		deltas:  make(chan []multiwatcher.Delta, 10),
+		stopped: make(chan struct{}),
+	}
+	s.client = &fakeWaitForAPI{watcher: s.watcher}
+	s.PatchValue(&newWaitForAPI, func(_ *waitForCommand) (waitForAPI, error) {
+		return s.client, nil
+	})
+}
+
+func (s *WaitForSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args           []string
+		serviceName    string
+		unitName       string
+		workloadStatus string
+		agentStatus    string
+		errMatch       string
+	}{{
+		args:     []string{},
+		errMatch: "no service or unit specified",
+	}, {
+		args:           []string{"wordpress"},
+		serviceName:    "wordpress",
+		workloadStatus: "active",
+	}, {
+		args:        []string{"wordpress/0", "--agent-status", "idle"},
+		unitName:    "wordpress/0",
+		agentStatus: "idle",
+	}, {
+		args:           []string{"wordpress/0", "--workload-status", "blocked", "--agent-status", "idle"},
+		unitName:       "wordpress/0",
+		workloadStatus: "blocked",
+		agentStatus:    "idle",
+	}, {
+		args:        []string{"wordpress", "--workload-status", "", "--agent-status", "idle"},
+		serviceName: "wordpress",
+		agentStatus: "idle",
+	}, {
+		args:     []string{"Word_Press"},
+		errMatch: `invalid service or unit name "Word_Press"`,
+	}, {
+		args:     []string{"wordpress", "--workload-status", "idle"},
+		errMatch: `invalid workload status "idle", expected one of: active, blocked, error, maintenance, terminated, unknown, waiting`,
+	}, {
+		args:     []string{"wordpress", "--agent-status", "started"},
+		errMatch: `invalid agent status "started", expected one of: allocating, error, executing, failed, idle, lost, rebooting`,
+	}, {
+		args:     []string{"wordpress", "--timeout", "0s"},
+		errMatch: "timeout must be positive",
+	}, {
+		args:     []string{"wordpress", "mysql"},
+		errMatch: `unrecognized args: \["mysql"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := &waitForCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.errMatch != "" {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(command.serviceName, gc.Equals, test.serviceName)
+		c.Check(command.unitName, gc.Equals, test.unitName)
+		c.Check(command.workloadStatus, gc.Equals, test.workloadStatus)
+		c.Check(command.agentStatus, gc.Equals, test.agentStatus)
+	}
+}
+
+func (s *WaitForSuite) TestWaitForService(c *gc.C) {
+	s.watcher.deltas <- []multiwatcher.Delta{
+		unitDelta("wordpress/0", "maintenance", "executing"),
+		unitDelta("wordpress/1", "active", "idle"),
+		unitDelta("mysql/0", "maintenance", "executing"),
+	}
+	s.watcher.deltas <- []multiwatcher.Delta{
+		unitDelta("wordpress/0", "active", "idle"),
+	}
+	ctx, err := testing.RunCommand(c, newWaitForCommand(), "wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "service wordpress reached workload status \"active\"\n")
+	c.Assert(s.watcher.isStopped(), jc.IsTrue)
+	c.Assert(s.client.closeCalled, jc.IsTrue)
+}
+
+func (s *WaitForSuite) TestWaitForUnitRemovedFromService(c *gc.C) {
+	removed := unitDelta("wordpress/0", "maintenance", "executing")
+	removed.Removed = true
+	s.watcher.deltas <- []multiwatcher.Delta{
+		unitDelta("wordpress/0", "maintenance", "executing"),
+		unitDelta("wordpress/1", "active", "idle"),
+	}
+	s.watcher.deltas <- []multiwatcher.Delta{removed}
+	_, err := testing.RunCommand(c, newWaitForCommand(), "wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *WaitForSuite) TestWaitForUnitAgentStatus(c *gc.C) {
+	s.watcher.deltas <- []multiwatcher.Delta{
+		unitDelta("wordpress/0", "active", "executing"),
+		unitDelta("wordpress/1", "maintenance", "executing"),
+	}
+	s.watcher.deltas <- []multiwatcher.Delta{
+		unitDelta("wordpress/0", "active", "idle"),
+	}
+	ctx, err := testing.RunCommand(c, newWaitForCommand(),
+		"wordpress/0", "--agent-status", "idle",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "unit wordpress/0 reached agent status \"idle\"\n")
+}
+
+func (s *WaitForSuite) TestWaitForTimeout(c *gc.C) {
+	s.watcher.deltas <- []multiwatcher.Delta{
+		unitDelta("mysql/0", "active", "idle"),
+	}
+	_, err := testing.RunCommand(c, newWaitForCommand(), "wordpress", "--timeout", "10ms")
+	c.Assert(err, gc.ErrorMatches, `timed out after 10ms waiting for service wordpress to reach workload status "active"`)
+	c.Assert(s.watcher.isStopped(), jc.IsTrue)
+}
+
+func (s *WaitForSuite) TestWaitForWatcherError(c *gc.C) {
+	s.watcher.Stop()
+	_, err := testing.RunCommand(c, newWaitForCommand(), "wordpress")
+	c.Assert(err, gc.ErrorMatches, "cannot watch environment: watcher was stopped")
+}
+
+func unitDelta(name, workloadStatus, agentStatus string) multiwatcher.Delta {
+	serviceName, _ := names.UnitService(name)
+	return multiwatcher.Delta{
+		Entity: &multiwatcher.UnitInfo{
+			Name:           name,
+			Service:        serviceName,
+			WorkloadStatus: multiwatcher.StatusInfo{Current: multiwatcher.Status(workloadStatus)},
+			AgentStatus:    multiwatcher.StatusInfo{Current: multiwatcher.Status(agentStatus)},
+		},
+	}
+}
+
+type fakeWaitForAPI struct {
+	watcher     *fakeWaitForWatcher
+	closeCalled bool
+}
+
+func (f *fakeWaitForAPI) WatchAll() (waitForWatcher, error) {
+	return f.watcher, nil
+}
+
+func (f *fakeWaitForAPI) Close() error {
+	f.closeCalled = true
+	return nil
+}
+
+// fakeWaitForWatcher returns the queued deltas from Next, and then
+// blocks until it is stopped, like the API's AllWatcher.
+type fakeWaitForWatcher struct {
+	deltas  chan []multiwatcher.Delta
+	stopped chan struct{}
+}
+
+func (w *fakeWaitForWatcher) Next() ([]multiwatcher.Delta, error) {
+	select {
+	case deltas := <-w.deltas:
+		return deltas, nil
+	case <-w.stopped:
+		return nil, errors.New("watcher was stopped")
+	}
+}
+
+func (w *fakeWaitForWatcher) Stop() error {
+	if !w.isStopped() {
+		close(w.stopped)
+	}
+	return nil
+}
+
+func (w *fakeWaitForWatcher) isStopped() bool {
+	select {
+	case <-w.stopped:
+		return true
+	default:
+		return false
+	}
+}
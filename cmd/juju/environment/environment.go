@@ -6,9 +6,9 @@ package environment
 import (
 	"github.com/juju/cmd"
 	"github.com/juju/loggo"
-	"github.com/juju/utils/featureflag"
 
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju/osenv/featureflag"
 )
 
 var logger = loggo.GetLogger("juju.cmd.juju.environment")
@@ -37,6 +37,8 @@ func NewSuperCommand() cmd.Command {
 	if featureflag.Enabled(feature.JES) {
 		environmentCmd.Register(newShareCommand())
 		environmentCmd.Register(newUnshareCommand())
+		environmentCmd.Register(newGrantCommand())
+		environmentCmd.Register(newRevokeCommand())
 		environmentCmd.Register(newUsersCommand())
 		environmentCmd.Register(newDestroyCommand())
 	}
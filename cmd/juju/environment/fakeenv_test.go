@@ -32,6 +32,8 @@ type fakeEnvAPI struct {
 	keys        []string
 	addUsers    []names.UserTag
 	removeUsers []names.UserTag
+	accessUser  names.UserTag
+	access      string
 }
 
 func (f *fakeEnvAPI) Close() error {
@@ -61,3 +63,15 @@ func (f *fakeEnvAPI) UnshareEnvironment(users ...names.UserTag) error {
 	f.removeUsers = users
 	return f.err
 }
+
+func (f *fakeEnvAPI) GrantEnvironment(user names.UserTag, access string) error {
+	f.accessUser = user
+	f.access = access
+	return f.err
+}
+
+func (f *fakeEnvAPI) RevokeEnvironment(user names.UserTag, access string) error {
+	f.accessUser = user
+	f.access = access
+	return f.err
+}
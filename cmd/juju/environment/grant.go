@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const grantEnvHelpDoc = `
+Grant a user read, write or admin access to the current environment.
+
+Users with read access may see the environment, but not change it.
+Users with write access may also change the environment, but not who
+may access it. Users with admin access may do anything with the
+environment, including granting and revoking access to it.
+
+If the user does not already have access to the environment, it is
+shared with them.
+
+Examples:
+ juju environment grant joe read
+     Give local user "joe" read-only access to the current environment
+
+ juju environment grant sam@ubuntuone write --environment myenv
+     Give remote user "sam@ubuntuone" write access to the environment
+     named "myenv"
+
+See Also:
+    juju environment revoke
+    juju environment users
+`
+
+// validAccess holds the levels of access that may be granted or
+// revoked.
+var validAccess = []string{"read", "write", "admin"}
+
+func checkAccess(access string) error {
+	for _, valid := range validAccess {
+		if access == valid {
+			return nil
+		}
+	}
+	return errors.Errorf("invalid access level %q, expected one of %s", access, strings.Join(validAccess, ", "))
+}
+
+func newGrantCommand() cmd.Command {
+	return envcmd.Wrap(&grantCommand{})
+}
+
+// grantCommand gives a user a level of access to an environment.
+type grantCommand struct {
+	envcmd.EnvCommandBase
+	api GrantEnvironmentAPI
+
+	User   names.UserTag
+	Access string
+}
+
+// Info implements Command.Info.
+func (c *grantCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "grant",
+		Args:    "<user> <read|write|admin>",
+		Purpose: "grant a user access to the current environment",
+		Doc:     strings.TrimSpace(grantEnvHelpDoc),
+	}
+}
+
+func (c *grantCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no user specified")
+	}
+	if len(args) == 1 {
+		return errors.New("no access level specified")
+	}
+	if !names.IsValidUser(args[0]) {
+		return errors.Errorf("invalid username: %q", args[0])
+	}
+	if err := checkAccess(args[1]); err != nil {
+		return err
+	}
+	c.User = names.NewUserTag(args[0])
+	c.Access = args[1]
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *grantCommand) getAPI() (GrantEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// GrantEnvironmentAPI defines the API functions used by the environment
+// grant command.
+type GrantEnvironmentAPI interface {
+	Close() error
+	GrantEnvironment(user names.UserTag, access string) error
+}
+
+func (c *grantCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return block.ProcessBlockedError(client.GrantEnvironment(c.User, c.Access), block.BlockChange)
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type grantSuite struct {
+	fakeEnvSuite
+}
+
+var _ = gc.Suite(&grantSuite{})
+
+func (s *grantSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command, _ := environment.NewGrantCommand(s.fake)
+	return testing.RunCommand(c, command, args...)
+}
+
+func (s *grantSuite) TestInit(c *gc.C) {
+	wrappedCmd, grantCmd := environment.NewGrantCommand(s.fake)
+	err := testing.InitCommand(wrappedCmd, []string{})
+	c.Assert(err, gc.ErrorMatches, "no user specified")
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob"})
+	c.Assert(err, gc.ErrorMatches, "no access level specified")
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob@local", "write"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(grantCmd.User, gc.Equals, names.NewUserTag("bob@local"))
+	c.Assert(grantCmd.Access, gc.Equals, "write")
+
+	err = testing.InitCommand(wrappedCmd, []string{"not valid/0", "read"})
+	c.Assert(err, gc.ErrorMatches, `invalid username: "not valid/0"`)
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob", "superuser"})
+	c.Assert(err, gc.ErrorMatches, `invalid access level "superuser", expected one of read, write, admin`)
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob", "read", "extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *grantSuite) TestPassesValues(c *gc.C) {
+	_, err := s.run(c, "sam", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.accessUser, gc.Equals, names.NewUserTag("sam"))
+	c.Assert(s.fake.access, gc.Equals, "admin")
+}
+
+func (s *grantSuite) TestBlockGrant(c *gc.C) {
+	s.fake.err = &params.Error{Code: params.CodeOperationBlocked}
+	_, err := s.run(c, "sam", "read")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
@@ -174,7 +174,7 @@ func getMissingEnvironmentInfoFields(values configstore.EnvironInfoData) (missin
 // switchEnvironment changes the default environment to the given name and
 // return, if set, the current default environment name.
 func switchEnvironment(envName string) (string, error) {
-	if defaultEnv := os.Getenv(osenv.JujuEnvEnvKey); defaultEnv != "" {
+	if defaultEnv := osenv.JujuEnvVar.Value(); defaultEnv != "" {
 		return "", errors.Errorf("cannot switch when %s is overriding the environment (set to %q)", osenv.JujuEnvEnvKey, defaultEnv)
 	}
 	currentEnv, err := envcmd.GetDefaultEnvironment()
@@ -0,0 +1,103 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const revokeEnvHelpDoc = `
+Revoke a user's access to the current environment.
+
+If a level of access is given, the user is left with the level below
+it: revoking admin access leaves write access, and revoking write
+access leaves read access. Revoking read access, or revoking without
+giving a level of access, stops the user accessing the environment
+at all.
+
+Examples:
+ juju environment revoke joe admin
+     Stop local user "joe" managing access to the current environment
+
+ juju environment revoke sam@ubuntuone --environment myenv
+     Stop remote user "sam@ubuntuone" accessing the environment
+     named "myenv"
+
+See Also:
+    juju environment grant
+    juju environment users
+`
+
+func newRevokeCommand() cmd.Command {
+	return envcmd.Wrap(&revokeCommand{})
+}
+
+// revokeCommand takes a level of access to an environment away from
+// a user.
+type revokeCommand struct {
+	envcmd.EnvCommandBase
+	api RevokeEnvironmentAPI
+
+	User   names.UserTag
+	Access string
+}
+
+// Info implements Command.Info.
+func (c *revokeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "revoke",
+		Args:    "<user> [read|write|admin]",
+		Purpose: "revoke a user's access to the current environment",
+		Doc:     strings.TrimSpace(revokeEnvHelpDoc),
+	}
+}
+
+func (c *revokeCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no user specified")
+	}
+	if !names.IsValidUser(args[0]) {
+		return errors.Errorf("invalid username: %q", args[0])
+	}
+	c.User = names.NewUserTag(args[0])
+	if len(args) > 1 {
+		if err := checkAccess(args[1]); err != nil {
+			return err
+		}
+		c.Access = args[1]
+		return cmd.CheckEmpty(args[2:])
+	}
+	return nil
+}
+
+func (c *revokeCommand) getAPI() (RevokeEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// RevokeEnvironmentAPI defines the API functions used by the environment
+// revoke command.
+type RevokeEnvironmentAPI interface {
+	Close() error
+	RevokeEnvironment(user names.UserTag, access string) error
+}
+
+func (c *revokeCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return block.ProcessBlockedError(client.RevokeEnvironment(c.User, c.Access), block.BlockChange)
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type revokeSuite struct {
+	fakeEnvSuite
+}
+
+var _ = gc.Suite(&revokeSuite{})
+
+func (s *revokeSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command, _ := environment.NewRevokeCommand(s.fake)
+	return testing.RunCommand(c, command, args...)
+}
+
+func (s *revokeSuite) TestInit(c *gc.C) {
+	wrappedCmd, revokeCmd := environment.NewRevokeCommand(s.fake)
+	err := testing.InitCommand(wrappedCmd, []string{})
+	c.Assert(err, gc.ErrorMatches, "no user specified")
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob@local"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revokeCmd.User, gc.Equals, names.NewUserTag("bob@local"))
+	c.Assert(revokeCmd.Access, gc.Equals, "")
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob@local", "admin"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revokeCmd.Access, gc.Equals, "admin")
+
+	err = testing.InitCommand(wrappedCmd, []string{"not valid/0"})
+	c.Assert(err, gc.ErrorMatches, `invalid username: "not valid/0"`)
+
+	err = testing.InitCommand(wrappedCmd, []string{"bob", "superuser"})
+	c.Assert(err, gc.ErrorMatches, `invalid access level "superuser", expected one of read, write, admin`)
+}
+
+func (s *revokeSuite) TestPassesValues(c *gc.C) {
+	_, err := s.run(c, "sam", "write")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.accessUser, gc.Equals, names.NewUserTag("sam"))
+	c.Assert(s.fake.access, gc.Equals, "write")
+}
+
+func (s *revokeSuite) TestBlockRevoke(c *gc.C) {
+	s.fake.err = &params.Error{Code: params.CodeOperationBlocked}
+	_, err := s.run(c, "sam")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
@@ -5,10 +5,14 @@ package environment
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"github.com/juju/utils/keyvalues"
+	"gopkg.in/yaml.v2"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
@@ -22,13 +26,24 @@ type attributes map[string]interface{}
 
 type setCommand struct {
 	envcmd.EnvCommandBase
-	api    SetEnvironmentAPI
-	values attributes
+	api        SetEnvironmentAPI
+	values     attributes
+	configFile cmd.FileVar
+	dryRun     bool
 }
 
 const setEnvHelpDoc = `
 Updates the environment of a running Juju instance.  Multiple key/value pairs
 can be passed on as command line arguments.
+
+Alternatively, --config names a yaml file of environment settings, such as
+the output of "juju environment get --format yaml". Only the settings that
+differ from the current environment configuration are changed, and they are
+all changed at once.
+
+With --dry-run, the settings that would change are shown, along with their
+current and new values, and nothing is changed. To return settings to their
+default values, use "juju environment unset".
 `
 
 func (c *setCommand) Info() *cmd.Info {
@@ -40,7 +55,18 @@ func (c *setCommand) Info() *cmd.Info {
 	}
 }
 
+func (c *setCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.Var(&c.configFile, "config", "path to yaml-formatted environment config")
+	f.BoolVar(&c.dryRun, "dry-run", false, "don't change anything, just show what would change")
+}
+
 func (c *setCommand) Init(args []string) (err error) {
+	if c.configFile.Path != "" {
+		if len(args) > 0 {
+			return errors.New("cannot specify --config when using key=value arguments")
+		}
+		return nil
+	}
 	if len(args) == 0 {
 		return fmt.Errorf("no key, value pairs specified")
 	}
@@ -86,6 +112,18 @@ func (c *setCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.configFile.Path != "" {
+		values, err := c.readConfigFile(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Only change the settings that differ, so that the
+		// output of "environment get" may be edited and set.
+		c.values = changedValues(envAttrs, values)
+		if _, ok := c.values["agent-version"]; ok {
+			return errors.New("agent-version must be set via upgrade-juju")
+		}
+	}
 	for key := range c.values {
 		// check if the key exists in the existing env config
 		// and warn the user if the key is not defined in
@@ -95,5 +133,66 @@ func (c *setCommand) Run(ctx *cmd.Context) error {
 		}
 
 	}
+	if c.dryRun {
+		writeChanges(ctx, envAttrs, changedValues(envAttrs, c.values))
+		return nil
+	}
+	if len(c.values) == 0 {
+		ctx.Infof("no changes to the environment configuration")
+		return nil
+	}
 	return block.ProcessBlockedError(client.EnvironmentSet(c.values), block.BlockChange)
 }
+
+// readConfigFile reads the environment settings from the file
+// given with --config.
+func (c *setCommand) readConfigFile(ctx *cmd.Context) (attributes, error) {
+	data, err := c.configFile.Read(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var values attributes
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.Annotatef(err, "cannot parse %q", c.configFile.Path)
+	}
+	if len(values) == 0 {
+		return nil, errors.Errorf("no settings found in %q", c.configFile.Path)
+	}
+	return values, nil
+}
+
+// changedValues returns the values that are not set, or are set
+// differently, in the current environment configuration. Values are
+// compared by their formatted representation, as those read from
+// the API and from yaml may differ in type.
+func changedValues(current, values attributes) attributes {
+	changed := make(attributes)
+	for key, value := range values {
+		if old, ok := current[key]; ok && fmt.Sprint(old) == fmt.Sprint(value) {
+			continue
+		}
+		changed[key] = value
+	}
+	return changed
+}
+
+// writeChanges writes the current and new values of the changed
+// settings, in key order.
+func writeChanges(ctx *cmd.Context, current, changed attributes) {
+	if len(changed) == 0 {
+		fmt.Fprintln(ctx.Stdout, "no changes")
+		return
+	}
+	keys := make([]string, 0, len(changed))
+	for key := range changed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		old, ok := current[key]
+		if !ok {
+			old = "(not set)"
+		}
+		fmt.Fprintf(ctx.Stdout, "%s: %v -> %v\n", key, old, changed[key])
+	}
+}
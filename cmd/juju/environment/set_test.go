@@ -4,6 +4,9 @@
 package environment_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
+
 	"github.com/juju/cmd"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -40,6 +43,9 @@ func (s *SetSuite) TestInit(c *gc.C) {
 		}, {
 			args:       []string{"agent-version=2.0.0"},
 			errorMatch: "agent-version must be set via upgrade-juju",
+		}, {
+			args:       []string{"--config", "env.yaml", "special=extra"},
+			errorMatch: "cannot specify --config when using key=value arguments",
 		},
 	} {
 		c.Logf("test %d", i)
@@ -74,3 +80,63 @@ func (s *SetSuite) TestBlockedError(c *gc.C) {
 	// msg is logged
 	c.Check(c.GetTestLog(), jc.Contains, "TestBlockedError")
 }
+
+func (s *SetSuite) writeConfigFile(c *gc.C, content string) string {
+	path := filepath.Join(c.MkDir(), "env.yaml")
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *SetSuite) TestSetFromConfigFile(c *gc.C) {
+	path := s.writeConfigFile(c, `
+name: test-env
+special: new value
+running: true
+extra: 5
+`)
+	_, err := s.run(c, "--config", path)
+	c.Assert(err, jc.ErrorIsNil)
+	// Only the changed settings are passed on.
+	c.Assert(s.fake.values, jc.DeepEquals, map[string]interface{}{
+		"special": "new value",
+		"extra":   5,
+	})
+}
+
+func (s *SetSuite) TestSetFromConfigFileNoChanges(c *gc.C) {
+	path := s.writeConfigFile(c, "name: test-env\nrunning: true\n")
+	ctx, err := s.run(c, "--config", path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "no changes to the environment configuration\n")
+	c.Assert(s.fake.values["special"], gc.Equals, "special value")
+}
+
+func (s *SetSuite) TestSetFromConfigFileAgentVersion(c *gc.C) {
+	path := s.writeConfigFile(c, "agent-version: 2.0.0\n")
+	_, err := s.run(c, "--config", path)
+	c.Assert(err, gc.ErrorMatches, "agent-version must be set via upgrade-juju")
+}
+
+func (s *SetSuite) TestSetFromConfigFileEmpty(c *gc.C) {
+	path := s.writeConfigFile(c, "")
+	_, err := s.run(c, "--config", path)
+	c.Assert(err, gc.ErrorMatches, `no settings found in ".*env.yaml"`)
+}
+
+func (s *SetSuite) TestDryRun(c *gc.C) {
+	ctx, err := s.run(c, "--dry-run", "special=extra", "running=true", "unknown=foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"special: special value -> extra\n"+
+		"unknown: (not set) -> foo\n",
+	)
+	c.Assert(s.fake.values["special"], gc.Equals, "special value")
+}
+
+func (s *SetSuite) TestDryRunNoChanges(c *gc.C) {
+	path := s.writeConfigFile(c, "special: special value\n")
+	ctx, err := s.run(c, "--dry-run", "--config", path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "no changes\n")
+}
@@ -43,12 +43,14 @@ func (s *UsersCommandSuite) SetUpTest(c *gc.C) {
 			CreatedBy:      "admin@local",
 			DateCreated:    time.Date(2014, 7, 20, 9, 0, 0, 0, time.UTC),
 			LastConnection: &last1,
+			Access:         "admin",
 		}, {
 			UserName:       "bob@local",
 			DisplayName:    "Bob",
 			CreatedBy:      "admin@local",
 			DateCreated:    time.Date(2015, 2, 15, 9, 0, 0, 0, time.UTC),
 			LastConnection: &last2,
+			Access:         "write",
 		}, {
 			UserName:    "charlie@ubuntu.com",
 			DisplayName: "Charlie",
@@ -64,10 +66,10 @@ func (s *UsersCommandSuite) TestEnvUsers(c *gc.C) {
 	context, err := testing.RunCommand(c, environment.NewUsersCommand(s.fake), "-e", "dummyenv")
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(testing.Stdout(context), gc.Equals, ""+
-		"NAME                DATE CREATED  LAST CONNECTION\n"+
-		"admin@local         2014-07-20    2015-03-20\n"+
-		"bob@local           2015-02-15    2015-03-01\n"+
-		"charlie@ubuntu.com  2015-02-15    never connected\n"+
+		"NAME                ACCESS  DATE CREATED  LAST CONNECTION\n"+
+		"admin@local         admin   2014-07-20    2015-03-20\n"+
+		"bob@local           write   2015-02-15    2015-03-01\n"+
+		"charlie@ubuntu.com  admin   2015-02-15    never connected\n"+
 		"\n")
 }
 
@@ -75,9 +77,9 @@ func (s *UsersCommandSuite) TestEnvUsersFormatJson(c *gc.C) {
 	context, err := testing.RunCommand(c, environment.NewUsersCommand(s.fake), "-e", "dummyenv", "--format", "json")
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(testing.Stdout(context), gc.Equals, "["+
-		`{"user-name":"admin@local","date-created":"2014-07-20","last-connection":"2015-03-20"},`+
-		`{"user-name":"bob@local","date-created":"2015-02-15","last-connection":"2015-03-01"},`+
-		`{"user-name":"charlie@ubuntu.com","date-created":"2015-02-15","last-connection":"never connected"}`+
+		`{"user-name":"admin@local","access":"admin","date-created":"2014-07-20","last-connection":"2015-03-20"},`+
+		`{"user-name":"bob@local","access":"write","date-created":"2015-02-15","last-connection":"2015-03-01"},`+
+		`{"user-name":"charlie@ubuntu.com","access":"admin","date-created":"2015-02-15","last-connection":"never connected"}`+
 		"]\n")
 }
 
@@ -86,12 +88,15 @@ func (s *UsersCommandSuite) TestUserInfoFormatYaml(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(testing.Stdout(context), gc.Equals, ""+
 		"- user-name: admin@local\n"+
+		"  access: admin\n"+
 		"  date-created: 2014-07-20\n"+
 		"  last-connection: 2015-03-20\n"+
 		"- user-name: bob@local\n"+
+		"  access: write\n"+
 		"  date-created: 2015-02-15\n"+
 		"  last-connection: 2015-03-01\n"+
 		"- user-name: charlie@ubuntu.com\n"+
+		"  access: admin\n"+
 		"  date-created: 2015-02-15\n"+
 		"  last-connection: never connected\n")
 }
@@ -95,6 +95,37 @@ spaces
    EC2 is the only provider supporting for spaces constraints. Support for other
    provides is planned for future releases.
 
+zones
+   Zones defines the list of availability zones, separated by commas, that the
+   machine may be started in. How machines are placed within those zones is
+   set by the availability-zone-policy environment setting. Zones are
+   currently supported by the EC2, OpenStack and MaaS environments.
+
+   Example: zones=us-east-1a,us-east-1c
+
+root-disk-source
+   Root-disk-source names the provider-specific storage that the machine's root
+   disk must be created on, for example a datastore on vSphere. It is currently
+   only supported by the vSphere environment.
+
+   Example: root-disk-source=fast-ssd
+
+virt-type
+   Virt-type defines the kind of virtualisation the machine must use. The valid
+   values depend on the provider; EC2 supports hvm and pv. Specifying this
+   constraint may conflict with instance-type, as the instance type determines
+   the virtualisation type. Virt-type is currently supported by the EC2 and GCE
+   environments.
+
+   Example: virt-type=hvm
+
+instance-role
+   Instance-role names a provider-specific role or profile that grants the
+   machine access to cloud resources, for example an IAM instance profile on
+   EC2. It is currently only supported by the EC2 environment.
+
+   Example: instance-role=juju-web
+
 instance-type
    Instance-type is the provider-specific name of a type of machine to deploy,
    for example m1.small on EC2 or A4 on Azure.  Specifying this constraint may
@@ -5,6 +5,7 @@ package machine
 
 import (
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/juju/cmd"
@@ -56,6 +57,12 @@ and bringing it under Juju's management; currently this requires that the
 machine be running Ubuntu, that it be accessible via SSH, and be running on
 the same network as the API server.
 
+Many existing machines can be provisioned at once by listing them, one
+[user@]host per line, in a file passed with --hosts-file. The hosts are
+provisioned concurrently, and must be reachable by SSH without a password
+prompt; blank lines and lines starting with "#" are ignored. Each host is
+reported on, and the command fails if any host could not be added.
+
 It is possible to override or augment constraints by passing provider-specific
 "placement directives" as an argument; these give the provider additional
 information about how to allocate the machine. For example, one can direct the
@@ -70,6 +77,7 @@ Examples:
    juju machine add lxc:4                (starts a new lxc container on machine 4)
    juju machine add --constraints mem=8G (starts a machine with at least 8GB RAM)
    juju machine add ssh:user@10.10.0.3   (manually provisions a machine with ssh)
+   juju machine add --hosts-file hosts   (manually provisions each machine listed in hosts)
    juju machine add zone=us-east-1a      (start a machine in zone us-east-1a on AWS)
    juju machine add maas2.name           (acquire machine maas2.name on MAAS)
 
@@ -110,6 +118,8 @@ type addCommand struct {
 	NumMachines int
 	// Disks describes disks that are to be attached to the machine.
 	Disks []storage.Constraints
+	// HostsFile names a file listing hosts to be manually provisioned.
+	HostsFile string
 }
 
 func (c *addCommand) Info() *cmd.Info {
@@ -126,6 +136,7 @@ func (c *addCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumMachines, "n", 1, "The number of machines to add")
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "additional machine constraints")
 	f.Var(disksFlag{&c.Disks}, "disks", "constraints for disks to attach to the machine")
+	f.StringVar(&c.HostsFile, "hosts-file", "", "manually provision the [user@]hosts listed in this file, one per line")
 }
 
 func (c *addCommand) Init(args []string) error {
@@ -136,6 +147,15 @@ func (c *addCommand) Init(args []string) error {
 	if err != nil {
 		return err
 	}
+	if c.HostsFile != "" {
+		if placement != "" {
+			return fmt.Errorf("cannot use --hosts-file when specifying a placement directive")
+		}
+		if c.NumMachines != 1 {
+			return fmt.Errorf("cannot use -n when specifying --hosts-file")
+		}
+		return nil
+	}
 	c.Placement, err = instance.ParsePlacement(placement)
 	if err == instance.ErrPlacementScopeMissing {
 		placement = "env-uuid" + ":" + placement
@@ -166,7 +186,10 @@ type MachineManagerAPI interface {
 	Close() error
 }
 
-var manualProvisioner = manual.ProvisionMachine
+var (
+	manualProvisioner = manual.ProvisionMachine
+	manualEnlister    = manual.EnlistMachines
+)
 
 func (c *addCommand) getClientAPI() (AddMachineAPI, error) {
 	if c.api != nil {
@@ -237,6 +260,10 @@ func (c *addCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 
+	if c.HostsFile != "" {
+		return c.enlistHosts(ctx, client, config)
+	}
+
 	logger.Infof("environment provisioning")
 	if c.Placement != nil && c.Placement.Scope == "env-uuid" {
 		c.Placement.Scope = client.EnvironmentUUID()
@@ -339,3 +366,55 @@ func (c *addCommand) Run(ctx *cmd.Context) error {
 	}
 	return nil
 }
+
+// enlistHosts manually provisions the hosts listed in the hosts file.
+func (c *addCommand) enlistHosts(ctx *cmd.Context, client AddMachineAPI, config *config.Config) error {
+	hosts, err := readHostsFile(ctx.AbsPath(c.HostsFile))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(hosts) == 0 {
+		return errors.Errorf("no hosts listed in %q", c.HostsFile)
+	}
+	logger.Infof("manual provisioning %d hosts", len(hosts))
+	results := manualEnlister(manual.EnlistMachinesArgs{
+		ProvisionMachineArgs: manual.ProvisionMachineArgs{
+			Client: client,
+			Stderr: ctx.Stderr,
+			UpdateBehavior: &params.UpdateBehavior{
+				config.EnableOSRefreshUpdate(),
+				config.EnableOSUpgrade(),
+			},
+		},
+		Hosts: hosts,
+	})
+	fmt.Fprint(ctx.Stderr, manual.EnlistSummary(results))
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("failed to enlist %d of %d hosts", failed, len(results))
+	}
+	return nil
+}
+
+// readHostsFile returns the hosts listed in the named file, one per
+// line, ignoring blank lines and comments.
+func readHostsFile(filename string) ([]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read hosts file")
+	}
+	var hosts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, line)
+	}
+	return hosts, nil
+}
@@ -4,6 +4,8 @@
 package machine_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -92,6 +94,15 @@ func (s *AddMachineSuite) TestInit(c *gc.C) {
 			args:      []string{"something:special"},
 			count:     1,
 			placement: "something:special",
+		}, {
+			args:  []string{"--hosts-file", "hosts"},
+			count: 1,
+		}, {
+			args:        []string{"--hosts-file", "hosts", "ssh:10.10.0.3"},
+			errorString: "cannot use --hosts-file when specifying a placement directive",
+		}, {
+			args:        []string{"--hosts-file", "hosts", "-n", "2"},
+			errorString: "cannot use -n when specifying --hosts-file",
 		},
 	} {
 		c.Logf("test %d", i)
@@ -149,6 +160,53 @@ func (s *AddMachineSuite) TestSSHPlacementError(c *gc.C) {
 	c.Assert(testing.Stderr(context), gc.Equals, "")
 }
 
+func (s *AddMachineSuite) writeHostsFile(c *gc.C, content string) string {
+	path := filepath.Join(c.MkDir(), "hosts")
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *AddMachineSuite) TestHostsFile(c *gc.C) {
+	var enlistArgs manual.EnlistMachinesArgs
+	s.PatchValue(machine.ManualEnlister, func(args manual.EnlistMachinesArgs) []manual.EnlistResult {
+		enlistArgs = args
+		return []manual.EnlistResult{
+			{Host: "10.1.2.3", MachineId: "1"},
+			{Host: "ubuntu@10.1.2.4", MachineId: "2"},
+		}
+	})
+	path := s.writeHostsFile(c, "# racked 2015-09-01\n10.1.2.3\n\n  ubuntu@10.1.2.4  \n")
+	context, err := s.run(c, "--hosts-file", path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(enlistArgs.Hosts, jc.DeepEquals, []string{"10.1.2.3", "ubuntu@10.1.2.4"})
+	c.Assert(enlistArgs.Client, gc.Equals, s.fakeAddMachine)
+	c.Assert(testing.Stderr(context), gc.Equals, `
+created machine 1 for 10.1.2.3
+created machine 2 for ubuntu@10.1.2.4
+enlisted 2 of 2 hosts
+`[1:])
+}
+
+func (s *AddMachineSuite) TestHostsFileFailures(c *gc.C) {
+	s.PatchValue(machine.ManualEnlister, func(args manual.EnlistMachinesArgs) []manual.EnlistResult {
+		return []manual.EnlistResult{
+			{Host: "10.1.2.3", MachineId: "1"},
+			{Host: "10.1.2.4", Error: manual.ErrProvisioned},
+		}
+	})
+	path := s.writeHostsFile(c, "10.1.2.3\n10.1.2.4\n")
+	context, err := s.run(c, "--hosts-file", path)
+	c.Assert(err, gc.ErrorMatches, "failed to enlist 1 of 2 hosts")
+	c.Assert(testing.Stderr(context), jc.Contains, "failed to enlist 10.1.2.4: machine is already provisioned\n")
+}
+
+func (s *AddMachineSuite) TestHostsFileEmpty(c *gc.C) {
+	path := s.writeHostsFile(c, "# nothing yet\n")
+	_, err := s.run(c, "--hosts-file", path)
+	c.Assert(err, gc.ErrorMatches, `no hosts listed in ".*hosts"`)
+}
+
 func (s *AddMachineSuite) TestParamsPassedOn(c *gc.C) {
 	_, err := s.run(c, "--constraints", "mem=8G", "--series=special", "zone=nz")
 	c.Assert(err, jc.ErrorIsNil)
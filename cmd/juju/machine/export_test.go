@@ -12,6 +12,7 @@ import (
 
 var (
 	ManualProvisioner = &manualProvisioner
+	ManualEnlister    = &manualEnlister
 )
 
 type AddCommand struct {
@@ -12,7 +12,6 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
-	"github.com/juju/utils/featureflag"
 	"github.com/juju/utils/set"
 
 	"github.com/juju/juju/api"
@@ -20,6 +19,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju/osenv/featureflag"
 )
 
 // SpaceAPI defines the necessary API methods needed by the space
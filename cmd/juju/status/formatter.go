@@ -170,6 +170,7 @@ func (sf *statusFormatter) formatUnit(info unitFormatInfo) unitStatus {
 	out := unitStatus{
 		WorkloadStatusInfo: sf.getWorkloadStatusInfo(info.unit),
 		AgentStatusInfo:    sf.getAgentStatusInfo(info.unit),
+		WorkloadVersion:    info.unit.WorkloadVersion,
 		Machine:            info.unit.Machine,
 		OpenedPorts:        info.unit.OpenedPorts,
 		PublicAddress:      info.unit.PublicAddress,
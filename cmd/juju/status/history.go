@@ -5,8 +5,6 @@ package status
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -72,11 +70,8 @@ func (c *statusHistoryCommand) Init(args []string) error {
 	// check env var.
 	if !c.isoTime {
 		var err error
-		envVarValue := os.Getenv(osenv.JujuStatusIsoTimeEnvKey)
-		if envVarValue != "" {
-			if c.isoTime, err = strconv.ParseBool(envVarValue); err != nil {
-				return errors.Annotatef(err, "invalid %s env var, expected true|false", osenv.JujuStatusIsoTimeEnvKey)
-			}
+		if c.isoTime, err = osenv.JujuStatusIsoTimeVar.Bool(); err != nil {
+			return errors.Annotatef(err, "invalid %s env var, expected true|false", osenv.JujuStatusIsoTimeEnvKey)
 		}
 	}
 	kind := params.HistoryKind(c.outputContent)
@@ -111,6 +111,33 @@ func FormatTabular(value interface{}) ([]byte, error) {
 	return out.Bytes(), nil
 }
 
+// formatRelationsTabular returns a tabular summary of the relations
+// between services, listing each service endpoint and the services
+// related to it.
+func formatRelationsTabular(fs formattedStatus) []byte {
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	p := func(values ...interface{}) {
+		for _, v := range values {
+			fmt.Fprintf(tw, "%s\t", v)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	p("\n[Relations]")
+	p("SERVICE\tENDPOINT\tRELATED")
+	for _, svcName := range common.SortStringsNaturally(stringKeysFromMap(fs.Services)) {
+		svc := fs.Services[svcName]
+		for _, endpoint := range common.SortStringsNaturally(stringKeysFromMap(svc.Relations)) {
+			related := common.SortStringsNaturally(svc.Relations[endpoint])
+			p(svcName, endpoint, strings.Join(related, ","))
+		}
+	}
+	tw.Flush()
+
+	return out.Bytes()
+}
+
 // agentDoing returns what hook or action, if any,
 // the agent is currently executing.
 // The hook name or action is extracted from the agent message.
@@ -5,26 +5,48 @@ package status
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/state/multiwatcher"
 )
 
 var logger = loggo.GetLogger("juju.cmd.juju.status")
 
 type statusAPI interface {
 	Status(patterns []string) (*params.FullStatus, error)
+	WatchAll() (allWatcher, error)
 	Close() error
 }
 
+// allWatcher is the part of the API's AllWatcher that the status
+// command uses to wait for changes to the environment.
+type allWatcher interface {
+	Next() ([]multiwatcher.Delta, error)
+	Stop() error
+}
+
+// statusClient adapts an api.Client to the statusAPI interface.
+type statusClient struct {
+	*api.Client
+}
+
+// WatchAll is part of the statusAPI interface.
+func (c statusClient) WatchAll() (allWatcher, error) {
+	w, err := c.Client.WatchAll()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
 // NewStatusCommand returns a new command, which reports on the
 // runtime state of various system entities.
 func NewStatusCommand() cmd.Command {
@@ -33,10 +55,12 @@ func NewStatusCommand() cmd.Command {
 
 type statusCommand struct {
 	envcmd.EnvCommandBase
-	out      cmd.Output
-	patterns []string
-	isoTime  bool
-	api      statusAPI
+	out       cmd.Output
+	patterns  []string
+	isoTime   bool
+	relations bool
+	watch     bool
+	api       statusAPI
 }
 
 var statusDoc = `
@@ -68,6 +92,12 @@ will be displayed.
 Wildcards ('*') may be specified in service/unit names to match any sequence
 of characters. For example, 'nova-*' will match any service whose name begins
 with 'nova-': 'nova-compute', 'nova-volume', etc.
+
+The --relations option adds a Relations section to the tabular output; the
+yaml and json formats always include relations.
+
+The --watch option keeps the command running, and writes the status again
+each time the environment changes, until interrupted.
 `
 
 func (c *statusCommand) Info() *cmd.Info {
@@ -82,6 +112,8 @@ func (c *statusCommand) Info() *cmd.Info {
 
 func (c *statusCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.isoTime, "utc", false, "display time as UTC in RFC3339 format")
+	f.BoolVar(&c.relations, "relations", false, "display relations in tabular format")
+	f.BoolVar(&c.watch, "watch", false, "write the status again whenever the environment changes")
 
 	oneLineFormatter := FormatOneline
 	defaultFormat := "yaml"
@@ -96,7 +128,7 @@ func (c *statusCommand) SetFlags(f *gnuflag.FlagSet) {
 		"short":   oneLineFormatter,
 		"oneline": oneLineFormatter,
 		"line":    oneLineFormatter,
-		"tabular": FormatTabular,
+		"tabular": c.formatTabular,
 		"summary": FormatSummary,
 	})
 }
@@ -107,11 +139,8 @@ func (c *statusCommand) Init(args []string) error {
 	// check env var.
 	if !c.isoTime {
 		var err error
-		envVarValue := os.Getenv(osenv.JujuStatusIsoTimeEnvKey)
-		if envVarValue != "" {
-			if c.isoTime, err = strconv.ParseBool(envVarValue); err != nil {
-				return errors.Annotatef(err, "invalid %s env var, expected true|false", osenv.JujuStatusIsoTimeEnvKey)
-			}
+		if c.isoTime, err = osenv.JujuStatusIsoTimeVar.Bool(); err != nil {
+			return errors.Annotatef(err, "invalid %s env var, expected true|false", osenv.JujuStatusIsoTimeEnvKey)
 		}
 	}
 	return nil
@@ -125,7 +154,11 @@ Error details:
 `
 
 var newApiClientForStatus = func(c *statusCommand) (statusAPI, error) {
-	return c.NewAPIClient()
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return statusClient{client}, nil
 }
 
 func (c *statusCommand) Run(ctx *cmd.Context) error {
@@ -135,6 +168,34 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 	}
 	defer apiclient.Close()
 
+	if !c.watch {
+		return c.writeStatus(ctx, apiclient)
+	}
+
+	watcher, err := apiclient.WatchAll()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch environment")
+	}
+	defer watcher.Stop()
+	// The first call to Next returns the current state of the
+	// environment, which is reported by the first status below.
+	if _, err := watcher.Next(); err != nil {
+		return errors.Annotate(err, "cannot watch environment")
+	}
+	for {
+		if err := c.writeStatus(ctx, apiclient); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := watcher.Next(); err != nil {
+			return errors.Annotate(err, "cannot watch environment")
+		}
+		fmt.Fprintln(ctx.Stdout)
+	}
+}
+
+// writeStatus fetches the status of the entities matching the
+// command's patterns, and writes it in the requested format.
+func (c *statusCommand) writeStatus(ctx *cmd.Context, apiclient statusAPI) error {
 	status, err := apiclient.Status(c.patterns)
 	if err != nil {
 		if status == nil {
@@ -151,3 +212,13 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 	formatted := formatter.format()
 	return c.out.Write(ctx, formatted)
 }
+
+// formatTabular returns the tabular status produced by FormatTabular,
+// followed by the relations between services if they were requested.
+func (c *statusCommand) formatTabular(value interface{}) ([]byte, error) {
+	out, err := FormatTabular(value)
+	if err != nil || !c.relations {
+		return out, err
+	}
+	return append(out, formatRelationsTabular(value.(formattedStatus))...), nil
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v6-unstable"
@@ -2312,6 +2313,60 @@ var statusTests = []testCase{
 			},
 		},
 	),
+	test( // 19
+		"unit with workload version",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		setAddresses{"0", network.NewAddresses("dummyenv-0.dns")},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", state.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		setAddresses{"1", network.NewAddresses("dummyenv-1.dns")},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", state.StatusStarted, ""},
+		addCharm{"mysql"},
+		addService{name: "mysql", charm: "mysql"},
+		setServiceExposed{"mysql", true},
+		addAliveUnit{"mysql", "1"},
+		setUnitCharmURL{"mysql/0", "cs:quantal/mysql-1"},
+		setUnitWorkloadVersion{"mysql/0", "5.7.9"},
+
+		expect{
+			"the unit's workload version is shown",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "cs:quantal/mysql-1",
+						"exposed": true,
+						"service-status": M{
+							"current": "active",
+							"since":   "01 Apr 15 01:23+10:00",
+						},
+						"units": M{
+							"mysql/0": M{
+								"machine":     "1",
+								"agent-state": "started",
+								"workload-status": M{
+									"current": "active",
+									"since":   "01 Apr 15 01:23+10:00",
+								},
+								"agent-status": M{
+									"current": "idle",
+									"since":   "01 Apr 15 01:23+10:00",
+								},
+								"workload-version": "5.7.9",
+								"public-address":   "dummyenv-1.dns",
+							},
+						},
+					},
+				},
+			},
+		},
+	),
 }
 
 // TODO(dfc) test failing components by destructively mutating the state under the hood
@@ -2626,6 +2681,18 @@ func (sus setAgentStatus) step(c *gc.C, ctx *context) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+type setUnitWorkloadVersion struct {
+	unitName string
+	version  string
+}
+
+func (swv setUnitWorkloadVersion) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(swv.unitName)
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.SetWorkloadVersion(swv.version)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 type setUnitCharmURL struct {
 	unitName string
 	charm    string
@@ -2839,6 +2906,7 @@ type fakeApiClient struct {
 	statusReturn *params.FullStatus
 	patternsUsed []string
 	closeCalled  bool
+	watcher      *fakeAllWatcher
 }
 
 func newFakeApiClient(statusReturn *params.FullStatus) fakeApiClient {
@@ -2852,11 +2920,54 @@ func (a *fakeApiClient) Status(patterns []string) (*params.FullStatus, error) {
 	return a.statusReturn, nil
 }
 
+func (a *fakeApiClient) WatchAll() (allWatcher, error) {
+	return a.watcher, nil
+}
+
 func (a *fakeApiClient) Close() error {
 	a.closeCalled = true
 	return nil
 }
 
+// fakeAllWatcher reports a change to the environment each time
+// Next is called, until it runs out of changes.
+type fakeAllWatcher struct {
+	changes    int
+	stopCalled bool
+}
+
+func (w *fakeAllWatcher) Next() ([]multiwatcher.Delta, error) {
+	if w.changes == 0 {
+		return nil, errors.New("watcher was stopped")
+	}
+	w.changes--
+	return []multiwatcher.Delta{}, nil
+}
+
+func (w *fakeAllWatcher) Stop() error {
+	w.stopCalled = true
+	return nil
+}
+
+func (s *StatusSuite) TestStatusWatch(c *gc.C) {
+	client := newFakeApiClient(&params.FullStatus{
+		EnvironmentName: "dummyenv",
+	})
+	// The initial state of the environment, followed by one change.
+	client.watcher = &fakeAllWatcher{changes: 2}
+	s.PatchValue(&newApiClientForStatus, func(_ *statusCommand) (statusAPI, error) {
+		return &client, nil
+	})
+
+	code, stdout, stderr := runStatus(c, "--format", "json", "--watch")
+	c.Check(code, gc.Equals, 1)
+	c.Check(string(stderr), gc.Equals, "error: cannot watch environment: watcher was stopped\n")
+	const status = `{"environment":"dummyenv","machines":{},"services":{}}` + "\n"
+	c.Check(string(stdout), gc.Equals, status+"\n"+status)
+	c.Check(client.watcher.stopCalled, jc.IsTrue)
+	c.Check(client.closeCalled, jc.IsTrue)
+}
+
 // Check that the client works with an older server which doesn't
 // return the top level Relations field nor the unit and machine level
 // Agent field (they were introduced at the same time).
@@ -3239,6 +3350,41 @@ func (s *StatusSuite) TestStatusWithFormatTabular(c *gc.C) {
 	s.testStatusWithFormatTabular(c, false)
 }
 
+func (s *StatusSuite) TestFormatTabularRelations(c *gc.C) {
+	status := formattedStatus{
+		Services: map[string]serviceStatus{
+			"wordpress": serviceStatus{
+				Charm: "cs:quantal/wordpress-3",
+				Relations: map[string][]string{
+					"db":      {"mysql"},
+					"logging": {"logging"},
+				},
+			},
+			"mysql": serviceStatus{
+				Charm: "cs:quantal/mysql-1",
+				Relations: map[string][]string{
+					"server": {"wordpress"},
+				},
+			},
+		},
+	}
+	command := &statusCommand{}
+	out, err := command.formatTabular(status)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(out), gc.Not(jc.Contains), "[Relations]")
+
+	command.relations = true
+	withRelations, err := command.formatTabular(status)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(withRelations), gc.Equals, string(out)+`
+[Relations] 
+SERVICE     ENDPOINT RELATED   
+mysql       server   wordpress 
+wordpress   db       mysql     
+wordpress   logging  logging   
+`)
+}
+
 func (s *StatusSuite) TestFormatTabularHookActionName(c *gc.C) {
 	status := formattedStatus{
 		Services: map[string]serviceStatus{
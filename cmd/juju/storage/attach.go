@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+func newAttachCommand() cmd.Command {
+	return envcmd.Wrap(&attachCommand{})
+}
+
+const (
+	attachCommandDoc = `
+Attach existing storage instances to a unit. The storage instances must
+previously have been detached from their units with "juju storage detach",
+and the unit's charm must declare storage with the same name and kind.
+
+Example:
+    Attach storage instance "data/0", detached from unit u/0, to unit u/1:
+
+      juju storage attach u/1 data/0
+`
+	attachCommandArgs = `<unit name> <storage id> ...`
+)
+
+// attachCommand attaches detached storage instances to a unit.
+type attachCommand struct {
+	StorageCommandBase
+	unitTag     string
+	storageTags []string
+	api         StorageAttachAPI
+}
+
+// Init implements Command.Init.
+func (c *attachCommand) Init(args []string) (err error) {
+	c.unitTag, c.storageTags, err = parseStorageAttachmentArgs("attach", args)
+	return err
+}
+
+// Info implements Command.Info.
+func (c *attachCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "attach",
+		Purpose: "attaches detached storage to a unit",
+		Doc:     attachCommandDoc,
+		Args:    attachCommandArgs,
+	}
+}
+
+// Run implements Command.Run.
+func (c *attachCommand) Run(ctx *cmd.Context) (err error) {
+	api := c.api
+	if api == nil {
+		api, err = c.NewStorageAPI()
+		if err != nil {
+			return err
+		}
+		defer api.Close()
+	}
+	ids := storageAttachmentIds(c.unitTag, c.storageTags)
+	results, err := api.Attach(ids)
+	if err != nil {
+		return err
+	}
+	reportStorageAttachmentFailures(ctx, ids, results)
+	return nil
+}
+
+// StorageAttachAPI defines the API methods that the storage attach
+// command uses.
+type StorageAttachAPI interface {
+	Close() error
+	Attach([]params.StorageAttachmentId) ([]params.ErrorResult, error)
+}
+
+// parseStorageAttachmentArgs parses the unit name and storage IDs
+// passed to the storage attach and detach commands, returning the
+// corresponding tags.
+func parseStorageAttachmentArgs(command string, args []string) (string, []string, error) {
+	if len(args) < 2 {
+		return "", nil, errors.Errorf("storage %s requires a unit and a storage id", command)
+	}
+	u := args[0]
+	if !names.IsValidUnit(u) {
+		return "", nil, errors.NotValidf("unit name %q", u)
+	}
+	storageTags := make([]string, len(args)-1)
+	for i, id := range args[1:] {
+		if !names.IsValidStorage(id) {
+			return "", nil, errors.NotValidf("storage id %q", id)
+		}
+		storageTags[i] = names.NewStorageTag(id).String()
+	}
+	return names.NewUnitTag(u).String(), storageTags, nil
+}
+
+func storageAttachmentIds(unitTag string, storageTags []string) []params.StorageAttachmentId {
+	ids := make([]params.StorageAttachmentId, len(storageTags))
+	for i, storageTag := range storageTags {
+		ids[i] = params.StorageAttachmentId{
+			StorageTag: storageTag,
+			UnitTag:    unitTag,
+		}
+	}
+	return ids
+}
+
+// reportStorageAttachmentFailures writes any failures in results to
+// the context's stderr, identifying the storage instance by its ID.
+func reportStorageAttachmentFailures(ctx *cmd.Context, ids []params.StorageAttachmentId, results []params.ErrorResult) {
+	for i, result := range results {
+		if result.Error == nil {
+			continue
+		}
+		storageId := ids[i].StorageTag
+		if tag, err := names.ParseStorageTag(storageId); err == nil {
+			storageId = tag.Id()
+		}
+		fmt.Fprintf(ctx.Stderr, fail+": %v\n", storageId, result.Error)
+	}
+}
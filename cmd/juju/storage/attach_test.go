@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+type attachSuite struct {
+	SubStorageSuite
+	mockAPI *mockAttachAPI
+}
+
+var _ = gc.Suite(&attachSuite{})
+
+func (s *attachSuite) SetUpTest(c *gc.C) {
+	s.SubStorageSuite.SetUpTest(c)
+	s.mockAPI = &mockAttachAPI{}
+}
+
+var attachInitErrorTests = []tstData{
+	{nil, ".*storage attach requires a unit and a storage id.*"},
+	{[]string{"tst/123"}, ".*storage attach requires a unit and a storage id.*"},
+	{[]string{"tst-123", "data/0"}, `.*unit name "tst-123" not valid.*`},
+	{[]string{"tst/123", "data"}, `.*storage id "data" not valid.*`},
+}
+
+func (s *attachSuite) TestInitErrors(c *gc.C) {
+	for i, t := range attachInitErrorTests {
+		c.Logf("test %d for %q", i, t.args)
+		_, err := s.run(c, t.args...)
+		c.Check(errors.Cause(err), gc.ErrorMatches, t.expectedErr)
+	}
+}
+
+func (s *attachSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	return testing.RunCommand(c, storage.NewAttachCommand(s.mockAPI), args...)
+}
+
+func (s *attachSuite) TestAttach(c *gc.C) {
+	ctx, err := s.run(c, "tst/123", "data/0", "err/1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "fail: storage \"err/1\": test failure\n")
+	c.Assert(s.mockAPI.ids, jc.DeepEquals, []params.StorageAttachmentId{
+		{StorageTag: "storage-data-0", UnitTag: "unit-tst-123"},
+		{StorageTag: "storage-err-1", UnitTag: "unit-tst-123"},
+	})
+}
+
+func (s *attachSuite) TestAttachAborted(c *gc.C) {
+	s.mockAPI.abort = true
+	_, err := s.run(c, "tst/123", "data/0")
+	c.Assert(err, gc.ErrorMatches, "aborted")
+}
+
+func (s *attachSuite) TestDetach(c *gc.C) {
+	ctx, err := testing.RunCommand(c, storage.NewDetachCommand(s.mockAPI), "tst/123", "err/1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "fail: storage \"err/1\": test failure\n")
+	c.Assert(s.mockAPI.ids, jc.DeepEquals, []params.StorageAttachmentId{
+		{StorageTag: "storage-err-1", UnitTag: "unit-tst-123"},
+	})
+}
+
+func (s *attachSuite) TestDetachInitError(c *gc.C) {
+	_, err := testing.RunCommand(c, storage.NewDetachCommand(s.mockAPI), "tst/123")
+	c.Assert(errors.Cause(err), gc.ErrorMatches, ".*storage detach requires a unit and a storage id.*")
+}
+
+type mockAttachAPI struct {
+	abort bool
+	ids   []params.StorageAttachmentId
+}
+
+func (s *mockAttachAPI) Close() error {
+	return nil
+}
+
+func (s *mockAttachAPI) Attach(ids []params.StorageAttachmentId) ([]params.ErrorResult, error) {
+	return s.modify(ids)
+}
+
+func (s *mockAttachAPI) Detach(ids []params.StorageAttachmentId) ([]params.ErrorResult, error) {
+	return s.modify(ids)
+}
+
+func (s *mockAttachAPI) modify(ids []params.StorageAttachmentId) ([]params.ErrorResult, error) {
+	if s.abort {
+		return nil, errors.New("aborted")
+	}
+	s.ids = ids
+	results := make([]params.ErrorResult, len(ids))
+	for i, id := range ids {
+		if id.StorageTag == "storage-err-1" {
+			results[i].Error = common.ServerError(errors.New("test failure"))
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+func newDetachCommand() cmd.Command {
+	return envcmd.Wrap(&detachCommand{})
+}
+
+const (
+	detachCommandDoc = `
+Detach storage instances from a unit without destroying them. The charm
+is notified with a storage-detaching hook, after which the storage is
+detached from the unit's machine. Detached storage can then be attached
+to another unit with "juju storage attach".
+
+Only block storage whose volumes can outlive their machines, such as
+EBS or Cinder volumes, may be detached.
+
+Example:
+    Detach storage instance "data/0" from unit u/0:
+
+      juju storage detach u/0 data/0
+`
+	detachCommandArgs = `<unit name> <storage id> ...`
+)
+
+// detachCommand detaches storage instances from a unit.
+type detachCommand struct {
+	StorageCommandBase
+	unitTag     string
+	storageTags []string
+	api         StorageDetachAPI
+}
+
+// Init implements Command.Init.
+func (c *detachCommand) Init(args []string) (err error) {
+	c.unitTag, c.storageTags, err = parseStorageAttachmentArgs("detach", args)
+	return err
+}
+
+// Info implements Command.Info.
+func (c *detachCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "detach",
+		Purpose: "detaches storage from a unit",
+		Doc:     detachCommandDoc,
+		Args:    detachCommandArgs,
+	}
+}
+
+// Run implements Command.Run.
+func (c *detachCommand) Run(ctx *cmd.Context) (err error) {
+	api := c.api
+	if api == nil {
+		api, err = c.NewStorageAPI()
+		if err != nil {
+			return err
+		}
+		defer api.Close()
+	}
+	ids := storageAttachmentIds(c.unitTag, c.storageTags)
+	results, err := api.Detach(ids)
+	if err != nil {
+		return err
+	}
+	reportStorageAttachmentFailures(ctx, ids, results)
+	return nil
+}
+
+// StorageDetachAPI defines the API methods that the storage detach
+// command uses.
+type StorageDetachAPI interface {
+	Close() error
+	Detach([]params.StorageAttachmentId) ([]params.ErrorResult, error)
+}
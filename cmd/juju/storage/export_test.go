@@ -47,6 +47,16 @@ func NewAddCommand(api StorageAddAPI) cmd.Command {
 	return envcmd.Wrap(cmd)
 }
 
+func NewAttachCommand(api StorageAttachAPI) cmd.Command {
+	cmd := &attachCommand{api: api}
+	return envcmd.Wrap(cmd)
+}
+
+func NewDetachCommand(api StorageDetachAPI) cmd.Command {
+	cmd := &detachCommand{api: api}
+	return envcmd.Wrap(cmd)
+}
+
 func NewFilesystemListCommand(api FilesystemListAPI) cmd.Command {
 	cmd := &filesystemListCommand{api: api}
 	return envcmd.Wrap(cmd)
@@ -42,6 +42,8 @@ func NewSuperCommand() cmd.Command {
 	storagecmd.Register(newShowCommand())
 	storagecmd.Register(newListCommand())
 	storagecmd.Register(newAddCommand())
+	storagecmd.Register(newAttachCommand())
+	storagecmd.Register(newDetachCommand())
 	storagecmd.Register(newPoolSuperCommand())
 	storagecmd.Register(newVolumeSuperCommand())
 	storagecmd.Register(NewFilesystemSuperCommand())
@@ -12,13 +12,13 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
-	"github.com/juju/utils/featureflag"
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/subnets"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju/osenv/featureflag"
 	"github.com/juju/juju/network"
 )
 
@@ -4,11 +4,11 @@
 package system
 
 import (
-	"fmt"
 	"sort"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs/configstore"
@@ -23,6 +23,7 @@ func newListCommand() cmd.Command {
 type listCommand struct {
 	envcmd.JujuCommandBase
 	cfgStore configstore.Storage
+	out      cmd.Output
 }
 
 var listDoc = `
@@ -48,6 +49,11 @@ func (c *listCommand) Info() *cmd.Info {
 	}
 }
 
+// SetFlags implements Command.SetFlags.
+func (c *listCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
 func (c *listCommand) getConfigstore() (configstore.Storage, error) {
 	if c.cfgStore != nil {
 		return c.cfgStore, nil
@@ -69,9 +75,5 @@ func (c *listCommand) Run(ctx *cmd.Context) error {
 	}
 
 	sort.Strings(list)
-	for _, name := range list {
-		fmt.Fprintf(ctx.Stdout, "%s\n", name)
-	}
-
-	return nil
+	return c.out.Write(ctx, list)
 }
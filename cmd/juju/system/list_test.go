@@ -83,6 +83,18 @@ func (s *ListSuite) TestSystemList(c *gc.C) {
 	c.Assert(testing.Stdout(context), gc.Equals, "test1\ntest3\n")
 }
 
+func (s *ListSuite) TestSystemListYAML(c *gc.C) {
+	context, err := testing.RunCommand(c, system.NewListCommand(s.store), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, "- test1\n- test3\n")
+}
+
+func (s *ListSuite) TestSystemListJSON(c *gc.C) {
+	context, err := testing.RunCommand(c, system.NewListCommand(s.store), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, `["test1","test3"]`+"\n")
+}
+
 func (s *ListSuite) TestUnrecognizedArg(c *gc.C) {
 	_, err := testing.RunCommand(c, system.NewListCommand(s.store), "whoops")
 	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["whoops"\]`)
@@ -4,7 +4,6 @@
 package system
 
 import (
-	"os"
 	"path"
 
 	"github.com/juju/cmd"
@@ -19,6 +18,7 @@ import (
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs/configstore"
 	"github.com/juju/juju/juju"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/network"
 )
 
@@ -112,7 +112,7 @@ func (c *loginCommand) Init(args []string) error {
 // macaroons. The returned value can be overridden by setting the
 // JUJU_COOKIEFILE environment variable.
 func cookieFile() string {
-	if file := os.Getenv("JUJU_COOKIEFILE"); file != "" {
+	if file := osenv.JujuCookieFileVar.Value(); file != "" {
 		return file
 	}
 	return path.Join(utils.Home(), ".go-cookies")
@@ -16,6 +16,8 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/cmd/jujud/util"
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/worker"
 )
 
 // AgentConf is a terribly confused interface.
@@ -113,3 +115,20 @@ func (ch *agentConf) CurrentConfig() agent.Config {
 	defer ch.mu.Unlock()
 	return ch._config.Clone()
 }
+
+// loggingConfigWorkerStarter returns a function that starts a worker
+// serving the agent's logging configuration on a socket, so that it
+// can be changed while the agent runs; see "jujud logging-config".
+func loggingConfigWorkerStarter(agentConfig agent.Config) func() (worker.Worker, error) {
+	return func() (worker.Worker, error) {
+		socketPath := logging.ConfigSocketPath(agentConfig.DataDir(), agentConfig.Tag().String())
+		listener, err := logging.NewConfigListener(socketPath)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot serve logging config")
+		}
+		return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+			<-stop
+			return listener.Close()
+		}), nil
+	}
+}
@@ -56,6 +56,10 @@ func (FakeConfig) Tag() names.Tag {
 	return names.NewMachineTag("42")
 }
 
+func (FakeConfig) Value(string) string {
+	return ""
+}
+
 type FakeAgentConfig struct {
 	AgentConf
 }
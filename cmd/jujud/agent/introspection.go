@@ -0,0 +1,36 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent
+
+import (
+	"runtime"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/introspection"
+)
+
+// introspectionName is the name of the manifold that serves an agent's
+// runtime diagnostics.
+const introspectionName = "introspection"
+
+// startIntrospection installs a manifold in the engine that serves
+// runtime diagnostics, including the engine's own report, on the
+// abstract unix socket "jujud-<tag>". Abstract sockets are only
+// available on linux; elsewhere nothing is installed.
+func startIntrospection(engine dependency.Engine, tag names.Tag) error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	return engine.Install(introspectionName, dependency.Manifold{
+		Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+			return introspection.NewWorker(introspection.Config{
+				SocketName: "jujud-" + tag.String(),
+				Reporter:   engine,
+			})
+		},
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent
+
+import (
+	"runtime"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/dependency"
+)
+
+type introspectionSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&introspectionSuite{})
+
+func (s *introspectionSuite) TestStartIntrospection(c *gc.C) {
+	if runtime.GOOS != "linux" {
+		c.Skip("introspection is only supported on linux")
+	}
+	engine := &installRecorder{}
+	err := startIntrospection(engine, names.NewUnitTag("wordpress/0"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(engine.installed, jc.DeepEquals, []string{"introspection"})
+	c.Assert(engine.manifolds[0].Inputs, gc.HasLen, 0)
+
+	w, err := engine.manifolds[0].Start(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+}
+
+// installRecorder is a dependency.Engine that records the manifolds
+// installed in it.
+type installRecorder struct {
+	dependency.Engine
+	installed []string
+	manifolds []dependency.Manifold
+}
+
+func (e *installRecorder) Install(name string, manifold dependency.Manifold) error {
+	e.installed = append(e.installed, name)
+	e.manifolds = append(e.manifolds, manifold)
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,28 +22,28 @@ import (
 	"github.com/juju/replicaset"
 	"github.com/juju/utils"
 	"github.com/juju/utils/clock"
-	"github.com/juju/utils/featureflag"
-	"github.com/juju/utils/series"
 	"github.com/juju/utils/set"
 	"github.com/juju/utils/symlink"
 	"github.com/juju/utils/voyeur"
 	"gopkg.in/juju/charmrepo.v1"
 	"gopkg.in/mgo.v2"
-	"gopkg.in/natefinch/lumberjack.v2"
 	"launchpad.net/gnuflag"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api"
 	apiagent "github.com/juju/juju/api/agent"
+	apicredentialvalidator "github.com/juju/juju/api/credentialvalidator"
 	apideployer "github.com/juju/juju/api/deployer"
-	apilogsender "github.com/juju/juju/api/logsender"
+	apihistorypruner "github.com/juju/juju/api/historypruner"
+	apihostkeyreporter "github.com/juju/juju/api/hostkeyreporter"
 	"github.com/juju/juju/api/metricsmanager"
 	"github.com/juju/juju/api/statushistory"
 	apiupgrader "github.com/juju/juju/api/upgrader"
 	"github.com/juju/juju/apiserver"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/jujud/agent/machine"
 	"github.com/juju/juju/cmd/jujud/reboot"
 	cmdutil "github.com/juju/juju/cmd/jujud/util"
 	"github.com/juju/juju/container"
@@ -54,7 +55,9 @@ import (
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
 	jujunames "github.com/juju/juju/juju/names"
+	"github.com/juju/juju/juju/osenv/featureflag"
 	"github.com/juju/juju/juju/paths"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider"
@@ -74,15 +77,18 @@ import (
 	"github.com/juju/juju/worker/charmrevisionworker"
 	"github.com/juju/juju/worker/cleaner"
 	"github.com/juju/juju/worker/conv2state"
+	"github.com/juju/juju/worker/credentialvalidator"
 	"github.com/juju/juju/worker/dblogpruner"
+	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/deployer"
 	"github.com/juju/juju/worker/diskmanager"
 	"github.com/juju/juju/worker/envworkermanager"
 	"github.com/juju/juju/worker/firewaller"
+	"github.com/juju/juju/worker/historypruner"
+	"github.com/juju/juju/worker/hostkeyreporter"
 	"github.com/juju/juju/worker/imagemetadataworker"
 	"github.com/juju/juju/worker/instancepoller"
 	"github.com/juju/juju/worker/localstorage"
-	workerlogger "github.com/juju/juju/worker/logger"
 	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/machiner"
 	"github.com/juju/juju/worker/metricworker"
@@ -108,7 +114,7 @@ const bootstrapMachineId = "0"
 var (
 	logger     = loggo.GetLogger("juju.cmd.jujud")
 	retryDelay = 3 * time.Second
-	JujuRun    = paths.MustSucceed(paths.JujuRun(series.HostSeries()))
+	JujuRun    = paths.MustSucceed(paths.HostJujuRun())
 
 	// The following are defined as variables to allow the tests to
 	// intercept calls to the functions.
@@ -234,10 +240,26 @@ func (a *machineAgentCmd) Init(args []string) error {
 	agentConfig := a.currentConfig.CurrentConfig()
 
 	// the context's stderr is set as the loggo writer in github.com/juju/cmd/logging.go
-	a.ctx.Stderr = &lumberjack.Logger{
+	a.ctx.Stderr = &logging.RotatingFile{
 		Filename:   agent.LogFilename(agentConfig),
-		MaxSize:    300, // megabytes
+		MaxSize:    300 * logging.Megabyte,
 		MaxBackups: 2,
+		Compress:   true,
+	}
+
+	forwarding := agentConfig.Value(agent.LogForwarding)
+	if err := logging.InstallForwarding(forwarding, agentConfig.Tag().String()); err != nil {
+		return errors.Annotate(err, "cannot set up log forwarding")
+	}
+
+	if auditLogging(agentConfig) {
+		key, err := auditLogKey(a.currentConfig)
+		if err != nil {
+			return errors.Annotate(err, "cannot set up audit log")
+		}
+		if err := logging.InstallAuditLog(agent.AuditLogFilename(agentConfig), key); err != nil {
+			return errors.Annotate(err, "cannot set up audit log")
+		}
 	}
 
 	return nil
@@ -447,8 +469,9 @@ func (a *MachineAgent) Run(*cmd.Context) error {
 	if err := a.createJujuRun(agentConfig.DataDir()); err != nil {
 		return fmt.Errorf("cannot create juju run symlink: %v", err)
 	}
-	a.runner.StartWorker("api", a.APIWorker)
+	a.runner.StartWorker("engine", a.APIWorkers)
 	a.runner.StartWorker("statestarter", a.newStateStarterWorker)
+	a.runner.StartWorker("logging-config", loggingConfigWorkerStarter(agentConfig))
 	a.runner.StartWorker("termination", func() (worker.Worker, error) {
 		return startTerminationWorker(
 			agentConfig.DataDir(),
@@ -666,29 +689,58 @@ func (a *MachineAgent) stateStarter(stopch <-chan struct{}) error {
 	}
 }
 
-// APIWorker returns a Worker that connects to the API and starts any
-// workers that need an API connection.
-func (a *MachineAgent) APIWorker() (_ worker.Worker, err error) {
-	st, entity, err := apicaller.OpenAPIState(a)
+// APIWorkers returns a dependency.Engine running the machine agent's
+// responsibilities that depend on the API connection.
+func (a *MachineAgent) APIWorkers() (worker.Worker, error) {
+	manifolds := machine.Manifolds(machine.ManifoldsConfig{
+		Agent:           agent.APIHostPortsSetter{a},
+		LogSource:       a.bufferedLogs,
+		StartAPIWorkers: a.startAPIWorkers,
+	})
+
+	config := dependency.EngineConfig{
+		IsFatal:     cmdutil.IsFatal,
+		WorstError:  cmdutil.MoreImportantError,
+		ErrorDelay:  3 * time.Second,
+		BounceDelay: 10 * time.Millisecond,
+	}
+	engine, err := dependency.NewEngine(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := dependency.Install(engine, manifolds); err != nil {
+		if err := worker.Stop(engine); err != nil {
+			logger.Errorf("while stopping engine with bad manifolds: %v", err)
+		}
+		return nil, err
+	}
+	if err := startIntrospection(engine, a.Tag()); err != nil {
+		if err := worker.Stop(engine); err != nil {
+			logger.Errorf("while stopping engine: %v", err)
+		}
+		return nil, err
+	}
+	return engine, nil
+}
+
+// startAPIWorkers starts the workers that need the API connection but
+// have not yet been given manifolds of their own. The connection
+// belongs to the engine's api caller, and is not closed here.
+func (a *MachineAgent) startAPIWorkers(st api.Connection) (worker.Worker, error) {
+	entity, err := st.Agent().Entity(a.Tag())
+	if err == nil && entity.Life() == params.Dead {
+		logger.Errorf("agent terminating - entity %q is dead", a.Tag())
+		return nil, worker.ErrTerminateAgent
+	}
+	if params.IsCodeNotFoundOrCodeUnauthorized(err) {
+		logger.Errorf("agent terminating due to error returned during entity lookup: %v", err)
+		return nil, worker.ErrTerminateAgent
+	}
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	reportOpenedAPI(st)
 
-	defer func() {
-		// TODO(fwereade): this is not properly tested. Old tests were evil
-		// (dependent on injecting an error in a patched-out upgrader API
-		// that shouldn't even be used at this level)... so I just deleted
-		// them. Not a major worry: this whole method will become redundant
-		// when we switch to the dependency engine (and specifically use
-		// worker/apicaller to connect).
-		if err != nil {
-			if err := st.Close(); err != nil {
-				logger.Errorf("while closing API: %v", err)
-			}
-		}
-	}()
-
 	agentConfig := a.CurrentConfig()
 	for _, job := range entity.Jobs() {
 		if job.NeedsState() {
@@ -720,7 +772,7 @@ func (a *MachineAgent) APIWorker() (_ worker.Worker, err error) {
 		return a.postUpgradeAPIWorker(st, agentConfig, entity)
 	})
 
-	return cmdutil.NewCloseWorker(logger, runner, st), nil // Note: a worker.Runner is itself a worker.Worker.
+	return runner, nil
 }
 
 func (a *MachineAgent) postUpgradeAPIWorker(
@@ -757,12 +809,6 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		})
 	}
 
-	if feature.IsDbLogEnabled() {
-		runner.StartWorker("logsender", func() (worker.Worker, error) {
-			return logsender.New(a.bufferedLogs, apilogsender.NewAPI(st)), nil
-		})
-	}
-
 	envConfig, err := st.Environment().EnvironConfig()
 	if err != nil {
 		return nil, fmt.Errorf("cannot read environment config: %v", err)
@@ -790,8 +836,18 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		addressUpdater := agent.APIHostPortsSetter{a}
 		return apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), addressUpdater), nil
 	})
-	runner.StartWorker("logger", func() (worker.Worker, error) {
-		return workerlogger.NewLogger(st.Logger(), agentConfig), nil
+	runner.StartWorker("hostkeyreporter", func() (worker.Worker, error) {
+		conf := hostkeyreporter.Config{
+			Facade:         apihostkeyreporter.NewAPI(st),
+			MachineTag:     agentConfig.Tag().(names.MachineTag),
+			ReportInterval: hostkeyreporter.DefaultReportInterval,
+			NewTimer:       worker.NewTimer,
+		}
+		w, err := hostkeyreporter.New(conf)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot start \"hostkeyreporter\"")
+		}
+		return w, nil
 	})
 
 	if !featureflag.Enabled(feature.DisableRsyslog) {
@@ -903,7 +959,7 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		}
 	}
 
-	return cmdutil.NewCloseWorker(logger, runner, st), nil // Note: a worker.Runner is itself a worker.Worker.
+	return runner, nil
 }
 
 // Restart restarts the agent's service.
@@ -1083,7 +1139,7 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	for _, job := range m.Jobs() {
 		switch job {
 		case state.JobHostUnits:
-			// Implemented in APIWorker.
+			// Implemented in startAPIWorkers.
 		case state.JobManageEnviron:
 			useMultipleCPUs()
 			a.startWorkerAfterUpgrade(runner, "env worker manager", func() (worker.Worker, error) {
@@ -1271,6 +1327,31 @@ func (a *MachineAgent) startEnvWorkers(
 		}
 		return w, nil
 	})
+	singularRunner.StartWorker("historypruner", func() (worker.Worker, error) {
+		conf := historypruner.Config{
+			Facade:        apihistorypruner.NewAPI(apiSt),
+			PruneInterval: historypruner.DefaultPruneInterval,
+			NewTimer:      worker.NewTimer,
+		}
+		w, err := historypruner.New(conf)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot start \"historypruner\"")
+		}
+		return w, nil
+	})
+	singularRunner.StartWorker("credentialvalidator", func() (worker.Worker, error) {
+		conf := credentialvalidator.Config{
+			Facade:        apicredentialvalidator.NewAPI(apiSt),
+			NewEnviron:    environs.New,
+			CheckInterval: credentialvalidator.DefaultCheckInterval,
+			NewTimer:      worker.NewTimer,
+		}
+		w, err := credentialvalidator.New(conf)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot start \"credentialvalidator\"")
+		}
+		return w, nil
+	})
 
 	return runner, nil
 }
@@ -1329,9 +1410,118 @@ func (a *MachineAgent) newApiserverWorker(st *state.State, certChanged chan para
 		LogDir:      logDir,
 		Validator:   a.limitLogins,
 		CertChanged: certChanged,
+		Limits:      apiserverLimits(agentConfig),
+		Audit:       apiserverAuditConfig(agentConfig),
+		ReadMirror:  apiserverReadMirror(agentConfig),
 	})
 }
 
+// apiserverLimits returns the limits the API server applies to its
+// clients: apiserver.DefaultLimits, overridden by any set in the
+// agent's config.
+func apiserverLimits(agentConfig agent.Config) apiserver.Limits {
+	limits := apiserver.DefaultLimits
+	intValue := func(key string, value *int) {
+		if s := agentConfig.Value(key); s != "" {
+			if n, err := strconv.Atoi(s); err != nil || n < 0 {
+				logger.Warningf("ignoring invalid %s %q", key, s)
+			} else {
+				*value = n
+			}
+		}
+	}
+	floatValue := func(key string, value *float64) {
+		if s := agentConfig.Value(key); s != "" {
+			if f, err := strconv.ParseFloat(s, 64); err != nil || f < 0 {
+				logger.Warningf("ignoring invalid %s %q", key, s)
+			} else {
+				*value = f
+			}
+		}
+	}
+	intValue(agent.APIMaxConnectionsPerEntity, &limits.MaxConnectionsPerEntity)
+	floatValue(agent.APIAddressLoginRate, &limits.AddressLoginRate)
+	intValue(agent.APIAddressLoginBurst, &limits.AddressLoginBurst)
+	floatValue(agent.APILoginRate, &limits.LoginRate)
+	intValue(agent.APILoginBurst, &limits.LoginBurst)
+	floatValue(agent.APIFacadeCallRate, &limits.FacadeCallRate)
+	intValue(agent.APIFacadeCallBurst, &limits.FacadeCallBurst)
+	return limits
+}
+
+// apiserverAuditConfig returns the configuration of the API server's
+// audit log: apiserver.DefaultAuditConfig, overridden by any set in the
+// agent's config.
+func apiserverAuditConfig(agentConfig agent.Config) apiserver.AuditConfig {
+	config := apiserver.DefaultAuditConfig
+	config.Enabled = auditLogging(agentConfig)
+	listValue := func(key string, value *[]string) {
+		if s := agentConfig.Value(key); s != "" {
+			var values []string
+			for _, v := range strings.Split(s, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			*value = values
+		}
+	}
+	listValue(agent.APIAuditRedactedParams, &config.RedactedParams)
+	listValue(agent.APIAuditFacades, &config.Facades)
+	listValue(agent.APIAuditExcludedFacades, &config.ExcludedFacades)
+	return config
+}
+
+// auditLogging returns whether the agent audits, as set in its config.
+// Auditing is enabled unless it is explicitly disabled.
+func auditLogging(agentConfig agent.Config) bool {
+	s := agentConfig.Value(agent.AuditLogging)
+	if s == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(s)
+	if err != nil {
+		logger.Warningf("ignoring invalid %s %q", agent.AuditLogging, s)
+		return true
+	}
+	return enabled
+}
+
+// auditLogKey returns the key the agent's audit log is hashed with,
+// generating it and saving it in the agent's config the first time.
+func auditLogKey(conf AgentConfigWriter) ([]byte, error) {
+	if key := conf.CurrentConfig().Value(agent.AuditLogKey); key != "" {
+		return []byte(key), nil
+	}
+	key, err := utils.RandomPassword()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = conf.ChangeConfig(func(config agent.ConfigSetter) error {
+		config.SetValue(agent.AuditLogKey, key)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot save audit log key")
+	}
+	return []byte(key), nil
+}
+
+// apiserverReadMirror returns whether the API server should serve
+// read-only calls from a read mirror, as set in the agent's config.
+func apiserverReadMirror(agentConfig agent.Config) bool {
+	s := agentConfig.Value(agent.APIReadMirror)
+	if s == "" {
+		return false
+	}
+	readMirror, err := strconv.ParseBool(s)
+	if err != nil {
+		logger.Warningf("ignoring invalid %s %q", agent.APIReadMirror, s)
+		return false
+	}
+	return readMirror
+}
+
 // limitLogins is called by the API server for each login attempt.
 // it returns an error if upgrades or restore are running.
 func (a *MachineAgent) limitLogins(req params.LoginRequest) error {
@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine
+
+import (
+	"github.com/juju/errors"
+
+	coreagent "github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/agent"
+	"github.com/juju/juju/worker/apicaller"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/gate"
+	"github.com/juju/juju/worker/logger"
+	"github.com/juju/juju/worker/logsender"
+)
+
+// ManifoldsConfig allows specialisation of the result of Manifolds.
+type ManifoldsConfig struct {
+
+	// Agent contains the agent that will be wrapped and made available to
+	// its dependencies via a dependency.Engine.
+	Agent coreagent.Agent
+
+	// LogSource will be read from by the logsender component.
+	LogSource logsender.LogRecordCh
+
+	// StartAPIWorkers starts the workers that need an API connection
+	// but do not yet have manifolds of their own. It will be called
+	// again, with a fresh connection, whenever the api caller is
+	// restarted; it must not close the connection it is given.
+	StartAPIWorkers func(api.Connection) (worker.Worker, error)
+}
+
+// Manifolds returns a set of co-configured manifolds covering the various
+// responsibilities of a machine agent. Workers that still need a
+// connection to state, and API workers not yet broken out into their
+// own manifolds, are run by StartAPIWorkers or by the agent's runner.
+//
+// Thou Shalt Not Use String Literals In This Function. Or Else.
+func Manifolds(config ManifoldsConfig) dependency.Manifolds {
+	return dependency.Manifolds{
+
+		// The agent manifold references the enclosing agent, and is the
+		// foundation stone on which most other manifolds ultimately depend.
+		AgentName: agent.Manifold(config.Agent),
+
+		// The api caller is a thin concurrent wrapper around a connection
+		// to some API server. It's used by many other manifolds, which all
+		// select their own desired facades.
+		APICallerName: apicaller.Manifold(apicaller.ManifoldConfig{
+			AgentName:       AgentName,
+			APIInfoGateName: APIInfoGateName,
+		}),
+
+		// This manifold is used to coordinate between the api caller and the
+		// log sender, which share the API credentials that the API caller may
+		// update. To avoid surprising races, the log sender waits for the api
+		// caller to unblock this, indicating that any password dance has been
+		// completed and the log-sender can now connect without confusion.
+		APIInfoGateName: gate.Manifold(),
+
+		// The log sender is a leaf worker that sends log messages to some
+		// API server, when configured so to do.
+		LogSenderName: logsender.Manifold(logsender.ManifoldConfig{
+			LogSource:     config.LogSource,
+			APICallerName: APICallerName,
+		}),
+
+		// The logging config updater is a leaf worker that indirectly
+		// controls the messages sent via the log sender or rsyslog,
+		// according to changes in environment config.
+		LoggingConfigUpdaterName: logger.Manifold(logger.ManifoldConfig{
+			AgentName:     AgentName,
+			APICallerName: APICallerName,
+		}),
+
+		// The api workers run everything else that needs the api
+		// connection: the upgrader and upgrade steps, and the workers
+		// that wait for the upgrade steps to complete. They share the
+		// api caller's connection, and are restarted with it; as they
+		// are given manifolds of their own they should be removed
+		// from StartAPIWorkers.
+		APIWorkersName: apiWorkersManifold(APICallerName, config.StartAPIWorkers),
+	}
+}
+
+// apiWorkersManifold returns a manifold that runs start with the
+// api.Connection supplied by the named api caller.
+func apiWorkersManifold(apiCallerName string, start func(api.Connection) (worker.Worker, error)) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{
+			apiCallerName,
+		},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			if start == nil {
+				return nil, errors.New("no api workers configured")
+			}
+			var conn api.Connection
+			if err := getResource(apiCallerName, &conn); err != nil {
+				return nil, err
+			}
+			return start(conn)
+		},
+	}
+}
+
+const (
+	AgentName                = "agent"
+	APICallerName            = "api-caller"
+	APIInfoGateName          = "api-info-gate"
+	APIWorkersName           = "api-workers"
+	LoggingConfigUpdaterName = "logging-config-updater"
+	LogSenderName            = "log-sender"
+)
@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/cmd/jujud/agent/machine"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	dt "github.com/juju/juju/worker/dependency/testing"
+)
+
+type ManifoldsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ManifoldsSuite{})
+
+func (s *ManifoldsSuite) TestStartFuncs(c *gc.C) {
+	manifolds := machine.Manifolds(machine.ManifoldsConfig{
+		Agent: fakeAgent{},
+	})
+
+	for name, manifold := range manifolds {
+		c.Logf("checking %q manifold", name)
+		c.Check(manifold.Start, gc.NotNil)
+	}
+}
+
+func (s *ManifoldsSuite) TestAcyclic(c *gc.C) {
+	manifolds := machine.Manifolds(machine.ManifoldsConfig{
+		Agent: fakeAgent{},
+	})
+	err := dependency.Validate(manifolds)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ManifoldsSuite) TestManifoldNames(c *gc.C) {
+	manifolds := machine.Manifolds(machine.ManifoldsConfig{})
+	expectedKeys := []string{
+		machine.AgentName,
+		machine.APICallerName,
+		machine.APIInfoGateName,
+		machine.APIWorkersName,
+		machine.LoggingConfigUpdaterName,
+		machine.LogSenderName,
+	}
+	keys := make([]string, 0, len(manifolds))
+	for k := range manifolds {
+		keys = append(keys, k)
+	}
+	c.Assert(expectedKeys, jc.SameContents, keys)
+}
+
+func (s *ManifoldsSuite) TestAPIWorkersUsesConnection(c *gc.C) {
+	conn := fakeConn{}
+	var started []api.Connection
+	manifolds := machine.Manifolds(machine.ManifoldsConfig{
+		StartAPIWorkers: func(st api.Connection) (worker.Worker, error) {
+			started = append(started, st)
+			return nil, errors.New("boom")
+		},
+	})
+	manifold := manifolds[machine.APIWorkersName]
+	c.Assert(manifold.Inputs, jc.DeepEquals, []string{machine.APICallerName})
+
+	getResource := dt.StubGetResource(dt.StubResources{
+		machine.APICallerName: dt.StubResource{Output: conn},
+	})
+	_, err := manifold.Start(getResource)
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(started, jc.DeepEquals, []api.Connection{conn})
+}
+
+func (s *ManifoldsSuite) TestAPIWorkersMissingConnection(c *gc.C) {
+	manifolds := machine.Manifolds(machine.ManifoldsConfig{
+		StartAPIWorkers: func(api.Connection) (worker.Worker, error) {
+			panic("unexpected")
+		},
+	})
+	getResource := dt.StubGetResource(dt.StubResources{
+		machine.APICallerName: dt.StubResource{Error: dependency.ErrMissing},
+	})
+	_, err := manifolds[machine.APIWorkersName].Start(getResource)
+	c.Assert(errors.Cause(err), gc.Equals, dependency.ErrMissing)
+}
+
+type fakeAgent struct {
+	agent.Agent
+}
+
+type fakeConn struct {
+	api.Connection
+}
@@ -29,7 +29,6 @@ import (
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v6-unstable"
 	"gopkg.in/juju/charmrepo.v1"
-	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api"
@@ -54,6 +53,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/dummy"
@@ -333,12 +333,13 @@ func (s *MachineSuite) TestUseLumberjack(c *gc.C) {
 	err = a.Init(nil)
 	c.Assert(err, gc.IsNil)
 
-	l, ok := ctx.Stderr.(*lumberjack.Logger)
+	l, ok := ctx.Stderr.(*logging.RotatingFile)
 	c.Assert(ok, jc.IsTrue)
 	c.Check(l.MaxAge, gc.Equals, 0)
 	c.Check(l.MaxBackups, gc.Equals, 2)
 	c.Check(l.Filename, gc.Equals, filepath.FromSlash("/var/log/juju/machine-42.log"))
-	c.Check(l.MaxSize, gc.Equals, 300)
+	c.Check(l.MaxSize, gc.Equals, int64(300*logging.Megabyte))
+	c.Check(l.Compress, jc.IsTrue)
 }
 
 func (s *MachineSuite) TestDontUseLumberjack(c *gc.C) {
@@ -365,7 +366,7 @@ func (s *MachineSuite) TestDontUseLumberjack(c *gc.C) {
 	err = a.Init(nil)
 	c.Assert(err, gc.IsNil)
 
-	_, ok := ctx.Stderr.(*lumberjack.Logger)
+	_, ok := ctx.Stderr.(*logging.RotatingFile)
 	c.Assert(ok, jc.IsFalse)
 }
 
@@ -402,6 +403,32 @@ func (s *MachineSuite) TestWithRemovedMachine(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *MachineSuite) TestStartAPIWorkersDeadMachine(c *gc.C) {
+	m, _, _ := s.primeAgent(c, state.JobHostUnits)
+	a := s.newAgent(c, m)
+	st := s.OpenAPIAsMachine(c, m.Tag(), initialMachinePassword, agent.BootstrapNonce)
+	err := m.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	w, err := a.startAPIWorkers(st)
+	c.Assert(err, gc.Equals, worker.ErrTerminateAgent)
+	c.Assert(w, gc.IsNil)
+}
+
+func (s *MachineSuite) TestStartAPIWorkersRemovedMachine(c *gc.C) {
+	m, _, _ := s.primeAgent(c, state.JobHostUnits)
+	a := s.newAgent(c, m)
+	st := s.OpenAPIAsMachine(c, m.Tag(), initialMachinePassword, agent.BootstrapNonce)
+	err := m.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	w, err := a.startAPIWorkers(st)
+	c.Assert(err, gc.Equals, worker.ErrTerminateAgent)
+	c.Assert(w, gc.IsNil)
+}
+
 func (s *MachineSuite) TestDyingMachine(c *gc.C) {
 	m, _, _ := s.primeAgent(c, state.JobHostUnits)
 	a := s.newAgent(c, m)
@@ -829,6 +856,28 @@ func (s *MachineSuite) TestManageEnvironRunsStatusHistoryPruner(c *gc.C) {
 	runner.waitForWorker(c, "statushistorypruner")
 }
 
+func (s *MachineSuite) TestManageEnvironRunsHistoryPruner(c *gc.C) {
+	m, _, _ := s.primeAgent(c, state.JobManageEnviron)
+	a := s.newAgent(c, m)
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+
+	_ = s.singularRecord.nextRunner(c)
+	runner := s.singularRecord.nextRunner(c)
+	runner.waitForWorker(c, "historypruner")
+}
+
+func (s *MachineSuite) TestManageEnvironRunsCredentialValidator(c *gc.C) {
+	m, _, _ := s.primeAgent(c, state.JobManageEnviron)
+	a := s.newAgent(c, m)
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+
+	_ = s.singularRecord.nextRunner(c)
+	runner := s.singularRecord.nextRunner(c)
+	runner.waitForWorker(c, "credentialvalidator")
+}
+
 func (s *MachineSuite) TestManageEnvironCallsUseMultipleCPUs(c *gc.C) {
 	// If it has been enabled, the JobManageEnviron agent should call utils.UseMultipleCPUs
 	usefulVersion := version.Binary{
@@ -1005,7 +1054,7 @@ func (s *MachineSuite) assertAgentOpensState(c *gc.C, reportOpened *func(io.Clos
 	defer a.Stop()
 	logger.Debugf("new agent %#v", a)
 
-	// All state jobs currently also run an APIWorker, so no
+	// All state jobs currently also run the API workers, so no
 	// need to check for that here, like in assertJobWithState.
 
 	agentAPIs := make(chan io.Closer, 1)
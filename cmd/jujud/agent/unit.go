@@ -9,16 +9,17 @@ import (
 	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
-	"github.com/juju/utils/featureflag"
-	"gopkg.in/natefinch/lumberjack.v2"
 	"launchpad.net/gnuflag"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/cmd/jujud/agent/unit"
 	cmdutil "github.com/juju/juju/cmd/jujud/util"
+	"github.com/juju/juju/juju/osenv/featureflag"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
@@ -94,12 +95,17 @@ func (a *UnitAgent) Init(args []string) error {
 		agentConfig := a.CurrentConfig()
 
 		// the writer in ctx.stderr gets set as the loggo writer in github.com/juju/cmd/logging.go
-		a.ctx.Stderr = &lumberjack.Logger{
+		a.ctx.Stderr = &logging.RotatingFile{
 			Filename:   agent.LogFilename(agentConfig),
-			MaxSize:    300, // megabytes
+			MaxSize:    300 * logging.Megabyte,
 			MaxBackups: 2,
+			Compress:   true,
 		}
 
+		forwarding := agentConfig.Value(agent.LogForwarding)
+		if err := logging.InstallForwarding(forwarding, a.Tag().String()); err != nil {
+			return errors.Annotate(err, "cannot set up log forwarding")
+		}
 	}
 
 	return nil
@@ -137,6 +143,7 @@ func (a *UnitAgent) Run(ctx *cmd.Context) error {
 	runUpgrades(agentConfig.Tag(), agentConfig.DataDir())
 
 	a.runner.StartWorker("api", a.APIWorkers)
+	a.runner.StartWorker("logging-config", loggingConfigWorkerStarter(agentConfig))
 	err := cmdutil.AgentDone(logger, a.runner.Wait())
 	a.tomb.Kill(err)
 	return err
@@ -187,6 +194,12 @@ func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
 		}
 		return nil, err
 	}
+	if err := startIntrospection(engine, a.Tag()); err != nil {
+		if err := worker.Stop(engine); err != nil {
+			logger.Errorf("while stopping engine: %v", err)
+		}
+		return nil, err
+	}
 	return engine, nil
 }
 
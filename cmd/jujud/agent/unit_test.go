@@ -17,7 +17,6 @@ import (
 	"github.com/juju/utils/arch"
 	"github.com/juju/utils/series"
 	gc "gopkg.in/check.v1"
-	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
@@ -25,6 +24,7 @@ import (
 	agenttesting "github.com/juju/juju/cmd/jujud/agent/testing"
 	envtesting "github.com/juju/juju/environs/testing"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
@@ -440,12 +440,13 @@ func (s *UnitSuite) TestUseLumberjack(c *gc.C) {
 	err = a.Init(nil)
 	c.Assert(err, gc.IsNil)
 
-	l, ok := ctx.Stderr.(*lumberjack.Logger)
+	l, ok := ctx.Stderr.(*logging.RotatingFile)
 	c.Assert(ok, jc.IsTrue)
 	c.Check(l.MaxAge, gc.Equals, 0)
 	c.Check(l.MaxBackups, gc.Equals, 2)
 	c.Check(l.Filename, gc.Equals, filepath.FromSlash("/var/log/juju/machine-42.log"))
-	c.Check(l.MaxSize, gc.Equals, 300)
+	c.Check(l.MaxSize, gc.Equals, int64(300*logging.Megabyte))
+	c.Check(l.Compress, jc.IsTrue)
 }
 
 func (s *UnitSuite) TestDontUseLumberjack(c *gc.C) {
@@ -465,6 +466,6 @@ func (s *UnitSuite) TestDontUseLumberjack(c *gc.C) {
 	err = a.Init(nil)
 	c.Assert(err, gc.IsNil)
 
-	_, ok := ctx.Stderr.(*lumberjack.Logger)
+	_, ok := ctx.Stderr.(*logging.RotatingFile)
 	c.Assert(ok, jc.IsFalse)
 }
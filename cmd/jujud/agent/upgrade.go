@@ -330,7 +330,11 @@ func (c *upgradeWorkerContext) runUpgradeSteps(agentConfig agent.ConfigSetter) e
 	a := c.agent
 	a.setMachineStatus(c.apiState, params.StatusStarted, fmt.Sprintf("upgrading to %v", c.toVersion))
 
-	context := upgrades.NewContext(agentConfig, c.apiState, c.st)
+	reportStep := func(description string) {
+		a.setMachineStatus(c.apiState, params.StatusStarted,
+			fmt.Sprintf("upgrading to %v: %s", c.toVersion, description))
+	}
+	context := upgrades.NewContext(agentConfig, c.apiState, c.st, reportStep)
 	logger.Infof("starting upgrade from %v to %v for %q", c.fromVersion, c.toVersion, c.tag)
 
 	targets := jobsToTargets(c.jobs, c.isMaster)
@@ -270,6 +270,29 @@ func (s *UpgradeSuite) TestUpgradeStepsRetries(c *gc.C) {
 	assertUpgradeComplete(c, context)
 }
 
+func (s *UpgradeSuite) TestUpgradeStepsReported(c *gc.C) {
+	fakePerformUpgrade := func(_ version.Number, _ []upgrades.Target, ctx upgrades.Context) error {
+		ctx.ReportStep("step 1")
+		ctx.ReportStep("step 2")
+		return nil
+	}
+	s.PatchValue(&upgradesPerformUpgrade, fakePerformUpgrade)
+
+	workerErr, _, agent, context := s.runUpgradeWorker(c, multiwatcher.JobHostUnits)
+
+	c.Check(workerErr, gc.IsNil)
+	expected := s.makeExpectedStatusCalls(0, succeeds, "")
+	expected = append(expected[:1], append([]MachineStatusCall{{
+		params.StatusStarted,
+		fmt.Sprintf("upgrading to %s: step 1", version.Current),
+	}, {
+		params.StatusStarted,
+		fmt.Sprintf("upgrading to %s: step 2", version.Current),
+	}}, expected[1:]...)...)
+	c.Assert(agent.MachineStatusCalls, jc.DeepEquals, expected)
+	assertUpgradeComplete(c, context)
+}
+
 func (s *UpgradeSuite) TestOtherUpgradeRunFailure(c *gc.C) {
 	// This test checks what happens something other than the upgrade
 	// steps themselves fails, ensuring the something is logged and
@@ -0,0 +1,96 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	cmdutil "github.com/juju/juju/cmd/jujud/util"
+	"github.com/juju/juju/logging"
+)
+
+// LoggingConfigCommand reads or changes the logging configuration of
+// a running agent.
+type LoggingConfigCommand struct {
+	cmd.CommandBase
+	dataDir string
+	tag     names.Tag
+	config  string
+	replace bool
+}
+
+const loggingConfigDoc = `
+Show the logging configuration of a running agent on this machine, or,
+if a configuration is given, change it. For example
+
+  jujud logging-config machine-0 juju.worker.uniter=DEBUG
+
+sets the level of the uniter's logs without changing any others; with
+--replace, all the other levels are reset first. The change lasts until
+the agent restarts, or until the environment's logging-config changes.
+`
+
+// NewLoggingConfigCommand returns a new LoggingConfigCommand.
+func NewLoggingConfigCommand() *LoggingConfigCommand {
+	return &LoggingConfigCommand{}
+}
+
+// Info returns usage information for the command.
+func (c *LoggingConfigCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "logging-config",
+		Args:    "<agent-tag> [<logging-config>]",
+		Purpose: "show or change a running agent's logging configuration",
+		Doc:     loggingConfigDoc,
+	}
+}
+
+func (c *LoggingConfigCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.dataDir, "data-dir", cmdutil.DataDir, "directory for juju data")
+	f.BoolVar(&c.replace, "replace", false, "reset all the levels before applying the configuration")
+}
+
+func (c *LoggingConfigCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("missing agent tag")
+	}
+	tag, err := names.ParseTag(args[0])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.tag = tag
+	if len(args) > 1 {
+		c.config, args = args[1], args[2:]
+		if err := logging.ValidateConfig(c.config); err != nil {
+			return errors.Annotate(err, "invalid logging config")
+		}
+	} else {
+		args = args[1:]
+		if c.replace {
+			return errors.New("--replace needs a logging config")
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *LoggingConfigCommand) Run(ctx *cmd.Context) error {
+	socketPath := logging.ConfigSocketPath(c.dataDir, c.tag.String())
+	result, err := logging.SetRemoteConfig(socketPath, logging.SetConfigArgs{
+		Config:  c.config,
+		Replace: c.replace,
+	})
+	if err != nil {
+		return errors.Annotatef(err, "cannot reach %s", c.tag)
+	}
+	if c.config != "" {
+		fmt.Fprintf(ctx.Stdout, "previous: %s\n", result.Previous)
+	}
+	fmt.Fprintf(ctx.Stdout, "current: %s\n", result.Current)
+	return nil
+}
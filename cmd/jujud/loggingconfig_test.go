@@ -0,0 +1,93 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type LoggingConfigSuite struct {
+	testing.BaseSuite
+
+	dataDir string
+}
+
+var _ = gc.Suite(&LoggingConfigSuite{})
+
+func (s *LoggingConfigSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	loggo.ResetLoggers()
+	s.AddCleanup(func(*gc.C) { loggo.ResetLoggers() })
+
+	s.dataDir = c.MkDir()
+	err := os.MkdirAll(filepath.Join(s.dataDir, "agents", "machine-0"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	listener, err := logging.NewConfigListener(logging.ConfigSocketPath(s.dataDir, "machine-0"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { c.Check(listener.Close(), jc.ErrorIsNil) })
+}
+
+func (s *LoggingConfigSuite) run(c *gc.C, args ...string) (string, error) {
+	args = append([]string{"--data-dir", s.dataDir}, args...)
+	ctx, err := testing.RunCommand(c, NewLoggingConfigCommand(), args...)
+	return testing.Stdout(ctx), err
+}
+
+func (s *LoggingConfigSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "missing agent tag",
+	}, {
+		args: []string{"machine0"},
+		err:  `"machine0" is not a valid tag`,
+	}, {
+		args: []string{"machine-0", "juju=LOUD"},
+		err:  `invalid logging config: unknown severity level "LOUD"`,
+	}, {
+		args: []string{"--replace", "machine-0"},
+		err:  "--replace needs a logging config",
+	}, {
+		args: []string{"machine-0", "juju=DEBUG", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := s.run(c, test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *LoggingConfigSuite) TestShow(c *gc.C) {
+	out, err := s.run(c, "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, gc.Equals, "current: <root>=WARNING\n")
+}
+
+func (s *LoggingConfigSuite) TestSet(c *gc.C) {
+	out, err := s.run(c, "machine-0", "juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, gc.Equals, "previous: <root>=WARNING\ncurrent: <root>=WARNING;juju.worker=DEBUG\n")
+	c.Check(loggo.GetLogger("juju.worker").LogLevel(), gc.Equals, loggo.DEBUG)
+
+	out, err = s.run(c, "--replace", "machine-0", "juju.state=TRACE")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, gc.Equals, "previous: <root>=WARNING;juju.worker=DEBUG\ncurrent: <root>=WARNING;juju.state=TRACE\n")
+}
+
+func (s *LoggingConfigSuite) TestNoAgent(c *gc.C) {
+	_, err := s.run(c, "unit-mysql-0")
+	c.Assert(err, gc.ErrorMatches, `cannot reach unit-mysql-0: .*`)
+}
@@ -147,6 +147,10 @@ func jujuDMain(args []string, ctx *cmd.Context) (code int, err error) {
 
 	jujud.Register(agentcmd.NewUnitAgent(ctx, logCh))
 
+	jujud.Register(NewVerifyServiceCommand())
+
+	jujud.Register(NewLoggingConfigCommand())
+
 	code = cmd.Main(jujud, ctx, args[1:])
 	return code, nil
 }
@@ -99,7 +99,7 @@ func (s *MainSuite) TestParseErrors(c *gc.C) {
 	msgf := "flag provided but not defined: --cheese"
 	checkMessage(c, msgf, "--cheese", "cavitate")
 
-	cmds := []string{"bootstrap-state", "unit", "machine"}
+	cmds := []string{"bootstrap-state", "unit", "machine", "verify-service", "logging-config"}
 	for _, cmd := range cmds {
 		checkMessage(c, msgf, cmd, "--cheese")
 	}
@@ -116,6 +116,12 @@ func (s *MainSuite) TestParseErrors(c *gc.C) {
 	checkMessage(c, msga, "machine",
 		"--machine-id", "42",
 		"toastie")
+	checkMessage(c, msga, "verify-service",
+		"conf.yaml",
+		"toastie")
+	checkMessage(c, msga, "logging-config",
+		"machine-0", "juju=DEBUG",
+		"toastie")
 }
 
 var expectedProviders = []string{
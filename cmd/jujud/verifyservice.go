@@ -0,0 +1,91 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/series"
+	goyaml "gopkg.in/yaml.v2"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/common"
+)
+
+// VerifyServiceCommand checks a service conf against an init system
+// before the service is installed.
+type VerifyServiceCommand struct {
+	cmd.CommandBase
+	initSystem string
+	filename   string
+}
+
+const verifyServiceDoc = `
+Check that the service described in the conf file could be installed
+on this host, without installing it. The file holds YAML, keyed by the
+lower-cased names of the service conf fields:
+
+  desc: juju agent for machine-0
+  execstart: /var/lib/juju/tools/machine-0/jujud machine --machine-id 0
+  after: [network-online.target]
+
+When checking against systemd the generated unit is also checked with
+"systemd-analyze verify", if it is installed, which reports missing
+executables and unknown directives.
+`
+
+// NewVerifyServiceCommand returns a new VerifyServiceCommand.
+func NewVerifyServiceCommand() *VerifyServiceCommand {
+	return &VerifyServiceCommand{}
+}
+
+// Info returns usage information for the command.
+func (c *VerifyServiceCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "verify-service",
+		Args:    "<conf-file>",
+		Purpose: "check a service conf before installing it",
+		Doc:     verifyServiceDoc,
+	}
+}
+
+func (c *VerifyServiceCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.initSystem, "init-system", "", "check against this init system rather than the local one")
+}
+
+func (c *VerifyServiceCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("missing conf file")
+	}
+	c.filename = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *VerifyServiceCommand) Run(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.filename))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var conf common.Conf
+	if err := goyaml.Unmarshal(data, &conf); err != nil {
+		return errors.Annotatef(err, "cannot parse %q", c.filename)
+	}
+
+	initSystem := c.initSystem
+	if initSystem == "" {
+		initSystem, err = service.VersionInitSystem(series.HostSeries())
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := service.Validate(conf, initSystem); err != nil {
+		return errors.Annotatef(err, "conf not valid for %s", initSystem)
+	}
+	fmt.Fprintf(ctx.Stdout, "conf is valid for %s\n", initSystem)
+	return nil
+}
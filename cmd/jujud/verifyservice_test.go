@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+)
+
+type VerifyServiceSuite struct {
+	testing.BaseSuite
+
+	dir string
+}
+
+var _ = gc.Suite(&VerifyServiceSuite{})
+
+func (s *VerifyServiceSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.dir = c.MkDir()
+}
+
+func (s *VerifyServiceSuite) writeConf(c *gc.C, data string) {
+	err := ioutil.WriteFile(filepath.Join(s.dir, "conf.yaml"), []byte(data), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *VerifyServiceSuite) run(c *gc.C, args ...string) (string, error) {
+	ctx, err := testing.RunCommandInDir(c, NewVerifyServiceCommand(), args, s.dir)
+	return testing.Stdout(ctx), err
+}
+
+func (s *VerifyServiceSuite) TestMissingFile(c *gc.C) {
+	_, err := s.run(c)
+
+	c.Check(err, gc.ErrorMatches, "missing conf file")
+}
+
+func (s *VerifyServiceSuite) TestValid(c *gc.C) {
+	s.writeConf(c, `
+desc: juju agent for machine-0
+execstart: /var/lib/juju/tools/machine-0/jujud machine --machine-id 0
+after: [network-online.target]
+`)
+
+	out, err := s.run(c, "--init-system", "upstart", "conf.yaml")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(out, gc.Equals, "conf is valid for upstart\n")
+}
+
+func (s *VerifyServiceSuite) TestInvalid(c *gc.C) {
+	s.writeConf(c, `
+desc: juju agent for machine-0
+execstart: jujud machine --machine-id 0
+`)
+
+	_, err := s.run(c, "--init-system", "upstart", "conf.yaml")
+
+	c.Check(err, gc.ErrorMatches, `conf not valid for upstart: .*relative path in ExecStart \(jujud\) not valid`)
+}
+
+func (s *VerifyServiceSuite) TestBadYAML(c *gc.C) {
+	s.writeConf(c, "desc: [")
+
+	_, err := s.run(c, "--init-system", "upstart", "conf.yaml")
+
+	c.Check(err, gc.ErrorMatches, `cannot parse "conf.yaml": .*`)
+}
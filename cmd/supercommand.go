@@ -16,7 +16,7 @@ import (
 func init() {
 	// If the environment key is empty, ConfigureLoggers returns nil and does
 	// nothing.
-	err := loggo.ConfigureLoggers(os.Getenv(osenv.JujuStartupLoggingConfigEnvKey))
+	err := loggo.ConfigureLoggers(osenv.JujuStartupLoggingConfigVar.Value())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR parsing %s: %s\n\n", osenv.JujuStartupLoggingConfigEnvKey, err)
 	}
@@ -31,7 +31,7 @@ var logger = loggo.GetLogger("juju.cmd")
 // - The command emits a log message when a command runs.
 func NewSuperCommand(p cmd.SuperCommandParams) *cmd.SuperCommand {
 	p.Log = &cmd.Log{
-		DefaultConfig: os.Getenv(osenv.JujuLoggingConfigEnvKey),
+		DefaultConfig: osenv.JujuLoggingConfigVar.Value(),
 	}
 	current := version.Binary{
 		Number: version.Current,
@@ -56,4 +56,7 @@ func NewSubSuperCommand(p cmd.SuperCommandParams) *cmd.SuperCommand {
 
 func runNotifier(name string) {
 	logger.Infof("running %s [%s %s]", name, version.Current, version.Compiler)
+	if err := osenv.Validate(); err != nil {
+		logger.Warningf("%v", err)
+	}
 }
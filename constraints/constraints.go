@@ -20,16 +20,20 @@ import (
 // The following constants list the supported constraint attribute names, as defined
 // by the fields in the Value struct.
 const (
-	Arch         = "arch"
-	Container    = "container"
-	CpuCores     = "cpu-cores"
-	CpuPower     = "cpu-power"
-	Mem          = "mem"
-	RootDisk     = "root-disk"
-	Tags         = "tags"
-	InstanceType = "instance-type"
-	Networks     = "networks"
-	Spaces       = "spaces"
+	Arch           = "arch"
+	Container      = "container"
+	CpuCores       = "cpu-cores"
+	CpuPower       = "cpu-power"
+	Mem            = "mem"
+	RootDisk       = "root-disk"
+	Tags           = "tags"
+	InstanceType   = "instance-type"
+	Networks       = "networks"
+	Spaces         = "spaces"
+	Zones          = "zones"
+	RootDiskSource = "root-disk-source"
+	VirtType       = "virt-type"
+	InstanceRole   = "instance-role"
 )
 
 // Value describes a user's requirements of the hardware on which units
@@ -88,6 +92,24 @@ type Value struct {
 	// TODO(dimitern): Drop this as soon as spaces can be used for
 	// deployments instead.
 	Networks *[]string `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	// Zones, if not nil, holds a list of availability zones, one of
+	// which the machine must be started in.
+	Zones *[]string `json:"zones,omitempty" yaml:"zones,omitempty"`
+
+	// RootDiskSource, if not nil or empty, names the provider-specific
+	// storage, such as a vSphere datastore, in which the machine's
+	// root disk must be created.
+	RootDiskSource *string `json:"root-disk-source,omitempty" yaml:"root-disk-source,omitempty"`
+
+	// VirtType, if not nil or empty, indicates that a machine must use
+	// the named type of virtualisation, such as "hvm" on EC2.
+	VirtType *string `json:"virt-type,omitempty" yaml:"virt-type,omitempty"`
+
+	// InstanceRole, if not nil or empty, names the cloud role, such as
+	// an EC2 IAM instance profile, that the machine must be started
+	// with.
+	InstanceRole *string `json:"instance-role,omitempty" yaml:"instance-role,omitempty"`
 }
 
 // fieldNames records a mapping from the constraint tag to struct field name.
@@ -167,6 +189,29 @@ func (v *Value) HaveSpaces() bool {
 	return v.Spaces != nil && len(*v.Spaces) > 0
 }
 
+// HaveZones returns whether any availability zones were specified.
+func (v *Value) HaveZones() bool {
+	return v.Zones != nil && len(*v.Zones) > 0
+}
+
+// HasRootDiskSource returns true if the constraints.Value specifies
+// a root disk source.
+func (v *Value) HasRootDiskSource() bool {
+	return v.RootDiskSource != nil && *v.RootDiskSource != ""
+}
+
+// HasVirtType returns true if the constraints.Value specifies a
+// virtualisation type.
+func (v *Value) HasVirtType() bool {
+	return v.VirtType != nil && *v.VirtType != ""
+}
+
+// HasInstanceRole returns true if the constraints.Value specifies an
+// instance role.
+func (v *Value) HasInstanceRole() bool {
+	return v.InstanceRole != nil && *v.InstanceRole != ""
+}
+
 // TODO(dimitern): Drop the following 3 methods once spaces can be
 // used as deployment constraints.
 
@@ -238,6 +283,19 @@ func (v Value) String() string {
 		s := strings.Join(*v.Networks, ",")
 		strs = append(strs, "networks="+s)
 	}
+	if v.Zones != nil {
+		s := strings.Join(*v.Zones, ",")
+		strs = append(strs, "zones="+s)
+	}
+	if v.RootDiskSource != nil {
+		strs = append(strs, "root-disk-source="+*v.RootDiskSource)
+	}
+	if v.VirtType != nil {
+		strs = append(strs, "virt-type="+*v.VirtType)
+	}
+	if v.InstanceRole != nil {
+		strs = append(strs, "instance-role="+*v.InstanceRole)
+	}
 	return strings.Join(strs, " ")
 }
 
@@ -281,6 +339,20 @@ func (v Value) GoString() string {
 	} else if v.Networks != nil {
 		values = append(values, "Networks: (*[]string)(nil)")
 	}
+	if v.Zones != nil && *v.Zones != nil {
+		values = append(values, fmt.Sprintf("Zones: %q", *v.Zones))
+	} else if v.Zones != nil {
+		values = append(values, "Zones: (*[]string)(nil)")
+	}
+	if v.RootDiskSource != nil {
+		values = append(values, fmt.Sprintf("RootDiskSource: %q", *v.RootDiskSource))
+	}
+	if v.VirtType != nil {
+		values = append(values, fmt.Sprintf("VirtType: %q", *v.VirtType))
+	}
+	if v.InstanceRole != nil {
+		values = append(values, fmt.Sprintf("InstanceRole: %q", *v.InstanceRole))
+	}
 	return fmt.Sprintf("{%s}", strings.Join(values, ", "))
 }
 
@@ -422,6 +494,14 @@ func (v *Value) setRaw(raw string) error {
 		err = v.setSpaces(str)
 	case Networks:
 		err = v.setNetworks(str)
+	case Zones:
+		err = v.setZones(str)
+	case RootDiskSource:
+		err = v.setRootDiskSource(str)
+	case VirtType:
+		err = v.setVirtType(str)
+	case InstanceRole:
+		err = v.setInstanceRole(str)
 	default:
 		return errors.Errorf("unknown constraint %q", name)
 	}
@@ -482,6 +562,14 @@ func (v *Value) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			if err == nil {
 				v.Networks = networks
 			}
+		case Zones:
+			v.Zones, err = parseYamlStrings("zones", val)
+		case RootDiskSource:
+			v.RootDiskSource = &vstr
+		case VirtType:
+			v.VirtType = &vstr
+		case InstanceRole:
+			v.InstanceRole = &vstr
 		default:
 			return errors.Errorf("unknown constraint value: %v", k)
 		}
@@ -573,6 +661,38 @@ func (v *Value) setTags(str string) error {
 	return nil
 }
 
+func (v *Value) setZones(str string) error {
+	if v.Zones != nil {
+		return errors.Errorf("already set")
+	}
+	v.Zones = parseCommaDelimited(str)
+	return nil
+}
+
+func (v *Value) setRootDiskSource(str string) error {
+	if v.RootDiskSource != nil {
+		return errors.Errorf("already set")
+	}
+	v.RootDiskSource = &str
+	return nil
+}
+
+func (v *Value) setVirtType(str string) error {
+	if v.VirtType != nil {
+		return errors.Errorf("already set")
+	}
+	v.VirtType = &str
+	return nil
+}
+
+func (v *Value) setInstanceRole(str string) error {
+	if v.InstanceRole != nil {
+		return errors.Errorf("already set")
+	}
+	v.InstanceRole = &str
+	return nil
+}
+
 func (v *Value) setSpaces(str string) error {
 	if v.Spaces != nil {
 		return errors.Errorf("already set")
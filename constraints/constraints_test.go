@@ -301,6 +301,61 @@ var parseConstraintsTests = []struct {
 		args:    []string{"networks="},
 	},
 
+	// zones
+	{
+		summary: "single zone",
+		args:    []string{"zones=us-east-1a"},
+	}, {
+		summary: "multiple zones",
+		args:    []string{"zones=us-east-1a,us-east-1c"},
+	}, {
+		summary: "no zones",
+		args:    []string{"zones="},
+	}, {
+		summary: "double set zones together",
+		args:    []string{"zones=a zones=b"},
+		err:     `bad "zones" constraint: already set`,
+	},
+
+	// root-disk-source
+	{
+		summary: "set root-disk-source",
+		args:    []string{"root-disk-source=datastore1"},
+	}, {
+		summary: "root-disk-source empty",
+		args:    []string{"root-disk-source="},
+	}, {
+		summary: "double set root-disk-source together",
+		args:    []string{"root-disk-source=a root-disk-source=b"},
+		err:     `bad "root-disk-source" constraint: already set`,
+	},
+
+	// virt-type
+	{
+		summary: "set virt-type",
+		args:    []string{"virt-type=hvm"},
+	}, {
+		summary: "virt-type empty",
+		args:    []string{"virt-type="},
+	}, {
+		summary: "double set virt-type separately",
+		args:    []string{"virt-type=hvm", "virt-type=pv"},
+		err:     `bad "virt-type" constraint: already set`,
+	},
+
+	// instance-role
+	{
+		summary: "set instance-role",
+		args:    []string{"instance-role=juju-machine"},
+	}, {
+		summary: "instance-role empty",
+		args:    []string{"instance-role="},
+	}, {
+		summary: "double set instance-role together",
+		args:    []string{"instance-role=a instance-role=b"},
+		err:     `bad "instance-role" constraint: already set`,
+	},
+
 	// instance type
 	{
 		summary: "set instance type",
@@ -410,6 +465,32 @@ func (s *ConstraintsSuite) TestIncludeExcludeAndHaveSpaces(c *gc.C) {
 	c.Check(con.HaveNetworks(), jc.IsFalse)
 }
 
+func (s *ConstraintsSuite) TestHaveZones(c *gc.C) {
+	con := constraints.MustParse("zones=us-east-1a,us-east-1c")
+	c.Assert(con.Zones, gc.Not(gc.IsNil))
+	c.Check(*con.Zones, jc.DeepEquals, []string{"us-east-1a", "us-east-1c"})
+	c.Check(con.HaveZones(), jc.IsTrue)
+	con = constraints.MustParse("zones=")
+	c.Check(con.HaveZones(), jc.IsFalse)
+	con = constraints.MustParse("mem=4G")
+	c.Check(con.HaveZones(), jc.IsFalse)
+}
+
+func (s *ConstraintsSuite) TestHasRootDiskSourceVirtTypeAndInstanceRole(c *gc.C) {
+	cons := constraints.MustParse("arch=amd64")
+	c.Check(cons.HasRootDiskSource(), jc.IsFalse)
+	c.Check(cons.HasVirtType(), jc.IsFalse)
+	c.Check(cons.HasInstanceRole(), jc.IsFalse)
+	cons = constraints.MustParse("root-disk-source= virt-type= instance-role=")
+	c.Check(cons.HasRootDiskSource(), jc.IsFalse)
+	c.Check(cons.HasVirtType(), jc.IsFalse)
+	c.Check(cons.HasInstanceRole(), jc.IsFalse)
+	cons = constraints.MustParse("root-disk-source=datastore1 virt-type=hvm instance-role=juju")
+	c.Check(cons.HasRootDiskSource(), jc.IsTrue)
+	c.Check(cons.HasVirtType(), jc.IsTrue)
+	c.Check(cons.HasInstanceRole(), jc.IsTrue)
+}
+
 func (s *ConstraintsSuite) TestIncludeExcludeAndHaveNetworks(c *gc.C) {
 	con := constraints.MustParse("networks=net1,^net2,net3,^net4")
 	c.Assert(con.Networks, gc.Not(gc.IsNil))
@@ -534,19 +615,32 @@ var constraintsRoundtripTests = []roundTrip{
 	{"Networks1", constraints.Value{Networks: nil}},
 	{"Networks2", constraints.Value{Networks: &[]string{}}},
 	{"Networks3", constraints.Value{Networks: &[]string{"net1", "^net2"}}},
+	{"Zones1", constraints.Value{Zones: nil}},
+	{"Zones2", constraints.Value{Zones: &[]string{}}},
+	{"Zones3", constraints.Value{Zones: &[]string{"us-east-1a", "us-east-1c"}}},
+	{"RootDiskSource1", constraints.Value{RootDiskSource: strp("")}},
+	{"RootDiskSource2", constraints.Value{RootDiskSource: strp("datastore1")}},
+	{"VirtType1", constraints.Value{VirtType: strp("")}},
+	{"VirtType2", constraints.Value{VirtType: strp("hvm")}},
+	{"InstanceRole1", constraints.Value{InstanceRole: strp("")}},
+	{"InstanceRole2", constraints.Value{InstanceRole: strp("juju-machine")}},
 	{"InstanceType1", constraints.Value{InstanceType: strp("")}},
 	{"InstanceType2", constraints.Value{InstanceType: strp("foo")}},
 	{"All", constraints.Value{
-		Arch:         strp("i386"),
-		Container:    ctypep("lxc"),
-		CpuCores:     uint64p(4096),
-		CpuPower:     uint64p(9001),
-		Mem:          uint64p(18000000000),
-		RootDisk:     uint64p(24000000000),
-		Tags:         &[]string{"foo", "bar"},
-		Spaces:       &[]string{"space1", "^space2"},
-		Networks:     &[]string{"net1", "^net2"},
-		InstanceType: strp("foo"),
+		Arch:           strp("i386"),
+		Container:      ctypep("lxc"),
+		CpuCores:       uint64p(4096),
+		CpuPower:       uint64p(9001),
+		Mem:            uint64p(18000000000),
+		RootDisk:       uint64p(24000000000),
+		Tags:           &[]string{"foo", "bar"},
+		Spaces:         &[]string{"space1", "^space2"},
+		Networks:       &[]string{"net1", "^net2"},
+		Zones:          &[]string{"us-east-1a"},
+		RootDiskSource: strp("datastore1"),
+		VirtType:       strp("hvm"),
+		InstanceRole:   strp("juju-machine"),
+		InstanceType:   strp("foo"),
 	}},
 }
 
@@ -744,6 +838,26 @@ func (s *ConstraintsSuite) TestAttributesWithValues(c *gc.C) {
 		} else {
 			assertMissing("networks")
 		}
+		if cons.Zones != nil {
+			c.Check(obtained["zones"], gc.DeepEquals, *cons.Zones)
+		} else {
+			assertMissing("zones")
+		}
+		if cons.RootDiskSource != nil {
+			c.Check(obtained["root-disk-source"], gc.Equals, *cons.RootDiskSource)
+		} else {
+			assertMissing("root-disk-source")
+		}
+		if cons.VirtType != nil {
+			c.Check(obtained["virt-type"], gc.Equals, *cons.VirtType)
+		} else {
+			assertMissing("virt-type")
+		}
+		if cons.InstanceRole != nil {
+			c.Check(obtained["instance-role"], gc.Equals, *cons.InstanceRole)
+		} else {
+			assertMissing("instance-role")
+		}
 		if cons.InstanceType != nil {
 			c.Check(obtained["instance-type"], gc.Equals, *cons.InstanceType)
 		} else {
@@ -313,6 +313,27 @@ var mergeTests = []struct {
 		reds:         []string{"mem", "arch"},
 		blues:        []string{"instance-type"},
 		expected:     "root-disk=8G cpu-cores=4 arch=amd64 mem=4G",
+	}, {
+		desc:         "zones, root-disk-source, virt-type and instance-role from fallback",
+		consFallback: "zones=a,b root-disk-source=ds1 virt-type=hvm instance-role=r1",
+		expected:     "zones=a,b root-disk-source=ds1 virt-type=hvm instance-role=r1",
+	}, {
+		desc:         "zones, root-disk-source, virt-type and instance-role with ignored fallback",
+		consFallback: "zones=a,b root-disk-source=ds1 virt-type=hvm instance-role=r1",
+		cons:         "zones=c root-disk-source=ds2 virt-type=pv instance-role=r2",
+		expected:     "zones=c root-disk-source=ds2 virt-type=pv instance-role=r2",
+	}, {
+		desc:         "empty values override fallback",
+		consFallback: "zones=a,b root-disk-source=ds1 virt-type=hvm instance-role=r1",
+		cons:         "zones= root-disk-source= virt-type= instance-role=",
+		expected:     "zones= root-disk-source= virt-type= instance-role=",
+	}, {
+		desc:         "virt-type conflict masked from fallback",
+		consFallback: "virt-type=pv mem=4G",
+		cons:         "instance-type=bar",
+		reds:         []string{"virt-type"},
+		blues:        []string{"instance-type"},
+		expected:     "instance-type=bar mem=4G",
 	},
 }
 
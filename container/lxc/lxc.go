@@ -22,7 +22,6 @@ import (
 	"github.com/juju/names"
 	"github.com/juju/utils"
 	"github.com/juju/utils/arch"
-	"github.com/juju/utils/keyvalues"
 	"github.com/juju/utils/series"
 	"github.com/juju/utils/symlink"
 	"launchpad.net/golxc"
@@ -33,6 +32,7 @@ import (
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/lxc/lxcutils"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/storage/looputil"
 )
 
@@ -432,11 +432,6 @@ func createContainer(
 // the script is invoked by the lxc template bash script.
 // It returns a slice of env variables to pass to the lxc create command.
 func wgetEnvironment(caCert []byte) (execEnv []string, closer func(), _ error) {
-	env := os.Environ()
-	kv, err := keyvalues.Parse(env, true)
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
 	// Create a wget bash script in a temporary directory.
 	tmpDir, err := ioutil.TempDir("", "wget")
 	if err != nil {
@@ -466,12 +461,7 @@ func wgetEnvironment(caCert []byte) (execEnv []string, closer func(), _ error) {
 	}
 
 	// Update the path to point to the script.
-	for k, v := range kv {
-		if strings.ToUpper(k) == "PATH" {
-			v = strings.Join([]string{tmpDir, v}, string(os.PathListSeparator))
-		}
-		execEnv = append(execEnv, fmt.Sprintf("%s=%s", k, v))
-	}
+	execEnv = osenv.NewEnvBuilder(os.Environ()).PrependPath(tmpDir).Environ()
 	return execEnv, closer, nil
 }
 
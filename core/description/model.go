@@ -0,0 +1,215 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package description defines the versioned format in which a whole
+// model (an environment's machines, services, units, relations,
+// storage and settings) is described, so that it can be exported from
+// one controller and recreated in another.
+package description
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/yaml.v2"
+)
+
+// CurrentVersion is the version of the format written by Serialize.
+// It must be incremented whenever the meaning of a field changes or a
+// field is removed, so that an older controller refuses a description
+// it would misread.
+const CurrentVersion = 1
+
+// Model describes a whole model.
+type Model struct {
+	// Version is the version of the format the model is described in.
+	Version int `yaml:"version"`
+
+	// Owner is the name of the user that owns the model.
+	Owner string `yaml:"owner"`
+
+	// Config holds the model's configuration attributes.
+	Config map[string]interface{} `yaml:"config"`
+
+	// Constraints holds the model's constraints, in the format
+	// understood by constraints.Parse.
+	Constraints string `yaml:"constraints,omitempty"`
+
+	Machines  []Machine         `yaml:"machines,omitempty"`
+	Services  []Service         `yaml:"services,omitempty"`
+	Relations []Relation        `yaml:"relations,omitempty"`
+	Storage   []StorageInstance `yaml:"storage,omitempty"`
+}
+
+// Machine describes a machine, which may be a container.
+type Machine struct {
+	Id            string   `yaml:"id"`
+	Nonce         string   `yaml:"nonce,omitempty"`
+	Series        string   `yaml:"series"`
+	ContainerType string   `yaml:"container-type,omitempty"`
+	Jobs          []string `yaml:"jobs"`
+	Placement     string   `yaml:"placement,omitempty"`
+	Constraints   string   `yaml:"constraints,omitempty"`
+
+	// InstanceId and Hardware are empty if the machine has not been
+	// provisioned. Hardware is in the format understood by
+	// instance.ParseHardware.
+	InstanceId string `yaml:"instance-id,omitempty"`
+	Hardware   string `yaml:"hardware,omitempty"`
+}
+
+// Service describes a service and its units.
+type Service struct {
+	Name        string `yaml:"name"`
+	Series      string `yaml:"series"`
+	Subordinate bool   `yaml:"subordinate,omitempty"`
+	CharmURL    string `yaml:"charm-url"`
+	ForceCharm  bool   `yaml:"force-charm,omitempty"`
+	Exposed     bool   `yaml:"exposed,omitempty"`
+	MinUnits    int    `yaml:"min-units,omitempty"`
+	Constraints string `yaml:"constraints,omitempty"`
+
+	// Settings holds the charm settings that have been set for the
+	// service.
+	Settings map[string]interface{} `yaml:"settings,omitempty"`
+
+	// LeaderSettings holds the settings written by the service's
+	// leader.
+	LeaderSettings map[string]string `yaml:"leader-settings,omitempty"`
+
+	Units []Unit `yaml:"units,omitempty"`
+}
+
+// Unit describes a unit of a service.
+type Unit struct {
+	Name string `yaml:"name"`
+
+	// Machine is the id of the machine the unit is assigned to, if
+	// any.
+	Machine string `yaml:"machine,omitempty"`
+
+	// Principal is the name of the principal unit of a subordinate
+	// unit.
+	Principal string `yaml:"principal,omitempty"`
+
+	// CharmURL is the URL of the charm the unit is running, if its
+	// agent has installed one.
+	CharmURL string `yaml:"charm-url,omitempty"`
+}
+
+// Relation describes a relation between services.
+type Relation struct {
+	Id        int        `yaml:"id"`
+	Key       string     `yaml:"key"`
+	Endpoints []Endpoint `yaml:"endpoints"`
+}
+
+// Endpoint describes one end of a relation.
+type Endpoint struct {
+	ServiceName string `yaml:"service-name"`
+	Name        string `yaml:"name"`
+	Role        string `yaml:"role"`
+	Interface   string `yaml:"interface"`
+	Scope       string `yaml:"scope"`
+
+	// UnitSettings holds the relation settings of each of the
+	// service's units in the relation's scope, by unit name.
+	UnitSettings map[string]map[string]interface{} `yaml:"unit-settings,omitempty"`
+}
+
+// StorageInstance describes a storage instance.
+type StorageInstance struct {
+	Id    string `yaml:"id"`
+	Kind  string `yaml:"kind"`
+	Owner string `yaml:"owner"`
+	Name  string `yaml:"name"`
+}
+
+// Serialize returns the model in the current version of the format,
+// after checking that it is consistent.
+func Serialize(model *Model) ([]byte, error) {
+	m := *model
+	m.Version = CurrentVersion
+	if err := m.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return yaml.Marshal(&m)
+}
+
+// Deserialize reads a model written by Serialize, checking that this
+// version of juju understands it and that it is consistent.
+func Deserialize(data []byte) (*Model, error) {
+	var model Model
+	if err := yaml.Unmarshal(data, &model); err != nil {
+		return nil, errors.Annotate(err, "cannot parse model description")
+	}
+	if model.Version != CurrentVersion {
+		return nil, errors.NotSupportedf("model description version %d", model.Version)
+	}
+	if err := model.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &model, nil
+}
+
+// Validate checks that the model is consistent: that names and ids are
+// unique, and that everything referred to is described. It does not
+// check that the model can be recreated in a particular controller.
+func (m *Model) Validate() error {
+	machines := set.NewStrings()
+	for _, machine := range m.Machines {
+		if machine.Id == "" {
+			return errors.NotValidf("machine with empty id")
+		}
+		if machines.Contains(machine.Id) {
+			return errors.NotValidf("duplicate machine %q", machine.Id)
+		}
+		machines.Add(machine.Id)
+	}
+
+	services := set.NewStrings()
+	units := set.NewStrings()
+	for _, service := range m.Services {
+		if service.Name == "" {
+			return errors.NotValidf("service with empty name")
+		}
+		if services.Contains(service.Name) {
+			return errors.NotValidf("duplicate service %q", service.Name)
+		}
+		services.Add(service.Name)
+		for _, unit := range service.Units {
+			if units.Contains(unit.Name) {
+				return errors.NotValidf("duplicate unit %q", unit.Name)
+			}
+			units.Add(unit.Name)
+			if unit.Machine != "" && !machines.Contains(unit.Machine) {
+				return errors.NotValidf("unit %q on unknown machine %q", unit.Name, unit.Machine)
+			}
+		}
+	}
+	for _, service := range m.Services {
+		for _, unit := range service.Units {
+			if unit.Principal != "" && !units.Contains(unit.Principal) {
+				return errors.NotValidf("unit %q with unknown principal %q", unit.Name, unit.Principal)
+			}
+		}
+	}
+
+	relations := set.NewStrings()
+	for _, relation := range m.Relations {
+		if relations.Contains(relation.Key) {
+			return errors.NotValidf("duplicate relation %q", relation.Key)
+		}
+		relations.Add(relation.Key)
+		for _, ep := range relation.Endpoints {
+			if !services.Contains(ep.ServiceName) {
+				return errors.NotValidf("relation %q with unknown service %q", relation.Key, ep.ServiceName)
+			}
+			for unit := range ep.UnitSettings {
+				if !units.Contains(unit) {
+					return errors.NotValidf("relation %q with unknown unit %q", relation.Key, unit)
+				}
+			}
+		}
+	}
+	return nil
+}
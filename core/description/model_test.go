@@ -0,0 +1,143 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package description_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/description"
+	"github.com/juju/juju/testing"
+)
+
+type modelSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&modelSuite{})
+
+func minimalModel() *description.Model {
+	return &description.Model{
+		Owner:  "admin@local",
+		Config: map[string]interface{}{"name": "foo", "type": "ec2"},
+		Machines: []description.Machine{{
+			Id:         "0",
+			Series:     "trusty",
+			Jobs:       []string{"JobHostUnits"},
+			InstanceId: "i-0",
+			Hardware:   "arch=amd64 mem=2048M",
+		}},
+		Services: []description.Service{{
+			Name:     "wordpress",
+			Series:   "trusty",
+			CharmURL: "cs:trusty/wordpress-3",
+			Settings: map[string]interface{}{"blog-title": "hello"},
+			Units:    []description.Unit{{Name: "wordpress/0", Machine: "0"}},
+		}, {
+			Name:        "logging",
+			Series:      "trusty",
+			Subordinate: true,
+			CharmURL:    "cs:trusty/logging-1",
+			Units:       []description.Unit{{Name: "logging/0", Principal: "wordpress/0"}},
+		}},
+		Relations: []description.Relation{{
+			Id:  1,
+			Key: "logging:info wordpress:juju-info",
+			Endpoints: []description.Endpoint{{
+				ServiceName: "wordpress",
+				Name:        "juju-info",
+				Role:        "provider",
+				Interface:   "juju-info",
+				Scope:       "container",
+				UnitSettings: map[string]map[string]interface{}{
+					"wordpress/0": {"private-address": "10.0.0.1"},
+				},
+			}, {
+				ServiceName: "logging",
+				Name:        "info",
+				Role:        "requirer",
+				Interface:   "juju-info",
+				Scope:       "container",
+			}},
+		}},
+	}
+}
+
+func (*modelSuite) TestRoundTrip(c *gc.C) {
+	model := minimalModel()
+	data, err := description.Serialize(model)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(model.Version, gc.Equals, 0)
+
+	read, err := description.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+	model.Version = description.CurrentVersion
+	c.Assert(read, jc.DeepEquals, model)
+}
+
+func (*modelSuite) TestDeserializeUnknownVersion(c *gc.C) {
+	_, err := description.Deserialize([]byte("version: 99\nowner: admin@local\n"))
+	c.Assert(err, gc.ErrorMatches, "model description version 99 not supported")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (*modelSuite) TestDeserializeInvalidYAML(c *gc.C) {
+	_, err := description.Deserialize([]byte("version: [\n"))
+	c.Assert(err, gc.ErrorMatches, "cannot parse model description: .*")
+}
+
+var validateTests = []struct {
+	about  string
+	modify func(*description.Model)
+	err    string
+}{{
+	about: "duplicate machine",
+	modify: func(m *description.Model) {
+		m.Machines = append(m.Machines, m.Machines[0])
+	},
+	err: `duplicate machine "0" not valid`,
+}, {
+	about: "unit on unknown machine",
+	modify: func(m *description.Model) {
+		m.Services[0].Units[0].Machine = "7"
+	},
+	err: `unit "wordpress/0" on unknown machine "7" not valid`,
+}, {
+	about: "unknown principal",
+	modify: func(m *description.Model) {
+		m.Services[1].Units[0].Principal = "mysql/0"
+	},
+	err: `unit "logging/0" with unknown principal "mysql/0" not valid`,
+}, {
+	about: "duplicate service",
+	modify: func(m *description.Model) {
+		m.Services[1].Name = "wordpress"
+	},
+	err: `duplicate service "wordpress" not valid`,
+}, {
+	about: "relation with unknown service",
+	modify: func(m *description.Model) {
+		m.Relations[0].Endpoints[1].ServiceName = "mysql"
+	},
+	err: `relation "logging:info wordpress:juju-info" with unknown service "mysql" not valid`,
+}, {
+	about: "relation with unknown unit",
+	modify: func(m *description.Model) {
+		m.Relations[0].Endpoints[0].UnitSettings["wordpress/1"] = nil
+	},
+	err: `relation "logging:info wordpress:juju-info" with unknown unit "wordpress/1" not valid`,
+}}
+
+func (*modelSuite) TestValidate(c *gc.C) {
+	for i, test := range validateTests {
+		c.Logf("test %d: %s", i, test.about)
+		model := minimalModel()
+		test.modify(model)
+		err := model.Validate()
+		c.Check(err, gc.ErrorMatches, test.err)
+		_, err = description.Serialize(model)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -106,3 +106,36 @@ type InstanceBroker interface {
 	// correct network configuration.
 	MaintainInstance(args StartInstanceParams) error
 }
+
+// InstanceLifecycle is implemented by InstanceBrokers that run
+// cloud-specific actions at points in an instance's life, such as
+// attaching it to a load balancer, registering it in DNS, or
+// deregistering it. The provisioner calls these methods around
+// StartInstance and StopInstances.
+type InstanceLifecycle interface {
+	// PreStartInstance is called before an instance is started. If
+	// it returns an error, the instance is not started.
+	PreStartInstance(args StartInstanceParams) error
+
+	// PostStartInstance is called after an instance has been
+	// started. If it returns an error, the instance is stopped.
+	PostStartInstance(args StartInstanceParams, result *StartInstanceResult) error
+
+	// PreStopInstances is called before the instances with the
+	// specified IDs are stopped. The instances are stopped even if
+	// it returns an error.
+	PreStopInstances(ids ...instance.Id) error
+}
+
+// InstanceBulkStopper is implemented by InstanceBrokers that can stop
+// many instances in a few provider API calls, rather than one call per
+// instance, so that destroying a large environment does not run into
+// the cloud's rate limits.
+type InstanceBulkStopper interface {
+	// BulkStopInstances shuts down the instances with the specified
+	// IDs, in batches as large as the provider allows. Unknown
+	// instance IDs are ignored. If some of the instances cannot be
+	// stopped, the others are still stopped and a
+	// *StopInstancesError is returned.
+	BulkStopInstances(ids ...instance.Id) error
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/juju/juju/cert"
 	"github.com/juju/juju/environs/tags"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/version"
 )
 
@@ -46,6 +48,16 @@ const (
 	// instance security groups.
 	FwNone = "none"
 
+	// SpreadZonePolicy requests that machines are started in the
+	// availability zone with the fewest machines of the same
+	// distribution group.
+	SpreadZonePolicy = "spread"
+
+	// PackZonePolicy requests that machines are started in the
+	// availability zone with the most machines of the same
+	// distribution group, filling zones one at a time.
+	PackZonePolicy = "pack"
+
 	// DefaultStatePort is the default port the state server is listening on.
 	DefaultStatePort int = 37017
 
@@ -97,6 +109,32 @@ const (
 	// config setting. Only non-zero, positive integer values will
 	// have effect.
 	DefaultLXCDefaultMTU = 0
+
+	// DefaultStatusHistoryMaxAge, DefaultActionResultsMaxAge and
+	// DefaultRunResultsMaxAge are the default number of hours for
+	// which status history, the results of completed actions, and the
+	// results of "juju run" are kept.
+	DefaultStatusHistoryMaxAge = 336
+	DefaultActionResultsMaxAge = 336
+	DefaultRunResultsMaxAge    = 336
+
+	// DefaultStatusHistoryMaxEntries, DefaultActionResultsMaxEntries
+	// and DefaultRunResultsMaxEntries are the default number of status
+	// history entries kept for each entity, of completed actions kept,
+	// and of "juju run" results kept.
+	DefaultStatusHistoryMaxEntries = 100
+	DefaultActionResultsMaxEntries = 10000
+	DefaultRunResultsMaxEntries    = 1000
+
+	// DefaultHookRetryMinDelay and DefaultHookRetryMaxDelay are the
+	// default number of seconds a unit agent waits before first
+	// retrying a failed hook, and the most it waits between retries.
+	DefaultHookRetryMinDelay = 5
+	DefaultHookRetryMaxDelay = 300
+
+	// DefaultHookRetryBackoffFactor is the default factor by which the
+	// delay between retries of a failed hook grows.
+	DefaultHookRetryBackoffFactor = 2
 )
 
 // TODO(katco-): Please grow this over time.
@@ -134,6 +172,12 @@ const (
 	// AptFtpProxyKey stores the key for this setting.
 	AptFtpProxyKey = "apt-ftp-proxy"
 
+	// PackageSourcesKey stores the key for this setting.
+	PackageSourcesKey = "package-sources"
+
+	// PackageSourceKeysKey stores the key for this setting.
+	PackageSourceKeysKey = "package-source-keys"
+
 	// NoProxyKey stores the key for this setting.
 	NoProxyKey = "no-proxy"
 
@@ -163,6 +207,58 @@ const (
 	// of k=v pairs, defining the tags for ResourceTags.
 	ResourceTagsKey = "resource-tags"
 
+	// ImageIdsKey is an optional list or space-separated string of
+	// [region/]series=image-id pairs, pinning the images used to
+	// start instances.
+	ImageIdsKey = "image-ids"
+
+	// AvailabilityZonePolicyKey stores the policy used to choose the
+	// availability zone in which to start an instance.
+	AvailabilityZonePolicyKey = "availability-zone-policy"
+
+	// StatusHistoryMaxAgeKey and StatusHistoryMaxEntriesKey store the
+	// maximum age in hours, and the maximum number for each entity, of
+	// status history entries kept by the history pruner. Zero means no
+	// limit.
+	StatusHistoryMaxAgeKey     = "status-history-max-age"
+	StatusHistoryMaxEntriesKey = "status-history-max-entries"
+
+	// ActionResultsMaxAgeKey and ActionResultsMaxEntriesKey store the
+	// maximum age in hours, and the maximum number, of completed
+	// actions kept by the history pruner. Zero means no limit.
+	ActionResultsMaxAgeKey     = "action-results-max-age"
+	ActionResultsMaxEntriesKey = "action-results-max-entries"
+
+	// RunResultsMaxAgeKey and RunResultsMaxEntriesKey store the
+	// maximum age in hours, and the maximum number, of "juju run"
+	// results kept by the history pruner. Zero means no limit.
+	RunResultsMaxAgeKey     = "run-results-max-age"
+	RunResultsMaxEntriesKey = "run-results-max-entries"
+
+	// ActionParallelismKey stores the maximum number of a unit's
+	// actions handed to its agent at once; further actions wait in the
+	// unit's queue. Zero means no limit.
+	ActionParallelismKey = "action-parallelism"
+
+	// AutomaticallyRetryHooksKey stores whether unit agents retry
+	// failed hooks by themselves, rather than waiting for the error
+	// to be resolved by the user.
+	AutomaticallyRetryHooksKey = "automatically-retry-hooks"
+
+	// HookRetryMaxAttemptsKey stores the number of times a failed hook
+	// is retried automatically before the unit agent gives up and
+	// waits for the error to be resolved. Zero means no limit.
+	HookRetryMaxAttemptsKey = "hook-retry-max-attempts"
+
+	// HookRetryMinDelayKey, HookRetryMaxDelayKey and
+	// HookRetryBackoffFactorKey store the backoff curve of automatic
+	// hook retries: the first retry waits the minimum delay in seconds,
+	// and each later one waits the factor times longer than the one
+	// before, up to the maximum delay.
+	HookRetryMinDelayKey      = "hook-retry-min-delay"
+	HookRetryMaxDelayKey      = "hook-retry-max-delay"
+	HookRetryBackoffFactorKey = "hook-retry-backoff-factor"
+
 	// For LXC containers, is the container allowed to mount block
 	// devices. A theoretical security issue, so must be explicitly
 	// allowed by the user.
@@ -405,13 +501,13 @@ func (c *Config) ensureUnitLogging() error {
 	// If the logging config hasn't been set, then look for the os environment
 	// variable, and failing that, get the config from loggo itself.
 	if loggingConfig == "" {
-		if environmentValue := os.Getenv(osenv.JujuLoggingConfigEnvKey); environmentValue != "" {
+		if environmentValue := osenv.JujuLoggingConfigVar.Value(); environmentValue != "" {
 			loggingConfig = environmentValue
 		} else {
 			loggingConfig = loggo.LoggerInfo()
 		}
 	}
-	levels, err := loggo.ParseConfigurationString(loggingConfig)
+	levels, err := logging.ParseConfig(loggingConfig)
 	if err != nil {
 		return err
 	}
@@ -596,7 +692,7 @@ func Validate(cfg, old *Config) error {
 
 	// If the logging config is set, make sure it is valid.
 	if v, ok := cfg.defined["logging-config"].(string); ok {
-		if _, err := loggo.ParseConfigurationString(v); err != nil {
+		if err := logging.ValidateConfig(v); err != nil {
 			return err
 		}
 	}
@@ -636,6 +732,10 @@ func Validate(cfg, old *Config) error {
 		return errors.Annotate(err, "validating resource tags")
 	}
 
+	if _, err := cfg.imageIds(); err != nil {
+		return errors.Annotate(err, "validating image ids")
+	}
+
 	// Check the immutable config values.  These can't change
 	if old != nil {
 		for _, attr := range immutableAttributes {
@@ -665,11 +765,40 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	// Package sources are only added when the package lists are
+	// refreshed.
+	if len(cfg.PackageSources()) > 0 && !cfg.EnableOSRefreshUpdate() {
+		return errors.Errorf("%s cannot be used when enable-os-refresh-update is false", PackageSourcesKey)
+	}
+
 	// Check LXCDefaultMTU is a positive integer, when set.
 	if lxcDefaultMTU, ok := cfg.LXCDefaultMTU(); ok && lxcDefaultMTU < 0 {
 		return errors.Errorf("%s: expected positive integer, got %v", LXCDefaultMTU, lxcDefaultMTU)
 	}
 
+	for _, key := range []string{
+		StatusHistoryMaxAgeKey,
+		StatusHistoryMaxEntriesKey,
+		ActionResultsMaxAgeKey,
+		ActionResultsMaxEntriesKey,
+		RunResultsMaxAgeKey,
+		RunResultsMaxEntriesKey,
+		ActionParallelismKey,
+		HookRetryMaxAttemptsKey,
+		HookRetryMinDelayKey,
+		HookRetryMaxDelayKey,
+	} {
+		if v, ok := cfg.defined[key].(int); ok && v < 0 {
+			return errors.Errorf("%s: expected non-negative integer, got %v", key, v)
+		}
+	}
+	if v, ok := cfg.defined[HookRetryBackoffFactorKey].(float64); ok && v < 1 {
+		return errors.Errorf("%s: expected number not less than 1, got %v", HookRetryBackoffFactorKey, v)
+	}
+	if opts := cfg.HookRetryStrategy(); opts.MinDelay > opts.MaxDelay {
+		return errors.Errorf("%s cannot be greater than %s", HookRetryMinDelayKey, HookRetryMaxDelayKey)
+	}
+
 	cfg.defined = ProcessDeprecatedAttributes(cfg.defined)
 	return nil
 }
@@ -968,6 +1097,27 @@ func (c *Config) AptMirror() string {
 	return c.asString("apt-mirror")
 }
 
+// PackageSources returns the additional package sources that machines
+// in the environment install packages from, one per line of the
+// package-sources setting: an apt source such as "ppa:juju/stable" or
+// "deb http://mirror.example.com/ubuntu trusty main", or the URL of a
+// yum repository.
+func (c *Config) PackageSources() []string {
+	var sources []string
+	for _, line := range strings.Split(c.asString(PackageSourcesKey), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sources = append(sources, line)
+		}
+	}
+	return sources
+}
+
+// PackageSourceKeys returns the ASCII-armored GPG public keys that the
+// packages from the additional package sources are signed with.
+func (c *Config) PackageSourceKeys() string {
+	return c.asString(PackageSourceKeysKey)
+}
+
 // BootstrapSSHOpts returns the SSH timeout and retry delays used
 // during bootstrap.
 func (c *Config) BootstrapSSHOpts() SSHTimeoutOpts {
@@ -1022,6 +1172,16 @@ func (c *Config) FirewallMode() string {
 	return c.mustString("firewall-mode")
 }
 
+// AvailabilityZonePolicy returns the policy used to choose the
+// availability zone in which to start an instance (SpreadZonePolicy
+// or PackZonePolicy). Machines are spread across zones by default.
+func (c *Config) AvailabilityZonePolicy() string {
+	if policy, ok := c.defined[AvailabilityZonePolicyKey].(string); ok && policy != "" {
+		return policy
+	}
+	return SpreadZonePolicy
+}
+
 // AgentVersion returns the proposed version number for the agent tools,
 // and whether it has been set. Once an environment is bootstrapped, this
 // must always be valid.
@@ -1054,6 +1214,39 @@ func (c *Config) ImageMetadataURL() (string, bool) {
 	return "", false
 }
 
+// PinnedImageId returns the id of the image pinned by the image-ids
+// setting for the series in the region, and whether there is one. An
+// image pinned for the series in the region takes precedence over one
+// pinned for the series in every region.
+func (c *Config) PinnedImageId(region, series string) (string, bool) {
+	ids, err := c.imageIds()
+	if err != nil {
+		panic(err) // should be prevented by Validate
+	}
+	if id, ok := ids[region+"/"+series]; ok {
+		return id, true
+	}
+	id, ok := ids[series]
+	return id, ok
+}
+
+func (c *Config) imageIds() (map[string]string, error) {
+	v, ok := c.defined[ImageIdsKey].(map[string]string)
+	if !ok {
+		return nil, nil
+	}
+	for k, id := range v {
+		parts := strings.Split(k, "/")
+		if len(parts) > 2 || parts[len(parts)-1] == "" || len(parts) == 2 && parts[0] == "" {
+			return nil, errors.Errorf("expected series or region/series, got %q", k)
+		}
+		if id == "" {
+			return nil, errors.Errorf("no image id for %q", k)
+		}
+	}
+	return v, nil
+}
+
 // Development returns whether the environment is in development mode.
 func (c *Config) Development() bool {
 	return c.defined["development"].(bool)
@@ -1201,6 +1394,74 @@ func (c *Config) CloudImageBaseURL() string {
 	return c.asString(CloudImageBaseURL)
 }
 
+// StatusHistoryRetention returns the maximum age of status history
+// entries to keep, and the number to keep for each entity. Zero values
+// mean no limit.
+func (c *Config) StatusHistoryRetention() (maxAge time.Duration, maxEntries int) {
+	return c.retention(
+		StatusHistoryMaxAgeKey, DefaultStatusHistoryMaxAge,
+		StatusHistoryMaxEntriesKey, DefaultStatusHistoryMaxEntries,
+	)
+}
+
+// ActionResultsRetention returns the maximum age and number of
+// completed actions to keep. Zero values mean no limit.
+func (c *Config) ActionResultsRetention() (maxAge time.Duration, maxEntries int) {
+	return c.retention(
+		ActionResultsMaxAgeKey, DefaultActionResultsMaxAge,
+		ActionResultsMaxEntriesKey, DefaultActionResultsMaxEntries,
+	)
+}
+
+// RunResultsRetention returns the maximum age and number of "juju run"
+// results to keep. Zero values mean no limit.
+func (c *Config) RunResultsRetention() (maxAge time.Duration, maxEntries int) {
+	return c.retention(
+		RunResultsMaxAgeKey, DefaultRunResultsMaxAge,
+		RunResultsMaxEntriesKey, DefaultRunResultsMaxEntries,
+	)
+}
+
+// ActionParallelism returns the maximum number of a unit's actions
+// handed to its agent at once. Zero means no limit.
+func (c *Config) ActionParallelism() int {
+	v, _ := c.defined[ActionParallelismKey].(int)
+	return v
+}
+
+// HookRetryStrategy returns how unit agents retry failed hooks.
+func (c *Config) HookRetryStrategy() HookRetryOpts {
+	opts := HookRetryOpts{
+		MinDelay:      time.Duration(DefaultHookRetryMinDelay) * time.Second,
+		MaxDelay:      time.Duration(DefaultHookRetryMaxDelay) * time.Second,
+		BackoffFactor: DefaultHookRetryBackoffFactor,
+	}
+	opts.Enabled, _ = c.defined[AutomaticallyRetryHooksKey].(bool)
+	opts.MaxAttempts, _ = c.defined[HookRetryMaxAttemptsKey].(int)
+	if v, ok := c.defined[HookRetryMinDelayKey].(int); ok {
+		opts.MinDelay = time.Duration(v) * time.Second
+	}
+	if v, ok := c.defined[HookRetryMaxDelayKey].(int); ok {
+		opts.MaxDelay = time.Duration(v) * time.Second
+	}
+	if v, ok := c.defined[HookRetryBackoffFactorKey].(float64); ok {
+		opts.BackoffFactor = v
+	}
+	return opts
+}
+
+func (c *Config) retention(ageKey string, defaultAge int, entriesKey string, defaultEntries int) (time.Duration, int) {
+	hours, ok := c.defined[ageKey].(int)
+	if !ok {
+		hours = defaultAge
+	}
+	entries, ok := c.defined[entriesKey].(int)
+	if !ok {
+		entries = defaultEntries
+	}
+	return time.Duration(hours) * time.Hour, entries
+}
+
 // ResourceTags returns a set of tags to set on environment resources
 // that Juju creates and manages, if the provider supports them. These
 // tags have no special meaning to Juju, but may be used for existing
@@ -1292,9 +1553,25 @@ var fields = func() schema.Fields {
 	if err != nil {
 		panic(err)
 	}
+	// environschema has no number type that allows fractions.
+	fs[HookRetryBackoffFactorKey] = forceFloat{}
 	return fs
 }()
 
+// forceFloat is a schema.Checker that coerces numbers, and strings
+// holding numbers, to float64, as schema.ForceInt does for integers.
+type forceFloat struct{}
+
+// Coerce implements schema.Checker.
+func (forceFloat) Coerce(v interface{}, path []string) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+	}
+	return schema.Float().Coerce(v, path)
+}
+
 // alwaysOptional holds configuration defaults for attributes that may
 // be unspecified even after a configuration has been created with all
 // defaults filled out.
@@ -1325,6 +1602,8 @@ var alwaysOptional = schema.Defaults{
 	AptHttpsProxyKey:             schema.Omit,
 	AptFtpProxyKey:               schema.Omit,
 	"apt-mirror":                 schema.Omit,
+	PackageSourcesKey:            schema.Omit,
+	PackageSourceKeysKey:         schema.Omit,
 	LxcClone:                     schema.Omit,
 	LXCDefaultMTU:                schema.Omit,
 	"disable-network-management": schema.Omit,
@@ -1335,7 +1614,21 @@ var alwaysOptional = schema.Defaults{
 	SetNumaControlPolicyKey:      DefaultNumaControlPolicy,
 	AllowLXCLoopMounts:           false,
 	ResourceTagsKey:              schema.Omit,
+	ImageIdsKey:                  schema.Omit,
+	AvailabilityZonePolicyKey:    schema.Omit,
 	CloudImageBaseURL:            schema.Omit,
+	StatusHistoryMaxAgeKey:       schema.Omit,
+	StatusHistoryMaxEntriesKey:   schema.Omit,
+	ActionResultsMaxAgeKey:       schema.Omit,
+	ActionResultsMaxEntriesKey:   schema.Omit,
+	RunResultsMaxAgeKey:          schema.Omit,
+	RunResultsMaxEntriesKey:      schema.Omit,
+	ActionParallelismKey:         schema.Omit,
+	AutomaticallyRetryHooksKey:   schema.Omit,
+	HookRetryMaxAttemptsKey:      schema.Omit,
+	HookRetryMinDelayKey:         schema.Omit,
+	HookRetryMaxDelayKey:         schema.Omit,
+	HookRetryBackoffFactorKey:    schema.Omit,
 
 	// Storage related config.
 	// Environ providers will specify their own defaults.
@@ -1532,6 +1825,26 @@ type SSHTimeoutOpts struct {
 	AddressesDelay time.Duration
 }
 
+// HookRetryOpts describes how a unit agent retries a failed hook
+// before waiting for the error to be resolved by the user.
+type HookRetryOpts struct {
+	// Enabled reports whether failed hooks are retried at all.
+	Enabled bool
+
+	// MaxAttempts is the number of retries made before giving up;
+	// zero means no limit.
+	MaxAttempts int
+
+	// MinDelay is the time waited before the first retry, and
+	// MaxDelay the most time waited before any retry.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+
+	// BackoffFactor is the factor by which the delay grows after
+	// each retry.
+	BackoffFactor float64
+}
+
 func addIfNotEmpty(settings map[string]interface{}, key, value string) {
 	if value != "" {
 		settings[key] = value
@@ -1639,6 +1952,16 @@ var configSchema = environschema.Fields{
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	PackageSourcesKey: {
+		Description: "Additional package sources for machines in the environment, one per line: apt sources such as ppa:juju/stable or deb lines, or yum repository URLs",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	PackageSourceKeysKey: {
+		Description: "The ASCII-armored GPG public keys that packages from the package sources are signed with",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
 	"authorized-keys": {
 		// TODO what to do about authorized-keys-path ?
 		Description: "Any authorized SSH public keys for the environment, as found in a ~/.ssh/authorized_keys file",
@@ -1774,6 +2097,83 @@ global or per instance security groups.`,
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	ImageIdsKey: {
+		Description: "The ids of the OS images to start instances with, as series=image-id or region/series=image-id pairs; each image must be listed in the image metadata",
+		Type:        environschema.Tattrs,
+		Group:       environschema.EnvironGroup,
+	},
+	StatusHistoryMaxAgeKey: {
+		Description: "The number of hours for which status history is kept; older entries are pruned. Zero means status history is kept regardless of age",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	StatusHistoryMaxEntriesKey: {
+		Description: "The number of status history entries kept for each entity; the oldest entries beyond this number are pruned. Zero means no limit",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	ActionResultsMaxAgeKey: {
+		Description: "The number of hours for which the results of completed actions are kept; older actions are pruned. Zero means results are kept regardless of age",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	ActionResultsMaxEntriesKey: {
+		Description: "The number of completed actions kept; the oldest actions beyond this number are pruned. Zero means no limit",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	RunResultsMaxAgeKey: {
+		Description: "The number of hours for which the results of \"juju run\" are kept; older results are pruned. Zero means results are kept regardless of age",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	RunResultsMaxEntriesKey: {
+		Description: "The number of \"juju run\" results kept; the oldest results beyond this number are pruned. Zero means no limit",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	ActionParallelismKey: {
+		Description: "The number of a unit's actions handed to its agent at once; further actions wait in the unit's queue until earlier ones finish. Zero means no limit",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	AutomaticallyRetryHooksKey: {
+		Description: "Whether unit agents retry failed hooks by themselves, backing off between attempts, rather than waiting for the error to be resolved",
+		Type:        environschema.Tbool,
+		Group:       environschema.EnvironGroup,
+	},
+	HookRetryMaxAttemptsKey: {
+		Description: "The number of times a failed hook is retried automatically before the unit agent waits for the error to be resolved. Zero means no limit",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	HookRetryMinDelayKey: {
+		Description: "The number of seconds a unit agent waits before first retrying a failed hook",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	HookRetryMaxDelayKey: {
+		Description: "The most seconds a unit agent waits between retries of a failed hook",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	HookRetryBackoffFactorKey: {
+		Description: "The factor, a number such as 1.5, by which the delay between retries of a failed hook grows after each retry",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	AvailabilityZonePolicyKey: {
+		Description: `The policy used to choose the availability zone in which to start a machine, on providers that support availability zones.
+
+'spread' starts each machine in the zone with the fewest machines of the same service, or with the fewest state servers.
+
+'pack' starts each machine in the zone with the most, filling zones one at a time.
+
+The zones constraint limits the zones considered.`,
+		Type:   environschema.Tstring,
+		Values: []interface{}{SpreadZonePolicy, PackZonePolicy},
+		Group:  environschema.EnvironGroup,
+	},
 	"image-stream": {
 		Description: `The simplestreams stream used to identify which image ids to search when starting an instance.`,
 		Type:        environschema.Tstring,
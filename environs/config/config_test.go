@@ -258,6 +258,61 @@ var configTests = []configTest{
 			"lxc-default-mtu": -42,
 		},
 		err: `lxc-default-mtu: expected positive integer, got -42`,
+	}, {
+		about:       "History retention set explicitly",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                       "my-type",
+			"name":                       "my-name",
+			"status-history-max-age":     24,
+			"action-results-max-entries": 0,
+		},
+	}, {
+		about:       "History retention invalid (negative)",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"status-history-max-age": -1,
+		},
+		err: `status-history-max-age: expected non-negative integer, got -1`,
+	}, {
+		about:       "Action parallelism invalid (negative)",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"action-parallelism": -1,
+		},
+		err: `action-parallelism: expected non-negative integer, got -1`,
+	}, {
+		about:       "Hook retry attempts invalid (negative)",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                    "my-type",
+			"name":                    "my-name",
+			"hook-retry-max-attempts": -1,
+		},
+		err: `hook-retry-max-attempts: expected non-negative integer, got -1`,
+	}, {
+		about:       "Hook retry backoff factor invalid (zero)",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                      "my-type",
+			"name":                      "my-name",
+			"hook-retry-backoff-factor": 0,
+		},
+		err: `hook-retry-backoff-factor: expected number not less than 1, got 0`,
+	}, {
+		about:       "Hook retry delays invalid (minimum above maximum)",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                 "my-type",
+			"name":                 "my-name",
+			"hook-retry-min-delay": 60,
+			"hook-retry-max-delay": 30,
+		},
+		err: `hook-retry-min-delay cannot be greater than hook-retry-max-delay`,
 	}, {
 		about:       "CA cert & key from path",
 		useDefaults: config.UseDefaults,
@@ -960,6 +1015,27 @@ var configTests = []configTest{
 			"apt-mirror": "http://my.archive.ubuntu.com",
 		},
 	},
+	{
+		about:       "Package sources",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"package-sources":     "ppa:juju/stable\ndeb http://mirror.example.com/ubuntu trusty main",
+			"package-source-keys": "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+		},
+	},
+	{
+		about:       "Package sources without OS refresh update",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                     "my-type",
+			"name":                     "my-name",
+			"package-sources":          "ppa:juju/stable",
+			"enable-os-refresh-update": false,
+		},
+		err: "package-sources cannot be used when enable-os-refresh-update is false",
+	},
 	{
 		about:       "Resource tags as space-separated string",
 		useDefaults: config.UseDefaults,
@@ -988,6 +1064,54 @@ var configTests = []configTest{
 		},
 		err: `resource-tags: expected "key=value", got "a"`,
 	},
+	{
+		about:       "Image ids",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":      "my-type",
+			"name":      "my-name",
+			"image-ids": "trusty=ami-0123 us-east-1/trusty=ami-4567",
+		},
+	},
+	{
+		about:       "Image ids with invalid key",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":      "my-type",
+			"name":      "my-name",
+			"image-ids": []string{"us-east-1/=ami-0123"},
+		},
+		err: `validating image ids: expected series or region/series, got "us-east-1/"`,
+	},
+	{
+		about:       "Image ids with missing id",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":      "my-type",
+			"name":      "my-name",
+			"image-ids": []string{"trusty="},
+		},
+		err: `validating image ids: no image id for "trusty"`,
+	},
+	{
+		about:       "Pack availability zone policy",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                     "my-type",
+			"name":                     "my-name",
+			"availability-zone-policy": config.PackZonePolicy,
+		},
+	},
+	{
+		about:       "Illegal availability zone policy",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                     "my-type",
+			"name":                     "my-name",
+			"availability-zone-policy": "scatter",
+		},
+		err: `availability-zone-policy: expected one of \[spread pack\], got "scatter"`,
+	},
 	{
 		about:       "Invalid identity URL value",
 		useDefaults: config.UseDefaults,
@@ -1829,6 +1953,107 @@ func (s *ConfigSuite) TestProxyValuesNotSet(c *gc.C) {
 	c.Assert(config.NoProxy(), gc.Equals, "")
 }
 
+func (s *ConfigSuite) TestPackageSources(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{
+		"package-sources":     "ppa:juju/stable\n\n  deb http://mirror.example.com/ubuntu trusty main  \n",
+		"package-source-keys": "some-key",
+	})
+	c.Assert(config.PackageSources(), gc.DeepEquals, []string{
+		"ppa:juju/stable",
+		"deb http://mirror.example.com/ubuntu trusty main",
+	})
+	c.Assert(config.PackageSourceKeys(), gc.Equals, "some-key")
+}
+
+func (s *ConfigSuite) TestPackageSourcesNotSet(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{})
+	c.Assert(config.PackageSources(), gc.HasLen, 0)
+	c.Assert(config.PackageSourceKeys(), gc.Equals, "")
+}
+
+func (s *ConfigSuite) TestAvailabilityZonePolicy(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{})
+	c.Check(config.AvailabilityZonePolicy(), gc.Equals, "spread")
+	config = newTestConfig(c, testing.Attrs{"availability-zone-policy": "pack"})
+	c.Check(config.AvailabilityZonePolicy(), gc.Equals, "pack")
+}
+
+func (s *ConfigSuite) TestHistoryRetention(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"status-history-max-age":     24,
+		"action-results-max-entries": 0,
+	})
+	maxAge, maxEntries := cfg.StatusHistoryRetention()
+	c.Check(maxAge, gc.Equals, 24*time.Hour)
+	c.Check(maxEntries, gc.Equals, config.DefaultStatusHistoryMaxEntries)
+	maxAge, maxEntries = cfg.ActionResultsRetention()
+	c.Check(maxAge, gc.Equals, config.DefaultActionResultsMaxAge*time.Hour)
+	c.Check(maxEntries, gc.Equals, 0)
+	maxAge, maxEntries = cfg.RunResultsRetention()
+	c.Check(maxAge, gc.Equals, config.DefaultRunResultsMaxAge*time.Hour)
+	c.Check(maxEntries, gc.Equals, config.DefaultRunResultsMaxEntries)
+}
+
+func (s *ConfigSuite) TestActionParallelism(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{})
+	c.Check(cfg.ActionParallelism(), gc.Equals, 0)
+	cfg = newTestConfig(c, testing.Attrs{"action-parallelism": 2})
+	c.Check(cfg.ActionParallelism(), gc.Equals, 2)
+}
+
+func (s *ConfigSuite) TestHookRetryStrategy(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{})
+	c.Check(cfg.HookRetryStrategy(), jc.DeepEquals, config.HookRetryOpts{
+		MinDelay:      config.DefaultHookRetryMinDelay * time.Second,
+		MaxDelay:      config.DefaultHookRetryMaxDelay * time.Second,
+		BackoffFactor: config.DefaultHookRetryBackoffFactor,
+	})
+	cfg = newTestConfig(c, testing.Attrs{
+		"automatically-retry-hooks": true,
+		"hook-retry-max-attempts":   3,
+		"hook-retry-min-delay":      10,
+		"hook-retry-max-delay":      60,
+		"hook-retry-backoff-factor": 3,
+	})
+	c.Check(cfg.HookRetryStrategy(), jc.DeepEquals, config.HookRetryOpts{
+		Enabled:       true,
+		MaxAttempts:   3,
+		MinDelay:      10 * time.Second,
+		MaxDelay:      60 * time.Second,
+		BackoffFactor: 3,
+	})
+}
+
+func (s *ConfigSuite) TestHookRetryBackoffFactorFraction(c *gc.C) {
+	s.addJujuFiles(c)
+	for i, value := range []interface{}{1.5, "1.5"} {
+		c.Logf("test %d: %#v", i, value)
+		cfg := newTestConfig(c, testing.Attrs{"hook-retry-backoff-factor": value})
+		c.Check(cfg.HookRetryStrategy().BackoffFactor, gc.Equals, 1.5)
+	}
+}
+
+func (s *ConfigSuite) TestPinnedImageId(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{
+		"image-ids": "trusty=ami-0123 us-east-1/trusty=ami-4567",
+	})
+	id, ok := config.PinnedImageId("us-east-1", "trusty")
+	c.Check(ok, jc.IsTrue)
+	c.Check(id, gc.Equals, "ami-4567")
+	id, ok = config.PinnedImageId("us-west-1", "trusty")
+	c.Check(ok, jc.IsTrue)
+	c.Check(id, gc.Equals, "ami-0123")
+	_, ok = config.PinnedImageId("us-east-1", "precise")
+	c.Check(ok, jc.IsFalse)
+}
+
 func (s *ConfigSuite) TestProxyConfigMap(c *gc.C) {
 	s.addJujuFiles(c)
 	cfg := newTestConfig(c, testing.Attrs{})
@@ -14,13 +14,13 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
-	"github.com/juju/utils/featureflag"
 	"github.com/juju/utils/fslock"
 	"github.com/juju/utils/set"
 	goyaml "gopkg.in/yaml.v2"
 
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/juju/osenv/featureflag"
 )
 
 var logger = loggo.GetLogger("juju.environs.configstore")
@@ -4,7 +4,13 @@
 package environs
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/juju/errors"
+
+	"github.com/juju/juju/instance"
 )
 
 var (
@@ -18,3 +24,28 @@ var (
 	ErrIPAddressesExhausted = errors.New("can't allocate a new IP address")
 	ErrIPAddressUnavailable = errors.New("the requested IP address is unavailable")
 )
+
+// StopInstancesError is returned by InstanceBulkStopper.BulkStopInstances
+// when some of the instances could not be stopped.
+type StopInstancesError struct {
+	// Failed holds the reason each instance that could not be
+	// stopped was not stopped.
+	Failed map[instance.Id]error
+
+	// Total is the number of instances asked to be stopped.
+	Total int
+}
+
+// Error is part of the error interface.
+func (e *StopInstancesError) Error() string {
+	ids := make([]string, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	reasons := make([]string, len(ids))
+	for i, id := range ids {
+		reasons[i] = fmt.Sprintf("%s: %v", id, e.Failed[instance.Id(id)])
+	}
+	return fmt.Sprintf("cannot stop %d of %d instances: %s", len(ids), e.Total, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,222 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package instancecache caches the instances returned by an
+// environment's Instances and AllInstances methods for a short time,
+// so that workers which poll the cloud for every machine, such as the
+// instance poller, do not make a provider API call each time.
+//
+// A cache is shared by all the users of an environment in the same
+// process, so that the provisioner can invalidate the cached instances
+// when it starts or stops instances.
+package instancecache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+var logger = loggo.GetLogger("juju.environs.instancecache")
+
+// DefaultTTL is how long instances are cached for by the caches
+// returned by ForEnviron.
+const DefaultTTL = 5 * time.Second
+
+// now is patched by tests.
+var now = time.Now
+
+// InstanceGetter is the part of environs.Environ that is cached.
+type InstanceGetter interface {
+	// Instances is specified in environs.Environ.
+	Instances(ids []instance.Id) ([]instance.Instance, error)
+
+	// AllInstances is specified in environs.InstanceBroker.
+	AllInstances() ([]instance.Instance, error)
+}
+
+type entry struct {
+	inst    instance.Instance
+	expires time.Time
+}
+
+// Cache is an InstanceGetter that caches the results of another for a
+// fixed time. Instances that were not found are cached too, until they
+// are invalidated or expire.
+type Cache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	env     InstanceGetter
+	entries map[instance.Id]entry
+	all     []instance.Instance
+	allTime time.Time
+	// generation is incremented on each invalidation, so that results
+	// fetched before an invalidation are not cached after it.
+	generation int
+}
+
+// New returns a Cache that caches the instances returned by env for
+// ttl.
+func New(env InstanceGetter, ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		env:     env,
+		entries: make(map[instance.Id]entry),
+	}
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[string]*Cache)
+)
+
+// ForEnviron returns the cache of env's instances shared by all users
+// of the environment in this process. The cache uses env to fetch
+// instances from then on, so that it uses the latest configuration;
+// if env is not the Environ used before, the cached instances are
+// discarded. If the environment has no UUID, an unshared cache is
+// returned.
+func ForEnviron(env environs.Environ) *Cache {
+	uuid, ok := env.Config().UUID()
+	if !ok {
+		return New(env, DefaultTTL)
+	}
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	c, ok := caches[uuid]
+	if !ok {
+		c = New(env, DefaultTTL)
+		caches[uuid] = c
+		return c
+	}
+	c.mu.Lock()
+	changed := c.env != InstanceGetter(env)
+	c.env = env
+	c.mu.Unlock()
+	if changed {
+		c.Invalidate()
+	}
+	return c
+}
+
+// Invalidate discards the cached instances with the given ids, and
+// any cached AllInstances result, from the shared cache of the
+// environment with the given UUID, if there is one. If no ids are
+// given, all the cached instances are discarded.
+func Invalidate(uuid string, ids ...instance.Id) {
+	cachesMu.Lock()
+	c, ok := caches[uuid]
+	cachesMu.Unlock()
+	if ok {
+		c.Invalidate(ids...)
+	}
+}
+
+// Invalidate discards the cached instances with the given ids, and any
+// cached AllInstances result. If no ids are given, all the cached
+// instances are discarded.
+func (c *Cache) Invalidate(ids ...instance.Id) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.all = nil
+	if len(ids) == 0 {
+		c.entries = make(map[instance.Id]entry)
+		return
+	}
+	for _, id := range ids {
+		delete(c.entries, id)
+	}
+}
+
+// Instances implements InstanceGetter. Only the instances that are not
+// cached are fetched.
+func (c *Cache) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	t := now()
+	result := make([]instance.Instance, len(ids))
+	var missing []instance.Id
+	var missingIndexes []int
+	c.mu.Lock()
+	env, generation := c.env, c.generation
+	for i, id := range ids {
+		if e, ok := c.entries[id]; ok && t.Before(e.expires) {
+			result[i] = e.inst
+			continue
+		}
+		missing = append(missing, id)
+		missingIndexes = append(missingIndexes, i)
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 {
+		logger.Tracef("fetching %d of %d instances", len(missing), len(ids))
+		insts, err := env.Instances(missing)
+		switch err {
+		case nil, environs.ErrPartialInstances, environs.ErrNoInstances:
+		default:
+			return nil, err
+		}
+		c.mu.Lock()
+		for j, id := range missing {
+			var inst instance.Instance
+			if err != environs.ErrNoInstances {
+				inst = insts[j]
+			}
+			result[missingIndexes[j]] = inst
+			if c.generation == generation {
+				c.entries[id] = entry{inst, t.Add(c.ttl)}
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	found := 0
+	for _, inst := range result {
+		if inst != nil {
+			found++
+		}
+	}
+	switch found {
+	case 0:
+		return nil, environs.ErrNoInstances
+	case len(ids):
+		return result, nil
+	}
+	return result, environs.ErrPartialInstances
+}
+
+// AllInstances implements InstanceGetter. The instances returned are
+// also cached for Instances.
+func (c *Cache) AllInstances() ([]instance.Instance, error) {
+	t := now()
+	c.mu.Lock()
+	if c.all != nil && t.Before(c.allTime.Add(c.ttl)) {
+		all := c.all
+		c.mu.Unlock()
+		return all, nil
+	}
+	env, generation := c.env, c.generation
+	c.mu.Unlock()
+
+	all, err := env.AllInstances()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.all, c.allTime = all, t
+		for _, inst := range all {
+			c.entries[inst.Id()] = entry{inst, t.Add(c.ttl)}
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancecache_test
+
+import (
+	"errors"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instancecache"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/testing"
+)
+
+type cacheSuite struct {
+	testing.BaseSuite
+	env   *fakeEnviron
+	cache *instancecache.Cache
+	now   time.Time
+}
+
+var _ = gc.Suite(&cacheSuite{})
+
+func (s *cacheSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.env = &fakeEnviron{instances: map[instance.Id]instance.Instance{
+		"i-0": &fakeInstance{id: "i-0"},
+		"i-1": &fakeInstance{id: "i-1"},
+	}}
+	s.cache = instancecache.New(s.env, time.Minute)
+	s.now = time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC)
+	s.PatchValue(instancecache.Now, func() time.Time { return s.now })
+}
+
+func (s *cacheSuite) TestInstancesCached(c *gc.C) {
+	insts, err := s.cache.Instances([]instance.Id{"i-0", "i-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts, gc.HasLen, 2)
+	insts, err = s.cache.Instances([]instance.Id{"i-1", "i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts[0].Id(), gc.Equals, instance.Id("i-1"))
+	c.Assert(insts[1].Id(), gc.Equals, instance.Id("i-0"))
+	c.Assert(s.env.calls, jc.DeepEquals, [][]instance.Id{{"i-0", "i-1"}})
+}
+
+func (s *cacheSuite) TestInstancesFetchesMissing(c *gc.C) {
+	_, err := s.cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	insts, err := s.cache.Instances([]instance.Id{"i-0", "i-1", "i-2"})
+	c.Assert(err, gc.Equals, environs.ErrPartialInstances)
+	c.Assert(insts, gc.HasLen, 3)
+	c.Assert(insts[2], gc.IsNil)
+	c.Assert(s.env.calls, jc.DeepEquals, [][]instance.Id{{"i-0"}, {"i-1", "i-2"}})
+
+	insts, err = s.cache.Instances([]instance.Id{"i-2"})
+	c.Assert(err, gc.Equals, environs.ErrNoInstances)
+	c.Assert(insts, gc.IsNil)
+	c.Assert(s.env.calls, gc.HasLen, 2)
+}
+
+func (s *cacheSuite) TestInstancesExpire(c *gc.C) {
+	_, err := s.cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.now = s.now.Add(time.Minute)
+	_, err = s.cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.calls, gc.HasLen, 2)
+}
+
+func (s *cacheSuite) TestInstancesError(c *gc.C) {
+	s.env.err = errors.New("rate limited")
+	_, err := s.cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, gc.ErrorMatches, "rate limited")
+	s.env.err = nil
+	_, err = s.cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.calls, gc.HasLen, 2)
+}
+
+func (s *cacheSuite) TestInvalidate(c *gc.C) {
+	_, err := s.cache.Instances([]instance.Id{"i-0", "i-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.cache.Invalidate("i-1")
+	_, err = s.cache.Instances([]instance.Id{"i-0", "i-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.cache.Invalidate()
+	_, err = s.cache.Instances([]instance.Id{"i-0", "i-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.calls, jc.DeepEquals, [][]instance.Id{{"i-0", "i-1"}, {"i-1"}, {"i-0", "i-1"}})
+}
+
+func (s *cacheSuite) TestAllInstances(c *gc.C) {
+	all, err := s.cache.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 2)
+	_, err = s.cache.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.cache.Instances([]instance.Id{"i-0", "i-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.allCalls, gc.Equals, 1)
+	c.Assert(s.env.calls, gc.HasLen, 0)
+
+	s.cache.Invalidate("i-0")
+	_, err = s.cache.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.allCalls, gc.Equals, 2)
+}
+
+func (s *cacheSuite) TestForEnviron(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"uuid": "a9f6e56c-6a1c-4b3b-9f50-4c7a3ff9c0de"})
+	env := &fakeConfigEnviron{fakeEnviron: s.env, cfg: cfg}
+	cache := instancecache.ForEnviron(env)
+	c.Assert(instancecache.ForEnviron(env), gc.Equals, cache)
+
+	_, err := cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	instancecache.Invalidate("a9f6e56c-6a1c-4b3b-9f50-4c7a3ff9c0de", "i-0")
+	_, err = cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.calls, gc.HasLen, 2)
+
+	// A new Environ for the same environment shares the cache, but
+	// not the instances cached through the old one.
+	env = &fakeConfigEnviron{fakeEnviron: s.env, cfg: cfg}
+	c.Assert(instancecache.ForEnviron(env), gc.Equals, cache)
+	_, err = cache.Instances([]instance.Id{"i-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.calls, gc.HasLen, 3)
+}
+
+type fakeConfigEnviron struct {
+	environs.Environ
+	*fakeEnviron
+	cfg *config.Config
+}
+
+func (e *fakeConfigEnviron) Config() *config.Config {
+	return e.cfg
+}
+
+func (e *fakeConfigEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	return e.fakeEnviron.Instances(ids)
+}
+
+func (e *fakeConfigEnviron) AllInstances() ([]instance.Instance, error) {
+	return e.fakeEnviron.AllInstances()
+}
+
+type fakeEnviron struct {
+	instances map[instance.Id]instance.Instance
+	err       error
+	calls     [][]instance.Id
+	allCalls  int
+}
+
+func (e *fakeEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	e.calls = append(e.calls, ids)
+	if e.err != nil {
+		return nil, e.err
+	}
+	insts := make([]instance.Instance, len(ids))
+	found := 0
+	for i, id := range ids {
+		if inst, ok := e.instances[id]; ok {
+			insts[i] = inst
+			found++
+		}
+	}
+	switch found {
+	case 0:
+		return nil, environs.ErrNoInstances
+	case len(ids):
+		return insts, nil
+	}
+	return insts, environs.ErrPartialInstances
+}
+
+func (e *fakeEnviron) AllInstances() ([]instance.Instance, error) {
+	e.allCalls++
+	var all []instance.Instance
+	for _, id := range []instance.Id{"i-0", "i-1"} {
+		all = append(all, e.instances[id])
+	}
+	return all, nil
+}
+
+type fakeInstance struct {
+	instance.Instance
+	id instance.Id
+}
+
+func (inst *fakeInstance) Id() instance.Id {
+	return inst.id
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/juju/errors"
+
 	"github.com/juju/loggo"
 	"github.com/juju/utils/arch"
 
@@ -32,6 +34,10 @@ type InstanceConstraint struct {
 	// eg ["ssd", "ebs"] means find images with ssd storage, but if none exist,
 	// find those with ebs instead.
 	Storage []string
+
+	// ImageId, if set, is the id of the only image that may be used,
+	// as pinned by the environment's image-ids setting.
+	ImageId string
 }
 
 // String returns a human readable form of this InstanceConstraint.
@@ -60,9 +66,22 @@ type InstanceSpec struct {
 // which instances can be run. The InstanceConstraint is used to filter allInstanceTypes and then a suitable image
 // compatible with the matching instance types is returned.
 func FindInstanceSpec(possibleImages []Image, ic *InstanceConstraint, allInstanceTypes []InstanceType) (*InstanceSpec, error) {
+	if ic.ImageId != "" {
+		var pinned []Image
+		for _, image := range possibleImages {
+			if image.Id == ic.ImageId {
+				pinned = append(pinned, image)
+			}
+		}
+		if len(pinned) == 0 {
+			return nil, errors.NewNotFound(nil, fmt.Sprintf("image %q pinned for %q in %s not found in image metadata",
+				ic.ImageId, ic.Series, ic.Region))
+		}
+		possibleImages = pinned
+	}
 	if len(possibleImages) == 0 {
-		return nil, fmt.Errorf("no %q images in %s with arches %s",
-			ic.Series, ic.Region, ic.Arches)
+		return nil, errors.NewNotFound(nil, fmt.Sprintf("no %q images in %s with arches %s",
+			ic.Series, ic.Region, ic.Arches))
 	}
 
 	matchingTypes, err := MatchingInstanceTypes(allInstanceTypes, ic.Region, ic.Constraints)
@@ -70,7 +89,7 @@ func FindInstanceSpec(possibleImages []Image, ic *InstanceConstraint, allInstanc
 		return nil, err
 	}
 	if len(matchingTypes) == 0 {
-		return nil, fmt.Errorf("no instance types found matching constraint: %s", ic)
+		return nil, errors.NewNotFound(nil, fmt.Sprintf("no instance types found matching constraint: %s", ic))
 	}
 
 	specs := []*InstanceSpec{}
@@ -95,7 +114,7 @@ func FindInstanceSpec(possibleImages []Image, ic *InstanceConstraint, allInstanc
 	for i, itype := range matchingTypes {
 		names[i] = itype.Name
 	}
-	return nil, fmt.Errorf("no %q images in %s matching instance types %v", ic.Series, ic.Region, names)
+	return nil, errors.NewNotFound(nil, fmt.Sprintf("no %q images in %s matching instance types %v", ic.Series, ic.Region, names))
 }
 
 // byArch sorts InstanceSpecs first by descending word-size, then
@@ -209,6 +209,7 @@ type instanceSpecTestParams struct {
 	stream           string
 	constraints      string
 	instanceTypes    []InstanceType
+	pinnedImageId    string
 	imageId          string
 	instanceTypeId   string
 	instanceTypeName string
@@ -298,6 +299,22 @@ var findInstanceSpecTests = []instanceSpecTestParams{
 			{Id: "1", Name: "it-1", Arches: []string{"amd64"}, VirtType: &hvm, Mem: 512, CpuCores: 2},
 		},
 	},
+	{
+		desc:          "pinned image is used in preference to others",
+		region:        "test",
+		arches:        []string{"amd64", "i386"},
+		pinnedImageId: "ami-b79b09b7",
+		imageId:       "ami-b79b09b7",
+		instanceTypes: []InstanceType{
+			{Id: "1", Name: "it-1", Arches: []string{"i386", "amd64"}, VirtType: &pv, Mem: 512},
+		},
+	},
+	{
+		desc:          "pinned image not in metadata",
+		region:        "test",
+		pinnedImageId: "ami-99999999",
+		err:           `image "ami-99999999" pinned for "precise" in test not found in image metadata`,
+	},
 	{
 		desc:        "empty instance type constraint",
 		region:      "test",
@@ -374,6 +391,7 @@ func (s *imageSuite) TestFindInstanceSpec(c *gc.C) {
 			Region:      t.region,
 			Arches:      t.arches,
 			Constraints: imageCons,
+			ImageId:     t.pinnedImageId,
 		}, t.instanceTypes)
 		if t.err != "" {
 			c.Check(err, gc.ErrorMatches, t.err)
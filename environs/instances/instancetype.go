@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/constraints"
 )
 
@@ -62,6 +64,9 @@ func (itype InstanceType) match(cons constraints.Value) (InstanceType, bool) {
 	if cons.Tags != nil && len(*cons.Tags) > 0 && !tagsMatch(*cons.Tags, itype.Tags) {
 		return nothing, false
 	}
+	if cons.HasVirtType() && itype.VirtType != nil && *itype.VirtType != *cons.VirtType {
+		return nothing, false
+	}
 	return itype, true
 }
 
@@ -128,7 +133,7 @@ func MatchingInstanceTypes(allInstanceTypes []InstanceType, region string, cons
 	}
 
 	// No luck, so report the error.
-	return nil, fmt.Errorf("no instance types in %s matching constraints %q", region, origCons)
+	return nil, errors.NewNotFound(nil, fmt.Sprintf("no instance types in %s matching constraints %q", region, origCons))
 }
 
 // tagsMatch returns if the tags in wanted all exist in have.
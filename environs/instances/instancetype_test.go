@@ -216,6 +216,15 @@ var getInstanceTypesTest = []struct {
 			{Id: "1", Name: "it-1", Arches: []string{"amd64"}, Mem: 512, CpuCores: 4, Cost: 100},
 		},
 		expectedItypes: []string{"it-2"},
+	}, {
+		about: "virt-type specified and match found",
+		cons:  "virt-type=hvm",
+		itypesToUse: []InstanceType{
+			{Id: "3", Name: "it-3", Arches: []string{"amd64"}, Mem: 4096, VirtType: &hvm},
+			{Id: "2", Name: "it-2", Arches: []string{"amd64"}, Mem: 4096, VirtType: &pv},
+			{Id: "1", Name: "it-1", Arches: []string{"amd64"}, Mem: 2048},
+		},
+		expectedItypes: []string{"it-1", "it-3"},
 	}, {
 		about:          "deprecated image type requested by name",
 		cons:           "instance-type=dep.small",
@@ -287,6 +296,8 @@ var instanceTypeMatchTests = []struct {
 	{"cpu-power=9001", "cc2.8xlarge", nil},
 	{"mem=1G", "t1.micro", nil},
 	{"arch=armhf", "c1.xlarge", nil},
+	{"virt-type=hvm", "cc1.4xlarge", []string{"amd64"}},
+	{"virt-type=pv", "cc1.4xlarge", nil},
 }
 
 func (s *instanceTypeSuite) TestMatch(c *gc.C) {
@@ -220,6 +220,16 @@ type InstanceTagger interface {
 	TagInstance(id instance.Id, tags map[string]string) error
 }
 
+// CredentialChecker is implemented by Environs that can verify their
+// cloud credentials against the cloud.
+type CredentialChecker interface {
+	// CheckCredentials makes a cheap, read-only call to the cloud
+	// with the environ's credentials. It returns an error satisfying
+	// errors.IsUnauthorized if the cloud rejects the credentials;
+	// any other error means the credentials could not be checked.
+	CheckCredentials() error
+}
+
 // BootstrapContext is an interface that is passed to
 // Environ.Bootstrap, providing a means of obtaining
 // information about and manipulating the context in which
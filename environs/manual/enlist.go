@@ -0,0 +1,164 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// DefaultEnlistParallelism is the number of hosts enlisted at once if
+// EnlistMachinesArgs.Parallelism is not set.
+const DefaultEnlistParallelism = 10
+
+// sshConnectionErrorCode is the exit code of ssh when it cannot
+// connect to the remote host, as opposed to the remote command
+// failing.
+const sshConnectionErrorCode = 255
+
+// enlistAttempt governs how often enlisting a host is retried after a
+// transient SSH failure.
+var enlistAttempt = utils.AttemptStrategy{
+	Total: time.Minute,
+	Delay: 10 * time.Second,
+}
+
+// provisionMachine is patched by tests.
+var provisionMachine = ProvisionMachine
+
+// EnlistMachinesArgs holds the arguments to EnlistMachines.
+type EnlistMachinesArgs struct {
+	// ProvisionMachineArgs holds the arguments used to provision
+	// each host. Its Host and Stdin fields are ignored: hosts are
+	// enlisted concurrently, so any prompt for a password fails
+	// rather than waiting for input. The output of each host is
+	// buffered, and written to Stdout and Stderr in one piece once
+	// the host is enlisted.
+	ProvisionMachineArgs
+
+	// Hosts holds the SSH hosts to enlist, each as [user@]host.
+	Hosts []string
+
+	// Parallelism is the maximum number of hosts enlisted at once.
+	// If zero, DefaultEnlistParallelism is used.
+	Parallelism int
+}
+
+// EnlistResult holds the outcome of enlisting a single host.
+type EnlistResult struct {
+	// Host is the host, as given in EnlistMachinesArgs.Hosts.
+	Host string
+
+	// MachineId is the id of the machine created for the host,
+	// if it was enlisted.
+	MachineId string
+
+	// Attempts is the number of times provisioning was attempted.
+	Attempts int
+
+	// Error holds the reason the host could not be enlisted.
+	Error error
+}
+
+// EnlistMachines provisions machine agents on several existing hosts
+// concurrently, as ProvisionMachine does for a single host. Hardware
+// characteristics are discovered on each host separately, and hosts
+// that cannot be reached over SSH are retried for a short time. The
+// results are returned in the order of args.Hosts.
+func EnlistMachines(args EnlistMachinesArgs) []EnlistResult {
+	parallelism := args.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultEnlistParallelism
+	}
+	results := make([]EnlistResult, len(args.Hosts))
+	sem := make(chan struct{}, parallelism)
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+	for i, host := range args.Hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var stdout, stderr bytes.Buffer
+			results[i] = enlistMachine(args.ProvisionMachineArgs, host, &stdout, &stderr)
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			writeOutput(args.Stdout, &stdout)
+			writeOutput(args.Stderr, &stderr)
+		}(i, host)
+	}
+	wg.Wait()
+	return results
+}
+
+// writeOutput writes a host's buffered output to w, if it is set.
+func writeOutput(w io.Writer, output *bytes.Buffer) {
+	if w == nil || output.Len() == 0 {
+		return
+	}
+	if _, err := output.WriteTo(w); err != nil {
+		logger.Warningf("cannot write enlisting output: %v", err)
+	}
+}
+
+// enlistMachine provisions a single host, sending the output of
+// provisioning to stdout and stderr, which are not shared with any
+// other host.
+func enlistMachine(args ProvisionMachineArgs, host string, stdout, stderr io.Writer) EnlistResult {
+	args.Host = host
+	args.Stdin = strings.NewReader("")
+	args.Stdout = stdout
+	args.Stderr = stderr
+	result := EnlistResult{Host: host}
+	for a := enlistAttempt.Start(); a.Next(); {
+		result.Attempts++
+		result.MachineId, result.Error = provisionMachine(args)
+		if result.Error == nil || !isTransientSSHError(result.Error) {
+			break
+		}
+		logger.Infof("cannot reach %s, retrying: %v", host, result.Error)
+	}
+	if result.Error != nil {
+		logger.Errorf("cannot enlist %s: %v", host, result.Error)
+	}
+	return result
+}
+
+// isTransientSSHError returns whether the error is caused by ssh
+// failing to connect, in which case trying again may succeed.
+func isTransientSSHError(err error) bool {
+	if rcErr, ok := errors.Cause(err).(*cmd.RcPassthroughError); ok {
+		return rcErr.Code == sshConnectionErrorCode
+	}
+	// Most SSH errors are reported with their output, which loses
+	// the original error, so look for the exit code in the message.
+	rcErr := cmd.NewRcPassthroughError(sshConnectionErrorCode)
+	return strings.Contains(err.Error(), rcErr.Error())
+}
+
+// EnlistSummary returns a line describing each result, followed by a
+// line counting the hosts enlisted.
+func EnlistSummary(results []EnlistResult) string {
+	var lines []string
+	enlisted := 0
+	for _, result := range results {
+		if result.Error != nil {
+			lines = append(lines, fmt.Sprintf("failed to enlist %s: %v", result.Host, result.Error))
+			continue
+		}
+		enlisted++
+		lines = append(lines, fmt.Sprintf("created machine %s for %s", result.MachineId, result.Host))
+	}
+	lines = append(lines, fmt.Sprintf("enlisted %d of %d hosts", enlisted, len(results)))
+	return strings.Join(lines, "\n") + "\n"
+}
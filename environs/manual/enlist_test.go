@@ -0,0 +1,166 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/manual"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type enlistSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&enlistSuite{})
+
+func (s *enlistSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(manual.EnlistAttempt, utils.AttemptStrategy{Min: 3})
+}
+
+func (s *enlistSuite) TestEnlistMachines(c *gc.C) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	s.PatchValue(manual.EnlistProvisionMachine, func(args manual.ProvisionMachineArgs) (string, error) {
+		c.Check(args.Client, gc.IsNil)
+		c.Check(args.Stdin, gc.NotNil)
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[args.Host]++
+		switch args.Host {
+		case "flaky":
+			if attempts[args.Host] == 1 {
+				return "", fmt.Errorf("%v (ssh: connect to host flaky port 22: Connection refused)",
+					cmd.NewRcPassthroughError(255))
+			}
+			return "1", nil
+		case "unreachable":
+			return "", cmd.NewRcPassthroughError(255)
+		case "provisioned":
+			return "", manual.ErrProvisioned
+		}
+		return "0", nil
+	})
+
+	results := manual.EnlistMachines(manual.EnlistMachinesArgs{
+		Hosts:       []string{"ubuntu@good", "flaky", "unreachable", "provisioned"},
+		Parallelism: 2,
+	})
+	c.Assert(results, gc.HasLen, 4)
+	c.Check(results[0], jc.DeepEquals, manual.EnlistResult{Host: "ubuntu@good", MachineId: "0", Attempts: 1})
+	c.Check(results[1], jc.DeepEquals, manual.EnlistResult{Host: "flaky", MachineId: "1", Attempts: 2})
+	c.Check(results[2].Attempts, gc.Equals, 3)
+	c.Check(results[2].Error, gc.ErrorMatches, "subprocess encountered error code 255")
+	c.Check(results[3].Attempts, gc.Equals, 1)
+	c.Check(results[3].Error, gc.Equals, manual.ErrProvisioned)
+
+	c.Check(manual.EnlistSummary(results), gc.Equals, `
+created machine 0 for ubuntu@good
+created machine 1 for flaky
+failed to enlist unreachable: subprocess encountered error code 255
+failed to enlist provisioned: machine is already provisioned
+enlisted 2 of 4 hosts
+`[1:])
+}
+
+func (s *enlistSuite) TestEnlistMachinesParallelism(c *gc.C) {
+	started := make(chan string)
+	release := make(chan struct{})
+	s.PatchValue(manual.EnlistProvisionMachine, func(args manual.ProvisionMachineArgs) (string, error) {
+		started <- args.Host
+		<-release
+		return args.Host, nil
+	})
+
+	hosts := []string{"a", "b", "c", "d", "e"}
+	done := make(chan []manual.EnlistResult)
+	go func() {
+		done <- manual.EnlistMachines(manual.EnlistMachinesArgs{
+			ProvisionMachineArgs: manual.ProvisionMachineArgs{Stderr: ioutil.Discard},
+			Hosts:                hosts,
+			Parallelism:          2,
+		})
+	}()
+	waitStarted := func() {
+		select {
+		case <-started:
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for host to be enlisted")
+		}
+	}
+	waitStarted()
+	waitStarted()
+	select {
+	case host := <-started:
+		c.Fatalf("%s enlisted while 2 hosts were being enlisted", host)
+	case <-time.After(coretesting.ShortWait):
+	}
+	close(release)
+	for range hosts[2:] {
+		waitStarted()
+	}
+	results := <-done
+	for i, result := range results {
+		c.Check(result.MachineId, gc.Equals, hosts[i])
+		c.Check(result.Error, jc.ErrorIsNil)
+	}
+}
+
+func (s *enlistSuite) TestEnlistMachinesWrappedError(c *gc.C) {
+	s.PatchValue(manual.EnlistProvisionMachine, func(args manual.ProvisionMachineArgs) (string, error) {
+		return "", errors.Annotate(cmd.NewRcPassthroughError(1), "running provisioning script")
+	})
+	results := manual.EnlistMachines(manual.EnlistMachinesArgs{Hosts: []string{"host"}})
+	c.Assert(results, gc.HasLen, 1)
+	c.Check(results[0].Attempts, gc.Equals, 1)
+	c.Check(results[0].Error, gc.ErrorMatches, "running provisioning script: subprocess encountered error code 1")
+}
+
+func (s *enlistSuite) TestEnlistMachinesBuffersOutput(c *gc.C) {
+	var stdout, stderr bytes.Buffer
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s.PatchValue(manual.EnlistProvisionMachine, func(args manual.ProvisionMachineArgs) (string, error) {
+		c.Check(args.Stdout, gc.Not(gc.Equals), &stdout)
+		c.Check(args.Stderr, gc.Not(gc.Equals), &stderr)
+		fmt.Fprintf(args.Stderr, "%s: starting\n", args.Host)
+		// Wait until both hosts have started, so that their
+		// output would be interleaved if it were not buffered.
+		started <- struct{}{}
+		<-release
+		fmt.Fprintf(args.Stdout, "%s: done\n", args.Host)
+		fmt.Fprintf(args.Stderr, "%s: finished\n", args.Host)
+		return args.Host, nil
+	})
+	go func() {
+		<-started
+		<-started
+		close(release)
+	}()
+
+	results := manual.EnlistMachines(manual.EnlistMachinesArgs{
+		ProvisionMachineArgs: manual.ProvisionMachineArgs{
+			Stdout: &stdout,
+			Stderr: &stderr,
+		},
+		Hosts: []string{"a", "b"},
+	})
+	c.Assert(results, gc.HasLen, 2)
+	for _, host := range []string{"a", "b"} {
+		c.Check(stdout.String(), jc.Contains, host+": done\n")
+		c.Check(stderr.String(), jc.Contains, host+": starting\n"+host+": finished\n")
+	}
+}
@@ -4,8 +4,11 @@
 package manual
 
 var (
-	NetLookupHost         = &netLookupHost
-	ProvisionMachineAgent = &provisionMachineAgent
+	NetLookupHost          = &netLookupHost
+	ProvisionMachineAgent  = &provisionMachineAgent
+	EnlistProvisionMachine = &provisionMachine
+	EnlistAttempt          = &enlistAttempt
+	RemoveMachineAgent     = &removeMachineAgent
 )
 
 const (
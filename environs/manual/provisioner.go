@@ -11,6 +11,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/names"
 	"github.com/juju/utils"
 	"github.com/juju/utils/shell"
 
@@ -22,7 +23,9 @@ import (
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state/multiwatcher"
+	"github.com/juju/juju/utils/ssh"
 )
 
 const manualInstancePrefix = "manual:"
@@ -118,6 +121,11 @@ func ProvisionMachine(args ProvisionMachineArgs) (machineId string, err error) {
 	// Finally, provision the machine agent.
 	err = runProvisionScript(provisioningScript, hostname, args.Stderr)
 	if err != nil {
+		// The script may have got as far as installing the agent's
+		// service, which must not outlive the machine.
+		if cleanupErr := removeMachineAgent(hostname, machineParams.Series, machineId); cleanupErr != nil {
+			logger.Warningf("error removing machine agent: %s", cleanupErr)
+		}
 		return machineId, err
 	}
 
@@ -244,6 +252,26 @@ func ProvisioningScript(icfg *instancecfg.InstanceConfig) (string, error) {
 	return buf.String(), nil
 }
 
+// removeMachineAgent stops and removes the service of the identified
+// machine's agent on the host, if it is installed.
+var removeMachineAgent = func(host, series, machineId string) error {
+	script, err := service.RemoveServicesScript(series, "jujud-"+names.NewMachineTag(machineId).String())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cmd := ssh.Command("ubuntu@"+host, []string{"sudo", "/bin/bash"}, nil)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Stdin = strings.NewReader(script)
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() != 0 {
+			err = fmt.Errorf("%v (%v)", err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	}
+	return nil
+}
+
 func runProvisionScript(script, host string, progressWriter io.Writer) error {
 	params := sshinit.ConfigureParams{
 		Host:           "ubuntu@" + host,
@@ -76,8 +76,17 @@ func (s *provisionerSuite) TestProvisionMachine(c *gc.C) {
 	envtesting.AssertUploadFakeToolsVersions(c, s.DefaultToolsStorage, "released", "released", binVersion)
 	envtools.DefaultBaseURL = defaultToolsURL
 
+	var removed []string
+	s.PatchValue(manual.RemoveMachineAgent, func(host, series, machineId string) error {
+		c.Check(host, gc.Equals, hostname)
+		c.Check(series, gc.Equals, coretesting.FakeDefaultSeries)
+		removed = append(removed, machineId)
+		return nil
+	})
+
 	for i, errorCode := range []int{255, 0} {
 		c.Logf("test %d: code %d", i, errorCode)
+		removed = nil
 		defer fakeSSH{
 			Series:                 series,
 			Arch:                   arch,
@@ -88,8 +97,10 @@ func (s *provisionerSuite) TestProvisionMachine(c *gc.C) {
 		if errorCode != 0 {
 			c.Assert(err, gc.ErrorMatches, fmt.Sprintf("subprocess encountered error code %d", errorCode))
 			c.Assert(machineId, gc.Equals, "")
+			c.Assert(removed, jc.DeepEquals, []string{fmt.Sprint(i + 1)})
 		} else {
 			c.Assert(err, jc.ErrorIsNil)
+			c.Assert(removed, gc.HasLen, 0)
 			c.Assert(machineId, gc.Not(gc.Equals), "")
 			// machine ID will be incremented. Even though we failed and the
 			// machine is removed, the ID is not reused.
@@ -4,10 +4,9 @@
 package environs
 
 import (
-	"github.com/juju/utils/featureflag"
-
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/osenv/featureflag"
 	"github.com/juju/juju/network"
 )
 
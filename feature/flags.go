@@ -5,7 +5,7 @@
 package feature
 
 import (
-	"github.com/juju/utils/featureflag"
+	"github.com/juju/juju/juju/osenv/featureflag"
 )
 
 // TODO (anastasiamac 2015-03-02)
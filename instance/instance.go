@@ -87,6 +87,30 @@ func IsRetryableCreationError(err error) bool {
 	return ok
 }
 
+// QuotaExceededError reports that an instance could not be created
+// because the cloud account has reached a limit on its resources. The
+// condition may clear as other instances are stopped, so instance
+// creation may be retried later.
+type QuotaExceededError struct {
+	message string
+}
+
+// Error is part of the error interface.
+func (e QuotaExceededError) Error() string { return e.message }
+
+// NewQuotaExceededError returns a QuotaExceededError with the given
+// message.
+func NewQuotaExceededError(errorMessage string) *QuotaExceededError {
+	return &QuotaExceededError{errorMessage}
+}
+
+// IsQuotaExceededError returns true if the given error is a
+// QuotaExceededError.
+func IsQuotaExceededError(err error) bool {
+	_, ok := err.(*QuotaExceededError)
+	return ok
+}
+
 func (hc HardwareCharacteristics) String() string {
 	var strs []string
 	if hc.Arch != nil {
@@ -38,6 +38,9 @@ type DeployServiceParams struct {
 	// TODO(dimitern): Drop this in a follow-up in favor of constraints.
 	Networks []string
 	Storage  map[string]storage.Constraints
+	// EndpointBindings maps the names of the charm's relation
+	// endpoints to the spaces they should be bound to.
+	EndpointBindings map[string]string
 }
 
 // DeployService takes a charm and various parameters and deploys it.
@@ -73,13 +76,14 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 
 	// TODO(dimitern): In a follow-up drop Networks and use spaces
 	// constraints for this when possible.
-	service, err := st.AddService(
-		args.ServiceName,
-		args.ServiceOwner,
-		args.Charm,
-		args.Networks,
-		stateStorageConstraints(args.Storage),
-	)
+	service, err := st.AddServiceWithArgs(state.AddServiceArgs{
+		Name:             args.ServiceName,
+		Owner:            args.ServiceOwner,
+		Charm:            args.Charm,
+		Networks:         args.Networks,
+		Storage:          stateStorageConstraints(args.Storage),
+		EndpointBindings: args.EndpointBindings,
+	})
 	if err != nil {
 		return nil, err
 	}
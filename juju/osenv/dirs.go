@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// These are patched out during tests.
+var (
+	goos        = runtime.GOOS
+	currentUser = user.Current
+)
+
+// home overrides the user's home directory when set.
+var (
+	homeMu sync.Mutex
+	home   string
+)
+
+// SetHome overrides the directory returned by Home and returns the
+// previous override. An empty string restores the default resolution.
+// It is intended for tests.
+func SetHome(newHome string) string {
+	homeMu.Lock()
+	defer homeMu.Unlock()
+
+	oldHome := home
+	home = newHome
+	return oldHome
+}
+
+// Home returns the current user's home directory. It is taken from
+// the environment: USERPROFILE, HOMEDRIVE and HOMEPATH, or HOME on
+// Windows, and HOME elsewhere. If none of those is set, the user
+// database is consulted. An empty string means the home directory
+// could not be found.
+func Home() string {
+	homeMu.Lock()
+	override := home
+	homeMu.Unlock()
+	if override != "" {
+		return override
+	}
+
+	if goos == "windows" {
+		if dir := os.Getenv("USERPROFILE"); dir != "" {
+			return dir
+		}
+		drive, path := os.Getenv("HOMEDRIVE"), os.Getenv("HOMEPATH")
+		if drive != "" && path != "" {
+			return drive + path
+		}
+	}
+	if dir := os.Getenv("HOME"); dir != "" {
+		return dir
+	}
+	if u, err := currentUser(); err == nil {
+		return u.HomeDir
+	}
+	return ""
+}
+
+// TempDir returns the directory for temporary files: TMP or TEMP on
+// Windows, falling back to the user's local temp folder, and TMPDIR
+// elsewhere, falling back to /tmp.
+func TempDir() string {
+	if goos == "windows" {
+		for _, key := range []string{"TMP", "TEMP"} {
+			if dir := os.Getenv(key); dir != "" {
+				return dir
+			}
+		}
+		if dir := localAppData(); dir != "" {
+			return filepath.Join(dir, "Temp")
+		}
+		return `C:\Windows\Temp`
+	}
+	if dir := os.Getenv("TMPDIR"); dir != "" {
+		return dir
+	}
+	return "/tmp"
+}
+
+// ConfigDir returns the directory under which applications keep the
+// current user's configuration: APPDATA on Windows, Application
+// Support in the user's Library on macOS, and ~/.config elsewhere.
+// An empty string means the directory could not be found.
+func ConfigDir() string {
+	switch goos {
+	case "windows":
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return dir
+		}
+		return underHome("AppData", "Roaming")
+	case "darwin":
+		return underHome("Library", "Application Support")
+	default:
+		return underHome(".config")
+	}
+}
+
+// localAppData returns the current user's local (non-roaming)
+// application data directory on Windows.
+func localAppData() string {
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return dir
+	}
+	return underHome("AppData", "Local")
+}
+
+// underHome joins the names to the user's home directory, or returns
+// an empty string if there is none.
+func underHome(names ...string) string {
+	dir := Home()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(append([]string{dir}, names...)...)
+}
@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv_test
+
+import (
+	"os/user"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type dirsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&dirsSuite{})
+
+func (s *dirsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	for _, key := range []string{
+		"HOME", "USERPROFILE", "HOMEDRIVE", "HOMEPATH",
+		"APPDATA", "LOCALAPPDATA", "TMP", "TEMP", "TMPDIR",
+	} {
+		s.PatchEnvironment(key, "")
+	}
+	osenv.PatchCurrentUser(s, nil, errors.New("no user"))
+}
+
+func (s *dirsSuite) TestHomeUnix(c *gc.C) {
+	osenv.PatchGOOS(s, "linux")
+	s.PatchEnvironment("USERPROFILE", `C:\Users\fred`)
+	s.PatchEnvironment("HOME", "/home/fred")
+	c.Check(osenv.Home(), gc.Equals, "/home/fred")
+}
+
+func (s *dirsSuite) TestHomeWindowsUserProfile(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	s.PatchEnvironment("USERPROFILE", `C:\Users\fred`)
+	s.PatchEnvironment("HOMEDRIVE", `D:`)
+	s.PatchEnvironment("HOMEPATH", `\fred`)
+	s.PatchEnvironment("HOME", "/home/fred")
+	c.Check(osenv.Home(), gc.Equals, `C:\Users\fred`)
+}
+
+func (s *dirsSuite) TestHomeWindowsHomeDrive(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	s.PatchEnvironment("HOMEDRIVE", `D:`)
+	s.PatchEnvironment("HOMEPATH", `\fred`)
+	s.PatchEnvironment("HOME", "/home/fred")
+	c.Check(osenv.Home(), gc.Equals, `D:\fred`)
+}
+
+func (s *dirsSuite) TestHomeWindowsHomeDriveIncomplete(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	s.PatchEnvironment("HOMEDRIVE", `D:`)
+	s.PatchEnvironment("HOME", "/home/fred")
+	c.Check(osenv.Home(), gc.Equals, "/home/fred")
+}
+
+func (s *dirsSuite) TestHomeFallsBackToUser(c *gc.C) {
+	for _, goos := range []string{"linux", "darwin", "windows"} {
+		c.Logf("os %s", goos)
+		osenv.PatchGOOS(s, goos)
+		osenv.PatchCurrentUser(s, &user.User{HomeDir: "/home/fred"}, nil)
+		c.Check(osenv.Home(), gc.Equals, "/home/fred")
+	}
+}
+
+func (s *dirsSuite) TestHomeNotFound(c *gc.C) {
+	c.Check(osenv.Home(), gc.Equals, "")
+}
+
+func (s *dirsSuite) TestSetHome(c *gc.C) {
+	s.PatchEnvironment("HOME", "/home/fred")
+	c.Assert(osenv.SetHome("/home/wilma"), gc.Equals, "")
+	c.Check(osenv.Home(), gc.Equals, "/home/wilma")
+	c.Assert(osenv.SetHome(""), gc.Equals, "/home/wilma")
+	c.Check(osenv.Home(), gc.Equals, "/home/fred")
+}
+
+func (s *dirsSuite) TestTempDirUnix(c *gc.C) {
+	osenv.PatchGOOS(s, "darwin")
+	c.Check(osenv.TempDir(), gc.Equals, "/tmp")
+	s.PatchEnvironment("TMPDIR", "/var/folders/xy/T")
+	c.Check(osenv.TempDir(), gc.Equals, "/var/folders/xy/T")
+}
+
+func (s *dirsSuite) TestTempDirWindows(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	c.Check(osenv.TempDir(), gc.Equals, `C:\Windows\Temp`)
+	s.PatchEnvironment("USERPROFILE", "fred")
+	c.Check(osenv.TempDir(), gc.Equals, filepath.Join("fred", "AppData", "Local", "Temp"))
+	s.PatchEnvironment("LOCALAPPDATA", "local")
+	c.Check(osenv.TempDir(), gc.Equals, filepath.Join("local", "Temp"))
+	s.PatchEnvironment("TEMP", "temp")
+	c.Check(osenv.TempDir(), gc.Equals, "temp")
+	s.PatchEnvironment("TMP", "tmp")
+	c.Check(osenv.TempDir(), gc.Equals, "tmp")
+}
+
+func (s *dirsSuite) TestConfigDir(c *gc.C) {
+	s.PatchEnvironment("HOME", "fred")
+	for goos, expected := range map[string]string{
+		"linux":   filepath.Join("fred", ".config"),
+		"darwin":  filepath.Join("fred", "Library", "Application Support"),
+		"windows": filepath.Join("fred", "AppData", "Roaming"),
+	} {
+		c.Logf("os %s", goos)
+		osenv.PatchGOOS(s, goos)
+		c.Check(osenv.ConfigDir(), gc.Equals, expected)
+	}
+}
+
+func (s *dirsSuite) TestConfigDirWindowsAppData(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	s.PatchEnvironment("APPDATA", "roaming")
+	c.Check(osenv.ConfigDir(), gc.Equals, "roaming")
+}
+
+func (s *dirsSuite) TestConfigDirNoHome(c *gc.C) {
+	c.Check(osenv.ConfigDir(), gc.Equals, "")
+}
+
+func (s *dirsSuite) TestJujuHomeWindowsWithoutAppData(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	s.PatchEnvironment("USERPROFILE", "fred")
+	c.Check(osenv.JujuHomeWin(), gc.Equals, filepath.Join("fred", "AppData", "Roaming", "Juju"))
+}
@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"strings"
+)
+
+// EnvBuilder composes the environment of a child process from a base
+// environment (usually os.Environ()) and changes to it. On Windows
+// variable names are case insensitive: a change to "path" updates
+// "Path", and the existing name is kept.
+//
+// The zero value is not usable; use NewEnvBuilder.
+type EnvBuilder struct {
+	ignoreCase bool
+	pathSep    string
+
+	// names holds the variables' names in the order they were added.
+	names []string
+
+	// values holds the variables' values, keyed by normalised name.
+	values map[string]string
+}
+
+// NewEnvBuilder returns an EnvBuilder that starts from the provided
+// "name=value" entries, following the conventions of the host OS.
+// Entries without a name, such as the "=C:=C:\" entries found on
+// Windows, are dropped.
+func NewEnvBuilder(base []string) *EnvBuilder {
+	return newEnvBuilder(base, goos == "windows")
+}
+
+// NewWindowsEnvBuilder is like NewEnvBuilder, but always follows the
+// conventions of Windows.
+func NewWindowsEnvBuilder(base []string) *EnvBuilder {
+	return newEnvBuilder(base, true)
+}
+
+func newEnvBuilder(base []string, windows bool) *EnvBuilder {
+	b := &EnvBuilder{
+		ignoreCase: windows,
+		pathSep:    ":",
+		values:     make(map[string]string),
+	}
+	if windows {
+		b.pathSep = ";"
+	}
+	return b.SetAll(base)
+}
+
+func (b *EnvBuilder) key(name string) string {
+	if b.ignoreCase {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+// Set sets the named variable to value.
+func (b *EnvBuilder) Set(name, value string) *EnvBuilder {
+	if name == "" {
+		return b
+	}
+	key := b.key(name)
+	if _, ok := b.values[key]; !ok {
+		b.names = append(b.names, name)
+	}
+	b.values[key] = value
+	return b
+}
+
+// SetAll sets the variables in the provided "name=value" entries, as
+// returned by os.Environ.
+func (b *EnvBuilder) SetAll(entries []string) *EnvBuilder {
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		b.Set(parts[0], parts[1])
+	}
+	return b
+}
+
+// SetMap sets the variables in the provided map.
+func (b *EnvBuilder) SetMap(vars map[string]string) *EnvBuilder {
+	for name, value := range vars {
+		b.Set(name, value)
+	}
+	return b
+}
+
+// Unset removes the named variables.
+func (b *EnvBuilder) Unset(names ...string) *EnvBuilder {
+	for _, name := range names {
+		key := b.key(name)
+		if _, ok := b.values[key]; !ok {
+			continue
+		}
+		delete(b.values, key)
+		for i, existing := range b.names {
+			if b.key(existing) == key {
+				b.names = append(b.names[:i], b.names[i+1:]...)
+				break
+			}
+		}
+	}
+	return b
+}
+
+// Get returns the value of the named variable, and whether it is set.
+func (b *EnvBuilder) Get(name string) (string, bool) {
+	value, ok := b.values[b.key(name)]
+	return value, ok
+}
+
+// PrependPath adds the directories to the front of PATH, in the order
+// given. Directories already in PATH are moved to the front rather
+// than repeated.
+func (b *EnvBuilder) PrependPath(dirs ...string) *EnvBuilder {
+	if len(dirs) == 0 {
+		return b
+	}
+	name := "PATH"
+	for _, existing := range b.names {
+		if b.key(existing) == b.key(name) {
+			name = existing
+			break
+		}
+	}
+	path := append([]string{}, dirs...)
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		seen[b.key(dir)] = true
+	}
+	current, _ := b.Get(name)
+	for _, dir := range strings.Split(current, b.pathSep) {
+		if dir != "" && !seen[b.key(dir)] {
+			path = append(path, dir)
+		}
+	}
+	return b.Set(name, strings.Join(path, b.pathSep))
+}
+
+// Environ returns the environment as "name=value" entries, suitable
+// for exec.Cmd's Env. The variables are in the order they were first
+// set.
+func (b *EnvBuilder) Environ() []string {
+	env := make([]string, 0, len(b.names))
+	for _, name := range b.names {
+		env = append(env, name+"="+b.values[b.key(name)])
+	}
+	return env
+}
@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type envBuilderSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&envBuilderSuite{})
+
+func (s *envBuilderSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	osenv.PatchGOOS(s, "linux")
+}
+
+func (s *envBuilderSuite) TestBase(c *gc.C) {
+	b := osenv.NewEnvBuilder([]string{"A=1", "B=x=y", "=C:=C:\\", "broken", "C="})
+	c.Assert(b.Environ(), jc.DeepEquals, []string{"A=1", "B=x=y", "C="})
+	value, ok := b.Get("B")
+	c.Check(ok, jc.IsTrue)
+	c.Check(value, gc.Equals, "x=y")
+	_, ok = b.Get("b")
+	c.Check(ok, jc.IsFalse)
+}
+
+func (s *envBuilderSuite) TestChanges(c *gc.C) {
+	env := osenv.NewEnvBuilder([]string{"A=1", "B=2", "C=3"}).
+		Set("B", "two").
+		Set("b", "lower").
+		Unset("A", "missing").
+		SetAll([]string{"D=4"}).
+		SetMap(map[string]string{"E": "5"}).
+		Environ()
+	c.Assert(env, jc.DeepEquals, []string{"B=two", "C=3", "b=lower", "D=4", "E=5"})
+}
+
+func (s *envBuilderSuite) TestWindowsIgnoresCase(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	env := osenv.NewEnvBuilder([]string{"a=foo", "b=bar", "foo=val", "TEMP=t"}).
+		SetAll([]string{"a=baz", "c=omg", "FOO=val2", "d=another"}).
+		Unset("temp").
+		Environ()
+	c.Assert(env, jc.DeepEquals, []string{"a=baz", "b=bar", "foo=val2", "c=omg", "d=another"})
+}
+
+func (s *envBuilderSuite) TestNewWindowsEnvBuilder(c *gc.C) {
+	env := osenv.NewWindowsEnvBuilder([]string{"Path=C:\\bin"}).
+		Set("PATH", "C:\\other").
+		Environ()
+	c.Assert(env, jc.DeepEquals, []string{"Path=C:\\other"})
+}
+
+func (s *envBuilderSuite) TestPrependPath(c *gc.C) {
+	env := osenv.NewEnvBuilder([]string{"PATH=/usr/bin:/bin:/opt/bin"}).
+		PrependPath("/opt/bin", "/var/lib/juju/tools").
+		Environ()
+	c.Assert(env, jc.DeepEquals, []string{"PATH=/opt/bin:/var/lib/juju/tools:/usr/bin:/bin"})
+}
+
+func (s *envBuilderSuite) TestPrependPathUnset(c *gc.C) {
+	env := osenv.NewEnvBuilder(nil).PrependPath("/bin").Environ()
+	c.Assert(env, jc.DeepEquals, []string{"PATH=/bin"})
+}
+
+func (s *envBuilderSuite) TestPrependPathWindows(c *gc.C) {
+	env := osenv.NewWindowsEnvBuilder([]string{`Path=C:\Windows;C:\Juju\bin`}).
+		PrependPath(`c:\juju\bin`).
+		Environ()
+	c.Assert(env, jc.DeepEquals, []string{`Path=c:\juju\bin;C:\Windows`})
+}
@@ -3,9 +3,49 @@
 
 package osenv
 
+import (
+	"os/user"
+)
+
 var (
 	JujuHomeWin   = jujuHomeWin
 	JujuHomeLinux = jujuHomeLinux
 	MergeEnvUnix  = mergeEnvUnix
 	MergeEnvWin   = mergeEnvWin
 )
+
+func PatchGOOS(patcher patcher, os string) {
+	patcher.PatchValue(&goos, os)
+}
+
+func PatchCurrentUser(patcher patcher, u *user.User, err error) {
+	patcher.PatchValue(&currentUser, func() (*user.User, error) {
+		return u, err
+	})
+}
+
+func PatchRename(patcher patcher, f func(string, string) error) {
+	patcher.PatchValue(&rename, f)
+}
+
+func PatchRemoveAll(patcher patcher, f func(string) error) {
+	patcher.PatchValue(&removeAll, f)
+}
+
+func PatchRemoveAll(patcher patcher, f func(string) error) {
+	patcher.PatchValue(&removeAll, f)
+}
+
+// PatchRegistry replaces the registry with a copy, so that tests can
+// register variables without affecting other tests.
+func PatchRegistry(patcher patcher) {
+	copied := make(map[string]*Var)
+	for name, v := range registry {
+		copied[name] = v
+	}
+	patcher.PatchValue(&registry, copied)
+}
+
+type patcher interface {
+	PatchValue(dest, value interface{})
+}
@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The featureflag package gates juju's experimental features on the
+// comma-separated flags in the JUJU_DEV_FEATURE_FLAGS environment
+// variable, e.g. "jes,address-allocation".
+//
+// The flags are read when the package is initialised, and are shared
+// with github.com/juju/utils/featureflag, so that the flags read from
+// the registry on Windows are seen here too. The names of the current
+// flags are defined in github.com/juju/juju/feature.
+package featureflag
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/utils/featureflag"
+
+	"github.com/juju/juju/juju/osenv"
+)
+
+// setMu serialises changes to the flags.
+var setMu sync.Mutex
+
+func init() {
+	Reload()
+}
+
+// Reload reads the flags from the environment again.
+func Reload() {
+	setMu.Lock()
+	defer setMu.Unlock()
+	featureflag.SetFlagsFromEnvironment(osenv.JujuFeatureFlagEnvKey)
+}
+
+// Enabled reports whether the named feature is enabled. Flags are not
+// case sensitive, and the empty flag is always enabled.
+func Enabled(flag string) bool {
+	return featureflag.Enabled(flag)
+}
+
+// All returns the enabled flags, sorted.
+func All() []string {
+	flags := featureflag.All()
+	sort.Strings(flags)
+	return flags
+}
+
+// String returns the enabled flags as a human readable string.
+func String() string {
+	return featureflag.String()
+}
+
+// AsEnvironmentValue returns the enabled flags in the form used for
+// JUJU_DEV_FEATURE_FLAGS, so that they can be passed on to other
+// processes.
+func AsEnvironmentValue() string {
+	return featureflag.AsEnvironmentValue()
+}
+
+// Set replaces the enabled flags with the provided ones, which child
+// processes inherit too, and returns a function that restores the
+// previous flags. It is intended for tests, which should call the
+// restore function during cleanup.
+func Set(flags ...string) (restore func()) {
+	setMu.Lock()
+	defer setMu.Unlock()
+
+	old := os.Getenv(osenv.JujuFeatureFlagEnvKey)
+	os.Setenv(osenv.JujuFeatureFlagEnvKey, strings.Join(flags, ","))
+	featureflag.SetFlagsFromEnvironment(osenv.JujuFeatureFlagEnvKey)
+	return func() {
+		setMu.Lock()
+		defer setMu.Unlock()
+		os.Setenv(osenv.JujuFeatureFlagEnvKey, old)
+		featureflag.SetFlagsFromEnvironment(osenv.JujuFeatureFlagEnvKey)
+	}
+}
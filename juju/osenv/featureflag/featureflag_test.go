@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package featureflag_test
+
+import (
+	"os"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	utilsfeatureflag "github.com/juju/utils/featureflag"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/juju/osenv/featureflag"
+)
+
+type flagSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&flagSuite{})
+
+func (s *flagSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.PatchEnvironment(osenv.JujuFeatureFlagEnvKey, "")
+	featureflag.Reload()
+	s.AddCleanup(func(*gc.C) { featureflag.Reload() })
+}
+
+func (s *flagSuite) TestReload(c *gc.C) {
+	c.Check(featureflag.Enabled("magic"), jc.IsFalse)
+	s.PatchEnvironment(osenv.JujuFeatureFlagEnvKey, " Magic, special,,")
+	c.Check(featureflag.Enabled("magic"), jc.IsFalse)
+	featureflag.Reload()
+	c.Check(featureflag.Enabled("magic"), jc.IsTrue)
+	c.Check(featureflag.Enabled("MAGIC"), jc.IsTrue)
+	c.Check(featureflag.Enabled("special"), jc.IsTrue)
+	c.Check(featureflag.Enabled("other"), jc.IsFalse)
+	c.Check(featureflag.Enabled(""), jc.IsTrue)
+	c.Check(featureflag.All(), jc.DeepEquals, []string{"magic", "special"})
+	c.Check(featureflag.String(), gc.Equals, `"magic", "special"`)
+	c.Check(featureflag.AsEnvironmentValue(), gc.Equals, "magic,special")
+}
+
+func (s *flagSuite) TestSet(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuFeatureFlagEnvKey, "old")
+	featureflag.Reload()
+
+	restore := featureflag.Set("new", "other")
+	c.Check(featureflag.All(), jc.DeepEquals, []string{"new", "other"})
+	c.Check(os.Getenv(osenv.JujuFeatureFlagEnvKey), gc.Equals, "new,other")
+
+	restore()
+	c.Check(featureflag.All(), jc.DeepEquals, []string{"old"})
+	c.Check(os.Getenv(osenv.JujuFeatureFlagEnvKey), gc.Equals, "old")
+}
+
+func (s *flagSuite) TestSharedWithUtils(c *gc.C) {
+	restore := featureflag.Set("magic")
+	defer restore()
+	c.Check(utilsfeatureflag.Enabled("magic"), jc.IsTrue)
+}
@@ -6,16 +6,13 @@ package osenv
 import (
 	"os"
 	"path/filepath"
-	"runtime"
 	"sync"
-
-	"github.com/juju/utils"
 )
 
 // jujuHome stores the path to the juju configuration
 // folder, which is only meaningful when running the juju
 // CLI tool, and is typically defined by $JUJU_HOME or
-// $HOME/.juju as default.
+// JujuHomeDir as default.
 var (
 	jujuHomeMu sync.Mutex
 	jujuHome   string
@@ -56,31 +53,35 @@ func JujuHomePath(names ...string) string {
 	return filepath.Join(all...)
 }
 
-// JujuHomeDir returns the directory where juju should store application-specific files
+// JujuHomeDir returns the directory where juju should store application-specific files.
+// This is $JUJU_DATA or $JUJU_HOME if set, otherwise JujuXDGDataHome. A legacy
+// ~/.juju directory that has not been migrated (see MigrateLegacyJujuHome) is
+// used in preference, so existing files are not lost; once a migration has
+// completed, any legacy directory left behind is ignored.
 func JujuHomeDir() string {
-	JujuHomeDir := os.Getenv(JujuHomeEnvKey)
-	if JujuHomeDir == "" {
-		if runtime.GOOS == "windows" {
-			JujuHomeDir = jujuHomeWin()
-		} else {
-			JujuHomeDir = jujuHomeLinux()
+	if dir := jujuDataOverride(); dir != "" {
+		return dir
+	}
+	if goos == "windows" {
+		return jujuHomeWin()
+	}
+	dataHome := JujuXDGDataHome()
+	if legacy := jujuHomeLinux(); legacy != "" && !legacyHomeMigrated(dataHome) {
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return legacy
 		}
 	}
-	return JujuHomeDir
+	return dataHome
 }
 
 // jujuHomeLinux returns the directory where juju should store application-specific files on Linux.
 func jujuHomeLinux() string {
-	home := utils.Home()
-	if home == "" {
-		return ""
-	}
-	return filepath.Join(home, ".juju")
+	return underHome(".juju")
 }
 
 // jujuHomeWin returns the directory where juju should store application-specific files on Windows.
 func jujuHomeWin() string {
-	appdata := os.Getenv("APPDATA")
+	appdata := ConfigDir()
 	if appdata == "" {
 		return ""
 	}
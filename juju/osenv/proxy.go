@@ -0,0 +1,295 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// The environment variables that hold the proxy settings. Both these
+// and their upper-case forms are read and written.
+const (
+	HttpProxyEnvKey  = "http_proxy"
+	HttpsProxyEnvKey = "https_proxy"
+	FtpProxyEnvKey   = "ftp_proxy"
+	NoProxyEnvKey    = "no_proxy"
+)
+
+// ProxySettings holds the proxies to use for HTTP, HTTPS and FTP, and
+// the hosts that should be reached directly.
+type ProxySettings struct {
+	Http  string
+	Https string
+	Ftp   string
+
+	// NoProxy holds host names, domains (".example.com"), IP
+	// addresses and CIDR ranges ("10.0.0.0/8"), or "*" for all hosts.
+	NoProxy []string
+}
+
+// DetectProxies returns the proxy settings found in the environment.
+// The lower-case variables take precedence over the upper-case ones.
+func DetectProxies() ProxySettings {
+	return ProxySettings{
+		Http:    getProxyEnv(HttpProxyEnvKey),
+		Https:   getProxyEnv(HttpsProxyEnvKey),
+		Ftp:     getProxyEnv(FtpProxyEnvKey),
+		NoProxy: splitNoProxy(getProxyEnv(NoProxyEnvKey)),
+	}
+}
+
+func getProxyEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToUpper(key))
+}
+
+func splitNoProxy(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// IsEmpty reports whether no proxies are set.
+func (s ProxySettings) IsEmpty() bool {
+	return s.Http == "" && s.Https == "" && s.Ftp == "" && len(s.NoProxy) == 0
+}
+
+// Normalize returns the settings in canonical form: proxies without a
+// scheme get "http://", and NoProxy is lower-cased, de-duplicated and
+// sorted. An error is returned if a proxy is not a valid URL or a
+// NoProxy entry that looks like a CIDR range is not one.
+func (s ProxySettings) Normalize() (ProxySettings, error) {
+	var result ProxySettings
+	for _, p := range []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{HttpProxyEnvKey, s.Http, &result.Http},
+		{HttpsProxyEnvKey, s.Https, &result.Https},
+		{FtpProxyEnvKey, s.Ftp, &result.Ftp},
+	} {
+		value, err := normalizeProxy(p.value)
+		if err != nil {
+			return ProxySettings{}, errors.Annotatef(err, "invalid %s", p.name)
+		}
+		*p.dest = value
+	}
+	seen := make(map[string]bool)
+	for _, host := range s.NoProxy {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || seen[host] {
+			continue
+		}
+		if strings.Contains(host, "/") {
+			if _, _, err := net.ParseCIDR(host); err != nil {
+				return ProxySettings{}, errors.NotValidf("%s entry %q", NoProxyEnvKey, host)
+			}
+		}
+		seen[host] = true
+		result.NoProxy = append(result.NoProxy, host)
+	}
+	sort.Strings(result.NoProxy)
+	return result, nil
+}
+
+func normalizeProxy(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", errors.NotValidf("proxy %q", value)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return "", errors.NotValidf("proxy %q (scheme %q)", value, u.Scheme)
+	}
+	if u.Host == "" {
+		return "", errors.NotValidf("proxy %q (no host)", value)
+	}
+	return u.String(), nil
+}
+
+// UseProxy reports whether requests to the provided host, which may
+// include a port, should go through a proxy.
+func (s ProxySettings) UseProxy(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+	for _, entry := range s.NoProxy {
+		entry = strings.ToLower(entry)
+		switch {
+		case entry == "*":
+			return false
+		case strings.Contains(entry, "/"):
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err == nil && ip != nil && ipNet.Contains(ip) {
+				return false
+			}
+		case ip != nil:
+			if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(ip) {
+				return false
+			}
+		default:
+			domain := strings.TrimPrefix(entry, "*")
+			if host == strings.TrimPrefix(domain, ".") {
+				return false
+			}
+			if !strings.HasPrefix(domain, ".") {
+				domain = "." + domain
+			}
+			if strings.HasSuffix(host, domain) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ProxyURL returns the proxy to use for the provided URL, or nil if
+// the URL should be reached directly. Unlike http.ProxyFromEnvironment
+// it honours CIDR ranges in NoProxy.
+func (s ProxySettings) ProxyURL(target *url.URL) (*url.URL, error) {
+	var proxy string
+	switch target.Scheme {
+	case "http":
+		proxy = s.Http
+	case "https":
+		proxy = s.Https
+	case "ftp":
+		proxy = s.Ftp
+	}
+	if proxy == "" || !s.UseProxy(target.Host) {
+		return nil, nil
+	}
+	proxy, err := normalizeProxy(proxy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return url.Parse(proxy)
+}
+
+// EnvironmentValues returns the settings as "key=value" strings, in
+// both lower and upper case, as used for a command's environment.
+// Empty settings are omitted.
+func (s ProxySettings) EnvironmentValues() []string {
+	var values []string
+	for _, kv := range s.pairs() {
+		if kv[1] != "" {
+			values = append(values,
+				kv[0]+"="+kv[1],
+				strings.ToUpper(kv[0])+"="+kv[1],
+			)
+		}
+	}
+	return values
+}
+
+// AsScriptEnvironment returns the settings as shell export statements,
+// one per line, suitable for sourcing from a profile script.
+func (s ProxySettings) AsScriptEnvironment() string {
+	var lines []string
+	for _, kv := range s.pairs() {
+		if kv[1] != "" {
+			lines = append(lines,
+				fmt.Sprintf("export %s=%s", kv[0], utils.ShQuote(kv[1])),
+				fmt.Sprintf("export %s=%s", strings.ToUpper(kv[0]), utils.ShQuote(kv[1])),
+			)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AsSystemdEnvironment returns the settings as systemd "Environment="
+// directives, one per line, suitable for a unit or drop-in file.
+func (s ProxySettings) AsSystemdEnvironment() string {
+	var lines []string
+	for _, value := range s.EnvironmentValues() {
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(value)
+		lines = append(lines, fmt.Sprintf(`Environment="%s"`, escaped))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s ProxySettings) pairs() [][2]string {
+	return [][2]string{
+		{HttpProxyEnvKey, s.Http},
+		{HttpsProxyEnvKey, s.Https},
+		{FtpProxyEnvKey, s.Ftp},
+		{NoProxyEnvKey, strings.Join(s.NoProxy, ",")},
+	}
+}
+
+// processProxy holds the settings last applied to this process.
+var (
+	processProxyMu sync.Mutex
+	processProxy   ProxySettings
+)
+
+// SetProxySettings applies the settings to the whole process: the
+// proxy environment variables are set (or unset, for empty settings)
+// for this process and its children, and ProxyFromSettings uses them
+// from then on. It returns the previous settings.
+func SetProxySettings(s ProxySettings) ProxySettings {
+	processProxyMu.Lock()
+	defer processProxyMu.Unlock()
+
+	for _, kv := range s.pairs() {
+		for _, key := range []string{kv[0], strings.ToUpper(kv[0])} {
+			if kv[1] == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, kv[1])
+			}
+		}
+	}
+	old := processProxy
+	processProxy = s
+	return old
+}
+
+// CurrentProxySettings returns the settings last applied with
+// SetProxySettings.
+func CurrentProxySettings() ProxySettings {
+	processProxyMu.Lock()
+	defer processProxyMu.Unlock()
+	return processProxy
+}
+
+// ProxyFromSettings may be used as an http.Transport's Proxy function.
+// Unlike http.ProxyFromEnvironment, which reads the environment only
+// once, it follows changes made with SetProxySettings.
+func ProxyFromSettings(req *http.Request) (*url.URL, error) {
+	return CurrentProxySettings().ProxyURL(req.URL)
+}
@@ -0,0 +1,222 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+var logger = loggo.GetLogger("juju.osenv")
+
+// Kind is the type of value held by an environment variable.
+type Kind string
+
+const (
+	String   Kind = "string"
+	Bool     Kind = "bool"
+	Int      Kind = "int"
+	Duration Kind = "duration"
+)
+
+// Var describes an environment variable that juju reads.
+type Var struct {
+	// Name is the name of the variable, e.g. "JUJU_HOME".
+	Name string
+
+	// Kind is the type of the variable's value.
+	Kind Kind
+
+	// Default is the value used when the variable is unset.
+	Default string
+
+	// Aliases holds deprecated names of the variable, which are read
+	// if it is unset.
+	Aliases []string
+
+	// Description describes what the variable does.
+	Description string
+}
+
+// registry holds the known variables, by name and by alias.
+var registry = make(map[string]*Var)
+
+// Register adds a variable to the known set and returns it. Register
+// is intended to be called while initialising packages; it panics if
+// the name or an alias is already known.
+func Register(v Var) *Var {
+	if v.Kind == "" {
+		v.Kind = String
+	}
+	for _, name := range append([]string{v.Name}, v.Aliases...) {
+		if _, ok := registry[name]; ok {
+			panic(fmt.Sprintf("environment variable %q registered twice", name))
+		}
+	}
+	if v.Default != "" {
+		if err := v.check(v.Default); err != nil {
+			panic(fmt.Sprintf("default for environment variable %q: %v", v.Name, err))
+		}
+	}
+	result := &v
+	registry[v.Name] = result
+	for _, alias := range v.Aliases {
+		registry[alias] = result
+	}
+	return result
+}
+
+// LookupVar returns the registered variable with the provided name or
+// alias.
+func LookupVar(name string) (*Var, bool) {
+	v, ok := registry[name]
+	return v, ok
+}
+
+// Vars returns all the registered variables, sorted by name.
+func Vars() []Var {
+	var vars []Var
+	for name, v := range registry {
+		if name == v.Name {
+			vars = append(vars, *v)
+		}
+	}
+	sort.Sort(varsByName(vars))
+	return vars
+}
+
+type varsByName []Var
+
+func (v varsByName) Len() int           { return len(v) }
+func (v varsByName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v varsByName) Less(i, j int) bool { return v[i].Name < v[j].Name }
+
+// lookup returns the variable's value and whether it is set, reading
+// the deprecated aliases if the variable itself is not.
+func (v *Var) lookup() (string, bool) {
+	if value := os.Getenv(v.Name); value != "" {
+		return value, true
+	}
+	for _, alias := range v.Aliases {
+		if value := os.Getenv(alias); value != "" {
+			logger.Warningf("%s is deprecated, use %s instead", alias, v.Name)
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// IsSet reports whether the variable (or one of its aliases) is set
+// to a non-empty value.
+func (v *Var) IsSet() bool {
+	_, ok := v.lookup()
+	return ok
+}
+
+// Value returns the variable's value, or its default if it is unset.
+func (v *Var) Value() string {
+	if value, ok := v.lookup(); ok {
+		return value
+	}
+	return v.Default
+}
+
+// Bool returns the variable's value as a boolean. Unset variables
+// without a default are false.
+func (v *Var) Bool() (bool, error) {
+	value := v.Value()
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, v.invalid(value, "expected true|false")
+	}
+	return b, nil
+}
+
+// Int returns the variable's value as an integer. Unset variables
+// without a default are 0.
+func (v *Var) Int() (int, error) {
+	value := v.Value()
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, v.invalid(value, "expected an integer")
+	}
+	return i, nil
+}
+
+// Duration returns the variable's value as a duration, such as "5s".
+// Unset variables without a default are 0.
+func (v *Var) Duration() (time.Duration, error) {
+	value := v.Value()
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, v.invalid(value, "expected a duration")
+	}
+	return d, nil
+}
+
+func (v *Var) invalid(value, expected string) error {
+	return errors.NotValidf("%s value %q (%s)", v.Name, value, expected)
+}
+
+// check returns an error if value is not valid for the variable's kind.
+func (v *Var) check(value string) error {
+	var err error
+	switch v.Kind {
+	case Bool:
+		_, err = strconv.ParseBool(value)
+	case Int:
+		_, err = strconv.Atoi(value)
+	case Duration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return v.invalid(value, "expected "+string(v.Kind))
+	}
+	return nil
+}
+
+// Validate checks the process environment, returning an error that
+// names any JUJU_* variables that are not registered or that have
+// values of the wrong kind.
+func Validate() error {
+	var problems []string
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		name := parts[0]
+		if !strings.HasPrefix(name, "JUJU_") {
+			continue
+		}
+		if len(parts) != 2 || parts[1] == "" {
+			// Empty variables are treated as unset.
+			continue
+		}
+		v, ok := registry[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown variable %s", name))
+		} else if err := v.check(parts[1]); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.Errorf("invalid juju environment: %s", strings.Join(problems, "; "))
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type registrySuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&registrySuite{})
+
+func (s *registrySuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	osenv.PatchRegistry(s)
+	// Start from an environment without any juju variables.
+	for _, kv := range os.Environ() {
+		if name := strings.SplitN(kv, "=", 2)[0]; strings.HasPrefix(name, "JUJU_") {
+			s.PatchEnvironment(name, "")
+		}
+	}
+}
+
+func (s *registrySuite) TestKnownVars(c *gc.C) {
+	for _, name := range []string{
+		osenv.JujuEnvEnvKey,
+		osenv.JujuHomeEnvKey,
+		osenv.JujuStatusIsoTimeEnvKey,
+		"JUJU_CONTEXT_ID",
+	} {
+		v, ok := osenv.LookupVar(name)
+		c.Check(ok, jc.IsTrue, gc.Commentf("%s", name))
+		c.Check(v.Name, gc.Equals, name)
+	}
+	_, ok := osenv.LookupVar("JUJU_UNKNOWN")
+	c.Check(ok, jc.IsFalse)
+
+	vars := osenv.Vars()
+	for i := 1; i < len(vars); i++ {
+		c.Check(vars[i-1].Name < vars[i].Name, jc.IsTrue)
+	}
+}
+
+func (s *registrySuite) TestValue(c *gc.C) {
+	v := osenv.Register(osenv.Var{Name: "JUJU_TEST_VALUE", Default: "default"})
+	c.Check(v.Kind, gc.Equals, osenv.String)
+	c.Check(v.IsSet(), jc.IsFalse)
+	c.Check(v.Value(), gc.Equals, "default")
+	s.PatchEnvironment("JUJU_TEST_VALUE", "set")
+	c.Check(v.IsSet(), jc.IsTrue)
+	c.Check(v.Value(), gc.Equals, "set")
+}
+
+func (s *registrySuite) TestAliases(c *gc.C) {
+	v := osenv.Register(osenv.Var{
+		Name:    "JUJU_TEST_NEW",
+		Aliases: []string{"JUJU_TEST_OLD"},
+	})
+	s.PatchEnvironment("JUJU_TEST_OLD", "old")
+	c.Check(v.Value(), gc.Equals, "old")
+	s.PatchEnvironment("JUJU_TEST_NEW", "new")
+	c.Check(v.Value(), gc.Equals, "new")
+
+	alias, ok := osenv.LookupVar("JUJU_TEST_OLD")
+	c.Assert(ok, jc.IsTrue)
+	c.Check(alias, gc.Equals, v)
+}
+
+func (s *registrySuite) TestBool(c *gc.C) {
+	v := osenv.Register(osenv.Var{Name: "JUJU_TEST_BOOL", Kind: osenv.Bool})
+	b, err := v.Bool()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(b, jc.IsFalse)
+	s.PatchEnvironment("JUJU_TEST_BOOL", "true")
+	b, err = v.Bool()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(b, jc.IsTrue)
+	s.PatchEnvironment("JUJU_TEST_BOOL", "yes")
+	_, err = v.Bool()
+	c.Check(err, gc.ErrorMatches, `JUJU_TEST_BOOL value "yes" \(expected true\|false\) not valid`)
+}
+
+func (s *registrySuite) TestInt(c *gc.C) {
+	v := osenv.Register(osenv.Var{Name: "JUJU_TEST_INT", Kind: osenv.Int, Default: "1"})
+	i, err := v.Int()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(i, gc.Equals, 1)
+	s.PatchEnvironment("JUJU_TEST_INT", "2")
+	i, err = v.Int()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(i, gc.Equals, 2)
+	s.PatchEnvironment("JUJU_TEST_INT", "two")
+	_, err = v.Int()
+	c.Check(err, gc.ErrorMatches, `JUJU_TEST_INT value "two" \(expected an integer\) not valid`)
+}
+
+func (s *registrySuite) TestDuration(c *gc.C) {
+	v := osenv.Register(osenv.Var{Name: "JUJU_TEST_DURATION", Kind: osenv.Duration})
+	d, err := v.Duration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(d, gc.Equals, time.Duration(0))
+	s.PatchEnvironment("JUJU_TEST_DURATION", "1m30s")
+	d, err = v.Duration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(d, gc.Equals, 90*time.Second)
+	s.PatchEnvironment("JUJU_TEST_DURATION", "90")
+	_, err = v.Duration()
+	c.Check(err, gc.ErrorMatches, `JUJU_TEST_DURATION value "90" \(expected a duration\) not valid`)
+}
+
+func (s *registrySuite) TestRegisterTwice(c *gc.C) {
+	osenv.Register(osenv.Var{Name: "JUJU_TEST_TWICE", Aliases: []string{"JUJU_TEST_ONCE"}})
+	c.Check(func() {
+		osenv.Register(osenv.Var{Name: "JUJU_TEST_TWICE"})
+	}, gc.PanicMatches, `environment variable "JUJU_TEST_TWICE" registered twice`)
+	c.Check(func() {
+		osenv.Register(osenv.Var{Name: "JUJU_TEST_OTHER", Aliases: []string{"JUJU_TEST_ONCE"}})
+	}, gc.PanicMatches, `environment variable "JUJU_TEST_ONCE" registered twice`)
+}
+
+func (s *registrySuite) TestRegisterInvalidDefault(c *gc.C) {
+	c.Check(func() {
+		osenv.Register(osenv.Var{Name: "JUJU_TEST_DEFAULT", Kind: osenv.Int, Default: "one"})
+	}, gc.PanicMatches, `default for environment variable "JUJU_TEST_DEFAULT": .* not valid`)
+}
+
+func (s *registrySuite) TestValidate(c *gc.C) {
+	c.Assert(osenv.Validate(), jc.ErrorIsNil)
+
+	s.PatchEnvironment(osenv.JujuEnvEnvKey, "local")
+	s.PatchEnvironment(osenv.JujuStatusIsoTimeEnvKey, "true")
+	s.PatchEnvironment("JUJU_UNIT_NAME", "wordpress/0")
+	c.Assert(osenv.Validate(), jc.ErrorIsNil)
+
+	s.PatchEnvironment("JUJU_ENVIRONMENT", "local")
+	s.PatchEnvironment(osenv.JujuCLIVersion, "two")
+	c.Assert(osenv.Validate(), gc.ErrorMatches, "invalid juju environment: "+
+		`JUJU_CLI_VERSION value "two" \(expected int\) not valid; `+
+		"unknown variable JUJU_ENVIRONMENT")
+}
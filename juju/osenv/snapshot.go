@@ -0,0 +1,74 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// environMu serialises the changes made by WithEnvironment, so that
+// concurrent calls do not see each other's environments.
+var environMu sync.Mutex
+
+// Snapshot returns a copy of the process environment.
+func Snapshot() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		// On Windows the environment includes entries such as
+		// "=C:=C:\\" that record the drives' current directories;
+		// they cannot be set with os.Setenv so they are skipped.
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+		env[parts[0]] = parts[1]
+	}
+	return env
+}
+
+// Restore replaces the process environment with env, which is usually
+// the result of an earlier call to Snapshot. Only the variables that
+// differ are changed; the entries Snapshot skips are left alone.
+func Restore(env map[string]string) error {
+	current := Snapshot()
+	for name := range current {
+		if _, ok := env[name]; !ok {
+			if err := os.Unsetenv(name); err != nil {
+				return errors.Annotatef(err, "cannot unset %s", name)
+			}
+		}
+	}
+	for name, value := range env {
+		if old, ok := current[name]; ok && old == value {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return errors.Annotatef(err, "cannot set %s", name)
+		}
+	}
+	return nil
+}
+
+// WithEnvironment calls f with the process environment replaced by
+// env, and then restores the original environment, even if f panics.
+// Calls to WithEnvironment are serialised, but nothing stops other
+// goroutines from reading or changing the environment meanwhile, so it
+// is best used where the process is otherwise quiet, e.g. in tests.
+func WithEnvironment(env map[string]string, f func()) error {
+	environMu.Lock()
+	defer environMu.Unlock()
+
+	original := Snapshot()
+	if err := Restore(env); err != nil {
+		Restore(original)
+		return errors.Trace(err)
+	}
+	defer Restore(original)
+	f()
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv_test
+
+import (
+	"os"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type snapshotSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&snapshotSuite{})
+
+func (s *snapshotSuite) TestSnapshot(c *gc.C) {
+	s.PatchEnvironment("OSENV_TEST_A", "a=b")
+	env := osenv.Snapshot()
+	c.Check(env["OSENV_TEST_A"], gc.Equals, "a=b")
+	c.Check(env, gc.HasLen, len(os.Environ()))
+
+	// The snapshot is a copy.
+	os.Setenv("OSENV_TEST_A", "c")
+	c.Check(env["OSENV_TEST_A"], gc.Equals, "a=b")
+}
+
+func (s *snapshotSuite) TestRestore(c *gc.C) {
+	s.PatchEnvironment("OSENV_TEST_A", "a")
+	s.PatchEnvironment("OSENV_TEST_B", "")
+	env := osenv.Snapshot()
+
+	changed := osenv.Snapshot()
+	changed["OSENV_TEST_A"] = "changed"
+	delete(changed, "OSENV_TEST_B")
+	changed["OSENV_TEST_C"] = "added"
+	err := osenv.Restore(changed)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(osenv.Snapshot(), jc.DeepEquals, changed)
+
+	err = osenv.Restore(env)
+	c.Assert(err, jc.ErrorIsNil)
+	restored := osenv.Snapshot()
+	c.Check(restored, jc.DeepEquals, env)
+	_, ok := restored["OSENV_TEST_C"]
+	c.Check(ok, jc.IsFalse)
+	value, ok := restored["OSENV_TEST_B"]
+	c.Check(ok, jc.IsTrue)
+	c.Check(value, gc.Equals, "")
+}
+
+func (s *snapshotSuite) TestWithEnvironment(c *gc.C) {
+	s.PatchEnvironment("OSENV_TEST_A", "a")
+	before := osenv.Snapshot()
+
+	var during map[string]string
+	err := osenv.WithEnvironment(map[string]string{"OSENV_TEST_B": "b"}, func() {
+		during = osenv.Snapshot()
+		os.Setenv("OSENV_TEST_C", "leaked?")
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(during, jc.DeepEquals, map[string]string{"OSENV_TEST_B": "b"})
+	c.Check(osenv.Snapshot(), jc.DeepEquals, before)
+}
+
+func (s *snapshotSuite) TestWithEnvironmentPanic(c *gc.C) {
+	before := osenv.Snapshot()
+	c.Check(func() {
+		osenv.WithEnvironment(nil, func() {
+			os.Setenv("OSENV_TEST_A", "a")
+			panic("boom")
+		})
+	}, gc.PanicMatches, "boom")
+	c.Check(osenv.Snapshot(), jc.DeepEquals, before)
+}
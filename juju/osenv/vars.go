@@ -13,6 +13,7 @@ import (
 const (
 	JujuEnvEnvKey           = "JUJU_ENV"
 	JujuHomeEnvKey          = "JUJU_HOME"
+	JujuDataEnvKey          = "JUJU_DATA"
 	JujuRepositoryEnvKey    = "JUJU_REPOSITORY"
 	JujuLoggingConfigEnvKey = "JUJU_LOGGING_CONFIG"
 	JujuFeatureFlagEnvKey   = "JUJU_DEV_FEATURE_FLAGS"
@@ -43,8 +44,129 @@ const (
 	// This includes args and output.
 	// Default is 1.
 	JujuCLIVersion = "JUJU_CLI_VERSION"
+
+	// JujuCookieFileEnvKey names the file holding the client's macaroon
+	// cookies, which defaults to ~/.go-cookies.
+	JujuCookieFileEnvKey = "JUJU_COOKIEFILE"
+
+	// JujuDummyDelayEnvKey sets the delay the dummy provider adds to
+	// its operations.
+	JujuDummyDelayEnvKey = "JUJU_DUMMY_DELAY"
+
+	// JujuLayoutEnvKey names the layout of juju's files on the local
+	// machine, overriding the one chosen for its OS; see juju/paths.
+	JujuLayoutEnvKey = "JUJU_LAYOUT"
+
+	// JujuPrefixEnvKey names a directory under which all of juju's
+	// files on the local machine are kept, for unprivileged installs
+	// and test sandboxes; see juju/paths.
+	JujuPrefixEnvKey = "JUJU_PREFIX"
+
+	// JujuAPIAddressesEnvKey names the space-separated API server
+	// addresses of the environment, as set for hooks and plugins.
+	JujuAPIAddressesEnvKey = "JUJU_API_ADDRESSES"
+
+	// JujuEnvUUIDEnvKey names the UUID of the environment, as set
+	// for hooks and plugins.
+	JujuEnvUUIDEnvKey = "JUJU_ENV_UUID"
 )
 
+// The juju environment variables that are read through the registry.
+var (
+	JujuEnvVar = Register(Var{
+		Name:        JujuEnvEnvKey,
+		Description: "the environment to operate in",
+	})
+	JujuHomeVar = Register(Var{
+		Name:        JujuHomeEnvKey,
+		Description: "the juju client's home directory",
+	})
+	JujuDataVar = Register(Var{
+		Name:        JujuDataEnvKey,
+		Description: "the juju client's data directory",
+	})
+	JujuRepositoryVar = Register(Var{
+		Name:        JujuRepositoryEnvKey,
+		Description: "the local charm repository",
+	})
+	JujuLoggingConfigVar = Register(Var{
+		Name:        JujuLoggingConfigEnvKey,
+		Description: "the logging configuration",
+	})
+	JujuStartupLoggingConfigVar = Register(Var{
+		Name:        JujuStartupLoggingConfigEnvKey,
+		Description: "the logging configuration used before commands are created",
+	})
+	JujuFeatureFlagVar = Register(Var{
+		Name:        JujuFeatureFlagEnvKey,
+		Description: "the comma-separated development feature flags",
+	})
+	JujuContainerTypeVar = Register(Var{
+		Name:        JujuContainerTypeEnvKey,
+		Description: "the type of container the agent runs in",
+	})
+	JujuStatusIsoTimeVar = Register(Var{
+		Name:        JujuStatusIsoTimeEnvKey,
+		Kind:        Bool,
+		Description: "whether status timestamps are shown in RFC3339 format",
+	})
+	JujuCLIVersionVar = Register(Var{
+		Name:        JujuCLIVersion,
+		Kind:        Int,
+		Default:     "1",
+		Description: "the oldest CLI version whose args and output are kept",
+	})
+	JujuCookieFileVar = Register(Var{
+		Name:        JujuCookieFileEnvKey,
+		Description: "the file holding the client's cookies",
+	})
+	JujuDummyDelayVar = Register(Var{
+		Name:        JujuDummyDelayEnvKey,
+		Kind:        Duration,
+		Description: "the delay added to the dummy provider's operations",
+	})
+	JujuLayoutVar = Register(Var{
+		Name:        JujuLayoutEnvKey,
+		Description: "the layout of juju's files, e.g. \"centos\"",
+	})
+	JujuPrefixVar = Register(Var{
+		Name:        JujuPrefixEnvKey,
+		Description: "the directory under which juju's files are kept",
+	})
+)
+
+// hookVars holds the variables set in the environment of hooks and
+// juju-run commands; see worker/uniter/runner/context.
+var hookVars = []string{
+	"JUJU_ACTION_NAME",
+	"JUJU_ACTION_TAG",
+	"JUJU_ACTION_UUID",
+	"JUJU_AGENT_SOCKET",
+	"JUJU_API_ADDRESSES",
+	"JUJU_AVAILABILITY_ZONE",
+	"JUJU_CHARM_DIR",
+	"JUJU_CONTEXT_ID",
+	"JUJU_ENV_NAME",
+	"JUJU_ENV_UUID",
+	"JUJU_HOOK_NAME",
+	"JUJU_MACHINE_ID",
+	"JUJU_METER_INFO",
+	"JUJU_METER_STATUS",
+	"JUJU_RELATION",
+	"JUJU_RELATION_ID",
+	"JUJU_REMOTE_UNIT",
+	"JUJU_UNIT_NAME",
+}
+
+func init() {
+	for _, name := range hookVars {
+		Register(Var{
+			Name:        name,
+			Description: "set by juju for hooks",
+		})
+	}
+}
+
 // FeatureFlags returns a map that can be merged with os.Environ.
 func FeatureFlags() map[string]string {
 	result := make(map[string]string)
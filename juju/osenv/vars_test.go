@@ -28,6 +28,7 @@ func (s *varsSuite) TestJujuHomeEnvVar(c *gc.C) {
 
 func (s *varsSuite) TestBlankJujuHomeEnvVar(c *gc.C) {
 	s.PatchEnvironment(osenv.JujuHomeEnvKey, "")
+	s.PatchEnvironment(osenv.XDGDataHomeEnvKey, "")
 
 	if runtime.GOOS == "windows" {
 		s.PatchEnvironment("APPDATA", `P:\foobar`)
@@ -39,10 +40,16 @@ func (s *varsSuite) TestBlankJujuHomeEnvVar(c *gc.C) {
 	if runtime.GOOS == "windows" {
 		c.Assert(osenv.JujuHomeDir(), gc.Equals, osenv.JujuHomeWin())
 	} else {
-		c.Assert(osenv.JujuHomeDir(), gc.Equals, osenv.JujuHomeLinux())
+		c.Assert(osenv.JujuHomeDir(), gc.Equals, osenv.JujuXDGDataHome())
 	}
 }
 
+func (s *varsSuite) TestJujuDataEnvVar(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuHomeEnvKey, "/foo/bar/baz")
+	s.PatchEnvironment(osenv.JujuDataEnvKey, "/data")
+	c.Assert(osenv.JujuHomeDir(), gc.Equals, "/data")
+}
+
 func (s *varsSuite) TestMergeEnvironment(c *gc.C) {
 	c.Check(osenv.MergeEnvironment(nil, nil), gc.HasLen, 0)
 	newValues := map[string]string{"a": "baz", "c": "omg"}
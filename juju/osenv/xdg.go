@@ -0,0 +1,242 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+)
+
+// The environment variables defined by the XDG base directory
+// specification, see
+// http://standards.freedesktop.org/basedir-spec/basedir-spec-latest.html
+const (
+	XDGDataHomeEnvKey   = "XDG_DATA_HOME"
+	XDGConfigHomeEnvKey = "XDG_CONFIG_HOME"
+	XDGCacheHomeEnvKey  = "XDG_CACHE_HOME"
+)
+
+// rename and removeAll are patched out during tests.
+var (
+	rename    = os.Rename
+	removeAll = os.RemoveAll
+)
+
+// legacyMigratedMarker is the file written to the XDG data home once
+// the contents of a legacy ~/.juju have been migrated to it.
+const legacyMigratedMarker = ".migrated-from-legacy-home"
+
+// jujuDataOverride returns the directory named by JUJU_DATA or, for
+// compatibility, JUJU_HOME. When either is set the client keeps all
+// of its files there, as it always has with JUJU_HOME.
+func jujuDataOverride() string {
+	if dir := os.Getenv(JujuDataEnvKey); dir != "" {
+		return dir
+	}
+	return os.Getenv(JujuHomeEnvKey)
+}
+
+// JujuXDGDataHome returns the directory where the juju client keeps
+// its data: $JUJU_DATA or $JUJU_HOME if set, otherwise juju under the
+// XDG data home (~/.local/share/juju by default). On Windows it is
+// the same as JujuHomeDir.
+func JujuXDGDataHome() string {
+	if dir := jujuDataOverride(); dir != "" {
+		return dir
+	}
+	if goos == "windows" {
+		return jujuHomeWin()
+	}
+	return xdgDir(XDGDataHomeEnvKey, ".local", "share")
+}
+
+// JujuXDGConfigHome returns the directory where the juju client keeps
+// its configuration: $JUJU_DATA or $JUJU_HOME if set, otherwise juju
+// under the XDG config home (~/.config/juju by default). On Windows
+// it is the same as JujuHomeDir.
+func JujuXDGConfigHome() string {
+	if dir := jujuDataOverride(); dir != "" {
+		return dir
+	}
+	if goos == "windows" {
+		return jujuHomeWin()
+	}
+	return xdgDir(XDGConfigHomeEnvKey, ".config")
+}
+
+// JujuXDGCacheHome returns the directory where the juju client keeps
+// files that may be removed at any time: the cache directory under
+// $JUJU_DATA or $JUJU_HOME if set, otherwise juju under the XDG cache
+// home (~/.cache/juju by default). On Windows it is under the user's
+// local (non-roaming) application data.
+func JujuXDGCacheHome() string {
+	if dir := jujuDataOverride(); dir != "" {
+		return filepath.Join(dir, "cache")
+	}
+	if goos == "windows" {
+		if dir := localAppData(); dir != "" {
+			return filepath.Join(dir, "Juju", "cache")
+		}
+		return ""
+	}
+	return xdgDir(XDGCacheHomeEnvKey, ".cache")
+}
+
+// xdgDir returns the juju directory under the base directory named by
+// the environment variable or, if that is unset, under the default
+// location in the user's home. As the specification requires,
+// relative paths in the variable are ignored.
+func xdgDir(key string, defaultPath ...string) string {
+	if base := os.Getenv(key); filepath.IsAbs(base) {
+		return filepath.Join(base, "juju")
+	}
+	base := underHome(defaultPath...)
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, "juju")
+}
+
+// MigrateLegacyJujuHome moves the contents of a legacy ~/.juju
+// directory to JujuXDGDataHome, and reports whether it did so. It is
+// only run when the user asks for it, as it moves the root directories
+// of any local provider environments too. Nothing is done if JUJU_DATA
+// or JUJU_HOME is set, on Windows, if there is no legacy directory, or
+// if the data home already has contents.
+//
+// Once the contents are in place a marker is written to the data home,
+// after which JujuHomeDir no longer uses the legacy directory, even if
+// it could only be partly removed. Calling MigrateLegacyJujuHome again
+// then finishes removing it.
+func MigrateLegacyJujuHome() (bool, error) {
+	if jujuDataOverride() != "" || goos == "windows" {
+		return false, nil
+	}
+	legacy := jujuHomeLinux()
+	dest := JujuXDGDataHome()
+	if legacy == "" || dest == "" {
+		return false, nil
+	}
+	if info, err := os.Lstat(legacy); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	} else if !info.IsDir() {
+		return false, nil
+	}
+	if legacyHomeMigrated(dest) {
+		// An earlier migration could not remove all of the legacy
+		// directory.
+		return true, removeLegacyHome(legacy)
+	}
+
+	switch names, err := readDirNames(dest); {
+	case os.IsNotExist(err):
+	case err != nil:
+		return false, errors.Trace(err)
+	case len(names) > 0:
+		return false, nil
+	default:
+		if err := os.Remove(dest); err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return false, errors.Annotatef(err, "cannot migrate %s", legacy)
+	}
+	if err := rename(legacy, dest); err == nil {
+		return true, errors.Trace(writeLegacyMigratedMarker(dest))
+	}
+	// The rename fails if the data home is on another filesystem, in
+	// which case the files are copied instead. The legacy directory
+	// is only removed once the copy is complete and marked so.
+	if err := copyTree(dest, legacy); err != nil {
+		os.RemoveAll(dest)
+		return false, errors.Annotatef(err, "cannot migrate %s", legacy)
+	}
+	if err := writeLegacyMigratedMarker(dest); err != nil {
+		os.RemoveAll(dest)
+		return false, errors.Annotatef(err, "cannot migrate %s", legacy)
+	}
+	return true, removeLegacyHome(legacy)
+}
+
+// legacyHomeMigrated reports whether the contents of the legacy juju
+// home have been migrated to the data home.
+func legacyHomeMigrated(dataHome string) bool {
+	_, err := os.Stat(filepath.Join(dataHome, legacyMigratedMarker))
+	return err == nil
+}
+
+func writeLegacyMigratedMarker(dataHome string) error {
+	path := filepath.Join(dataHome, legacyMigratedMarker)
+	return errors.Trace(ioutil.WriteFile(path, nil, 0600))
+}
+
+func removeLegacyHome(legacy string) error {
+	if err := removeAll(legacy); err != nil {
+		return errors.Annotatef(err, "cannot remove %s after migration", legacy)
+	}
+	return nil
+}
+
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
+
+// copyTree copies the directory tree at src to dest, which must not
+// exist, preserving permissions and symlinks.
+func copyTree(dest, src string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			return os.Mkdir(target, mode.Perm())
+		case mode&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case mode.IsRegular():
+			return copyFile(target, path, mode.Perm())
+		default:
+			return errors.NotSupportedf("copying %s (mode %v)", path, mode)
+		}
+	})
+}
+
+func copyFile(dest, src string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,217 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type xdgSuite struct {
+	testing.BaseSuite
+	home string
+}
+
+var _ = gc.Suite(&xdgSuite{})
+
+func (s *xdgSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.home = c.MkDir()
+	for _, key := range []string{
+		osenv.JujuDataEnvKey,
+		osenv.JujuHomeEnvKey,
+		osenv.XDGDataHomeEnvKey,
+		osenv.XDGConfigHomeEnvKey,
+		osenv.XDGCacheHomeEnvKey,
+		"LOCALAPPDATA",
+	} {
+		s.PatchEnvironment(key, "")
+	}
+	s.PatchEnvironment("HOME", s.home)
+	osenv.PatchGOOS(s, "linux")
+}
+
+func (s *xdgSuite) TestDefaults(c *gc.C) {
+	c.Check(osenv.JujuXDGDataHome(), gc.Equals, filepath.Join(s.home, ".local", "share", "juju"))
+	c.Check(osenv.JujuXDGConfigHome(), gc.Equals, filepath.Join(s.home, ".config", "juju"))
+	c.Check(osenv.JujuXDGCacheHome(), gc.Equals, filepath.Join(s.home, ".cache", "juju"))
+}
+
+func (s *xdgSuite) TestJujuHomeDirUsesDataHome(c *gc.C) {
+	c.Check(osenv.JujuHomeDir(), gc.Equals, filepath.Join(s.home, ".local", "share", "juju"))
+}
+
+func (s *xdgSuite) TestJujuHomeDirFallsBackToLegacy(c *gc.C) {
+	legacy := filepath.Join(s.home, ".juju")
+	err := os.Mkdir(legacy, 0700)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(osenv.JujuHomeDir(), gc.Equals, legacy)
+
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(migrated, jc.IsTrue)
+	c.Check(osenv.JujuHomeDir(), gc.Equals, filepath.Join(s.home, ".local", "share", "juju"))
+}
+
+func (s *xdgSuite) TestXDGVariables(c *gc.C) {
+	s.PatchEnvironment(osenv.XDGDataHomeEnvKey, "/data")
+	s.PatchEnvironment(osenv.XDGConfigHomeEnvKey, "/config")
+	s.PatchEnvironment(osenv.XDGCacheHomeEnvKey, "/cache")
+	c.Check(osenv.JujuXDGDataHome(), gc.Equals, "/data/juju")
+	c.Check(osenv.JujuXDGConfigHome(), gc.Equals, "/config/juju")
+	c.Check(osenv.JujuXDGCacheHome(), gc.Equals, "/cache/juju")
+}
+
+func (s *xdgSuite) TestRelativeXDGVariablesIgnored(c *gc.C) {
+	s.PatchEnvironment(osenv.XDGDataHomeEnvKey, "data")
+	c.Check(osenv.JujuXDGDataHome(), gc.Equals, filepath.Join(s.home, ".local", "share", "juju"))
+}
+
+func (s *xdgSuite) TestOverrides(c *gc.C) {
+	s.PatchEnvironment(osenv.XDGDataHomeEnvKey, "/data")
+	s.PatchEnvironment(osenv.JujuHomeEnvKey, "/juju-home")
+	c.Check(osenv.JujuXDGDataHome(), gc.Equals, "/juju-home")
+	c.Check(osenv.JujuXDGConfigHome(), gc.Equals, "/juju-home")
+	c.Check(osenv.JujuXDGCacheHome(), gc.Equals, "/juju-home/cache")
+
+	s.PatchEnvironment(osenv.JujuDataEnvKey, "/juju-data")
+	c.Check(osenv.JujuXDGDataHome(), gc.Equals, "/juju-data")
+	c.Check(osenv.JujuXDGConfigHome(), gc.Equals, "/juju-data")
+	c.Check(osenv.JujuXDGCacheHome(), gc.Equals, "/juju-data/cache")
+}
+
+func (s *xdgSuite) TestWindows(c *gc.C) {
+	osenv.PatchGOOS(s, "windows")
+	s.PatchEnvironment("APPDATA", "roaming")
+	s.PatchEnvironment("LOCALAPPDATA", "local")
+	c.Check(osenv.JujuXDGDataHome(), gc.Equals, filepath.Join("roaming", "Juju"))
+	c.Check(osenv.JujuXDGConfigHome(), gc.Equals, filepath.Join("roaming", "Juju"))
+	c.Check(osenv.JujuXDGCacheHome(), gc.Equals, filepath.Join("local", "Juju", "cache"))
+}
+
+func (s *xdgSuite) makeLegacyHome(c *gc.C) string {
+	legacy := filepath.Join(s.home, ".juju")
+	err := os.MkdirAll(filepath.Join(legacy, "environments"), 0700)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(legacy, "environments.yaml"), []byte("default: foo\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(legacy, "environments", "foo.jenv"), []byte("jenv"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.Symlink("environments/foo.jenv", filepath.Join(legacy, "current.jenv"))
+	c.Assert(err, jc.ErrorIsNil)
+	return legacy
+}
+
+func (s *xdgSuite) checkMigrated(c *gc.C, legacy string) {
+	_, err := os.Stat(legacy)
+	c.Check(os.IsNotExist(err), jc.IsTrue)
+
+	dataHome := osenv.JujuXDGDataHome()
+	data, err := ioutil.ReadFile(filepath.Join(dataHome, "environments.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "default: foo\n")
+	info, err := os.Stat(filepath.Join(dataHome, "environments", "foo.jenv"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0600))
+	link, err := os.Readlink(filepath.Join(dataHome, "current.jenv"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(link, gc.Equals, "environments/foo.jenv")
+	_, err = os.Stat(filepath.Join(dataHome, ".migrated-from-legacy-home"))
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *xdgSuite) TestMigrateLegacyJujuHome(c *gc.C) {
+	legacy := s.makeLegacyHome(c)
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsTrue)
+	s.checkMigrated(c, legacy)
+
+	migrated, err = osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsFalse)
+}
+
+func (s *xdgSuite) TestMigrateLegacyJujuHomeCopies(c *gc.C) {
+	osenv.PatchRename(s, func(string, string) error {
+		return errors.New("cross-device link")
+	})
+	legacy := s.makeLegacyHome(c)
+	err := os.MkdirAll(osenv.JujuXDGDataHome(), 0700)
+	c.Assert(err, jc.ErrorIsNil)
+
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsTrue)
+	s.checkMigrated(c, legacy)
+}
+
+func (s *xdgSuite) TestMigrateLegacyJujuHomeNoLegacy(c *gc.C) {
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsFalse)
+	_, err = os.Stat(osenv.JujuXDGDataHome())
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+}
+
+func (s *xdgSuite) TestMigrateLegacyJujuHomeDataHomeInUse(c *gc.C) {
+	legacy := s.makeLegacyHome(c)
+	dataHome := osenv.JujuXDGDataHome()
+	err := os.MkdirAll(dataHome, 0700)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(dataHome, "controllers.yaml"), nil, 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsFalse)
+	_, err = os.Stat(filepath.Join(legacy, "environments.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *xdgSuite) TestMigrateLegacyJujuHomeOverridden(c *gc.C) {
+	legacy := s.makeLegacyHome(c)
+	s.PatchEnvironment(osenv.JujuHomeEnvKey, legacy)
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsFalse)
+	_, err = os.Stat(filepath.Join(legacy, "environments.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *xdgSuite) TestMigrateLegacyJujuHomePartlyRemoved(c *gc.C) {
+	osenv.PatchRename(s, func(string, string) error {
+		return errors.New("cross-device link")
+	})
+	osenv.PatchRemoveAll(s, func(path string) error {
+		os.Remove(filepath.Join(path, "environments.yaml"))
+		return errors.New("permission denied")
+	})
+	legacy := s.makeLegacyHome(c)
+
+	migrated, err := osenv.MigrateLegacyJujuHome()
+	c.Assert(err, gc.ErrorMatches, "cannot remove .* after migration: permission denied")
+	c.Assert(migrated, jc.IsTrue)
+
+	// The partly removed legacy directory is never used again.
+	_, err = os.Stat(legacy)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(osenv.JujuHomeDir(), gc.Equals, osenv.JujuXDGDataHome())
+
+	// Migrating again finishes removing it.
+	osenv.PatchRemoveAll(s, os.RemoveAll)
+	migrated, err = osenv.MigrateLegacyJujuHome()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migrated, jc.IsTrue)
+	s.checkMigrated(c, legacy)
+}
@@ -5,3 +5,7 @@ package paths
 
 var OsStat = &osStat
 var ExecLookPath = &execLookPath
+var (
+	GOOS          = &goos
+	OSReleaseFile = &osReleaseFile
+)
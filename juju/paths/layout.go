@@ -0,0 +1,311 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package paths
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	jujuos "github.com/juju/utils/os"
+	"github.com/juju/utils/series"
+
+	"github.com/juju/juju/juju/osenv"
+)
+
+// Layout describes where juju keeps its files on a machine.
+type Layout struct {
+	// Name identifies the layout, and is used to select it with
+	// LayoutParams, or on the local machine with the JUJU_LAYOUT
+	// environment variable.
+	Name string
+
+	TempDir         string
+	LogDir          string
+	DataDir         string
+	StorageDir      string
+	ConfDir         string
+	JujuRun         string
+	CertDir         string
+	MetricsSpoolDir string
+	UniterStateDir  string
+}
+
+// The names of the standard layouts.
+const (
+	LinuxLayout    = "linux"
+	CentOSLayout   = "centos"
+	OpenSUSELayout = "opensuse"
+	WindowsLayout  = "windows"
+)
+
+// linuxLayout follows the Filesystem Hierarchy Standard, and is used
+// on Ubuntu and most other Linux distributions.
+var linuxLayout = Layout{
+	Name:            LinuxLayout,
+	TempDir:         nixVals[tmpDir],
+	LogDir:          nixVals[logDir],
+	DataDir:         nixVals[dataDir],
+	StorageDir:      nixVals[storageDir],
+	ConfDir:         nixVals[confDir],
+	JujuRun:         nixVals[jujuRun],
+	CertDir:         nixVals[certDir],
+	MetricsSpoolDir: nixVals[metricsSpoolDir],
+	UniterStateDir:  nixVals[uniterStateDir],
+}
+
+var windowsLayout = Layout{
+	Name:            WindowsLayout,
+	TempDir:         winVals[tmpDir],
+	LogDir:          winVals[logDir],
+	DataDir:         winVals[dataDir],
+	StorageDir:      winVals[storageDir],
+	ConfDir:         winVals[confDir],
+	JujuRun:         winVals[jujuRun],
+	CertDir:         winVals[certDir],
+	MetricsSpoolDir: winVals[metricsSpoolDir],
+	UniterStateDir:  winVals[uniterStateDir],
+}
+
+// layouts holds the known layouts, by name.
+var (
+	layoutsMu sync.Mutex
+	layouts   = map[string]Layout{
+		LinuxLayout:    linuxLayout,
+		CentOSLayout:   renamed(linuxLayout, CentOSLayout),
+		OpenSUSELayout: renamed(linuxLayout, OpenSUSELayout),
+		WindowsLayout:  windowsLayout,
+	}
+)
+
+func renamed(l Layout, name string) Layout {
+	l.Name = name
+	return l
+}
+
+// RegisterLayout adds a layout, or replaces the one with the same
+// name, so that it can be selected by name.
+func RegisterLayout(l Layout) error {
+	if l.Name == "" {
+		return errors.NotValidf("layout without a name")
+	}
+	layoutsMu.Lock()
+	defer layoutsMu.Unlock()
+	layouts[l.Name] = l
+	return nil
+}
+
+// LookupLayout returns the named layout.
+func LookupLayout(name string) (Layout, error) {
+	layoutsMu.Lock()
+	defer layoutsMu.Unlock()
+	l, ok := layouts[name]
+	if !ok {
+		return Layout{}, errors.NotFoundf("layout %q", name)
+	}
+	return l, nil
+}
+
+// LayoutNames returns the names of the known layouts, sorted.
+func LayoutNames() []string {
+	layoutsMu.Lock()
+	defer layoutsMu.Unlock()
+	var names []string
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrefixLayout returns a copy of the layout with all of its paths
+// moved under prefix, e.g. "/var/lib/juju" becomes
+// "/opt/juju/var/lib/juju" for prefix "/opt/juju".
+func PrefixLayout(l Layout, name, prefix string) Layout {
+	join := func(p string) string {
+		if p == "" {
+			return ""
+		}
+		if l.Name == WindowsLayout {
+			// Windows paths start with a drive, e.g. "C:/Juju/log".
+			if i := strings.Index(p, ":"); i >= 0 {
+				p = p[i+1:]
+			}
+		}
+		return path.Join(prefix, p)
+	}
+	return Layout{
+		Name:            name,
+		TempDir:         join(l.TempDir),
+		LogDir:          join(l.LogDir),
+		DataDir:         join(l.DataDir),
+		StorageDir:      join(l.StorageDir),
+		ConfDir:         join(l.ConfDir),
+		JujuRun:         join(l.JujuRun),
+		CertDir:         join(l.CertDir),
+		MetricsSpoolDir: join(l.MetricsSpoolDir),
+		UniterStateDir:  join(l.UniterStateDir),
+	}
+}
+
+// LayoutParams chooses the layout of juju's files on a machine.
+type LayoutParams struct {
+	// Series is the machine's series. It chooses the layout if Name
+	// is empty.
+	Series string
+
+	// Name, if set, names the layout to use instead of the one
+	// chosen for the series.
+	Name string
+
+	// Prefix, if set, is the absolute path of a directory under
+	// which the layout is moved.
+	Prefix string
+}
+
+// LayoutFor returns the layout chosen by the parameters.
+func LayoutFor(params LayoutParams) (Layout, error) {
+	var l Layout
+	var err error
+	if params.Name != "" {
+		l, err = LookupLayout(params.Name)
+	} else {
+		l, err = layoutForSeries(params.Series)
+	}
+	if err != nil {
+		return Layout{}, err
+	}
+	return relocate(l, params.Prefix)
+}
+
+// relocate moves the layout under prefix, if it is set.
+func relocate(l Layout, prefix string) (Layout, error) {
+	if prefix == "" {
+		return l, nil
+	}
+	if !path.IsAbs(prefix) && !filepath.IsAbs(prefix) {
+		return Layout{}, errors.NotValidf("prefix %q (not an absolute path)", prefix)
+	}
+	return PrefixLayout(l, l.Name, filepath.ToSlash(prefix)), nil
+}
+
+// LayoutForSeries returns the layout used by machines running the
+// provided series. It does not depend on the local environment, as
+// the machine is usually not this one; use LayoutFor to choose
+// another layout.
+func LayoutForSeries(ser string) (Layout, error) {
+	return LayoutFor(LayoutParams{Series: ser})
+}
+
+func layoutForSeries(ser string) (Layout, error) {
+	hostOS, err := series.GetOSFromSeries(ser)
+	if err != nil {
+		return Layout{}, err
+	}
+	switch hostOS {
+	case jujuos.Windows:
+		return LookupLayout(WindowsLayout)
+	case jujuos.CentOS:
+		return LookupLayout(CentOSLayout)
+	default:
+		return LookupLayout(LinuxLayout)
+	}
+}
+
+// These are patched out during tests.
+var (
+	goos          = runtime.GOOS
+	osReleaseFile = "/etc/os-release"
+)
+
+// HostLayout returns the layout used on the local machine: the one
+// named by JUJU_LAYOUT if it is set, otherwise one chosen by the OS,
+// and on Linux the distribution named in /etc/os-release. If
+// JUJU_PREFIX is set, the layout is moved under that directory. The
+// agents and the services they install on this machine find their
+// files through it; see HostDataDir and friends.
+func HostLayout() (Layout, error) {
+	name := osenv.JujuLayoutVar.Value()
+	if name == "" {
+		if goos == "windows" {
+			name = WindowsLayout
+		} else {
+			name = distroLayout(osReleaseFile)
+		}
+	}
+	l, err := LookupLayout(name)
+	if err != nil {
+		return Layout{}, errors.Annotatef(err, "invalid %s", osenv.JujuLayoutEnvKey)
+	}
+	l, err = relocate(l, osenv.JujuPrefixVar.Value())
+	if err != nil {
+		return Layout{}, errors.Annotatef(err, "invalid %s", osenv.JujuPrefixEnvKey)
+	}
+	return l, nil
+}
+
+// distroLayout returns the name of the layout used by the Linux
+// distribution described in the os-release file.
+func distroLayout(filename string) string {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		logger.Debugf("cannot read %s, using the %s layout: %v", filename, LinuxLayout, err)
+		return LinuxLayout
+	}
+	var ids []string
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.Trim(parts[1], "\t '\"")
+		switch parts[0] {
+		case "ID":
+			ids = append([]string{value}, ids...)
+		case "ID_LIKE":
+			ids = append(ids, strings.Fields(value)...)
+		}
+	}
+	for _, id := range ids {
+		switch {
+		case id == "ubuntu", id == "debian":
+			return LinuxLayout
+		case id == "centos", id == "rhel", id == "fedora":
+			return CentOSLayout
+		case strings.HasPrefix(id, "opensuse"), id == "suse", id == "sles":
+			return OpenSUSELayout
+		}
+	}
+	return LinuxLayout
+}
+
+// value returns the layout's value for the variable.
+func (l Layout) value(v osVarType) string {
+	switch v {
+	case tmpDir:
+		return l.TempDir
+	case logDir:
+		return l.LogDir
+	case dataDir:
+		return l.DataDir
+	case storageDir:
+		return l.StorageDir
+	case confDir:
+		return l.ConfDir
+	case jujuRun:
+		return l.JujuRun
+	case certDir:
+		return l.CertDir
+	case metricsSpoolDir:
+		return l.MetricsSpoolDir
+	case uniterStateDir:
+		return l.UniterStateDir
+	}
+	return ""
+}
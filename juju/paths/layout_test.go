@@ -0,0 +1,209 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package paths_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/juju/paths"
+	"github.com/juju/juju/testing"
+)
+
+type layoutSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&layoutSuite{})
+
+func (s *layoutSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "")
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "")
+	s.PatchValue(paths.GOOS, "linux")
+}
+
+func (s *layoutSuite) TestLayoutNames(c *gc.C) {
+	c.Assert(paths.LayoutNames(), jc.DeepEquals, []string{"centos", "linux", "opensuse", "windows"})
+}
+
+func (s *layoutSuite) TestLayoutForSeries(c *gc.C) {
+	for i, test := range []struct {
+		series string
+		layout string
+	}{
+		{"trusty", paths.LinuxLayout},
+		{"centos7", paths.CentOSLayout},
+		{"win2012r2", paths.WindowsLayout},
+	} {
+		c.Logf("test %d: %s", i, test.series)
+		layout, err := paths.LayoutForSeries(test.series)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(layout.Name, gc.Equals, test.layout)
+	}
+}
+
+func (s *layoutSuite) TestLayoutForSeriesUnknown(c *gc.C) {
+	_, err := paths.LayoutForSeries("no-such-series")
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *layoutSuite) TestDirs(c *gc.C) {
+	dataDir, err := paths.DataDir("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dataDir, gc.Equals, "/var/lib/juju")
+	logDir, err := paths.LogDir("win2012r2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(logDir, gc.Equals, "C:/Juju/log")
+}
+
+func (s *layoutSuite) TestLayoutForName(c *gc.C) {
+	layout, err := paths.LayoutFor(paths.LayoutParams{Series: "trusty", Name: paths.WindowsLayout})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.Name, gc.Equals, paths.WindowsLayout)
+}
+
+func (s *layoutSuite) TestLayoutForNameUnknown(c *gc.C) {
+	_, err := paths.LayoutFor(paths.LayoutParams{Name: "nope"})
+	c.Assert(err, gc.ErrorMatches, `layout "nope" not found`)
+}
+
+func (s *layoutSuite) TestLayoutForSeriesIgnoresEnvironment(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "windows")
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "/sandbox")
+	layout, err := paths.LayoutForSeries("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.Name, gc.Equals, paths.LinuxLayout)
+	c.Check(layout.DataDir, gc.Equals, "/var/lib/juju")
+	dataDir, err := paths.DataDir("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dataDir, gc.Equals, "/var/lib/juju")
+}
+
+func (s *layoutSuite) TestConfiguredLayout(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "windows")
+	layout, err := paths.HostLayout()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.Name, gc.Equals, paths.WindowsLayout)
+}
+
+func (s *layoutSuite) TestConfiguredLayoutUnknown(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "nope")
+	_, err := paths.HostLayout()
+	c.Assert(err, gc.ErrorMatches, `invalid JUJU_LAYOUT: layout "nope" not found`)
+}
+
+func (s *layoutSuite) TestHostDirs(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "linux")
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "/sandbox")
+	dataDir, err := paths.HostDataDir()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dataDir, gc.Equals, "/sandbox/var/lib/juju")
+	jujuRun, err := paths.HostJujuRun()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(jujuRun, gc.Equals, "/sandbox/usr/bin/juju-run")
+}
+
+func (s *layoutSuite) TestRegisterPrefixLayout(c *gc.C) {
+	linux, err := paths.LookupLayout(paths.LinuxLayout)
+	c.Assert(err, jc.ErrorIsNil)
+	err = paths.RegisterLayout(paths.PrefixLayout(linux, "test-prefix", "/opt/juju"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	layout, err := paths.LayoutFor(paths.LayoutParams{Name: "test-prefix"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.DataDir, gc.Equals, "/opt/juju/var/lib/juju")
+	c.Check(layout.JujuRun, gc.Equals, "/opt/juju/usr/bin/juju-run")
+}
+
+func (s *layoutSuite) TestPrefixLayoutWindows(c *gc.C) {
+	windows, err := paths.LookupLayout(paths.WindowsLayout)
+	c.Assert(err, jc.ErrorIsNil)
+	layout := paths.PrefixLayout(windows, "custom", "D:/Juju")
+	c.Check(layout.Name, gc.Equals, "custom")
+	c.Check(layout.LogDir, gc.Equals, "D:/Juju/Juju/log")
+}
+
+func (s *layoutSuite) TestRegisterLayoutNoName(c *gc.C) {
+	err := paths.RegisterLayout(paths.Layout{})
+	c.Assert(err, gc.ErrorMatches, "layout without a name not valid")
+}
+
+func (s *layoutSuite) TestHostLayout(c *gc.C) {
+	for i, test := range []struct {
+		osRelease string
+		layout    string
+	}{
+		{"NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\n", paths.LinuxLayout},
+		{"NAME=\"CentOS Linux\"\nID=\"centos\"\nID_LIKE=\"rhel fedora\"\n", paths.CentOSLayout},
+		{"NAME=\"openSUSE Leap\"\nID=opensuse\n", paths.OpenSUSELayout},
+		{"ID=sles\n", paths.OpenSUSELayout},
+		{"ID=\"scientific\"\nID_LIKE=\"rhel\"\n", paths.CentOSLayout},
+		{"ID=arch\n", paths.LinuxLayout},
+	} {
+		c.Logf("test %d", i)
+		filename := filepath.Join(c.MkDir(), "os-release")
+		err := ioutil.WriteFile(filename, []byte(test.osRelease), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+		s.PatchValue(paths.OSReleaseFile, filename)
+
+		layout, err := paths.HostLayout()
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(layout.Name, gc.Equals, test.layout)
+	}
+}
+
+func (s *layoutSuite) TestHostLayoutNoOSRelease(c *gc.C) {
+	s.PatchValue(paths.OSReleaseFile, filepath.Join(c.MkDir(), "missing"))
+	layout, err := paths.HostLayout()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.Name, gc.Equals, paths.LinuxLayout)
+}
+
+func (s *layoutSuite) TestHostLayoutWindows(c *gc.C) {
+	s.PatchValue(paths.GOOS, "windows")
+	layout, err := paths.HostLayout()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.Name, gc.Equals, paths.WindowsLayout)
+}
+
+func (s *layoutSuite) TestPrefix(c *gc.C) {
+	layout, err := paths.LayoutFor(paths.LayoutParams{Series: "trusty", Prefix: "/home/me/juju"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.DataDir, gc.Equals, "/home/me/juju/var/lib/juju")
+	layout, err = paths.LayoutFor(paths.LayoutParams{Series: "centos7", Prefix: "/home/me/juju"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.LogDir, gc.Equals, "/home/me/juju/var/log")
+}
+
+func (s *layoutSuite) TestHostPrefix(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "/home/me/juju")
+	layout, err := paths.HostLayout()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.ConfDir, gc.Equals, "/home/me/juju/etc/juju")
+}
+
+func (s *layoutSuite) TestPrefixWithLayout(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "/sandbox")
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "windows")
+	layout, err := paths.HostLayout()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(layout.Name, gc.Equals, paths.WindowsLayout)
+	c.Check(layout.DataDir, gc.Equals, "/sandbox/Juju/lib/juju")
+}
+
+func (s *layoutSuite) TestPrefixRelative(c *gc.C) {
+	_, err := paths.LayoutFor(paths.LayoutParams{Series: "trusty", Prefix: "sandbox"})
+	c.Assert(err, gc.ErrorMatches, `prefix "sandbox" \(not an absolute path\) not valid`)
+}
+
+func (s *layoutSuite) TestHostPrefixRelative(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "sandbox")
+	_, err := paths.HostLayout()
+	c.Assert(err, gc.ErrorMatches, `invalid JUJU_PREFIX: prefix "sandbox" \(not an absolute path\) not valid`)
+}
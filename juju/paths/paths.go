@@ -5,10 +5,12 @@ import (
 	"os/exec"
 
 	"github.com/juju/errors"
-	jujuos "github.com/juju/utils/os"
+	"github.com/juju/loggo"
 	"github.com/juju/utils/series"
 )
 
+var logger = loggo.GetLogger("juju.juju.paths")
+
 type osVarType int
 
 const (
@@ -48,19 +50,30 @@ var winVals = map[osVarType]string{
 }
 
 // osVal will lookup the value of the key valname
-// in the apropriate map, based on the series. This will
+// in the layout used by the series. This will
 // help reduce boilerplate code
 func osVal(ser string, valname osVarType) (string, error) {
-	os, err := series.GetOSFromSeries(ser)
+	layout, err := LayoutForSeries(ser)
 	if err != nil {
 		return "", err
 	}
-	switch os {
-	case jujuos.Windows:
-		return winVals[valname], nil
-	default:
-		return nixVals[valname], nil
+	return layout.value(valname), nil
+}
+
+// hostVal looks up the value of the key valname in the local
+// machine's layout (see HostLayout). If JUJU_LAYOUT or JUJU_PREFIX is
+// invalid, the layout for the host's series is used instead, so that
+// a bad setting does not stop juju from starting.
+func hostVal(valname osVarType) (string, error) {
+	layout, err := HostLayout()
+	if err != nil {
+		logger.Warningf("using the default layout: %v", err)
+		layout, err = LayoutForSeries(series.HostSeries())
+		if err != nil {
+			return "", err
+		}
 	}
+	return layout.value(valname), nil
 }
 
 // TempDir returns the path on disk to the corect tmp directory
@@ -113,6 +126,43 @@ func JujuRun(series string) (string, error) {
 	return osVal(series, jujuRun)
 }
 
+// HostLogDir returns the directory where juju may save log files on
+// the local machine.
+func HostLogDir() (string, error) {
+	return hostVal(logDir)
+}
+
+// HostDataDir returns the folder used by juju to store tools, charms,
+// locks, etc on the local machine.
+func HostDataDir() (string, error) {
+	return hostVal(dataDir)
+}
+
+// HostMetricsSpoolDir returns the folder used by juju to store metrics
+// on the local machine.
+func HostMetricsSpoolDir() (string, error) {
+	return hostVal(metricsSpoolDir)
+}
+
+// HostCertDir returns the folder on the local machine holding the
+// certificates added by default to the Juju client api certificate
+// pool.
+func HostCertDir() (string, error) {
+	return hostVal(certDir)
+}
+
+// HostConfDir returns the directory where Juju may store
+// configuration files on the local machine.
+func HostConfDir() (string, error) {
+	return hostVal(confDir)
+}
+
+// HostJujuRun returns the absolute path to the juju-run binary on the
+// local machine.
+func HostJujuRun() (string, error) {
+	return hostVal(jujuRun)
+}
+
 func MustSucceed(s string, e error) string {
 	if e != nil {
 		panic(e)
@@ -0,0 +1,255 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+const (
+	// AuditModule is the module whose messages are written to the
+	// audit log. It is the module the audit package logs to.
+	AuditModule = "audit"
+
+	// auditWriterName is the name of the loggo writer installed by
+	// InstallAuditLog.
+	auditWriterName = "audit-log"
+)
+
+// auditInstalled records whether an audit log is installed, in which
+// case the audit module is never configured to drop its messages. It
+// is guarded by configMu.
+var auditInstalled bool
+
+// AuditRecord is a record in an audit log. Each record holds the hash
+// of the record before it, so that removing or changing any record
+// breaks the chain; see VerifyAuditLog. The hashes are keyed with a
+// secret held by the agent, so that the chain cannot be rebuilt by
+// someone who can write the log but does not know the key.
+type AuditRecord struct {
+	Record
+
+	// PrevHash is the Hash of the previous record, or empty for the
+	// first record in the log.
+	PrevHash string `json:"prev-hash"`
+
+	// Hash is the hex-encoded HMAC-SHA256 of PrevHash and the
+	// JSON-encoded Record.
+	Hash string `json:"hash"`
+}
+
+// auditHash returns the hash, keyed with key, of the record chained
+// to prevHash.
+func auditHash(key []byte, prevHash string, record Record) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	h := hmac.New(sha256.New, key)
+	io.WriteString(h, prevHash)
+	io.WriteString(h, "\n")
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// AuditLog is a loggo.Writer that writes the messages logged to the
+// audit module, one JSON-encoded AuditRecord per line, chaining each
+// record to the one before it.
+type AuditLog struct {
+	mu       sync.Mutex
+	writer   io.Writer
+	key      []byte
+	lastHash string
+}
+
+// NewAuditLog returns an AuditLog writing to w, keying its hashes with
+// key. The first record written is chained to lastHash, which should
+// be the Hash of the last record already written to w, or empty if
+// there is none.
+func NewAuditLog(w io.Writer, key []byte, lastHash string) *AuditLog {
+	return &AuditLog{
+		writer:   w,
+		key:      key,
+		lastHash: lastHash,
+	}
+}
+
+// OpenAuditLog returns an AuditLog appending to the named file,
+// creating it if necessary. An existing file is verified with key
+// first, and is not appended to if its chain is broken.
+func OpenAuditLog(filename string, key []byte) (*AuditLog, error) {
+	if len(key) == 0 {
+		return nil, errors.New("audit log key not set")
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot open audit log")
+	}
+	lastHash, err := VerifyAuditLog(file, key)
+	if err != nil {
+		file.Close()
+		return nil, errors.Annotatef(err, "cannot append to audit log %q", filename)
+	}
+	return NewAuditLog(file, key, lastHash), nil
+}
+
+// Write implements loggo.Writer. Messages logged to modules other
+// than the audit module are ignored.
+func (l *AuditLog) Write(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) {
+	if module != AuditModule && !strings.HasPrefix(module, AuditModule+".") {
+		return
+	}
+	record := Record{
+		Timestamp: timestamp.UTC(),
+		Level:     level.String(),
+		Module:    module,
+		Message:   message,
+	}
+	if filename != "" {
+		record.Location = fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.write(record); err != nil {
+		// Losing an audit record must not go unnoticed, and the
+		// logs may be where the audit record was headed.
+		fmt.Fprintf(os.Stderr, "cannot write audit record: %v\n", err)
+	}
+}
+
+func (l *AuditLog) write(record Record) error {
+	hash, err := auditHash(l.key, l.lastHash, record)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data, err := json.Marshal(AuditRecord{
+		Record:   record,
+		PrevHash: l.lastHash,
+		Hash:     hash,
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := l.writer.Write(append(data, '\n')); err != nil {
+		return errors.Trace(err)
+	}
+	l.lastHash = hash
+	return nil
+}
+
+// Close closes the underlying writer, if it is an io.Closer.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if closer, ok := l.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// VerifyAuditLog reads an audit log, checking that each record is
+// chained to the one before it with hashes keyed with key. It returns
+// the hash of the last record, or an error identifying the first line
+// at which the chain is broken. Records may be of any length.
+func VerifyAuditLog(r io.Reader, key []byte) (lastHash string, err error) {
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return lastHash, nil
+		}
+		if err != nil && err != io.EOF {
+			return "", errors.Annotate(err, "cannot read audit log")
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return "", errors.Errorf("line %d: invalid audit record: %v", lineNum, err)
+		}
+		if record.PrevHash != lastHash {
+			return "", errors.Errorf("line %d: audit record not chained to previous record", lineNum)
+		}
+		hash, err := auditHash(key, record.PrevHash, record.Record)
+		if err != nil {
+			return "", errors.Annotatef(err, "line %d", lineNum)
+		}
+		if !hmac.Equal([]byte(record.Hash), []byte(hash)) {
+			return "", errors.Errorf("line %d: audit record hash mismatch", lineNum)
+		}
+		lastHash = hash
+	}
+}
+
+// VerifyAuditLogFile verifies the chain of the named audit log with
+// key; see VerifyAuditLog.
+func VerifyAuditLogFile(filename string, key []byte) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return errors.Annotate(err, "cannot open audit log")
+	}
+	defer file.Close()
+	_, err = VerifyAuditLog(file, key)
+	return errors.Trace(err)
+}
+
+// InstallAuditLog writes the messages logged to the audit module to
+// the named file, keying its hashes with key, and replacing any audit
+// log installed before. While it is installed the audit module logs at
+// INFO or below, whatever the logging configuration.
+func InstallAuditLog(filename string, key []byte) error {
+	auditLog, err := OpenAuditLog(filename, key)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	UninstallAuditLog()
+	if err := loggo.RegisterWriter(auditWriterName, auditLog, loggo.TRACE); err != nil {
+		auditLog.Close()
+		return errors.Annotate(err, "cannot install audit log")
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	auditInstalled = true
+	keepAuditLevel()
+	return nil
+}
+
+// UninstallAuditLog stops writing the audit log.
+func UninstallAuditLog() {
+	configMu.Lock()
+	auditInstalled = false
+	configMu.Unlock()
+	writer, _, err := loggo.RemoveWriter(auditWriterName)
+	if err != nil {
+		return
+	}
+	if auditLog, ok := writer.(*AuditLog); ok {
+		auditLog.Close()
+	}
+}
+
+// keepAuditLevel ensures that audit messages are not dropped while an
+// audit log is installed. It must be called with configMu held.
+func keepAuditLevel() {
+	if !auditInstalled {
+		return
+	}
+	logger := loggo.GetLogger(AuditModule)
+	if logger.EffectiveLogLevel() > loggo.INFO {
+		logger.SetLogLevel(loggo.INFO)
+	}
+}
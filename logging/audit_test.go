@@ -0,0 +1,184 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type auditSuite struct {
+	testing.BaseSuite
+	filename string
+}
+
+var _ = gc.Suite(&auditSuite{})
+
+func (s *auditSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.filename = filepath.Join(c.MkDir(), "machine-0-audit.log")
+	loggo.ResetLoggers()
+	s.AddCleanup(func(*gc.C) {
+		logging.UninstallAuditLog()
+		loggo.ResetLoggers()
+	})
+}
+
+var (
+	auditTime = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	auditKey  = []byte("sekrit")
+)
+
+func writeAudit(l *logging.AuditLog, messages ...string) {
+	for _, message := range messages {
+		l.Write(loggo.INFO, "audit", "/src/audit.go", 42, auditTime, message)
+	}
+}
+
+func (s *auditSuite) TestChained(c *gc.C) {
+	var buf bytes.Buffer
+	l := logging.NewAuditLog(&buf, auditKey, "")
+	writeAudit(l, "user-admin: deployed mysql", "user-admin: removed mysql")
+	l.Write(loggo.INFO, "juju.worker", "", 0, auditTime, "not audited")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(lines, gc.HasLen, 2)
+	var records [2]logging.AuditRecord
+	for i, line := range lines {
+		err := json.Unmarshal([]byte(line), &records[i])
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Check(records[0].Record, jc.DeepEquals, logging.Record{
+		Timestamp: auditTime,
+		Level:     "INFO",
+		Module:    "audit",
+		Location:  "audit.go:42",
+		Message:   "user-admin: deployed mysql",
+	})
+	c.Check(records[0].PrevHash, gc.Equals, "")
+	c.Check(records[0].Hash, gc.Matches, "[0-9a-f]{64}")
+	c.Check(records[1].PrevHash, gc.Equals, records[0].Hash)
+
+	lastHash, err := logging.VerifyAuditLog(strings.NewReader(buf.String()), auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(lastHash, gc.Equals, records[1].Hash)
+}
+
+func (s *auditSuite) TestVerifyEmpty(c *gc.C) {
+	lastHash, err := logging.VerifyAuditLog(strings.NewReader(""), auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(lastHash, gc.Equals, "")
+}
+
+func (s *auditSuite) auditLog(c *gc.C, messages ...string) []string {
+	var buf bytes.Buffer
+	writeAudit(logging.NewAuditLog(&buf, auditKey, ""), messages...)
+	return strings.SplitAfter(buf.String(), "\n")
+}
+
+func (s *auditSuite) TestVerifyChangedRecord(c *gc.C) {
+	lines := s.auditLog(c, "user-admin: granted bob", "user-admin: granted eve")
+	lines[1] = strings.Replace(lines[1], "eve", "mallory", 1)
+	_, err := logging.VerifyAuditLog(strings.NewReader(strings.Join(lines, "")), auditKey)
+	c.Assert(err, gc.ErrorMatches, "line 2: audit record hash mismatch")
+}
+
+func (s *auditSuite) TestVerifyRemovedRecord(c *gc.C) {
+	lines := s.auditLog(c, "one", "two", "three")
+	_, err := logging.VerifyAuditLog(strings.NewReader(lines[0]+lines[2]), auditKey)
+	c.Assert(err, gc.ErrorMatches, "line 2: audit record not chained to previous record")
+}
+
+func (s *auditSuite) TestVerifyInvalidRecord(c *gc.C) {
+	lines := s.auditLog(c, "one")
+	_, err := logging.VerifyAuditLog(strings.NewReader(lines[0]+"rubbish\n"), auditKey)
+	c.Assert(err, gc.ErrorMatches, "line 2: invalid audit record: .*")
+}
+
+func (s *auditSuite) TestVerifyWrongKey(c *gc.C) {
+	lines := s.auditLog(c, "one")
+	_, err := logging.VerifyAuditLog(strings.NewReader(lines[0]), []byte("guess"))
+	c.Assert(err, gc.ErrorMatches, "line 1: audit record hash mismatch")
+}
+
+func (s *auditSuite) TestVerifyLongRecord(c *gc.C) {
+	long := strings.Repeat("x", 100*1024)
+	lines := s.auditLog(c, "one", long, "three")
+	_, err := logging.VerifyAuditLog(strings.NewReader(strings.Join(lines, "")), auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *auditSuite) TestVerifyUnterminatedRecord(c *gc.C) {
+	lines := s.auditLog(c, "one", "two")
+	log := lines[0] + strings.TrimSuffix(lines[1], "\n")
+	lastHash, err := logging.VerifyAuditLog(strings.NewReader(log), auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastHash, gc.Not(gc.Equals), "")
+}
+
+func (s *auditSuite) TestOpenAuditLogWithoutKey(c *gc.C) {
+	_, err := logging.OpenAuditLog(s.filename, nil)
+	c.Assert(err, gc.ErrorMatches, "audit log key not set")
+}
+
+func (s *auditSuite) TestOpenAuditLogResumesChain(c *gc.C) {
+	l, err := logging.OpenAuditLog(s.filename, auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+	writeAudit(l, "one")
+	c.Assert(l.Close(), jc.ErrorIsNil)
+
+	l, err = logging.OpenAuditLog(s.filename, auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+	writeAudit(l, "two")
+	c.Assert(l.Close(), jc.ErrorIsNil)
+
+	c.Assert(logging.VerifyAuditLogFile(s.filename, auditKey), jc.ErrorIsNil)
+	data, err := ioutil.ReadFile(s.filename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(strings.Count(string(data), "\n"), gc.Equals, 2)
+}
+
+func (s *auditSuite) TestOpenAuditLogBrokenChain(c *gc.C) {
+	lines := s.auditLog(c, "one", "two")
+	err := ioutil.WriteFile(s.filename, []byte(lines[1]), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = logging.OpenAuditLog(s.filename, auditKey)
+	c.Assert(err, gc.ErrorMatches, `cannot append to audit log ".*": line 1: audit record not chained to previous record`)
+}
+
+func (s *auditSuite) TestInstallAuditLog(c *gc.C) {
+	err := logging.InstallAuditLog(s.filename, auditKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(loggo.GetLogger("audit").EffectiveLogLevel(), gc.Equals, loggo.INFO)
+
+	// The audit level survives reconfiguration.
+	_, err = logging.ReplaceConfig("<root>=ERROR;audit=ERROR")
+	c.Assert(err, jc.ErrorIsNil)
+	loggo.GetLogger("audit").Infof("user-admin: deployed mysql")
+	loggo.GetLogger("juju").Errorf("not audited")
+
+	logging.UninstallAuditLog()
+	data, err := ioutil.ReadFile(s.filename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), jc.Contains, `"message":"user-admin: deployed mysql"`)
+	c.Check(string(data), gc.Not(jc.Contains), "not audited")
+	c.Assert(logging.VerifyAuditLogFile(s.filename, auditKey), jc.ErrorIsNil)
+
+	// Once uninstalled, the audit module is configured as usual.
+	_, err = logging.ReplaceConfig("<root>=ERROR")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(loggo.GetLogger("audit").EffectiveLogLevel(), gc.Equals, loggo.ERROR)
+}
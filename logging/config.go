@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+// configMu serialises changes to the loggers' configuration.
+var configMu sync.Mutex
+
+// ParseConfig parses a logging configuration string, such as
+// "<root>=INFO;juju.worker=DEBUG", returning the level of each
+// module named.
+func ParseConfig(config string) (map[string]loggo.Level, error) {
+	levels, err := loggo.ParseConfigurationString(config)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return levels, nil
+}
+
+// ValidateConfig returns an error if the logging configuration string
+// is not valid.
+func ValidateConfig(config string) error {
+	_, err := ParseConfig(config)
+	return errors.Trace(err)
+}
+
+// Config returns the current logging configuration.
+func Config() string {
+	return loggo.LoggerInfo()
+}
+
+// UpdateConfig sets the levels of the modules named in the logging
+// configuration, leaving the other modules' levels alone. It returns
+// the previous configuration, which can be restored with
+// ReplaceConfig. While an audit log is installed, the audit module's
+// level is kept at INFO or below.
+func UpdateConfig(config string) (previous string, err error) {
+	if err := ValidateConfig(config); err != nil {
+		return "", errors.Trace(err)
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	previous = loggo.LoggerInfo()
+	if err := loggo.ConfigureLoggers(config); err != nil {
+		return "", errors.Trace(err)
+	}
+	keepAuditLevel()
+	return previous, nil
+}
+
+// ReplaceConfig resets all the modules' levels and then configures
+// them as described by the logging configuration. It returns the
+// previous configuration. If the new configuration is invalid the
+// previous one is kept.
+func ReplaceConfig(config string) (previous string, err error) {
+	if err := ValidateConfig(config); err != nil {
+		return "", errors.Trace(err)
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	previous = loggo.LoggerInfo()
+	loggo.ResetLoggers()
+	if err := loggo.ConfigureLoggers(config); err != nil {
+		loggo.ResetLoggers()
+		loggo.ConfigureLoggers(previous)
+		return "", errors.Trace(err)
+	}
+	keepAuditLevel()
+	return previous, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type configSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&configSuite{})
+
+func (s *configSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	loggo.ResetLoggers()
+	s.AddCleanup(func(*gc.C) { loggo.ResetLoggers() })
+}
+
+func (s *configSuite) TestParseConfig(c *gc.C) {
+	levels, err := logging.ParseConfig("juju=INFO; juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(levels, jc.DeepEquals, map[string]loggo.Level{
+		"juju":        loggo.INFO,
+		"juju.worker": loggo.DEBUG,
+	})
+}
+
+func (s *configSuite) TestValidateConfig(c *gc.C) {
+	c.Assert(logging.ValidateConfig(""), jc.ErrorIsNil)
+	c.Assert(logging.ValidateConfig("juju=TRACE"), jc.ErrorIsNil)
+	c.Assert(logging.ValidateConfig("juju=LOUD"), gc.ErrorMatches, `unknown severity level "LOUD"`)
+}
+
+func (s *configSuite) TestUpdateConfig(c *gc.C) {
+	_, err := logging.UpdateConfig("juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	previous, err := logging.UpdateConfig("juju.state=TRACE")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(previous, gc.Equals, "<root>=WARNING;juju.worker=DEBUG")
+	c.Check(logging.Config(), gc.Equals, "<root>=WARNING;juju.state=TRACE;juju.worker=DEBUG")
+}
+
+func (s *configSuite) TestReplaceConfig(c *gc.C) {
+	_, err := logging.UpdateConfig("juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	previous, err := logging.ReplaceConfig("juju.state=TRACE")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(previous, gc.Equals, "<root>=WARNING;juju.worker=DEBUG")
+	c.Check(logging.Config(), gc.Equals, "<root>=WARNING;juju.state=TRACE")
+}
+
+func (s *configSuite) TestInvalidConfigKeepsPrevious(c *gc.C) {
+	_, err := logging.UpdateConfig("juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = logging.ReplaceConfig("juju.state=LOUD")
+	c.Assert(err, gc.ErrorMatches, `unknown severity level "LOUD"`)
+	_, err = logging.UpdateConfig("juju.state=LOUD")
+	c.Assert(err, gc.ErrorMatches, `unknown severity level "LOUD"`)
+	c.Check(logging.Config(), gc.Equals, "<root>=WARNING;juju.worker=DEBUG")
+}
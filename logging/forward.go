@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+// The destinations that log messages can be forwarded to.
+const (
+	SyslogForwarding   = "syslog"
+	JournaldForwarding = "journald"
+)
+
+// forwardingWriterNames holds the names of the loggo writers installed
+// by InstallForwarding, by destination.
+var forwardingWriterNames = map[string]string{
+	SyslogForwarding:   "forward-syslog",
+	JournaldForwarding: "forward-journald",
+}
+
+// InstallForwarding forwards log messages to the host's logging
+// infrastructure. The destinations are given as a comma-separated
+// list, e.g. "syslog,journald", as found in the LOG_FORWARDING agent
+// config value; messages are sent with the tag of the entity logging.
+// Any forwarding installed before is removed first.
+func InstallForwarding(destinations, tag string) error {
+	writers := make(map[string]loggo.Writer)
+	for _, dest := range strings.Split(destinations, ",") {
+		dest = strings.TrimSpace(dest)
+		var err error
+		switch dest {
+		case "":
+			continue
+		case SyslogForwarding:
+			writers[dest] = NewSyslogWriter(tag)
+		case JournaldForwarding:
+			writers[dest], err = newJournalWriter(tag)
+		default:
+			err = errors.NotValidf("log forwarding destination %q", dest)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	UninstallForwarding()
+	for dest, writer := range writers {
+		if err := loggo.RegisterWriter(forwardingWriterNames[dest], writer, loggo.TRACE); err != nil {
+			UninstallForwarding()
+			return errors.Annotatef(err, "cannot forward logs to %s", dest)
+		}
+	}
+	return nil
+}
+
+// UninstallForwarding stops forwarding log messages.
+func UninstallForwarding() {
+	for _, name := range forwardingWriterNames {
+		writer, _, err := loggo.RemoveWriter(name)
+		if err != nil {
+			continue
+		}
+		if syslog, ok := writer.(*SyslogWriter); ok {
+			syslog.Close()
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type forwardSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&forwardSuite{})
+
+func (s *forwardSuite) TearDownTest(c *gc.C) {
+	logging.UninstallForwarding()
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *forwardSuite) TestNone(c *gc.C) {
+	err := logging.InstallForwarding("", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = loggo.RemoveWriter("forward-syslog")
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *forwardSuite) TestSyslog(c *gc.C) {
+	err := logging.InstallForwarding(" syslog, ", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	writer, _, err := loggo.RemoveWriter("forward-syslog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(writer, gc.FitsTypeOf, &logging.SyslogWriter{})
+	c.Check(writer.(*logging.SyslogWriter).Tag, gc.Equals, "machine-0")
+}
+
+func (s *forwardSuite) TestInvalid(c *gc.C) {
+	err := logging.InstallForwarding("syslog,carrier-pigeon", "machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `log forwarding destination "carrier-pigeon" not valid`)
+	_, _, err = loggo.RemoveWriter("forward-syslog")
+	c.Assert(err, gc.NotNil)
+}
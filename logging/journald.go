@@ -0,0 +1,69 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+//go:build linux
+// +build linux
+
+package logging
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/coreos/go-systemd/journal"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+// These are patched out during tests.
+var (
+	journalEnabled = journal.Enabled
+	journalSend    = journal.Send
+)
+
+// journalWriter is a loggo.Writer that forwards messages to
+// systemd-journald, with juju's details in JUJU_* fields.
+type journalWriter struct {
+	tag string
+}
+
+func newJournalWriter(tag string) (loggo.Writer, error) {
+	if !journalEnabled() {
+		return nil, errors.NotSupportedf("journald forwarding without a journal")
+	}
+	return &journalWriter{tag: tag}, nil
+}
+
+// Write implements loggo.Writer. Messages that cannot be sent are
+// dropped.
+func (w *journalWriter) Write(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) {
+	vars := map[string]string{
+		"SYSLOG_IDENTIFIER": "juju",
+		"JUJU_MODULE":       module,
+		"JUJU_LEVEL":        level.String(),
+	}
+	if w.tag != "" {
+		vars["JUJU_ENTITY"] = w.tag
+	}
+	if filename != "" {
+		vars["CODE_FILE"] = filepath.Base(filename)
+		vars["CODE_LINE"] = strconv.Itoa(line)
+	}
+	journalSend(message, journalPriority(level), vars)
+}
+
+// journalPriority returns the journal priority for the level.
+func journalPriority(level loggo.Level) journal.Priority {
+	switch level {
+	case loggo.CRITICAL:
+		return journal.PriCrit
+	case loggo.ERROR:
+		return journal.PriErr
+	case loggo.WARNING:
+		return journal.PriWarning
+	case loggo.INFO:
+		return journal.PriInfo
+	}
+	return journal.PriDebug
+}
@@ -0,0 +1,16 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+//go:build !linux
+// +build !linux
+
+package logging
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+func newJournalWriter(tag string) (loggo.Writer, error) {
+	return nil, errors.NotSupportedf("journald forwarding on this OS")
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+//go:build linux
+// +build linux
+
+package logging_test
+
+import (
+	"github.com/coreos/go-systemd/journal"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type journaldSuite struct {
+	testing.BaseSuite
+	sent []sentMessage
+}
+
+type sentMessage struct {
+	message  string
+	priority journal.Priority
+	vars     map[string]string
+}
+
+var _ = gc.Suite(&journaldSuite{})
+
+func (s *journaldSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.sent = nil
+	s.PatchValue(logging.JournalEnabled, func() bool { return true })
+	s.PatchValue(logging.JournalSend, func(message string, priority journal.Priority, vars map[string]string) error {
+		s.sent = append(s.sent, sentMessage{message, priority, vars})
+		return nil
+	})
+	s.AddCleanup(func(*gc.C) { logging.UninstallForwarding() })
+}
+
+func (s *journaldSuite) TestForward(c *gc.C) {
+	err := logging.InstallForwarding("journald", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	logger := loggo.GetLogger("test.journald")
+	logger.SetLogLevel(loggo.DEBUG)
+	logger.Errorf("it broke")
+
+	c.Assert(s.sent, gc.HasLen, 1)
+	sent := s.sent[0]
+	c.Check(sent.message, gc.Equals, "it broke")
+	c.Check(sent.priority, gc.Equals, journal.PriErr)
+	c.Check(sent.vars["CODE_LINE"], gc.Not(gc.Equals), "")
+	delete(sent.vars, "CODE_LINE")
+	c.Check(sent.vars, jc.DeepEquals, map[string]string{
+		"SYSLOG_IDENTIFIER": "juju",
+		"JUJU_MODULE":       "test.journald",
+		"JUJU_LEVEL":        "ERROR",
+		"JUJU_ENTITY":       "machine-0",
+		"CODE_FILE":         "journald_test.go",
+	})
+}
+
+func (s *journaldSuite) TestNoJournal(c *gc.C) {
+	s.PatchValue(logging.JournalEnabled, func() bool { return false })
+	err := logging.InstallForwarding("syslog,journald", "machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+
+	// Nothing was installed.
+	loggo.GetLogger("test.journald").Errorf("it broke")
+	c.Assert(s.sent, gc.HasLen, 0)
+	_, _, err = loggo.RemoveWriter("forward-syslog")
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *journaldSuite) TestReinstall(c *gc.C) {
+	err := logging.InstallForwarding("journald", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = logging.InstallForwarding("journald", "machine-1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	loggo.GetLogger("test.journald").Errorf("it broke")
+	c.Assert(s.sent, gc.HasLen, 1)
+	c.Check(s.sent[0].vars["JUJU_ENTITY"], gc.Equals, "machine-1")
+
+	logging.UninstallForwarding()
+	loggo.GetLogger("test.journald").Errorf("it broke")
+	c.Assert(s.sent, gc.HasLen, 1)
+}
@@ -0,0 +1,45 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+// Record is the form of a log message written by JSONFormatter.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Module    string    `json:"module"`
+	Location  string    `json:"location,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// JSONFormatter is a loggo.Formatter that writes each message as a
+// JSON-encoded Record, for consumption by log aggregation systems.
+type JSONFormatter struct{}
+
+// Format implements loggo.Formatter.
+func (*JSONFormatter) Format(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) string {
+	record := Record{
+		Timestamp: timestamp.UTC(),
+		Level:     level.String(),
+		Module:    module,
+		Message:   message,
+	}
+	if filename != "" {
+		record.Location = fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		// A Record always marshals, but never lose the message.
+		return fmt.Sprintf("%q", message)
+	}
+	return string(data)
+}
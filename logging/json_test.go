@@ -0,0 +1,31 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+)
+
+type jsonSuite struct{}
+
+var _ = gc.Suite(&jsonSuite{})
+
+func (*jsonSuite) TestFormat(c *gc.C) {
+	ts := time.Date(2015, 6, 1, 12, 30, 0, 0, time.FixedZone("X", 3600))
+	formatter := &logging.JSONFormatter{}
+	line := formatter.Format(loggo.ERROR, "juju.worker", "/src/juju/worker/runner.go", 42, ts, `it "broke"`)
+	c.Assert(line, gc.Equals, `{"timestamp":"2015-06-01T11:30:00Z","level":"ERROR","module":"juju.worker","location":"runner.go:42","message":"it \"broke\""}`)
+}
+
+func (*jsonSuite) TestFormatNoLocation(c *gc.C) {
+	ts := time.Date(2015, 6, 1, 12, 30, 0, 0, time.UTC)
+	formatter := &logging.JSONFormatter{}
+	line := formatter.Format(loggo.INFO, "unit.mysql/0.install", "", 0, ts, "hello")
+	c.Assert(line, gc.Equals, `{"timestamp":"2015-06-01T12:30:00Z","level":"INFO","module":"unit.mysql/0.install","message":"hello"}`)
+}
@@ -0,0 +1,158 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+// entry holds a log message passed to a loggo.Writer.
+type entry struct {
+	level     loggo.Level
+	module    string
+	filename  string
+	line      int
+	timestamp time.Time
+	message   string
+}
+
+// repeats returns whether e repeats other; the time they were logged
+// is ignored.
+func (e entry) repeats(other entry) bool {
+	return e.level == other.level &&
+		e.module == other.module &&
+		e.filename == other.filename &&
+		e.line == other.line &&
+		e.message == other.message
+}
+
+// budget tracks the messages logged by a module in one second.
+type budget struct {
+	second     int64
+	count      int
+	suppressed int
+	last       entry
+}
+
+// LimitedWriter is a loggo.Writer that protects another from floods of
+// messages. It can collapse runs of identical messages into a single
+// "last message repeated N times" message, and can limit the number
+// of messages written for each module in each second, reporting how
+// many it has dropped.
+//
+// The counts are written when the next message arrives, so Flush
+// should be called before the writer is discarded.
+type LimitedWriter struct {
+	writer      loggo.Writer
+	deduplicate bool
+	rateLimit   int
+
+	mu      sync.Mutex
+	last    *entry
+	written bool
+	repeats int
+	budgets map[string]*budget
+}
+
+// NewLimitedWriter returns a LimitedWriter writing to w. If deduplicate
+// is true, repeated messages are collapsed; if rateLimit is positive,
+// at most that many messages are written per second for each module.
+func NewLimitedWriter(w loggo.Writer, deduplicate bool, rateLimit int) *LimitedWriter {
+	return &LimitedWriter{
+		writer:      w,
+		deduplicate: deduplicate,
+		rateLimit:   rateLimit,
+		budgets:     make(map[string]*budget),
+	}
+}
+
+// Write implements loggo.Writer. Messages are rate limited by the
+// time they were logged, not the time they are written.
+func (w *LimitedWriter) Write(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) {
+	e := entry{level, module, filename, line, timestamp, message}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.deduplicate {
+		// Repeats of a message that was suppressed are rate
+		// limited in turn, rather than counted.
+		if w.last != nil && w.written && e.repeats(*w.last) {
+			w.repeats++
+			w.last.timestamp = e.timestamp
+			return
+		}
+		w.flushRepeats()
+		w.last = &e
+	}
+	w.written = w.limit(e)
+}
+
+// Flush writes the counts of any repeated or suppressed messages not
+// yet reported.
+func (w *LimitedWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushRepeats()
+	for module, b := range w.budgets {
+		w.flushSuppressed(b)
+		delete(w.budgets, module)
+	}
+}
+
+// limit writes the entry unless its module has used up its budget
+// for the second in which it was logged, and returns whether it was
+// written.
+func (w *LimitedWriter) limit(e entry) bool {
+	if w.rateLimit <= 0 {
+		w.write(e)
+		return true
+	}
+	b := w.budgets[e.module]
+	if b == nil {
+		b = &budget{}
+		w.budgets[e.module] = b
+	}
+	if second := e.timestamp.Unix(); b.second != second {
+		w.flushSuppressed(b)
+		*b = budget{second: second}
+	}
+	b.count++
+	if b.count > w.rateLimit {
+		b.suppressed++
+		b.last = e
+		return false
+	}
+	w.write(e)
+	return true
+}
+
+func (w *LimitedWriter) flushRepeats() {
+	if w.repeats == 0 {
+		return
+	}
+	e := *w.last
+	e.message = fmt.Sprintf("last message repeated %d times", w.repeats)
+	w.write(e)
+	w.repeats = 0
+}
+
+func (w *LimitedWriter) flushSuppressed(b *budget) {
+	if b.suppressed == 0 {
+		return
+	}
+	e := b.last
+	e.level = loggo.WARNING
+	e.filename, e.line = "", 0
+	e.message = fmt.Sprintf("%d messages suppressed by rate limit", b.suppressed)
+	w.write(e)
+	b.suppressed = 0
+}
+
+func (w *LimitedWriter) write(e entry) {
+	w.writer.Write(e.level, e.module, e.filename, e.line, e.timestamp, e.message)
+}
@@ -0,0 +1,135 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type limitSuite struct {
+	testing.BaseSuite
+	tw loggo.TestWriter
+}
+
+var _ = gc.Suite(&limitSuite{})
+
+func (s *limitSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.tw.Clear()
+}
+
+var limitTime = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type logged struct {
+	level   loggo.Level
+	module  string
+	message string
+}
+
+func (s *limitSuite) write(w *logging.LimitedWriter, offset time.Duration, msg logged) {
+	w.Write(msg.level, msg.module, "branch.go", 10, limitTime.Add(offset), msg.message)
+}
+
+func (s *limitSuite) assertLogged(c *gc.C, expect ...logged) {
+	var obtained []logged
+	for _, v := range s.tw.Log() {
+		obtained = append(obtained, logged{v.Level, v.Module, v.Message})
+	}
+	c.Assert(obtained, gc.DeepEquals, expect)
+}
+
+var (
+	branchError = logged{loggo.ERROR, "juju.charm", "cannot read branch"}
+	otherError  = logged{loggo.ERROR, "juju.charm", "cannot read charm"}
+)
+
+func (s *limitSuite) TestPassThrough(c *gc.C) {
+	w := logging.NewLimitedWriter(&s.tw, false, 0)
+	for i := 0; i < 3; i++ {
+		s.write(w, 0, branchError)
+	}
+	w.Flush()
+	s.assertLogged(c, branchError, branchError, branchError)
+}
+
+func (s *limitSuite) TestDeduplicate(c *gc.C) {
+	w := logging.NewLimitedWriter(&s.tw, true, 0)
+	for i := 0; i < 1000; i++ {
+		s.write(w, time.Duration(i)*time.Millisecond, branchError)
+	}
+	s.write(w, time.Second, otherError)
+	s.write(w, time.Second, branchError)
+	s.assertLogged(c,
+		branchError,
+		logged{loggo.ERROR, "juju.charm", "last message repeated 999 times"},
+		otherError,
+		branchError,
+	)
+	c.Check(s.tw.Log()[1].Timestamp, gc.Equals, limitTime.Add(999*time.Millisecond))
+}
+
+func (s *limitSuite) TestDeduplicateFlush(c *gc.C) {
+	w := logging.NewLimitedWriter(&s.tw, true, 0)
+	s.write(w, 0, branchError)
+	s.write(w, 0, branchError)
+	w.Flush()
+	w.Flush()
+	s.assertLogged(c,
+		branchError,
+		logged{loggo.ERROR, "juju.charm", "last message repeated 1 times"},
+	)
+}
+
+func (s *limitSuite) TestDeduplicateDifferentLevel(c *gc.C) {
+	w := logging.NewLimitedWriter(&s.tw, true, 0)
+	warning := branchError
+	warning.level = loggo.WARNING
+	s.write(w, 0, branchError)
+	s.write(w, 0, warning)
+	s.assertLogged(c, branchError, warning)
+}
+
+func (s *limitSuite) TestRateLimit(c *gc.C) {
+	w := logging.NewLimitedWriter(&s.tw, false, 2)
+	other := logged{loggo.INFO, "juju.worker", "working"}
+	for i := 0; i < 5; i++ {
+		s.write(w, 0, branchError)
+		s.write(w, 0, other)
+	}
+	s.write(w, time.Second, otherError)
+	s.assertLogged(c,
+		branchError, other,
+		branchError, other,
+		logged{loggo.WARNING, "juju.charm", "3 messages suppressed by rate limit"},
+		otherError,
+	)
+
+	s.tw.Clear()
+	w.Flush()
+	s.assertLogged(c,
+		logged{loggo.WARNING, "juju.worker", "3 messages suppressed by rate limit"},
+	)
+}
+
+func (s *limitSuite) TestDeduplicateAndRateLimit(c *gc.C) {
+	w := logging.NewLimitedWriter(&s.tw, true, 1)
+	for i := 0; i < 10; i++ {
+		s.write(w, 0, branchError)
+	}
+	s.write(w, 0, otherError)
+	s.write(w, time.Second, otherError)
+	w.Flush()
+	s.assertLogged(c,
+		branchError,
+		logged{loggo.ERROR, "juju.charm", "last message repeated 9 times"},
+		logged{loggo.WARNING, "juju.charm", "1 messages suppressed by rate limit"},
+		otherError,
+	)
+}
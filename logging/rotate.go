@@ -0,0 +1,262 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const (
+	// Megabyte is a convenience for setting RotatingFile.MaxSize.
+	Megabyte = 1024 * 1024
+
+	// DefaultMaxSize is the MaxSize used when none is set.
+	DefaultMaxSize = 100 * Megabyte
+
+	// compressedExt is the extension added to compressed backups.
+	compressedExt = ".gz"
+)
+
+// RotatingFile is an io.WriteCloser that writes to a file, moving it
+// aside when it grows past MaxSize. The file is named Filename; its
+// backups are named Filename.1 (the most recent), Filename.2 and so
+// on, with a ".gz" extension if they are compressed.
+//
+// A RotatingFile can be used as the target of a Sink, or as the
+// stderr of an agent's command context.
+type RotatingFile struct {
+	// Filename is the name of the file written to.
+	Filename string
+
+	// MaxSize is the size, in bytes, that the file may grow to
+	// before it is rotated. If it is zero, DefaultMaxSize is used.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files kept; the oldest
+	// are removed. If it is zero, the file is truncated rather than
+	// rotated.
+	MaxBackups int
+
+	// MaxAge is the number of days for which rotated files are
+	// kept; older backups are removed when the file is rotated. If
+	// it is zero, backups are not removed because of their age.
+	MaxAge int
+
+	// Compress causes rotated files to be compressed with gzip.
+	Compress bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// compressing tracks the compression of the newest backup,
+	// which must finish before the backups are rotated again.
+	compressing sync.WaitGroup
+}
+
+// Write implements io.Writer. The file is rotated before the write if
+// the write would make it larger than MaxSize, so writes are never
+// split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize() {
+		if err := f.rotate(); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer. It waits for any backup being
+// compressed.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.compressing.Wait()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Rotate moves the current file aside, and starts a new one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return f.rotate()
+}
+
+func (f *RotatingFile) maxSize() int64 {
+	if f.MaxSize > 0 {
+		return f.MaxSize
+	}
+	return DefaultMaxSize
+}
+
+// open opens the file for appending, creating it if necessary.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Annotate(err, "cannot open log file")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Annotate(err, "cannot open log file")
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) backupName(n int) string {
+	return fmt.Sprintf("%s.%d", f.Filename, n)
+}
+
+// rotate closes the current file, moves it and its backups aside,
+// and opens a new file with the same mode. It must be called with
+// f.mu held and the file open.
+func (f *RotatingFile) rotate() error {
+	info, err := f.file.Stat()
+	if err != nil {
+		return errors.Annotate(err, "cannot rotate log file")
+	}
+	if err := f.file.Close(); err != nil {
+		return errors.Annotate(err, "cannot rotate log file")
+	}
+	f.file = nil
+	f.compressing.Wait()
+
+	if f.MaxBackups > 0 {
+		if err := f.shiftBackups(); err != nil {
+			return errors.Annotate(err, "cannot rotate log file")
+		}
+		if err := f.removeExpiredBackups(); err != nil {
+			return errors.Annotate(err, "cannot rotate log file")
+		}
+		if err := os.Rename(f.Filename, f.backupName(1)); err != nil {
+			return errors.Annotate(err, "cannot rotate log file")
+		}
+		if f.Compress {
+			f.compressing.Add(1)
+			go func(name string) {
+				defer f.compressing.Done()
+				if err := compress(name); err != nil {
+					// The logs may not be working, so report
+					// the failure where it will be seen.
+					fmt.Fprintf(os.Stderr, "cannot compress log file: %v\n", err)
+				}
+			}(f.backupName(1))
+		}
+	}
+
+	file, err := os.OpenFile(f.Filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return errors.Annotate(err, "cannot rotate log file")
+	}
+	f.file = file
+	f.size = 0
+	return nil
+}
+
+// shiftBackups renames each backup to the next number, removing the
+// oldest so that there is room for the new one.
+func (f *RotatingFile) shiftBackups() error {
+	for _, ext := range []string{"", compressedExt} {
+		oldest := f.backupName(f.MaxBackups) + ext
+		if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for n := f.MaxBackups - 1; n > 0; n-- {
+		for _, ext := range []string{"", compressedExt} {
+			err := os.Rename(f.backupName(n)+ext, f.backupName(n+1)+ext)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeExpiredBackups removes the backups last written more than
+// MaxAge days ago. The newest backup is about to be replaced, so it is
+// not considered.
+func (f *RotatingFile) removeExpiredBackups() error {
+	if f.MaxAge <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-time.Duration(f.MaxAge) * 24 * time.Hour)
+	for n := 2; n <= f.MaxBackups; n++ {
+		for _, ext := range []string{"", compressedExt} {
+			name := f.backupName(n) + ext
+			info, err := os.Stat(name)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if info.ModTime().Before(cutoff) {
+				if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compress replaces the named file with a gzipped copy.
+func compress(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(name+compressedExt, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + compressedExt)
+		return err
+	}
+	// Windows cannot remove open files.
+	in.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type rotateSuite struct {
+	testing.BaseSuite
+	dir      string
+	filename string
+}
+
+var _ = gc.Suite(&rotateSuite{})
+
+func (s *rotateSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.dir = c.MkDir()
+	s.filename = filepath.Join(s.dir, "machine-0.log")
+}
+
+func (s *rotateSuite) write(c *gc.C, f *logging.RotatingFile, data string) {
+	n, err := f.Write([]byte(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, len(data))
+}
+
+func (s *rotateSuite) assertFile(c *gc.C, name, content string) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, content)
+}
+
+func (s *rotateSuite) assertCompressed(c *gc.C, name, content string) {
+	file, err := os.Open(filepath.Join(s.dir, name))
+	c.Assert(err, jc.ErrorIsNil)
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, content)
+}
+
+func (s *rotateSuite) assertFiles(c *gc.C, names ...string) {
+	infos, err := ioutil.ReadDir(s.dir)
+	c.Assert(err, jc.ErrorIsNil)
+	var found []string
+	for _, info := range infos {
+		found = append(found, info.Name())
+	}
+	c.Check(found, jc.SameContents, names)
+}
+
+func (s *rotateSuite) TestAppends(c *gc.C) {
+	err := ioutil.WriteFile(s.filename, []byte("old\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	f := &logging.RotatingFile{Filename: s.filename, MaxSize: 100}
+	s.write(c, f, "new\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+	s.assertFile(c, "machine-0.log", "old\nnew\n")
+}
+
+func (s *rotateSuite) TestRotates(c *gc.C) {
+	f := &logging.RotatingFile{Filename: s.filename, MaxSize: 10, MaxBackups: 2}
+	s.write(c, f, "aaaaaa\n")
+	s.write(c, f, "bbbbbb\n")
+	s.write(c, f, "cccccc\n")
+	s.write(c, f, "dddddd\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+
+	s.assertFiles(c, "machine-0.log", "machine-0.log.1", "machine-0.log.2")
+	s.assertFile(c, "machine-0.log", "dddddd\n")
+	s.assertFile(c, "machine-0.log.1", "cccccc\n")
+	s.assertFile(c, "machine-0.log.2", "bbbbbb\n")
+}
+
+func (s *rotateSuite) TestLargeWrite(c *gc.C) {
+	f := &logging.RotatingFile{Filename: s.filename, MaxSize: 4, MaxBackups: 1}
+	s.write(c, f, "too large\n")
+	s.write(c, f, "also too large\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+	s.assertFile(c, "machine-0.log", "also too large\n")
+	s.assertFile(c, "machine-0.log.1", "too large\n")
+}
+
+func (s *rotateSuite) TestNoBackups(c *gc.C) {
+	f := &logging.RotatingFile{Filename: s.filename, MaxSize: 10}
+	s.write(c, f, "aaaaaa\n")
+	s.write(c, f, "bbbbbb\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+	s.assertFiles(c, "machine-0.log")
+	s.assertFile(c, "machine-0.log", "bbbbbb\n")
+}
+
+func (s *rotateSuite) TestCompress(c *gc.C) {
+	f := &logging.RotatingFile{Filename: s.filename, MaxSize: 10, MaxBackups: 2, Compress: true}
+	s.write(c, f, "aaaaaa\n")
+	s.write(c, f, "bbbbbb\n")
+	s.write(c, f, "cccccc\n")
+	s.write(c, f, "dddddd\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+
+	s.assertFiles(c, "machine-0.log", "machine-0.log.1.gz", "machine-0.log.2.gz")
+	s.assertFile(c, "machine-0.log", "dddddd\n")
+	s.assertCompressed(c, "machine-0.log.1.gz", "cccccc\n")
+	s.assertCompressed(c, "machine-0.log.2.gz", "bbbbbb\n")
+}
+
+func (s *rotateSuite) TestRotateKeepsMode(c *gc.C) {
+	err := ioutil.WriteFile(s.filename, []byte("old\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	f := &logging.RotatingFile{Filename: s.filename, MaxBackups: 1}
+	c.Assert(f.Rotate(), jc.ErrorIsNil)
+	s.write(c, f, "new\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+
+	s.assertFile(c, "machine-0.log", "new\n")
+	s.assertFile(c, "machine-0.log.1", "old\n")
+	info, err := os.Stat(s.filename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0600))
+}
+
+func (s *rotateSuite) TestRemovesExpiredBackups(c *gc.C) {
+	f := &logging.RotatingFile{Filename: s.filename, MaxSize: 10, MaxBackups: 3, MaxAge: 7}
+	s.write(c, f, "aaaaaa\n")
+	s.write(c, f, "bbbbbb\n")
+	s.write(c, f, "cccccc\n")
+	s.assertFiles(c, "machine-0.log", "machine-0.log.1", "machine-0.log.2")
+
+	// Backdate the oldest backup; it is removed at the next rotation,
+	// once it has been shifted along.
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	err := os.Chtimes(filepath.Join(s.dir, "machine-0.log.2"), old, old)
+	c.Assert(err, jc.ErrorIsNil)
+	s.write(c, f, "dddddd\n")
+	c.Assert(f.Close(), jc.ErrorIsNil)
+
+	s.assertFiles(c, "machine-0.log", "machine-0.log.1", "machine-0.log.2")
+	s.assertFile(c, "machine-0.log", "dddddd\n")
+	s.assertFile(c, "machine-0.log.1", "cccccc\n")
+	s.assertFile(c, "machine-0.log.2", "bbbbbb\n")
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/juju/sockets"
+)
+
+// SetConfigEndpoint is the endpoint of the logging configuration RPC
+// server.
+const SetConfigEndpoint = "LoggingConfigServer.Set"
+
+// SetConfigArgs holds the arguments for a SetConfigEndpoint call.
+type SetConfigArgs struct {
+	// Config is the logging configuration string, e.g.
+	// "juju.worker=DEBUG".
+	Config string
+
+	// Replace causes all the modules' levels to be reset before
+	// the configuration is applied, rather than only those of the
+	// modules named.
+	Replace bool
+}
+
+// ConfigResult holds the result of a call to the logging
+// configuration RPC server.
+type ConfigResult struct {
+	// Previous is the configuration before the call.
+	Previous string
+
+	// Current is the configuration after the call.
+	Current string
+}
+
+// LoggingConfigServer is the entity whose methods are called over the
+// RPC connection; it reads and changes the configuration of the
+// process's loggers.
+type LoggingConfigServer struct{}
+
+// Set changes the logging configuration. An empty configuration, not
+// replacing the current one, changes nothing, so can be used to read
+// the current configuration.
+func (LoggingConfigServer) Set(args SetConfigArgs, result *ConfigResult) error {
+	set := UpdateConfig
+	if args.Replace {
+		set = ReplaceConfig
+	}
+	previous, err := set(args.Config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	*result = ConfigResult{Previous: previous, Current: Config()}
+	return nil
+}
+
+// ConfigSocketPath returns the socket (or, on Windows, named pipe) on
+// which the tagged agent serves its logging configuration.
+func ConfigSocketPath(dataDir, tag string) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf(`\\.\pipe\%s-logging`, tag)
+	}
+	return filepath.Join(dataDir, "agents", tag, "logging.socket")
+}
+
+// ConfigListener serves a LoggingConfigServer on a socket, so that the
+// logging configuration of a running agent can be changed.
+type ConfigListener struct {
+	listener net.Listener
+	server   *rpc.Server
+	closed   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewConfigListener returns a ConfigListener serving on the socket. It
+// should be closed by the creator when they are done with it.
+func NewConfigListener(socketPath string) (*ConfigListener, error) {
+	server := rpc.NewServer()
+	if err := server.Register(LoggingConfigServer{}); err != nil {
+		return nil, errors.Trace(err)
+	}
+	listener, err := sockets.Listen(socketPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	l := &ConfigListener{
+		listener: listener,
+		server:   server,
+		closed:   make(chan struct{}),
+	}
+	go l.run()
+	return l, nil
+}
+
+func (l *ConfigListener) run() {
+	defer close(l.closed)
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			break
+		}
+		l.wg.Add(1)
+		go func(conn net.Conn) {
+			defer l.wg.Done()
+			l.server.ServeConn(conn)
+		}(conn)
+	}
+	l.wg.Wait()
+}
+
+// Close stops accepting connections, and blocks until all existing
+// connections have been closed.
+func (l *ConfigListener) Close() error {
+	err := l.listener.Close()
+	<-l.closed
+	return err
+}
+
+// GetRemoteConfig returns the logging configuration of the process
+// serving on the socket.
+func GetRemoteConfig(socketPath string) (string, error) {
+	result, err := SetRemoteConfig(socketPath, SetConfigArgs{})
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return result.Current, nil
+}
+
+// SetRemoteConfig changes the logging configuration of the process
+// serving on the socket.
+func SetRemoteConfig(socketPath string, args SetConfigArgs) (ConfigResult, error) {
+	client, err := sockets.Dial(socketPath)
+	if err != nil {
+		return ConfigResult{}, errors.Trace(err)
+	}
+	defer client.Close()
+	var result ConfigResult
+	if err := client.Call(SetConfigEndpoint, args, &result); err != nil {
+		return ConfigResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type serverSuite struct {
+	testing.BaseSuite
+	socketPath string
+	listener   *logging.ConfigListener
+}
+
+var _ = gc.Suite(&serverSuite{})
+
+func (s *serverSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	loggo.ResetLoggers()
+	s.AddCleanup(func(*gc.C) { loggo.ResetLoggers() })
+
+	s.socketPath = logging.ConfigSocketPath(c.MkDir(), "machine-0")
+	if runtime.GOOS != "windows" {
+		s.socketPath = filepath.Join(c.MkDir(), "logging.socket")
+	}
+	listener, err := logging.NewConfigListener(s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	s.listener = listener
+}
+
+func (s *serverSuite) TearDownTest(c *gc.C) {
+	c.Check(s.listener.Close(), jc.ErrorIsNil)
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *serverSuite) TestSocketPath(c *gc.C) {
+	path := logging.ConfigSocketPath("/var/lib/juju", "unit-mysql-0")
+	if runtime.GOOS == "windows" {
+		c.Assert(path, gc.Equals, `\\.\pipe\unit-mysql-0-logging`)
+	} else {
+		c.Assert(path, gc.Equals, "/var/lib/juju/agents/unit-mysql-0/logging.socket")
+	}
+}
+
+func (s *serverSuite) TestGetRemoteConfig(c *gc.C) {
+	_, err := logging.UpdateConfig("juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	config, err := logging.GetRemoteConfig(s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config, gc.Equals, "<root>=WARNING;juju.worker=DEBUG")
+}
+
+func (s *serverSuite) TestSetRemoteConfig(c *gc.C) {
+	_, err := logging.UpdateConfig("juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := logging.SetRemoteConfig(s.socketPath, logging.SetConfigArgs{Config: "juju.state=TRACE"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, logging.ConfigResult{
+		Previous: "<root>=WARNING;juju.worker=DEBUG",
+		Current:  "<root>=WARNING;juju.state=TRACE;juju.worker=DEBUG",
+	})
+	c.Assert(loggo.GetLogger("juju.state").LogLevel(), gc.Equals, loggo.TRACE)
+}
+
+func (s *serverSuite) TestSetRemoteConfigReplace(c *gc.C) {
+	_, err := logging.UpdateConfig("juju.worker=DEBUG")
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := logging.SetRemoteConfig(s.socketPath, logging.SetConfigArgs{
+		Config:  "juju.state=TRACE",
+		Replace: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Current, gc.Equals, "<root>=WARNING;juju.state=TRACE")
+}
+
+func (s *serverSuite) TestSetRemoteConfigInvalid(c *gc.C) {
+	_, err := logging.SetRemoteConfig(s.socketPath, logging.SetConfigArgs{Config: "juju=LOUD"})
+	c.Assert(err, gc.ErrorMatches, `unknown severity level "LOUD"`)
+}
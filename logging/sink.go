@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package logging provides loggo writers and formatters used by juju's
+// commands and agents, so that log messages can be sent to several
+// destinations at once, each with its own format and level.
+package logging
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+// Format names the way log messages are written by a sink.
+type Format string
+
+const (
+	// TextFormat writes messages in loggo's default format.
+	TextFormat Format = "text"
+
+	// JSONFormat writes each message as a JSON object on its own
+	// line; see Record.
+	JSONFormat Format = "json"
+)
+
+// NewFormatter returns the loggo.Formatter for the format. The empty
+// format is TextFormat.
+func NewFormatter(format Format) (loggo.Formatter, error) {
+	switch format {
+	case "", TextFormat:
+		return &loggo.DefaultFormatter{}, nil
+	case JSONFormat:
+		return &JSONFormatter{}, nil
+	}
+	return nil, errors.NotValidf("log format %q", format)
+}
+
+// Sink describes a destination for log messages.
+type Sink struct {
+	// Name identifies the sink's loggo writer.
+	Name string
+
+	// Writer receives the formatted messages, one per line.
+	Writer io.Writer
+
+	// Format is the format the messages are written in.
+	Format Format
+
+	// Level is the lowest level of message written to the sink;
+	// messages must also be enabled for their module. UNSPECIFIED
+	// writes all the enabled messages.
+	Level loggo.Level
+
+	// Deduplicate collapses runs of identical messages into one,
+	// followed by a count of the repeats.
+	Deduplicate bool
+
+	// RateLimit is the most messages written to the sink for each
+	// module in each second. Zero is unlimited.
+	RateLimit int
+}
+
+// Validate returns an error if the sink is not usable.
+func (s Sink) Validate() error {
+	if s.Name == "" {
+		return errors.NotValidf("empty sink name")
+	}
+	if s.Writer == nil {
+		return errors.NotValidf("sink %q without a writer", s.Name)
+	}
+	if _, err := NewFormatter(s.Format); err != nil {
+		return errors.Annotatef(err, "sink %q", s.Name)
+	}
+	if s.RateLimit < 0 {
+		return errors.NotValidf("sink %q rate limit %d", s.Name, s.RateLimit)
+	}
+	return nil
+}
+
+// Install registers the sinks as loggo writers, so that log messages
+// are written to all of them. If any sink cannot be installed, none
+// are.
+func Install(sinks ...Sink) error {
+	for _, sink := range sinks {
+		if err := sink.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, sink := range sinks {
+		formatter, _ := NewFormatter(sink.Format)
+		writer := loggo.NewSimpleWriter(sink.Writer, formatter)
+		if sink.Deduplicate || sink.RateLimit > 0 {
+			writer = NewLimitedWriter(writer, sink.Deduplicate, sink.RateLimit)
+		}
+		if err := loggo.RegisterWriter(sink.Name, writer, sink.Level); err != nil {
+			Uninstall(sinks[:i]...)
+			return errors.Annotatef(err, "cannot install sink %q", sink.Name)
+		}
+	}
+	return nil
+}
+
+// Uninstall removes the sinks' loggo writers, first writing the counts
+// of any messages they have collapsed or suppressed. Sinks that are
+// not installed are ignored.
+func Uninstall(sinks ...Sink) {
+	for _, sink := range sinks {
+		writer, _, err := loggo.RemoveWriter(sink.Name)
+		if err != nil {
+			continue
+		}
+		if limited, ok := writer.(*LimitedWriter); ok {
+			limited.Flush()
+		}
+	}
+}
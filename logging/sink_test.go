@@ -0,0 +1,140 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type sinkSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&sinkSuite{})
+
+func (s *sinkSuite) TestNewFormatter(c *gc.C) {
+	for _, format := range []logging.Format{"", logging.TextFormat} {
+		formatter, err := logging.NewFormatter(format)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(formatter, gc.FitsTypeOf, &loggo.DefaultFormatter{})
+	}
+	formatter, err := logging.NewFormatter(logging.JSONFormat)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(formatter, gc.FitsTypeOf, &logging.JSONFormatter{})
+
+	_, err = logging.NewFormatter("xml")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `log format "xml" not valid`)
+}
+
+func (s *sinkSuite) TestValidate(c *gc.C) {
+	var buf bytes.Buffer
+	for i, test := range []struct {
+		sink logging.Sink
+		err  string
+	}{{
+		sink: logging.Sink{Writer: &buf},
+		err:  "empty sink name not valid",
+	}, {
+		sink: logging.Sink{Name: "foo"},
+		err:  `sink "foo" without a writer not valid`,
+	}, {
+		sink: logging.Sink{Name: "foo", Writer: &buf, Format: "xml"},
+		err:  `sink "foo": log format "xml" not valid`,
+	}, {
+		sink: logging.Sink{Name: "foo", Writer: &buf, RateLimit: -1},
+		err:  `sink "foo" rate limit -1 not valid`,
+	}} {
+		c.Logf("test %d", i)
+		c.Check(test.sink.Validate(), gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *sinkSuite) TestInstall(c *gc.C) {
+	var text, jsonl bytes.Buffer
+	sinks := []logging.Sink{{
+		Name:   "text",
+		Writer: &text,
+	}, {
+		Name:   "json",
+		Writer: &jsonl,
+		Format: logging.JSONFormat,
+		Level:  loggo.WARNING,
+	}}
+	err := logging.Install(sinks...)
+	c.Assert(err, jc.ErrorIsNil)
+	defer logging.Uninstall(sinks...)
+
+	logger := loggo.GetLogger("test.logging")
+	logger.SetLogLevel(loggo.DEBUG)
+	logger.Debugf("debug detail")
+	logger.Warningf("careful")
+
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	c.Assert(lines, gc.HasLen, 2)
+	c.Check(lines[0], gc.Matches, `.* DEBUG test.logging sink_test.go:\d+ debug detail`)
+	c.Check(lines[1], gc.Matches, `.* WARNING test.logging sink_test.go:\d+ careful`)
+
+	lines = strings.Split(strings.TrimSpace(jsonl.String()), "\n")
+	c.Assert(lines, gc.HasLen, 1)
+	var record logging.Record
+	err = json.Unmarshal([]byte(lines[0]), &record)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(record.Level, gc.Equals, "WARNING")
+	c.Check(record.Message, gc.Equals, "careful")
+}
+
+func (s *sinkSuite) TestInstallLimited(c *gc.C) {
+	var buf bytes.Buffer
+	sink := logging.Sink{
+		Name:        "limited",
+		Writer:      &buf,
+		Deduplicate: true,
+	}
+	err := logging.Install(sink)
+	c.Assert(err, jc.ErrorIsNil)
+
+	logger := loggo.GetLogger("test.logging")
+	for i := 0; i < 3; i++ {
+		logger.Warningf("cannot read branch")
+	}
+	logging.Uninstall(sink)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(lines, gc.HasLen, 2)
+	c.Check(lines[0], gc.Matches, `.* WARNING test.logging sink_test.go:\d+ cannot read branch`)
+	c.Check(lines[1], gc.Matches, `.* WARNING test.logging sink_test.go:\d+ last message repeated 2 times`)
+}
+
+func (s *sinkSuite) TestInstallInvalid(c *gc.C) {
+	var buf bytes.Buffer
+	err := logging.Install(logging.Sink{Name: "ok", Writer: &buf}, logging.Sink{Name: "bad"})
+	c.Assert(err, gc.ErrorMatches, `sink "bad" without a writer not valid`)
+	_, _, err = loggo.RemoveWriter("ok")
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *sinkSuite) TestInstallDuplicate(c *gc.C) {
+	var buf bytes.Buffer
+	first := logging.Sink{Name: "first", Writer: &buf}
+	err := logging.Install(first)
+	c.Assert(err, jc.ErrorIsNil)
+	defer logging.Uninstall(first)
+
+	second := logging.Sink{Name: "second", Writer: &buf}
+	err = logging.Install(second, first)
+	c.Assert(err, gc.ErrorMatches, `cannot install sink "first": .*`)
+	_, _, err = loggo.RemoveWriter("second")
+	c.Assert(err, gc.NotNil)
+}
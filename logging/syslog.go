@@ -0,0 +1,163 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+// syslogAddrs holds the sockets the local syslog daemon may listen
+// on, in the order they are tried.
+var syslogAddrs = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+const (
+	// syslogFacility is the "daemon" facility.
+	syslogFacility = 3
+
+	// syslogSDID identifies juju's structured data, using
+	// Canonical's private enterprise number.
+	syslogSDID = "juju@28978"
+)
+
+// SyslogWriter is a loggo.Writer that forwards messages to the local
+// syslog daemon in RFC 5424 format. Each message's module, level,
+// location and the tag of the entity that logged it are sent as
+// structured data.
+type SyslogWriter struct {
+	// Tag is the tag of the entity logging, e.g. "machine-0".
+	Tag string
+
+	// AppName is the name of the application logging.
+	AppName string
+
+	hostname string
+	pid      int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogWriter returns a SyslogWriter for the tagged entity. It
+// connects to syslog when the first message is written.
+func NewSyslogWriter(tag string) *SyslogWriter {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	return &SyslogWriter{
+		Tag:      tag,
+		AppName:  filepath.Base(os.Args[0]),
+		hostname: hostname,
+		pid:      os.Getpid(),
+	}
+}
+
+// Write implements loggo.Writer. Messages that cannot be sent are
+// dropped, since there is nowhere to report the failure; the
+// connection is retried with the next message.
+func (w *SyslogWriter) Write(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) {
+	msg := w.format(level, module, filename, line, timestamp, message)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			conn, err := dialSyslog()
+			if err != nil {
+				return
+			}
+			w.conn = conn
+		}
+		if _, err := w.conn.Write([]byte(msg)); err == nil {
+			return
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// Close closes the connection to syslog, if any.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// format returns the message as an RFC 5424 syslog message.
+func (w *SyslogWriter) format(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) string {
+	params := []string{
+		sdParam("module", module),
+		sdParam("level", level.String()),
+	}
+	if filename != "" {
+		params = append(params, sdParam("location", fmt.Sprintf("%s:%d", filepath.Base(filename), line)))
+	}
+	if w.Tag != "" {
+		params = append(params, sdParam("tag", w.Tag))
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d - [%s %s] %s",
+		syslogFacility*8+syslogSeverity(level),
+		timestamp.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		nilValue(w.hostname),
+		nilValue(w.AppName),
+		w.pid,
+		syslogSDID,
+		strings.Join(params, " "),
+		message,
+	)
+}
+
+// syslogSeverity returns the syslog severity for the level.
+func syslogSeverity(level loggo.Level) int {
+	switch level {
+	case loggo.CRITICAL:
+		return 2
+	case loggo.ERROR:
+		return 3
+	case loggo.WARNING:
+		return 4
+	case loggo.INFO:
+		return 6
+	}
+	return 7
+}
+
+// sdParam returns an RFC 5424 structured data parameter.
+func sdParam(name, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+	return fmt.Sprintf(`%s="%s"`, name, value)
+}
+
+// nilValue returns value, or the RFC 5424 NILVALUE if it is empty.
+func nilValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.Replace(value, " ", "_", -1)
+}
+
+func dialSyslog() (net.Conn, error) {
+	for _, addr := range syslogAddrs {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, addr); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, errors.New("cannot connect to syslog")
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logging_test
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logging"
+	"github.com/juju/juju/testing"
+)
+
+type syslogSuite struct {
+	testing.BaseSuite
+	listener *net.UnixConn
+}
+
+var _ = gc.Suite(&syslogSuite{})
+
+func (s *syslogSuite) SetUpTest(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("no syslog on windows")
+	}
+	s.BaseSuite.SetUpTest(c)
+	addr := filepath.Join(c.MkDir(), "log")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.listener = listener
+	s.AddCleanup(func(*gc.C) { listener.Close() })
+	s.PatchValue(logging.SyslogAddrs, []string{filepath.Join(c.MkDir(), "missing"), addr})
+}
+
+func (s *syslogSuite) read(c *gc.C) string {
+	buf := make([]byte, 4096)
+	s.listener.SetReadDeadline(time.Now().Add(testing.LongWait))
+	n, err := s.listener.Read(buf)
+	c.Assert(err, jc.ErrorIsNil)
+	return string(buf[:n])
+}
+
+func (s *syslogSuite) TestWrite(c *gc.C) {
+	w := logging.NewSyslogWriter("unit-mysql-0")
+	w.AppName = "jujud"
+	defer w.Close()
+
+	ts := time.Date(2015, 6, 1, 12, 30, 0, 5000, time.UTC)
+	w.Write(loggo.WARNING, "juju.worker.uniter", "/src/uniter.go", 12, ts, `bad "thing" [x]`)
+	c.Assert(s.read(c), gc.Matches, `<28>1 2015-06-01T12:30:00.000005Z \S+ jujud \d+ - `+
+		`\[juju@28978 module="juju.worker.uniter" level="WARNING" location="uniter.go:12" tag="unit-mysql-0"\] `+
+		`bad "thing" \[x\]`)
+
+	w.Write(loggo.DEBUG, `odd"module]`, "", 0, ts, "detail")
+	c.Assert(s.read(c), gc.Matches, `<31>1 .* \[juju@28978 module="odd\\"module\\]" level="DEBUG" tag="unit-mysql-0"\] detail`)
+}
+
+func (s *syslogSuite) TestSeverities(c *gc.C) {
+	w := logging.NewSyslogWriter("")
+	defer w.Close()
+	for level, pri := range map[loggo.Level]string{
+		loggo.CRITICAL: "<26>",
+		loggo.ERROR:    "<27>",
+		loggo.WARNING:  "<28>",
+		loggo.INFO:     "<30>",
+		loggo.DEBUG:    "<31>",
+		loggo.TRACE:    "<31>",
+	} {
+		w.Write(level, "juju", "", 0, time.Now(), "hi")
+		c.Check(s.read(c)[:4], gc.Equals, pri)
+	}
+}
+
+func (s *syslogSuite) TestNoSyslog(c *gc.C) {
+	s.PatchValue(logging.SyslogAddrs, []string{filepath.Join(c.MkDir(), "missing")})
+	w := logging.NewSyslogWriter("machine-0")
+	// Messages are dropped.
+	w.Write(loggo.INFO, "juju", "", 0, time.Now(), "hi")
+	c.Assert(w.Close(), jc.ErrorIsNil)
+}
@@ -0,0 +1,51 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongo
+
+import (
+	"io"
+	"net"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+)
+
+// notMasterCodes holds the error codes mongo returns when an
+// operation that requires the primary is sent to a secondary, as
+// happens for a while after the replica set elects a new primary.
+var notMasterCodes = map[int]bool{
+	10107: true, // not master
+	13435: true, // not master and slaveOk=false
+	13436: true, // not master or secondary; cannot currently read from this replSet member
+}
+
+// IsConnectionError returns whether the error indicates that the
+// connection to mongo has been lost or now refers to a server that is
+// no longer the primary. After such an error the session should be
+// refreshed, after which the operation may succeed.
+func IsConnectionError(err error) bool {
+	err = errors.Cause(err)
+	switch err := err.(type) {
+	case nil:
+		return false
+	case net.Error:
+		return true
+	case *mgo.QueryError:
+		return notMasterCodes[err.Code] || isNotMasterMessage(err.Message)
+	case *mgo.LastError:
+		return notMasterCodes[err.Code] || isNotMasterMessage(err.Err)
+	}
+	if err == io.EOF {
+		return true
+	}
+	msg := err.Error()
+	return msg == "no reachable servers" ||
+		msg == "Closed explicitly" ||
+		isNotMasterMessage(msg)
+}
+
+func isNotMasterMessage(msg string) bool {
+	return strings.HasPrefix(msg, "not master")
+}
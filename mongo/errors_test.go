@@ -0,0 +1,46 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongo_test
+
+import (
+	"io"
+	"net"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2"
+
+	"github.com/juju/juju/mongo"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type errorsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&errorsSuite{})
+
+func (s *errorsSuite) TestIsConnectionError(c *gc.C) {
+	for i, test := range []struct {
+		err    error
+		expect bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{errors.Annotate(io.EOF, "reading"), true},
+		{errors.New("no reachable servers"), true},
+		{&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, true},
+		{&mgo.QueryError{Code: 10107, Message: "not master"}, true},
+		{&mgo.QueryError{Code: 13435, Message: "not master and slaveOk=false"}, true},
+		{&mgo.LastError{Code: 13436, Err: "not master or secondary"}, true},
+		{&mgo.QueryError{Code: 11000, Message: "duplicate key"}, false},
+		{mgo.ErrNotFound, false},
+		{errors.New("some other error"), false},
+	} {
+		c.Logf("test %d: %v", i, test.err)
+		c.Check(mongo.IsConnectionError(test.err), gc.Equals, test.expect)
+	}
+	c.Check(mongo.IsConnectionError(errors.New("not master")), jc.IsTrue)
+}
@@ -22,6 +22,10 @@ var (
 	ipv6UniqueLocal = mustParseCIDR("fc00::/7")
 )
 
+// fanOverlay is the default Ubuntu fan overlay network, from which
+// containers on fan-enabled hosts get their addresses.
+var fanOverlay = mustParseCIDR("252.0.0.0/8")
+
 // globalPreferIPv6 determines whether IPv6 addresses will be
 // preferred when selecting a public or internal addresses, using the
 // Select*() methods below. InitializeFromConfig() needs to be called
@@ -58,7 +62,8 @@ const (
 // address can be reached from the wider internet, it is considered
 // public. A private network address is either specific to the cloud
 // or cloud subnet a machine belongs to, or to the machine itself for
-// containers.
+// containers. Addresses on a fan overlay network are reachable only
+// from other fan-enabled hosts in the same underlay.
 type Scope string
 
 const (
@@ -67,6 +72,7 @@ const (
 	ScopeCloudLocal   Scope = "local-cloud"
 	ScopeMachineLocal Scope = "local-machine"
 	ScopeLinkLocal    Scope = "link-local"
+	ScopeFanLocal     Scope = "local-fan"
 )
 
 // Address represents the location of a machine, including metadata
@@ -167,6 +173,13 @@ func isIPv4PrivateNetworkAddress(addrType AddressType, ip net.IP) bool {
 		classCPrivate.Contains(ip)
 }
 
+func isIPv4FanAddress(addrType AddressType, ip net.IP) bool {
+	if addrType != IPv4Address {
+		return false
+	}
+	return fanOverlay.Contains(ip)
+}
+
 func isIPv6UniqueLocalAddress(addrType AddressType, ip net.IP) bool {
 	if addrType != IPv6Address {
 		return false
@@ -188,6 +201,9 @@ func deriveScope(addr Address) Scope {
 	if ip.IsLoopback() {
 		return ScopeMachineLocal
 	}
+	if isIPv4FanAddress(addr.Type, ip) {
+		return ScopeFanLocal
+	}
 	if isIPv4PrivateNetworkAddress(addr.Type, ip) ||
 		isIPv6UniqueLocalAddress(addr.Type, ip) {
 		return ScopeCloudLocal
@@ -293,7 +309,7 @@ func publicMatch(addr Address, preferIPv6 bool) scopeMatch {
 	switch addr.Scope {
 	case ScopePublic:
 		return mayPreferIPv6(addr, exactScope, preferIPv6)
-	case ScopeCloudLocal, ScopeUnknown:
+	case ScopeCloudLocal, ScopeFanLocal, ScopeUnknown:
 		return mayPreferIPv6(addr, fallbackScope, preferIPv6)
 	}
 	return invalidScope
@@ -328,7 +344,7 @@ func cloudLocalMatch(addr Address, preferIPv6 bool) scopeMatch {
 	switch addr.Scope {
 	case ScopeCloudLocal:
 		return mayPreferIPv6(addr, exactScope, preferIPv6)
-	case ScopePublic, ScopeUnknown:
+	case ScopeFanLocal, ScopePublic, ScopeUnknown:
 		return mayPreferIPv6(addr, fallbackScope, preferIPv6)
 	}
 	return invalidScope
@@ -395,6 +411,7 @@ func bestAddressIndexes(numAddr int, preferIPv6 bool, getAddr func(i int) Addres
 // - public IPs first;
 // - hostnames after that, but "localhost" will be last if present;
 // - cloud-local next;
+// - fan-local next;
 // - machine-local next;
 // - link-local next;
 // - non-hostnames with unknown scope last.
@@ -408,6 +425,8 @@ func (a Address) sortOrder(preferIPv6 bool) int {
 		order = 0x00
 	case ScopeCloudLocal:
 		order = 0x20
+	case ScopeFanLocal:
+		order = 0x30
 	case ScopeMachineLocal:
 		order = 0x40
 	case ScopeLinkLocal:
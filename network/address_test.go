@@ -50,6 +50,10 @@ func (s *AddressSuite) TestNewScopedAddressIPv4(c *gc.C) {
 		value:         "169.254.1.1",
 		scope:         network.ScopeUnknown,
 		expectedScope: network.ScopeLinkLocal,
+	}, {
+		value:         "252.0.16.3",
+		scope:         network.ScopeUnknown,
+		expectedScope: network.ScopeFanLocal,
 	}, {
 		value:         "8.8.8.8",
 		scope:         network.ScopeUnknown,
@@ -163,6 +167,10 @@ func (s *AddressSuite) TestNewAddresses(c *gc.C) {
 		[]string{"fc00::1", "fd00::2"},
 		network.IPv6Address,
 		network.ScopeCloudLocal,
+	}, {
+		[]string{"252.0.16.3", "252.10.1.254"},
+		network.IPv4Address,
+		network.ScopeFanLocal,
 	}, {
 		[]string{"8.8.8.8", "8.8.4.4"},
 		network.IPv4Address,
@@ -270,6 +278,22 @@ var selectPublicTests = []selectTest{{
 	},
 	3,
 	true,
+}, {
+	"a fan-local address is selected when no public address exists",
+	[]network.Address{
+		{"127.0.0.1", network.IPv4Address, "machine", network.ScopeMachineLocal},
+		{"252.0.16.3", network.IPv4Address, "fan", network.ScopeFanLocal},
+	},
+	1,
+	false,
+}, {
+	"a public address is preferred to a fan-local one",
+	[]network.Address{
+		{"252.0.16.3", network.IPv4Address, "fan", network.ScopeFanLocal},
+		{"8.8.8.8", network.IPv4Address, "public", network.ScopePublic},
+	},
+	1,
+	false,
 }, {
 	"a machine IPv4 local address is not selected",
 	[]network.Address{
@@ -407,6 +431,22 @@ var selectInternalTests = []selectTest{{
 	},
 	0,
 	false,
+}, {
+	"a cloud local address is preferred to a fan-local address",
+	[]network.Address{
+		{"252.0.16.3", network.IPv4Address, "fan", network.ScopeFanLocal},
+		{"10.0.0.1", network.IPv4Address, "cloud", network.ScopeCloudLocal},
+	},
+	1,
+	false,
+}, {
+	"a fan-local address is selected when no cloud local address exists",
+	[]network.Address{
+		{"127.0.0.1", network.IPv4Address, "machine", network.ScopeMachineLocal},
+		{"252.0.16.3", network.IPv4Address, "fan", network.ScopeFanLocal},
+	},
+	1,
+	false,
 }, {
 	"a machine local or link-local address is not selected",
 	[]network.Address{
@@ -748,6 +788,7 @@ func (*AddressSuite) TestSortAddresses(c *gc.C) {
 		"fe80::2",
 		"7.8.8.8",
 		"172.16.0.1",
+		"252.0.16.3",
 		"example.com",
 		"8.8.8.8",
 	)
@@ -766,6 +807,8 @@ func (*AddressSuite) TestSortAddresses(c *gc.C) {
 		"172.16.0.1",
 		// Then IPv6 cloud-local addresses.
 		"fc00::1",
+		// Then fan-local addresses.
+		"252.0.16.3",
 		// Then machine-local IPv4 addresses.
 		"127.0.0.1",
 		// Then machine-local IPv6 addresses.
@@ -791,6 +834,8 @@ func (*AddressSuite) TestSortAddresses(c *gc.C) {
 		"fc00::1",
 		// Then IPv4 cloud-local addresses.
 		"172.16.0.1",
+		// Then fan-local addresses.
+		"252.0.16.3",
 		// Then machine-local IPv6 addresses.
 		"::1",
 		// Then machine-local IPv4 addresses.
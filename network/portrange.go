@@ -19,11 +19,21 @@ type PortRange struct {
 	Protocol string
 }
 
+// ICMPPortRange is the port range used to allow ICMP traffic. ICMP
+// has no ports, so both bounds are -1.
+var ICMPPortRange = PortRange{FromPort: -1, ToPort: -1, Protocol: "icmp"}
+
 // IsValid determines if the port range is valid.
 func (p PortRange) Validate() error {
 	proto := strings.ToLower(p.Protocol)
+	if proto == "icmp" {
+		if p.FromPort == -1 && p.ToPort == -1 {
+			return nil
+		}
+		return errors.Errorf("invalid ICMP port range %d-%d, expected no ports", p.FromPort, p.ToPort)
+	}
 	if proto != "tcp" && proto != "udp" {
-		return errors.Errorf(`invalid protocol %q, expected "tcp" or "udp"`, proto)
+		return errors.Errorf(`invalid protocol %q, expected "tcp", "udp", or "icmp"`, proto)
 	}
 	err := errors.Errorf(
 		"invalid port range %d-%d/%s",
@@ -51,6 +61,9 @@ func (a PortRange) ConflictsWith(b PortRange) bool {
 }
 
 func (p PortRange) String() string {
+	if strings.ToLower(p.Protocol) == "icmp" {
+		return "icmp"
+	}
 	if p.FromPort == p.ToPort {
 		return fmt.Sprintf("%d/%s", p.FromPort, strings.ToLower(p.Protocol))
 	}
@@ -135,8 +148,11 @@ func CollapsePorts(ports []Port) (result []PortRange) {
 // string does not include a protocol then "tcp" is used. Validate()
 // gets called on the result before returning. If validation fails the
 // invalid PortRange is still returned.
-// Example strings: "80/tcp", "443", "12345-12349/udp".
+// Example strings: "80/tcp", "443", "12345-12349/udp", "icmp".
 func ParsePortRange(inPortRange string) (PortRange, error) {
+	if strings.ToLower(inPortRange) == "icmp" {
+		return ICMPPortRange, nil
+	}
 	// Extract the protocol.
 	protocol := "tcp"
 	parts := strings.SplitN(inPortRange, "/", 2)
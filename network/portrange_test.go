@@ -63,6 +63,11 @@ func (*PortRangeSuite) TestConflictsWith(c *gc.C) {
 		network.PortRange{100, 200, "TCP"},
 		network.PortRange{120, 140, "TCP"},
 		true,
+	}, {
+		"icmp",
+		network.PortRange{-1, -1, "icmp"},
+		network.PortRange{-1, -1, "icmp"},
+		true,
 	}}
 
 	for i, t := range testCases {
@@ -93,6 +98,11 @@ func (*PortRangeSuite) TestStrings(c *gc.C) {
 		gc.Equals,
 		"80-100/tcp",
 	)
+	c.Assert(
+		network.ICMPPortRange.String(),
+		gc.Equals,
+		"icmp",
+	)
 }
 
 func (*PortRangeSuite) TestValidate(c *gc.C) {
@@ -139,7 +149,15 @@ func (*PortRangeSuite) TestValidate(c *gc.C) {
 	}, {
 		"invalid protocol",
 		network.PortRange{80, 80, "some protocol"},
-		`invalid protocol "some protocol", expected "tcp" or "udp"`,
+		`invalid protocol "some protocol", expected "tcp", "udp", or "icmp"`,
+	}, {
+		"valid icmp",
+		network.PortRange{-1, -1, "icmp"},
+		"",
+	}, {
+		"icmp with ports",
+		network.PortRange{80, 80, "icmp"},
+		"invalid ICMP port range 80-80, expected no ports",
 	}}
 
 	for i, t := range testCases {
@@ -250,6 +268,14 @@ func (*PortRangeSuite) TestParsePortRangeRoundTrip(c *gc.C) {
 	c.Check(portRangeStr, gc.Equals, "8000-8099/tcp")
 }
 
+func (*PortRangeSuite) TestParsePortRangeICMP(c *gc.C) {
+	portRange, err := network.ParsePortRange("ICMP")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(portRange, gc.Equals, network.ICMPPortRange)
+	c.Check(portRange.String(), gc.Equals, "icmp")
+}
+
 func (*PortRangeSuite) TestParsePortRangeMultiRange(c *gc.C) {
 	_, err := network.ParsePortRange("10-55-100")
 
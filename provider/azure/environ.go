@@ -572,6 +572,10 @@ func (env *azureEnviron) selectInstanceTypeAndImage(constraint *instances.Instan
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.Tags,
+	constraints.Zones,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -736,11 +740,14 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 
 	snapshot := env.getSnapshot()
 	location := snapshot.ecfg.location()
+	series := args.Tools.OneSeries()
+	imageId, _ := snapshot.ecfg.PinnedImageId(location, series)
 	instanceType, sourceImageName, err := env.selectInstanceTypeAndImage(&instances.InstanceConstraint{
 		Region:      location,
-		Series:      args.Tools.OneSeries(),
+		Series:      series,
 		Arches:      args.Tools.Arches(),
 		Constraints: args.Constraints,
+		ImageId:     imageId,
 	})
 	if err != nil {
 		return nil, err
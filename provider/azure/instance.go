@@ -109,6 +109,10 @@ func (azInstance *azureInstance) openEndpoints(api *gwacl.ManagementAPI, portRan
 		RoleName:       azInstance.roleName,
 	}
 	for _, portRange := range portRanges {
+		if portRange.Protocol == "icmp" {
+			logger.Warningf("ignoring %v: ICMP cannot be opened or closed with Azure endpoints", portRange)
+			continue
+		}
 		name := fmt.Sprintf("%s%d-%d", portRange.Protocol, portRange.FromPort, portRange.ToPort)
 		for port := portRange.FromPort; port <= portRange.ToPort; port++ {
 			endpoint := gwacl.InputEndpoint{
@@ -156,6 +160,10 @@ func (azInstance *azureInstance) closeEndpoints(api *gwacl.ManagementAPI, portRa
 		RoleName:       azInstance.roleName,
 	}
 	for _, portRange := range portRanges {
+		if portRange.Protocol == "icmp" {
+			logger.Warningf("ignoring %v: ICMP cannot be opened or closed with Azure endpoints", portRange)
+			continue
+		}
 		name := fmt.Sprintf("%s%d-%d", portRange.Protocol, portRange.FromPort, portRange.ToPort)
 		for port := portRange.FromPort; port <= portRange.ToPort; port++ {
 			request.InputEndpoints = append(request.InputEndpoints, gwacl.InputEndpoint{
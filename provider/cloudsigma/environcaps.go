@@ -36,6 +36,10 @@ var unsupportedConstraints = []string{
 	constraints.Container,
 	constraints.InstanceType,
 	constraints.Tags,
+	constraints.Zones,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator returns a Validator instance which
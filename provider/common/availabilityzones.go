@@ -6,7 +6,11 @@ package common
 import (
 	"sort"
 
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 )
 
@@ -131,23 +135,77 @@ func AvailabilityZoneAllocations(env ZonedEnviron, group []instance.Id) ([]Avail
 	return zoneInstances, nil
 }
 
+// OrderAvailabilityZones orders the availability zone allocations
+// returned by AvailabilityZoneAllocations according to the
+// availability-zone-policy setting, best zone first. The spread policy
+// keeps the zones in ascending order of population; the pack policy
+// orders them in descending order of population, so that zones are
+// filled one at a time. Zones with the same population remain ordered
+// by name.
+//
+// If zones is not empty, only the allocations for those zones are
+// returned, and it is an error if none of them is available.
+func OrderAvailabilityZones(zoneInstances []AvailabilityZoneInstances, policy string, zones []string) ([]AvailabilityZoneInstances, error) {
+	if len(zones) > 0 {
+		allowed := set.NewStrings(zones...)
+		var filtered []AvailabilityZoneInstances
+		for _, z := range zoneInstances {
+			if allowed.Contains(z.ZoneName) {
+				filtered = append(filtered, z)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, errors.Errorf("none of the availability zones %q is available", zones)
+		}
+		zoneInstances = filtered
+	}
+	ordered := make([]AvailabilityZoneInstances, len(zoneInstances))
+	copy(ordered, zoneInstances)
+	switch policy {
+	case config.SpreadZonePolicy:
+		sort.Sort(byPopulationThenName(ordered))
+	case config.PackZonePolicy:
+		sort.Sort(byDescendingPopulationThenName{ordered})
+	default:
+		return nil, errors.NotValidf("availability zone policy %q", policy)
+	}
+	return ordered, nil
+}
+
+type byDescendingPopulationThenName struct {
+	byPopulationThenName
+}
+
+func (b byDescendingPopulationThenName) Less(i, j int) bool {
+	a := b.byPopulationThenName
+	if len(a[i].Instances) != len(a[j].Instances) {
+		return len(a[i].Instances) > len(a[j].Instances)
+	}
+	return a[i].ZoneName < a[j].ZoneName
+}
+
 var internalAvailabilityZoneAllocations = AvailabilityZoneAllocations
 
 // DistributeInstances is a common function for implement the
 // state.InstanceDistributor policy based on availability zone
-// spread.
+// spread, or packing if the environment's availability-zone-policy
+// asks for it.
 func DistributeInstances(env ZonedEnviron, candidates, group []instance.Id) ([]instance.Id, error) {
 	// Determine the best availability zones for the group.
 	zoneInstances, err := internalAvailabilityZoneAllocations(env, group)
 	if err != nil || len(zoneInstances) == 0 {
 		return nil, err
 	}
+	zoneInstances, err = OrderAvailabilityZones(zoneInstances, env.Config().AvailabilityZonePolicy(), nil)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine which of the candidates are eligible based on whether
 	// they are allocated in one of the best availability zones.
 	var allEligible []string
 	for i := range zoneInstances {
-		if i > 0 && len(zoneInstances[i].Instances) > len(zoneInstances[i-1].Instances) {
+		if i > 0 && len(zoneInstances[i].Instances) != len(zoneInstances[i-1].Instances) {
 			break
 		}
 		for _, id := range zoneInstances[i].Instances {
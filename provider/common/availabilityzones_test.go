@@ -10,6 +10,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider/common"
 	coretesting "github.com/juju/juju/testing"
@@ -45,6 +46,11 @@ func (s *AvailabilityZoneSuite) SetUpSuite(c *gc.C) {
 	}
 }
 
+func (s *AvailabilityZoneSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.env.config = configGetter(c)
+}
+
 func (s *AvailabilityZoneSuite) TestAvailabilityZoneAllocationsAllInstances(c *gc.C) {
 	var called int
 	s.PatchValue(&s.env.instanceAvailabilityZoneNames, func(ids []instance.Id) ([]string, error) {
@@ -254,3 +260,84 @@ func (s *AvailabilityZoneSuite) TestDistributeInstances(c *gc.C) {
 		c.Assert(eligible, jc.SameContents, test.eligible)
 	}
 }
+
+func (s *AvailabilityZoneSuite) TestDistributeInstancesPack(c *gc.C) {
+	cfg, err := s.env.Config().Apply(map[string]interface{}{
+		"availability-zone-policy": "pack",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.env.config = func() *config.Config { return cfg }
+	s.PatchValue(common.InternalAvailabilityZoneAllocations, func(_ common.ZonedEnviron, group []instance.Id) ([]common.AvailabilityZoneInstances, error) {
+		return []common.AvailabilityZoneInstances{{
+			ZoneName:  "az0",
+			Instances: []instance.Id{"i0"},
+		}, {
+			ZoneName:  "az1",
+			Instances: []instance.Id{"i1", "i2"},
+		}}, nil
+	})
+	eligible, err := common.DistributeInstances(&s.env, []instance.Id{"i0", "i2", "i3"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(eligible, jc.SameContents, []instance.Id{"i2"})
+}
+
+var orderZoneInstances = []common.AvailabilityZoneInstances{{
+	ZoneName:  "az2",
+	Instances: nil,
+}, {
+	ZoneName:  "az0",
+	Instances: []instance.Id{"i0"},
+}, {
+	ZoneName:  "az1",
+	Instances: []instance.Id{"i1"},
+}, {
+	ZoneName:  "az3",
+	Instances: []instance.Id{"i2", "i3"},
+}}
+
+func zoneNames(zoneInstances []common.AvailabilityZoneInstances) []string {
+	names := make([]string, len(zoneInstances))
+	for i, z := range zoneInstances {
+		names[i] = z.ZoneName
+	}
+	return names
+}
+
+func (s *AvailabilityZoneSuite) TestOrderAvailabilityZones(c *gc.C) {
+	for i, test := range []struct {
+		policy string
+		zones  []string
+		expect []string
+	}{{
+		policy: config.SpreadZonePolicy,
+		expect: []string{"az2", "az0", "az1", "az3"},
+	}, {
+		policy: config.PackZonePolicy,
+		expect: []string{"az3", "az0", "az1", "az2"},
+	}, {
+		policy: config.SpreadZonePolicy,
+		zones:  []string{"az3", "az1", "az9"},
+		expect: []string{"az1", "az3"},
+	}, {
+		policy: config.PackZonePolicy,
+		zones:  []string{"az2", "az1"},
+		expect: []string{"az1", "az2"},
+	}} {
+		c.Logf("test %d: %s %v", i, test.policy, test.zones)
+		ordered, err := common.OrderAvailabilityZones(orderZoneInstances, test.policy, test.zones)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(zoneNames(ordered), jc.DeepEquals, test.expect)
+	}
+	// The allocations passed in are left alone.
+	c.Check(zoneNames(orderZoneInstances), jc.DeepEquals, []string{"az2", "az0", "az1", "az3"})
+}
+
+func (s *AvailabilityZoneSuite) TestOrderAvailabilityZonesNoneAvailable(c *gc.C) {
+	_, err := common.OrderAvailabilityZones(orderZoneInstances, config.SpreadZonePolicy, []string{"az8", "az9"})
+	c.Assert(err, gc.ErrorMatches, `none of the availability zones \["az8" "az9"\] is available`)
+}
+
+func (s *AvailabilityZoneSuite) TestOrderAvailabilityZonesInvalidPolicy(c *gc.C) {
+	_, err := common.OrderAvailabilityZones(orderZoneInstances, "scatter", nil)
+	c.Assert(err, gc.ErrorMatches, `availability zone policy "scatter" not valid`)
+}
@@ -38,7 +38,7 @@ func destroyInstances(env environs.Environ) error {
 		for i, inst := range instances {
 			ids[i] = inst.Id()
 		}
-		if err := env.StopInstances(ids...); err != nil {
+		if err := StopInstances(env, ids...); err != nil {
 			return err
 		}
 		fallthrough
@@ -0,0 +1,43 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+// StopInstances stops the instances with the specified IDs, in batches
+// if the broker implements environs.InstanceBulkStopper, and with
+// broker.StopInstances otherwise.
+func StopInstances(broker environs.InstanceBroker, ids ...instance.Id) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if stopper, ok := broker.(environs.InstanceBulkStopper); ok {
+		return stopper.BulkStopInstances(ids...)
+	}
+	return errors.Trace(broker.StopInstances(ids...))
+}
+
+// InstanceIdBatches splits ids into batches of at most size IDs each,
+// for providers whose APIs limit the number of instances handled by a
+// single call.
+func InstanceIdBatches(ids []instance.Id, size int) [][]instance.Id {
+	if size <= 0 {
+		size = len(ids)
+	}
+	var batches [][]instance.Id
+	for len(ids) > 0 {
+		n := size
+		if n > len(ids) {
+			n = len(ids)
+		}
+		batches = append(batches, ids[:n])
+		ids = ids[n:]
+	}
+	return batches
+}
@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/testing"
+)
+
+type StopInstancesSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&StopInstancesSuite{})
+
+type mockBulkStopperEnviron struct {
+	mockEnviron
+	bulkStopInstances stopInstancesFunc
+}
+
+func (env *mockBulkStopperEnviron) BulkStopInstances(ids ...instance.Id) error {
+	return env.bulkStopInstances(ids)
+}
+
+func (s *StopInstancesSuite) TestStopInstances(c *gc.C) {
+	var stopped []instance.Id
+	env := &mockEnviron{
+		stopInstances: func(ids []instance.Id) error {
+			stopped = ids
+			return errors.New("nope")
+		},
+	}
+	err := common.StopInstances(env, "i-0", "i-1")
+	c.Assert(err, gc.ErrorMatches, "nope")
+	c.Assert(stopped, jc.DeepEquals, []instance.Id{"i-0", "i-1"})
+}
+
+func (s *StopInstancesSuite) TestStopInstancesNone(c *gc.C) {
+	env := &mockEnviron{
+		stopInstances: func(ids []instance.Id) error {
+			c.Fatalf("StopInstances called")
+			return nil
+		},
+	}
+	err := common.StopInstances(env)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *StopInstancesSuite) TestStopInstancesBulk(c *gc.C) {
+	env := &mockBulkStopperEnviron{
+		mockEnviron: mockEnviron{
+			stopInstances: func(ids []instance.Id) error {
+				c.Fatalf("StopInstances called")
+				return nil
+			},
+		},
+		bulkStopInstances: func(ids []instance.Id) error {
+			return &environs.StopInstancesError{
+				Failed: map[instance.Id]error{
+					"i-2": errors.New("rate limited"),
+					"i-0": errors.New("busy"),
+				},
+				Total: len(ids),
+			}
+		},
+	}
+	err := common.StopInstances(env, "i-0", "i-1", "i-2")
+	c.Assert(err, gc.ErrorMatches, "cannot stop 2 of 3 instances: i-0: busy; i-2: rate limited")
+	stopErr, ok := err.(*environs.StopInstancesError)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(stopErr.Failed, gc.HasLen, 2)
+}
+
+func (s *StopInstancesSuite) TestInstanceIdBatches(c *gc.C) {
+	ids := []instance.Id{"i-0", "i-1", "i-2", "i-3", "i-4"}
+	c.Assert(common.InstanceIdBatches(ids, 2), jc.DeepEquals, [][]instance.Id{
+		{"i-0", "i-1"}, {"i-2", "i-3"}, {"i-4"},
+	})
+	c.Assert(common.InstanceIdBatches(ids, 5), jc.DeepEquals, [][]instance.Id{ids})
+	c.Assert(common.InstanceIdBatches(ids, 0), jc.DeepEquals, [][]instance.Id{ids})
+	c.Assert(common.InstanceIdBatches(nil, 2), gc.HasLen, 0)
+}
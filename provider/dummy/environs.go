@@ -26,7 +26,6 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -48,6 +47,7 @@ import (
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/common"
@@ -283,6 +283,7 @@ type environ struct {
 }
 
 var _ environs.Environ = (*environ)(nil)
+var _ environs.CredentialChecker = (*environ)(nil)
 
 // discardOperations discards all Operations written to it.
 var discardOperations chan<- Operation
@@ -301,7 +302,7 @@ func init() {
 	Reset()
 
 	// parse errors are ignored
-	providerDelay, _ = time.ParseDuration(os.Getenv("JUJU_DUMMY_DELAY"))
+	providerDelay, _ = osenv.JujuDummyDelayVar.Duration()
 }
 
 // Reset resets the entire dummy environment and forgets any registered
@@ -683,6 +684,19 @@ func (e *environ) checkBroken(method string) error {
 	return nil
 }
 
+// CheckCredentials is specified in the environs.CredentialChecker
+// interface. The dummy environ rejects the secret "invalid".
+func (e *environ) CheckCredentials() error {
+	defer delay()
+	if err := e.checkBroken("CheckCredentials"); err != nil {
+		return err
+	}
+	if e.ecfg().secret() == "invalid" {
+		return errors.Unauthorizedf("dummy secret not valid")
+	}
+	return nil
+}
+
 // SupportedArchitectures is specified on the EnvironCapability interface.
 func (*environ) SupportedArchitectures() ([]string, error) {
 	return []string{arch.AMD64, arch.I386, arch.PPC64EL, arch.ARM64}, nil
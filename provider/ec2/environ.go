@@ -83,6 +83,8 @@ var _ environs.NetworkingEnviron = (*environ)(nil)
 var _ simplestreams.HasRegion = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ environs.InstanceTagger = (*environ)(nil)
+var _ environs.CredentialChecker = (*environ)(nil)
 
 type defaultVpc struct {
 	hasDefaultVpc bool
@@ -248,14 +250,19 @@ func (e *environ) SupportsAddressAllocation(_ network.Id) (bool, error) {
 
 var unsupportedConstraints = []string{
 	constraints.Tags,
+	constraints.RootDiskSource,
 }
 
+// supportedVirtTypes holds the virtualisation types used by the EC2
+// instance types.
+var supportedVirtTypes = []string{hvm, paravirtual}
+
 // ConstraintsValidator is defined on the Environs interface.
 func (e *environ) ConstraintsValidator() (constraints.Validator, error) {
 	validator := constraints.NewValidator()
 	validator.RegisterConflicts(
 		[]string{constraints.InstanceType},
-		[]string{constraints.Mem, constraints.CpuCores, constraints.CpuPower})
+		[]string{constraints.Mem, constraints.CpuCores, constraints.CpuPower, constraints.VirtType})
 	validator.RegisterUnsupported(unsupportedConstraints)
 	supportedArches, err := e.SupportedArchitectures()
 	if err != nil {
@@ -267,6 +274,7 @@ func (e *environ) ConstraintsValidator() (constraints.Validator, error) {
 		instTypeNames[i] = itype.Name
 	}
 	validator.RegisterVocabulary(constraints.InstanceType, instTypeNames)
+	validator.RegisterVocabulary(constraints.VirtType, supportedVirtTypes)
 	return validator, nil
 }
 
@@ -317,6 +325,22 @@ func (e *environ) AvailabilityZones() ([]common.AvailabilityZone, error) {
 	return e.availabilityZones, nil
 }
 
+// CheckCredentials is specified in the environs.CredentialChecker
+// interface. It lists the region's availability zones, which any
+// valid credentials may do.
+func (e *environ) CheckCredentials() error {
+	filter := ec2.NewFilter()
+	filter.Add("region-name", e.ecfg().region())
+	_, err := ec2AvailabilityZones(e.ec2(), filter)
+	switch ec2ErrCode(err) {
+	case "":
+		return errors.Trace(err)
+	case "AuthFailure", "InvalidClientTokenId", "SignatureDoesNotMatch", "UnauthorizedOperation":
+		return errors.NewUnauthorized(err, "credentials not valid")
+	}
+	return errors.Annotate(err, "cannot check credentials")
+}
+
 // InstanceAvailabilityZoneNames returns the availability zone names for each
 // of the specified instances.
 func (e *environ) InstanceAvailabilityZoneNames(ids []instance.Id) ([]string, error) {
@@ -469,7 +493,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 
 	// If no availability zone is specified, then automatically spread across
 	// the known zones for optimal spread across the instance distribution
-	// group.
+	// group, or pack into them if the availability-zone-policy says so.
 	if len(availabilityZones) == 0 {
 		var group []instance.Id
 		var err error
@@ -483,6 +507,14 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		if err != nil {
 			return nil, err
 		}
+		var zones []string
+		if args.Constraints.HaveZones() {
+			zones = *args.Constraints.Zones
+		}
+		zoneInstances, err = common.OrderAvailabilityZones(zoneInstances, e.Config().AvailabilityZonePolicy(), zones)
+		if err != nil {
+			return nil, err
+		}
 		for _, z := range zoneInstances {
 			availabilityZones = append(availabilityZones, z.ZoneName)
 		}
@@ -523,12 +555,15 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		return nil, err
 	}
 
+	region := e.ecfg().region()
+	imageId, _ := e.Config().PinnedImageId(region, args.InstanceConfig.Series)
 	spec, err := findInstanceSpec(sources, e.Config().ImageStream(), &instances.InstanceConstraint{
-		Region:      e.ecfg().region(),
+		Region:      region,
 		Series:      args.InstanceConfig.Series,
 		Arches:      arches,
 		Constraints: args.Constraints,
 		Storage:     []string{ssdStorage, ebsStorage},
+		ImageId:     imageId,
 	})
 	if err != nil {
 		return nil, err
@@ -547,10 +582,21 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		return nil, err
 	}
 
-	userData, err := providerinit.ComposeUserData(args.InstanceConfig, nil, AmazonRenderer{})
+	renderer := newStoringRenderer(e.Storage(), args.InstanceConfig.MachineId)
+	userData, err := providerinit.ComposeUserData(args.InstanceConfig, nil, renderer)
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot make user data")
 	}
+	defer func() {
+		if resultErr == nil {
+			return
+		}
+		// Don't leave the secrets of a machine that did not start
+		// in storage.
+		if err := renderer.RemoveUserdata(); err != nil {
+			logger.Errorf("error removing stored user data: %v", err)
+		}
+	}()
 	logger.Debugf("ec2 user data; %d bytes", len(userData))
 	cfg := e.Config()
 	groups, err := e.setUpGroups(args.InstanceConfig.MachineId, cfg.APIPort())
@@ -562,6 +608,13 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	blockDeviceMappings := getBlockDeviceMappings(args.Constraints, args.InstanceConfig.Series)
 	rootDiskSize := uint64(blockDeviceMappings[0].VolumeSize) * 1024
 
+	// The instance-role constraint names the IAM instance profile
+	// the instance is started with.
+	var instanceProfile string
+	if args.Constraints.HasInstanceRole() {
+		instanceProfile = *args.Constraints.InstanceRole
+	}
+
 	for _, availZone := range availabilityZones {
 		instResp, err = runInstances(e.ec2(), &ec2.RunInstances{
 			AvailZone: availZone,
@@ -573,6 +626,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 			InstanceType:        spec.InstanceType.Name,
 			SecurityGroups:      groups,
 			BlockDeviceMappings: blockDeviceMappings,
+			IamInstanceProfile:  instanceProfile,
 		})
 		if isZoneConstrainedError(err) {
 			logger.Infof("%q is constrained, trying another availability zone", availZone)
@@ -633,6 +687,14 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	}, nil
 }
 
+// TagInstance implements environs.InstanceTagger.
+func (e *environ) TagInstance(id instance.Id, tags map[string]string) error {
+	if err := tagResources(e.ec2(), tags, string(id)); err != nil {
+		return errors.Annotate(err, "tagging instance")
+	}
+	return nil
+}
+
 // tagResources calls ec2.CreateTags, tagging each of the specified resources
 // with the given tags. tagResources will retry for a short period of time
 // if it receives a *.NotFound error response from EC2.
@@ -706,10 +768,34 @@ func _runInstances(e *ec2.EC2, ri *ec2.RunInstances) (resp *ec2.RunInstancesResp
 }
 
 func (e *environ) StopInstances(ids ...instance.Id) error {
-	if err := e.terminateInstances(ids); err != nil {
+	return e.BulkStopInstances(ids...)
+}
+
+var _ environs.InstanceBulkStopper = (*environ)(nil)
+
+// BulkStopInstances is specified in the InstanceBulkStopper interface.
+// The instances are terminated in batches of up to
+// maxTerminateInstances.
+func (e *environ) BulkStopInstances(ids ...instance.Id) error {
+	failed := make(map[instance.Id]error)
+	var stopped []instance.Id
+	for _, batch := range common.InstanceIdBatches(ids, maxTerminateInstances) {
+		batchFailed := e.terminateInstances(batch)
+		for _, id := range batch {
+			if err, ok := batchFailed[id]; ok {
+				failed[id] = err
+			} else {
+				stopped = append(stopped, id)
+			}
+		}
+	}
+	if err := common.RemoveStateInstances(e.Storage(), stopped...); err != nil {
 		return errors.Trace(err)
 	}
-	return common.RemoveStateInstances(e.Storage(), ids...)
+	if len(failed) > 0 {
+		return &environs.StopInstancesError{Failed: failed, Total: len(ids)}
+	}
+	return nil
 }
 
 // groupInfoByName returns information on the security group
@@ -1263,7 +1349,14 @@ func (*environ) Provider() environs.EnvironProvider {
 	return &providerInstance
 }
 
-func (e *environ) terminateInstances(ids []instance.Id) error {
+// maxTerminateInstances is the largest number of instances that EC2
+// will terminate in one TerminateInstances call.
+var maxTerminateInstances = 1000
+
+// terminateInstances terminates the instances with the given ids in a
+// single call if it can, and returns the reason each instance that
+// could not be terminated was not terminated.
+func (e *environ) terminateInstances(ids []instance.Id) map[instance.Id]error {
 	if len(ids) == 0 {
 		return nil
 	}
@@ -1276,26 +1369,33 @@ func (e *environ) terminateInstances(ids []instance.Id) error {
 	for a := shortAttempt.Start(); a.Next(); {
 		_, err = ec2inst.TerminateInstances(strs)
 		if err == nil || ec2ErrCode(err) != "InvalidInstanceID.NotFound" {
-			return err
+			break
 		}
 	}
-	if len(ids) == 1 {
-		return err
+	if err == nil {
+		return nil
+	}
+	if len(ids) == 1 || ec2ErrCode(err) != "InvalidInstanceID.NotFound" {
+		failed := make(map[instance.Id]error)
+		for _, id := range ids {
+			failed[id] = err
+		}
+		return failed
 	}
 	// If we get a NotFound error, it means that no instances have been
 	// terminated even if some exist, so try them one by one, ignoring
 	// NotFound errors.
-	var firstErr error
+	failed := make(map[instance.Id]error)
 	for _, id := range ids {
 		_, err = ec2inst.TerminateInstances([]string{string(id)})
 		if ec2ErrCode(err) == "InvalidInstanceID.NotFound" {
 			err = nil
 		}
-		if err != nil && firstErr == nil {
-			firstErr = err
+		if err != nil {
+			failed[id] = err
 		}
 	}
-	return firstErr
+	return failed
 }
 
 func (e *environ) globalGroupName() string {
@@ -10,6 +10,7 @@ import (
 	"gopkg.in/amz.v3/ec2"
 	"gopkg.in/amz.v3/s3"
 
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/imagemetadata"
 	"github.com/juju/juju/environs/jujutest"
@@ -56,6 +57,7 @@ var (
 	RunInstances                = &runInstances
 	BlockDeviceNamer            = blockDeviceNamer
 	GetBlockDeviceMappings      = getBlockDeviceMappings
+	MaxTerminateInstances       = &maxTerminateInstances
 )
 
 // BucketStorage returns a storage instance addressing
@@ -66,6 +68,12 @@ func BucketStorage(b *s3.Bucket) storage.Storage {
 	}
 }
 
+// NewStoringRenderer returns the renderer StartInstance uses for the
+// given machine's userdata.
+func NewStoringRenderer(stor storage.Storage, machineId string) renderers.ProviderRenderer {
+	return newStoringRenderer(stor, machineId)
+}
+
 // DeleteBucket deletes the s3 bucket used by the storage instance.
 func DeleteBucket(s storage.Storage) error {
 	return deleteBucket(s.(*ec2storage))
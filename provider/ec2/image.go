@@ -24,6 +24,16 @@ const defaultCpuPower = 100
 // filterImages returns only that subset of the input (in the same order) that
 // this provider finds suitable.
 func filterImages(images []*imagemetadata.ImageMetadata, ic *instances.InstanceConstraint) []*imagemetadata.ImageMetadata {
+	// A pinned image is used whatever its storage type.
+	if ic != nil && ic.ImageId != "" {
+		var pinned []*imagemetadata.ImageMetadata
+		for _, image := range images {
+			if image.Id == ic.ImageId {
+				pinned = append(pinned, image)
+			}
+		}
+		return pinned
+	}
 	// Gather the images for each available storage type.
 	imagesByStorage := make(map[string][]*imagemetadata.ImageMetadata)
 	for _, image := range images {
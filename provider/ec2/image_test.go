@@ -254,6 +254,47 @@ func (*specSuite) TestFilterImagesReturnsSelectively(c *gc.C) {
 	c.Check(filterImages(input, ic), gc.DeepEquals, expectation)
 }
 
+func (*specSuite) TestFilterImagesPinned(c *gc.C) {
+	ssd := imagemetadata.ImageMetadata{Id: "ssd", Storage: "ssd"}
+	ebs := imagemetadata.ImageMetadata{Id: "pinned", Storage: "ebs"}
+	input := []*imagemetadata.ImageMetadata{&ssd, &ebs}
+	expectation := []*imagemetadata.ImageMetadata{&ebs}
+
+	ic := &instances.InstanceConstraint{Storage: []string{"ssd", "ebs"}, ImageId: "pinned"}
+	c.Check(filterImages(input, ic), gc.DeepEquals, expectation)
+}
+
+func (s *specSuite) TestFindInstanceSpecPinned(c *gc.C) {
+	spec, err := findInstanceSpec(
+		[]simplestreams.DataSource{
+			simplestreams.NewURLDataSource("test", "test:", utils.VerifySSLHostnames)},
+		"released",
+		&instances.InstanceConstraint{
+			Region:      "test",
+			Series:      testing.FakeDefaultSeries,
+			Arches:      both,
+			Constraints: constraints.MustParse("mem=4G root-disk=16384M"),
+			Storage:     []string{ssdStorage, ebsStorage},
+			ImageId:     "ami-00000039",
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(spec.Image.Id, gc.Equals, "ami-00000039")
+}
+
+func (s *specSuite) TestFindInstanceSpecPinnedNotFound(c *gc.C) {
+	_, err := findInstanceSpec(
+		[]simplestreams.DataSource{
+			simplestreams.NewURLDataSource("test", "test:", utils.VerifySSLHostnames)},
+		"released",
+		&instances.InstanceConstraint{
+			Region:  "test",
+			Series:  testing.FakeDefaultSeries,
+			Arches:  both,
+			ImageId: "ami-99999999",
+		})
+	c.Assert(err, gc.ErrorMatches, `image "ami-99999999" pinned for "trusty" in test not found in image metadata`)
+}
+
 func (*specSuite) TestFilterImagesMaintainsOrdering(c *gc.C) {
 	input := []*imagemetadata.ImageMetadata{
 		{Id: "one", Storage: "ebs"},
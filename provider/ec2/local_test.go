@@ -312,6 +312,27 @@ func (t *localServerSuite) TestInstanceStatus(c *gc.C) {
 	c.Assert(inst.Status(), gc.Equals, "terminated")
 }
 
+func (t *localServerSuite) TestBulkStopInstances(c *gc.C) {
+	t.BaseSuite.PatchValue(ec2.MaxTerminateInstances, 2)
+	restoreTimeouts := envtesting.PatchAttemptStrategies(ec2.ShortAttempt)
+	defer restoreTimeouts()
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	inst1, _ := testing.AssertStartInstance(c, env, "1")
+	inst2, _ := testing.AssertStartInstance(c, env, "2")
+	inst3, _ := testing.AssertStartInstance(c, env, "3")
+
+	stopper, ok := env.(environs.InstanceBulkStopper)
+	c.Assert(ok, jc.IsTrue)
+	err = stopper.BulkStopInstances(inst1.Id(), inst2.Id(), "i-unknown", inst3.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	insts, err := env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts, gc.HasLen, 1)
+}
+
 func (t *localServerSuite) TestStartInstanceHardwareCharacteristics(c *gc.C) {
 	env := t.Prepare(c)
 	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
@@ -387,6 +408,27 @@ func (t *localServerSuite) TestGetAvailabilityZones(c *gc.C) {
 	c.Assert(zones[0].Name(), gc.Equals, "whatever")
 }
 
+func (t *localServerSuite) TestCheckCredentials(c *gc.C) {
+	var resultErr error
+	t.PatchValue(ec2.EC2AvailabilityZones, func(e *amzec2.EC2, f *amzec2.Filter) (*amzec2.AvailabilityZonesResp, error) {
+		return &amzec2.AvailabilityZonesResp{}, resultErr
+	})
+	env := t.Prepare(c).(environs.CredentialChecker)
+
+	err := env.CheckCredentials()
+	c.Assert(err, jc.ErrorIsNil)
+
+	resultErr = &amzec2.Error{Code: "AuthFailure", Message: "AWS was not able to validate the provided access credentials"}
+	err = env.CheckCredentials()
+	c.Assert(err, jc.Satisfies, errors.IsUnauthorized)
+	c.Assert(err, gc.ErrorMatches, "credentials not valid: AWS was not able to validate the provided access credentials.*")
+
+	resultErr = &amzec2.Error{Code: "RequestLimitExceeded", Message: "slow down"}
+	err = env.CheckCredentials()
+	c.Assert(err, gc.Not(jc.Satisfies), errors.IsUnauthorized)
+	c.Assert(err, gc.ErrorMatches, "cannot check credentials: slow down.*")
+}
+
 func (t *localServerSuite) TestGetAvailabilityZonesCommon(c *gc.C) {
 	var resultZones []amzec2.AvailabilityZoneInfo
 	t.PatchValue(ec2.EC2AvailabilityZones, func(e *amzec2.EC2, f *amzec2.Filter) (*amzec2.AvailabilityZonesResp, error) {
@@ -687,6 +729,10 @@ func (t *localServerSuite) TestConstraintsValidatorUnsupported(c *gc.C) {
 	unsupported, err := validator.Validate(cons)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(unsupported, gc.DeepEquals, []string{"tags"})
+	cons = constraints.MustParse("virt-type=hvm instance-role=juju root-disk-source=ssd")
+	unsupported, err = validator.Validate(cons)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unsupported, gc.DeepEquals, []string{"root-disk-source"})
 }
 
 func (t *localServerSuite) TestConstraintsValidatorVocab(c *gc.C) {
@@ -699,6 +745,9 @@ func (t *localServerSuite) TestConstraintsValidatorVocab(c *gc.C) {
 	cons = constraints.MustParse("instance-type=foo")
 	_, err = validator.Validate(cons)
 	c.Assert(err, gc.ErrorMatches, "invalid constraint value: instance-type=foo\nvalid values are:.*")
+	cons = constraints.MustParse("virt-type=kvm")
+	_, err = validator.Validate(cons)
+	c.Assert(err, gc.ErrorMatches, "invalid constraint value: virt-type=kvm\nvalid values are:.*")
 }
 
 func (t *localServerSuite) TestConstraintsMerge(c *gc.C) {
@@ -712,6 +761,19 @@ func (t *localServerSuite) TestConstraintsMerge(c *gc.C) {
 	c.Assert(cons, gc.DeepEquals, constraints.MustParse("arch=i386 instance-type=m1.small tags=bar"))
 }
 
+func (t *localServerSuite) TestConstraintsMergeVirtType(c *gc.C) {
+	env := t.Prepare(c)
+	validator, err := env.ConstraintsValidator()
+	c.Assert(err, jc.ErrorIsNil)
+	// An instance type determines the virtualisation type, so it
+	// overrides any virt-type in the fallback constraints.
+	consA := constraints.MustParse("virt-type=pv instance-role=juju")
+	consB := constraints.MustParse("instance-type=m3.medium")
+	cons, err := validator.Merge(consA, consB)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cons, gc.DeepEquals, constraints.MustParse("instance-type=m3.medium instance-role=juju"))
+}
+
 func (t *localServerSuite) TestPrecheckInstanceValidInstanceType(c *gc.C) {
 	env := t.Prepare(c)
 	cons := constraints.MustParse("instance-type=m1.small root-disk=1G")
@@ -1113,6 +1175,39 @@ func (t *localServerSuite) TestInstanceTags(c *gc.C) {
 	})
 }
 
+func (t *localServerSuite) TestTagInstance(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	instances, err := env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+
+	tagger := env.(environs.InstanceTagger)
+	err = tagger.TagInstance(instances[0].Id(), map[string]string{
+		"juju-units-deployed": "mysql/0",
+		"juju-is-state":       "true",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Existing tags are replaced; others are left alone.
+	err = tagger.TagInstance(instances[0].Id(), map[string]string{
+		"juju-units-deployed": "mysql/0 wordpress/0",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	instances, err = env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+	c.Assert(ec2.InstanceEC2(instances[0]).Tags, jc.SameContents, []amzec2.Tag{
+		{"Name", "juju-sample-machine-0"},
+		{"juju-env-uuid", coretesting.EnvironmentTag.Id()},
+		{"juju-is-state", "true"},
+		{"juju-units-deployed", "mysql/0 wordpress/0"},
+	})
+}
+
 func (t *localServerSuite) TestRootDiskTags(c *gc.C) {
 	env := t.Prepare(c)
 	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
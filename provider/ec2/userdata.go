@@ -5,15 +5,28 @@
 package ec2
 
 import (
+	"time"
+
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"github.com/juju/utils"
 	jujuos "github.com/juju/utils/os"
 
 	"github.com/juju/juju/cloudconfig/providerinit/renderers"
+	"github.com/juju/juju/environs/storage"
 )
 
 type AmazonRenderer struct{}
 
+// userdataSizeLimit is the most userdata EC2 accepts, before it is
+// base64-encoded.
+const userdataSizeLimit = 16 * 1024
+
+// UserdataSizeLimit implements renderers.SizeLimiter.
+func (AmazonRenderer) UserdataSizeLimit(jujuos.OSType) int {
+	return userdataSizeLimit
+}
+
 func (AmazonRenderer) EncodeUserdata(udata []byte, os jujuos.OSType) ([]byte, error) {
 	switch os {
 	case jujuos.Ubuntu, jujuos.CentOS:
@@ -24,3 +37,33 @@ func (AmazonRenderer) EncodeUserdata(udata []byte, os jujuos.OSType) ([]byte, er
 		return nil, errors.Errorf("Cannot encode userdata for OS: %s", os.String())
 	}
 }
+
+// userdataURLExpiry is how long the URL of stored userdata is valid.
+// The machine fetches its userdata once, when it first boots; after
+// that the URL must not give away the secrets the userdata holds.
+const userdataURLExpiry = time.Hour
+
+// storingRenderer is an AmazonRenderer that puts userdata too large
+// for EC2 in the environment's storage, from where the machine fetches
+// it with a short-lived signed URL.
+type storingRenderer struct {
+	AmazonRenderer
+	renderers.StorageUserdataStore
+}
+
+// newStoringRenderer returns the renderer for the userdata of the
+// given machine. Its stored userdata is removed if the machine cannot
+// be started, replaced when the machine is started again, and removed
+// with the rest of the storage when the environment is destroyed.
+func newStoringRenderer(stor storage.Storage, machineId string) storingRenderer {
+	store := renderers.StorageUserdataStore{
+		Storage: stor,
+		Name:    "userdata/" + names.NewMachineTag(machineId).String(),
+	}
+	if stor, ok := stor.(*ec2storage); ok {
+		store.URL = func(name string) (string, error) {
+			return stor.bucket.SignedURL(name, userdataURLExpiry)
+		}
+	}
+	return storingRenderer{StorageUserdataStore: store}
+}
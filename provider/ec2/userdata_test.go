@@ -11,6 +11,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/cloudconfig/providerinit/renderers"
+	"github.com/juju/juju/environs/filestorage"
 	"github.com/juju/juju/provider/ec2"
 	"github.com/juju/juju/testing"
 )
@@ -51,3 +52,26 @@ func (s *UserdataSuite) TestAmazonUnknownOS(c *gc.C) {
 	c.Assert(result, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, "Cannot encode userdata for OS: Arch")
 }
+
+func (s *UserdataSuite) TestAmazonSizeLimit(c *gc.C) {
+	var renderer renderers.ProviderRenderer = ec2.AmazonRenderer{}
+	limiter, ok := renderer.(renderers.SizeLimiter)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(limiter.UserdataSizeLimit(os.Ubuntu), gc.Equals, 16*1024)
+}
+
+func (s *UserdataSuite) TestStoringRenderer(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	var renderer renderers.ProviderRenderer = ec2.NewStoringRenderer(stor, "3")
+	_, ok := renderer.(renderers.SizeLimiter)
+	c.Assert(ok, jc.IsTrue)
+	store, ok := renderer.(renderers.UserdataStore)
+	c.Assert(ok, jc.IsTrue)
+
+	url, err := store.StoreUserdata([]byte("#cloud-config\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	expected, err := stor.URL("userdata/machine-3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(url, gc.Equals, expected)
+}
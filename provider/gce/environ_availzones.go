@@ -84,7 +84,9 @@ var availabilityZoneAllocations = common.AvailabilityZoneAllocations
 // provided then only that one is returned. Otherwise the environment is
 // queried for available zones. In that case, the resulting list is
 // roughly ordered such that the environment's instances are spread
-// evenly across the region.
+// evenly across the region, or packed into as few zones as possible,
+// according to the availability-zone-policy, and limited to any zones
+// constraint.
 func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([]string, error) {
 	if args.Placement != "" {
 		// args.Placement will always be a zone name or empty.
@@ -98,7 +100,7 @@ func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([
 
 	// If no availability zone is specified, then automatically spread across
 	// the known zones for optimal spread across the instance distribution
-	// group.
+	// group, or pack into them if the availability-zone-policy says so.
 	var group []instance.Id
 	var err error
 	if args.DistributionGroup != nil {
@@ -111,6 +113,14 @@ func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	var zones []string
+	if args.Constraints.HaveZones() {
+		zones = *args.Constraints.Zones
+	}
+	zoneInstances, err = common.OrderAvailabilityZones(zoneInstances, env.Config().AvailabilityZonePolicy(), zones)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	logger.Infof("found %d zones: %v", len(zoneInstances), zoneInstances)
 
 	var zoneNames []string
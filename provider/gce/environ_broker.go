@@ -117,11 +117,14 @@ func (env *environ) finishInstanceConfig(args environs.StartInstanceParams, spec
 func (env *environ) buildInstanceSpec(args environs.StartInstanceParams) (*instances.InstanceSpec, error) {
 	arches := args.Tools.Arches()
 	series := args.Tools.OneSeries()
+	region := env.ecfg.region()
+	imageId, _ := env.Config().PinnedImageId(region, series)
 	spec, err := findInstanceSpec(env, env.Config().ImageStream(), &instances.InstanceConstraint{
-		Region:      env.ecfg.region(),
+		Region:      region,
 		Series:      series,
 		Arches:      arches,
 		Constraints: args.Constraints,
+		ImageId:     imageId,
 	})
 	return spec, errors.Trace(err)
 }
@@ -68,6 +68,8 @@ var unsupportedConstraints = []string{
 	constraints.Tags,
 	// TODO(dimitern: Replace Networks with Spaces in a follow-up.
 	constraints.Networks,
+	constraints.RootDiskSource,
+	constraints.InstanceRole,
 }
 
 // instanceTypeConstraints defines the fields defined on each of the
@@ -78,6 +80,7 @@ var instanceTypeConstraints = []string{
 	constraints.CpuPower,
 	constraints.Mem,
 	constraints.Container, // VirtType
+	constraints.VirtType,
 }
 
 // ConstraintsValidator returns a Validator value which is used to
@@ -112,6 +115,7 @@ func (env *environ) ConstraintsValidator() (constraints.Validator, error) {
 	validator.RegisterVocabulary(constraints.InstanceType, instTypeNames)
 
 	validator.RegisterVocabulary(constraints.Container, []string{vtype})
+	validator.RegisterVocabulary(constraints.VirtType, []string{vtype})
 
 	return validator, nil
 }
@@ -202,6 +202,29 @@ func (s *environPolSuite) TestConstraintsValidatorVocabContainer(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, "invalid constraint value: container=lxc\nvalid values are:.*")
 }
 
+func (s *environPolSuite) TestConstraintsValidatorVocabVirtType(c *gc.C) {
+	validator, err := s.Env.ConstraintsValidator()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cons := constraints.MustParse("virt-type=hvm")
+	_, err = validator.Validate(cons)
+
+	c.Check(err, gc.ErrorMatches, "invalid constraint value: virt-type=hvm\nvalid values are:.*")
+}
+
+func (s *environPolSuite) TestConstraintsValidatorUnsupportedNewKeys(c *gc.C) {
+	s.FakeCommon.Arches = []string{arch.AMD64}
+
+	validator, err := s.Env.ConstraintsValidator()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cons := constraints.MustParse("virt-type=kvm root-disk-source=ssd instance-role=juju")
+	unsupported, err := validator.Validate(cons)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(unsupported, jc.SameContents, []string{"root-disk-source", "instance-role"})
+}
+
 func (s *environPolSuite) TestConstraintsValidatorConflicts(c *gc.C) {
 	s.FakeCommon.Arches = []string{arch.AMD64}
 
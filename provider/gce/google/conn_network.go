@@ -23,6 +23,10 @@ func (gce Connection) Ports(fwname string) ([]network.PortRange, error) {
 
 	var ports []network.PortRange
 	for _, allowed := range firewall.Allowed {
+		if allowed.IPProtocol == "icmp" {
+			ports = append(ports, network.ICMPPortRange)
+			continue
+		}
 		for _, portRangeStr := range allowed.Ports {
 			portRange, err := network.ParsePortRange(portRangeStr)
 			if err != nil {
@@ -35,6 +35,28 @@ func (s *connSuite) TestConnectionPorts(c *gc.C) {
 	}})
 }
 
+func (s *connSuite) TestConnectionPortsICMP(c *gc.C) {
+	s.FakeConn.Firewall = &compute.Firewall{
+		Name:         "spam",
+		TargetTags:   []string{"spam"},
+		SourceRanges: []string{"0.0.0.0/0"},
+		Allowed: []*compute.FirewallAllowed{{
+			IPProtocol: "icmp",
+		}, {
+			IPProtocol: "tcp",
+			Ports:      []string{"80"},
+		}},
+	}
+
+	ports, err := s.Conn.Ports("spam")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(ports, jc.DeepEquals, []network.PortRange{
+		network.ICMPPortRange,
+		{FromPort: 80, ToPort: 80, Protocol: "tcp"},
+	})
+}
+
 func (s *connSuite) TestConnectionPortsAPI(c *gc.C) {
 	s.FakeConn.Firewall = &compute.Firewall{
 		Name:         "spam",
@@ -71,7 +71,10 @@ func firewallSpec(name string, ps network.PortSet) *compute.Firewall {
 	for _, protocol := range ps.Protocols() {
 		allowed := compute.FirewallAllowed{
 			IPProtocol: protocol,
-			Ports:      ps.PortStrings(protocol),
+		}
+		// GCE rejects ports for ICMP, which has none.
+		if protocol != "icmp" {
+			allowed.Ports = ps.PortStrings(protocol)
 		}
 		firewall.Allowed = append(firewall.Allowed, &allowed)
 	}
@@ -61,10 +61,13 @@ func (s *networkSuite) TestFirewallSpec(c *gc.C) {
 		network.MustParsePortRange("80-81/tcp"),
 		network.MustParsePortRange("8888/tcp"),
 		network.MustParsePortRange("1234/udp"),
+		network.ICMPPortRange,
 	)
 	fw := google.FirewallSpec("spam", ports)
 
 	allowed := []*compute.FirewallAllowed{{
+		IPProtocol: "icmp",
+	}, {
 		IPProtocol: "tcp",
 		Ports:      []string{"80", "81", "8888"},
 	}, {
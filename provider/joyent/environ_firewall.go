@@ -39,6 +39,20 @@ func createFirewallRuleAll(envName string, portRange network.PortRange) string {
 	return fmt.Sprintf(firewallRuleAll, envName, strings.ToLower(portRange.Protocol), portList)
 }
 
+// withoutICMP returns the given port ranges without any ICMP ranges,
+// which the firewall rules created here cannot express.
+func withoutICMP(ports []network.PortRange) []network.PortRange {
+	var result []network.PortRange
+	for _, p := range ports {
+		if p.Protocol == "icmp" {
+			logger.Warningf("ignoring %v: ICMP is not supported by the joyent firewall", p)
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
 // Helper method to check if a firewall rule string already exist
 func ruleExists(rules []cloudapi.FirewallRule, rule string) (bool, string) {
 	for _, r := range rules {
@@ -96,7 +110,7 @@ func (env *joyentEnviron) OpenPorts(ports []network.PortRange) error {
 		return fmt.Errorf("cannot get firewall rules: %v", err)
 	}
 
-	for _, p := range ports {
+	for _, p := range withoutICMP(ports) {
 		rule := createFirewallRuleAll(env.Config().Name(), p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := env.compute.cloudapi.EnableFirewallRule(id)
@@ -129,7 +143,7 @@ func (env *joyentEnviron) ClosePorts(ports []network.PortRange) error {
 		return fmt.Errorf("cannot get firewall rules: %v", err)
 	}
 
-	for _, p := range ports {
+	for _, p := range withoutICMP(ports) {
 		rule := createFirewallRuleAll(env.Config().Name(), p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := env.compute.cloudapi.DisableFirewallRule(id)
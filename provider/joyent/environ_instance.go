@@ -14,6 +14,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	"github.com/juju/utils/arch"
+	jujuseries "github.com/juju/utils/series"
 
 	"github.com/juju/juju/cloudconfig/cloudinit"
 	"github.com/juju/juju/cloudconfig/instancecfg"
@@ -61,6 +62,10 @@ func (env *joyentEnviron) machineFullName(machineId string) string {
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.Tags,
+	constraints.Zones,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -97,11 +102,14 @@ func (env *joyentEnviron) StartInstance(args environs.StartInstanceParams) (*env
 
 	series := args.Tools.OneSeries()
 	arches := args.Tools.Arches()
+	region := env.Ecfg().Region()
+	imageId, _ := env.Config().PinnedImageId(region, series)
 	spec, err := env.FindInstanceSpec(&instances.InstanceConstraint{
-		Region:      env.Ecfg().Region(),
+		Region:      region,
 		Series:      series,
 		Arches:      arches,
 		Constraints: args.Constraints,
+		ImageId:     imageId,
 	})
 	if err != nil {
 		return nil, err
@@ -143,13 +151,17 @@ func (env *joyentEnviron) StartInstance(args environs.StartInstanceParams) (*env
 		return nil, errors.Annotate(err, "cannot make user data")
 	}
 	logger.Debugf("joyent user data: %d bytes", len(userData))
+	operatingSystem, err := jujuseries.GetOSFromSeries(series)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	var machine *cloudapi.Machine
 	machine, err = env.compute.cloudapi.CreateMachine(cloudapi.CreateMachineOpts{
 		//Name:	 env.machineFullName(machineConf.MachineId),
 		Package:  spec.InstanceType.Name,
 		Image:    spec.Image.Id,
-		Metadata: map[string]string{"metadata.cloud-init:user-data": string(userData)},
+		Metadata: userdataMetadata(userData, operatingSystem),
 		Tags:     map[string]string{"tag.group": "juju", "tag.env": env.Config().Name()},
 	})
 	if err != nil {
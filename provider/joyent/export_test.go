@@ -227,3 +227,4 @@ var GetPorts = getPorts
 var CreateFirewallRuleAll = createFirewallRuleAll
 
 var CreateFirewallRuleVm = createFirewallRuleVm
+var UserdataMetadata = userdataMetadata
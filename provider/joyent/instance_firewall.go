@@ -43,7 +43,7 @@ func (inst *joyentInstance) OpenPorts(machineId string, ports []network.PortRang
 	}
 
 	machineId = string(inst.Id())
-	for _, p := range ports {
+	for _, p := range withoutICMP(ports) {
 		rule := createFirewallRuleVm(inst.env.Config().Name(), machineId, p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := inst.env.compute.cloudapi.EnableFirewallRule(id)
@@ -77,7 +77,7 @@ func (inst *joyentInstance) ClosePorts(machineId string, ports []network.PortRan
 	}
 
 	machineId = string(inst.Id())
-	for _, p := range ports {
+	for _, p := range withoutICMP(ports) {
 		rule := createFirewallRuleVm(inst.env.Config().Name(), machineId, p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := inst.env.compute.cloudapi.DisableFirewallRule(id)
@@ -8,6 +8,18 @@ import (
 	"github.com/juju/errors"
 
 	jujuos "github.com/juju/utils/os"
+
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
+)
+
+const (
+	// cloudInitUserdataKey is the machine metadata key cloudinit
+	// reads its userdata from.
+	cloudInitUserdataKey = "metadata.cloud-init:user-data"
+
+	// userScriptKey is the machine metadata key of the script that
+	// Joyent images run at boot, whether or not they have cloudinit.
+	userScriptKey = "metadata.user-script"
 )
 
 type JoyentRenderer struct{}
@@ -20,3 +32,23 @@ func (JoyentRenderer) EncodeUserdata(udata []byte, os jujuos.OSType) ([]byte, er
 		return nil, errors.Errorf("Cannot encode userdata for OS: %s", os.String())
 	}
 }
+
+// UserdataFormat implements renderers.FormatRenderer. Joyent's CentOS
+// images do not ship cloudinit, so their userdata is rendered as a
+// script to be run as the machine's user-script.
+func (JoyentRenderer) UserdataFormat(os jujuos.OSType) renderers.Format {
+	if os == jujuos.CentOS {
+		return renderers.ScriptFormat
+	}
+	return renderers.DefaultFormat(os)
+}
+
+// userdataMetadata returns the machine metadata that passes the
+// userdata to a machine running the OS.
+func userdataMetadata(udata []byte, os jujuos.OSType) map[string]string {
+	key := cloudInitUserdataKey
+	if renderers.UserdataFormat(JoyentRenderer{}, os) == renderers.ScriptFormat {
+		key = userScriptKey
+	}
+	return map[string]string{key: string(udata)}
+}
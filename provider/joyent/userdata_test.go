@@ -8,6 +8,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/cloudconfig/providerinit/renderers"
 	"github.com/juju/juju/provider/joyent"
 	"github.com/juju/juju/testing"
 	"github.com/juju/utils/os"
@@ -38,3 +39,19 @@ func (s *UserdataSuite) TestJoyentUnknownOS(c *gc.C) {
 	c.Assert(result, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, "Cannot encode userdata for OS: Windows")
 }
+
+func (s *UserdataSuite) TestJoyentUserdataFormat(c *gc.C) {
+	var renderer renderers.ProviderRenderer = joyent.JoyentRenderer{}
+	c.Assert(renderers.UserdataFormat(renderer, os.Ubuntu), gc.Equals, renderers.CloudConfigFormat)
+	c.Assert(renderers.UserdataFormat(renderer, os.CentOS), gc.Equals, renderers.ScriptFormat)
+}
+
+func (s *UserdataSuite) TestJoyentUserdataMetadata(c *gc.C) {
+	data := []byte("test")
+	c.Assert(joyent.UserdataMetadata(data, os.Ubuntu), jc.DeepEquals, map[string]string{
+		"metadata.cloud-init:user-data": "test",
+	})
+	c.Assert(joyent.UserdataMetadata(data, os.CentOS), jc.DeepEquals, map[string]string{
+		"metadata.user-script": "test",
+	})
+}
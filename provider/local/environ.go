@@ -351,6 +351,10 @@ var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
 	constraints.Tags,
+	constraints.Zones,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -768,6 +768,9 @@ func (environ *maasEnviron) startNode(node gomaasapi.MAASObject, series string,
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -877,7 +880,7 @@ func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 
 	// If no placement is specified, then automatically spread across
 	// the known zones for optimal spread across the instance distribution
-	// group.
+	// group, or pack into them if the availability-zone-policy says so.
 	if args.Placement == "" {
 		var group []instance.Id
 		var err error
@@ -887,13 +890,26 @@ func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 				return nil, errors.Annotate(err, "cannot get distribution group")
 			}
 		}
+		var zones []string
+		if args.Constraints.HaveZones() {
+			zones = *args.Constraints.Zones
+		}
 		zoneInstances, err := availabilityZoneAllocations(environ, group)
 		if errors.IsNotImplemented(err) {
 			// Availability zones are an extension, so we may get a
-			// not implemented error; ignore these.
+			// not implemented error; ignore these, unless zones
+			// were asked for.
+			if len(zones) > 0 {
+				return nil, errors.NotSupportedf("zones constraint without availability zones")
+			}
 		} else if err != nil {
 			return nil, errors.Annotate(err, "cannot get availability zone allocations")
-		} else if len(zoneInstances) > 0 {
+		} else {
+			policy := environ.Config().AvailabilityZonePolicy()
+			zoneInstances, err = common.OrderAvailabilityZones(zoneInstances, policy, zones)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
 			for _, z := range zoneInstances {
 				availabilityZones = append(availabilityZones, z.ZoneName)
 			}
@@ -1010,10 +1026,14 @@ func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 	if err != nil {
 		return nil, err
 	}
-	if len(resultVolumes) != len(requestedVolumes) {
+	if len(resultVolumes) == 0 && len(requestedVolumes) > 0 {
 		err = errors.New("the version of MAAS being used does not support Juju storage")
 		return nil, err
 	}
+	if len(resultVolumes) != len(requestedVolumes) {
+		err = errors.Errorf("no disks allocated for volumes %v", missingVolumes(requestedVolumes, resultVolumes))
+		return nil, err
+	}
 
 	return &environs.StartInstanceResult{
 		Instance:          inst,
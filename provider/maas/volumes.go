@@ -16,7 +16,6 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
-	"github.com/juju/juju/provider/common"
 	"github.com/juju/juju/storage"
 )
 
@@ -84,6 +83,13 @@ func newStorageConfig(attrs map[string]interface{}) (*storageConfig, error) {
 			tags = append(tags, f)
 		}
 	}
+	// Tags are passed to MAAS in the storage constraint, where
+	// these characters have special meaning.
+	for _, tag := range tags {
+		if strings.ContainsAny(tag, ",:()") {
+			return nil, errors.Errorf("tags may not contain commas, colons or parentheses: %q", tag)
+		}
+	}
 	return &storageConfig{tags: tags}, nil
 }
 
@@ -125,10 +131,11 @@ type volumeInfo struct {
 	tags     []string
 }
 
-// mibToGB converts the value in MiB to GB.
+// mibToGB converts the value in MiB to GB, rounding up so that MAAS
+// does not choose a disk smaller than the size requested.
 // Juju works in MiB, MAAS expects GB.
 func mibToGb(m uint64) uint64 {
-	return common.MiBToGiB(m) * (humanize.GiByte / humanize.GByte)
+	return (m*humanize.MiByte + humanize.GByte - 1) / humanize.GByte
 }
 
 // buildMAASVolumeParameters creates the MAAS volume information to include
@@ -158,6 +165,22 @@ func buildMAASVolumeParameters(args []storage.VolumeParams, cons constraints.Val
 	return volumes, nil
 }
 
+// missingVolumes returns the ids of the requested volumes that are
+// not among the volumes bound to a node's disks.
+func missingVolumes(requested []names.VolumeTag, bound []storage.Volume) []string {
+	found := set.NewStrings()
+	for _, v := range bound {
+		found.Add(v.Tag.Id())
+	}
+	var missing []string
+	for _, tag := range requested {
+		if !found.Contains(tag.Id()) {
+			missing = append(missing, tag.Id())
+		}
+	}
+	return missing
+}
+
 // volumes creates the storage volumes and attachments
 // corresponding to the volume info associated with a MAAS node.
 func (mi *maasInstance) volumes(
@@ -32,7 +32,7 @@ func (s *volumeSuite) TestBuildMAASVolumeParametersJustRootDisk(c *gc.C) {
 	vInfo, err := buildMAASVolumeParameters(nil, cons)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(vInfo, jc.DeepEquals, []volumeInfo{
-		{"root", 20, nil},
+		{"root", 21, nil},
 	})
 }
 
@@ -43,7 +43,7 @@ func (s *volumeSuite) TestBuildMAASVolumeParametersNoTags(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(vInfo, jc.DeepEquals, []volumeInfo{
 		{"root", 0, nil}, //root disk
-		{"1", 1954, nil},
+		{"1", 2098, nil},
 	})
 }
 
@@ -56,8 +56,8 @@ func (s *volumeSuite) TestBuildMAASVolumeParametersWithRootDisk(c *gc.C) {
 	}, cons)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(vInfo, jc.DeepEquals, []volumeInfo{
-		{"root", 20, nil}, //root disk
-		{"1", 1954, nil},
+		{"root", 21, nil}, //root disk
+		{"1", 2098, nil},
 	})
 }
 
@@ -68,10 +68,43 @@ func (s *volumeSuite) TestBuildMAASVolumeParametersWithTags(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(vInfo, jc.DeepEquals, []volumeInfo{
 		{"root", 0, nil}, //root disk
-		{"1", 1954, []string{"tag1", "tag2"}},
+		{"1", 2098, []string{"tag1", "tag2"}},
 	})
 }
 
+func (s *volumeSuite) TestBuildMAASVolumeParametersRoundsUp(c *gc.C) {
+	// 32GiB is a little over 34GB, so a 34GB disk will not do.
+	vInfo, err := buildMAASVolumeParameters([]storage.VolumeParams{
+		{Tag: names.NewVolumeTag("1"), Size: 32 * 1024},
+		{Tag: names.NewVolumeTag("2"), Size: 953},
+		{Tag: names.NewVolumeTag("3"), Size: 954},
+	}, constraints.Value{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vInfo, jc.DeepEquals, []volumeInfo{
+		{"root", 0, nil},
+		{"1", 35, nil},
+		{"2", 1, nil},
+		{"3", 2, nil},
+	})
+}
+
+func (s *volumeSuite) TestBuildMAASVolumeParametersInvalidTags(c *gc.C) {
+	for _, tags := range []interface{}{"ssd,a:b", []string{"ssd", "a(b)"}} {
+		_, err := buildMAASVolumeParameters([]storage.VolumeParams{
+			{Tag: names.NewVolumeTag("1"), Size: 1024, Attributes: map[string]interface{}{"tags": tags}},
+		}, constraints.Value{})
+		c.Check(err, gc.ErrorMatches, `tags may not contain commas, colons or parentheses: ".*"`)
+	}
+}
+
+func (s *volumeSuite) TestMissingVolumes(c *gc.C) {
+	missing := missingVolumes(
+		[]names.VolumeTag{names.NewVolumeTag("1"), names.NewVolumeTag("2"), names.NewVolumeTag("3")},
+		[]storage.Volume{{Tag: names.NewVolumeTag("2")}},
+	)
+	c.Assert(missing, jc.DeepEquals, []string{"1", "3"})
+}
+
 func (s *volumeSuite) TestInstanceVolumes(c *gc.C) {
 	obj := s.testMAASObject.TestServer.NewNode(validVolumeJson)
 	instance := maasInstance{&obj}
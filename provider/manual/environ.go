@@ -13,6 +13,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/names"
 	"github.com/juju/utils"
 	"github.com/juju/utils/arch"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/utils/ssh"
 	"github.com/juju/juju/worker/localstorage"
 	"github.com/juju/juju/worker/terminationworker"
@@ -280,12 +282,22 @@ var runSSHCommand = func(host string, command []string, stdin string) (stdout st
 }
 
 func (e *manualEnviron) Destroy() error {
+	// If the machine agent is not running, its services and the
+	// database's are removed here instead.
+	agentService := "jujud-" + names.NewMachineTag(agent.BootstrapMachineId).String()
+	removeServices, err := service.RemoveServicesScript(
+		config.PreferredSeries(e.Config()),
+		agentService,
+		mongo.ServiceName(""),
+	)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	script := `
 set -x
 touch %s
 pkill -%d jujud && exit
-stop %s
-rm -f /etc/init/juju*
+%s
 rm -f /etc/rsyslog.d/*juju*
 rm -fr %s %s
 exit 0
@@ -297,11 +309,11 @@ exit 0
 			agent.UninstallAgentFile,
 		)),
 		terminationworker.TerminationSignal,
-		mongo.ServiceName(""),
+		removeServices,
 		utils.ShQuote(agent.DefaultPaths.DataDir),
 		utils.ShQuote(agent.DefaultPaths.LogDir),
 	)
-	_, err := runSSHCommand(
+	_, err = runSSHCommand(
 		"ubuntu@"+e.envConfig().bootstrapHost(),
 		[]string{"sudo", "/bin/bash"}, script,
 	)
@@ -316,6 +328,10 @@ var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
 	constraints.Tags,
+	constraints.Zones,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
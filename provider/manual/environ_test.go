@@ -12,9 +12,11 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/storage"
 	envtesting "github.com/juju/juju/environs/testing"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/service"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -83,6 +85,10 @@ func (s *environSuite) TestInstances(c *gc.C) {
 }
 
 func (s *environSuite) TestDestroy(c *gc.C) {
+	removeServices, err := service.RemoveServicesScript(
+		config.PreferredSeries(s.env.Config()), "jujud-machine-0", "juju-db",
+	)
+	c.Assert(err, jc.ErrorIsNil)
 	var resultStderr string
 	var resultErr error
 	runSSHCommandTesting := func(host string, command []string, stdin string) (string, error) {
@@ -92,8 +98,7 @@ func (s *environSuite) TestDestroy(c *gc.C) {
 set -x
 touch '/var/lib/juju/uninstall-agent'
 pkill -6 jujud && exit
-stop juju-db
-rm -f /etc/init/juju*
+`+removeServices+`
 rm -f /etc/rsyslog.d/*juju*
 rm -fr '/var/lib/juju' '/var/log/juju'
 exit 0
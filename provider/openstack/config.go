@@ -79,7 +79,7 @@ var configSchema = environschema.Fields{
 		Type:        environschema.Tbool,
 	},
 	"network": {
-		Description: "The network label or UUID to bring machines up on when multiple networks exist.",
+		Description: "The network label or UUID to bring machines up on when multiple networks exist, unless the networks constraint selects others.",
 		Type:        environschema.Tstring,
 	},
 }
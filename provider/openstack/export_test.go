@@ -321,11 +321,11 @@ func SetUseFloatingIP(e environs.Environ, val bool) {
 }
 
 func SetUpGlobalGroup(e environs.Environ, name string, apiPort int) (nova.SecurityGroup, error) {
-	return e.(*environ).setUpGlobalGroup(name, apiPort)
+	return (&novaFirewaller{environ: e.(*environ)}).setUpGlobalGroup(name, apiPort)
 }
 
 func EnsureGroup(e environs.Environ, name string, rules []nova.RuleInfo) (nova.SecurityGroup, error) {
-	return e.(*environ).ensureGroup(name, rules)
+	return (&novaFirewaller{environ: e.(*environ)}).ensureGroup(name, rules)
 }
 
 // ImageMetadataStorage returns a Storage object pointing where the goose
@@ -371,3 +371,20 @@ var RuleMatchesPortRange = ruleMatchesPortRange
 
 var MakeServiceURL = &makeServiceURL
 var ProviderInstance = providerInstance
+
+// NewNeutronFirewaller returns a firewaller that sends its Neutron
+// requests to the given sender. It has no environ, so only methods
+// that do not use the environment's config may be called.
+func NewNeutronFirewaller(sender neutronRequestSender) *neutronFirewaller {
+	return &neutronFirewaller{neutron: newNeutronClient(sender)}
+}
+
+// SecurityGroups returns the security groups with the given ids and
+// names, given in pairs.
+func SecurityGroups(idsAndNames ...string) []securityGroup {
+	groups := make([]securityGroup, len(idsAndNames)/2)
+	for i := range groups {
+		groups[i] = securityGroup{Id: idsAndNames[2*i], Name: idsAndNames[2*i+1]}
+	}
+	return groups
+}
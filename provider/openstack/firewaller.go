@@ -0,0 +1,712 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	gooseerrors "gopkg.in/goose.v1/errors"
+	"gopkg.in/goose.v1/nova"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+// firewaller manages the security groups that control access to an
+// environment's instances, and the ports through which instances are
+// attached to networks.
+type firewaller interface {
+	// SetUpGroups creates the security groups for a new machine, and
+	// returns them.
+	SetUpGroups(machineId string, apiPort int) ([]securityGroup, error)
+
+	// InstanceNetworks returns the networks and security groups with
+	// which to start the named machine on the given networks.
+	InstanceNetworks(machineName string, networkIds []string, groups []securityGroup) ([]nova.ServerNetworks, []nova.SecurityGroupName, error)
+
+	// DeletePorts deletes any ports created by InstanceNetworks, for
+	// when the instance could not be started.
+	DeletePorts(networks []nova.ServerNetworks) error
+
+	// DeleteInstancePorts deletes the ports created for the given
+	// instances, which have been stopped.
+	DeleteInstancePorts(ids []instance.Id) error
+
+	// OpenPorts opens the given port ranges in the named group.
+	OpenPorts(groupName string, ports []network.PortRange) error
+
+	// ClosePorts closes the given port ranges in the named group.
+	ClosePorts(groupName string, ports []network.PortRange) error
+
+	// Ports returns the port ranges open in the named group.
+	Ports(groupName string) ([]network.PortRange, error)
+
+	// DeleteGroups deletes the named security groups.
+	DeleteGroups(names ...string) error
+
+	// DeleteAll deletes all the environment's ports and security
+	// groups, when the environment is destroyed.
+	DeleteAll() error
+}
+
+// securityGroup identifies a security group.
+type securityGroup struct {
+	Id   string
+	Name string
+}
+
+// firewaller returns the firewaller for the environment. Neutron is
+// used if the cloud's service catalog includes the network service;
+// otherwise security groups are managed with nova.
+func (e *environ) firewaller() (firewaller, error) {
+	if !e.client.IsAuthenticated() {
+		if err := authenticateClient(e); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := makeServiceURL(e.client, neutronServiceType, nil); err == nil {
+		return &neutronFirewaller{environ: e, neutron: newNeutronClient(e.client)}, nil
+	}
+	return &novaFirewaller{environ: e}, nil
+}
+
+// securityGroupDeleter is implemented by both nova and Neutron clients.
+type securityGroupDeleter interface {
+	DeleteSecurityGroup(id string) error
+}
+
+// deleteSecurityGroup attempts to delete the security group. Should it fail,
+// the deletion is retried due to timing issues in openstack. A security group
+// cannot be deleted while it is in use. Theoretically we terminate all the
+// instances before we attempt to delete the associated security groups, but
+// in practice nova hasn't always finished with the instance before it
+// returns, so there is a race condition where we think the instance is
+// terminated and hence attempt to delete the security groups but nova still
+// has it around internally. To attempt to catch this timing issue, deletion
+// of the groups is tried multiple times.
+func deleteSecurityGroup(client securityGroupDeleter, name, id string) {
+	attempts := utils.AttemptStrategy{
+		Total: 30 * time.Second,
+		Delay: time.Second,
+	}
+	logger.Debugf("deleting security group %q", name)
+	i := 0
+	for attempt := attempts.Start(); attempt.Next(); {
+		err := client.DeleteSecurityGroup(id)
+		if err == nil {
+			return
+		}
+		i++
+		if i%4 == 0 {
+			message := fmt.Sprintf("waiting to delete security group %q", name)
+			if i != 4 {
+				message = "still " + message
+			}
+			logger.Debugf(message)
+		}
+	}
+	logger.Warningf("cannot delete security group %q. Used by another environment?", name)
+}
+
+// environGroupsRegexp returns a regexp matching the names of the
+// environment's security groups, other than the global group.
+func (e *environ) environGroupsRegexp() (*regexp.Regexp, error) {
+	re, err := regexp.Compile(fmt.Sprintf("^%s(-\\d+)?$", regexp.QuoteMeta(e.jujuGroupName())))
+	return re, errors.Trace(err)
+}
+
+// novaFirewaller manages security groups with the nova API.
+type novaFirewaller struct {
+	environ *environ
+}
+
+// SetUpGroups is part of the firewaller interface.
+//
+// Instances are tagged with a group so they can be distinguished from
+// other instances that might be running on the same OpenStack account.
+// In addition, a specific machine security group is created for each
+// machine, so that its firewall rules can be configured per machine.
+//
+// Note: ideally we'd have a better way to determine group membership so that 2
+// people that happen to share an openstack account and name their environment
+// "openstack" don't end up destroying each other's machines.
+func (c *novaFirewaller) SetUpGroups(machineId string, apiPort int) ([]securityGroup, error) {
+	e := c.environ
+	jujuGroup, err := c.setUpGlobalGroup(e.jujuGroupName(), apiPort)
+	if err != nil {
+		return nil, err
+	}
+	var machineGroup nova.SecurityGroup
+	switch e.Config().FirewallMode() {
+	case config.FwInstance:
+		machineGroup, err = c.ensureGroup(e.machineGroupName(machineId), nil)
+	case config.FwGlobal:
+		machineGroup, err = c.ensureGroup(e.globalGroupName(), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups := []securityGroup{
+		{Id: jujuGroup.Id, Name: jujuGroup.Name},
+		{Id: machineGroup.Id, Name: machineGroup.Name},
+	}
+	if e.ecfg().useDefaultSecurityGroup() {
+		defaultGroup, err := e.nova().SecurityGroupByName("default")
+		if err != nil {
+			return nil, fmt.Errorf("loading default security group: %v", err)
+		}
+		groups = append(groups, securityGroup{Id: defaultGroup.Id, Name: defaultGroup.Name})
+	}
+	return groups, nil
+}
+
+func (c *novaFirewaller) setUpGlobalGroup(groupName string, apiPort int) (nova.SecurityGroup, error) {
+	return c.ensureGroup(groupName,
+		[]nova.RuleInfo{
+			{
+				IPProtocol: "tcp",
+				FromPort:   22,
+				ToPort:     22,
+				Cidr:       "0.0.0.0/0",
+			},
+			{
+				IPProtocol: "tcp",
+				FromPort:   apiPort,
+				ToPort:     apiPort,
+				Cidr:       "0.0.0.0/0",
+			},
+			{
+				IPProtocol: "tcp",
+				FromPort:   1,
+				ToPort:     65535,
+			},
+			{
+				IPProtocol: "udp",
+				FromPort:   1,
+				ToPort:     65535,
+			},
+			{
+				IPProtocol: "icmp",
+				FromPort:   -1,
+				ToPort:     -1,
+			},
+		})
+}
+
+// zeroGroup holds the zero security group.
+var zeroGroup nova.SecurityGroup
+
+// ensureGroup returns the security group with name and perms.
+// If a group with name does not exist, one will be created.
+// If it exists, its permissions are set to perms.
+func (c *novaFirewaller) ensureGroup(name string, rules []nova.RuleInfo) (nova.SecurityGroup, error) {
+	novaClient := c.environ.nova()
+	// First attempt to look up an existing group by name.
+	group, err := novaClient.SecurityGroupByName(name)
+	if err == nil {
+		// Group exists, so assume it is correctly set up and return it.
+		// TODO(jam): 2013-09-18 http://pad.lv/121795
+		// We really should verify the group is set up correctly,
+		// because deleting and re-creating environments can get us bad
+		// groups (especially if they were set up under Python)
+		return *group, nil
+	}
+	// Doesn't exist, so try and create it.
+	group, err = novaClient.CreateSecurityGroup(name, "juju group")
+	if err != nil {
+		if !gooseerrors.IsDuplicateValue(err) {
+			return zeroGroup, err
+		} else {
+			// We just tried to create a duplicate group, so load the existing group.
+			group, err = novaClient.SecurityGroupByName(name)
+			if err != nil {
+				return zeroGroup, err
+			}
+			return *group, nil
+		}
+	}
+	// The new group is created so now add the rules.
+	group.Rules = make([]nova.SecurityGroupRule, len(rules))
+	for i, rule := range rules {
+		rule.ParentGroupId = group.Id
+		if rule.Cidr == "" {
+			// http://pad.lv/1226996 Rules that don't have a CIDR
+			// are meant to apply only to this group. If you don't
+			// supply CIDR or GroupId then openstack assumes you
+			// mean CIDR=0.0.0.0/0
+			rule.GroupId = &group.Id
+		}
+		groupRule, err := novaClient.CreateSecurityGroupRule(rule)
+		if err != nil && !gooseerrors.IsDuplicateValue(err) {
+			return zeroGroup, err
+		}
+		group.Rules[i] = *groupRule
+	}
+	return *group, nil
+}
+
+// InstanceNetworks is part of the firewaller interface. Nova attaches
+// the instance to each network, and applies the groups by name.
+func (c *novaFirewaller) InstanceNetworks(machineName string, networkIds []string, groups []securityGroup) ([]nova.ServerNetworks, []nova.SecurityGroupName, error) {
+	networks := make([]nova.ServerNetworks, len(networkIds))
+	for i, networkId := range networkIds {
+		networks[i] = nova.ServerNetworks{NetworkId: networkId}
+	}
+	return networks, securityGroupNames(groups), nil
+}
+
+// DeletePorts is part of the firewaller interface. Nova creates and
+// deletes the ports itself.
+func (c *novaFirewaller) DeletePorts(networks []nova.ServerNetworks) error {
+	return nil
+}
+
+// DeleteInstancePorts is part of the firewaller interface. Nova
+// deletes the ports itself.
+func (c *novaFirewaller) DeleteInstancePorts(ids []instance.Id) error {
+	return nil
+}
+
+// portsToRuleInfo maps port ranges to nova rules
+func portsToRuleInfo(groupId string, ports []network.PortRange) []nova.RuleInfo {
+	rules := make([]nova.RuleInfo, len(ports))
+	for i, portRange := range ports {
+		rules[i] = nova.RuleInfo{
+			ParentGroupId: groupId,
+			FromPort:      portRange.FromPort,
+			ToPort:        portRange.ToPort,
+			IPProtocol:    portRange.Protocol,
+			Cidr:          "0.0.0.0/0",
+		}
+	}
+	return rules
+}
+
+// OpenPorts is part of the firewaller interface.
+func (c *novaFirewaller) OpenPorts(name string, portRanges []network.PortRange) error {
+	novaclient := c.environ.nova()
+	group, err := novaclient.SecurityGroupByName(name)
+	if err != nil {
+		return err
+	}
+	rules := portsToRuleInfo(group.Id, portRanges)
+	for _, rule := range rules {
+		_, err := novaclient.CreateSecurityGroupRule(rule)
+		if err != nil {
+			// TODO: if err is not rule already exists, raise?
+			logger.Debugf("error creating security group rule: %v", err.Error())
+		}
+	}
+	return nil
+}
+
+// ruleMatchesPortRange checks if supplied nova security group rule matches the port range
+func ruleMatchesPortRange(rule nova.SecurityGroupRule, portRange network.PortRange) bool {
+	if rule.IPProtocol == nil || rule.FromPort == nil || rule.ToPort == nil {
+		return false
+	}
+	return *rule.IPProtocol == portRange.Protocol &&
+		*rule.FromPort == portRange.FromPort &&
+		*rule.ToPort == portRange.ToPort
+}
+
+// ClosePorts is part of the firewaller interface.
+func (c *novaFirewaller) ClosePorts(name string, portRanges []network.PortRange) error {
+	if len(portRanges) == 0 {
+		return nil
+	}
+	novaclient := c.environ.nova()
+	group, err := novaclient.SecurityGroupByName(name)
+	if err != nil {
+		return err
+	}
+	// TODO: Hey look ma, it's quadratic
+	for _, portRange := range portRanges {
+		for _, p := range (*group).Rules {
+			if !ruleMatchesPortRange(p, portRange) {
+				continue
+			}
+			err := novaclient.DeleteSecurityGroupRule(p.Id)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// Ports is part of the firewaller interface.
+func (c *novaFirewaller) Ports(name string) (portRanges []network.PortRange, err error) {
+	group, err := c.environ.nova().SecurityGroupByName(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range (*group).Rules {
+		portRanges = append(portRanges, network.PortRange{
+			Protocol: *p.IPProtocol,
+			FromPort: *p.FromPort,
+			ToPort:   *p.ToPort,
+		})
+	}
+	network.SortPortRanges(portRanges)
+	return portRanges, nil
+}
+
+// DeleteGroups is part of the firewaller interface. If a security
+// group is also used by another environment (see bug #1300755), an
+// attempt to delete this group fails. A warning is logged in this case.
+func (c *novaFirewaller) DeleteGroups(securityGroupNames ...string) error {
+	novaclient := c.environ.nova()
+	allSecurityGroups, err := novaclient.ListSecurityGroups()
+	if err != nil {
+		return err
+	}
+	for _, securityGroup := range allSecurityGroups {
+		for _, name := range securityGroupNames {
+			if securityGroup.Name == name {
+				deleteSecurityGroup(novaclient, name, securityGroup.Id)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteAll is part of the firewaller interface.
+func (c *novaFirewaller) DeleteAll() error {
+	novaClient := c.environ.nova()
+	securityGroups, err := novaClient.ListSecurityGroups()
+	if err != nil {
+		return errors.Annotate(err, "cannot list security groups")
+	}
+	re, err := c.environ.environGroupsRegexp()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	globalGroupName := c.environ.globalGroupName()
+	for _, group := range securityGroups {
+		if re.MatchString(group.Name) || group.Name == globalGroupName {
+			deleteSecurityGroup(novaClient, group.Name, group.Id)
+		}
+	}
+	return nil
+}
+
+// securityGroupNames returns the names of the groups, for starting an
+// instance with nova.
+func securityGroupNames(groups []securityGroup) []nova.SecurityGroupName {
+	names := make([]nova.SecurityGroupName, len(groups))
+	for i, g := range groups {
+		names[i] = nova.SecurityGroupName{g.Name}
+	}
+	return names
+}
+
+// neutronFirewaller manages security groups and ports with the
+// Neutron API. Instances started on explicit networks are attached
+// through ports that juju creates in the instance's security groups,
+// and deletes when the instance is stopped.
+type neutronFirewaller struct {
+	environ *environ
+	neutron *neutronClient
+}
+
+const (
+	neutronIngress  = "ingress"
+	neutronIPv4     = "IPv4"
+	neutronAnywhere = "0.0.0.0/0"
+)
+
+// SetUpGroups is part of the firewaller interface. The groups are the
+// same as those set up with nova.
+func (c *neutronFirewaller) SetUpGroups(machineId string, apiPort int) ([]securityGroup, error) {
+	e := c.environ
+	jujuGroup, err := c.ensureGroup(e.jujuGroupName(), c.globalGroupRules(apiPort))
+	if err != nil {
+		return nil, err
+	}
+	var machineGroup *neutronSecurityGroup
+	switch e.Config().FirewallMode() {
+	case config.FwInstance:
+		machineGroup, err = c.ensureGroup(e.machineGroupName(machineId), nil)
+	case config.FwGlobal:
+		machineGroup, err = c.ensureGroup(e.globalGroupName(), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups := []securityGroup{
+		{Id: jujuGroup.Id, Name: jujuGroup.Name},
+		{Id: machineGroup.Id, Name: machineGroup.Name},
+	}
+	if e.ecfg().useDefaultSecurityGroup() {
+		defaultGroup, err := c.neutron.SecurityGroupByName("default")
+		if err != nil {
+			return nil, fmt.Errorf("loading default security group: %v", err)
+		}
+		groups = append(groups, securityGroup{Id: defaultGroup.Id, Name: defaultGroup.Name})
+	}
+	return groups, nil
+}
+
+// globalGroupRules returns the rules of the environment's juju group:
+// ssh and the API port are open to all, and all traffic is allowed
+// between the environment's instances. A rule with no remote ip prefix
+// allows traffic from the group's own members.
+func (c *neutronFirewaller) globalGroupRules(apiPort int) []neutronSecurityRule {
+	portRule := func(protocol string, from, to int, prefix string) neutronSecurityRule {
+		return neutronSecurityRule{
+			Protocol:       protocol,
+			PortRangeMin:   &from,
+			PortRangeMax:   &to,
+			RemoteIPPrefix: prefix,
+		}
+	}
+	return []neutronSecurityRule{
+		portRule("tcp", 22, 22, neutronAnywhere),
+		portRule("tcp", apiPort, apiPort, neutronAnywhere),
+		portRule("tcp", 1, 65535, ""),
+		portRule("udp", 1, 65535, ""),
+		{Protocol: "icmp"},
+	}
+}
+
+// ensureGroup returns the security group with the given name, creating
+// it with the given rules if it does not exist.
+func (c *neutronFirewaller) ensureGroup(name string, rules []neutronSecurityRule) (*neutronSecurityGroup, error) {
+	group, err := c.neutron.SecurityGroupByName(name)
+	if err == nil {
+		// As with nova, assume an existing group is correctly set up.
+		return group, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	group, err = c.neutron.CreateSecurityGroup(name, "juju group")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, rule := range rules {
+		rule.SecurityGroupId = group.Id
+		rule.Direction = neutronIngress
+		rule.EtherType = neutronIPv4
+		if rule.RemoteIPPrefix == "" {
+			rule.RemoteGroupId = &group.Id
+		}
+		groupRule, err := c.neutron.CreateSecurityGroupRule(rule)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		group.Rules = append(group.Rules, *groupRule)
+	}
+	return group, nil
+}
+
+// InstanceNetworks is part of the firewaller interface. A port is
+// created on each network, in the given groups, and the instance is
+// attached through it. If no networks are given, nova chooses the
+// network and applies the groups by name.
+func (c *neutronFirewaller) InstanceNetworks(machineName string, networkIds []string, groups []securityGroup) ([]nova.ServerNetworks, []nova.SecurityGroupName, error) {
+	if len(networkIds) == 0 {
+		return nil, securityGroupNames(groups), nil
+	}
+	groupIds := make([]string, len(groups))
+	for i, g := range groups {
+		groupIds[i] = g.Id
+	}
+	var networks []nova.ServerNetworks
+	for _, networkId := range networkIds {
+		port, err := c.neutron.CreatePort(machineName, networkId, groupIds)
+		if err != nil {
+			if err := c.DeletePorts(networks); err != nil {
+				logger.Errorf("cannot delete ports for %q: %v", machineName, err)
+			}
+			return nil, nil, errors.Trace(err)
+		}
+		logger.Debugf("created port %q on network %q", port.Id, networkId)
+		networks = append(networks, nova.ServerNetworks{PortId: port.Id})
+	}
+	return networks, nil, nil
+}
+
+// DeletePorts is part of the firewaller interface.
+func (c *neutronFirewaller) DeletePorts(networks []nova.ServerNetworks) error {
+	var firstErr error
+	for _, n := range networks {
+		if n.PortId == "" {
+			continue
+		}
+		if err := c.neutron.DeletePort(n.PortId); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// DeleteInstancePorts is part of the firewaller interface. Only the
+// ports juju created are deleted; nova deletes those it created itself.
+func (c *neutronFirewaller) DeleteInstancePorts(ids []instance.Id) error {
+	prefix := fmt.Sprintf("juju-%s-", c.environ.Config().Name())
+	for _, id := range ids {
+		ports, err := c.neutron.ListPorts(url.Values{"device_id": {string(id)}})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, port := range ports {
+			if !strings.HasPrefix(port.Name, prefix) {
+				continue
+			}
+			if err := c.neutron.DeletePort(port.Id); err != nil && !gooseerrors.IsNotFound(err) {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+// portRangeRule returns the rule that opens the port range to all.
+func portRangeRule(groupId string, portRange network.PortRange) neutronSecurityRule {
+	rule := neutronSecurityRule{
+		SecurityGroupId: groupId,
+		Direction:       neutronIngress,
+		EtherType:       neutronIPv4,
+		Protocol:        portRange.Protocol,
+		RemoteIPPrefix:  neutronAnywhere,
+	}
+	if portRange.Protocol != "icmp" {
+		from, to := portRange.FromPort, portRange.ToPort
+		rule.PortRangeMin = &from
+		rule.PortRangeMax = &to
+	}
+	return rule
+}
+
+// neutronRulePortRange returns the port range opened by the rule, and
+// whether it is one opened by OpenPorts.
+func neutronRulePortRange(rule neutronSecurityRule) (network.PortRange, bool) {
+	if rule.Direction != neutronIngress || rule.RemoteIPPrefix != neutronAnywhere || rule.Protocol == "" {
+		return network.PortRange{}, false
+	}
+	portRange := network.PortRange{Protocol: rule.Protocol, FromPort: -1, ToPort: -1}
+	if rule.PortRangeMin != nil && rule.PortRangeMax != nil {
+		portRange.FromPort = *rule.PortRangeMin
+		portRange.ToPort = *rule.PortRangeMax
+	}
+	return portRange, true
+}
+
+// OpenPorts is part of the firewaller interface.
+func (c *neutronFirewaller) OpenPorts(name string, portRanges []network.PortRange) error {
+	group, err := c.neutron.SecurityGroupByName(name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, portRange := range portRanges {
+		if _, err := c.neutron.CreateSecurityGroupRule(portRangeRule(group.Id, portRange)); err != nil {
+			// As with nova, an existing rule is not an error.
+			logger.Debugf("error creating security group rule: %v", err)
+		}
+	}
+	return nil
+}
+
+// ClosePorts is part of the firewaller interface.
+func (c *neutronFirewaller) ClosePorts(name string, portRanges []network.PortRange) error {
+	if len(portRanges) == 0 {
+		return nil
+	}
+	group, err := c.neutron.SecurityGroupByName(name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, portRange := range portRanges {
+		for _, rule := range group.Rules {
+			if ruleRange, ok := neutronRulePortRange(rule); !ok || ruleRange != portRange {
+				continue
+			}
+			if err := c.neutron.DeleteSecurityGroupRule(rule.Id); err != nil {
+				return errors.Trace(err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// Ports is part of the firewaller interface.
+func (c *neutronFirewaller) Ports(name string) ([]network.PortRange, error) {
+	group, err := c.neutron.SecurityGroupByName(name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var portRanges []network.PortRange
+	for _, rule := range group.Rules {
+		if portRange, ok := neutronRulePortRange(rule); ok {
+			portRanges = append(portRanges, portRange)
+		}
+	}
+	network.SortPortRanges(portRanges)
+	return portRanges, nil
+}
+
+// DeleteGroups is part of the firewaller interface.
+func (c *neutronFirewaller) DeleteGroups(names ...string) error {
+	groups, err := c.neutron.ListSecurityGroups("")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, group := range groups {
+		for _, name := range names {
+			if group.Name == name {
+				deleteSecurityGroup(c.neutron, name, group.Id)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteAll is part of the firewaller interface. The environment's
+// ports are deleted first, so that its groups are no longer in use.
+func (c *neutronFirewaller) DeleteAll() error {
+	ports, err := c.neutron.ListPorts(nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	machinePort, err := regexp.Compile(fmt.Sprintf("^juju-%s-machine-\\d+$", regexp.QuoteMeta(c.environ.Config().Name())))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, port := range ports {
+		if !machinePort.MatchString(port.Name) {
+			continue
+		}
+		if err := c.neutron.DeletePort(port.Id); err != nil && !gooseerrors.IsNotFound(err) {
+			return errors.Trace(err)
+		}
+	}
+
+	groups, err := c.neutron.ListSecurityGroups("")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	re, err := c.environ.environGroupsRegexp()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	globalGroupName := c.environ.globalGroupName()
+	for _, group := range groups {
+		if re.MatchString(group.Name) || group.Name == globalGroupName {
+			deleteSecurityGroup(c.neutron, group.Name, group.Id)
+		}
+	}
+	return nil
+}
@@ -442,6 +442,30 @@ func (s *localServerSuite) TestStartInstanceNetworkUnknownId(c *gc.C) {
 		"404; error info: .*itemNotFound.*")
 }
 
+func (s *localServerSuite) TestStartInstanceWithNetworks(c *gc.C) {
+	// The requested networks are used instead of the network setting.
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"network": "no-network-with-this-label",
+	}))
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	inst, _ := testing.AssertStartInstanceWithNetworks(c, env, "100", constraints.Value{}, []string{"net"})
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *localServerSuite) TestStartInstanceWithUnknownNetwork(c *gc.C) {
+	_, _, _, err := testing.StartInstanceWithConstraintsAndNetworks(s.env, "100", constraints.Value{}, []string{"no-network-with-this-label"})
+	c.Assert(err, gc.ErrorMatches, "No networks exist with label .*")
+}
+
+func (s *localServerSuite) TestStartInstanceExcludingNetworks(c *gc.C) {
+	cons := constraints.MustParse("networks=^net")
+	_, _, _, err := testing.StartInstanceWithConstraints(s.env, "100", cons)
+	c.Assert(err, jc.Satisfies, jujuerrors.IsNotSupported)
+}
+
 func assertSecurityGroups(c *gc.C, env environs.Environ, expected []string) {
 	novaClient := openstack.GetNovaClient(env)
 	groups, err := novaClient.ListSecurityGroups()
@@ -0,0 +1,195 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/juju/errors"
+	"gopkg.in/goose.v1/client"
+	goosehttp "gopkg.in/goose.v1/http"
+)
+
+// neutronServiceType is the catalog service type of the OpenStack
+// Networking (Neutron) API.
+const neutronServiceType = "network"
+
+// neutronRequestSender sends requests to an OpenStack service. It is
+// implemented by goose's client.Client.
+type neutronRequestSender interface {
+	SendRequest(method, svcType, apiCall string, requestData *goosehttp.RequestData) error
+}
+
+// neutronClient is a client for the parts of the Neutron v2.0 API used
+// to manage the security groups and ports of an environment's
+// instances.
+type neutronClient struct {
+	client neutronRequestSender
+}
+
+func newNeutronClient(client neutronRequestSender) *neutronClient {
+	return &neutronClient{client}
+}
+
+// neutronSecurityGroup describes a Neutron security group.
+type neutronSecurityGroup struct {
+	Id          string                `json:"id,omitempty"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Rules       []neutronSecurityRule `json:"security_group_rules,omitempty"`
+}
+
+// neutronSecurityRule describes a rule in a Neutron security group.
+// PortRangeMin and PortRangeMax are nil for rules that apply to all
+// ports, such as those for icmp.
+type neutronSecurityRule struct {
+	Id              string  `json:"id,omitempty"`
+	SecurityGroupId string  `json:"security_group_id"`
+	Direction       string  `json:"direction"`
+	EtherType       string  `json:"ethertype,omitempty"`
+	Protocol        string  `json:"protocol,omitempty"`
+	PortRangeMin    *int    `json:"port_range_min,omitempty"`
+	PortRangeMax    *int    `json:"port_range_max,omitempty"`
+	RemoteIPPrefix  string  `json:"remote_ip_prefix,omitempty"`
+	RemoteGroupId   *string `json:"remote_group_id,omitempty"`
+}
+
+// neutronPort describes a Neutron port.
+type neutronPort struct {
+	Id             string   `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	NetworkId      string   `json:"network_id"`
+	DeviceId       string   `json:"device_id,omitempty"`
+	SecurityGroups []string `json:"security_groups"`
+}
+
+func (c *neutronClient) send(method, apiCall string, params *url.Values, req, resp interface{}, status int) error {
+	requestData := goosehttp.RequestData{
+		Params:         params,
+		ReqValue:       req,
+		RespValue:      resp,
+		ExpectedStatus: []int{status},
+	}
+	return c.client.SendRequest(method, neutronServiceType, "v2.0/"+apiCall, &requestData)
+}
+
+// ListSecurityGroups returns the security groups with the given name,
+// or all the security groups if name is empty.
+func (c *neutronClient) ListSecurityGroups(name string) ([]neutronSecurityGroup, error) {
+	var params *url.Values
+	if name != "" {
+		params = &url.Values{"name": {name}}
+	}
+	var resp struct {
+		Groups []neutronSecurityGroup `json:"security_groups"`
+	}
+	if err := c.send(client.GET, "security-groups", params, nil, &resp, http.StatusOK); err != nil {
+		return nil, errors.Annotate(err, "cannot list security groups")
+	}
+	return resp.Groups, nil
+}
+
+// SecurityGroupByName returns the security group with the given name.
+// Neutron allows several groups to share a name; the first is used.
+func (c *neutronClient) SecurityGroupByName(name string) (*neutronSecurityGroup, error) {
+	groups, err := c.ListSecurityGroups(name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(groups) == 0 {
+		return nil, errors.NotFoundf("security group %q", name)
+	}
+	return &groups[0], nil
+}
+
+// CreateSecurityGroup creates a security group. Neutron adds rules
+// allowing all egress traffic to a new group.
+func (c *neutronClient) CreateSecurityGroup(name, description string) (*neutronSecurityGroup, error) {
+	var req struct {
+		Group neutronSecurityGroup `json:"security_group"`
+	}
+	req.Group = neutronSecurityGroup{Name: name, Description: description}
+	var resp struct {
+		Group neutronSecurityGroup `json:"security_group"`
+	}
+	if err := c.send(client.POST, "security-groups", nil, &req, &resp, http.StatusCreated); err != nil {
+		return nil, errors.Annotatef(err, "cannot create security group %q", name)
+	}
+	return &resp.Group, nil
+}
+
+// DeleteSecurityGroup deletes the security group with the given id.
+func (c *neutronClient) DeleteSecurityGroup(id string) error {
+	if err := c.send(client.DELETE, "security-groups/"+id, nil, nil, nil, http.StatusNoContent); err != nil {
+		return errors.Annotatef(err, "cannot delete security group %q", id)
+	}
+	return nil
+}
+
+// CreateSecurityGroupRule adds a rule to a security group.
+func (c *neutronClient) CreateSecurityGroupRule(rule neutronSecurityRule) (*neutronSecurityRule, error) {
+	req := struct {
+		Rule neutronSecurityRule `json:"security_group_rule"`
+	}{rule}
+	var resp struct {
+		Rule neutronSecurityRule `json:"security_group_rule"`
+	}
+	if err := c.send(client.POST, "security-group-rules", nil, &req, &resp, http.StatusCreated); err != nil {
+		return nil, errors.Annotate(err, "cannot create security group rule")
+	}
+	return &resp.Rule, nil
+}
+
+// DeleteSecurityGroupRule deletes the security group rule with the
+// given id.
+func (c *neutronClient) DeleteSecurityGroupRule(id string) error {
+	if err := c.send(client.DELETE, "security-group-rules/"+id, nil, nil, nil, http.StatusNoContent); err != nil {
+		return errors.Annotatef(err, "cannot delete security group rule %q", id)
+	}
+	return nil
+}
+
+// ListPorts returns the ports matching the given filter, which may
+// be nil.
+func (c *neutronClient) ListPorts(filter url.Values) ([]neutronPort, error) {
+	var params *url.Values
+	if len(filter) > 0 {
+		params = &filter
+	}
+	var resp struct {
+		Ports []neutronPort `json:"ports"`
+	}
+	if err := c.send(client.GET, "ports", params, nil, &resp, http.StatusOK); err != nil {
+		return nil, errors.Annotate(err, "cannot list ports")
+	}
+	return resp.Ports, nil
+}
+
+// CreatePort creates a port on a network, in the given security
+// groups.
+func (c *neutronClient) CreatePort(name, networkId string, groupIds []string) (*neutronPort, error) {
+	req := struct {
+		Port neutronPort `json:"port"`
+	}{neutronPort{
+		Name:           name,
+		NetworkId:      networkId,
+		SecurityGroups: groupIds,
+	}}
+	var resp struct {
+		Port neutronPort `json:"port"`
+	}
+	if err := c.send(client.POST, "ports", nil, &req, &resp, http.StatusCreated); err != nil {
+		return nil, errors.Annotatef(err, "cannot create port on network %q", networkId)
+	}
+	return &resp.Port, nil
+}
+
+// DeletePort deletes the port with the given id.
+func (c *neutronClient) DeletePort(id string) error {
+	if err := c.send(client.DELETE, "ports/"+id, nil, nil, nil, http.StatusNoContent); err != nil {
+		return errors.Annotatef(err, "cannot delete port %q", id)
+	}
+	return nil
+}
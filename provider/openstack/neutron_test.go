@@ -0,0 +1,290 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/goose.v1/client"
+	goosehttp "gopkg.in/goose.v1/http"
+	"gopkg.in/goose.v1/nova"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/openstack"
+	"github.com/juju/juju/testing"
+)
+
+type neutronSuite struct {
+	testing.BaseSuite
+	neutron *fakeNeutron
+}
+
+var _ = gc.Suite(&neutronSuite{})
+
+func (s *neutronSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.neutron = newFakeNeutron()
+	s.neutron.addGroup("juju-testenv-global")
+}
+
+func (s *neutronSuite) TestOpenPorts(c *gc.C) {
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	err := fw.OpenPorts("juju-testenv-global", []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "udp", FromPort: 53, ToPort: 54},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	rules := s.neutron.groups["group-0"]["security_group_rules"].([]interface{})
+	c.Assert(rules, gc.HasLen, 2)
+	rule := rules[0].(map[string]interface{})
+	c.Check(rule["security_group_id"], gc.Equals, "group-0")
+	c.Check(rule["direction"], gc.Equals, "ingress")
+	c.Check(rule["ethertype"], gc.Equals, "IPv4")
+	c.Check(rule["protocol"], gc.Equals, "tcp")
+	c.Check(rule["port_range_min"], gc.Equals, float64(80))
+	c.Check(rule["port_range_max"], gc.Equals, float64(80))
+	c.Check(rule["remote_ip_prefix"], gc.Equals, "0.0.0.0/0")
+}
+
+func (s *neutronSuite) TestPorts(c *gc.C) {
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	err := fw.OpenPorts("juju-testenv-global", []network.PortRange{
+		{Protocol: "udp", FromPort: 53, ToPort: 54},
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// Rules for traffic within the group are not open ports.
+	s.neutron.addRule("group-0", map[string]interface{}{
+		"direction":       "ingress",
+		"protocol":        "tcp",
+		"port_range_min":  1,
+		"port_range_max":  65535,
+		"remote_group_id": "group-0",
+	})
+
+	ports, err := fw.Ports("juju-testenv-global")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ports, jc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "udp", FromPort: 53, ToPort: 54},
+	})
+}
+
+func (s *neutronSuite) TestClosePorts(c *gc.C) {
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	err := fw.OpenPorts("juju-testenv-global", []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "tcp", FromPort: 443, ToPort: 443},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = fw.ClosePorts("juju-testenv-global", []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	ports, err := fw.Ports("juju-testenv-global")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ports, jc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 443, ToPort: 443},
+	})
+}
+
+func (s *neutronSuite) TestOpenPortsMissingGroup(c *gc.C) {
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	err := fw.OpenPorts("juju-testenv-0", []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+	})
+	c.Assert(err, gc.ErrorMatches, `security group "juju-testenv-0" not found`)
+}
+
+func (s *neutronSuite) TestInstanceNetworksCreatesPorts(c *gc.C) {
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	networks, groupNames, err := fw.InstanceNetworks(
+		"juju-testenv-machine-0",
+		[]string{"net-a", "net-b"},
+		openstack.SecurityGroups("group-0", "juju-testenv-global"),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(groupNames, gc.HasLen, 0)
+	c.Check(networks, jc.DeepEquals, []nova.ServerNetworks{
+		{PortId: "port-0"},
+		{PortId: "port-1"},
+	})
+	c.Assert(s.neutron.ports, gc.HasLen, 2)
+	port := s.neutron.ports["port-1"]
+	c.Check(port["name"], gc.Equals, "juju-testenv-machine-0")
+	c.Check(port["network_id"], gc.Equals, "net-b")
+	c.Check(port["security_groups"], jc.DeepEquals, []interface{}{"group-0"})
+
+	err = fw.DeletePorts(networks)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.neutron.ports, gc.HasLen, 0)
+}
+
+func (s *neutronSuite) TestInstanceNetworksWithoutNetworks(c *gc.C) {
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	networks, groupNames, err := fw.InstanceNetworks(
+		"juju-testenv-machine-0",
+		nil,
+		openstack.SecurityGroups("group-0", "juju-testenv-global"),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(networks, gc.HasLen, 0)
+	c.Check(groupNames, jc.DeepEquals, []nova.SecurityGroupName{{"juju-testenv-global"}})
+	c.Check(s.neutron.ports, gc.HasLen, 0)
+}
+
+func (s *neutronSuite) TestInstanceNetworksCleansUpOnError(c *gc.C) {
+	s.neutron.failNetwork = "net-b"
+	fw := openstack.NewNeutronFirewaller(s.neutron)
+	_, _, err := fw.InstanceNetworks(
+		"juju-testenv-machine-0",
+		[]string{"net-a", "net-b"},
+		openstack.SecurityGroups("group-0", "juju-testenv-global"),
+	)
+	c.Assert(err, gc.ErrorMatches, `cannot create port on network "net-b": no such network`)
+	c.Assert(s.neutron.ports, gc.HasLen, 0)
+}
+
+// fakeNeutron is an in-memory implementation of the parts of the
+// Neutron API used by the provider.
+type fakeNeutron struct {
+	groups      map[string]map[string]interface{}
+	ports       map[string]map[string]interface{}
+	nextId      int
+	failNetwork string
+}
+
+func newFakeNeutron() *fakeNeutron {
+	return &fakeNeutron{
+		groups: make(map[string]map[string]interface{}),
+		ports:  make(map[string]map[string]interface{}),
+	}
+}
+
+func (f *fakeNeutron) addGroup(name string) map[string]interface{} {
+	id := fmt.Sprintf("group-%d", len(f.groups))
+	group := map[string]interface{}{
+		"id":                   id,
+		"name":                 name,
+		"security_group_rules": []interface{}{},
+	}
+	f.groups[id] = group
+	return group
+}
+
+func (f *fakeNeutron) addRule(groupId string, rule map[string]interface{}) map[string]interface{} {
+	rule["id"] = fmt.Sprintf("rule-%d", f.nextId)
+	f.nextId++
+	rule["security_group_id"] = groupId
+	group := f.groups[groupId]
+	group["security_group_rules"] = append(group["security_group_rules"].([]interface{}), rule)
+	return rule
+}
+
+func (f *fakeNeutron) deleteRule(id string) bool {
+	for _, group := range f.groups {
+		rules := group["security_group_rules"].([]interface{})
+		for i, rule := range rules {
+			if rule.(map[string]interface{})["id"] == id {
+				group["security_group_rules"] = append(rules[:i], rules[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SendRequest is part of the neutronRequestSender interface.
+func (f *fakeNeutron) SendRequest(method, svcType, apiCall string, requestData *goosehttp.RequestData) error {
+	if svcType != "network" || !strings.HasPrefix(apiCall, "v2.0/") {
+		return fmt.Errorf("unexpected request %s %s %s", method, svcType, apiCall)
+	}
+	var req map[string]interface{}
+	if requestData.ReqValue != nil {
+		if err := roundTrip(requestData.ReqValue, &req); err != nil {
+			return err
+		}
+	}
+	resp, status, err := f.handle(method, strings.TrimPrefix(apiCall, "v2.0/"), requestData, req)
+	if err != nil {
+		return err
+	}
+	if status != requestData.ExpectedStatus[0] {
+		return fmt.Errorf("unexpected status %d", status)
+	}
+	if requestData.RespValue != nil {
+		return roundTrip(resp, requestData.RespValue)
+	}
+	return nil
+}
+
+func (f *fakeNeutron) handle(method, apiCall string, requestData *goosehttp.RequestData, req map[string]interface{}) (interface{}, int, error) {
+	parts := strings.SplitN(apiCall, "/", 2)
+	switch {
+	case method == client.GET && apiCall == "security-groups":
+		groups := []interface{}{}
+		for _, group := range f.groups {
+			if requestData.Params != nil {
+				if name := requestData.Params.Get("name"); name != "" && group["name"] != name {
+					continue
+				}
+			}
+			groups = append(groups, group)
+		}
+		return map[string]interface{}{"security_groups": groups}, http.StatusOK, nil
+	case method == client.POST && apiCall == "security-groups":
+		group := f.addGroup(req["security_group"].(map[string]interface{})["name"].(string))
+		return map[string]interface{}{"security_group": group}, http.StatusCreated, nil
+	case method == client.DELETE && parts[0] == "security-groups":
+		delete(f.groups, parts[1])
+		return nil, http.StatusNoContent, nil
+	case method == client.POST && apiCall == "security-group-rules":
+		rule := req["security_group_rule"].(map[string]interface{})
+		groupId := rule["security_group_id"].(string)
+		if _, ok := f.groups[groupId]; !ok {
+			return nil, 0, fmt.Errorf("no such security group")
+		}
+		return map[string]interface{}{"security_group_rule": f.addRule(groupId, rule)}, http.StatusCreated, nil
+	case method == client.DELETE && parts[0] == "security-group-rules":
+		if !f.deleteRule(parts[1]) {
+			return nil, 0, fmt.Errorf("no such rule")
+		}
+		return nil, http.StatusNoContent, nil
+	case method == client.GET && apiCall == "ports":
+		ports := []interface{}{}
+		for _, port := range f.ports {
+			ports = append(ports, port)
+		}
+		return map[string]interface{}{"ports": ports}, http.StatusOK, nil
+	case method == client.POST && apiCall == "ports":
+		port := req["port"].(map[string]interface{})
+		if port["network_id"] == f.failNetwork {
+			return nil, 0, fmt.Errorf("no such network")
+		}
+		port["id"] = fmt.Sprintf("port-%d", len(f.ports))
+		f.ports[port["id"].(string)] = port
+		return map[string]interface{}{"port": port}, http.StatusCreated, nil
+	case method == client.DELETE && parts[0] == "ports":
+		delete(f.ports, parts[1])
+		return nil, http.StatusNoContent, nil
+	}
+	return nil, 0, fmt.Errorf("unexpected request %s %s", method, apiCall)
+}
+
+// roundTrip copies in to out through JSON, as a request and response
+// would be.
+func roundTrip(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -505,8 +505,12 @@ func (inst *openstackInstance) OpenPorts(machineId string, ports []network.PortR
 		return fmt.Errorf("invalid firewall mode %q for opening ports on instance",
 			inst.e.Config().FirewallMode())
 	}
+	fw, err := inst.e.firewaller()
+	if err != nil {
+		return err
+	}
 	name := inst.e.machineGroupName(machineId)
-	if err := inst.e.openPortsInGroup(name, ports); err != nil {
+	if err := fw.OpenPorts(name, ports); err != nil {
 		return err
 	}
 	logger.Infof("opened ports in security group %s: %v", name, ports)
@@ -518,8 +522,12 @@ func (inst *openstackInstance) ClosePorts(machineId string, ports []network.Port
 		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
 			inst.e.Config().FirewallMode())
 	}
+	fw, err := inst.e.firewaller()
+	if err != nil {
+		return err
+	}
 	name := inst.e.machineGroupName(machineId)
-	if err := inst.e.closePortsInGroup(name, ports); err != nil {
+	if err := fw.ClosePorts(name, ports); err != nil {
 		return err
 	}
 	logger.Infof("closed ports in security group %s: %v", name, ports)
@@ -531,8 +539,12 @@ func (inst *openstackInstance) Ports(machineId string) ([]network.PortRange, err
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
 			inst.e.Config().FirewallMode())
 	}
+	fw, err := inst.e.firewaller()
+	if err != nil {
+		return nil, err
+	}
 	name := inst.e.machineGroupName(machineId)
-	portRanges, err := inst.e.portsInGroup(name)
+	portRanges, err := fw.Ports(name)
 	if err != nil {
 		return nil, err
 	}
@@ -576,6 +588,9 @@ func (e *environ) SupportedArchitectures() ([]string, error) {
 var unsupportedConstraints = []string{
 	constraints.Tags,
 	constraints.CpuPower,
+	constraints.RootDiskSource,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -895,6 +910,30 @@ func (e *environ) resolveNetwork(networkName string) (string, error) {
 	return "", fmt.Errorf("Multiple networks with label %q: %v", networkName, networkIds)
 }
 
+// startInstanceNetworks returns the ids of the networks a new instance
+// is attached to. These are the provider networks requested by the
+// networks constraint and the machine's services, if any, or else the
+// network set by the network setting. Each network is given as an id
+// or a label.
+func (e *environ) startInstanceNetworks(args environs.StartInstanceParams) ([]string, error) {
+	networkNames := args.InstanceConfig.Networks
+	if len(networkNames) == 0 {
+		if usingNetwork := e.ecfg().network(); usingNetwork != "" {
+			networkNames = []string{usingNetwork}
+		}
+	}
+	var networkIds []string
+	for _, networkName := range networkNames {
+		networkId, err := e.resolveNetwork(networkName)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debugf("using network id %q", networkId)
+		networkIds = append(networkIds, networkId)
+	}
+	return networkIds, nil
+}
+
 // allocatePublicIP tries to find an available floating IP address, or
 // allocates a new one, returning it, or an error
 func (e *environ) allocatePublicIP() (*nova.FloatingIP, error) {
@@ -975,7 +1014,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 
 	// If no availability zone is specified, then automatically spread across
 	// the known zones for optimal spread across the instance distribution
-	// group.
+	// group, or pack into them if the availability-zone-policy says so.
 	if len(availabilityZones) == 0 {
 		var group []instance.Id
 		var err error
@@ -985,13 +1024,25 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 				return nil, err
 			}
 		}
+		var zones []string
+		if args.Constraints.HaveZones() {
+			zones = *args.Constraints.Zones
+		}
 		zoneInstances, err := availabilityZoneAllocations(e, group)
 		if errors.IsNotImplemented(err) {
 			// Availability zones are an extension, so we may get a
-			// not implemented error; ignore these.
+			// not implemented error; ignore these, unless zones
+			// were asked for.
+			if len(zones) > 0 {
+				return nil, errors.NotSupportedf("zones constraint without availability zones")
+			}
 		} else if err != nil {
 			return nil, err
 		} else {
+			zoneInstances, err = common.OrderAvailabilityZones(zoneInstances, e.Config().AvailabilityZonePolicy(), zones)
+			if err != nil {
+				return nil, err
+			}
 			for _, zone := range zoneInstances {
 				availabilityZones = append(availabilityZones, zone.ZoneName)
 			}
@@ -1002,17 +1053,20 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		}
 	}
 
-	if args.InstanceConfig.HasNetworks() {
-		return nil, fmt.Errorf("starting instances with networks is not supported yet.")
+	if excluded := args.Constraints.ExcludeNetworks(); len(excluded) > 0 {
+		return nil, errors.NotSupportedf("excluding networks %q", excluded)
 	}
 
 	series := args.Tools.OneSeries()
 	arches := args.Tools.Arches()
+	region := e.ecfg().region()
+	imageId, _ := e.Config().PinnedImageId(region, series)
 	spec, err := findInstanceSpec(e, &instances.InstanceConstraint{
-		Region:      e.ecfg().region(),
+		Region:      region,
 		Series:      series,
 		Arches:      arches,
 		Constraints: args.Constraints,
+		ImageId:     imageId,
 	})
 	if err != nil {
 		return nil, err
@@ -1033,15 +1087,9 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 	}
 	logger.Debugf("openstack user data; %d bytes", len(userData))
 
-	var networks = []nova.ServerNetworks{}
-	usingNetwork := e.ecfg().network()
-	if usingNetwork != "" {
-		networkId, err := e.resolveNetwork(usingNetwork)
-		if err != nil {
-			return nil, err
-		}
-		logger.Debugf("using network id %q", networkId)
-		networks = append(networks, nova.ServerNetworks{NetworkId: networkId})
+	networkIds, err := e.startInstanceNetworks(args)
+	if err != nil {
+		return nil, err
 	}
 	withPublicIP := e.ecfg().useFloatingIP()
 	var publicIP *nova.FloatingIP
@@ -1056,19 +1104,23 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 	}
 
 	cfg := e.Config()
-	groups, err := e.setUpGroups(args.InstanceConfig.MachineId, cfg.APIPort())
+	fw, err := e.firewaller()
 	if err != nil {
-		return nil, fmt.Errorf("cannot set up groups: %v", err)
+		return nil, err
 	}
-	var groupNames = make([]nova.SecurityGroupName, len(groups))
-	for i, g := range groups {
-		groupNames[i] = nova.SecurityGroupName{g.Name}
+	groups, err := fw.SetUpGroups(args.InstanceConfig.MachineId, cfg.APIPort())
+	if err != nil {
+		return nil, fmt.Errorf("cannot set up groups: %v", err)
 	}
 
 	machineName := resourceName(
 		names.NewMachineTag(args.InstanceConfig.MachineId),
 		e.Config().Name(),
 	)
+	networks, groupNames, err := fw.InstanceNetworks(machineName, networkIds, groups)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set up networks: %v", err)
+	}
 
 	var server *nova.Entity
 	for _, availZone := range availabilityZones {
@@ -1095,6 +1147,9 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		}
 	}
 	if err != nil {
+		if err := fw.DeletePorts(networks); err != nil {
+			logger.Errorf("cannot delete ports of %q: %v", machineName, err)
+		}
 		return nil, fmt.Errorf("cannot run instance: %v", err)
 	}
 	detail, err := e.nova().GetServer(server.Id)
@@ -1156,8 +1211,15 @@ func (e *environ) StopInstances(ids ...instance.Id) error {
 	if err := e.terminateInstances(ids); err != nil {
 		return err
 	}
+	fw, err := e.firewaller()
+	if err != nil {
+		return err
+	}
+	if err := fw.DeleteInstancePorts(ids); err != nil {
+		return err
+	}
 	if securityGroupNames != nil {
-		return e.deleteSecurityGroups(securityGroupNames)
+		return fw.DeleteGroups(securityGroupNames...)
 	}
 	return nil
 }
@@ -1311,22 +1373,11 @@ func (e *environ) Destroy() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	novaClient := e.nova()
-	securityGroups, err := novaClient.ListSecurityGroups()
-	if err != nil {
-		return errors.Annotate(err, "cannot list security groups")
-	}
-	re, err := regexp.Compile(fmt.Sprintf("^%s(-\\d+)?$", e.jujuGroupName()))
+	fw, err := e.firewaller()
 	if err != nil {
 		return errors.Trace(err)
 	}
-	globalGroupName := e.globalGroupName()
-	for _, group := range securityGroups {
-		if re.MatchString(group.Name) || group.Name == globalGroupName {
-			deleteSecurityGroup(novaClient, group.Name, group.Id)
-		}
-	}
-	return nil
+	return fw.DeleteAll()
 }
 
 func (e *environ) globalGroupName() string {
@@ -1352,89 +1403,6 @@ func (e *environ) machinesFilter() *nova.Filter {
 	return filter
 }
 
-// portsToRuleInfo maps port ranges to nova rules
-func portsToRuleInfo(groupId string, ports []network.PortRange) []nova.RuleInfo {
-	rules := make([]nova.RuleInfo, len(ports))
-	for i, portRange := range ports {
-		rules[i] = nova.RuleInfo{
-			ParentGroupId: groupId,
-			FromPort:      portRange.FromPort,
-			ToPort:        portRange.ToPort,
-			IPProtocol:    portRange.Protocol,
-			Cidr:          "0.0.0.0/0",
-		}
-	}
-	return rules
-}
-
-func (e *environ) openPortsInGroup(name string, portRanges []network.PortRange) error {
-	novaclient := e.nova()
-	group, err := novaclient.SecurityGroupByName(name)
-	if err != nil {
-		return err
-	}
-	rules := portsToRuleInfo(group.Id, portRanges)
-	for _, rule := range rules {
-		_, err := novaclient.CreateSecurityGroupRule(rule)
-		if err != nil {
-			// TODO: if err is not rule already exists, raise?
-			logger.Debugf("error creating security group rule: %v", err.Error())
-		}
-	}
-	return nil
-}
-
-// ruleMatchesPortRange checks if supplied nova security group rule matches the port range
-func ruleMatchesPortRange(rule nova.SecurityGroupRule, portRange network.PortRange) bool {
-	if rule.IPProtocol == nil || rule.FromPort == nil || rule.ToPort == nil {
-		return false
-	}
-	return *rule.IPProtocol == portRange.Protocol &&
-		*rule.FromPort == portRange.FromPort &&
-		*rule.ToPort == portRange.ToPort
-}
-
-func (e *environ) closePortsInGroup(name string, portRanges []network.PortRange) error {
-	if len(portRanges) == 0 {
-		return nil
-	}
-	novaclient := e.nova()
-	group, err := novaclient.SecurityGroupByName(name)
-	if err != nil {
-		return err
-	}
-	// TODO: Hey look ma, it's quadratic
-	for _, portRange := range portRanges {
-		for _, p := range (*group).Rules {
-			if !ruleMatchesPortRange(p, portRange) {
-				continue
-			}
-			err := novaclient.DeleteSecurityGroupRule(p.Id)
-			if err != nil {
-				return err
-			}
-			break
-		}
-	}
-	return nil
-}
-
-func (e *environ) portsInGroup(name string) (portRanges []network.PortRange, err error) {
-	group, err := e.nova().SecurityGroupByName(name)
-	if err != nil {
-		return nil, err
-	}
-	for _, p := range (*group).Rules {
-		portRanges = append(portRanges, network.PortRange{
-			Protocol: *p.IPProtocol,
-			FromPort: *p.FromPort,
-			ToPort:   *p.ToPort,
-		})
-	}
-	network.SortPortRanges(portRanges)
-	return portRanges, nil
-}
-
 // TODO: following 30 lines nearly verbatim from environs/ec2
 
 func (e *environ) OpenPorts(ports []network.PortRange) error {
@@ -1442,7 +1410,11 @@ func (e *environ) OpenPorts(ports []network.PortRange) error {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on environment",
 			e.Config().FirewallMode())
 	}
-	if err := e.openPortsInGroup(e.globalGroupName(), ports); err != nil {
+	fw, err := e.firewaller()
+	if err != nil {
+		return err
+	}
+	if err := fw.OpenPorts(e.globalGroupName(), ports); err != nil {
 		return err
 	}
 	logger.Infof("opened ports in global group: %v", ports)
@@ -1454,7 +1426,11 @@ func (e *environ) ClosePorts(ports []network.PortRange) error {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on environment",
 			e.Config().FirewallMode())
 	}
-	if err := e.closePortsInGroup(e.globalGroupName(), ports); err != nil {
+	fw, err := e.firewaller()
+	if err != nil {
+		return err
+	}
+	if err := fw.ClosePorts(e.globalGroupName(), ports); err != nil {
 		return err
 	}
 	logger.Infof("closed ports in global group: %v", ports)
@@ -1466,186 +1442,15 @@ func (e *environ) Ports() ([]network.PortRange, error) {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment",
 			e.Config().FirewallMode())
 	}
-	return e.portsInGroup(e.globalGroupName())
-}
-
-func (e *environ) Provider() environs.EnvironProvider {
-	return &providerInstance
-}
-
-func (e *environ) setUpGlobalGroup(groupName string, apiPort int) (nova.SecurityGroup, error) {
-	return e.ensureGroup(groupName,
-		[]nova.RuleInfo{
-			{
-				IPProtocol: "tcp",
-				FromPort:   22,
-				ToPort:     22,
-				Cidr:       "0.0.0.0/0",
-			},
-			{
-				IPProtocol: "tcp",
-				FromPort:   apiPort,
-				ToPort:     apiPort,
-				Cidr:       "0.0.0.0/0",
-			},
-			{
-				IPProtocol: "tcp",
-				FromPort:   1,
-				ToPort:     65535,
-			},
-			{
-				IPProtocol: "udp",
-				FromPort:   1,
-				ToPort:     65535,
-			},
-			{
-				IPProtocol: "icmp",
-				FromPort:   -1,
-				ToPort:     -1,
-			},
-		})
-}
-
-// setUpGroups creates the security groups for the new machine, and
-// returns them.
-//
-// Instances are tagged with a group so they can be distinguished from
-// other instances that might be running on the same OpenStack account.
-// In addition, a specific machine security group is created for each
-// machine, so that its firewall rules can be configured per machine.
-//
-// Note: ideally we'd have a better way to determine group membership so that 2
-// people that happen to share an openstack account and name their environment
-// "openstack" don't end up destroying each other's machines.
-func (e *environ) setUpGroups(machineId string, apiPort int) ([]nova.SecurityGroup, error) {
-	jujuGroup, err := e.setUpGlobalGroup(e.jujuGroupName(), apiPort)
-	if err != nil {
-		return nil, err
-	}
-	var machineGroup nova.SecurityGroup
-	switch e.Config().FirewallMode() {
-	case config.FwInstance:
-		machineGroup, err = e.ensureGroup(e.machineGroupName(machineId), nil)
-	case config.FwGlobal:
-		machineGroup, err = e.ensureGroup(e.globalGroupName(), nil)
-	}
+	fw, err := e.firewaller()
 	if err != nil {
 		return nil, err
 	}
-	groups := []nova.SecurityGroup{jujuGroup, machineGroup}
-	if e.ecfg().useDefaultSecurityGroup() {
-		defaultGroup, err := e.nova().SecurityGroupByName("default")
-		if err != nil {
-			return nil, fmt.Errorf("loading default security group: %v", err)
-		}
-		groups = append(groups, *defaultGroup)
-	}
-	return groups, nil
+	return fw.Ports(e.globalGroupName())
 }
 
-// zeroGroup holds the zero security group.
-var zeroGroup nova.SecurityGroup
-
-// ensureGroup returns the security group with name and perms.
-// If a group with name does not exist, one will be created.
-// If it exists, its permissions are set to perms.
-func (e *environ) ensureGroup(name string, rules []nova.RuleInfo) (nova.SecurityGroup, error) {
-	novaClient := e.nova()
-	// First attempt to look up an existing group by name.
-	group, err := novaClient.SecurityGroupByName(name)
-	if err == nil {
-		// Group exists, so assume it is correctly set up and return it.
-		// TODO(jam): 2013-09-18 http://pad.lv/121795
-		// We really should verify the group is set up correctly,
-		// because deleting and re-creating environments can get us bad
-		// groups (especially if they were set up under Python)
-		return *group, nil
-	}
-	// Doesn't exist, so try and create it.
-	group, err = novaClient.CreateSecurityGroup(name, "juju group")
-	if err != nil {
-		if !gooseerrors.IsDuplicateValue(err) {
-			return zeroGroup, err
-		} else {
-			// We just tried to create a duplicate group, so load the existing group.
-			group, err = novaClient.SecurityGroupByName(name)
-			if err != nil {
-				return zeroGroup, err
-			}
-			return *group, nil
-		}
-	}
-	// The new group is created so now add the rules.
-	group.Rules = make([]nova.SecurityGroupRule, len(rules))
-	for i, rule := range rules {
-		rule.ParentGroupId = group.Id
-		if rule.Cidr == "" {
-			// http://pad.lv/1226996 Rules that don't have a CIDR
-			// are meant to apply only to this group. If you don't
-			// supply CIDR or GroupId then openstack assumes you
-			// mean CIDR=0.0.0.0/0
-			rule.GroupId = &group.Id
-		}
-		groupRule, err := novaClient.CreateSecurityGroupRule(rule)
-		if err != nil && !gooseerrors.IsDuplicateValue(err) {
-			return zeroGroup, err
-		}
-		group.Rules[i] = *groupRule
-	}
-	return *group, nil
-}
-
-// deleteSecurityGroups deletes the given security groups. If a security
-// group is also used by another environment (see bug #1300755), an attempt
-// to delete this group fails. A warning is logged in this case.
-func (e *environ) deleteSecurityGroups(securityGroupNames []string) error {
-	novaclient := e.nova()
-	allSecurityGroups, err := novaclient.ListSecurityGroups()
-	if err != nil {
-		return err
-	}
-	for _, securityGroup := range allSecurityGroups {
-		for _, name := range securityGroupNames {
-			if securityGroup.Name == name {
-				deleteSecurityGroup(novaclient, name, securityGroup.Id)
-				break
-			}
-		}
-	}
-	return nil
-}
-
-// deleteSecurityGroup attempts to delete the security group. Should it fail,
-// the deletion is retried due to timing issues in openstack. A security group
-// cannot be deleted while it is in use. Theoretically we terminate all the
-// instances before we attempt to delete the associated security groups, but
-// in practice nova hasn't always finished with the instance before it
-// returns, so there is a race condition where we think the instance is
-// terminated and hence attempt to delete the security groups but nova still
-// has it around internally. To attempt to catch this timing issue, deletion
-// of the groups is tried multiple times.
-func deleteSecurityGroup(novaclient *nova.Client, name, id string) {
-	attempts := utils.AttemptStrategy{
-		Total: 30 * time.Second,
-		Delay: time.Second,
-	}
-	logger.Debugf("deleting security group %q", name)
-	i := 0
-	for attempt := attempts.Start(); attempt.Next(); {
-		err := novaclient.DeleteSecurityGroup(id)
-		if err == nil {
-			return
-		}
-		i++
-		if i%4 == 0 {
-			message := fmt.Sprintf("waiting to delete security group %q", name)
-			if i != 4 {
-				message = "still " + message
-			}
-			logger.Debugf(message)
-		}
-	}
-	logger.Warningf("cannot delete security group %q. Used by another environment?", name)
+func (e *environ) Provider() environs.EnvironProvider {
+	return &providerInstance
 }
 
 func (e *environ) terminateInstances(ids []instance.Id) error {
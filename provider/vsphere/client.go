@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
@@ -73,6 +74,7 @@ var newConnection = func(url *url.URL) (*govmomi.Client, error) {
 type instanceSpec struct {
 	machineID string
 	zone      *vmwareAvailZone
+	placement *vmwarePlacement
 	hwc       *instance.HardwareCharacteristics
 	img       *OvaFileMetadata
 	userData  []byte
@@ -200,6 +202,81 @@ func (c *client) getVm(name string) (*mo.VirtualMachine, error) {
 	return &vm, nil
 }
 
+// placementRefs holds the vCenter inventory objects in which an
+// instance is created.
+type placementRefs struct {
+	resourcePool *object.ResourcePool
+	datastore    types.ManagedObjectReference
+	folder       *object.Folder
+}
+
+// resolvePlacement finds the resource pool, datastore and folder in
+// which to create the instance in its zone, checking that any named
+// by its placement exist in the vCenter inventory and belong to the
+// zone. This is done before the instance's image is imported, so that
+// mistakes are reported early.
+func (c *client) resolvePlacement(ecfg *environConfig, spec *instanceSpec) (*placementRefs, error) {
+	zone := spec.zone
+	if len(zone.r.Datastore) == 0 {
+		return nil, errors.Errorf("zone %q has no datastores", zone.Name())
+	}
+	refs := &placementRefs{
+		resourcePool: object.NewResourcePool(c.connection.Client, *zone.r.ResourcePool),
+		datastore:    zone.r.Datastore[0],
+	}
+
+	if name := spec.placement.datastore; name != "" {
+		found := false
+		for _, ref := range zone.r.Datastore {
+			var ds mo.Datastore
+			if err := c.connection.RetrieveOne(context.TODO(), ref, []string{"name"}, &ds); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if ds.Name == name {
+				refs.datastore, found = ref, true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.NotFoundf("datastore %q in zone %q", name, zone.Name())
+		}
+	}
+
+	if poolPath := spec.placement.resourcePool; poolPath != "" {
+		pool, err := c.finder.ResourcePool(context.TODO(), poolPath)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot find resource pool %q", poolPath)
+		}
+		var moPool mo.ResourcePool
+		if err := c.connection.RetrieveOne(context.TODO(), pool.Reference(), []string{"owner"}, &moPool); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if moPool.Owner != zone.r.Self {
+			return nil, errors.Errorf("resource pool %q is not in zone %q", poolPath, zone.Name())
+		}
+		refs.resourcePool = pool
+	}
+
+	folders, err := c.datacenter.Folders(context.TODO())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	refs.folder = folders.VmFolder
+	if folderPath := spec.placement.folder; folderPath != "" {
+		index := object.NewSearchIndex(c.connection.Client)
+		ref, err := index.FindByInventoryPath(context.TODO(), path.Join("/", ecfg.datacenter(), "vm", folderPath))
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot find folder %q", folderPath)
+		}
+		folder, ok := ref.(*object.Folder)
+		if !ok {
+			return nil, errors.NotFoundf("folder %q", folderPath)
+		}
+		refs.folder = folder
+	}
+	return refs, nil
+}
+
 //AvailabilityZones retuns list of all root compute resources in the system
 func (c *client) AvailabilityZones() ([]*mo.ComputeResource, error) {
 	folders, err := c.datacenter.Folders(context.TODO())
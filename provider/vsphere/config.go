@@ -22,6 +22,9 @@ const (
 	cfgUser            = "user"
 	cfgPassword        = "password"
 	cfgExternalNetwork = "external-network"
+	cfgDatastore       = "datastore"
+	cfgResourcePool    = "resource-pool"
+	cfgFolder          = "folder"
 )
 
 // boilerplateConfig will be shown in help output, so please keep it up to
@@ -44,6 +47,23 @@ vmware:
   # This network should have ip pool configured or DHCP server connected to it.
   # This parameter is optional. 
   extenal-network:
+
+  # Name of the datastore that created vms will store their disks in.
+  # If not set, the first datastore of the vm's compute resource is used.
+  # This parameter is optional and may be overridden by a
+  # datastore=<name> placement directive.
+  # datastore:
+
+  # Inventory path of the resource pool that created vms will be put in.
+  # If not set, the root resource pool of the vm's compute resource is used.
+  # This parameter is optional and may be overridden by a
+  # resource-pool=<path> placement directive.
+  # resource-pool:
+
+  # Path, relative to the datacenter's vm folder, of the folder that
+  # created vms will be put in. This parameter is optional and may be
+  # overridden by a folder=<path> placement directive.
+  # folder:
 `[1:]
 
 // configFields is the spec for each vmware config value's type.
@@ -53,6 +73,9 @@ var configFields = schema.Fields{
 	cfgPassword:        schema.String(),
 	cfgDatacenter:      schema.String(),
 	cfgExternalNetwork: schema.String(),
+	cfgDatastore:       schema.String(),
+	cfgResourcePool:    schema.String(),
+	cfgFolder:          schema.String(),
 }
 
 var requiredFields = []string{
@@ -64,6 +87,9 @@ var requiredFields = []string{
 
 var configDefaults = schema.Defaults{
 	cfgExternalNetwork: "",
+	cfgDatastore:       "",
+	cfgResourcePool:    "",
+	cfgFolder:          "",
 }
 
 var configSecretFields = []string{
@@ -138,6 +164,18 @@ func (c *environConfig) externalNetwork() string {
 	return c.attrs[cfgExternalNetwork].(string)
 }
 
+func (c *environConfig) datastore() string {
+	return c.attrs[cfgDatastore].(string)
+}
+
+func (c *environConfig) resourcePool() string {
+	return c.attrs[cfgResourcePool].(string)
+}
+
+func (c *environConfig) folder() string {
+	return c.attrs[cfgFolder].(string)
+}
+
 func (c *environConfig) url() (*url.URL, error) {
 	return url.Parse(fmt.Sprintf("https://%s:%s@%s/sdk", c.user(), c.password(), c.host()))
 }
@@ -117,6 +117,10 @@ var newConfigTests = []configTestSpec{{
 	info:   "password cannot be empty",
 	insert: testing.Attrs{"password": ""},
 	err:    "password: must not be empty",
+}, {
+	info:   "placement defaults can be set",
+	insert: testing.Attrs{"datastore": "datastore1", "resource-pool": "/datacenter1/host/z1/Resources/juju", "folder": "juju"},
+	expect: testing.Attrs{"datastore": "datastore1", "resource-pool": "/datacenter1/host/z1/Resources/juju", "folder": "juju"},
 }, {
 	info:   "unknown field is not touched",
 	insert: testing.Attrs{"unknown-field": "12345"},
@@ -206,6 +210,10 @@ var changeConfigTests = []configTestSpec{{
 	info:   "cannot change password",
 	insert: testing.Attrs{"password": "password2"},
 	expect: testing.Attrs{"password": "password2"},
+}, {
+	info:   "can change datastore",
+	insert: testing.Attrs{"datastore": "datastore2"},
+	expect: testing.Attrs{"datastore": "datastore2"},
 }, {
 	info:   "can insert unknown field",
 	insert: testing.Attrs{"unknown": "ignoti"},
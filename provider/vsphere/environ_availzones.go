@@ -85,24 +85,21 @@ func (env *environ) availZone(name string) (*vmwareAvailZone, error) {
 var AvailabilityZoneAllocations = common.AvailabilityZoneAllocations
 
 // parseAvailabilityZones returns the availability zones that should be
-// tried for the given instance spec. If a placement argument was
-// provided then only that one is returned. Otherwise the environment is
+// tried for the given instance spec. If the placement names a zone
+// then only that one is returned. Otherwise the environment is
 // queried for available zones. In that case, the resulting list is
 // roughly ordered such that the environment's instances are spread
-// evenly across the region.
-func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([]string, error) {
-	if args.Placement != "" {
-		// args.Placement will always be a zone name or empty.
-		placement, err := env.parsePlacement(args.Placement)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return []string{placement.Name()}, nil
+// evenly across the region, or packed into as few zones as possible,
+// according to the availability-zone-policy, and limited to any zones
+// constraint.
+func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams, placement *vmwarePlacement) ([]string, error) {
+	if placement.zone != nil {
+		return []string{placement.zone.Name()}, nil
 	}
 
 	// If no availability zone is specified, then automatically spread across
 	// the known zones for optimal spread across the instance distribution
-	// group.
+	// group, or pack into them if the availability-zone-policy says so.
 	var group []instance.Id
 	var err error
 	if args.DistributionGroup != nil {
@@ -115,6 +112,14 @@ func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	var zones []string
+	if args.Constraints.HaveZones() {
+		zones = *args.Constraints.Zones
+	}
+	zoneInstances, err = common.OrderAvailabilityZones(zoneInstances, env.Config().AvailabilityZonePolicy(), zones)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	logger.Infof("found %d zones: %v", len(zoneInstances), zoneInstances)
 
 	var zoneNames []string
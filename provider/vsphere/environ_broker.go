@@ -122,7 +122,11 @@ func (env *environ) newRawInstance(args environs.StartInstanceParams, img *OvaFi
 		CpuPower: &cpuPower,
 		RootDisk: &rootDisk,
 	}
-	zones, err := env.parseAvailabilityZones(args)
+	placement, err := env.parsePlacement(args.Placement, args.Constraints)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	zones, err := env.parseAvailabilityZones(args, placement)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
@@ -141,6 +145,7 @@ func (env *environ) newRawInstance(args environs.StartInstanceParams, img *OvaFi
 		spec := &instanceSpec{
 			machineID: machineID,
 			zone:      availZone,
+			placement: placement,
 			hwc:       hwc,
 			img:       img,
 			userData:  userData,
@@ -153,6 +158,7 @@ func (env *environ) newRawInstance(args environs.StartInstanceParams, img *OvaFi
 			logger.Warningf("Error while trying to create instance in %s availability zone: %s", zone, err)
 			continue
 		}
+		hwc.AvailabilityZone = &zone
 		break
 	}
 	if err != nil {
@@ -178,6 +178,14 @@ func (s *environBrokerSuite) TestStartInstanceInvalidPlacement(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "unknown placement directive: .*")
 }
 
+func (s *environBrokerSuite) TestStartInstanceEmptyPlacementValue(c *gc.C) {
+	s.PrepareStartInstanceFakes(c)
+	startInstArgs := s.CreateStartInstanceArgs(c)
+	startInstArgs.Placement = "datastore="
+	_, err := s.Env.StartInstance(startInstArgs)
+	c.Assert(err, gc.ErrorMatches, "empty datastore in placement directive: datastore=")
+}
+
 func (s *environBrokerSuite) TestStartInstanceSelectZone(c *gc.C) {
 	client := vsphere.ExposeEnvFakeClient(s.Env)
 	s.FakeAvailabilityZones(client, "z1", "z2")
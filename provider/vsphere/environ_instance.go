@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/errors"
 
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider/common"
@@ -107,26 +108,73 @@ func (env *environ) StateServerInstances() ([]instance.Id, error) {
 	return results, nil
 }
 
-// parsePlacement extracts the availability zone from the placement
-// string and returns it. If no zone is found there then an error is
-// returned.
-func (env *environ) parsePlacement(placement string) (*vmwareAvailZone, error) {
-	if placement == "" {
-		return nil, nil
-	}
+// vmwarePlacement describes where in the vCenter inventory an instance
+// is created.
+type vmwarePlacement struct {
+	// zone is the compute resource to use. If nil, the zones are
+	// tried in the order chosen by the availability-zone-policy.
+	zone *vmwareAvailZone
+
+	// datastore is the name of the datastore to store the instance's
+	// disks in. If empty, the zone's first datastore is used.
+	datastore string
+
+	// resourcePool is the inventory path of the resource pool to
+	// create the instance in. If empty, the zone's root resource
+	// pool is used.
+	resourcePool string
+
+	// folder is the inventory path, relative to the datacenter's VM
+	// folder, of the folder to create the instance in. If empty, the
+	// VM folder itself is used.
+	folder string
+}
 
-	pos := strings.IndexRune(placement, '=')
-	if pos == -1 {
-		return nil, errors.Errorf("unknown placement directive: %v", placement)
+// parsePlacement returns the placement given by the environment's
+// datastore, resource-pool and folder settings, overridden by the
+// root-disk-source constraint and then by the placement string. The
+// placement string holds comma-separated zone=, datastore=,
+// resource-pool= and folder= directives; a zone directive must name
+// an existing zone. The datastore, resource pool and folder are
+// checked against the vCenter inventory when the instance is created.
+func (env *environ) parsePlacement(placement string, cons constraints.Value) (*vmwarePlacement, error) {
+	result := &vmwarePlacement{
+		datastore:    env.ecfg.datastore(),
+		resourcePool: env.ecfg.resourcePool(),
+		folder:       env.ecfg.folder(),
+	}
+	if cons.HasRootDiskSource() {
+		result.datastore = *cons.RootDiskSource
+	}
+	if placement == "" {
+		return result, nil
 	}
 
-	switch key, value := placement[:pos], placement[pos+1:]; key {
-	case "zone":
-		zone, err := env.availZone(value)
-		if err != nil {
-			return nil, errors.Trace(err)
+	for _, directive := range strings.Split(placement, ",") {
+		pos := strings.IndexRune(directive, '=')
+		if pos == -1 {
+			return nil, errors.Errorf("unknown placement directive: %v", placement)
+		}
+		key, value := directive[:pos], directive[pos+1:]
+		if value == "" {
+			return nil, errors.Errorf("empty %s in placement directive: %v", key, placement)
+		}
+		switch key {
+		case "zone":
+			zone, err := env.availZone(value)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			result.zone = zone
+		case "datastore":
+			result.datastore = value
+		case "resource-pool":
+			result.resourcePool = value
+		case "folder":
+			result.folder = value
+		default:
+			return nil, errors.Errorf("unknown placement directive: %v", placement)
 		}
-		return zone, nil
 	}
-	return nil, errors.Errorf("unknown placement directive: %v", placement)
+	return result, nil
 }
@@ -20,7 +20,7 @@ import (
 // are valid for use in creating an instance in this environment.
 func (env *environ) PrecheckInstance(series string, cons constraints.Value, placement string) error {
 	if placement != "" {
-		if _, err := env.parsePlacement(placement); err != nil {
+		if _, err := env.parsePlacement(placement, cons); err != nil {
 			return err
 		}
 	}
@@ -71,6 +71,8 @@ func (env *environ) lookupArchitectures() ([]string, error) {
 var unsupportedConstraints = []string{
 	constraints.Tags,
 	constraints.Networks,
+	constraints.VirtType,
+	constraints.InstanceRole,
 }
 
 // instanceTypeConstraints defines the fields defined on each of the
@@ -59,6 +59,17 @@ func (s *environPolSuite) TestConstraintsValidatorUnsupported(c *gc.C) {
 	c.Check(unsupported, jc.DeepEquals, []string{"tags"})
 }
 
+func (s *environPolSuite) TestConstraintsValidatorRootDiskSource(c *gc.C) {
+	validator, err := s.Env.ConstraintsValidator()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cons := constraints.MustParse("arch=amd64 root-disk-source=datastore1 virt-type=hvm instance-role=juju")
+	unsupported, err := validator.Validate(cons)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(unsupported, jc.SameContents, []string{"virt-type", "instance-role"})
+}
+
 func (s *environPolSuite) TestConstraintsValidatorVocabArch(c *gc.C) {
 	validator, err := s.Env.ConstraintsValidator()
 	c.Assert(err, jc.ErrorIsNil)
@@ -52,7 +52,7 @@ type ovaImportManager struct {
 }
 
 func (m *ovaImportManager) importOva(ecfg *environConfig, instSpec *instanceSpec) (*object.VirtualMachine, error) {
-	folders, err := m.client.datacenter.Folders(context.TODO())
+	refs, err := m.client.resolvePlacement(ecfg, instSpec)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -80,9 +80,8 @@ func (m *ovaImportManager) importOva(ecfg *environConfig, instSpec *instanceSpec
 	}
 
 	ovfManager := object.NewOvfManager(m.client.connection.Client)
-	resourcePool := object.NewReference(m.client.connection.Client, *instSpec.zone.r.ResourcePool)
-	datastore := object.NewReference(m.client.connection.Client, instSpec.zone.r.Datastore[0])
-	spec, err := ovfManager.CreateImportSpec(context.TODO(), string(ovf), resourcePool, datastore, cisp)
+	datastore := object.NewReference(m.client.connection.Client, refs.datastore)
+	spec, err := ovfManager.CreateImportSpec(context.TODO(), string(ovf), refs.resourcePool, datastore, cisp)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -132,8 +131,7 @@ func (m *ovaImportManager) importOva(ecfg *environConfig, instSpec *instanceSpec
 			},
 		})
 	}
-	rp := object.NewResourcePool(m.client.connection.Client, *instSpec.zone.r.ResourcePool)
-	lease, err := rp.ImportVApp(context.TODO(), spec.ImportSpec, folders.VmFolder, nil)
+	lease, err := refs.resourcePool.ImportVApp(context.TODO(), spec.ImportSpec, refs.folder, nil)
 	if err != nil {
 		return nil, errors.Annotatef(err, "failed to import vapp")
 	}
@@ -38,6 +38,9 @@ var (
 		"user":             "user1",
 		"password":         "password1",
 		"external-network": "",
+		"datastore":        "",
+		"resource-pool":    "",
+		"folder":           "",
 	})
 )
 
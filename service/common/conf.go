@@ -5,6 +5,8 @@ package common
 
 import (
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/juju/errors"
@@ -24,6 +26,27 @@ type Conf struct {
 	// service will not start until after the other stops.
 	AfterStopped string
 
+	// After lists the services or targets (e.g. "network-online.target")
+	// that must have started before this service is started. Names
+	// without a suffix are taken to be services.
+	After []string
+
+	// Requires lists the services or targets this service cannot run
+	// without. The service is not started if one of them fails to
+	// start, and is stopped when one of them stops.
+	Requires []string
+
+	// Wants lists the services or targets that should be started along
+	// with this service. Unlike Requires, a failure to start one of them
+	// does not prevent this service from starting.
+	// Ignored on Windows, which has no weak dependencies.
+	Wants []string
+
+	// WantedBy is the target that pulls the service in when the host
+	// boots. If empty then the normal multi-user target is used.
+	// Only supported by systemd.
+	WantedBy string
+
 	// Env holds the environment variables that will be set when the
 	// command runs.
 	// Currently not used on Windows.
@@ -36,19 +59,75 @@ type Conf struct {
 	// Currently not used on Windows.
 	Limit map[string]int
 
+	// User is the name (or numeric ID) of the user under which the
+	// service's commands will run. If empty then the init system's
+	// default (usually root) is used.
+	// Not supported on Windows.
+	User string
+
+	// Group is the name (or numeric ID) of the group under which the
+	// service's commands will run. If empty then the primary group of
+	// User is used.
+	// Not supported on Windows.
+	Group string
+
+	// UMask is the file mode creation mask (in octal, e.g. "0022") that
+	// will be set for the service's commands. If empty then the init
+	// system's default is used.
+	// Not supported on Windows.
+	UMask string
+
+	// WorkingDirectory is the directory in which the service's commands
+	// will run. The path must be absolute.
+	// Not supported on Windows.
+	WorkingDirectory string
+
 	// Timeout is how many seconds may pass before an exec call (e.g.
 	// ExecStart) times out. Values less than or equal to 0 (the
 	// default) are treated as though there is no timeout.
 	Timeout int
 
+	// TimeoutStopSec is how many seconds the init system will wait
+	// for the service to stop gracefully before forcibly killing it.
+	// Values less than or equal to 0 (the default) leave the init
+	// system's default in place.
+	// Not supported on Windows.
+	TimeoutStopSec int
+
+	// KillMode determines which of the service's processes are
+	// signalled when it is stopped. It must be one of "control-group",
+	// "process", "mixed", or "none". If empty then the init system's
+	// default is used.
+	// Only supported by systemd.
+	KillMode string
+
+	// KillSignal is the name of the signal (e.g. "SIGTERM") initially
+	// sent to the service's processes when it is stopped. If empty
+	// then the init system's default is used.
+	// Not supported on Windows.
+	KillSignal string
+
 	// ExecStart is the command (with arguments) that will be run. The
 	// path to the executable must be absolute.
 	// The command will be restarted if it exits with a non-zero exit code.
 	ExecStart string
 
-	// ExecStopPost is the command that will be run after the service stops.
-	// The path to the executable must be absolute.
-	ExecStopPost string
+	// ExecStartPre holds the commands that will be run, in order,
+	// before ExecStart. The path to each executable must be absolute.
+	// Not supported on Windows.
+	ExecStartPre []string
+
+	// ExecStartPost holds the commands that will be run, in order,
+	// after ExecStart has been started. The path to each executable
+	// must be absolute.
+	// Not supported on Windows.
+	ExecStartPost []string
+
+	// ExecStopPost holds the commands that will be run, in order,
+	// after the service stops. The path to each executable must be
+	// absolute.
+	// Not supported on Windows.
+	ExecStopPost []string
 
 	// Logfile, if set, indicates where the service's output should be
 	// written.
@@ -74,32 +153,125 @@ func (c Conf) IsZero() bool {
 // Validate checks the conf's values for correctness.
 func (c Conf) Validate(renderer shell.Renderer) error {
 	if c.Desc == "" {
-		return errors.New("missing Desc")
+		return ConfInvalidf("Desc", "missing Desc")
+	}
+	if hasControl(c.Desc) {
+		return ConfInvalidf("Desc", "Desc %q (control characters) not valid", c.Desc)
 	}
 
 	// Check the Exec* fields.
 	if c.ExecStart == "" {
-		return errors.New("missing ExecStart")
+		return ConfInvalidf("ExecStart", "missing ExecStart")
+	}
+	if err := c.checkExec("ExecStart", c.ExecStart, renderer); err != nil {
+		return errors.Trace(err)
 	}
-	for field, cmd := range map[string]string{
-		"ExecStart":    c.ExecStart,
-		"ExecStopPost": c.ExecStopPost,
+	for field, cmds := range map[string][]string{
+		"ExecStartPre":  c.ExecStartPre,
+		"ExecStartPost": c.ExecStartPost,
+		"ExecStopPost":  c.ExecStopPost,
 	} {
-		if cmd == "" {
-			continue
+		for _, cmd := range cmds {
+			if strings.TrimSpace(cmd) == "" {
+				return ConfInvalidf(field, "empty %s entry not valid", field)
+			}
+			if err := c.checkExec(field, cmd, renderer); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	if err := c.checkDependencies(); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := c.checkOwnership(); err != nil {
+		return errors.Trace(err)
+	}
+
+	for name := range c.Env {
+		if err := ValidateEnvName(name); err != nil {
+			return ConfInvalidf("Env", "Env key %q not valid", name)
 		}
-		if err := c.checkExec(field, cmd, renderer); err != nil {
-			return errors.Trace(err)
+	}
+
+	if c.UMask != "" {
+		mask, err := strconv.ParseUint(c.UMask, 8, 32)
+		if err != nil || mask > 0777 {
+			return ConfInvalidf("UMask", "UMask %q not valid", c.UMask)
 		}
 	}
 
+	if c.WorkingDirectory != "" && !renderer.IsAbs(c.WorkingDirectory) {
+		return ConfInvalidf("WorkingDirectory", "relative path in WorkingDirectory (%s) not valid", c.WorkingDirectory)
+	}
+
+	if err := c.checkKill(); err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+func (c Conf) checkDependencies() error {
+	for field, names := range map[string][]string{
+		"After":    c.After,
+		"Requires": c.Requires,
+		"Wants":    c.Wants,
+	} {
+		for _, name := range names {
+			if name == "" || strings.ContainsAny(name, " \t\n") {
+				return ConfInvalidf(field, "%s entry %q not valid", field, name)
+			}
+		}
+	}
+	if strings.ContainsAny(c.WantedBy, " \t\n") {
+		return ConfInvalidf("WantedBy", "WantedBy %q not valid", c.WantedBy)
+	}
+	return nil
+}
+
+// accountNameRE matches the user and group values accepted by both
+// systemd (User=/Group=) and upstart (setuid/setgid): either a numeric
+// ID or a name made of the characters allowed by useradd/groupadd.
+// In particular it rules out whitespace and ":", which would otherwise
+// corrupt the generated unit or the chown commands.
+var accountNameRE = regexp.MustCompile(`^([0-9]+|[A-Za-z_][A-Za-z0-9_.-]*[$]?)$`)
+
+func (c Conf) checkOwnership() error {
+	if c.User != "" && !accountNameRE.MatchString(c.User) {
+		return ConfInvalidf("User", "User %q not valid", c.User)
+	}
+	if c.Group != "" && !accountNameRE.MatchString(c.Group) {
+		return ConfInvalidf("Group", "Group %q not valid", c.Group)
+	}
+	return nil
+}
+
+var (
+	killModes = map[string]bool{
+		"control-group": true,
+		"process":       true,
+		"mixed":         true,
+		"none":          true,
+	}
+	killSignalRE = regexp.MustCompile(`^SIG[A-Z0-9]+$`)
+)
+
+func (c Conf) checkKill() error {
+	if c.KillMode != "" && !killModes[c.KillMode] {
+		return ConfInvalidf("KillMode", "KillMode %q not valid", c.KillMode)
+	}
+	if c.KillSignal != "" && !killSignalRE.MatchString(c.KillSignal) {
+		return ConfInvalidf("KillSignal", "KillSignal %q not valid", c.KillSignal)
+	}
 	return nil
 }
 
 func (c Conf) checkExec(name, cmd string, renderer shell.Renderer) error {
 	path := executable(cmd)
 	if !renderer.IsAbs(path) {
-		return errors.NotValidf("relative path in %s (%s)", name, path)
+		return ConfInvalidf(name, "relative path in %s (%s) not valid", name, path)
 	}
 	return nil
 }
@@ -4,6 +4,7 @@
 package common_test
 
 import (
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils/shell"
@@ -109,9 +110,215 @@ func (*confSuite) TestValidateRelativeExecStopPost(c *gc.C) {
 	conf := common.Conf{
 		Desc:         "some service",
 		ExecStart:    "/path/to/some-command a b c",
-		ExecStopPost: "some-other-command a b c",
+		ExecStopPost: []string{"some-other-command a b c"},
 	}
 	err := conf.Validate(renderer)
 
 	c.Check(err, gc.ErrorMatches, `.*relative path in ExecStopPost \(.*`)
 }
+
+func (*confSuite) TestValidateHooksOkay(c *gc.C) {
+	conf := common.Conf{
+		Desc:          "some service",
+		ExecStart:     "/path/to/some-command a b c",
+		ExecStartPre:  []string{"/path/to/setup", "/path/to/check x"},
+		ExecStartPost: []string{"/path/to/notify"},
+		ExecStopPost:  []string{"/path/to/cleanup"},
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (*confSuite) TestValidateRelativeExecStartPre(c *gc.C) {
+	conf := common.Conf{
+		Desc:         "some service",
+		ExecStart:    "/path/to/some-command a b c",
+		ExecStartPre: []string{"/path/to/setup", "check x"},
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*relative path in ExecStartPre \(check\).*`)
+}
+
+func (*confSuite) TestValidateEmptyExecStartPost(c *gc.C) {
+	conf := common.Conf{
+		Desc:          "some service",
+		ExecStart:     "/path/to/some-command a b c",
+		ExecStartPost: []string{" "},
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `empty ExecStartPost entry not valid`)
+}
+
+func (*confSuite) TestValidateOwnershipOkay(c *gc.C) {
+	conf := common.Conf{
+		Desc:             "some service",
+		ExecStart:        "/path/to/some-command a b c",
+		User:             "some-user",
+		Group:            "some_group",
+		UMask:            "0027",
+		WorkingDirectory: "/var/lib/some-service",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (*confSuite) TestValidateOwnershipNumericIDs(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		User:      "1001",
+		Group:     "1002",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (*confSuite) TestValidateBadUser(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		User:      "some user",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `.*User "some user" not valid.*`)
+}
+
+func (*confSuite) TestValidateBadGroup(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		Group:     "Some:Group",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*Group "Some:Group" not valid.*`)
+}
+
+func (*confSuite) TestValidateBadUMask(c *gc.C) {
+	for _, mask := range []string{"0999", "abc", "01777"} {
+		c.Logf("checking UMask %q", mask)
+		conf := common.Conf{
+			Desc:      "some service",
+			ExecStart: "/path/to/some-command a b c",
+			UMask:     mask,
+		}
+		err := conf.Validate(renderer)
+
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (*confSuite) TestValidateRelativeWorkingDirectory(c *gc.C) {
+	conf := common.Conf{
+		Desc:             "some service",
+		ExecStart:        "/path/to/some-command a b c",
+		WorkingDirectory: "some/dir",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*relative path in WorkingDirectory \(.*`)
+}
+
+func (*confSuite) TestValidateKillOkay(c *gc.C) {
+	conf := common.Conf{
+		Desc:           "some service",
+		ExecStart:      "/path/to/some-command a b c",
+		TimeoutStopSec: 90,
+		KillMode:       "mixed",
+		KillSignal:     "SIGINT",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (*confSuite) TestValidateBadKillMode(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		KillMode:  "everything",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*KillMode "everything" not valid.*`)
+}
+
+func (*confSuite) TestValidateBadKillSignal(c *gc.C) {
+	conf := common.Conf{
+		Desc:       "some service",
+		ExecStart:  "/path/to/some-command a b c",
+		KillSignal: "term",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*KillSignal "term" not valid.*`)
+}
+
+func (*confSuite) TestValidateDependenciesOkay(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		After:     []string{"network-online.target", "jujud-machine-0"},
+		Requires:  []string{"jujud-machine-0"},
+		Wants:     []string{"network-online.target"},
+		WantedBy:  "multi-user.target",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (*confSuite) TestValidateBadDependency(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		Requires:  []string{"jujud machine-0"},
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*Requires entry "jujud machine-0" not valid.*`)
+}
+
+func (*confSuite) TestValidateConfInvalidField(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		UMask:     "0999",
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	field, ok := common.ConfInvalidField(err)
+	c.Check(ok, jc.IsTrue)
+	c.Check(field, gc.Equals, "UMask")
+}
+
+func (*confSuite) TestValidateDescControlCharacters(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some\nservice",
+		ExecStart: "/path/to/some-command a b c",
+	}
+	err := conf.Validate(renderer)
+
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "Desc")
+}
+
+func (*confSuite) TestValidateBadEnvName(c *gc.C) {
+	conf := common.Conf{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command a b c",
+		Env:       map[string]string{"A B": "c"},
+	}
+	err := conf.Validate(renderer)
+
+	c.Check(err, gc.ErrorMatches, `.*Env key "A B" not valid.*`)
+}
@@ -0,0 +1,169 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// TimeoutError is returned when the init system does not finish an
+// operation on a service in the allowed time. The operation may still
+// complete later, so callers may retry or escalate (e.g. by killing
+// the service's processes).
+type TimeoutError struct {
+	// Service is the name of the service.
+	Service string
+
+	// Op is the operation that timed out (e.g. "start").
+	Op string
+
+	// Timeout is how long the operation was given.
+	Timeout time.Duration
+}
+
+// Error implements error.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %v waiting to %s service %q", e.Timeout, e.Op, e.Service)
+}
+
+// IsTimeout returns whether or not the cause of the provided error
+// is a *TimeoutError.
+func IsTimeout(err error) bool {
+	_, ok := errors.Cause(err).(*TimeoutError)
+	return ok
+}
+
+// JobFailedError is returned when the init system finishes an
+// operation on a service without success. Unlike a timeout, retrying
+// the operation is unlikely to help without fixing the service.
+type JobFailedError struct {
+	// Service is the name of the service.
+	Service string
+
+	// Op is the operation that failed (e.g. "start").
+	Op string
+
+	// Result is the init system's description of the outcome
+	// (e.g. systemd's job result "failed" or "dependency").
+	Result string
+}
+
+// Error implements error.
+func (e *JobFailedError) Error() string {
+	return fmt.Sprintf("failed to %s service %q (job result %q)", e.Op, e.Service, e.Result)
+}
+
+// IsJobFailed returns whether or not the cause of the provided error
+// is a *JobFailedError.
+func IsJobFailed(err error) bool {
+	_, ok := errors.Cause(err).(*JobFailedError)
+	return ok
+}
+
+// NotInstalledError describes a service that is not installed in the
+// init system. Use NewNotInstalledError to create one.
+type NotInstalledError struct {
+	// Service is the name of the service.
+	Service string
+}
+
+// Error implements error.
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("service %q not installed", e.Service)
+}
+
+// NewNotInstalledError returns a NotFound error for the named service,
+// which IsNotInstalled recognizes.
+func NewNotInstalledError(service string) error {
+	return errors.NewNotFound(&NotInstalledError{Service: service}, "")
+}
+
+// IsNotInstalled returns whether or not the provided error was caused
+// by a service not being installed.
+func IsNotInstalled(err error) bool {
+	return findError(err, func(err error) bool {
+		_, ok := err.(*NotInstalledError)
+		return ok
+	})
+}
+
+// ConfInvalidError describes a Conf field with an unacceptable value.
+// Use ConfInvalidf to create one.
+type ConfInvalidError struct {
+	// Field is the name of the offending Conf field (e.g. "ExecStart").
+	Field string
+
+	// Reason describes what is wrong with the field's value.
+	Reason string
+}
+
+// Error implements error.
+func (e *ConfInvalidError) Error() string {
+	return e.Reason
+}
+
+// ConfInvalidf returns a NotValid error for the named Conf field,
+// which IsConfInvalid recognizes. The reason is formatted with
+// fmt.Sprintf.
+func ConfInvalidf(field, format string, args ...interface{}) error {
+	err := &ConfInvalidError{
+		Field:  field,
+		Reason: fmt.Sprintf(format, args...),
+	}
+	return errors.NewNotValid(err, "")
+}
+
+// ConfNotSupportedf returns a NotSupported error for the named Conf
+// field, for a value the init system cannot honour. Like the errors
+// returned by ConfInvalidf, ConfInvalidField recognizes it. The reason
+// is formatted with fmt.Sprintf.
+func ConfNotSupportedf(field, format string, args ...interface{}) error {
+	err := &ConfInvalidError{
+		Field:  field,
+		Reason: fmt.Sprintf(format, args...),
+	}
+	return errors.NewNotSupported(err, "")
+}
+
+// IsConfInvalid returns whether or not the provided error was caused
+// by an invalid Conf field.
+func IsConfInvalid(err error) bool {
+	_, ok := ConfInvalidField(err)
+	return ok
+}
+
+// ConfInvalidField returns the name of the offending Conf field if the
+// provided error was caused by an invalid Conf field.
+func ConfInvalidField(err error) (string, bool) {
+	var field string
+	found := findError(err, func(err error) bool {
+		confErr, ok := err.(*ConfInvalidError)
+		if ok {
+			field = confErr.Field
+		}
+		return ok
+	})
+	return field, found
+}
+
+// findError walks the chain of errors wrapped by err (using the juju
+// errors package) and reports whether any of them matches.
+func findError(err error, match func(error) bool) bool {
+	for err != nil {
+		if match(err) {
+			return true
+		}
+		wrapper, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return false
+		}
+		err = wrapper.Underlying()
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+)
+
+type errorsSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&errorsSuite{})
+
+func (*errorsSuite) TestTimeoutError(c *gc.C) {
+	err := &common.TimeoutError{
+		Service: "jujud-machine-0",
+		Op:      "start",
+		Timeout: time.Minute,
+	}
+
+	c.Check(err, gc.ErrorMatches, `timed out after 1m0s waiting to start service "jujud-machine-0"`)
+}
+
+func (*errorsSuite) TestIsTimeout(c *gc.C) {
+	err := errors.Annotate(&common.TimeoutError{Op: "stop"}, "failed")
+
+	c.Check(common.IsTimeout(err), jc.IsTrue)
+	c.Check(common.IsTimeout(errors.New("failed")), jc.IsFalse)
+}
+
+func (*errorsSuite) TestJobFailedError(c *gc.C) {
+	err := &common.JobFailedError{
+		Service: "jujud-machine-0",
+		Op:      "start",
+		Result:  "dependency",
+	}
+
+	c.Check(err, gc.ErrorMatches, `failed to start service "jujud-machine-0" \(job result "dependency"\)`)
+}
+
+func (*errorsSuite) TestIsJobFailed(c *gc.C) {
+	err := errors.Annotate(&common.JobFailedError{Op: "start"}, "failed")
+
+	c.Check(common.IsJobFailed(err), jc.IsTrue)
+	c.Check(common.IsJobFailed(&common.TimeoutError{}), jc.IsFalse)
+}
+
+func (*errorsSuite) TestNotInstalledError(c *gc.C) {
+	err := common.NewNotInstalledError("jujud-machine-0")
+
+	c.Check(err, gc.ErrorMatches, `service "jujud-machine-0" not installed`)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
+}
+
+func (*errorsSuite) TestIsNotInstalled(c *gc.C) {
+	err := errors.Annotate(common.NewNotInstalledError("jujud-machine-0"), "failed")
+
+	c.Check(common.IsNotInstalled(errors.Trace(err)), jc.IsTrue)
+	c.Check(common.IsNotInstalled(errors.NotFoundf("service")), jc.IsFalse)
+}
+
+func (*errorsSuite) TestConfInvalidf(c *gc.C) {
+	err := common.ConfInvalidf("User", "User %q not valid", "some user")
+
+	c.Check(err, gc.ErrorMatches, `User "some user" not valid`)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, jc.Satisfies, common.IsConfInvalid)
+}
+
+func (*errorsSuite) TestConfNotSupportedf(c *gc.C) {
+	err := common.ConfNotSupportedf("KillMode", "Conf.KillMode not supported")
+
+	c.Check(err, gc.ErrorMatches, `Conf.KillMode not supported`)
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	field, ok := common.ConfInvalidField(errors.Trace(err))
+	c.Check(ok, jc.IsTrue)
+	c.Check(field, gc.Equals, "KillMode")
+}
+
+func (*errorsSuite) TestConfInvalidField(c *gc.C) {
+	err := errors.Annotate(common.ConfInvalidf("ExecStart", "missing ExecStart"), "failed")
+
+	field, ok := common.ConfInvalidField(errors.Trace(err))
+	c.Check(ok, jc.IsTrue)
+	c.Check(field, gc.Equals, "ExecStart")
+
+	_, ok = common.ConfInvalidField(errors.NotValidf("ExecStart"))
+	c.Check(ok, jc.IsFalse)
+}
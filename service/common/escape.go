@@ -0,0 +1,186 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// This file holds the quoting rules for the values that end up in the
+// init systems' conf files. Each Quote function has an Unquote that
+// reverses it exactly, so a conf read back from disk matches the conf
+// it was written from.
+
+// envNameRE matches the environment variable names that every init
+// system accepts.
+var envNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnvName checks that the provided name may be used for an
+// environment variable.
+func ValidateEnvName(name string) error {
+	if !envNameRE.MatchString(name) {
+		return errors.NotValidf("environment variable name %q", name)
+	}
+	return nil
+}
+
+// hasControl reports whether the string holds any ASCII control
+// characters (e.g. newlines), other than tabs.
+func hasControl(s string) bool {
+	for _, r := range s {
+		if r != '\t' && (r < 0x20 || r == 0x7f) {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeSpecifiers escapes the "%" characters in the value, which
+// systemd would otherwise expand as unit specifiers (e.g. "%n").
+func EscapeSpecifiers(value string) string {
+	return strings.Replace(value, "%", "%%", -1)
+}
+
+// UnescapeSpecifiers reverses EscapeSpecifiers.
+func UnescapeSpecifiers(value string) string {
+	return strings.Replace(value, "%%", "%", -1)
+}
+
+// systemdEscapes maps the characters that are backslash-escaped in
+// a quoted systemd value to their escape letter.
+var systemdEscapes = map[rune]rune{
+	'\\': '\\',
+	'"':  '"',
+	'\n': 'n',
+	'\r': 'r',
+	'\t': 't',
+}
+
+// QuoteSystemdEnv returns the value of an "Environment=" directive
+// that sets the named variable to the provided value. The assignment
+// is double-quoted, with C-style escapes for backslashes, quotes and
+// line breaks, so any value survives intact.
+func QuoteSystemdEnv(name, value string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	buf.WriteString(name)
+	buf.WriteByte('=')
+	for _, r := range EscapeSpecifiers(value) {
+		if esc, ok := systemdEscapes[r]; ok {
+			buf.WriteByte('\\')
+			buf.WriteRune(esc)
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// UnquoteSystemdEnv reverses QuoteSystemdEnv, returning the variable's
+// name and value. A single unquoted assignment is accepted too.
+func UnquoteSystemdEnv(directive string) (name, value string, err error) {
+	assignment := directive
+	if strings.HasPrefix(directive, `"`) {
+		if len(directive) < 2 || !strings.HasSuffix(directive, `"`) {
+			return "", "", errors.NotValidf("environment assignment %s", directive)
+		}
+		assignment, err = unescapeSystemd(directive[1 : len(directive)-1])
+		if err != nil {
+			return "", "", errors.Annotatef(err, "environment assignment %s", directive)
+		}
+	} else if strings.ContainsAny(directive, " \t\\") {
+		return "", "", errors.NotSupportedf("environment assignment %s (several or escaped)", directive)
+	}
+	parts := strings.SplitN(assignment, "=", 2)
+	if len(parts) != 2 {
+		return "", "", errors.NotValidf("environment assignment %s", directive)
+	}
+	if err := ValidateEnvName(parts[0]); err != nil {
+		return "", "", errors.Trace(err)
+	}
+	return parts[0], UnescapeSpecifiers(parts[1]), nil
+}
+
+func unescapeSystemd(value string) (string, error) {
+	var buf bytes.Buffer
+	escaped := false
+	for _, r := range value {
+		if !escaped {
+			switch r {
+			case '\\':
+				escaped = true
+			case '"':
+				return "", errors.NotValidf("unescaped quote")
+			default:
+				buf.WriteRune(r)
+			}
+			continue
+		}
+		escaped = false
+		found := false
+		for orig, esc := range systemdEscapes {
+			if r == esc {
+				buf.WriteRune(orig)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", errors.NotSupportedf("escape sequence %q", `\`+string(r))
+		}
+	}
+	if escaped {
+		return "", errors.NotValidf("trailing backslash")
+	}
+	return buf.String(), nil
+}
+
+// QuoteUpstart returns the value double-quoted as upstart (libnih)
+// expects, for use in the "description" and "env" stanzas. Upstart
+// has no way to escape a line break, so the value must not hold one;
+// see CheckUpstartValue.
+func QuoteUpstart(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// UnquoteUpstart reverses QuoteUpstart.
+func UnquoteUpstart(quoted string) (string, error) {
+	if len(quoted) < 2 || !strings.HasPrefix(quoted, `"`) || !strings.HasSuffix(quoted, `"`) {
+		return "", errors.NotValidf("upstart value %s (not quoted)", quoted)
+	}
+	var buf bytes.Buffer
+	escaped := false
+	for _, r := range quoted[1 : len(quoted)-1] {
+		switch {
+		case escaped:
+			escaped = false
+			buf.WriteRune(r)
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			return "", errors.NotValidf("upstart value %s (unescaped quote)", quoted)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	if escaped {
+		return "", errors.NotValidf("upstart value %s (trailing backslash)", quoted)
+	}
+	return buf.String(), nil
+}
+
+// CheckUpstartValue returns an error if the value cannot be quoted
+// for upstart.
+func CheckUpstartValue(value string) error {
+	if hasControl(value) {
+		return errors.NotValidf("upstart value %q (control characters)", value)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"math/rand"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+)
+
+type escapeSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&escapeSuite{})
+
+// awkwardRunes are the characters that have a special meaning to at
+// least one init system.
+var awkwardRunes = []rune(" \t\n\r\"'\\%$=;&|<>{}#`é\x00a")
+
+// randomValues returns values made up of the awkward characters. The
+// random source is seeded so failures can be reproduced.
+func randomValues(count int, exclude func(rune) bool) []string {
+	rnd := rand.New(rand.NewSource(1))
+	var values []string
+	for i := 0; i < count; i++ {
+		var value []rune
+		for j := rnd.Intn(12); j >= 0; j-- {
+			r := awkwardRunes[rnd.Intn(len(awkwardRunes))]
+			if exclude == nil || !exclude(r) {
+				value = append(value, r)
+			}
+		}
+		values = append(values, string(value))
+	}
+	return values
+}
+
+func (*escapeSuite) TestValidateEnvName(c *gc.C) {
+	for _, name := range []string{"A", "_", "JUJU_DEV_FEATURE_FLAGS", "a1"} {
+		c.Check(common.ValidateEnvName(name), jc.ErrorIsNil)
+	}
+	for _, name := range []string{"", "1A", "A-B", "A B", "A=B", "A\n"} {
+		c.Check(common.ValidateEnvName(name), jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (*escapeSuite) TestEscapeSpecifiers(c *gc.C) {
+	for value, expected := range map[string]string{
+		"":        "",
+		"abc":     "abc",
+		"%n":      "%%n",
+		"100%":    "100%%",
+		"%%":      "%%%%",
+		"a%b%%c%": "a%%b%%%%c%%",
+	} {
+		escaped := common.EscapeSpecifiers(value)
+		c.Check(escaped, gc.Equals, expected)
+		c.Check(common.UnescapeSpecifiers(escaped), gc.Equals, value)
+	}
+}
+
+func (*escapeSuite) TestQuoteSystemdEnv(c *gc.C) {
+	for value, expected := range map[string]string{
+		"":          `"A="`,
+		"b":         `"A=b"`,
+		"b c":       `"A=b c"`,
+		`"quoted"`:  `"A=\"quoted\""`,
+		`C:\`:       `"A=C:\\"`,
+		"one\ntwo":  `"A=one\ntwo"`,
+		"tab\there": `"A=tab\there"`,
+		"cr\r":      `"A=cr\r"`,
+		"50%":       `"A=50%%"`,
+		"$HOME":     `"A=$HOME"`,
+		"x=y":       `"A=x=y"`,
+	} {
+		c.Check(common.QuoteSystemdEnv("A", value), gc.Equals, expected)
+	}
+}
+
+func (*escapeSuite) TestUnquoteSystemdEnvUnquoted(c *gc.C) {
+	name, value, err := common.UnquoteSystemdEnv("A=b")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(name, gc.Equals, "A")
+	c.Check(value, gc.Equals, "b")
+}
+
+func (*escapeSuite) TestUnquoteSystemdEnvInvalid(c *gc.C) {
+	for directive, expected := range map[string]string{
+		`"`:         `environment assignment " not valid`,
+		`"A=b`:      `environment assignment "A=b not valid`,
+		`"A"`:       `environment assignment "A" not valid`,
+		`"A=b"c"`:   `environment assignment "A=b"c": unescaped quote not valid`,
+		`"A=b\"`:    `environment assignment "A=b\\": trailing backslash not valid`,
+		`"A=\x"`:    `environment assignment "A=\\x": escape sequence "\\\\x" not supported`,
+		`"1A=b"`:    `environment variable name "1A" not valid`,
+		`A=b C=d`:   `environment assignment A=b C=d \(several or escaped\) not supported`,
+		`NOEQUALS`:  `environment assignment NOEQUALS not valid`,
+		`"A B=c d"`: `environment variable name "A B" not valid`,
+	} {
+		c.Logf("checking %s", directive)
+		_, _, err := common.UnquoteSystemdEnv(directive)
+		c.Check(err, gc.ErrorMatches, expected)
+	}
+}
+
+func (*escapeSuite) TestSystemdEnvRoundTrip(c *gc.C) {
+	for _, value := range randomValues(500, nil) {
+		name, result, err := common.UnquoteSystemdEnv(common.QuoteSystemdEnv("A", value))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(name, gc.Equals, "A")
+		c.Check(result, gc.Equals, value)
+	}
+}
+
+func (*escapeSuite) TestQuoteUpstart(c *gc.C) {
+	for value, expected := range map[string]string{
+		"":         `""`,
+		"b c":      `"b c"`,
+		`"quoted"`: `"\"quoted\""`,
+		`C:\`:      `"C:\\"`,
+		"it's":     `"it's"`,
+		"50%":      `"50%"`,
+		"tab\t":    "\"tab\t\"",
+	} {
+		c.Check(common.QuoteUpstart(value), gc.Equals, expected)
+	}
+}
+
+func (*escapeSuite) TestUnquoteUpstartInvalid(c *gc.C) {
+	for _, quoted := range []string{``, `"`, `abc`, `"a"b"`, `"a\"`} {
+		_, err := common.UnquoteUpstart(quoted)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (*escapeSuite) TestUpstartRoundTrip(c *gc.C) {
+	isControl := func(r rune) bool { return r == '\n' || r == '\r' || r == 0 }
+	for _, value := range randomValues(500, isControl) {
+		c.Assert(common.CheckUpstartValue(value), jc.ErrorIsNil)
+		result, err := common.UnquoteUpstart(common.QuoteUpstart(value))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(result, gc.Equals, value)
+	}
+}
+
+func (*escapeSuite) TestCheckUpstartValue(c *gc.C) {
+	for _, value := range []string{"a\nb", "a\rb", "\x00", "\x7f"} {
+		c.Check(common.CheckUpstartValue(value), jc.Satisfies, errors.IsNotValid)
+	}
+	c.Check(common.CheckUpstartValue("a\tb"), jc.ErrorIsNil)
+}
@@ -4,6 +4,9 @@
 package common
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/juju/utils/shell"
 )
@@ -39,3 +42,58 @@ func (s Service) Validate(renderer shell.Renderer) error {
 func (s *Service) UpdateConfig(conf Conf) {
 	s.Conf = conf
 }
+
+// servicePrefixRE matches the prefixes that may be used to filter
+// service names. It excludes anything that would need quoting in the
+// generated shell commands.
+var servicePrefixRE = regexp.MustCompile(`^[A-Za-z0-9_.@:-]*$`)
+
+// ValidatePrefix checks that the provided service name prefix is safe
+// to use when listing services.
+func ValidatePrefix(prefix string) error {
+	if !servicePrefixRE.MatchString(prefix) {
+		return errors.NotValidf("service name prefix %q", prefix)
+	}
+	return nil
+}
+
+// FilterPrefix returns the names that start with the provided prefix.
+func FilterPrefix(names []string, prefix string) []string {
+	if prefix == "" {
+		return names
+	}
+	var matching []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matching = append(matching, name)
+		}
+	}
+	return matching
+}
+
+// RegexpPrefix returns a prefix that every name matched by the provided
+// regular expression starts with, for use when listing services. It is
+// empty unless the expression is anchored at the start of the name
+// and begins with a literal that passes ValidatePrefix.
+func RegexpPrefix(re *regexp.Regexp) string {
+	if !strings.HasPrefix(re.String(), "^") {
+		return ""
+	}
+	prefix, _ := re.LiteralPrefix()
+	if ValidatePrefix(prefix) != nil {
+		return ""
+	}
+	return prefix
+}
+
+// FilterRegexp returns the names matched by the provided regular
+// expression.
+func FilterRegexp(names []string, re *regexp.Regexp) []string {
+	var matching []string
+	for _, name := range names {
+		if re.MatchString(name) {
+			matching = append(matching, name)
+		}
+	}
+	return matching
+}
@@ -4,6 +4,9 @@
 package common_test
 
 import (
+	"regexp"
+
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -97,3 +100,48 @@ func (*serviceSuite) TestValidateMissingExecStart(c *gc.C) {
 
 	c.Check(err, gc.ErrorMatches, ".*missing ExecStart.*")
 }
+
+func (*serviceSuite) TestValidatePrefix(c *gc.C) {
+	for _, prefix := range []string{"", "jujud-", "juju-db", "unit@", "a.b_c:"} {
+		err := common.ValidatePrefix(prefix)
+
+		c.Check(err, jc.ErrorIsNil)
+	}
+}
+
+func (*serviceSuite) TestValidatePrefixInvalid(c *gc.C) {
+	for _, prefix := range []string{"juju d", "jujud-*", `"`, "'", "a/b", "$x"} {
+		err := common.ValidatePrefix(prefix)
+
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (*serviceSuite) TestFilterPrefix(c *gc.C) {
+	names := []string{"jujud-machine-0", "juju-db", "sshd", "jujud-unit-mysql-0"}
+
+	c.Check(common.FilterPrefix(names, "jujud-"), jc.DeepEquals, []string{"jujud-machine-0", "jujud-unit-mysql-0"})
+	c.Check(common.FilterPrefix(names, "nope"), gc.HasLen, 0)
+	c.Check(common.FilterPrefix(names, ""), jc.DeepEquals, names)
+}
+
+func (*serviceSuite) TestRegexpPrefix(c *gc.C) {
+	for pattern, prefix := range map[string]string{
+		`^jujud-unit-[a-z]+-[0-9]+$`: "jujud-unit-",
+		`^(?:jujud-unit-.*)$`:        "jujud-unit-",
+		`jujud-unit-`:                "",
+		`^jujud-(machine|unit)-`:     "",
+		`^juju d-`:                   "",
+		`^a|b`:                       "",
+	} {
+		c.Check(common.RegexpPrefix(regexp.MustCompile(pattern)), gc.Equals, prefix, gc.Commentf("%s", pattern))
+	}
+}
+
+func (*serviceSuite) TestFilterRegexp(c *gc.C) {
+	names := []string{"jujud-machine-0", "juju-db", "sshd", "jujud-unit-mysql-0"}
+
+	c.Check(common.FilterRegexp(names, regexp.MustCompile(`^jujud-(machine|unit)-`)), jc.DeepEquals, []string{"jujud-machine-0", "jujud-unit-mysql-0"})
+	c.Check(common.FilterRegexp(names, regexp.MustCompile(`-db$`)), jc.DeepEquals, []string{"juju-db"})
+	c.Check(common.FilterRegexp(names, regexp.MustCompile(`^nope`)), gc.HasLen, 0)
+}
@@ -265,3 +265,17 @@ func (ss *FakeService) StartCommands() ([]string, error) {
 
 	return nil, ss.NextErr()
 }
+
+// RemoveCommands implements Service.
+func (ss *FakeService) RemoveCommands() ([]string, error) {
+	ss.AddCall("RemoveCommands")
+
+	return nil, ss.NextErr()
+}
+
+// ReadConf implements service.ConfReader.
+func (ss *FakeService) ReadConf() (common.Conf, error) {
+	ss.AddCall("ReadConf")
+
+	return ss.Service.Conf, ss.NextErr()
+}
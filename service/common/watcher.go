@@ -0,0 +1,38 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+// StateChange describes a change in the state of a service, as
+// reported by the init system.
+type StateChange struct {
+	// Service is the name of the service.
+	Service string
+
+	// From is the state the service was in before the change. It is
+	// empty for the first change reported by a Watcher.
+	From string
+
+	// To is the state the service is now in (e.g. "active" or
+	// "failed").
+	To string
+
+	// SubState is the init system's more detailed description of the
+	// new state (e.g. systemd's "auto-restart"), if it has one.
+	SubState string
+}
+
+// Watcher reports changes in a service's state as they happen.
+type Watcher interface {
+	// Changes returns the channel on which the changes are delivered.
+	// The first change holds the state the service was in when the
+	// watcher started. The channel is closed when the watcher stops.
+	Changes() <-chan StateChange
+
+	// Stop stops the watcher and returns the error, if any, which
+	// stopped it first.
+	Stop() error
+
+	// Err returns the error which stopped the watcher, if any.
+	Err() error
+}
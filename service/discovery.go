@@ -5,13 +5,14 @@ import (
 	"strings"
 
 	"github.com/juju/errors"
-	"github.com/juju/utils/featureflag"
 	"github.com/juju/utils/os"
 	"github.com/juju/utils/series"
 	"github.com/juju/utils/shell"
 
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju/osenv/featureflag"
 	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/openrc"
 	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/service/upstart"
 	"github.com/juju/juju/service/windows"
@@ -25,7 +26,7 @@ func DiscoverService(name string, conf common.Conf) (Service, error) {
 		return nil, errors.Trace(err)
 	}
 
-	service, err := newService(name, conf, initName, series.HostSeries())
+	service, err := newHostService(name, conf, initName)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -96,6 +97,7 @@ var discoveryFuncs = []discoveryCheck{
 	{InitSystemUpstart, upstart.IsRunning},
 	{InitSystemSystemd, systemd.IsRunning},
 	{InitSystemWindows, windows.IsRunning},
+	{InitSystemOpenRC, openrc.IsRunning},
 }
 
 func discoverLocalInitSystem() (string, error) {
@@ -121,6 +123,9 @@ if [ -d /run/systemd/system ]; then
 elif [ -f /sbin/initctl ] && /sbin/initctl --system list 2>&1 > /dev/null; then
     echo -n upstart
     exit 0
+elif [ -d /run/openrc ]; then
+    echo -n openrc
+    exit 0
 fi
 
 # uh-oh
@@ -22,6 +22,7 @@ import (
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/service"
 	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/openrc"
 	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/service/upstart"
 	"github.com/juju/juju/service/windows"
@@ -90,6 +91,8 @@ func (dt discoveryTest) checkService(c *gc.C, svc service.Service, err error, na
 		c.Check(svc, gc.FitsTypeOf, &systemd.Service{})
 	case service.InitSystemWindows:
 		c.Check(svc, gc.FitsTypeOf, &windows.Service{})
+	case service.InitSystemOpenRC:
+		c.Check(svc, gc.FitsTypeOf, &openrc.Service{})
 	default:
 		c.Errorf("unknown expected init system %q", dt.expected)
 		return
@@ -210,6 +213,33 @@ func (s *discoverySuite) TestDiscoverServiceVersionFallback(c *gc.C) {
 	}
 }
 
+func (s *discoverySuite) TestDiscoverServiceLocalOpenRC(c *gc.C) {
+	test := discoveryTest{
+		os:       jujuos.Unknown,
+		expected: service.InitSystemOpenRC,
+	}
+	test.setLocal(c, s)
+
+	svc, err := service.DiscoverService(s.name, s.conf)
+
+	test.checkService(c, svc, err, s.name, s.conf)
+}
+
+func (s *discoverySuite) TestDiscoverServiceHostLayout(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuLayoutEnvKey, "linux")
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "/sandbox")
+	test := discoveryTest{
+		os:       jujuos.Unknown,
+		expected: service.InitSystemSystemd,
+	}
+	test.setLocal(c, s)
+
+	svc, err := service.DiscoverService(s.name, s.conf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc, gc.FitsTypeOf, &systemd.Service{})
+	c.Check(svc.(*systemd.Service).Dirname, gc.Equals, "/sandbox/var/lib/juju/init/"+s.name)
+}
+
 func (s *discoverySuite) TestVersionInitSystem(c *gc.C) {
 	for _, test := range discoveryTests {
 		test.log(c)
@@ -8,3 +8,11 @@ var (
 	DiscoverLocalInitSystem = discoverLocalInitSystem
 	NewShellSelectCommand   = newShellSelectCommand
 )
+
+func PatchLocalInitSystem(patcher interface {
+	PatchValue(interface{}, interface{})
+}, initName string) {
+	patcher.PatchValue(&localInitSystem, func() (string, error) {
+		return initName, nil
+	})
+}
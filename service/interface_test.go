@@ -4,6 +4,8 @@
 package service
 
 import (
+	"github.com/juju/juju/service/openrc"
+	"github.com/juju/juju/service/snap"
 	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/service/upstart"
 	"github.com/juju/juju/service/windows"
@@ -12,3 +14,12 @@ import (
 var _ Service = (*upstart.Service)(nil)
 var _ Service = (*windows.Service)(nil)
 var _ Service = (*systemd.Service)(nil)
+var _ Service = (*openrc.Service)(nil)
+var _ RestartableService = (*openrc.Service)(nil)
+var _ GracefulStopService = (*systemd.Service)(nil)
+var _ RestartableService = (*systemd.Service)(nil)
+var _ WatchableService = (*systemd.Service)(nil)
+var _ ConfReader = (*systemd.Service)(nil)
+var _ ConfReader = (*upstart.Service)(nil)
+var _ Service = (*snap.Service)(nil)
+var _ RestartableService = (*snap.Service)(nil)
@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// ConfReader is a service that can read back the conf it was
+// installed with. The upstart and systemd backends implement it.
+type ConfReader interface {
+	// ReadConf returns the conf of the installed service.
+	ReadConf() (common.Conf, error)
+}
+
+// newLocalService is patched out during tests.
+var newLocalService = func(name string, conf common.Conf, initSystem string) (Service, error) {
+	return newHostService(name, conf, initSystem)
+}
+
+// MigrateService moves the named service on the local host from one
+// init system to another (e.g. from upstart to systemd when a host
+// is upgraded from trusty to xenial). The installed service's conf is
+// read back and installed with the new init system, after which the
+// old service is removed. The service is left running only if it was
+// running beforehand. Transient services are not supported.
+func MigrateService(name, from, to string) error {
+	if from == to {
+		return errors.NotValidf("migrating service %q from %s to itself", name, from)
+	}
+
+	old, err := newLocalService(name, common.Conf{}, from)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	reader, ok := old.(ConfReader)
+	if !ok {
+		return errors.NotSupportedf("migrating %s services", from)
+	}
+	conf, err := reader.ReadConf()
+	if err != nil {
+		return errors.Annotatef(err, "failed to read %s conf for service %q", from, name)
+	}
+	if conf.Transient {
+		return errors.NotSupportedf("migrating transient service %q", name)
+	}
+
+	svc, err := newLocalService(name, conf, to)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	running, err := old.Running()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if running {
+		if err := old.Stop(); err != nil {
+			return errors.Annotatef(err, "failed to stop %s service %q", from, name)
+		}
+	}
+
+	if err := svc.Install(); err != nil {
+		if running {
+			// Leave things as they were.
+			if err := old.Start(); err != nil {
+				logger.Errorf("failed to restart %s service %q: %v", from, name, err)
+			}
+		}
+		return errors.Annotatef(err, "failed to install %s service %q", to, name)
+	}
+	if err := old.Remove(); err != nil {
+		return errors.Annotatef(err, "failed to remove %s service %q", from, name)
+	}
+	if running {
+		if err := svc.Start(); err != nil {
+			return errors.Annotatef(err, "failed to start %s service %q", to, name)
+		}
+	}
+	logger.Infof("migrated service %q from %s to %s", name, from, to)
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/common"
+	svctesting "github.com/juju/juju/service/common/testing"
+)
+
+type migrateSuite struct {
+	service.BaseSuite
+
+	newService *svctesting.FakeService
+}
+
+var _ = gc.Suite(&migrateSuite{})
+
+func (s *migrateSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+
+	err := s.Service.SetStatus(s.Name, "running")
+	c.Assert(err, jc.ErrorIsNil)
+	s.newService = svctesting.NewFakeService(s.Name, s.Conf)
+	s.PatchLocalServices(map[string]service.Service{
+		service.InitSystemUpstart: s.Service,
+		service.InitSystemSystemd: s.newService,
+	})
+}
+
+func (s *migrateSuite) TestMigrateService(c *gc.C) {
+	err := service.MigrateService(s.Name, service.InitSystemUpstart, service.InitSystemSystemd)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.Stub.CheckCallNames(c,
+		"NewLocalService",
+		"ReadConf",
+		"NewLocalService",
+		"Running",
+		"Stop",
+		"Remove",
+	)
+	s.Stub.CheckCall(c, 0, "NewLocalService", s.Name, common.Conf{}, service.InitSystemUpstart)
+	s.Stub.CheckCall(c, 2, "NewLocalService", s.Name, s.Conf, service.InitSystemSystemd)
+	s.newService.CheckCallNames(c, "Install", "Start")
+	c.Check(s.newService.InstalledNames(), jc.DeepEquals, []string{s.Name})
+	c.Check(s.Service.InstalledNames(), gc.HasLen, 0)
+}
+
+func (s *migrateSuite) TestMigrateServiceNotRunning(c *gc.C) {
+	err := s.Service.SetStatus(s.Name, "installed")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = service.MigrateService(s.Name, service.InitSystemUpstart, service.InitSystemSystemd)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.Stub.CheckCallNames(c,
+		"NewLocalService",
+		"ReadConf",
+		"NewLocalService",
+		"Running",
+		"Remove",
+	)
+	s.newService.CheckCallNames(c, "Install")
+}
+
+func (s *migrateSuite) TestMigrateServiceInstallFailed(c *gc.C) {
+	s.newService.SetErrors(s.Failure)
+
+	err := service.MigrateService(s.Name, service.InitSystemUpstart, service.InitSystemSystemd)
+
+	s.CheckFailure(c, err)
+	c.Check(err, gc.ErrorMatches, `failed to install systemd service "juju-agent-machine-0": <failed>`)
+	s.Stub.CheckCallNames(c,
+		"NewLocalService",
+		"ReadConf",
+		"NewLocalService",
+		"Running",
+		"Stop",
+		"Start",
+	)
+}
+
+func (s *migrateSuite) TestMigrateServiceNotInstalled(c *gc.C) {
+	s.Stub.SetErrors(nil, common.NewNotInstalledError(s.Name))
+
+	err := service.MigrateService(s.Name, service.InitSystemUpstart, service.InitSystemSystemd)
+
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
+	s.Stub.CheckCallNames(c, "NewLocalService", "ReadConf")
+}
+
+func (s *migrateSuite) TestMigrateServiceTransient(c *gc.C) {
+	s.Service.Service.Conf.Transient = true
+
+	err := service.MigrateService(s.Name, service.InitSystemUpstart, service.InitSystemSystemd)
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *migrateSuite) TestMigrateServiceNoConfReader(c *gc.C) {
+	s.PatchLocalServices(map[string]service.Service{
+		service.InitSystemWindows: struct{ service.Service }{s.Service},
+	})
+
+	err := service.MigrateService(s.Name, service.InitSystemWindows, service.InitSystemSystemd)
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *migrateSuite) TestMigrateServiceSameInitSystem(c *gc.C) {
+	err := service.MigrateService(s.Name, service.InitSystemSystemd, service.InitSystemSystemd)
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	s.Stub.CheckNoCalls(c)
+}
@@ -0,0 +1,277 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openrc
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// defaultAfter lists the services every juju service is ordered
+// after. "logger" and "net" are the OpenRC virtual services provided
+// by the syslog daemon and the network interfaces respectively.
+var defaultAfter = []string{"logger", "net"}
+
+// targetServices maps the systemd targets which have an OpenRC
+// counterpart to the name of the corresponding virtual service.
+var targetServices = map[string]string{
+	"syslog.target":         "logger",
+	"network.target":        "net",
+	"network-online.target": "net",
+}
+
+// ulimitFlags maps the supported Conf.Limit keys to the flag that
+// sets the corresponding limit in the shell's ulimit builtin.
+var ulimitFlags = map[string]string{
+	"as":         "-v",
+	"core":       "-c",
+	"cpu":        "-t",
+	"data":       "-d",
+	"fsize":      "-f",
+	"memlock":    "-l",
+	"msgqueue":   "-q",
+	"nice":       "-e",
+	"nofile":     "-n",
+	"nproc":      "-u",
+	"rss":        "-m",
+	"rtprio":     "-r",
+	"sigpending": "-i",
+	"stack":      "-s",
+}
+
+// Serialize renders the conf as an OpenRC init script.
+func Serialize(name string, conf common.Conf) ([]byte, error) {
+	data, err := newScriptData(name, conf)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var buf bytes.Buffer
+	if err := scriptT.Execute(&buf, data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scriptData holds the values used to render scriptT. All values are
+// already quoted for the shell, which OpenRC uses to source the script.
+type scriptData struct {
+	Description   string
+	Command       string
+	CommandArgs   string
+	CommandUser   string
+	Pidfile       string
+	Logfile       string
+	DaemonArgs    string
+	Retry         string
+	Ulimit        string
+	Env           []string
+	Need          []string
+	Use           []string
+	After         []string
+	ExtraScript   string
+	ExecStartPre  []string
+	ExecStartPost []string
+	ExecStopPost  []string
+	RawLogfile    string
+	HasStartPre   bool
+}
+
+func newScriptData(name string, conf common.Conf) (*scriptData, error) {
+	execStart := strings.TrimSpace(conf.ExecStart)
+	parts := strings.SplitN(execStart, " ", 2)
+	data := &scriptData{
+		Description:   quote(conf.Desc),
+		Command:       quote(parts[0]),
+		Pidfile:       quote(fmt.Sprintf("/run/%s.pid", name)),
+		ExtraScript:   conf.ExtraScript,
+		ExecStartPre:  conf.ExecStartPre,
+		ExecStartPost: conf.ExecStartPost,
+		ExecStopPost:  conf.ExecStopPost,
+	}
+	if len(parts) > 1 {
+		data.CommandArgs = quote(strings.TrimSpace(parts[1]))
+	}
+
+	if conf.User != "" || conf.Group != "" {
+		data.CommandUser = quote(conf.User + ":" + conf.Group)
+	}
+
+	if conf.Logfile != "" {
+		data.Logfile = quote(conf.Logfile)
+		data.RawLogfile = conf.Logfile
+	}
+
+	var daemonArgs []string
+	if conf.UMask != "" {
+		daemonArgs = append(daemonArgs, "--umask", conf.UMask)
+	}
+	if conf.WorkingDirectory != "" {
+		daemonArgs = append(daemonArgs, "--chdir", conf.WorkingDirectory)
+	}
+	if len(daemonArgs) > 0 {
+		data.DaemonArgs = quoteArgs(daemonArgs)
+	}
+
+	data.Retry = retry(conf)
+
+	ulimit, err := ulimitArgs(conf.Limit)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data.Ulimit = ulimit
+
+	var keys []string
+	for key := range conf.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		data.Env = append(data.Env, key+"="+quote(conf.Env[key]))
+	}
+
+	data.Need = serviceNames(conf.Requires)
+	data.Use = serviceNames(conf.Wants)
+	data.After = serviceNames(defaultAfter, conf.After)
+	data.HasStartPre = data.ExtraScript != "" || data.RawLogfile != "" || len(data.ExecStartPre) > 0
+	return data, nil
+}
+
+// retry returns the quoted value of the script's "retry" variable,
+// which start-stop-daemon uses as its stop schedule. It is empty if
+// OpenRC's default schedule should be used.
+func retry(conf common.Conf) string {
+	if conf.KillSignal == "" && conf.TimeoutStopSec == 0 {
+		return ""
+	}
+	signal := conf.KillSignal
+	if signal == "" {
+		signal = "SIGTERM"
+	}
+	if conf.TimeoutStopSec == 0 {
+		return quote(signal)
+	}
+	return quote(fmt.Sprintf("%s/%d/SIGKILL/5", signal, conf.TimeoutStopSec))
+}
+
+// ulimitArgs returns the quoted ulimit arguments that set the provided
+// limits, for use as the script's "rc_ulimit" variable.
+func ulimitArgs(limit map[string]int) (string, error) {
+	if len(limit) == 0 {
+		return "", nil
+	}
+	var keys []string
+	for key := range limit {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, key := range keys {
+		flag, ok := ulimitFlags[key]
+		if !ok {
+			return "", common.ConfNotSupportedf("Limit", "Conf.Limit key %q not supported", key)
+		}
+		args = append(args, flag, fmt.Sprint(limit[key]))
+	}
+	return quote(strings.Join(args, " ")), nil
+}
+
+// serviceNames returns the OpenRC service names for the provided
+// dependencies. Targets without an OpenRC counterpart are dropped.
+func serviceNames(deps ...[]string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		for _, name := range dep {
+			if strings.HasSuffix(name, ".target") {
+				virtual, ok := targetServices[name]
+				if !ok {
+					logger.Debugf("ignoring dependency on target %q", name)
+					continue
+				}
+				name = virtual
+			}
+			name = strings.TrimSuffix(name, ".service")
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// quote returns the value single-quoted for a POSIX shell. OpenRC
+// evals command_args, so quoting inside it is preserved.
+func quote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// quoteArgs returns the arguments as a single double-quoted value in
+// which each argument is single-quoted. OpenRC evals
+// start_stop_daemon_args, so each argument is passed on intact even if
+// it contains spaces.
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+	return `"` + doubleQuoteReplacer.Replace(strings.Join(quoted, " ")) + `"`
+}
+
+// doubleQuoteReplacer escapes the characters which are special inside
+// double quotes.
+var doubleQuoteReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"$", `\$`,
+	"`", "\\`",
+)
+
+var scriptT = template.Must(template.New("").Parse(`
+#!/sbin/openrc-run
+
+description={{.Description}}
+
+command={{.Command}}
+{{if .CommandArgs}}command_args={{.CommandArgs}}
+{{end}}command_background=true
+pidfile={{.Pidfile}}
+{{if .CommandUser}}command_user={{.CommandUser}}
+{{end}}{{if .Logfile}}output_log={{.Logfile}}
+error_log={{.Logfile}}
+{{end}}{{if .DaemonArgs}}start_stop_daemon_args={{.DaemonArgs}}
+{{end}}{{if .Retry}}retry={{.Retry}}
+{{end}}{{if .Ulimit}}rc_ulimit={{.Ulimit}}
+{{end}}{{range .Env}}export {{.}}
+{{end}}
+depend() {
+{{if .Need}}	need{{range .Need}} {{.}}{{end}}
+{{end}}{{if .Use}}	use{{range .Use}} {{.}}{{end}}
+{{end}}	after{{range .After}} {{.}}{{end}}
+}
+{{if .HasStartPre}}
+start_pre() {
+{{if .ExtraScript}}{{.ExtraScript}}
+{{end}}{{if .RawLogfile}}	# Ensure log files are properly protected
+	touch {{.RawLogfile}}
+	chmod 0600 {{.RawLogfile}}
+{{end}}{{range .ExecStartPre}}	{{.}} || return 1
+{{end}}}
+{{end}}{{if .ExecStartPost}}
+start_post() {
+{{range .ExecStartPost}}	{{.}}
+{{end}}}
+{{end}}{{if .ExecStopPost}}
+stop_post() {
+{{range .ExecStopPost}}	{{.}}
+{{end}}}
+{{end}}`[1:]))
@@ -0,0 +1,332 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package openrc implements the service interface for the OpenRC init
+// system, as found on Alpine Linux and Gentoo.
+package openrc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/shell"
+
+	"github.com/juju/juju/service/common"
+)
+
+// Runlevel is the OpenRC runlevel to which juju's services are added.
+const Runlevel = "default"
+
+var (
+	InitDir = "/etc/init.d" // the default init directory name.
+
+	logger   = loggo.GetLogger("juju.service.openrc")
+	runDir   = "/run/openrc"
+	renderer = &shell.BashRenderer{}
+)
+
+// IsRunning returns whether or not OpenRC is the local init system.
+func IsRunning() (bool, error) {
+	if runtime.GOOS == "windows" {
+		return false, nil
+	}
+
+	// OpenRC creates its state directory when it boots the system, so
+	// its presence tells us that OpenRC is in charge (rather than
+	// merely installed).
+	fi, err := os.Stat(runDir)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return fi.IsDir(), nil
+}
+
+// ListServices returns the name of all installed services on the
+// local host.
+func ListServices() ([]string, error) {
+	fis, err := ioutil.ReadDir(InitDir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var services []string
+	for _, fi := range fis {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		services = append(services, fi.Name())
+	}
+	return services, nil
+}
+
+// ListServicesMatching returns the names of the installed services
+// on the local host that start with the provided prefix.
+func ListServicesMatching(prefix string) ([]string, error) {
+	services, err := ListServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return common.FilterPrefix(services, prefix), nil
+}
+
+// ListCommand returns a command that will list the services on a host.
+func ListCommand() string {
+	return fmt.Sprintf(`ls -1 %s | sort | uniq`, InitDir)
+}
+
+// ListCommandMatching returns a command that will list the services
+// on a host that start with the provided prefix.
+func ListCommandMatching(prefix string) string {
+	if prefix == "" {
+		return ListCommand()
+	}
+	return fmt.Sprintf(`ls -1 %s | awk 'index($0, "%s") == 1' | sort | uniq`, InitDir, prefix)
+}
+
+// Service provides visibility into and control over an OpenRC service.
+type Service struct {
+	common.Service
+}
+
+// NewService returns a new Service for the named OpenRC service.
+func NewService(name string, conf common.Conf) *Service {
+	return &Service{
+		Service: common.Service{
+			Name: name,
+			Conf: conf,
+		},
+	}
+}
+
+// Name implements service.Service.
+func (s Service) Name() string {
+	return s.Service.Name
+}
+
+// Conf implements service.Service.
+func (s Service) Conf() common.Conf {
+	return s.Service.Conf
+}
+
+// scriptPath returns the path to the service's init script.
+func (s *Service) scriptPath() string {
+	return path.Join(InitDir, s.Service.Name)
+}
+
+// Validate returns an error if the service is not adequately defined.
+func (s *Service) Validate() error {
+	if err := s.Service.Validate(renderer); err != nil {
+		return errors.Trace(err)
+	}
+
+	if s.Service.Conf.Transient {
+		return common.ConfNotSupportedf("Transient", "Conf.Transient not supported")
+	}
+	if s.Service.Conf.AfterStopped != "" {
+		return common.ConfNotSupportedf("AfterStopped", "Conf.AfterStopped not supported")
+	}
+	if s.Service.Conf.KillMode != "" {
+		return common.ConfNotSupportedf("KillMode", "Conf.KillMode not supported")
+	}
+	if s.Service.Conf.WantedBy != "" {
+		return common.ConfNotSupportedf("WantedBy", "Conf.WantedBy not supported")
+	}
+	if strings.Contains(s.Service.Conf.ExecStart, "\n") {
+		return common.ConfNotSupportedf("ExecStart", "Conf.ExecStart (multiple lines) not supported")
+	}
+
+	return nil
+}
+
+// render returns the OpenRC init script for the service.
+func (s *Service) render() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return Serialize(s.Name(), s.Conf())
+}
+
+// Installed returns whether the service's init script exists in the
+// init directory.
+func (s *Service) Installed() (bool, error) {
+	_, err := os.Stat(s.scriptPath())
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// Exists returns whether the service's init script exists in the
+// init directory with the same content that this Service would have
+// if installed.
+func (s *Service) Exists() (bool, error) {
+	_, same, _, err := s.existsAndSame()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return same, nil
+}
+
+func (s *Service) existsAndSame() (exists, same bool, script []byte, err error) {
+	expected, err := s.render()
+	if err != nil {
+		return false, false, nil, errors.Trace(err)
+	}
+	current, err := ioutil.ReadFile(s.scriptPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, false, expected, nil
+		}
+		return false, false, nil, errors.Trace(err)
+	}
+	return true, bytes.Equal(current, expected), expected, nil
+}
+
+// Running returns true if the Service appears to be running.
+func (s *Service) Running() (bool, error) {
+	installed, err := s.Installed()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !installed {
+		return false, nil
+	}
+
+	// "rc-service NAME status" exits non-zero for any state other
+	// than "started" (3 for stopped, 4 for crashed, and so on).
+	out, err := exec.Command("rc-service", s.Service.Name, "status").CombinedOutput()
+	logger.Tracef("Running \"rc-service %s status\": %q", s.Service.Name, out)
+	if err == nil {
+		return true, nil
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		return false, nil
+	}
+	return false, errors.Trace(err)
+}
+
+// Start starts the service.
+func (s *Service) Start() error {
+	running, err := s.Running()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if running {
+		return nil
+	}
+	return runCommand("rc-service", s.Service.Name, "start")
+}
+
+// Stop stops the service.
+func (s *Service) Stop() error {
+	running, err := s.Running()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !running {
+		return nil
+	}
+	return runCommand("rc-service", s.Service.Name, "stop")
+}
+
+// Restart restarts the service.
+func (s *Service) Restart() error {
+	return runCommand("rc-service", s.Service.Name, "restart")
+}
+
+// Remove removes the service from its runlevel and deletes its init
+// script from the init directory.
+func (s *Service) Remove() error {
+	installed, err := s.Installed()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !installed {
+		return nil
+	}
+	if err := runCommand("rc-update", "del", s.Service.Name, Runlevel); err != nil {
+		// The service may never have been added to the runlevel.
+		logger.Debugf("ignoring rc-update failure: %v", err)
+	}
+	return errors.Trace(os.Remove(s.scriptPath()))
+}
+
+// Install writes the service's init script and adds the service to
+// the default runlevel.
+func (s *Service) Install() error {
+	exists, same, script, err := s.existsAndSame()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if same {
+		return nil
+	}
+	if exists {
+		if err := s.Stop(); err != nil {
+			return errors.Annotate(err, "openrc: could not stop installed service")
+		}
+		if err := s.Remove(); err != nil {
+			return errors.Annotate(err, "openrc: could not remove installed service")
+		}
+	}
+	if err := ioutil.WriteFile(s.scriptPath(), script, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	if err := runCommand("rc-update", "add", s.Service.Name, Runlevel); err != nil {
+		return errors.Annotate(err, "openrc: could not enable service")
+	}
+	return nil
+}
+
+// InstallCommands returns shell commands to install the service.
+func (s *Service) InstallCommands() ([]string, error) {
+	script, err := s.render()
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		fmt.Sprintf("cat > %s << 'EOF'\n%sEOF\n", s.scriptPath(), script),
+		"chmod 0755 " + s.scriptPath(),
+		fmt.Sprintf("rc-update add %s %s", s.Service.Name, Runlevel),
+	}, nil
+}
+
+// StartCommands returns shell commands to start the service.
+func (s *Service) StartCommands() ([]string, error) {
+	return []string{fmt.Sprintf("rc-service %s start", s.Service.Name)}, nil
+}
+
+// RemoveCommands returns shell commands to stop and remove the service.
+func (s *Service) RemoveCommands() ([]string, error) {
+	return []string{
+		fmt.Sprintf("rc-service %s stop || true", s.Service.Name),
+		fmt.Sprintf("rc-update del %s %s || true", s.Service.Name, Runlevel),
+		"rm -f " + s.scriptPath(),
+	}, nil
+}
+
+func runCommand(args ...string) error {
+	out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) > 0 {
+		return fmt.Errorf("exec %q: %v (%s)", args, err, out)
+	}
+	return fmt.Errorf("exec %q: %v", args, err)
+}
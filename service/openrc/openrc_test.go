@@ -0,0 +1,493 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openrc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/openrc"
+	coretesting "github.com/juju/juju/testing"
+)
+
+func Test(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping openrc tests on windows")
+	}
+	gc.TestingT(t)
+}
+
+type OpenRCSuite struct {
+	coretesting.BaseSuite
+	testPath string
+	logPath  string
+	initDir  string
+	service  *openrc.Service
+}
+
+var _ = gc.Suite(&OpenRCSuite{})
+
+func (s *OpenRCSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.testPath = c.MkDir()
+	s.logPath = filepath.Join(s.testPath, "calls.log")
+	s.initDir = c.MkDir()
+	s.PatchEnvPathPrepend(s.testPath)
+	s.PatchValue(&openrc.InitDir, s.initDir)
+	s.service = openrc.NewService(
+		"some-service",
+		common.Conf{
+			Desc:      "some service",
+			ExecStart: "/path/to/some-command",
+		},
+	)
+	s.MakeTool(c, "rc-update", "exit 0")
+	s.StoppedStatus(c)
+}
+
+// MakeTool writes a fake command to the test path. Each invocation
+// is recorded in the calls log before the script runs.
+func (s *OpenRCSuite) MakeTool(c *gc.C, name, script string) {
+	path := filepath.Join(s.testPath, name)
+	content := "#!/bin/bash --norc\n" +
+		`echo "` + name + ` $@" >> ` + s.logPath + "\n" +
+		script + "\n"
+	err := ioutil.WriteFile(path, []byte(content), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *OpenRCSuite) StoppedStatus(c *gc.C) {
+	s.MakeTool(c, "rc-service", `if [ "$2" = "status" ]; then exit 3; fi`)
+}
+
+func (s *OpenRCSuite) RunningStatus(c *gc.C) {
+	s.MakeTool(c, "rc-service", `if [ "$2" = "status" ]; then exit 0; fi`)
+}
+
+func (s *OpenRCSuite) checkCalls(c *gc.C, expected ...string) {
+	data, err := ioutil.ReadFile(s.logPath)
+	if os.IsNotExist(err) {
+		c.Check(expected, gc.HasLen, 0)
+		return
+	}
+	c.Assert(err, jc.ErrorIsNil)
+	var calls string
+	for _, call := range expected {
+		calls += call + "\n"
+	}
+	c.Check(string(data), gc.Equals, calls)
+}
+
+func (s *OpenRCSuite) resetCalls(c *gc.C) {
+	err := os.Remove(s.logPath)
+	if !os.IsNotExist(err) {
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *OpenRCSuite) goodInstall(c *gc.C) {
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+	s.resetCalls(c)
+}
+
+func (s *OpenRCSuite) TestIsRunning(c *gc.C) {
+	dir := c.MkDir()
+	s.PatchValue(openrc.RunDir, dir)
+
+	running, err := openrc.IsRunning()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsTrue)
+
+	s.PatchValue(openrc.RunDir, filepath.Join(dir, "missing"))
+
+	running, err = openrc.IsRunning()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsFalse)
+}
+
+func (s *OpenRCSuite) TestListServices(c *gc.C) {
+	for _, name := range []string{"sshd", "jujud-machine-0", ".hidden"} {
+		err := ioutil.WriteFile(filepath.Join(s.initDir, name), nil, 0755)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err := os.Mkdir(filepath.Join(s.initDir, "subdir"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+
+	services, err := openrc.ListServices()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(services, jc.SameContents, []string{"sshd", "jujud-machine-0"})
+}
+
+func (s *OpenRCSuite) TestListServicesMatching(c *gc.C) {
+	for _, name := range []string{"sshd", "jujud-machine-0", "jujud-unit-mysql-0"} {
+		err := ioutil.WriteFile(filepath.Join(s.initDir, name), nil, 0755)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	services, err := openrc.ListServicesMatching("jujud-")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(services, jc.SameContents, []string{"jujud-machine-0", "jujud-unit-mysql-0"})
+}
+
+func (s *OpenRCSuite) TestListCommandMatching(c *gc.C) {
+	c.Check(openrc.ListCommandMatching(""), gc.Equals, openrc.ListCommand())
+	c.Check(openrc.ListCommandMatching("jujud-"), gc.Equals,
+		"ls -1 "+s.initDir+` | awk 'index($0, "jujud-") == 1' | sort | uniq`)
+}
+
+func (s *OpenRCSuite) TestSerializeMinimal(c *gc.C) {
+	data, err := openrc.Serialize("some-service", s.service.Conf())
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(string(data), gc.Equals, `
+#!/sbin/openrc-run
+
+description='some service'
+
+command='/path/to/some-command'
+command_background=true
+pidfile='/run/some-service.pid'
+
+depend() {
+	after logger net
+}
+`[1:])
+}
+
+func (s *OpenRCSuite) TestSerializeFull(c *gc.C) {
+	conf := common.Conf{
+		Desc:             "juju agent for machine-0",
+		ExecStart:        `/var/lib/juju/tools/jujud machine --data-dir '/var/lib/juju'`,
+		Env:              map[string]string{"B": "it's", "A": "1"},
+		Limit:            map[string]int{"nofile": 20000, "nproc": 50},
+		Logfile:          "/var/log/juju/machine-0.log",
+		ExtraScript:      "\tmkdir -p /var/run/juju",
+		ExecStartPre:     []string{"/bin/true"},
+		ExecStartPost:    []string{"/usr/bin/logger started"},
+		ExecStopPost:     []string{"/bin/rm -f /var/run/juju/lock"},
+		User:             "juju",
+		Group:            "adm",
+		UMask:            "0022",
+		WorkingDirectory: "/var/lib/juju",
+		TimeoutStopSec:   30,
+		KillSignal:       "SIGINT",
+		After:            []string{"mongodb.service", "network-online.target"},
+		Requires:         []string{"mongodb"},
+		Wants:            []string{"ntpd.service", "basic.target"},
+	}
+
+	data, err := openrc.Serialize("jujud-machine-0", conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(string(data), gc.Equals, `
+#!/sbin/openrc-run
+
+description='juju agent for machine-0'
+
+command='/var/lib/juju/tools/jujud'
+command_args='machine --data-dir '\''/var/lib/juju'\'''
+command_background=true
+pidfile='/run/jujud-machine-0.pid'
+command_user='juju:adm'
+output_log='/var/log/juju/machine-0.log'
+error_log='/var/log/juju/machine-0.log'
+start_stop_daemon_args="'--umask' '0022' '--chdir' '/var/lib/juju'"
+retry='SIGINT/30/SIGKILL/5'
+rc_ulimit='-n 20000 -u 50'
+export A='1'
+export B='it'\''s'
+
+depend() {
+	need mongodb
+	use ntpd
+	after logger net mongodb
+}
+
+start_pre() {
+	mkdir -p /var/run/juju
+	# Ensure log files are properly protected
+	touch /var/log/juju/machine-0.log
+	chmod 0600 /var/log/juju/machine-0.log
+	/bin/true || return 1
+}
+
+start_post() {
+	/usr/bin/logger started
+}
+
+stop_post() {
+	/bin/rm -f /var/run/juju/lock
+}
+`[1:])
+}
+
+func (s *OpenRCSuite) TestSerializeDaemonArgsQuoted(c *gc.C) {
+	conf := s.service.Conf()
+	conf.WorkingDirectory = `/srv/it's "my" $dir`
+
+	data, err := openrc.Serialize("some-service", conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(string(data), jc.Contains, `
+start_stop_daemon_args="'--chdir' '/srv/it'\''s \"my\" \$dir'"
+`)
+}
+
+func (s *OpenRCSuite) TestSerializeExecStartPreChecked(c *gc.C) {
+	conf := s.service.Conf()
+	conf.ExecStartPre = []string{"/bin/false", "/bin/true"}
+
+	data, err := openrc.Serialize("some-service", conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(string(data), jc.Contains, `
+	/bin/false || return 1
+	/bin/true || return 1
+}
+`)
+}
+
+func (s *OpenRCSuite) TestSerializeUnknownLimit(c *gc.C) {
+	conf := s.service.Conf()
+	conf.Limit = map[string]int{"bogus": 1}
+
+	_, err := openrc.Serialize("some-service", conf)
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "Limit")
+}
+
+func (s *OpenRCSuite) TestValidateNotSupported(c *gc.C) {
+	for field, update := range map[string]func(*common.Conf){
+		"Transient":    func(conf *common.Conf) { conf.Transient = true },
+		"AfterStopped": func(conf *common.Conf) { conf.AfterStopped = "other-service" },
+		"KillMode":     func(conf *common.Conf) { conf.KillMode = "process" },
+		"WantedBy":     func(conf *common.Conf) { conf.WantedBy = "graphical.target" },
+		"ExecStart":    func(conf *common.Conf) { conf.ExecStart = "/path/to/some-command\n/path/to/other-command" },
+	} {
+		conf := s.service.Conf()
+		update(&conf)
+		svc := openrc.NewService("some-service", conf)
+
+		err := svc.Validate()
+
+		c.Check(err, jc.Satisfies, errors.IsNotSupported)
+		errField, _ := common.ConfInvalidField(err)
+		c.Check(errField, gc.Equals, field)
+	}
+}
+
+func (s *OpenRCSuite) TestInstall(c *gc.C) {
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	filename := filepath.Join(s.initDir, "some-service")
+	fi, err := os.Stat(filename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fi.Mode().Perm(), gc.Equals, os.FileMode(0755))
+	data, err := ioutil.ReadFile(filename)
+	c.Assert(err, jc.ErrorIsNil)
+	expected, err := openrc.Serialize("some-service", s.service.Conf())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, string(expected))
+	s.checkCalls(c, "rc-update add some-service default")
+}
+
+func (s *OpenRCSuite) TestInstallAlreadyInstalled(c *gc.C) {
+	s.goodInstall(c)
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c)
+}
+
+func (s *OpenRCSuite) TestInstallChanged(c *gc.C) {
+	s.goodInstall(c)
+	s.RunningStatus(c)
+	s.service.Service.Conf.ExecStart = "/path/to/other-command"
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c,
+		"rc-service some-service status",
+		"rc-service some-service stop",
+		"rc-update del some-service default",
+		"rc-update add some-service default",
+	)
+	exists, err := s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsTrue)
+}
+
+func (s *OpenRCSuite) TestInstallEnableFailed(c *gc.C) {
+	s.MakeTool(c, "rc-update", "exit 1")
+
+	err := s.service.Install()
+
+	c.Check(err, gc.ErrorMatches, `openrc: could not enable service: .*exit status 1`)
+}
+
+func (s *OpenRCSuite) TestInstalled(c *gc.C) {
+	installed, err := s.service.Installed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(installed, jc.IsFalse)
+
+	s.goodInstall(c)
+	installed, err = s.service.Installed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(installed, jc.IsTrue)
+}
+
+func (s *OpenRCSuite) TestExists(c *gc.C) {
+	exists, err := s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsFalse)
+
+	s.goodInstall(c)
+	exists, err = s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsTrue)
+
+	s.service.Service.Conf.ExecStart = "/path/to/other-command"
+	exists, err = s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsFalse)
+}
+
+func (s *OpenRCSuite) TestRunning(c *gc.C) {
+	running, err := s.service.Running()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsFalse)
+	s.checkCalls(c)
+
+	s.goodInstall(c)
+	running, err = s.service.Running()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsFalse)
+
+	s.RunningStatus(c)
+	running, err = s.service.Running()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsTrue)
+}
+
+func (s *OpenRCSuite) TestStart(c *gc.C) {
+	s.goodInstall(c)
+	s.MakeTool(c, "rc-service", `if [ "$2" = "status" ]; then exit 3; fi; exit 99`)
+	c.Check(s.service.Start(), gc.ErrorMatches, ".*exit status 99.*")
+
+	s.StoppedStatus(c)
+	s.resetCalls(c)
+	c.Check(s.service.Start(), jc.ErrorIsNil)
+	s.checkCalls(c,
+		"rc-service some-service status",
+		"rc-service some-service start",
+	)
+
+	s.RunningStatus(c)
+	s.resetCalls(c)
+	c.Check(s.service.Start(), jc.ErrorIsNil)
+	s.checkCalls(c, "rc-service some-service status")
+}
+
+func (s *OpenRCSuite) TestStop(c *gc.C) {
+	s.goodInstall(c)
+	c.Check(s.service.Stop(), jc.ErrorIsNil)
+	s.checkCalls(c, "rc-service some-service status")
+
+	s.MakeTool(c, "rc-service", `if [ "$2" = "status" ]; then exit 0; fi; exit 99`)
+	c.Check(s.service.Stop(), gc.ErrorMatches, ".*exit status 99.*")
+
+	s.RunningStatus(c)
+	s.resetCalls(c)
+	c.Check(s.service.Stop(), jc.ErrorIsNil)
+	s.checkCalls(c,
+		"rc-service some-service status",
+		"rc-service some-service stop",
+	)
+}
+
+func (s *OpenRCSuite) TestRestart(c *gc.C) {
+	err := s.service.Restart()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "rc-service some-service restart")
+}
+
+func (s *OpenRCSuite) TestRemove(c *gc.C) {
+	s.goodInstall(c)
+
+	err := s.service.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = os.Stat(filepath.Join(s.initDir, "some-service"))
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+	s.checkCalls(c, "rc-update del some-service default")
+}
+
+func (s *OpenRCSuite) TestRemoveNotEnabled(c *gc.C) {
+	s.goodInstall(c)
+	s.MakeTool(c, "rc-update", "exit 1")
+
+	err := s.service.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = os.Stat(filepath.Join(s.initDir, "some-service"))
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *OpenRCSuite) TestRemoveMissing(c *gc.C) {
+	err := s.service.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c)
+}
+
+func (s *OpenRCSuite) TestInstallCommands(c *gc.C) {
+	commands, err := s.service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	filename := filepath.Join(s.initDir, "some-service")
+	script, err := openrc.Serialize("some-service", s.service.Conf())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(commands, jc.DeepEquals, []string{
+		"cat > " + filename + " << 'EOF'\n" + string(script) + "EOF\n",
+		"chmod 0755 " + filename,
+		"rc-update add some-service default",
+	})
+}
+
+func (s *OpenRCSuite) TestRemoveCommands(c *gc.C) {
+	commands, err := s.service.RemoveCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(commands, jc.DeepEquals, []string{
+		"rc-service some-service stop || true",
+		"rc-update del some-service default || true",
+		"rm -f " + filepath.Join(s.initDir, "some-service"),
+	})
+}
+
+func (s *OpenRCSuite) TestStartCommands(c *gc.C) {
+	commands, err := s.service.StartCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(commands, jc.DeepEquals, []string{
+		"rc-service some-service start",
+	})
+}
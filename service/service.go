@@ -4,6 +4,7 @@
 package service
 
 import (
+	"regexp"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 
 	"github.com/juju/juju/juju/paths"
 	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/openrc"
 	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/service/upstart"
 	"github.com/juju/juju/service/windows"
@@ -28,6 +30,7 @@ const (
 	InitSystemSystemd = "systemd"
 	InitSystemUpstart = "upstart"
 	InitSystemWindows = "windows"
+	InitSystemOpenRC  = "openrc"
 )
 
 // linuxInitSystems lists the names of the init systems that juju might
@@ -35,6 +38,7 @@ const (
 var linuxInitSystems = []string{
 	InitSystemSystemd,
 	InitSystemUpstart,
+	InitSystemOpenRC,
 }
 
 // ServiceActions represents the actions that may be requested for
@@ -85,6 +89,11 @@ type Service interface {
 	// StartCommands returns the list of commands to run on a
 	// (remote) host to start the service.
 	StartCommands() ([]string, error)
+
+	// RemoveCommands returns the list of commands to run on a
+	// (remote) host to stop and remove the service. The commands
+	// succeed even if the service is not installed.
+	RemoveCommands() ([]string, error)
 }
 
 // RestartableService is a service that directly supports restarting.
@@ -93,6 +102,25 @@ type RestartableService interface {
 	Restart() error
 }
 
+// GracefulStopService is a service that can report whether it
+// stopped gracefully or had to be forcibly killed. Only the systemd
+// backend implements it: neither upstart nor the Windows service
+// manager expose how a stopped service's processes exited.
+type GracefulStopService interface {
+	// StopGracefully stops the service, waiting up to the conf's
+	// TimeoutStopSec for it to exit. It reports whether the service's
+	// processes had to be forcibly killed.
+	StopGracefully() (forced bool, err error)
+}
+
+// WatchableService is a service whose state changes can be watched as
+// they happen, rather than by polling Running. Only the systemd
+// backend implements it.
+type WatchableService interface {
+	// Watch starts watching the service's state.
+	Watch() (common.Watcher, error)
+}
+
 // TODO(ericsnow) bug #1426458
 // Eliminate the need to pass an empty conf for most service methods
 // and several helper functions.
@@ -111,6 +139,19 @@ func NewService(name string, conf common.Conf, series string) (Service, error) {
 }
 
 func newService(name string, conf common.Conf, initSystem, series string) (Service, error) {
+	return newServiceInDataDir(name, conf, initSystem, func() (string, error) {
+		return paths.DataDir(series)
+	})
+}
+
+// newHostService returns a service on the local machine, whose juju
+// data dir is the one in the local machine's layout (see
+// paths.HostLayout).
+func newHostService(name string, conf common.Conf, initSystem string) (Service, error) {
+	return newServiceInDataDir(name, conf, initSystem, paths.HostDataDir)
+}
+
+func newServiceInDataDir(name string, conf common.Conf, initSystem string, dataDir func() (string, error)) (Service, error) {
 	switch initSystem {
 	case InitSystemWindows:
 		svc, err := windows.NewService(name, conf)
@@ -120,8 +161,10 @@ func newService(name string, conf common.Conf, initSystem, series string) (Servi
 		return svc, nil
 	case InitSystemUpstart:
 		return upstart.NewService(name, conf), nil
+	case InitSystemOpenRC:
+		return openrc.NewService(name, conf), nil
 	case InitSystemSystemd:
-		dataDir, err := paths.DataDir(series)
+		dataDir, err := dataDir()
 		if err != nil {
 			return nil, errors.Annotatef(err, "failed to find juju data dir for service %q", name)
 		}
@@ -136,8 +179,70 @@ func newService(name string, conf common.Conf, initSystem, series string) (Servi
 	}
 }
 
+// validateServiceName is the name given to the service checked by
+// Validate, which only has a conf to go on.
+const validateServiceName = "juju-validate"
+
+// Validate checks that the conf describes a service the named init
+// system can run, without installing anything. The service is
+// validated and rendered just as it would be when installed. For
+// systemd the rendered unit is also checked with "systemd-analyze
+// verify", if available, which catches a missing ExecStart executable
+// or an unknown directive before the service is installed.
+func Validate(conf common.Conf, initSystem string) error {
+	switch initSystem {
+	case InitSystemWindows:
+		svc := &windows.Service{
+			Service: common.Service{
+				Name: validateServiceName,
+				Conf: conf,
+			},
+		}
+		if err := svc.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+		return nil
+	case InitSystemUpstart:
+		svc := upstart.NewService(validateServiceName, conf)
+		if err := svc.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := svc.InstallCommands(); err != nil {
+			return errors.Trace(err)
+		}
+		return nil
+	case InitSystemOpenRC:
+		svc := openrc.NewService(validateServiceName, conf)
+		if err := svc.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := svc.InstallCommands(); err != nil {
+			return errors.Trace(err)
+		}
+		return nil
+	case InitSystemSystemd:
+		if err := systemd.Verify(validateServiceName, conf); err != nil {
+			return errors.Trace(err)
+		}
+		return nil
+	default:
+		return errors.NotFoundf("init system %q", initSystem)
+	}
+}
+
 // ListServices lists all installed services on the running system
 func ListServices() ([]string, error) {
+	return ListServicesMatching("")
+}
+
+// ListServicesMatching lists the installed services on the running
+// system whose names start with the provided prefix. Where the init
+// system supports it the filtering is done by the init system itself.
+func ListServicesMatching(prefix string) ([]string, error) {
+	if err := common.ValidatePrefix(prefix); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	initName, err := VersionInitSystem(series.HostSeries())
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -145,19 +250,25 @@ func ListServices() ([]string, error) {
 
 	switch initName {
 	case InitSystemWindows:
-		services, err := windows.ListServices()
+		services, err := windows.ListServicesMatching(prefix)
 		if err != nil {
 			return nil, errors.Annotatef(err, "failed to list %s services", initName)
 		}
 		return services, nil
 	case InitSystemUpstart:
-		services, err := upstart.ListServices()
+		services, err := upstart.ListServicesMatching(prefix)
 		if err != nil {
 			return nil, errors.Annotatef(err, "failed to list %s services", initName)
 		}
 		return services, nil
 	case InitSystemSystemd:
-		services, err := systemd.ListServices()
+		services, err := systemd.ListServicesMatching(prefix)
+		if err != nil {
+			return nil, errors.Annotatef(err, "failed to list %s services", initName)
+		}
+		return services, nil
+	case InitSystemOpenRC:
+		services, err := openrc.ListServicesMatching(prefix)
 		if err != nil {
 			return nil, errors.Annotatef(err, "failed to list %s services", initName)
 		}
@@ -167,32 +278,113 @@ func ListServices() ([]string, error) {
 	}
 }
 
+// ListServicesMatchingRegexp lists the installed services on the
+// running system whose names match the provided regular expression.
+// If the expression is anchored to a literal prefix, the init system
+// is asked only for the services with that prefix.
+func ListServicesMatchingRegexp(re *regexp.Regexp) ([]string, error) {
+	services, err := ListServicesMatching(common.RegexpPrefix(re))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return common.FilterRegexp(services, re), nil
+}
+
 // ListServicesScript returns the commands that should be run to get
 // a list of service names on a host.
 func ListServicesScript() string {
+	return listServicesScript("")
+}
+
+// ListServicesScriptMatching returns the commands that should be run
+// to get a list of the service names on a host that start with the
+// provided prefix.
+func ListServicesScriptMatching(prefix string) (string, error) {
+	if err := common.ValidatePrefix(prefix); err != nil {
+		return "", errors.Trace(err)
+	}
+	return listServicesScript(prefix), nil
+}
+
+// ListServicesScriptMatchingRegexp returns the commands that should be
+// run to get a list of the service names on a host that match the
+// provided regular expression. The names are filtered with "grep -P",
+// so the expression should only use syntax that Go and PCRE share.
+func ListServicesScriptMatchingRegexp(re *regexp.Regexp) string {
+	filter := " | { grep -P " + utils.ShQuote(re.String()) + " || true; }"
+	return listServicesScriptFiltered(common.RegexpPrefix(re), filter)
+}
+
+func listServicesScript(prefix string) string {
+	return listServicesScriptFiltered(prefix, "")
+}
+
+// listServicesScriptFiltered returns the script that lists the service
+// names starting with prefix, piping each init system's list through
+// filter.
+func listServicesScriptFiltered(prefix, filter string) string {
+	handler := func(initSystem string) (string, bool) {
+		cmd, ok := listServicesCommand(initSystem, prefix)
+		return cmd + filter, ok
+	}
 	commands := []string{
 		"init_system=$(" + DiscoverInitSystemScript() + ")",
 		// If the init system is not identified then the script will
 		// "exit 1". This is correct since the script should fail if no
 		// init system can be identified.
-		newShellSelectCommand("init_system", "exit 1", listServicesCommand),
+		newShellSelectCommand("init_system", "exit 1", handler),
 	}
 	return strings.Join(commands, "\n")
 }
 
-func listServicesCommand(initSystem string) (string, bool) {
+func listServicesCommand(initSystem, prefix string) (string, bool) {
 	switch initSystem {
 	case InitSystemWindows:
-		return windows.ListCommand(), true
+		return windows.ListCommandMatching(prefix), true
 	case InitSystemUpstart:
-		return upstart.ListCommand(), true
+		return upstart.ListCommandMatching(prefix), true
 	case InitSystemSystemd:
-		return systemd.ListCommand(), true
+		return systemd.ListCommandMatching(prefix), true
+	case InitSystemOpenRC:
+		return openrc.ListCommandMatching(prefix), true
 	default:
 		return "", false
 	}
 }
 
+// RemoveServicesScript returns the commands that should be run on a
+// host to stop and remove the named services, using the RemoveCommands
+// of whichever init system the host runs. The series determines the
+// juju paths on the host. Services that are not installed are skipped,
+// and the script does nothing if no init system can be identified.
+func RemoveServicesScript(series string, names ...string) (string, error) {
+	cmds := make(map[string]string)
+	for _, initSystem := range linuxInitSystems {
+		var lines []string
+		for _, name := range names {
+			svc, err := newService(name, common.Conf{}, initSystem, series)
+			if err != nil {
+				return "", errors.Trace(err)
+			}
+			remove, err := svc.RemoveCommands()
+			if err != nil {
+				return "", errors.Annotatef(err, "cannot remove %s service %q", initSystem, name)
+			}
+			lines = append(lines, remove...)
+		}
+		cmds[initSystem] = strings.Join(lines, "\n    ")
+	}
+	handler := func(initSystem string) (string, bool) {
+		cmd, ok := cmds[initSystem]
+		return cmd, ok
+	}
+	commands := []string{
+		"init_system=$(" + DiscoverInitSystemScript() + ")",
+		newShellSelectCommand("init_system", "true", handler),
+	}
+	return strings.Join(commands, "\n"), nil
+}
+
 // installStartRetryAttempts defines how much InstallAndStart retries
 // upon Start failures.
 var installStartRetryAttempts = utils.AttemptStrategy{
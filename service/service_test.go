@@ -4,13 +4,16 @@
 package service_test
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/common"
 	svctesting "github.com/juju/juju/service/common/testing"
 	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/service/upstart"
@@ -57,6 +60,15 @@ func (s *serviceSuite) TestNewServiceKnown(c *gc.C) {
 	}
 }
 
+func (s *serviceSuite) TestNewServiceIgnoresLocalPrefix(c *gc.C) {
+	// The service may be for another machine, so the local
+	// JUJU_PREFIX must not move it.
+	s.PatchEnvironment(osenv.JujuPrefixEnvKey, "/sandbox")
+	svc, err := service.NewService(s.Name, s.Conf, "vivid")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(svc.(*systemd.Service).Dirname, gc.Equals, "/var/lib/juju/init/"+s.Name)
+}
+
 func (s *serviceSuite) TestNewServiceMissingName(c *gc.C) {
 	_, err := service.NewService("", s.Conf, service.InitSystemUpstart)
 
@@ -69,12 +81,70 @@ func (s *serviceSuite) TestNewServiceUnknown(c *gc.C) {
 	c.Check(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *serviceSuite) TestValidate(c *gc.C) {
+	for _, initSystem := range []string{
+		service.InitSystemUpstart,
+		service.InitSystemOpenRC,
+	} {
+		c.Logf("checking %q", initSystem)
+		err := service.Validate(s.Conf, initSystem)
+
+		c.Check(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *serviceSuite) TestValidateWindows(c *gc.C) {
+	s.Conf.ExecStart = `C:\Juju\bin\jujud.exe machine 0`
+
+	err := service.Validate(s.Conf, service.InitSystemWindows)
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *serviceSuite) TestValidateInvalid(c *gc.C) {
+	s.Conf.ExecStart = "jujud machine-0"
+
+	for _, initSystem := range []string{
+		service.InitSystemSystemd,
+		service.InitSystemUpstart,
+		service.InitSystemOpenRC,
+		service.InitSystemWindows,
+	} {
+		c.Logf("checking %q", initSystem)
+		err := service.Validate(s.Conf, initSystem)
+
+		field, ok := common.ConfInvalidField(err)
+		c.Check(ok, jc.IsTrue)
+		c.Check(field, gc.Equals, "ExecStart")
+	}
+}
+
+func (s *serviceSuite) TestValidateNotSupported(c *gc.C) {
+	s.Conf.Transient = true
+
+	err := service.Validate(s.Conf, service.InitSystemOpenRC)
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *serviceSuite) TestValidateUnknown(c *gc.C) {
+	err := service.Validate(s.Conf, "<unknown>")
+
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *serviceSuite) TestListServices(c *gc.C) {
 	_, err := service.ListServices()
 
 	c.Check(err, jc.ErrorIsNil)
 }
 
+func (s *serviceSuite) TestListServicesMatchingRegexp(c *gc.C) {
+	_, err := service.ListServicesMatchingRegexp(regexp.MustCompile(`^jujud-(machine|unit)-`))
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
 func (*serviceSuite) TestListServicesScript(c *gc.C) {
 	script := service.ListServicesScript()
 
@@ -90,6 +160,9 @@ func (*serviceSuite) TestListServicesScript(c *gc.C) {
 		`upstart)`,
 		`    sudo initctl list | awk '{print $1}' | sort | uniq`,
 		`    ;;`,
+		`openrc)`,
+		`    ls -1 /etc/init.d | sort | uniq`,
+		`    ;;`,
 		`*)`,
 		`    exit 1`,
 		`    ;;`,
@@ -98,6 +171,113 @@ func (*serviceSuite) TestListServicesScript(c *gc.C) {
 	c.Check(strings.Split(script, "\n"), jc.DeepEquals, expected)
 }
 
+func (*serviceSuite) TestListServicesScriptMatching(c *gc.C) {
+	script, err := service.ListServicesScriptMatching("jujud-")
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := strings.Split(service.DiscoverInitSystemScript(), "\n")
+	expected[0] = "init_system=$(" + expected[0]
+	expected[len(expected)-1] += ")"
+	expected = append(expected,
+		`case "$init_system" in`,
+		`systemd)`,
+		`    /bin/systemctl list-unit-files --no-legend --no-page -t service 'jujud-*'`+
+			` | grep -o -P '^\w[\S]*(?=\.service)'`,
+		`    ;;`,
+		`upstart)`,
+		`    sudo initctl list | awk 'index($1, "jujud-") == 1 {print $1}' | sort | uniq`,
+		`    ;;`,
+		`openrc)`,
+		`    ls -1 /etc/init.d | awk 'index($0, "jujud-") == 1' | sort | uniq`,
+		`    ;;`,
+		`*)`,
+		`    exit 1`,
+		`    ;;`,
+		`esac`,
+	)
+	c.Check(strings.Split(script, "\n"), jc.DeepEquals, expected)
+}
+
+func (*serviceSuite) TestListServicesScriptMatchingRegexp(c *gc.C) {
+	script := service.ListServicesScriptMatchingRegexp(regexp.MustCompile(`^jujud-unit-[a-z]+-\d+$`))
+
+	filter := ` | { grep -P '^jujud-unit-[a-z]+-\d+$' || true; }`
+	expected := strings.Split(service.DiscoverInitSystemScript(), "\n")
+	expected[0] = "init_system=$(" + expected[0]
+	expected[len(expected)-1] += ")"
+	expected = append(expected,
+		`case "$init_system" in`,
+		`systemd)`,
+		`    /bin/systemctl list-unit-files --no-legend --no-page -t service 'jujud-unit-*'`+
+			` | grep -o -P '^\w[\S]*(?=\.service)'`+filter,
+		`    ;;`,
+		`upstart)`,
+		`    sudo initctl list | awk 'index($1, "jujud-unit-") == 1 {print $1}' | sort | uniq`+filter,
+		`    ;;`,
+		`openrc)`,
+		`    ls -1 /etc/init.d | awk 'index($0, "jujud-unit-") == 1' | sort | uniq`+filter,
+		`    ;;`,
+		`*)`,
+		`    exit 1`,
+		`    ;;`,
+		`esac`,
+	)
+	c.Check(strings.Split(script, "\n"), jc.DeepEquals, expected)
+}
+
+func (*serviceSuite) TestListServicesScriptMatchingInvalid(c *gc.C) {
+	_, err := service.ListServicesScriptMatching("jujud-'; rm -rf /")
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (*serviceSuite) TestListServicesMatchingInvalid(c *gc.C) {
+	_, err := service.ListServicesMatching("jujud *")
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (*serviceSuite) TestRemoveServicesScript(c *gc.C) {
+	script, err := service.RemoveServicesScript("trusty", "jujud-machine-0", "juju-db")
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := strings.Split(service.DiscoverInitSystemScript(), "\n")
+	expected[0] = "init_system=$(" + expected[0]
+	expected[len(expected)-1] += ")"
+	expected = append(expected,
+		`case "$init_system" in`,
+		`systemd)`,
+		`    /bin/systemctl stop jujud-machine-0.service || true`,
+		`    /bin/systemctl disable jujud-machine-0.service || true`,
+		`    rm -rf '/var/lib/juju/init/jujud-machine-0'`,
+		`    /bin/systemctl daemon-reload`,
+		`    /bin/systemctl stop juju-db.service || true`,
+		`    /bin/systemctl disable juju-db.service || true`,
+		`    rm -rf '/var/lib/juju/init/juju-db'`,
+		`    /bin/systemctl daemon-reload`,
+		`    ;;`,
+		`upstart)`,
+		`    stop jujud-machine-0 || true`,
+		`    rm -f /etc/init/jujud-machine-0.conf`,
+		`    stop juju-db || true`,
+		`    rm -f /etc/init/juju-db.conf`,
+		`    ;;`,
+		`openrc)`,
+		`    rc-service jujud-machine-0 stop || true`,
+		`    rc-update del jujud-machine-0 default || true`,
+		`    rm -f /etc/init.d/jujud-machine-0`,
+		`    rc-service juju-db stop || true`,
+		`    rc-update del juju-db default || true`,
+		`    rm -f /etc/init.d/juju-db`,
+		`    ;;`,
+		`*)`,
+		`    true`,
+		`    ;;`,
+		`esac`,
+	)
+	c.Check(strings.Split(script, "\n"), jc.DeepEquals, expected)
+}
+
 func (s *serviceSuite) TestInstallAndStartOkay(c *gc.C) {
 	s.PatchAttempts(5)
 
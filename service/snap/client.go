@@ -0,0 +1,177 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package snap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// socketPath is the path of snapd's REST API socket.
+var socketPath = "/run/snapd.socket"
+
+// newClient returns a client for the local snapd. The snap command is
+// used to change snaps, since it waits for the change to complete, but
+// the API gives a structured view of the installed snaps.
+var newClient = func() *client {
+	return &client{
+		http: &http.Client{
+			Transport: &http.Transport{
+				Dial: func(_, _ string) (net.Conn, error) {
+					return net.Dial("unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// client reads the state of the installed snaps from snapd.
+type client struct {
+	http *http.Client
+}
+
+// snapInfo holds the details of an installed snap.
+type snapInfo struct {
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	Revision        string `json:"revision"`
+	TrackingChannel string `json:"tracking-channel"`
+	Confinement     string `json:"confinement"`
+	Epoch           epoch  `json:"epoch"`
+}
+
+// appInfo holds the state of a snap's daemon.
+type appInfo struct {
+	Snap    string `json:"snap"`
+	Name    string `json:"name"`
+	Daemon  string `json:"daemon"`
+	Enabled bool   `json:"enabled"`
+	Active  bool   `json:"active"`
+}
+
+// Snap returns the details of the named installed snap.
+func (c *client) Snap(name string) (*snapInfo, error) {
+	var info snapInfo
+	if err := c.get("/v2/snaps/"+name, nil, &info); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &info, nil
+}
+
+// App returns the state of the named daemon, given as "snap.app".
+func (c *client) App(name string) (*appInfo, error) {
+	var apps []appInfo
+	query := url.Values{"names": {name}, "select": {"service"}}
+	if err := c.get("/v2/apps", query, &apps); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, app := range apps {
+		if app.Snap+"."+app.Name == name {
+			return &app, nil
+		}
+	}
+	return nil, errors.NotFoundf("snap service %q", name)
+}
+
+// response is the envelope of every snapd response.
+type response struct {
+	Type       string          `json:"type"`
+	StatusCode int             `json:"status-code"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// errorResult is the result of an error response.
+type errorResult struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind"`
+}
+
+func (c *client) get(path string, query url.Values, result interface{}) error {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     "localhost",
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	resp, err := c.http.Get(u.String())
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to snapd")
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return errors.Annotatef(err, "cannot decode snapd response to %q", path)
+	}
+	if r.Type == "error" {
+		var e errorResult
+		if err := json.Unmarshal(r.Result, &e); err != nil {
+			return errors.Annotatef(err, "cannot decode snapd error for %q", path)
+		}
+		if r.StatusCode == http.StatusNotFound {
+			return errors.NewNotFound(nil, e.Message)
+		}
+		return errors.Errorf("snapd: %s", e.Message)
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return errors.Annotatef(err, "cannot decode snapd result for %q", path)
+	}
+	return nil
+}
+
+// epoch is a snap's epoch: the data epochs its revision can read.
+type epoch struct {
+	Read  []uint32 `json:"read"`
+	Write []uint32 `json:"write"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Older versions of snapd
+// report the epoch as a string such as "1" or "1*", where the star
+// means that epoch 0 can be read too.
+func (e *epoch) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		type plain epoch
+		return json.Unmarshal(data, (*plain)(e))
+	}
+	star := strings.HasSuffix(s, "*")
+	n, err := strconv.ParseUint(strings.TrimSuffix(s, "*"), 10, 32)
+	if err != nil {
+		return errors.NotValidf("epoch %q", s)
+	}
+	*e = epoch{Read: []uint32{uint32(n)}, Write: []uint32{uint32(n)}}
+	if star && n > 0 {
+		e.Read = []uint32{uint32(n - 1), uint32(n)}
+	}
+	return nil
+}
+
+// CanRead reports whether data of the provided epoch can be read. A
+// snap that declares no epoch has epoch 0.
+func (e epoch) CanRead(n uint32) bool {
+	if len(e.Read) == 0 {
+		return n == 0
+	}
+	for _, r := range e.Read {
+		if r == n {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the epoch in the form used in snap.yaml.
+func (e epoch) String() string {
+	if len(e.Read) == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%v", e.Read)
+}
@@ -0,0 +1,420 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package snap implements the service interface for daemons that are
+// delivered as snaps. The daemon itself is defined by the snap, so the
+// service's conf only describes it; installing the service installs
+// (or refreshes) the snap that provides it.
+package snap
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/shell"
+
+	"github.com/juju/juju/service/common"
+)
+
+// BinDir is the directory in which snapd exposes the snaps' apps.
+const BinDir = "/snap/bin"
+
+var (
+	logger   = loggo.GetLogger("juju.service.snap")
+	renderer = &shell.BashRenderer{}
+)
+
+var (
+	snapNameRE = regexp.MustCompile(`^[a-z0-9](-?[a-z0-9])*$`)
+	appNameRE  = regexp.MustCompile(`^[A-Za-z0-9](-?[A-Za-z0-9])*$`)
+	channelRE  = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*(/[a-z0-9][a-z0-9._-]*){0,2}$`)
+)
+
+// risks holds the channel risk levels, from the most to the least
+// stable.
+var risks = []string{"stable", "candidate", "beta", "edge"}
+
+// App identifies the snap daemon that provides a service, and how its
+// snap is to be installed.
+type App struct {
+	// Snap is the name of the snap.
+	Snap string
+
+	// Name is the name of the daemon app in the snap.
+	Name string
+
+	// Channel is the channel the snap is installed from and pinned
+	// to, e.g. "4.0/stable". If empty the snap tracks snapd's default
+	// channel.
+	Channel string
+
+	// Classic is whether the snap uses classic confinement.
+	Classic bool
+
+	// Epoch is the epoch of the service's data. The installed snap
+	// must be able to read it, so a refresh to a revision that cannot
+	// is reverted. Snaps that do not declare an epoch have epoch 0.
+	Epoch uint32
+}
+
+// Validate checks the app's values for correctness. The values are
+// used verbatim in shell commands, so anything that would need quoting
+// is rejected.
+func (a App) Validate() error {
+	if !snapNameRE.MatchString(a.Snap) {
+		return errors.NotValidf("snap name %q", a.Snap)
+	}
+	if !appNameRE.MatchString(a.Name) {
+		return errors.NotValidf("app name %q", a.Name)
+	}
+	if a.Channel != "" && !channelRE.MatchString(a.Channel) {
+		return errors.NotValidf("channel %q", a.Channel)
+	}
+	return nil
+}
+
+// Command returns the path of the app's command.
+func (a App) Command() string {
+	if a.Name == a.Snap {
+		return BinDir + "/" + a.Snap
+	}
+	return BinDir + "/" + a.Snap + "." + a.Name
+}
+
+// String returns the name by which snapd knows the app's service.
+func (a App) String() string {
+	return a.Snap + "." + a.Name
+}
+
+// normalizeChannel returns the full "track/risk[/branch]" form of the
+// channel, as reported by snapd for the channel a snap is tracking.
+func normalizeChannel(channel string) string {
+	parts := strings.Split(channel, "/")
+	isRisk := func(s string) bool {
+		for _, risk := range risks {
+			if s == risk {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case len(parts) == 1 && isRisk(parts[0]):
+		return "latest/" + channel
+	case len(parts) == 1:
+		return channel + "/stable"
+	case len(parts) == 2 && isRisk(parts[0]):
+		return "latest/" + channel
+	}
+	return channel
+}
+
+// Service provides visibility into and control over a daemon that is
+// delivered as a snap.
+type Service struct {
+	common.Service
+
+	// App identifies the daemon.
+	App App
+}
+
+// NewService returns a new Service for the named service, provided by
+// the app. If the conf has no ExecStart the app's command is used.
+func NewService(name string, conf common.Conf, app App) (*Service, error) {
+	if err := app.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if conf.ExecStart == "" {
+		conf.ExecStart = app.Command()
+	}
+	svc := &Service{
+		Service: common.Service{
+			Name: name,
+			Conf: conf,
+		},
+		App: app,
+	}
+	return svc, nil
+}
+
+// Name implements service.Service.
+func (s Service) Name() string {
+	return s.Service.Name
+}
+
+// Conf implements service.Service.
+func (s Service) Conf() common.Conf {
+	return s.Service.Conf
+}
+
+// Validate returns an error if the service is not adequately defined.
+// Everything other than the description is up to the snap, so the
+// conf may hold no more than the app's command and a description.
+func (s *Service) Validate() error {
+	if err := s.App.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := s.Service.Validate(renderer); err != nil {
+		return errors.Trace(err)
+	}
+	if s.Service.Conf.ExecStart != s.App.Command() {
+		return common.ConfInvalidf("ExecStart", "ExecStart %q does not match app %s", s.Service.Conf.ExecStart, s.App)
+	}
+	rest := s.Service.Conf
+	rest.Desc, rest.ExecStart = "", ""
+	if !rest.IsZero() {
+		return errors.NotSupportedf("conf fields other than Desc and ExecStart")
+	}
+	return nil
+}
+
+// Installed returns whether the snap that provides the service is
+// installed.
+func (s *Service) Installed() (bool, error) {
+	_, err := newClient().Snap(s.App.Snap)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// Exists returns whether the snap is installed from the app's channel,
+// can read the app's epoch and provides the app's daemon.
+func (s *Service) Exists() (bool, error) {
+	if err := s.Validate(); err != nil {
+		return false, errors.Trace(err)
+	}
+	client := newClient()
+	info, err := client.Snap(s.App.Snap)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !s.tracking(info) || !info.Epoch.CanRead(s.App.Epoch) {
+		return false, nil
+	}
+	_, err = client.App(s.App.String())
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// tracking reports whether the installed snap tracks the app's channel.
+func (s *Service) tracking(info *snapInfo) bool {
+	return s.App.Channel == "" || normalizeChannel(info.TrackingChannel) == normalizeChannel(s.App.Channel)
+}
+
+// Running returns whether the service's daemon is active.
+func (s *Service) Running() (bool, error) {
+	app, err := newClient().App(s.App.String())
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return app.Active, nil
+}
+
+// Start starts the service's daemon and enables it to start on boot.
+func (s *Service) Start() error {
+	running, err := s.Running()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if running {
+		return nil
+	}
+	installed, err := s.Installed()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !installed {
+		return common.NewNotInstalledError(s.Service.Name)
+	}
+	return errors.Trace(runSnap("start", "--enable", s.App.String()))
+}
+
+// Stop stops the service's daemon.
+func (s *Service) Stop() error {
+	running, err := s.Running()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !running {
+		return nil
+	}
+	return errors.Trace(runSnap("stop", s.App.String()))
+}
+
+// Restart restarts the service's daemon.
+func (s *Service) Restart() error {
+	installed, err := s.Installed()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !installed {
+		return common.NewNotInstalledError(s.Service.Name)
+	}
+	return errors.Trace(runSnap("restart", s.App.String()))
+}
+
+// Install installs the snap that provides the service. If the snap is
+// already installed but tracks a different channel it is refreshed to
+// the app's channel.
+func (s *Service) Install() error {
+	if err := s.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	info, err := newClient().Snap(s.App.Snap)
+	switch {
+	case errors.IsNotFound(err):
+		if err := runSnap(s.installArgs()...); err != nil {
+			return errors.Annotatef(err, "snap: could not install %q", s.App.Snap)
+		}
+		return errors.Trace(s.checkApp())
+	case err != nil:
+		return errors.Trace(err)
+	case !s.tracking(info):
+		return errors.Trace(s.refresh())
+	}
+	return errors.Trace(s.checkApp())
+}
+
+// Refresh refreshes the snap that provides the service to the latest
+// revision in the app's channel. If that revision cannot read the
+// app's epoch the snap is reverted to the previous revision.
+func (s *Service) Refresh() error {
+	if err := s.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	installed, err := s.Installed()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !installed {
+		return common.NewNotInstalledError(s.Service.Name)
+	}
+	return errors.Trace(s.refresh())
+}
+
+func (s *Service) refresh() error {
+	args := []string{"refresh"}
+	if s.App.Channel != "" {
+		args = append(args, "--channel="+s.App.Channel)
+	}
+	if err := runSnap(append(args, s.App.Snap)...); err != nil {
+		return errors.Annotatef(err, "snap: could not refresh %q", s.App.Snap)
+	}
+	err := s.checkApp()
+	if errors.IsNotValid(err) {
+		logger.Warningf("reverting snap %q: %v", s.App.Snap, err)
+		if err := runSnap("revert", s.App.Snap); err != nil {
+			logger.Errorf("failed to revert snap %q: %v", s.App.Snap, err)
+		}
+	}
+	return errors.Trace(err)
+}
+
+// checkApp checks that the installed snap can read the app's epoch
+// and provides the app's daemon.
+func (s *Service) checkApp() error {
+	client := newClient()
+	info, err := client.Snap(s.App.Snap)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !info.Epoch.CanRead(s.App.Epoch) {
+		return errors.NotValidf("snap %q revision %s (epoch %s) for data epoch %d",
+			s.App.Snap, info.Revision, info.Epoch, s.App.Epoch)
+	}
+	if _, err := client.App(s.App.String()); errors.IsNotFound(err) {
+		return errors.NotValidf("snap %q revision %s without daemon %q",
+			s.App.Snap, info.Revision, s.App.Name)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+func (s *Service) installArgs() []string {
+	args := []string{"install"}
+	if s.App.Classic {
+		args = append(args, "--classic")
+	}
+	if s.App.Channel != "" {
+		args = append(args, "--channel="+s.App.Channel)
+	}
+	return append(args, s.App.Snap)
+}
+
+// Remove removes the snap that provides the service. Note that this
+// removes any other services the snap provides too.
+func (s *Service) Remove() error {
+	installed, err := s.Installed()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !installed {
+		return nil
+	}
+	return errors.Trace(runSnap("remove", s.App.Snap))
+}
+
+// InstallCommands returns shell commands to install the service. The
+// refresh pins an already installed snap to the app's channel; unlike
+// Install, the commands do not check the app's epoch.
+func (s *Service) InstallCommands() ([]string, error) {
+	if err := s.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	cmds := []string{"snap " + strings.Join(s.installArgs(), " ")}
+	if s.App.Channel != "" {
+		cmds = append(cmds, fmt.Sprintf("snap refresh --channel=%s %s", s.App.Channel, s.App.Snap))
+	}
+	return cmds, nil
+}
+
+// StartCommands returns shell commands to start the service.
+func (s *Service) StartCommands() ([]string, error) {
+	if err := s.App.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []string{"snap start --enable " + s.App.String()}, nil
+}
+
+// RemoveCommands returns shell commands to stop and remove the service.
+func (s *Service) RemoveCommands() ([]string, error) {
+	if err := s.App.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []string{
+		fmt.Sprintf("snap stop %s || true", s.App),
+		fmt.Sprintf("snap remove %s || true", s.App.Snap),
+	}, nil
+}
+
+// runSnap runs the snap command with the provided arguments. It is
+// patched out during tests.
+var runSnap = func(args ...string) error {
+	out, err := exec.Command("snap", args...).CombinedOutput()
+	logger.Tracef("snap %s: %q", strings.Join(args, " "), out)
+	if err == nil {
+		return nil
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) > 0 {
+		return fmt.Errorf("exec %q: %v (%s)", append([]string{"snap"}, args...), err, out)
+	}
+	return fmt.Errorf("exec %q: %v", append([]string{"snap"}, args...), err)
+}
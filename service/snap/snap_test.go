@@ -0,0 +1,380 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package snap_test
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strings"
+	stdtesting "testing"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/snap"
+)
+
+func Test(t *stdtesting.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping snap tests on windows")
+	}
+	gc.TestingT(t)
+}
+
+// fakeSnapd serves the parts of the snapd API used by the snap
+// package. The results are the raw JSON of each installed snap and
+// service.
+type fakeSnapd struct {
+	snaps map[string]string
+	apps  map[string]string
+}
+
+func (f *fakeSnapd) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var result string
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/v2/snaps/"):
+		name := strings.TrimPrefix(req.URL.Path, "/v2/snaps/")
+		if info, ok := f.snaps[name]; ok {
+			result = info
+		}
+	case req.URL.Path == "/v2/apps":
+		name := req.URL.Query().Get("names")
+		if app, ok := f.apps[name]; ok {
+			result = "[" + app + "]"
+		}
+	}
+	if result == "" {
+		fmt.Fprint(w, `{"type": "error", "status-code": 404, "result": {"message": "snap not installed", "kind": "snap-not-found"}}`)
+		return
+	}
+	fmt.Fprintf(w, `{"type": "sync", "status-code": 200, "result": %s}`, result)
+}
+
+func (f *fakeSnapd) install(name, channel, revision, epoch string) {
+	f.snaps[name] = fmt.Sprintf(`{"name": %q, "revision": %q, "tracking-channel": %q, "epoch": %s}`,
+		name, revision, channel, epoch)
+}
+
+func (f *fakeSnapd) setApp(snapName, app string, active bool) {
+	f.apps[snapName+"."+app] = fmt.Sprintf(`{"snap": %q, "name": %q, "daemon": "simple", "active": %v}`,
+		snapName, app, active)
+}
+
+type snapSuite struct {
+	testing.IsolationSuite
+
+	stub    *testing.Stub
+	snapd   *fakeSnapd
+	onRun   func(args []string)
+	service *snap.Service
+}
+
+var _ = gc.Suite(&snapSuite{})
+
+func (s *snapSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+
+	s.snapd = &fakeSnapd{
+		snaps: make(map[string]string),
+		apps:  make(map[string]string),
+	}
+	socket := filepath.Join(c.MkDir(), "snapd.socket")
+	listener, err := net.Listen("unix", socket)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { listener.Close() })
+	go http.Serve(listener, s.snapd)
+	s.PatchValue(snap.SocketPath, socket)
+
+	s.stub = &testing.Stub{}
+	s.onRun = nil
+	s.PatchValue(snap.RunSnap, func(args ...string) error {
+		s.stub.AddCall("snap", strings.Join(args, " "))
+		if s.onRun != nil {
+			s.onRun(args)
+		}
+		return s.stub.NextErr()
+	})
+
+	s.service, err = snap.NewService("juju-db", common.Conf{
+		Desc: "juju state database",
+	}, snap.App{
+		Snap:    "juju-db",
+		Name:    "daemon",
+		Channel: "4.0/stable",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *snapSuite) checkCalls(c *gc.C, calls ...string) {
+	var expected []testing.StubCall
+	for _, call := range calls {
+		expected = append(expected, testing.StubCall{
+			FuncName: "snap",
+			Args:     []interface{}{call},
+		})
+	}
+	s.stub.CheckCalls(c, expected)
+}
+
+func (s *snapSuite) TestNewService(c *gc.C) {
+	c.Check(s.service.Name(), gc.Equals, "juju-db")
+	c.Check(s.service.Conf(), jc.DeepEquals, common.Conf{
+		Desc:      "juju state database",
+		ExecStart: "/snap/bin/juju-db.daemon",
+	})
+}
+
+func (s *snapSuite) TestNewServiceInvalidApp(c *gc.C) {
+	for _, app := range []snap.App{
+		{Snap: "Juju-DB", Name: "daemon"},
+		{Snap: "juju-db", Name: "daemon; rm -rf /"},
+		{Snap: "juju-db", Name: "daemon", Channel: "4.0/stable --devmode"},
+	} {
+		_, err := snap.NewService("juju-db", common.Conf{Desc: "db"}, app)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *snapSuite) TestAppCommand(c *gc.C) {
+	c.Check(snap.App{Snap: "jujud", Name: "jujud"}.Command(), gc.Equals, "/snap/bin/jujud")
+	c.Check(snap.App{Snap: "juju-db", Name: "daemon"}.Command(), gc.Equals, "/snap/bin/juju-db.daemon")
+}
+
+func (s *snapSuite) TestNormalizeChannel(c *gc.C) {
+	for channel, expected := range map[string]string{
+		"stable":            "latest/stable",
+		"edge/fix":          "latest/edge/fix",
+		"4.0":               "4.0/stable",
+		"4.0/candidate":     "4.0/candidate",
+		"4.0/edge/fix-1234": "4.0/edge/fix-1234",
+	} {
+		c.Check(snap.NormalizeChannel(channel), gc.Equals, expected)
+	}
+}
+
+func (s *snapSuite) TestValidateUnsupportedConf(c *gc.C) {
+	s.service.Service.Conf.Env = map[string]string{"FOO": "bar"}
+
+	err := s.service.Validate()
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *snapSuite) TestValidateExecStart(c *gc.C) {
+	s.service.Service.Conf.ExecStart = "/usr/bin/mongod"
+
+	err := s.service.Validate()
+
+	c.Check(err, jc.Satisfies, common.IsConfInvalid)
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "ExecStart")
+}
+
+func (s *snapSuite) TestInstall(c *gc.C) {
+	s.onRun = func(args []string) {
+		s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+		s.snapd.setApp("juju-db", "daemon", false)
+	}
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "install --channel=4.0/stable juju-db")
+	exists, err := s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsTrue)
+}
+
+func (s *snapSuite) TestInstallClassic(c *gc.C) {
+	s.service.App.Classic = true
+	s.service.App.Channel = ""
+	s.onRun = func(args []string) {
+		s.snapd.install("juju-db", "latest/stable", "42", `"0"`)
+		s.snapd.setApp("juju-db", "daemon", false)
+	}
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "install --classic juju-db")
+}
+
+func (s *snapSuite) TestInstallAlreadyInstalled(c *gc.C) {
+	s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", true)
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c)
+}
+
+func (s *snapSuite) TestInstallMissingDaemon(c *gc.C) {
+	s.onRun = func(args []string) {
+		s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	}
+
+	err := s.service.Install()
+
+	c.Check(err, gc.ErrorMatches, `snap "juju-db" revision 42 without daemon "daemon" not valid`)
+}
+
+func (s *snapSuite) TestInstallPinsChannel(c *gc.C) {
+	s.snapd.install("juju-db", "latest/stable", "41", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", true)
+	exists, err := s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsFalse)
+	s.onRun = func(args []string) {
+		s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	}
+
+	err = s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "refresh --channel=4.0/stable juju-db")
+}
+
+func (s *snapSuite) TestRefreshRevertsEpoch(c *gc.C) {
+	s.snapd.install("juju-db", "4.0/stable", "41", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", true)
+	s.onRun = func(args []string) {
+		if args[0] == "refresh" {
+			s.snapd.install("juju-db", "4.0/stable", "42", `"2"`)
+		}
+	}
+
+	err := s.service.Refresh()
+
+	c.Check(err, gc.ErrorMatches, `snap "juju-db" revision 42 \(epoch \[2\]\) for data epoch 0 not valid`)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	s.checkCalls(c,
+		"refresh --channel=4.0/stable juju-db",
+		"revert juju-db",
+	)
+}
+
+func (s *snapSuite) TestRefreshEpochStar(c *gc.C) {
+	s.service.App.Epoch = 1
+	s.snapd.install("juju-db", "4.0/stable", "42", `"1*"`)
+	s.snapd.setApp("juju-db", "daemon", true)
+
+	err := s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "refresh --channel=4.0/stable juju-db")
+}
+
+func (s *snapSuite) TestRefreshNotInstalled(c *gc.C) {
+	err := s.service.Refresh()
+
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
+	s.checkCalls(c)
+}
+
+func (s *snapSuite) TestRunning(c *gc.C) {
+	running, err := s.service.Running()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsFalse)
+
+	s.snapd.setApp("juju-db", "daemon", true)
+
+	running, err = s.service.Running()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, jc.IsTrue)
+}
+
+func (s *snapSuite) TestStart(c *gc.C) {
+	s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", false)
+
+	err := s.service.Start()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "start --enable juju-db.daemon")
+}
+
+func (s *snapSuite) TestStartRunning(c *gc.C) {
+	s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", true)
+
+	err := s.service.Start()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c)
+}
+
+func (s *snapSuite) TestStartNotInstalled(c *gc.C) {
+	err := s.service.Start()
+
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
+}
+
+func (s *snapSuite) TestStop(c *gc.C) {
+	s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", true)
+
+	err := s.service.Stop()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "stop juju-db.daemon")
+}
+
+func (s *snapSuite) TestRestart(c *gc.C) {
+	s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+	s.snapd.setApp("juju-db", "daemon", true)
+
+	err := s.service.Restart()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkCalls(c, "restart juju-db.daemon")
+}
+
+func (s *snapSuite) TestRemove(c *gc.C) {
+	err := s.service.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkCalls(c)
+
+	s.snapd.install("juju-db", "4.0/stable", "42", `{"read": [0], "write": [0]}`)
+
+	err = s.service.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkCalls(c, "remove juju-db")
+}
+
+func (s *snapSuite) TestSnapdError(c *gc.C) {
+	s.snapd.snaps["juju-db"] = `{`
+
+	_, err := s.service.Installed()
+
+	c.Check(err, gc.ErrorMatches, `cannot decode snapd response to "/v2/snaps/juju-db": .*`)
+}
+
+func (s *snapSuite) TestCommands(c *gc.C) {
+	s.service.App.Classic = true
+
+	commands, err := s.service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(commands, jc.DeepEquals, []string{
+		"snap install --classic --channel=4.0/stable juju-db",
+		"snap refresh --channel=4.0/stable juju-db",
+	})
+
+	commands, err = s.service.StartCommands()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(commands, jc.DeepEquals, []string{"snap start --enable juju-db.daemon"})
+
+	commands, err = s.service.RemoveCommands()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(commands, jc.DeepEquals, []string{
+		"snap stop juju-db.daemon || true",
+		"snap remove juju-db || true",
+	})
+}
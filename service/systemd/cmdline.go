@@ -28,15 +28,30 @@ func (c commands) resolve(args string) string {
 	return binary + " " + args
 }
 
+// inRoot returns commands that act on the systemd installation under
+// the provided root directory rather than on the running system.
+func (c commands) inRoot(rootDir string) commands {
+	c.binary = c.resolve("--root=" + c.Quote(rootDir))
+	return c
+}
+
 func (c commands) unitFilename(name, dirname string) string {
 	return c.Join(dirname, name+".service")
 }
 
 func (c commands) listAll() string {
+	return c.listMatching("")
+}
+
+func (c commands) listMatching(prefix string) string {
 	// We can't just use the same command as listRunning (with an extra
 	// "--all" because it misses some inactive units.
-	args := `list-unit-files --no-legend --no-page -t service` +
-		` | grep -o -P '^\w[\S]*(?=\.service)'`
+	args := `list-unit-files --no-legend --no-page -t service`
+	if prefix != "" {
+		// Let systemctl do the filtering.
+		args += " " + c.Quote(prefix+"*")
+	}
+	args += ` | grep -o -P '^\w[\S]*(?=\.service)'`
 	return c.resolve(args)
 }
 
@@ -78,6 +93,21 @@ func (c commands) enableLinked(name, dirname string) string {
 	return c.resolve(args)
 }
 
+func (c commands) isActive(name string) string {
+	args := fmt.Sprintf("is-active --quiet %s.service", name)
+	return c.resolve(args)
+}
+
+func (c commands) stopIfLoaded(name string) string {
+	args := fmt.Sprintf("stop %s.service || true", name)
+	return c.resolve(args)
+}
+
+func (c commands) disableIfEnabled(name string) string {
+	args := fmt.Sprintf("disable %s.service || true", name)
+	return c.resolve(args)
+}
+
 func (c commands) enable(name string) string {
 	args := fmt.Sprintf("enable %s.service", name)
 	return c.resolve(args)
@@ -99,11 +129,19 @@ func (c commands) conf(name, dirname string) string {
 	return args
 }
 
+func (c commands) script(filename string) string {
+	return "cat " + c.Quote(filename)
+}
+
 func (c commands) mkdirs(dirname string) string {
 	cmds := c.MkdirAll(dirname)
 	return strings.Join(cmds, "\n")
 }
 
+func (c commands) removeAll(dirname string) string {
+	return "rm -rf " + c.Quote(dirname)
+}
+
 func (c commands) writeConf(name, dirname string, data []byte) string {
 	filename := c.unitFilename(name, dirname)
 	cmds := c.WriteFile(filename, data)
@@ -122,6 +160,12 @@ func (c commands) chmod(name, dirname string, perm os.FileMode) string {
 	return strings.Join(cmds, "\n")
 }
 
+func (c commands) chown(name, dirname, user, group string) string {
+	filename := c.Join(dirname, name)
+	cmds := c.Chown(filename, user, group)
+	return strings.Join(cmds, "\n")
+}
+
 // Cmdline exposes the core operations of interacting with systemd units.
 type Cmdline struct {
 	commands commands
@@ -131,7 +175,13 @@ type Cmdline struct {
 
 // ListAll returns the names of all enabled systemd units.
 func (cl Cmdline) ListAll() ([]string, error) {
-	cmd := cl.commands.listAll()
+	return cl.ListMatching("")
+}
+
+// ListMatching returns the names of the enabled systemd units that
+// start with the provided prefix.
+func (cl Cmdline) ListMatching(prefix string) ([]string, error) {
+	cmd := cl.commands.listMatching(prefix)
 
 	out, err := cl.runCommand(cmd, "List")
 	if err != nil {
@@ -157,6 +207,16 @@ func (cl Cmdline) conf(name, dirname string) ([]byte, error) {
 	return []byte(out), nil
 }
 
+func (cl Cmdline) script(filename string) ([]byte, error) {
+	cmd := cl.commands.script(filename)
+
+	out, err := cl.runCommand(cmd, "get script")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []byte(out), nil
+}
+
 const runCommandMsg = "%s failed (%s)"
 
 func (Cmdline) runCommand(cmd, label string) (string, error) {
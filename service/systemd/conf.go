@@ -5,8 +5,8 @@ package systemd
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -57,6 +57,10 @@ func syslogUserGroup() (string, string) {
 func normalize(name string, conf common.Conf, scriptPath string, renderer confRenderer) (common.Conf, []byte) {
 	var data []byte
 
+	// systemd ignores the whitespace around a value.
+	conf.Desc = strings.TrimSpace(conf.Desc)
+	conf.ExecStart = strings.TrimSpace(conf.ExecStart)
+
 	var cmds []string
 	if conf.Logfile != "" {
 		filename := conf.Logfile
@@ -94,18 +98,91 @@ func normalize(name string, conf common.Conf, scriptPath string, renderer confRe
 		conf.Limit = nil
 	}
 
+	conf.After = unitNames(conf.After)
+	conf.Requires = unitNames(conf.Requires)
+	conf.Wants = unitNames(conf.Wants)
+	if conf.WantedBy == defaultTarget {
+		conf.WantedBy = ""
+	}
+
 	if conf.Transient {
 		// TODO(ericsnow) Handle Transient via systemd-run command?
-		conf.ExecStopPost = commands{}.disable(name)
+		// Copy the hooks so the caller's conf is left untouched.
+		hooks := append([]string(nil), conf.ExecStopPost...)
+		conf.ExecStopPost = append(hooks, commands{}.disable(name))
 	}
 
 	return conf, data
 }
 
+// denormalize reverses normalize for a conf whose commands were moved
+// into the provided exec-start script.
+func denormalize(conf common.Conf, script []byte) common.Conf {
+	lines := strings.Split(strings.TrimRight(string(script), "\n"), "\n")
+	// Drop the shebang and the blank line that follows it.
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "#!") {
+		lines = lines[1:]
+	}
+	if len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return conf
+	}
+
+	conf.ExecStart = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	for i, line := range lines {
+		if line != "# Set up logging." {
+			continue
+		}
+		for _, logLine := range lines[i:] {
+			if strings.HasPrefix(logLine, "exec >> ") {
+				conf.Logfile = common.Unquote(strings.TrimPrefix(logLine, "exec >> "))
+			}
+		}
+		lines = lines[:i]
+		break
+	}
+	conf.ExtraScript = strings.Join(lines, "\n")
+	return conf
+}
+
+// defaultTarget is the target that pulls in juju's services at boot.
+const defaultTarget = "multi-user.target"
+
+// defaultAfter lists the units every juju service is ordered after.
+var defaultAfter = []string{
+	"syslog.target",
+	"network.target",
+	"systemd-user-sessions.service",
+}
+
+// unitNames converts the provided service names to systemd unit names.
+// Names that already have a unit suffix (e.g. ".target") are left
+// alone. An empty list results in nil.
+func unitNames(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	var units []string
+	for _, name := range names {
+		if !strings.Contains(name, ".") {
+			name += ".service"
+		}
+		units = append(units, name)
+	}
+	return units
+}
+
 func isSimpleCommand(cmd string) bool {
 	if strings.ContainsAny(cmd, "\n;|><&") {
 		return false
 	}
+	// systemd would take a trailing backslash as a line continuation.
+	if strings.HasSuffix(cmd, `\`) {
+		return false
+	}
 
 	return true
 }
@@ -120,14 +197,43 @@ func validate(name string, conf common.Conf, renderer shell.Renderer) error {
 	}
 
 	if conf.ExtraScript != "" {
-		return errors.NotValidf("unexpected ExtraScript")
+		return common.ConfInvalidf("ExtraScript", "unexpected ExtraScript")
+	}
+
+	// systemd runs the hooks directly rather than through a shell.
+	for field, cmds := range map[string][]string{
+		"ExecStartPre":  conf.ExecStartPre,
+		"ExecStartPost": conf.ExecStartPost,
+		"ExecStopPost":  conf.ExecStopPost,
+	} {
+		for _, cmd := range cmds {
+			if !isSimpleCommand(cmd) {
+				return common.ConfInvalidf(field, "%s entry %q (not a simple command) not valid", field, cmd)
+			}
+		}
+	}
+
+	// systemd would take a trailing backslash as a line continuation,
+	// and these values are written as they are.
+	for field, value := range map[string]string{
+		"Desc":             conf.Desc,
+		"WorkingDirectory": conf.WorkingDirectory,
+	} {
+		if strings.HasSuffix(value, `\`) {
+			return common.ConfInvalidf(field, "%s %q (trailing backslash) not valid", field, value)
+		}
+	}
+	for name, value := range conf.Env {
+		if strings.ContainsRune(value, 0) {
+			return common.ConfInvalidf("Env", "Env value for %q (NUL character) not valid", name)
+		}
 	}
 
-	// We ignore Desc and Logfile.
+	// We ignore Logfile.
 
 	for k := range conf.Limit {
 		if _, ok := limitMap[k]; !ok {
-			return errors.NotValidf("conf.Limit key %q", k)
+			return common.ConfInvalidf("Limit", "conf.Limit key %q not valid", k)
 		}
 	}
 
@@ -158,16 +264,11 @@ func serializeUnit(conf common.Conf) []*unit.UnitOption {
 		unitOptions = append(unitOptions, &unit.UnitOption{
 			Section: "Unit",
 			Name:    "Description",
-			Value:   conf.Desc,
+			Value:   common.EscapeSpecifiers(conf.Desc),
 		})
 	}
 
-	after := []string{
-		"syslog.target",
-		"network.target",
-		"systemd-user-sessions.service",
-	}
-	for _, name := range after {
+	for _, name := range defaultAfter {
 		unitOptions = append(unitOptions, &unit.UnitOption{
 			Section: "Unit",
 			Name:    "After",
@@ -183,6 +284,23 @@ func serializeUnit(conf common.Conf) []*unit.UnitOption {
 		})
 	}
 
+	for _, dep := range []struct {
+		name  string
+		units []string
+	}{
+		{"After", conf.After},
+		{"Requires", conf.Requires},
+		{"Wants", conf.Wants},
+	} {
+		for _, name := range dep.units {
+			unitOptions = append(unitOptions, &unit.UnitOption{
+				Section: "Unit",
+				Name:    dep.name,
+				Value:   name,
+			})
+		}
+	}
+
 	return unitOptions
 }
 
@@ -192,11 +310,16 @@ func serializeService(conf common.Conf) []*unit.UnitOption {
 	// TODO(ericsnow) Support "Type" (e.g. "forking")? For now we just
 	// use the default, "simple".
 
-	for k, v := range conf.Env {
+	var envNames []string
+	for name := range conf.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
 		unitOptions = append(unitOptions, &unit.UnitOption{
 			Section: "Service",
 			Name:    "Environment",
-			Value:   fmt.Sprintf(`"%s=%s"`, k, v),
+			Value:   common.QuoteSystemdEnv(name, conf.Env[name]),
 		})
 	}
 
@@ -208,11 +331,43 @@ func serializeService(conf common.Conf) []*unit.UnitOption {
 		})
 	}
 
+	for _, opt := range []struct{ name, value string }{
+		{"User", conf.User},
+		{"Group", conf.Group},
+		{"UMask", conf.UMask},
+		{"WorkingDirectory", common.EscapeSpecifiers(conf.WorkingDirectory)},
+	} {
+		if opt.value == "" {
+			continue
+		}
+		unitOptions = append(unitOptions, &unit.UnitOption{
+			Section: "Service",
+			Name:    opt.name,
+			Value:   opt.value,
+		})
+	}
+
+	for _, cmd := range conf.ExecStartPre {
+		unitOptions = append(unitOptions, &unit.UnitOption{
+			Section: "Service",
+			Name:    "ExecStartPre",
+			Value:   common.EscapeSpecifiers(cmd),
+		})
+	}
+
 	if conf.ExecStart != "" {
 		unitOptions = append(unitOptions, &unit.UnitOption{
 			Section: "Service",
 			Name:    "ExecStart",
-			Value:   conf.ExecStart,
+			Value:   common.EscapeSpecifiers(conf.ExecStart),
+		})
+	}
+
+	for _, cmd := range conf.ExecStartPost {
+		unitOptions = append(unitOptions, &unit.UnitOption{
+			Section: "Service",
+			Name:    "ExecStartPost",
+			Value:   common.EscapeSpecifiers(cmd),
 		})
 	}
 
@@ -233,11 +388,35 @@ func serializeService(conf common.Conf) []*unit.UnitOption {
 		})
 	}
 
-	if conf.ExecStopPost != "" {
+	if conf.TimeoutStopSec > 0 {
+		unitOptions = append(unitOptions, &unit.UnitOption{
+			Section: "Service",
+			Name:    "TimeoutStopSec",
+			Value:   strconv.Itoa(conf.TimeoutStopSec),
+		})
+	}
+
+	if conf.KillMode != "" {
+		unitOptions = append(unitOptions, &unit.UnitOption{
+			Section: "Service",
+			Name:    "KillMode",
+			Value:   conf.KillMode,
+		})
+	}
+
+	if conf.KillSignal != "" {
+		unitOptions = append(unitOptions, &unit.UnitOption{
+			Section: "Service",
+			Name:    "KillSignal",
+			Value:   conf.KillSignal,
+		})
+	}
+
+	for _, cmd := range conf.ExecStopPost {
 		unitOptions = append(unitOptions, &unit.UnitOption{
 			Section: "Service",
 			Name:    "ExecStopPost",
-			Value:   conf.ExecStopPost,
+			Value:   common.EscapeSpecifiers(cmd),
 		})
 	}
 
@@ -247,10 +426,14 @@ func serializeService(conf common.Conf) []*unit.UnitOption {
 func serializeInstall(conf common.Conf) []*unit.UnitOption {
 	var unitOptions []*unit.UnitOption
 
+	target := conf.WantedBy
+	if target == "" {
+		target = defaultTarget
+	}
 	unitOptions = append(unitOptions, &unit.UnitOption{
 		Section: "Install",
 		Name:    "WantedBy",
-		Value:   "multi-user.target",
+		Value:   target,
 	})
 
 	return unitOptions
@@ -274,29 +457,37 @@ func deserializeOptions(opts []*unit.UnitOption, renderer shell.Renderer) (commo
 		case "Unit":
 			switch uo.Name {
 			case "Description":
-				conf.Desc = uo.Value
+				conf.Desc = common.UnescapeSpecifiers(uo.Value)
 			case "After":
-				// Do nothing until we support it in common.Conf.
+				if !isDefaultAfter(uo.Value) {
+					conf.After = append(conf.After, uo.Value)
+				}
+			case "Requires":
+				conf.Requires = append(conf.Requires, uo.Value)
+			case "Wants":
+				conf.Wants = append(conf.Wants, uo.Value)
 			default:
 				return conf, errors.NotSupportedf("Unit directive %q", uo.Name)
 			}
 		case "Service":
 			switch {
 			case uo.Name == "ExecStart":
-				conf.ExecStart = uo.Value
+				conf.ExecStart = common.UnescapeSpecifiers(uo.Value)
+			case uo.Name == "ExecStartPre":
+				conf.ExecStartPre = append(conf.ExecStartPre, common.UnescapeSpecifiers(uo.Value))
+			case uo.Name == "ExecStartPost":
+				conf.ExecStartPost = append(conf.ExecStartPost, common.UnescapeSpecifiers(uo.Value))
+			case uo.Name == "ExecStopPost":
+				conf.ExecStopPost = append(conf.ExecStopPost, common.UnescapeSpecifiers(uo.Value))
 			case uo.Name == "Environment":
+				name, value, err := common.UnquoteSystemdEnv(uo.Value)
+				if err != nil {
+					return conf, errors.Annotate(err, "service environment")
+				}
 				if conf.Env == nil {
 					conf.Env = make(map[string]string)
 				}
-				var value = uo.Value
-				if strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
-					value = value[1 : len(value)-1]
-				}
-				parts := strings.SplitN(value, "=", 2)
-				if len(parts) != 2 {
-					return conf, errors.NotValidf("service environment value %q", uo.Value)
-				}
-				conf.Env[parts[0]] = parts[1]
+				conf.Env[name] = value
 			case strings.HasPrefix(uo.Name, "Limit"):
 				if conf.Limit == nil {
 					conf.Limit = make(map[string]int)
@@ -317,6 +508,24 @@ func deserializeOptions(opts []*unit.UnitOption, renderer shell.Renderer) (commo
 					return conf, errors.Trace(err)
 				}
 				conf.Timeout = timeout
+			case uo.Name == "TimeoutStopSec":
+				timeout, err := strconv.Atoi(uo.Value)
+				if err != nil {
+					return conf, errors.Trace(err)
+				}
+				conf.TimeoutStopSec = timeout
+			case uo.Name == "KillMode":
+				conf.KillMode = uo.Value
+			case uo.Name == "KillSignal":
+				conf.KillSignal = uo.Value
+			case uo.Name == "User":
+				conf.User = uo.Value
+			case uo.Name == "Group":
+				conf.Group = uo.Value
+			case uo.Name == "UMask":
+				conf.UMask = uo.Value
+			case uo.Name == "WorkingDirectory":
+				conf.WorkingDirectory = common.UnescapeSpecifiers(uo.Value)
 			case uo.Name == "Type":
 				// Do nothing until we support it in common.Conf.
 			case uo.Name == "RemainAfterExit":
@@ -329,14 +538,14 @@ func deserializeOptions(opts []*unit.UnitOption, renderer shell.Renderer) (commo
 		case "Install":
 			switch uo.Name {
 			case "WantedBy":
-				if uo.Value != "multi-user.target" {
-					return conf, errors.NotValidf("unit target %q", uo.Value)
+				if uo.Value != defaultTarget {
+					conf.WantedBy = uo.Value
 				}
 			default:
 				return conf, errors.NotSupportedf("Install directive %q", uo.Name)
 			}
 		default:
-			return conf, errors.NotSupportedf("section %q", uo.Name)
+			return conf, errors.NotSupportedf("section %q", uo.Section)
 		}
 	}
 
@@ -344,6 +553,15 @@ func deserializeOptions(opts []*unit.UnitOption, renderer shell.Renderer) (commo
 	return conf, errors.Trace(err)
 }
 
+func isDefaultAfter(name string) bool {
+	for _, after := range defaultAfter {
+		if name == after {
+			return true
+		}
+	}
+	return false
+}
+
 // CleanShutdownService is added to machines to ensure DHCP-assigned
 // IP addresses are released on shutdown, reboot, or halt. See bug
 // http://pad.lv/1348663 for more info.
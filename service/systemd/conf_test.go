@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd_test
+
+import (
+	"math/rand"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/systemd"
+)
+
+type confSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&confSuite{})
+
+// checkRoundTrip checks that the conf is read back from its unit file
+// just as it was written.
+func (*confSuite) checkRoundTrip(c *gc.C, conf common.Conf) {
+	svc, err := systemd.NewService("jujud-machine-0", conf, "/var/lib/juju")
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := systemd.Serialize("jujud-machine-0", svc.Service.Conf, renderer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := systemd.Deserialize(data, renderer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(result, jc.DeepEquals, svc.Service.Conf)
+}
+
+func (s *confSuite) TestRoundTrip(c *gc.C) {
+	s.checkRoundTrip(c, common.Conf{
+		Desc:             "juju agent for machine-0 (100% awesome)",
+		ExecStart:        `/var/lib/juju/tools/machine-0/jujud machine --data-dir "/var/lib/juju" --format %s`,
+		ExecStartPre:     []string{"/bin/mkdir -p /run/juju%i"},
+		WorkingDirectory: "/var/lib/juju/100%",
+		Env: map[string]string{
+			"SPACES":  "a b  c",
+			"QUOTES":  `"double" 'single'`,
+			"SLASHES": `C:\Juju\ \`,
+			"LINES":   "one\ntwo\r\n",
+			"PERCENT": "%n %% 100%",
+			"DOLLAR":  "$HOME ${PATH}",
+			"EMPTY":   "",
+			"EQUALS":  "a=b=c",
+		},
+	})
+}
+
+func (s *confSuite) TestRoundTripTrimsWhitespace(c *gc.C) {
+	svc, err := systemd.NewService("jujud-machine-0", common.Conf{
+		Desc:      "  juju agent\t",
+		ExecStart: "/var/lib/juju/tools/machine-0/jujud machine ",
+	}, "/var/lib/juju")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(svc.Service.Conf.Desc, gc.Equals, "juju agent")
+	c.Check(svc.Service.Conf.ExecStart, gc.Equals, "/var/lib/juju/tools/machine-0/jujud machine")
+	s.checkRoundTrip(c, svc.Service.Conf)
+}
+
+func (s *confSuite) TestRoundTripRandom(c *gc.C) {
+	rnd := rand.New(rand.NewSource(1))
+	random := func(chars string) string {
+		awkward := []rune(chars)
+		var value []rune
+		for i := rnd.Intn(12); i >= 0; i-- {
+			value = append(value, awkward[rnd.Intn(len(awkward))])
+		}
+		return string(value)
+	}
+	const line = " \t\"'\\%$=;&|<>{}#`éa"
+	for i := 0; i < 200; i++ {
+		// Commands that are not simple are moved to a script, so
+		// they do not need to survive the unit file.
+		s.checkRoundTrip(c, common.Conf{
+			Desc:      "juju %" + random(line) + "%",
+			ExecStart: "/usr/bin/jujud " + random(line),
+			Env:       map[string]string{"A": random(line + "\n\r"), "B": random(line + "\n\r")},
+		})
+	}
+}
+
+func (*confSuite) TestTrailingBackslash(c *gc.C) {
+	_, err := systemd.NewService("jujud-machine-0", common.Conf{
+		Desc:      `juju agent \`,
+		ExecStart: "/var/lib/juju/tools/machine-0/jujud machine",
+	}, "/var/lib/juju")
+
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "Desc")
+}
+
+func (*confSuite) TestTrailingBackslashExecStart(c *gc.C) {
+	svc, err := systemd.NewService("jujud-machine-0", common.Conf{
+		Desc:      "juju agent",
+		ExecStart: `/var/lib/juju/tools/machine-0/jujud machine C:\`,
+	}, "/var/lib/juju")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The command is run from a script instead.
+	c.Check(svc.Service.Conf.ExecStart, gc.Equals, "/var/lib/juju/init/jujud-machine-0/exec-start.sh")
+	c.Check(string(svc.Script), jc.Contains, `jujud machine C:\`)
+}
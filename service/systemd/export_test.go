@@ -4,11 +4,17 @@
 package systemd
 
 import (
+	"io/ioutil"
+	"path"
+	"time"
+
 	"github.com/juju/testing"
+	"github.com/juju/utils/clock"
 )
 
 var (
 	Serialize       = serialize
+	Deserialize     = deserialize
 	SyslogUserGroup = syslogUserGroup
 )
 
@@ -22,17 +28,35 @@ func PatchNewChan(patcher patcher) chan string {
 	return ch
 }
 
+func PatchClock(patcher patcher, clock clock.Clock) {
+	patcher.PatchValue(&jobClock, clock)
+}
+
+func PatchStopTimeout(patcher patcher, timeout, slack time.Duration) {
+	patcher.PatchValue(&defaultTimeoutStop, timeout)
+	patcher.PatchValue(&jobTimeoutSlack, slack)
+}
+
 func PatchNewConn(patcher patcher, stub *testing.Stub) *StubDbusAPI {
 	conn := &StubDbusAPI{Stub: stub}
 	patcher.PatchValue(&newConn, func() (dbusAPI, error) { return conn, nil })
 	return conn
 }
 
+// PatchNewPatternConn patches newConn to return conn wrapped so that
+// it can also list units by pattern.
+func PatchNewPatternConn(patcher patcher, conn *StubDbusAPI) *StubPatternDbusAPI {
+	patterned := &StubPatternDbusAPI{conn}
+	patcher.PatchValue(&newConn, func() (dbusAPI, error) { return patterned, nil })
+	return patterned
+}
+
 func PatchFileOps(patcher patcher, stub *testing.Stub) *StubFileOps {
 	fops := &StubFileOps{Stub: stub}
 	patcher.PatchValue(&removeAll, fops.RemoveAll)
 	patcher.PatchValue(&mkdirAll, fops.MkdirAll)
 	patcher.PatchValue(&createFile, fops.CreateFile)
+	patcher.PatchValue(&chown, fops.Chown)
 	return fops
 }
 
@@ -41,3 +65,26 @@ func PatchExec(patcher patcher, stub *testing.Stub) *StubExec {
 	patcher.PatchValue(&runCommands, exec.RunCommand)
 	return exec
 }
+
+// PatchAnalyze patches out systemd-analyze. Each unit passed to it is
+// recorded as a call to "RunAnalyze" with the unit's content.
+func PatchAnalyze(patcher patcher, stub *testing.Stub, out string) {
+	patcher.PatchValue(&lookPath, func(file string) (string, error) {
+		stub.AddCall("LookPath", file)
+		return "/bin/" + file, stub.NextErr()
+	})
+	patcher.PatchValue(&runAnalyze, func(filename string) ([]byte, error) {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		stub.AddCall("RunAnalyze", path.Base(filename), string(data))
+		return []byte(out), stub.NextErr()
+	})
+}
+
+// PatchHostRootDir makes the package treat rootDir as the root of the
+// host's filesystem.
+func PatchHostRootDir(patcher patcher, rootDir string) {
+	patcher.PatchValue(&hostRootDir, rootDir)
+}
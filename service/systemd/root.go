@@ -0,0 +1,202 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// unitDir is the directory in which systemd looks for the units
+// installed by the system administrator.
+const unitDir = "/etc/systemd/system"
+
+// NewServiceInRoot returns a Service that is installed into the root
+// filesystem at rootDir (e.g. a container's rootfs) rather than into
+// the host's. Such a service is managed entirely through the files
+// under rootDir: installing it links and enables its unit with
+// symlinks, just as "systemctl --root" would, so it works on images
+// that are not running. The host's dbus is never used, so starting
+// and stopping the service is not supported; it is started when the
+// container boots.
+func NewServiceInRoot(name string, conf common.Conf, dataDir, rootDir string) (*Service, error) {
+	if !filepath.IsAbs(rootDir) {
+		return nil, errors.NotValidf("relative root dir %q", rootDir)
+	}
+	svc, err := NewService(name, conf, dataDir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	svc.RootDir = rootDir
+	return svc, nil
+}
+
+// inRoot returns the path on the host of the provided path under the
+// service's RootDir.
+func (s *Service) inRoot(paths ...string) string {
+	return filepath.Join(append([]string{s.RootDir}, paths...)...)
+}
+
+// wantsLink returns the path (relative to RootDir) of the link that
+// enables the service.
+func (s *Service) wantsLink() string {
+	target := s.Service.Conf.WantedBy
+	if target == "" {
+		target = defaultTarget
+	}
+	return path.Join(unitDir, target+".wants", s.UnitName)
+}
+
+// notSupportedInRoot returns the error for an operation which needs
+// systemd itself, rather than only the files under RootDir.
+func (s *Service) notSupportedInRoot(op string) error {
+	return errors.NotSupportedf("%s service %q in root dir %q", op, s.Service.Name, s.RootDir)
+}
+
+func (s *Service) installedInRoot() (bool, error) {
+	_, err := os.Lstat(s.inRoot(unitDir, s.UnitName))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+func (s *Service) existsInRoot() (bool, error) {
+	expected, err := s.serialize()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	same, err := sameFile(s.inRoot(s.Dirname, s.ConfName), expected)
+	if err != nil || !same || s.Script == nil {
+		return same, errors.Trace(err)
+	}
+	// Unlike on the host, the script is checked too, since nothing
+	// else will notice that it is out of date.
+	same, err = sameFile(s.inRoot(s.Service.Conf.ExecStart), s.Script)
+	return same, errors.Trace(err)
+}
+
+// sameFile reports whether the file exists with the expected content.
+func sameFile(filename string, expected []byte) (bool, error) {
+	current, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return bytes.Equal(current, expected), nil
+}
+
+func (s *Service) installInRoot() error {
+	same, err := s.existsInRoot()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if same {
+		return errors.AlreadyExistsf("service %s", s.Service.Name)
+	}
+	if err := s.removeInRoot(); err != nil {
+		return errors.Trace(err)
+	}
+
+	data, err := s.serialize()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dirname := s.inRoot(s.Dirname)
+	if err := mkdirAll(dirname); err != nil {
+		return s.errorf(err, "failed to create juju-managed service dir %q", dirname)
+	}
+	if s.Script != nil {
+		// The script is not chowned since the container's users
+		// may not match the host's.
+		scriptPath := s.inRoot(s.Service.Conf.ExecStart)
+		if err := createFile(scriptPath, s.Script, 0755); err != nil {
+			return s.errorf(err, "failed to write script at %q", scriptPath)
+		}
+	}
+	filename := s.inRoot(s.Dirname, s.ConfName)
+	if err := createFile(filename, data, 0644); err != nil {
+		return s.errorf(err, "failed to write conf file %q", filename)
+	}
+
+	// The links point at paths inside the root, so they resolve
+	// correctly once the container is running.
+	links := []struct{ link, target string }{
+		{path.Join(unitDir, s.UnitName), path.Join(s.Dirname, s.ConfName)},
+		{s.wantsLink(), path.Join(unitDir, s.UnitName)},
+	}
+	for _, l := range links {
+		link := s.inRoot(l.link)
+		if err := mkdirAll(filepath.Dir(link)); err != nil {
+			return s.errorf(err, "failed to create dir for link %q", link)
+		}
+		if err := symlink(l.target, link); err != nil {
+			return s.errorf(err, "failed to link %q", link)
+		}
+	}
+	return nil
+}
+
+func (s *Service) removeInRoot() error {
+	for _, link := range []string{s.wantsLink(), path.Join(unitDir, s.UnitName)} {
+		if err := os.Remove(s.inRoot(link)); err != nil && !os.IsNotExist(err) {
+			return s.errorf(err, "failed to remove link %q", s.inRoot(link))
+		}
+	}
+	if err := removeAll(s.inRoot(s.Dirname)); err != nil {
+		return s.errorf(err, "failed to delete juju-managed conf dir")
+	}
+	return nil
+}
+
+var symlink = os.Symlink
+
+// rootInstallCommands returns the commands that install the service
+// under RootDir, using "systemctl --root" to link and enable it.
+func (s *Service) rootInstallCommands() ([]string, error) {
+	data, err := s.serialize()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	rootCmds := cmds.inRoot(s.RootDir)
+	dirname := s.inRoot(s.Dirname)
+	cmdList := []string{
+		cmds.mkdirs(dirname),
+	}
+	if s.Script != nil {
+		scriptName := renderer.Base(renderer.ScriptFilename("exec-start", ""))
+		cmdList = append(cmdList,
+			cmds.writeFile(scriptName, dirname, s.Script),
+			cmds.chmod(scriptName, dirname, 0755),
+		)
+	}
+	cmdList = append(cmdList,
+		cmds.writeConf(s.Service.Name, dirname, data),
+		rootCmds.link(s.Service.Name, s.Dirname),
+		rootCmds.enable(s.Service.Name),
+	)
+	return cmdList, nil
+}
+
+// rootRemoveCommands returns the commands that remove the service
+// from under RootDir.
+func (s *Service) rootRemoveCommands() []string {
+	rootCmds := cmds.inRoot(s.RootDir)
+	return []string{
+		fmt.Sprintf("%s || true", rootCmds.disable(s.Service.Name)),
+		cmds.removeAll(s.inRoot(s.Dirname)),
+	}
+}
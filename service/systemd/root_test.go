@@ -0,0 +1,182 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/systemd"
+)
+
+type rootSuite struct {
+	testing.IsolationSuite
+
+	rootDir string
+	conf    common.Conf
+}
+
+var _ = gc.Suite(&rootSuite{})
+
+func (s *rootSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+
+	s.rootDir = c.MkDir()
+	s.conf = common.Conf{
+		Desc:        "juju agent for machine-0",
+		ExecStart:   jujud + " machine-0",
+		ExtraScript: "echo hello",
+	}
+}
+
+func (s *rootSuite) newService(c *gc.C) *systemd.Service {
+	svc, err := systemd.NewServiceInRoot("jujud-machine-0", s.conf, "/var/lib/juju", s.rootDir)
+	c.Assert(err, jc.ErrorIsNil)
+	return svc
+}
+
+func (s *rootSuite) checkLink(c *gc.C, link, target string) {
+	dest, err := os.Readlink(filepath.Join(s.rootDir, link))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dest, gc.Equals, target)
+}
+
+func (s *rootSuite) TestNewServiceInRootRelative(c *gc.C) {
+	_, err := systemd.NewServiceInRoot("jujud-machine-0", s.conf, "/var/lib/juju", "rootfs")
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *rootSuite) TestInstall(c *gc.C) {
+	svc := s.newService(c)
+
+	err := svc.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	dirname := filepath.Join(s.rootDir, "/var/lib/juju/init/jujud-machine-0")
+	data, err := ioutil.ReadFile(filepath.Join(dirname, "jujud-machine-0.service"))
+	c.Assert(err, jc.ErrorIsNil)
+	expected, err := systemd.Serialize("jujud-machine-0.service", svc.Service.Conf, renderer)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, string(expected))
+
+	info, err := os.Stat(filepath.Join(dirname, "exec-start.sh"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0755))
+
+	s.checkLink(c, "/etc/systemd/system/jujud-machine-0.service",
+		"/var/lib/juju/init/jujud-machine-0/jujud-machine-0.service")
+	s.checkLink(c, "/etc/systemd/system/multi-user.target.wants/jujud-machine-0.service",
+		"/etc/systemd/system/jujud-machine-0.service")
+
+	installed, err := svc.Installed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(installed, jc.IsTrue)
+	exists, err := svc.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsTrue)
+}
+
+func (s *rootSuite) TestInstallWantedBy(c *gc.C) {
+	s.conf.WantedBy = "juju-machine.target"
+	svc := s.newService(c)
+
+	err := svc.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkLink(c, "/etc/systemd/system/juju-machine.target.wants/jujud-machine-0.service",
+		"/etc/systemd/system/jujud-machine-0.service")
+}
+
+func (s *rootSuite) TestInstallReplaces(c *gc.C) {
+	err := s.newService(c).Install()
+	c.Assert(err, jc.ErrorIsNil)
+	s.conf.ExtraScript = "echo goodbye"
+	svc := s.newService(c)
+	exists, err := svc.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(exists, jc.IsFalse)
+
+	err = svc.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	exists, err = svc.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exists, jc.IsTrue)
+	// Installing again is a no-op.
+	err = svc.Install()
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *rootSuite) TestRemove(c *gc.C) {
+	svc := s.newService(c)
+	err := svc.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = svc.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	installed, err := svc.Installed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(installed, jc.IsFalse)
+	for _, path := range []string{
+		"/etc/systemd/system/multi-user.target.wants/jujud-machine-0.service",
+		"/etc/systemd/system/jujud-machine-0.service",
+		"/var/lib/juju/init/jujud-machine-0",
+	} {
+		_, err := os.Lstat(filepath.Join(s.rootDir, path))
+		c.Check(os.IsNotExist(err), jc.IsTrue)
+	}
+	// Removing again is a no-op.
+	err = svc.Remove()
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *rootSuite) TestNotSupported(c *gc.C) {
+	svc := s.newService(c)
+
+	c.Check(svc.Start(), jc.Satisfies, errors.IsNotSupported)
+	c.Check(svc.Stop(), jc.Satisfies, errors.IsNotSupported)
+	c.Check(svc.Restart(), jc.Satisfies, errors.IsNotSupported)
+	_, err := svc.Running()
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	_, err = svc.Watch()
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	_, err = svc.StartCommands()
+	c.Check(err, gc.ErrorMatches, `starting service "jujud-machine-0" in root dir ".*" not supported`)
+}
+
+func (s *rootSuite) TestInstallCommands(c *gc.C) {
+	s.conf.ExtraScript = ""
+	svc := s.newService(c)
+
+	commands, err := svc.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	dirname := s.rootDir + "/var/lib/juju/init/jujud-machine-0"
+	c.Assert(commands, gc.HasLen, 4)
+	c.Check(commands[0], gc.Equals, "mkdir -p '"+dirname+"'")
+	c.Check(commands[1], gc.Matches, "(?s)cat > '"+dirname+"/jujud-machine-0.service' << 'EOF'\n.*EOF")
+	c.Check(commands[2], gc.Equals, "/bin/systemctl --root='"+s.rootDir+"' link '/var/lib/juju/init/jujud-machine-0/jujud-machine-0.service'")
+	c.Check(commands[3], gc.Equals, "/bin/systemctl --root='"+s.rootDir+"' enable jujud-machine-0.service")
+}
+
+func (s *rootSuite) TestRemoveCommands(c *gc.C) {
+	svc := s.newService(c)
+
+	commands, err := svc.RemoveCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(commands, jc.DeepEquals, []string{
+		"/bin/systemctl --root='" + s.rootDir + "' disable jujud-machine-0.service || true",
+		"rm -rf '" + s.rootDir + "/var/lib/juju/init/jujud-machine-0'",
+	})
+}
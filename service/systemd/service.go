@@ -9,10 +9,12 @@ import (
 	"path"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/coreos/go-systemd/dbus"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
 	"github.com/juju/utils/shell"
 
 	"github.com/juju/juju/service/common"
@@ -38,18 +40,59 @@ func IsRunning() (bool, error) {
 
 // ListServices returns the list of installed service names.
 func ListServices() ([]string, error) {
+	return ListServicesMatching("")
+}
+
+// ListServicesMatching returns the names of the installed services
+// that start with the provided prefix. systemd does the filtering.
+func ListServicesMatching(prefix string) ([]string, error) {
+	names, err := listServicesByPatterns([]string{prefix + "*.service"})
+	if err == nil {
+		return names, nil
+	}
+	logger.Debugf("listing services over dbus failed, using systemctl: %v", err)
+
 	// TODO(ericsnow) conn.ListUnits misses some inactive units, so we
 	// would need conn.ListUnitFiles. Such a method has been requested.
 	// (see https://github.com/coreos/go-systemd/issues/76). In the
 	// meantime we use systemctl at the shell to list the services.
-	// Once that is addressed upstread we can just call listServices here.
-	names, err := Cmdline{}.ListAll()
+	names, err = Cmdline{}.ListMatching(prefix)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	return names, nil
 }
 
+// unitPatternLister is implemented by the dbus connections which can
+// have systemd filter units by name (go-systemd with systemd 230 or
+// later).
+type unitPatternLister interface {
+	ListUnitsByPatterns(states, patterns []string) ([]dbus.UnitStatus, error)
+}
+
+// listServicesByPatterns returns the names of the services whose unit
+// names match any of the provided glob patterns, in any state. Like
+// conn.ListUnits it only sees loaded units, which juju's services are
+// since they are always enabled. It fails with a NotSupported error if
+// the connection cannot filter units.
+func listServicesByPatterns(patterns []string) ([]string, error) {
+	conn, err := newConn()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer conn.Close()
+
+	lister, ok := conn.(unitPatternLister)
+	if !ok {
+		return nil, errors.NotSupportedf("listing units by pattern")
+	}
+	units, err := lister.ListUnitsByPatterns(nil, patterns)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return serviceNames(units), nil
+}
+
 func listServices() ([]string, error) {
 	conn, err := newConn()
 	if err != nil {
@@ -61,7 +104,11 @@ func listServices() ([]string, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	return serviceNames(units), nil
+}
 
+// serviceNames returns the names of the services among the units.
+func serviceNames(units []dbus.UnitStatus) []string {
 	var services []string
 	for _, unit := range units {
 		name := unit.Name
@@ -71,7 +118,7 @@ func listServices() ([]string, error) {
 		name = strings.TrimSuffix(name, ".service")
 		services = append(services, name)
 	}
-	return services, nil
+	return services
 }
 
 // ListCommand returns a command that will list the services on a host.
@@ -79,6 +126,12 @@ func ListCommand() string {
 	return cmds.listAll()
 }
 
+// ListCommandMatching returns a command that will list the services
+// on a host that start with the provided prefix.
+func ListCommandMatching(prefix string) string {
+	return cmds.listMatching(prefix)
+}
+
 // Service provides visibility into and control over a systemd service.
 type Service struct {
 	common.Service
@@ -87,6 +140,11 @@ type Service struct {
 	UnitName string
 	Dirname  string
 	Script   []byte
+
+	// RootDir, if set, is the root filesystem (e.g. a container's
+	// rootfs) into which the service is installed. Dirname and
+	// the conf's paths are relative to it. See NewServiceInRoot.
+	RootDir string
 }
 
 // NewService returns a new value that implements Service for systemd.
@@ -121,20 +179,26 @@ type dbusAPI interface {
 	ListUnits() ([]dbus.UnitStatus, error)
 	StartUnit(string, string, chan<- string) (int, error)
 	StopUnit(string, string, chan<- string) (int, error)
+	RestartUnit(string, string, chan<- string) (int, error)
 	LinkUnitFiles([]string, bool, bool) ([]dbus.LinkUnitFileChange, error)
 	EnableUnitFiles([]string, bool, bool) (bool, []dbus.EnableUnitFileChange, error)
 	DisableUnitFiles([]string, bool) ([]dbus.DisableUnitFileChange, error)
 	GetUnitProperties(string) (map[string]interface{}, error)
 	GetUnitTypeProperties(string, string) (map[string]interface{}, error)
 	Reload() error
+	Subscribe() error
+	Unsubscribe() error
+	SetSubStateSubscriber(chan<- *dbus.SubStateUpdate, chan<- error)
 }
 
 var newConn = func() (dbusAPI, error) {
 	return dbus.New()
 }
 
+// newChan returns a buffered channel so that go-systemd's job listener
+// never blocks on a job we stopped waiting for.
 var newChan = func() chan string {
-	return make(chan string)
+	return make(chan string, 1)
 }
 
 func (s *Service) errorf(err error, msg string, args ...interface{}) error {
@@ -207,6 +271,9 @@ func (s *Service) setConf(conf common.Conf) error {
 
 // Installed implements Service.
 func (s *Service) Installed() (bool, error) {
+	if s.RootDir != "" {
+		return s.installedInRoot()
+	}
 	names, err := ListServices()
 	if err != nil {
 		return false, s.errorf(err, "failed to list services")
@@ -224,6 +291,9 @@ func (s *Service) Exists() (bool, error) {
 	if s.NoConf() {
 		return false, s.errorf(nil, "no conf expected")
 	}
+	if s.RootDir != "" {
+		return s.existsInRoot()
+	}
 
 	same, err := s.check()
 	if err != nil {
@@ -256,6 +326,38 @@ func (s *Service) readConf() (common.Conf, error) {
 	return conf, nil
 }
 
+// ReadConf returns the conf of the installed service, as read back
+// from its unit file. Commands that were moved into the service's
+// exec-start script when it was installed are moved back into
+// ExtraScript, Logfile and ExecStart, so that the conf may be used
+// with another init system.
+func (s *Service) ReadConf() (common.Conf, error) {
+	if s.RootDir != "" {
+		return common.Conf{}, s.notSupportedInRoot("reading conf of")
+	}
+	installed, err := s.Installed()
+	if err != nil {
+		return common.Conf{}, errors.Trace(err)
+	}
+	if !installed {
+		return common.Conf{}, common.NewNotInstalledError(s.Service.Name)
+	}
+
+	conf, err := s.readConf()
+	if err != nil {
+		return common.Conf{}, errors.Trace(err)
+	}
+	scriptPath := renderer.ScriptFilename("exec-start", s.Dirname)
+	if conf.ExecStart != scriptPath {
+		return conf, nil
+	}
+	script, err := Cmdline{}.script(scriptPath)
+	if err != nil {
+		return common.Conf{}, s.errorf(err, "failed to read script at %q", scriptPath)
+	}
+	return denormalize(conf, script), nil
+}
+
 func (s Service) newConn() (dbusAPI, error) {
 	conn, err := newConn()
 	if err != nil {
@@ -266,6 +368,9 @@ func (s Service) newConn() (dbusAPI, error) {
 
 // Running implements Service.
 func (s *Service) Running() (bool, error) {
+	if s.RootDir != "" {
+		return false, s.notSupportedInRoot("checking")
+	}
 	conn, err := s.newConn()
 	if err != nil {
 		return false, errors.Trace(err)
@@ -301,12 +406,15 @@ func (s *Service) Start() error {
 }
 
 func (s *Service) start() error {
+	if s.RootDir != "" {
+		return s.notSupportedInRoot("starting")
+	}
 	installed, err := s.Installed()
 	if err != nil {
 		return errors.Trace(err)
 	}
 	if !installed {
-		return errors.NotFoundf("service " + s.Service.Name)
+		return common.NewNotInstalledError(s.Service.Name)
 	}
 	running, err := s.Running()
 	if err != nil {
@@ -328,45 +436,159 @@ func (s *Service) start() error {
 		return s.errorf(err, "dbus start request failed")
 	}
 
-	if err := s.wait("start", statusCh); err != nil {
+	if err := s.wait("start", statusCh, s.startTimeout()); err != nil {
 		return errors.Trace(err)
 	}
 
 	return nil
 }
 
-func (s *Service) wait(op string, statusCh chan string) error {
-	status := <-statusCh
+func (s *Service) wait(op string, statusCh chan string, timeout time.Duration) error {
+	var status string
+	select {
+	case status = <-statusCh:
+	case <-jobClock.After(timeout):
+		err := &common.TimeoutError{
+			Service: s.Service.Name,
+			Op:      op,
+			Timeout: timeout,
+		}
+		return s.errorf(err, "systemd job did not complete")
+	}
 
 	// TODO(ericsnow) Other status values *may* be okay. See:
 	//  https://godoc.org/github.com/coreos/go-systemd/dbus#Conn.StartUnit
 	if status != "done" {
-		return s.errorf(nil, "failed to %s (API status %q)", op, status)
+		err := &common.JobFailedError{
+			Service: s.Service.Name,
+			Op:      op,
+			Result:  status,
+		}
+		return s.errorf(err, "systemd job did not succeed")
 	}
 	return nil
 }
 
+var (
+	// jobClock is used to time out waits for systemd jobs.
+	jobClock clock.Clock = clock.WallClock
+
+	// defaultTimeoutStart and defaultTimeoutStop are systemd's
+	// DefaultTimeoutStartSec and DefaultTimeoutStopSec, used when
+	// the conf does not set Timeout or TimeoutStopSec.
+	defaultTimeoutStart = 90 * time.Second
+	defaultTimeoutStop  = 90 * time.Second
+
+	// jobTimeoutSlack is how long we wait beyond systemd's own timeout
+	// for it to kill the service's processes and finish the job.
+	jobTimeoutSlack = 10 * time.Second
+)
+
+// startTimeout returns how long to wait for a start or restart job
+// to complete.
+func (s *Service) startTimeout() time.Duration {
+	timeout := defaultTimeoutStart
+	if secs := s.Service.Conf.Timeout; secs > 0 {
+		timeout = time.Duration(secs) * time.Second
+	}
+	return timeout + jobTimeoutSlack
+}
+
+// stopTimeout returns how long to wait for a stop job to complete.
+func (s *Service) stopTimeout() time.Duration {
+	timeout := defaultTimeoutStop
+	if secs := s.Service.Conf.TimeoutStopSec; secs > 0 {
+		timeout = time.Duration(secs) * time.Second
+	}
+	return timeout + jobTimeoutSlack
+}
+
 // Stop implements Service.
 func (s *Service) Stop() error {
-	err := s.stop()
+	_, err := s.StopGracefully()
+	return err
+}
+
+// StopGracefully implements service.GracefulStopService. It waits for
+// systemd to finish the stop job, which takes at most the conf's
+// TimeoutStopSec, and reports whether the service's processes had to
+// be killed once that timeout passed.
+func (s *Service) StopGracefully() (bool, error) {
+	forced, err := s.stop()
 	if errors.IsNotFound(err) {
 		logger.Debugf("service %q not running", s.Name())
-		return nil
+		return false, nil
 	} else if err != nil {
 		logger.Errorf("service %q failed to stop: %v", s.Name(), err)
+		return false, err
+	}
+	if forced {
+		logger.Warningf("service %q did not stop in time and was killed", s.Name())
+	} else {
+		logger.Debugf("service %q successfully stopped", s.Name())
+	}
+	return forced, nil
+}
+
+func (s *Service) stop() (bool, error) {
+	if s.RootDir != "" {
+		return false, s.notSupportedInRoot("stopping")
+	}
+	running, err := s.Running()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !running {
+		return false, errors.NotFoundf("running service %s", s.Service.Name)
+	}
+
+	conn, err := s.newConn()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	defer conn.Close()
+
+	statusCh := newChan()
+	_, err = conn.StopUnit(s.UnitName, "fail", statusCh)
+	if err != nil {
+		return false, s.errorf(err, "dbus stop request failed")
+	}
+
+	if err := s.wait("stop", statusCh, s.stopTimeout()); err != nil {
+		return false, errors.Trace(err)
+	}
+
+	props, err := conn.GetUnitTypeProperties(s.UnitName, "Service")
+	if err != nil {
+		return false, s.errorf(err, "dbus service properties request failed")
+	}
+	// systemd sets the result to "timeout" when the processes did not
+	// exit within TimeoutStopSec and had to be sent SIGKILL.
+	result, _ := props["Result"].(string)
+	return result == "timeout", nil
+}
+
+// Restart implements service.RestartableService. Like Start, it gives
+// up waiting once the conf's Timeout has passed.
+func (s *Service) Restart() error {
+	if err := s.restart(); err != nil {
+		logger.Errorf("service %q failed to restart: %v", s.Name(), err)
 		return err
 	}
-	logger.Debugf("service %q successfully stopped", s.Name())
+	logger.Debugf("service %q successfully restarted", s.Name())
 	return nil
 }
 
-func (s *Service) stop() error {
-	running, err := s.Running()
+func (s *Service) restart() error {
+	if s.RootDir != "" {
+		return s.notSupportedInRoot("restarting")
+	}
+	installed, err := s.Installed()
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if !running {
-		return errors.NotFoundf("running service %s", s.Service.Name)
+	if !installed {
+		return common.NewNotInstalledError(s.Service.Name)
 	}
 
 	conn, err := s.newConn()
@@ -376,16 +598,16 @@ func (s *Service) stop() error {
 	defer conn.Close()
 
 	statusCh := newChan()
-	_, err = conn.StopUnit(s.UnitName, "fail", statusCh)
+	_, err = conn.RestartUnit(s.UnitName, "fail", statusCh)
 	if err != nil {
-		return s.errorf(err, "dbus stop request failed")
+		return s.errorf(err, "dbus restart request failed")
 	}
 
-	if err := s.wait("stop", statusCh); err != nil {
+	if err := s.wait("restart", statusCh, s.startTimeout()); err != nil {
 		return errors.Trace(err)
 	}
 
-	return err
+	return nil
 }
 
 // Remove implements Service.
@@ -403,12 +625,22 @@ func (s *Service) Remove() error {
 }
 
 func (s *Service) remove() error {
+	if s.RootDir != "" {
+		installed, err := s.installedInRoot()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !installed {
+			return common.NewNotInstalledError(s.Service.Name)
+		}
+		return s.removeInRoot()
+	}
 	installed, err := s.Installed()
 	if err != nil {
 		return errors.Trace(err)
 	}
 	if !installed {
-		return errors.NotFoundf("service %s", s.Service.Name)
+		return common.NewNotInstalledError(s.Service.Name)
 	}
 
 	conn, err := s.newConn()
@@ -457,6 +689,9 @@ func (s *Service) Install() error {
 }
 
 func (s *Service) install() error {
+	if s.RootDir != "" {
+		return s.installInRoot()
+	}
 	installed, err := s.Installed()
 	if err != nil {
 		return errors.Trace(err)
@@ -527,6 +762,11 @@ func (s *Service) writeConf() (string, error) {
 		if err := createFile(scriptPath, s.Script, 0755); err != nil {
 			return filename, s.errorf(err, "failed to write script at %q", scriptPath)
 		}
+		if user, group := s.Service.Conf.User, s.Service.Conf.Group; user != "" || group != "" {
+			if err := chown(scriptPath, user, group); err != nil {
+				return filename, s.errorf(err, "failed to set ownership of script at %q", scriptPath)
+			}
+		}
 	}
 
 	if err := createFile(filename, data, 0644); err != nil {
@@ -544,11 +784,20 @@ var createFile = func(filename string, data []byte, perm os.FileMode) error {
 	return ioutil.WriteFile(filename, data, perm)
 }
 
+var chown = func(filename, user, group string) error {
+	cmd := strings.Join(renderer.Chown(filename, user, group), "\n")
+	_, err := Cmdline{}.runCommand(cmd, "chown")
+	return errors.Trace(err)
+}
+
 // InstallCommands implements Service.
 func (s *Service) InstallCommands() ([]string, error) {
 	if s.NoConf() {
 		return nil, s.errorf(nil, "missing conf")
 	}
+	if s.RootDir != "" {
+		return s.rootInstallCommands()
+	}
 
 	name := s.Name()
 	dirname := s.Dirname
@@ -568,6 +817,9 @@ func (s *Service) InstallCommands() ([]string, error) {
 			cmds.writeFile(scriptName, dirname, s.Script),
 			cmds.chmod(scriptName, dirname, 0755),
 		}...)
+		if user, group := s.Service.Conf.User, s.Service.Conf.Group; user != "" || group != "" {
+			cmdList = append(cmdList, cmds.chown(scriptName, dirname, user, group))
+		}
 	}
 	cmdList = append(cmdList, []string{
 		cmds.writeConf(name, dirname, data),
@@ -580,9 +832,32 @@ func (s *Service) InstallCommands() ([]string, error) {
 
 // StartCommands implements Service.
 func (s *Service) StartCommands() ([]string, error) {
+	if s.RootDir != "" {
+		return nil, s.notSupportedInRoot("starting")
+	}
 	name := s.Name()
 	cmdList := []string{
 		cmds.start(name),
 	}
+	if !s.Service.Conf.Transient {
+		// Fail the script if the service did not come up.
+		cmdList = append(cmdList, cmds.isActive(name))
+	}
+	return cmdList, nil
+}
+
+// RemoveCommands implements Service.
+func (s *Service) RemoveCommands() ([]string, error) {
+	if s.RootDir != "" {
+		return s.rootRemoveCommands(), nil
+	}
+	name := s.Name()
+	cmdList := []string{
+		cmds.stopIfLoaded(name),
+		// Disabling also removes the link made by InstallCommands.
+		cmds.disableIfEnabled(name),
+		cmds.removeAll(s.Dirname),
+		cmds.reload(),
+	}
 	return cmdList, nil
 }
@@ -8,12 +8,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/coreos/go-systemd/unit"
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
 	"github.com/juju/utils/exec"
 	"github.com/juju/utils/shell"
 	gc "gopkg.in/check.v1"
@@ -55,6 +57,7 @@ type initSystemSuite struct {
 
 	dataDir string
 	ch      chan string
+	clock   *coretesting.Clock
 	stub    *testing.Stub
 	conn    *systemd.StubDbusAPI
 	fops    *systemd.StubFileOps
@@ -77,6 +80,8 @@ func (s *initSystemSuite) SetUpTest(c *gc.C) {
 	s.dataDir = dataDir
 	// Patch things out.
 	s.ch = systemd.PatchNewChan(s)
+	s.clock = coretesting.NewClock(time.Time{})
+	systemd.PatchClock(s, s.clock)
 
 	s.stub = &testing.Stub{}
 	s.conn = systemd.PatchNewConn(s, s.stub)
@@ -157,6 +162,26 @@ func (s *initSystemSuite) setConf(c *gc.C, conf common.Conf) {
 	})
 }
 
+// waitForTimeout runs the provided operation, which is expected to
+// block waiting for a systemd job, and advances the clock until the
+// operation gives up.
+func (s *initSystemSuite) waitForTimeout(c *gc.C, op func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- op()
+	}()
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.clock.Advance(time.Minute)
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+	}
+	c.Fatalf("timed out waiting for operation to give up")
+	return nil
+}
+
 func (s *initSystemSuite) checkCreateFileCall(c *gc.C, index int, filename, content string, perm os.FileMode) {
 	if content == "" {
 		name := filename
@@ -206,7 +231,7 @@ func (s *initSystemSuite) TestListServices(c *gc.C) {
 		"jujud-unit-wordpress-0",
 		"another",
 	})
-	s.stub.CheckCallNames(c, "RunCommand")
+	s.stub.CheckCallNames(c, "Close", "RunCommand")
 }
 
 func (s *initSystemSuite) TestListServicesEmpty(c *gc.C) {
@@ -216,7 +241,72 @@ func (s *initSystemSuite) TestListServicesEmpty(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 
 	c.Check(names, gc.HasLen, 0)
-	s.stub.CheckCallNames(c, "RunCommand")
+	s.stub.CheckCallNames(c, "Close", "RunCommand")
+}
+
+func (s *initSystemSuite) TestListServicesMatching(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.addService("jujud-unit-wordpress-0", "active")
+	s.addListResponse()
+
+	names, err := systemd.ListServicesMatching("jujud-")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(names, jc.SameContents, []string{
+		"jujud-machine-0",
+		"jujud-unit-wordpress-0",
+	})
+	s.stub.CheckCalls(c, []testing.StubCall{{
+		FuncName: "Close",
+	}, {
+		FuncName: "RunCommand",
+		Args: []interface{}{
+			exec.RunParams{
+				Commands: `/bin/systemctl list-unit-files --no-legend --no-page -t service 'jujud-*' | grep -o -P '^\w[\S]*(?=\.service)'`,
+			},
+		},
+	}})
+}
+
+func (s *initSystemSuite) TestListServicesMatchingByPatterns(c *gc.C) {
+	systemd.PatchNewPatternConn(s, s.conn)
+	s.addService("jujud-machine-0", "active")
+	s.addService("something-else", "error")
+	s.addService("jujud-unit-wordpress-0", "inactive")
+
+	names, err := systemd.ListServicesMatching("jujud-")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(names, jc.SameContents, []string{
+		"jujud-machine-0",
+		"jujud-unit-wordpress-0",
+	})
+	s.stub.CheckCalls(c, []testing.StubCall{{
+		FuncName: "ListUnitsByPatterns",
+		Args:     []interface{}{[]string(nil), []string{"jujud-*.service"}},
+	}, {
+		FuncName: "Close",
+	}})
+}
+
+func (s *initSystemSuite) TestListServicesMatchingByPatternsFallback(c *gc.C) {
+	systemd.PatchNewPatternConn(s, s.conn)
+	s.addService("jujud-machine-0", "active")
+	s.addListResponse()
+	s.stub.SetErrors(errors.New("Unknown method 'ListUnitsByPatterns'"))
+
+	names, err := systemd.ListServicesMatching("jujud-")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(names, jc.DeepEquals, []string{"jujud-machine-0"})
+	s.stub.CheckCallNames(c, "ListUnitsByPatterns", "Close", "RunCommand")
+}
+
+func (s *initSystemSuite) TestListCommandMatching(c *gc.C) {
+	c.Check(systemd.ListCommandMatching(""), gc.Equals, listCmdArg.Commands)
+	c.Check(systemd.ListCommandMatching("jujud-"), gc.Equals,
+		`/bin/systemctl list-unit-files --no-legend --no-page -t service 'jujud-*'`+
+			` | grep -o -P '^\w[\S]*(?=\.service)'`)
 }
 
 func (s *initSystemSuite) TestNewService(c *gc.C) {
@@ -521,6 +611,105 @@ func (s *initSystemSuite) TestStart(c *gc.C) {
 	}})
 }
 
+func (s *initSystemSuite) TestStartTimeout(c *gc.C) {
+	s.addService("jujud-machine-0", "inactive")
+	s.addListResponse()
+
+	err := s.waitForTimeout(c, s.service.Start)
+
+	c.Check(err, jc.Satisfies, common.IsTimeout)
+	c.Check(err, gc.ErrorMatches, `.*timed out after 1m40s waiting to start service "jujud-machine-0".*`)
+	s.stub.CheckCallNames(c, "RunCommand", "ListUnits", "Close", "StartUnit", "Close")
+}
+
+func (s *initSystemSuite) TestReadConf(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.addListResponse()
+	s.setConf(c, s.service.Service.Conf)
+
+	conf, err := s.service.ReadConf()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(conf, jc.DeepEquals, s.service.Service.Conf)
+}
+
+func (s *initSystemSuite) TestReadConfScript(c *gc.C) {
+	s.conf.ExtraScript = "echo hello\n\necho world"
+	s.conf.Logfile = "/var/log/juju/machine-0.log"
+	svc := s.newService(c)
+	c.Assert(svc.Script, gc.NotNil)
+	s.addService("jujud-machine-0", "active")
+	s.addListResponse()
+	s.setConf(c, svc.Service.Conf)
+	s.exec.Responses = append(s.exec.Responses, exec.ExecResponse{
+		Stdout: svc.Script,
+	})
+
+	conf, err := svc.ReadConf()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(conf, jc.DeepEquals, s.conf)
+	s.stub.CheckCallNames(c, "RunCommand", "RunCommand", "RunCommand")
+	s.stub.CheckCall(c, 2, "RunCommand", exec.RunParams{
+		Commands: "cat '" + s.dataDir + "/init/jujud-machine-0/exec-start.sh'",
+	})
+}
+
+func (s *initSystemSuite) TestReadConfNotInstalled(c *gc.C) {
+	s.addListResponse()
+
+	_, err := s.service.ReadConf()
+
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
+}
+
+func (s *initSystemSuite) TestStartJobFailed(c *gc.C) {
+	s.addService("jujud-machine-0", "inactive")
+	s.ch <- "failed"
+	s.addListResponse()
+
+	err := s.service.Start()
+
+	c.Check(err, jc.Satisfies, common.IsJobFailed)
+	c.Check(err, gc.ErrorMatches, `.*failed to start service "jujud-machine-0" \(job result "failed"\).*`)
+}
+
+func (s *initSystemSuite) TestRestart(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.ch <- "done"
+	s.addListResponse()
+
+	err := s.service.Restart()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCalls(c, []testing.StubCall{{
+		FuncName: "RunCommand",
+		Args: []interface{}{
+			listCmdArg,
+		},
+	}, {
+		FuncName: "RestartUnit",
+		Args: []interface{}{
+			s.name + ".service",
+			"fail",
+			(chan<- string)(s.ch),
+		},
+	}, {
+		FuncName: "Close",
+	}})
+}
+
+func (s *initSystemSuite) TestRestartTimeout(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.addListResponse()
+	s.service.Service.Conf.Timeout = 20
+
+	err := s.waitForTimeout(c, s.service.Restart)
+
+	c.Check(err, jc.Satisfies, common.IsTimeout)
+	c.Check(err, gc.ErrorMatches, `.*timed out after 30s waiting to restart service "jujud-machine-0".*`)
+}
+
 func (s *initSystemSuite) TestStartAlreadyRunning(c *gc.C) {
 	s.addService("jujud-machine-0", "active")
 	s.ch <- "done" // just in case
@@ -542,6 +731,7 @@ func (s *initSystemSuite) TestStartNotInstalled(c *gc.C) {
 	err := s.service.Start()
 
 	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
 	s.stub.CheckCallNames(c, "RunCommand")
 }
 
@@ -563,11 +753,84 @@ func (s *initSystemSuite) TestStop(c *gc.C) {
 			"fail",
 			(chan<- string)(s.ch),
 		},
+	}, {
+		FuncName: "GetUnitTypeProperties",
+		Args: []interface{}{
+			s.name + ".service",
+			"Service",
+		},
 	}, {
 		FuncName: "Close",
 	}})
 }
 
+func (s *initSystemSuite) TestStopGracefully(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.conn.SetProperty("Service", "Result", "success")
+	s.ch <- "done"
+
+	forced, err := s.service.StopGracefully()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(forced, jc.IsFalse)
+	s.stub.CheckCallNames(c, "ListUnits", "Close", "StopUnit", "GetUnitTypeProperties", "Close")
+}
+
+func (s *initSystemSuite) TestStopGracefullyForced(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.conn.SetProperty("Service", "Result", "timeout")
+	s.ch <- "done"
+
+	forced, err := s.service.StopGracefully()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(forced, jc.IsTrue)
+}
+
+func (s *initSystemSuite) TestStopTimeout(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	systemd.PatchClock(s, clock.WallClock)
+	systemd.PatchStopTimeout(s, 0, 10*time.Millisecond)
+	// Nothing is sent on s.ch, so the stop job never completes.
+
+	forced, err := s.service.StopGracefully()
+
+	c.Check(err, gc.ErrorMatches, `.*timed out after 10ms waiting to stop.*`)
+	c.Check(forced, jc.IsFalse)
+	s.stub.CheckCallNames(c, "ListUnits", "Close", "StopUnit", "Close")
+}
+
+func (s *initSystemSuite) TestStopTimeoutDefault(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	// Nothing is sent on s.ch, so the stop job never completes.
+
+	err := s.waitForTimeout(c, func() error {
+		_, err := s.service.StopGracefully()
+		return err
+	})
+
+	// systemd's DefaultTimeoutStopSec, plus time for it to finish
+	// the job.
+	c.Check(err, jc.Satisfies, common.IsTimeout)
+	c.Check(err, gc.ErrorMatches, `.*timed out after 1m40s waiting to stop service "jujud-machine-0".*`)
+	s.stub.CheckCallNames(c, "ListUnits", "Close", "StopUnit", "Close")
+}
+
+func (s *initSystemSuite) TestStopTimeoutConf(c *gc.C) {
+	s.addService("jujud-machine-0", "active")
+	s.service.Service.Conf.TimeoutStopSec = 30
+	// Nothing is sent on s.ch, so the stop job never completes.
+
+	err := s.waitForTimeout(c, func() error {
+		_, err := s.service.StopGracefully()
+		return err
+	})
+
+	c.Check(err, jc.Satisfies, common.IsTimeout)
+	c.Check(err, gc.ErrorMatches, `.*timed out after 40s waiting to stop service "jujud-machine-0".*`)
+	s.stub.CheckCallNames(c, "ListUnits", "Close", "StopUnit", "Close")
+}
+
 func (s *initSystemSuite) TestStopNotRunning(c *gc.C) {
 	s.addService("jujud-machine-0", "inactive")
 	s.ch <- "done" // just in case
@@ -715,6 +978,7 @@ func (s *initSystemSuite) TestInstallZombie(c *gc.C) {
 		"ListUnits",
 		"Close",
 		"StopUnit",
+		"GetUnitTypeProperties",
 		"Close",
 		"RunCommand",
 		"DisableUnitFiles",
@@ -730,7 +994,7 @@ func (s *initSystemSuite) TestInstallZombie(c *gc.C) {
 	)
 	filename := fmt.Sprintf("%s/init/%s/%s.service", s.dataDir, s.name, s.name)
 	content := s.newConfStrEnv(s.name, `"a=c"`)
-	s.checkCreateFileCall(c, 12, filename, content, 0644)
+	s.checkCreateFileCall(c, 13, filename, content, 0644)
 }
 
 func (s *initSystemSuite) TestInstallMultiline(c *gc.C) {
@@ -758,6 +1022,72 @@ func (s *initSystemSuite) TestInstallMultiline(c *gc.C) {
 	s.checkCreateFileCall(c, 3, filename, content, 0644)
 }
 
+func (s *initSystemSuite) TestInstallMultilineOwnership(c *gc.C) {
+	scriptPath := fmt.Sprintf("%s/init/%s/exec-start.sh", s.dataDir, s.name)
+	cmd := "a\nb\nc"
+	s.service.Service.Conf.ExecStart = scriptPath
+	s.service.Service.Conf.User = "jujud"
+	s.service.Service.Conf.Group = "juju"
+	s.service.Script = []byte(cmd)
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c,
+		"RunCommand",
+		"MkdirAll",
+		"CreateFile",
+		"Chown",
+		"CreateFile",
+		"LinkUnitFiles",
+		"Reload",
+		"EnableUnitFiles",
+		"Close",
+	)
+	s.checkCreateFileCall(c, 2, scriptPath, cmd, 0755)
+	s.stub.CheckCall(c, 3, "Chown", scriptPath, "jujud", "juju")
+	filename := fmt.Sprintf("%s/init/%s/%s.service", s.dataDir, s.name, s.name)
+	content := strings.Replace(
+		s.newConfStrCmd(s.name, scriptPath),
+		"[Service]\n",
+		"[Service]\nUser=jujud\nGroup=juju\n",
+		1,
+	)
+	s.checkCreateFileCall(c, 4, filename, content, 0644)
+}
+
+func (s *initSystemSuite) TestInstallMultilineGroupOnly(c *gc.C) {
+	scriptPath := fmt.Sprintf("%s/init/%s/exec-start.sh", s.dataDir, s.name)
+	cmd := "a\nb\nc"
+	s.service.Service.Conf.ExecStart = scriptPath
+	s.service.Service.Conf.Group = "juju"
+	s.service.Script = []byte(cmd)
+
+	err := s.service.Install()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c,
+		"RunCommand",
+		"MkdirAll",
+		"CreateFile",
+		"Chown",
+		"CreateFile",
+		"LinkUnitFiles",
+		"Reload",
+		"EnableUnitFiles",
+		"Close",
+	)
+	s.stub.CheckCall(c, 3, "Chown", scriptPath, "", "juju")
+	filename := fmt.Sprintf("%s/init/%s/%s.service", s.dataDir, s.name, s.name)
+	content := strings.Replace(
+		s.newConfStrCmd(s.name, scriptPath),
+		"[Service]\n",
+		"[Service]\nGroup=juju\n",
+		1,
+	)
+	s.checkCreateFileCall(c, 4, filename, content, 0644)
+}
+
 func (s *initSystemSuite) TestInstallEmptyConf(c *gc.C) {
 	s.service.Service.Conf = common.Conf{}
 
@@ -810,6 +1140,140 @@ exec 2>&1
 	test.CheckCommands(c, commands)
 }
 
+func (s *initSystemSuite) TestInstallCommandsOwnership(c *gc.C) {
+	name := "jujud-machine-0"
+	s.conf.User = "jujud"
+	s.conf.UMask = "0027"
+	s.conf.WorkingDirectory = "/var/lib/juju"
+	service := s.newService(c)
+	commands, err := service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	test := systemdtesting.WriteConfTest{
+		Service: name,
+		DataDir: s.dataDir,
+		Expected: strings.Replace(
+			s.newConfStr(name),
+			"[Service]\n",
+			"[Service]\nUser=jujud\nUMask=0027\nWorkingDirectory=/var/lib/juju\n",
+			1),
+	}
+	test.CheckCommands(c, commands)
+}
+
+func (s *initSystemSuite) TestInstallCommandsKill(c *gc.C) {
+	name := "jujud-machine-0"
+	s.conf.TimeoutStopSec = 300
+	s.conf.KillMode = "process"
+	s.conf.KillSignal = "SIGINT"
+	service := s.newService(c)
+	commands, err := service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	test := systemdtesting.WriteConfTest{
+		Service: name,
+		DataDir: s.dataDir,
+		Expected: strings.Replace(
+			s.newConfStr(name),
+			"[Service]\n",
+			"[Service]\nTimeoutStopSec=300\nKillMode=process\nKillSignal=SIGINT\n",
+			1),
+	}
+	test.CheckCommands(c, commands)
+}
+
+func (s *initSystemSuite) TestInstallCommandsDependencies(c *gc.C) {
+	name := "jujud-machine-0"
+	s.conf.After = []string{"network-online.target", "juju-db"}
+	s.conf.Requires = []string{"juju-db"}
+	s.conf.Wants = []string{"network-online.target"}
+	s.conf.WantedBy = "cloud-init.target"
+	service := s.newService(c)
+	commands, err := service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := strings.Replace(
+		s.newConfStr(name),
+		"After=systemd-user-sessions.service\n",
+		"After=systemd-user-sessions.service\n"+
+			"After=network-online.target\n"+
+			"After=juju-db.service\n"+
+			"Requires=juju-db.service\n"+
+			"Wants=network-online.target\n",
+		1)
+	expected = strings.Replace(expected, "WantedBy=multi-user.target", "WantedBy=cloud-init.target", 1)
+	test := systemdtesting.WriteConfTest{
+		Service:  name,
+		DataDir:  s.dataDir,
+		Expected: expected,
+	}
+	test.CheckCommands(c, commands)
+}
+
+func (s *initSystemSuite) TestExistsDependencies(c *gc.C) {
+	s.conf.After = []string{"juju-db"}
+	s.conf.Requires = []string{"juju-db"}
+	s.conf.WantedBy = "multi-user.target"
+	s.service = s.newService(c)
+	s.setConf(c, s.service.Service.Conf)
+
+	exists, err := s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(exists, jc.IsTrue)
+	c.Check(s.service.Service.Conf.After, jc.DeepEquals, []string{"juju-db.service"})
+	c.Check(s.service.Service.Conf.WantedBy, gc.Equals, "")
+}
+
+func (s *initSystemSuite) TestInstallCommandsHooks(c *gc.C) {
+	name := "jujud-machine-0"
+	s.conf.ExecStartPre = []string{"/bin/mkdir -p /var/run/juju", "/bin/true"}
+	s.conf.ExecStartPost = []string{"/usr/bin/logger started"}
+	s.conf.ExecStopPost = []string{"/bin/rm -f /var/run/juju/lock"}
+	service := s.newService(c)
+	commands, err := service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	tag := name[len("jujud-"):]
+	expected := strings.Replace(
+		s.newConfStr(name),
+		"ExecStart="+jujud+" "+tag+"\nRestart=on-failure\n",
+		"ExecStartPre=/bin/mkdir -p /var/run/juju\n"+
+			"ExecStartPre=/bin/true\n"+
+			"ExecStart="+jujud+" "+tag+"\n"+
+			"ExecStartPost=/usr/bin/logger started\n"+
+			"Restart=on-failure\n"+
+			"ExecStopPost=/bin/rm -f /var/run/juju/lock\n",
+		1)
+	test := systemdtesting.WriteConfTest{
+		Service:  name,
+		DataDir:  s.dataDir,
+		Expected: expected,
+	}
+	test.CheckCommands(c, commands)
+}
+
+func (s *initSystemSuite) TestExistsHooks(c *gc.C) {
+	s.conf.ExecStartPre = []string{"/bin/mkdir -p /var/run/juju"}
+	s.conf.ExecStartPost = []string{"/usr/bin/logger started"}
+	s.conf.ExecStopPost = []string{"/bin/rm -f /var/run/juju/lock"}
+	s.service = s.newService(c)
+	s.setConf(c, s.service.Service.Conf)
+
+	exists, err := s.service.Exists()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(exists, jc.IsTrue)
+}
+
+func (s *initSystemSuite) TestNewServiceHooksNotSimple(c *gc.C) {
+	s.conf.ExecStartPre = []string{"/bin/mkdir -p /var/run/juju && /bin/true"}
+
+	_, err := systemd.NewService(s.name, s.conf, s.dataDir)
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
 func (s *initSystemSuite) TestInstallCommandsShutdown(c *gc.C) {
 	name := "juju-shutdown-job"
 	conf, err := service.ShutdownAfterConf("cloud-final")
@@ -856,5 +1320,30 @@ func (s *initSystemSuite) TestStartCommands(c *gc.C) {
 
 	c.Check(commands, jc.DeepEquals, []string{
 		"/bin/systemctl start jujud-machine-0.service",
+		"/bin/systemctl is-active --quiet jujud-machine-0.service",
 	})
 }
+
+func (s *initSystemSuite) TestStartCommandsTransient(c *gc.C) {
+	s.service.Service.Conf.Transient = true
+
+	commands, err := s.service.StartCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(commands, jc.DeepEquals, []string{
+		"/bin/systemctl start jujud-machine-0.service",
+	})
+}
+
+func (s *initSystemSuite) TestRemoveCommands(c *gc.C) {
+	commands, err := s.service.RemoveCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(commands, jc.DeepEquals, []string{
+		"/bin/systemctl stop jujud-machine-0.service || true",
+		"/bin/systemctl disable jujud-machine-0.service || true",
+		"rm -rf '" + s.dataDir + "/init/jujud-machine-0'",
+		"/bin/systemctl daemon-reload",
+	})
+	s.stub.CheckCalls(c, nil)
+}
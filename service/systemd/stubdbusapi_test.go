@@ -4,6 +4,8 @@
 package systemd
 
 import (
+	"path"
+
 	"github.com/coreos/go-systemd/dbus"
 	"github.com/juju/testing"
 )
@@ -14,6 +16,9 @@ type StubDbusAPI struct {
 	Units     []dbus.UnitStatus
 	Props     map[string]interface{}
 	TypeProps map[string]interface{}
+
+	Updates chan<- *dbus.SubStateUpdate
+	Errors  chan<- error
 }
 
 func (fda *StubDbusAPI) AddService(name, desc, status string) {
@@ -71,6 +76,12 @@ func (fda *StubDbusAPI) StopUnit(name string, mode string, ch chan<- string) (in
 	return 0, fda.NextErr()
 }
 
+func (fda *StubDbusAPI) RestartUnit(name string, mode string, ch chan<- string) (int, error) {
+	fda.Stub.AddCall("RestartUnit", name, mode, ch)
+
+	return 0, fda.NextErr()
+}
+
 func (fda *StubDbusAPI) LinkUnitFiles(files []string, runtime bool, force bool) ([]dbus.LinkUnitFileChange, error) {
 	fda.Stub.AddCall("LinkUnitFiles", files, runtime, force)
 
@@ -112,3 +123,47 @@ func (fda *StubDbusAPI) Close() {
 
 	fda.Stub.NextErr() // We don't return the error (just pop it off).
 }
+
+func (fda *StubDbusAPI) Subscribe() error {
+	fda.Stub.AddCall("Subscribe")
+
+	return fda.Stub.NextErr()
+}
+
+func (fda *StubDbusAPI) Unsubscribe() error {
+	fda.Stub.AddCall("Unsubscribe")
+
+	return fda.Stub.NextErr()
+}
+
+func (fda *StubDbusAPI) SetSubStateSubscriber(updates chan<- *dbus.SubStateUpdate, errs chan<- error) {
+	fda.Stub.AddCall("SetSubStateSubscriber")
+	fda.Stub.NextErr() // We don't return the error (just pop it off).
+
+	fda.Updates = updates
+	fda.Errors = errs
+}
+
+// StubPatternDbusAPI is a StubDbusAPI which can also list units by
+// pattern.
+type StubPatternDbusAPI struct {
+	*StubDbusAPI
+}
+
+func (fda *StubPatternDbusAPI) ListUnitsByPatterns(states, patterns []string) ([]dbus.UnitStatus, error) {
+	fda.Stub.AddCall("ListUnitsByPatterns", states, patterns)
+	if err := fda.NextErr(); err != nil {
+		return nil, err
+	}
+
+	var units []dbus.UnitStatus
+	for _, unit := range fda.Units {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, unit.Name); ok {
+				units = append(units, unit)
+				break
+			}
+		}
+	}
+	return units, nil
+}
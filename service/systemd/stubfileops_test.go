@@ -30,3 +30,9 @@ func (sfo *StubFileOps) CreateFile(filename string, data []byte, perm os.FileMod
 
 	return sfo.NextErr()
 }
+
+func (sfo *StubFileOps) Chown(filename, user, group string) error {
+	sfo.AddCall("Chown", filename, user, group)
+
+	return sfo.NextErr()
+}
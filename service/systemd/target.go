@@ -0,0 +1,246 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/unit"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// MachineTarget is the systemd target that groups all of juju's
+// services on a host. Starting or stopping it starts or stops them
+// all, e.g. "systemctl stop juju-machine.target".
+const MachineTarget = "juju-machine.target"
+
+// machineTargetDropIn is the name of the drop-in that ties a service
+// to MachineTarget, so that stopping the target stops the service.
+const machineTargetDropIn = "juju-machine.conf"
+
+// hostRootDir is the root of the host's filesystem. It is patched
+// out during tests.
+var hostRootDir = "/"
+
+func onHost(paths ...string) string {
+	return filepath.Join(append([]string{hostRootDir}, paths...)...)
+}
+
+// machineTargetUnit returns the content of MachineTarget's unit file.
+func machineTargetUnit() []byte {
+	opts := []*unit.UnitOption{
+		{Section: "Unit", Name: "Description", Value: "juju services on this machine"},
+		{Section: "Install", Name: "WantedBy", Value: defaultTarget},
+	}
+	data, _ := ioutil.ReadAll(UnitSerialize(opts))
+	return data
+}
+
+// machineTargetDropInUnit returns the content of the drop-in that
+// makes a service part of MachineTarget.
+func machineTargetDropInUnit() []byte {
+	opts := []*unit.UnitOption{
+		{Section: "Unit", Name: "PartOf", Value: MachineTarget},
+	}
+	data, _ := ioutil.ReadAll(UnitSerialize(opts))
+	return data
+}
+
+// targetPaths returns the wants link and drop-in file (relative to
+// the root) that make the named service part of MachineTarget.
+func targetPaths(name string) (link, dropIn string) {
+	unitName := name + ".service"
+	link = path.Join(unitDir, MachineTarget+".wants", unitName)
+	dropIn = path.Join(unitDir, unitName+".d", machineTargetDropIn)
+	return link, dropIn
+}
+
+// InstallMachineTarget writes and enables MachineTarget, if it is not
+// already installed with the expected content.
+func InstallMachineTarget() error {
+	filename := onHost(unitDir, MachineTarget)
+	data := machineTargetUnit()
+	current, err := ioutil.ReadFile(filename)
+	if err == nil && bytes.Equal(current, data) {
+		return nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	if err := mkdirAll(onHost(unitDir)); err != nil {
+		return errors.Annotatef(err, "failed to write %s", MachineTarget)
+	}
+	if err := createFile(filename, data, 0644); err != nil {
+		return errors.Annotatef(err, "failed to write %s", MachineTarget)
+	}
+
+	conn, err := newConn()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer conn.Close()
+	if err := conn.Reload(); err != nil {
+		return errors.Annotatef(err, "dbus daemon reload for %s failed", MachineTarget)
+	}
+	runtime, force := false, true
+	if _, _, err := conn.EnableUnitFiles([]string{MachineTarget}, runtime, force); err != nil {
+		return errors.Annotatef(err, "dbus enable request for %s failed", MachineTarget)
+	}
+	return nil
+}
+
+// InstallMachineTargetCommands returns the commands that install and
+// enable MachineTarget on a (remote) host.
+func InstallMachineTargetCommands() []string {
+	filename := path.Join(unitDir, MachineTarget)
+	return []string{
+		fmt.Sprintf("cat > %s << 'EOF'\n%sEOF", renderer.Quote(filename), machineTargetUnit()),
+		cmds.reload(),
+		cmds.resolve("enable " + MachineTarget),
+	}
+}
+
+// AddToMachineTarget makes the named service part of MachineTarget:
+// the target wants the service and stopping the target stops it. The
+// target should already be installed.
+func AddToMachineTarget(name string) error {
+	link, dropIn := targetPaths(name)
+	for _, dir := range []string{path.Dir(link), path.Dir(dropIn)} {
+		if err := mkdirAll(onHost(dir)); err != nil {
+			return errors.Annotatef(err, "failed to add service %q to %s", name, MachineTarget)
+		}
+	}
+	if err := createFile(onHost(dropIn), machineTargetDropInUnit(), 0644); err != nil {
+		return errors.Annotatef(err, "failed to add service %q to %s", name, MachineTarget)
+	}
+	if err := removeFile(onHost(link)); err != nil {
+		return errors.Annotatef(err, "failed to add service %q to %s", name, MachineTarget)
+	}
+	if err := symlink(path.Join(unitDir, name+".service"), onHost(link)); err != nil {
+		return errors.Annotatef(err, "failed to add service %q to %s", name, MachineTarget)
+	}
+	return errors.Trace(reloadTarget())
+}
+
+// AddToMachineTargetCommands returns the commands that make the named
+// service part of MachineTarget on a (remote) host.
+func AddToMachineTargetCommands(name string) []string {
+	link, dropIn := targetPaths(name)
+	return []string{
+		fmt.Sprintf("mkdir -p %s %s", renderer.Quote(path.Dir(link)), renderer.Quote(path.Dir(dropIn))),
+		fmt.Sprintf("cat > %s << 'EOF'\n%sEOF", renderer.Quote(dropIn), machineTargetDropInUnit()),
+		fmt.Sprintf("ln -sf %s %s", renderer.Quote(path.Join(unitDir, name+".service")), renderer.Quote(link)),
+		cmds.reload(),
+	}
+}
+
+// RemoveFromMachineTarget undoes AddToMachineTarget. It does nothing
+// if the service is not part of the target. Since the links would be
+// left dangling it should be called before the service is removed.
+func RemoveFromMachineTarget(name string) error {
+	link, dropIn := targetPaths(name)
+	for _, filename := range []string{link, dropIn} {
+		if err := removeFile(onHost(filename)); err != nil {
+			return errors.Annotatef(err, "failed to remove service %q from %s", name, MachineTarget)
+		}
+	}
+	// The drop-in dir is removed only if nothing else is in it.
+	if err := os.Remove(onHost(path.Dir(dropIn))); err != nil && !os.IsNotExist(err) {
+		logger.Debugf("leaving %s: %v", path.Dir(dropIn), err)
+	}
+	return errors.Trace(reloadTarget())
+}
+
+// RemoveFromMachineTargetCommands returns the commands that remove the
+// named service from MachineTarget on a (remote) host.
+func RemoveFromMachineTargetCommands(name string) []string {
+	link, dropIn := targetPaths(name)
+	return []string{
+		fmt.Sprintf("rm -f %s %s", renderer.Quote(link), renderer.Quote(dropIn)),
+		fmt.Sprintf("rmdir %s 2> /dev/null || true", renderer.Quote(path.Dir(dropIn))),
+		cmds.reload(),
+	}
+}
+
+// MachineTargetServices returns the names of the services that are
+// part of MachineTarget.
+func MachineTargetServices() ([]string, error) {
+	link, _ := targetPaths("")
+	infos, err := ioutil.ReadDir(onHost(path.Dir(link)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var names []string
+	for _, info := range infos {
+		if name := info.Name(); strings.HasSuffix(name, ".service") {
+			names = append(names, strings.TrimSuffix(name, ".service"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// StopMachineTarget stops MachineTarget, and so all of juju's services
+// on the host, waiting up to timeout for them to stop.
+func StopMachineTarget(timeout time.Duration) error {
+	conn, err := newConn()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer conn.Close()
+
+	statusCh := newChan()
+	if _, err := conn.StopUnit(MachineTarget, "replace", statusCh); err != nil {
+		return errors.Annotatef(err, "dbus stop request for %s failed", MachineTarget)
+	}
+	var status string
+	select {
+	case status = <-statusCh:
+	case <-jobClock.After(timeout):
+		return errors.Trace(&common.TimeoutError{
+			Service: MachineTarget,
+			Op:      "stop",
+			Timeout: timeout,
+		})
+	}
+	if status != "done" {
+		return errors.Trace(&common.JobFailedError{
+			Service: MachineTarget,
+			Op:      "stop",
+			Result:  status,
+		})
+	}
+	return nil
+}
+
+func reloadTarget() error {
+	conn, err := newConn()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer conn.Close()
+	if err := conn.Reload(); err != nil {
+		return errors.Annotatef(err, "dbus daemon reload for %s failed", MachineTarget)
+	}
+	return nil
+}
+
+// removeFile removes the file, if it exists.
+func removeFile(filename string) error {
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/systemd"
+)
+
+type targetSuite struct {
+	testing.IsolationSuite
+
+	rootDir string
+	stub    *testing.Stub
+	ch      chan string
+}
+
+var _ = gc.Suite(&targetSuite{})
+
+func (s *targetSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+
+	s.rootDir = c.MkDir()
+	systemd.PatchHostRootDir(s, s.rootDir)
+	s.stub = &testing.Stub{}
+	systemd.PatchNewConn(s, s.stub)
+	s.ch = systemd.PatchNewChan(s)
+}
+
+func (s *targetSuite) path(path string) string {
+	return filepath.Join(s.rootDir, path)
+}
+
+func (s *targetSuite) checkFile(c *gc.C, path, expected string) {
+	data, err := ioutil.ReadFile(s.path(path))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, expected)
+}
+
+func (s *targetSuite) TestInstallMachineTarget(c *gc.C) {
+	err := systemd.InstallMachineTarget()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkFile(c, "/etc/systemd/system/juju-machine.target", `
+[Unit]
+Description=juju services on this machine
+
+[Install]
+WantedBy=multi-user.target
+
+`[1:])
+	s.stub.CheckCallNames(c, "Reload", "EnableUnitFiles", "Close")
+	s.stub.CheckCall(c, 1, "EnableUnitFiles", []string{"juju-machine.target"}, false, true)
+}
+
+func (s *targetSuite) TestInstallMachineTargetAlreadyInstalled(c *gc.C) {
+	err := systemd.InstallMachineTarget()
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.ResetCalls()
+
+	err = systemd.InstallMachineTarget()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckNoCalls(c)
+}
+
+func (s *targetSuite) TestAddToMachineTarget(c *gc.C) {
+	err := systemd.AddToMachineTarget("jujud-machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	link, err := os.Readlink(s.path("/etc/systemd/system/juju-machine.target.wants/jujud-machine-0.service"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(link, gc.Equals, "/etc/systemd/system/jujud-machine-0.service")
+	s.checkFile(c, "/etc/systemd/system/jujud-machine-0.service.d/juju-machine.conf", `
+[Unit]
+PartOf=juju-machine.target
+
+`[1:])
+	s.stub.CheckCallNames(c, "Reload", "Close")
+
+	// Adding again is fine.
+	err = systemd.AddToMachineTarget("jujud-machine-0")
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *targetSuite) TestMachineTargetServices(c *gc.C) {
+	names, err := systemd.MachineTargetServices()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(names, gc.HasLen, 0)
+
+	for _, name := range []string{"jujud-unit-mysql-0", "jujud-machine-0"} {
+		err := systemd.AddToMachineTarget(name)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	names, err = systemd.MachineTargetServices()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(names, jc.DeepEquals, []string{"jujud-machine-0", "jujud-unit-mysql-0"})
+}
+
+func (s *targetSuite) TestRemoveFromMachineTarget(c *gc.C) {
+	err := systemd.AddToMachineTarget("jujud-machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.ResetCalls()
+
+	err = systemd.RemoveFromMachineTarget("jujud-machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	for _, path := range []string{
+		"/etc/systemd/system/juju-machine.target.wants/jujud-machine-0.service",
+		"/etc/systemd/system/jujud-machine-0.service.d",
+	} {
+		_, err := os.Lstat(s.path(path))
+		c.Check(os.IsNotExist(err), jc.IsTrue)
+	}
+	s.stub.CheckCallNames(c, "Reload", "Close")
+
+	// Removing again is fine.
+	err = systemd.RemoveFromMachineTarget("jujud-machine-0")
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *targetSuite) TestRemoveFromMachineTargetKeepsDropIns(c *gc.C) {
+	err := systemd.AddToMachineTarget("jujud-machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	other := s.path("/etc/systemd/system/jujud-machine-0.service.d/other.conf")
+	err = ioutil.WriteFile(other, nil, 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = systemd.RemoveFromMachineTarget("jujud-machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = os.Stat(other)
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *targetSuite) TestStopMachineTarget(c *gc.C) {
+	s.ch <- "done"
+
+	err := systemd.StopMachineTarget(time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "StopUnit", "Close")
+	s.stub.CheckCall(c, 0, "StopUnit", "juju-machine.target", "replace", (chan<- string)(s.ch))
+}
+
+func (s *targetSuite) TestStopMachineTargetFailed(c *gc.C) {
+	s.ch <- "failed"
+
+	err := systemd.StopMachineTarget(time.Minute)
+
+	c.Check(err, jc.Satisfies, common.IsJobFailed)
+}
+
+func (s *targetSuite) TestCommands(c *gc.C) {
+	c.Check(systemd.InstallMachineTargetCommands(), jc.DeepEquals, []string{
+		"cat > '/etc/systemd/system/juju-machine.target' << 'EOF'\n" +
+			"[Unit]\nDescription=juju services on this machine\n\n" +
+			"[Install]\nWantedBy=multi-user.target\n\nEOF",
+		"/bin/systemctl daemon-reload",
+		"/bin/systemctl enable juju-machine.target",
+	})
+	c.Check(systemd.AddToMachineTargetCommands("jujud-machine-0"), jc.DeepEquals, []string{
+		"mkdir -p '/etc/systemd/system/juju-machine.target.wants' '/etc/systemd/system/jujud-machine-0.service.d'",
+		"cat > '/etc/systemd/system/jujud-machine-0.service.d/juju-machine.conf' << 'EOF'\n" +
+			"[Unit]\nPartOf=juju-machine.target\n\nEOF",
+		"ln -sf '/etc/systemd/system/jujud-machine-0.service' '/etc/systemd/system/juju-machine.target.wants/jujud-machine-0.service'",
+		"/bin/systemctl daemon-reload",
+	})
+	c.Check(systemd.RemoveFromMachineTargetCommands("jujud-machine-0"), jc.DeepEquals, []string{
+		"rm -f '/etc/systemd/system/juju-machine.target.wants/jujud-machine-0.service' '/etc/systemd/system/jujud-machine-0.service.d/juju-machine.conf'",
+		"rmdir '/etc/systemd/system/jujud-machine-0.service.d' 2> /dev/null || true",
+		"/bin/systemctl daemon-reload",
+	})
+}
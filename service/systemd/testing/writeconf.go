@@ -50,7 +50,14 @@ func (wct WriteConfTest) CheckCommands(c *gc.C, commands []string) {
 	wct.checkWriteConf(c, commands)
 }
 
+// CheckInstallAndStartCommands checks the given install commands,
+// followed by the start commands, against the test's expectations.
 func (wct WriteConfTest) CheckInstallAndStartCommands(c *gc.C, commands []string) {
+	// Only non-transient services are checked for having come up.
+	verify := "/bin/systemctl is-active --quiet " + wct.servicename()
+	if commands[len(commands)-1] == verify {
+		commands = commands[:len(commands)-1]
+	}
 	wct.CheckCommands(c, commands[:len(commands)-1])
 	c.Check(commands[len(commands)-1], gc.Equals, "/bin/systemctl start "+wct.servicename())
 }
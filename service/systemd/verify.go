@@ -0,0 +1,83 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// analyzeExecutable is the systemd tool used to check unit files.
+const analyzeExecutable = "systemd-analyze"
+
+var lookPath = exec.LookPath
+
+var runAnalyze = func(filename string) ([]byte, error) {
+	return exec.Command(analyzeExecutable, "verify", filename).CombinedOutput()
+}
+
+// Verify checks that the conf describes a unit which systemd accepts,
+// without installing anything. On top of juju's own validation, the
+// unit (and its ExtraScript, if any) is written to a scratch directory
+// and checked with "systemd-analyze verify", which catches problems
+// like a missing executable or an unknown directive. That check is
+// skipped if systemd-analyze is not available.
+func Verify(name string, conf common.Conf) error {
+	dataDir, err := ioutil.TempDir("", "juju-systemd-verify")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	svc, err := NewService(name, conf, dataDir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data, err := svc.serialize()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if _, err := lookPath(analyzeExecutable); err != nil {
+		logger.Debugf("%s not found, not verifying unit for service %q", analyzeExecutable, name)
+		return nil
+	}
+
+	// The files are written directly (rather than with writeConf) so
+	// that nothing is chowned.
+	if err := os.MkdirAll(svc.Dirname, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	if svc.Script != nil {
+		scriptPath := renderer.ScriptFilename("exec-start", svc.Dirname)
+		if err := ioutil.WriteFile(scriptPath, svc.Script, 0755); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	filename := path.Join(svc.Dirname, svc.ConfName)
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return errors.Trace(err)
+	}
+
+	out, err := runAnalyze(filename)
+	out = bytes.TrimSpace(out)
+	if _, ok := err.(*exec.ExitError); ok {
+		return errors.NotValidf("unit for service %q (%s)", name, out)
+	} else if err != nil {
+		return errors.Annotatef(err, "failed to run %s", analyzeExecutable)
+	}
+	if len(out) > 0 {
+		// Older versions of systemd-analyze exit successfully even
+		// when they find problems.
+		logger.Warningf("%s verify for service %q: %s", analyzeExecutable, name, out)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	jujuerrors "github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/service/systemd"
+)
+
+type verifySuite struct {
+	testing.IsolationSuite
+
+	stub *testing.Stub
+	conf common.Conf
+}
+
+var _ = gc.Suite(&verifySuite{})
+
+func (s *verifySuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+
+	s.stub = &testing.Stub{}
+	s.conf = common.Conf{
+		Desc:      "juju agent for machine-0",
+		ExecStart: jujud + " machine-0",
+	}
+}
+
+func (s *verifySuite) TestVerify(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "")
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCalls(c, []testing.StubCall{{
+		FuncName: "LookPath",
+		Args:     []interface{}{"systemd-analyze"},
+	}, {
+		FuncName: "RunAnalyze",
+		Args: []interface{}{
+			"jujud-machine-0.service",
+			fmt.Sprintf(confStr[1:], "machine-0", jujud+" machine-0"),
+		},
+	}})
+}
+
+func (s *verifySuite) TestVerifyWarnings(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "Unknown lvalue 'Foo' in section 'Service'")
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+
+	c.Check(err, jc.ErrorIsNil)
+	s.stub.CheckCallNames(c, "LookPath", "RunAnalyze")
+}
+
+func (s *verifySuite) TestVerifyRejected(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "Command /var/lib/juju/bin/jujud is not executable: No such file or directory")
+	s.stub.SetErrors(nil, &exec.ExitError{})
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+
+	c.Check(err, jc.Satisfies, jujuerrors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `unit for service "jujud-machine-0" \(Command .* is not executable: .*\) not valid`)
+}
+
+func (s *verifySuite) TestVerifyAnalyzeFailed(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "")
+	s.stub.SetErrors(nil, errors.New("<failed>"))
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+
+	c.Check(err, gc.ErrorMatches, `failed to run systemd-analyze: <failed>`)
+}
+
+func (s *verifySuite) TestVerifyNoAnalyze(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "")
+	s.stub.SetErrors(errors.New("not found"))
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+
+	c.Check(err, jc.ErrorIsNil)
+	s.stub.CheckCallNames(c, "LookPath")
+}
+
+func (s *verifySuite) TestVerifyInvalidConf(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "")
+	s.conf.ExecStart = "jujud machine-0"
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+
+	c.Check(err, jc.Satisfies, common.IsConfInvalid)
+	s.stub.CheckNoCalls(c)
+}
+
+func (s *verifySuite) TestVerifyExtraScript(c *gc.C) {
+	systemd.PatchAnalyze(s, s.stub, "")
+	s.conf.ExtraScript = "echo hello"
+
+	err := systemd.Verify("jujud-machine-0", s.conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "LookPath", "RunAnalyze")
+	unit := s.stub.Calls()[1].Args[1].(string)
+	c.Check(unit, gc.Matches, `(?s).*ExecStart=/tmp/.*/init/jujud-machine-0/exec-start.sh\n.*`)
+}
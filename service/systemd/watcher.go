@@ -0,0 +1,145 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd
+
+import (
+	"sync"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// watchBufferSize is the size of the buffer that holds dbus updates
+// until a Watcher gets to them. systemd signals every unit's updates
+// on the connection, so the buffer needs room for bursts of changes.
+const watchBufferSize = 100
+
+// Watch implements service.WatchableService. It subscribes to the
+// unit's property changes over dbus and delivers each transition of
+// the unit's active state (e.g. "active" to "failed").
+func (s *Service) Watch() (common.Watcher, error) {
+	if s.RootDir != "" {
+		return nil, s.notSupportedInRoot("watching")
+	}
+	conn, err := s.newConn()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := conn.Subscribe(); err != nil {
+		conn.Close()
+		return nil, s.errorf(err, "dbus subscribe request failed")
+	}
+	updates := make(chan *dbus.SubStateUpdate, watchBufferSize)
+	errs := make(chan error, 1)
+	conn.SetSubStateSubscriber(updates, errs)
+
+	w := &watcher{
+		service: s.Service.Name,
+		unit:    s.UnitName,
+		conn:    conn,
+		changes: make(chan common.StateChange),
+		dying:   make(chan struct{}),
+		dead:    make(chan struct{}),
+	}
+	go func() {
+		defer close(w.dead)
+		defer conn.Close()
+		w.err = w.loop(updates, errs)
+	}()
+	return w, nil
+}
+
+// watcher implements common.Watcher for a systemd unit.
+type watcher struct {
+	service string
+	unit    string
+	conn    dbusAPI
+	changes chan common.StateChange
+
+	stopOnce sync.Once
+	dying    chan struct{}
+	dead     chan struct{}
+	err      error
+}
+
+// Changes implements common.Watcher.
+func (w *watcher) Changes() <-chan common.StateChange {
+	return w.changes
+}
+
+// Stop implements common.Watcher.
+func (w *watcher) Stop() error {
+	w.stopOnce.Do(func() { close(w.dying) })
+	<-w.dead
+	return w.err
+}
+
+// Err implements common.Watcher.
+func (w *watcher) Err() error {
+	select {
+	case <-w.dead:
+		return w.err
+	default:
+		return nil
+	}
+}
+
+func (w *watcher) loop(updates <-chan *dbus.SubStateUpdate, errs <-chan error) error {
+	defer close(w.changes)
+	defer w.conn.Unsubscribe()
+
+	var state, subState string
+	check := func() (bool, error) {
+		props, err := w.conn.GetUnitProperties(w.unit)
+		if err != nil {
+			return false, errors.Annotatef(err, "failed to read state of service %q", w.service)
+		}
+		active, _ := props["ActiveState"].(string)
+		if active == state {
+			return false, nil
+		}
+		change := common.StateChange{
+			Service:  w.service,
+			From:     state,
+			To:       active,
+			SubState: subState,
+		}
+		if change.SubState == "" {
+			change.SubState, _ = props["SubState"].(string)
+		}
+		state = active
+		select {
+		case w.changes <- change:
+			return false, nil
+		case <-w.dying:
+			return true, nil
+		}
+	}
+
+	if stopped, err := check(); stopped || err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		subState = ""
+		select {
+		case <-w.dying:
+			return nil
+		case update := <-updates:
+			if update.UnitName != w.unit {
+				continue
+			}
+			subState = update.SubState
+		case err := <-errs:
+			// go-systemd reports an error if it had to drop an update
+			// because the buffer was full, so the state is checked
+			// directly in case the update was for this unit.
+			logger.Warningf("watching service %q: %v", w.service, err)
+		}
+		if stopped, err := check(); stopped || err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
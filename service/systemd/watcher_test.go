@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd_test
+
+import (
+	"time"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service/common"
+	coretesting "github.com/juju/juju/testing"
+)
+
+func (s *initSystemSuite) setState(active, sub string) {
+	s.conn.SetProperty("", "ActiveState", active)
+	s.conn.SetProperty("", "SubState", sub)
+}
+
+func (s *initSystemSuite) assertChange(c *gc.C, w common.Watcher, expected common.StateChange) {
+	select {
+	case change, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+		c.Check(change, jc.DeepEquals, expected)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for change")
+	}
+}
+
+func (s *initSystemSuite) assertNoChange(c *gc.C, w common.Watcher) {
+	select {
+	case change := <-w.Changes():
+		c.Fatalf("unexpected change %#v", change)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *initSystemSuite) assertClosed(c *gc.C, w common.Watcher) {
+	select {
+	case _, ok := <-w.Changes():
+		c.Check(ok, jc.IsFalse)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for changes to close")
+	}
+}
+
+func (s *initSystemSuite) TestWatch(c *gc.C) {
+	s.setState("active", "running")
+
+	w, err := s.service.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertChange(c, w, common.StateChange{
+		Service:  s.name,
+		To:       "active",
+		SubState: "running",
+	})
+
+	// Updates for other units and for the unit's sub-state alone
+	// are not reported.
+	s.conn.Updates <- &dbus.SubStateUpdate{UnitName: "other.service", SubState: "dead"}
+	s.conn.Updates <- &dbus.SubStateUpdate{UnitName: s.name + ".service", SubState: "running"}
+	s.assertNoChange(c, w)
+
+	s.setState("failed", "failed")
+	s.conn.Updates <- &dbus.SubStateUpdate{UnitName: s.name + ".service", SubState: "failed"}
+	s.assertChange(c, w, common.StateChange{
+		Service:  s.name,
+		From:     "active",
+		To:       "failed",
+		SubState: "failed",
+	})
+
+	c.Check(w.Err(), jc.ErrorIsNil)
+	err = w.Stop()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertClosed(c, w)
+
+	s.stub.CheckCallNames(c,
+		"Subscribe",
+		"SetSubStateSubscriber",
+		"GetUnitProperties",
+		"GetUnitProperties",
+		"GetUnitProperties",
+		"Unsubscribe",
+		"Close",
+	)
+	s.stub.CheckCall(c, 2, "GetUnitProperties", s.name+".service")
+}
+
+func (s *initSystemSuite) TestWatchDroppedUpdate(c *gc.C) {
+	s.setState("active", "running")
+
+	w, err := s.service.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	defer w.Stop()
+	s.assertChange(c, w, common.StateChange{
+		Service:  s.name,
+		To:       "active",
+		SubState: "running",
+	})
+
+	s.setState("activating", "auto-restart")
+	s.conn.Errors <- errors.New("update channel full")
+	s.assertChange(c, w, common.StateChange{
+		Service:  s.name,
+		From:     "active",
+		To:       "activating",
+		SubState: "auto-restart",
+	})
+}
+
+func (s *initSystemSuite) TestWatchSubscribeFailed(c *gc.C) {
+	failure := errors.New("<failed>")
+	s.stub.SetErrors(failure)
+
+	_, err := s.service.Watch()
+
+	c.Check(errors.Cause(err), gc.Equals, failure)
+	s.stub.CheckCallNames(c, "Subscribe", "Close")
+}
+
+func (s *initSystemSuite) TestWatchPropertiesFailed(c *gc.C) {
+	failure := errors.New("<failed>")
+	s.setState("active", "running")
+	s.stub.SetErrors(nil, nil, nil, failure)
+
+	w, err := s.service.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertChange(c, w, common.StateChange{
+		Service:  s.name,
+		To:       "active",
+		SubState: "running",
+	})
+
+	s.conn.Updates <- &dbus.SubStateUpdate{UnitName: s.name + ".service", SubState: "failed"}
+	s.assertClosed(c, w)
+
+	err = w.Stop()
+	c.Check(errors.Cause(err), gc.Equals, failure)
+	c.Check(w.Err(), gc.ErrorMatches, `failed to read state of service "jujud-machine-0": .*`)
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/systemd"
+)
+
+// MachineTarget is the name of the target that groups all of juju's
+// services on a systemd host, so that they may be stopped and started
+// together (e.g. "systemctl stop juju-machine.target").
+const MachineTarget = systemd.MachineTarget
+
+// localInitSystem is patched out during tests.
+var localInitSystem = discoverInitSystem
+
+// checkMachineTarget returns an error if the local init system does
+// not support MachineTarget. Only systemd has targets.
+func checkMachineTarget() error {
+	initName, err := localInitSystem()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if initName != InitSystemSystemd {
+		return errors.NotSupportedf("%s with init system %q", MachineTarget, initName)
+	}
+	return nil
+}
+
+// InstallMachineTarget installs and enables MachineTarget on the
+// local host.
+func InstallMachineTarget() error {
+	if err := checkMachineTarget(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(systemd.InstallMachineTarget())
+}
+
+// AddToMachineTarget makes the named service on the local host part
+// of MachineTarget.
+func AddToMachineTarget(name string) error {
+	if err := checkMachineTarget(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(systemd.AddToMachineTarget(name))
+}
+
+// RemoveFromMachineTarget removes the named service on the local host
+// from MachineTarget. It should be called before the service itself
+// is removed.
+func RemoveFromMachineTarget(name string) error {
+	if err := checkMachineTarget(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(systemd.RemoveFromMachineTarget(name))
+}
+
+// MachineTargetServices returns the names of the services on the local
+// host that are part of MachineTarget.
+func MachineTargetServices() ([]string, error) {
+	if err := checkMachineTarget(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	names, err := systemd.MachineTargetServices()
+	return names, errors.Trace(err)
+}
+
+// StopMachineTarget stops all of juju's services on the local host by
+// stopping MachineTarget, waiting up to timeout for them to stop.
+func StopMachineTarget(timeout time.Duration) error {
+	if err := checkMachineTarget(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(systemd.StopMachineTarget(timeout))
+}
@@ -0,0 +1,37 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/service"
+)
+
+type targetSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&targetSuite{})
+
+func (s *targetSuite) TestNotSupported(c *gc.C) {
+	service.PatchLocalInitSystem(s, service.InitSystemUpstart)
+
+	err := service.InstallMachineTarget()
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	c.Check(err, gc.ErrorMatches, `juju-machine.target with init system "upstart" not supported`)
+	err = service.AddToMachineTarget("jujud-machine-0")
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	err = service.RemoveFromMachineTarget("jujud-machine-0")
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	_, err = service.MachineTargetServices()
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	err = service.StopMachineTarget(time.Minute)
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
@@ -22,8 +22,9 @@ import (
 type Stub struct {
 	*testing.Stub
 
-	Version version.Binary
-	Service Service
+	Version  version.Binary
+	Service  Service
+	Services map[string]Service
 }
 
 // GetVersion stubs out .
@@ -42,6 +43,14 @@ func (s *Stub) DiscoverService(name string) (Service, error) {
 	return s.Service, s.NextErr()
 }
 
+// NewLocalService stubs out service.newLocalService. It returns the
+// service in Services for the init system.
+func (s *Stub) NewLocalService(name string, conf common.Conf, initSystem string) (Service, error) {
+	s.AddCall("NewLocalService", name, conf, initSystem)
+
+	return s.Services[initSystem], s.NextErr()
+}
+
 // TODO(ericsnow) StubFileInfo belongs in utils/fs.
 
 // StubFileInfo implements os.FileInfo.
@@ -100,6 +109,13 @@ func (s *BaseSuite) PatchSeries(ser string) {
 	s.PatchValue(&series.HostSeries, func() string { return ser })
 }
 
+// PatchLocalServices makes MigrateService use the provided services,
+// keyed by init system.
+func (s *BaseSuite) PatchLocalServices(services map[string]Service) {
+	s.Patched.Services = services
+	s.PatchValue(&newLocalService, s.Patched.NewLocalService)
+}
+
 func NewDiscoveryCheck(name string, running bool, failure error) discoveryCheck {
 	return discoveryCheck{
 		name: name,
@@ -123,6 +139,7 @@ func (s *BaseSuite) PatchLocalDiscoveryNoMatch(expected string) {
 		InitSystemUpstart,
 		InitSystemSystemd,
 		InitSystemWindows,
+		InitSystemOpenRC,
 	}
 	var checks []discoveryCheck
 	for _, name := range names {
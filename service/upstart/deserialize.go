@@ -0,0 +1,234 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upstart
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/service/common"
+)
+
+// Deserialize parses an upstart job, as rendered by Serialize, and
+// populates a new Conf with the result. Stanzas juju does not write
+// are not supported.
+//
+// Upstart has no ordering without a dependency, so every job in the
+// "start on" expression is reported in After, including those that
+// are also in Requires.
+func Deserialize(data []byte) (common.Conf, error) {
+	var conf common.Conf
+	var block string
+	var blockLines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if block != "" {
+			if line != "end script" {
+				blockLines = append(blockLines, line)
+				continue
+			}
+			if err := setScript(&conf, block, blockLines); err != nil {
+				return conf, errors.Trace(err)
+			}
+			block, blockLines = "", nil
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "script" || strings.HasSuffix(line, " script") {
+			block = line
+			continue
+		}
+		if err := setStanza(&conf, line); err != nil {
+			return conf, errors.Trace(err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return conf, errors.Trace(err)
+	}
+	if block != "" {
+		return conf, errors.NotValidf("unterminated %q block", block)
+	}
+	return conf, nil
+}
+
+// setStanza updates the conf with the value of a single-line stanza.
+func setStanza(conf *common.Conf, line string) error {
+	var keyword, value string
+	switch {
+	case strings.HasPrefix(line, "start on "), strings.HasPrefix(line, "stop on "):
+		parts := strings.SplitN(line, " on ", 2)
+		keyword, value = parts[0]+" on", parts[1]
+	case strings.HasPrefix(line, "kill timeout "), strings.HasPrefix(line, "kill signal "):
+		parts := strings.SplitN(line, " ", 3)
+		keyword, value = parts[0]+" "+parts[1], parts[2]
+	default:
+		parts := strings.SplitN(line, " ", 2)
+		keyword = parts[0]
+		if len(parts) > 1 {
+			value = parts[1]
+		}
+	}
+
+	switch keyword {
+	case "author", "respawn", "normal":
+		// These are the same for every juju job.
+	case "description":
+		desc, err := common.UnquoteUpstart(value)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		conf.Desc = desc
+	case "start on":
+		if strings.HasPrefix(value, "stopped ") {
+			conf.Transient = true
+			conf.AfterStopped = strings.TrimPrefix(value, "stopped ")
+			break
+		}
+		conf.After = eventJobs(value, " and ", "started ")
+	case "stop on":
+		conf.Requires = eventJobs(value, " or ", "stopping ")
+	case "setuid":
+		conf.User = value
+	case "setgid":
+		conf.Group = value
+	case "umask":
+		conf.UMask = value
+	case "chdir":
+		conf.WorkingDirectory = value
+	case "kill timeout":
+		timeout, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.NotValidf("kill timeout %q", value)
+		}
+		conf.TimeoutStopSec = timeout
+	case "kill signal":
+		conf.KillSignal = value
+	case "env":
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return errors.NotValidf("env stanza %q", value)
+		}
+		envValue, err := common.UnquoteUpstart(parts[1])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if conf.Env == nil {
+			conf.Env = make(map[string]string)
+		}
+		conf.Env[parts[0]] = envValue
+	case "limit":
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return errors.NotValidf("limit stanza %q", value)
+		}
+		limit, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return errors.NotValidf("limit value %q", fields[1])
+		}
+		if conf.Limit == nil {
+			conf.Limit = make(map[string]int)
+		}
+		conf.Limit[fields[0]] = limit
+	default:
+		return errors.NotSupportedf("stanza %q", keyword)
+	}
+	return nil
+}
+
+// eventJobs returns the names of the jobs named in the provided event
+// expression, which joins events of the given kind with sep.
+func eventJobs(expr, sep, kind string) []string {
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "("), ")")
+	var names []string
+	for _, event := range strings.Split(expr, sep) {
+		if strings.HasPrefix(event, kind) {
+			names = append(names, strings.TrimPrefix(event, kind))
+		}
+	}
+	return names
+}
+
+// setScript updates the conf with the content of a script block.
+func setScript(conf *common.Conf, block string, lines []string) error {
+	switch block {
+	case "script":
+		if conf.Transient {
+			conf.ExecStart = strings.TrimSpace(strings.Join(lines, "\n"))
+			return nil
+		}
+		setMainScript(conf, lines)
+	case "pre-start script":
+		conf.ExecStartPre = scriptCommands(lines)
+	case "post-start script":
+		conf.ExecStartPost = scriptCommands(lines)
+	case "post-stop script":
+		conf.ExecStopPost = scriptCommands(lines)
+	default:
+		return errors.NotSupportedf("%q block", block)
+	}
+	return nil
+}
+
+// setMainScript splits the job's main script back into ExtraScript,
+// Logfile and ExecStart.
+func setMainScript(conf *common.Conf, lines []string) {
+	execIndex := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  exec ") {
+			execIndex = i
+		}
+	}
+	if execIndex < 0 {
+		conf.ExtraScript = strings.Trim(strings.Join(lines, "\n"), "\n")
+		return
+	}
+
+	execStart := strings.TrimPrefix(lines[execIndex], "  exec ")
+	if strings.HasSuffix(execStart, " 2>&1") {
+		if i := strings.LastIndex(execStart, " >> "); i >= 0 {
+			conf.Logfile = strings.TrimSuffix(execStart[i+len(" >> "):], " 2>&1")
+			execStart = execStart[:i]
+		}
+	}
+	conf.ExecStart = execStart
+
+	logLines := map[string]bool{}
+	if conf.Logfile != "" {
+		logLines = map[string]bool{
+			"  # Ensure log files are properly protected": true,
+			"  touch " + conf.Logfile:                     true,
+			"  chown syslog:syslog " + conf.Logfile:       true,
+			"  chmod 0600 " + conf.Logfile:                true,
+		}
+	}
+	var extra []string
+	for _, line := range lines[:execIndex] {
+		if !logLines[line] {
+			extra = append(extra, line)
+		}
+	}
+	conf.ExtraScript = strings.Trim(strings.Join(extra, "\n"), "\n")
+}
+
+// scriptCommands returns the commands in a hook's script block.
+func scriptCommands(lines []string) []string {
+	var cmds []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			cmds = append(cmds, line)
+		}
+	}
+	return cmds
+}
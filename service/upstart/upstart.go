@@ -12,6 +12,7 @@ import (
 	"path"
 	"regexp"
 	"runtime"
+	"strings"
 	"text/template"
 
 	"github.com/juju/errors"
@@ -82,12 +83,31 @@ func ListServices() ([]string, error) {
 	return services, nil
 }
 
+// ListServicesMatching returns the names of the installed services
+// on the local host that start with the provided prefix.
+func ListServicesMatching(prefix string) ([]string, error) {
+	services, err := ListServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return common.FilterPrefix(services, prefix), nil
+}
+
 // ListCommand returns a command that will list the services on a host.
 func ListCommand() string {
 	// TODO(ericsnow) Do "ls /etc/init/*.conf" instead?
 	return `sudo initctl list | awk '{print $1}' | sort | uniq`
 }
 
+// ListCommandMatching returns a command that will list the services
+// on a host that start with the provided prefix.
+func ListCommandMatching(prefix string) string {
+	if prefix == "" {
+		return ListCommand()
+	}
+	return fmt.Sprintf(`sudo initctl list | awk 'index($1, "%s") == 1 {print $1}' | sort | uniq`, prefix)
+}
+
 var startedRE = regexp.MustCompile(`^.* start/running(?:, process (\d+))?\n$`)
 
 // Service provides visibility into and control over an upstart service.
@@ -125,25 +145,42 @@ func (s *Service) Validate() error {
 		return errors.Trace(err)
 	}
 
+	if s.Service.Conf.KillMode != "" {
+		return common.ConfNotSupportedf("KillMode", "Conf.KillMode not supported")
+	}
+
+	if s.Service.Conf.WantedBy != "" {
+		return common.ConfNotSupportedf("WantedBy", "Conf.WantedBy not supported")
+	}
+
+	for name, value := range s.Service.Conf.Env {
+		if err := common.CheckUpstartValue(value); err != nil {
+			return common.ConfInvalidf("Env", "Env value for %q (control characters) not valid", name)
+		}
+	}
+
 	if s.Service.Conf.Transient {
 		if len(s.Service.Conf.Env) > 0 {
-			return errors.NotSupportedf("Conf.Env (when transient)")
+			return common.ConfNotSupportedf("Env", "Conf.Env (when transient) not supported")
 		}
 		if len(s.Service.Conf.Limit) > 0 {
-			return errors.NotSupportedf("Conf.Limit (when transient)")
+			return common.ConfNotSupportedf("Limit", "Conf.Limit (when transient) not supported")
 		}
 		if s.Service.Conf.Logfile != "" {
-			return errors.NotSupportedf("Conf.Logfile (when transient)")
+			return common.ConfNotSupportedf("Logfile", "Conf.Logfile (when transient) not supported")
 		}
 		if s.Service.Conf.ExtraScript != "" {
-			return errors.NotSupportedf("Conf.ExtraScript (when transient)")
+			return common.ConfNotSupportedf("ExtraScript", "Conf.ExtraScript (when transient) not supported")
+		}
+		if len(s.Service.Conf.ExecStartPre) > 0 {
+			return common.ConfNotSupportedf("ExecStartPre", "Conf.ExecStartPre (when transient) not supported")
+		}
+		if len(s.Service.Conf.ExecStartPost) > 0 {
+			return common.ConfNotSupportedf("ExecStartPost", "Conf.ExecStartPost (when transient) not supported")
 		}
 	} else {
 		if s.Service.Conf.AfterStopped != "" {
-			return errors.NotSupportedf("Conf.AfterStopped (when not transient)")
-		}
-		if s.Service.Conf.ExecStopPost != "" {
-			return errors.NotSupportedf("Conf.ExecStopPost (when not transient)")
+			return common.ConfNotSupportedf("AfterStopped", "Conf.AfterStopped (when not transient) not supported")
 		}
 	}
 
@@ -157,7 +194,9 @@ func (s *Service) render() ([]byte, error) {
 	}
 	conf := s.Conf()
 	if conf.Transient {
-		conf.ExecStopPost = "rm " + s.confPath()
+		// Copy the hooks so the service's own conf is left untouched.
+		hooks := append([]string(nil), conf.ExecStopPost...)
+		conf.ExecStopPost = append(hooks, "rm "+s.confPath())
 	}
 	return Serialize(s.Name(), conf)
 }
@@ -204,6 +243,32 @@ func (s *Service) existsAndSame() (exists, same bool, conf []byte, err error) {
 	return true, bytes.Equal(current, expected), expected, nil
 }
 
+// ReadConf returns the conf of the installed service, as parsed from
+// its job file.
+func (s *Service) ReadConf() (common.Conf, error) {
+	data, err := ioutil.ReadFile(s.confPath())
+	if os.IsNotExist(err) {
+		return common.Conf{}, common.NewNotInstalledError(s.Service.Name)
+	} else if err != nil {
+		return common.Conf{}, errors.Trace(err)
+	}
+	conf, err := Deserialize(data)
+	if err != nil {
+		return common.Conf{}, errors.Annotatef(err, "failed to parse %q", s.confPath())
+	}
+	if conf.Transient {
+		// Drop the clean-up command added by render.
+		hooks := conf.ExecStopPost
+		if n := len(hooks); n > 0 && hooks[n-1] == "rm "+s.confPath() {
+			conf.ExecStopPost = hooks[:n-1]
+		}
+		if len(conf.ExecStopPost) == 0 {
+			conf.ExecStopPost = nil
+		}
+	}
+	return conf, nil
+}
+
 // Running returns true if the Service appears to be running.
 func (s *Service) Running() (bool, error) {
 	cmd := exec.Command("status", "--system", s.Service.Name)
@@ -322,6 +387,14 @@ func (s *Service) StartCommands() ([]string, error) {
 	return []string{"start " + s.Service.Name}, nil
 }
 
+// RemoveCommands returns shell commands to stop and remove the service.
+func (s *Service) RemoveCommands() ([]string, error) {
+	return []string{
+		"stop " + s.Service.Name + " || true",
+		"rm -f " + s.confPath(),
+	}, nil
+}
+
 // Serialize renders the conf as raw bytes.
 func Serialize(name string, conf common.Conf) ([]byte, error) {
 	var buf bytes.Buffer
@@ -330,25 +403,98 @@ func Serialize(name string, conf common.Conf) ([]byte, error) {
 			return nil, err
 		}
 	} else {
-		if err := confT.Execute(&buf, conf); err != nil {
+		data := confData{
+			Conf:    conf,
+			StartOn: startOn(conf),
+			StopOn:  stopOn(conf),
+		}
+		if err := confT.Execute(&buf, data); err != nil {
 			return nil, err
 		}
 	}
 	return buf.Bytes(), nil
 }
 
+// confData holds the values used to render confT.
+type confData struct {
+	common.Conf
+
+	// StartOn is the job's "start on" event expression.
+	StartOn string
+
+	// StopOn is the job's "stop on" event expression.
+	StopOn string
+}
+
+// jobNames returns the upstart job names for the provided dependencies.
+// Upstart has no equivalent of systemd's targets, so those are dropped.
+func jobNames(deps ...[]string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		for _, name := range dep {
+			if strings.HasSuffix(name, ".target") {
+				logger.Debugf("ignoring dependency on target %q", name)
+				continue
+			}
+			name = strings.TrimSuffix(name, ".service")
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// startOn emulates ordering after (and requiring) other services by
+// waiting for each of them to have started.
+func startOn(conf common.Conf) string {
+	events := []string{"runlevel [2345]"}
+	for _, name := range jobNames(conf.After, conf.Requires) {
+		events = append(events, "started "+name)
+	}
+	if len(events) == 1 {
+		return events[0]
+	}
+	return "(" + strings.Join(events, " and ") + ")"
+}
+
+// stopOn emulates a hard dependency by stopping the service along with
+// any of the services it requires.
+func stopOn(conf common.Conf) string {
+	events := []string{"runlevel [!2345]"}
+	for _, name := range jobNames(conf.Requires) {
+		events = append(events, "stopping "+name)
+	}
+	if len(events) == 1 {
+		return events[0]
+	}
+	return "(" + strings.Join(events, " or ") + ")"
+}
+
 // TODO(ericsnow) Use a different solution than templates?
 
-// BUG: %q quoting does not necessarily match libnih quoting rules
-// (as used by upstart); this may become an issue in the future.
-var confT = template.Must(template.New("").Parse(`
-description "{{.Desc}}"
+// templateFuncs holds the functions used by the conf templates. Values
+// are quoted following libnih's rules (as used by upstart).
+var templateFuncs = template.FuncMap{
+	"quote": common.QuoteUpstart,
+}
+
+var confT = template.Must(template.New("").Funcs(templateFuncs).Parse(`
+description {{quote .Desc}}
 author "Juju Team <juju@lists.ubuntu.com>"
-start on runlevel [2345]
-stop on runlevel [!2345]
+start on {{.StartOn}}
+stop on {{.StopOn}}
 respawn
 normal exit 0
-{{range $k, $v := .Env}}env {{$k}}={{$v|printf "%q"}}
+{{if .User}}setuid {{.User}}
+{{end}}{{if .Group}}setgid {{.Group}}
+{{end}}{{if .UMask}}umask {{.UMask}}
+{{end}}{{if .WorkingDirectory}}chdir {{.WorkingDirectory}}
+{{end}}{{if gt .TimeoutStopSec 0}}kill timeout {{.TimeoutStopSec}}
+{{end}}{{if .KillSignal}}kill signal {{.KillSignal}}
+{{end}}{{range $k, $v := .Env}}env {{$k}}={{quote $v}}
 {{end}}
 {{range $k, $v := .Limit}}limit {{$k}} {{$v}} {{$v}}
 {{end}}
@@ -362,10 +508,22 @@ script
 {{end}}
   exec {{.ExecStart}}{{if .Logfile}} >> {{.Logfile}} 2>&1{{end}}
 end script
-`[1:]))
+{{if .ExecStartPre}}
+pre-start script
+{{range .ExecStartPre}}  {{.}}
+{{end}}end script
+{{end}}{{if .ExecStartPost}}
+post-start script
+{{range .ExecStartPost}}  {{.}}
+{{end}}end script
+{{end}}{{if .ExecStopPost}}
+post-stop script
+{{range .ExecStopPost}}  {{.}}
+{{end}}end script
+{{end}}`[1:]))
 
-var transientConfT = template.Must(template.New("").Parse(`
-description "{{.Desc}}"
+var transientConfT = template.Must(template.New("").Funcs(templateFuncs).Parse(`
+description {{quote .Desc}}
 author "Juju Team <juju@lists.ubuntu.com>"
 start on stopped {{.AfterStopped}}
 
@@ -374,8 +532,8 @@ script
 end script
 {{if .ExecStopPost}}
 post-stop script
-  {{.ExecStopPost}}
-end script
+{{range .ExecStopPost}}  {{.}}
+{{end}}end script
 {{end}}
 `[1:]))
 
@@ -9,8 +9,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	"github.com/juju/utils/symlink"
@@ -88,6 +90,24 @@ func (s *UpstartSuite) goodInstall(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *UpstartSuite) TestListServicesMatching(c *gc.C) {
+	for _, name := range []string{"jujud-machine-0.conf", "juju-db.conf", "jujud-unit-mysql-0.conf", "README"} {
+		err := ioutil.WriteFile(filepath.Join(s.initDir, name), nil, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	services, err := upstart.ListServicesMatching("jujud-")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(services, jc.SameContents, []string{"jujud-machine-0", "jujud-unit-mysql-0"})
+}
+
+func (s *UpstartSuite) TestListCommandMatching(c *gc.C) {
+	c.Check(upstart.ListCommandMatching(""), gc.Equals, upstart.ListCommand())
+	c.Check(upstart.ListCommandMatching("jujud-"), gc.Equals,
+		`sudo initctl list | awk 'index($1, "jujud-") == 1 {print $1}' | sort | uniq`)
+}
+
 func (s *UpstartSuite) TestInstalled(c *gc.C) {
 	installed, err := s.service.Installed()
 	c.Assert(err, jc.ErrorIsNil)
@@ -338,6 +358,248 @@ end script
 `)
 }
 
+func (s *UpstartSuite) TestInstallOwnership(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.User = "some-user"
+	conf.Group = "some-group"
+	conf.UMask = "0027"
+	conf.WorkingDirectory = "/var/lib/some-service"
+	s.assertInstall(c, conf, `setuid some-user
+setgid some-group
+umask 0027
+chdir /var/lib/some-service
+
+
+script
+
+
+  exec /path/to/some-command x y z
+end script
+`)
+}
+
+func (s *UpstartSuite) TestInstallKill(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.TimeoutStopSec = 30
+	conf.KillSignal = "SIGINT"
+	s.assertInstall(c, conf, `kill timeout 30
+kill signal SIGINT
+
+
+script
+
+
+  exec /path/to/some-command x y z
+end script
+`)
+}
+
+func (s *UpstartSuite) TestInstallHooks(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.ExecStartPre = []string{"/bin/mkdir -p /var/run/some-service", "/bin/true"}
+	conf.ExecStartPost = []string{"/usr/bin/logger started"}
+	conf.ExecStopPost = []string{"/bin/rm -f /var/run/some-service/lock"}
+	s.assertInstall(c, conf, `
+
+script
+
+
+  exec /path/to/some-command x y z
+end script
+
+pre-start script
+  /bin/mkdir -p /var/run/some-service
+  /bin/true
+end script
+
+post-start script
+  /usr/bin/logger started
+end script
+
+post-stop script
+  /bin/rm -f /var/run/some-service/lock
+end script
+`)
+}
+
+func (s *UpstartSuite) TestInstallCommandsTransientStopPost(c *gc.C) {
+	s.service.Service.Conf = common.Conf{
+		Desc:         "this is an upstart service",
+		ExecStart:    "/path/to/some-command x y z",
+		Transient:    true,
+		AfterStopped: "some-other-service",
+		ExecStopPost: []string{"/path/to/cleanup"},
+	}
+	expectPath := filepath.Join(upstart.InitDir, "some-service.conf")
+
+	cmds, err := s.service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(cmds, jc.DeepEquals, []string{
+		"cat > " + expectPath + " << 'EOF'\n" + `description "this is an upstart service"
+author "Juju Team <juju@lists.ubuntu.com>"
+start on stopped some-other-service
+
+script
+  /path/to/some-command x y z
+end script
+
+post-stop script
+  /path/to/cleanup
+  rm ` + expectPath + `
+end script
+
+EOF
+`,
+	})
+}
+
+func (s *UpstartSuite) TestTransientHooksNotSupported(c *gc.C) {
+	s.service.Service.Conf.Transient = true
+	s.service.Service.Conf.AfterStopped = "some-other-service"
+	s.service.Service.Conf.ExecStartPre = []string{"/bin/true"}
+
+	err := s.service.Validate()
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "ExecStartPre")
+}
+
+func (s *UpstartSuite) TestRemoveCommands(c *gc.C) {
+	cmds, err := s.service.RemoveCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(cmds, jc.DeepEquals, []string{
+		"stop some-service || true",
+		"rm -f " + filepath.Join(upstart.InitDir, "some-service.conf"),
+	})
+}
+
+func (s *UpstartSuite) TestInstallKillModeNotSupported(c *gc.C) {
+	s.service.Service.Conf.KillMode = "process"
+
+	err := s.service.Validate()
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "KillMode")
+}
+
+func (s *UpstartSuite) TestSerializeDependencies(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.After = []string{"network-online.target", "jujud-machine-0"}
+	conf.Requires = []string{"juju-db.service"}
+	conf.Wants = []string{"some-other-service"}
+
+	data, err := upstart.Serialize("some-service", conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	lines := strings.Split(string(data), "\n")
+	c.Check(lines[2], gc.Equals, "start on (runlevel [2345] and started jujud-machine-0 and started juju-db)")
+	c.Check(lines[3], gc.Equals, "stop on (runlevel [!2345] or stopping juju-db)")
+}
+
+func (s *UpstartSuite) TestDeserializeRoundTrip(c *gc.C) {
+	for i, conf := range []common.Conf{{
+		Desc:      "some service",
+		ExecStart: "/path/to/some-command x y",
+	}, {
+		Desc:        "some service",
+		ExecStart:   "/path/to/some-command",
+		ExtraScript: "echo one\n\necho two",
+		Logfile:     "/var/log/some.log",
+	}, {
+		Desc:             "some service",
+		ExecStart:        "/path/to/some-command",
+		After:            []string{"jujud-machine-0", "juju-db"},
+		Requires:         []string{"juju-db"},
+		User:             "juju",
+		Group:            "juju",
+		UMask:            "0027",
+		WorkingDirectory: "/var/lib/juju",
+		TimeoutStopSec:   30,
+		KillSignal:       "SIGINT",
+		Env:              map[string]string{"A": "b c", "D": `"quoted"`},
+		Limit:            map[string]int{"nofile": 65000},
+		ExecStartPre:     []string{"/bin/mkdir -p /run/juju", "/bin/true"},
+		ExecStartPost:    []string{"/bin/true"},
+		ExecStopPost:     []string{"/bin/rm -rf /run/juju"},
+	}, {
+		Desc:         "some service",
+		Transient:    true,
+		AfterStopped: "jujud-machine-0",
+		ExecStart:    "/path/to/some-command x",
+		ExecStopPost: []string{"/bin/true"},
+	}} {
+		c.Logf("test %d", i)
+		data, err := upstart.Serialize("some-service", conf)
+		c.Assert(err, jc.ErrorIsNil)
+
+		result, err := upstart.Deserialize(data)
+		c.Assert(err, jc.ErrorIsNil)
+
+		c.Check(result, jc.DeepEquals, conf)
+	}
+}
+
+func (s *UpstartSuite) TestDeserializeRequiresOrdered(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.Requires = []string{"juju-db"}
+	data, err := upstart.Serialize("some-service", conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := upstart.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(result.After, jc.DeepEquals, []string{"juju-db"})
+	c.Check(result.Requires, jc.DeepEquals, []string{"juju-db"})
+}
+
+func (s *UpstartSuite) TestDeserializeUnknownStanza(c *gc.C) {
+	_, err := upstart.Deserialize([]byte("description \"x\"\nexpect fork\n"))
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	c.Check(err, gc.ErrorMatches, `stanza "expect" not supported`)
+}
+
+func (s *UpstartSuite) TestDeserializeUnterminated(c *gc.C) {
+	_, err := upstart.Deserialize([]byte("script\n  exec /bin/true\n"))
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *UpstartSuite) TestReadConf(c *gc.C) {
+	s.service.Service.Conf.Transient = true
+	s.service.Service.Conf.AfterStopped = "jujud-machine-0"
+	cmds, err := s.service.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+	data := strings.TrimSuffix(strings.SplitN(cmds[0], "\n", 2)[1], "EOF\n")
+	err = ioutil.WriteFile(filepath.Join(s.initDir, "some-service.conf"), []byte(data), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	conf, err := s.service.ReadConf()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(conf, jc.DeepEquals, s.service.Conf())
+}
+
+func (s *UpstartSuite) TestReadConfNotInstalled(c *gc.C) {
+	_, err := s.service.ReadConf()
+
+	c.Check(err, jc.Satisfies, common.IsNotInstalled)
+}
+
+func (s *UpstartSuite) TestWantedByNotSupported(c *gc.C) {
+	s.service.Service.Conf.WantedBy = "graphical.target"
+
+	err := s.service.Validate()
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "WantedBy")
+}
+
 func (s *UpstartSuite) TestInstallAlreadyRunning(c *gc.C) {
 	pathTo := func(name string) string {
 		return filepath.Join(s.testPath, name)
@@ -435,3 +697,33 @@ func (s *IsRunningSuite) TestInitctlCantBeRun(c *gc.C) {
 	c.Assert(isUpstart, jc.IsFalse)
 	c.Assert(err, gc.ErrorMatches, ".+: permission denied")
 }
+
+func (s *UpstartSuite) TestDeserializeRoundTripQuoting(c *gc.C) {
+	conf := common.Conf{
+		Desc:      `some "quoted" service at 100% \o/`,
+		ExecStart: "/path/to/some-command",
+		Env: map[string]string{
+			"QUOTES":  `"double" 'single'`,
+			"SLASHES": `C:\Juju\ \`,
+			"DOLLAR":  "$HOME ${PATH}",
+			"TAB":     "a\tb",
+			"EMPTY":   "",
+		},
+	}
+	data, err := upstart.Serialize("some-service", conf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := upstart.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(result, jc.DeepEquals, conf)
+}
+
+func (s *UpstartSuite) TestValidateEnvNewline(c *gc.C) {
+	s.service.Service.Conf.Env = map[string]string{"A": "one\ntwo"}
+
+	err := s.service.Validate()
+
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "Env")
+}
@@ -55,11 +55,30 @@ func ListServices() ([]string, error) {
 	return listServices()
 }
 
+// ListServicesMatching returns the names of the installed services
+// on the local host that start with the provided prefix.
+func ListServicesMatching(prefix string) ([]string, error) {
+	services, err := listServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return common.FilterPrefix(services, prefix), nil
+}
+
 // ListCommand returns a command that will list the services on a host.
 func ListCommand() string {
 	return `(Get-Service).Name`
 }
 
+// ListCommandMatching returns a command that will list the services
+// on a host that start with the provided prefix.
+func ListCommandMatching(prefix string) string {
+	if prefix == "" {
+		return ListCommand()
+	}
+	return fmt.Sprintf(`(Get-Service -Name '%s*').Name`, prefix)
+}
+
 // ServiceManager exposes methods needed to manage a windows service
 type ServiceManager interface {
 	// Start starts a service.
@@ -121,12 +140,63 @@ func (s *Service) Validate() error {
 		return errors.Trace(err)
 	}
 
-	if s.Service.Conf.Transient {
-		return errors.NotSupportedf("transient services")
+	conf := s.Service.Conf
+	if conf.Transient {
+		return common.ConfNotSupportedf("Transient", "transient services not supported")
 	}
 
-	if s.Service.Conf.AfterStopped != "" {
-		return errors.NotSupportedf("Conf.AfterStopped")
+	if conf.AfterStopped != "" {
+		return common.ConfNotSupportedf("AfterStopped", "Conf.AfterStopped not supported")
+	}
+
+	if conf.User != "" {
+		return common.ConfNotSupportedf("User", "Conf.User and Conf.Group not supported")
+	}
+	if conf.Group != "" {
+		return common.ConfNotSupportedf("Group", "Conf.User and Conf.Group not supported")
+	}
+
+	if conf.UMask != "" {
+		return common.ConfNotSupportedf("UMask", "Conf.UMask not supported")
+	}
+
+	if conf.WorkingDirectory != "" {
+		return common.ConfNotSupportedf("WorkingDirectory", "Conf.WorkingDirectory not supported")
+	}
+
+	if conf.KillMode != "" {
+		return common.ConfNotSupportedf("KillMode", "Conf.KillMode and Conf.KillSignal not supported")
+	}
+	if conf.KillSignal != "" {
+		return common.ConfNotSupportedf("KillSignal", "Conf.KillMode and Conf.KillSignal not supported")
+	}
+
+	if conf.TimeoutStopSec > 0 {
+		return common.ConfNotSupportedf("TimeoutStopSec", "Conf.TimeoutStopSec not supported")
+	}
+
+	if conf.WantedBy != "" {
+		return common.ConfNotSupportedf("WantedBy", "Conf.WantedBy not supported")
+	}
+
+	if strings.ContainsAny(conf.ExecStart, "\r\n") {
+		// InstallCommands would split the command across lines.
+		return common.ConfInvalidf("ExecStart", "ExecStart (multiple lines) not valid")
+	}
+
+	// The service manager runs ServiceBinary directly, so there is
+	// nowhere to hook in extra commands.
+	for _, hook := range []struct {
+		field string
+		cmds  []string
+	}{
+		{"ExecStartPre", conf.ExecStartPre},
+		{"ExecStartPost", conf.ExecStartPost},
+		{"ExecStopPost", conf.ExecStopPost},
+	} {
+		if len(hook.cmds) > 0 {
+			return common.ConfNotSupportedf(hook.field, "Conf.%s not supported", hook.field)
+		}
 	}
 
 	return nil
@@ -229,10 +299,42 @@ func (s *Service) Install() error {
 	return nil
 }
 
+// dependencies returns the names of the services the service depends
+// on. The service manager has no notion of ordering without a hard
+// dependency, so After is treated like Requires. Every juju service
+// depends on the WMI service, which is needed by almost all
+// installers and by powershell.
+func dependencies(conf common.Conf) []string {
+	deps := []string{"Winmgmt"}
+	seen := map[string]bool{"Winmgmt": true}
+	for _, names := range [][]string{conf.Requires, conf.After} {
+		for _, name := range names {
+			if strings.HasSuffix(name, ".target") {
+				logger.Debugf("ignoring dependency on target %q", name)
+				continue
+			}
+			name = strings.TrimSuffix(name, ".service")
+			if !seen[name] {
+				seen[name] = true
+				deps = append(deps, name)
+			}
+		}
+	}
+	return deps
+}
+
 // InstallCommands returns shell commands to install the service.
 func (s *Service) InstallCommands() ([]string, error) {
+	if err := s.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	deps := dependencies(s.Service.Conf)
+	for i, dep := range deps[1:] {
+		deps[i+1] = renderer.Quote(dep)
+	}
 	cmd := fmt.Sprintf(serviceInstallCommands[1:],
 		renderer.Quote(s.Service.Name),
+		strings.Join(deps, ","),
 		renderer.Quote(s.Service.Conf.Desc),
 		renderer.Quote(s.Service.Conf.ExecStart),
 		renderer.Quote(s.Service.Name),
@@ -247,7 +349,20 @@ func (s *Service) StartCommands() ([]string, error) {
 	return []string{cmd}, nil
 }
 
+// RemoveCommands returns shell commands to stop and remove the service.
+func (s *Service) RemoveCommands() ([]string, error) {
+	cmd := fmt.Sprintf(serviceRemoveCommands[1:],
+		renderer.Quote(s.Service.Name),
+		renderer.Quote(s.Service.Name),
+	)
+	return strings.Split(cmd, "\n"), nil
+}
+
+const serviceRemoveCommands = `
+Stop-Service %s -ErrorAction SilentlyContinue
+sc.exe delete %s`
+
 const serviceInstallCommands = `
-New-Service -Credential $jujuCreds -Name %s -DependsOn Winmgmt -DisplayName %s %s
+New-Service -Credential $jujuCreds -Name %s -DependsOn %s -DisplayName %s %s
 sc.exe failure %s reset=5 actions=restart/1000
 sc.exe failureflag %s 1`
@@ -52,6 +52,21 @@ func (s *serviceSuite) SetUpTest(c *gc.C) {
 	s.stub.ResetCalls()
 }
 
+func (s *serviceSuite) TestRemoveCommands(c *gc.C) {
+	commands, err := s.mgr.RemoveCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(commands, jc.DeepEquals, []string{
+		`Stop-Service 'machine-1' -ErrorAction SilentlyContinue`,
+		`sc.exe delete 'machine-1'`,
+	})
+}
+
+func (s *serviceSuite) TestListCommandMatching(c *gc.C) {
+	c.Check(windows.ListCommandMatching(""), gc.Equals, windows.ListCommand())
+	c.Check(windows.ListCommandMatching("jujud-"), gc.Equals, `(Get-Service -Name 'jujud-*').Name`)
+}
+
 func (s *serviceSuite) TestInstall(c *gc.C) {
 	err := s.mgr.Install()
 	c.Assert(err, gc.IsNil)
@@ -202,3 +217,94 @@ func (s *serviceSuite) TestInstalledListError(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, listErr.Error())
 	c.Assert(exists, jc.IsFalse)
 }
+
+func (s *serviceSuite) TestValidateOwnershipNotSupported(c *gc.C) {
+	for _, conf := range []common.Conf{
+		{User: "jujud"},
+		{Group: "juju"},
+		{UMask: "0022"},
+		{WorkingDirectory: `C:\juju`},
+	} {
+		conf.Desc = s.conf.Desc
+		conf.ExecStart = s.conf.ExecStart
+		s.mgr.UpdateConfig(conf)
+
+		err := s.mgr.Validate()
+
+		c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	}
+}
+
+func (s *serviceSuite) TestValidateKillNotSupported(c *gc.C) {
+	for _, conf := range []common.Conf{
+		{TimeoutStopSec: 30},
+		{KillMode: "process"},
+		{KillSignal: "SIGINT"},
+	} {
+		conf.Desc = s.conf.Desc
+		conf.ExecStart = s.conf.ExecStart
+		s.mgr.UpdateConfig(conf)
+
+		err := s.mgr.Validate()
+
+		c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	}
+}
+
+func (s *serviceSuite) TestValidateHooksNotSupported(c *gc.C) {
+	for field, conf := range map[string]common.Conf{
+		"ExecStartPre":  {ExecStartPre: []string{`C:\juju\setup.exe`}},
+		"ExecStartPost": {ExecStartPost: []string{`C:\juju\notify.exe`}},
+		"ExecStopPost":  {ExecStopPost: []string{`C:\juju\cleanup.exe`}},
+	} {
+		conf.Desc = s.conf.Desc
+		conf.ExecStart = s.conf.ExecStart
+		s.mgr.UpdateConfig(conf)
+
+		err := s.mgr.Validate()
+
+		c.Check(err, jc.Satisfies, errors.IsNotSupported)
+		errField, _ := common.ConfInvalidField(err)
+		c.Check(errField, gc.Equals, field)
+	}
+}
+
+func (s *serviceSuite) TestInstallCommandsHooksNotSupported(c *gc.C) {
+	conf := s.conf
+	conf.ExecStartPre = []string{`C:\juju\setup.exe`}
+	s.mgr.UpdateConfig(conf)
+
+	_, err := s.mgr.InstallCommands()
+
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *serviceSuite) TestValidateMultilineExecStart(c *gc.C) {
+	conf := s.conf
+	conf.ExecStart += "\nC:\\juju\\other.exe"
+	s.mgr.UpdateConfig(conf)
+
+	err := s.mgr.Validate()
+
+	field, _ := common.ConfInvalidField(err)
+	c.Check(field, gc.Equals, "ExecStart")
+}
+
+func (s *serviceSuite) TestInstallCommandsDependencies(c *gc.C) {
+	conf := s.conf
+	conf.After = []string{"network-online.target", "jujud-machine-0"}
+	conf.Requires = []string{"jujud-machine-0.service"}
+	s.mgr.UpdateConfig(conf)
+
+	cmds, err := s.mgr.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(cmds[0], jc.Contains, "-DependsOn Winmgmt,'jujud-machine-0' -DisplayName")
+}
+
+func (s *serviceSuite) TestInstallCommandsDefaultDependencies(c *gc.C) {
+	cmds, err := s.mgr.InstallCommands()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(cmds[0], jc.Contains, "-DependsOn Winmgmt -DisplayName")
+}
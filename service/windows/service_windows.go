@@ -268,11 +268,10 @@ func (s *SvcManager) Exists(name string, conf common.Conf) (bool, error) {
 	// We escape and compose BinaryPathName the same way mgr.CreateService does.
 	execStart := s.escapeExecPath(conf.ServiceBinary, conf.ServiceArgs)
 	cfg := mgr.Config{
-		// make this service dependent on WMI service. WMI is needed for almost
-		// all installers to work properly, and is needed for all of the advanced windows
-		// instrumentation bits (powershell included). Juju agents must start after this
-		// service to ensure hooks run properly.
-		Dependencies:     []string{"Winmgmt"},
+		// make this service dependent on WMI service (see dependencies).
+		// Juju agents must start after this service to ensure hooks run
+		// properly.
+		Dependencies:     dependencies(conf),
 		StartType:        mgr.StartAutomatic,
 		DisplayName:      conf.Desc,
 		ServiceStartName: jujudUser,
@@ -340,7 +339,7 @@ func (s *SvcManager) Create(name string, conf common.Conf) error {
 		return errors.Trace(err)
 	}
 	cfg := mgr.Config{
-		Dependencies:     []string{"Winmgmt"},
+		Dependencies:     dependencies(conf),
 		ErrorControl:     mgr.ErrorSevere,
 		StartType:        mgr.StartAutomatic,
 		DisplayName:      conf.Desc,
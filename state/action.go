@@ -9,7 +9,9 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
 	"github.com/juju/utils"
+	"gopkg.in/juju/charm.v6-unstable"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
@@ -39,6 +41,10 @@ const (
 
 	// ActionRunning indicates that the Action is currently running.
 	ActionRunning ActionStatus = "running"
+
+	// ActionAborting indicates that the Action was cancelled while it
+	// was running, and that its receiver's agent is stopping it.
+	ActionAborting ActionStatus = "aborting"
 )
 const actionMarker string = "_a_"
 
@@ -99,6 +105,18 @@ type actionDoc struct {
 
 	// Results are the structured results from the action.
 	Results map[string]interface{} `bson:"results"`
+
+	// Timeout is how long the action may run for before its
+	// receiver's agent stops it; zero means no limit.
+	Timeout time.Duration `bson:"timeout,omitempty"`
+
+	// Queued is true while a pending action waits in its receiver's
+	// queue for a free slot. Its notification is only written when it
+	// is dispatched to the receiver.
+	Queued bool `bson:"queued,omitempty"`
+
+	// QueueSeq orders the actions waiting in a receiver's queue.
+	QueueSeq int `bson:"queue-seq,omitempty"`
 }
 
 // Action represents an instruction to do some "action" and is expected
@@ -152,6 +170,18 @@ func (a *Action) Status() ActionStatus {
 	return a.doc.Status
 }
 
+// Timeout returns how long the action may run for before it is
+// stopped; zero means no limit.
+func (a *Action) Timeout() time.Duration {
+	return a.doc.Timeout
+}
+
+// Queued returns whether the action is pending in its receiver's queue,
+// waiting for earlier actions to finish before it is dispatched.
+func (a *Action) Queued() bool {
+	return a.doc.Queued
+}
+
 // Results returns the structured output of the action and any error.
 func (a *Action) Results() (map[string]interface{}, string) {
 	return a.doc.Results, a.doc.Message
@@ -184,13 +214,17 @@ type ActionResults struct {
 }
 
 // Begin marks an action as running, and logs the time it was started.
-// It asserts that the action is currently pending.
+// It asserts that the action is currently pending, and has been
+// dispatched to its receiver.
 func (a *Action) Begin() (*Action, error) {
 	err := a.st.runTransaction([]txn.Op{
 		{
-			C:      actionsC,
-			Id:     a.doc.DocId,
-			Assert: bson.D{{"status", ActionPending}},
+			C:  actionsC,
+			Id: a.doc.DocId,
+			Assert: bson.D{
+				{"status", ActionPending},
+				{"queued", bson.D{{"$ne", true}}},
+			},
 			Update: bson.D{{"$set", bson.D{
 				{"status", ActionRunning},
 				{"started", nowToTheSecond()},
@@ -208,35 +242,109 @@ func (a *Action) Finish(results ActionResults) (*Action, error) {
 	return a.removeAndLog(results.Status, results.Results, results.Message)
 }
 
+// Cancel cancels the action. A pending action is cancelled at once. A
+// running action is marked as aborting; its receiver's agent stops it,
+// and it is then recorded as cancelled.
+func (a *Action) Cancel() (*Action, error) {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := a.st.actionDoc(a.doc.DocId)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		switch doc.Status {
+		case ActionPending:
+			return a.st.finishActionOps(doc, ActionCancelled, nil, "action cancelled")
+		case ActionRunning:
+			return []txn.Op{{
+				C:      actionsC,
+				Id:     doc.DocId,
+				Assert: bson.D{{"status", ActionRunning}},
+				Update: bson.D{{"$set", bson.D{{"status", ActionAborting}}}},
+			}}, nil
+		case ActionAborting:
+			return nil, jujutxn.ErrNoOperations
+		}
+		return nil, errors.Errorf("action %q is already %s", a.Id(), doc.Status)
+	}
+	if err := a.st.run(buildTxn); err != nil {
+		return nil, errors.Annotatef(err, "cannot cancel action %q", a.Id())
+	}
+	return a.st.Action(a.Id())
+}
+
 // removeAndLog takes the action off of the pending queue, and creates
 // an actionresult to capture the outcome of the action. It asserts that
 // the action is not already completed.
 func (a *Action) removeAndLog(finalStatus ActionStatus, results map[string]interface{}, message string) (*Action, error) {
-	err := a.st.runTransaction([]txn.Op{
-		{
-			C:  actionsC,
-			Id: a.doc.DocId,
-			Assert: bson.D{{"status", bson.D{
-				{"$nin", []interface{}{
-					ActionCompleted,
-					ActionCancelled,
-					ActionFailed,
-				}}}}},
-			Update: bson.D{{"$set", bson.D{
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := a.st.actionDoc(a.doc.DocId)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return a.st.finishActionOps(doc, finalStatus, results, message)
+	}
+	if err := a.st.run(buildTxn); err != nil {
+		return nil, err
+	}
+	return a.st.Action(a.Id())
+}
+
+// finishActionOps returns the operations that record the outcome of the
+// given action, and take it off its receiver's queue. An aborting
+// action that failed is recorded as cancelled, since it failed because
+// it was stopped.
+func (st *State) finishActionOps(doc actionDoc, finalStatus ActionStatus, results map[string]interface{}, message string) ([]txn.Op, error) {
+	switch doc.Status {
+	case ActionCompleted, ActionCancelled, ActionFailed:
+		return nil, errors.Errorf("action %q is already %s", st.localID(doc.DocId), doc.Status)
+	case ActionAborting:
+		if finalStatus == ActionFailed {
+			finalStatus = ActionCancelled
+		}
+	}
+	ops := []txn.Op{{
+		C:      actionsC,
+		Id:     doc.DocId,
+		Assert: bson.D{{"status", doc.Status}},
+		Update: bson.D{
+			{"$set", bson.D{
 				{"status", finalStatus},
 				{"message", message},
 				{"results", results},
 				{"completed", nowToTheSecond()},
-			}}},
-		}, {
-			C:      actionNotificationsC,
-			Id:     a.st.docID(ensureActionMarker(a.Receiver()) + a.Id()),
-			Remove: true,
-		}})
+			}},
+			{"$unset", bson.D{
+				{"queued", nil},
+				{"queue-seq", nil},
+			}},
+		},
+	}, {
+		C:      actionNotificationsC,
+		Id:     st.docID(ensureActionMarker(doc.Receiver) + st.localID(doc.DocId)),
+		Remove: true,
+	}}
+	queueOps, err := st.releaseActionOps(doc)
 	if err != nil {
-		return nil, err
+		return nil, errors.Trace(err)
 	}
-	return a.st.Action(a.Id())
+	return append(ops, queueOps...), nil
+}
+
+// actionDoc returns the current document of the action with the given
+// document id.
+func (st *State) actionDoc(docId string) (actionDoc, error) {
+	actions, closer := st.getCollection(actionsC)
+	defer closer()
+
+	var doc actionDoc
+	err := actions.FindId(docId).One(&doc)
+	if err == mgo.ErrNotFound {
+		return actionDoc{}, errors.NotFoundf("action %q", st.localID(docId))
+	}
+	if err != nil {
+		return actionDoc{}, errors.Annotatef(err, "cannot get action %q", st.localID(docId))
+	}
+	return doc, nil
 }
 
 // newActionTagFromNotification converts an actionNotificationDoc into
@@ -254,29 +362,64 @@ func newAction(st *State, adoc actionDoc) *Action {
 	}
 }
 
-// newActionDoc builds the actionDoc with the given name and parameters.
-func newActionDoc(st *State, receiverTag names.Tag, actionName string, parameters map[string]interface{}) (actionDoc, actionNotificationDoc, error) {
-	prefix := ensureActionMarker(receiverTag.Id())
+// newActionDoc builds the actionDoc with the given name, parameters and
+// timeout.
+func newActionDoc(st *State, receiverTag names.Tag, actionName string, parameters map[string]interface{}, timeout time.Duration) (actionDoc, error) {
 	actionId, err := NewUUID()
 	if err != nil {
-		return actionDoc{}, actionNotificationDoc{}, err
+		return actionDoc{}, err
 	}
 	actionLogger.Debugf("newActionDoc name: '%s', receiver: '%s', actionId: '%s'", actionName, receiverTag, actionId)
-	envuuid := st.EnvironUUID()
 	return actionDoc{
-			DocId:      st.docID(actionId.String()),
-			EnvUUID:    envuuid,
-			Receiver:   receiverTag.Id(),
-			Name:       actionName,
-			Parameters: parameters,
-			Enqueued:   nowToTheSecond(),
-			Status:     ActionPending,
-		}, actionNotificationDoc{
-			DocId:    st.docID(prefix + actionId.String()),
-			EnvUUID:  envuuid,
-			Receiver: receiverTag.Id(),
-			ActionID: actionId.String(),
-		}, nil
+		DocId:      st.docID(actionId.String()),
+		EnvUUID:    st.EnvironUUID(),
+		Receiver:   receiverTag.Id(),
+		Name:       actionName,
+		Parameters: parameters,
+		Enqueued:   nowToTheSecond(),
+		Status:     ActionPending,
+		Timeout:    timeout,
+	}, nil
+}
+
+// newActionNotificationDoc builds the actionNotificationDoc that
+// dispatches the given action to its receiver.
+func newActionNotificationDoc(st *State, receiver, actionId string) actionNotificationDoc {
+	return actionNotificationDoc{
+		DocId:    st.docID(ensureActionMarker(receiver) + actionId),
+		EnvUUID:  st.EnvironUUID(),
+		Receiver: receiver,
+		ActionID: actionId,
+	}
+}
+
+// actionTimeout returns the timeout declared for an action in its
+// charm's actions.yaml, if any. The timeout is a duration such as "10m",
+// or a number of seconds; the charm package passes it through as part
+// of the action's schema.
+func actionTimeout(spec charm.ActionSpec) (time.Duration, error) {
+	var timeout time.Duration
+	switch value := spec.Params["timeout"].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil {
+			return 0, errors.NotValidf("timeout %q", value)
+		}
+	case int:
+		timeout = time.Duration(value) * time.Second
+	case int64:
+		timeout = time.Duration(value) * time.Second
+	case float64:
+		timeout = time.Duration(value * float64(time.Second))
+	default:
+		return 0, errors.NotValidf("timeout %v", value)
+	}
+	if timeout < 0 {
+		return 0, errors.NotValidf("negative timeout %v", timeout)
+	}
+	return timeout, nil
 }
 
 var ensureActionMarker = ensureSuffixFn(actionMarker)
@@ -329,8 +472,15 @@ func (st *State) FindActionTagsByPrefix(prefix string) []names.ActionTag {
 	return results
 }
 
-// EnqueueAction
+// EnqueueAction adds an action with the given name and payload to the
+// receiver's queue. It is dispatched to the receiver at once unless the
+// environment's action-parallelism limit means it must wait for earlier
+// actions to finish.
 func (st *State) EnqueueAction(receiver names.Tag, actionName string, payload map[string]interface{}) (*Action, error) {
+	return st.enqueueAction(receiver, actionName, payload, 0)
+}
+
+func (st *State) enqueueAction(receiver names.Tag, actionName string, payload map[string]interface{}, timeout time.Duration) (*Action, error) {
 	if len(actionName) == 0 {
 		return nil, errors.New("action name required")
 	}
@@ -340,36 +490,32 @@ func (st *State) EnqueueAction(receiver names.Tag, actionName string, payload ma
 		return nil, errors.Trace(err)
 	}
 
-	doc, ndoc, err := newActionDoc(st, receiver, actionName, payload)
+	doc, err := newActionDoc(st, receiver, actionName, payload, timeout)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	ops := []txn.Op{{
-		C:      receiverCollectionName,
-		Id:     receiverId,
-		Assert: notDeadDoc,
-	}, {
-		C:      actionsC,
-		Id:     doc.DocId,
-		Assert: txn.DocMissing,
-		Insert: doc,
-	}, {
-		C:      actionNotificationsC,
-		Id:     ndoc.DocId,
-		Assert: txn.DocMissing,
-		Insert: ndoc,
-	}}
-
 	buildTxn := func(attempt int) ([]txn.Op, error) {
 		if notDead, err := isNotDead(st, receiverCollectionName, receiverId); err != nil {
 			return nil, err
 		} else if !notDead {
 			return nil, ErrDead
-		} else if attempt != 0 {
-			return nil, errors.Errorf("unexpected attempt number '%d'", attempt)
 		}
-		return ops, nil
+		queueOps, err := st.enqueueActionOps(&doc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := []txn.Op{{
+			C:      receiverCollectionName,
+			Id:     receiverId,
+			Assert: notDeadDoc,
+		}, {
+			C:      actionsC,
+			Id:     doc.DocId,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}
+		return append(ops, queueOps...), nil
 	}
 	if err = st.run(buildTxn); err == nil {
 		return newAction(st, doc), nil
@@ -425,10 +571,24 @@ func (st *State) matchingActionsPending(ar ActionReceiver) ([]*Action, error) {
 }
 
 // matchingActionsRunning finds actions that match ActionReceiver and
-// that are running.
+// that are running, including those being aborted.
 func (st *State) matchingActionsRunning(ar ActionReceiver) ([]*Action, error) {
-	completed := bson.D{{"status", ActionRunning}}
-	return st.matchingActionsByReceiverAndStatus(ar.Tag(), completed)
+	running := bson.D{{"status", bson.D{{"$in", []ActionStatus{ActionRunning, ActionAborting}}}}}
+	return st.matchingActionsByReceiverAndStatus(ar.Tag(), running)
+}
+
+// matchingActionsQueued finds actions that match ActionReceiver and
+// that are waiting in its queue, oldest first.
+func (st *State) matchingActionsQueued(ar ActionReceiver) ([]*Action, error) {
+	docs, err := st.queuedActions(ar.Tag().Id())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	actions := make([]*Action, len(docs))
+	for i, doc := range docs {
+		actions[i] = newAction(st, doc)
+	}
+	return actions, nil
 }
 
 // matchingActionsCompleted finds actions that match ActionReceiver and
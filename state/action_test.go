@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -463,6 +464,189 @@ func (s *ActionSuite) TestComplete(c *gc.C) {
 	c.Assert(len(actions), gc.Equals, 0)
 }
 
+func (s *ActionSuite) setActionParallelism(c *gc.C, n int) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"action-parallelism": n}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ActionSuite) TestActionParallelismQueuesActions(c *gc.C) {
+	s.setActionParallelism(c, 1)
+	unit, err := s.State.Unit(s.unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	preventUnitDestroyRemove(c, unit)
+
+	a1, err := unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a1.Queued(), jc.IsFalse)
+	a2, err := unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a2.Queued(), jc.IsTrue)
+	a3, err := unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a3.Queued(), jc.IsTrue)
+
+	// Only the dispatched action is sent to the unit's agent.
+	w := unit.WatchActionNotifications()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(expectActionIds(a1)...)
+	wc.AssertNoChange()
+
+	queued, err := unit.QueuedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(expectActionIds(queued...), jc.DeepEquals, expectActionIds(a2, a3))
+
+	// Queued actions are all pending.
+	pending, err := unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 3)
+
+	// Finishing the dispatched action dispatches the oldest queued one.
+	_, err = a1.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	a2, err = s.State.Action(a2.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a2.Queued(), jc.IsFalse)
+
+	queued, err = unit.QueuedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(expectActionIds(queued...), jc.DeepEquals, expectActionIds(a3))
+}
+
+func (s *ActionSuite) TestActionParallelismUnlimited(c *gc.C) {
+	for i := 0; i < 3; i++ {
+		a, err := s.unit.AddAction("snapshot", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(a.Queued(), jc.IsFalse)
+	}
+	queued, err := s.unit.QueuedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 0)
+}
+
+func (s *ActionSuite) TestBeginQueuedActionFails(c *gc.C) {
+	s.setActionParallelism(c, 1)
+	_, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a.Queued(), jc.IsTrue)
+
+	_, err = a.Begin()
+	c.Assert(err, gc.NotNil)
+	a, err = s.State.Action(a.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a.Status(), gc.Equals, state.ActionPending)
+}
+
+func (s *ActionSuite) TestCancelPending(c *gc.C) {
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	a, err = a.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a.Status(), gc.Equals, state.ActionCancelled)
+	_, message := a.Results()
+	c.Assert(message, gc.Equals, "action cancelled")
+
+	_, err = a.Cancel()
+	c.Assert(err, gc.ErrorMatches, `cannot cancel action ".*": action ".*" is already cancelled`)
+}
+
+func (s *ActionSuite) TestCancelRunning(c *gc.C) {
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = a.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+
+	a, err = a.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a.Status(), gc.Equals, state.ActionAborting)
+
+	// Aborting actions are still running until their agent stops them.
+	running, err := s.unit.RunningActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(expectActionIds(running...), jc.DeepEquals, expectActionIds(a))
+
+	// Cancelling again changes nothing.
+	a, err = a.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a.Status(), gc.Equals, state.ActionAborting)
+
+	// The agent reports the stopped action as failed.
+	a, err = a.Finish(state.ActionResults{Status: state.ActionFailed, Message: "action cancelled"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a.Status(), gc.Equals, state.ActionCancelled)
+}
+
+func (s *ActionSuite) TestCancelCompleted(c *gc.C) {
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = a.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = a.Cancel()
+	c.Assert(err, gc.ErrorMatches, `cannot cancel action ".*": action ".*" is already completed`)
+}
+
+func (s *ActionSuite) TestCancelQueued(c *gc.C) {
+	s.setActionParallelism(c, 1)
+	a1, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	a2, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a2.Queued(), jc.IsTrue)
+
+	a2, err = a2.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a2.Status(), gc.Equals, state.ActionCancelled)
+	c.Assert(a2.Queued(), jc.IsFalse)
+
+	queued, err := s.unit.QueuedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 0)
+
+	// The dispatched action is unaffected, and its slot is freed when
+	// it finishes.
+	_, err = a1.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	a3, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(a3.Queued(), jc.IsFalse)
+}
+
+func (s *ActionSuite) TestAddActionTimeout(c *gc.C) {
+	ch := s.AddActionsCharm(c, "mysql", `
+slow:
+  timeout: 10m
+fast:
+  timeout: 30
+bad:
+  timeout: soon
+none:
+  description: no timeout
+`[1:], 1)
+	svc := s.AddTestingService(c, "timeouts", ch)
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	curl, _ := svc.CharmURL()
+	err = unit.SetCharmURL(curl)
+	c.Assert(err, jc.ErrorIsNil)
+
+	for name, expected := range map[string]time.Duration{
+		"slow": 10 * time.Minute,
+		"fast": 30 * time.Second,
+		"none": 0,
+	} {
+		a, err := unit.AddAction(name, nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(a.Timeout(), gc.Equals, expected, gc.Commentf("action %q", name))
+	}
+
+	_, err = unit.AddAction("bad", nil)
+	c.Assert(err, gc.ErrorMatches, `action "bad": timeout "soon" not valid`)
+}
+
 func (s *ActionSuite) TestFindActionTagsByPrefix(c *gc.C) {
 	prefix := "feedbeef"
 	uuidMock := uuidMockHelper{}
@@ -865,6 +1049,7 @@ func (r mockAR) Actions() ([]*state.Action, error)                 { return nil,
 func (r mockAR) CompletedActions() ([]*state.Action, error)        { return nil, nil }
 func (r mockAR) PendingActions() ([]*state.Action, error)          { return nil, nil }
 func (r mockAR) RunningActions() ([]*state.Action, error)          { return nil, nil }
+func (r mockAR) QueuedActions() ([]*state.Action, error)           { return nil, nil }
 func (r mockAR) Tag() names.Tag                                    { return names.NewUnitTag(r.id) }
 
 // TestMock verifies the mock UUID generator works as expected.
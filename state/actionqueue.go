@@ -0,0 +1,225 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// actionQueueDoc records how many of a receiver's actions have been
+// dispatched to it: that is, how many have notifications, so that the
+// receiver's agent may run them, and have not yet finished. Actions
+// enqueued while the receiver has no free slot wait, marked as queued,
+// until earlier ones finish.
+//
+// Every change to a receiver's queue updates its actionQueueDoc, so
+// that transactions changing the queue can assert that it has not
+// changed since it was read.
+type actionQueueDoc struct {
+	DocId      string `bson:"_id"`
+	EnvUUID    string `bson:"env-uuid"`
+	Receiver   string `bson:"receiver"`
+	Dispatched int    `bson:"dispatched"`
+	TxnRevno   int64  `bson:"txn-revno"`
+}
+
+// actionQueue returns the queue document of the given receiver, or
+// nil if none has been created yet.
+func (st *State) actionQueue(receiver string) (*actionQueueDoc, error) {
+	queues, closer := st.getCollection(actionQueuesC)
+	defer closer()
+
+	var doc actionQueueDoc
+	err := queues.FindId(receiver).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get action queue of %q", receiver)
+	}
+	return &doc, nil
+}
+
+// queuedActions returns the actions waiting in the given receiver's
+// queue, oldest first.
+func (st *State) queuedActions(receiver string) ([]actionDoc, error) {
+	actions, closer := st.getCollection(actionsC)
+	defer closer()
+
+	var docs []actionDoc
+	err := actions.Find(bson.D{
+		{"receiver", receiver},
+		{"status", ActionPending},
+		{"queued", true},
+	}).Sort("queue-seq").All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get queued actions of %q", receiver)
+	}
+	return docs, nil
+}
+
+// actionParallelism returns the number of a receiver's actions that
+// may be dispatched at once; zero means no limit.
+func (st *State) actionParallelism() (int, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return cfg.ActionParallelism(), nil
+}
+
+// freeActionSlots returns how many of want actions may be dispatched to
+// a receiver with the given number of dispatched actions.
+func freeActionSlots(limit, dispatched, want int) int {
+	if limit == 0 {
+		return want
+	}
+	free := limit - dispatched
+	if free < 0 {
+		free = 0
+	}
+	if free > want {
+		free = want
+	}
+	return free
+}
+
+// dispatchActionOps returns the operations that dispatch the given
+// queued action to its receiver.
+func (st *State) dispatchActionOps(doc actionDoc) []txn.Op {
+	ndoc := newActionNotificationDoc(st, doc.Receiver, st.localID(doc.DocId))
+	return []txn.Op{{
+		C:  actionsC,
+		Id: doc.DocId,
+		Assert: bson.D{
+			{"status", ActionPending},
+			{"queued", true},
+		},
+		Update: bson.D{{"$unset", bson.D{
+			{"queued", nil},
+			{"queue-seq", nil},
+		}}},
+	}, {
+		C:      actionNotificationsC,
+		Id:     ndoc.DocId,
+		Assert: txn.DocMissing,
+		Insert: ndoc,
+	}}
+}
+
+// enqueueActionOps returns the operations that add the given action to
+// its receiver's queue, dispatching it if the receiver has a free slot
+// and no older action is waiting. The action's Queued and QueueSeq
+// fields are set accordingly.
+func (st *State) enqueueActionOps(doc *actionDoc) ([]txn.Op, error) {
+	limit, err := st.actionParallelism()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	queue, err := st.actionQueue(doc.Receiver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var queued []actionDoc
+	dispatched := 0
+	if queue != nil {
+		if queued, err = st.queuedActions(doc.Receiver); err != nil {
+			return nil, errors.Trace(err)
+		}
+		dispatched = queue.Dispatched
+	}
+
+	// Older queued actions are dispatched before the new one.
+	slots := freeActionSlots(limit, dispatched, len(queued)+1)
+	var ops []txn.Op
+	for i := 0; i < slots && i < len(queued); i++ {
+		ops = append(ops, st.dispatchActionOps(queued[i])...)
+	}
+	doc.Queued = slots <= len(queued)
+	doc.QueueSeq = 0
+	if doc.Queued {
+		if doc.QueueSeq, err = st.sequence("actionqueue"); err != nil {
+			return nil, errors.Trace(err)
+		}
+		// Sequences start at zero, which would be omitted.
+		doc.QueueSeq++
+	} else {
+		ndoc := newActionNotificationDoc(st, doc.Receiver, st.localID(doc.DocId))
+		ops = append(ops, txn.Op{
+			C:      actionNotificationsC,
+			Id:     ndoc.DocId,
+			Assert: txn.DocMissing,
+			Insert: ndoc,
+		})
+	}
+	return append(ops, st.updateActionQueueOp(queue, doc.Receiver, dispatched+slots)), nil
+}
+
+// releaseActionOps returns the operations that take the given action,
+// which is finishing, off its receiver's queue, and dispatch the oldest
+// queued actions into any slots that become free.
+func (st *State) releaseActionOps(doc actionDoc) ([]txn.Op, error) {
+	queue, err := st.actionQueue(doc.Receiver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if queue == nil {
+		// The action was enqueued before receivers had queues, and
+		// nothing has been queued since.
+		return nil, nil
+	}
+	limit, err := st.actionParallelism()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	queued, err := st.queuedActions(doc.Receiver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dispatched := queue.Dispatched
+	if !doc.Queued && dispatched > 0 {
+		dispatched--
+	}
+
+	var ops []txn.Op
+	for _, qdoc := range queued {
+		if qdoc.DocId == doc.DocId {
+			continue
+		}
+		if freeActionSlots(limit, dispatched, 1) == 0 {
+			break
+		}
+		ops = append(ops, st.dispatchActionOps(qdoc)...)
+		dispatched++
+	}
+	return append(ops, st.updateActionQueueOp(queue, doc.Receiver, dispatched)), nil
+}
+
+// updateActionQueueOp returns the operation that records the number of
+// the receiver's dispatched actions, creating its queue document if
+// queue is nil.
+func (st *State) updateActionQueueOp(queue *actionQueueDoc, receiver string, dispatched int) txn.Op {
+	if queue == nil {
+		return txn.Op{
+			C:      actionQueuesC,
+			Id:     st.docID(receiver),
+			Assert: txn.DocMissing,
+			Insert: &actionQueueDoc{
+				DocId:      st.docID(receiver),
+				EnvUUID:    st.EnvironUUID(),
+				Receiver:   receiver,
+				Dispatched: dispatched,
+			},
+		}
+	}
+	return txn.Op{
+		C:      actionQueuesC,
+		Id:     queue.DocId,
+		Assert: bson.D{{"txn-revno", queue.TxnRevno}},
+		Update: bson.D{{"$set", bson.D{{"dispatched", dispatched}}}},
+	}
+}
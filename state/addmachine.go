@@ -576,8 +576,22 @@ func (st *State) machineStorageOps(
 		})
 	}
 
-	// TODO(axw) handle args.filesystemAttachments, args.volumeAttachments
-	// when we handle attaching to existing (e.g. shared) storage.
+	// Create attachments to existing volumes, such as volumes that have
+	// been detached from another unit's machine.
+	for tag, params := range args.volumeAttachments {
+		volumeOps = append(volumeOps, txn.Op{
+			C:      volumesC,
+			Id:     tag.Id(),
+			Assert: isAliveDoc,
+			Update: bson.D{{"$inc", bson.D{{"attachmentcount", 1}}}},
+		})
+		volumeAttachments = append(volumeAttachments, volumeAttachmentTemplate{
+			tag, params,
+		})
+	}
+
+	// TODO(axw) handle args.filesystemAttachments when we handle
+	// attaching to existing (e.g. shared) storage.
 
 	ops := make([]txn.Op, 0, len(filesystemOps)+len(volumeOps)+len(fsAttachments)+len(volumeAttachments))
 	if len(fsAttachments) > 0 {
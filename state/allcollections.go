@@ -130,6 +130,26 @@ func allCollections() collectionSchema {
 		// was implemented.
 		actionresultsC: {global: true},
 
+		// This collection holds the offers of services' endpoints for
+		// use by services in other environments.
+		serviceOffersC: {
+			global: true,
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "service"},
+			}},
+		},
+
+		// This collection records which environments consume which
+		// offers, and through which remote services.
+		offerConnectionsC: {
+			global: true,
+			indexes: []mgo.Index{{
+				Key: []string{"offer-env-uuid"},
+			}, {
+				Key: []string{"consumer-env-uuid"},
+			}},
+		},
+
 		// -----------------
 
 		// Local collections
@@ -186,6 +206,20 @@ func allCollections() collectionSchema {
 		},
 		minUnitsC: {},
 
+		// This collection holds the services offered from other
+		// environments that are consumed by this one.
+		remoteServicesC: {},
+
+		// These collections hold the revisions of the resources
+		// attached to services, and the revisions each service's
+		// units are pinned to.
+		resourcesC: {
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "service", "name"},
+			}},
+		},
+		resourcePinsC: {},
+
 		// meterStatusC is the collection used to store meter status information.
 		meterStatusC:  {},
 		settingsrefsC: {},
@@ -205,6 +239,7 @@ func allCollections() collectionSchema {
 		instanceDataC:  {},
 		machinesC:      {},
 		rebootC:        {},
+		sshHostKeysC:   {},
 
 		// -----
 
@@ -272,6 +307,10 @@ func allCollections() collectionSchema {
 		},
 		openedPortsC:       {},
 		requestedNetworksC: {},
+
+		// This collection holds the network space to which each of a
+		// service's endpoints is bound.
+		endpointBindingsC: {},
 		subnetsC: {
 			indexes: []mgo.Index{{
 				// TODO(dimitern): make unique per-environment, not globally.
@@ -288,6 +327,23 @@ func allCollections() collectionSchema {
 		// These collections hold information associated with actions.
 		actionsC:             {},
 		actionNotificationsC: {},
+		actionQueuesC:        {},
+
+		// This collection holds the results of commands run on
+		// machines and units with "juju run". Its documents are
+		// written without transactions, and pruned as history.
+		runResultsC: {
+			indexes: []mgo.Index{{
+				// Used when pruning the history.
+				Key: []string{"env-uuid", "started"},
+			}},
+		},
+
+		// -----
+
+		// This collection holds the key/value state stored by each
+		// unit's charm.
+		unitStatesC: {},
 
 		// -----
 
@@ -312,6 +368,9 @@ func allCollections() collectionSchema {
 		statusesHistoryC: {
 			indexes: []mgo.Index{{
 				Key: []string{"env-uuid", "globalkey"},
+			}, {
+				// Used when pruning the history.
+				Key: []string{"env-uuid", "updated"},
 			}},
 		},
 		spacesC: {},
@@ -325,6 +384,43 @@ func allCollections() collectionSchema {
 		// ======================
 
 		// metrics; status-history; logs; ..?
+
+		// This collection holds a record of the calls made to the API
+		// servers, for all environments. Entries expire after
+		// auditLogMaxAge.
+		auditLogC: {
+			global:    true,
+			rawAccess: true,
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "-time"},
+			}, {
+				Key: []string{"caller", "-time"},
+			}, {
+				Key:         []string{"created"},
+				ExpireAfter: auditLogMaxAge,
+			}},
+		},
+
+		// This collection holds the relation settings that units publish
+		// across offer connections, for units in the other environment
+		// to read.
+		remoteSettingsC: {
+			global:    true,
+			rawAccess: true,
+			indexes: []mgo.Index{{
+				Key: []string{"connection", "side"},
+			}},
+		},
+
+		// This collection holds the bearer tokens issued to let
+		// external systems call the API on behalf of users.
+		authTokensC: {
+			global:    true,
+			rawAccess: true,
+			indexes: []mgo.Index{{
+				Key: []string{"user", "env-uuid"},
+			}},
+		},
 	}
 }
 
@@ -334,9 +430,12 @@ func allCollections() collectionSchema {
 // inspection.
 const (
 	actionNotificationsC   = "actionnotifications"
+	actionQueuesC          = "actionqueues"
 	actionresultsC         = "actionresults"
 	actionsC               = "actions"
 	annotationsC           = "annotations"
+	auditLogC              = "auditlog"
+	authTokensC            = "authtokens"
 	blockDevicesC          = "blockdevices"
 	blocksC                = "blocks"
 	charmsC                = "charms"
@@ -344,6 +443,7 @@ const (
 	cloudimagemetadataC    = "cloudimagemetadata"
 	constraintsC           = "constraints"
 	containerRefsC         = "containerRefs"
+	endpointBindingsC      = "endpointbindings"
 	envUsersC              = "envusers"
 	environmentsC          = "environments"
 	filesystemAttachmentsC = "filesystemAttachments"
@@ -359,16 +459,24 @@ const (
 	minUnitsC              = "minunits"
 	networkInterfacesC     = "networkinterfaces"
 	networksC              = "networks"
+	offerConnectionsC      = "offerconnections"
 	openedPortsC           = "openedPorts"
 	rebootC                = "reboot"
 	relationScopesC        = "relationscopes"
 	relationsC             = "relations"
+	remoteServicesC        = "remoteservices"
+	remoteSettingsC        = "remotesettings"
 	requestedNetworksC     = "requestednetworks"
+	resourcePinsC          = "resourcepins"
+	resourcesC             = "resources"
 	restoreInfoC           = "restoreInfo"
+	runResultsC            = "runresults"
 	sequenceC              = "sequence"
+	serviceOffersC         = "serviceoffers"
 	servicesC              = "services"
 	settingsC              = "settings"
 	settingsrefsC          = "settingsrefs"
+	sshHostKeysC           = "sshhostkeys"
 	stateServersC          = "stateServers"
 	statusesC              = "statuses"
 	statusesHistoryC       = "statuseshistory"
@@ -380,6 +488,7 @@ const (
 	toolsmetadataC         = "toolsmetadata"
 	txnLogC                = "txns.log"
 	txnsC                  = "txns"
+	unitStatesC            = "unitstates"
 	unitsC                 = "units"
 	upgradeInfoC           = "upgradeInfo"
 	userenvnameC           = "userenvname"
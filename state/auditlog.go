@@ -0,0 +1,152 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// auditLogMaxAge is how long entries are kept in the audit log; mongo
+// removes older entries through a TTL index on their creation time.
+const auditLogMaxAge = 30 * 24 * time.Hour
+
+// AuditEntry records a call made to the API server.
+type AuditEntry struct {
+	// Time is when the call was made.
+	Time time.Time
+
+	// EnvUUID identifies the environment the caller was connected to.
+	EnvUUID string
+
+	// Caller is the tag of the entity that made the call.
+	Caller string
+
+	// Facade, Version and Method identify the method called.
+	Facade  string
+	Version int
+	Method  string
+
+	// EntityTags holds the tags of the entities named in the call's
+	// parameters.
+	EntityTags []string
+
+	// Params holds the JSON encoding of the call's parameters, with
+	// any sensitive values redacted.
+	Params string
+
+	// ResultCode is "ok" if the call succeeded. Otherwise it holds the
+	// code of the error returned, or "error" if that has no code.
+	ResultCode string
+}
+
+// auditEntryDoc is the document used to store an AuditEntry. Time
+// holds nanoseconds since the epoch, for ordering; Created holds the
+// same time as a date, which the collection's TTL index requires.
+type auditEntryDoc struct {
+	Id         bson.ObjectId `bson:"_id"`
+	Time       int64         `bson:"time"`
+	Created    time.Time     `bson:"created"`
+	EnvUUID    string        `bson:"env-uuid"`
+	Caller     string        `bson:"caller"`
+	Facade     string        `bson:"facade"`
+	Version    int           `bson:"version"`
+	Method     string        `bson:"method"`
+	EntityTags []string      `bson:"entity-tags,omitempty"`
+	Params     string        `bson:"params,omitempty"`
+	ResultCode string        `bson:"result-code"`
+}
+
+// AddAuditEntries records the given API calls in the audit log.
+func (st *State) AddAuditEntries(entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		docs[i] = &auditEntryDoc{
+			Id:         bson.NewObjectId(),
+			Time:       entry.Time.UnixNano(),
+			Created:    entry.Time,
+			EnvUUID:    entry.EnvUUID,
+			Caller:     entry.Caller,
+			Facade:     entry.Facade,
+			Version:    entry.Version,
+			Method:     entry.Method,
+			EntityTags: entry.EntityTags,
+			Params:     entry.Params,
+			ResultCode: entry.ResultCode,
+		}
+	}
+	auditLog, closer := st.getRawCollection(auditLogC)
+	defer closer()
+	if err := auditLog.Insert(docs...); err != nil {
+		return errors.Annotatef(err, "cannot add %d audit log entries", len(entries))
+	}
+	return nil
+}
+
+// AuditFilter selects entries from the audit log. Empty fields select
+// all entries.
+type AuditFilter struct {
+	EnvUUID string
+	Caller  string
+	Facade  string
+	Method  string
+
+	// Since selects entries recorded at or after the given time.
+	Since time.Time
+
+	// Limit is the maximum number of entries returned.
+	Limit int
+}
+
+// AuditEntries returns the entries in the audit log selected by the
+// filter, newest first.
+func (st *State) AuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	query := bson.D{}
+	if filter.EnvUUID != "" {
+		query = append(query, bson.DocElem{"env-uuid", filter.EnvUUID})
+	}
+	if filter.Caller != "" {
+		query = append(query, bson.DocElem{"caller", filter.Caller})
+	}
+	if filter.Facade != "" {
+		query = append(query, bson.DocElem{"facade", filter.Facade})
+	}
+	if filter.Method != "" {
+		query = append(query, bson.DocElem{"method", filter.Method})
+	}
+	if !filter.Since.IsZero() {
+		query = append(query, bson.DocElem{"time", bson.D{{"$gte", filter.Since.UnixNano()}}})
+	}
+
+	auditLog, closer := st.getRawCollection(auditLogC)
+	defer closer()
+	q := auditLog.Find(query).Sort("-time", "-_id")
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	var docs []auditEntryDoc
+	if err := q.All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot read audit log")
+	}
+	entries := make([]AuditEntry, len(docs))
+	for i, doc := range docs {
+		entries[i] = AuditEntry{
+			Time:       time.Unix(0, doc.Time).UTC(),
+			EnvUUID:    doc.EnvUUID,
+			Caller:     doc.Caller,
+			Facade:     doc.Facade,
+			Version:    doc.Version,
+			Method:     doc.Method,
+			EntityTags: doc.EntityTags,
+			Params:     doc.Params,
+			ResultCode: doc.ResultCode,
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type AuditLogSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&AuditLogSuite{})
+
+func (s *AuditLogSuite) TestAuditEntriesEmpty(c *gc.C) {
+	entries, err := s.State.AuditEntries(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 0)
+}
+
+func (s *AuditLogSuite) TestEntriesExpire(c *gc.C) {
+	coll := s.State.MongoSession().DB("juju").C(state.AuditLogC)
+	indexes, err := coll.Indexes()
+	c.Assert(err, jc.ErrorIsNil)
+	for _, index := range indexes {
+		if len(index.Key) == 1 && index.Key[0] == "created" {
+			c.Assert(index.ExpireAfter, gc.Equals, state.AuditLogMaxAge)
+			return
+		}
+	}
+	c.Fatalf("no TTL index on audit log: %+v", indexes)
+}
+
+func (s *AuditLogSuite) TestAddAuditEntries(c *gc.C) {
+	now := time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC)
+	added := []state.AuditEntry{{
+		Time:       now,
+		EnvUUID:    "env-a",
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Version:    0,
+		Method:     "FullStatus",
+		ResultCode: "ok",
+	}, {
+		Time:       now.Add(time.Second),
+		EnvUUID:    "env-a",
+		Caller:     "machine-0",
+		Facade:     "Machiner",
+		Version:    0,
+		Method:     "SetStatus",
+		EntityTags: []string{"machine-0"},
+		Params:     `{"Entities":[{"Tag":"machine-0"}]}`,
+		ResultCode: "ok",
+	}, {
+		Time:       now.Add(2 * time.Second),
+		EnvUUID:    "env-b",
+		Caller:     "user-admin",
+		Facade:     "Client",
+		Version:    0,
+		Method:     "AddMachines",
+		ResultCode: "unauthorized access",
+	}}
+	err := s.State.AddAuditEntries(added)
+	c.Assert(err, jc.ErrorIsNil)
+
+	entries, err := s.State.AuditEntries(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, jc.DeepEquals, []state.AuditEntry{added[2], added[1], added[0]})
+}
+
+func (s *AuditLogSuite) TestAuditEntriesFilter(c *gc.C) {
+	now := time.Date(2015, 9, 1, 12, 0, 0, 0, time.UTC)
+	var added []state.AuditEntry
+	for i, caller := range []string{"user-admin", "machine-0", "user-admin", "user-bob"} {
+		added = append(added, state.AuditEntry{
+			Time:       now.Add(time.Duration(i) * time.Minute),
+			EnvUUID:    "env-a",
+			Caller:     caller,
+			Facade:     "Client",
+			Method:     "FullStatus",
+			ResultCode: "ok",
+		})
+	}
+	added[3].EnvUUID = "env-b"
+	added[2].Method = "AddMachines"
+	err := s.State.AddAuditEntries(added)
+	c.Assert(err, jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		about    string
+		filter   state.AuditFilter
+		expected []state.AuditEntry
+	}{{
+		about:    "environment",
+		filter:   state.AuditFilter{EnvUUID: "env-b"},
+		expected: added[3:],
+	}, {
+		about:    "caller",
+		filter:   state.AuditFilter{Caller: "user-admin"},
+		expected: []state.AuditEntry{added[2], added[0]},
+	}, {
+		about:    "method",
+		filter:   state.AuditFilter{Facade: "Client", Method: "FullStatus"},
+		expected: []state.AuditEntry{added[3], added[1], added[0]},
+	}, {
+		about:    "since",
+		filter:   state.AuditFilter{Since: now.Add(2 * time.Minute)},
+		expected: []state.AuditEntry{added[3], added[2]},
+	}, {
+		about:    "limit",
+		filter:   state.AuditFilter{EnvUUID: "env-a", Limit: 2},
+		expected: []state.AuditEntry{added[2], added[1]},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		entries, err := s.State.AuditEntries(test.filter)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(entries, jc.DeepEquals, test.expected)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// AuthToken records a bearer token issued to let an external system
+// call the API as a user, with a limited set of permissions.
+type AuthToken struct {
+	// Id uniquely identifies the token.
+	Id string
+
+	// User is the tag of the user the token acts for.
+	User string
+
+	// EnvUUID identifies the environment the token may be used with.
+	EnvUUID string
+
+	// Scopes holds the facades, or facade methods, that the token
+	// permits calls to.
+	Scopes []string
+
+	// Issued and Expires hold when the token was issued and when it
+	// stops being valid.
+	Issued  time.Time
+	Expires time.Time
+
+	// Revoked is true if the token has been revoked before it
+	// expired.
+	Revoked bool
+}
+
+// authTokenDoc is the document used to store an AuthToken.
+type authTokenDoc struct {
+	Id      string   `bson:"_id"`
+	User    string   `bson:"user"`
+	EnvUUID string   `bson:"env-uuid"`
+	Scopes  []string `bson:"scopes"`
+	Issued  int64    `bson:"issued"`
+	Expires int64    `bson:"expires"`
+	Revoked bool     `bson:"revoked"`
+}
+
+func (doc *authTokenDoc) token() AuthToken {
+	return AuthToken{
+		Id:      doc.Id,
+		User:    doc.User,
+		EnvUUID: doc.EnvUUID,
+		Scopes:  doc.Scopes,
+		Issued:  time.Unix(0, doc.Issued).UTC(),
+		Expires: time.Unix(0, doc.Expires).UTC(),
+		Revoked: doc.Revoked,
+	}
+}
+
+// AddAuthToken records a new token that lets the given user call the
+// given scopes of the API in this environment until it expires.
+func (st *State) AddAuthToken(user names.UserTag, scopes []string, expires time.Time) (AuthToken, error) {
+	if len(scopes) == 0 {
+		return AuthToken{}, errors.New("cannot add auth token: no scopes specified")
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return AuthToken{}, errors.Trace(err)
+	}
+	doc := &authTokenDoc{
+		Id:      uuid.String(),
+		User:    user.String(),
+		EnvUUID: st.EnvironUUID(),
+		Scopes:  scopes,
+		Issued:  nowToTheSecond().UnixNano(),
+		Expires: expires.UnixNano(),
+	}
+	authTokens, closer := st.getRawCollection(authTokensC)
+	defer closer()
+	if err := authTokens.Insert(doc); err != nil {
+		return AuthToken{}, errors.Annotate(err, "cannot add auth token")
+	}
+	return doc.token(), nil
+}
+
+// AuthToken returns the token with the given id.
+func (st *State) AuthToken(id string) (AuthToken, error) {
+	authTokens, closer := st.getRawCollection(authTokensC)
+	defer closer()
+	var doc authTokenDoc
+	err := authTokens.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return AuthToken{}, errors.NotFoundf("auth token %q", id)
+	} else if err != nil {
+		return AuthToken{}, errors.Annotatef(err, "cannot get auth token %q", id)
+	}
+	return doc.token(), nil
+}
+
+// AuthTokens returns the tokens issued for the given user in this
+// environment, oldest first.
+func (st *State) AuthTokens(user names.UserTag) ([]AuthToken, error) {
+	authTokens, closer := st.getRawCollection(authTokensC)
+	defer closer()
+	var docs []authTokenDoc
+	query := bson.D{{"user", user.String()}, {"env-uuid", st.EnvironUUID()}}
+	if err := authTokens.Find(query).Sort("issued", "_id").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get auth tokens for %s", user)
+	}
+	tokens := make([]AuthToken, len(docs))
+	for i, doc := range docs {
+		tokens[i] = doc.token()
+	}
+	return tokens, nil
+}
+
+// RevokeAuthToken marks the token with the given id as revoked, so it
+// can no longer be used.
+func (st *State) RevokeAuthToken(id string) error {
+	authTokens, closer := st.getRawCollection(authTokensC)
+	defer closer()
+	err := authTokens.UpdateId(id, bson.D{{"$set", bson.D{{"revoked", true}}}})
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("auth token %q", id)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot revoke auth token %q", id)
+	}
+	return nil
+}
+
+// RemoveExpiredAuthTokens removes the records of all tokens that
+// expired before the given time.
+func (st *State) RemoveExpiredAuthTokens(before time.Time) error {
+	authTokens, closer := st.getRawCollection(authTokensC)
+	defer closer()
+	_, err := authTokens.RemoveAll(bson.D{{"expires", bson.D{{"$lt", before.UnixNano()}}}})
+	return errors.Annotate(err, "cannot remove expired auth tokens")
+}
+
+const authTokenKeyKey = "authTokenKey"
+
+// authTokenKeyDoc holds the key that state servers use to sign and
+// verify auth tokens.
+type authTokenKeyDoc struct {
+	Key []byte `bson:"key"`
+}
+
+// authTokenKeySize is the size, in bytes, of the key used to sign
+// auth tokens.
+const authTokenKeySize = 32
+
+// AuthTokenKey returns the key used to sign and verify auth tokens,
+// creating it if it does not already exist.
+func (st *State) AuthTokenKey() ([]byte, error) {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		_, err := st.authTokenKey()
+		if err == nil {
+			return nil, jujutxn.ErrNoOperations
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+		key, err := utils.RandomBytes(authTokenKeySize)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{{
+			C:      stateServersC,
+			Id:     authTokenKeyKey,
+			Assert: txn.DocMissing,
+			Insert: &authTokenKeyDoc{Key: key},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, errors.Annotate(err, "cannot create auth token key")
+	}
+	return st.authTokenKey()
+}
+
+func (st *State) authTokenKey() ([]byte, error) {
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+	var doc authTokenKeyDoc
+	err := stateServers.Find(bson.D{{"_id", authTokenKeyKey}}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("auth token key")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return doc.Key, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type AuthTokenSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&AuthTokenSuite{})
+
+func (s *AuthTokenSuite) TestAuthTokenKey(c *gc.C) {
+	key, err := s.State.AuthTokenKey()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(key, gc.HasLen, 32)
+
+	// The key is only created once.
+	again, err := s.State.AuthTokenKey()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(again, jc.DeepEquals, key)
+}
+
+func (s *AuthTokenSuite) TestAddAuthToken(c *gc.C) {
+	user := names.NewUserTag("bob")
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	token, err := s.State.AddAuthToken(user, []string{"Client.FullStatus"}, expires)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Id, gc.Not(gc.Equals), "")
+	c.Assert(token.User, gc.Equals, "user-bob")
+	c.Assert(token.EnvUUID, gc.Equals, s.State.EnvironUUID())
+	c.Assert(token.Scopes, jc.DeepEquals, []string{"Client.FullStatus"})
+	c.Assert(token.Expires, gc.Equals, expires)
+	c.Assert(token.Revoked, jc.IsFalse)
+
+	got, err := s.State.AuthToken(token.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, token)
+
+	tokens, err := s.State.AuthTokens(user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, jc.DeepEquals, []state.AuthToken{token})
+
+	tokens, err = s.State.AuthTokens(names.NewUserTag("mary"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 0)
+}
+
+func (s *AuthTokenSuite) TestAddAuthTokenNoScopes(c *gc.C) {
+	_, err := s.State.AddAuthToken(names.NewUserTag("bob"), nil, time.Now().Add(time.Hour))
+	c.Assert(err, gc.ErrorMatches, "cannot add auth token: no scopes specified")
+}
+
+func (s *AuthTokenSuite) TestAuthTokenNotFound(c *gc.C) {
+	_, err := s.State.AuthToken("missing")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.State.RevokeAuthToken("missing")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *AuthTokenSuite) TestRevokeAuthToken(c *gc.C) {
+	token, err := s.State.AddAuthToken(names.NewUserTag("bob"), []string{"Client"}, time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RevokeAuthToken(token.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	token, err = s.State.AuthToken(token.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Revoked, jc.IsTrue)
+}
+
+func (s *AuthTokenSuite) TestRemoveExpiredAuthTokens(c *gc.C) {
+	now := time.Now()
+	user := names.NewUserTag("bob")
+	expired, err := s.State.AddAuthToken(user, []string{"Client"}, now.Add(-time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	valid, err := s.State.AddAuthToken(user, []string{"Client"}, now.Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RemoveExpiredAuthTokens(now)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AuthToken(expired.Id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.AuthToken(valid.Id)
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -35,6 +35,10 @@ type charmDoc struct {
 	Actions *charm.Actions `bson:"actions"`
 	Metrics *charm.Metrics `bson:"metrics"`
 
+	// Resources holds the resources declared in the charm's
+	// metadata, which the charm package does not know about.
+	Resources map[string]ResourceMeta `bson:"resources,omitempty"`
+
 	// DEPRECATED: BundleURL is deprecated, and exists here
 	// only for migration purposes. We should remove this
 	// when migrations are no longer necessary.
@@ -51,6 +55,10 @@ type charmDoc struct {
 func insertCharmOps(
 	st *State, ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string,
 ) ([]txn.Op, error) {
+	resources, err := readCharmResourceMetas(ch)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read charm resources")
+	}
 	return insertAnyCharmOps(&charmDoc{
 		DocID:        curl.String(),
 		URL:          curl,
@@ -59,6 +67,7 @@ func insertCharmOps(
 		Config:       safeConfig(ch),
 		Metrics:      ch.Metrics(),
 		Actions:      ch.Actions(),
+		Resources:    resources,
 		BundleSha256: bundleSha256,
 		StoragePath:  storagePath,
 	})
@@ -105,11 +114,16 @@ func updateCharmOps(
 	st *State, ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string, assert bson.D,
 ) ([]txn.Op, error) {
 
+	resources, err := readCharmResourceMetas(ch)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read charm resources")
+	}
 	updateFields := bson.D{{"$set", bson.D{
 		{"meta", ch.Meta()},
 		{"config", safeConfig(ch)},
 		{"actions", ch.Actions()},
 		{"metrics", ch.Metrics()},
+		{"resources", resources},
 		{"storagepath", storagePath},
 		{"bundlesha256", bundleSha256},
 		{"pendingupload", false},
@@ -261,6 +275,12 @@ func (c *Charm) Actions() *charm.Actions {
 	return c.doc.Actions
 }
 
+// Resources returns the resources declared in the charm's metadata,
+// keyed by name.
+func (c *Charm) Resources() map[string]ResourceMeta {
+	return c.doc.Resources
+}
+
 // StoragePath returns the storage path of the charm bundle.
 func (c *Charm) StoragePath() string {
 	return c.doc.StoragePath
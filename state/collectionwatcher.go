@@ -0,0 +1,234 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"reflect"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/mgo.v2/bson"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/state/watcher"
+)
+
+// collectionWatcherConfig describes the documents watched by a
+// collectionWatcher, and how their ids are reported.
+type collectionWatcherConfig struct {
+	// collName is the name of the collection to watch. For
+	// per-environment collections, only documents in the State's
+	// environment are watched.
+	collName string
+
+	// filter, if not nil, is called with the _id of each changed
+	// document, and returns whether the change is of interest.
+	filter func(key interface{}) bool
+
+	// query, if not nil, selects the documents of interest. A
+	// document that stops matching the query is reported as if it
+	// were removed. It must not select on _id.
+	query bson.D
+
+	// fields, if not empty, holds the names of the fields of
+	// interest: changes to a document that leave all of them
+	// unchanged are not reported. If empty, all changes to the
+	// documents of interest are reported.
+	fields []string
+
+	// idconv, if not nil, converts the local id of each changed
+	// document to the value reported. Documents whose ids cannot be
+	// converted are logged and not reported.
+	idconv func(localID string) (string, error)
+}
+
+// collectionWatcher is a StringsWatcher that reports the ids of the
+// documents in a collection that are added, removed or changed. The
+// first event holds the ids of all the documents of interest.
+//
+// It saves writing a new watcher for each kind of document whose
+// changes are reported by id. Changes are coalesced: while the
+// receiver is not reading events, the ids of further changes are
+// merged into the pending event, so a slow receiver holds back at
+// most one id per document rather than a queue of events.
+type collectionWatcher struct {
+	commonWatcher
+	collectionWatcherConfig
+
+	// known holds the fields of interest of each document of
+	// interest, by _id.
+	known map[string]bson.M
+	out   chan []string
+}
+
+var _ StringsWatcher = (*collectionWatcher)(nil)
+
+// newCollectionWatcher starts and returns a watcher of the documents
+// described by config.
+func newCollectionWatcher(st *State, config collectionWatcherConfig) StringsWatcher {
+	if !allCollections()[config.collName].global {
+		filter := config.filter
+		config.filter = func(key interface{}) bool {
+			if !st.isForStateEnv(key) {
+				return false
+			}
+			return filter == nil || filter(key)
+		}
+	}
+	w := &collectionWatcher{
+		commonWatcher:           commonWatcher{st: st},
+		collectionWatcherConfig: config,
+		known:                   make(map[string]bson.M),
+		out:                     make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for the collectionWatcher.
+func (w *collectionWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+// report adds the value reported for the document with the given _id
+// to ids.
+func (w *collectionWatcher) report(ids set.Strings, docID string) {
+	id := w.st.localID(docID)
+	if w.idconv != nil {
+		var err error
+		if id, err = w.idconv(id); err != nil {
+			logger.Errorf("cannot report change to %s document %q: %v", w.collName, docID, err)
+			return
+		}
+	}
+	ids.Add(id)
+}
+
+// find returns the fields of interest of the documents of interest
+// with the given ids, or of all of them if ids is nil, by _id.
+func (w *collectionWatcher) find(ids []string) (map[string]bson.M, error) {
+	coll, closer := w.st.getCollection(w.collName)
+	defer closer()
+
+	var query bson.D
+	if ids != nil {
+		query = bson.D{{"_id", bson.D{{"$in", ids}}}}
+	}
+	query = append(query, w.query...)
+	fields := bson.D{{"_id", 1}}
+	for _, field := range w.fields {
+		fields = append(fields, bson.DocElem{field, 1})
+	}
+
+	result := make(map[string]bson.M)
+	iter := coll.Find(query).Select(fields).Iter()
+	for {
+		doc := make(bson.M)
+		if !iter.Next(&doc) {
+			break
+		}
+		docID, ok := doc["_id"].(string)
+		if !ok {
+			return nil, errors.Errorf("id is not of type string, got %T", doc["_id"])
+		}
+		delete(doc, "_id")
+		result[docID] = doc
+	}
+	return result, iter.Close()
+}
+
+func (w *collectionWatcher) initial() (set.Strings, error) {
+	docs, err := w.find(nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ids := make(set.Strings)
+	for docID, fields := range docs {
+		if w.filter != nil && !w.filter(docID) {
+			continue
+		}
+		w.known[docID] = fields
+		w.report(ids, docID)
+	}
+	return ids, nil
+}
+
+func (w *collectionWatcher) merge(ids set.Strings, updates map[interface{}]bool) error {
+	var changed []string
+	latest := make(map[string]bson.M)
+	for docID, exists := range updates {
+		docID, ok := docID.(string)
+		if !ok {
+			return errors.Errorf("id is not of type string, got %T", docID)
+		}
+		if exists {
+			changed = append(changed, docID)
+		}
+		// Documents not found below have been removed or no
+		// longer match the query.
+		latest[docID] = nil
+	}
+	if len(changed) > 0 {
+		docs, err := w.find(changed)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for docID, fields := range docs {
+			latest[docID] = fields
+		}
+	}
+
+	for docID, fields := range latest {
+		old, known := w.known[docID]
+		switch {
+		case fields == nil && !known:
+			continue
+		case fields == nil:
+			delete(w.known, docID)
+		case known && len(w.fields) > 0 && reflect.DeepEqual(old, fields):
+			continue
+		default:
+			w.known[docID] = fields
+		}
+		w.report(ids, docID)
+	}
+	return nil
+}
+
+func (w *collectionWatcher) loop() error {
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollectionWithFilter(w.collName, in, w.filter)
+	defer w.st.watcher.UnwatchCollection(w.collName, in)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			updates, ok := collect(ch, in, w.tomb.Dying())
+			if !ok {
+				return tomb.ErrDying
+			}
+			if err := w.merge(ids, updates); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.SortedValues():
+			ids = make(set.Strings)
+			out = nil
+		}
+	}
+}
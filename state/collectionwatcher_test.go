@@ -0,0 +1,131 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type CollectionWatcherSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&CollectionWatcherSuite{})
+
+func (s *CollectionWatcherSuite) addMachine(c *gc.C, series string) *state.Machine {
+	m, err := s.State.AddMachine(series, state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	return m
+}
+
+func (s *CollectionWatcherSuite) TestWatchFields(c *gc.C) {
+	m0 := s.addMachine(c, "quantal")
+	s.addMachine(c, "trusty")
+	w := state.NewCollectionWatcher(s.State, state.CollectionWatcherConfig{
+		CollName: state.MachinesC,
+		Query:    bson.D{{"series", "quantal"}},
+		Fields:   []string{"life"},
+		IdConv:   func(id string) (string, error) { return "machine-" + id, nil },
+	})
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange("machine-0")
+	wc.AssertNoChange()
+
+	// Changes to other fields are not reported.
+	err := m0.SetPassword("loooooooooooooooooooooooooong")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	err = m0.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.addMachine(c, "quantal")
+	s.addMachine(c, "trusty")
+	wc.AssertChange("machine-0", "machine-2")
+	wc.AssertNoChange()
+
+	err = m0.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m0.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("machine-0")
+	wc.AssertNoChange()
+}
+
+func (s *CollectionWatcherSuite) TestWatchAllChanges(c *gc.C) {
+	m0 := s.addMachine(c, "quantal")
+	w := state.NewCollectionWatcher(s.State, state.CollectionWatcherConfig{
+		CollName: state.MachinesC,
+	})
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+
+	err := m0.SetPassword("loooooooooooooooooooooooooong")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+}
+
+func (s *CollectionWatcherSuite) TestWatchFilter(c *gc.C) {
+	w := state.NewCollectionWatcher(s.State, state.CollectionWatcherConfig{
+		CollName: state.MachinesC,
+		Filter: func(key interface{}) bool {
+			return key.(string) != s.State.EnvironUUID()+":1"
+		},
+	})
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	s.addMachine(c, "quantal")
+	s.addMachine(c, "quantal")
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+}
+
+func (s *CollectionWatcherSuite) TestWatchIdConvError(c *gc.C) {
+	w := state.NewCollectionWatcher(s.State, state.CollectionWatcherConfig{
+		CollName: state.MachinesC,
+		IdConv: func(id string) (string, error) {
+			if id == "1" {
+				return "", errors.New("bad id")
+			}
+			return id, nil
+		},
+	})
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	s.addMachine(c, "quantal")
+	s.addMachine(c, "quantal")
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+}
+
+func (s *CollectionWatcherSuite) TestWatchOtherEnvironmentIgnored(c *gc.C) {
+	w := state.NewCollectionWatcher(s.State, state.CollectionWatcherConfig{
+		CollName: state.MachinesC,
+	})
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	_, err := st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+}
@@ -17,16 +17,20 @@ import (
 
 // constraintsDoc is the mongodb representation of a constraints.Value.
 type constraintsDoc struct {
-	EnvUUID      string `bson:"env-uuid"`
-	Arch         *string
-	CpuCores     *uint64
-	CpuPower     *uint64
-	Mem          *uint64
-	RootDisk     *uint64
-	InstanceType *string
-	Container    *instance.ContainerType
-	Tags         *[]string
-	Spaces       *[]string
+	EnvUUID        string `bson:"env-uuid"`
+	Arch           *string
+	CpuCores       *uint64
+	CpuPower       *uint64
+	Mem            *uint64
+	RootDisk       *uint64
+	InstanceType   *string
+	Container      *instance.ContainerType
+	Tags           *[]string
+	Spaces         *[]string
+	Zones          *[]string
+	RootDiskSource *string
+	VirtType       *string
+	InstanceRole   *string
 	// TODO(dimitern): Drop this once it's not possible to specify
 	// networks= in constraints.
 	Networks *[]string
@@ -34,32 +38,40 @@ type constraintsDoc struct {
 
 func (doc constraintsDoc) value() constraints.Value {
 	return constraints.Value{
-		Arch:         doc.Arch,
-		CpuCores:     doc.CpuCores,
-		CpuPower:     doc.CpuPower,
-		Mem:          doc.Mem,
-		RootDisk:     doc.RootDisk,
-		InstanceType: doc.InstanceType,
-		Container:    doc.Container,
-		Tags:         doc.Tags,
-		Spaces:       doc.Spaces,
-		Networks:     doc.Networks,
+		Arch:           doc.Arch,
+		CpuCores:       doc.CpuCores,
+		CpuPower:       doc.CpuPower,
+		Mem:            doc.Mem,
+		RootDisk:       doc.RootDisk,
+		InstanceType:   doc.InstanceType,
+		Container:      doc.Container,
+		Tags:           doc.Tags,
+		Spaces:         doc.Spaces,
+		Zones:          doc.Zones,
+		RootDiskSource: doc.RootDiskSource,
+		VirtType:       doc.VirtType,
+		InstanceRole:   doc.InstanceRole,
+		Networks:       doc.Networks,
 	}
 }
 
 func newConstraintsDoc(st *State, cons constraints.Value) constraintsDoc {
 	return constraintsDoc{
-		EnvUUID:      st.EnvironUUID(),
-		Arch:         cons.Arch,
-		CpuCores:     cons.CpuCores,
-		CpuPower:     cons.CpuPower,
-		Mem:          cons.Mem,
-		RootDisk:     cons.RootDisk,
-		InstanceType: cons.InstanceType,
-		Container:    cons.Container,
-		Tags:         cons.Tags,
-		Spaces:       cons.Spaces,
-		Networks:     cons.Networks,
+		EnvUUID:        st.EnvironUUID(),
+		Arch:           cons.Arch,
+		CpuCores:       cons.CpuCores,
+		CpuPower:       cons.CpuPower,
+		Mem:            cons.Mem,
+		RootDisk:       cons.RootDisk,
+		InstanceType:   cons.InstanceType,
+		Container:      cons.Container,
+		Tags:           cons.Tags,
+		Spaces:         cons.Spaces,
+		Zones:          cons.Zones,
+		RootDiskSource: cons.RootDiskSource,
+		VirtType:       cons.VirtType,
+		InstanceRole:   cons.InstanceRole,
+		Networks:       cons.Networks,
 	}
 }
 
@@ -174,6 +174,18 @@ var setConstraintsTests = []struct {
 	effectiveServiceCons: "container=kvm arch=amd64",
 	effectiveUnitCons:    "container=kvm mem=8G arch=amd64",
 	effectiveMachineCons: "mem=8G arch=amd64",
+}, {
+	about:        "zones, root-disk-source, virt-type and instance-role are stored and merged",
+	consToSet:    "zones=az2 virt-type= instance-role=web",
+	consFallback: "zones=az1,az3 root-disk-source=ssd virt-type=hvm",
+
+	// service and machine constraints override the environment's
+	// values key by key, and an explicitly empty value clears the
+	// fallback, as for any other constraint.
+	effectiveEnvironCons: "zones=az1,az3 root-disk-source=ssd virt-type=hvm",
+	effectiveServiceCons: "zones=az2 virt-type= instance-role=web",
+	effectiveUnitCons:    "zones=az2 root-disk-source=ssd virt-type= instance-role=web",
+	effectiveMachineCons: "zones=az2 root-disk-source=ssd virt-type= instance-role=web",
 }}
 
 func (s *constraintsValidationSuite) TestMachineConstraints(c *gc.C) {
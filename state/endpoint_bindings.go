@@ -0,0 +1,146 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// endpointBindingsDoc records the network space to which each of a
+// service's charm relation endpoints is bound. The document ID is the
+// service's global key.
+type endpointBindingsDoc struct {
+	DocID   string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+
+	// Bindings maps endpoint names to space names. Endpoints that
+	// are not bound do not appear.
+	Bindings map[string]string `bson:"bindings"`
+}
+
+// readEndpointBindings returns the endpoint bindings document with
+// the given global key, or nil if there is none.
+func readEndpointBindings(st *State, key string) (*endpointBindingsDoc, error) {
+	endpointBindings, closer := st.getCollection(endpointBindingsC)
+	defer closer()
+
+	var doc endpointBindingsDoc
+	err := endpointBindings.FindId(key).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &doc, nil
+}
+
+// createEndpointBindingsOp returns the operation that records the
+// given bindings for the entity with the given global key.
+func createEndpointBindingsOp(st *State, key string, bindings map[string]string) txn.Op {
+	return txn.Op{
+		C:      endpointBindingsC,
+		Id:     st.docID(key),
+		Assert: txn.DocMissing,
+		Insert: &endpointBindingsDoc{
+			DocID:    st.docID(key),
+			EnvUUID:  st.EnvironUUID(),
+			Bindings: bindings,
+		},
+	}
+}
+
+func removeEndpointBindingsOp(st *State, key string) txn.Op {
+	return txn.Op{
+		C:      endpointBindingsC,
+		Id:     st.docID(key),
+		Remove: true,
+	}
+}
+
+// EndpointBindings returns the network space to which each of the
+// service's charm relation endpoints is bound. Endpoints that are not
+// bound to a space are not included.
+func (s *Service) EndpointBindings() (map[string]string, error) {
+	doc, err := readEndpointBindings(s.st, s.globalKey())
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get endpoint bindings for service %q", s)
+	}
+	bindings := make(map[string]string)
+	if doc != nil {
+		for endpoint, space := range doc.Bindings {
+			bindings[endpoint] = space
+		}
+	}
+	return bindings, nil
+}
+
+// SetEndpointBindings replaces the service's endpoint bindings. Each
+// key must name a relation endpoint of the service's charm, and each
+// value an existing space.
+func (s *Service) SetEndpointBindings(bindings map[string]string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set endpoint bindings for service %q", s)
+	ch, _, err := s.Charm()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := validateEndpointBindings(s.st, ch.Meta(), bindings); err != nil {
+		return errors.Trace(err)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if s.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		doc, err := readEndpointBindings(s.st, s.globalKey())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := []txn.Op{{
+			C:      servicesC,
+			Id:     s.doc.DocID,
+			Assert: bson.D{{"life", Alive}, {"charmurl", s.doc.CharmURL}},
+		}}
+		if doc == nil {
+			if len(bindings) == 0 {
+				return nil, jujutxn.ErrNoOperations
+			}
+			return append(ops, createEndpointBindingsOp(s.st, s.globalKey(), bindings)), nil
+		}
+		return append(ops, txn.Op{
+			C:      endpointBindingsC,
+			Id:     doc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"bindings", bindings}}}},
+		}), nil
+	}
+	return s.st.run(buildTxn)
+}
+
+// validateEndpointBindings returns an error if any of the bindings
+// does not name a relation endpoint defined in meta and an existing
+// space.
+func validateEndpointBindings(st *State, meta *charm.Meta, bindings map[string]string) error {
+	for endpoint, space := range bindings {
+		_, provides := meta.Provides[endpoint]
+		_, requires := meta.Requires[endpoint]
+		_, peers := meta.Peers[endpoint]
+		if !provides && !requires && !peers {
+			return errors.NotValidf("endpoint %q", endpoint)
+		}
+		if _, err := st.Space(space); err != nil {
+			return errors.Annotatef(err, "endpoint %q", endpoint)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type EndpointBindingsSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&EndpointBindingsSuite{})
+
+func (s *EndpointBindingsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err := s.State.AddSpace("db", nil, false)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSpace("public", nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EndpointBindingsSuite) TestEndpointBindingsEmpty(c *gc.C) {
+	bindings, err := s.service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, gc.HasLen, 0)
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindings(c *gc.C) {
+	err := s.service.SetEndpointBindings(map[string]string{
+		"db":  "db",
+		"url": "public",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	bindings, err := s.service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, jc.DeepEquals, map[string]string{
+		"db":  "db",
+		"url": "public",
+	})
+
+	err = s.service.SetEndpointBindings(map[string]string{"cache": "db"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	bindings, err = s.service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, jc.DeepEquals, map[string]string{"cache": "db"})
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindingsUnknownEndpoint(c *gc.C) {
+	err := s.service.SetEndpointBindings(map[string]string{"foo": "db"})
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "wordpress": endpoint "foo" not valid`)
+	c.Assert(errors.Cause(err), jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindingsUnknownSpace(c *gc.C) {
+	err := s.service.SetEndpointBindings(map[string]string{"db": "missing"})
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "wordpress": endpoint "db": space "missing" not found`)
+	c.Assert(errors.Cause(err), jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindingsDying(c *gc.C) {
+	err := s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetEndpointBindings(map[string]string{"db": "db"})
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "wordpress": .*`)
+}
+
+func (s *EndpointBindingsSuite) TestEndpointBindingsRemovedWithService(c *gc.C) {
+	err := s.service.SetEndpointBindings(map[string]string{"db": "db"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	bindings, err := s.service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, gc.HasLen, 0)
+}
+
+func (s *EndpointBindingsSuite) TestAddServiceWithBindings(c *gc.C) {
+	service, err := s.State.AddServiceWithArgs(state.AddServiceArgs{
+		Name:             "mysql",
+		Owner:            s.Owner.String(),
+		Charm:            s.AddTestingCharm(c, "mysql"),
+		EndpointBindings: map[string]string{"server": "db"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	bindings, err := service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, jc.DeepEquals, map[string]string{"server": "db"})
+}
+
+func (s *EndpointBindingsSuite) TestAddServiceWithInvalidBindings(c *gc.C) {
+	_, err := s.State.AddServiceWithArgs(state.AddServiceArgs{
+		Name:             "mysql",
+		Owner:            s.Owner.String(),
+		Charm:            s.AddTestingCharm(c, "mysql"),
+		EndpointBindings: map[string]string{"server": "missing"},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add service "mysql": endpoint "server": space "missing" not found`)
+
+	// The service was not added without its bindings.
+	_, err = s.State.Service("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -38,6 +38,12 @@ type environmentDoc struct {
 	// LatestAvailableTools is a string representing the newest version
 	// found while checking streams for new versions.
 	LatestAvailableTools string `bson:"available-tools,omitempty"`
+
+	// CredentialInvalid is true if the environment's cloud credentials
+	// were rejected by the cloud when they were last checked, for the
+	// reason given in CredentialInvalidReason.
+	CredentialInvalid       bool   `bson:"credential-invalid,omitempty"`
+	CredentialInvalidReason string `bson:"credential-invalid-reason,omitempty"`
 }
 
 // StateServerEnvironment returns the environment that was bootstrapped.
@@ -279,6 +285,41 @@ func (e *Environment) LatestToolsVersion() version.Number {
 	return v
 }
 
+// CredentialValid returns whether the environment's cloud credentials
+// were accepted by the cloud when they were last checked, and if not,
+// the reason they were rejected. Credentials that have never been
+// checked are considered valid.
+func (e *Environment) CredentialValid() (bool, string) {
+	return !e.doc.CredentialInvalid, e.doc.CredentialInvalidReason
+}
+
+// SetCredentialValid records whether the environment's cloud
+// credentials were accepted by the cloud, and if not, why not.
+func (e *Environment) SetCredentialValid(valid bool, reason string) error {
+	if valid {
+		reason = ""
+	}
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     e.doc.UUID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"credential-invalid", !valid},
+			{"credential-invalid-reason", reason},
+		}}},
+	}}
+	err := e.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("environment")
+	}
+	if err != nil {
+		return errors.Annotate(err, "cannot set credential validity")
+	}
+	e.doc.CredentialInvalid = !valid
+	e.doc.CredentialInvalidReason = reason
+	return nil
+}
+
 // globalKey returns the global database key for the environment.
 func (e *Environment) globalKey() string {
 	return environGlobalKey
@@ -135,6 +135,35 @@ func (s *EnvironSuite) TestStateServerEnvironment(c *gc.C) {
 	c.Assert(env.Life(), gc.Equals, state.Alive)
 }
 
+func (s *EnvironSuite) TestCredentialValid(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	valid, reason := env.CredentialValid()
+	c.Assert(valid, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "")
+
+	err = env.SetCredentialValid(false, "access key expired")
+	c.Assert(err, jc.ErrorIsNil)
+	valid, reason = env.CredentialValid()
+	c.Assert(valid, jc.IsFalse)
+	c.Assert(reason, gc.Equals, "access key expired")
+
+	env, err = s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	valid, reason = env.CredentialValid()
+	c.Assert(valid, jc.IsFalse)
+	c.Assert(reason, gc.Equals, "access key expired")
+
+	// The reason is cleared once the credentials are valid again.
+	err = env.SetCredentialValid(true, "ignored")
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	valid, reason = env.CredentialValid()
+	c.Assert(valid, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "")
+}
+
 func (s *EnvironSuite) TestStateServerEnvironmentAccessibleFromOtherEnvironments(c *gc.C) {
 	cfg, _ := s.createTestEnvConfig(c)
 	_, st, err := s.State.NewEnvironment(cfg, names.NewUserTag("test@remote"))
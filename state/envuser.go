@@ -31,6 +31,39 @@ type envUserDoc struct {
 	DisplayName string    `bson:"displayname"`
 	CreatedBy   string    `bson:"createdby"`
 	DateCreated time.Time `bson:"datecreated"`
+
+	// Access is empty for users added before access levels were
+	// introduced, who have admin access.
+	Access EnvironmentAccess `bson:"access,omitempty"`
+}
+
+// EnvironmentAccess is the level of access a user has to an
+// environment.
+type EnvironmentAccess string
+
+const (
+	// EnvironmentReadAccess allows a user to see the environment,
+	// but not change it.
+	EnvironmentReadAccess EnvironmentAccess = "read"
+
+	// EnvironmentWriteAccess allows a user to see and change the
+	// environment, but not who may access it.
+	EnvironmentWriteAccess EnvironmentAccess = "write"
+
+	// EnvironmentAdminAccess allows a user to do anything with the
+	// environment, including granting and revoking access to it.
+	// Admin access to the state server environment makes a user
+	// a system administrator.
+	EnvironmentAdminAccess EnvironmentAccess = "admin"
+)
+
+// Validate returns an error if the access level is not known.
+func (a EnvironmentAccess) Validate() error {
+	switch a {
+	case EnvironmentReadAccess, EnvironmentWriteAccess, EnvironmentAdminAccess:
+		return nil
+	}
+	return errors.NotValidf("environment access %q", a)
 }
 
 // envUserLastConnectionDoc is updated by the apiserver whenever the user
@@ -80,6 +113,37 @@ func (e *EnvironmentUser) DateCreated() time.Time {
 	return e.doc.DateCreated.UTC()
 }
 
+// Access returns the level of access the user has to the environment.
+func (e *EnvironmentUser) Access() EnvironmentAccess {
+	if e.doc.Access == "" {
+		return EnvironmentAdminAccess
+	}
+	return e.doc.Access
+}
+
+// SetAccess changes the level of access the user has to the
+// environment.
+func (e *EnvironmentUser) SetAccess(access EnvironmentAccess) error {
+	if err := access.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	ops := []txn.Op{{
+		C:      envUsersC,
+		Id:     envUserID(e.UserTag()),
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"access", access}}}},
+	}}
+	err := e.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("environment user %q", e.UserName())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot set access for %q", e.UserName())
+	}
+	e.doc.Access = access
+	return nil
+}
+
 // LastConnection returns when this EnvironmentUser last connected through the API
 // in UTC. The resulting time will be nil if the user has never logged in.
 func (e *EnvironmentUser) LastConnection() (time.Time, error) {
@@ -154,8 +218,18 @@ func (st *State) EnvironmentUser(user names.UserTag) (*EnvironmentUser, error) {
 	return envUser, nil
 }
 
-// AddEnvironmentUser adds a new user to the database.
+// AddEnvironmentUser adds a new user to the database, with admin access
+// to the environment.
 func (st *State) AddEnvironmentUser(user, createdBy names.UserTag, displayName string) (*EnvironmentUser, error) {
+	return st.AddEnvironmentUserWithAccess(user, createdBy, displayName, EnvironmentAdminAccess)
+}
+
+// AddEnvironmentUserWithAccess adds a new user to the database, with the
+// given level of access to the environment.
+func (st *State) AddEnvironmentUserWithAccess(user, createdBy names.UserTag, displayName string, access EnvironmentAccess) (*EnvironmentUser, error) {
+	if err := access.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
 	// Ensure local user exists in state before adding them as an environment user.
 	if user.IsLocal() {
 		localUser, err := st.User(user)
@@ -175,7 +249,7 @@ func (st *State) AddEnvironmentUser(user, createdBy names.UserTag, displayName s
 	}
 
 	envuuid := st.EnvironUUID()
-	op := createEnvUserOp(envuuid, user, createdBy, displayName)
+	op := createEnvUserOp(envuuid, user, createdBy, displayName, access)
 	err := st.runTransaction([]txn.Op{op})
 	if err == txn.ErrAborted {
 		err = errors.AlreadyExistsf("environment user %q", user.Canonical())
@@ -193,7 +267,7 @@ func envUserID(user names.UserTag) string {
 	return strings.ToLower(username)
 }
 
-func createEnvUserOp(envuuid string, user, createdBy names.UserTag, displayName string) txn.Op {
+func createEnvUserOp(envuuid string, user, createdBy names.UserTag, displayName string, access EnvironmentAccess) txn.Op {
 	creatorname := createdBy.Canonical()
 	doc := &envUserDoc{
 		ID:          envUserID(user),
@@ -202,6 +276,7 @@ func createEnvUserOp(envuuid string, user, createdBy names.UserTag, displayName
 		DisplayName: displayName,
 		CreatedBy:   creatorname,
 		DateCreated: nowToTheSecond(),
+		Access:      access,
 	}
 	return txn.Op{
 		C:      envUsersC,
@@ -283,8 +358,8 @@ func (st *State) EnvironmentsForUser(user names.UserTag) ([]*UserEnvironment, er
 	return result, nil
 }
 
-// IsSystemAdministrator returns true if the user specified has access to the
-// state server environment (the system environment).
+// IsSystemAdministrator returns true if the user specified has admin access
+// to the state server environment (the system environment).
 func (st *State) IsSystemAdministrator(user names.UserTag) (bool, error) {
 	ssinfo, err := st.StateServerInfo()
 	if err != nil {
@@ -299,6 +374,10 @@ func (st *State) IsSystemAdministrator(user names.UserTag) (bool, error) {
 	count, err := envUsers.Find(bson.D{
 		{"env-uuid", serverUUID},
 		{"user", user.Canonical()},
+		// Users without a recorded access level have admin access.
+		{"access", bson.D{{"$nin", []EnvironmentAccess{
+			EnvironmentReadAccess, EnvironmentWriteAccess,
+		}}}},
 	}).Count()
 	if err != nil {
 		return false, errors.Trace(err)
@@ -310,6 +310,67 @@ func (s *EnvUserSuite) TestIsSystemAdministrator(c *gc.C) {
 	c.Assert(isAdmin, jc.IsTrue)
 }
 
+func (s *EnvUserSuite) TestIsSystemAdministratorRequiresAdminAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	envUser, err := s.State.AddEnvironmentUserWithAccess(user.UserTag(), s.Owner, "", state.EnvironmentWriteAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	isAdmin, err := s.State.IsSystemAdministrator(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isAdmin, jc.IsFalse)
+
+	err = envUser.SetAccess(state.EnvironmentAdminAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	isAdmin, err = s.State.IsSystemAdministrator(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isAdmin, jc.IsTrue)
+}
+
+func (s *EnvUserSuite) TestAddEnvironmentUserWithAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	envUser, err := s.State.AddEnvironmentUserWithAccess(user.UserTag(), s.Owner, "", state.EnvironmentReadAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentReadAccess)
+
+	envUser, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentReadAccess)
+}
+
+func (s *EnvUserSuite) TestAddEnvironmentUserInvalidAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	_, err := s.State.AddEnvironmentUserWithAccess(user.UserTag(), s.Owner, "", "superuser")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `environment access "superuser" not valid`)
+}
+
+func (s *EnvUserSuite) TestAddEnvironmentUserDefaultsToAdminAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	envUser, err := s.State.AddEnvironmentUser(user.UserTag(), s.Owner, "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentAdminAccess)
+}
+
+func (s *EnvUserSuite) TestSetAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	envUser, err := s.State.AddEnvironmentUserWithAccess(user.UserTag(), s.Owner, "", state.EnvironmentReadAccess)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = envUser.SetAccess(state.EnvironmentWriteAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentWriteAccess)
+	envUser, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentWriteAccess)
+
+	err = envUser.SetAccess("superuser")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	err = s.State.RemoveEnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = envUser.SetAccess(state.EnvironmentReadAccess)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *EnvUserSuite) TestIsSystemAdministratorFromOtherState(c *gc.C) {
 	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
 
@@ -37,6 +37,7 @@ const (
 	BlockDevicesC      = blockDevicesC
 	StorageInstancesC  = storageInstancesC
 	StatusesHistoryC   = statusesHistoryC
+	AuditLogC          = auditLogC
 )
 
 var (
@@ -52,6 +53,7 @@ var (
 	PickAddress            = &pickAddress
 	AddVolumeOps           = (*State).addVolumeOps
 	CombineMeterStatus     = combineMeterStatus
+	AuditLogMaxAge         = auditLogMaxAge
 )
 
 type (
@@ -444,3 +446,27 @@ func WriteLogWithOplog(
 func SpaceDoc(s *Space) spaceDoc {
 	return s.doc
 }
+
+// CollectionWatcherConfig mirrors collectionWatcherConfig for tests.
+type CollectionWatcherConfig struct {
+	CollName string
+	Filter   func(key interface{}) bool
+	Query    bson.D
+	Fields   []string
+	IdConv   func(localID string) (string, error)
+}
+
+func NewCollectionWatcher(st *State, config CollectionWatcherConfig) StringsWatcher {
+	return newCollectionWatcher(st, collectionWatcherConfig{
+		collName: config.CollName,
+		filter:   config.Filter,
+		query:    config.Query,
+		fields:   config.Fields,
+		idconv:   config.IdConv,
+	})
+}
+
+// FlushStatusHistory writes the status history entries buffered by st.
+func FlushStatusHistory(st *State) {
+	st.flushStatusHistory()
+}
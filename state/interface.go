@@ -138,8 +138,9 @@ type ActionReceiver interface {
 	// ActionReceiver.
 	AddAction(name string, payload map[string]interface{}) (*Action, error)
 
-	// CancelAction removes a pending Action from the queue for this
-	// ActionReceiver and marks it as cancelled.
+	// CancelAction cancels an Action queued or running for this
+	// ActionReceiver. A running Action is stopped by the receiver's
+	// agent.
 	CancelAction(action *Action) (*Action, error)
 
 	// WatchActionNotifications returns a StringsWatcher that will notify
@@ -161,6 +162,10 @@ type ActionReceiver interface {
 	// RunningActions returns the list of Actions currently running for
 	// this ActionReceiver.
 	RunningActions() ([]*Action, error)
+
+	// QueuedActions returns the list of pending Actions waiting in the
+	// queue of this ActionReceiver for earlier Actions to finish.
+	QueuedActions() ([]*Action, error)
 }
 
 // GlobalEntity specifies entity.
@@ -7,7 +7,9 @@
 package state
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -314,8 +316,15 @@ func (t *logTailer) tailOplog() error {
 }
 
 func (t *logTailer) paramsToSelector(params *LogTailerParams, prefix string) bson.D {
+	return logsSelector(t.envUUID, params, prefix)
+}
+
+// logsSelector returns a selector matching the logs of the given
+// environment that pass the filtering in params. If prefix is not
+// empty, it is prepended to each field name.
+func logsSelector(envUUID string, params *LogTailerParams, prefix string) bson.D {
 	sel := bson.D{
-		{"e", t.envUUID},
+		{"e", envUUID},
 		{"t", bson.M{"$gte": params.StartTime}},
 	}
 	if params.MinLevel > loggo.UNSPECIFIED {
@@ -423,6 +432,110 @@ func logDocToRecord(doc *logDoc) *LogRecord {
 	}
 }
 
+// MaxLogQueryLimit is the largest number of logs QueryLogs will return
+// at once.
+const MaxLogQueryLimit = 10000
+
+// LogQueryParams specifies which logs QueryLogs should return.
+type LogQueryParams struct {
+	// StartTime and EndTime bound the times of the logs returned.
+	// Logs at StartTime are included, those at EndTime are not. A
+	// zero EndTime leaves the range open ended.
+	StartTime time.Time
+	EndTime   time.Time
+
+	MinLevel      loggo.Level
+	IncludeEntity []string
+	ExcludeEntity []string
+	IncludeModule []string
+	ExcludeModule []string
+
+	// After holds a token returned by an earlier call to
+	// QueryLogs. If set, only the logs that follow the last log
+	// returned by that call are returned.
+	After string
+
+	// Limit is the most logs that will be returned. If it is not
+	// positive, or is more than MaxLogQueryLimit, MaxLogQueryLimit
+	// is used.
+	Limit int
+}
+
+// QueryLogs returns the logs of the environment that match the given
+// parameters, ordered by time, along with a token that may be passed
+// back as params.After to fetch the logs that follow them. If no logs
+// are returned, the token is params.After.
+//
+// Logs are paged through in the order of their recorded times, so a
+// log written after a query with a time earlier than that of the last
+// log returned will not be seen by queries that follow on from it.
+func QueryLogs(st LoggingState, params *LogQueryParams) ([]*LogRecord, string, error) {
+	conds := []bson.D{logsSelector(st.EnvironUUID(), &LogTailerParams{
+		StartTime:     params.StartTime,
+		MinLevel:      params.MinLevel,
+		IncludeEntity: params.IncludeEntity,
+		ExcludeEntity: params.ExcludeEntity,
+		IncludeModule: params.IncludeModule,
+		ExcludeModule: params.ExcludeModule,
+	}, "")}
+	if !params.EndTime.IsZero() {
+		conds = append(conds, bson.D{{"t", bson.M{"$lt": params.EndTime}}})
+	}
+	if params.After != "" {
+		t, id, err := parseLogQueryToken(params.After)
+		if err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		conds = append(conds, bson.D{{"$or", []bson.D{
+			{{"t", bson.M{"$gt": t}}},
+			{{"t", t}, {"_id", bson.M{"$gt": id}}},
+		}}})
+	}
+	sel := bson.D{{"$and", conds}}
+
+	limit := params.Limit
+	if limit <= 0 || limit > MaxLogQueryLimit {
+		limit = MaxLogQueryLimit
+	}
+
+	session := st.MongoSession().Copy()
+	defer session.Close()
+	logsColl := session.DB(logsDB).C(logsC)
+	var docs []logDoc
+	if err := logsColl.Find(sel).Sort("t", "_id").Limit(limit).All(&docs); err != nil {
+		return nil, "", errors.Annotate(err, "cannot query logs")
+	}
+	if len(docs) == 0 {
+		return nil, params.After, nil
+	}
+	records := make([]*LogRecord, len(docs))
+	for i := range docs {
+		records[i] = logDocToRecord(&docs[i])
+	}
+	last := docs[len(docs)-1]
+	return records, makeLogQueryToken(last.Time, last.Id), nil
+}
+
+// makeLogQueryToken returns a token naming the log with the given time
+// and id, as understood by parseLogQueryToken.
+func makeLogQueryToken(t time.Time, id bson.ObjectId) string {
+	return fmt.Sprintf("%d.%s", t.UnixNano(), id.Hex())
+}
+
+// parseLogQueryToken returns the time and id of the log named by a
+// token returned by makeLogQueryToken.
+func parseLogQueryToken(token string) (time.Time, bson.ObjectId, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || !bson.IsObjectIdHex(parts[1]) {
+		return time.Time{}, "", errors.NotValidf("log query token %q", token)
+	}
+	nsec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", errors.NotValidf("log query token %q", token)
+	}
+	return time.Unix(0, nsec), bson.ObjectIdHex(parts[1]), nil
+}
+
 // PruneLogs removes old log documents in order to control the size of
 // logs collection. All logs older than minLogTime are
 // removed. Further removal is also performed if the logs collection
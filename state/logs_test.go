@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -579,3 +580,94 @@ func (s *LogTailerSuite) assertTailer(c *gc.C, tailer state.LogTailer, expectedC
 		}
 	}
 }
+
+func (s *LogTailerSuite) TestQueryLogsPaging(c *gc.C) {
+	t0 := time.Now().Truncate(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		ts := t0.Add(time.Duration(i) * time.Second)
+		s.writeLogsT(c, ts, ts, 1, logTemplate{Message: strconv.Itoa(i)})
+	}
+
+	var messages []string
+	token := ""
+	for _, expectCount := range []int{2, 2, 1, 0} {
+		records, next, err := state.QueryLogs(s.State, &state.LogQueryParams{
+			After: token,
+			Limit: 2,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(records, gc.HasLen, expectCount)
+		if expectCount == 0 {
+			c.Assert(next, gc.Equals, token)
+		}
+		for _, rec := range records {
+			messages = append(messages, rec.Message)
+		}
+		token = next
+	}
+	c.Assert(messages, jc.DeepEquals, []string{"0", "1", "2", "3", "4"})
+}
+
+func (s *LogTailerSuite) TestQueryLogsPagingSameTime(c *gc.C) {
+	s.writeLogs(c, 3, logTemplate{})
+
+	count := 0
+	token := ""
+	for {
+		records, next, err := state.QueryLogs(s.State, &state.LogQueryParams{
+			After: token,
+			Limit: 1,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		if len(records) == 0 {
+			break
+		}
+		count += len(records)
+		c.Assert(count <= 3, jc.IsTrue)
+		token = next
+	}
+	c.Assert(count, gc.Equals, 3)
+}
+
+func (s *LogTailerSuite) TestQueryLogsTimeRange(c *gc.C) {
+	t0 := time.Now().Truncate(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		ts := t0.Add(time.Duration(i) * time.Second)
+		s.writeLogsT(c, ts, ts, 1, logTemplate{Message: strconv.Itoa(i)})
+	}
+
+	records, _, err := state.QueryLogs(s.State, &state.LogQueryParams{
+		StartTime: t0.Add(time.Second),
+		EndTime:   t0.Add(3 * time.Second),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+	c.Assert(records[0].Message, gc.Equals, "1")
+	c.Assert(records[1].Message, gc.Equals, "2")
+}
+
+func (s *LogTailerSuite) TestQueryLogsFiltering(c *gc.C) {
+	s.writeLogs(c, 1, logTemplate{EnvUUID: "someuuid", Message: "bad"})
+	s.writeLogs(c, 1, logTemplate{Level: loggo.DEBUG, Message: "bad"})
+	s.writeLogs(c, 1, logTemplate{Entity: names.NewUnitTag("foo/0"), Message: "bad"})
+	s.writeLogs(c, 1, logTemplate{Module: "juju.thing", Message: "bad"})
+	s.writeLogs(c, 2, logTemplate{Level: loggo.ERROR, Message: "good"})
+
+	records, _, err := state.QueryLogs(s.State, &state.LogQueryParams{
+		MinLevel:      loggo.INFO,
+		ExcludeEntity: []string{"unit-foo*"},
+		ExcludeModule: []string{"juju.thing"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+	for _, rec := range records {
+		c.Check(rec.Message, gc.Equals, "good")
+		c.Check(rec.Level, gc.Equals, loggo.ERROR)
+	}
+}
+
+func (s *LogTailerSuite) TestQueryLogsInvalidToken(c *gc.C) {
+	_, _, err := state.QueryLogs(s.State, &state.LogQueryParams{After: "bad"})
+	c.Assert(err, gc.ErrorMatches, `log query token "bad" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -0,0 +1,249 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/core/description"
+)
+
+// Export describes the whole environment, so that it can be
+// recreated in another controller. The environment must be stable:
+// if any machine, service or unit is not alive, an error is returned.
+func (st *State) Export() (*description.Model, error) {
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cons, err := st.EnvironConstraints()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	model := &description.Model{
+		Version:     description.CurrentVersion,
+		Owner:       env.Owner().Id(),
+		Config:      cfg.AllAttrs(),
+		Constraints: cons.String(),
+	}
+	if model.Machines, err = st.exportMachines(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if model.Services, err = st.exportServices(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if model.Relations, err = st.exportRelations(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if model.Storage, err = st.exportStorage(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return model, nil
+}
+
+func (st *State) exportMachines() ([]description.Machine, error) {
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]description.Machine, len(machines))
+	for i, m := range machines {
+		if m.Life() != Alive {
+			return nil, errors.Errorf("cannot export machine %s: machine is %s", m, m.Life())
+		}
+		cons, err := m.Constraints()
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot export machine %s", m)
+		}
+		jobs := make([]string, len(m.doc.Jobs))
+		for j, job := range m.doc.Jobs {
+			jobs[j] = job.String()
+		}
+		result[i] = description.Machine{
+			Id:            m.doc.Id,
+			Nonce:         m.doc.Nonce,
+			Series:        m.doc.Series,
+			ContainerType: m.doc.ContainerType,
+			Jobs:          jobs,
+			Placement:     m.doc.Placement,
+			Constraints:   cons.String(),
+		}
+		instId, err := m.InstanceId()
+		if errors.IsNotProvisioned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "cannot export machine %s", m)
+		}
+		hw, err := m.HardwareCharacteristics()
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot export machine %s", m)
+		}
+		result[i].InstanceId = string(instId)
+		result[i].Hardware = hw.String()
+	}
+	return result, nil
+}
+
+func (st *State) exportServices() ([]description.Service, error) {
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]description.Service, len(services))
+	for i, s := range services {
+		if s.Life() != Alive {
+			return nil, errors.Errorf("cannot export service %s: service is %s", s, s.Life())
+		}
+		curl, force := s.CharmURL()
+		result[i] = description.Service{
+			Name:        s.doc.Name,
+			Series:      s.doc.Series,
+			Subordinate: s.doc.Subordinate,
+			CharmURL:    curl.String(),
+			ForceCharm:  force,
+			Exposed:     s.doc.Exposed,
+			MinUnits:    s.doc.MinUnits,
+		}
+		if !s.doc.Subordinate {
+			cons, err := s.Constraints()
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot export service %s", s)
+			}
+			result[i].Constraints = cons.String()
+		}
+		if result[i].Settings, err = s.ConfigSettings(); err != nil {
+			return nil, errors.Annotatef(err, "cannot export service %s", s)
+		}
+		result[i].LeaderSettings, err = s.LeaderSettings()
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, errors.Annotatef(err, "cannot export service %s", s)
+		}
+		units, err := s.AllUnits()
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot export service %s", s)
+		}
+		for _, u := range units {
+			if u.Life() != Alive {
+				return nil, errors.Errorf("cannot export unit %s: unit is %s", u, u.Life())
+			}
+			unit := description.Unit{
+				Name:      u.doc.Name,
+				Machine:   u.doc.MachineId,
+				Principal: u.doc.Principal,
+			}
+			if u.doc.CharmURL != nil {
+				unit.CharmURL = u.doc.CharmURL.String()
+			}
+			result[i].Units = append(result[i].Units, unit)
+		}
+	}
+	return result, nil
+}
+
+func (st *State) exportRelations() ([]description.Relation, error) {
+	relations, err := st.AllRelations()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]description.Relation, len(relations))
+	for i, r := range relations {
+		if r.Life() != Alive {
+			return nil, errors.Errorf("cannot export relation %q: relation is %s", r, r.Life())
+		}
+		result[i] = description.Relation{
+			Id:  r.doc.Id,
+			Key: r.doc.Key,
+		}
+		for _, ep := range r.Endpoints() {
+			if remote, err := st.isRemoteService(ep.ServiceName); err != nil {
+				return nil, errors.Trace(err)
+			} else if remote {
+				return nil, errors.NotSupportedf("exporting relation %q with remote service %q", r, ep.ServiceName)
+			}
+			settings, err := st.exportRelationUnitSettings(r, ep)
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot export relation %q", r)
+			}
+			result[i].Endpoints = append(result[i].Endpoints, description.Endpoint{
+				ServiceName:  ep.ServiceName,
+				Name:         ep.Name,
+				Role:         string(ep.Role),
+				Interface:    ep.Interface,
+				Scope:        string(ep.Scope),
+				UnitSettings: settings,
+			})
+		}
+	}
+	return result, nil
+}
+
+// exportRelationUnitSettings returns the relation settings of each of
+// the endpoint's service's units that is in the relation's scope.
+func (st *State) exportRelationUnitSettings(r *Relation, ep Endpoint) (map[string]map[string]interface{}, error) {
+	service, err := st.Service(ep.ServiceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	units, err := service.AllUnits()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make(map[string]map[string]interface{})
+	for _, u := range units {
+		ru, err := r.Unit(u)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		inScope, err := ru.InScope()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !inScope {
+			continue
+		}
+		settings, err := ru.Settings()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result[u.Name()] = settings.Map()
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func (st *State) exportStorage() ([]description.StorageInstance, error) {
+	instances, err := st.AllStorageInstances()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]description.StorageInstance, len(instances))
+	for i, si := range instances {
+		if si.Life() != Alive {
+			return nil, errors.Errorf("cannot export storage %s: storage is %s", si.StorageTag().Id(), si.Life())
+		}
+		if !si.HasOwner() {
+			return nil, errors.Errorf("cannot export storage %s: storage is detached", si.StorageTag().Id())
+		}
+		kind := "unknown"
+		switch si.Kind() {
+		case StorageKindBlock:
+			kind = "block"
+		case StorageKindFilesystem:
+			kind = "filesystem"
+		}
+		result[i] = description.StorageInstance{
+			Id:    si.StorageTag().Id(),
+			Kind:  kind,
+			Owner: si.Owner().String(),
+			Name:  si.StorageName(),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/core/description"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type MigrationExportSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&MigrationExportSuite{})
+
+func (s *MigrationExportSuite) TestExportEmpty(c *gc.C) {
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(model.Version, gc.Equals, description.CurrentVersion)
+	c.Assert(model.Owner, gc.Equals, s.Owner.Id())
+	c.Assert(model.Config["name"], gc.Equals, "testenv")
+	c.Assert(model.Machines, gc.HasLen, 0)
+	c.Assert(model.Services, gc.HasLen, 0)
+}
+
+func (s *MigrationExportSuite) TestExport(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobHostUnits},
+	})
+	service := s.Factory.MakeService(c, &factory.ServiceParams{
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	err := service.UpdateConfigSettings(charm.Settings{"blog-title": "hello"})
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Service: service, Machine: machine})
+
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(model.Machines, gc.HasLen, 1)
+	exported := model.Machines[0]
+	c.Check(exported.Id, gc.Equals, machine.Id())
+	c.Check(exported.Series, gc.Equals, machine.Series())
+	c.Check(exported.Jobs, jc.DeepEquals, []string{"JobHostUnits"})
+	instId, err := machine.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(exported.InstanceId, gc.Equals, string(instId))
+
+	c.Assert(model.Services, gc.HasLen, 1)
+	c.Check(model.Services[0].Name, gc.Equals, service.Name())
+	curl, _ := service.CharmURL()
+	c.Check(model.Services[0].CharmURL, gc.Equals, curl.String())
+	c.Check(model.Services[0].Settings, jc.DeepEquals, map[string]interface{}{"blog-title": "hello"})
+	c.Check(model.Services[0].Units, jc.DeepEquals, []description.Unit{{
+		Name:    unit.Name(),
+		Machine: machine.Id(),
+	}})
+
+	_, err = description.Serialize(model)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MigrationExportSuite) TestExportDyingMachine(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := machine.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Export()
+	c.Assert(err, gc.ErrorMatches, "cannot export machine 0: machine is dying")
+}
@@ -0,0 +1,706 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/core/description"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state/storage"
+)
+
+// Import recreates in this controller an environment described by
+// Export in another, and returns the new environment and a State for
+// it. The charms of all the described services must already have been
+// added to this State's environment; they are copied into the new one.
+//
+// The description is checked before anything is written. If recreating
+// the environment then fails part way, everything written for it is
+// removed again, so that the environment is imported either completely
+// or not at all.
+func (st *State) Import(model *description.Model) (_ *Environment, _ *State, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot import environment")
+	cfg, err := config.New(config.NoDefaults, model.Config)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	owner := names.NewUserTag(model.Owner)
+	charms, err := st.precheckImport(model, cfg, owner)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	env, newSt, err := st.NewEnvironment(cfg, owner)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	imp := &importer{
+		st:     st,
+		newSt:  newSt,
+		model:  model,
+		owner:  owner,
+		charms: charms,
+	}
+	if err := imp.run(); err != nil {
+		if rollbackErr := imp.rollback(); rollbackErr != nil {
+			logger.Errorf("cannot remove partly imported environment %s: %v", env.UUID(), rollbackErr)
+		}
+		newSt.Close()
+		return nil, nil, errors.Trace(err)
+	}
+	return env, newSt, nil
+}
+
+// precheckImport checks that the described environment can be
+// recreated in this controller, and returns the charms its services
+// use, keyed by the URLs in the description.
+func (st *State) precheckImport(model *description.Model, cfg *config.Config, owner names.UserTag) (map[string]*Charm, error) {
+	if model.Version != description.CurrentVersion {
+		return nil, errors.NotSupportedf("model description version %d", model.Version)
+	}
+	if err := model.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	uuid, ok := cfg.UUID()
+	if !ok {
+		return nil, errors.New("environment uuid was not supplied")
+	}
+	if _, err := st.GetEnvironment(names.NewEnvironTag(uuid)); err == nil {
+		return nil, errors.AlreadyExistsf("environment %q", uuid)
+	} else if !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	if owner.IsLocal() {
+		if _, err := st.User(owner); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if _, err := constraints.Parse(model.Constraints); err != nil {
+		return nil, errors.Annotate(err, "environment constraints")
+	}
+	for _, m := range model.Machines {
+		if _, err := importJobs(m.Jobs); err != nil {
+			return nil, errors.Annotatef(err, "machine %s", m.Id)
+		}
+		if _, err := constraints.Parse(m.Constraints); err != nil {
+			return nil, errors.Annotatef(err, "machine %s constraints", m.Id)
+		}
+		if _, err := instance.ParseHardware(m.Hardware); err != nil {
+			return nil, errors.Annotatef(err, "machine %s hardware", m.Id)
+		}
+		if parent := parentId(m.Id); parent != "" && !describesMachine(model, parent) {
+			return nil, errors.NotValidf("container %s of unknown machine %s", m.Id, parent)
+		}
+	}
+	charms := make(map[string]*Charm)
+	for _, s := range model.Services {
+		if _, err := constraints.Parse(s.Constraints); err != nil {
+			return nil, errors.Annotatef(err, "service %s constraints", s.Name)
+		}
+		curl, err := charm.ParseURL(s.CharmURL)
+		if err != nil {
+			return nil, errors.Annotatef(err, "service %s", s.Name)
+		}
+		ch, err := st.Charm(curl)
+		if err != nil {
+			return nil, errors.Annotatef(err, "service %s", s.Name)
+		}
+		charms[s.CharmURL] = ch
+	}
+	for _, si := range model.Storage {
+		if _, err := importStorageKind(si.Kind); err != nil {
+			return nil, errors.Annotatef(err, "storage %s", si.Id)
+		}
+		if _, err := names.ParseTag(si.Owner); err != nil {
+			return nil, errors.Annotatef(err, "storage %s", si.Id)
+		}
+	}
+	return charms, nil
+}
+
+// describesMachine returns whether the model describes the machine
+// with the given id.
+func describesMachine(model *description.Model, id string) bool {
+	for _, m := range model.Machines {
+		if m.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// parentId returns the id of the machine hosting the container with
+// the given id, or "" if the id is of a top level machine.
+func parentId(machineId string) string {
+	if i := strings.LastIndex(machineId, "/"); i > 0 {
+		if j := strings.LastIndex(machineId[:i], "/"); j > 0 {
+			return machineId[:j]
+		}
+	}
+	return ""
+}
+
+func importJobs(jobs []string) ([]MachineJob, error) {
+	result := make([]MachineJob, len(jobs))
+outer:
+	for i, name := range jobs {
+		for job, jobName := range jobNames {
+			if string(jobName) == name {
+				result[i] = job
+				continue outer
+			}
+		}
+		return nil, errors.NotValidf("machine job %q", name)
+	}
+	return result, nil
+}
+
+func importStorageKind(kind string) (StorageKind, error) {
+	switch kind {
+	case "block":
+		return StorageKindBlock, nil
+	case "filesystem":
+		return StorageKindFilesystem, nil
+	case "unknown":
+		return StorageKindUnknown, nil
+	}
+	return StorageKindUnknown, errors.NotValidf("storage kind %q", kind)
+}
+
+// importer recreates a described environment in a new environment.
+type importer struct {
+	st     *State
+	newSt  *State
+	model  *description.Model
+	owner  names.UserTag
+	charms map[string]*Charm
+
+	// copied holds the storage paths of the charm archives copied
+	// into the new environment.
+	copied []string
+}
+
+// run adds the charms to the new environment, then writes all the
+// described entities in a single transaction.
+func (imp *importer) run() error {
+	if err := imp.copyCharms(); err != nil {
+		return errors.Trace(err)
+	}
+	cons, err := constraints.Parse(imp.model.Constraints)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := imp.newSt.SetEnvironConstraints(cons); err != nil {
+		return errors.Trace(err)
+	}
+	var ops []txn.Op
+	for _, build := range []func() ([]txn.Op, error){
+		imp.machineOps,
+		imp.serviceOps,
+		imp.relationOps,
+		imp.storageOps,
+	} {
+		more, err := build()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, more...)
+	}
+	if err := imp.newSt.runTransaction(ops); err != nil {
+		return errors.Annotate(err, "cannot write environment")
+	}
+	return errors.Trace(imp.setSequences())
+}
+
+// rollback removes everything written for the new environment.
+func (imp *importer) rollback() error {
+	// RemoveAllEnvironDocs requires the environment to be dying.
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     imp.newSt.EnvironUUID(),
+		Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
+	}}
+	if err := imp.newSt.runTransaction(ops); err != nil {
+		return errors.Trace(err)
+	}
+	stor := storage.NewStorage(imp.newSt.EnvironUUID(), imp.newSt.MongoSession())
+	for _, path := range imp.copied {
+		if err := stor.Remove(path); err != nil {
+			logger.Warningf("cannot remove charm archive %q: %v", path, err)
+		}
+	}
+	return errors.Trace(imp.newSt.RemoveAllEnvironDocs())
+}
+
+// copyCharms copies the charms the services use, with their archives,
+// into the new environment.
+func (imp *importer) copyCharms() error {
+	src := storage.NewStorage(imp.st.EnvironUUID(), imp.st.MongoSession())
+	dst := storage.NewStorage(imp.newSt.EnvironUUID(), imp.newSt.MongoSession())
+	for _, ch := range imp.charms {
+		if err := copyCharmArchive(src, dst, ch.StoragePath()); err != nil {
+			return errors.Annotatef(err, "cannot copy charm %q", ch.URL())
+		}
+		imp.copied = append(imp.copied, ch.StoragePath())
+		if _, err := imp.newSt.AddCharm(ch, ch.URL(), ch.StoragePath(), ch.BundleSha256()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func copyCharmArchive(src, dst storage.Storage, path string) error {
+	r, length, err := src.Get(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer r.Close()
+	return errors.Trace(dst.Put(path, r, length))
+}
+
+// machineOps returns the operations that add the described machines.
+func (imp *importer) machineOps() ([]txn.Op, error) {
+	st := imp.newSt
+	principals := make(map[string][]string)
+	for _, s := range imp.model.Services {
+		for _, u := range s.Units {
+			if u.Machine != "" && u.Principal == "" {
+				principals[u.Machine] = append(principals[u.Machine], u.Name)
+			}
+		}
+	}
+	children := make(map[string][]string)
+	for _, m := range imp.model.Machines {
+		if parent := parentId(m.Id); parent != "" {
+			children[parent] = append(children[parent], m.Id)
+		}
+	}
+	var ops []txn.Op
+	for _, m := range imp.model.Machines {
+		jobs, err := importJobs(m.Jobs)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		cons, err := constraints.Parse(m.Constraints)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		template := MachineTemplate{
+			Series:      m.Series,
+			Constraints: cons,
+			Jobs:        jobs,
+			Nonce:       m.Nonce,
+			Placement:   m.Placement,
+			Dirty:       len(principals[m.Id]) > 0,
+			principals:  principals[m.Id],
+		}
+		mdoc := st.machineDocForTemplate(template, m.Id)
+		mdoc.ContainerType = m.ContainerType
+		prereqOps, machineOp, err := st.insertNewMachineOps(mdoc, template)
+		if err != nil {
+			return nil, errors.Annotatef(err, "machine %s", m.Id)
+		}
+		ops = append(ops, prereqOps...)
+		ops = append(ops, machineOp, st.insertNewContainerRefOp(m.Id, children[m.Id]...))
+		if m.InstanceId == "" {
+			continue
+		}
+		hw, err := instance.ParseHardware(m.Hardware)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, txn.Op{
+			C:      instanceDataC,
+			Id:     mdoc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &instanceData{
+				DocID:      mdoc.DocID,
+				MachineId:  mdoc.Id,
+				InstanceId: instance.Id(m.InstanceId),
+				EnvUUID:    mdoc.EnvUUID,
+				Arch:       hw.Arch,
+				Mem:        hw.Mem,
+				RootDisk:   hw.RootDisk,
+				CpuCores:   hw.CpuCores,
+				CpuPower:   hw.CpuPower,
+				Tags:       hw.Tags,
+				AvailZone:  hw.AvailabilityZone,
+			},
+		})
+	}
+	return ops, nil
+}
+
+// serviceOps returns the operations that add the described services
+// and their units.
+func (imp *importer) serviceOps() ([]txn.Op, error) {
+	relationCounts := make(map[string]int)
+	for _, r := range imp.model.Relations {
+		for _, ep := range r.Endpoints {
+			relationCounts[ep.ServiceName]++
+		}
+	}
+	var ops []txn.Op
+	for _, s := range imp.model.Services {
+		svc, svcOps, err := imp.serviceOpsFor(s, relationCounts[s.Name])
+		if err != nil {
+			return nil, errors.Annotatef(err, "service %s", s.Name)
+		}
+		ops = append(ops, svcOps...)
+		for _, u := range s.Units {
+			unitOps, err := imp.unitOps(svc, s, u)
+			if err != nil {
+				return nil, errors.Annotatef(err, "unit %s", u.Name)
+			}
+			ops = append(ops, unitOps...)
+		}
+	}
+	return ops, nil
+}
+
+func (imp *importer) serviceOpsFor(s description.Service, relationCount int) (*Service, []txn.Op, error) {
+	st := imp.newSt
+	ch := imp.charms[s.CharmURL]
+	cons, err := constraints.Parse(s.Constraints)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	storageCons := make(map[string]StorageConstraints)
+	if err := addDefaultStorageConstraints(st, storageCons, ch.Meta()); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	svcDoc := &serviceDoc{
+		DocID:         st.docID(s.Name),
+		Name:          s.Name,
+		EnvUUID:       st.EnvironUUID(),
+		Series:        s.Series,
+		Subordinate:   s.Subordinate,
+		CharmURL:      ch.URL(),
+		ForceCharm:    s.ForceCharm,
+		Life:          Alive,
+		UnitCount:     len(s.Units),
+		RelationCount: relationCount,
+		Exposed:       s.Exposed,
+		MinUnits:      s.MinUnits,
+		OwnerTag:      imp.owner.String(),
+	}
+	svc := newService(st, svcDoc)
+	leaderSettings := make(map[string]interface{}, len(s.LeaderSettings))
+	for key, value := range s.LeaderSettings {
+		leaderSettings[key] = value
+	}
+	statusDoc := statusDoc{
+		EnvUUID:    st.EnvironUUID(),
+		Status:     StatusUnknown,
+		StatusInfo: MessageWaitForAgentInit,
+		Updated:    time.Now().UnixNano(),
+		NeverSet:   true,
+	}
+	ops := []txn.Op{
+		createConstraintsOp(st, svc.globalKey(), cons),
+		createRequestedNetworksOp(st, svc.globalKey(), nil),
+		createStorageConstraintsOp(svc.globalKey(), storageCons),
+		createSettingsOp(svc.settingsKey(), s.Settings),
+		createSettingsOp(leadershipSettingsKey(s.Name), leaderSettings),
+		createStatusOp(st, svc.globalKey(), statusDoc),
+		{
+			C:      settingsrefsC,
+			Id:     st.docID(svc.settingsKey()),
+			Assert: txn.DocMissing,
+			Insert: settingsRefsDoc{
+				RefCount: 1,
+				EnvUUID:  st.EnvironUUID(),
+			},
+		}, {
+			C:      servicesC,
+			Id:     svcDoc.DocID,
+			Assert: txn.DocMissing,
+			Insert: svcDoc,
+		},
+	}
+	if s.MinUnits > 0 {
+		ops = append(ops, txn.Op{
+			C:      minUnitsC,
+			Id:     svcDoc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &minUnitsDoc{
+				ServiceName: s.Name,
+				EnvUUID:     st.EnvironUUID(),
+			},
+		})
+	}
+	return svc, ops, nil
+}
+
+func (imp *importer) unitOps(svc *Service, s description.Service, u description.Unit) ([]txn.Op, error) {
+	st := imp.newSt
+	var subordinates []string
+	for _, other := range imp.model.Services {
+		for _, sub := range other.Units {
+			if sub.Principal == u.Name {
+				subordinates = append(subordinates, sub.Name)
+			}
+		}
+	}
+	unitTag := names.NewUnitTag(u.Name).String()
+	var storageAttachments int
+	for _, si := range imp.model.Storage {
+		if si.Owner == unitTag {
+			storageAttachments++
+		}
+	}
+	udoc := &unitDoc{
+		DocID:                  st.docID(u.Name),
+		Name:                   u.Name,
+		EnvUUID:                st.EnvironUUID(),
+		Service:                s.Name,
+		Series:                 s.Series,
+		Principal:              u.Principal,
+		Subordinates:           subordinates,
+		StorageAttachmentCount: storageAttachments,
+		MachineId:              u.Machine,
+		Life:                   Alive,
+	}
+	if u.CharmURL != "" {
+		curl, err := charm.ParseURL(u.CharmURL)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		udoc.CharmURL = curl
+	}
+	now := time.Now().UnixNano()
+	globalKey := unitGlobalKey(u.Name)
+	agentGlobalKey := unitAgentGlobalKey(u.Name)
+	ops := []txn.Op{
+		createStatusOp(st, globalKey, statusDoc{
+			Status:     StatusUnknown,
+			StatusInfo: MessageWaitForAgentInit,
+			Updated:    now,
+			EnvUUID:    st.EnvironUUID(),
+		}),
+		createStatusOp(st, agentGlobalKey, statusDoc{
+			Status:  StatusAllocating,
+			Updated: now,
+			EnvUUID: st.EnvironUUID(),
+		}),
+		createMeterStatusOp(st, agentGlobalKey, &meterStatusDoc{Code: MeterNotSet.String()}),
+		{
+			C:      unitsC,
+			Id:     udoc.DocID,
+			Assert: txn.DocMissing,
+			Insert: udoc,
+		},
+	}
+	if u.Principal == "" {
+		cons, err := constraints.Parse(s.Constraints)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		cons, err = st.resolveConstraints(cons)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, createConstraintsOp(st, agentGlobalKey, cons))
+	}
+	return ops, nil
+}
+
+// relationOps returns the operations that add the described relations,
+// with the settings of the units in their scopes.
+func (imp *importer) relationOps() ([]txn.Op, error) {
+	st := imp.newSt
+	principals := make(map[string]string)
+	services := make(map[string]*Service)
+	for _, s := range imp.model.Services {
+		services[s.Name] = newService(st, &serviceDoc{
+			Name:     s.Name,
+			CharmURL: imp.charms[s.CharmURL].URL(),
+		})
+		for _, u := range s.Units {
+			principals[u.Name] = u.Principal
+		}
+	}
+	var ops []txn.Op
+	for _, r := range imp.model.Relations {
+		var eps []Endpoint
+		var unitCount int
+		for _, dep := range r.Endpoints {
+			ep, err := services[dep.ServiceName].Endpoint(dep.Name)
+			if err != nil {
+				return nil, errors.Annotatef(err, "relation %q", r.Key)
+			}
+			if string(ep.Role) != dep.Role || ep.Interface != dep.Interface {
+				return nil, errors.Errorf("relation %q: endpoint %s:%s does not match charm", r.Key, dep.ServiceName, dep.Name)
+			}
+			ep.Scope = charm.RelationScope(dep.Scope)
+			eps = append(eps, ep)
+			unitCount += len(dep.UnitSettings)
+		}
+		if key := relationKey(eps); key != r.Key {
+			return nil, errors.Errorf("relation %q: endpoints describe %q", r.Key, key)
+		}
+		docID := st.docID(r.Key)
+		ops = append(ops, txn.Op{
+			C:      relationsC,
+			Id:     docID,
+			Assert: txn.DocMissing,
+			Insert: &relationDoc{
+				DocID:     docID,
+				Key:       r.Key,
+				EnvUUID:   st.EnvironUUID(),
+				Id:        r.Id,
+				Endpoints: eps,
+				Life:      Alive,
+				UnitCount: unitCount,
+			},
+		})
+		for i, dep := range r.Endpoints {
+			for unitName, settings := range dep.UnitSettings {
+				scope := []string{"r", strconv.Itoa(r.Id)}
+				if eps[i].Scope == charm.ScopeContainer {
+					container := principals[unitName]
+					if container == "" {
+						container = unitName
+					}
+					scope = append(scope, container)
+				}
+				key := strings.Join(append(scope, string(eps[i].Role), unitName), "#")
+				scopeDocID := st.docID(key)
+				ops = append(ops, createSettingsOp(key, settings), txn.Op{
+					C:      relationScopesC,
+					Id:     scopeDocID,
+					Assert: txn.DocMissing,
+					Insert: relationScopeDoc{
+						DocID:   scopeDocID,
+						Key:     key,
+						EnvUUID: st.EnvironUUID(),
+					},
+				})
+			}
+		}
+	}
+	return ops, nil
+}
+
+// storageOps returns the operations that add the described storage
+// instances, attached to the units that own them.
+func (imp *importer) storageOps() ([]txn.Op, error) {
+	st := imp.newSt
+	serviceCharms := make(map[string]*charm.URL)
+	for _, s := range imp.model.Services {
+		serviceCharms[s.Name] = imp.charms[s.CharmURL].URL()
+	}
+	var ops []txn.Op
+	for _, si := range imp.model.Storage {
+		kind, err := importStorageKind(si.Kind)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		owner, err := names.ParseTag(si.Owner)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc := &storageInstanceDoc{
+			DocID:       st.docID(si.Id),
+			EnvUUID:     st.EnvironUUID(),
+			Id:          si.Id,
+			Kind:        kind,
+			Life:        Alive,
+			Owner:       si.Owner,
+			StorageName: si.Name,
+		}
+		switch owner := owner.(type) {
+		case names.UnitTag:
+			serviceName, err := names.UnitService(owner.Id())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			doc.CharmURL = serviceCharms[serviceName]
+			doc.AttachmentCount = 1
+			ops = append(ops, createStorageAttachmentOp(names.NewStorageTag(si.Id), owner))
+		case names.ServiceTag:
+			doc.CharmURL = serviceCharms[owner.Id()]
+		default:
+			return nil, errors.NotValidf("storage %s owner %q", si.Id, si.Owner)
+		}
+		ops = append(ops, txn.Op{
+			C:      storageInstancesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		})
+	}
+	return ops, nil
+}
+
+// setSequences makes the sequences used to name new machines, units,
+// relations and storage instances continue after the imported ones.
+func (imp *importer) setSequences() error {
+	next := make(map[string]int)
+	bump := func(name string, used int) {
+		if used >= next[name] {
+			next[name] = used + 1
+		}
+	}
+	for _, m := range imp.model.Machines {
+		parts := strings.Split(m.Id, "/")
+		seq, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			return errors.Errorf("invalid machine id %q", m.Id)
+		}
+		if parent := parentId(m.Id); parent != "" {
+			bump(fmt.Sprintf("machine%s%sContainer", parent, parts[len(parts)-2]), seq)
+		} else {
+			bump("machine", seq)
+		}
+	}
+	for _, s := range imp.model.Services {
+		for _, u := range s.Units {
+			seq, err := strconv.Atoi(u.Name[strings.LastIndex(u.Name, "/")+1:])
+			if err != nil {
+				return errors.Errorf("invalid unit name %q", u.Name)
+			}
+			bump(names.NewServiceTag(s.Name).String(), seq)
+		}
+	}
+	for _, r := range imp.model.Relations {
+		bump("relation", r.Id)
+	}
+	for _, si := range imp.model.Storage {
+		seq, err := strconv.Atoi(si.Id[strings.LastIndex(si.Id, "/")+1:])
+		if err != nil {
+			return errors.Errorf("invalid storage id %q", si.Id)
+		}
+		bump("stores", seq)
+	}
+
+	sequences, closer := imp.newSt.getCollection(sequenceC)
+	defer closer()
+	for name, counter := range next {
+		_, err := sequences.FindId(name).Apply(mgo.Change{
+			Update: bson.M{"$set": bson.M{
+				"name":     name,
+				"env-uuid": imp.newSt.EnvironUUID(),
+				"counter":  counter,
+			}},
+			Upsert: true,
+		}, &sequenceDoc{})
+		if err != nil {
+			return errors.Annotatef(err, "cannot set %q sequence", name)
+		}
+	}
+	return nil
+}
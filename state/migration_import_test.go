@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/core/description"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type MigrationImportSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&MigrationImportSuite{})
+
+// exportForImport describes the test environment with a new UUID and
+// name, so that it can be imported into the same controller.
+func (s *MigrationImportSuite) exportForImport(c *gc.C) *description.Model {
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	model.Config["uuid"] = utils.MustNewUUID().String()
+	model.Config["name"] = "imported"
+	return model
+}
+
+func (s *MigrationImportSuite) TestImport(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobHostUnits},
+	})
+	wordpress := s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	err := wordpress.UpdateConfigSettings(charm.Settings{"blog-title": "hello"})
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Service: wordpress, Machine: machine})
+	mysql := s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(map[string]interface{}{"user": "wp"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	model := s.exportForImport(c)
+	env, st, err := s.State.Import(model)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	c.Assert(env.Name(), gc.Equals, "imported")
+	c.Assert(env.Owner(), gc.Equals, s.Owner)
+
+	imported, err := st.Machine(machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(imported.Series(), gc.Equals, machine.Series())
+	c.Assert(imported.Jobs(), jc.DeepEquals, machine.Jobs())
+	instId, err := machine.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	importedId, err := imported.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(importedId, gc.Equals, instId)
+
+	svc, err := st.Service("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, charm.Settings{"blog-title": "hello"})
+	importedUnit, err := st.Unit(unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	machineId, err := importedUnit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineId, gc.Equals, machine.Id())
+	_, err = st.Service(mysql.Name())
+	c.Assert(err, jc.ErrorIsNil)
+
+	importedRel, err := st.KeyRelation(rel.String())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(importedRel.Id(), gc.Equals, rel.Id())
+	importedRU, err := importedRel.Unit(importedUnit)
+	c.Assert(err, jc.ErrorIsNil)
+	inScope, err := importedRU.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsTrue)
+	relSettings, err := importedRU.Settings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(relSettings.Map(), jc.DeepEquals, map[string]interface{}{"user": "wp"})
+
+	// New entities are named after the imported ones.
+	newMachine, err := st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(newMachine.Id(), gc.Equals, "1")
+	newUnit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(newUnit.Name(), gc.Equals, "wordpress/1")
+}
+
+func (s *MigrationImportSuite) TestImportUnknownCharm(c *gc.C) {
+	model := s.exportForImport(c)
+	model.Services = []description.Service{{
+		Name:     "wordpress",
+		Series:   "quantal",
+		CharmURL: "cs:quantal/wordpress-42",
+	}}
+	_, _, err := s.State.Import(model)
+	c.Assert(err, gc.ErrorMatches, `cannot import environment: service wordpress: charm "cs:quantal/wordpress-42" not found`)
+	s.assertNotImported(c, model)
+}
+
+func (s *MigrationImportSuite) TestImportExistingEnvironment(c *gc.C) {
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.State.Import(model)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *MigrationImportSuite) TestImportRollsBack(c *gc.C) {
+	s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	s.Factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The environment is created before the relation is found not to
+	// match the charms; it must be removed again.
+	model := s.exportForImport(c)
+	model.Relations[0].Endpoints[0].Interface = "pgsql"
+	_, _, err = s.State.Import(model)
+	c.Assert(err, gc.ErrorMatches, `cannot import environment: relation .* does not match charm`)
+	s.assertNotImported(c, model)
+}
+
+func (s *MigrationImportSuite) assertNotImported(c *gc.C, model *description.Model) {
+	uuid := model.Config["uuid"].(string)
+	_, err := s.State.GetEnvironment(names.NewEnvironTag(uuid))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -186,7 +186,7 @@ func (st *State) envSetupOps(cfg *config.Config, envUUID, serverUUID string, own
 	if serverUUID == "" {
 		serverUUID = envUUID
 	}
-	envUserOp := createEnvUserOp(envUUID, owner, owner, owner.Name())
+	envUserOp := createEnvUserOp(envUUID, owner, owner, owner.Name(), EnvironmentAdminAccess)
 	ops := []txn.Op{
 		createConstraintsOp(st, environGlobalKey, constraints.Value{}),
 		createSettingsOp(environGlobalKey, cfg.AllAttrs()),
@@ -229,7 +229,7 @@ func isUnauthorized(err error) bool {
 }
 
 // newState creates an incomplete *State, with a configured watcher but no
-// pwatcher, leadershipManager, or serverTag. You must start() the returned
+// pwatcher, leadershipManager, sessionManager or serverTag. You must start() the returned
 // *State before it will function correctly.
 func newState(environTag names.EnvironTag, session *mgo.Session, mongoInfo *mongo.MongoInfo, policy Policy) (_ *State, resultErr error) {
 	// Set up database.
@@ -276,13 +276,21 @@ func (st *State) Close() (err error) {
 		}
 	}
 
-	handle("transaction watcher", st.watcher.Stop())
-	if st.pwatcher != nil {
-		handle("presence watcher", st.pwatcher.Stop())
-	}
-	if st.leadershipManager != nil {
-		st.leadershipManager.Kill()
-		handle("leadership manager", st.leadershipManager.Wait())
+	if !st.readMirror {
+		handle("transaction watcher", st.watcher.Stop())
+		if st.pwatcher != nil {
+			handle("presence watcher", st.pwatcher.Stop())
+		}
+		if st.statusHistory != nil {
+			handle("status history buffer", st.statusHistory.Stop())
+		}
+		if st.sessionManager != nil {
+			handle("session manager", st.sessionManager.Stop())
+		}
+		if st.leadershipManager != nil {
+			st.leadershipManager.Kill()
+			handle("leadership manager", st.leadershipManager.Wait())
+		}
 	}
 	st.mu.Lock()
 	if st.allManager != nil {
@@ -62,15 +62,22 @@ func PortRangeFromNetworkPortRange(unitName string, portRange network.PortRange)
 	return NewPortRange(unitName, portRange.FromPort, portRange.ToPort, portRange.Protocol)
 }
 
-// Validate checks if the port range is valid.
+// Validate checks if the port range is valid. ICMP port ranges must
+// have both bounds set to -1.
 func (p PortRange) Validate() error {
 	proto := strings.ToLower(p.Protocol)
-	if proto != "tcp" && proto != "udp" {
+	if proto != "tcp" && proto != "udp" && proto != "icmp" {
 		return errors.Errorf("invalid protocol %q", proto)
 	}
 	if !names.IsValidUnit(p.UnitName) {
 		return errors.Errorf("invalid unit %q", p.UnitName)
 	}
+	if proto == "icmp" {
+		if p.FromPort != -1 || p.ToPort != -1 {
+			return errors.Errorf("invalid ICMP port range %d-%d, expected no ports", p.FromPort, p.ToPort)
+		}
+		return nil
+	}
 	if p.FromPort > p.ToPort {
 		return errors.Errorf("invalid port range %d-%d", p.FromPort, p.ToPort)
 	}
@@ -136,6 +143,9 @@ func (prA PortRange) CheckConflicts(prB PortRange) error {
 
 // Strings returns the port range as a string.
 func (p PortRange) String() string {
+	if strings.ToLower(p.Protocol) == "icmp" {
+		return fmt.Sprintf("icmp (%q)", p.UnitName)
+	}
 	return fmt.Sprintf("%d-%d/%s (%q)", p.FromPort, p.ToPort, strings.ToLower(p.Protocol), p.UnitName)
 }
 
@@ -657,6 +657,21 @@ func (p *PortRangeSuite) TestPortRangeValidityAndLength(c *gc.C) {
 	}
 }
 
+func (p *PortRangeSuite) TestICMPPortRange(c *gc.C) {
+	icmp := state.PortRange{"wordpress/0", -1, -1, "icmp"}
+	c.Check(icmp.Validate(), jc.ErrorIsNil)
+	c.Check(icmp.String(), gc.Equals, `icmp ("wordpress/0")`)
+
+	withPorts := state.PortRange{"wordpress/0", 80, 80, "icmp"}
+	c.Check(withPorts.Validate(), gc.ErrorMatches, "invalid ICMP port range 80-80, expected no ports")
+
+	// Like any other port range, ICMP can only be opened by one unit
+	// on a machine.
+	c.Check(icmp.CheckConflicts(icmp), jc.ErrorIsNil)
+	other := state.PortRange{"mysql/0", -1, -1, "icmp"}
+	c.Check(icmp.CheckConflicts(other), gc.ErrorMatches, `port ranges icmp \("wordpress/0"\) and icmp \("mysql/0"\) conflict`)
+}
+
 func (p *PortRangeSuite) TestSanitizeBounds(c *gc.C) {
 	tests := []struct {
 		about  string
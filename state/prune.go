@@ -0,0 +1,227 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/mongo"
+)
+
+// HistoryKind identifies a kind of historical record that accumulates
+// as an environment is used, and which must be pruned to stop its
+// collection growing without bound.
+type HistoryKind string
+
+const (
+	// StatusHistory identifies the entries recorded each time the
+	// status of an entity changes.
+	StatusHistory HistoryKind = "status-history"
+
+	// ActionResults identifies actions that have finished running,
+	// along with their results.
+	ActionResults HistoryKind = "action-results"
+
+	// RunResultsHistory identifies the results recorded for commands
+	// run with "juju run".
+	RunResultsHistory HistoryKind = "run-results"
+)
+
+// RetentionPolicy describes which records of a kind are kept when
+// the history is pruned.
+type RetentionPolicy struct {
+	// MaxAge is the age beyond which records are removed. If zero,
+	// records are not removed because of their age.
+	MaxAge time.Duration
+
+	// MaxEntries is the number of records kept, newest first. For
+	// kinds recorded per entity, such as status history, it is the
+	// number kept for each entity. If zero, records are not removed
+	// because of their number.
+	MaxEntries int
+}
+
+// historyCollection describes how the records of a HistoryKind are
+// stored.
+type historyCollection struct {
+	// name is the name of the collection holding the records.
+	name string
+
+	// selector selects the records in the collection that may be
+	// pruned.
+	selector bson.D
+
+	// groupField, if set, is the field identifying the entity a
+	// record belongs to; MaxEntries applies to each entity's records
+	// separately.
+	groupField string
+
+	// timeField is the field holding the time a record was made.
+	timeField string
+
+	// timeValue converts a time to the representation held in
+	// timeField.
+	timeValue func(time.Time) interface{}
+
+	// txnManaged is true if the records are written with
+	// transactions, and so must also be removed with them.
+	txnManaged bool
+}
+
+var historyCollections = map[HistoryKind]historyCollection{
+	StatusHistory: {
+		name:       statusesHistoryC,
+		groupField: "globalkey",
+		timeField:  "updated",
+		timeValue:  func(t time.Time) interface{} { return t.UnixNano() },
+	},
+	ActionResults: {
+		name: actionsC,
+		selector: bson.D{{"status", bson.D{{"$in", []ActionStatus{
+			ActionCompleted,
+			ActionCancelled,
+			ActionFailed,
+		}}}}},
+		timeField:  "completed",
+		timeValue:  func(t time.Time) interface{} { return t },
+		txnManaged: true,
+	},
+	RunResultsHistory: {
+		name:      runResultsC,
+		timeField: "started",
+		timeValue: func(t time.Time) interface{} { return t },
+	},
+}
+
+// pruneBatchSize is the number of records removed at once.
+const pruneBatchSize = 100
+
+// HistoryRetentionPolicies returns the retention policy configured for
+// each kind of history in the environment's config.
+func HistoryRetentionPolicies(cfg *config.Config) map[HistoryKind]RetentionPolicy {
+	policies := make(map[HistoryKind]RetentionPolicy)
+	var policy RetentionPolicy
+	policy.MaxAge, policy.MaxEntries = cfg.StatusHistoryRetention()
+	policies[StatusHistory] = policy
+	policy.MaxAge, policy.MaxEntries = cfg.ActionResultsRetention()
+	policies[ActionResults] = policy
+	policy.MaxAge, policy.MaxEntries = cfg.RunResultsRetention()
+	policies[RunResultsHistory] = policy
+	return policies
+}
+
+// PruneHistory removes the environment's records of the given kind
+// that are older than the policy's MaxAge, and then the oldest records
+// beyond its MaxEntries, for each entity if the kind is recorded per
+// entity.
+func (st *State) PruneHistory(kind HistoryKind, policy RetentionPolicy) error {
+	hc, ok := historyCollections[kind]
+	if !ok {
+		return errors.NotValidf("history kind %q", kind)
+	}
+	if kind == StatusHistory {
+		st.flushStatusHistory()
+	}
+	coll, closer := st.getCollection(hc.name)
+	defer closer()
+
+	if policy.MaxAge > 0 {
+		cutoff := GetClock().Now().Add(-policy.MaxAge)
+		sel := append(bson.D{{
+			hc.timeField, bson.D{{"$lt", hc.timeValue(cutoff)}},
+		}}, hc.selector...)
+		iter := coll.Find(sel).Select(bson.D{{"_id", 1}}).Iter()
+		removed, err := st.pruneHistoryDocs(hc, coll, iter)
+		if err != nil {
+			return errors.Annotatef(err, "pruning %s older than %v", kind, policy.MaxAge)
+		}
+		logger.Debugf("pruned %d %s records older than %v", removed, kind, policy.MaxAge)
+	}
+	if policy.MaxEntries > 0 {
+		removed, err := st.pruneHistoryEntries(hc, coll, policy.MaxEntries)
+		if err != nil {
+			return errors.Annotatef(err, "pruning %s beyond %d records", kind, policy.MaxEntries)
+		}
+		logger.Debugf("pruned %d %s records beyond the newest %d", removed, kind, policy.MaxEntries)
+	}
+	return nil
+}
+
+// pruneHistoryEntries removes all but the newest maxEntries records in
+// the collection, or in each group of records if the collection is
+// grouped, and returns the number removed.
+func (st *State) pruneHistoryEntries(hc historyCollection, coll mongo.Collection, maxEntries int) (int, error) {
+	prune := func(sel bson.D) (int, error) {
+		iter := coll.Find(sel).Sort("-" + hc.timeField).Skip(maxEntries).Select(bson.D{{"_id", 1}}).Iter()
+		return st.pruneHistoryDocs(hc, coll, iter)
+	}
+	if hc.groupField == "" {
+		return prune(hc.selector)
+	}
+	var groups []string
+	if err := coll.Find(hc.selector).Distinct(hc.groupField, &groups); err != nil {
+		return 0, errors.Trace(err)
+	}
+	removed := 0
+	for _, group := range groups {
+		n, err := prune(append(bson.D{{hc.groupField, group}}, hc.selector...))
+		removed += n
+		if err != nil {
+			return removed, errors.Annotatef(err, "%s %q", hc.groupField, group)
+		}
+	}
+	return removed, nil
+}
+
+// pruneHistoryDocs removes the documents returned by iter in batches,
+// and returns the number removed.
+func (st *State) pruneHistoryDocs(hc historyCollection, coll mongo.Collection, iter *mgo.Iter) (int, error) {
+	var ids []interface{}
+	removed := 0
+	flush := func() error {
+		if len(ids) == 0 {
+			return nil
+		}
+		if hc.txnManaged {
+			ops := make([]txn.Op, len(ids))
+			for i, id := range ids {
+				ops[i] = txn.Op{C: hc.name, Id: id, Remove: true}
+			}
+			if err := st.runTransaction(ops); err != nil {
+				return errors.Trace(err)
+			}
+		} else {
+			_, err := coll.Writeable().RemoveAll(bson.D{{"_id", bson.D{{"$in", ids}}}})
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		removed += len(ids)
+		ids = ids[:0]
+		return nil
+	}
+	var doc struct {
+		Id interface{} `bson:"_id"`
+	}
+	for iter.Next(&doc) {
+		ids = append(ids, doc.Id)
+		if len(ids) < pruneBatchSize {
+			continue
+		}
+		if err := flush(); err != nil {
+			iter.Close()
+			return removed, errors.Trace(err)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return removed, errors.Trace(err)
+	}
+	return removed, errors.Trace(flush())
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type PruneSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&PruneSuite{})
+
+func (s *PruneSuite) advanceClock(d time.Duration) {
+	s.PatchValue(&state.GetClock, func() clock.Clock {
+		return coretesting.NewClock(time.Now().Add(d))
+	})
+}
+
+func (s *PruneSuite) statusHistoryCount(c *gc.C) int {
+	state.FlushStatusHistory(s.State)
+	history := s.State.MongoSession().DB("juju").C("statuseshistory")
+	n, err := history.Find(bson.D{{"env-uuid", s.State.EnvironUUID()}}).Count()
+	c.Assert(err, jc.ErrorIsNil)
+	return n
+}
+
+func (s *PruneSuite) TestPruneStatusHistoryByAge(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	primeUnitStatusHistory(c, unit, 5)
+
+	err := s.State.PruneHistory(state.StatusHistory, state.RetentionPolicy{MaxAge: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	history, err := unit.StatusHistory(10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 6)
+
+	s.advanceClock(2 * time.Hour)
+	err = s.State.PruneHistory(state.StatusHistory, state.RetentionPolicy{MaxAge: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.statusHistoryCount(c), gc.Equals, 0)
+}
+
+func (s *PruneSuite) TestPruneStatusHistoryByEntries(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	primeUnitStatusHistory(c, unit, 10)
+	c.Assert(s.statusHistoryCount(c) > 3, jc.IsTrue)
+
+	err := s.State.PruneHistory(state.StatusHistory, state.RetentionPolicy{MaxEntries: 3})
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := unit.StatusHistory(10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	for i, statusInfo := range history {
+		checkPrimedUnitStatus(c, statusInfo, 9-i)
+	}
+}
+
+func (s *PruneSuite) TestPruneStatusHistoryByEntriesPerEntity(c *gc.C) {
+	unit0 := s.Factory.MakeUnit(c, nil)
+	unit1 := s.Factory.MakeUnit(c, nil)
+	primeUnitStatusHistory(c, unit0, 10)
+	primeUnitStatusHistory(c, unit1, 2)
+
+	err := s.State.PruneHistory(state.StatusHistory, state.RetentionPolicy{MaxEntries: 3})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Each unit keeps its newest entries, however many the other
+	// unit has.
+	history, err := unit0.StatusHistory(10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	for i, statusInfo := range history {
+		checkPrimedUnitStatus(c, statusInfo, 9-i)
+	}
+	history, err = unit1.StatusHistory(10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+}
+
+func (s *PruneSuite) TestPruneActionResults(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	unit, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	var actions []*state.Action
+	for i := 0; i < 3; i++ {
+		action, err := unit.AddAction("snapshot", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		actions = append(actions, action)
+	}
+	_, err = actions[0].Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = actions[1].Finish(state.ActionResults{Status: state.ActionFailed})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.PruneHistory(state.ActionResults, state.RetentionPolicy{MaxEntries: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	completed, err := unit.CompletedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(completed, gc.HasLen, 1)
+
+	// Pending actions are never pruned, however old.
+	s.advanceClock(2 * time.Hour)
+	err = s.State.PruneHistory(state.ActionResults, state.RetentionPolicy{MaxAge: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	completed, err = unit.CompletedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(completed, gc.HasLen, 0)
+	pending, err := unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+	c.Assert(pending[0].Id(), gc.Equals, actions[2].Id())
+}
+
+func (s *PruneSuite) TestPruneRunResults(c *gc.C) {
+	now := time.Now()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		started := now.Add(time.Duration(i-3) * time.Hour)
+		id, err := s.State.AddRunResults("hostname", started, nil)
+		c.Assert(err, jc.ErrorIsNil)
+		ids = append(ids, id)
+	}
+
+	err := s.State.PruneHistory(state.RunResultsHistory, state.RetentionPolicy{MaxEntries: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.RunResults(ids[0])
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.PruneHistory(state.RunResultsHistory, state.RetentionPolicy{MaxAge: 90 * time.Minute})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.RunResults(ids[1])
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.RunResults(ids[2])
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *PruneSuite) TestPruneHistoryUnknownKind(c *gc.C) {
+	err := s.State.PruneHistory("audit", state.RetentionPolicy{MaxEntries: 1})
+	c.Assert(err, gc.ErrorMatches, `history kind "audit" not valid`)
+}
+
+func (s *PruneSuite) TestHistoryRetentionPolicies(c *gc.C) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{
+		"status-history-max-age":     24,
+		"action-results-max-entries": 0,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state.HistoryRetentionPolicies(cfg), jc.DeepEquals, map[state.HistoryKind]state.RetentionPolicy{
+		state.StatusHistory:     {MaxAge: 24 * time.Hour, MaxEntries: 100},
+		state.ActionResults:     {MaxAge: 336 * time.Hour, MaxEntries: 0},
+		state.RunResultsHistory: {MaxAge: 336 * time.Hour, MaxEntries: 1000},
+	})
+}
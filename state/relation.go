@@ -182,6 +182,18 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 		if ep.ServiceName == ignoreService {
 			continue
 		}
+		if remote, err := r.st.isRemoteService(ep.ServiceName); err != nil {
+			return nil, errors.Trace(err)
+		} else if remote {
+			// Remote services have no life or units of their own.
+			ops = append(ops, txn.Op{
+				C:      remoteServicesC,
+				Id:     r.st.docID(ep.ServiceName),
+				Assert: txn.DocExists,
+				Update: bson.D{{"$inc", bson.D{{"relationcount", -1}}}},
+			})
+			continue
+		}
 		var asserts bson.D
 		hasRelation := bson.D{{"relationcount", bson.D{{"$gt", 0}}}}
 		if departingUnit == nil {
@@ -0,0 +1,499 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// RemoteService represents, in the environment that consumes it, a
+// service offered from another environment.
+type RemoteService struct {
+	st  *State
+	doc remoteServiceDoc
+}
+
+// remoteServiceDoc represents the internal state of a remote service
+// in MongoDB.
+type remoteServiceDoc struct {
+	DocID         string           `bson:"_id"`
+	Name          string           `bson:"name"`
+	EnvUUID       string           `bson:"env-uuid"`
+	OfferURL      string           `bson:"offer-url"`
+	SourceEnvUUID string           `bson:"source-env-uuid"`
+	Endpoints     []charm.Relation `bson:"endpoints"`
+	RelationCount int              `bson:"relationcount"`
+}
+
+// Name returns the name of the remote service in this environment.
+func (s *RemoteService) Name() string {
+	return s.doc.Name
+}
+
+// String returns the remote service name.
+func (s *RemoteService) String() string {
+	return s.doc.Name
+}
+
+// OfferURL returns the URL of the offer the remote service consumes.
+func (s *RemoteService) OfferURL() string {
+	return s.doc.OfferURL
+}
+
+// SourceEnvUUID returns the UUID of the environment the remote
+// service's offer was made from.
+func (s *RemoteService) SourceEnvUUID() string {
+	return s.doc.SourceEnvUUID
+}
+
+// ConnectionId returns the id of the offer connection through which
+// the remote service consumes its offer.
+func (s *RemoteService) ConnectionId() string {
+	return offerConnectionId(s.doc.EnvUUID, s.doc.Name)
+}
+
+// Endpoints returns the remote service's endpoints.
+func (s *RemoteService) Endpoints() []Endpoint {
+	eps := make([]Endpoint, len(s.doc.Endpoints))
+	for i, rel := range s.doc.Endpoints {
+		eps[i] = Endpoint{
+			ServiceName: s.doc.Name,
+			Relation:    rel,
+		}
+	}
+	return eps
+}
+
+// Endpoint returns the remote service's endpoint with the given name.
+func (s *RemoteService) Endpoint(relationName string) (Endpoint, error) {
+	for _, ep := range s.Endpoints() {
+		if ep.Name == relationName {
+			return ep, nil
+		}
+	}
+	return Endpoint{}, errors.NotFoundf("remote service %q endpoint %q", s, relationName)
+}
+
+// filterEndpoints returns the remote service's endpoints that could be
+// intended by the supplied relation name, which may be empty, and which
+// cause the filter param to return true.
+func (s *RemoteService) filterEndpoints(relName string, filter func(ep Endpoint) bool) ([]Endpoint, error) {
+	eps := s.Endpoints()
+	if relName != "" {
+		ep, err := s.Endpoint(relName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		eps = []Endpoint{ep}
+	}
+	final := []Endpoint{}
+	for _, ep := range eps {
+		if filter(ep) {
+			final = append(final, ep)
+		}
+	}
+	return final, nil
+}
+
+// relationCountOp returns an operation that adds n to the number of
+// relations the remote service is in.
+func (s *RemoteService) relationCountOp(n int) txn.Op {
+	return txn.Op{
+		C:      remoteServicesC,
+		Id:     s.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$inc", bson.D{{"relationcount", n}}}},
+	}
+}
+
+// Refresh refreshes the contents of the remote service from the
+// underlying state.
+func (s *RemoteService) Refresh() error {
+	remoteServices, closer := s.st.getCollection(remoteServicesC)
+	defer closer()
+	err := remoteServices.FindId(s.doc.DocID).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("remote service %q", s)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot refresh remote service %q", s)
+	}
+	return nil
+}
+
+// Remove removes the remote service, and the offer connection through
+// which it consumes its offer, along with any settings exchanged
+// through the connection. A remote service cannot be removed while it
+// is in any relations.
+func (s *RemoteService) Remove() error {
+	connId := s.ConnectionId()
+	ops := []txn.Op{{
+		C:      remoteServicesC,
+		Id:     s.doc.DocID,
+		Assert: bson.D{{"relationcount", bson.D{{"$not", bson.D{{"$gt", 0}}}}}},
+		Remove: true,
+	}, {
+		C:      offerConnectionsC,
+		Id:     connId,
+		Remove: true,
+	}}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		if err := s.Refresh(); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Errorf("cannot remove remote service %q: service has relations", s)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot remove remote service %q", s)
+	}
+	remoteSettings, closer := s.st.getRawCollection(remoteSettingsC)
+	defer closer()
+	if _, err := remoteSettings.RemoveAll(bson.D{{"connection", connId}}); err != nil {
+		return errors.Annotatef(err, "cannot remove settings of remote service %q", s)
+	}
+	return nil
+}
+
+// AddRemoteServiceArgs holds the arguments for AddRemoteService.
+type AddRemoteServiceArgs struct {
+	// Name is the name the remote service will have in this
+	// environment; it must not be used by any other service.
+	Name string
+
+	// OfferURL is the URL of the offer to consume.
+	OfferURL string
+
+	// Consumer is the user consuming the offer.
+	Consumer names.UserTag
+}
+
+// AddRemoteService consumes an offer made from another environment,
+// adding a remote service to this environment that has the offered
+// endpoints, and recording the connection between the environments.
+func (st *State) AddRemoteService(args AddRemoteServiceArgs) (_ *RemoteService, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add remote service %q", args.Name)
+	if !names.IsValidService(args.Name) {
+		return nil, errors.NotValidf("service name %q", args.Name)
+	}
+	offer, err := st.ServiceOffer(args.OfferURL)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if offer.EnvUUID == st.EnvironUUID() {
+		return nil, errors.NotValidf("offer from the same environment")
+	}
+	endpoints, err := st.offeredEndpoints(offer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	} else if env.Life() != Alive {
+		return nil, errors.New("environment is no longer alive")
+	}
+	doc := &remoteServiceDoc{
+		DocID:         st.docID(args.Name),
+		Name:          args.Name,
+		EnvUUID:       st.EnvironUUID(),
+		OfferURL:      offer.URL,
+		SourceEnvUUID: offer.EnvUUID,
+		Endpoints:     endpoints,
+	}
+	connDoc := &offerConnectionDoc{
+		Id:              offerConnectionId(st.EnvironUUID(), args.Name),
+		OfferURL:        offer.URL,
+		OfferEnvUUID:    offer.EnvUUID,
+		ConsumerEnvUUID: st.EnvironUUID(),
+		ServiceName:     args.Name,
+		User:            args.Consumer.String(),
+	}
+	ops := []txn.Op{
+		env.assertAliveOp(),
+		{
+			C:      serviceOffersC,
+			Id:     offer.URL,
+			Assert: txn.DocExists,
+		}, {
+			C:      servicesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+		}, {
+			C:      remoteServicesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}, {
+			C:      offerConnectionsC,
+			Id:     connDoc.Id,
+			Assert: txn.DocMissing,
+			Insert: connDoc,
+		},
+	}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		if err := checkEnvLife(st); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, err := st.ServiceOffer(offer.URL); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return nil, errors.AlreadyExistsf("service %q", args.Name)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &RemoteService{st: st, doc: *doc}, nil
+}
+
+// offeredEndpoints returns the endpoints of the service offered by
+// offer, as defined by the service's charm in the offer's environment.
+func (st *State) offeredEndpoints(offer ServiceOffer) ([]charm.Relation, error) {
+	offerSt, err := st.ForEnviron(names.NewEnvironTag(offer.EnvUUID))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer offerSt.Close()
+	svc, err := offerSt.Service(offer.ServiceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	endpoints := make([]charm.Relation, len(offer.Endpoints))
+	for i, name := range offer.Endpoints {
+		ep, err := svc.Endpoint(name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		endpoints[i] = ep.Relation
+	}
+	return endpoints, nil
+}
+
+// RemoteService returns the remote service with the given name.
+func (st *State) RemoteService(name string) (*RemoteService, error) {
+	remoteServices, closer := st.getCollection(remoteServicesC)
+	defer closer()
+	var doc remoteServiceDoc
+	err := remoteServices.FindId(name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("remote service %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get remote service %q", name)
+	}
+	return &RemoteService{st: st, doc: doc}, nil
+}
+
+// isRemoteService returns whether the named service is a remote
+// service.
+func (st *State) isRemoteService(name string) (bool, error) {
+	remoteServices, closer := st.getCollection(remoteServicesC)
+	defer closer()
+	n, err := remoteServices.FindId(name).Count()
+	if err != nil {
+		return false, errors.Annotatef(err, "cannot get remote service %q", name)
+	}
+	return n > 0, nil
+}
+
+// AllRemoteServices returns all the remote services in the environment.
+func (st *State) AllRemoteServices() ([]*RemoteService, error) {
+	remoteServices, closer := st.getCollection(remoteServicesC)
+	defer closer()
+	var docs []remoteServiceDoc
+	if err := remoteServices.Find(nil).Sort("name").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get remote services")
+	}
+	result := make([]*RemoteService, len(docs))
+	for i, doc := range docs {
+		result[i] = &RemoteService{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// OfferConnection records that a remote service in one environment
+// consumes an offer made from another.
+type OfferConnection struct {
+	// Id uniquely identifies the connection.
+	Id string
+
+	// OfferURL is the URL of the consumed offer.
+	OfferURL string
+
+	// OfferEnvUUID identifies the environment the offer was made
+	// from.
+	OfferEnvUUID string
+
+	// ConsumerEnvUUID identifies the environment of the remote
+	// service.
+	ConsumerEnvUUID string
+
+	// ServiceName is the name of the remote service in the consuming
+	// environment.
+	ServiceName string
+
+	// User is the tag of the user who consumed the offer.
+	User string
+}
+
+// Side returns the side of the connection that the given environment
+// is on.
+func (c OfferConnection) Side(envUUID string) (ConnectionSide, error) {
+	switch envUUID {
+	case c.OfferEnvUUID:
+		return OfferingSide, nil
+	case c.ConsumerEnvUUID:
+		return ConsumingSide, nil
+	}
+	return "", errors.NotFoundf("environment %q in offer connection %q", envUUID, c.Id)
+}
+
+// ConnectionSide identifies one side of an offer connection.
+type ConnectionSide string
+
+const (
+	// OfferingSide is the side of the environment that made the offer.
+	OfferingSide ConnectionSide = "offering"
+
+	// ConsumingSide is the side of the environment that consumed the
+	// offer.
+	ConsumingSide ConnectionSide = "consuming"
+)
+
+// Other returns the other side of the connection.
+func (side ConnectionSide) Other() ConnectionSide {
+	if side == OfferingSide {
+		return ConsumingSide
+	}
+	return OfferingSide
+}
+
+// offerConnectionDoc is the document used to store an OfferConnection.
+type offerConnectionDoc struct {
+	Id              string `bson:"_id"`
+	OfferURL        string `bson:"offer-url"`
+	OfferEnvUUID    string `bson:"offer-env-uuid"`
+	ConsumerEnvUUID string `bson:"consumer-env-uuid"`
+	ServiceName     string `bson:"service"`
+	User            string `bson:"user"`
+}
+
+func (doc *offerConnectionDoc) connection() OfferConnection {
+	return OfferConnection{
+		Id:              doc.Id,
+		OfferURL:        doc.OfferURL,
+		OfferEnvUUID:    doc.OfferEnvUUID,
+		ConsumerEnvUUID: doc.ConsumerEnvUUID,
+		ServiceName:     doc.ServiceName,
+		User:            doc.User,
+	}
+}
+
+// offerConnectionId returns the id of the connection through which the
+// named remote service in the given environment consumes its offer.
+func offerConnectionId(consumerEnvUUID, serviceName string) string {
+	return consumerEnvUUID + ":" + serviceName
+}
+
+// OfferConnection returns the offer connection with the given id.
+func (st *State) OfferConnection(id string) (OfferConnection, error) {
+	connections, closer := st.getCollection(offerConnectionsC)
+	defer closer()
+	var doc offerConnectionDoc
+	err := connections.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return OfferConnection{}, errors.NotFoundf("offer connection %q", id)
+	} else if err != nil {
+		return OfferConnection{}, errors.Annotatef(err, "cannot get offer connection %q", id)
+	}
+	return doc.connection(), nil
+}
+
+// OfferConnections returns the offer connections that this environment
+// is on either side of.
+func (st *State) OfferConnections() ([]OfferConnection, error) {
+	connections, closer := st.getCollection(offerConnectionsC)
+	defer closer()
+	uuid := st.EnvironUUID()
+	query := bson.D{{"$or", []bson.D{
+		{{"offer-env-uuid", uuid}},
+		{{"consumer-env-uuid", uuid}},
+	}}}
+	var docs []offerConnectionDoc
+	if err := connections.Find(query).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get offer connections")
+	}
+	result := make([]OfferConnection, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.connection()
+	}
+	return result, nil
+}
+
+// remoteSettingsDoc holds the relation settings published by a unit on
+// one side of an offer connection, for the other side to read.
+type remoteSettingsDoc struct {
+	Id         string         `bson:"_id"`
+	Connection string         `bson:"connection"`
+	Side       ConnectionSide `bson:"side"`
+	Unit       string         `bson:"unit"`
+	Settings   settingsMap    `bson:"settings"`
+}
+
+func remoteSettingsId(connId string, side ConnectionSide, unit string) string {
+	return fmt.Sprintf("%s#%s#%s", connId, side, unit)
+}
+
+// SetRemoteUnitSettings publishes the relation settings of a unit on
+// the given side of an offer connection, replacing any it published
+// before.
+func (st *State) SetRemoteUnitSettings(connId string, side ConnectionSide, unit string, settings map[string]interface{}) error {
+	if _, err := st.OfferConnection(connId); err != nil {
+		return errors.Trace(err)
+	}
+	remoteSettings, closer := st.getRawCollection(remoteSettingsC)
+	defer closer()
+	id := remoteSettingsId(connId, side, unit)
+	_, err := remoteSettings.UpsertId(id, bson.D{{"$set", bson.D{
+		{"connection", connId},
+		{"side", side},
+		{"unit", unit},
+		{"settings", copyMap(settings, escapeReplacer.Replace)},
+	}}})
+	if err != nil {
+		return errors.Annotatef(err, "cannot set settings of %s unit %q", side, unit)
+	}
+	return nil
+}
+
+// RemoveRemoteUnitSettings removes the relation settings published by
+// a unit on the given side of an offer connection.
+func (st *State) RemoveRemoteUnitSettings(connId string, side ConnectionSide, unit string) error {
+	remoteSettings, closer := st.getRawCollection(remoteSettingsC)
+	defer closer()
+	err := remoteSettings.RemoveId(remoteSettingsId(connId, side, unit))
+	if err != nil && err != mgo.ErrNotFound {
+		return errors.Annotatef(err, "cannot remove settings of %s unit %q", side, unit)
+	}
+	return nil
+}
+
+// RemoteUnitSettings returns the relation settings published by the
+// units on the given side of an offer connection, keyed by unit name.
+func (st *State) RemoteUnitSettings(connId string, side ConnectionSide) (map[string]map[string]interface{}, error) {
+	remoteSettings, closer := st.getRawCollection(remoteSettingsC)
+	defer closer()
+	var docs []remoteSettingsDoc
+	query := bson.D{{"connection", connId}, {"side", side}}
+	if err := remoteSettings.Find(query).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get %s settings of offer connection %q", side, connId)
+	}
+	result := make(map[string]map[string]interface{})
+	for _, doc := range docs {
+		result[doc.Unit] = doc.Settings
+	}
+	return result, nil
+}
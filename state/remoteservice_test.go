@@ -0,0 +1,247 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type RemoteServiceSuite struct {
+	ConnSuite
+	consumer *state.State
+	offer    state.ServiceOffer
+}
+
+var _ = gc.Suite(&RemoteServiceSuite{})
+
+func (s *RemoteServiceSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	offer, err := s.State.AddServiceOffer(state.AddServiceOfferArgs{
+		OfferName:   "db",
+		ServiceName: "mysql",
+		Endpoints:   []string{"server"},
+		Owner:       s.Owner,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.offer = offer
+	s.consumer = s.Factory.MakeEnvironment(c, nil)
+}
+
+func (s *RemoteServiceSuite) TearDownTest(c *gc.C) {
+	if s.consumer != nil {
+		s.consumer.Close()
+	}
+	s.ConnSuite.TearDownTest(c)
+}
+
+func (s *RemoteServiceSuite) addRemoteService(c *gc.C) *state.RemoteService {
+	remote, err := s.consumer.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:     "remotedb",
+		OfferURL: s.offer.URL,
+		Consumer: names.NewUserTag("bob"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return remote
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteService(c *gc.C) {
+	svc, err := s.State.Service("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	ep, err := svc.Endpoint("server")
+	c.Assert(err, jc.ErrorIsNil)
+
+	remote := s.addRemoteService(c)
+	c.Assert(remote.Name(), gc.Equals, "remotedb")
+	c.Assert(remote.OfferURL(), gc.Equals, s.offer.URL)
+	c.Assert(remote.SourceEnvUUID(), gc.Equals, s.State.EnvironUUID())
+	c.Assert(remote.Endpoints(), jc.DeepEquals, []state.Endpoint{{
+		ServiceName: "remotedb",
+		Relation:    ep.Relation,
+	}})
+	c.Assert(ep.Role, gc.Equals, charm.RoleProvider)
+
+	got, err := s.consumer.RemoteService("remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got.Endpoints(), jc.DeepEquals, remote.Endpoints())
+	all, err := s.consumer.AllRemoteServices()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 1)
+	c.Assert(all[0].Name(), gc.Equals, "remotedb")
+
+	// The remote service is not visible from the offering environment.
+	_, err = s.State.RemoteService("remotedb")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	conn, err := s.State.OfferConnection(remote.ConnectionId())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conn, jc.DeepEquals, state.OfferConnection{
+		Id:              remote.ConnectionId(),
+		OfferURL:        s.offer.URL,
+		OfferEnvUUID:    s.State.EnvironUUID(),
+		ConsumerEnvUUID: s.consumer.EnvironUUID(),
+		ServiceName:     "remotedb",
+		User:            "user-bob",
+	})
+	side, err := conn.Side(s.State.EnvironUUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(side, gc.Equals, state.OfferingSide)
+	side, err = conn.Side(s.consumer.EnvironUUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(side, gc.Equals, state.ConsumingSide)
+
+	// Both environments see the connection.
+	for _, st := range []*state.State{s.State, s.consumer} {
+		conns, err := st.OfferConnections()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(conns, jc.DeepEquals, []state.OfferConnection{conn})
+	}
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteServiceNameInUse(c *gc.C) {
+	s.addRemoteService(c)
+	_, err := s.consumer.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:     "remotedb",
+		OfferURL: s.offer.URL,
+		Consumer: names.NewUserTag("bob"),
+	})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+
+	// Local services may not take the remote service's name, either.
+	ch := state.AddTestingCharm(c, s.consumer, "mysql")
+	_, err = s.consumer.AddService("remotedb", s.Owner.String(), ch, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add service "remotedb": service already exists`)
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteServiceSameEnvironment(c *gc.C) {
+	_, err := s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:     "remotedb",
+		OfferURL: s.offer.URL,
+		Consumer: s.Owner,
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "remotedb": offer from the same environment not valid`)
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteServiceOfferNotFound(c *gc.C) {
+	err := s.State.RemoveServiceOffer(s.offer.URL)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.consumer.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:     "remotedb",
+		OfferURL: s.offer.URL,
+		Consumer: s.Owner,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestRemoteUnitSettings(c *gc.C) {
+	remote := s.addRemoteService(c)
+	connId := remote.ConnectionId()
+
+	err := s.consumer.SetRemoteUnitSettings(connId, state.ConsumingSide, "wordpress/0", map[string]interface{}{
+		"user.name": "wp",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetRemoteUnitSettings(connId, state.OfferingSide, "mysql/0", map[string]interface{}{
+		"host": "10.0.0.1",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	settings, err := s.State.RemoteUnitSettings(connId, state.ConsumingSide)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, map[string]map[string]interface{}{
+		"wordpress/0": {"user.name": "wp"},
+	})
+	settings, err = s.consumer.RemoteUnitSettings(connId, state.OfferingSide)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, map[string]map[string]interface{}{
+		"mysql/0": {"host": "10.0.0.1"},
+	})
+
+	err = s.consumer.RemoveRemoteUnitSettings(connId, state.ConsumingSide, "wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err = s.State.RemoteUnitSettings(connId, state.ConsumingSide)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.HasLen, 0)
+}
+
+func (s *RemoteServiceSuite) TestSetRemoteUnitSettingsNoConnection(c *gc.C) {
+	err := s.State.SetRemoteUnitSettings("nowhere:nothing", state.OfferingSide, "mysql/0", nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestRemove(c *gc.C) {
+	remote := s.addRemoteService(c)
+	connId := remote.ConnectionId()
+	err := s.State.SetRemoteUnitSettings(connId, state.OfferingSide, "mysql/0", map[string]interface{}{
+		"host": "10.0.0.1",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = remote.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.consumer.RemoteService("remotedb")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.OfferConnection(connId)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	settings, err := s.consumer.RemoteUnitSettings(connId, state.OfferingSide)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.HasLen, 0)
+}
+
+func (s *RemoteServiceSuite) addConsumerRelation(c *gc.C) *state.Relation {
+	f := factory.NewFactory(s.consumer)
+	f.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: f.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	eps, err := s.consumer.InferEndpoints("wordpress", "remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.consumer.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	return rel
+}
+
+func (s *RemoteServiceSuite) TestAddRelation(c *gc.C) {
+	s.addRemoteService(c)
+	rel := s.addConsumerRelation(c)
+	c.Assert(rel.String(), gc.Equals, "wordpress:db remotedb:server")
+
+	ep, err := rel.Endpoint("remotedb")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep.Interface, gc.Equals, "mysql")
+	c.Assert(ep.Role, gc.Equals, charm.RoleProvider)
+}
+
+func (s *RemoteServiceSuite) TestAddRelationUnofferedEndpoint(c *gc.C) {
+	s.addRemoteService(c)
+	f := factory.NewFactory(s.consumer)
+	f.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: f.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	_, err := s.consumer.InferEndpoints("wordpress", "remotedb:admin")
+	c.Assert(err, gc.ErrorMatches, `remote service "remotedb" endpoint "admin" not found`)
+}
+
+func (s *RemoteServiceSuite) TestRemoveWithRelations(c *gc.C) {
+	remote := s.addRemoteService(c)
+	rel := s.addConsumerRelation(c)
+
+	err := remote.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove remote service "remotedb": service has relations`)
+
+	err = rel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = remote.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.consumer.RemoteService("remotedb")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,128 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// charmMetadataFile is the file in a charm that holds its metadata.
+const charmMetadataFile = "metadata.yaml"
+
+// ResourceMeta describes a resource declared in the resources section
+// of a charm's metadata. Only declared resources may be added to the
+// charm's services.
+type ResourceMeta struct {
+	// Name is the name the charm uses for the resource.
+	Name string `bson:"name"`
+
+	// Type identifies the kind of content the resource holds.
+	Type ResourceType `bson:"type"`
+
+	// Filename is the name under which a file resource's content is
+	// expected by the charm.
+	Filename string `bson:"filename,omitempty"`
+
+	// Description describes the resource.
+	Description string `bson:"description,omitempty"`
+}
+
+// ParseResourceMetas returns the resources declared in the resources
+// section of a charm's metadata, keyed by name. A resource's type
+// defaults to "file", and file resources must give a filename:
+//
+//	resources:
+//	  data:
+//	    type: file
+//	    filename: data.tgz
+//	    description: Data loaded into the database.
+//	  server-image:
+//	    type: oci-image
+func ParseResourceMetas(r io.Reader) (map[string]ResourceMeta, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var meta struct {
+		Resources map[string]struct {
+			Type        ResourceType `yaml:"type"`
+			Filename    string       `yaml:"filename"`
+			Description string       `yaml:"description"`
+		} `yaml:"resources"`
+	}
+	if err := goyaml.Unmarshal(data, &meta); err != nil {
+		return nil, errors.Annotate(err, "cannot parse charm metadata")
+	}
+	if len(meta.Resources) == 0 {
+		return nil, nil
+	}
+	metas := make(map[string]ResourceMeta)
+	for name, res := range meta.Resources {
+		if !IsValidResourceName(name) {
+			return nil, errors.NotValidf("resource name %q", name)
+		}
+		if res.Type == "" {
+			res.Type = ResourceTypeFile
+		}
+		if err := res.Type.Validate(); err != nil {
+			return nil, errors.Annotatef(err, "resource %q", name)
+		}
+		if res.Type == ResourceTypeFile && res.Filename == "" {
+			return nil, errors.NotValidf("file resource %q without filename", name)
+		}
+		metas[name] = ResourceMeta{
+			Name:        name,
+			Type:        res.Type,
+			Filename:    res.Filename,
+			Description: res.Description,
+		}
+	}
+	return metas, nil
+}
+
+// readCharmResourceMetas returns the resources declared in the
+// metadata of a charm directory or archive. The charm package does
+// not know about resources, so the metadata is read again here. No
+// resources are returned for charms not held in a file.
+func readCharmResourceMetas(ch charm.Charm) (map[string]ResourceMeta, error) {
+	switch ch := ch.(type) {
+	case *charm.CharmDir:
+		f, err := os.Open(filepath.Join(ch.Path, charmMetadataFile))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		defer f.Close()
+		return ParseResourceMetas(f)
+	case *charm.CharmArchive:
+		if ch.Path == "" {
+			return nil, nil
+		}
+		zipr, err := zip.OpenReader(ch.Path)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		defer zipr.Close()
+		for _, f := range zipr.File {
+			if f.Name != charmMetadataFile {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			defer r.Close()
+			return ParseResourceMetas(r)
+		}
+		return nil, errors.NotFoundf("charm metadata")
+	}
+	return nil, nil
+}
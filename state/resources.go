@@ -0,0 +1,479 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state/storage"
+)
+
+// ResourceType identifies the kind of content a resource holds.
+type ResourceType string
+
+const (
+	// ResourceTypeFile resources hold a blob, stored in the
+	// environment's storage, that units download from the API server.
+	ResourceTypeFile ResourceType = "file"
+
+	// ResourceTypeOCIImage resources hold a reference to an OCI
+	// image, such as "docker.io/library/mysql:5.7".
+	ResourceTypeOCIImage ResourceType = "oci-image"
+)
+
+// Validate returns an error if the type is not known.
+func (t ResourceType) Validate() error {
+	switch t {
+	case ResourceTypeFile, ResourceTypeOCIImage:
+		return nil
+	}
+	return errors.NotValidf("resource type %q", t)
+}
+
+// validResourceName matches the names that resources may be given.
+var validResourceName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// IsValidResourceName reports whether name is a valid resource name.
+func IsValidResourceName(name string) bool {
+	return validResourceName.MatchString(name)
+}
+
+// Resource describes a revision of a resource attached to a service,
+// which the service's units fetch from the API server rather than
+// from the internet.
+type Resource struct {
+	// ServiceName is the name of the service the resource is
+	// attached to.
+	ServiceName string
+
+	// Name is the name the service's charm uses for the resource.
+	Name string
+
+	// Type identifies the kind of content the resource holds.
+	Type ResourceType
+
+	// Revision identifies this revision of the resource. Each
+	// revision added to a service's resource is given a revision one
+	// higher than the last.
+	Revision int
+
+	// Size and SHA256 describe the blob held by a file resource.
+	Size   int64
+	SHA256 string
+
+	// StoragePath is the path in the environment's storage at which
+	// the blob of a file resource is held.
+	StoragePath string
+
+	// ImageRef is the image referred to by an OCI image resource.
+	ImageRef string
+
+	// Added is when the revision was added.
+	Added time.Time
+}
+
+// resourceDoc is the document used to store a Resource revision.
+type resourceDoc struct {
+	DocID       string       `bson:"_id"`
+	EnvUUID     string       `bson:"env-uuid"`
+	Service     string       `bson:"service"`
+	Name        string       `bson:"name"`
+	Type        ResourceType `bson:"type"`
+	Revision    int          `bson:"revision"`
+	Size        int64        `bson:"size,omitempty"`
+	SHA256      string       `bson:"sha256,omitempty"`
+	StoragePath string       `bson:"storagepath,omitempty"`
+	ImageRef    string       `bson:"imageref,omitempty"`
+	Added       time.Time    `bson:"added"`
+}
+
+func (doc *resourceDoc) resource() Resource {
+	return Resource{
+		ServiceName: doc.Service,
+		Name:        doc.Name,
+		Type:        doc.Type,
+		Revision:    doc.Revision,
+		Size:        doc.Size,
+		SHA256:      doc.SHA256,
+		StoragePath: doc.StoragePath,
+		ImageRef:    doc.ImageRef,
+		Added:       doc.Added.UTC(),
+	}
+}
+
+// resourcePinDoc records the revision of a resource that a service's
+// units are to use, in place of the latest.
+type resourcePinDoc struct {
+	DocID    string `bson:"_id"`
+	EnvUUID  string `bson:"env-uuid"`
+	Service  string `bson:"service"`
+	Name     string `bson:"name"`
+	Revision int    `bson:"revision"`
+}
+
+// resourceKey returns the key of the named resource of a service.
+func resourceKey(serviceName, name string) string {
+	return serviceName + "#" + name
+}
+
+// resourceRevisionKey returns the key of a revision of the named
+// resource of a service.
+func resourceRevisionKey(serviceName, name string, revision int) string {
+	return fmt.Sprintf("%s#%d", resourceKey(serviceName, name), revision)
+}
+
+// ResourceStoragePath returns a path at which the blob of a new revision
+// of a service's file resource may be stored.
+func ResourceStoragePath(serviceName, name string) string {
+	return fmt.Sprintf("resources/%s/%s/%s", serviceName, name, bson.NewObjectId().Hex())
+}
+
+// AddResourceArgs holds the arguments for AddResource.
+type AddResourceArgs struct {
+	// ServiceName is the name of the service to attach the resource
+	// to.
+	ServiceName string
+
+	// Name is the name the service's charm uses for the resource.
+	Name string
+
+	// Type identifies the kind of content the resource holds.
+	Type ResourceType
+
+	// Size, SHA256 and StoragePath describe the blob of a file
+	// resource, which must already be held in the environment's
+	// storage.
+	Size        int64
+	SHA256      string
+	StoragePath string
+
+	// ImageRef is the image referred to by an OCI image resource.
+	ImageRef string
+}
+
+// Validate returns an error if the arguments are not valid.
+func (args AddResourceArgs) Validate() error {
+	if !IsValidResourceName(args.Name) {
+		return errors.NotValidf("resource name %q", args.Name)
+	}
+	if err := args.Type.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	switch args.Type {
+	case ResourceTypeFile:
+		if args.StoragePath == "" || args.SHA256 == "" {
+			return errors.NotValidf("file resource without content")
+		}
+		if args.ImageRef != "" {
+			return errors.NotValidf("file resource with image reference")
+		}
+	case ResourceTypeOCIImage:
+		if args.ImageRef == "" {
+			return errors.NotValidf("OCI image resource without image reference")
+		}
+		if args.StoragePath != "" {
+			return errors.NotValidf("OCI image resource with content")
+		}
+	}
+	return nil
+}
+
+// AddResource adds a new revision of a service's resource. Unless the
+// resource is pinned to an earlier revision, the service's units will
+// use the new revision from now on.
+func (st *State) AddResource(args AddResourceArgs) (_ Resource, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add resource %q to service %q", args.Name, args.ServiceName)
+	if err := args.Validate(); err != nil {
+		return Resource{}, errors.Trace(err)
+	}
+	svc, err := st.Service(args.ServiceName)
+	if err != nil {
+		return Resource{}, errors.Trace(err)
+	}
+	if svc.Life() != Alive {
+		return Resource{}, errors.New("service is not alive")
+	}
+	curl, err := checkResourceDeclared(svc, args)
+	if err != nil {
+		return Resource{}, errors.Trace(err)
+	}
+	revision, err := st.sequence("resource#" + resourceKey(args.ServiceName, args.Name))
+	if err != nil {
+		return Resource{}, errors.Trace(err)
+	}
+	doc := &resourceDoc{
+		DocID:       st.docID(resourceRevisionKey(args.ServiceName, args.Name, revision+1)),
+		EnvUUID:     st.EnvironUUID(),
+		Service:     args.ServiceName,
+		Name:        args.Name,
+		Type:        args.Type,
+		Revision:    revision + 1,
+		Size:        args.Size,
+		SHA256:      args.SHA256,
+		StoragePath: args.StoragePath,
+		ImageRef:    args.ImageRef,
+		Added:       GetClock().Now().UTC().Round(time.Second),
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := svc.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if svc.Life() != Alive {
+				return nil, errors.New("service is not alive")
+			}
+			if curl, err = checkResourceDeclared(svc, args); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		return []txn.Op{{
+			C:      servicesC,
+			Id:     svc.doc.DocID,
+			Assert: bson.D{{"life", Alive}, {"charmurl", curl}},
+		}, {
+			C:      resourcesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return Resource{}, errors.Trace(err)
+	}
+	return doc.resource(), nil
+}
+
+// checkResourceDeclared returns an error unless the service's charm
+// declares the resource being added, with the same type. The URL of
+// the charm is returned, so that the declaration can be asserted.
+func checkResourceDeclared(svc *Service, args AddResourceArgs) (*charm.URL, error) {
+	ch, _, err := svc.Charm()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	meta, ok := ch.Resources()[args.Name]
+	if !ok {
+		msg := fmt.Sprintf("resource not declared by charm %q", ch.URL())
+		return nil, errors.NewNotValid(nil, msg)
+	}
+	if meta.Type != args.Type {
+		msg := fmt.Sprintf("charm %q declares %s resource, not %s", ch.URL(), meta.Type, args.Type)
+		return nil, errors.NewNotValid(nil, msg)
+	}
+	return ch.URL(), nil
+}
+
+// ResourceRevisions returns the revisions of a service's resource,
+// oldest first.
+func (st *State) ResourceRevisions(serviceName, name string) ([]Resource, error) {
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+	var docs []resourceDoc
+	sel := bson.D{{"service", serviceName}, {"name", name}}
+	if err := resources.Find(sel).Sort("revision").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get revisions of resource %q", name)
+	}
+	result := make([]Resource, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.resource()
+	}
+	return result, nil
+}
+
+// ServiceResource describes the revision of a resource that a
+// service's units are to use.
+type ServiceResource struct {
+	Resource
+
+	// Pinned records whether the revision has been pinned, rather
+	// than being the latest.
+	Pinned bool
+}
+
+// ServiceResources returns the revisions of its resources that a
+// service's units are to use, ordered by name.
+func (st *State) ServiceResources(serviceName string) ([]ServiceResource, error) {
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+	var docs []resourceDoc
+	sel := bson.D{{"service", serviceName}}
+	if err := resources.Find(sel).Sort("name", "-revision").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get resources of service %q", serviceName)
+	}
+	pins, err := st.resourcePins(serviceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []ServiceResource
+	for _, doc := range docs {
+		n := len(result)
+		if n == 0 || result[n-1].Name != doc.Name {
+			// This is the latest revision of the resource.
+			result = append(result, ServiceResource{Resource: doc.resource()})
+			n++
+		}
+		if revision, ok := pins[doc.Name]; ok && revision == doc.Revision {
+			result[n-1] = ServiceResource{Resource: doc.resource(), Pinned: true}
+		}
+	}
+	return result, nil
+}
+
+// ServiceResource returns the revision of the named resource that a
+// service's units are to use.
+func (st *State) ServiceResource(serviceName, name string) (ServiceResource, error) {
+	pins, err := st.resourcePins(serviceName)
+	if err != nil {
+		return ServiceResource{}, errors.Trace(err)
+	}
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+	var doc resourceDoc
+	sel := bson.D{{"service", serviceName}, {"name", name}}
+	if revision, ok := pins[name]; ok {
+		sel = append(sel, bson.DocElem{"revision", revision})
+	}
+	err = resources.Find(sel).Sort("-revision").One(&doc)
+	if err == mgo.ErrNotFound {
+		return ServiceResource{}, errors.NotFoundf("resource %q of service %q", name, serviceName)
+	} else if err != nil {
+		return ServiceResource{}, errors.Annotatef(err, "cannot get resource %q of service %q", name, serviceName)
+	}
+	_, pinned := pins[name]
+	return ServiceResource{Resource: doc.resource(), Pinned: pinned}, nil
+}
+
+// resourcePins returns the pinned revisions of a service's resources,
+// keyed by name.
+func (st *State) resourcePins(serviceName string) (map[string]int, error) {
+	resourcePins, closer := st.getCollection(resourcePinsC)
+	defer closer()
+	var docs []resourcePinDoc
+	if err := resourcePins.Find(bson.D{{"service", serviceName}}).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get pinned resources of service %q", serviceName)
+	}
+	pins := make(map[string]int)
+	for _, doc := range docs {
+		pins[doc.Name] = doc.Revision
+	}
+	return pins, nil
+}
+
+// PinResource pins a service's resource to the given revision, so that
+// the service's units use it rather than the latest revision.
+func (st *State) PinResource(serviceName, name string, revision int) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot pin resource %q of service %q", name, serviceName)
+	key := resourceKey(serviceName, name)
+	revisionId := st.docID(resourceRevisionKey(serviceName, name, revision))
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		resources, closer := st.getCollection(resourcesC)
+		defer closer()
+		if n, err := resources.FindId(revisionId).Count(); err != nil {
+			return nil, errors.Trace(err)
+		} else if n == 0 {
+			return nil, errors.NotFoundf("revision %d", revision)
+		}
+		ops := []txn.Op{{
+			C:      resourcesC,
+			Id:     revisionId,
+			Assert: txn.DocExists,
+		}}
+		pins, err := st.resourcePins(serviceName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, ok := pins[name]; ok {
+			return append(ops, txn.Op{
+				C:      resourcePinsC,
+				Id:     st.docID(key),
+				Assert: txn.DocExists,
+				Update: bson.D{{"$set", bson.D{{"revision", revision}}}},
+			}), nil
+		}
+		return append(ops, txn.Op{
+			C:      resourcePinsC,
+			Id:     st.docID(key),
+			Assert: txn.DocMissing,
+			Insert: &resourcePinDoc{
+				DocID:    st.docID(key),
+				EnvUUID:  st.EnvironUUID(),
+				Service:  serviceName,
+				Name:     name,
+				Revision: revision,
+			},
+		}), nil
+	}
+	return errors.Trace(st.run(buildTxn))
+}
+
+// UnpinResource unpins a service's resource, so that the service's
+// units use its latest revision.
+func (st *State) UnpinResource(serviceName, name string) error {
+	ops := []txn.Op{{
+		C:      resourcePinsC,
+		Id:     st.docID(resourceKey(serviceName, name)),
+		Remove: true,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot unpin resource %q of service %q", name, serviceName)
+	}
+	return nil
+}
+
+// removeResourcesOps returns the operations needed to schedule the
+// removal of a service's resources along with the service. The
+// cleanup is only scheduled if the service has resources; none can
+// be added once the service is no longer alive.
+func (st *State) removeResourcesOps(serviceName string) []txn.Op {
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+	n, err := resources.Find(bson.D{{"service", serviceName}}).Count()
+	if err == nil && n == 0 {
+		return nil
+	}
+	return []txn.Op{st.newCleanupOp(cleanupResourcesForRemovedService, serviceName)}
+}
+
+// cleanupResourcesForRemovedService removes the resources of a service
+// that has been removed, along with the blobs of its file resources.
+func (st *State) cleanupResourcesForRemovedService(serviceName string) error {
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+	var docs []resourceDoc
+	if err := resources.Find(bson.D{{"service", serviceName}}).All(&docs); err != nil {
+		return errors.Annotatef(err, "cannot get resources of service %q", serviceName)
+	}
+	stor := storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+	var ops []txn.Op
+	names := make(map[string]bool)
+	for _, doc := range docs {
+		if doc.StoragePath != "" {
+			if err := stor.Remove(doc.StoragePath); err != nil && !errors.IsNotFound(err) {
+				return errors.Annotatef(err, "cannot remove content of resource %q", doc.Name)
+			}
+		}
+		ops = append(ops, txn.Op{
+			C:      resourcesC,
+			Id:     doc.DocID,
+			Remove: true,
+		})
+		if !names[doc.Name] {
+			names[doc.Name] = true
+			ops = append(ops, txn.Op{
+				C:      resourcePinsC,
+				Id:     st.docID(resourceKey(serviceName, doc.Name)),
+				Remove: true,
+			})
+		}
+	}
+	return errors.Trace(st.runTransaction(ops))
+}
@@ -0,0 +1,302 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+)
+
+type ResourcesSuite struct {
+	ConnSuite
+	mysql *state.Service
+}
+
+var _ = gc.Suite(&ResourcesSuite{})
+
+func (s *ResourcesSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+// addFileResource stores the given content and adds it as a revision of
+// the named resource of the mysql service.
+func (s *ResourcesSuite) addFileResource(c *gc.C, name, content string) state.Resource {
+	path := state.ResourceStoragePath("mysql", name)
+	stor := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession())
+	err := stor.Put(path, strings.NewReader(content), int64(len(content)))
+	c.Assert(err, jc.ErrorIsNil)
+	res, err := s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "mysql",
+		Name:        name,
+		Type:        state.ResourceTypeFile,
+		Size:        int64(len(content)),
+		SHA256:      "sha-" + content,
+		StoragePath: path,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return res
+}
+
+func (s *ResourcesSuite) TestAddResource(c *gc.C) {
+	res := s.addFileResource(c, "data", "abc")
+	c.Assert(res.Added.IsZero(), jc.IsFalse)
+	c.Assert(res.StoragePath, gc.Matches, "resources/mysql/data/[0-9a-f]+")
+	c.Assert(res.Type, gc.Equals, state.ResourceTypeFile)
+	c.Assert(res.Revision, gc.Equals, 1)
+	c.Assert(res.Size, gc.Equals, int64(3))
+	c.Assert(res.SHA256, gc.Equals, "sha-abc")
+
+	revisions, err := s.State.ResourceRevisions("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, gc.HasLen, 1)
+	c.Assert(revisions[0].StoragePath, gc.Equals, res.StoragePath)
+	c.Assert(revisions[0].Added.Equal(res.Added), jc.IsTrue)
+}
+
+func (s *ResourcesSuite) TestAddResourceRevisions(c *gc.C) {
+	s.addFileResource(c, "data", "abc")
+	res := s.addFileResource(c, "data", "def")
+	c.Assert(res.Revision, gc.Equals, 2)
+
+	revisions, err := s.State.ResourceRevisions("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, gc.HasLen, 2)
+	c.Assert(revisions[0].Revision, gc.Equals, 1)
+	c.Assert(revisions[1].Revision, gc.Equals, 2)
+
+	current, err := s.State.ServiceResource("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, 2)
+	c.Assert(current.Pinned, jc.IsFalse)
+}
+
+func (s *ResourcesSuite) TestAddOCIImageResource(c *gc.C) {
+	res, err := s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "mysql",
+		Name:        "server-image",
+		Type:        state.ResourceTypeOCIImage,
+		ImageRef:    "docker.io/library/mysql:5.7",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Revision, gc.Equals, 1)
+	c.Assert(res.ImageRef, gc.Equals, "docker.io/library/mysql:5.7")
+	c.Assert(res.StoragePath, gc.Equals, "")
+}
+
+func (s *ResourcesSuite) TestAddResourceInvalid(c *gc.C) {
+	for i, test := range []struct {
+		args state.AddResourceArgs
+		err  string
+	}{{
+		args: state.AddResourceArgs{Name: "Data", Type: state.ResourceTypeFile},
+		err:  `resource name "Data" not valid`,
+	}, {
+		args: state.AddResourceArgs{Name: "data", Type: "tarball"},
+		err:  `resource type "tarball" not valid`,
+	}, {
+		args: state.AddResourceArgs{Name: "data", Type: state.ResourceTypeFile},
+		err:  `file resource without content not valid`,
+	}, {
+		args: state.AddResourceArgs{Name: "data", Type: state.ResourceTypeOCIImage},
+		err:  `OCI image resource without image reference not valid`,
+	}, {
+		args: state.AddResourceArgs{
+			Name:        "data",
+			Type:        state.ResourceTypeOCIImage,
+			ImageRef:    "mysql",
+			StoragePath: "resources/mysql/data/1",
+		},
+		err: `OCI image resource with content not valid`,
+	}} {
+		c.Logf("test %d", i)
+		test.args.ServiceName = "mysql"
+		_, err := s.State.AddResource(test.args)
+		c.Check(err, gc.ErrorMatches, `cannot add resource ".*" to service "mysql": `+test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *ResourcesSuite) TestAddResourceServiceNotAlive(c *gc.C) {
+	_, err := s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "mysql",
+		Name:        "image",
+		Type:        state.ResourceTypeOCIImage,
+		ImageRef:    "mysql",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add resource "image" to service "mysql": service is not alive`)
+}
+
+func (s *ResourcesSuite) TestAddResourceNotDeclared(c *gc.C) {
+	_, err := s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "mysql",
+		Name:        "backup-image",
+		Type:        state.ResourceTypeOCIImage,
+		ImageRef:    "mysql",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add resource "backup-image" to service "mysql": resource not declared by charm "local:quantal/quantal-mysql-[0-9]+"`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	_, err = s.State.AddResource(state.AddResourceArgs{
+		ServiceName: "mysql",
+		Name:        "data",
+		Type:        state.ResourceTypeOCIImage,
+		ImageRef:    "mysql",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add resource "data" to service "mysql": charm "local:quantal/quantal-mysql-[0-9]+" declares file resource, not oci-image`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *ResourcesSuite) TestCharmResources(c *gc.C) {
+	ch, _, err := s.mysql.Charm()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Resources(), jc.DeepEquals, map[string]state.ResourceMeta{
+		"data": {
+			Name:        "data",
+			Type:        state.ResourceTypeFile,
+			Filename:    "data.tgz",
+			Description: "Data loaded into the database",
+		},
+		"config": {
+			Name:     "config",
+			Type:     state.ResourceTypeFile,
+			Filename: "my.cnf",
+		},
+		"image": {
+			Name: "image",
+			Type: state.ResourceTypeOCIImage,
+		},
+		"server-image": {
+			Name: "server-image",
+			Type: state.ResourceTypeOCIImage,
+		},
+	})
+}
+
+func (s *ResourcesSuite) TestParseResourceMetas(c *gc.C) {
+	metas, err := state.ParseResourceMetas(strings.NewReader(`
+name: dummy
+resources:
+  data:
+    filename: data.tgz
+  image:
+    type: oci-image
+    description: The server image
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metas, jc.DeepEquals, map[string]state.ResourceMeta{
+		"data":  {Name: "data", Type: state.ResourceTypeFile, Filename: "data.tgz"},
+		"image": {Name: "image", Type: state.ResourceTypeOCIImage, Description: "The server image"},
+	})
+
+	metas, err = state.ParseResourceMetas(strings.NewReader("name: dummy\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metas, gc.HasLen, 0)
+}
+
+func (s *ResourcesSuite) TestParseResourceMetasInvalid(c *gc.C) {
+	for i, test := range []struct {
+		resources string
+		err       string
+	}{{
+		resources: "Data: {filename: data.tgz}",
+		err:       `resource name "Data" not valid`,
+	}, {
+		resources: "data: {type: tarball}",
+		err:       `resource "data": resource type "tarball" not valid`,
+	}, {
+		resources: "data: {type: file}",
+		err:       `file resource "data" without filename not valid`,
+	}, {
+		resources: "[data]",
+		err:       "cannot parse charm metadata: .*",
+	}} {
+		c.Logf("test %d", i)
+		_, err := state.ParseResourceMetas(strings.NewReader("resources: " + test.resources + "\n"))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ResourcesSuite) TestServiceResourceNotFound(c *gc.C) {
+	_, err := s.State.ServiceResource("mysql", "data")
+	c.Assert(err, gc.ErrorMatches, `resource "data" of service "mysql" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ResourcesSuite) TestPinResource(c *gc.C) {
+	s.addFileResource(c, "data", "abc")
+	s.addFileResource(c, "data", "def")
+	s.addFileResource(c, "config", "xyz")
+
+	err := s.State.PinResource("mysql", "data", 1)
+	c.Assert(err, jc.ErrorIsNil)
+	current, err := s.State.ServiceResource("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, 1)
+	c.Assert(current.Pinned, jc.IsTrue)
+
+	// A new revision does not replace a pinned one.
+	s.addFileResource(c, "data", "ghi")
+	resources, err := s.State.ServiceResources("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, gc.HasLen, 2)
+	c.Assert(resources[0].Name, gc.Equals, "config")
+	c.Assert(resources[0].Revision, gc.Equals, 1)
+	c.Assert(resources[0].Pinned, jc.IsFalse)
+	c.Assert(resources[1].Name, gc.Equals, "data")
+	c.Assert(resources[1].Revision, gc.Equals, 1)
+	c.Assert(resources[1].Pinned, jc.IsTrue)
+
+	// Pinning again moves the pin.
+	err = s.State.PinResource("mysql", "data", 2)
+	c.Assert(err, jc.ErrorIsNil)
+	current, err = s.State.ServiceResource("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, 2)
+
+	err = s.State.UnpinResource("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	current, err = s.State.ServiceResource("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.Revision, gc.Equals, 3)
+	c.Assert(current.Pinned, jc.IsFalse)
+}
+
+func (s *ResourcesSuite) TestPinResourceRevisionNotFound(c *gc.C) {
+	s.addFileResource(c, "data", "abc")
+	err := s.State.PinResource("mysql", "data", 2)
+	c.Assert(err, gc.ErrorMatches, `cannot pin resource "data" of service "mysql": revision 2 not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ResourcesSuite) TestRemoveServiceRemovesResources(c *gc.C) {
+	res := s.addFileResource(c, "data", "abc")
+	err := s.State.PinResource("mysql", "data", 1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	needsCleanup, err := s.State.NeedsCleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(needsCleanup, jc.IsFalse)
+
+	revisions, err := s.State.ResourceRevisions("mysql", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, gc.HasLen, 0)
+	stor := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession())
+	_, _, err = stor.Get(res.StoragePath)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+)
+
+// MaxRunResultOutput is the number of bytes of each of a target's
+// stdout and stderr that are recorded by AddRunResults. Any more
+// output is dropped, so that the results of a command with copious
+// output cannot grow a document beyond mongo's limit.
+const MaxRunResultOutput = 64 * 1024
+
+// RunResult holds the outcome of running commands on a single
+// machine or unit.
+type RunResult struct {
+	MachineId string `bson:"machineid"`
+	UnitId    string `bson:"unitid,omitempty"`
+	Stdout    []byte `bson:"stdout"`
+	Stderr    []byte `bson:"stderr"`
+	Code      int    `bson:"code"`
+	Error     string `bson:"error,omitempty"`
+
+	// Truncated is true if some of the output was dropped when the
+	// result was recorded; see MaxRunResultOutput.
+	Truncated bool `bson:"truncated,omitempty"`
+}
+
+// runResultsDoc records the results of a "juju run" invocation
+// across all of its targets, so that they can be retrieved later.
+type runResultsDoc struct {
+	DocID    string      `bson:"_id"`
+	Id       string      `bson:"id"`
+	EnvUUID  string      `bson:"env-uuid"`
+	Commands string      `bson:"commands"`
+	Started  time.Time   `bson:"started"`
+	Results  []RunResult `bson:"results"`
+}
+
+// RunResults holds the recorded results of a "juju run" invocation.
+type RunResults struct {
+	doc runResultsDoc
+}
+
+// Id returns the id under which the results were recorded.
+func (r *RunResults) Id() string {
+	return r.doc.Id
+}
+
+// Commands returns the commands that were run.
+func (r *RunResults) Commands() string {
+	return r.doc.Commands
+}
+
+// Started returns the time at which the commands were started.
+func (r *RunResults) Started() time.Time {
+	return r.doc.Started
+}
+
+// Results returns the result for each target the commands were run on.
+func (r *RunResults) Results() []RunResult {
+	return r.doc.Results
+}
+
+// AddRunResults records the results of running the given commands,
+// which were started at the given time, and returns the id with which
+// they can be retrieved. Only the first MaxRunResultOutput bytes of
+// each target's stdout and stderr are kept. The results are pruned
+// as history (see RunResultsHistory), so they are inserted without a
+// transaction.
+func (st *State) AddRunResults(commands string, started time.Time, results []RunResult) (string, error) {
+	seq, err := st.sequence("run")
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	id := fmt.Sprint(seq)
+	capped := make([]RunResult, len(results))
+	for i, result := range results {
+		if len(result.Stdout) > MaxRunResultOutput {
+			result.Stdout = result.Stdout[:MaxRunResultOutput]
+			result.Truncated = true
+		}
+		if len(result.Stderr) > MaxRunResultOutput {
+			result.Stderr = result.Stderr[:MaxRunResultOutput]
+			result.Truncated = true
+		}
+		capped[i] = result
+	}
+	doc := runResultsDoc{
+		DocID:    st.docID(id),
+		Id:       id,
+		EnvUUID:  st.EnvironUUID(),
+		Commands: commands,
+		Started:  started.UTC(),
+		Results:  capped,
+	}
+	runResults, closer := st.getCollection(runResultsC)
+	defer closer()
+	if err := runResults.Writeable().Insert(&doc); err != nil {
+		return "", errors.Annotate(err, "cannot add run results")
+	}
+	return id, nil
+}
+
+// RunResults returns the results recorded with the given id.
+func (st *State) RunResults(id string) (*RunResults, error) {
+	runResults, closer := st.getCollection(runResultsC)
+	defer closer()
+
+	var doc runResultsDoc
+	err := runResults.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("run results %q", id)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get run results %q", id)
+	}
+	return &RunResults{doc}, nil
+}
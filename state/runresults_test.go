@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type RunResultsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&RunResultsSuite{})
+
+func (s *RunResultsSuite) TestRunResultsNotFound(c *gc.C) {
+	_, err := s.State.RunResults("1")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `run results "1" not found`)
+}
+
+func (s *RunResultsSuite) TestAddRunResults(c *gc.C) {
+	started := time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	results := []state.RunResult{{
+		MachineId: "0",
+		Stdout:    []byte("out"),
+		Stderr:    []byte("err"),
+	}, {
+		MachineId: "1",
+		UnitId:    "mysql/0",
+		Stdout:    []byte("out"),
+		Stderr:    []byte("err"),
+		Code:      1,
+		Error:     "boom",
+	}}
+	id, err := s.State.AddRunResults("hostname", started, results)
+	c.Assert(err, jc.ErrorIsNil)
+
+	runResults, err := s.State.RunResults(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(runResults.Id(), gc.Equals, id)
+	c.Assert(runResults.Commands(), gc.Equals, "hostname")
+	c.Assert(runResults.Started().Equal(started), jc.IsTrue)
+	c.Assert(runResults.Results(), jc.DeepEquals, results)
+
+	// Each set of results is recorded under a new id.
+	id2, err := s.State.AddRunResults("uptime", started, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(id2, gc.Not(gc.Equals), id)
+}
+
+func (s *RunResultsSuite) TestAddRunResultsTruncatesOutput(c *gc.C) {
+	long := bytes.Repeat([]byte("x"), state.MaxRunResultOutput+1)
+	id, err := s.State.AddRunResults("yes", time.Now(), []state.RunResult{{
+		MachineId: "0",
+		Stdout:    long,
+		Stderr:    []byte("err"),
+	}, {
+		MachineId: "1",
+		Stdout:    []byte("out"),
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	runResults, err := s.State.RunResults(id)
+	c.Assert(err, jc.ErrorIsNil)
+	results := runResults.Results()
+	c.Assert(results, gc.HasLen, 2)
+	c.Check(results[0].Stdout, jc.DeepEquals, long[:state.MaxRunResultOutput])
+	c.Check(results[0].Stderr, jc.DeepEquals, []byte("err"))
+	c.Check(results[0].Truncated, jc.IsTrue)
+	c.Check(results[1].Stdout, jc.DeepEquals, []byte("out"))
+	c.Check(results[1].Truncated, jc.IsFalse)
+}
@@ -228,11 +228,12 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 		removeRequestedNetworksOp(s.st, s.globalKey()),
 		removeStorageConstraintsOp(s.globalKey()),
 		removeConstraintsOp(s.st, s.globalKey()),
+		removeEndpointBindingsOp(s.st, s.globalKey()),
 		annotationRemoveOp(s.st, s.globalKey()),
 		removeLeadershipSettingsOp(s.Tag().Id()),
 		removeStatusOp(s.st, s.globalKey()),
 	}
-	return ops
+	return append(ops, s.st.removeResourcesOps(s.doc.Name)...)
 }
 
 // IsExposed returns whether this service is exposed. The explicitly open
@@ -852,6 +853,7 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 		removeStatusOp(s.st, u.globalKey()),
 		removeConstraintsOp(s.st, u.globalAgentKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
+		removeUnitStateOp(s.st, u.doc.Name),
 		s.st.newCleanupOp(cleanupRemovedUnit, u.doc.Name),
 	)
 	ops = append(ops, portsOps...)
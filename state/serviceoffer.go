@@ -0,0 +1,278 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// ServiceOffer records that some of a service's endpoints are offered
+// for use by services in other environments.
+type ServiceOffer struct {
+	// URL uniquely identifies the offer.
+	URL string
+
+	// EnvUUID identifies the environment of the offered service.
+	EnvUUID string
+
+	// ServiceName is the name of the offered service.
+	ServiceName string
+
+	// Endpoints holds the names of the offered endpoints.
+	Endpoints []string
+
+	// Description describes the offer to potential consumers.
+	Description string
+
+	// Owner is the tag of the user who made the offer.
+	Owner string
+
+	// Users holds the tags of the users, other than the owner, who
+	// may consume the offer.
+	Users []string
+}
+
+// CanConsume reports whether the given user may consume the offer.
+func (o ServiceOffer) CanConsume(user names.UserTag) bool {
+	if o.Owner == user.String() {
+		return true
+	}
+	for _, u := range o.Users {
+		if u == user.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceOfferDoc is the document used to store a ServiceOffer.
+type serviceOfferDoc struct {
+	URL         string   `bson:"_id"`
+	EnvUUID     string   `bson:"env-uuid"`
+	ServiceName string   `bson:"service"`
+	Endpoints   []string `bson:"endpoints"`
+	Description string   `bson:"description"`
+	Owner       string   `bson:"owner"`
+	Users       []string `bson:"users"`
+}
+
+func (doc *serviceOfferDoc) offer() ServiceOffer {
+	return ServiceOffer{
+		URL:         doc.URL,
+		EnvUUID:     doc.EnvUUID,
+		ServiceName: doc.ServiceName,
+		Endpoints:   doc.Endpoints,
+		Description: doc.Description,
+		Owner:       doc.Owner,
+		Users:       doc.Users,
+	}
+}
+
+// ServiceOfferURL returns the URL of the offer with the given name,
+// made by the given user from the named environment.
+func ServiceOfferURL(owner names.UserTag, envName, offerName string) string {
+	return fmt.Sprintf("local:/u/%s/%s/%s", owner.Name(), envName, offerName)
+}
+
+// AddServiceOfferArgs holds the arguments for AddServiceOffer.
+type AddServiceOfferArgs struct {
+	// OfferName names the offer; it must be a valid service name, and
+	// be unique among the owner's offers from the environment.
+	OfferName string
+
+	// ServiceName is the name of the service to offer.
+	ServiceName string
+
+	// Endpoints holds the names of the service's endpoints to offer.
+	Endpoints []string
+
+	// Description describes the offer to potential consumers.
+	Description string
+
+	// Owner is the user making the offer.
+	Owner names.UserTag
+
+	// Users holds the users, other than the owner, who may consume
+	// the offer.
+	Users []names.UserTag
+}
+
+// AddServiceOffer offers endpoints of a service in this environment
+// for use by services in other environments.
+func (st *State) AddServiceOffer(args AddServiceOfferArgs) (_ ServiceOffer, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot offer service %q", args.ServiceName)
+	if !names.IsValidService(args.OfferName) {
+		return ServiceOffer{}, errors.NotValidf("offer name %q", args.OfferName)
+	}
+	if len(args.Endpoints) == 0 {
+		return ServiceOffer{}, errors.NotValidf("offer with no endpoints")
+	}
+	svc, err := st.Service(args.ServiceName)
+	if err != nil {
+		return ServiceOffer{}, errors.Trace(err)
+	}
+	for _, name := range args.Endpoints {
+		ep, err := svc.Endpoint(name)
+		if err != nil {
+			return ServiceOffer{}, errors.Trace(err)
+		}
+		if ep.Role == charm.RolePeer {
+			return ServiceOffer{}, errors.NotValidf("offer of peer endpoint %q", name)
+		}
+	}
+	if _, err := st.EnvironmentUser(args.Owner); err != nil {
+		return ServiceOffer{}, errors.Trace(err)
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return ServiceOffer{}, errors.Trace(err)
+	}
+	doc := &serviceOfferDoc{
+		URL:         ServiceOfferURL(args.Owner, env.Name(), args.OfferName),
+		EnvUUID:     st.EnvironUUID(),
+		ServiceName: args.ServiceName,
+		Endpoints:   args.Endpoints,
+		Description: args.Description,
+		Owner:       args.Owner.String(),
+		Users:       userTagStrings(args.Users),
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := svc.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if _, err := st.ServiceOffer(doc.URL); err == nil {
+				return nil, errors.AlreadyExistsf("offer %q", doc.URL)
+			} else if !errors.IsNotFound(err) {
+				return nil, errors.Trace(err)
+			}
+		}
+		if svc.Life() != Alive {
+			return nil, errors.New("service is not alive")
+		}
+		return []txn.Op{{
+			C:      servicesC,
+			Id:     svc.doc.DocID,
+			Assert: isAliveDoc,
+		}, {
+			C:      serviceOffersC,
+			Id:     doc.URL,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return ServiceOffer{}, errors.Trace(err)
+	}
+	return doc.offer(), nil
+}
+
+// ServiceOffer returns the offer with the given URL.
+func (st *State) ServiceOffer(url string) (ServiceOffer, error) {
+	offers, closer := st.getCollection(serviceOffersC)
+	defer closer()
+	var doc serviceOfferDoc
+	err := offers.FindId(url).One(&doc)
+	if err == mgo.ErrNotFound {
+		return ServiceOffer{}, errors.NotFoundf("offer %q", url)
+	} else if err != nil {
+		return ServiceOffer{}, errors.Annotatef(err, "cannot get offer %q", url)
+	}
+	return doc.offer(), nil
+}
+
+// ServiceOfferFilter restricts the offers returned by ServiceOffers.
+// Empty fields match any offer.
+type ServiceOfferFilter struct {
+	EnvUUID     string
+	ServiceName string
+}
+
+// ServiceOffers returns the offers, from all environments, that match
+// the filter, ordered by URL.
+func (st *State) ServiceOffers(filter ServiceOfferFilter) ([]ServiceOffer, error) {
+	offers, closer := st.getCollection(serviceOffersC)
+	defer closer()
+	query := bson.D{}
+	if filter.EnvUUID != "" {
+		query = append(query, bson.DocElem{"env-uuid", filter.EnvUUID})
+	}
+	if filter.ServiceName != "" {
+		query = append(query, bson.DocElem{"service", filter.ServiceName})
+	}
+	var docs []serviceOfferDoc
+	if err := offers.Find(query).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get offers")
+	}
+	result := make([]ServiceOffer, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.offer()
+	}
+	return result, nil
+}
+
+// RemoveServiceOffer removes the offer with the given URL, which must
+// have been made from this environment. Services in other environments
+// that already consume the offer are unaffected.
+func (st *State) RemoveServiceOffer(url string) error {
+	offer, err := st.ServiceOffer(url)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if offer.EnvUUID != st.EnvironUUID() {
+		return errors.NotFoundf("offer %q in this environment", url)
+	}
+	ops := []txn.Op{{
+		C:      serviceOffersC,
+		Id:     url,
+		Remove: true,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove offer %q", url)
+	}
+	return nil
+}
+
+// GrantOfferAccess lets the given user consume the offer with the
+// given URL.
+func (st *State) GrantOfferAccess(url string, user names.UserTag) error {
+	return st.updateOfferUsers(url, bson.D{{"$addToSet", bson.D{{"users", user.String()}}}})
+}
+
+// RevokeOfferAccess stops the given user from consuming the offer with
+// the given URL. The offer's owner may always consume it.
+func (st *State) RevokeOfferAccess(url string, user names.UserTag) error {
+	return st.updateOfferUsers(url, bson.D{{"$pull", bson.D{{"users", user.String()}}}})
+}
+
+func (st *State) updateOfferUsers(url string, update bson.D) error {
+	ops := []txn.Op{{
+		C:      serviceOffersC,
+		Id:     url,
+		Assert: txn.DocExists,
+		Update: update,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("offer %q", url)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot update users of offer %q", url)
+	}
+	return nil
+}
+
+func userTagStrings(tags []names.UserTag) []string {
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.String()
+	}
+	return result
+}
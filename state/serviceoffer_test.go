@@ -0,0 +1,164 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type ServiceOfferSuite struct {
+	ConnSuite
+	url string
+}
+
+var _ = gc.Suite(&ServiceOfferSuite{})
+
+func (s *ServiceOfferSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	s.url = state.ServiceOfferURL(s.Owner, env.Name(), "db")
+}
+
+func (s *ServiceOfferSuite) addOffer(c *gc.C, users ...names.UserTag) state.ServiceOffer {
+	offer, err := s.State.AddServiceOffer(state.AddServiceOfferArgs{
+		OfferName:   "db",
+		ServiceName: "mysql",
+		Endpoints:   []string{"server"},
+		Description: "a database",
+		Owner:       s.Owner,
+		Users:       users,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return offer
+}
+
+func (s *ServiceOfferSuite) TestAddServiceOffer(c *gc.C) {
+	offer := s.addOffer(c, names.NewUserTag("bob"))
+	c.Assert(offer, jc.DeepEquals, state.ServiceOffer{
+		URL:         s.url,
+		EnvUUID:     s.State.EnvironUUID(),
+		ServiceName: "mysql",
+		Endpoints:   []string{"server"},
+		Description: "a database",
+		Owner:       s.Owner.String(),
+		Users:       []string{"user-bob"},
+	})
+
+	got, err := s.State.ServiceOffer(s.url)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, offer)
+}
+
+func (s *ServiceOfferSuite) TestAddServiceOfferTwice(c *gc.C) {
+	s.addOffer(c)
+	_, err := s.State.AddServiceOffer(state.AddServiceOfferArgs{
+		OfferName:   "db",
+		ServiceName: "mysql",
+		Endpoints:   []string{"server"},
+		Owner:       s.Owner,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *ServiceOfferSuite) TestAddServiceOfferInvalid(c *gc.C) {
+	for i, test := range []struct {
+		args state.AddServiceOfferArgs
+		err  string
+	}{{
+		args: state.AddServiceOfferArgs{OfferName: "bad_name", ServiceName: "mysql", Endpoints: []string{"server"}},
+		err:  `cannot offer service "mysql": offer name "bad_name" not valid`,
+	}, {
+		args: state.AddServiceOfferArgs{OfferName: "db", ServiceName: "mysql"},
+		err:  `cannot offer service "mysql": offer with no endpoints not valid`,
+	}, {
+		args: state.AddServiceOfferArgs{OfferName: "db", ServiceName: "wordpress", Endpoints: []string{"db"}},
+		err:  `cannot offer service "wordpress": service "wordpress" not found`,
+	}, {
+		args: state.AddServiceOfferArgs{OfferName: "db", ServiceName: "mysql", Endpoints: []string{"db"}},
+		err:  `cannot offer service "mysql": service "mysql" has no "db" relation`,
+	}} {
+		c.Logf("test %d", i)
+		test.args.Owner = s.Owner
+		_, err := s.State.AddServiceOffer(test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ServiceOfferSuite) TestServiceOfferNotFound(c *gc.C) {
+	_, err := s.State.ServiceOffer(s.url)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ServiceOfferSuite) TestServiceOffers(c *gc.C) {
+	offer := s.addOffer(c)
+	other := s.Factory.MakeEnvironment(c, nil)
+	defer other.Close()
+
+	for i, test := range []struct {
+		filter state.ServiceOfferFilter
+		expect []state.ServiceOffer
+	}{{
+		filter: state.ServiceOfferFilter{},
+		expect: []state.ServiceOffer{offer},
+	}, {
+		filter: state.ServiceOfferFilter{EnvUUID: s.State.EnvironUUID(), ServiceName: "mysql"},
+		expect: []state.ServiceOffer{offer},
+	}, {
+		filter: state.ServiceOfferFilter{EnvUUID: other.EnvironUUID()},
+		expect: []state.ServiceOffer{},
+	}, {
+		filter: state.ServiceOfferFilter{ServiceName: "wordpress"},
+		expect: []state.ServiceOffer{},
+	}} {
+		c.Logf("test %d", i)
+		// Offers are global, so are visible from any environment.
+		offers, err := other.ServiceOffers(test.filter)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(offers, jc.DeepEquals, test.expect)
+	}
+}
+
+func (s *ServiceOfferSuite) TestRemoveServiceOffer(c *gc.C) {
+	s.addOffer(c)
+	other := s.Factory.MakeEnvironment(c, nil)
+	defer other.Close()
+	err := other.RemoveServiceOffer(s.url)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.RemoveServiceOffer(s.url)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.ServiceOffer(s.url)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ServiceOfferSuite) TestOfferAccess(c *gc.C) {
+	bob := names.NewUserTag("bob")
+	s.addOffer(c)
+	offer, err := s.State.ServiceOffer(s.url)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.CanConsume(s.Owner), jc.IsTrue)
+	c.Assert(offer.CanConsume(bob), jc.IsFalse)
+
+	err = s.State.GrantOfferAccess(s.url, bob)
+	c.Assert(err, jc.ErrorIsNil)
+	offer, err = s.State.ServiceOffer(s.url)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.CanConsume(bob), jc.IsTrue)
+
+	err = s.State.RevokeOfferAccess(s.url, bob)
+	c.Assert(err, jc.ErrorIsNil)
+	offer, err = s.State.ServiceOffer(s.url)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.CanConsume(bob), jc.IsFalse)
+
+	err = s.State.GrantOfferAccess("local:/u/admin/nowhere/nothing", bob)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
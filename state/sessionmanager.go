@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/mongo"
+)
+
+// sessionCheckPeriod is the interval between checks of the state's
+// mongo session. It is patched by tests.
+var sessionCheckPeriod = 10 * time.Second
+
+// sessionManager keeps the state's root mongo session healthy. The
+// sessions used for each operation are copies of the root session and
+// share its connection to the primary, so when the connection is lost
+// or the replica set elects a new primary every copy fails until the
+// root session is refreshed. The manager pings the session and follows
+// the replica set's primary, refreshing the session as soon as either
+// goes wrong rather than waiting for the failures to cascade into
+// workers being restarted.
+type sessionManager struct {
+	tomb    tomb.Tomb
+	session *mgo.Session
+	primary string
+}
+
+// newSessionManager starts a sessionManager for the given root session.
+func newSessionManager(session *mgo.Session) *sessionManager {
+	m := &sessionManager{session: session}
+	go func() {
+		defer m.tomb.Done()
+		m.tomb.Kill(m.loop())
+	}()
+	return m
+}
+
+// Stop stops the manager and returns any error it encountered.
+func (m *sessionManager) Stop() error {
+	m.tomb.Kill(nil)
+	return errors.Trace(m.tomb.Wait())
+}
+
+func (m *sessionManager) loop() error {
+	m.primary, _ = replicaSetPrimary(m.session)
+	for {
+		select {
+		case <-m.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(sessionCheckPeriod):
+			m.check()
+		}
+	}
+}
+
+// check pings the session, refreshing it if the connection has been
+// lost, and refreshes it if the replica set's primary has changed.
+// Failures are only logged: mongo may take a while to elect a primary,
+// and the session is checked again later.
+func (m *sessionManager) check() {
+	if err := pingSession(m.session); err != nil {
+		logger.Warningf("mongo session is not usable: %v", err)
+		return
+	}
+	primary, err := replicaSetPrimary(m.session)
+	if err != nil {
+		logger.Debugf("cannot determine mongo primary: %v", err)
+		return
+	}
+	if primary == m.primary {
+		return
+	}
+	if m.primary != "" {
+		logger.Infof("mongo primary changed from %q to %q; refreshing session", m.primary, primary)
+		m.session.Refresh()
+	}
+	m.primary = primary
+}
+
+// pingSession pings the session. If the connection to mongo has been
+// lost, the error is still returned, so that the caller knows, but the
+// session is refreshed so that later operations connect to the current
+// primary.
+func pingSession(session *mgo.Session) error {
+	err := session.Ping()
+	if err != nil && mongo.IsConnectionError(err) {
+		logger.Debugf("refreshing mongo session after ping failed: %v", err)
+		session.Refresh()
+	}
+	return errors.Trace(err)
+}
+
+// replicaSetPrimary returns the address of the replica set's primary,
+// or "" if mongo is not running as a replica set.
+func replicaSetPrimary(session *mgo.Session) (string, error) {
+	var result struct {
+		Primary string `bson:"primary"`
+	}
+	if err := session.Run("isMaster", &result); err != nil {
+		return "", errors.Trace(err)
+	}
+	return result.Primary, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// sshHostKeysDoc records the SSH host keys reported by a machine's
+// agent, so that clients connecting to the machine can verify them.
+type sshHostKeysDoc struct {
+	DocID     string `bson:"_id"`
+	EnvUUID   string `bson:"env-uuid"`
+	MachineId string `bson:"machineid"`
+
+	// Keys holds the machine's public host keys, in the
+	// authorized_keys format.
+	Keys []string `bson:"keys"`
+}
+
+// SSHHostKeys returns the SSH host keys reported by the machine's
+// agent. It returns an error satisfying errors.IsNotFound if no keys
+// have been reported yet.
+func (m *Machine) SSHHostKeys() ([]string, error) {
+	sshHostKeys, closer := m.st.getCollection(sshHostKeysC)
+	defer closer()
+
+	var doc sshHostKeysDoc
+	err := sshHostKeys.FindId(m.doc.Id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("ssh host keys for machine %q", m)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get ssh host keys for machine %q", m)
+	}
+	return doc.Keys, nil
+}
+
+// SetSSHHostKeys records the SSH host keys of the machine, replacing
+// any that were recorded before.
+func (m *Machine) SetSSHHostKeys(keys []string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set ssh host keys for machine %q", m)
+	if len(keys) == 0 {
+		return errors.NotValidf("empty key list")
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Life == Dead {
+			return nil, ErrDead
+		}
+		ops := []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: notDeadDoc,
+		}}
+		existing, err := m.SSHHostKeys()
+		if errors.IsNotFound(err) {
+			return append(ops, txn.Op{
+				C:      sshHostKeysC,
+				Id:     m.st.docID(m.doc.Id),
+				Assert: txn.DocMissing,
+				Insert: &sshHostKeysDoc{
+					DocID:     m.st.docID(m.doc.Id),
+					EnvUUID:   m.st.EnvironUUID(),
+					MachineId: m.doc.Id,
+					Keys:      keys,
+				},
+			}), nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if stringSlicesEqual(existing, keys) {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return append(ops, txn.Op{
+			C:      sshHostKeysC,
+			Id:     m.st.docID(m.doc.Id),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"keys", keys}}}},
+		}), nil
+	}
+	return m.st.run(buildTxn)
+}
+
+// removeSSHHostKeysOp returns the operation that removes the SSH host
+// keys recorded for the machine, if any.
+func removeSSHHostKeysOp(st *State, machineId string) txn.Op {
+	return txn.Op{
+		C:      sshHostKeysC,
+		Id:     st.docID(machineId),
+		Remove: true,
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type SSHHostKeysSuite struct {
+	ConnSuite
+	machine *state.Machine
+}
+
+var _ = gc.Suite(&SSHHostKeysSuite{})
+
+func (s *SSHHostKeysSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.machine = factory.NewFactory(s.State).MakeMachine(c, nil)
+}
+
+func (s *SSHHostKeysSuite) TestSSHHostKeysNotFound(c *gc.C) {
+	_, err := s.machine.SSHHostKeys()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `ssh host keys for machine "0" not found`)
+}
+
+func (s *SSHHostKeysSuite) TestSetSSHHostKeys(c *gc.C) {
+	err := s.machine.SetSSHHostKeys([]string{"ssh-rsa rsa", "ssh-ed25519 ed25519"})
+	c.Assert(err, jc.ErrorIsNil)
+	keys, err := s.machine.SSHHostKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, jc.DeepEquals, []string{"ssh-rsa rsa", "ssh-ed25519 ed25519"})
+
+	// Setting the same keys again is a no-op.
+	err = s.machine.SetSSHHostKeys([]string{"ssh-rsa rsa", "ssh-ed25519 ed25519"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.SetSSHHostKeys([]string{"ssh-rsa new"})
+	c.Assert(err, jc.ErrorIsNil)
+	keys, err = s.machine.SSHHostKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, jc.DeepEquals, []string{"ssh-rsa new"})
+}
+
+func (s *SSHHostKeysSuite) TestSetSSHHostKeysEmpty(c *gc.C) {
+	err := s.machine.SetSSHHostKeys(nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set ssh host keys for machine "0": empty key list not valid`)
+}
+
+func (s *SSHHostKeysSuite) TestSetSSHHostKeysDead(c *gc.C) {
+	err := s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetSSHHostKeys([]string{"ssh-rsa rsa"})
+	c.Assert(err, gc.ErrorMatches, `cannot set ssh host keys for machine "0": not found or dead`)
+}
+
+func (s *SSHHostKeysSuite) TestRemoveMachineRemovesSSHHostKeys(c *gc.C) {
+	err := s.machine.SetSSHHostKeys([]string{"ssh-rsa rsa"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.machine.SSHHostKeys()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
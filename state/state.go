@@ -66,11 +66,18 @@ type State struct {
 	database   Database
 	policy     Policy
 
+	// readMirror is true for a State returned by ReadMirror, which
+	// shares the watchers and workers below with the State it mirrors
+	// and so does not stop them when closed.
+	readMirror bool
+
 	// TODO(fwereade): move these out of state and make them independent
 	// workers on which state depends.
 	watcher           *watcher.Watcher
 	pwatcher          *presence.Watcher
 	leadershipManager leadership.ManagerWorker
+	sessionManager    *sessionManager
+	statusHistory     *statusHistoryBuffer
 
 	// mu guards allManager, allEnvManager & allEnvWatcherBacking
 	mu                   sync.Mutex
@@ -168,8 +175,45 @@ func (st *State) ForEnviron(env names.EnvironTag) (*State, error) {
 	return newState, nil
 }
 
-// start starts the presence watcher, leadership manager and images metadata storage,
-// and fills in the serverTag field with the supplied value.
+// ReadMirror returns a new State for the same environment whose reads
+// are served by the nearest member of the mongo replica set, which on
+// a state server is usually its local secondary, so that they do not
+// load the primary. Writes made through it still go to the primary.
+// As reads may lag behind writes, it should only be used for
+// operations that can tolerate that, such as reporting status.
+//
+// The mirror shares the watchers, leadership manager and status
+// history buffer of st rather than starting its own, and must not be
+// used once st is closed.
+func (st *State) ReadMirror() (*State, error) {
+	session := st.session.Copy()
+	session.SetMode(mgo.Nearest, true)
+	database, err := allCollections().Load(session.DB(jujuDB), st.EnvironUUID())
+	if err != nil {
+		session.Close()
+		return nil, errors.Trace(err)
+	}
+	mirror := &State{
+		environTag:        st.environTag,
+		serverTag:         st.serverTag,
+		mongoInfo:         st.mongoInfo,
+		session:           session,
+		database:          database,
+		policy:            st.policy,
+		readMirror:        true,
+		watcher:           st.watcher,
+		pwatcher:          st.pwatcher,
+		leadershipManager: st.leadershipManager,
+		statusHistory:     st.statusHistory,
+	}
+	mirror.CloudImageMetadataStorage = cloudimagemetadata.NewStorage(
+		st.EnvironUUID(), cloudimagemetadataC, &environMongo{mirror},
+	)
+	return mirror, nil
+}
+
+// start starts the presence watcher, leadership manager, session manager and
+// images metadata storage, and fills in the serverTag field with the supplied value.
 func (st *State) start(serverTag names.EnvironTag) error {
 	st.serverTag = serverTag
 
@@ -220,6 +264,12 @@ func (st *State) start(serverTag names.EnvironTag) error {
 
 	logger.Infof("starting presence watcher")
 	st.pwatcher = presence.NewWatcher(st.getPresence(), st.environTag)
+
+	logger.Infof("starting mongo session manager")
+	st.sessionManager = newSessionManager(st.session)
+
+	logger.Infof("starting status history buffer")
+	st.statusHistory = newStatusHistoryBuffer(st.writeStatusHistory)
 	return nil
 }
 
@@ -290,9 +340,12 @@ func (st *State) newDB() (Database, func()) {
 }
 
 // Ping probes the state's database connection to ensure
-// that it is still alive.
+// that it is still alive. If the connection has been lost, for
+// example because the replica set elected a new primary, Ping
+// returns an error but re-dials the session, so that later
+// operations use the new connection.
 func (st *State) Ping() error {
-	return st.session.Ping()
+	return pingSession(st.session)
 }
 
 // MongoSession returns the underlying mongodb session
@@ -1091,12 +1144,41 @@ func (st *State) addPeerRelationsOps(serviceName string, peers map[string]charm.
 	return ops, nil
 }
 
+// AddServiceArgs defines the arguments for the AddServiceWithArgs
+// method.
+type AddServiceArgs struct {
+	Name     string
+	Owner    string
+	Charm    *Charm
+	Networks []string
+	Storage  map[string]StorageConstraints
+
+	// EndpointBindings maps the names of the charm's relation
+	// endpoints to the spaces they are bound to.
+	EndpointBindings map[string]string
+}
+
 // AddService creates a new service, running the supplied charm, with the
 // supplied name (which must be unique). If the charm defines peer relations,
 // they will be created automatically.
 func (st *State) AddService(
 	name, owner string, ch *Charm, networks []string, storage map[string]StorageConstraints,
 ) (service *Service, err error) {
+	return st.AddServiceWithArgs(AddServiceArgs{
+		Name:     name,
+		Owner:    owner,
+		Charm:    ch,
+		Networks: networks,
+		Storage:  storage,
+	})
+}
+
+// AddServiceWithArgs creates a new service as described by args. It
+// behaves as AddService does, and also binds the charm's endpoints as
+// requested, in the same transaction.
+func (st *State) AddServiceWithArgs(args AddServiceArgs) (service *Service, err error) {
+	name, owner, ch := args.Name, args.Owner, args.Charm
+	networks, storage := args.Networks, args.Storage
 	defer errors.DeferredAnnotatef(&err, "cannot add service %q", name)
 	ownerTag, err := names.ParseUserTag(owner)
 	if err != nil {
@@ -1132,6 +1214,9 @@ func (st *State) AddService(
 	if err := validateStorageConstraints(st, storage, ch.Meta()); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err := validateEndpointBindings(st, ch.Meta(), args.EndpointBindings); err != nil {
+		return nil, errors.Trace(err)
+	}
 	serviceID := st.docID(name)
 	// Create the service addition operations.
 	peers := ch.Meta().Peers
@@ -1186,8 +1271,15 @@ func (st *State) AddService(
 			Id:     serviceID,
 			Assert: txn.DocMissing,
 			Insert: svcDoc,
+		}, {
+			C:      remoteServicesC,
+			Id:     serviceID,
+			Assert: txn.DocMissing,
 		},
 	}
+	if len(args.EndpointBindings) > 0 {
+		ops = append(ops, createEndpointBindingsOp(st, svc.globalKey(), args.EndpointBindings))
+	}
 	// Collect peer relation addition operations.
 	peerOps, err := st.addPeerRelationsOps(name, peers)
 	if err != nil {
@@ -1587,7 +1679,17 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 		return nil, errors.Errorf("invalid endpoint %q", name)
 	}
 	svc, err := st.Service(svcName)
-	if err != nil {
+	if errors.IsNotFound(err) {
+		// A remote service offers only the endpoints it was
+		// consumed with.
+		remoteSvc, err2 := st.RemoteService(svcName)
+		if errors.IsNotFound(err2) {
+			return nil, errors.Trace(err)
+		} else if err2 != nil {
+			return nil, errors.Trace(err2)
+		}
+		return remoteSvc.filterEndpoints(relName, filter)
+	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
 	eps := []Endpoint{}
@@ -1651,12 +1753,18 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 		}
 		// Collect per-service operations, checking sanity as we go.
 		var ops []txn.Op
-		var subordinateCount int
+		var subordinateCount, remoteCount int
 		series := map[string]bool{}
 		for _, ep := range eps {
 			svc, err := st.Service(ep.ServiceName)
 			if errors.IsNotFound(err) {
-				return nil, errors.Errorf("service %q does not exist", ep.ServiceName)
+				op, err := st.remoteEndpointOp(ep)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				remoteCount++
+				ops = append(ops, op)
+				continue
 			} else if err != nil {
 				return nil, errors.Trace(err)
 			} else if svc.doc.Life != Alive {
@@ -1680,6 +1788,9 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 				Update: bson.D{{"$inc", bson.D{{"relationcount", 1}}}},
 			})
 		}
+		if remoteCount > 1 {
+			return nil, errors.Errorf("cannot relate remote services to each other")
+		}
 		if matchSeries && len(series) != 1 {
 			return nil, errors.Errorf("principal and subordinate services' series must match")
 		}
@@ -1718,6 +1829,26 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 	return nil, errors.Trace(err)
 }
 
+// remoteEndpointOp returns the operation that records a new relation
+// of a remote service, after checking that the remote service was
+// consumed with the given endpoint.
+func (st *State) remoteEndpointOp(ep Endpoint) (txn.Op, error) {
+	remoteSvc, err := st.RemoteService(ep.ServiceName)
+	if errors.IsNotFound(err) {
+		return txn.Op{}, errors.Errorf("service %q does not exist", ep.ServiceName)
+	} else if err != nil {
+		return txn.Op{}, errors.Trace(err)
+	}
+	if ep.Scope == charm.ScopeContainer {
+		return txn.Op{}, errors.Errorf("remote service %q cannot be in a container scoped relation", ep.ServiceName)
+	}
+	offered, err := remoteSvc.Endpoint(ep.Name)
+	if err != nil || offered.Relation != ep.Relation {
+		return txn.Op{}, errors.Errorf("%q does not implement %q", ep.ServiceName, ep)
+	}
+	return remoteSvc.relationCountOp(1), nil
+}
+
 // EndpointsRelation returns the existing relation with the given endpoints.
 func (st *State) EndpointsRelation(endpoints ...Endpoint) (*Relation, error) {
 	return st.KeyRelation(relationKey(endpoints))
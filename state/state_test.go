@@ -736,6 +736,16 @@ func (s *StateSuite) TestPing(c *gc.C) {
 	c.Assert(s.State.Ping(), gc.NotNil)
 }
 
+func (s *StateSuite) TestPingReconnects(c *gc.C) {
+	c.Assert(s.State.Ping(), gc.IsNil)
+	gitjujutesting.MgoServer.Restart()
+	c.Assert(s.State.Ping(), gc.NotNil)
+	// The failed ping refreshed the session.
+	c.Assert(s.State.Ping(), gc.IsNil)
+	_, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *StateSuite) TestIsNotFound(c *gc.C) {
 	err1 := fmt.Errorf("unrelated error")
 	err2 := errors.NotFoundf("foo")
@@ -3024,6 +3034,51 @@ func (s *StateSuite) TestOpenWithoutSetMongoPassword(c *gc.C) {
 	c.Check(err, jc.ErrorIsNil)
 }
 
+func (s *StateSuite) TestReadMirror(c *gc.C) {
+	mirror, err := s.State.ReadMirror()
+	c.Assert(err, jc.ErrorIsNil)
+	defer mirror.Close()
+	c.Assert(mirror.EnvironUUID(), gc.Equals, s.State.EnvironUUID())
+	c.Assert(mirror.MongoSession().Mode(), gc.Equals, mgo.Nearest)
+	c.Assert(s.State.MongoSession().Mode(), gc.Not(gc.Equals), mgo.Nearest)
+
+	// Without secondaries, reads are served by the primary, so
+	// what is written through one state is read through the other.
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	mirrored, err := mirror.Machine(machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mirrored.Tag(), gc.Equals, machine.Tag())
+
+	// Writes through the mirror go to the primary.
+	err = mirrored.SetPassword("passwordwithenoughchars")
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.PasswordValid("passwordwithenoughchars"), jc.IsTrue)
+
+	// Presence is read through the watcher shared with the state.
+	_, err = mirrored.AgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *StateSuite) TestReadMirrorCloseLeavesStateRunning(c *gc.C) {
+	mirror, err := s.State.ReadMirror()
+	c.Assert(err, jc.ErrorIsNil)
+	err = mirror.Close()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The watchers and workers shared with the mirror still work.
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	w := machine.Watch()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+	_, err = machine.AgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *StateSuite) TestOpenBadAddress(c *gc.C) {
 	info := statetesting.NewMongoInfo()
 	info.Addrs = []string{"0.1.2.3:1234"}
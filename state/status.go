@@ -209,15 +209,35 @@ func probablyUpdateStatusHistory(st *State, globalKey string, doc statusDoc) {
 		Updated:    doc.Updated,
 		GlobalKey:  globalKey,
 	}
+	if st.statusHistory != nil {
+		st.statusHistory.Add(historyDoc)
+		return
+	}
+	if err := st.writeStatusHistory([]interface{}{historyDoc}); err != nil {
+		logger.Errorf("failed to write status history: %v", err)
+	}
+}
+
+// writeStatusHistory inserts status history entries.
+func (st *State) writeStatusHistory(docs []interface{}) error {
 	history, closer := st.getCollection(statusesHistoryC)
 	defer closer()
-	historyW := history.Writeable()
-	if err := historyW.Insert(historyDoc); err != nil {
+	return errors.Trace(history.Writeable().Insert(docs...))
+}
+
+// flushStatusHistory writes any buffered status history entries, so
+// that they are seen by a subsequent read of the history.
+func (st *State) flushStatusHistory() {
+	if st.statusHistory == nil {
+		return
+	}
+	if err := st.statusHistory.Flush(); err != nil {
 		logger.Errorf("failed to write status history: %v", err)
 	}
 }
 
 func statusHistory(st *State, globalKey string, size int) ([]StatusInfo, error) {
+	st.flushStatusHistory()
 	statusHistory, closer := st.getCollection(statusesHistoryC)
 	defer closer()
 
@@ -245,6 +265,7 @@ func statusHistory(st *State, globalKey string, size int) ([]StatusInfo, error)
 // PruneStatusHistory removes status history entries until
 // only the maxLogsPerEntity newest records per unit remain.
 func PruneStatusHistory(st *State, maxLogsPerEntity int) error {
+	st.flushStatusHistory()
 	history, closer := st.getCollection(statusesHistoryC)
 	defer closer()
 
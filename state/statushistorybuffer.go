@@ -0,0 +1,113 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"launchpad.net/tomb"
+)
+
+var (
+	// statusHistoryFlushPeriod is the longest time a status history
+	// entry is buffered before it is written. It is patched by tests.
+	statusHistoryFlushPeriod = time.Second
+
+	// statusHistoryBatchSize is the number of buffered status history
+	// entries that causes the buffer to be written straight away.
+	statusHistoryBatchSize = 500
+)
+
+// statusHistoryBuffer collects status history entries and writes them
+// in batches, rather than writing each one as its status changes. On
+// large environments statuses change constantly, and a single insert of
+// many entries is far cheaper for mongo than many inserts of one.
+//
+// Entries are written at least every statusHistoryFlushPeriod, as soon
+// as statusHistoryBatchSize of them are waiting, and when the buffer is
+// stopped. Anything reading the history must call Flush first, so that
+// it sees the entries that are still buffered.
+type statusHistoryBuffer struct {
+	tomb  tomb.Tomb
+	write func(docs []interface{}) error
+	full  chan struct{}
+
+	// flushMu is held while entries are written, so that Flush does
+	// not return while another flush is still writing.
+	flushMu sync.Mutex
+
+	// mu guards docs.
+	mu   sync.Mutex
+	docs []interface{}
+}
+
+// newStatusHistoryBuffer starts a statusHistoryBuffer that writes
+// entries with the given function.
+func newStatusHistoryBuffer(write func(docs []interface{}) error) *statusHistoryBuffer {
+	b := &statusHistoryBuffer{
+		write: write,
+		full:  make(chan struct{}, 1),
+	}
+	go func() {
+		defer b.tomb.Done()
+		b.tomb.Kill(b.loop())
+	}()
+	return b
+}
+
+// Add adds an entry to the buffer.
+func (b *statusHistoryBuffer) Add(doc interface{}) {
+	b.mu.Lock()
+	b.docs = append(b.docs, doc)
+	full := len(b.docs) >= statusHistoryBatchSize
+	b.mu.Unlock()
+	if !full {
+		return
+	}
+	select {
+	case b.full <- struct{}{}:
+	default:
+	}
+}
+
+// Flush writes all the buffered entries. If they cannot be written
+// they are dropped, as a missing status history entry is better than
+// history accumulating in memory while mongo is unavailable.
+func (b *statusHistoryBuffer) Flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+	b.mu.Lock()
+	docs := b.docs
+	b.docs = nil
+	b.mu.Unlock()
+	if len(docs) == 0 {
+		return nil
+	}
+	if err := b.write(docs); err != nil {
+		return errors.Annotatef(err, "cannot write %d status history entries", len(docs))
+	}
+	return nil
+}
+
+// Stop writes any buffered entries and stops the buffer.
+func (b *statusHistoryBuffer) Stop() error {
+	b.tomb.Kill(nil)
+	return errors.Trace(b.tomb.Wait())
+}
+
+func (b *statusHistoryBuffer) loop() error {
+	for {
+		select {
+		case <-b.tomb.Dying():
+			return errors.Trace(b.Flush())
+		case <-time.After(statusHistoryFlushPeriod):
+		case <-b.full:
+		}
+		if err := b.Flush(); err != nil {
+			logger.Errorf("failed to write status history: %v", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+)
+
+type statusHistoryBufferSuite struct {
+	testing.IsolationSuite
+
+	mu      sync.Mutex
+	batches [][]interface{}
+	written chan struct{}
+	err     error
+}
+
+var _ = gc.Suite(&statusHistoryBufferSuite{})
+
+func (s *statusHistoryBufferSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.batches = nil
+	s.written = make(chan struct{}, 10)
+	s.err = nil
+}
+
+func (s *statusHistoryBufferSuite) write(docs []interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, docs)
+	s.written <- struct{}{}
+	return s.err
+}
+
+func (s *statusHistoryBufferSuite) checkBatches(c *gc.C, expect ...[]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.Check(s.batches, jc.DeepEquals, expect)
+}
+
+func (s *statusHistoryBufferSuite) waitWritten(c *gc.C) {
+	select {
+	case <-s.written:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("status history not written")
+	}
+}
+
+func (s *statusHistoryBufferSuite) TestFlush(c *gc.C) {
+	s.PatchValue(&statusHistoryFlushPeriod, time.Hour)
+	b := newStatusHistoryBuffer(s.write)
+	defer b.Stop()
+
+	b.Add("a")
+	b.Add("b")
+	err := b.Flush()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkBatches(c, []interface{}{"a", "b"})
+
+	// Nothing is written when nothing is buffered.
+	err = b.Flush()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkBatches(c, []interface{}{"a", "b"})
+}
+
+func (s *statusHistoryBufferSuite) TestFlushError(c *gc.C) {
+	s.PatchValue(&statusHistoryFlushPeriod, time.Hour)
+	s.err = errors.New("boom")
+	b := newStatusHistoryBuffer(s.write)
+	defer b.Stop()
+
+	b.Add("a")
+	err := b.Flush()
+	c.Assert(err, gc.ErrorMatches, "cannot write 1 status history entries: boom")
+
+	// The entries that could not be written are dropped.
+	s.err = nil
+	b.Add("b")
+	err = b.Flush()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkBatches(c, []interface{}{"a"}, []interface{}{"b"})
+}
+
+func (s *statusHistoryBufferSuite) TestFlushPeriodically(c *gc.C) {
+	s.PatchValue(&statusHistoryFlushPeriod, coretesting.ShortWait)
+	b := newStatusHistoryBuffer(s.write)
+	defer b.Stop()
+
+	b.Add("a")
+	s.waitWritten(c)
+	s.checkBatches(c, []interface{}{"a"})
+}
+
+func (s *statusHistoryBufferSuite) TestFlushWhenFull(c *gc.C) {
+	s.PatchValue(&statusHistoryFlushPeriod, time.Hour)
+	s.PatchValue(&statusHistoryBatchSize, 3)
+	b := newStatusHistoryBuffer(s.write)
+	defer b.Stop()
+
+	b.Add("a")
+	b.Add("b")
+	select {
+	case <-s.written:
+		c.Fatalf("status history written before buffer full")
+	case <-time.After(coretesting.ShortWait):
+	}
+	b.Add("c")
+	s.waitWritten(c)
+	s.checkBatches(c, []interface{}{"a", "b", "c"})
+}
+
+func (s *statusHistoryBufferSuite) TestStopFlushes(c *gc.C) {
+	s.PatchValue(&statusHistoryFlushPeriod, time.Hour)
+	b := newStatusHistoryBuffer(s.write)
+
+	b.Add("a")
+	err := b.Stop()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkBatches(c, []interface{}{"a"})
+}
@@ -35,9 +35,14 @@ type StorageInstance interface {
 	Kind() StorageKind
 
 	// Owner returns the tag of the service or unit that owns this storage
-	// instance.
+	// instance, or nil if it has none; see HasOwner.
 	Owner() names.Tag
 
+	// HasOwner reports whether the storage instance has an owner. A
+	// storage instance that has been detached from its unit has no
+	// owner until it is attached to another unit.
+	HasOwner() bool
+
 	// StorageName returns the name of the storage, as defined in the charm
 	// storage metadata. This does not uniquely identify storage instances,
 	// but identifies the group that the instances belong to.
@@ -94,15 +99,22 @@ func (s *storageInstance) Kind() StorageKind {
 }
 
 func (s *storageInstance) Owner() names.Tag {
+	if s.doc.Owner == "" {
+		return nil
+	}
 	tag, err := names.ParseTag(s.doc.Owner)
 	if err != nil {
-		// This should be impossible; we do not expose
-		// a means of modifying the owner tag.
+		// This should be impossible; the owner tag is only
+		// ever set to a valid unit or service tag.
 		panic(err)
 	}
 	return tag
 }
 
+func (s *storageInstance) HasOwner() bool {
+	return s.doc.Owner != ""
+}
+
 func (s *storageInstance) StorageName() string {
 	return s.doc.StorageName
 }
@@ -621,6 +633,218 @@ func destroyStorageAttachmentOps(storage names.StorageTag, unit names.UnitTag) [
 	return ops
 }
 
+// DetachStorage ensures that the storage attachment between the specified
+// storage instance and unit will be removed at some point, without
+// destroying the storage instance. Once the attachment has been removed,
+// the storage instance's volume is detached from the unit's machine, and
+// the storage instance may be attached to another unit with AttachStorage.
+func (st *State) DetachStorage(storage names.StorageTag, unit names.UnitTag) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot detach storage %s from unit %s", storage.Id(), unit.Id())
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		s, err := st.storageAttachment(storage, unit)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if s.doc.Life != Alive {
+			return nil, jujutxn.ErrNoOperations
+		}
+		si, err := st.storageInstance(storage)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if si.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if si.doc.Owner != unit.String() {
+			return nil, errors.NotSupportedf("detaching storage not owned by the unit")
+		}
+		if err := validateStorageDetachable(st, si); err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := destroyStorageAttachmentOps(storage, unit)
+		ops = append(ops, txn.Op{
+			C:      storageInstancesC,
+			Id:     si.doc.Id,
+			Assert: bson.D{{"life", Alive}, {"owner", unit.String()}},
+			Update: bson.D{{"$set", bson.D{{"owner", ""}}}},
+		})
+		return ops, nil
+	}
+	return st.run(buildTxn)
+}
+
+// validateStorageDetachable returns an error if the storage instance cannot
+// outlive the machine it is attached to. Only block storage whose volume is
+// not bound to the machine may be detached.
+func validateStorageDetachable(st *State, si *storageInstance) error {
+	if si.doc.Kind != StorageKindBlock {
+		// TODO(axw) filesystems are always machine-bound until we
+		// support persistent filesystems, or re-creating volume-backed
+		// filesystems on another machine.
+		return errors.NotSupportedf("detaching filesystem storage")
+	}
+	volume, err := st.storageInstanceVolume(si.StorageTag())
+	if errors.IsNotFound(err) {
+		// The unit has not been assigned to a machine yet,
+		// so there is nothing to detach.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	machineBound, err := isVolumeInherentlyMachineBound(st, volume.VolumeTag())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if machineBound {
+		return errors.NotSupportedf("detaching machine-bound volume %s", volume.VolumeTag().Id())
+	}
+	return nil
+}
+
+// detachStorageMachineOps returns txn.Ops for detaching the volume assigned
+// to the storage instance from the machine that the unit is assigned to.
+func detachStorageMachineOps(st *State, si *storageInstance, unit names.UnitTag) ([]txn.Op, error) {
+	volume, err := st.storageInstanceVolume(si.StorageTag())
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	u, err := st.Unit(unit.Id())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machineId, err := u.AssignedMachineId()
+	if errors.IsNotAssigned(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machine := names.NewMachineTag(machineId)
+	attachment, err := st.VolumeAttachment(machine, volume.VolumeTag())
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if attachment.Life() != Alive {
+		return nil, nil
+	}
+	return detachVolumeOps(machine, volume.VolumeTag()), nil
+}
+
+// AttachStorage attaches the specified storage instance, which must have
+// been detached from its previous unit with DetachStorage, to the specified
+// unit. If the unit is assigned to a machine, the storage instance's volume
+// will be attached to that machine.
+func (st *State) AttachStorage(storage names.StorageTag, unit names.UnitTag) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot attach storage %s to unit %s", storage.Id(), unit.Id())
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		si, err := st.storageInstance(storage)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if si.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if si.doc.Owner == unit.String() {
+			return nil, jujutxn.ErrNoOperations
+		}
+		if si.doc.Owner != "" {
+			return nil, errors.Errorf("storage is attached to %s", si.doc.Owner)
+		}
+		u, err := st.Unit(unit.Id())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if u.Life() != Alive {
+			return nil, unitNotAliveErr
+		}
+		s, err := u.Service()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ch, _, err := s.Charm()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := validateStorageAttachable(st, ch.Meta(), u, si); err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := []txn.Op{
+			createStorageAttachmentOp(storage, unit),
+			{
+				C:      storageInstancesC,
+				Id:     si.doc.Id,
+				Assert: bson.D{{"life", Alive}, {"owner", ""}},
+				Update: bson.D{
+					{"$set", bson.D{{"owner", unit.String()}}},
+					{"$inc", bson.D{{"attachmentcount", 1}}},
+				},
+			},
+			{
+				C:      unitsC,
+				Id:     u.doc.DocID,
+				Assert: isAliveDoc,
+				Update: bson.D{{"$inc", bson.D{{"storageattachmentcount", 1}}}},
+			},
+		}
+		cons, err := u.StorageConstraints()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		attached := &storageInstance{st, si.doc}
+		attached.doc.Owner = unit.String()
+		machineOps, err := unitAssignedMachineStorageOps(
+			st, unit, ch.Meta(), cons, u.Series(), attached,
+		)
+		if err == nil {
+			ops = append(ops, machineOps...)
+		} else if !errors.IsNotAssigned(err) {
+			return nil, errors.Trace(err)
+		}
+		return ops, nil
+	}
+	return st.run(buildTxn)
+}
+
+// validateStorageAttachable returns an error if the storage instance cannot
+// be attached to the unit, because the unit's charm does not declare
+// matching storage, or the unit already has as many instances of the
+// storage as the charm allows.
+func validateStorageAttachable(st *State, charmMeta *charm.Meta, u *Unit, si *storageInstance) error {
+	charmStorage, ok := charmMeta.Storage[si.doc.StorageName]
+	if !ok {
+		return errors.NotFoundf("charm storage %q", si.doc.StorageName)
+	}
+	if charmStorage.Shared {
+		return errors.NotSupportedf("attaching shared storage")
+	}
+	var kind StorageKind
+	switch charmStorage.Type {
+	case charm.StorageBlock:
+		kind = StorageKindBlock
+	case charm.StorageFilesystem:
+		kind = StorageKindFilesystem
+	}
+	if kind != si.doc.Kind {
+		return errors.Errorf("storage kind does not match charm storage %q", si.doc.StorageName)
+	}
+	if charmStorage.CountMax >= 0 {
+		count, err := st.countEntityStorageInstancesForName(u.Tag(), si.doc.StorageName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if count >= uint64(charmStorage.CountMax) {
+			return errors.Errorf(
+				"unit already has %d instances of storage %q, the maximum allowed",
+				count, si.doc.StorageName,
+			)
+		}
+	}
+	return nil
+}
+
 // Remove removes the storage attachment from state, and may remove its storage
 // instance as well, if the storage instance is Dying and no other references to
 // it exist. It will fail if the storage attachment is not Dead.
@@ -669,6 +893,16 @@ func removeStorageAttachmentOps(
 		Assert: txn.DocExists,
 		Update: bson.D{{"$inc", bson.D{{"storageattachmentcount", -1}}}},
 	}}
+	if si.doc.Owner == "" && si.doc.Life == Alive {
+		// The storage instance has been detached from the unit, so
+		// detach its volume from the unit's machine; the storage
+		// instance may then be attached to another unit.
+		detachOps, err := detachStorageMachineOps(st, si, names.NewUnitTag(s.doc.Unit))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, detachOps...)
+	}
 	if si.doc.AttachmentCount == 1 {
 		var hasLastRef bson.D
 		if si.doc.Life == Dying {
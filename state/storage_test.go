@@ -828,6 +828,114 @@ func (s *StorageStateSuite) TestDestroyUnitStorageAttachments(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *StorageStateSuite) setupDetachableStorage(c *gc.C) (*state.Service, *state.Unit, names.StorageTag) {
+	ch := s.AddTestingCharm(c, "storage-block")
+	service := s.AddTestingServiceWithStorage(c, "storage-block", ch, map[string]state.StorageConstraints{
+		"allecto": makeStorageCons("persistent-block", 1024, 1),
+	})
+	u, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.AssignToNewMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	return service, u, names.NewStorageTag("allecto/0")
+}
+
+func (s *StorageStateSuite) TestDetachStorage(c *gc.C) {
+	_, u, storageTag := s.setupDetachableStorage(c)
+	machineId, err := u.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	machineTag := names.NewMachineTag(machineId)
+
+	err = s.State.DetachStorage(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	attachment, err := s.State.StorageAttachment(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attachment.Life(), gc.Equals, state.Dying)
+	si, err := s.State.StorageInstance(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(si.HasOwner(), jc.IsFalse)
+	c.Assert(si.Owner(), gc.IsNil)
+
+	// Once the attachment is removed, the storage instance and its
+	// volume remain, but the volume is detached from the machine.
+	err = s.State.RemoveStorageAttachment(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	si, err = s.State.StorageInstance(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(si.Life(), gc.Equals, state.Alive)
+	volume, err := s.State.StorageInstanceVolume(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volume.Life(), gc.Equals, state.Alive)
+	volumeAttachment, err := s.State.VolumeAttachment(machineTag, volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeAttachment.Life(), gc.Equals, state.Dying)
+}
+
+func (s *StorageStateSuite) TestDetachStorageFilesystem(c *gc.C) {
+	_, u, storageTag := s.setupSingleStorage(c, "filesystem", "loop-pool")
+	err := s.State.DetachStorage(storageTag, u.UnitTag())
+	c.Assert(err, gc.ErrorMatches, "cannot detach storage data/0 from unit storage-filesystem/0: detaching filesystem storage not supported")
+	c.Assert(errors.Cause(err), jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *StorageStateSuite) TestAttachStorage(c *gc.C) {
+	service, u, storageTag := s.setupDetachableStorage(c)
+	err := s.State.DetachStorage(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveStorageAttachment(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	u2, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = u2.AssignToNewMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	machineId, err := u2.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.AttachStorage(storageTag, u2.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	si, err := s.State.StorageInstance(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(si.HasOwner(), jc.IsTrue)
+	c.Assert(si.Owner(), gc.Equals, u2.Tag())
+	attachment, err := s.State.StorageAttachment(storageTag, u2.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attachment.Life(), gc.Equals, state.Alive)
+
+	// The existing volume is attached to the new unit's machine,
+	// rather than a new volume being created.
+	volume, err := s.State.StorageInstanceVolume(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	volumeAttachment, err := s.State.VolumeAttachment(names.NewMachineTag(machineId), volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeAttachment.Life(), gc.Equals, state.Alive)
+}
+
+func (s *StorageStateSuite) TestAttachStorageOwned(c *gc.C) {
+	service, _, storageTag := s.setupDetachableStorage(c)
+	u2, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.AttachStorage(storageTag, u2.UnitTag())
+	c.Assert(err, gc.ErrorMatches, "cannot attach storage allecto/0 to unit storage-block/1: storage is attached to unit-storage-block-0")
+}
+
+func (s *StorageStateSuite) TestAttachStorageTooMany(c *gc.C) {
+	service, u, storageTag := s.setupSingleStorage(c, "block", "persistent-block")
+	err := u.AssignToNewMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.DetachStorage(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveStorageAttachment(storageTag, u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The charm allows only one instance of "data" per unit, and the
+	// new unit already has its own.
+	u2, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.AttachStorage(storageTag, u2.UnitTag())
+	c.Assert(err, gc.ErrorMatches, `cannot attach storage data/0 to unit storage-block/1: unit already has 1 instances of storage "data", the maximum allowed`)
+}
+
 func (s *StorageStateSuite) TestStorageLocationConflictIdentical(c *gc.C) {
 	s.testStorageLocationConflict(
 		c, "/srv", "/srv",
@@ -0,0 +1,132 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/instance"
+)
+
+// SystemMachine describes a machine in one of the environments on the
+// system. Unlike a *Machine, it is not tied to a State for the
+// machine's environment, and cannot be refreshed or changed.
+type SystemMachine struct {
+	EnvUUID    string
+	Id         string
+	Life       Life
+	Series     string
+	Jobs       []MachineJob
+	HasVote    bool
+	InstanceId instance.Id
+}
+
+// AllMachinesForSystem returns the machines in all the environments on
+// the system, ordered by environment UUID and then machine id. The
+// InstanceId of a machine that has not been provisioned is empty.
+//
+// The machines are read with a single query, rather than by opening a
+// State for each environment.
+func (st *State) AllMachinesForSystem() ([]SystemMachine, error) {
+	machinesCollection, closer := st.getRawCollection(machinesC)
+	defer closer()
+
+	var mdocs []machineDoc
+	err := machinesCollection.Find(nil).Select(bson.D{
+		{"machineid", 1},
+		{"env-uuid", 1},
+		{"life", 1},
+		{"series", 1},
+		{"jobs", 1},
+		{"hasvote", 1},
+	}).Sort("env-uuid", "machineid").All(&mdocs)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get all machines")
+	}
+
+	instanceDataCollection, closer := st.getRawCollection(instanceDataC)
+	defer closer()
+
+	var idocs []instanceData
+	err = instanceDataCollection.Find(nil).Select(bson.D{
+		{"machineid", 1},
+		{"env-uuid", 1},
+		{"instanceid", 1},
+	}).All(&idocs)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get instance data for all machines")
+	}
+	instanceIds := make(map[string]instance.Id, len(idocs))
+	for _, doc := range idocs {
+		instanceIds[ensureEnvUUID(doc.EnvUUID, doc.MachineId)] = doc.InstanceId
+	}
+
+	machines := make([]SystemMachine, len(mdocs))
+	for i, doc := range mdocs {
+		machines[i] = SystemMachine{
+			EnvUUID:    doc.EnvUUID,
+			Id:         doc.Id,
+			Life:       doc.Life,
+			Series:     doc.Series,
+			Jobs:       doc.Jobs,
+			HasVote:    doc.HasVote,
+			InstanceId: instanceIds[ensureEnvUUID(doc.EnvUUID, doc.Id)],
+		}
+	}
+	return machines, nil
+}
+
+// UnitCountsForSystem returns the number of units in each environment
+// on the system, keyed by environment UUID. Environments without units
+// are omitted.
+func (st *State) UnitCountsForSystem() (map[string]int, error) {
+	unitsCollection, closer := st.getRawCollection(unitsC)
+	defer closer()
+
+	var results []struct {
+		EnvUUID string `bson:"_id"`
+		Count   int    `bson:"count"`
+	}
+	err := unitsCollection.Pipe([]bson.M{{
+		"$group": bson.M{
+			"_id":   "$env-uuid",
+			"count": bson.M{"$sum": 1},
+		},
+	}}).All(&results)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot count units")
+	}
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.EnvUUID] = result.Count
+	}
+	return counts, nil
+}
+
+// UnitCountsByOwnerForSystem returns the total number of units in the
+// environments owned by each user, keyed by the owner's user name.
+// Owners whose environments have no units are omitted.
+func (st *State) UnitCountsByOwnerForSystem() (map[string]int, error) {
+	envCounts, err := st.UnitCountsForSystem()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	environments, closer := st.getRawCollection(environmentsC)
+	defer closer()
+
+	var envDocs []environmentDoc
+	err = environments.Find(nil).Select(bson.D{{"owner", 1}}).All(&envDocs)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get all environments")
+	}
+	counts := make(map[string]int)
+	for _, doc := range envDocs {
+		if n := envCounts[doc.UUID]; n > 0 {
+			counts[doc.Owner] += n
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type SystemSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&SystemSuite{})
+
+func (s *SystemSuite) makeEnvironment(c *gc.C, owner string) (*state.State, *factory.Factory) {
+	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{
+		Owner: names.NewUserTag(owner),
+	})
+	s.AddCleanup(func(*gc.C) { st.Close() })
+	return st, factory.NewFactory(st)
+}
+
+func (s *SystemSuite) TestAllMachinesForSystem(c *gc.C) {
+	s.Factory.MakeMachine(c, &factory.MachineParams{InstanceId: "inst-0"})
+	st2, f2 := s.makeEnvironment(c, "bob@remote")
+	f2.MakeMachine(c, &factory.MachineParams{
+		Jobs:       []state.MachineJob{state.JobHostUnits},
+		InstanceId: "inst-1",
+	})
+	_, err := st2.AddMachine("trusty", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	machines, err := s.State.AllMachinesForSystem()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, jc.SameContents, []state.SystemMachine{{
+		EnvUUID:    s.State.EnvironUUID(),
+		Id:         "0",
+		Life:       state.Alive,
+		Series:     "quantal",
+		Jobs:       []state.MachineJob{state.JobHostUnits},
+		InstanceId: "inst-0",
+	}, {
+		EnvUUID:    st2.EnvironUUID(),
+		Id:         "0",
+		Life:       state.Alive,
+		Series:     "quantal",
+		Jobs:       []state.MachineJob{state.JobHostUnits},
+		InstanceId: "inst-1",
+	}, {
+		EnvUUID: st2.EnvironUUID(),
+		Id:      "1",
+		Life:    state.Alive,
+		Series:  "trusty",
+		Jobs:    []state.MachineJob{state.JobHostUnits},
+	}})
+}
+
+func (s *SystemSuite) TestUnitCountsForSystem(c *gc.C) {
+	s.Factory.MakeUnit(c, nil)
+	st2, f2 := s.makeEnvironment(c, "bob@remote")
+	service := f2.MakeService(c, nil)
+	f2.MakeUnit(c, &factory.UnitParams{Service: service})
+	f2.MakeUnit(c, &factory.UnitParams{Service: service})
+	st3, _ := s.makeEnvironment(c, "bob@remote")
+
+	counts, err := s.State.UnitCountsForSystem()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(counts, jc.DeepEquals, map[string]int{
+		s.State.EnvironUUID(): 1,
+		st2.EnvironUUID():     2,
+	})
+	c.Assert(counts[st3.EnvironUUID()], gc.Equals, 0)
+}
+
+func (s *SystemSuite) TestUnitCountsByOwnerForSystem(c *gc.C) {
+	s.Factory.MakeUnit(c, nil)
+	_, f2 := s.makeEnvironment(c, "bob@remote")
+	f2.MakeUnit(c, nil)
+	_, f3 := s.makeEnvironment(c, "bob@remote")
+	f3.MakeUnit(c, nil)
+	s.makeEnvironment(c, "mary@remote")
+
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	counts, err := s.State.UnitCountsByOwnerForSystem()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(counts, jc.DeepEquals, map[string]int{
+		env.Owner().Canonical(): 1,
+		"bob@remote":            2,
+	})
+}
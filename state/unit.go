@@ -96,6 +96,7 @@ type unitDoc struct {
 	MachineId              string
 	Resolved               ResolvedMode
 	Tools                  *tools.Tools `bson:",omitempty"`
+	WorkloadVersion        string       `bson:"workloadversion,omitempty"`
 	Life                   Life
 	TxnRevno               int64 `bson:"txn-revno"`
 	PasswordHash           string
@@ -228,6 +229,29 @@ func (u *Unit) SetAgentVersion(v version.Binary) (err error) {
 	return nil
 }
 
+// WorkloadVersion returns the version of the workload software that
+// the unit's charm last reported, or "" if it has reported none.
+func (u *Unit) WorkloadVersion() string {
+	return u.doc.WorkloadVersion
+}
+
+// SetWorkloadVersion records the version of the workload software
+// that the unit's charm is running.
+func (u *Unit) SetWorkloadVersion(version string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set workload version for unit %q", u)
+	ops := []txn.Op{{
+		C:      unitsC,
+		Id:     u.doc.DocID,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"workloadversion", version}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return onAbort(err, ErrDead)
+	}
+	u.doc.WorkloadVersion = version
+	return nil
+}
+
 // SetPassword sets the password for the machine's agent.
 func (u *Unit) SetPassword(password string) error {
 	if len(password) < utils.MinAgentPasswordLength {
@@ -326,6 +350,7 @@ func (u *Unit) Destroy() (err error) {
 }
 
 func (u *Unit) eraseHistory() error {
+	u.st.flushStatusHistory()
 	history, closer := u.st.getCollection(statusesHistoryC)
 	defer closer()
 	// XXX(fwereade): 2015-06-19 this is anything but safe: we must not mix
@@ -1621,12 +1646,20 @@ func machineStorageParamsForStorageInstance(
 	volumeAttachments := make(map[names.VolumeTag]VolumeAttachmentParams)
 	filesystemAttachments := make(map[names.FilesystemTag]FilesystemAttachmentParams)
 
+	owner := storage.Owner()
 	switch storage.Kind() {
 	case StorageKindBlock:
 		volumeAttachmentParams := VolumeAttachmentParams{
 			charmStorage.ReadOnly,
 		}
-		if unit == storage.Owner() {
+		volume, err := st.storageInstanceVolume(storage.StorageTag())
+		switch {
+		case err == nil:
+			// The storage instance is owned by the service, or was
+			// detached from another unit, so there is a volume
+			// already, for which we will just add an attachment.
+			volumeAttachments[volume.VolumeTag()] = volumeAttachmentParams
+		case errors.IsNotFound(err) && unit == owner:
 			// The storage instance is owned by the unit, so we'll need
 			// to create a volume.
 			cons := allCons[storage.StorageName()]
@@ -1639,15 +1672,8 @@ func machineStorageParamsForStorageInstance(
 			volumes = append(volumes, MachineVolumeParams{
 				volumeParams, volumeAttachmentParams,
 			})
-		} else {
-			// The storage instance is owned by the service, so there
-			// should be a (shared) volume already, for which we will
-			// just add an attachment.
-			volume, err := st.StorageInstanceVolume(storage.StorageTag())
-			if err != nil {
-				return nil, errors.Annotatef(err, "getting volume for storage %q", storage.Tag().Id())
-			}
-			volumeAttachments[volume.VolumeTag()] = volumeAttachmentParams
+		default:
+			return nil, errors.Annotatef(err, "getting volume for storage %q", storage.Tag().Id())
 		}
 	case StorageKindFilesystem:
 		location, err := filesystemMountPoint(charmStorage, storage.StorageTag(), series)
@@ -1662,7 +1688,7 @@ func machineStorageParamsForStorageInstance(
 			location,
 			charmStorage.ReadOnly,
 		}
-		if unit == storage.Owner() {
+		if unit == owner {
 			// The storage instance is owned by the unit, so we'll need
 			// to create a filesystem.
 			cons := allCons[storage.StorageName()]
@@ -1967,7 +1993,8 @@ func (u *Unit) UnassignFromMachine() (err error) {
 type ActionSpecsByName map[string]charm.ActionSpec
 
 // AddAction adds a new Action of type name and using arguments payload to
-// this Unit, and returns its ID.  Note that the use of spec.InsertDefaults
+// this Unit, and returns its ID. The action's timeout is taken from the
+// charm's actions.yaml. Note that the use of spec.InsertDefaults
 // mutates payload.
 func (u *Unit) AddAction(name string, payload map[string]interface{}) (*Action, error) {
 	if len(name) == 0 {
@@ -1990,7 +2017,11 @@ func (u *Unit) AddAction(name string, payload map[string]interface{}) (*Action,
 	if err != nil {
 		return nil, err
 	}
-	return u.st.EnqueueAction(u.Tag(), name, payloadWithDefaults)
+	timeout, err := actionTimeout(spec)
+	if err != nil {
+		return nil, errors.Annotatef(err, "action %q", name)
+	}
+	return u.st.enqueueAction(u.Tag(), name, payloadWithDefaults, timeout)
 }
 
 // ActionSpecs gets the ActionSpec map for the Unit's charm.
@@ -2019,10 +2050,10 @@ func (u *Unit) ActionSpecs() (ActionSpecsByName, error) {
 	return chActions.ActionSpecs, nil
 }
 
-// CancelAction removes a pending Action from the queue for this
-// ActionReceiver and marks it as cancelled.
+// CancelAction cancels an Action queued or running for this
+// ActionReceiver; see Action.Cancel.
 func (u *Unit) CancelAction(action *Action) (*Action, error) {
-	return action.Finish(ActionResults{Status: ActionCancelled})
+	return action.Cancel()
 }
 
 // WatchActionNotifications starts and returns a StringsWatcher that
@@ -2052,6 +2083,12 @@ func (u *Unit) RunningActions() ([]*Action, error) {
 	return u.st.matchingActionsRunning(u)
 }
 
+// QueuedActions returns a list of the pending actions waiting in this
+// unit's queue for earlier actions to finish, oldest first.
+func (u *Unit) QueuedActions() ([]*Action, error) {
+	return u.st.matchingActionsQueued(u)
+}
+
 // Resolve marks the unit as having had any previous state transition
 // problems resolved, and informs the unit that it may attempt to
 // reestablish normal workflow. The retryHooks parameter informs
@@ -848,6 +848,27 @@ func (s *UnitSuite) TestSetPassword(c *gc.C) {
 	})
 }
 
+func (s *UnitSuite) TestSetWorkloadVersion(c *gc.C) {
+	c.Assert(s.unit.WorkloadVersion(), gc.Equals, "")
+
+	err := s.unit.SetWorkloadVersion("4.2.1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.WorkloadVersion(), gc.Equals, "4.2.1")
+
+	unit, err := s.State.Unit(s.unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.WorkloadVersion(), gc.Equals, "4.2.1")
+}
+
+func (s *UnitSuite) TestSetWorkloadVersionDead(c *gc.C) {
+	preventUnitDestroyRemove(c, s.unit)
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.unit.SetWorkloadVersion("4.2.1")
+	c.Assert(err, gc.ErrorMatches, `cannot set workload version for unit "wordpress/0": not found or dead`)
+}
+
 func (s *UnitSuite) TestSetAgentCompatPassword(c *gc.C) {
 	e, err := s.State.Unit(s.unit.Name())
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,175 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// MaxCharmStateSize is the largest total size, in bytes, of the keys
+// and values a unit's charm may store.
+const MaxCharmStateSize = 64 * 1024
+
+// unitStateDoc records the key/value state stored by a unit's charm.
+// Unlike files written by the charm on the unit's machine, it survives
+// the machine's replacement, and is removed only with the unit.
+type unitStateDoc struct {
+	DocID   string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+	Unit    string `bson:"unit"`
+
+	// CharmState holds the charm's state, with keys escaped for
+	// storage in mongo.
+	CharmState map[string]string `bson:"charm-state,omitempty"`
+}
+
+// unitState returns the state document of the unit, or nil if the
+// unit's charm has not yet stored any state.
+func (u *Unit) unitState() (*unitStateDoc, error) {
+	unitStates, closer := u.st.getCollection(unitStatesC)
+	defer closer()
+
+	var doc unitStateDoc
+	err := unitStates.FindId(u.doc.Name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &doc, nil
+}
+
+// CharmState returns the key/value state stored by the unit's charm.
+func (u *Unit) CharmState() (map[string]string, error) {
+	doc, err := u.unitState()
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get charm state for unit %q", u)
+	}
+	state := make(map[string]string)
+	if doc != nil {
+		for key, value := range doc.CharmState {
+			state[unescapeReplacer.Replace(key)] = value
+		}
+	}
+	return state, nil
+}
+
+// SetCharmState updates the key/value state stored by the unit's
+// charm: each key in set is given its value, and each key in unset is
+// removed. The resulting state may not be larger than
+// MaxCharmStateSize.
+func (u *Unit) SetCharmState(set map[string]string, unset []string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set charm state for unit %q", u)
+	for key := range set {
+		if key == "" {
+			return errors.NotValidf("empty key")
+		}
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if u.doc.Life == Dead {
+			return nil, ErrDead
+		}
+		doc, err := u.unitState()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		state := make(map[string]string)
+		if doc != nil {
+			for key, value := range doc.CharmState {
+				state[key] = value
+			}
+		}
+		var setOps, unsetOps bson.D
+		for key, value := range set {
+			key = escapeReplacer.Replace(key)
+			if old, ok := state[key]; ok && old == value {
+				continue
+			}
+			state[key] = value
+			setOps = append(setOps, bson.DocElem{"charm-state." + key, value})
+		}
+		for _, key := range unset {
+			key = escapeReplacer.Replace(key)
+			if _, ok := state[key]; !ok {
+				continue
+			}
+			delete(state, key)
+			unsetOps = append(unsetOps, bson.DocElem{"charm-state." + key, nil})
+		}
+		if len(setOps) == 0 && len(unsetOps) == 0 {
+			return nil, jujutxn.ErrNoOperations
+		}
+		size := 0
+		for key, value := range state {
+			size += len(key) + len(value)
+		}
+		if size > MaxCharmStateSize {
+			return nil, errors.Errorf("state of %d bytes exceeds limit of %d bytes", size, MaxCharmStateSize)
+		}
+
+		ops := []txn.Op{{
+			C:      unitsC,
+			Id:     u.doc.DocID,
+			Assert: notDeadDoc,
+		}}
+		if doc == nil {
+			return append(ops, txn.Op{
+				C:      unitStatesC,
+				Id:     u.st.docID(u.doc.Name),
+				Assert: txn.DocMissing,
+				Insert: &unitStateDoc{
+					DocID:      u.st.docID(u.doc.Name),
+					EnvUUID:    u.st.EnvironUUID(),
+					Unit:       u.doc.Name,
+					CharmState: state,
+				},
+			}), nil
+		}
+		// Assert that the keys being changed still hold the values
+		// they were read with.
+		var assert bson.D
+		for _, elem := range append(setOps, unsetOps...) {
+			key := elem.Name[len("charm-state."):]
+			if old, ok := doc.CharmState[key]; ok {
+				assert = append(assert, bson.DocElem{elem.Name, old})
+			} else {
+				assert = append(assert, bson.DocElem{elem.Name, bson.D{{"$exists", false}}})
+			}
+		}
+		var update bson.D
+		if len(setOps) > 0 {
+			update = append(update, bson.DocElem{"$set", setOps})
+		}
+		if len(unsetOps) > 0 {
+			update = append(update, bson.DocElem{"$unset", unsetOps})
+		}
+		return append(ops, txn.Op{
+			C:      unitStatesC,
+			Id:     doc.DocID,
+			Assert: assert,
+			Update: update,
+		}), nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// removeUnitStateOp returns the operation that removes the state
+// stored by the named unit's charm, if any.
+func removeUnitStateOp(st *State, unitName string) txn.Op {
+	return txn.Op{
+		C:      unitStatesC,
+		Id:     st.docID(unitName),
+		Remove: true,
+	}
+}
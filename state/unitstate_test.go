@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type UnitStateSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&UnitStateSuite{})
+
+func (s *UnitStateSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.unit = factory.NewFactory(s.State).MakeUnit(c, nil)
+}
+
+func (s *UnitStateSuite) TestCharmStateEmpty(c *gc.C) {
+	charmState, err := s.unit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, gc.HasLen, 0)
+}
+
+func (s *UnitStateSuite) TestSetCharmState(c *gc.C) {
+	err := s.unit.SetCharmState(map[string]string{
+		"foo":         "bar",
+		"dotted.key":  "baz",
+		"$dollar-key": "qux",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	charmState, err := s.unit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, jc.DeepEquals, map[string]string{
+		"foo":         "bar",
+		"dotted.key":  "baz",
+		"$dollar-key": "qux",
+	})
+
+	err = s.unit.SetCharmState(map[string]string{"foo": "new"}, []string{"dotted.key", "missing"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	charmState, err = s.unit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, jc.DeepEquals, map[string]string{
+		"foo":         "new",
+		"$dollar-key": "qux",
+	})
+}
+
+func (s *UnitStateSuite) TestSetCharmStateEmptyKey(c *gc.C) {
+	err := s.unit.SetCharmState(map[string]string{"": "bar"}, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set charm state for unit "mysql/0": empty key not valid`)
+}
+
+func (s *UnitStateSuite) TestSetCharmStateTooLarge(c *gc.C) {
+	value := strings.Repeat("x", state.MaxCharmStateSize/2)
+	err := s.unit.SetCharmState(map[string]string{"a": value}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetCharmState(map[string]string{"b": value}, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set charm state for unit "mysql/0": state of \d+ bytes exceeds limit of \d+ bytes`)
+}
+
+func (s *UnitStateSuite) TestSetCharmStateDead(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetCharmState(map[string]string{"foo": "bar"}, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set charm state for unit "mysql/0": not found or dead`)
+}
+
+func (s *UnitStateSuite) TestCharmStateRemovedWithUnit(c *gc.C) {
+	err := s.unit.SetCharmState(map[string]string{"foo": "bar"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	charmState, err := s.unit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, gc.HasLen, 0)
+}
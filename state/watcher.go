@@ -2399,48 +2399,21 @@ func (w *machineInterfacesWatcher) loop() error {
 	}
 }
 
-// openedPortsWatcher notifies of changes in the openedPorts
-// collection
-type openedPortsWatcher struct {
-	commonWatcher
-	known map[string]int64
-	out   chan []string
-}
-
-var _ Watcher = (*openedPortsWatcher)(nil)
-
 // WatchOpenedPorts starts and returns a StringsWatcher notifying of
 // changes to the openedPorts collection. Reported changes have the
 // following format: "<machine-id>:<network-name>", i.e.
 // "0:juju-public".
 func (st *State) WatchOpenedPorts() StringsWatcher {
-	return newOpenedPortsWatcher(st)
-}
-
-func newOpenedPortsWatcher(st *State) StringsWatcher {
-	w := &openedPortsWatcher{
-		commonWatcher: commonWatcher{st: st},
-		known:         make(map[string]int64),
-		out:           make(chan []string),
-	}
-	go func() {
-		defer w.tomb.Done()
-		defer close(w.out)
-		w.tomb.Kill(w.loop())
-	}()
-
-	return w
-}
-
-// Changes returns the event channel for w
-func (w *openedPortsWatcher) Changes() <-chan []string {
-	return w.out
+	return newCollectionWatcher(st, collectionWatcherConfig{
+		collName: openedPortsC,
+		idconv:   openedPortsChangeId,
+	})
 }
 
-// transformId converts a global key for a ports document (e.g.
+// openedPortsChangeId converts a global key for a ports document (e.g.
 // "m#42#n#juju-public") into a colon-separated string with the
 // machine id and network name (e.g. "42:juju-public").
-func (w *openedPortsWatcher) transformId(globalKey string) (string, error) {
+func openedPortsChangeId(globalKey string) (string, error) {
 	parts, err := extractPortsIdParts(globalKey)
 	if err != nil {
 		return "", errors.Trace(err)
@@ -2448,98 +2421,6 @@ func (w *openedPortsWatcher) transformId(globalKey string) (string, error) {
 	return fmt.Sprintf("%s:%s", parts[machineIdPart], parts[networkNamePart]), nil
 }
 
-func (w *openedPortsWatcher) initial() (set.Strings, error) {
-	ports, closer := w.st.getCollection(openedPortsC)
-	defer closer()
-
-	portDocs := set.NewStrings()
-	var doc portsDoc
-	iter := ports.Find(nil).Select(bson.D{{"_id", 1}, {"txn-revno", 1}}).Iter()
-	for iter.Next(&doc) {
-		id, err := w.st.strictLocalID(doc.DocID)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		if doc.TxnRevno != -1 {
-			w.known[id] = doc.TxnRevno
-		}
-		if changeId, err := w.transformId(id); err != nil {
-			logger.Errorf(err.Error())
-		} else {
-			portDocs.Add(changeId)
-		}
-	}
-	return portDocs, errors.Trace(iter.Close())
-}
-
-func (w *openedPortsWatcher) loop() error {
-	in := make(chan watcher.Change)
-	changes, err := w.initial()
-	if err != nil {
-		return errors.Trace(err)
-	}
-	w.st.watcher.WatchCollectionWithFilter(openedPortsC, in, w.st.isForStateEnv)
-	defer w.st.watcher.UnwatchCollection(openedPortsC, in)
-
-	out := w.out
-	for {
-		select {
-		case <-w.tomb.Dying():
-			return tomb.ErrDying
-		case <-w.st.watcher.Dead():
-			return stateWatcherDeadError(w.st.watcher.Err())
-		case ch := <-in:
-			if err = w.merge(changes, ch); err != nil {
-				return errors.Trace(err)
-			}
-			if !changes.IsEmpty() {
-				out = w.out
-			}
-		case out <- changes.Values():
-			out = nil
-			changes = set.NewStrings()
-		}
-	}
-}
-
-func (w *openedPortsWatcher) merge(ids set.Strings, change watcher.Change) error {
-	id, ok := change.Id.(string)
-	if !ok {
-		return errors.Errorf("id %v is not of type string, got %T", id, id)
-	}
-	localID, err := w.st.strictLocalID(id)
-	if err != nil {
-		return errors.Trace(err)
-	}
-	if change.Revno == -1 {
-		delete(w.known, localID)
-		if changeId, err := w.transformId(localID); err != nil {
-			logger.Errorf(err.Error())
-		} else {
-			// Report the removed id.
-			ids.Add(changeId)
-		}
-		return nil
-	}
-	openedPorts, closer := w.st.getCollection(openedPortsC)
-	currentRevno, err := getTxnRevno(openedPorts, id)
-	closer()
-	if err != nil {
-		return err
-	}
-	knownRevno, isKnown := w.known[localID]
-	w.known[localID] = currentRevno
-	if !isKnown || currentRevno > knownRevno {
-		if changeId, err := w.transformId(localID); err != nil {
-			logger.Errorf(err.Error())
-		} else {
-			// Report the unknown-so-far id.
-			ids.Add(changeId)
-		}
-	}
-	return nil
-}
-
 // WatchForRebootEvent returns a notify watcher that will trigger an event
 // when the reboot flag is set on our machine agent, our parent machine agent
 // or grandparent machine agent
@@ -15,6 +15,8 @@ import (
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"launchpad.net/tomb"
+
+	"github.com/juju/juju/mongo"
 )
 
 var logger = loggo.GetLogger("juju.state.watcher")
@@ -213,6 +215,11 @@ func (w *Watcher) StartSync() {
 // It must not be changed when any watchers are active.
 var Period time.Duration = 5 * time.Second
 
+// maxSyncRetries is the number of consecutive syncs that may fail
+// because the connection to mongo was lost, for example while the
+// replica set elects a new primary, before the watcher gives up.
+const maxSyncRetries = 3
+
 // loop implements the main watcher loop.
 func (w *Watcher) loop() error {
 	next := time.After(Period)
@@ -220,12 +227,19 @@ func (w *Watcher) loop() error {
 	if err := w.initLastId(); err != nil {
 		return errors.Trace(err)
 	}
+	retries := 0
 	for {
 		if w.needSync {
-			if err := w.sync(); err != nil {
+			if err := w.sync(); err == nil {
+				retries = 0
+				w.flush()
+			} else if mongo.IsConnectionError(err) && retries < maxSyncRetries {
+				retries++
+				logger.Warningf("watcher sync failed (attempt %d of %d), refreshing session: %v", retries, maxSyncRetries, err)
+				w.log.Database.Session.Refresh()
+			} else {
 				return errors.Trace(err)
 			}
-			w.flush()
 			next = time.After(Period)
 		}
 		select {
@@ -426,7 +440,9 @@ func (w *Watcher) sync() error {
 		}
 	}
 	if err := iter.Close(); err != nil {
-		return errors.Errorf("watcher iteration error: %v", err)
+		// Read the log again from the same place next time.
+		w.lastId = lastId
+		return errors.Annotate(err, "watcher iteration error")
 	}
 	return nil
 }
@@ -197,6 +197,19 @@ func (s *watcherSuite) remove(c *gc.C, coll string, id interface{}) (revno int64
 	return -1
 }
 
+func (s *FastPeriodSuite) TestSurvivesMongoRestart(c *gc.C) {
+	s.w.Watch("test", "a", -1, s.ch)
+	gitjujutesting.MgoServer.Restart()
+
+	// The watcher retries its syncs while mongo is unavailable, and
+	// carries on once the session can be refreshed.
+	s.MgoSuite.Session.Refresh()
+	revno := s.insert(c, "test", "a")
+	s.w.StartSync()
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno})
+	c.Assert(s.w.Err(), gc.Equals, tomb.ErrStillAlive)
+}
+
 func (s *FastPeriodSuite) TestErrAndDead(c *gc.C) {
 	c.Assert(s.w.Err(), gc.Equals, tomb.ErrStillAlive)
 	select {
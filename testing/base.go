@@ -45,6 +45,7 @@ func (s *JujuOSEnvSuite) SetUpTest(c *gc.C) {
 	s.oldEnvironment = make(map[string]string)
 	for _, name := range []string{
 		osenv.JujuHomeEnvKey,
+		osenv.JujuDataEnvKey,
 		osenv.JujuEnvEnvKey,
 		osenv.JujuLoggingConfigEnvKey,
 		osenv.JujuFeatureFlagEnvKey,
@@ -125,6 +126,10 @@ type BaseSuite struct {
 	testing.CleanupSuite
 	testing.LoggingSuite
 	JujuOSEnvSuite
+
+	// environ holds the process environment from before the test,
+	// which is restored once everything else is torn down.
+	environ map[string]string
 }
 
 func (s *BaseSuite) SetUpSuite(c *gc.C) {
@@ -142,6 +147,7 @@ func (s *BaseSuite) TearDownSuite(c *gc.C) {
 }
 
 func (s *BaseSuite) SetUpTest(c *gc.C) {
+	s.environ = osenv.Snapshot()
 	s.CleanupSuite.SetUpTest(c)
 	s.LoggingSuite.SetUpTest(c)
 	s.JujuOSEnvSuite.SetUpTest(c)
@@ -158,6 +164,9 @@ func (s *BaseSuite) TearDownTest(c *gc.C) {
 	s.JujuOSEnvSuite.TearDownTest(c)
 	s.LoggingSuite.TearDownTest(c)
 	s.CleanupSuite.TearDownTest(c)
+	if err := osenv.Restore(s.environ); err != nil {
+		c.Errorf("cannot restore environment: %v", err)
+	}
 }
 
 // CheckString compares two strings. If they do not match then the spot
@@ -29,14 +29,26 @@ type Context interface {
 	// APIContext returns a new Context suitable for API-based upgrade
 	// steps.
 	APIContext() Context
+
+	// ReportStep is called with the description of each upgrade step
+	// just before it is run, so that progress can be reported.
+	ReportStep(description string)
 }
 
-// NewContext returns a new upgrade context.
-func NewContext(agentConfig agent.ConfigSetter, api api.Connection, st *state.State) Context {
+// NewContext returns a new upgrade context. If reportStep is non-nil,
+// it is called with the description of each upgrade step before the
+// step is run.
+func NewContext(
+	agentConfig agent.ConfigSetter,
+	api api.Connection,
+	st *state.State,
+	reportStep func(description string),
+) Context {
 	return &upgradeContext{
 		agentConfig: agentConfig,
 		api:         api,
 		st:          st,
+		reportStep:  reportStep,
 	}
 }
 
@@ -45,6 +57,7 @@ type upgradeContext struct {
 	agentConfig agent.ConfigSetter
 	api         api.Connection
 	st          *state.State
+	reportStep  func(string)
 }
 
 // APIState is defined on the Context interface.
@@ -77,6 +90,7 @@ func (c *upgradeContext) StateContext() Context {
 	return &upgradeContext{
 		agentConfig: c.agentConfig,
 		st:          c.st,
+		reportStep:  c.reportStep,
 	}
 }
 
@@ -85,5 +99,13 @@ func (c *upgradeContext) APIContext() Context {
 	return &upgradeContext{
 		agentConfig: c.agentConfig,
 		api:         c.api,
+		reportStep:  c.reportStep,
+	}
+}
+
+// ReportStep is defined on the Context interface.
+func (c *upgradeContext) ReportStep(description string) {
+	if c.reportStep != nil {
+		c.reportStep(description)
 	}
 }
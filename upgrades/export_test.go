@@ -8,6 +8,7 @@ import "github.com/juju/juju/environs"
 var (
 	UpgradeOperations         = &upgradeOperations
 	StateUpgradeOperations    = &stateUpgradeOperations
+	StateStepsForAllUpgrades  = &stateStepsForAllUpgrades
 	UbuntuHome                = &ubuntuHome
 	RootLogDir                = &rootLogDir
 	RootSpoolDir              = &rootSpoolDir
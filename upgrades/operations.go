@@ -51,6 +51,25 @@ var stateUpgradeOperations = func() []Operation {
 	return steps
 }
 
+// stateStepsForAllUpgrades returns the state-based steps that are run
+// on every upgrade, whatever the versions, after the operations for
+// each version. They must leave alone whatever an earlier upgrade has
+// already done.
+var stateStepsForAllUpgrades = func() []Step {
+	return []Step{
+		&upgradeStep{
+			// The migrations select only the documents they
+			// have not yet changed, so new migrations can be
+			// added without tying them to a version.
+			description: "migrate state schema",
+			targets:     []Target{DatabaseMaster},
+			run: func(context Context) error {
+				return migrateSchema(context.State())
+			},
+		},
+	}
+}
+
 // upgradeOperations returns an ordered slice of sets of API-based
 // operations needed to upgrade Juju to particular version. As per the
 // state-based operations above, ordering is important.
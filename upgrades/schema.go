@@ -0,0 +1,31 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgrades
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/upgrades/schema"
+)
+
+// migrateSchema logs the changes the schema migrations would make to
+// the juju database, and then makes them.
+func migrateSchema(st *state.State) error {
+	session := st.MongoSession().Copy()
+	defer session.Close()
+	db := session.DB("juju")
+
+	results, err := schema.Run(db, schema.Migrations, true)
+	if err != nil {
+		return errors.Annotate(err, "planning schema migrations")
+	}
+	logger.Infof("schema migrations to run:\n%s", schema.Report(results))
+	results, err = schema.Run(db, schema.Migrations, false)
+	if err != nil {
+		return errors.Annotate(err, "running schema migrations")
+	}
+	logger.Infof("schema migrations run:\n%s", schema.Report(results))
+	return nil
+}
@@ -0,0 +1,21 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Migrations holds the migrations run against the juju database when a
+// controller is upgraded. New migrations are appended; existing ones
+// must not be reordered or removed, as later migrations may depend on
+// the changes made by earlier ones.
+var Migrations = []Migration{{
+	// Status history copied by old versions of juju may have no
+	// update time, and so would never be pruned by age.
+	Name:       "set missing status history update times",
+	Collection: "statuseshistory",
+	Selector:   bson.D{{"updated", bson.D{{"$exists", false}}}},
+	Update:     bson.D{{"$set", bson.D{{"updated", int64(0)}}}},
+}}
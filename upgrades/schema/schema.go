@@ -0,0 +1,192 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The schema package migrates the documents held in Juju's mongo
+// collections from one schema to the next.
+//
+// Each Migration selects the documents that still need migrating and
+// describes the update that brings them up to date. Once a document
+// has been updated it must no longer be selected, so a migration can
+// be run any number of times, and an interrupted upgrade can simply
+// be run again. Migrations are run in the order they are given.
+//
+// Migrations can also be run in dry-run mode, which reports the number
+// of documents each migration would change without changing them.
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+var logger = loggo.GetLogger("juju.upgrades.schema")
+
+// Migration describes a change to the documents in a collection.
+type Migration struct {
+	// Name describes the migration in logs and reports.
+	Name string
+
+	// Collection is the name of the collection to migrate.
+	Collection string
+
+	// Selector selects the documents that need migrating. It must not
+	// select a document once Update has been applied to it.
+	Selector bson.D
+
+	// Update is the update applied to each selected document.
+	Update bson.D
+
+	// TxnManaged is true if the collection is written with mgo/txn
+	// transactions, and so must also be migrated with them.
+	TxnManaged bool
+}
+
+// Validate returns an error if the migration is incomplete.
+func (m Migration) Validate() error {
+	if m.Name == "" {
+		return errors.NotValidf("migration with empty name")
+	}
+	if m.Collection == "" {
+		return errors.NotValidf("migration %q with empty collection", m.Name)
+	}
+	if len(m.Update) == 0 {
+		return errors.NotValidf("migration %q with empty update", m.Name)
+	}
+	return nil
+}
+
+// Result records the outcome of a migration.
+type Result struct {
+	// Name is the name of the migration.
+	Name string
+
+	// Collection is the name of the migrated collection.
+	Collection string
+
+	// Count is the number of documents changed by the migration or,
+	// in a dry run, the number of documents it would change. As
+	// documents may be written while a dry run is in progress, the
+	// count is an estimate.
+	Count int
+}
+
+// Report formats the results of a run for logging, one line per
+// migration.
+func Report(results []Result) string {
+	lines := make([]string, len(results))
+	for i, result := range results {
+		lines[i] = fmt.Sprintf("%s (%s): %d documents", result.Name, result.Collection, result.Count)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// batchSize is the number of documents migrated in each transaction.
+const batchSize = 100
+
+// Run applies the migrations, in order, to the collections in db, and
+// returns a Result for each of them. If dryRun is true, no documents
+// are changed and the results hold the number of documents each
+// migration would change.
+//
+// If a migration fails, the results of the migrations that ran before
+// it are returned along with the error.
+func Run(db *mgo.Database, migrations []Migration, dryRun bool) ([]Result, error) {
+	for _, m := range migrations {
+		if err := m.Validate(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	runner := jujutxn.NewRunner(jujutxn.RunnerParams{Database: db})
+	results := make([]Result, 0, len(migrations))
+	for _, m := range migrations {
+		var count int
+		var err error
+		switch {
+		case dryRun:
+			count, err = db.C(m.Collection).Find(m.Selector).Count()
+		case m.TxnManaged:
+			count, err = migrateWithTxns(db, runner, m)
+		default:
+			count, err = migrate(db, m)
+		}
+		if err != nil {
+			return results, errors.Annotatef(err, "migration %q", m.Name)
+		}
+		logger.Debugf("migration %q: %d documents in %s", m.Name, count, m.Collection)
+		results = append(results, Result{
+			Name:       m.Name,
+			Collection: m.Collection,
+			Count:      count,
+		})
+	}
+	return results, nil
+}
+
+// migrate applies the migration to a collection that is not
+// txn-managed.
+func migrate(db *mgo.Database, m Migration) (int, error) {
+	info, err := db.C(m.Collection).UpdateAll(m.Selector, m.Update)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return info.Updated, nil
+}
+
+// migrateWithTxns applies the migration to a txn-managed collection,
+// a batch of documents at a time. Each document is asserted to still
+// match the selector, so a document changed by another writer since
+// it was read is picked up again by the next attempt.
+func migrateWithTxns(db *mgo.Database, runner jujutxn.Runner, m Migration) (int, error) {
+	coll := db.C(m.Collection)
+	var assert interface{} = txn.DocExists
+	if len(m.Selector) > 0 {
+		assert = m.Selector
+	}
+	migrated := make(map[interface{}]bool)
+	for {
+		var batch []interface{}
+		buildTxn := func(int) ([]txn.Op, error) {
+			batch = batch[:0]
+			var docs []struct {
+				Id interface{} `bson:"_id"`
+			}
+			err := coll.Find(m.Selector).Select(bson.D{{"_id", 1}}).Limit(batchSize).All(&docs)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if len(docs) == 0 {
+				return nil, jujutxn.ErrNoOperations
+			}
+			ops := make([]txn.Op, len(docs))
+			for i, doc := range docs {
+				if migrated[doc.Id] {
+					return nil, errors.Errorf("document %v still selected after migration", doc.Id)
+				}
+				batch = append(batch, doc.Id)
+				ops[i] = txn.Op{
+					C:      m.Collection,
+					Id:     doc.Id,
+					Assert: assert,
+					Update: m.Update,
+				}
+			}
+			return ops, nil
+		}
+		if err := runner.Run(buildTxn); err != nil {
+			return len(migrated), errors.Trace(err)
+		}
+		if len(batch) == 0 {
+			return len(migrated), nil
+		}
+		for _, id := range batch {
+			migrated[id] = true
+		}
+	}
+}
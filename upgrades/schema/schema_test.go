@@ -0,0 +1,193 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	jujutxn "github.com/juju/txn"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/upgrades/schema"
+)
+
+type SchemaSuite struct {
+	testing.BaseSuite
+	gitjujutesting.MgoSuite
+	db *mgo.Database
+}
+
+var _ = gc.Suite(&SchemaSuite{})
+
+func (s *SchemaSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	s.MgoSuite.SetUpSuite(c)
+}
+
+func (s *SchemaSuite) TearDownSuite(c *gc.C) {
+	s.MgoSuite.TearDownSuite(c)
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *SchemaSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.MgoSuite.SetUpTest(c)
+	s.db = s.Session.DB("juju")
+}
+
+func (s *SchemaSuite) TearDownTest(c *gc.C) {
+	s.MgoSuite.TearDownTest(c)
+	s.BaseSuite.TearDownTest(c)
+}
+
+var addVersion = schema.Migration{
+	Name:       "add version",
+	Collection: "things",
+	Selector:   bson.D{{"version", bson.D{{"$exists", false}}}},
+	Update:     bson.D{{"$set", bson.D{{"version", 1}}}},
+}
+
+var bumpVersion = schema.Migration{
+	Name:       "bump version",
+	Collection: "things",
+	Selector:   bson.D{{"version", 1}},
+	Update:     bson.D{{"$set", bson.D{{"version", 2}}}},
+}
+
+func (s *SchemaSuite) insertThings(c *gc.C, docs ...bson.M) {
+	for _, doc := range docs {
+		err := s.db.C("things").Insert(doc)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *SchemaSuite) insertThingsWithTxn(c *gc.C, ids ...string) {
+	ops := make([]txn.Op, len(ids))
+	for i, id := range ids {
+		ops[i] = txn.Op{C: "things", Id: id, Assert: txn.DocMissing, Insert: bson.M{}}
+	}
+	runner := jujutxn.NewRunner(jujutxn.RunnerParams{Database: s.db})
+	err := runner.RunTransaction(ops)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SchemaSuite) txnRevno(c *gc.C, id string) int64 {
+	var doc struct {
+		Revno int64 `bson:"txn-revno"`
+	}
+	err := s.db.C("things").FindId(id).One(&doc)
+	c.Assert(err, jc.ErrorIsNil)
+	return doc.Revno
+}
+
+func (s *SchemaSuite) versions(c *gc.C) map[string]int {
+	var docs []struct {
+		Id      string `bson:"_id"`
+		Version int    `bson:"version"`
+	}
+	err := s.db.C("things").Find(nil).All(&docs)
+	c.Assert(err, jc.ErrorIsNil)
+	versions := make(map[string]int)
+	for _, doc := range docs {
+		versions[doc.Id] = doc.Version
+	}
+	return versions
+}
+
+func (s *SchemaSuite) TestDryRun(c *gc.C) {
+	s.insertThings(c, bson.M{"_id": "a"}, bson.M{"_id": "b"}, bson.M{"_id": "c", "version": 1})
+
+	results, err := schema.Run(s.db, []schema.Migration{addVersion, bumpVersion}, true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []schema.Result{
+		{Name: "add version", Collection: "things", Count: 2},
+		{Name: "bump version", Collection: "things", Count: 1},
+	})
+	c.Assert(s.versions(c), jc.DeepEquals, map[string]int{"a": 0, "b": 0, "c": 1})
+}
+
+func (s *SchemaSuite) TestRunInOrder(c *gc.C) {
+	s.insertThings(c, bson.M{"_id": "a"}, bson.M{"_id": "b"}, bson.M{"_id": "c", "version": 1})
+
+	results, err := schema.Run(s.db, []schema.Migration{addVersion, bumpVersion}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []schema.Result{
+		{Name: "add version", Collection: "things", Count: 2},
+		{Name: "bump version", Collection: "things", Count: 3},
+	})
+	c.Assert(s.versions(c), jc.DeepEquals, map[string]int{"a": 2, "b": 2, "c": 2})
+}
+
+func (s *SchemaSuite) TestRunIdempotent(c *gc.C) {
+	s.insertThings(c, bson.M{"_id": "a"})
+
+	migrations := []schema.Migration{addVersion}
+	_, err := schema.Run(s.db, migrations, false)
+	c.Assert(err, jc.ErrorIsNil)
+	results, err := schema.Run(s.db, migrations, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []schema.Result{
+		{Name: "add version", Collection: "things", Count: 0},
+	})
+	c.Assert(s.versions(c), jc.DeepEquals, map[string]int{"a": 1})
+}
+
+func (s *SchemaSuite) TestRunTxnManaged(c *gc.C) {
+	var ids []string
+	for i := 0; i < 150; i++ {
+		ids = append(ids, string(rune('a'+i/26))+string(rune('a'+i%26)))
+	}
+	s.insertThingsWithTxn(c, ids...)
+	revno := s.txnRevno(c, "aa")
+
+	m := addVersion
+	m.TxnManaged = true
+	results, err := schema.Run(s.db, []schema.Migration{m}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []schema.Result{
+		{Name: "add version", Collection: "things", Count: 150},
+	})
+	for id, version := range s.versions(c) {
+		c.Check(version, gc.Equals, 1, gc.Commentf("document %q", id))
+	}
+	c.Assert(s.txnRevno(c, "aa"), gc.Equals, revno+1)
+}
+
+func (s *SchemaSuite) TestRunTxnManagedNotIdempotent(c *gc.C) {
+	s.insertThingsWithTxn(c, "a")
+
+	m := schema.Migration{
+		Name:       "touch",
+		Collection: "things",
+		Update:     bson.D{{"$inc", bson.D{{"touched", 1}}}},
+		TxnManaged: true,
+	}
+	_, err := schema.Run(s.db, []schema.Migration{m}, false)
+	c.Assert(err, gc.ErrorMatches, `migration "touch": document a still selected after migration`)
+}
+
+func (s *SchemaSuite) TestRunInvalidMigration(c *gc.C) {
+	m := addVersion
+	m.Update = nil
+	_, err := schema.Run(s.db, []schema.Migration{bumpVersion, m}, false)
+	c.Assert(err, gc.ErrorMatches, `migration "add version" with empty update not valid`)
+}
+
+func (s *SchemaSuite) TestReport(c *gc.C) {
+	report := schema.Report([]schema.Result{
+		{Name: "add version", Collection: "things", Count: 2},
+		{Name: "bump version", Collection: "things", Count: 0},
+	})
+	c.Assert(report, gc.Equals, "add version (things): 2 documents\nbump version (things): 0 documents")
+}
+
+func (s *SchemaSuite) TestMigrationsValid(c *gc.C) {
+	for _, m := range schema.Migrations {
+		c.Check(m.Validate(), jc.ErrorIsNil)
+	}
+}
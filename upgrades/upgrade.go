@@ -86,8 +86,11 @@ func (e *upgradeError) Error() string {
 
 // AreUpgradesDefined returns true if there are upgrade operations
 // defined between the version supplied and the running software
-// version.
+// version, or steps run on every upgrade and the versions differ.
 func AreUpgradesDefined(from version.Number) bool {
+	if releaseChanged(from) && len(stateStepsForAllUpgrades()) > 0 {
+		return true
+	}
 	return newUpgradeOpsIterator(from).Next() || newStateUpgradeOpsIterator(from).Next()
 }
 
@@ -99,6 +102,12 @@ func PerformUpgrade(from version.Number, targets []Target, context Context) erro
 		if err := runUpgradeSteps(ops, targets, context.StateContext()); err != nil {
 			return err
 		}
+		if releaseChanged(from) {
+			steps := stateStepsForAllUpgrades()
+			if err := runSteps(steps, targets, context.StateContext()); err != nil {
+				return err
+			}
+		}
 	}
 
 	ops := newUpgradeOpsIterator(from)
@@ -110,6 +119,15 @@ func PerformUpgrade(from version.Number, targets []Target, context Context) erro
 	return nil
 }
 
+// releaseChanged reports whether from is a different release to the
+// running software. Development builds of a release differ only in
+// their build number, and are not upgraded between.
+func releaseChanged(from version.Number) bool {
+	to := version.Current
+	from.Build, to.Build = 0, 0
+	return from != to
+}
+
 func hasStateTarget(targets []Target) bool {
 	for _, target := range targets {
 		if target == StateServer || target == DatabaseMaster {
@@ -128,16 +146,26 @@ func hasStateTarget(targets []Target) bool {
 // operation can be retried.
 func runUpgradeSteps(ops *opsIterator, targets []Target, context Context) error {
 	for ops.Next() {
-		for _, step := range ops.Get().Steps() {
-			if targetsMatch(targets, step.Targets()) {
-				logger.Infof("running upgrade step: %v", step.Description())
-				if err := step.Run(context); err != nil {
-					logger.Errorf("upgrade step %q failed: %v", step.Description(), err)
-					return &upgradeError{
-						description: step.Description(),
-						err:         err,
-					}
-				}
+		if err := runSteps(ops.Get().Steps(), targets, context); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSteps runs those of the steps that apply to the targets, in order.
+func runSteps(steps []Step, targets []Target, context Context) error {
+	for _, step := range steps {
+		if !targetsMatch(targets, step.Targets()) {
+			continue
+		}
+		logger.Infof("running upgrade step: %v", step.Description())
+		context.ReportStep(step.Description())
+		if err := step.Run(context); err != nil {
+			logger.Errorf("upgrade step %q failed: %v", step.Description(), err)
+			return &upgradeError{
+				description: step.Description(),
+				err:         err,
 			}
 		}
 	}
@@ -74,6 +74,15 @@ type upgradeSuite struct {
 
 var _ = gc.Suite(&upgradeSuite{})
 
+// stateStepsForAllUpgrades holds the real steps, which the suite
+// replaces with none.
+var stateStepsForAllUpgrades = *upgrades.StateStepsForAllUpgrades
+
+func (s *upgradeSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(upgrades.StateStepsForAllUpgrades, func() []upgrades.Step { return nil })
+}
+
 type mockUpgradeOperation struct {
 	targetVersion version.Number
 	steps         []upgrades.Step
@@ -121,6 +130,7 @@ func newUpgradeStep(msg string, targets ...upgrades.Target) *mockUpgradeStep {
 
 type mockContext struct {
 	messages        []string
+	reported        []string
 	agentConfig     *mockAgentConfig
 	realAgentConfig agent.ConfigSetter
 	apiState        api.Connection
@@ -150,6 +160,10 @@ func (c *mockContext) APIContext() upgrades.Context {
 	return c
 }
 
+func (c *mockContext) ReportStep(description string) {
+	c.reported = append(c.reported, description)
+}
+
 type mockAgentConfig struct {
 	agent.ConfigSetter
 	dataDir      string
@@ -559,6 +573,45 @@ func (s *upgradeSuite) TestPerformUpgrade(c *gc.C) {
 	}
 }
 
+func (s *upgradeSuite) TestStateStepsForAllUpgrades(c *gc.C) {
+	s.PatchValue(upgrades.StateUpgradeOperations, stateUpgradeOperations)
+	s.PatchValue(upgrades.UpgradeOperations, upgradeOperations)
+	s.PatchValue(upgrades.StateStepsForAllUpgrades, func() []upgrades.Step {
+		return []upgrades.Step{
+			newUpgradeStep("every upgrade", upgrades.DatabaseMaster),
+		}
+	})
+	s.PatchValue(&version.Current, version.MustParse("1.14.1"))
+
+	// No steps are defined for 1.14.1, but the steps for all
+	// upgrades are still run on the database master.
+	c.Check(upgrades.AreUpgradesDefined(version.MustParse("1.13.0")), jc.IsTrue)
+	ctx := &mockContext{}
+	err := upgrades.PerformUpgrade(version.MustParse("1.13.0"), targets(upgrades.DatabaseMaster), ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ctx.messages, jc.DeepEquals, []string{"every upgrade"})
+
+	ctx = &mockContext{}
+	err = upgrades.PerformUpgrade(version.MustParse("1.13.0"), targets(upgrades.StateServer), ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ctx.messages, gc.HasLen, 0)
+
+	// Nothing is run if the version has not changed.
+	c.Check(upgrades.AreUpgradesDefined(version.Current), jc.IsFalse)
+	ctx = &mockContext{}
+	err = upgrades.PerformUpgrade(version.Current, targets(upgrades.DatabaseMaster), ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ctx.messages, gc.HasLen, 0)
+}
+
+func (s *upgradeSuite) TestStateStepsForAllUpgradesMigrateSchema(c *gc.C) {
+	var descriptions []string
+	for _, step := range stateStepsForAllUpgrades() {
+		descriptions = append(descriptions, step.Description())
+	}
+	c.Assert(descriptions, jc.DeepEquals, []string{"migrate state schema"})
+}
+
 type contextStep struct {
 	useAPI bool
 }
@@ -615,13 +668,27 @@ func (s *upgradeSuite) TestApiStepsGetRestrictedContext(c *gc.C) {
 func (s *upgradeSuite) checkContextRestriction(c *gc.C, expectedPanic string) {
 	fromVersion := version.MustParse("1.20.0")
 	type fakeAgentConfigSetter struct{ agent.ConfigSetter }
-	ctx := upgrades.NewContext(fakeAgentConfigSetter{}, nil, new(state.State))
+	ctx := upgrades.NewContext(fakeAgentConfigSetter{}, nil, new(state.State), nil)
 	c.Assert(
 		func() { upgrades.PerformUpgrade(fromVersion, targets(upgrades.StateServer), ctx) },
 		gc.PanicMatches, expectedPanic,
 	)
 }
 
+func (s *upgradeSuite) TestPerformUpgradeReportsSteps(c *gc.C) {
+	s.PatchValue(upgrades.StateUpgradeOperations, stateUpgradeOperations)
+	s.PatchValue(upgrades.UpgradeOperations, upgradeOperations)
+	s.PatchValue(&version.Current, version.MustParse("1.12.0"))
+
+	// Each step is reported before it is run, so a failing
+	// step is reported even though it does not complete.
+	ctx := &mockContext{}
+	err := upgrades.PerformUpgrade(version.MustParse("1.11.0"), targets(upgrades.HostMachine), ctx)
+	c.Assert(err, gc.ErrorMatches, "step 2 error: upgrade error occurred")
+	c.Assert(ctx.messages, jc.DeepEquals, []string{"step 1 - 1.12.0"})
+	c.Assert(ctx.reported, jc.DeepEquals, []string{"step 1 - 1.12.0", "step 2 error"})
+}
+
 func (s *upgradeSuite) TestStateStepsNotAttemptedWhenNoStateTarget(c *gc.C) {
 	stateCount := 0
 	stateUpgradeOperations := func() []upgrades.Operation {
@@ -6,9 +6,9 @@ package utils
 import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
-	"github.com/juju/utils/featureflag"
 
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju/osenv/featureflag"
 )
 
 var logger = loggo.GetLogger("juju.utils")
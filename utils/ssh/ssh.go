@@ -36,6 +36,9 @@ type Options struct {
 	// knownHostsFile is a path to a file in which to save the host's
 	// fingerprint.
 	knownHostsFile string
+	// strictHostKeyChecking, if set, refuses connections to hosts
+	// whose keys are not in the known hosts file.
+	strictHostKeyChecking bool
 }
 
 // SetProxyCommand sets a command to execute to proxy traffic through.
@@ -63,6 +66,15 @@ func (o *Options) SetKnownHostsFile(file string) {
 	o.knownHostsFile = file
 }
 
+// EnableStrictHostKeyChecking refuses connections to hosts whose keys
+// are unknown or have changed, rather than accepting them.
+//
+// Host keys are checked against the file set with SetKnownHostsFile,
+// or ~/.ssh/known_hosts by default.
+func (o *Options) EnableStrictHostKeyChecking() {
+	o.strictHostKeyChecking = true
+}
+
 // AllowPasswordAuthentication allows the SSH
 // client to prompt the user for a password.
 //
@@ -4,6 +4,7 @@
 package ssh
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -48,11 +49,15 @@ func (c *GoCryptoClient) Command(host string, command []string, options *Options
 	user, host := splitUserHost(host)
 	port := sshDefaultPort
 	var proxyCommand []string
+	var knownHostsFile string
+	var strictHostKeyChecking bool
 	if options != nil {
 		if options.port != 0 {
 			port = options.port
 		}
 		proxyCommand = options.proxyCommand
+		knownHostsFile = options.knownHostsFile
+		strictHostKeyChecking = options.strictHostKeyChecking
 	}
 	logger.Tracef(`running (equivalent of): ssh "%s@%s" -p %d '%s'`, user, host, port, shellCommand)
 	return &Cmd{impl: &goCryptoCommand{
@@ -61,6 +66,9 @@ func (c *GoCryptoClient) Command(host string, command []string, options *Options
 		addr:         fmt.Sprintf("%s:%d", host, port),
 		command:      shellCommand,
 		proxyCommand: proxyCommand,
+
+		knownHostsFile:        knownHostsFile,
+		strictHostKeyChecking: strictHostKeyChecking,
 	}}
 }
 
@@ -77,11 +85,15 @@ type goCryptoCommand struct {
 	addr         string
 	command      string
 	proxyCommand []string
-	stdin        io.Reader
-	stdout       io.Writer
-	stderr       io.Writer
-	client       *ssh.Client
-	sess         *ssh.Session
+
+	knownHostsFile        string
+	strictHostKeyChecking bool
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	client *ssh.Client
+	sess   *ssh.Session
 }
 
 var sshDial = ssh.Dial
@@ -142,6 +154,9 @@ func (c *goCryptoCommand) ensureSession() (*ssh.Session, error) {
 			}),
 		},
 	}
+	if c.strictHostKeyChecking {
+		config.HostKeyCallback = knownHostsCallback(c.knownHostsFile)
+	}
 	client, err := sshDialWithProxy(c.addr, c.proxyCommand, config)
 	if err != nil {
 		return nil, err
@@ -240,3 +255,56 @@ func splitUserHost(s string) (user, host string) {
 	}
 	return "", userHost[0]
 }
+
+// knownHostsCallback returns a host key callback that accepts only
+// the keys listed for the host in the given known hosts file, or in
+// ~/.ssh/known_hosts if the file is empty. Hashed host names are not
+// supported.
+func knownHostsCallback(file string) func(string, net.Addr, ssh.PublicKey) error {
+	if file == "" {
+		file = "~/.ssh/known_hosts"
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		path, err := utils.NormalizePath(file)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot read known hosts: %v", err)
+		}
+		host, port, err := net.SplitHostPort(hostname)
+		if err != nil {
+			host, port = hostname, ""
+		}
+		if port != "" && port != fmt.Sprint(sshDefaultPort) {
+			host = fmt.Sprintf("[%s]:%s", host, port)
+		}
+		marshalled := key.Marshal()
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+			if len(fields) != 2 || !knownHostMatches(fields[0], host) {
+				continue
+			}
+			known, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields[1]))
+			if err != nil {
+				continue
+			}
+			if bytes.Equal(known.Marshal(), marshalled) {
+				return nil
+			}
+		}
+		return fmt.Errorf("host key for %s not found in %s", host, path)
+	}
+}
+
+// knownHostMatches reports whether the comma-separated list of host
+// names in a known hosts entry includes host.
+func knownHostMatches(hosts, host string) bool {
+	for _, h := range strings.Split(hosts, ",") {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
@@ -28,6 +28,7 @@ var (
 
 type sshServer struct {
 	cfg      *cryptossh.ServerConfig
+	hostKey  cryptossh.PublicKey
 	listener net.Listener
 	client   *cryptossh.Client
 }
@@ -38,7 +39,8 @@ func newServer(c *gc.C) *sshServer {
 	key, err := cryptossh.ParsePrivateKey([]byte(private))
 	c.Assert(err, jc.ErrorIsNil)
 	server := &sshServer{
-		cfg: &cryptossh.ServerConfig{},
+		cfg:     &cryptossh.ServerConfig{},
+		hostKey: key.PublicKey(),
 	}
 	server.cfg.AddHostKey(key)
 	server.listener, err = net.Listen("tcp", "127.0.0.1:0")
@@ -155,6 +157,65 @@ func (s *SSHGoCryptoCommandSuite) TestCommand(c *gc.C) {
 	c.Assert(checkedKey, jc.IsTrue)
 }
 
+func (s *SSHGoCryptoCommandSuite) TestCommandStrictHostKeyChecking(c *gc.C) {
+	private, _, err := ssh.GenerateKey("test-client")
+	c.Assert(err, jc.ErrorIsNil)
+	key, err := cryptossh.ParsePrivateKey([]byte(private))
+	c.Assert(err, jc.ErrorIsNil)
+	client, err := ssh.NewGoCryptoClient(key)
+	c.Assert(err, jc.ErrorIsNil)
+	server := newServer(c)
+	server.cfg.PublicKeyCallback = func(cryptossh.ConnMetadata, cryptossh.PublicKey) (*cryptossh.Permissions, error) {
+		return nil, nil
+	}
+	port := server.listener.Addr().(*net.TCPAddr).Port
+	knownHosts := filepath.Join(c.MkDir(), "known_hosts")
+	line := fmt.Sprintf("[127.0.0.1]:%d %s", port, cryptossh.MarshalAuthorizedKey(server.hostKey))
+	err = ioutil.WriteFile(knownHosts, []byte(line), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var opts ssh.Options
+	opts.SetPort(port)
+	opts.SetKnownHostsFile(knownHosts)
+	opts.EnableStrictHostKeyChecking()
+	cmd := client.Command("127.0.0.1", testCommand, &opts)
+	go server.run(c)
+	out, err := cmd.Output()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(out), gc.Equals, "abc value\n")
+}
+
+func (s *SSHGoCryptoCommandSuite) TestCommandStrictHostKeyCheckingUnknownKey(c *gc.C) {
+	private, _, err := ssh.GenerateKey("test-client")
+	c.Assert(err, jc.ErrorIsNil)
+	key, err := cryptossh.ParsePrivateKey([]byte(private))
+	c.Assert(err, jc.ErrorIsNil)
+	client, err := ssh.NewGoCryptoClient(key)
+	c.Assert(err, jc.ErrorIsNil)
+	server := newServer(c)
+	defer server.listener.Close()
+	port := server.listener.Addr().(*net.TCPAddr).Port
+	knownHosts := filepath.Join(c.MkDir(), "known_hosts")
+	line := fmt.Sprintf("[127.0.0.1]:%d %s", port, cryptossh.MarshalAuthorizedKey(key.PublicKey()))
+	err = ioutil.WriteFile(knownHosts, []byte(line), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var opts ssh.Options
+	opts.SetPort(port)
+	opts.SetKnownHostsFile(knownHosts)
+	opts.EnableStrictHostKeyChecking()
+	cmd := client.Command("127.0.0.1", testCommand, &opts)
+	go func() {
+		conn, err := server.listener.Accept()
+		if err == nil {
+			cryptossh.NewServerConn(conn, server.cfg)
+			conn.Close()
+		}
+	}()
+	_, err = cmd.Output()
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`.*host key for \[127.0.0.1\]:%d not found in .*`, port))
+}
+
 func (s *SSHGoCryptoCommandSuite) TestCopy(c *gc.C) {
 	client, err := ssh.NewGoCryptoClient()
 	c.Assert(err, jc.ErrorIsNil)
@@ -64,10 +64,15 @@ func NewOpenSSHClient() (*OpenSSHClient, error) {
 }
 
 func opensshOptions(options *Options, commandKind opensshCommandKind) []string {
-	args := append([]string{}, opensshCommonOptions...)
 	if options == nil {
 		options = &Options{}
 	}
+	var args []string
+	if options.strictHostKeyChecking {
+		args = append(args, "-o", "StrictHostKeyChecking yes")
+	} else {
+		args = append(args, opensshCommonOptions...)
+	}
 	if len(options.proxyCommand) > 0 {
 		args = append(args, "-o", "ProxyCommand "+utils.CommandString(options.proxyCommand...))
 	}
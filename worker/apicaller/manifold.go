@@ -7,6 +7,7 @@ import (
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/dependency"
@@ -78,13 +79,20 @@ func startFunc(config ManifoldConfig) dependency.StartFunc {
 	}
 }
 
-// outputFunc extracts a base.APICaller from a *apiConnWorker.
+// outputFunc extracts a base.APICaller from a *apiConnWorker. It also
+// supplies the api.Connection itself, for the benefit of the machine
+// agent's workers that have not yet been moved into their own manifolds.
 func outputFunc(in worker.Worker, out interface{}) error {
 	inWorker, _ := in.(*apiConnWorker)
-	outPointer, _ := out.(*base.APICaller)
-	if inWorker == nil || outPointer == nil {
-		return errors.Errorf("expected %T->%T; got %T->%T", inWorker, outPointer, in, out)
+	if inWorker != nil {
+		switch outPointer := out.(type) {
+		case *base.APICaller:
+			*outPointer = inWorker.conn
+			return nil
+		case *api.Connection:
+			*outPointer = inWorker.conn
+			return nil
+		}
 	}
-	*outPointer = inWorker.conn
-	return nil
+	return errors.Errorf("expected %T->%T; got %T->%T", inWorker, (*base.APICaller)(nil), in, out)
 }
@@ -241,6 +241,15 @@ func (s *ManifoldSuite) TestOutputSuccess(c *gc.C) {
 	c.Check(apicaller, gc.Equals, s.conn)
 }
 
+func (s *ManifoldSuite) TestOutputConnection(c *gc.C) {
+	worker := s.setupWorkerTest(c)
+
+	var conn api.Connection
+	err := s.manifold.Output(worker, &conn)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(conn, gc.Equals, s.conn)
+}
+
 func (s *ManifoldSuite) TestOutputBadWorker(c *gc.C) {
 	var apicaller base.APICaller
 	err := s.manifold.Output(dummyWorker{}, &apicaller)
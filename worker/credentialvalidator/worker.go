@@ -0,0 +1,96 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.credentialvalidator")
+
+// DefaultCheckInterval is the default interval between checks of the
+// environment's cloud credentials.
+const DefaultCheckInterval = 30 * time.Minute
+
+// Facade represents the API used to read the environment's
+// configuration, and to record whether its credentials are valid.
+type Facade interface {
+	EnvironConfig() (*config.Config, error)
+	SetCredentialStatus(valid bool, reason string) error
+}
+
+// NewEnvironFunc opens an environ with the given configuration.
+type NewEnvironFunc func(*config.Config) (environs.Environ, error)
+
+// Config holds all necessary attributes to start a credential
+// validator worker.
+type Config struct {
+	Facade        Facade
+	NewEnviron    NewEnvironFunc
+	CheckInterval time.Duration
+	NewTimer      worker.NewTimerFunc
+}
+
+// Validate will err unless basic requirements for a valid
+// config are met.
+func (c *Config) Validate() error {
+	if c.Facade == nil {
+		return errors.New("missing Facade")
+	}
+	if c.NewEnviron == nil {
+		return errors.New("missing NewEnviron")
+	}
+	if c.NewTimer == nil {
+		return errors.New("missing Timer")
+	}
+	return nil
+}
+
+// New returns a worker.Worker that periodically checks the
+// environment's cloud credentials against the cloud, and records
+// whether the cloud accepted them. Environments whose provider cannot
+// check credentials are left alone.
+func New(conf Config) (worker.Worker, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	doCheck := func(stop <-chan struct{}) error {
+		return errors.Trace(check(conf))
+	}
+	return worker.NewPeriodicWorker(doCheck, conf.CheckInterval, conf.NewTimer), nil
+}
+
+func check(conf Config) error {
+	cfg, err := conf.Facade.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env, err := conf.NewEnviron(cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	checker, ok := env.(environs.CredentialChecker)
+	if !ok {
+		return nil
+	}
+	err = checker.CheckCredentials()
+	switch {
+	case err == nil:
+		return conf.Facade.SetCredentialStatus(true, "")
+	case errors.IsUnauthorized(err):
+		logger.Warningf("cloud credentials for environment %q are not valid: %v", cfg.Name(), err)
+		return conf.Facade.SetCredentialStatus(false, err.Error())
+	}
+	// The check could not be made, which says nothing about
+	// the credentials; try again next time.
+	logger.Warningf("cannot check cloud credentials for environment %q: %v", cfg.Name(), err)
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/credentialvalidator"
+)
+
+type credentialValidatorSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&credentialValidatorSuite{})
+
+func (s *credentialValidatorSuite) TestValidate(c *gc.C) {
+	newEnviron := func(*config.Config) (environs.Environ, error) {
+		return nil, nil
+	}
+	_, err := credentialvalidator.New(credentialvalidator.Config{
+		NewEnviron: newEnviron,
+		NewTimer:   worker.NewTimer,
+	})
+	c.Assert(err, gc.ErrorMatches, "missing Facade")
+	_, err = credentialvalidator.New(credentialvalidator.Config{
+		Facade:   newFakeFacade(c),
+		NewTimer: worker.NewTimer,
+	})
+	c.Assert(err, gc.ErrorMatches, "missing NewEnviron")
+	_, err = credentialvalidator.New(credentialvalidator.Config{
+		Facade:     newFakeFacade(c),
+		NewEnviron: newEnviron,
+	})
+	c.Assert(err, gc.ErrorMatches, "missing Timer")
+}
+
+func (s *credentialValidatorSuite) TestValidCredentials(c *gc.C) {
+	status := s.runCheck(c, &fakeEnviron{})
+	c.Assert(status, jc.DeepEquals, credentialStatus{valid: true})
+}
+
+func (s *credentialValidatorSuite) TestInvalidCredentials(c *gc.C) {
+	env := &fakeEnviron{err: errors.Unauthorizedf("access key expired")}
+	status := s.runCheck(c, env)
+	c.Assert(status, jc.DeepEquals, credentialStatus{
+		valid:  false,
+		reason: "access key expired",
+	})
+}
+
+func (s *credentialValidatorSuite) TestCheckFailedLeavesStatus(c *gc.C) {
+	facade := newFakeFacade(c)
+	env := &fakeEnviron{err: errors.New("connection refused")}
+	s.startWorker(c, facade, env)
+	select {
+	case status := <-facade.statuses:
+		c.Fatalf("unexpected status reported: %#v", status)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *credentialValidatorSuite) TestUncheckableEnviron(c *gc.C) {
+	facade := newFakeFacade(c)
+	conf := credentialvalidator.Config{
+		Facade: facade,
+		NewEnviron: func(*config.Config) (environs.Environ, error) {
+			return uncheckableEnviron{}, nil
+		},
+		CheckInterval: coretesting.ShortWait,
+		NewTimer:      worker.NewTimer,
+	}
+	w, err := credentialvalidator.New(conf)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+	select {
+	case status := <-facade.statuses:
+		c.Fatalf("unexpected status reported: %#v", status)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *credentialValidatorSuite) runCheck(c *gc.C, env *fakeEnviron) credentialStatus {
+	facade := newFakeFacade(c)
+	s.startWorker(c, facade, env)
+	select {
+	case status := <-facade.statuses:
+		return status
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for validator to call SetCredentialStatus")
+	}
+	panic("unreachable")
+}
+
+func (s *credentialValidatorSuite) startWorker(c *gc.C, facade *fakeFacade, env *fakeEnviron) {
+	conf := credentialvalidator.Config{
+		Facade: facade,
+		NewEnviron: func(cfg *config.Config) (environs.Environ, error) {
+			c.Check(cfg, gc.Equals, facade.config)
+			return env, nil
+		},
+		CheckInterval: coretesting.LongWait,
+		NewTimer:      worker.NewTimer,
+	}
+	w, err := credentialvalidator.New(conf)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) {
+		c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	})
+}
+
+type credentialStatus struct {
+	valid  bool
+	reason string
+}
+
+type fakeFacade struct {
+	config   *config.Config
+	statuses chan credentialStatus
+}
+
+func newFakeFacade(c *gc.C) *fakeFacade {
+	return &fakeFacade{
+		config:   coretesting.EnvironConfig(c),
+		statuses: make(chan credentialStatus, 1),
+	}
+}
+
+// EnvironConfig implements Facade.
+func (f *fakeFacade) EnvironConfig() (*config.Config, error) {
+	return f.config, nil
+}
+
+// SetCredentialStatus implements Facade.
+func (f *fakeFacade) SetCredentialStatus(valid bool, reason string) error {
+	select {
+	case f.statuses <- credentialStatus{valid, reason}:
+	case <-time.After(coretesting.LongWait):
+		return errors.New("timed out waiting for facade call SetCredentialStatus to run")
+	}
+	return nil
+}
+
+// fakeEnviron is an environs.Environ that can check its credentials.
+type fakeEnviron struct {
+	environs.Environ
+	err error
+}
+
+// CheckCredentials implements environs.CredentialChecker.
+func (e *fakeEnviron) CheckCredentials() error {
+	return e.err
+}
+
+// uncheckableEnviron is an environs.Environ that cannot check its
+// credentials.
+type uncheckableEnviron struct {
+	environs.Environ
+}
@@ -44,7 +44,7 @@ type SimpleContext struct {
 	// discoverService is a surrogate for service.DiscoverService.
 	discoverService func(string, common.Conf) (deployerService, error)
 
-	// listServices is a surrogate for service.ListServices.
+	// listServices is a surrogate for service.ListServicesMatching.
 	listServices func() ([]string, error)
 }
 
@@ -79,7 +79,7 @@ func NewSimpleContext(agentConfig agent.Config, api APICalls) *SimpleContext {
 			return service.DiscoverService(name, conf)
 		},
 		listServices: func() ([]string, error) {
-			return service.ListServices()
+			return service.ListServicesMatching("jujud-")
 		},
 	}
 }
@@ -173,8 +173,9 @@ type deployerService interface {
 
 // findUpstartJob tries to find an init system job matching the
 // given unit name in one of these formats:
-//   jujud-<deployer-tag>:<unit-tag>.conf (for compatibility)
-//   jujud-<unit-tag>.conf (default)
+//
+//	jujud-<deployer-tag>:<unit-tag>.conf (for compatibility)
+//	jujud-<unit-tag>.conf (default)
 func (ctx *SimpleContext) findInitSystemJob(unitName string) (deployerService, error) {
 	unitsAndJobs, err := ctx.deployedUnitsInitSystemJobs()
 	if err != nil {
@@ -237,6 +237,30 @@ func (s *InstanceModeSuite) TestMultipleExposedServices(c *gc.C) {
 	s.assertPorts(c, inst2, m2.Id(), nil)
 }
 
+func (s *InstanceModeSuite) TestExposedServiceICMP(c *gc.C) {
+	fw, err := firewaller.NewFirewaller(s.firewaller)
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertKillAndWait(c, fw)
+
+	svc := s.AddTestingService(c, "wordpress", s.charm)
+	err = svc.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	u, m := s.addUnit(c, svc)
+	inst := s.startInstance(c, m)
+	err = u.OpenPorts("icmp", -1, -1)
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.OpenPort("tcp", 80)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{network.ICMPPortRange, {80, 80, "tcp"}})
+
+	err = u.ClosePorts("icmp", -1, -1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{80, 80, "tcp"}})
+}
+
 func (s *InstanceModeSuite) TestMachineWithoutInstanceId(c *gc.C) {
 	fw, err := firewaller.NewFirewaller(s.firewaller)
 	c.Assert(err, jc.ErrorIsNil)
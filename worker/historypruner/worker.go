@@ -0,0 +1,54 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package historypruner
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/worker"
+)
+
+// DefaultPruneInterval is the default interval between prune calls.
+const DefaultPruneInterval = 5 * time.Minute
+
+// Facade represents an API that implements history pruning. The
+// records kept are determined by the retention policies held in the
+// environment's config.
+type Facade interface {
+	Prune() error
+}
+
+// Config holds all necessary attributes to start a pruner worker.
+type Config struct {
+	Facade        Facade
+	PruneInterval time.Duration
+	NewTimer      worker.NewTimerFunc
+}
+
+// Validate will err unless basic requirements for a valid
+// config are met.
+func (c *Config) Validate() error {
+	if c.Facade == nil {
+		return errors.New("missing Facade")
+	}
+	if c.NewTimer == nil {
+		return errors.New("missing Timer")
+	}
+	return nil
+}
+
+// New returns a worker.Worker that periodically prunes the
+// environment's status history, finished actions and other historical
+// records.
+func New(conf Config) (worker.Worker, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	doPruning := func(stop <-chan struct{}) error {
+		return errors.Trace(conf.Facade.Prune())
+	}
+	return worker.NewPeriodicWorker(doPruning, conf.PruneInterval, conf.NewTimer), nil
+}
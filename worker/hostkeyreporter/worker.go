@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostkeyreporter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.hostkeyreporter")
+
+// DefaultReportInterval is the default interval between reports of
+// the machine's SSH host keys.
+const DefaultReportInterval = 10 * time.Minute
+
+// Facade represents the API used to record a machine's SSH host keys,
+// so that clients connecting to the machine can verify them.
+type Facade interface {
+	ReportKeys(tag names.MachineTag, publicKeys []string) error
+}
+
+// Config holds all necessary attributes to start a host key reporter
+// worker.
+type Config struct {
+	Facade         Facade
+	MachineTag     names.MachineTag
+	RootDir        string
+	ReportInterval time.Duration
+	NewTimer       worker.NewTimerFunc
+}
+
+// Validate will err unless basic requirements for a valid
+// config are met.
+func (c *Config) Validate() error {
+	if c.Facade == nil {
+		return errors.New("missing Facade")
+	}
+	if c.MachineTag.Id() == "" {
+		return errors.New("missing MachineTag")
+	}
+	if c.NewTimer == nil {
+		return errors.New("missing Timer")
+	}
+	return nil
+}
+
+// New returns a worker.Worker that periodically reports the public
+// SSH host keys found in /etc/ssh, under the configured root
+// directory, for the machine.
+func New(conf Config) (worker.Worker, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	doReport := func(stop <-chan struct{}) error {
+		keys, err := readPublicKeys(conf.RootDir)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(keys) == 0 {
+			logger.Warningf("no SSH host keys found")
+			return nil
+		}
+		return errors.Trace(conf.Facade.ReportKeys(conf.MachineTag, keys))
+	}
+	return worker.NewPeriodicWorker(doReport, conf.ReportInterval, conf.NewTimer), nil
+}
+
+// readPublicKeys returns the contents of the public SSH host key
+// files in the given root directory's /etc/ssh.
+func readPublicKeys(rootDir string) ([]string, error) {
+	pattern := filepath.Join(rootDir, "etc", "ssh", "ssh_host_*_key.pub")
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var keys []string
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read SSH host key")
+		}
+		keys = append(keys, strings.TrimSpace(string(data)))
+	}
+	return keys, nil
+}
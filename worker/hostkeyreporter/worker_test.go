@@ -0,0 +1,145 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostkeyreporter_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/hostkeyreporter"
+)
+
+type hostKeyReporterSuite struct {
+	coretesting.BaseSuite
+	rootDir string
+}
+
+var _ = gc.Suite(&hostKeyReporterSuite{})
+
+func (s *hostKeyReporterSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.rootDir = c.MkDir()
+	sshDir := filepath.Join(s.rootDir, "etc", "ssh")
+	err := os.MkdirAll(sshDir, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	for name, content := range map[string]string{
+		"ssh_host_rsa_key.pub":     "ssh-rsa rsa\n",
+		"ssh_host_ed25519_key.pub": "ssh-ed25519 ed25519\n",
+		"ssh_host_rsa_key":         "private",
+	} {
+		err := ioutil.WriteFile(filepath.Join(sshDir, name), []byte(content), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *hostKeyReporterSuite) TestValidate(c *gc.C) {
+	_, err := hostkeyreporter.New(hostkeyreporter.Config{
+		MachineTag: names.NewMachineTag("0"),
+		NewTimer:   worker.NewTimer,
+	})
+	c.Assert(err, gc.ErrorMatches, "missing Facade")
+	_, err = hostkeyreporter.New(hostkeyreporter.Config{
+		Facade:   newFakeFacade(),
+		NewTimer: worker.NewTimer,
+	})
+	c.Assert(err, gc.ErrorMatches, "missing MachineTag")
+	_, err = hostkeyreporter.New(hostkeyreporter.Config{
+		Facade:     newFakeFacade(),
+		MachineTag: names.NewMachineTag("0"),
+	})
+	c.Assert(err, gc.ErrorMatches, "missing Timer")
+}
+
+func (s *hostKeyReporterSuite) TestWorkerReportsKeys(c *gc.C) {
+	fakeTimer := newMockTimer()
+	facade := newFakeFacade()
+	conf := hostkeyreporter.Config{
+		Facade:         facade,
+		MachineTag:     names.NewMachineTag("0"),
+		RootDir:        s.rootDir,
+		ReportInterval: coretesting.ShortWait,
+		NewTimer: func(d time.Duration) worker.PeriodicTimer {
+			return fakeTimer
+		},
+	}
+
+	reporter, err := hostkeyreporter.New(conf)
+	c.Check(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) {
+		c.Assert(worker.Stop(reporter), jc.ErrorIsNil)
+	})
+
+	err = fakeTimer.fire()
+	c.Check(err, jc.ErrorIsNil)
+
+	select {
+	case report := <-facade.reports:
+		c.Assert(report.tag, gc.Equals, names.NewMachineTag("0"))
+		c.Assert(report.keys, jc.DeepEquals, []string{
+			"ssh-ed25519 ed25519",
+			"ssh-rsa rsa",
+		})
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for reporter to call ReportKeys")
+	}
+}
+
+type mockTimer struct {
+	c chan time.Time
+}
+
+func (t *mockTimer) Reset(d time.Duration) bool {
+	return true
+}
+
+func (t *mockTimer) CountDown() <-chan time.Time {
+	return t.c
+}
+
+func (t *mockTimer) fire() error {
+	select {
+	case t.c <- time.Time{}:
+	case <-time.After(coretesting.LongWait):
+		return errors.New("timed out waiting for reporter to run")
+	}
+	return nil
+}
+
+func newMockTimer() *mockTimer {
+	return &mockTimer{c: make(chan time.Time)}
+}
+
+type report struct {
+	tag  names.MachineTag
+	keys []string
+}
+
+type fakeFacade struct {
+	reports chan report
+}
+
+func newFakeFacade() *fakeFacade {
+	return &fakeFacade{
+		reports: make(chan report, 1),
+	}
+}
+
+// ReportKeys implements Facade.
+func (f *fakeFacade) ReportKeys(tag names.MachineTag, keys []string) error {
+	select {
+	case f.reports <- report{tag, keys}:
+	case <-time.After(coretesting.LongWait):
+		return errors.New("timed out waiting for facade call ReportKeys to run")
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	apiinstancepoller "github.com/juju/juju/api/instancepoller"
 	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs/instancecache"
 	"github.com/juju/juju/worker"
 )
 
@@ -48,7 +49,7 @@ func (u *updaterWorker) loop() (err error) {
 	if err != nil {
 		return err
 	}
-	u.aggregator = newAggregator(u.observer.Environ())
+	u.aggregator = newAggregator(instancecache.ForEnviron(u.observer.Environ()))
 	logger.Infof("instance poller received inital environment configuration")
 	defer func() {
 		obsErr := worker.Stop(u.observer)
@@ -0,0 +1,243 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package introspection provides a worker that serves runtime
+// diagnostics for an agent over a local, abstract-namespace unix
+// socket, so that stuck agents can be examined in the field.
+//
+// The socket can be queried with, for example:
+//
+//	echo -e "GET /goroutines HTTP/1.0\r\n" | socat abstract-connect:jujud-machine-0 STDIO
+package introspection
+
+import (
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	goyaml "gopkg.in/yaml.v2"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+)
+
+var logger = loggo.GetLogger("juju.worker.introspection")
+
+// Config describes the arguments required to create the introspection
+// worker.
+type Config struct {
+	// SocketName is the name of the abstract unix socket to listen
+	// on; it must not include the leading "@".
+	SocketName string
+
+	// Reporter, if set, supplies the worker engine status and
+	// dependency graph. It may be nil.
+	Reporter dependency.Reporter
+}
+
+// Validate returns an error if the config cannot be used.
+func (config Config) Validate() error {
+	if config.SocketName == "" {
+		return errors.NotValidf("empty SocketName")
+	}
+	return nil
+}
+
+type introspectionWorker struct {
+	tomb     tomb.Tomb
+	listener *net.UnixListener
+	reporter dependency.Reporter
+}
+
+// NewWorker starts an HTTP server listening on the abstract unix
+// socket named in the config, and returns a worker that stops the
+// server when killed. It is only supported on linux.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if runtime.GOOS != "linux" {
+		return nil, errors.NotSupportedf("introspection on %s", runtime.GOOS)
+	}
+	path := "@" + config.SocketName
+	addr, err := net.ResolveUnixAddr("unix", path)
+	if err != nil {
+		return nil, errors.Annotate(err, "unable to resolve unix socket")
+	}
+	listener, err := net.ListenUnix("unix", addr)
+	if err != nil {
+		return nil, errors.Annotate(err, "unable to listen on unix socket")
+	}
+	logger.Debugf("introspection listening on %q", path)
+
+	w := &introspectionWorker{
+		listener: listener,
+		reporter: config.Reporter,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *introspectionWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *introspectionWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+func (w *introspectionWorker) loop() error {
+	mux := http.NewServeMux()
+	registerEndpoints(mux, w.reporter)
+	srv := http.Server{Handler: mux}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(w.listener)
+	}()
+	select {
+	case <-w.tomb.Dying():
+		// Closing the listener causes Serve to return.
+		w.listener.Close()
+		<-served
+		return tomb.ErrDying
+	case err := <-served:
+		w.listener.Close()
+		return errors.Annotate(err, "introspection server stopped")
+	}
+}
+
+// registerEndpoints adds the introspection handlers to the mux.
+func registerEndpoints(mux *http.ServeMux, reporter dependency.Reporter) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/goroutines", goroutinesHandler)
+	mux.HandleFunc("/metrics", metricsHandler)
+	mux.Handle("/depengine/", depengineHandler{reporter})
+	mux.Handle("/depengine/graph", depgraphHandler{reporter})
+}
+
+// goroutinesHandler writes the stacks of all current goroutines.
+func goroutinesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	profile := runtimepprof.Lookup("goroutine")
+	if err := profile.WriteTo(w, 2); err != nil {
+		logger.Errorf("cannot write goroutines: %v", err)
+	}
+}
+
+// metricsHandler writes the process's published expvar variables,
+// such as the API server's rejected request counts, as a JSON object.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprint(w, "{")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if !first {
+			fmt.Fprint(w, ",")
+		}
+		first = false
+		fmt.Fprintf(w, "\n%q: %s", kv.Key, kv.Value)
+	})
+	fmt.Fprint(w, "\n}\n")
+}
+
+// depengineHandler writes the reporter's report as YAML.
+type depengineHandler struct {
+	reporter dependency.Reporter
+}
+
+// ServeHTTP is part of the http.Handler interface.
+func (h depengineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.reporter == nil {
+		http.Error(w, "missing reporter", http.StatusNotFound)
+		return
+	}
+	bytes, err := goyaml.Marshal(sanitiseReport(h.reporter.Report()))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(bytes)
+}
+
+// depgraphHandler writes the dependencies between the reporter's
+// manifolds in graphviz dot format.
+type depgraphHandler struct {
+	reporter dependency.Reporter
+}
+
+// ServeHTTP is part of the http.Handler interface.
+func (h depgraphHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.reporter == nil {
+		http.Error(w, "missing reporter", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	fmt.Fprint(w, dependencyGraph(h.reporter.Report()))
+}
+
+// dependencyGraph returns a dot graph with an edge from each manifold
+// in the report to each of its inputs.
+func dependencyGraph(report map[string]interface{}) string {
+	manifolds, _ := report[dependency.KeyManifolds].(map[string]interface{})
+	names := make([]string, 0, len(manifolds))
+	for name := range manifolds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	graph := "digraph dependencies {\n"
+	for _, name := range names {
+		manifold, _ := manifolds[name].(map[string]interface{})
+		state, _ := manifold[dependency.KeyState].(string)
+		graph += fmt.Sprintf("\t%q [label=%q];\n", name, fmt.Sprintf("%s\n(%s)", name, state))
+		inputs, _ := manifold[dependency.KeyInputs].([]string)
+		for _, input := range inputs {
+			graph += fmt.Sprintf("\t%q -> %q;\n", name, input)
+		}
+	}
+	return graph + "}\n"
+}
+
+// sanitiseReport returns a copy of the report in which errors are
+// replaced by their messages, so that they can be marshalled.
+func sanitiseReport(report map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(report))
+	for key, value := range report {
+		result[key] = sanitiseValue(value)
+	}
+	return result
+}
+
+func sanitiseValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case error:
+		return value.Error()
+	case map[string]interface{}:
+		return sanitiseReport(value)
+	case []map[string]interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = sanitiseReport(item)
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,176 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package introspection_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"runtime"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/introspection"
+)
+
+type suite struct {
+	testing.IsolationSuite
+	name     string
+	reporter *reporter
+	worker   worker.Worker
+}
+
+var _ = gc.Suite(&suite{})
+
+func (s *suite) SetUpTest(c *gc.C) {
+	if runtime.GOOS != "linux" {
+		c.Skip("introspection worker not supported on non-linux")
+	}
+	s.IsolationSuite.SetUpTest(c)
+	s.name = "introspection-test"
+	s.reporter = &reporter{
+		values: map[string]interface{}{
+			dependency.KeyState: "started",
+			dependency.KeyManifolds: map[string]interface{}{
+				"agent": map[string]interface{}{
+					dependency.KeyState:  "started",
+					dependency.KeyInputs: []string{},
+				},
+				"api-caller": map[string]interface{}{
+					dependency.KeyState:  "stopped",
+					dependency.KeyError:  errors.New("boom"),
+					dependency.KeyInputs: []string{"agent"},
+				},
+			},
+		},
+	}
+	s.startWorker(c, s.reporter)
+}
+
+func (s *suite) TearDownTest(c *gc.C) {
+	if s.worker != nil {
+		c.Check(worker.Stop(s.worker), jc.ErrorIsNil)
+	}
+	s.IsolationSuite.TearDownTest(c)
+}
+
+func (s *suite) startWorker(c *gc.C, reporter dependency.Reporter) {
+	if s.worker != nil {
+		c.Assert(worker.Stop(s.worker), jc.ErrorIsNil)
+	}
+	config := introspection.Config{SocketName: s.name}
+	if reporter != nil {
+		config.Reporter = reporter
+	}
+	w, err := introspection.NewWorker(config)
+	c.Assert(err, jc.ErrorIsNil)
+	s.worker = w
+}
+
+func (s *suite) get(c *gc.C, path string) (int, string) {
+	client := http.Client{
+		Transport: &http.Transport{
+			Dial: func(_, _ string) (net.Conn, error) {
+				return net.Dial("unix", "@"+s.name)
+			},
+		},
+	}
+	resp, err := client.Get("http://unix.socket" + path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	return resp.StatusCode, string(body)
+}
+
+func (s *suite) TestConfigValidation(c *gc.C) {
+	w, err := introspection.NewWorker(introspection.Config{})
+	c.Check(w, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "empty SocketName not valid")
+}
+
+func (s *suite) TestStopsListening(c *gc.C) {
+	c.Assert(worker.Stop(s.worker), jc.ErrorIsNil)
+	s.worker = nil
+	_, err := net.Dial("unix", "@"+s.name)
+	c.Assert(err, gc.ErrorMatches, ".*connection refused")
+}
+
+func (s *suite) TestGoroutines(c *gc.C) {
+	code, body := s.get(c, "/goroutines")
+	c.Assert(code, gc.Equals, http.StatusOK)
+	c.Assert(body, jc.Contains, "goroutine ")
+	c.Assert(body, jc.Contains, "introspection")
+}
+
+func (s *suite) TestPprof(c *gc.C) {
+	code, body := s.get(c, "/debug/pprof/goroutine?debug=1")
+	c.Assert(code, gc.Equals, http.StatusOK)
+	c.Assert(body, jc.HasPrefix, "goroutine profile: total ")
+}
+
+var testMetric = expvar.NewInt("introspection-test-metric")
+
+func (s *suite) TestMetrics(c *gc.C) {
+	testMetric.Set(42)
+	code, body := s.get(c, "/metrics")
+	c.Assert(code, gc.Equals, http.StatusOK)
+	var metrics map[string]interface{}
+	err := json.Unmarshal([]byte(body), &metrics)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metrics["introspection-test-metric"], gc.Equals, float64(42))
+}
+
+func (s *suite) TestDepengine(c *gc.C) {
+	code, body := s.get(c, "/depengine/")
+	c.Assert(code, gc.Equals, http.StatusOK)
+	c.Assert(body, gc.Equals, `
+manifolds:
+  agent:
+    inputs: []
+    state: started
+  api-caller:
+    error: boom
+    inputs:
+    - agent
+    state: stopped
+state: started
+`[1:])
+}
+
+func (s *suite) TestDepengineGraph(c *gc.C) {
+	code, body := s.get(c, "/depengine/graph")
+	c.Assert(code, gc.Equals, http.StatusOK)
+	c.Assert(body, gc.Equals, `
+digraph dependencies {
+	"agent" [label="agent\n(started)"];
+	"api-caller" [label="api-caller\n(stopped)"];
+	"api-caller" -> "agent";
+}
+`[1:])
+}
+
+func (s *suite) TestMissingReporter(c *gc.C) {
+	s.startWorker(c, nil)
+	for _, path := range []string{"/depengine/", "/depengine/graph"} {
+		code, body := s.get(c, path)
+		c.Check(code, gc.Equals, http.StatusNotFound)
+		c.Check(body, gc.Equals, "missing reporter\n")
+	}
+}
+
+type reporter struct {
+	values map[string]interface{}
+}
+
+func (r *reporter) Report() map[string]interface{} {
+	return r.values
+}
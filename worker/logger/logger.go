@@ -9,6 +9,7 @@ import (
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api/logger"
 	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/logging"
 	"github.com/juju/juju/worker"
 )
 
@@ -43,13 +44,11 @@ func (logger *Logger) setLogging() {
 	} else {
 		if loggingConfig != logger.lastConfig {
 			log.Debugf("reconfiguring logging from %q to %q", logger.lastConfig, loggingConfig)
-			loggo.ResetLoggers()
-			if err := loggo.ConfigureLoggers(loggingConfig); err != nil {
+			if _, err := logging.ReplaceConfig(loggingConfig); err != nil {
 				// This shouldn't occur as the loggingConfig should be
 				// validated by the original Config before it gets here.
+				// The previous configuration is kept.
 				log.Warningf("configure loggers failed: %v", err)
-				// Try to reset to what we had before
-				loggo.ConfigureLoggers(logger.lastConfig)
 			}
 			logger.lastConfig = loggingConfig
 		}
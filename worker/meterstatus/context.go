@@ -72,6 +72,11 @@ func (ctx *limitedContext) Flush(_ string, err error) error {
 	return err
 }
 
+// ActionAborted implements runner.Context.
+func (ctx *limitedContext) ActionAborted() (bool, error) {
+	return false, jujuc.ErrRestrictedContext
+}
+
 // HasExecutionSetUnitStatus implements runner.Context.
 func (ctx *limitedContext) HasExecutionSetUnitStatus() bool { return false }
 
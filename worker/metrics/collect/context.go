@@ -75,6 +75,11 @@ func (ctx *hookContext) ActionData() (*context.ActionData, error) {
 	return nil, jujuc.ErrRestrictedContext
 }
 
+// ActionAborted implements runner.Context.
+func (ctx *hookContext) ActionAborted() (bool, error) {
+	return false, jujuc.ErrRestrictedContext
+}
+
 // HasExecutionSetUnitStatus implements runner.Context.
 func (ctx *hookContext) HasExecutionSetUnitStatus() bool { return false }
 
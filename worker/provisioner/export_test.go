@@ -96,3 +96,19 @@ func init() {
 }
 
 var ClassifyMachine = classifyMachine
+
+var (
+	NewInstanceLifecycle = newInstanceLifecycle
+	RunHookCommands      = &runHookCommands
+)
+
+var (
+	StartInstanceRetryCount = &startInstanceRetryCount
+	StartInstanceRetryDelay = &startInstanceRetryDelay
+)
+
+// ClassifyStartInstanceError returns the reason recorded in a
+// machine's status data when starting its instance fails with err.
+func ClassifyStartInstanceError(err error) string {
+	return string(classifyStartInstanceError(err))
+}
@@ -106,6 +106,8 @@ func (broker *kvmBroker) StartInstance(args environs.StartInstanceParams) (*envi
 		config.Proxy,
 		config.AptProxy,
 		config.AptMirror,
+		config.PackageSources,
+		config.PackageSourceKeys,
 		config.PreferIPv6,
 		config.EnableOSRefreshUpdate,
 		config.EnableOSUpgrade,
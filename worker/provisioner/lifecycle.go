@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/exec"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+)
+
+// runHookCommands is patched by tests.
+var runHookCommands = exec.RunCommands
+
+// instanceHooks implements environs.InstanceLifecycle by running the
+// shell commands set in the provisioning machine's agent configuration.
+type instanceHooks struct {
+	preStart     string
+	postStart    string
+	preTerminate string
+	envName      string
+	envUUID      string
+}
+
+var _ environs.InstanceLifecycle = (*instanceHooks)(nil)
+
+// newInstanceLifecycle returns the InstanceLifecycle the provisioner
+// uses around starting and stopping instances with broker, or nil if
+// there is none. A broker that implements environs.InstanceLifecycle
+// itself is used as it is; otherwise the hooks set by the operator in
+// the agent configuration, if any, are run.
+func newInstanceLifecycle(broker environs.InstanceBroker, agentConfig agent.Config, envCfg *config.Config) environs.InstanceLifecycle {
+	if lifecycle, ok := broker.(environs.InstanceLifecycle); ok {
+		return lifecycle
+	}
+	hooks := &instanceHooks{
+		preStart:     agentConfig.Value(agent.PreStartHook),
+		postStart:    agentConfig.Value(agent.PostStartHook),
+		preTerminate: agentConfig.Value(agent.PreTerminateHook),
+		envName:      envCfg.Name(),
+	}
+	if hooks.preStart == "" && hooks.postStart == "" && hooks.preTerminate == "" {
+		return nil
+	}
+	hooks.envUUID, _ = envCfg.UUID()
+	return hooks
+}
+
+// PreStartInstance is specified in the environs.InstanceLifecycle
+// interface.
+func (h *instanceHooks) PreStartInstance(args environs.StartInstanceParams) error {
+	return h.run("pre-start-hook", h.preStart, startHookVars(args, nil))
+}
+
+// PostStartInstance is specified in the environs.InstanceLifecycle
+// interface.
+func (h *instanceHooks) PostStartInstance(args environs.StartInstanceParams, result *environs.StartInstanceResult) error {
+	return h.run("post-start-hook", h.postStart, startHookVars(args, result))
+}
+
+// PreStopInstances is specified in the environs.InstanceLifecycle
+// interface. The hook is run once for each instance; all the
+// instances are visited, the first error is returned and the others
+// are logged.
+func (h *instanceHooks) PreStopInstances(ids ...instance.Id) error {
+	var firstErr error
+	for _, id := range ids {
+		err := h.run("pre-terminate-hook", h.preTerminate, []string{"JUJU_INSTANCE_ID=" + string(id)})
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		} else {
+			logger.Errorf("instance %s: %v", id, err)
+		}
+	}
+	return firstErr
+}
+
+// startHookVars returns the environment variables describing the
+// machine being started, and the instance started for it if any.
+func startHookVars(args environs.StartInstanceParams, result *environs.StartInstanceResult) []string {
+	var vars []string
+	if icfg := args.InstanceConfig; icfg != nil {
+		vars = append(vars,
+			"JUJU_MACHINE_ID="+icfg.MachineId,
+			"JUJU_SERIES="+icfg.Series,
+		)
+	}
+	if result == nil || result.Instance == nil {
+		return vars
+	}
+	vars = append(vars, "JUJU_INSTANCE_ID="+string(result.Instance.Id()))
+	if hw := result.Hardware; hw != nil && hw.AvailabilityZone != nil {
+		vars = append(vars, "JUJU_AVAILABILITY_ZONE="+*hw.AvailabilityZone)
+	}
+	return vars
+}
+
+// run runs the given hook command, if any, with the variables added
+// to the environment. The environment's name and UUID, and the name
+// of the hook, are always added.
+func (h *instanceHooks) run(name, command string, vars []string) error {
+	if command == "" {
+		return nil
+	}
+	env := append(os.Environ(),
+		"JUJU_ENV_NAME="+h.envName,
+		"JUJU_ENV_UUID="+h.envUUID,
+		"JUJU_HOOK_NAME="+strings.TrimSuffix(name, "-hook"),
+	)
+	env = append(env, vars...)
+	logger.Debugf("running %s: %s (%s)", name, command, strings.Join(vars, " "))
+	result, err := runHookCommands(exec.RunParams{
+		Commands:    command,
+		Environment: env,
+	})
+	if err != nil {
+		return errors.Annotatef(err, "running %s", name)
+	}
+	if result.Code != 0 {
+		output := strings.TrimSpace(string(result.Stderr))
+		if output == "" {
+			output = strings.TrimSpace(string(result.Stdout))
+		}
+		msg := fmt.Sprintf("%s failed with exit code %d", name, result.Code)
+		if output != "" {
+			msg += ": " + output
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
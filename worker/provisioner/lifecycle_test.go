@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/exec"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/cloudconfig/instancecfg"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/provisioner"
+)
+
+type lifecycleSuite struct {
+	coretesting.BaseSuite
+	runs []exec.RunParams
+	code int
+}
+
+var _ = gc.Suite(&lifecycleSuite{})
+
+func (s *lifecycleSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.runs = nil
+	s.code = 0
+	s.PatchValue(provisioner.RunHookCommands, func(args exec.RunParams) (*exec.ExecResponse, error) {
+		s.runs = append(s.runs, args)
+		return &exec.ExecResponse{Code: s.code, Stderr: []byte("no such load balancer\n")}, nil
+	})
+}
+
+func (s *lifecycleSuite) lifecycle(c *gc.C, values map[string]string) environs.InstanceLifecycle {
+	return provisioner.NewInstanceLifecycle(nil, hookAgentConfig{values: values}, coretesting.EnvironConfig(c))
+}
+
+func (s *lifecycleSuite) checkVars(c *gc.C, run exec.RunParams, expect ...string) {
+	env := make(map[string]bool)
+	for _, v := range run.Environment {
+		env[v] = true
+	}
+	for _, v := range expect {
+		c.Check(env[v], jc.IsTrue, gc.Commentf("%s not set", v))
+	}
+}
+
+func (s *lifecycleSuite) TestNoHooks(c *gc.C) {
+	c.Assert(s.lifecycle(c, nil), gc.IsNil)
+}
+
+func (s *lifecycleSuite) TestBrokerLifecycle(c *gc.C) {
+	broker := &lifecycleBroker{}
+	lifecycle := provisioner.NewInstanceLifecycle(broker, hookAgentConfig{
+		values: map[string]string{agent.PreStartHook: "check-quota"},
+	}, coretesting.EnvironConfig(c))
+	c.Assert(lifecycle, gc.Equals, broker)
+}
+
+func (s *lifecycleSuite) TestStartHooks(c *gc.C) {
+	lifecycle := s.lifecycle(c, map[string]string{
+		agent.PreStartHook:  "check-quota",
+		agent.PostStartHook: "attach-lb",
+	})
+	zone := "zone-a"
+	args := environs.StartInstanceParams{
+		InstanceConfig: &instancecfg.InstanceConfig{MachineId: "3", Series: "trusty"},
+	}
+	result := &environs.StartInstanceResult{
+		Instance: &testInstance{id: "i-3"},
+		Hardware: &instance.HardwareCharacteristics{AvailabilityZone: &zone},
+	}
+	err := lifecycle.PreStartInstance(args)
+	c.Assert(err, jc.ErrorIsNil)
+	err = lifecycle.PostStartInstance(args, result)
+	c.Assert(err, jc.ErrorIsNil)
+	err = lifecycle.PreStopInstances("i-3")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.runs, gc.HasLen, 2)
+	c.Check(s.runs[0].Commands, gc.Equals, "check-quota")
+	s.checkVars(c, s.runs[0], "JUJU_HOOK_NAME=pre-start", "JUJU_ENV_NAME=testenv", "JUJU_MACHINE_ID=3", "JUJU_SERIES=trusty")
+	c.Check(s.runs[1].Commands, gc.Equals, "attach-lb")
+	s.checkVars(c, s.runs[1], "JUJU_HOOK_NAME=post-start", "JUJU_MACHINE_ID=3", "JUJU_INSTANCE_ID=i-3", "JUJU_AVAILABILITY_ZONE=zone-a")
+}
+
+func (s *lifecycleSuite) TestHookFails(c *gc.C) {
+	lifecycle := s.lifecycle(c, map[string]string{agent.PostStartHook: "attach-lb"})
+	s.code = 2
+	err := lifecycle.PostStartInstance(environs.StartInstanceParams{}, &environs.StartInstanceResult{})
+	c.Assert(err, gc.ErrorMatches, "post-start-hook failed with exit code 2: no such load balancer")
+}
+
+func (s *lifecycleSuite) TestHookCannotRun(c *gc.C) {
+	lifecycle := s.lifecycle(c, map[string]string{agent.PreStartHook: "check-quota"})
+	s.PatchValue(provisioner.RunHookCommands, func(exec.RunParams) (*exec.ExecResponse, error) {
+		return nil, errors.New("no shell")
+	})
+	err := lifecycle.PreStartInstance(environs.StartInstanceParams{})
+	c.Assert(err, gc.ErrorMatches, "running pre-start-hook: no shell")
+}
+
+func (s *lifecycleSuite) TestPreStopInstances(c *gc.C) {
+	lifecycle := s.lifecycle(c, map[string]string{agent.PreTerminateHook: "deregister"})
+	s.code = 1
+	err := lifecycle.PreStopInstances("i-0", "i-1")
+	c.Assert(err, gc.ErrorMatches, "pre-terminate-hook failed with exit code 1: no such load balancer")
+	c.Assert(s.runs, gc.HasLen, 2)
+	s.checkVars(c, s.runs[0], "JUJU_HOOK_NAME=pre-terminate", "JUJU_INSTANCE_ID=i-0")
+	s.checkVars(c, s.runs[1], "JUJU_INSTANCE_ID=i-1")
+}
+
+// hookAgentConfig is an agent.Config holding only values.
+type hookAgentConfig struct {
+	agent.Config
+	values map[string]string
+}
+
+func (c hookAgentConfig) Value(key string) string {
+	return c.values[key]
+}
+
+// lifecycleBroker is an environs.InstanceBroker that implements
+// environs.InstanceLifecycle itself.
+type lifecycleBroker struct {
+	environs.InstanceBroker
+	environs.InstanceLifecycle
+}
+
+type testInstance struct {
+	instance.Instance
+	id instance.Id
+}
+
+func (inst *testInstance) Id() instance.Id {
+	return inst.id
+}
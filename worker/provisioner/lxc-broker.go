@@ -155,6 +155,8 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (*envi
 		config.Proxy,
 		config.AptProxy,
 		config.AptMirror,
+		config.PackageSources,
+		config.PackageSourceKeys,
 		config.PreferIPv6,
 		config.EnableOSRefreshUpdate,
 		config.EnableOSUpgrade,
@@ -35,6 +35,7 @@ type Provisioner interface {
 	Stop() error
 	getMachineWatcher() (apiwatcher.StringsWatcher, error)
 	getRetryWatcher() (apiwatcher.NotifyWatcher, error)
+	getCredentialStatusGetter() CredentialStatusGetter
 }
 
 // environProvisioner represents a running provisioning worker for machine nodes
@@ -151,6 +152,8 @@ func (p *provisioner) getStartTask(harvestMode config.HarvestMode) (ProvisionerT
 		auth,
 		envCfg.ImageStream(),
 		secureServerConnection,
+		p.getCredentialStatusGetter(),
+		newInstanceLifecycle(p.broker, p.agentConfig, envCfg),
 	)
 	return task, nil
 }
@@ -230,6 +233,10 @@ func (p *environProvisioner) getRetryWatcher() (apiwatcher.NotifyWatcher, error)
 	return p.st.WatchMachineErrorRetry()
 }
 
+func (p *environProvisioner) getCredentialStatusGetter() CredentialStatusGetter {
+	return p.st
+}
+
 // setConfig updates the environment configuration and notifies
 // the config observer.
 func (p *environProvisioner) setConfig(environConfig *config.Config) error {
@@ -340,3 +347,9 @@ func (p *containerProvisioner) getMachineWatcher() (apiwatcher.StringsWatcher, e
 func (p *containerProvisioner) getRetryWatcher() (apiwatcher.NotifyWatcher, error) {
 	return nil, errors.NotImplementedf("getRetryWatcher")
 }
+
+// getCredentialStatusGetter returns nil, as containers are not started
+// with the environment's cloud credentials.
+func (p *containerProvisioner) getCredentialStatusGetter() CredentialStatusGetter {
+	return nil
+}
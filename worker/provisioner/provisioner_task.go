@@ -22,6 +22,7 @@ import (
 	"github.com/juju/juju/environmentserver/authentication"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instancecache"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/watcher"
@@ -57,8 +58,18 @@ type ToolsFinder interface {
 	FindTools(version version.Number, series string, arch string) (coretools.List, error)
 }
 
+// CredentialStatusGetter is an interface used for checking whether
+// the environment's cloud credentials are known to be valid before
+// starting instances.
+type CredentialStatusGetter interface {
+	// CredentialStatus returns the last known validity of the
+	// environment's cloud credentials.
+	CredentialStatus() (params.CredentialStatus, error)
+}
+
 var _ MachineGetter = (*apiprovisioner.State)(nil)
 var _ ToolsFinder = (*apiprovisioner.State)(nil)
+var _ CredentialStatusGetter = (*apiprovisioner.State)(nil)
 
 func NewProvisionerTask(
 	machineTag names.MachineTag,
@@ -71,6 +82,8 @@ func NewProvisionerTask(
 	auth authentication.AuthenticationProvider,
 	imageStream string,
 	secureServerConnection bool,
+	credentials CredentialStatusGetter,
+	lifecycle environs.InstanceLifecycle,
 ) ProvisionerTask {
 	task := &provisionerTask{
 		machineTag:             machineTag,
@@ -83,8 +96,11 @@ func NewProvisionerTask(
 		harvestMode:            harvestMode,
 		harvestModeChan:        make(chan config.HarvestMode, 1),
 		machines:               make(map[string]*apiprovisioner.Machine),
+		retries:                make(map[string]*startRetry),
 		imageStream:            imageStream,
 		secureServerConnection: secureServerConnection,
+		credentials:            credentials,
+		lifecycle:              lifecycle,
 	}
 	go func() {
 		defer task.tomb.Done()
@@ -106,10 +122,31 @@ type provisionerTask struct {
 	secureServerConnection bool
 	harvestMode            config.HarvestMode
 	harvestModeChan        chan config.HarvestMode
+	// credentials is nil for provisioners that do not start
+	// instances in the cloud.
+	credentials CredentialStatusGetter
+	// lifecycle, if not nil, is called around starting and
+	// stopping instances.
+	lifecycle environs.InstanceLifecycle
 	// instance id -> instance
 	instances map[instance.Id]instance.Instance
 	// machine id -> machine
 	machines map[string]*apiprovisioner.Machine
+	// machine id -> pending retry of starting its instance
+	retries map[string]*startRetry
+}
+
+// startRetry holds a machine whose instance failed to start with a
+// retryable error, to be started again from the task loop once due.
+type startRetry struct {
+	machine             *apiprovisioner.Machine
+	provisioningInfo    *params.ProvisioningInfo
+	startInstanceParams environs.StartInstanceParams
+	// attempt holds the number of the next attempt.
+	attempt int
+	// delay holds how long to wait if the next attempt fails too.
+	delay time.Duration
+	due   time.Time
 }
 
 // Kill implements worker.Worker.Kill.
@@ -155,6 +192,12 @@ func (task *provisionerTask) loop() error {
 	// the machines that are relevant. Also, since this is available straight
 	// away, we know there will be some changes right off the bat.
 	for {
+		// Wake up when the earliest pending retry of starting an
+		// instance is due.
+		var retryTimer <-chan time.Time
+		if due, ok := task.nextRetry(); ok {
+			retryTimer = time.After(due.Sub(time.Now()))
+		}
 		select {
 		case <-task.tomb.Dying():
 			logger.Infof("Shutting down provisioner task %s", task.machineTag)
@@ -188,10 +231,54 @@ func (task *provisionerTask) loop() error {
 			if err := task.processMachinesWithTransientErrors(); err != nil {
 				return errors.Annotate(err, "failed to process machines with transient errors")
 			}
+		case <-retryTimer:
+			if err := task.retryStartMachines(); err != nil {
+				return errors.Annotate(err, "failed to retry starting machines")
+			}
 		}
 	}
 }
 
+// nextRetry returns when the earliest pending retry of starting an
+// instance is due, and whether there is one.
+func (task *provisionerTask) nextRetry() (time.Time, bool) {
+	var next time.Time
+	for _, retry := range task.retries {
+		if next.IsZero() || retry.due.Before(next) {
+			next = retry.due
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// retryStartMachines makes the next attempt to start an instance for
+// each machine whose retry is due. Machines that have been removed or
+// are no longer alive are dropped.
+func (task *provisionerTask) retryStartMachines() error {
+	now := time.Now()
+	for id, retry := range task.retries {
+		if retry.due.After(now) {
+			continue
+		}
+		delete(task.retries, id)
+		machine := retry.machine
+		if err := machine.Refresh(); params.IsCodeNotFound(err) {
+			logger.Infof("not retrying start of removed machine %q", machine)
+			continue
+		} else if err != nil {
+			return errors.Annotatef(err, "cannot refresh machine %q", machine)
+		}
+		if machine.Life() != params.Alive {
+			logger.Infof("not retrying start of %s machine %q", machine.Life(), machine)
+			continue
+		}
+		if err := task.startInstance(retry); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // SetHarvestMode implements ProvisionerTask.SetHarvestMode().
 func (task *provisionerTask) SetHarvestMode(mode config.HarvestMode) {
 	select {
@@ -280,6 +367,7 @@ func (task *provisionerTask) processMachines(ids []string) error {
 
 	// Remove any dead machines from state.
 	for _, machine := range dead {
+		delete(task.retries, machine.Id())
 		logger.Infof("removing dead machine %q", machine)
 		if err := machine.Remove(); err != nil {
 			logger.Errorf("failed to remove dead machine %q", machine)
@@ -483,12 +571,34 @@ func (task *provisionerTask) stopInstances(instances []instance.Instance) error
 	for i, inst := range instances {
 		ids[i] = inst.Id()
 	}
-	if err := task.broker.StopInstances(ids...); err != nil {
+	if task.lifecycle != nil {
+		// The instances are going away regardless, so a failing
+		// hook must not keep them running.
+		if err := task.lifecycle.PreStopInstances(ids...); err != nil {
+			logger.Errorf("pre-terminate hook failed for instances %v: %v", ids, err)
+		}
+	}
+	err := task.broker.StopInstances(ids...)
+	task.invalidateInstanceCache(ids...)
+	if err != nil {
 		return errors.Annotate(err, "broker failed to stop instances")
 	}
 	return nil
 }
 
+// invalidateInstanceCache discards the instances with the given ids
+// from the environment's shared instance cache, so that the instance
+// poller sees instances that have been started or stopped at once.
+func (task *provisionerTask) invalidateInstanceCache(ids ...instance.Id) {
+	env, ok := task.broker.(environs.Environ)
+	if !ok {
+		return
+	}
+	if uuid, ok := env.Config().UUID(); ok {
+		instancecache.Invalidate(uuid, ids...)
+	}
+}
+
 func (task *provisionerTask) constructInstanceConfig(
 	machine *apiprovisioner.Machine,
 	auth authentication.AuthenticationProvider,
@@ -596,7 +706,23 @@ func (task *provisionerTask) maintainMachines(machines []*apiprovisioner.Machine
 }
 
 func (task *provisionerTask) startMachines(machines []*apiprovisioner.Machine) error {
+	if len(machines) == 0 {
+		return nil
+	}
+	if task.credentials != nil {
+		status, err := task.credentials.CredentialStatus()
+		if err != nil {
+			return errors.Annotate(err, "cannot get credential status")
+		}
+		if !status.Valid {
+			return task.suspendMachines(machines, status.Reason)
+		}
+	}
 	for _, m := range machines {
+		if _, ok := task.retries[m.Id()]; ok {
+			// Already waiting to be retried from the task loop.
+			continue
+		}
 
 		pInfo, err := task.blockUntilProvisioned(m.ProvisioningInfo)
 		if err != nil {
@@ -650,6 +776,46 @@ func (task *provisionerTask) setErrorStatus(message string, machine *apiprovisio
 	return nil
 }
 
+// setStartErrorStatus sets the machine's status to error after it
+// failed to start, recording the classified reason and the number of
+// attempts made in the status data.
+func (task *provisionerTask) setStartErrorStatus(
+	machine *apiprovisioner.Machine, err error, reason startErrorReason, attempts int,
+) error {
+	logger.Errorf("cannot start instance for machine %q after %d attempt(s) (%s): %v", machine, attempts, reason, err)
+	data := map[string]interface{}{
+		"reason":   string(reason),
+		"attempts": attempts,
+	}
+	if err1 := machine.SetStatus(params.StatusError, err.Error(), data); err1 != nil {
+		// Something is wrong with this machine, better report it back.
+		return errors.Annotatef(err1, "cannot set error status for machine %q", machine)
+	}
+	return nil
+}
+
+// suspendMachines sets the status of each machine to error, recording
+// that it was not started because the environment's cloud credentials
+// are invalid. The machines are marked as transient so they will be
+// retried, and started once the credentials have been updated.
+func (task *provisionerTask) suspendMachines(machines []*apiprovisioner.Machine, reason string) error {
+	logger.Warningf("cloud credentials not valid, not starting machines %v: %s", machines, reason)
+	message := "cloud credentials not valid"
+	if reason != "" {
+		message += ": " + reason
+	}
+	data := map[string]interface{}{
+		"reason":    string(reasonInvalidCredential),
+		"transient": true,
+	}
+	for _, machine := range machines {
+		if err := machine.SetStatus(params.StatusError, message, data); err != nil {
+			return errors.Annotatef(err, "cannot set error status for machine %q", machine)
+		}
+	}
+	return nil
+}
+
 func (task *provisionerTask) prepareNetworkAndInterfaces(networkInfo []network.InterfaceInfo) (
 	networks []params.Network, ifaces []params.NetworkInterface, err error) {
 	if len(networkInfo) == 0 {
@@ -687,24 +853,63 @@ func (task *provisionerTask) startMachine(
 	startInstanceParams environs.StartInstanceParams,
 ) error {
 
+	if task.lifecycle != nil {
+		if err := task.lifecycle.PreStartInstance(startInstanceParams); err != nil {
+			return task.setErrorStatus("pre-start hook failed for machine %q: %v", machine, err)
+		}
+	}
+
+	return task.startInstance(&startRetry{
+		machine:             machine,
+		provisioningInfo:    provisioningInfo,
+		startInstanceParams: startInstanceParams,
+		attempt:             1,
+		delay:               startInstanceRetryDelay,
+	})
+}
+
+// startInstance makes one attempt to start an instance for the given
+// machine. If the attempt fails with a retryable error and attempts
+// remain, the machine is queued to be retried from the task loop
+// after a delay that doubles each time; otherwise the machine's
+// status is set to error.
+func (task *provisionerTask) startInstance(start *startRetry) error {
+	machine := start.machine
+	startInstanceParams := start.startInstanceParams
 	result, err := task.broker.StartInstance(startInstanceParams)
 	if err != nil {
-		// If this is a retryable error, we retry once
-		if instance.IsRetryableCreationError(errors.Cause(err)) {
-			logger.Infof("retryable error received on start instance - retrying instance creation")
-			result, err = task.broker.StartInstance(startInstanceParams)
-			if err != nil {
-				return task.setErrorStatus("cannot start instance for machine after a retry %q: %v", machine, err)
-			}
-		} else {
+		reason := classifyStartInstanceError(err)
+		if !reason.retryable() || start.attempt > startInstanceRetryCount {
 			// Set the state to error, so the machine will be skipped next
 			// time until the error is resolved, but don't return an
 			// error; just keep going with the other machines.
-			return task.setErrorStatus("cannot start instance for machine %q: %v", machine, err)
+			return task.setStartErrorStatus(machine, err, reason, start.attempt)
 		}
+		logger.Infof(
+			"%s error starting instance for machine %q (attempt %d); retrying in %v: %v",
+			reason, machine, start.attempt, start.delay, err,
+		)
+		retry := *start
+		retry.attempt++
+		retry.delay *= 2
+		retry.due = time.Now().Add(start.delay)
+		task.retries[machine.Id()] = &retry
+		return nil
 	}
 
 	inst := result.Instance
+	task.invalidateInstanceCache(inst.Id())
+	if task.lifecycle != nil {
+		if err := task.lifecycle.PostStartInstance(startInstanceParams, result); err != nil {
+			// Stop the instance right away, as for an instance that
+			// cannot be registered below.
+			statusErr := task.setErrorStatus("post-start hook failed for machine %q: %v", machine, err)
+			if err := task.stopInstances([]instance.Instance{inst}); err != nil {
+				return errors.Annotatef(err, "cannot stop instance %q for machine %v", inst.Id(), machine)
+			}
+			return statusErr
+		}
+	}
 	hardware := result.Hardware
 	nonce := startInstanceParams.InstanceConfig.MachineNonce
 	networks, ifaces, err := task.prepareNetworkAndInterfaces(result.NetworkInfo)
@@ -734,7 +939,9 @@ func (task *provisionerTask) startMachine(
 	}
 	// We need to stop the instance right away here, set error status and go on.
 	task.setErrorStatus("cannot register instance for machine %v: %v", machine, err)
-	if err := task.broker.StopInstances(inst.Id()); err != nil {
+	err = task.broker.StopInstances(inst.Id())
+	task.invalidateInstanceCache(inst.Id())
+	if err != nil {
 		// We cannot even stop the instance, log the error and quit.
 		return errors.Annotatef(err, "cannot stop instance %q for machine %v", inst.Id(), machine)
 	}
@@ -119,6 +119,7 @@ func (s *CommonProvisionerSuite) SetUpTest(c *gc.C) {
 	dummy.SetStatePolicy(nil)
 
 	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(provisioner.StartInstanceRetryDelay, time.Duration(0))
 
 	// Create the operations channel with more than enough space
 	// for those tests that don't listen on it.
@@ -632,7 +633,8 @@ func (s *ProvisionerSuite) TestProvisionerFailedStartInstanceWithInjectedCreatio
 
 	retryableError := instance.NewRetryableCreationError("container failed to start and was destroyed")
 	destroyError := errors.New("container failed to start and failed to destroy: manual cleanup of containers needed")
-	// send the error message TWICE, because the provisioner will retry only ONCE
+	// send the retryable error first, so the provisioner retries and
+	// then gives up on the non-retryable one
 	errorInjectionChannel <- retryableError
 	errorInjectionChannel <- destroyError
 
@@ -657,6 +659,127 @@ func (s *ProvisionerSuite) TestProvisionerFailedStartInstanceWithInjectedCreatio
 
 }
 
+func (s *ProvisionerSuite) TestProvisionerGivesUpRetryingQuotaExceededError(c *gc.C) {
+	s.PatchValue(provisioner.StartInstanceRetryCount, 2)
+	errorInjectionChannel := make(chan error, 3)
+
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	cleanup := dummy.PatchTransientErrorInjectionChannel(errorInjectionChannel)
+	defer cleanup()
+
+	// One attempt plus two retries all fail.
+	quotaError := instance.NewQuotaExceededError("instance limit reached")
+	for i := 0; i < 3; i++ {
+		errorInjectionChannel <- quotaError
+	}
+
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkNoOperations(c)
+
+	t0 := time.Now()
+	for time.Since(t0) < coretesting.LongWait {
+		statusInfo, err := m.Status()
+		c.Assert(err, jc.ErrorIsNil)
+		if statusInfo.Status == state.StatusPending {
+			time.Sleep(coretesting.ShortWait)
+			continue
+		}
+		c.Assert(statusInfo.Status, gc.Equals, state.StatusError)
+		c.Assert(statusInfo.Message, gc.Equals, quotaError.Error())
+		// Status data is passed through the API as JSON, so
+		// numbers are read back as float64.
+		c.Assert(statusInfo.Data, jc.DeepEquals, map[string]interface{}{
+			"reason":   "quota-exceeded",
+			"attempts": float64(3),
+		})
+		break
+	}
+	c.Assert(errorInjectionChannel, gc.HasLen, 0)
+}
+
+func (s *ProvisionerSuite) TestProvisionerSuspendedWhenCredentialsInvalid(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetCredentialValid(false, "access key expired")
+	c.Assert(err, jc.ErrorIsNil)
+
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	// Check that an instance is not provisioned when the machine is created...
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkNoOperations(c)
+
+	t0 := time.Now()
+	for time.Since(t0) < coretesting.LongWait {
+		// ...and that the machine is marked for retry.
+		statusInfo, err := m.Status()
+		c.Assert(err, jc.ErrorIsNil)
+		if statusInfo.Status == state.StatusPending {
+			time.Sleep(coretesting.ShortWait)
+			continue
+		}
+		c.Assert(statusInfo.Status, gc.Equals, state.StatusError)
+		c.Assert(statusInfo.Message, gc.Equals, "cloud credentials not valid: access key expired")
+		c.Assert(statusInfo.Data, jc.DeepEquals, map[string]interface{}{
+			"reason":    "invalid-credential",
+			"transient": true,
+		})
+		return
+	}
+	c.Fatalf("timed out waiting for machine status to be set")
+}
+
+func (s *ProvisionerSuite) TestProvisionerSucceedStartInstanceAfterQuotaExceededError(c *gc.C) {
+	errorInjectionChannel := make(chan error, 2)
+
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	cleanup := dummy.PatchTransientErrorInjectionChannel(errorInjectionChannel)
+	defer cleanup()
+
+	quotaError := instance.NewQuotaExceededError("instance limit reached")
+	errorInjectionChannel <- quotaError
+	errorInjectionChannel <- quotaError
+
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkStartInstanceNoSecureConnection(c, m)
+}
+
+func (s *ProvisionerSuite) TestProvisionerStopsWhileRetryPending(c *gc.C) {
+	s.PatchValue(provisioner.StartInstanceRetryDelay, time.Hour)
+	errorInjectionChannel := make(chan error, 1)
+
+	p := s.newEnvironProvisioner(c)
+	cleanup := dummy.PatchTransientErrorInjectionChannel(errorInjectionChannel)
+	defer cleanup()
+
+	errorInjectionChannel <- instance.NewQuotaExceededError("instance limit reached")
+	_, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	for a := coretesting.LongAttempt.Start(); len(errorInjectionChannel) > 0; {
+		c.Assert(a.Next(), jc.IsTrue, gc.Commentf("instance not started"))
+	}
+
+	// The retry is an hour away, but does not hold up stopping.
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- p.Stop()
+	}()
+	select {
+	case err := <-stopped:
+		c.Assert(err, jc.ErrorIsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for provisioner to stop")
+	}
+}
+
 func (s *ProvisionerSuite) TestProvisionerSucceedStartInstanceWithInjectedRetryableCreationError(c *gc.C) {
 	// create the error injection channel
 	errorInjectionChannel := make(chan error, 1)
@@ -946,6 +1069,31 @@ func (s *MachineClassifySuite) TestMachineClassification(c *gc.C) {
 	}
 }
 
+func (s *MachineClassifySuite) TestClassifyStartInstanceError(c *gc.C) {
+	for i, t := range []struct {
+		err    error
+		reason string
+	}{{
+		err:    instance.NewRetryableCreationError("container failed to start"),
+		reason: "transient",
+	}, {
+		err:    errors.Annotate(instance.NewRetryableCreationError("container failed to start"), "cannot start"),
+		reason: "transient",
+	}, {
+		err:    instance.NewQuotaExceededError("instance limit reached"),
+		reason: "quota-exceeded",
+	}, {
+		err:    errors.NotFoundf("image"),
+		reason: "no-matching-instance",
+	}, {
+		err:    errors.New("boom"),
+		reason: "fatal",
+	}} {
+		c.Logf("test %d: %v", i, t.err)
+		c.Check(provisioner.ClassifyStartInstanceError(t.err), gc.Equals, t.reason)
+	}
+}
+
 func (s *ProvisionerSuite) TestProvisioningMachinesWithSpacesSuccess(c *gc.C) {
 	p := s.newEnvironProvisioner(c)
 	defer p.Stop()
@@ -1315,6 +1463,8 @@ func (s *ProvisionerSuite) newProvisionerTask(
 		auth,
 		imagemetadata.ReleasedStream,
 		true,
+		s.provisioner,
+		nil,
 	)
 }
 
@@ -0,0 +1,71 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/instance"
+)
+
+// startInstanceRetryCount is the number of times the provisioner will
+// retry starting an instance after a transient or quota error, before
+// giving up and setting the machine's status to error.
+var startInstanceRetryCount = 3
+
+// startInstanceRetryDelay is the delay before the first retry of
+// starting an instance; it doubles for each subsequent retry.
+var startInstanceRetryDelay = 10 * time.Second
+
+// startErrorReason classifies an error returned when starting an
+// instance. It is recorded as the "reason" in the machine's status
+// data when provisioning fails.
+type startErrorReason string
+
+const (
+	// reasonTransient describes a failure that is expected to clear
+	// on its own, such as a container that failed to start cleanly.
+	reasonTransient startErrorReason = "transient"
+
+	// reasonQuotaExceeded describes a failure caused by the cloud
+	// account reaching a resource limit, which may clear as other
+	// instances are stopped.
+	reasonQuotaExceeded startErrorReason = "quota-exceeded"
+
+	// reasonNoMatchingInstance describes a failure to find an image
+	// or instance type satisfying the machine's series and
+	// constraints; it will not clear without user intervention.
+	reasonNoMatchingInstance startErrorReason = "no-matching-instance"
+
+	// reasonInvalidCredential describes a machine whose provisioning
+	// was suspended because the environment's cloud credentials have
+	// been found to be invalid; it clears once they are updated.
+	reasonInvalidCredential startErrorReason = "invalid-credential"
+
+	// reasonFatal describes any other failure.
+	reasonFatal startErrorReason = "fatal"
+)
+
+// retryable returns whether starting the instance should be retried
+// after an error with this reason.
+func (reason startErrorReason) retryable() bool {
+	return reason == reasonTransient || reason == reasonQuotaExceeded
+}
+
+// classifyStartInstanceError returns the reason for the given error
+// from StartInstance.
+func classifyStartInstanceError(err error) startErrorReason {
+	cause := errors.Cause(err)
+	switch {
+	case instance.IsRetryableCreationError(cause):
+		return reasonTransient
+	case instance.IsQuotaExceededError(cause):
+		return reasonQuotaExceeded
+	case errors.IsNotFound(cause):
+		return reasonNoMatchingInstance
+	}
+	return reasonFatal
+}
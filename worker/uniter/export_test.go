@@ -5,3 +5,6 @@ package uniter
 
 // NewUniterResolver returns a new aggregate uniter resolver.
 var NewUniterResolver = newUniterResolver
+
+// NewHookRetryTimer returns a new timer for retrying failed hooks.
+var NewHookRetryTimer = newHookRetryTimer
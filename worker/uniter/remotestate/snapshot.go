@@ -52,6 +52,10 @@ type Snapshot struct {
 	// update-status hook is supposed to run.
 	UpdateStatusVersion int
 
+	// RetryHookVersion increments each time a failed
+	// hook is supposed to be retried.
+	RetryHookVersion int
+
 	// Actions is the list of pending actions to
 	// be peformed by this unit.
 	Actions []string
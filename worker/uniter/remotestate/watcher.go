@@ -34,6 +34,7 @@ type RemoteStateWatcher struct {
 	storageAttachmentChanges  chan storageAttachmentChange
 	leadershipTracker         leadership.Tracker
 	updateStatusChannel       func() <-chan time.Time
+	retryHookChannel          <-chan struct{}
 
 	tomb tomb.Tomb
 
@@ -49,6 +50,10 @@ type WatcherConfig struct {
 	LeadershipTracker   leadership.Tracker
 	UpdateStatusChannel func() <-chan time.Time
 	UnitTag             names.UnitTag
+
+	// RetryHookChannel, if not nil, signals that a failed
+	// hook should be retried.
+	RetryHookChannel <-chan struct{}
 }
 
 // NewWatcher returns a RemoteStateWatcher that handles state changes pertaining to the
@@ -62,6 +67,7 @@ func NewWatcher(config WatcherConfig) (*RemoteStateWatcher, error) {
 		storageAttachmentChanges:  make(chan storageAttachmentChange),
 		leadershipTracker:         config.LeadershipTracker,
 		updateStatusChannel:       config.UpdateStatusChannel,
+		retryHookChannel:          config.RetryHookChannel,
 		// Note: it is important that the out channel be buffered!
 		// The remote state watcher will perform a non-blocking send
 		// on the channel to wake up the observer. It is non-blocking
@@ -390,6 +396,12 @@ func (w *RemoteStateWatcher) loop(unitTag names.UnitTag) (err error) {
 			if err := w.updateStatusChanged(); err != nil {
 				return err
 			}
+
+		case <-w.retryHookChannel:
+			logger.Debugf("retry hook timer triggered")
+			if err := w.retryHookTimerTriggered(); err != nil {
+				return err
+			}
 		}
 
 		// Something changed.
@@ -405,6 +417,14 @@ func (w *RemoteStateWatcher) updateStatusChanged() error {
 	return nil
 }
 
+// retryHookTimerTriggered is called when the retry hook timer expires.
+func (w *RemoteStateWatcher) retryHookTimerTriggered() error {
+	w.mu.Lock()
+	w.current.RetryHookVersion++
+	w.mu.Unlock()
+	return nil
+}
+
 // unitChanged responds to changes in the unit.
 func (w *RemoteStateWatcher) unitChanged() error {
 	if err := w.unit.Refresh(); err != nil {
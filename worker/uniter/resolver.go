@@ -15,9 +15,15 @@ import (
 )
 
 type uniterResolver struct {
-	clearResolved   func() error
-	reportHookError func(hook.Info) error
-	fixDeployer     func() error
+	clearResolved       func() error
+	reportHookError     func(hook.Info) error
+	fixDeployer         func() error
+	startRetryHookTimer func()
+	stopRetryHookTimer  func()
+
+	// retryHookTimerStarted records whether a retry of the
+	// current hook error has been scheduled.
+	retryHookTimerStarted bool
 
 	leadershipResolver resolver.Resolver
 	actionsResolver    resolver.Resolver
@@ -29,19 +35,23 @@ func newUniterResolver(
 	clearResolved func() error,
 	reportHookError func(hook.Info) error,
 	fixDeployer func() error,
+	startRetryHookTimer func(),
+	stopRetryHookTimer func(),
 	leadershipResolver resolver.Resolver,
 	actionsResolver resolver.Resolver,
 	relationsResolver resolver.Resolver,
 	storageResolver resolver.Resolver,
 ) *uniterResolver {
 	return &uniterResolver{
-		clearResolved:      clearResolved,
-		reportHookError:    reportHookError,
-		fixDeployer:        fixDeployer,
-		leadershipResolver: leadershipResolver,
-		actionsResolver:    actionsResolver,
-		relationsResolver:  relationsResolver,
-		storageResolver:    storageResolver,
+		clearResolved:       clearResolved,
+		reportHookError:     reportHookError,
+		fixDeployer:         fixDeployer,
+		startRetryHookTimer: startRetryHookTimer,
+		stopRetryHookTimer:  stopRetryHookTimer,
+		leadershipResolver:  leadershipResolver,
+		actionsResolver:     actionsResolver,
+		relationsResolver:   relationsResolver,
+		storageResolver:     storageResolver,
 	}
 }
 
@@ -70,6 +80,13 @@ func (s *uniterResolver) NextOp(
 		return nil, resolver.ErrRestart
 	}
 
+	if localState.Kind != operation.RunHook || localState.Step != operation.Pending {
+		// There is no hook error, so the next one to occur is
+		// retried from the start of the backoff curve.
+		s.stopRetryHookTimer()
+		s.retryHookTimerStarted = false
+	}
+
 	if localState.Kind == operation.Continue {
 		if err := s.fixDeployer(); err != nil {
 			return nil, errors.Trace(err)
@@ -163,16 +180,31 @@ func (s *uniterResolver) nextOpHookError(
 
 	switch remoteState.ResolvedMode {
 	case params.ResolvedNone:
+		if remoteState.RetryHookVersion > localState.RetryHookVersion {
+			// The retry timer has fired, so run the hook again. If
+			// it fails once more, the timer is started afresh with
+			// a longer delay.
+			s.retryHookTimerStarted = false
+			return opFactory.NewRunHook(*localState.Hook)
+		}
+		if !s.retryHookTimerStarted {
+			s.startRetryHookTimer()
+			s.retryHookTimerStarted = true
+		}
 		return nil, resolver.ErrNoOperation
 	case params.ResolvedRetryHooks:
 		if err := s.clearResolved(); err != nil {
 			return nil, errors.Trace(err)
 		}
+		s.stopRetryHookTimer()
+		s.retryHookTimerStarted = false
 		return opFactory.NewRunHook(*localState.Hook)
 	case params.ResolvedNoHooks:
 		if err := s.clearResolved(); err != nil {
 			return nil, errors.Trace(err)
 		}
+		s.stopRetryHookTimer()
+		s.retryHookTimerStarted = false
 		return opFactory.NewSkipHook(*localState.Hook)
 	default:
 		return nil, errors.Errorf(
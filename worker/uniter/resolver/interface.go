@@ -79,6 +79,10 @@ type LocalState struct {
 	// been committed.
 	LeaderSettingsVersion int
 
+	// RetryHookVersion is the version of hook retries from
+	// remotestate.Snapshot for which a hook has last been run.
+	RetryHookVersion int
+
 	// CompletedActions is the set of actions that have been completed.
 	// This is used to prevent us re running actions requested by the
 	// state server.
@@ -107,6 +107,13 @@ func (s *resolverOpFactory) wrapUpgradeOp(op operation.Operation, charmURL *char
 }
 
 func (s *resolverOpFactory) wrapHookOp(op operation.Operation, info hook.Info) operation.Operation {
+	// The retry hook version is recorded when the hook is prepared,
+	// rather than committed, so that a hook that fails again is not
+	// retried until the next retry is signalled.
+	retryHookVersion := s.RemoteState.RetryHookVersion
+	op = onPrepareWrapper{op, func() {
+		s.LocalState.RetryHookVersion = retryHookVersion
+	}}
 	switch info.Kind {
 	case hooks.ConfigChanged:
 		v := s.RemoteState.ConfigVersion
@@ -141,6 +148,20 @@ func (op onCommitWrapper) Commit(state operation.State) (*operation.State, error
 	return st, nil
 }
 
+type onPrepareWrapper struct {
+	operation.Operation
+	f func()
+}
+
+func (op onPrepareWrapper) Prepare(state operation.State) (*operation.State, error) {
+	st, err := op.Operation.Prepare(state)
+	if err != nil {
+		return nil, err
+	}
+	op.f()
+	return st, nil
+}
+
 func onCommit(op operation.Operation) {
 	if wrapper, ok := op.(onCommitWrapper); ok {
 		wrapper.f()
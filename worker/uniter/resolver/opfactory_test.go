@@ -126,6 +126,43 @@ func (s *ResolverOpFactorySuite) testLeaderSettingsChanged(
 	c.Assert(f.LocalState.UpdateStatusVersion, gc.Equals, 3)
 }
 
+func (s *ResolverOpFactorySuite) TestRetryHookPrepared(c *gc.C) {
+	s.testRetryHookPrepared(c, resolver.ResolverOpFactory.NewRunHook)
+	s.testRetryHookPrepared(c, resolver.ResolverOpFactory.NewSkipHook)
+}
+
+func (s *ResolverOpFactorySuite) testRetryHookPrepared(
+	c *gc.C, meth func(resolver.ResolverOpFactory, hook.Info) (operation.Operation, error),
+) {
+	f := resolver.NewResolverOpFactory(s.opFactory)
+	f.RemoteState.RetryHookVersion = 1
+
+	op, err := meth(f, hook.Info{Kind: hooks.ConfigChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	f.RemoteState.RetryHookVersion = 2
+
+	// Local state's RetryHookVersion should be set to what
+	// RemoteState's RetryHookVersion was when the operation
+	// was constructed as soon as the operation is prepared, since
+	// a hook that fails is never committed.
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(f.LocalState.RetryHookVersion, gc.Equals, 1)
+}
+
+func (s *ResolverOpFactorySuite) TestPrepareError(c *gc.C) {
+	f := resolver.NewResolverOpFactory(s.opFactory)
+	f.RemoteState.RetryHookVersion = 1
+	s.opFactory.op.prepare = func(operation.State) (*operation.State, error) {
+		return nil, errors.New("Prepare fails")
+	}
+	op, err := f.NewRunHook(hook.Info{Kind: hooks.ConfigChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, gc.ErrorMatches, "Prepare fails")
+	c.Assert(f.LocalState.RetryHookVersion, gc.Equals, 0)
+}
+
 func (s *ResolverOpFactorySuite) TestUpgrade(c *gc.C) {
 	s.testUpgrade(c, resolver.ResolverOpFactory.NewUpgrade)
 	s.testUpgrade(c, resolver.ResolverOpFactory.NewRevertUpgrade)
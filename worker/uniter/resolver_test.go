@@ -9,6 +9,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/juju/charm.v6-unstable/hooks"
 
 	"github.com/juju/juju/worker/uniter"
 	uniteractions "github.com/juju/juju/worker/uniter/actions"
@@ -26,6 +27,9 @@ type resolverSuite struct {
 	remoteState remotestate.Snapshot
 	opFactory   operation.Factory
 	resolver    resolver.Resolver
+
+	retryTimerStarts int
+	retryTimerStops  int
 }
 
 var _ = gc.Suite(&resolverSuite{})
@@ -36,14 +40,23 @@ func (s *resolverSuite) SetUpTest(c *gc.C) {
 		CharmURL: s.charmURL,
 	}
 	s.opFactory = operation.NewFactory(operation.FactoryParams{})
+	s.retryTimerStarts = 0
+	s.retryTimerStops = 0
+	s.resolver = s.newResolver(c, func(_ hook.Info) error {
+		return errors.New("unexpected report hook error")
+	})
+}
 
+func (s *resolverSuite) newResolver(c *gc.C, reportHookError func(hook.Info) error) resolver.Resolver {
 	attachments, err := storage.NewAttachments(&dummyStorageAccessor{}, names.NewUnitTag("u/0"), c.MkDir(), nil)
 	c.Assert(err, jc.ErrorIsNil)
 
-	s.resolver = uniter.NewUniterResolver(
+	return uniter.NewUniterResolver(
 		func() error { return errors.New("unexpected resolved") },
-		func(_ hook.Info) error { return errors.New("unexpected report hook error") },
+		reportHookError,
 		func() error { return nil },
+		func() { s.retryTimerStarts++ },
+		func() { s.retryTimerStops++ },
 		uniteractions.NewResolver(),
 		leadership.NewResolver(),
 		relation.NewRelationsResolver(&dummyRelations{}),
@@ -82,3 +95,73 @@ func (s *resolverSuite) TestNotStartedNotInstalled(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(op.String(), gc.Equals, "run install hook")
 }
+
+// hookErrorResolver returns a resolver for the hook error tests, which
+// expect the error to be reported.
+func (s *resolverSuite) hookErrorResolver(c *gc.C) resolver.Resolver {
+	return s.newResolver(c, func(_ hook.Info) error { return nil })
+}
+
+func (s *resolverSuite) hookErrorState() resolver.LocalState {
+	return resolver.LocalState{
+		CharmURL: s.charmURL,
+		State: operation.State{
+			Kind:      operation.RunHook,
+			Step:      operation.Pending,
+			Installed: true,
+			Started:   true,
+			Hook:      &hook.Info{Kind: hooks.ConfigChanged},
+		},
+	}
+}
+
+func (s *resolverSuite) TestHookErrorStartsRetryTimer(c *gc.C) {
+	r := s.hookErrorResolver(c)
+	localState := s.hookErrorState()
+	_, err := r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+	c.Assert(s.retryTimerStarts, gc.Equals, 1)
+
+	// The timer is only started once for each failure.
+	_, err = r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+	c.Assert(s.retryTimerStarts, gc.Equals, 1)
+}
+
+func (s *resolverSuite) TestHookErrorRetried(c *gc.C) {
+	r := s.hookErrorResolver(c)
+	localState := s.hookErrorState()
+	_, err := r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+
+	s.remoteState.RetryHookVersion = 1
+	op, err := r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(op.String(), gc.Equals, "run config-changed hook")
+
+	// If the hook fails again, the timer is started again.
+	localState.RetryHookVersion = 1
+	_, err = r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+	c.Assert(s.retryTimerStarts, gc.Equals, 2)
+	c.Assert(s.retryTimerStops, gc.Equals, 0)
+}
+
+func (s *resolverSuite) TestRetryTimerStoppedWithoutHookError(c *gc.C) {
+	r := s.hookErrorResolver(c)
+	localState := s.hookErrorState()
+	_, err := r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+
+	localState.Kind = operation.Continue
+	localState.Hook = nil
+	_, err = r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+	c.Assert(s.retryTimerStops, gc.Equals, 1)
+
+	// A later failure starts the timer afresh.
+	localState = s.hookErrorState()
+	_, err = r.NextOp(localState, s.remoteState, s.opFactory)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+	c.Assert(s.retryTimerStarts, gc.Equals, 2)
+}
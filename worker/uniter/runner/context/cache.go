@@ -5,6 +5,7 @@ package context
 
 import (
 	"sort"
+	"sync"
 
 	"github.com/juju/juju/apiserver/params"
 )
@@ -18,7 +19,12 @@ type SettingsMap map[string]params.Settings
 // RelationCache stores a relation's remote unit membership and settings.
 // Member settings are stored until invalidated or removed by name; settings
 // of non-member units are stored only until the cache is pruned.
+//
+// A RelationCache is safe for concurrent use. Concurrent requests for the
+// settings of a unit not yet cached are served by a single read.
 type RelationCache struct {
+	// mu guards the fields below.
+	mu sync.Mutex
 	// readSettings is used to get settings data if when not already present.
 	readSettings SettingsFunc
 	// members' keys define the relation's membership; non-nil values hold
@@ -42,6 +48,8 @@ func NewRelationCache(readSettings SettingsFunc, memberNames []string) *Relation
 // Prune resets the membership to the supplied list, and discards the settings
 // of all non-member units.
 func (cache *RelationCache) Prune(memberNames []string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
 	newMembers := SettingsMap{}
 	for _, memberName := range memberNames {
 		newMembers[memberName] = cache.members[memberName]
@@ -52,6 +60,8 @@ func (cache *RelationCache) Prune(memberNames []string) {
 
 // MemberNames returns the names of the remote units present in the relation.
 func (cache *RelationCache) MemberNames() (memberNames []string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
 	for memberName := range cache.members {
 		memberNames = append(memberNames, memberName)
 	}
@@ -62,6 +72,8 @@ func (cache *RelationCache) MemberNames() (memberNames []string) {
 // Settings returns the settings of the named remote unit. It's valid to get
 // the settings of any unit that has ever been in the relation.
 func (cache *RelationCache) Settings(unitName string) (params.Settings, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
 	settings, isMember := cache.members[unitName]
 	if settings == nil {
 		if !isMember {
@@ -87,11 +99,15 @@ func (cache *RelationCache) Settings(unitName string) (params.Settings, error) {
 // member of the relation, and that the next attempt to read its settings will
 // use fresh data.
 func (cache *RelationCache) InvalidateMember(memberName string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
 	cache.members[memberName] = nil
 }
 
 // RemoveMember ensures that the named remote unit will not be considered a
 // member of the relation,
 func (cache *RelationCache) RemoveMember(memberName string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
 	delete(cache.members, memberName)
 }
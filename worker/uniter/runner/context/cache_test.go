@@ -4,6 +4,8 @@
 package context_test
 
 import (
+	"sync"
+
 	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -102,6 +104,26 @@ func (s *RelationCacheSuite) TestSettingsCachesMemberSettings(c *gc.C) {
 	}
 }
 
+func (s *RelationCacheSuite) TestConcurrentSettingsReadOnce(c *gc.C) {
+	s.results = []settingsResult{{
+		params.Settings{"foo": "bar"}, nil,
+	}}
+	cache := context.NewRelationCache(s.ReadSettings, []string{"x/2"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			settings, err := cache.Settings("x/2")
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(settings, jc.DeepEquals, params.Settings{"foo": "bar"})
+		}()
+	}
+	wg.Wait()
+	c.Assert(s.calls, jc.DeepEquals, []string{"x/2"})
+}
+
 func (s *RelationCacheSuite) TestInvalidateMemberUncachesMemberSettings(c *gc.C) {
 	s.results = []settingsResult{{
 		params.Settings{"foo": "bar"}, nil,
@@ -164,6 +164,12 @@ type HookContext struct {
 	// This collection will be added to the unit on successful
 	// hook run, so the actual add will happen in a flush.
 	storageAddConstraints map[string][]params.StorageConstraints
+
+	// charmState holds the key/value state stored by the unit's charm,
+	// read on first use. Changes made during the hook are recorded in
+	// dirtyCharmState, and written on successful hook run.
+	charmState      map[string]string
+	dirtyCharmState map[string]bool
 }
 
 func (ctx *HookContext) RequestReboot(priority jujuc.RebootPriority) error {
@@ -283,6 +289,79 @@ func (ctx *HookContext) SetUnitStatus(status jujuc.StatusInfo) error {
 	)
 }
 
+// SetUnitWorkloadVersion records the version of the workload software
+// that the unit is running.
+func (ctx *HookContext) SetUnitWorkloadVersion(version string) error {
+	return ctx.unit.SetWorkloadVersion(version)
+}
+
+// ensureCharmState reads the state stored by the unit's charm, if it
+// has not been read already.
+func (ctx *HookContext) ensureCharmState() error {
+	if ctx.charmState != nil {
+		return nil
+	}
+	charmState, err := ctx.unit.CharmState()
+	if err != nil {
+		return errors.Annotate(err, "cannot read charm state")
+	}
+	if charmState == nil {
+		charmState = make(map[string]string)
+	}
+	ctx.charmState = charmState
+	ctx.dirtyCharmState = make(map[string]bool)
+	return nil
+}
+
+// GetCharmState returns the key/value state stored by the unit's charm,
+// including changes made during the current hook.
+func (ctx *HookContext) GetCharmState() (map[string]string, error) {
+	if err := ctx.ensureCharmState(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make(map[string]string, len(ctx.charmState))
+	for key, value := range ctx.charmState {
+		result[key] = value
+	}
+	return result, nil
+}
+
+// GetCharmStateValue returns the value of the given key in the state
+// stored by the unit's charm.
+func (ctx *HookContext) GetCharmStateValue(key string) (string, error) {
+	if err := ctx.ensureCharmState(); err != nil {
+		return "", errors.Trace(err)
+	}
+	value, ok := ctx.charmState[key]
+	if !ok {
+		return "", errors.NotFoundf("state key %q", key)
+	}
+	return value, nil
+}
+
+// SetCharmStateValue sets the value of the given key in the state
+// stored by the unit's charm. The change is written when the context
+// is flushed.
+func (ctx *HookContext) SetCharmStateValue(key, value string) error {
+	if err := ctx.ensureCharmState(); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.charmState[key] = value
+	ctx.dirtyCharmState[key] = true
+	return nil
+}
+
+// DeleteCharmStateValue removes the given key from the state stored by
+// the unit's charm. The change is written when the context is flushed.
+func (ctx *HookContext) DeleteCharmStateValue(key string) error {
+	if err := ctx.ensureCharmState(); err != nil {
+		return errors.Trace(err)
+	}
+	delete(ctx.charmState, key)
+	ctx.dirtyCharmState[key] = true
+	return nil
+}
+
 // SetServiceStatus will set the given status to the service to which this
 // unit's belong, only if this unit is the leader.
 func (ctx *HookContext) SetServiceStatus(status jujuc.StatusInfo) error {
@@ -391,6 +470,12 @@ func (ctx *HookContext) OpenedPorts() []network.PortRange {
 	return unitRanges
 }
 
+// NetworkConfig returns the network configuration of the unit for the
+// given binding name.
+func (ctx *HookContext) NetworkConfig(bindingName string) ([]params.NetworkConfig, error) {
+	return ctx.unit.NetworkConfig(bindingName)
+}
+
 func (ctx *HookContext) ConfigSettings() (charm.Settings, error) {
 	if ctx.configSettings == nil {
 		var err error
@@ -431,6 +516,22 @@ func (ctx *HookContext) SetActionMessage(message string) error {
 	return nil
 }
 
+// ActionAborted reports whether the action being run has been
+// cancelled, and should be stopped.
+func (ctx *HookContext) ActionAborted() (bool, error) {
+	if ctx.actionData == nil {
+		return false, errors.New("not running an action")
+	}
+	status, err := ctx.state.ActionStatus(ctx.actionData.Tag)
+	if errors.IsNotImplemented(err) {
+		// Older state servers cannot cancel running actions.
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return status == params.ActionAborting, nil
+}
+
 // SetActionFailed sets the fail state of the action.
 func (ctx *HookContext) SetActionFailed() error {
 	if ctx.actionData == nil {
@@ -644,6 +745,25 @@ func (ctx *HookContext) Flush(process string, ctxErr error) (err error) {
 		}
 	}
 
+	if len(ctx.dirtyCharmState) > 0 && writeChanges {
+		set := make(map[string]string)
+		var unset []string
+		for key := range ctx.dirtyCharmState {
+			if value, ok := ctx.charmState[key]; ok {
+				set[key] = value
+			} else {
+				unset = append(unset, key)
+			}
+		}
+		if err := ctx.unit.SetCharmState(set, unset); err != nil {
+			err = errors.Annotatef(err, "cannot write charm state")
+			logger.Errorf("%v", err)
+			if ctxErr == nil {
+				ctxErr = err
+			}
+		}
+	}
+
 	// TODO (tasdomas) 2014 09 03: context finalization needs to modified to apply all
 	//                             changes in one api call to minimize the risk
 	//                             of partial failures.
@@ -116,6 +116,15 @@ func (s *InterfaceSuite) TestSetUnitStatus(c *gc.C) {
 	c.Check(unitStatus.Data, gc.DeepEquals, map[string]interface{}{})
 }
 
+func (s *InterfaceSuite) TestSetUnitWorkloadVersion(c *gc.C) {
+	ctx := s.GetContext(c, -1, "")
+	err := ctx.SetUnitWorkloadVersion("4.2.1")
+	c.Check(err, jc.ErrorIsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.unit.WorkloadVersion(), gc.Equals, "4.2.1")
+}
+
 func (s *InterfaceSuite) TestSetUnitStatusUpdatesFlag(c *gc.C) {
 	ctx := s.GetContext(c, -1, "")
 	c.Assert(ctx.(runner.Context).HasExecutionSetUnitStatus(), jc.IsFalse)
@@ -207,6 +207,40 @@ func (s *FlushContextSuite) TestRunHookAddUnitStorageOnSuccess(c *gc.C) {
 	c.Assert(all, gc.HasLen, 0)
 }
 
+func (s *FlushContextSuite) TestRunHookCharmStateFlushingError(c *gc.C) {
+	ctx := s.context(c)
+	err := ctx.SetCharmStateValue("foo", "bar")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Flush the context with a failure.
+	err = ctx.Flush("some badge", errors.New("blam pow"))
+	c.Assert(err, gc.ErrorMatches, "blam pow")
+
+	// Check that the changes have not been written to state.
+	charmState, err := s.unit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, gc.HasLen, 0)
+}
+
+func (s *FlushContextSuite) TestRunHookCharmStateFlushingSuccess(c *gc.C) {
+	err := s.unit.SetCharmState(map[string]string{"foo": "bar", "baz": "qux"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := s.context(c)
+	err = ctx.SetCharmStateValue("foo", "new")
+	c.Assert(err, jc.ErrorIsNil)
+	err = ctx.DeleteCharmStateValue("baz")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Flush the context with a success.
+	err = ctx.Flush("some badge", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Check that the changes have been written to state.
+	charmState, err := s.unit.CharmState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmState, jc.DeepEquals, map[string]string{"foo": "new"})
+}
+
 func (s *HookContextSuite) context(c *gc.C) *context.HookContext {
 	uuid, err := utils.NewUUID()
 	c.Assert(err, jc.ErrorIsNil)
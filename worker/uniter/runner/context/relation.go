@@ -5,6 +5,7 @@ package context
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/juju/juju/api/uniter"
 	"github.com/juju/juju/apiserver/params"
@@ -23,7 +24,9 @@ type ContextRelation struct {
 	endpointName string
 
 	// settings allows read and write access to the relation unit settings.
-	settings *uniter.Settings
+	// It is read on first use, guarded by settingsMu.
+	settingsMu sync.Mutex
+	settings   *uniter.Settings
 
 	// cache holds remote unit membership and settings.
 	cache *RelationCache
@@ -61,6 +64,8 @@ func (ctx *ContextRelation) ReadSettings(unit string) (settings params.Settings,
 }
 
 func (ctx *ContextRelation) Settings() (jujuc.Settings, error) {
+	ctx.settingsMu.Lock()
+	defer ctx.settingsMu.Unlock()
 	if ctx.settings == nil {
 		node, err := ctx.ru.Settings()
 		if err != nil {
@@ -73,6 +78,8 @@ func (ctx *ContextRelation) Settings() (jujuc.Settings, error) {
 
 // WriteSettings persists all changes made to the unit's relation settings.
 func (ctx *ContextRelation) WriteSettings() (err error) {
+	ctx.settingsMu.Lock()
+	defer ctx.settingsMu.Unlock()
 	if ctx.settings != nil {
 		err = ctx.settings.Write()
 	}
@@ -12,6 +12,8 @@ var (
 	SearchHook              = searchHook
 	HookCommand             = hookCommand
 	LookPath                = lookPath
+
+	ActionAbortPollInterval = &actionAbortPollInterval
 )
 
 func RunnerPaths(rnr Runner) context.Paths {
@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type applicationVersionSetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&applicationVersionSetSuite{})
+
+var applicationVersionSetInitTests = []struct {
+	args []string
+	err  string
+}{
+	{[]string{"4.2.1"}, ""},
+	{[]string{}, "no version specified"},
+	{[]string{"4.2.1", "extra"}, `unrecognized args: \["extra"\]`},
+}
+
+func (s *applicationVersionSetSuite) TestInit(c *gc.C) {
+	for i, t := range applicationVersionSetInitTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, cmdString("application-version-set"))
+		c.Assert(err, jc.ErrorIsNil)
+		testing.TestInit(c, com, t.args, t.err)
+	}
+}
+
+func (s *applicationVersionSetSuite) TestHelp(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, cmdString("application-version-set"))
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"--help"})
+	c.Assert(code, gc.Equals, 0)
+	expectedHelp := "" +
+		"usage: application-version-set <version>\n" +
+		"purpose: set workload version\n" +
+		"\n" +
+		"Sets the version of the workload software that the unit is running,\n" +
+		"such as the version of the database server the charm deploys. The\n" +
+		"version is shown in the unit's status.\n"
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, expectedHelp)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+}
+
+func (s *applicationVersionSetSuite) TestSetVersion(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, cmdString("application-version-set"))
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"4.2.1"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "")
+	s.Stub.CheckCall(c, 0, "SetUnitWorkloadVersion", "4.2.1")
+	c.Assert(hctx.info.WorkloadVersion, gc.Equals, "4.2.1")
+}
+
+func (s *applicationVersionSetSuite) TestSetVersionError(c *gc.C) {
+	s.Stub.SetErrors(errors.New("boom"))
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, cmdString("application-version-set"))
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"4.2.1"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: boom\n")
+}
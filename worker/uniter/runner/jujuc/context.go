@@ -45,6 +45,8 @@ type Context interface {
 type HookContext interface {
 	ContextUnit
 	ContextStatus
+	ContextVersion
+	ContextCharmState
 	ContextInstance
 	ContextNetworking
 	ContextLeadership
@@ -122,6 +124,31 @@ type ContextStatus interface {
 	SetServiceStatus(StatusInfo) error
 }
 
+// ContextVersion is the part of a hook context related to the version
+// of the unit's workload.
+type ContextVersion interface {
+	// SetUnitWorkloadVersion records the version of the workload
+	// software that the unit is running.
+	SetUnitWorkloadVersion(string) error
+}
+
+// ContextCharmState is the part of a hook context related to the
+// key/value state that the unit's charm stores with the state server.
+type ContextCharmState interface {
+	// GetCharmState returns all of the charm's state.
+	GetCharmState() (map[string]string, error)
+
+	// GetCharmStateValue returns the value of the given key, or an
+	// error satisfying errors.IsNotFound if it is not set.
+	GetCharmStateValue(key string) (string, error)
+
+	// SetCharmStateValue sets the value of the given key.
+	SetCharmStateValue(key, value string) error
+
+	// DeleteCharmStateValue removes the given key.
+	DeleteCharmStateValue(key string) error
+}
+
 // ContextInstance is the part of a hook context related to the unit's intance.
 type ContextInstance interface {
 	// AvailabilityZone returns the executing unit's availablilty zone or an error
@@ -156,6 +183,11 @@ type ContextNetworking interface {
 	// unit on its assigned machine. The result is sorted first by
 	// protocol, then by number.
 	OpenedPorts() []network.PortRange
+
+	// NetworkConfig returns the network configuration of the executing
+	// unit for the given binding name: its addresses in the space to
+	// which the charm endpoint of that name is bound.
+	NetworkConfig(bindingName string) ([]params.NetworkConfig, error)
 }
 
 // ContextLeadership is the part of a hook context related to the
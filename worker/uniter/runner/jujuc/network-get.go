@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// networkGetCommand implements the network-get command.
+type networkGetCommand struct {
+	cmd.CommandBase
+	ctx Context
+
+	bindingName    string
+	primaryAddress bool
+
+	out cmd.Output
+}
+
+// NewNetworkGetCommand returns a new networkGetCommand with the given context.
+func NewNetworkGetCommand(ctx Context) (cmd.Command, error) {
+	return &networkGetCommand{ctx: ctx}, nil
+}
+
+// Info is part of the cmd.Command interface.
+func (c *networkGetCommand) Info() *cmd.Info {
+	doc := `
+network-get prints the network configuration of the unit for the given
+binding name, which is the name of one of the charm's relation endpoints.
+If the endpoint is bound to a space, the unit's addresses in that space are
+printed, together with the CIDRs of their subnets; otherwise the unit's
+private address is printed.
+
+With --primary-address, only the first address is printed.
+`
+	return &cmd.Info{
+		Name:    "network-get",
+		Args:    "<binding-name> --primary-address",
+		Purpose: "get network config",
+		Doc:     doc,
+	}
+}
+
+// SetFlags is part of the cmd.Command interface.
+func (c *networkGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.BoolVar(&c.primaryAddress, "primary-address", false, "get the primary address for the binding")
+}
+
+// Init is part of the cmd.Command interface.
+func (c *networkGetCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("no arguments specified")
+	}
+	c.bindingName = args[0]
+	if c.bindingName == "" {
+		return errors.New("no binding name specified")
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run is part of the cmd.Command interface.
+func (c *networkGetCommand) Run(ctx *cmd.Context) error {
+	config, err := c.ctx.NetworkConfig(c.bindingName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(config) == 0 {
+		return errors.NotFoundf("addresses for binding %q", c.bindingName)
+	}
+	if c.primaryAddress {
+		return c.out.Write(ctx, config[0].Address)
+	}
+	addresses := make([]map[string]string, len(config))
+	for i, info := range config {
+		address := map[string]string{"address": info.Address}
+		if info.CIDR != "" {
+			address["cidr"] = info.CIDR
+		}
+		addresses[i] = address
+	}
+	return c.out.Write(ctx, map[string]interface{}{
+		"addresses": addresses,
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type NetworkGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&NetworkGetSuite{})
+
+func (s *NetworkGetSuite) createCommand(c *gc.C) cmd.Command {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.info.NetworkInterface.NetworkConfig = map[string][]params.NetworkConfig{
+		"db": {
+			{Address: "10.0.0.5", CIDR: "10.0.0.0/24"},
+			{Address: "10.10.0.5", CIDR: "10.10.0.0/24"},
+		},
+		"website": {
+			{Address: "192.168.0.99"},
+		},
+	}
+	com, err := jujuc.NewCommand(hctx, cmdString("network-get"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+var networkGetInitTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, "no arguments specified"},
+	{[]string{""}, "no binding name specified"},
+	{[]string{"db", "extra"}, `unrecognized args: \["extra"\]`},
+	{[]string{"db"}, ""},
+	{[]string{"db", "--primary-address"}, ""},
+}
+
+func (s *NetworkGetSuite) TestInit(c *gc.C) {
+	for i, t := range networkGetInitTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com := s.createCommand(c)
+		err := testing.InitCommand(com, t.args)
+		if t.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, t.err)
+		}
+	}
+}
+
+var networkGetTests = []struct {
+	args []string
+	out  string
+}{
+	{[]string{"db", "--primary-address"}, "10.0.0.5\n"},
+	{[]string{"db", "--primary-address", "--format", "json"}, `"10.0.0.5"` + "\n"},
+	{[]string{"website", "--primary-address"}, "192.168.0.99\n"},
+	{[]string{"db"}, `
+addresses:
+- address: 10.0.0.5
+  cidr: 10.0.0.0/24
+- address: 10.10.0.5
+  cidr: 10.10.0.0/24
+`[1:]},
+	{[]string{"website", "--format", "json"}, `{"addresses":[{"address":"192.168.0.99"}]}` + "\n"},
+}
+
+func (s *NetworkGetSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range networkGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com := s.createCommand(c)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *NetworkGetSuite) TestUnknownBinding(c *gc.C) {
+	com := s.createCommand(c)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"foo"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: binding \"foo\" not found\n")
+	c.Check(bufferString(ctx.Stdout), gc.Equals, "")
+}
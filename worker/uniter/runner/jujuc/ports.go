@@ -15,7 +15,7 @@ import (
 )
 
 const (
-	portFormat = "<port>[/<protocol>] or <from>-<to>[/<protocol>]"
+	portFormat = "<port>[/<protocol>] or <from>-<to>[/<protocol>] or icmp"
 
 	portExp  = "(?:[0-9]+)"
 	protoExp = "(?:[a-z0-9]+)"
@@ -69,6 +69,10 @@ func (pr portRange) validate() error {
 
 func parseArguments(args []string) (portRange, error) {
 	arg := strings.ToLower(args[0])
+	if arg == "icmp" {
+		// ICMP has no ports.
+		return portRange{-1, -1, "icmp"}, nil
+	}
 	if !validPortOrRange.MatchString(arg) {
 		return portRange{}, errors.Errorf("expected %s; got %q", portFormat, args[0])
 	}
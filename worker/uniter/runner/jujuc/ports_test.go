@@ -37,12 +37,16 @@ var portsTests = []struct {
 	{[]string{"close-port", "443/udp"}, makeRanges("99/tcp")},
 	{[]string{"open-port", "123/udp"}, makeRanges("99/tcp", "123/udp")},
 	{[]string{"close-port", "9999/UDP"}, makeRanges("99/tcp", "123/udp")},
+	{[]string{"open-port", "ICMP"}, makeRanges("99/tcp", "123/udp", "icmp")},
+	{[]string{"close-port", "icmp"}, makeRanges("99/tcp", "123/udp")},
 }
 
 func makeRanges(stringRanges ...string) []network.PortRange {
 	var results []network.PortRange
 	for _, s := range stringRanges {
-		if strings.Contains(s, "-") {
+		if s == "icmp" {
+			results = append(results, network.ICMPPortRange)
+		} else if strings.Contains(s, "-") {
 			parts := strings.Split(s, "-")
 			fromPort, _ := strconv.Atoi(parts[0])
 			parts = strings.Split(parts[1], "/")
@@ -89,9 +93,9 @@ var badPortsTests = []struct {
 	{nil, "no port or range specified"},
 	{[]string{"0"}, `port must be in the range \[1, 65535\]; got "0"`},
 	{[]string{"65536"}, `port must be in the range \[1, 65535\]; got "65536"`},
-	{[]string{"two"}, `expected <port>\[/<protocol>\] or <from>-<to>\[/<protocol>\]; got "two"`},
+	{[]string{"two"}, `expected <port>\[/<protocol>\] or <from>-<to>\[/<protocol>\] or icmp; got "two"`},
 	{[]string{"80/http"}, `protocol must be "tcp" or "udp"; got "http"`},
-	{[]string{"blah/blah/blah"}, `expected <port>\[/<protocol>\] or <from>-<to>\[/<protocol>\]; got "blah/blah/blah"`},
+	{[]string{"blah/blah/blah"}, `expected <port>\[/<protocol>\] or <from>-<to>\[/<protocol>\] or icmp; got "blah/blah/blah"`},
 	{[]string{"123", "haha"}, `unrecognized args: \["haha"\]`},
 	{[]string{"1-0"}, `invalid port range 1-0/tcp; expected fromPort <= toPort`},
 	{[]string{"-42"}, `flag provided but not defined: -4`},
@@ -99,6 +103,8 @@ var badPortsTests = []struct {
 	{[]string{"9999/foo"}, `protocol must be "tcp" or "udp"; got "foo"`},
 	{[]string{"80-90/http"}, `protocol must be "tcp" or "udp"; got "http"`},
 	{[]string{"20-10/tcp"}, `invalid port range 20-10/tcp; expected fromPort <= toPort`},
+	{[]string{"80/icmp"}, `protocol must be "tcp" or "udp"; got "icmp"`},
+	{[]string{"icmp", "80"}, `unrecognized args: \["80"\]`},
 }
 
 func (s *PortsSuite) TestBadArgs(c *gc.C) {
@@ -119,7 +125,7 @@ func (s *PortsSuite) TestHelp(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	flags := testing.NewFlagSet()
 	c.Assert(string(open.Info().Help(flags)), gc.Equals, `
-usage: open-port <port>[/<protocol>] or <from>-<to>[/<protocol>]
+usage: open-port <port>[/<protocol>] or <from>-<to>[/<protocol>] or icmp
 purpose: register a port or range to open
 
 The port range will only be open while the service is exposed.
@@ -128,7 +134,7 @@ The port range will only be open while the service is exposed.
 	close, err := jujuc.NewCommand(hctx, cmdString("close-port"))
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(string(close.Info().Help(flags)), gc.Equals, `
-usage: close-port <port>[/<protocol>] or <from>-<to>[/<protocol>]
+usage: close-port <port>[/<protocol>] or <from>-<to>[/<protocol>] or icmp
 purpose: ensure a port or range is always closed
 `[1:])
 }
@@ -39,6 +39,25 @@ func (*RestrictedContext) ServiceStatus() (ServiceStatusInfo, error) {
 // SetServiceStatus implements jujuc.Context.
 func (*RestrictedContext) SetServiceStatus(StatusInfo) error { return ErrRestrictedContext }
 
+// SetUnitWorkloadVersion implements jujuc.Context.
+func (*RestrictedContext) SetUnitWorkloadVersion(string) error { return ErrRestrictedContext }
+
+// GetCharmState implements jujuc.Context.
+func (*RestrictedContext) GetCharmState() (map[string]string, error) {
+	return nil, ErrRestrictedContext
+}
+
+// GetCharmStateValue implements jujuc.Context.
+func (*RestrictedContext) GetCharmStateValue(string) (string, error) {
+	return "", ErrRestrictedContext
+}
+
+// SetCharmStateValue implements jujuc.Context.
+func (*RestrictedContext) SetCharmStateValue(string, string) error { return ErrRestrictedContext }
+
+// DeleteCharmStateValue implements jujuc.Context.
+func (*RestrictedContext) DeleteCharmStateValue(string) error { return ErrRestrictedContext }
+
 // AvailabilityZone implements jujuc.Context.
 func (*RestrictedContext) AvailabilityZone() (string, error) { return "", ErrRestrictedContext }
 
@@ -64,6 +83,11 @@ func (*RestrictedContext) ClosePorts(protocol string, fromPort, toPort int) erro
 // OpenedPorts implements jujuc.Context.
 func (*RestrictedContext) OpenedPorts() []network.PortRange { return nil }
 
+// NetworkConfig implements jujuc.Context.
+func (*RestrictedContext) NetworkConfig(bindingName string) ([]params.NetworkConfig, error) {
+	return nil, ErrRestrictedContext
+}
+
 // IsLeader implements jujuc.Context.
 func (*RestrictedContext) IsLeader() (bool, error) { return false, ErrRestrictedContext }
 
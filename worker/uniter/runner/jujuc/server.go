@@ -51,6 +51,13 @@ var baseCommands = map[string]creator{
 	"juju-reboot" + cmdSuffix:   NewJujuRebootCommand,
 	"status-get" + cmdSuffix:    NewStatusGetCommand,
 	"status-set" + cmdSuffix:    NewStatusSetCommand,
+	"network-get" + cmdSuffix:   NewNetworkGetCommand,
+
+	"application-version-set" + cmdSuffix: NewApplicationVersionSetCommand,
+
+	"state-get" + cmdSuffix:    NewStateGetCommand,
+	"state-set" + cmdSuffix:    NewStateSetCommand,
+	"state-delete" + cmdSuffix: NewStateDeleteCommand,
 }
 
 var storageCommands = map[string]creator{
@@ -265,6 +265,11 @@ var newCommandTests = []struct {
 	{"storage-get", ""},
 	{"status-get", ""},
 	{"status-set", ""},
+	{"network-get", ""},
+	{"application-version-set", ""},
+	{"state-get", ""},
+	{"state-set", ""},
+	{"state-delete", ""},
 	// The error message contains .exe on Windows
 	{"random", "unknown command: random(.exe)?"},
 }
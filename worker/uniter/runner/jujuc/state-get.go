@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// stateGetCommand implements the state-get command.
+type stateGetCommand struct {
+	cmd.CommandBase
+	ctx    Context
+	key    string
+	strict bool
+	out    cmd.Output
+}
+
+// NewStateGetCommand returns a new stateGetCommand with the given context.
+func NewStateGetCommand(ctx Context) (cmd.Command, error) {
+	return &stateGetCommand{ctx: ctx}, nil
+}
+
+// Info is part of the cmd.Command interface.
+func (c *stateGetCommand) Info() *cmd.Info {
+	doc := `
+state-get prints the value of a key in the unit's persistent state, which is
+stored by the state server rather than on the unit's machine. If no key is
+given, or if the key is "-", all keys and values will be printed.
+
+A missing key prints nothing, unless --strict is given, in which case it is
+an error.
+`
+	return &cmd.Info{
+		Name:    "state-get",
+		Args:    "[<key>]",
+		Purpose: "print unit persistent state",
+		Doc:     doc,
+	}
+}
+
+// SetFlags is part of the cmd.Command interface.
+func (c *stateGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.BoolVar(&c.strict, "strict", false, "return an error if the key is not set")
+}
+
+// Init is part of the cmd.Command interface.
+func (c *stateGetCommand) Init(args []string) error {
+	c.key = ""
+	if len(args) == 0 {
+		return nil
+	}
+	key := args[0]
+	if key == "-" {
+		key = ""
+	} else if strings.Contains(key, "=") {
+		return errors.Errorf("invalid key %q", key)
+	}
+	c.key = key
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run is part of the cmd.Command interface.
+func (c *stateGetCommand) Run(ctx *cmd.Context) error {
+	if c.key == "" {
+		state, err := c.ctx.GetCharmState()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return c.out.Write(ctx, state)
+	}
+	value, err := c.ctx.GetCharmStateValue(c.key)
+	if errors.IsNotFound(err) && !c.strict {
+		return c.out.Write(ctx, nil)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return c.out.Write(ctx, value)
+}
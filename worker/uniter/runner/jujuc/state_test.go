@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type stateSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&stateSuite{})
+
+func (s *stateSuite) newHookContext(c *gc.C) *Context {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.info.CharmState.CharmState = map[string]string{
+		"one": "1",
+		"two": "2",
+	}
+	return hctx
+}
+
+func (s *stateSuite) run(c *gc.C, hctx *Context, name string, args ...string) (int, *cmd.Context) {
+	com, err := jujuc.NewCommand(hctx, cmdString(name))
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, args)
+	return code, ctx
+}
+
+var stateInitTests = []struct {
+	command string
+	args    []string
+	err     string
+}{
+	{"state-get", nil, ""},
+	{"state-get", []string{"-"}, ""},
+	{"state-get", []string{"key"}, ""},
+	{"state-get", []string{"key=value"}, `invalid key "key=value"`},
+	{"state-get", []string{"key", "extra"}, `unrecognized args: \["extra"\]`},
+	{"state-set", nil, "no key/value pairs specified"},
+	{"state-set", []string{"key=value", "other="}, ""},
+	{"state-set", []string{"key"}, `expected "key=value", got "key"`},
+	{"state-delete", nil, "no keys specified"},
+	{"state-delete", []string{"key", "other"}, ""},
+}
+
+func (s *stateSuite) TestInit(c *gc.C) {
+	for i, t := range stateInitTests {
+		c.Logf("test %d: %s %#v", i, t.command, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, cmdString(t.command))
+		c.Assert(err, jc.ErrorIsNil)
+		testing.TestInit(c, com, t.args, t.err)
+	}
+}
+
+func (s *stateSuite) TestGetKey(c *gc.C) {
+	code, ctx := s.run(c, s.newHookContext(c), "state-get", "one")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "1\n")
+	s.Stub.CheckCall(c, 0, "GetCharmStateValue", "one")
+}
+
+func (s *stateSuite) TestGetMissingKey(c *gc.C) {
+	code, ctx := s.run(c, s.newHookContext(c), "state-get", "three")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "")
+}
+
+func (s *stateSuite) TestGetMissingKeyStrict(c *gc.C) {
+	code, ctx := s.run(c, s.newHookContext(c), "state-get", "--strict", "three")
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: state key \"three\" not found\n")
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "")
+}
+
+func (s *stateSuite) TestGetAll(c *gc.C) {
+	code, ctx := s.run(c, s.newHookContext(c), "state-get", "--format", "json")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Assert(bufferString(ctx.Stdout), jc.JSONEquals, map[string]string{
+		"one": "1",
+		"two": "2",
+	})
+	s.Stub.CheckCallNames(c, "GetCharmState")
+}
+
+func (s *stateSuite) TestGetError(c *gc.C) {
+	s.Stub.SetErrors(errors.New("boom"))
+	code, ctx := s.run(c, s.newHookContext(c), "state-get", "one")
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: boom\n")
+}
+
+func (s *stateSuite) TestSet(c *gc.C) {
+	hctx := s.newHookContext(c)
+	code, ctx := s.run(c, hctx, "state-set", "two=zwei", "three=3")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	s.Stub.CheckCall(c, 0, "SetCharmStateValue", "three", "3")
+	s.Stub.CheckCall(c, 1, "SetCharmStateValue", "two", "zwei")
+	c.Assert(hctx.info.CharmState.CharmState, jc.DeepEquals, map[string]string{
+		"one":   "1",
+		"two":   "zwei",
+		"three": "3",
+	})
+}
+
+func (s *stateSuite) TestSetError(c *gc.C) {
+	s.Stub.SetErrors(errors.New("boom"))
+	code, ctx := s.run(c, s.newHookContext(c), "state-set", "one=eins")
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: cannot set state key \"one\": boom\n")
+}
+
+func (s *stateSuite) TestDelete(c *gc.C) {
+	hctx := s.newHookContext(c)
+	code, ctx := s.run(c, hctx, "state-delete", "one", "three")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	s.Stub.CheckCall(c, 0, "DeleteCharmStateValue", "one")
+	s.Stub.CheckCall(c, 1, "DeleteCharmStateValue", "three")
+	c.Assert(hctx.info.CharmState.CharmState, jc.DeepEquals, map[string]string{
+		"two": "2",
+	})
+}
+
+func (s *stateSuite) TestDeleteError(c *gc.C) {
+	s.Stub.SetErrors(errors.New("boom"))
+	code, ctx := s.run(c, s.newHookContext(c), "state-delete", "one")
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: cannot delete state key \"one\": boom\n")
+}
@@ -13,6 +13,8 @@ import (
 type ContextInfo struct {
 	Unit
 	Status
+	Version
+	CharmState
 	Instance
 	NetworkInterface
 	Leadership
@@ -51,6 +53,8 @@ type contextBase struct {
 type Context struct {
 	ContextUnit
 	ContextStatus
+	ContextVersion
+	ContextCharmState
 	ContextInstance
 	ContextNetworking
 	ContextLeader
@@ -68,6 +72,10 @@ func NewContext(stub *testing.Stub, info *ContextInfo) *Context {
 	ctx.ContextUnit.info = &info.Unit
 	ctx.ContextStatus.stub = stub
 	ctx.ContextStatus.info = &info.Status
+	ctx.ContextVersion.stub = stub
+	ctx.ContextVersion.info = &info.Version
+	ctx.ContextCharmState.stub = stub
+	ctx.ContextCharmState.info = &info.CharmState
 	ctx.ContextInstance.stub = stub
 	ctx.ContextInstance.info = &info.Instance
 	ctx.ContextNetworking.stub = stub
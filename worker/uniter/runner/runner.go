@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -44,6 +45,7 @@ type Context interface {
 	Id() string
 	HookVars(paths context.Paths) ([]string, error)
 	ActionData() (*context.ActionData, error)
+	ActionAborted() (bool, error)
 	SetProcess(process *os.Process)
 	HasExecutionSetUnitStatus() bool
 	ResetExecutionSetUnitStatus()
@@ -165,12 +167,68 @@ func (runner *runner) runCharmHook(hookName string, env []string, charmLocation
 		// Record the *os.Process of the hook
 		runner.context.SetProcess(ps.Process)
 		// Block until execution finishes
-		err = ps.Wait()
+		if charmLocation == "actions" {
+			err = runner.waitAction(ps)
+		} else {
+			err = ps.Wait()
+		}
 	}
 	hookLogger.stop()
 	return errors.Trace(err)
 }
 
+// actionAbortPollInterval is how often a running action checks whether
+// it has been cancelled.
+var actionAbortPollInterval = 5 * time.Second
+
+// waitAction waits for the given action process to finish, killing it
+// if it runs for longer than the action's timeout, or if the action is
+// cancelled.
+func (runner *runner) waitAction(ps *exec.Cmd) error {
+	actionData, err := runner.context.ActionData()
+	if err != nil {
+		ps.Process.Kill()
+		ps.Wait()
+		return errors.Trace(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ps.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if actionData.Timeout > 0 {
+		timer := time.NewTimer(actionData.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	poll := time.NewTicker(actionAbortPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-timeout:
+			logger.Infof("action %q timed out after %v", actionData.Name, actionData.Timeout)
+			ps.Process.Kill()
+			<-done
+			return errors.Errorf("action timed out after %v", actionData.Timeout)
+		case <-poll.C:
+			aborted, err := runner.context.ActionAborted()
+			if err != nil {
+				logger.Warningf("cannot check whether action %q was cancelled: %v", actionData.Name, err)
+				continue
+			}
+			if aborted {
+				logger.Infof("action %q cancelled", actionData.Name)
+				ps.Process.Kill()
+				<-done
+				return errors.New("action cancelled")
+			}
+		}
+	}
+}
+
 func (runner *runner) startJujucServer() (*jujuc.Server, error) {
 	// Prepare server.
 	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
@@ -148,6 +148,7 @@ func (s *RunHookSuite) TestRunHook(c *gc.C) {
 type MockContext struct {
 	runner.Context
 	actionData   *context.ActionData
+	aborted      bool
 	expectPid    int
 	flushBadge   string
 	flushFailure error
@@ -169,6 +170,10 @@ func (ctx *MockContext) ActionData() (*context.ActionData, error) {
 	return ctx.actionData, nil
 }
 
+func (ctx *MockContext) ActionAborted() (bool, error) {
+	return ctx.aborted, nil
+}
+
 func (ctx *MockContext) SetProcess(process *os.Process) {
 	ctx.expectPid = process.Pid
 }
@@ -275,6 +280,42 @@ func (s *RunMockContextSuite) TestRunActionFlushFailure(c *gc.C) {
 	s.assertRecordedPid(c, ctx.expectPid)
 }
 
+func (s *RunMockContextSuite) TestRunActionTimeout(c *gc.C) {
+	ctx := &MockContext{
+		actionData: &context.ActionData{Timeout: 100 * time.Millisecond},
+	}
+	makeCharm(c, hookSpec{
+		dir:   "actions",
+		name:  hookName,
+		perm:  0700,
+		sleep: 10,
+	}, s.paths.GetCharmDir())
+	t0 := time.Now()
+	err := runner.NewRunner(ctx, s.paths).RunAction("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushFailure, gc.ErrorMatches, "action timed out after 100ms")
+	c.Assert(time.Since(t0) < 5*time.Second, jc.IsTrue)
+}
+
+func (s *RunMockContextSuite) TestRunActionAborted(c *gc.C) {
+	s.PatchValue(runner.ActionAbortPollInterval, 10*time.Millisecond)
+	ctx := &MockContext{
+		actionData: &context.ActionData{},
+		aborted:    true,
+	}
+	makeCharm(c, hookSpec{
+		dir:   "actions",
+		name:  hookName,
+		perm:  0700,
+		sleep: 10,
+	}, s.paths.GetCharmDir())
+	t0 := time.Now()
+	err := runner.NewRunner(ctx, s.paths).RunAction("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushFailure, gc.ErrorMatches, "action cancelled")
+	c.Assert(time.Since(t0) < 5*time.Second, jc.IsTrue)
+}
+
 func (s *RunMockContextSuite) TestRunCommandsFlushSuccess(c *gc.C) {
 	expectErr := errors.New("pew pew pew")
 	ctx := &MockContext{
@@ -4,7 +4,10 @@
 package uniter
 
 import (
+	"sync"
 	"time"
+
+	"github.com/juju/juju/apiserver/params"
 )
 
 const (
@@ -21,3 +24,95 @@ func updateStatusSignal() <-chan time.Time {
 func NewUpdateStatusTimer() func() <-chan time.Time {
 	return updateStatusSignal
 }
+
+// hookRetryTimer signals when a failed hook should be retried. The
+// delay before each retry grows according to the retry strategy, until
+// the timer is reset.
+type hookRetryTimer struct {
+	strategy params.HookRetryStrategy
+	after    func(time.Duration) <-chan time.Time
+	signal   chan struct{}
+
+	mu       sync.Mutex
+	attempts int
+	cancel   chan struct{}
+}
+
+// newHookRetryTimer returns a hookRetryTimer that follows the given
+// strategy, using after to wait for each retry.
+func newHookRetryTimer(strategy params.HookRetryStrategy, after func(time.Duration) <-chan time.Time) *hookRetryTimer {
+	return &hookRetryTimer{
+		strategy: strategy,
+		after:    after,
+		signal:   make(chan struct{}, 1),
+	}
+}
+
+// Signal returns the channel on which the timer signals that the
+// failed hook should be retried.
+func (t *hookRetryTimer) Signal() <-chan struct{} {
+	return t.signal
+}
+
+// Start schedules the next retry of the failed hook, unless retries are
+// disabled, all permitted attempts have been made, or a retry is
+// already scheduled.
+func (t *hookRetryTimer) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.strategy.ShouldRetry || t.cancel != nil {
+		return
+	}
+	if t.strategy.MaxAttempts > 0 && t.attempts >= t.strategy.MaxAttempts {
+		logger.Infof("hook failed %d times; waiting for the error to be resolved", t.attempts+1)
+		return
+	}
+	delay := t.delay()
+	t.attempts++
+	logger.Infof("retrying failed hook in %v", delay)
+	wake := t.after(delay)
+	cancel := make(chan struct{})
+	t.cancel = cancel
+	go func() {
+		select {
+		case <-wake:
+		case <-cancel:
+			return
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.cancel != cancel {
+			// Reset while we were waking.
+			return
+		}
+		t.cancel = nil
+		select {
+		case t.signal <- struct{}{}:
+		default:
+		}
+	}()
+}
+
+// Reset cancels any scheduled retry, so that the next one is made
+// after the strategy's minimum delay.
+func (t *hookRetryTimer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		close(t.cancel)
+		t.cancel = nil
+	}
+	t.attempts = 0
+}
+
+// delay returns the time to wait before the next retry.
+func (t *hookRetryTimer) delay() time.Duration {
+	delay := t.strategy.MinDelay
+	for i := 0; i < t.attempts && delay < t.strategy.MaxDelay; i++ {
+		delay = time.Duration(float64(delay) * t.strategy.BackoffFactor)
+	}
+	if delay > t.strategy.MaxDelay {
+		delay = t.strategy.MaxDelay
+	}
+	return delay
+}
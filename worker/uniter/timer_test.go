@@ -0,0 +1,116 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter"
+)
+
+type hookRetryTimerSuite struct {
+	coretesting.BaseSuite
+	clock *coretesting.Clock
+}
+
+var _ = gc.Suite(&hookRetryTimerSuite{})
+
+func (s *hookRetryTimerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = coretesting.NewClock(time.Now())
+}
+
+var testHookRetryStrategy = params.HookRetryStrategy{
+	ShouldRetry:   true,
+	MaxAttempts:   3,
+	MinDelay:      5 * time.Second,
+	MaxDelay:      15 * time.Second,
+	BackoffFactor: 2,
+}
+
+func (s *hookRetryTimerSuite) assertSignal(c *gc.C, signal <-chan struct{}) {
+	select {
+	case <-signal:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for hook retry signal")
+	}
+}
+
+func (s *hookRetryTimerSuite) assertNoSignal(c *gc.C, signal <-chan struct{}) {
+	select {
+	case <-signal:
+		c.Fatalf("unexpected hook retry signal")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *hookRetryTimerSuite) TestBackoff(c *gc.C) {
+	timer := uniter.NewHookRetryTimer(testHookRetryStrategy, s.clock.After)
+	defer timer.Reset()
+
+	for _, delay := range []time.Duration{
+		5 * time.Second, 10 * time.Second, 15 * time.Second,
+	} {
+		timer.Start()
+		s.clock.Advance(delay - time.Second)
+		s.assertNoSignal(c, timer.Signal())
+		s.clock.Advance(time.Second)
+		s.assertSignal(c, timer.Signal())
+	}
+
+	// All permitted attempts have been made.
+	timer.Start()
+	s.clock.Advance(time.Hour)
+	s.assertNoSignal(c, timer.Signal())
+}
+
+func (s *hookRetryTimerSuite) TestFractionalBackoff(c *gc.C) {
+	strategy := testHookRetryStrategy
+	strategy.BackoffFactor = 1.5
+	strategy.MaxDelay = time.Minute
+	timer := uniter.NewHookRetryTimer(strategy, s.clock.After)
+	defer timer.Reset()
+
+	for _, delay := range []time.Duration{
+		5 * time.Second, 7500 * time.Millisecond, 11250 * time.Millisecond,
+	} {
+		timer.Start()
+		s.clock.Advance(delay - time.Millisecond)
+		s.assertNoSignal(c, timer.Signal())
+		s.clock.Advance(time.Millisecond)
+		s.assertSignal(c, timer.Signal())
+	}
+}
+
+func (s *hookRetryTimerSuite) TestReset(c *gc.C) {
+	timer := uniter.NewHookRetryTimer(testHookRetryStrategy, s.clock.After)
+	timer.Start()
+	s.clock.Advance(5 * time.Second)
+	s.assertSignal(c, timer.Signal())
+
+	// A pending retry is cancelled, and the next one waits
+	// the minimum delay again.
+	timer.Start()
+	timer.Reset()
+	s.clock.Advance(10 * time.Second)
+	s.assertNoSignal(c, timer.Signal())
+
+	timer.Start()
+	defer timer.Reset()
+	s.clock.Advance(5 * time.Second)
+	s.assertSignal(c, timer.Signal())
+}
+
+func (s *hookRetryTimerSuite) TestDisabled(c *gc.C) {
+	strategy := testHookRetryStrategy
+	strategy.ShouldRetry = false
+	timer := uniter.NewHookRetryTimer(strategy, s.clock.After)
+	timer.Start()
+	s.clock.Advance(time.Hour)
+	s.assertNoSignal(c, timer.Signal())
+}
@@ -174,6 +174,20 @@ func (u *Uniter) loop(unitTag names.UnitTag) (err error) {
 		charmURL = curl
 	}
 
+	strategy, err := u.unit.HookRetryStrategy()
+	if errors.IsNotImplemented(err) {
+		// Older state servers cannot tell us to retry failed
+		// hooks, so we wait for errors to be resolved.
+		logger.Debugf("hook retry strategy unavailable: %v", err)
+	} else if err != nil {
+		return errors.Annotate(err, "getting hook retry strategy")
+	}
+	retryHookTimer := newHookRetryTimer(strategy, time.After)
+	u.addCleanup(func() error {
+		retryHookTimer.Reset()
+		return nil
+	})
+
 	var (
 		watcher   *remotestate.RemoteStateWatcher
 		watcherMu sync.Mutex
@@ -195,6 +209,7 @@ func (u *Uniter) loop(unitTag names.UnitTag) (err error) {
 				LeadershipTracker:   u.leadershipTracker,
 				UnitTag:             unitTag,
 				UpdateStatusChannel: u.updateStatusAt,
+				RetryHookChannel:    retryHookTimer.Signal(),
 			})
 		if err != nil {
 			return errors.Trace(err)
@@ -248,13 +263,15 @@ func (u *Uniter) loop(unitTag names.UnitTag) (err error) {
 		}
 
 		uniterResolver := &uniterResolver{
-			clearResolved:      clearResolved,
-			reportHookError:    u.reportHookError,
-			fixDeployer:        u.deployer.Fix,
-			actionsResolver:    actions.NewResolver(),
-			leadershipResolver: uniterleadership.NewResolver(),
-			relationsResolver:  relation.NewRelationsResolver(u.relations),
-			storageResolver:    storage.NewResolver(u.storage),
+			clearResolved:       clearResolved,
+			reportHookError:     u.reportHookError,
+			fixDeployer:         u.deployer.Fix,
+			startRetryHookTimer: retryHookTimer.Start,
+			stopRetryHookTimer:  retryHookTimer.Reset,
+			actionsResolver:     actions.NewResolver(),
+			leadershipResolver:  uniterleadership.NewResolver(),
+			relationsResolver:   relation.NewRelationsResolver(u.relations),
+			storageResolver:     storage.NewResolver(u.storage),
 		}
 
 		// We should not do anything until there has been a change
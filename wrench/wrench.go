@@ -12,7 +12,6 @@ import (
 	"sync"
 
 	"github.com/juju/loggo"
-	"github.com/juju/utils/series"
 
 	"github.com/juju/juju/juju/paths"
 )
@@ -21,7 +20,7 @@ var (
 	enabledMu sync.Mutex
 	enabled   = true
 
-	dataDir   = paths.MustSucceed(paths.DataDir(series.HostSeries()))
+	dataDir   = paths.MustSucceed(paths.HostDataDir())
 	wrenchDir = filepath.Join(dataDir, "wrench")
 	jujuUid   = os.Getuid()
 )